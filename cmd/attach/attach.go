@@ -0,0 +1,83 @@
+package attach
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"os"
+
+	"github.com/nehpz/claudicus/pkg/cmdctx"
+	"github.com/nehpz/claudicus/pkg/hosts"
+	"github.com/nehpz/claudicus/pkg/tui"
+
+	"github.com/peterbourgon/ff/v3/ffcli"
+)
+
+var (
+	fs        = flag.NewFlagSet("uzi attach", flag.ExitOnError)
+	printOnly = fs.Bool("print", false, "print the command that attaches to the agent instead of running it")
+	CmdAttach = &ffcli.Command{
+		Name:       "attach",
+		ShortUsage: "uzi attach [--print] [<agent-name>]",
+		ShortHelp:  "Attach the terminal to an agent's tmux session",
+		LongHelp: `Attach the terminal to the tmux session of an agent, over ssh for agents
+on remote hosts. Without an agent name on a terminal, the agent is picked
+from a list.
+
+With --print, the command that attaches is printed instead of run, e.g.
+
+  ssh -t dev@gpu1 tmux attach-session -t agent-app-3f2a1c-claude
+
+so a teammate on the same machine, or with access to the remote host, can
+paste it to watch or take over the agent without looking up its session.`,
+		FlagSet: fs,
+		Exec:    executeAttach,
+	}
+)
+
+// interactive and pickAgent let the agent to attach to be picked on a
+// terminal when none is named
+var (
+	interactive = tui.Interactive
+	pickAgent   = tui.PickAgent
+)
+
+func executeAttach(ctx context.Context, args []string) error {
+	if len(args) == 0 && interactive() {
+		agentName, err := pickAgent("Attach to which agent?")
+		if errors.Is(err, tui.ErrNoAgentPicked) {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+		args = []string{agentName}
+	}
+	if len(args) != 1 {
+		return fmt.Errorf("agent name argument is required")
+	}
+
+	sm, err := cmdctx.From(ctx).State()
+	if err != nil {
+		return err
+	}
+	sessionName, err := sm.SessionForAgent(args[0])
+	if err != nil {
+		return err
+	}
+	target := hosts.Local()
+	if agentState, err := sm.GetWorktreeInfo(sessionName); err == nil {
+		target = hosts.ForState(*agentState)
+	}
+
+	if *printOnly {
+		fmt.Println(target.AttachCommandLine(sessionName))
+		return nil
+	}
+	cmd := target.AttachCommand(sessionName)
+	cmd.Stdin = os.Stdin
+	cmd.Stdout = os.Stdout
+	cmd.Stderr = os.Stderr
+	return cmd.Run()
+}
@@ -0,0 +1,29 @@
+package attach
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/nehpz/claudicus/pkg/tui"
+)
+
+func TestExecuteAttachPicksAgent(t *testing.T) {
+	defer func(i func() bool, p func(string) (string, error)) { interactive, pickAgent = i, p }(interactive, pickAgent)
+	interactive = func() bool { return true }
+
+	pickAgent = func(string) (string, error) { return "", tui.ErrNoAgentPicked }
+	if err := executeAttach(context.Background(), nil); err != nil {
+		t.Errorf("Expected a cancelled pick to do nothing, got %v", err)
+	}
+
+	pickAgent = func(string) (string, error) { return "", errors.New("no active agents") }
+	if err := executeAttach(context.Background(), nil); err == nil || err.Error() != "no active agents" {
+		t.Errorf("Expected the picker's error, got %v", err)
+	}
+
+	interactive = func() bool { return false }
+	if err := executeAttach(context.Background(), nil); err == nil {
+		t.Error("Expected an agent name to be required without a terminal")
+	}
+}
@@ -0,0 +1,179 @@
+package auth
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/nehpz/claudicus/pkg/config"
+	"github.com/nehpz/claudicus/pkg/keyring"
+
+	"github.com/peterbourgon/ff/v3/ffcli"
+	"golang.org/x/term"
+)
+
+var (
+	fs        = flag.NewFlagSet("uzi auth", flag.ExitOnError)
+	setFs     = flag.NewFlagSet("uzi auth set", flag.ExitOnError)
+	setConfig = setFs.String("config", config.GetDefaultConfigPath(), "path to config file")
+	rmFs      = flag.NewFlagSet("uzi auth rm", flag.ExitOnError)
+	lsFs      = flag.NewFlagSet("uzi auth ls", flag.ExitOnError)
+	lsConfig  = lsFs.String("config", config.GetDefaultConfigPath(), "path to config file")
+	apiKeys   = keyring.System()
+	CmdAuth   = &ffcli.Command{
+		Name:       "auth",
+		ShortUsage: "uzi auth <set|rm|ls>",
+		ShortHelp:  "Store agent API keys in the OS keyring",
+		LongHelp: `Keep the API keys of agent CLIs in the OS keyring instead of shell
+profiles or uzi.yaml. When an agent is spawned on this machine, its stored key
+is exported into its tmux session as the variable its CLI reads, e.g.
+ANTHROPIC_API_KEY for claude. Agents on remote hosts don't get the keys.
+
+The variables of claude, codex, gemini, and cursor are built in; others are
+set in uzi.yaml:
+
+  auth:
+    env:
+      aider: OPENAI_API_KEY
+
+Keys are stored with security on macOS and secret-tool (libsecret) on Linux.
+Exporting them needs tmux 3.2 or newer.`,
+		FlagSet: fs,
+		Subcommands: []*ffcli.Command{
+			{
+				Name:       "set",
+				ShortUsage: "uzi auth set <agent-type>",
+				ShortHelp:  "Store the API key of an agent type",
+				LongHelp: `Store the API key of an agent type, such as claude. The key is read
+without echo from the terminal, or from stdin when it is piped in.`,
+				FlagSet: setFs,
+				Exec: func(ctx context.Context, args []string) error {
+					if len(args) != 1 {
+						return fmt.Errorf("usage: uzi auth set <agent-type>")
+					}
+					cfg, err := loadConfig(*setConfig)
+					if err != nil {
+						return err
+					}
+					return executeSet(apiKeys, cfg, args[0], os.Stdin, os.Stdout)
+				},
+			},
+			{
+				Name:       "rm",
+				ShortUsage: "uzi auth rm <agent-type>",
+				ShortHelp:  "Remove the stored API key of an agent type",
+				FlagSet:    rmFs,
+				Exec: func(ctx context.Context, args []string) error {
+					if len(args) != 1 {
+						return fmt.Errorf("usage: uzi auth rm <agent-type>")
+					}
+					return executeRm(apiKeys, args[0], os.Stdout)
+				},
+			},
+			{
+				Name:       "ls",
+				ShortUsage: "uzi auth ls",
+				ShortHelp:  "Show which agent types have a stored API key",
+				FlagSet:    lsFs,
+				Exec: func(ctx context.Context, args []string) error {
+					cfg, err := loadConfig(*lsConfig)
+					if err != nil {
+						return err
+					}
+					return executeLs(apiKeys, cfg, os.Stdout)
+				},
+			},
+		},
+		Exec: func(ctx context.Context, args []string) error {
+			return flag.ErrHelp
+		},
+	}
+)
+
+// loadConfig loads uzi.yaml for its auth.env section; without one the
+// built-in variables are used
+func loadConfig(path string) (*config.Config, error) {
+	cfg, err := config.LoadConfig(path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return cfg, cfg.Validate()
+}
+
+func executeSet(k keyring.Keyring, cfg *config.Config, agent string, in *os.File, out io.Writer) error {
+	name := cfg.APIKeyEnv(agent)
+	if name == "" {
+		return fmt.Errorf("no environment variable is known for agent type %q; set auth.env.%s in uzi.yaml", agent, agent)
+	}
+	key, err := readKey(agent, in, out)
+	if err != nil {
+		return err
+	}
+	if err := k.Set(agent, key); err != nil {
+		return fmt.Errorf("failed to store the API key: %w", err)
+	}
+	fmt.Fprintf(out, "Stored the API key for %s; new %s agents get it as %s\n", agent, agent, name)
+	return nil
+}
+
+// readKey reads an API key without echo from a terminal, or as the first
+// line of piped input
+func readKey(agent string, in *os.File, out io.Writer) (string, error) {
+	var key string
+	if term.IsTerminal(int(in.Fd())) {
+		fmt.Fprintf(out, "API key for %s: ", agent)
+		data, err := term.ReadPassword(int(in.Fd()))
+		fmt.Fprintln(out)
+		if err != nil {
+			return "", fmt.Errorf("failed to read the API key: %w", err)
+		}
+		key = string(data)
+	} else {
+		line, err := bufio.NewReader(in).ReadString('\n')
+		if err != nil && err != io.EOF {
+			return "", fmt.Errorf("failed to read the API key: %w", err)
+		}
+		key = line
+	}
+	key = strings.TrimSpace(key)
+	if key == "" {
+		return "", fmt.Errorf("no API key given")
+	}
+	return key, nil
+}
+
+func executeRm(k keyring.Keyring, agent string, out io.Writer) error {
+	if err := k.Delete(agent); err != nil {
+		if errors.Is(err, keyring.ErrNotFound) {
+			return fmt.Errorf("no API key is stored for %s", agent)
+		}
+		return fmt.Errorf("failed to remove the API key: %w", err)
+	}
+	fmt.Fprintf(out, "Removed the API key for %s\n", agent)
+	return nil
+}
+
+func executeLs(k keyring.Keyring, cfg *config.Config, out io.Writer) error {
+	w := tabwriter.NewWriter(out, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "AGENT\tVARIABLE\tKEY")
+	for _, agent := range cfg.APIKeyAgents() {
+		stored := "stored"
+		if _, err := k.Get(agent); err != nil {
+			if !errors.Is(err, keyring.ErrNotFound) {
+				return fmt.Errorf("failed to read the keyring: %w", err)
+			}
+			stored = "-"
+		}
+		fmt.Fprintf(w, "%s\t%s\t%s\n", agent, cfg.APIKeyEnv(agent), stored)
+	}
+	return w.Flush()
+}
@@ -0,0 +1,92 @@
+package auth
+
+import (
+	"bytes"
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/nehpz/claudicus/pkg/config"
+	"github.com/nehpz/claudicus/pkg/keyring"
+)
+
+// mapKeyring is an in-memory keyring
+type mapKeyring map[string]string
+
+func (m mapKeyring) Get(agent string) (string, error) {
+	if key, ok := m[agent]; ok {
+		return key, nil
+	}
+	return "", keyring.ErrNotFound
+}
+
+func (m mapKeyring) Set(agent, key string) error {
+	m[agent] = key
+	return nil
+}
+
+func (m mapKeyring) Delete(agent string) error {
+	if _, ok := m[agent]; !ok {
+		return keyring.ErrNotFound
+	}
+	delete(m, agent)
+	return nil
+}
+
+// pipeInput returns a file that reads input, as piped into uzi auth set
+func pipeInput(t *testing.T, input string) *os.File {
+	t.Helper()
+	r, w, err := os.Pipe()
+	if err != nil {
+		t.Fatal(err)
+	}
+	w.WriteString(input)
+	w.Close()
+	t.Cleanup(func() { r.Close() })
+	return r
+}
+
+func TestAuthCommands(t *testing.T) {
+	keys := mapKeyring{}
+	var out bytes.Buffer
+
+	if err := executeSet(keys, nil, "claude", pipeInput(t, "  sk-ant-123\n"), &out); err != nil {
+		t.Fatal(err)
+	}
+	if keys["claude"] != "sk-ant-123" || !strings.Contains(out.String(), "as ANTHROPIC_API_KEY") {
+		t.Errorf("Expected the trimmed key stored, got %v and %q", keys, out.String())
+	}
+	if err := executeSet(keys, nil, "codex", pipeInput(t, "\n"), &out); err == nil {
+		t.Error("Expected an empty key to be refused")
+	}
+	if err := executeSet(keys, nil, "aider", pipeInput(t, "sk-456\n"), &out); err == nil || !strings.Contains(err.Error(), "auth.env.aider") {
+		t.Errorf("Expected an agent type without a variable to point at auth.env, got %v", err)
+	}
+
+	cfg := &config.Config{Auth: &config.AuthConfig{Env: map[string]string{"aider": "OPENAI_API_KEY"}}}
+	if err := executeSet(keys, cfg, "aider", pipeInput(t, "sk-456"), &out); err != nil {
+		t.Fatal(err)
+	}
+	out.Reset()
+	if err := executeLs(keys, cfg, &out); err != nil {
+		t.Fatal(err)
+	}
+	for _, want := range []string{"aider   OPENAI_API_KEY     stored", "claude  ANTHROPIC_API_KEY  stored", "codex   OPENAI_API_KEY     -"} {
+		if !strings.Contains(out.String(), want) {
+			t.Errorf("Expected %q in:\n%s", want, out.String())
+		}
+	}
+	if strings.Contains(out.String(), "sk-") {
+		t.Error("Expected ls to never print keys")
+	}
+
+	if err := executeRm(keys, "claude", &out); err != nil {
+		t.Fatal(err)
+	}
+	if _, ok := keys["claude"]; ok {
+		t.Error("Expected the key removed")
+	}
+	if err := executeRm(keys, "claude", &out); err == nil {
+		t.Error("Expected removing a missing key to fail")
+	}
+}
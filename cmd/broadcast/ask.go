@@ -0,0 +1,268 @@
+package broadcast
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"sort"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"github.com/nehpz/claudicus/pkg/cmdctx"
+	"github.com/nehpz/claudicus/pkg/config"
+	"github.com/nehpz/claudicus/pkg/state"
+	"github.com/nehpz/claudicus/pkg/tmuxops"
+
+	"github.com/charmbracelet/log"
+	"github.com/peterbourgon/ff/v3/ffcli"
+)
+
+// Reply statuses of uzi ask --collect
+const (
+	ReplyAnswered = "answered"
+	ReplyPending  = "no answer"
+	ReplyGone     = "gone"
+)
+
+// askScrollback is how many lines of each agent pane are searched for a reply
+const askScrollback = 500
+
+// askPoll is how often --collect reads the panes of agents yet to reply
+var askPoll = 2 * time.Second
+
+// newAskID returns the id that marks the replies to one question; tests
+// replace it
+var newAskID = func() string {
+	b := make([]byte, 4)
+	rand.Read(b)
+	return hex.EncodeToString(b)
+}
+
+// sessionScrollback reads the end of a session's agent pane history for
+// replies; tests replace it
+var sessionScrollback = func(ctx context.Context, sessionName string) (string, error) {
+	output, err := sessionHost(ctx, sessionName).ExecuteCommand("tmux", tmuxops.ScrollbackArgs(sessionName, askScrollback)...)
+	return string(output), err
+}
+
+var (
+	askFs         = flag.NewFlagSet("uzi ask", flag.ExitOnError)
+	collect       = askFs.Bool("collect", false, "wait for each agent's reply and print them together")
+	askTimeout    = askFs.Duration("timeout", 2*time.Minute, "how long --collect waits for replies")
+	askChannel    = askFs.String("channel", "", "ask only sessions subscribed to this channel")
+	askJSON       = askFs.Bool("json", false, "print the collected replies as JSON")
+	askConfigPath = askFs.String("config", config.GetDefaultConfigPath(), "path to config file")
+	CmdAsk        = &ffcli.Command{
+		Name:       "ask",
+		ShortUsage: "uzi ask [--collect] [--timeout 2m] <question>",
+		ShortHelp:  "Ask every agent a question and collect their replies",
+		LongHelp: `Send a question to every active, unpaused agent session, like uzi
+broadcast, asking each agent to put its answer between two marker lines.
+
+With --collect, uzi then reads each agent's pane until every agent has
+replied or --timeout passes, and prints a table of the replies by agent,
+with the most common answer when agents agree. Agents that have not replied
+in time are listed with no answer; their replies still show in their panes.
+
+  uzi ask --collect "Are the integration tests passing on your branch? yes or no"
+  uzi ask --collect --timeout 5m --channel backend "Which files would you change to add rate limiting?"
+
+Flags go before the question. --json prints the replies as JSON.`,
+		FlagSet: askFs,
+		Exec: func(ctx context.Context, args []string) error {
+			return executeAsk(ctx, args, &RealCommandExecutor{}, os.Stdout)
+		},
+	}
+)
+
+// Reply is one agent's answer to uzi ask --collect
+type Reply struct {
+	Agent   string `json:"agent"`
+	Session string `json:"session"`
+	Status  string `json:"status"`
+	Answer  string `json:"answer,omitempty"`
+}
+
+// askMarkers returns the lines an agent puts its reply between
+func askMarkers(id string) (begin, end string) {
+	return "[uzi-answer " + id + "]", "[/uzi-answer " + id + "]"
+}
+
+// askMessage returns the question as sent to agents, with the instructions
+// for marking the reply
+func askMessage(question, id string) string {
+	begin, end := askMarkers(id)
+	return fmt.Sprintf("%s (Reply with your answer between a line with only %s and a line with only %s.)", question, begin, end)
+}
+
+// replyDecoration is what agent CLIs draw before the lines of a reply, such
+// as claude's bullet and box borders
+const replyDecoration = " \t⏺●│"
+
+// parseReply returns the last reply between the markers of id in a pane,
+// and whether there is one. Marker lines must hold only the marker, so the
+// question echoed in the pane is not taken for a reply.
+func parseReply(pane, id string) (string, bool) {
+	begin, end := askMarkers(id)
+	lines := strings.Split(pane, "\n")
+	for i := range lines {
+		lines[i] = strings.TrimRight(strings.TrimLeft(lines[i], replyDecoration), " \t")
+	}
+	for last := len(lines) - 1; last >= 0; last-- {
+		if lines[last] != end {
+			continue
+		}
+		for first := last - 1; first >= 0; first-- {
+			if lines[first] == begin {
+				return strings.TrimSpace(strings.Join(lines[first+1:last], "\n")), true
+			}
+		}
+		return "", false
+	}
+	return "", false
+}
+
+func executeAsk(ctx context.Context, args []string, executor CommandExecutor, out io.Writer) error {
+	if len(args) == 0 {
+		return fmt.Errorf("question argument is required")
+	}
+	question := strings.Join(args, " ")
+	cc := cmdctx.From(ctx)
+	// Without a config file no patterns are forbidden
+	cfg, _ := cc.LoadConfig(*askConfigPath)
+	if err := cfg.CheckBroadcast(question); err != nil {
+		return err
+	}
+
+	activeSessions, err := getActiveSessions(ctx)
+	if err != nil {
+		return err
+	}
+	activeSessions, _ = unpausedSessions(ctx, activeSessions)
+	if *askChannel != "" {
+		activeSessions = channelSessions(ctx, activeSessions, *askChannel)
+	}
+	if len(activeSessions) == 0 {
+		return fmt.Errorf("no active, unpaused agent sessions to ask")
+	}
+	sort.Strings(activeSessions)
+
+	id := newAskID()
+	message := askMessage(question, id)
+	broadcaster := tmuxops.NewRoutedBroadcaster(routeSessions(ctx, executor))
+	replies := make([]Reply, 0, len(activeSessions))
+	var gone []string
+	for _, session := range activeSessions {
+		reply := Reply{Agent: state.AgentNameFromSession(session), Session: session, Status: ReplyPending}
+		err := broadcaster.CheckSession(session)
+		if err == nil {
+			err = broadcaster.Deliver(session, message, tmuxops.Delivery{})
+		}
+		if errors.Is(err, tmuxops.ErrSessionGone) {
+			reply.Status = ReplyGone
+			gone = append(gone, session)
+		} else if err != nil {
+			log.Error("Failed to ask session", "session", session, "error", err)
+			continue
+		}
+		replies = append(replies, reply)
+	}
+	forgetGoneSessions(ctx, gone)
+
+	if !*collect {
+		fmt.Fprintf(out, "Asked %d agents\n", len(replies)-len(gone))
+		return nil
+	}
+	if !cc.WantJSON(*askJSON) {
+		fmt.Fprintf(out, "Asked %d agents; waiting up to %s for replies...\n", len(replies)-len(gone), *askTimeout)
+	}
+	if err := collectReplies(ctx, replies, id, *askTimeout); err != nil {
+		return err
+	}
+
+	if cc.WantJSON(*askJSON) {
+		encoder := json.NewEncoder(out)
+		encoder.SetIndent("", "  ")
+		return encoder.Encode(replies)
+	}
+	printReplies(out, replies)
+	return nil
+}
+
+// collectReplies reads the panes of the agents yet to reply until all have
+// or timeout passes. A reply is only taken once its end marker shows, so one
+// the agent is still writing is not cut short.
+func collectReplies(ctx context.Context, replies []Reply, id string, timeout time.Duration) error {
+	deadline := time.Now().Add(timeout)
+	for {
+		pending := 0
+		for i := range replies {
+			if replies[i].Status != ReplyPending {
+				continue
+			}
+			pane, err := sessionScrollback(ctx, replies[i].Session)
+			if err == nil {
+				if answer, ok := parseReply(pane, id); ok {
+					replies[i].Status, replies[i].Answer = ReplyAnswered, answer
+					continue
+				}
+			}
+			pending++
+		}
+		if pending == 0 || !time.Now().Before(deadline) {
+			return nil
+		}
+		if err := sleep(ctx, askPoll); err != nil {
+			return err
+		}
+	}
+}
+
+// printReplies writes a table of the replies by agent, continuing answers of
+// several lines on the lines below, and the answer most agents gave
+func printReplies(out io.Writer, replies []Reply) {
+	w := tabwriter.NewWriter(out, 0, 0, 2, ' ', 0)
+	fmt.Fprintf(w, "\nAGENT\tSTATUS\tANSWER\n")
+	for _, reply := range replies {
+		lines := strings.Split(reply.Answer, "\n")
+		fmt.Fprintf(w, "%s\t%s\t%s\n", reply.Agent, reply.Status, lines[0])
+		for _, line := range lines[1:] {
+			fmt.Fprintf(w, "\t\t%s\n", line)
+		}
+	}
+	w.Flush()
+
+	if answer, count, answered := consensus(replies); count > 1 {
+		fmt.Fprintf(out, "\nMost common answer (%d of %d replies): %s\n", count, answered, answer)
+	}
+}
+
+// consensus returns the answer given most often, ignoring case, surrounding
+// space, and trailing punctuation, how many agents gave it, and how many
+// replied. Of answers given equally often, the one given first wins.
+func consensus(replies []Reply) (answer string, count, answered int) {
+	counts := make(map[string]int)
+	first := make(map[string]string)
+	for _, reply := range replies {
+		if reply.Status != ReplyAnswered {
+			continue
+		}
+		answered++
+		key := strings.ToLower(strings.TrimRight(strings.TrimSpace(reply.Answer), ".!"))
+		if _, ok := first[key]; !ok {
+			first[key] = reply.Answer
+		}
+		counts[key]++
+		if counts[key] > count {
+			answer, count = first[key], counts[key]
+		}
+	}
+	return answer, count, answered
+}
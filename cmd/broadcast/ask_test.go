@@ -0,0 +1,101 @@
+package broadcast
+
+import (
+	"bytes"
+	"context"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestParseReply(t *testing.T) {
+	question := askMessage("Are the tests passing?", "ab12")
+	tests := []struct {
+		name   string
+		pane   string
+		want   string
+		wantOK bool
+	}{
+		{name: "question only", pane: "> " + question + "\n", wantOK: false},
+		{name: "unfinished reply", pane: "> " + question + "\n⏺ [uzi-answer ab12]\n  Yes, all 42\n", wantOK: false},
+		{
+			name:   "reply",
+			pane:   "> " + question + "\n\n⏺ [uzi-answer ab12]\n  Yes, all 42 pass.\n  Ran go test ./...\n  [/uzi-answer ab12]\n\n> ",
+			want:   "Yes, all 42 pass.\nRan go test ./...",
+			wantOK: true,
+		},
+		{name: "other question", pane: "[uzi-answer ff00]\nno\n[/uzi-answer ff00]\n", wantOK: false},
+		{
+			name:   "latest reply",
+			pane:   "[uzi-answer ab12]\nno\n[/uzi-answer ab12]\n[uzi-answer ab12]\nyes\n[/uzi-answer ab12]\n",
+			want:   "yes",
+			wantOK: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, ok := parseReply(tt.pane, "ab12")
+			if got != tt.want || ok != tt.wantOK {
+				t.Errorf("parseReply() = %q, %v, want %q, %v", got, ok, tt.want, tt.wantOK)
+			}
+		})
+	}
+}
+
+func TestConsensus(t *testing.T) {
+	replies := []Reply{
+		{Agent: "john", Status: ReplyAnswered, Answer: "Yes."},
+		{Agent: "sarah", Status: ReplyAnswered, Answer: "no"},
+		{Agent: "emily", Status: ReplyAnswered, Answer: "yes"},
+		{Agent: "tom", Status: ReplyPending},
+	}
+	if answer, count, answered := consensus(replies); answer != "Yes." || count != 2 || answered != 3 {
+		t.Errorf("consensus() = %q, %d, %d, want \"Yes.\", 2, 3", answer, count, answered)
+	}
+}
+
+func TestExecuteAskCollect(t *testing.T) {
+	// Arrange
+	withActiveSessions(t, []string{"agent-repo-abc123-sarah", "agent-repo-abc123-john"}, nil)
+	originalID, originalPoll, originalScrollback := newAskID, askPoll, sessionScrollback
+	newAskID, askPoll = func() string { return "ab12" }, time.Millisecond
+	*collect, *askTimeout = true, 50*time.Millisecond
+	t.Cleanup(func() {
+		newAskID, askPoll, sessionScrollback = originalID, originalPoll, originalScrollback
+		*collect, *askTimeout = false, 2*time.Minute
+	})
+	// john replies on the second read, sarah never does
+	reads := 0
+	sessionScrollback = func(ctx context.Context, sessionName string) (string, error) {
+		if sessionName != "agent-repo-abc123-john" {
+			return "", nil
+		}
+		reads++
+		if reads < 2 {
+			return "> thinking", nil
+		}
+		return "⏺ [uzi-answer ab12]\n  yes\n  [/uzi-answer ab12]\n", nil
+	}
+	executor := &MockCommandExecutor{}
+	var out bytes.Buffer
+
+	// Act
+	err := executeAsk(context.Background(), []string{"Passing?"}, executor, &out)
+
+	// Assert
+	if err != nil {
+		t.Fatalf("executeAsk() error = %v", err)
+	}
+	if len(executor.commands) != 4 || !strings.Contains(strings.Join(executor.commands[0], " "), "[uzi-answer ab12]") {
+		t.Errorf("Expected the question with reply markers sent to both agents, got %v", executor.commands)
+	}
+	if reads != 2 {
+		t.Errorf("Expected john's pane read until he replied, got %d reads", reads)
+	}
+	output := out.String()
+	for _, want := range []string{"john   answered   yes", "sarah  no answer"} {
+		if !strings.Contains(output, want) {
+			t.Errorf("Expected %q in the replies, got:\n%s", want, output)
+		}
+	}
+}
@@ -2,38 +2,176 @@ package broadcast
 
 import (
 	"context"
+	"errors"
 	"flag"
 	"fmt"
-	"os/exec"
+	"sort"
 	"strings"
+	"time"
 
+	"github.com/nehpz/claudicus/pkg/cmdctx"
+	"github.com/nehpz/claudicus/pkg/config"
+	"github.com/nehpz/claudicus/pkg/hosts"
 	"github.com/nehpz/claudicus/pkg/state"
+	"github.com/nehpz/claudicus/pkg/tmuxops"
 
 	"github.com/charmbracelet/log"
 	"github.com/peterbourgon/ff/v3/ffcli"
 )
 
 // CommandExecutor abstracts the execution of external commands
-type CommandExecutor interface {
-	Execute(command string, args ...string) error
-}
+type CommandExecutor = tmuxops.CommandExecutor
 
 // RealCommandExecutor implements CommandExecutor using exec.Command
-type RealCommandExecutor struct{}
+type RealCommandExecutor = tmuxops.RealCommandExecutor
+
+// getActiveSessions lists the sessions to broadcast to; tests replace it to
+// exercise the send path without real state
+var getActiveSessions = func(ctx context.Context) ([]string, error) {
+	sm, err := cmdctx.From(ctx).State()
+	if err != nil {
+		return nil, err
+	}
+	return sm.GetActiveSessionsForRepo()
+}
+
+// sessionState looks up a session in the state store
+func sessionState(ctx context.Context, sessionName string) (*state.AgentState, error) {
+	sm, err := cmdctx.From(ctx).State()
+	if err != nil {
+		return nil, err
+	}
+	return sm.GetWorktreeInfo(sessionName)
+}
 
-// Execute runs the command using exec.Command
-func (r *RealCommandExecutor) Execute(command string, args ...string) error {
-	cmd := exec.Command(command, args...)
-	return cmd.Run()
+// sessionHost looks up the machine a session runs on; tests replace it
+var sessionHost = func(ctx context.Context, sessionName string) hosts.Target {
+	info, err := sessionState(ctx, sessionName)
+	if err != nil {
+		return hosts.Local()
+	}
+	return hosts.ForState(*info)
+}
+
+// sessionBranch looks up the branch a session works on; tests replace it
+var sessionBranch = func(ctx context.Context, sessionName string) string {
+	info, err := sessionState(ctx, sessionName)
+	if err != nil {
+		return ""
+	}
+	return info.BranchName
+}
+
+// sessionInChannel reports whether a session subscribes to a broadcast
+// channel; tests replace it
+var sessionInChannel = func(ctx context.Context, sessionName, channel string) bool {
+	info, err := sessionState(ctx, sessionName)
+	if err != nil {
+		return false
+	}
+	return info.InChannel(channel)
+}
+
+// sessionPaused reports whether a session was stopped by `uzi pause`; tests
+// replace it
+var sessionPaused = func(ctx context.Context, sessionName string) bool {
+	info, err := sessionState(ctx, sessionName)
+	if err != nil {
+		return false
+	}
+	return info.Paused
+}
+
+// sessionPane captures the agent pane of a session for --serial; tests
+// replace it
+var sessionPane = func(ctx context.Context, sessionName string) (string, error) {
+	return sessionHost(ctx, sessionName).PaneContent(sessionName)
+}
+
+// forgetSession removes a session whose tmux session is gone from state;
+// tests replace it
+var forgetSession = func(ctx context.Context, sessionName string) error {
+	sm, err := cmdctx.From(ctx).State()
+	if err != nil {
+		return err
+	}
+	return sm.RemoveState(sessionName)
+}
+
+// serialPoll is how often --serial reads the pane of the agent it waits for,
+// and serialPickup how long the agent may take to start working on the
+// message before it is taken to have handled it at once
+var (
+	serialPoll   = time.Second
+	serialPickup = 5 * time.Second
+)
+
+// routeSessions sends tmux commands for remote sessions over ssh and runs
+// the rest with the local executor
+func routeSessions(ctx context.Context, executor CommandExecutor) func(sessionName string) CommandExecutor {
+	return func(sessionName string) CommandExecutor {
+		if target := sessionHost(ctx, sessionName); !target.IsLocal() {
+			return target
+		}
+		return executor
+	}
 }
 
 var (
 	fs           = flag.NewFlagSet("uzi broadcast", flag.ExitOnError)
+	templateName = fs.String("template", "", "send the named message from the broadcasts: section of uzi.yaml")
+	channelName  = fs.String("channel", "", "send only to sessions subscribed to this channel")
+	configPath   = fs.String("config", config.GetDefaultConfigPath(), "path to config file")
+	noEnter      = fs.Bool("no-enter", false, "type the message into each agent's input without submitting it")
+	literal      = fs.Bool("literal", false, "type key names such as Enter or C-c in the message as text")
+	keys         = fs.String("keys", "", `press tmux keys instead of sending a message, e.g. "C-c" or "Escape C-u"`)
+	delay        = fs.Duration("delay", 0, "pause between sessions (default broadcastDelivery.delay in uzi.yaml)")
+	ack          = fs.Bool("ack", false, "submit each message only once it shows in the agent's pane")
+	serial       = fs.Bool("serial", false, "wait for each agent to return to ready before messaging the next")
 	CmdBroadcast = &ffcli.Command{
 		Name:       "broadcast",
 		ShortUsage: "uzi broadcast <message>",
 		ShortHelp:  "Send a message to all active agent sessions",
-		FlagSet:    fs,
+		LongHelp: `
+The broadcast command types a message into every active agent session. With
+--template NAME the message is taken from the broadcasts: section of uzi.yaml
+instead of the arguments.
+
+{agent} and {branch} in the message are replaced with each session's agent
+name and branch, so one broadcast can address every agent by name.
+
+With --channel NAME the message only goes to sessions subscribed to that
+channel, with uzi prompt --channel or through the channels: section of
+uzi.yaml, which subscribes sessions by tag.
+
+Every message is submitted with Enter unless --no-enter is given, which leaves
+it in each agent's input to be edited or submitted later. tmux presses a
+message that is a key name, such as "Enter", instead of typing it; --literal
+always types the text.
+
+--keys presses tmux keys in every session instead of sending a message, such
+as --keys C-c to interrupt the whole fleet or --keys "Escape C-u" to clear
+their input. Keys are separated by spaces and nothing is submitted.
+
+Sessions paused with uzi pause are skipped until uzi resume.
+
+Each session is checked right before it is messaged, so one killed or
+detached while the broadcast is under way is skipped and reported rather than
+failing the rest. Local sessions found gone are removed from uzi's state.
+
+A message matching one of policy.forbidBroadcastPatterns in uzi.yaml, such as
+"rm -rf", is refused before it reaches any agent.
+
+Sessions are messaged one at a time in name order. --delay pauses between
+them, so a burst of send-keys does not interleave with busy agents. With
+--ack each message is typed, then submitted only once it shows in the agent's
+pane; a message that does not show within broadcastDelivery.ackTimeout (5s by
+default) is left unsubmitted and reported. --serial waits for each agent to
+pick the message up and return to ready before messaging the next, for up to
+broadcastDelivery.serialTimeout (10m by default). The broadcastDelivery:
+section of uzi.yaml sets the defaults of all three.
+`,
+		FlagSet: fs,
 		Exec: func(ctx context.Context, args []string) error {
 			executor := &RealCommandExecutor{}
 			return executeBroadcast(ctx, args, executor)
@@ -42,21 +180,34 @@ var (
 )
 
 func executeBroadcast(ctx context.Context, args []string, executor CommandExecutor) error {
-	if len(args) == 0 {
-		return fmt.Errorf("message argument is required")
-	}
-
-	message := strings.Join(args, " ")
-	log.Debug("Broadcasting message", "message", message)
-
-	// Get state manager to read from config
-	sm := state.NewStateManager()
-	if sm == nil {
-		return fmt.Errorf("could not initialize state manager")
+	var message string
+	keyNames := strings.Fields(*keys)
+	if *keys != "" {
+		if len(keyNames) == 0 {
+			return fmt.Errorf("--keys needs at least one key")
+		}
+		if len(args) > 0 || *templateName != "" {
+			return fmt.Errorf("give either a message or --keys, not both")
+		}
+		if *noEnter || *literal || *ack || *serial {
+			return fmt.Errorf("--no-enter, --literal, --ack, and --serial apply to messages, not --keys")
+		}
+		log.Debug("Broadcasting keys", "keys", keyNames)
+	} else {
+		var err error
+		if message, err = broadcastMessage(ctx, args, *templateName, *configPath); err != nil {
+			return err
+		}
+		// Without a config file no patterns are forbidden
+		cfg, _ := cmdctx.From(ctx).LoadConfig(*configPath)
+		if err := cfg.CheckBroadcast(message); err != nil {
+			return err
+		}
+		log.Debug("Broadcasting message", "message", message)
 	}
 
 	// Get active sessions from state
-	activeSessions, err := sm.GetActiveSessionsForRepo()
+	activeSessions, err := getActiveSessions(ctx)
 	if err != nil {
 		log.Error("Error getting active sessions", "error", err)
 		return err
@@ -65,20 +216,198 @@ func executeBroadcast(ctx context.Context, args []string, executor CommandExecut
 	if len(activeSessions) == 0 {
 		return fmt.Errorf("no active agent sessions found")
 	}
+	activeSessions, paused := unpausedSessions(ctx, activeSessions)
+	if len(activeSessions) == 0 {
+		return fmt.Errorf("all %d active agent sessions are paused; resume them with uzi resume", paused)
+	}
+	if paused > 0 {
+		fmt.Printf("Skipping %d paused agent sessions\n", paused)
+	}
+	if *channelName != "" {
+		activeSessions = channelSessions(ctx, activeSessions, *channelName)
+		if len(activeSessions) == 0 {
+			return fmt.Errorf("no active agent sessions subscribed to channel %q", *channelName)
+		}
+	}
+
+	if len(keyNames) > 0 {
+		fmt.Printf("Sending keys %s to %d agent sessions:\n", strings.Join(keyNames, " "), len(activeSessions))
+	} else {
+		fmt.Printf("Broadcasting message to %d agent sessions:\n", len(activeSessions))
+	}
 
-	fmt.Printf("Broadcasting message to %d agent sessions:\n", len(activeSessions))
+	cfg, _ := cmdctx.From(ctx).LoadConfig(*configPath)
+	pause := cfg.BroadcastDelay()
+	if flagSet(fs, "delay") {
+		pause = *delay
+	}
+	delivery := tmuxops.Delivery{Literal: *literal, NoEnter: *noEnter}
+	if *ack || cfg.BroadcastAck() {
+		delivery.AckTimeout = cfg.BroadcastAckTimeout()
+	}
+	waitSerial := *serial || cfg.BroadcastSerial()
 
-	// Send message to each session
-	for _, session := range activeSessions {
+	// Send message to each session, in order
+	sort.Strings(activeSessions)
+	broadcaster := tmuxops.NewRoutedBroadcaster(routeSessions(ctx, executor))
+	var gone []string
+	for i, session := range activeSessions {
+		if i > 0 && pause > 0 {
+			if err := sleep(ctx, pause); err != nil {
+				return err
+			}
+		}
 		fmt.Printf("\n=== %s ===\n", session)
 
-		// Send the message to the agent window
-		if err := executor.Execute("tmux", "send-keys", "-t", session+":agent", message, "Enter"); err != nil {
+		// The session may have died since the sessions were listed
+		if err := broadcaster.CheckSession(session); err != nil {
+			fmt.Println("Skipped: tmux session is gone")
+			gone = append(gone, session)
+			continue
+		}
+		if len(keyNames) > 0 {
+			if err := broadcaster.SendKeys(session, keyNames...); errors.Is(err, tmuxops.ErrSessionGone) {
+				fmt.Println("Skipped: tmux session is gone")
+				gone = append(gone, session)
+			} else if err != nil {
+				log.Error("Failed to send keys to session", "session", session, "error", err)
+			}
+			continue
+		}
+		expanded := config.ExpandBroadcast(message, state.AgentNameFromSession(session), sessionBranch(ctx, session))
+		if err := broadcaster.Deliver(session, expanded, delivery); errors.Is(err, tmuxops.ErrSessionGone) {
+			fmt.Println("Skipped: tmux session is gone")
+			gone = append(gone, session)
+			continue
+		} else if err != nil {
 			log.Error("Failed to send message to session", "session", session, "error", err)
 			continue
 		}
-		executor.Execute("tmux", "send-keys", "-t", session+":agent", "Enter")
+		if waitSerial && !*noEnter && i < len(activeSessions)-1 {
+			fmt.Printf("Waiting for %s to return to ready...\n", state.AgentNameFromSession(session))
+			if err := waitReady(ctx, session, cfg.BroadcastSerialTimeout()); err != nil {
+				if ctx.Err() != nil {
+					return ctx.Err()
+				}
+				log.Warn("Moving on without waiting", "session", session, "error", err)
+			}
+		}
 	}
 
+	forgetGoneSessions(ctx, gone)
 	return nil
 }
+
+// forgetGoneSessions reports the sessions a broadcast skipped because they
+// were gone and removes the local ones from state. A remote session is kept,
+// since its host may only be unreachable for now.
+func forgetGoneSessions(ctx context.Context, gone []string) {
+	if len(gone) == 0 {
+		return
+	}
+	var removed []string
+	for _, session := range gone {
+		if !sessionHost(ctx, session).IsLocal() {
+			continue
+		}
+		if err := forgetSession(ctx, session); err != nil {
+			log.Warn("Failed to remove gone session from state", "session", session, "error", err)
+			continue
+		}
+		removed = append(removed, state.AgentNameFromSession(session))
+	}
+	fmt.Printf("\nSkipped %d agent sessions whose tmux session is gone\n", len(gone))
+	if len(removed) > 0 {
+		fmt.Printf("Removed from state: %s\n", strings.Join(removed, ", "))
+	}
+}
+
+// waitReady waits until the agent of a session has picked up the message it
+// was sent and is ready for input again. An agent that does not start working
+// within serialPickup is taken to have handled the message at once.
+func waitReady(ctx context.Context, sessionName string, timeout time.Duration) error {
+	start := time.Now()
+	pickedUp := false
+	for {
+		pane, err := sessionPane(ctx, sessionName)
+		if err != nil {
+			return fmt.Errorf("failed to read the pane of %s: %w", sessionName, err)
+		}
+		switch state.AgentStatusFromPane(pane) {
+		case state.StatusRunning, state.StatusStarting:
+			pickedUp = true
+		default:
+			if pickedUp || time.Since(start) >= serialPickup {
+				return nil
+			}
+		}
+		if time.Since(start) >= timeout {
+			return fmt.Errorf("%s is still working after %s", state.AgentNameFromSession(sessionName), timeout)
+		}
+		if err := sleep(ctx, serialPoll); err != nil {
+			return err
+		}
+	}
+}
+
+// sleep waits for d or until ctx is done
+func sleep(ctx context.Context, d time.Duration) error {
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-time.After(d):
+		return nil
+	}
+}
+
+// flagSet reports whether the named flag was passed on the command line
+func flagSet(fs *flag.FlagSet, name string) bool {
+	set := false
+	fs.Visit(func(f *flag.Flag) {
+		if f.Name == name {
+			set = true
+		}
+	})
+	return set
+}
+
+// channelSessions returns the sessions subscribed to channel
+func channelSessions(ctx context.Context, sessions []string, channel string) []string {
+	var subscribed []string
+	for _, session := range sessions {
+		if sessionInChannel(ctx, session, channel) {
+			subscribed = append(subscribed, session)
+		}
+	}
+	return subscribed
+}
+
+// unpausedSessions returns the sessions that are not paused and how many were
+func unpausedSessions(ctx context.Context, sessions []string) ([]string, int) {
+	var unpaused []string
+	for _, session := range sessions {
+		if !sessionPaused(ctx, session) {
+			unpaused = append(unpaused, session)
+		}
+	}
+	return unpaused, len(sessions) - len(unpaused)
+}
+
+// broadcastMessage returns the message given as arguments or, with --template,
+// the named template from the config file
+func broadcastMessage(ctx context.Context, args []string, template, path string) (string, error) {
+	if template == "" {
+		if len(args) == 0 {
+			return "", fmt.Errorf("message argument is required")
+		}
+		return strings.Join(args, " "), nil
+	}
+	if len(args) > 0 {
+		return "", fmt.Errorf("give either a message or --template, not both")
+	}
+	cfg, err := cmdctx.From(ctx).LoadConfig(path)
+	if err != nil {
+		return "", fmt.Errorf("failed to load broadcast templates from %s: %w", path, err)
+	}
+	return cfg.BroadcastTemplate(template)
+}
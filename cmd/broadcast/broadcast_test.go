@@ -2,19 +2,38 @@ package broadcast
 
 import (
 	"context"
+	"errors"
 	"fmt"
+	"os"
+	"path/filepath"
+	"reflect"
 	"strings"
 	"testing"
+	"time"
+
+	"github.com/nehpz/claudicus/pkg/config"
+	"github.com/nehpz/claudicus/pkg/hosts"
 )
 
 // MockCommandExecutor implements CommandExecutor for testing
 type MockCommandExecutor struct {
 	shouldFail bool
 	commands   [][]string
+	checked    []string        // sessions checked with has-session, kept out of commands
+	gone       map[string]bool // sessions has-session reports missing
 }
 
-// Execute records the command and returns an error if shouldFail is true
+// Execute records the command and returns an error if shouldFail is true.
+// Session checks are recorded apart and fail only for gone sessions.
 func (m *MockCommandExecutor) Execute(command string, args ...string) error {
+	if len(args) == 3 && args[0] == "has-session" {
+		session := strings.TrimPrefix(args[2], "=")
+		m.checked = append(m.checked, session)
+		if m.gone[session] {
+			return fmt.Errorf("can't find session: %s", session)
+		}
+		return nil
+	}
 	fullCmd := append([]string{command}, args...)
 	m.commands = append(m.commands, fullCmd)
 	if m.shouldFail {
@@ -2169,3 +2188,536 @@ func TestRepetitiveExecution(t *testing.T) {
 		})
 	}
 }
+
+// withActiveSessions replaces the session lookup for the duration of a test
+func withActiveSessions(t *testing.T, sessions []string, err error) {
+	t.Helper()
+	original := getActiveSessions
+	getActiveSessions = func(context.Context) ([]string, error) { return sessions, err }
+	t.Cleanup(func() { getActiveSessions = original })
+}
+
+// TestExecuteBroadcastTmuxArguments asserts the exact tmux argument vectors sent to each session
+func TestExecuteBroadcastTmuxArguments(t *testing.T) {
+	// Arrange
+	withActiveSessions(t, []string{"agent-repo-abc123-john", "agent-repo-abc123-sarah"}, nil)
+	executor := &MockCommandExecutor{}
+
+	// Act
+	err := executeBroadcast(context.Background(), []string{"run", "the", "tests"}, executor)
+
+	// Assert
+	if err != nil {
+		t.Fatalf("executeBroadcast() error = %v", err)
+	}
+	want := [][]string{
+		{"tmux", "send-keys", "-t", "agent-repo-abc123-john:{start}", "run the tests", "Enter"},
+		{"tmux", "send-keys", "-t", "agent-repo-abc123-john:{start}", "Enter"},
+		{"tmux", "send-keys", "-t", "agent-repo-abc123-sarah:{start}", "run the tests", "Enter"},
+		{"tmux", "send-keys", "-t", "agent-repo-abc123-sarah:{start}", "Enter"},
+	}
+	if !reflect.DeepEqual(executor.commands, want) {
+		t.Errorf("executeBroadcast() commands = %v, want %v", executor.commands, want)
+	}
+	if checked := []string{"agent-repo-abc123-john", "agent-repo-abc123-sarah"}; !reflect.DeepEqual(executor.checked, checked) {
+		t.Errorf("Expected each session checked before its send, got %v", executor.checked)
+	}
+}
+
+// TestExecuteBroadcastSkipsGoneSessions verifies sessions that died after
+// being listed are skipped, reported, and removed from state when local
+func TestExecuteBroadcastSkipsGoneSessions(t *testing.T) {
+	// Arrange
+	withActiveSessions(t, []string{"agent-repo-abc123-john", "agent-repo-abc123-sarah"}, nil)
+	executor := &MockCommandExecutor{gone: map[string]bool{"agent-repo-abc123-john": true}}
+	forgotten := withForgetSession(t)
+
+	// Act
+	err := executeBroadcast(context.Background(), []string{"hello"}, executor)
+
+	// Assert
+	if err != nil {
+		t.Fatalf("executeBroadcast() error = %v", err)
+	}
+	want := [][]string{
+		{"tmux", "send-keys", "-t", "agent-repo-abc123-sarah:{start}", "hello", "Enter"},
+		{"tmux", "send-keys", "-t", "agent-repo-abc123-sarah:{start}", "Enter"},
+	}
+	if !reflect.DeepEqual(executor.commands, want) {
+		t.Errorf("Expected only sarah messaged, got %v", executor.commands)
+	}
+	if !reflect.DeepEqual(*forgotten, []string{"agent-repo-abc123-john"}) {
+		t.Errorf("Expected john removed from state, got %v", *forgotten)
+	}
+}
+
+// TestForgetGoneSessionsKeepsRemote verifies a gone remote session stays in
+// state, since its host may only be unreachable
+func TestForgetGoneSessionsKeepsRemote(t *testing.T) {
+	// Arrange
+	original := sessionHost
+	sessionHost = func(ctx context.Context, sessionName string) hosts.Target {
+		if sessionName == "agent-repo-abc123-remote" {
+			return hosts.Target{Name: "box", SSH: "dev@box"}
+		}
+		return hosts.Local()
+	}
+	t.Cleanup(func() { sessionHost = original })
+	forgotten := withForgetSession(t)
+
+	// Act
+	forgetGoneSessions(context.Background(), []string{"agent-repo-abc123-remote", "agent-repo-abc123-sarah"})
+
+	// Assert
+	if !reflect.DeepEqual(*forgotten, []string{"agent-repo-abc123-sarah"}) {
+		t.Errorf("Expected only the local session removed, got %v", *forgotten)
+	}
+}
+
+// withForgetSession records the sessions removed from state for the duration
+// of a test
+func withForgetSession(t *testing.T) *[]string {
+	t.Helper()
+	var forgotten []string
+	original := forgetSession
+	forgetSession = func(ctx context.Context, sessionName string) error {
+		forgotten = append(forgotten, sessionName)
+		return nil
+	}
+	t.Cleanup(func() { forgetSession = original })
+	return &forgotten
+}
+
+// TestExecuteBroadcastSpecialCharactersUnquoted verifies messages reach tmux as a single raw argument
+func TestExecuteBroadcastSpecialCharactersUnquoted(t *testing.T) {
+	// Arrange
+	withActiveSessions(t, []string{"agent-repo-abc123-sarah"}, nil)
+	executor := &MockCommandExecutor{}
+
+	// Act
+	err := executeBroadcast(context.Background(), []string{`don't`, `"panic"`, "$HOME", "世界"}, executor)
+
+	// Assert
+	if err != nil {
+		t.Fatalf("executeBroadcast() error = %v", err)
+	}
+	if got := executor.commands[0][4]; got != `don't "panic" $HOME 世界` {
+		t.Errorf("Expected raw message argument, got %q", got)
+	}
+}
+
+// TestExecuteBroadcastSendFailure verifies a failed send skips the follow-up Enter but not other sessions
+func TestExecuteBroadcastSendFailure(t *testing.T) {
+	// Arrange
+	withActiveSessions(t, []string{"agent-repo-abc123-john", "agent-repo-abc123-sarah"}, nil)
+	executor := &MockCommandExecutor{shouldFail: true}
+
+	// Act
+	err := executeBroadcast(context.Background(), []string{"hello"}, executor)
+
+	// Assert
+	if err != nil {
+		t.Errorf("executeBroadcast() should not fail when individual sends fail, got %v", err)
+	}
+	want := [][]string{
+		{"tmux", "send-keys", "-t", "agent-repo-abc123-john:{start}", "hello", "Enter"},
+		{"tmux", "send-keys", "-t", "agent-repo-abc123-sarah:{start}", "hello", "Enter"},
+	}
+	if !reflect.DeepEqual(executor.commands, want) {
+		t.Errorf("executeBroadcast() commands = %v, want %v", executor.commands, want)
+	}
+}
+
+// TestExecuteBroadcastSessionLookupError verifies state errors are returned before any tmux call
+func TestExecuteBroadcastSessionLookupError(t *testing.T) {
+	// Arrange
+	withActiveSessions(t, nil, fmt.Errorf("state file corrupt"))
+	executor := &MockCommandExecutor{}
+
+	// Act
+	err := executeBroadcast(context.Background(), []string{"hello"}, executor)
+
+	// Assert
+	if err == nil || !strings.Contains(err.Error(), "state file corrupt") {
+		t.Errorf("Expected state error, got %v", err)
+	}
+	if len(executor.commands) != 0 {
+		t.Errorf("Expected no tmux commands, got %v", executor.commands)
+	}
+}
+
+// TestRouteSessions verifies remote sessions are sent over ssh to their host
+func TestRouteSessions(t *testing.T) {
+	// Arrange
+	original := sessionHost
+	sessionHost = func(ctx context.Context, sessionName string) hosts.Target {
+		if sessionName == "agent-repo-abc123-remote" {
+			return hosts.Target{Name: "box", SSH: "dev@box"}
+		}
+		return hosts.Local()
+	}
+	t.Cleanup(func() { sessionHost = original })
+	executor := &MockCommandExecutor{}
+
+	// Act
+	route := routeSessions(context.Background(), executor)
+
+	// Assert
+	if got := route("agent-repo-abc123-sarah"); got != executor {
+		t.Errorf("Expected local sessions on the local executor, got %#v", got)
+	}
+	if got, ok := route("agent-repo-abc123-remote").(hosts.Target); !ok || got.SSH != "dev@box" {
+		t.Errorf("Expected remote sessions on their host, got %#v", got)
+	}
+}
+
+// TestExecuteBroadcastTemplate sends a template from uzi.yaml with each
+// session's agent and branch filled in
+func TestExecuteBroadcastTemplate(t *testing.T) {
+	// Arrange
+	path := filepath.Join(t.TempDir(), "uzi.yaml")
+	if err := os.WriteFile(path, []byte("broadcasts:\n  status: \"{agent}, summarize your progress on {branch}\"\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	withActiveSessions(t, []string{"agent-repo-abc123-john", "agent-repo-abc123-sarah"}, nil)
+	original := sessionBranch
+	sessionBranch = func(ctx context.Context, sessionName string) string {
+		return "branch-of-" + sessionName[len("agent-repo-abc123-"):]
+	}
+	t.Cleanup(func() { sessionBranch = original })
+	*templateName, *configPath = "status", path
+	t.Cleanup(func() { *templateName, *configPath = "", config.GetDefaultConfigPath() })
+	executor := &MockCommandExecutor{}
+
+	// Act
+	err := executeBroadcast(context.Background(), nil, executor)
+
+	// Assert
+	if err != nil {
+		t.Fatalf("executeBroadcast() error = %v", err)
+	}
+	want := [][]string{
+		{"tmux", "send-keys", "-t", "agent-repo-abc123-john:{start}", "john, summarize your progress on branch-of-john", "Enter"},
+		{"tmux", "send-keys", "-t", "agent-repo-abc123-john:{start}", "Enter"},
+		{"tmux", "send-keys", "-t", "agent-repo-abc123-sarah:{start}", "sarah, summarize your progress on branch-of-sarah", "Enter"},
+		{"tmux", "send-keys", "-t", "agent-repo-abc123-sarah:{start}", "Enter"},
+	}
+	if !reflect.DeepEqual(executor.commands, want) {
+		t.Errorf("Expected commands %v, got %v", want, executor.commands)
+	}
+}
+
+// TestBroadcastMessage covers choosing between the arguments and a template
+func TestBroadcastMessage(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "uzi.yaml")
+	if err := os.WriteFile(path, []byte("broadcasts:\n  status: Summarize your progress\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if got, err := broadcastMessage(context.Background(), []string{"hi", "all"}, "", path); err != nil || got != "hi all" {
+		t.Errorf("broadcastMessage(args) = %q, %v", got, err)
+	}
+	if got, err := broadcastMessage(context.Background(), nil, "status", path); err != nil || got != "Summarize your progress" {
+		t.Errorf("broadcastMessage(template) = %q, %v", got, err)
+	}
+	for name, args := range map[string][]string{"missing": nil, "status": {"extra"}} {
+		if _, err := broadcastMessage(context.Background(), args, name, path); err == nil {
+			t.Errorf("Expected an error for template %q with args %v", name, args)
+		}
+	}
+	if _, err := broadcastMessage(context.Background(), nil, "", path); err == nil || err.Error() != "message argument is required" {
+		t.Errorf("Expected the missing message error, got %v", err)
+	}
+}
+
+// TestExecuteBroadcastChannel checks that --channel only reaches subscribers
+func TestExecuteBroadcastChannel(t *testing.T) {
+	// Arrange
+	withActiveSessions(t, []string{"agent-repo-abc123-john", "agent-repo-abc123-sarah"}, nil)
+	original := sessionInChannel
+	sessionInChannel = func(ctx context.Context, sessionName, channel string) bool {
+		return sessionName == "agent-repo-abc123-sarah" && channel == "frontend"
+	}
+	t.Cleanup(func() { sessionInChannel = original })
+	*channelName = "frontend"
+	t.Cleanup(func() { *channelName = "" })
+	executor := &MockCommandExecutor{}
+
+	// Act
+	err := executeBroadcast(context.Background(), []string{"rebuild"}, executor)
+
+	// Assert
+	if err != nil {
+		t.Fatalf("executeBroadcast() error = %v", err)
+	}
+	want := [][]string{
+		{"tmux", "send-keys", "-t", "agent-repo-abc123-sarah:{start}", "rebuild", "Enter"},
+		{"tmux", "send-keys", "-t", "agent-repo-abc123-sarah:{start}", "Enter"},
+	}
+	if !reflect.DeepEqual(executor.commands, want) {
+		t.Errorf("Expected commands %v, got %v", want, executor.commands)
+	}
+
+	*channelName = "backend"
+	if err := executeBroadcast(context.Background(), []string{"rebuild"}, executor); err == nil || !strings.Contains(err.Error(), `subscribed to channel "backend"`) {
+		t.Errorf("Expected an error for a channel without subscribers, got %v", err)
+	}
+}
+
+// TestExecuteBroadcastSkipsPaused checks that paused sessions get no message
+func TestExecuteBroadcastSkipsPaused(t *testing.T) {
+	// Arrange
+	withActiveSessions(t, []string{"agent-repo-abc123-john", "agent-repo-abc123-sarah"}, nil)
+	paused := map[string]bool{"agent-repo-abc123-john": true}
+	original := sessionPaused
+	sessionPaused = func(ctx context.Context, sessionName string) bool { return paused[sessionName] }
+	t.Cleanup(func() { sessionPaused = original })
+	executor := &MockCommandExecutor{}
+
+	// Act
+	err := executeBroadcast(context.Background(), []string{"rebuild"}, executor)
+
+	// Assert
+	if err != nil {
+		t.Fatalf("executeBroadcast() error = %v", err)
+	}
+	want := [][]string{
+		{"tmux", "send-keys", "-t", "agent-repo-abc123-sarah:{start}", "rebuild", "Enter"},
+		{"tmux", "send-keys", "-t", "agent-repo-abc123-sarah:{start}", "Enter"},
+	}
+	if !reflect.DeepEqual(executor.commands, want) {
+		t.Errorf("Expected commands %v, got %v", want, executor.commands)
+	}
+
+	paused["agent-repo-abc123-sarah"] = true
+	if err := executeBroadcast(context.Background(), []string{"rebuild"}, executor); err == nil || !strings.Contains(err.Error(), "are paused") {
+		t.Errorf("Expected an error when every session is paused, got %v", err)
+	}
+}
+
+// TestExecuteBroadcastKeys checks that --keys presses keys without a message or Enter
+func TestExecuteBroadcastKeys(t *testing.T) {
+	// Arrange
+	withActiveSessions(t, []string{"agent-repo-abc123-john", "agent-repo-abc123-sarah"}, nil)
+	*keys = "Escape  C-c"
+	t.Cleanup(func() { *keys = "" })
+	executor := &MockCommandExecutor{}
+
+	// Act
+	err := executeBroadcast(context.Background(), nil, executor)
+
+	// Assert
+	if err != nil {
+		t.Fatalf("executeBroadcast() error = %v", err)
+	}
+	want := [][]string{
+		{"tmux", "send-keys", "-t", "agent-repo-abc123-john:{start}", "Escape", "C-c"},
+		{"tmux", "send-keys", "-t", "agent-repo-abc123-sarah:{start}", "Escape", "C-c"},
+	}
+	if !reflect.DeepEqual(executor.commands, want) {
+		t.Errorf("Expected commands %v, got %v", want, executor.commands)
+	}
+
+	if err := executeBroadcast(context.Background(), []string{"hello"}, executor); err == nil || !strings.Contains(err.Error(), "not both") {
+		t.Errorf("Expected an error for a message with --keys, got %v", err)
+	}
+	*noEnter = true
+	t.Cleanup(func() { *noEnter = false })
+	if err := executeBroadcast(context.Background(), nil, executor); err == nil || !strings.Contains(err.Error(), "--no-enter") {
+		t.Errorf("Expected an error for --no-enter with --keys, got %v", err)
+	}
+	*keys = " "
+	if err := executeBroadcast(context.Background(), nil, executor); err == nil || !strings.Contains(err.Error(), "at least one key") {
+		t.Errorf("Expected an error for --keys without keys, got %v", err)
+	}
+}
+
+// TestExecuteBroadcastNoEnterLiteral checks that --no-enter and --literal pre-fill the input as typed
+func TestExecuteBroadcastNoEnterLiteral(t *testing.T) {
+	// Arrange
+	withActiveSessions(t, []string{"agent-repo-abc123-sarah"}, nil)
+	*noEnter, *literal = true, true
+	t.Cleanup(func() { *noEnter, *literal = false, false })
+	executor := &MockCommandExecutor{}
+
+	// Act
+	err := executeBroadcast(context.Background(), []string{"C-c", "{agent}"}, executor)
+
+	// Assert
+	if err != nil {
+		t.Fatalf("executeBroadcast() error = %v", err)
+	}
+	want := [][]string{
+		{"tmux", "send-keys", "-t", "agent-repo-abc123-sarah:{start}", "-l", "C-c sarah"},
+	}
+	if !reflect.DeepEqual(executor.commands, want) {
+		t.Errorf("Expected commands %v, got %v", want, executor.commands)
+	}
+}
+
+// paneExecutor is a MockCommandExecutor whose agent panes echo what was typed
+type paneExecutor struct {
+	MockCommandExecutor
+	typed map[string]string
+}
+
+func (p *paneExecutor) Execute(command string, args ...string) error {
+	if p.typed == nil {
+		p.typed = map[string]string{}
+	}
+	if len(args) == 4 && args[0] == "send-keys" {
+		p.typed[args[2]] = args[3]
+	}
+	return p.MockCommandExecutor.Execute(command, args...)
+}
+
+func (p *paneExecutor) ExecuteCommand(name string, args ...string) ([]byte, error) {
+	return []byte("> " + p.typed[args[len(args)-1]]), nil
+}
+
+// TestExecuteBroadcastOrderedAck checks that sessions are messaged in name
+// order and, with --ack, submitted once the message shows in their pane
+func TestExecuteBroadcastOrderedAck(t *testing.T) {
+	// Arrange
+	withActiveSessions(t, []string{"agent-repo-abc123-sarah", "agent-repo-abc123-john"}, nil)
+	*ack = true
+	t.Cleanup(func() { *ack = false })
+	executor := &paneExecutor{}
+
+	// Act
+	err := executeBroadcast(context.Background(), []string{"rebase"}, executor)
+
+	// Assert
+	if err != nil {
+		t.Fatalf("executeBroadcast() error = %v", err)
+	}
+	var want [][]string
+	for _, session := range []string{"agent-repo-abc123-john", "agent-repo-abc123-sarah"} {
+		target := session + ":{start}"
+		want = append(want,
+			[]string{"tmux", "send-keys", "-t", target, "rebase"},
+			[]string{"tmux", "send-keys", "-t", target, "Enter"},
+			[]string{"tmux", "send-keys", "-t", target, "Enter"},
+		)
+	}
+	if !reflect.DeepEqual(executor.commands, want) {
+		t.Errorf("Expected commands %v, got %v", want, executor.commands)
+	}
+}
+
+// TestExecuteBroadcastSerial checks that --serial waits for each agent to
+// work through the message before messaging the next
+func TestExecuteBroadcastSerial(t *testing.T) {
+	// Arrange
+	withActiveSessions(t, []string{"agent-repo-abc123-john", "agent-repo-abc123-sarah"}, nil)
+	*serial = true
+	originalPoll, originalPane := serialPoll, sessionPane
+	serialPoll = time.Millisecond
+	t.Cleanup(func() { *serial, serialPoll, sessionPane = false, originalPoll, originalPane })
+
+	executor := &MockCommandExecutor{}
+	// john works for three captures after his message, then is ready
+	var captures []int
+	sessionPane = func(ctx context.Context, sessionName string) (string, error) {
+		captures = append(captures, len(executor.commands))
+		if len(captures) <= 3 {
+			return "✻ Thinking… (esc to interrupt)", nil
+		}
+		return "> ", nil
+	}
+
+	// Act
+	err := executeBroadcast(context.Background(), []string{"rebase"}, executor)
+
+	// Assert
+	if err != nil {
+		t.Fatalf("executeBroadcast() error = %v", err)
+	}
+	if len(captures) != 4 {
+		t.Fatalf("Expected john's pane read until he was ready and sarah's not at all, got %d reads", len(captures))
+	}
+	for _, sent := range captures {
+		if sent != 2 {
+			t.Errorf("Expected sarah messaged only after john was ready, got reads after %v commands", captures)
+			break
+		}
+	}
+	if len(executor.commands) != 4 {
+		t.Errorf("Expected both sessions messaged, got %v", executor.commands)
+	}
+}
+
+func TestWaitReady(t *testing.T) {
+	originalPoll, originalPickup, originalPane := serialPoll, serialPickup, sessionPane
+	serialPoll, serialPickup = time.Millisecond, 5*time.Millisecond
+	t.Cleanup(func() { serialPoll, serialPickup, sessionPane = originalPoll, originalPickup, originalPane })
+
+	// An agent that never starts working is taken to have handled the message
+	sessionPane = func(context.Context, string) (string, error) { return "> ", nil }
+	if err := waitReady(context.Background(), "agent-repo-abc123-john", time.Second); err != nil {
+		t.Errorf("waitReady() error = %v", err)
+	}
+
+	sessionPane = func(context.Context, string) (string, error) { return "esc to interrupt", nil }
+	if err := waitReady(context.Background(), "agent-repo-abc123-john", 10*time.Millisecond); err == nil || !strings.Contains(err.Error(), "john is still working") {
+		t.Errorf("Expected a timeout for a busy agent, got %v", err)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	if err := waitReady(ctx, "agent-repo-abc123-john", time.Second); err != context.Canceled {
+		t.Errorf("Expected a cancelled wait to stop, got %v", err)
+	}
+}
+
+// TestExecuteBroadcastDelayFromConfig checks that broadcastDelivery.delay
+// paces the sessions
+func TestExecuteBroadcastDelayFromConfig(t *testing.T) {
+	// Arrange
+	withActiveSessions(t, []string{"agent-repo-abc123-john", "agent-repo-abc123-sarah"}, nil)
+	path := filepath.Join(t.TempDir(), "uzi.yaml")
+	if err := os.WriteFile(path, []byte("broadcastDelivery:\n  delay: 50ms\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	original := *configPath
+	*configPath = path
+	t.Cleanup(func() { *configPath = original })
+
+	// Act
+	start := time.Now()
+	err := executeBroadcast(context.Background(), []string{"hello"}, &MockCommandExecutor{})
+
+	// Assert
+	if err != nil {
+		t.Fatalf("executeBroadcast() error = %v", err)
+	}
+	if elapsed := time.Since(start); elapsed < 50*time.Millisecond {
+		t.Errorf("Expected a 50ms pause between sessions, took %s", elapsed)
+	}
+}
+
+// TestExecuteBroadcastForbiddenPattern checks that a message matching
+// policy.forbidBroadcastPatterns reaches no session
+func TestExecuteBroadcastForbiddenPattern(t *testing.T) {
+	// Arrange
+	withActiveSessions(t, []string{"agent-repo-abc123-john", "agent-repo-abc123-sarah"}, nil)
+	path := filepath.Join(t.TempDir(), "uzi.yaml")
+	if err := os.WriteFile(path, []byte("policy:\n  forbidBroadcastPatterns: [\"rm -rf\"]\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	original := *configPath
+	*configPath = path
+	t.Cleanup(func() { *configPath = original })
+	executor := &MockCommandExecutor{}
+
+	// Act
+	err := executeBroadcast(context.Background(), []string{"run", "rm -rf", "build"}, executor)
+
+	// Assert
+	var violation *config.PolicyViolation
+	if !errors.As(err, &violation) || violation.Rule != "forbidBroadcastPatterns" {
+		t.Fatalf("Expected a forbidBroadcastPatterns violation, got %v", err)
+	}
+	if len(executor.commands) != 0 {
+		t.Errorf("Expected no tmux commands, got %v", executor.commands)
+	}
+}
@@ -1,15 +1,23 @@
 package checkpoint
 
 import (
+	"bufio"
 	"context"
 	"encoding/json"
+	"errors"
 	"flag"
 	"fmt"
+	"io"
 	"os"
-	"os/exec"
 	"strings"
 
+	"github.com/nehpz/claudicus/pkg/cmdctx"
+	"github.com/nehpz/claudicus/pkg/config"
+	"github.com/nehpz/claudicus/pkg/events"
+	"github.com/nehpz/claudicus/pkg/pipeline"
 	"github.com/nehpz/claudicus/pkg/state"
+	"github.com/nehpz/claudicus/pkg/tui"
+	"github.com/nehpz/claudicus/pkg/vcs"
 
 	"github.com/charmbracelet/log"
 	"github.com/peterbourgon/ff/v3/ffcli"
@@ -17,16 +25,76 @@ import (
 
 var (
 	fs            = flag.NewFlagSet("uzi checkpoint", flag.ExitOnError)
+	paths         pathsFlag
+	configPath    = fs.String("config", config.GetDefaultConfigPath(), "path to config file")
+	force         = fs.Bool("force", false, "with --paths, overwrite uncommitted changes to the selected files")
 	CmdCheckpoint = &ffcli.Command{
 		Name:       "checkpoint",
-		ShortUsage: "uzi checkpoint <agent-name> <commit-message>",
+		ShortUsage: "uzi checkpoint [<agent-name> <commit-message>]",
 		ShortHelp:  "Rebase changes from an agent worktree into the current worktree and commit",
-		FlagSet:    fs,
-		Exec:       executeCheckpoint,
+		LongHelp: `Rebase the agent branch onto the current branch after committing the agent's
+pending changes. Without arguments on a terminal, the agent is picked from a
+list and the commit message asked for.
+
+With --paths <glob> (before the agent name), only files changed by the agent that match one of the globs are
+copied from the agent branch and committed on the current branch; the rest of
+the agent's work stays on its branch. --paths may be repeated or take a
+comma-separated list, and "dir/**" selects everything below dir. It refuses
+when a selected file has uncommitted changes in the current worktree unless
+--force is given.
+
+The checkpoint: section of uzi.yaml sets the author of checkpoint commits and
+whether they are signed off and signed; without it, the git config is used.
+Its messageTemplate wraps the commit message, filling in {message}, {agent},
+{session}, {prompt}, and {model}, e.g. "agent({agent}): {message}".`,
+		FlagSet: fs,
+		Exec:    executeCheckpoint,
+	}
+)
+
+// askCommitMessage reads the message of a checkpoint commit from in
+func askCommitMessage(in io.Reader, out io.Writer) (string, error) {
+	fmt.Fprint(out, "Commit message: ")
+	answer, err := bufio.NewReader(in).ReadString('\n')
+	if message := strings.TrimSpace(answer); message != "" {
+		return message, nil
 	}
+	if err != nil {
+		fmt.Fprintln(out)
+	}
+	return "", fmt.Errorf("a commit message is required")
+}
+
+// repo is the version control checkpoints commit and merge with
+var repo vcs.VCS = vcs.Git{Stdout: os.Stdout, Stderr: os.Stderr}
+
+func init() {
+	fs.Var(&paths, "paths", "only checkpoint agent files matching this glob (repeatable)")
+}
+
+// interactive and pickAgent let the agent to checkpoint be picked on a
+// terminal when none is named
+var (
+	interactive = tui.Interactive
+	pickAgent   = tui.PickAgent
 )
 
 func executeCheckpoint(ctx context.Context, args []string) error {
+	if len(args) == 0 && interactive() {
+		agentName, err := pickAgent("Checkpoint which agent?")
+		if errors.Is(err, tui.ErrNoAgentPicked) {
+			fmt.Println("Checkpoint aborted")
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+		message, err := askCommitMessage(os.Stdin, os.Stdout)
+		if err != nil {
+			return err
+		}
+		args = []string{agentName, message}
+	}
 	if len(args) < 2 {
 		return fmt.Errorf("agent name and commit message arguments are required")
 	}
@@ -35,10 +103,15 @@ func executeCheckpoint(ctx context.Context, args []string) error {
 	commitMessage := args[1]
 	log.Debug("Checkpointing changes from agent", "agent", agentName)
 
+	commitConfig, err := loadCheckpointConfig(ctx, *configPath)
+	if err != nil {
+		return err
+	}
+
 	// Get state manager to read from config
-	sm := state.NewStateManager()
-	if sm == nil {
-		return fmt.Errorf("could not initialize state manager")
+	sm, err := cmdctx.From(ctx).State()
+	if err != nil {
+		return err
 	}
 
 	// Get active sessions from state
@@ -67,6 +140,13 @@ func executeCheckpoint(ctx context.Context, args []string) error {
 		return fmt.Errorf("no active session found for agent: %s", agentName)
 	}
 
+	// Keep a concurrent kill from removing the worktree mid-rebase
+	lock, err := sm.LockSession(sessionToCheckpoint, "checkpoint")
+	if err != nil {
+		return err
+	}
+	defer lock.Release()
+
 	// Get session state to find worktree path
 	states := make(map[string]state.AgentState)
 	if data, err := os.ReadFile(sm.GetStatePath()); err != nil {
@@ -77,13 +157,19 @@ func executeCheckpoint(ctx context.Context, args []string) error {
 		}
 	}
 
-	sessionState, ok := states[sessionToCheckpoint]
-	if !ok || sessionState.WorktreePath == "" {
-		return fmt.Errorf("invalid state for session: %s", sessionToCheckpoint)
+	sessionState, err := checkpointState(states, sessionToCheckpoint, agentName)
+	if err != nil {
+		return err
 	}
 
-	// Get the actual branch name from the state
-	agentBranchName := sessionState.BranchName
+	// Fill in checkpoint.messageTemplate so the commit records where it came from
+	commitMessage = commitConfig.RenderMessage(config.CheckpointMessage{
+		Message: commitMessage,
+		Agent:   agentName,
+		Session: sessionToCheckpoint,
+		Prompt:  sessionState.Prompt,
+		Model:   sessionState.Model,
+	})
 
 	// Get current directory (should be the main worktree)
 	currentDir, err := os.Getwd()
@@ -91,67 +177,132 @@ func executeCheckpoint(ctx context.Context, args []string) error {
 		return fmt.Errorf("error getting current directory: %v", err)
 	}
 
-	// Get the current branch name in the main worktree
-	getCurrentBranchCmd := exec.CommandContext(ctx, "git", "branch", "--show-current")
-	getCurrentBranchCmd.Dir = currentDir
-	currentBranchOutput, err := getCurrentBranchCmd.Output()
+	if err := mergeAgent(ctx, currentDir, sessionState, commitOptions(commitConfig, commitMessage), paths); err != nil {
+		return recordCheckpoint(sm, sessionToCheckpoint, err)
+	}
+	if len(paths) > 0 {
+		fmt.Printf("Successfully checkpointed selected files from agent: %s\n", agentName)
+	} else {
+		fmt.Printf("Successfully checkpointed changes from agent: %s\n", agentName)
+	}
+	fmt.Printf("Successfully committed changes with message: %s\n", commitMessage)
+	recordPipelineCheckpoint(agentName)
+	cfg, _ := cmdctx.From(ctx).LoadConfig(*configPath)
+	cmdctx.From(ctx).Events(cfg).Dispatch(events.NewEvent(events.EventCheckpoint, sessionToCheckpoint, commitMessage))
+	return recordCheckpoint(sm, sessionToCheckpoint, nil)
+}
+
+// mergeAgent commits the pending changes of an agent on its branch and brings
+// the branch into the checkout in currentDir: all of it, or with paths only
+// the files matching them
+func mergeAgent(ctx context.Context, currentDir string, sessionState state.AgentState, commit vcs.CommitOptions, paths []string) error {
+	agentBranchName := sessionState.BranchName
+
+	currentBranch, err := repo.CurrentBranch(ctx, currentDir)
 	if err != nil {
 		return fmt.Errorf("error getting current branch: %v", err)
 	}
-	currentBranch := strings.TrimSpace(string(currentBranchOutput))
-
-	// Check if agent branch exists
-	checkBranchCmd := exec.CommandContext(ctx, "git", "show-ref", "--verify", "--quiet", "refs/heads/"+agentBranchName)
-	checkBranchCmd.Dir = currentDir
-	if err := checkBranchCmd.Run(); err != nil {
+	if _, err := repo.RevParse(ctx, currentDir, agentBranchName); err != nil {
 		return fmt.Errorf("agent branch does not exist: %s", agentBranchName)
 	}
 
-	// Stage all changes and commit on the agent branch
-	addCmd := exec.CommandContext(ctx, "git", "add", ".")
-	addCmd.Dir = sessionState.WorktreePath
-	if err := addCmd.Run(); err != nil {
-		return fmt.Errorf("error staging changes: %v", err)
-	}
-
-	commitCmd := exec.CommandContext(ctx, "git", "commit", "-am", commitMessage)
-	commitCmd.Dir = sessionState.WorktreePath
-	commitCmd.Stdout = os.Stdout
-	commitCmd.Stderr = os.Stderr
-	if err := commitCmd.Run(); err != nil {
-		log.Warn("No unstaged changes to commit, rebasing")
+	// Commit everything on the agent branch, leaving out the file the agent
+	// touches to signal it is done
+	agentCommit := commit
+	agentCommit.Exclude = []string{config.DoneFile}
+	if err := repo.Commit(ctx, sessionState.WorktreePath, agentCommit); errors.Is(err, vcs.ErrNothingToCommit) {
+		log.Warn("No uncommitted changes to commit, rebasing")
+	} else if err != nil {
+		return fmt.Errorf("error committing agent changes: %v", err)
 	}
 
 	// Get the base commit where the agent branch diverged
-	mergeBaseCmd := exec.CommandContext(ctx, "git", "merge-base", currentBranch, agentBranchName)
-	mergeBaseCmd.Dir = currentDir
-	mergeBaseOutput, err := mergeBaseCmd.Output()
+	mergeBase, err := repo.MergeBase(ctx, currentDir, currentBranch, agentBranchName)
 	if err != nil {
 		return fmt.Errorf("error finding merge base: %v", err)
 	}
-	mergeBase := strings.TrimSpace(string(mergeBaseOutput))
 
-	// Check if there are any changes to rebase
-	diffCmd := exec.CommandContext(ctx, "git", "rev-list", "--count", mergeBase+".."+agentBranchName)
-	diffCmd.Dir = currentDir
-	diffOutput, err := diffCmd.Output()
+	// Partial checkpoint: bring over only the selected files instead of rebasing
+	if len(paths) > 0 {
+		return stagedMerge(ctx, currentDir, mergeBase, agentBranchName, paths, *force, commit)
+	}
+
+	changeCount, err := repo.CountCommits(ctx, currentDir, mergeBase, agentBranchName)
 	if err != nil {
 		return fmt.Errorf("error checking for changes: %v", err)
 	}
-	changeCount := strings.TrimSpace(string(diffOutput))
-
-	fmt.Printf("Checkpointing %s commits from agent: %s\n", changeCount, agentName)
+	fmt.Printf("Checkpointing %d commits from branch: %s\n", changeCount, agentBranchName)
 
-	// Rebase the agent branch onto the current branch using --no-pager
-	rebaseCmd := exec.CommandContext(ctx, "git", "--no-pager", "rebase", agentBranchName)
-	rebaseCmd.Dir = currentDir
-	rebaseCmd.Stdout = os.Stdout
-	rebaseCmd.Stderr = os.Stderr
-	if err := rebaseCmd.Run(); err != nil {
+	if err := repo.Merge(ctx, currentDir, agentBranchName); err != nil {
 		return fmt.Errorf("error rebasing agent changes: %v", err)
 	}
-
-	fmt.Printf("Successfully checkpointed changes from agent: %s\n", agentName)
-	fmt.Printf("Successfully committed changes with message: %s\n", commitMessage)
 	return nil
 }
+
+// loadCheckpointConfig returns the checkpoint commit settings from the config
+// file. Without a config file, commits use the git config alone.
+func loadCheckpointConfig(ctx context.Context, path string) (*config.CheckpointConfig, error) {
+	cfg, err := cmdctx.From(ctx).LoadConfig(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to load %s: %w", path, err)
+	}
+	if err := cfg.Validate(); err != nil {
+		return nil, fmt.Errorf("invalid %s: %w", path, err)
+	}
+	return cfg.Checkpoint, nil
+}
+
+// commitOptions describes a checkpoint commit: the message, and the author
+// and signing options from the config
+func commitOptions(cc *config.CheckpointConfig, message string) vcs.CommitOptions {
+	opts := vcs.CommitOptions{Message: message}
+	if cc == nil {
+		return opts
+	}
+	if name, email, err := config.ParseAuthor(cc.Author); err == nil {
+		opts.AuthorName, opts.AuthorEmail = name, email
+	}
+	opts.Sign = cc.Sign
+	opts.Signoff = cc.Signoff
+	return opts
+}
+
+// checkpointState returns the state of a session that can be checkpointed.
+// Shared sessions run in the main checkout and have no branch to merge.
+func checkpointState(states map[string]state.AgentState, sessionName, agentName string) (state.AgentState, error) {
+	sessionState, ok := states[sessionName]
+	if ok && sessionState.IsShared() {
+		return sessionState, fmt.Errorf("agent %s runs in the main checkout (--no-worktree) and has no branch to checkpoint", agentName)
+	}
+	if ok && sessionState.IsRemote() {
+		return sessionState, fmt.Errorf("agent %s runs on host %s; push its branch there and merge it locally", agentName, sessionState.Host)
+	}
+	if !ok || sessionState.WorktreePath == "" {
+		return sessionState, fmt.Errorf("invalid state for session: %s", sessionName)
+	}
+	return sessionState, nil
+}
+
+// recordCheckpoint saves the outcome of a checkpoint on the session, for
+// `uzi report`, and returns err
+func recordCheckpoint(sm *state.StateManager, sessionName string, err error) error {
+	if recordErr := sm.SetCheckpointResult(sessionName, err); recordErr != nil {
+		log.Warn("Could not record checkpoint result", "session", sessionName, "error", recordErr)
+	}
+	return err
+}
+
+// recordPipelineCheckpoint lets a waiting `uzi pipeline run` know the agent's stage work landed
+func recordPipelineCheckpoint(agentName string) {
+	store, err := pipeline.NewStore()
+	if err != nil {
+		log.Warn("Could not open pipeline state", "error", err)
+		return
+	}
+	if _, err := store.MarkCheckpointed(agentName); err != nil {
+		log.Warn("Could not record pipeline checkpoint", "agent", agentName, "error", err)
+	}
+}
@@ -1,9 +1,16 @@
 package checkpoint
 
 import (
+	"bytes"
 	"context"
+	"errors"
+	"reflect"
 	"strings"
 	"testing"
+
+	"github.com/nehpz/claudicus/pkg/state"
+	"github.com/nehpz/claudicus/pkg/tui"
+	"github.com/nehpz/claudicus/pkg/vcs"
 )
 
 // TestExecuteCheckpoint tests the main executeCheckpoint function using table-driven tests
@@ -79,8 +86,8 @@ func TestCmdCheckpointGlobalVariable(t *testing.T) {
 		t.Errorf("CmdCheckpoint.Name = %v, want %v", CmdCheckpoint.Name, "checkpoint")
 	}
 
-	if CmdCheckpoint.ShortUsage != "uzi checkpoint <agent-name> <commit-message>" {
-		t.Errorf("CmdCheckpoint.ShortUsage = %v, want %v", CmdCheckpoint.ShortUsage, "uzi checkpoint <agent-name> <commit-message>")
+	if CmdCheckpoint.ShortUsage != "uzi checkpoint [<agent-name> <commit-message>]" {
+		t.Errorf("CmdCheckpoint.ShortUsage = %v, want %v", CmdCheckpoint.ShortUsage, "uzi checkpoint [<agent-name> <commit-message>]")
 	}
 
 	if CmdCheckpoint.ShortHelp != "Rebase changes from an agent worktree into the current worktree and commit" {
@@ -96,6 +103,47 @@ func TestCmdCheckpointGlobalVariable(t *testing.T) {
 	}
 }
 
+func TestExecuteCheckpointPicksAgent(t *testing.T) {
+	defer func(i func() bool, p func(string) (string, error)) { interactive, pickAgent = i, p }(interactive, pickAgent)
+	interactive = func() bool { return true }
+
+	pickAgent = func(string) (string, error) { return "", tui.ErrNoAgentPicked }
+	if err := executeCheckpoint(context.Background(), nil); err != nil {
+		t.Errorf("Expected a cancelled pick to abort quietly, got %v", err)
+	}
+
+	pickAgent = func(string) (string, error) { return "", errors.New("no active agents") }
+	if err := executeCheckpoint(context.Background(), nil); err == nil || err.Error() != "no active agents" {
+		t.Errorf("Expected the picker's error, got %v", err)
+	}
+
+	// The agent and message are only asked for when neither is given
+	pickAgent = func(string) (string, error) {
+		t.Fatal("Expected no picker with an agent name")
+		return "", nil
+	}
+	if err := executeCheckpoint(context.Background(), []string{"sarah"}); err == nil || !strings.Contains(err.Error(), "arguments are required") {
+		t.Errorf("Expected the missing message reported, got %v", err)
+	}
+}
+
+func TestAskCommitMessage(t *testing.T) {
+	var out bytes.Buffer
+	message, err := askCommitMessage(strings.NewReader("  Add login form\n"), &out)
+	if err != nil || message != "Add login form" {
+		t.Errorf("askCommitMessage() = %q, %v", message, err)
+	}
+	if out.String() != "Commit message: " {
+		t.Errorf("Unexpected prompt %q", out.String())
+	}
+
+	for _, answer := range []string{"\n", "", "   "} {
+		if _, err := askCommitMessage(strings.NewReader(answer), &bytes.Buffer{}); err == nil {
+			t.Errorf("Expected an error for the answer %q", answer)
+		}
+	}
+}
+
 // TestArgumentValidation tests the argument validation logic specifically
 func TestArgumentValidation(t *testing.T) {
 	tests := []struct {
@@ -156,3 +204,64 @@ func TestArgumentValidation(t *testing.T) {
 		})
 	}
 }
+
+func TestCheckpointState(t *testing.T) {
+	states := map[string]state.AgentState{
+		"agent-p-1-sarah": {WorktreePath: "/wt/sarah", BranchName: "sarah-branch"},
+		"agent-p-1-rev":   {Mode: state.ModeShared},
+		"agent-p-1-empty": {},
+		"agent-p-1-gpu":   {WorktreePath: "/home/dev/wt/gpu", Host: "gpu1", SSH: "dev@gpu1"},
+	}
+
+	if got, err := checkpointState(states, "agent-p-1-sarah", "sarah"); err != nil || got.BranchName != "sarah-branch" {
+		t.Errorf("Expected worktree session to be checkpointable, got %+v, %v", got, err)
+	}
+
+	tests := []struct {
+		session string
+		want    string
+	}{
+		{"agent-p-1-rev", "runs in the main checkout"},
+		{"agent-p-1-gpu", "runs on host gpu1"},
+		{"agent-p-1-empty", "invalid state for session"},
+		{"agent-p-1-missing", "invalid state for session"},
+	}
+	for _, tt := range tests {
+		if _, err := checkpointState(states, tt.session, "agent"); err == nil || !strings.Contains(err.Error(), tt.want) {
+			t.Errorf("checkpointState(%q) error = %v, want substring %q", tt.session, err, tt.want)
+		}
+	}
+}
+
+func TestMergeAgent(t *testing.T) {
+	fake := vcs.NewFake("/repo", "main")
+	defer func(saved vcs.VCS) { repo = saved }(repo)
+	repo = fake
+
+	ctx := context.Background()
+	if err := fake.CreateWorktree(ctx, "/repo", "/wt/sarah", vcs.WorktreeOptions{Branch: "sarah"}); err != nil {
+		t.Fatal(err)
+	}
+	fake.SetChanges("/wt/sarah", vcs.Stat{Files: 1, Insertions: 3})
+	sarah := state.AgentState{WorktreePath: "/wt/sarah", BranchName: "sarah"}
+
+	if err := mergeAgent(ctx, "/repo", sarah, vcs.CommitOptions{Message: "fix login"}, nil); err != nil {
+		t.Fatalf("mergeAgent() error = %v", err)
+	}
+	if got, want := fake.Log("main"), []string{"initial", "fix login"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("Expected the agent's commit on main, got %v", got)
+	}
+
+	// Nothing pending: the branch is merged as it is
+	if err := mergeAgent(ctx, "/repo", sarah, vcs.CommitOptions{Message: "again"}, nil); err != nil {
+		t.Errorf("Expected a checkpoint without changes to succeed, got %v", err)
+	}
+	if got := fake.Log("main"); len(got) != 2 {
+		t.Errorf("Expected no new commit without changes, got %v", got)
+	}
+
+	missing := state.AgentState{WorktreePath: "/wt/gone", BranchName: "gone"}
+	if err := mergeAgent(ctx, "/repo", missing, vcs.CommitOptions{Message: "x"}, nil); err == nil || !strings.Contains(err.Error(), "agent branch does not exist") {
+		t.Errorf("Expected an error for a missing branch, got %v", err)
+	}
+}
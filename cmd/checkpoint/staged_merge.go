@@ -0,0 +1,188 @@
+package checkpoint
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"os/exec"
+	"path"
+	"strings"
+
+	"github.com/nehpz/claudicus/pkg/vcs"
+)
+
+// pathsFlag collects --paths globs; it may be repeated or given a comma-separated list
+type pathsFlag []string
+
+func (p *pathsFlag) String() string {
+	return strings.Join(*p, ",")
+}
+
+func (p *pathsFlag) Set(value string) error {
+	for _, glob := range strings.Split(value, ",") {
+		if glob = strings.TrimSpace(glob); glob != "" {
+			*p = append(*p, glob)
+		}
+	}
+	return nil
+}
+
+// fileChange is a path changed on the agent branch with its git status letter (A, M, D, ...)
+type fileChange struct {
+	status string
+	path   string
+}
+
+// parseNameStatus parses `git diff --name-status --no-renames` output
+func parseNameStatus(output string) []fileChange {
+	var changes []fileChange
+	for _, line := range strings.Split(output, "\n") {
+		fields := strings.SplitN(strings.TrimSpace(line), "\t", 2)
+		if len(fields) != 2 {
+			continue
+		}
+		changes = append(changes, fileChange{status: fields[0][:1], path: fields[1]})
+	}
+	return changes
+}
+
+// matchGlob reports whether a repository-relative file path matches a --paths glob.
+// Globs use path.Match syntax against the full path; a glob ending in "/" or "/**"
+// selects everything below that directory, and a wildcard glob without "/"
+// (such as "*.go") also matches the file's base name.
+func matchGlob(glob, file string) bool {
+	if dir, ok := strings.CutSuffix(glob, "/**"); ok {
+		return strings.HasPrefix(file, dir+"/")
+	}
+	if strings.HasSuffix(glob, "/") {
+		return strings.HasPrefix(file, glob)
+	}
+	if matched, _ := path.Match(glob, file); matched {
+		return true
+	}
+	if !strings.Contains(glob, "/") && strings.ContainsAny(glob, "*?[") {
+		matched, _ := path.Match(glob, path.Base(file))
+		return matched
+	}
+	return false
+}
+
+// selectChanges keeps the changes whose path matches any of the globs
+func selectChanges(changes []fileChange, globs []string) []fileChange {
+	var selected []fileChange
+	for _, change := range changes {
+		for _, glob := range globs {
+			if matchGlob(glob, change.path) {
+				selected = append(selected, change)
+				break
+			}
+		}
+	}
+	return selected
+}
+
+// stagedMergeCommands returns the git argument vectors that bring the selected
+// changes from the agent branch into the index and working tree
+func stagedMergeCommands(agentBranch string, changes []fileChange) [][]string {
+	var restored, deleted []string
+	for _, change := range changes {
+		if change.status == "D" {
+			deleted = append(deleted, change.path)
+		} else {
+			restored = append(restored, change.path)
+		}
+	}
+
+	var commands [][]string
+	if len(restored) > 0 {
+		commands = append(commands, append([]string{"checkout", agentBranch, "--"}, restored...))
+	}
+	if len(deleted) > 0 {
+		commands = append(commands, append([]string{"rm", "--quiet", "--"}, deleted...))
+	}
+	return commands
+}
+
+// parsePorcelainPaths returns the paths listed in `git status --porcelain` output;
+// for a rename only the new path is kept
+func parsePorcelainPaths(output string) []string {
+	var files []string
+	for _, line := range strings.Split(output, "\n") {
+		if len(line) < 4 {
+			continue
+		}
+		file := line[3:]
+		if _, to, ok := strings.Cut(file, " -> "); ok {
+			file = to
+		}
+		files = append(files, file)
+	}
+	return files
+}
+
+// dirtyPaths returns the selected paths that have uncommitted changes in dir,
+// which checking them out from the agent branch would overwrite
+func dirtyPaths(ctx context.Context, dir string, changes []fileChange) ([]string, error) {
+	args := []string{"status", "--porcelain", "--"}
+	for _, change := range changes {
+		args = append(args, change.path)
+	}
+	cmd := exec.CommandContext(ctx, "git", args...)
+	cmd.Dir = dir
+	output, err := cmd.Output()
+	if err != nil {
+		return nil, err
+	}
+	return parsePorcelainPaths(string(output)), nil
+}
+
+// stagedMerge applies only the files matching globs from the agent branch onto the
+// current branch in dir and commits them, leaving the rest of the agent's work behind.
+// Unless force is set, it refuses when any of those files has uncommitted changes in dir.
+// Picking files from a branch is not part of vcs.VCS, so this runs git directly.
+func stagedMerge(ctx context.Context, dir, mergeBase, agentBranch string, globs []string, force bool, commit vcs.CommitOptions) error {
+	diffCmd := exec.CommandContext(ctx, "git", "diff", "--name-status", "--no-renames", mergeBase, agentBranch)
+	diffCmd.Dir = dir
+	diffOutput, err := diffCmd.Output()
+	if err != nil {
+		return fmt.Errorf("error listing agent changes: %v", err)
+	}
+
+	selected := selectChanges(parseNameStatus(string(diffOutput)), globs)
+	if len(selected) == 0 {
+		return fmt.Errorf("no changed files match paths: %s", strings.Join(globs, ", "))
+	}
+
+	if !force {
+		dirty, err := dirtyPaths(ctx, dir, selected)
+		if err != nil {
+			return fmt.Errorf("error checking for uncommitted changes: %v", err)
+		}
+		if len(dirty) > 0 {
+			return fmt.Errorf("uncommitted changes would be overwritten in: %s (commit or stash them, or use --force)", strings.Join(dirty, ", "))
+		}
+	}
+
+	fmt.Printf("Checkpointing %d files from branch: %s\n", len(selected), agentBranch)
+	for _, change := range selected {
+		fmt.Printf("  %s %s\n", change.status, change.path)
+	}
+
+	for _, args := range stagedMergeCommands(agentBranch, selected) {
+		cmd := exec.CommandContext(ctx, "git", args...)
+		cmd.Dir = dir
+		cmd.Stderr = os.Stderr
+		if err := cmd.Run(); err != nil {
+			return fmt.Errorf("error staging agent files: %v", err)
+		}
+	}
+
+	// Commit only the selected paths so anything already staged in dir stays staged
+	for _, change := range selected {
+		commit.Paths = append(commit.Paths, change.path)
+	}
+	if err := repo.Commit(ctx, dir, commit); err != nil {
+		return fmt.Errorf("error committing selected files: %v", err)
+	}
+	return nil
+}
@@ -0,0 +1,197 @@
+package checkpoint
+
+import (
+	"context"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/nehpz/claudicus/pkg/config"
+	"github.com/nehpz/claudicus/pkg/vcs"
+)
+
+func TestPathsFlag(t *testing.T) {
+	var p pathsFlag
+	if err := p.Set("src/**, docs/*.md"); err != nil {
+		t.Fatalf("Set() error = %v", err)
+	}
+	if err := p.Set("go.mod"); err != nil {
+		t.Fatalf("Set() error = %v", err)
+	}
+
+	want := pathsFlag{"src/**", "docs/*.md", "go.mod"}
+	if !reflect.DeepEqual(p, want) {
+		t.Errorf("pathsFlag = %v, want %v", p, want)
+	}
+	if p.String() != "src/**,docs/*.md,go.mod" {
+		t.Errorf("String() = %q", p.String())
+	}
+}
+
+func TestParseNameStatus(t *testing.T) {
+	output := "M\tcmd/app/main.go\nA\tdocs/new.md\nD\told.txt\n\n"
+
+	want := []fileChange{
+		{status: "M", path: "cmd/app/main.go"},
+		{status: "A", path: "docs/new.md"},
+		{status: "D", path: "old.txt"},
+	}
+	if got := parseNameStatus(output); !reflect.DeepEqual(got, want) {
+		t.Errorf("parseNameStatus() = %v, want %v", got, want)
+	}
+}
+
+func TestMatchGlob(t *testing.T) {
+	tests := []struct {
+		glob string
+		file string
+		want bool
+	}{
+		{"src/**", "src/a/b/c.go", true},
+		{"src/**", "srcx/c.go", false},
+		{"src/", "src/main.go", true},
+		{"cmd/*/main.go", "cmd/app/main.go", true},
+		{"cmd/*.go", "cmd/app/main.go", false},
+		{"*.go", "cmd/app/main.go", true},
+		{"*.md", "cmd/app/main.go", false},
+		{"go.mod", "go.mod", true},
+		{"main.go", "cmd/app/main.go", false},
+		{`notes\*.txt`, "notes*.txt", true},
+		{"[", "go.mod", false},
+	}
+
+	for _, tt := range tests {
+		if got := matchGlob(tt.glob, tt.file); got != tt.want {
+			t.Errorf("matchGlob(%q, %q) = %v, want %v", tt.glob, tt.file, got, tt.want)
+		}
+	}
+}
+
+func TestSelectChanges(t *testing.T) {
+	changes := []fileChange{
+		{status: "M", path: "pkg/api/handler.go"},
+		{status: "A", path: "scratch/notes.txt"},
+		{status: "D", path: "pkg/api/legacy.go"},
+	}
+
+	got := selectChanges(changes, []string{"pkg/**"})
+	want := []fileChange{changes[0], changes[2]}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("selectChanges() = %v, want %v", got, want)
+	}
+
+	if got := selectChanges(changes, []string{"*.rs"}); len(got) != 0 {
+		t.Errorf("Expected no matches, got %v", got)
+	}
+}
+
+func TestStagedMergeCommands(t *testing.T) {
+	changes := []fileChange{
+		{status: "M", path: "pkg/api/handler.go"},
+		{status: "D", path: "pkg/api/legacy.go"},
+		{status: "A", path: "pkg/api/new.go"},
+	}
+
+	want := [][]string{
+		{"checkout", "agent-sarah", "--", "pkg/api/handler.go", "pkg/api/new.go"},
+		{"rm", "--quiet", "--", "pkg/api/legacy.go"},
+	}
+	if got := stagedMergeCommands("agent-sarah", changes); !reflect.DeepEqual(got, want) {
+		t.Errorf("stagedMergeCommands() = %v, want %v", got, want)
+	}
+
+	onlyDeleted := stagedMergeCommands("agent-sarah", []fileChange{{status: "D", path: "a.txt"}})
+	if len(onlyDeleted) != 1 || onlyDeleted[0][0] != "rm" {
+		t.Errorf("Expected only an rm command, got %v", onlyDeleted)
+	}
+}
+
+func TestCmdCheckpointPathsFlag(t *testing.T) {
+	if CmdCheckpoint.FlagSet.Lookup("paths") == nil {
+		t.Error("CmdCheckpoint should define a paths flag")
+	}
+}
+
+func TestCommitOptions(t *testing.T) {
+	if got := commitOptions(nil, "msg"); !reflect.DeepEqual(got, vcs.CommitOptions{Message: "msg"}) {
+		t.Errorf("commitOptions(nil) = %+v", got)
+	}
+
+	cc := &config.CheckpointConfig{Author: "Agent Claude <agents@team>", Signoff: true, Sign: true}
+	got := commitOptions(cc, "msg")
+	want := vcs.CommitOptions{Message: "msg", AuthorName: "Agent Claude", AuthorEmail: "agents@team", Sign: true, Signoff: true}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("commitOptions() = %+v, want %+v", got, want)
+	}
+}
+
+func TestParsePorcelainPaths(t *testing.T) {
+	output := " M a.txt\nA  dir/b.go\nR  old.txt -> new.txt\n?? c.txt\n"
+	want := []string{"a.txt", "dir/b.go", "new.txt", "c.txt"}
+	if got := parsePorcelainPaths(output); !reflect.DeepEqual(got, want) {
+		t.Errorf("parsePorcelainPaths() = %v, want %v", got, want)
+	}
+	if got := parsePorcelainPaths(""); got != nil {
+		t.Errorf("parsePorcelainPaths(\"\") = %v, want nil", got)
+	}
+}
+
+func TestStagedMergeRefusesDirtyPaths(t *testing.T) {
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git not available")
+	}
+	for _, key := range []string{"GIT_AUTHOR_NAME", "GIT_COMMITTER_NAME"} {
+		t.Setenv(key, "t")
+	}
+	for _, key := range []string{"GIT_AUTHOR_EMAIL", "GIT_COMMITTER_EMAIL"} {
+		t.Setenv(key, "t@example.com")
+	}
+	ctx := context.Background()
+	dir := t.TempDir()
+	git := func(args ...string) string {
+		t.Helper()
+		cmd := exec.Command("git", args...)
+		cmd.Dir = dir
+		out, err := cmd.CombinedOutput()
+		if err != nil {
+			t.Fatalf("git %v: %v\n%s", args, err, out)
+		}
+		return strings.TrimSpace(string(out))
+	}
+	write := func(name, content string) {
+		t.Helper()
+		if err := os.WriteFile(filepath.Join(dir, name), []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	git("init", "-q")
+	write("a.txt", "base\n")
+	git("add", "a.txt")
+	git("commit", "-q", "-m", "base")
+	base := git("rev-parse", "HEAD")
+	main := git("rev-parse", "--abbrev-ref", "HEAD")
+	git("checkout", "-q", "-b", "agent-sarah")
+	write("a.txt", "agent\n")
+	git("commit", "-q", "-am", "agent work")
+	git("checkout", "-q", main)
+	write("a.txt", "local edit\n")
+
+	err := stagedMerge(ctx, dir, base, "agent-sarah", []string{"a.txt"}, false, vcs.CommitOptions{Message: "pick"})
+	if err == nil || !strings.Contains(err.Error(), "a.txt") || !strings.Contains(err.Error(), "--force") {
+		t.Fatalf("Expected stagedMerge to refuse the dirty a.txt, got %v", err)
+	}
+	if content, _ := os.ReadFile(filepath.Join(dir, "a.txt")); string(content) != "local edit\n" {
+		t.Errorf("Expected the local edit to be kept, got %q", content)
+	}
+
+	if err := stagedMerge(ctx, dir, base, "agent-sarah", []string{"a.txt"}, true, vcs.CommitOptions{Message: "pick"}); err != nil {
+		t.Fatalf("stagedMerge() with force error = %v", err)
+	}
+	if content, _ := os.ReadFile(filepath.Join(dir, "a.txt")); string(content) != "agent\n" {
+		t.Errorf("Expected force to take the agent's a.txt, got %q", content)
+	}
+}
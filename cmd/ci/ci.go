@@ -0,0 +1,398 @@
+package ci
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"strings"
+	"time"
+
+	"github.com/nehpz/claudicus/cmd/prompt"
+	"github.com/nehpz/claudicus/pkg/cmdctx"
+	"github.com/nehpz/claudicus/pkg/config"
+	"github.com/nehpz/claudicus/pkg/state"
+
+	"github.com/charmbracelet/log"
+	"github.com/peterbourgon/ff/v3/ffcli"
+)
+
+// Exit codes of `uzi ci run` besides 0 for success
+const (
+	ExitFailed  = 1   // at least one agent exited, or failed to checkpoint
+	ExitTimeout = 124 // at least one agent was still working at --timeout, as with timeout(1)
+)
+
+// DefaultDoneMarker is the line agents are asked to print once they are finished
+const DefaultDoneMarker = "UZI_DONE"
+
+// ciTag marks the sessions spawned by `uzi ci run`
+const ciTag = "ci"
+
+var (
+	fs                  = flag.NewFlagSet("uzi ci", flag.ExitOnError)
+	runFs               = flag.NewFlagSet("uzi ci run", flag.ExitOnError)
+	agentsFlag          = runFs.String("agents", "claude:1", "agents to run with their commands and counts (e.g., 'claude:1,codex:2')")
+	promptFile          = runFs.String("prompt-file", "", "file with the task for the agents")
+	promptFlag          = runFs.String("prompt", "", "task for the agents, instead of --prompt-file")
+	timeout             = runFs.Duration("timeout", 30*time.Minute, "how long to wait for the agents to finish")
+	idle                = runFs.Duration("idle", 2*time.Minute, "consider an agent finished once its pane is unchanged and ready for this long")
+	doneMarker          = runFs.String("done-marker", DefaultDoneMarker, "line an agent prints when it is finished; empty relies on idle detection only")
+	checkpointOnSuccess = runFs.Bool("checkpoint-on-success", false, "checkpoint the work of every finished agent onto the current branch")
+	junitPath           = runFs.String("junit", "", "write a JUnit XML report to this file")
+	jsonPath            = runFs.String("json", "", "write a JSON report to this file")
+	keep                = runFs.Bool("keep", false, "leave the agent sessions running instead of killing them at the end")
+	poll                = runFs.Duration("poll", 5*time.Second, "how often to check the agents")
+	configPath          = runFs.String("config", config.GetDefaultConfigPath(), "path to config file")
+	CmdCI               = &ffcli.Command{
+		Name:       "ci",
+		ShortUsage: "uzi ci run [flags]",
+		ShortHelp:  "Run agents unattended in CI pipelines",
+		FlagSet:    fs,
+		Subcommands: []*ffcli.Command{
+			{
+				Name:       "run",
+				ShortUsage: "uzi ci run --prompt-file task.md [--agents claude:1] [--timeout 30m] [--checkpoint-on-success] [--junit results.xml] [--json results.json]",
+				ShortHelp:  "Spawn agents, wait for them to finish, and report the results",
+				LongHelp: `Spawn agents on the task in --prompt-file and wait until each one is
+finished: the agent printed the --done-marker line it is asked to print, or its
+pane has been unchanged and ready for --idle. Agents whose session exits first
+fail. With --checkpoint-on-success, the work of every finished agent is
+checkpointed onto the current branch.
+
+Results are printed and optionally written as JUnit XML and JSON. The sessions
+are killed at the end unless --keep is set. The exit code is 0 when every agent
+finished, 124 when an agent was still working at --timeout, and 1 otherwise.`,
+				FlagSet: runFs,
+				Exec:    executeRun,
+			},
+		},
+		Exec: func(ctx context.Context, args []string) error {
+			return flag.ErrHelp
+		},
+	}
+)
+
+// ExitError is returned when a CI run completes with failures; ExitCode is
+// the process exit code for the pipeline
+type ExitError struct {
+	Code int
+	Err  error
+}
+
+func (e *ExitError) Error() string { return e.Err.Error() }
+func (e *ExitError) Unwrap() error { return e.Err }
+func (e *ExitError) ExitCode() int { return e.Code }
+
+// activeSessions is the part of the state manager a CI run needs
+type activeSessions interface {
+	GetActiveSessionsForRepo() ([]string, error)
+	GetWorktreeInfo(sessionName string) (*state.AgentState, error)
+}
+
+// runner drives a CI run; every dependency is swappable so runs can be tested
+// without git or tmux
+type runner struct {
+	sessions    activeSessions
+	spawn       func(ctx context.Context, opts prompt.SpawnOptions) ([]string, error)
+	paneContent func(sessionName string) (string, error)
+	checkpoint  func(ctx context.Context, agentName, message string) error
+	kill        func(ctx context.Context, agentName string) error
+	now         func() time.Time
+	poll        time.Duration
+	out         io.Writer
+}
+
+// runOptions holds the flags of a CI run
+type runOptions struct {
+	agents     string
+	prompt     string
+	timeout    time.Duration
+	idle       time.Duration
+	doneMarker string
+	checkpoint bool
+	keep       bool
+}
+
+// agentWatch tracks one agent's pane while waiting for it to finish
+type agentWatch struct {
+	paneHash    []byte
+	lastChanged time.Time
+}
+
+func executeRun(ctx context.Context, args []string) error {
+	if len(args) > 0 {
+		return fmt.Errorf("unexpected arguments: %s", strings.Join(args, " "))
+	}
+	task := *promptFlag
+	if *promptFile != "" {
+		data, err := os.ReadFile(*promptFile)
+		if err != nil {
+			return err
+		}
+		task = string(data)
+	}
+	if strings.TrimSpace(task) == "" {
+		return fmt.Errorf("--prompt-file or --prompt is required")
+	}
+	if *timeout <= 0 || *idle <= 0 || *poll <= 0 {
+		return fmt.Errorf("--timeout, --idle, and --poll must be positive")
+	}
+
+	sm, err := cmdctx.From(ctx).State()
+	if err != nil {
+		return err
+	}
+
+	r := &runner{
+		sessions:    sm,
+		spawn:       prompt.Spawn,
+		paneContent: state.DefaultSessionProbe{}.PaneContent,
+		checkpoint: func(ctx context.Context, agentName, message string) error {
+			return runUzi(ctx, "checkpoint", agentName, message)
+		},
+		kill: func(ctx context.Context, agentName string) error {
+			return runUzi(ctx, "kill", agentName)
+		},
+		now:  time.Now,
+		poll: *poll,
+		out:  os.Stdout,
+	}
+
+	report, err := r.run(ctx, runOptions{
+		agents:     *agentsFlag,
+		prompt:     task,
+		timeout:    *timeout,
+		idle:       *idle,
+		doneMarker: *doneMarker,
+		checkpoint: *checkpointOnSuccess,
+		keep:       *keep,
+	})
+	if err != nil {
+		return err
+	}
+
+	if *junitPath != "" {
+		if err := writeReportFile(*junitPath, report.WriteJUnit); err != nil {
+			return err
+		}
+	}
+	if *jsonPath != "" {
+		if err := writeReportFile(*jsonPath, report.WriteJSON); err != nil {
+			return err
+		}
+	}
+	return report.Err()
+}
+
+// runUzi runs a uzi subcommand with this executable
+func runUzi(ctx context.Context, args ...string) error {
+	executable, err := os.Executable()
+	if err != nil {
+		return err
+	}
+	output, err := exec.CommandContext(ctx, executable, args...).CombinedOutput()
+	if err != nil {
+		return fmt.Errorf("uzi %s: %w: %s", args[0], err, strings.TrimSpace(string(output)))
+	}
+	return nil
+}
+
+// writeReportFile creates path and writes a report to it with write
+func writeReportFile(path string, write func(w io.Writer) error) error {
+	f, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+	if err := write(f); err != nil {
+		f.Close()
+		return err
+	}
+	return f.Close()
+}
+
+// run spawns the agents, waits for each to finish, optionally checkpoints
+// them, and returns the results. The error is only set when no agents could
+// be spawned; agent failures are part of the report.
+func (r *runner) run(ctx context.Context, opts runOptions) (*Report, error) {
+	started := r.now()
+	agents, err := r.spawn(ctx, prompt.SpawnOptions{
+		ConfigPath: *configPath,
+		Agents:     opts.agents,
+		Prompt:     agentPrompt(opts.prompt, opts.doneMarker),
+		Tags:       []string{ciTag},
+	})
+	if err == nil && len(agents) == 0 {
+		err = fmt.Errorf("no agents could be spawned")
+	}
+	if err != nil {
+		return nil, err
+	}
+	fmt.Fprintf(r.out, "Spawned %s; waiting up to %s\n", strings.Join(agents, ", "), opts.timeout)
+
+	waitCtx, cancel := context.WithTimeout(ctx, opts.timeout)
+	defer cancel()
+	results := r.wait(waitCtx, agents, opts, started)
+
+	for i := range results {
+		result := &results[i]
+		if info, err := r.sessionState(result.Agent); err == nil {
+			result.Branch = info.BranchName
+		}
+		if opts.checkpoint && result.Status == StatusPassed {
+			message := fmt.Sprintf("Checkpoint %s from uzi ci", result.Agent)
+			if err := r.checkpoint(ctx, result.Agent, message); err != nil {
+				result.Status = StatusFailed
+				result.Reason = fmt.Sprintf("checkpoint failed: %v", err)
+			} else {
+				result.Checkpointed = true
+			}
+		}
+		fmt.Fprintf(r.out, "%s: %s (%s)\n", result.Agent, result.Status, result.Reason)
+	}
+
+	if !opts.keep {
+		for _, agent := range agents {
+			if err := r.kill(ctx, agent); err != nil {
+				log.Warn("Failed to kill agent session", "agent", agent, "error", err)
+			}
+		}
+	}
+
+	return &Report{StartedAt: started, Duration: r.now().Sub(started), Results: results}, nil
+}
+
+// wait polls the agents until every one has finished, exited, or ctx expired
+func (r *runner) wait(ctx context.Context, agents []string, opts runOptions, started time.Time) []Result {
+	results := make(map[string]Result, len(agents))
+	watches := make(map[string]*agentWatch, len(agents))
+	finish := func(agent, status, reason string) {
+		results[agent] = Result{Agent: agent, Status: status, Reason: reason, Duration: r.now().Sub(started)}
+	}
+
+	ticker := time.NewTicker(r.poll)
+	defer ticker.Stop()
+
+	for len(results) < len(agents) {
+		active, err := r.activeAgents()
+		if err != nil {
+			log.Warn("Could not check agent sessions", "error", err)
+		}
+		for _, agent := range agents {
+			if _, done := results[agent]; done || err != nil {
+				continue
+			}
+			sessionName, ok := active[agent]
+			if !ok {
+				finish(agent, StatusFailed, "session exited before finishing")
+				continue
+			}
+			content, paneErr := r.paneContent(sessionName)
+			if paneErr != nil {
+				continue
+			}
+			if hasDoneMarker(content, opts.doneMarker) {
+				finish(agent, StatusPassed, "printed "+opts.doneMarker)
+				continue
+			}
+			if r.idleFor(watches, agent, content) >= opts.idle && state.AgentStatusFromPane(content) == "ready" {
+				finish(agent, StatusPassed, fmt.Sprintf("idle for %s", opts.idle))
+			}
+		}
+		if len(results) == len(agents) {
+			break
+		}
+
+		select {
+		case <-ctx.Done():
+			for _, agent := range agents {
+				if _, done := results[agent]; !done {
+					finish(agent, StatusTimeout, fmt.Sprintf("still working after %s", opts.timeout))
+				}
+			}
+		case <-ticker.C:
+		}
+	}
+
+	ordered := make([]Result, 0, len(agents))
+	for _, agent := range agents {
+		ordered = append(ordered, results[agent])
+	}
+	return ordered
+}
+
+// idleFor returns how long the agent's pane content has been unchanged
+func (r *runner) idleFor(watches map[string]*agentWatch, agent, content string) time.Duration {
+	hash := sha256.Sum256([]byte(content))
+	now := r.now()
+	watch, ok := watches[agent]
+	if !ok || !bytes.Equal(watch.paneHash, hash[:]) {
+		watches[agent] = &agentWatch{paneHash: hash[:], lastChanged: now}
+		return 0
+	}
+	return now.Sub(watch.lastChanged)
+}
+
+// activeAgents maps the agent names of the repository's live sessions to their session names
+func (r *runner) activeAgents() (map[string]string, error) {
+	sessions, err := r.sessions.GetActiveSessionsForRepo()
+	if err != nil {
+		return nil, err
+	}
+	active := make(map[string]string, len(sessions))
+	for _, sessionName := range sessions {
+		active[state.AgentNameFromSession(sessionName)] = sessionName
+	}
+	return active, nil
+}
+
+// sessionState returns the state of the agent's session
+func (r *runner) sessionState(agent string) (*state.AgentState, error) {
+	active, err := r.activeAgents()
+	if err != nil {
+		return nil, err
+	}
+	sessionName, ok := active[agent]
+	if !ok {
+		return nil, fmt.Errorf("no active session found for agent: %s", agent)
+	}
+	return r.sessions.GetWorktreeInfo(sessionName)
+}
+
+// agentPrompt turns the task into a single-line prompt that asks the agent to
+// print the done marker when it is finished. The prompt is typed into the
+// agent pane's shell inside quotes, so newlines are folded into spaces and
+// quotes and characters the shell would expand are dropped.
+func agentPrompt(task, marker string) string {
+	if marker != "" {
+		task += fmt.Sprintf("\n\nWhen you have completely finished, print %s on a line by itself.", marker)
+	}
+	task = strings.Map(func(r rune) rune {
+		switch r {
+		case '\'', '"', '`', '$', '\\':
+			return -1
+		}
+		return r
+	}, task)
+	return strings.Join(strings.Fields(task), " ")
+}
+
+// hasDoneMarker reports whether a line of the pane consists of the marker,
+// ignoring the bullet agents put in front of their messages. The prompt
+// mentions the marker too, but never on a line of its own.
+func hasDoneMarker(content, marker string) bool {
+	if marker == "" {
+		return false
+	}
+	for _, line := range strings.Split(content, "\n") {
+		line = strings.TrimSpace(strings.TrimLeft(strings.TrimSpace(line), "⏺●•*>-"))
+		if line == marker {
+			return true
+		}
+	}
+	return false
+}
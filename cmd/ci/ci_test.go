@@ -0,0 +1,237 @@
+package ci
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/nehpz/claudicus/cmd/prompt"
+	"github.com/nehpz/claudicus/pkg/state"
+)
+
+// fakeSessions keeps agent sessions in memory, keyed by session name
+type fakeSessions struct {
+	mu     sync.Mutex
+	states map[string]state.AgentState
+}
+
+func (f *fakeSessions) GetActiveSessionsForRepo() ([]string, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	var names []string
+	for name := range f.states {
+		names = append(names, name)
+	}
+	return names, nil
+}
+
+func (f *fakeSessions) GetWorktreeInfo(sessionName string) (*state.AgentState, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	s, ok := f.states[sessionName]
+	if !ok {
+		return nil, errors.New("no state found")
+	}
+	return &s, nil
+}
+
+// newTestRunner spawns the given agents and serves each pane from panes,
+// a function of the number of polls so far. The clock advances a minute on
+// every reading.
+func newTestRunner(agents []string, panes func(agent string, poll int) string) (*runner, *[]string, *[]string, *bytes.Buffer) {
+	sessions := &fakeSessions{states: map[string]state.AgentState{}}
+	polls := map[string]int{}
+	clock := time.Date(2025, 1, 1, 12, 0, 0, 0, time.UTC)
+	var checkpointed, killed []string
+	out := &bytes.Buffer{}
+	r := &runner{
+		sessions: sessions,
+		spawn: func(ctx context.Context, opts prompt.SpawnOptions) ([]string, error) {
+			for _, agent := range agents {
+				sessions.states["agent-app-abc123-"+agent] = state.AgentState{BranchName: agent + "-branch", Tags: opts.Tags}
+			}
+			return agents, nil
+		},
+		paneContent: func(sessionName string) (string, error) {
+			agent := state.AgentNameFromSession(sessionName)
+			polls[agent]++
+			return panes(agent, polls[agent]), nil
+		},
+		checkpoint: func(ctx context.Context, agentName, message string) error {
+			checkpointed = append(checkpointed, agentName)
+			if agentName == "broken" {
+				return errors.New("rebase conflict")
+			}
+			return nil
+		},
+		kill: func(ctx context.Context, agentName string) error {
+			killed = append(killed, agentName)
+			return nil
+		},
+		now: func() time.Time {
+			clock = clock.Add(time.Minute)
+			return clock
+		},
+		poll: time.Millisecond,
+		out:  out,
+	}
+	return r, &checkpointed, &killed, out
+}
+
+func testOptions() runOptions {
+	return runOptions{agents: "claude:2", prompt: "fix the build", timeout: time.Minute, idle: 2 * time.Minute, doneMarker: DefaultDoneMarker, checkpoint: true}
+}
+
+func TestRunFinishedAgents(t *testing.T) {
+	r, checkpointed, killed, _ := newTestRunner([]string{"sarah", "emily"}, func(agent string, poll int) string {
+		if agent == "sarah" {
+			return fmt.Sprintf("⏺ Fixed it\n⏺ %s\n", DefaultDoneMarker)
+		}
+		// emily stops changing after the third poll
+		if poll < 3 {
+			return fmt.Sprintf("esc to interrupt %d", poll)
+		}
+		return "> "
+	})
+
+	report, err := r.run(context.Background(), testOptions())
+	if err != nil {
+		t.Fatalf("run() error = %v", err)
+	}
+	if err := report.Err(); err != nil {
+		t.Errorf("Expected every agent to pass, got %v", err)
+	}
+	sarah, emily := report.Results[0], report.Results[1]
+	if sarah.Status != StatusPassed || sarah.Reason != "printed UZI_DONE" || sarah.Branch != "sarah-branch" || !sarah.Checkpointed {
+		t.Errorf("Unexpected result for sarah: %+v", sarah)
+	}
+	if emily.Status != StatusPassed || !strings.HasPrefix(emily.Reason, "idle") {
+		t.Errorf("Unexpected result for emily: %+v", emily)
+	}
+	if len(*checkpointed) != 2 || len(*killed) != 2 {
+		t.Errorf("Expected both agents checkpointed and killed, got %v and %v", *checkpointed, *killed)
+	}
+}
+
+func TestRunFailures(t *testing.T) {
+	r, _, killed, _ := newTestRunner([]string{"broken", "busy"}, func(agent string, poll int) string {
+		if agent == "broken" {
+			return DefaultDoneMarker
+		}
+		return fmt.Sprintf("esc to interrupt %d", poll)
+	})
+	opts := testOptions()
+	opts.timeout = 20 * time.Millisecond
+	opts.keep = true
+
+	report, err := r.run(context.Background(), opts)
+	if err != nil {
+		t.Fatalf("run() error = %v", err)
+	}
+	broken, busy := report.Results[0], report.Results[1]
+	if broken.Status != StatusFailed || !strings.Contains(broken.Reason, "rebase conflict") || broken.Checkpointed {
+		t.Errorf("Expected the failed checkpoint to fail the agent, got %+v", broken)
+	}
+	if busy.Status != StatusTimeout {
+		t.Errorf("Expected busy to time out, got %+v", busy)
+	}
+
+	var exitErr *ExitError
+	if !errors.As(report.Err(), &exitErr) || exitErr.ExitCode() != ExitTimeout {
+		t.Errorf("Expected the timeout exit code, got %v", report.Err())
+	}
+	if len(*killed) != 0 {
+		t.Errorf("Expected --keep to leave the sessions, got %v killed", *killed)
+	}
+}
+
+func TestRunExitedSession(t *testing.T) {
+	r, _, _, _ := newTestRunner([]string{"sarah"}, func(agent string, poll int) string { return "esc to interrupt" })
+	sessions := r.sessions.(*fakeSessions)
+	r.paneContent = func(sessionName string) (string, error) {
+		sessions.mu.Lock()
+		delete(sessions.states, sessionName)
+		sessions.mu.Unlock()
+		return "", errors.New("can't find session")
+	}
+
+	report, err := r.run(context.Background(), testOptions())
+	if err != nil {
+		t.Fatalf("run() error = %v", err)
+	}
+	if report.Results[0].Status != StatusFailed {
+		t.Errorf("Expected the exited agent to fail, got %+v", report.Results[0])
+	}
+	var exitErr *ExitError
+	if !errors.As(report.Err(), &exitErr) || exitErr.ExitCode() != ExitFailed {
+		t.Errorf("Expected the failure exit code, got %v", report.Err())
+	}
+}
+
+func TestAgentPrompt(t *testing.T) {
+	got := agentPrompt("Fix the \"login\" form\n\n- run `make test`\n", DefaultDoneMarker)
+	want := "Fix the login form - run make test When you have completely finished, print UZI_DONE on a line by itself."
+	if got != want {
+		t.Errorf("agentPrompt() = %q, want %q", got, want)
+	}
+	if agentPrompt("task", "") != "task" {
+		t.Error("Expected no marker instruction without a marker")
+	}
+}
+
+func TestHasDoneMarker(t *testing.T) {
+	tests := map[string]bool{
+		"⏺ UZI_DONE":                         true,
+		"  UZI_DONE  ":                       true,
+		"> fix it. print UZI_DONE when done": false,
+		"UZI_DONE_NOT":                       false,
+	}
+	for content, want := range tests {
+		if got := hasDoneMarker("output\n"+content+"\n", DefaultDoneMarker); got != want {
+			t.Errorf("hasDoneMarker(%q) = %v, want %v", content, got, want)
+		}
+	}
+	if hasDoneMarker("UZI_DONE", "") {
+		t.Error("Expected no marker to never match")
+	}
+}
+
+func TestReportOutputs(t *testing.T) {
+	report := &Report{
+		StartedAt: time.Date(2025, 1, 1, 12, 0, 0, 0, time.UTC),
+		Duration:  90 * time.Second,
+		Results: []Result{
+			{Agent: "sarah", Branch: "sarah-branch", Status: StatusPassed, Duration: time.Minute},
+			{Agent: "emily", Status: StatusFailed, Reason: "session exited before finishing"},
+			{Agent: "liam", Status: StatusTimeout, Reason: "still working after 30m0s"},
+		},
+	}
+
+	var junit bytes.Buffer
+	if err := report.WriteJUnit(&junit); err != nil {
+		t.Fatal(err)
+	}
+	for _, want := range []string{
+		`<testsuite name="uzi ci" tests="3" failures="1" errors="1" time="90.000" timestamp="2025-01-01T12:00:00Z">`,
+		`<testcase name="sarah" classname="uzi.ci" time="60.000">`,
+		`<failure message="session exited before finishing" type="failed"></failure>`,
+		`<error message="still working after 30m0s" type="timeout"></error>`,
+	} {
+		if !strings.Contains(junit.String(), want) {
+			t.Errorf("Expected %q in JUnit report:\n%s", want, junit.String())
+		}
+	}
+
+	var data bytes.Buffer
+	if err := report.WriteJSON(&data); err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(data.String(), `"status": "timeout"`) {
+		t.Errorf("Unexpected JSON report: %s", data.String())
+	}
+}
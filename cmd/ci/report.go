@@ -0,0 +1,132 @@
+package ci
+
+import (
+	"encoding/json"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"strings"
+	"time"
+)
+
+// Statuses of an agent in a CI run
+const (
+	StatusPassed  = "passed"
+	StatusFailed  = "failed"
+	StatusTimeout = "timeout"
+)
+
+// Result is the outcome of one agent in a CI run
+type Result struct {
+	Agent        string        `json:"agent"`
+	Branch       string        `json:"branch,omitempty"`
+	Status       string        `json:"status"`
+	Reason       string        `json:"reason"`
+	Duration     time.Duration `json:"duration"`
+	Checkpointed bool          `json:"checkpointed"`
+}
+
+// Report is the outcome of a CI run
+type Report struct {
+	StartedAt time.Time     `json:"started_at"`
+	Duration  time.Duration `json:"duration"`
+	Results   []Result      `json:"results"`
+}
+
+// Err returns nil when every agent passed, or an ExitError with ExitTimeout
+// if any agent timed out and ExitFailed otherwise
+func (r *Report) Err() error {
+	var failed []string
+	code := 0
+	for _, result := range r.Results {
+		switch result.Status {
+		case StatusPassed:
+			continue
+		case StatusTimeout:
+			code = ExitTimeout
+		default:
+			if code == 0 {
+				code = ExitFailed
+			}
+		}
+		failed = append(failed, fmt.Sprintf("%s %s", result.Agent, result.Status))
+	}
+	if code == 0 {
+		return nil
+	}
+	return &ExitError{Code: code, Err: fmt.Errorf("%d of %d agents did not pass: %s", len(failed), len(r.Results), strings.Join(failed, ", "))}
+}
+
+// WriteJSON writes the report as indented JSON
+func (r *Report) WriteJSON(w io.Writer) error {
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	return enc.Encode(r)
+}
+
+type junitSuite struct {
+	XMLName   xml.Name    `xml:"testsuite"`
+	Name      string      `xml:"name,attr"`
+	Tests     int         `xml:"tests,attr"`
+	Failures  int         `xml:"failures,attr"`
+	Errors    int         `xml:"errors,attr"`
+	Time      string      `xml:"time,attr"`
+	Timestamp string      `xml:"timestamp,attr"`
+	Cases     []junitCase `xml:"testcase"`
+}
+
+type junitCase struct {
+	Name      string        `xml:"name,attr"`
+	ClassName string        `xml:"classname,attr"`
+	Time      string        `xml:"time,attr"`
+	Failure   *junitFailure `xml:"failure,omitempty"`
+	Error     *junitFailure `xml:"error,omitempty"`
+	SystemOut string        `xml:"system-out,omitempty"`
+}
+
+type junitFailure struct {
+	Message string `xml:"message,attr"`
+	Type    string `xml:"type,attr"`
+}
+
+// WriteJUnit writes the report as a JUnit XML test suite with one test case
+// per agent. Failed agents are failures and timed out agents are errors.
+func (r *Report) WriteJUnit(w io.Writer) error {
+	suite := junitSuite{
+		Name:      "uzi ci",
+		Tests:     len(r.Results),
+		Time:      seconds(r.Duration),
+		Timestamp: r.StartedAt.UTC().Format(time.RFC3339),
+	}
+	for _, result := range r.Results {
+		c := junitCase{Name: result.Agent, ClassName: "uzi.ci", Time: seconds(result.Duration)}
+		if result.Branch != "" {
+			c.SystemOut = "branch: " + result.Branch
+		}
+		switch result.Status {
+		case StatusFailed:
+			suite.Failures++
+			c.Failure = &junitFailure{Message: result.Reason, Type: result.Status}
+		case StatusTimeout:
+			suite.Errors++
+			c.Error = &junitFailure{Message: result.Reason, Type: result.Status}
+		}
+		suite.Cases = append(suite.Cases, c)
+	}
+
+	if _, err := io.WriteString(w, xml.Header); err != nil {
+		return err
+	}
+	enc := xml.NewEncoder(w)
+	enc.Indent("", "  ")
+	if err := enc.Encode(suite); err != nil {
+		return err
+	}
+	_, err := io.WriteString(w, "\n")
+	return err
+}
+
+// seconds formats a duration as JUnit does, in seconds with millisecond precision
+func seconds(d time.Duration) string {
+	return fmt.Sprintf("%.3f", d.Seconds())
+}
@@ -0,0 +1,254 @@
+package completion
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"sort"
+	"strings"
+
+	"github.com/nehpz/claudicus/pkg/cmdctx"
+	"github.com/nehpz/claudicus/pkg/state"
+
+	"github.com/peterbourgon/ff/v3/ffcli"
+)
+
+var (
+	fs            = flag.NewFlagSet("uzi completion", flag.ExitOnError)
+	CmdCompletion = &ffcli.Command{
+		Name:       "completion",
+		ShortUsage: "uzi completion bash|zsh|fish",
+		ShortHelp:  "Generate shell completion scripts",
+		LongHelp: `Generate a completion script for the given shell and print it to stdout.
+
+Examples:
+  source <(uzi completion bash)
+  uzi completion zsh > "${fpath[1]}/_uzi"
+  uzi completion fish > ~/.config/fish/completions/uzi.fish
+
+Agent names are completed dynamically from the active sessions in state.`,
+		FlagSet: fs,
+		Exec:    executeCompletion,
+	}
+
+	// subcommands is the command tree the scripts are generated from.
+	// It is set by main via SetSubcommands to avoid an import cycle.
+	subcommands []*ffcli.Command
+)
+
+// agentCommands lists subcommands that take an agent name as a positional
+// argument; for grep it follows the pattern
+var agentCommands = map[string]bool{
+	"kill":       true,
+	"checkpoint": true,
+	"nudge":      true,
+	"pause":      true,
+	"resume":     true,
+	"diff":       true,
+	"attach":     true,
+	"watch":      true,
+	"status":     true,
+	"grep":       true,
+	"todos":      true,
+}
+
+// SetSubcommands registers the top-level commands used to generate completion scripts
+func SetSubcommands(cmds []*ffcli.Command) {
+	subcommands = cmds
+}
+
+func executeCompletion(ctx context.Context, args []string) error {
+	if len(args) == 0 {
+		return fmt.Errorf("shell argument is required (bash, zsh, or fish)")
+	}
+
+	switch args[0] {
+	case "agents":
+		// Hidden helper invoked by the generated scripts for dynamic completion
+		prefix := ""
+		if len(args) > 1 {
+			prefix = args[1]
+		}
+		sm, err := cmdctx.From(ctx).State()
+		if err != nil {
+			return err
+		}
+		return printAgentNames(os.Stdout, state.NewCompleter(sm), prefix)
+	default:
+		return writeScript(os.Stdout, args[0], commandSpecs(subcommands))
+	}
+}
+
+// printAgentNames writes one matching agent name per line
+func printAgentNames(w io.Writer, completer *state.Completer, prefix string) error {
+	names, err := completer.AgentNames(prefix)
+	if err != nil {
+		return err
+	}
+	for _, name := range names {
+		fmt.Fprintln(w, name)
+	}
+	return nil
+}
+
+// commandSpec describes a subcommand for script generation
+type commandSpec struct {
+	name      string
+	help      string
+	flags     []string
+	takesName bool
+}
+
+// commandSpecs flattens ffcli commands into sorted specs with their flag names
+func commandSpecs(cmds []*ffcli.Command) []commandSpec {
+	specs := make([]commandSpec, 0, len(cmds)+1)
+	for _, cmd := range cmds {
+		spec := commandSpec{
+			name:      cmd.Name,
+			help:      cmd.ShortHelp,
+			takesName: agentCommands[cmd.Name],
+		}
+		if cmd.FlagSet != nil {
+			cmd.FlagSet.VisitAll(func(f *flag.Flag) {
+				spec.flags = append(spec.flags, "-"+f.Name)
+			})
+		}
+		specs = append(specs, spec)
+	}
+	sort.Slice(specs, func(i, j int) bool {
+		return specs[i].name < specs[j].name
+	})
+	return specs
+}
+
+// writeScript writes the completion script for the requested shell
+func writeScript(w io.Writer, shell string, specs []commandSpec) error {
+	switch shell {
+	case "bash":
+		return writeBash(w, specs)
+	case "zsh":
+		return writeZsh(w, specs)
+	case "fish":
+		return writeFish(w, specs)
+	default:
+		return fmt.Errorf("unsupported shell: %s (expected bash, zsh, or fish)", shell)
+	}
+}
+
+func commandNames(specs []commandSpec) []string {
+	names := make([]string, len(specs))
+	for i, spec := range specs {
+		names[i] = spec.name
+	}
+	return names
+}
+
+func writeBash(w io.Writer, specs []commandSpec) error {
+	var b strings.Builder
+	b.WriteString("# bash completion for uzi\n")
+	b.WriteString("_uzi_completion() {\n")
+	b.WriteString("    local cur=\"${COMP_WORDS[COMP_CWORD]}\"\n")
+	b.WriteString("    if [ \"$COMP_CWORD\" -eq 1 ]; then\n")
+	fmt.Fprintf(&b, "        COMPREPLY=( $(compgen -W \"%s\" -- \"$cur\") )\n", strings.Join(commandNames(specs), " "))
+	b.WriteString("        return\n")
+	b.WriteString("    fi\n")
+	b.WriteString("    local opts=\"\"\n")
+	b.WriteString("    case \"${COMP_WORDS[1]}\" in\n")
+	for _, spec := range specs {
+		fmt.Fprintf(&b, "        %s)\n", spec.name)
+		fmt.Fprintf(&b, "            opts=\"%s\"\n", strings.Join(spec.flags, " "))
+		if spec.takesName {
+			b.WriteString("            if [[ \"$cur\" != -* ]]; then\n")
+			b.WriteString("                opts=\"$(uzi completion agents 2>/dev/null)")
+			if spec.name == "kill" {
+				b.WriteString(" all")
+			}
+			b.WriteString("\"\n")
+			b.WriteString("            fi\n")
+		}
+		b.WriteString("            ;;\n")
+	}
+	b.WriteString("    esac\n")
+	b.WriteString("    COMPREPLY=( $(compgen -W \"$opts\" -- \"$cur\") )\n")
+	b.WriteString("}\n")
+	b.WriteString("complete -F _uzi_completion uzi\n")
+
+	_, err := io.WriteString(w, b.String())
+	return err
+}
+
+func writeZsh(w io.Writer, specs []commandSpec) error {
+	var b strings.Builder
+	b.WriteString("#compdef uzi\n\n")
+	b.WriteString("_uzi() {\n")
+	b.WriteString("    local -a subcmds\n")
+	b.WriteString("    subcmds=(\n")
+	for _, spec := range specs {
+		fmt.Fprintf(&b, "        '%s:%s'\n", spec.name, zshEscape(spec.help))
+	}
+	b.WriteString("    )\n")
+	b.WriteString("    if (( CURRENT == 2 )); then\n")
+	b.WriteString("        _describe 'command' subcmds\n")
+	b.WriteString("        return\n")
+	b.WriteString("    fi\n")
+	b.WriteString("    case $words[2] in\n")
+	for _, spec := range specs {
+		fmt.Fprintf(&b, "        %s)\n", spec.name)
+		if spec.takesName {
+			b.WriteString("            if [[ $PREFIX != -* ]]; then\n")
+			b.WriteString("                compadd -- ${(f)\"$(uzi completion agents 2>/dev/null)\"}")
+			if spec.name == "kill" {
+				b.WriteString(" all")
+			}
+			b.WriteString("\n")
+			b.WriteString("                return\n")
+			b.WriteString("            fi\n")
+		}
+		if len(spec.flags) > 0 {
+			fmt.Fprintf(&b, "            compadd -- %s\n", strings.Join(spec.flags, " "))
+		}
+		b.WriteString("            ;;\n")
+	}
+	b.WriteString("    esac\n")
+	b.WriteString("}\n\n")
+	b.WriteString("compdef _uzi uzi\n")
+
+	_, err := io.WriteString(w, b.String())
+	return err
+}
+
+func writeFish(w io.Writer, specs []commandSpec) error {
+	var b strings.Builder
+	b.WriteString("# fish completion for uzi\n")
+	b.WriteString("complete -c uzi -f\n")
+	for _, spec := range specs {
+		fmt.Fprintf(&b, "complete -c uzi -n __fish_use_subcommand -a %s -d '%s'\n", spec.name, fishEscape(spec.help))
+	}
+	for _, spec := range specs {
+		condition := fmt.Sprintf("'__fish_seen_subcommand_from %s'", spec.name)
+		for _, f := range spec.flags {
+			fmt.Fprintf(&b, "complete -c uzi -n %s -o %s\n", condition, strings.TrimPrefix(f, "-"))
+		}
+		if spec.takesName {
+			agents := "(uzi completion agents 2>/dev/null)"
+			if spec.name == "kill" {
+				agents += " all"
+			}
+			fmt.Fprintf(&b, "complete -c uzi -n %s -a '%s'\n", condition, agents)
+		}
+	}
+
+	_, err := io.WriteString(w, b.String())
+	return err
+}
+
+func zshEscape(s string) string {
+	s = strings.ReplaceAll(s, "'", "'\\''")
+	return strings.ReplaceAll(s, ":", "\\:")
+}
+
+func fishEscape(s string) string {
+	return strings.ReplaceAll(s, "'", "\\'")
+}
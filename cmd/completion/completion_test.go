@@ -0,0 +1,139 @@
+package completion
+
+import (
+	"bytes"
+	"context"
+	"flag"
+	"strings"
+	"testing"
+
+	"github.com/peterbourgon/ff/v3/ffcli"
+)
+
+func testCommands() []*ffcli.Command {
+	lsFlags := flag.NewFlagSet("uzi ls", flag.ContinueOnError)
+	lsFlags.Bool("json", false, "output in JSON format")
+	lsFlags.Bool("a", false, "show all sessions")
+
+	return []*ffcli.Command{
+		{Name: "ls", ShortHelp: "List active agent sessions", FlagSet: lsFlags},
+		{Name: "kill", ShortHelp: "Delete agent's session", FlagSet: flag.NewFlagSet("uzi kill", flag.ContinueOnError)},
+		{Name: "checkpoint", ShortHelp: "Rebase agent changes", FlagSet: flag.NewFlagSet("uzi checkpoint", flag.ContinueOnError)},
+	}
+}
+
+func TestCommandSpecs(t *testing.T) {
+	specs := commandSpecs(testCommands())
+
+	if len(specs) != 3 {
+		t.Fatalf("Expected 3 specs, got %d", len(specs))
+	}
+
+	// Specs are sorted by name
+	if specs[0].name != "checkpoint" || specs[1].name != "kill" || specs[2].name != "ls" {
+		t.Errorf("Unexpected spec order: %v", commandNames(specs))
+	}
+
+	if !specs[0].takesName || !specs[1].takesName || specs[2].takesName {
+		t.Error("Expected only kill and checkpoint to take agent names")
+	}
+
+	if strings.Join(specs[2].flags, " ") != "-a -json" {
+		t.Errorf("Expected ls flags '-a -json', got %v", specs[2].flags)
+	}
+}
+
+func TestAgentCommands(t *testing.T) {
+	for _, name := range []string{"kill", "checkpoint", "nudge", "pause", "resume", "diff", "attach", "watch", "status", "grep", "todos"} {
+		if !agentCommands[name] {
+			t.Errorf("Expected %s to complete agent names", name)
+		}
+	}
+	for _, name := range []string{"ls", "prompt", "completion"} {
+		if agentCommands[name] {
+			t.Errorf("Expected %s not to complete agent names", name)
+		}
+	}
+
+	specs := commandSpecs([]*ffcli.Command{{Name: "watch"}, {Name: "grep"}})
+	for _, spec := range specs {
+		if !spec.takesName {
+			t.Errorf("Expected the %s spec to take agent names", spec.name)
+		}
+	}
+}
+
+func TestWriteScript(t *testing.T) {
+	specs := commandSpecs(testCommands())
+
+	tests := []struct {
+		shell    string
+		contains []string
+	}{
+		{
+			shell: "bash",
+			contains: []string{
+				"complete -F _uzi_completion uzi",
+				`compgen -W "checkpoint kill ls"`,
+				`opts="-a -json"`,
+				"uzi completion agents 2>/dev/null) all",
+			},
+		},
+		{
+			shell: "zsh",
+			contains: []string{
+				"#compdef uzi",
+				"'ls:List active agent sessions'",
+				"compadd -- -a -json",
+				"uzi completion agents 2>/dev/null",
+			},
+		},
+		{
+			shell: "fish",
+			contains: []string{
+				"complete -c uzi -n __fish_use_subcommand -a ls -d 'List active agent sessions'",
+				"complete -c uzi -n '__fish_seen_subcommand_from ls' -o json",
+				"complete -c uzi -n '__fish_seen_subcommand_from kill' -a '(uzi completion agents 2>/dev/null) all'",
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.shell, func(t *testing.T) {
+			var buf bytes.Buffer
+			if err := writeScript(&buf, tt.shell, specs); err != nil {
+				t.Fatalf("writeScript(%s) error = %v", tt.shell, err)
+			}
+			script := buf.String()
+			for _, want := range tt.contains {
+				if !strings.Contains(script, want) {
+					t.Errorf("%s script missing %q\n%s", tt.shell, want, script)
+				}
+			}
+		})
+	}
+}
+
+func TestWriteScriptUnsupportedShell(t *testing.T) {
+	var buf bytes.Buffer
+	err := writeScript(&buf, "powershell", nil)
+	if err == nil || !strings.Contains(err.Error(), "unsupported shell") {
+		t.Errorf("Expected unsupported shell error, got %v", err)
+	}
+}
+
+func TestExecuteCompletionRequiresShell(t *testing.T) {
+	err := executeCompletion(context.Background(), []string{})
+	if err == nil || !strings.Contains(err.Error(), "shell argument is required") {
+		t.Errorf("Expected shell argument error, got %v", err)
+	}
+}
+
+func TestCmdCompletion(t *testing.T) {
+	if CmdCompletion.Name != "completion" {
+		t.Errorf("CmdCompletion.Name = %v, want completion", CmdCompletion.Name)
+	}
+	if CmdCompletion.Exec == nil {
+		t.Error("CmdCompletion.Exec should not be nil")
+	}
+}
@@ -0,0 +1,140 @@
+package diff
+
+import (
+	"context"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"github.com/nehpz/claudicus/pkg/cmdctx"
+	"github.com/nehpz/claudicus/pkg/hosts"
+	"github.com/nehpz/claudicus/pkg/state"
+
+	"github.com/peterbourgon/ff/v3/ffcli"
+)
+
+var (
+	fs         = flag.NewFlagSet("uzi diff", flag.ExitOnError)
+	statOnly   = fs.Bool("stat", false, "show changed files with insert/delete counts instead of the patch")
+	jsonOutput = fs.Bool("json", false, "output the per-file breakdown in JSON format")
+	CmdDiff    = &ffcli.Command{
+		Name:       "diff",
+		ShortUsage: "uzi diff [--stat] [--json] <agent-name>",
+		ShortHelp:  "Show an agent's changes against its base commit",
+		LongHelp: `Without flags, diff prints the patch of everything the agent changed in its
+worktree, untracked files included. --stat lists each changed file with its
+insertions and deletions instead, like git diff --stat, and --json prints the
+same breakdown as JSON. Sessions on remote hosts are diffed over ssh.`,
+		FlagSet: fs,
+		Exec:    executeDiff,
+	}
+)
+
+// barWidth is the widest +/- bar of --stat
+const barWidth = 40
+
+// changeLetters abbreviates change types like git's --name-status
+var changeLetters = map[string]string{
+	state.ChangeAdded:    "A",
+	state.ChangeModified: "M",
+	state.ChangeDeleted:  "D",
+}
+
+func executeDiff(ctx context.Context, args []string) error {
+	if len(args) == 0 {
+		return fmt.Errorf("agent name argument is required")
+	}
+
+	cc := cmdctx.From(ctx)
+	sm, err := cc.State()
+	if err != nil {
+		return err
+	}
+	asJSON := cc.WantJSON(*jsonOutput)
+	agentState, err := findAgent(sm, args[0])
+	if err != nil {
+		return err
+	}
+
+	if !*statOnly && !asJSON {
+		cmd := hosts.ForState(agentState).Shell(ctx, agentState.WorktreePath, state.PatchScript)
+		cmd.Stdout = os.Stdout
+		cmd.Stderr = os.Stderr
+		if err := cmd.Run(); err != nil {
+			return fmt.Errorf("git diff failed: %w", err)
+		}
+		return nil
+	}
+
+	aggregator := state.NewAggregator(state.WithRemoteProbe(hosts.RemoteProbe))
+	stat, err := aggregator.FileDiffs(agentState)
+	if err != nil {
+		return fmt.Errorf("error getting diff of %s: %w", args[0], err)
+	}
+	if asJSON {
+		encoder := json.NewEncoder(os.Stdout)
+		encoder.SetIndent("", "  ")
+		return encoder.Encode(stat)
+	}
+	printStat(os.Stdout, stat)
+	return nil
+}
+
+// findAgent returns the saved state of the repository's agent with the name
+func findAgent(sm *state.StateManager, agentName string) (state.AgentState, error) {
+	activeSessions, err := sm.GetActiveSessionsForRepo()
+	if err != nil {
+		return state.AgentState{}, fmt.Errorf("error getting active sessions: %w", err)
+	}
+	for _, session := range activeSessions {
+		if state.AgentNameFromSession(session) != agentName {
+			continue
+		}
+		agentState, err := sm.GetWorktreeInfo(session)
+		if err != nil {
+			return state.AgentState{}, err
+		}
+		return *agentState, nil
+	}
+	return state.AgentState{}, fmt.Errorf("no active session found for agent: %s", agentName)
+}
+
+// printStat lists the changed files with a +/- bar each, then the totals
+func printStat(out io.Writer, stat state.DiffStat) {
+	if len(stat.Files) == 0 {
+		fmt.Fprintln(out, "No changes")
+		return
+	}
+
+	pathWidth, countWidth := 0, 0
+	for _, file := range stat.Files {
+		pathWidth = max(pathWidth, len(file.Path))
+		countWidth = max(countWidth, len(fmt.Sprint(file.Insertions+file.Deletions)))
+	}
+
+	largest := stat.Largest()
+	for _, file := range stat.Files {
+		if file.Binary {
+			fmt.Fprintf(out, " %s %-*s | %*s\n", changeLetters[file.Change], pathWidth, file.Path, countWidth, "Bin")
+			continue
+		}
+		plus, minus := state.ScaleBar(file.Insertions, file.Deletions, largest, barWidth)
+		fmt.Fprintf(out, " %s %-*s | %*d %s%s\n", changeLetters[file.Change], pathWidth, file.Path,
+			countWidth, file.Insertions+file.Deletions, strings.Repeat("+", plus), strings.Repeat("-", minus))
+	}
+
+	fmt.Fprintf(out, " %d %s changed, %d %s(+), %d %s(-)\n",
+		len(stat.Files), plural(len(stat.Files), "file", "files"),
+		stat.Insertions(), plural(stat.Insertions(), "insertion", "insertions"),
+		stat.Deletions(), plural(stat.Deletions(), "deletion", "deletions"))
+}
+
+func plural(n int, one, many string) string {
+	if n == 1 {
+		return one
+	}
+	return many
+}
@@ -0,0 +1,37 @@
+package diff
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/nehpz/claudicus/pkg/state"
+)
+
+func TestPrintStat(t *testing.T) {
+	var out bytes.Buffer
+	printStat(&out, state.DiffStat{Files: []state.FileDiff{
+		{Path: "docs/guide.md", Change: state.ChangeAdded, Insertions: 5},
+		{Path: "logo.png", Change: state.ChangeModified, Binary: true},
+		{Path: "main.go", Change: state.ChangeModified, Insertions: 12, Deletions: 3},
+	}})
+
+	want := " A docs/guide.md |  5 +++++\n" +
+		" M logo.png      | Bin\n" +
+		" M main.go       | 15 ++++++++++++---\n" +
+		" 3 files changed, 17 insertions(+), 3 deletions(-)\n"
+	if out.String() != want {
+		t.Errorf("printStat() = %q, want %q", out.String(), want)
+	}
+
+	out.Reset()
+	printStat(&out, state.DiffStat{Files: []state.FileDiff{{Path: "main.go", Change: state.ChangeDeleted, Deletions: 1}}})
+	if want := " D main.go | 1 -\n 1 file changed, 0 insertions(+), 1 deletion(-)\n"; out.String() != want {
+		t.Errorf("printStat() = %q, want %q", out.String(), want)
+	}
+
+	out.Reset()
+	printStat(&out, state.DiffStat{})
+	if out.String() != "No changes\n" {
+		t.Errorf("Unexpected output without changes: %q", out.String())
+	}
+}
@@ -0,0 +1,384 @@
+package digest
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"os/exec"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/nehpz/claudicus/pkg/cmdctx"
+	"github.com/nehpz/claudicus/pkg/heartbeat"
+	"github.com/nehpz/claudicus/pkg/hosts"
+	"github.com/nehpz/claudicus/pkg/scrollback"
+	"github.com/nehpz/claudicus/pkg/state"
+
+	"github.com/charmbracelet/log"
+	"github.com/peterbourgon/ff/v3/ffcli"
+)
+
+var (
+	fs           = flag.NewFlagSet("uzi digest", flag.ExitOnError)
+	since        = fs.Duration("since", 8*time.Hour, "summarize the activity of this long ago until now")
+	format       = fs.String("format", "markdown", "output format: markdown, text, or json")
+	slackWebhook = fs.String("slack-webhook", "", "also post the digest to this Slack incoming webhook URL")
+	CmdDigest    = &ffcli.Command{
+		Name:       "digest",
+		ShortUsage: "uzi digest [--since 8h] [--format markdown|text|json] [--slack-webhook URL]",
+		ShortHelp:  "Summarize what every agent did recently",
+		LongHelp: `The digest command summarizes the activity of every agent of the repository
+over the last --since, for reviewing an overnight run in one go. For each
+agent it shows:
+
+- its status transitions, as recorded by uzi auto with heartbeats enabled
+- its diff, and how much it grew over the period
+- checkpoints and completion within the period
+- errors in its claude transcripts: failed tool calls and API errors
+
+With --slack-webhook, or UZI_SLACK_WEBHOOK, the digest is also posted to a
+Slack incoming webhook. To mail it, pipe it to your mailer, e.g.
+uzi digest | mail -s "Agent digest" me@example.com.`,
+		FlagSet: fs,
+		Exec:    executeDigest,
+	}
+)
+
+// maxErrors is how many transcript errors are listed per agent; the latest are kept
+const maxErrors = 5
+
+// httpClient posts digests to Slack
+var httpClient = &http.Client{Timeout: 10 * time.Second}
+
+// lineCount counts the inserted and deleted lines of a diff
+type lineCount struct {
+	Insertions int `json:"insertions"`
+	Deletions  int `json:"deletions"`
+}
+
+// agentDigest is what one agent did over the period
+type agentDigest struct {
+	Agent   string `json:"agent"`
+	Session string `json:"session"`
+	Model   string `json:"model,omitempty"`
+	// Status is the current status, or gone for sessions no longer in state
+	Status      string                 `json:"status"`
+	Transitions []heartbeat.Transition `json:"transitions,omitempty"`
+	Diff        lineCount              `json:"diff"`
+	// Growth is how much the diff grew over the period; nil when its size at
+	// the start is unknown
+	Growth          *lineCount                   `json:"growth,omitempty"`
+	CheckpointedAt  time.Time                    `json:"checkpointed_at,omitzero"` // set if a checkpoint ran in the period
+	CheckpointError string                       `json:"checkpoint_error,omitempty"`
+	DoneAt          time.Time                    `json:"done_at,omitzero"` // set if the agent finished in the period
+	Errors          []scrollback.TranscriptError `json:"errors,omitempty"`
+}
+
+// digestReport is the activity of every agent over a period
+type digestReport struct {
+	Since  time.Time     `json:"since"`
+	Until  time.Time     `json:"until"`
+	Agents []agentDigest `json:"agents"`
+}
+
+// digestSession is a session in state with what is known about it now
+type digestSession struct {
+	state  state.AgentState
+	status string
+	diff   lineCount
+	errors []scrollback.TranscriptError
+}
+
+const statusGone = "gone"
+
+func executeDigest(ctx context.Context, args []string) error {
+	switch *format {
+	case "markdown", "text", "json":
+	default:
+		return fmt.Errorf("invalid --format %q: must be markdown, text, or json", *format)
+	}
+	if *since <= 0 {
+		return fmt.Errorf("invalid --since %s: must be positive", *since)
+	}
+
+	sm, err := cmdctx.From(ctx).State()
+	if err != nil {
+		return err
+	}
+	states, err := sm.StatesForRepo()
+	if err != nil {
+		return fmt.Errorf("error loading sessions: %w", err)
+	}
+	activeSessions, err := sm.GetActiveSessionsForRepo()
+	if err != nil {
+		return fmt.Errorf("error getting active sessions: %w", err)
+	}
+	alive := make(map[string]bool, len(activeSessions))
+	for _, sessionName := range activeSessions {
+		alive[sessionName] = true
+	}
+	repoRoot, err := exec.CommandContext(ctx, "git", "rev-parse", "--show-toplevel").Output()
+	if err != nil {
+		return fmt.Errorf("uzi digest must run in a git repository: %w", err)
+	}
+	// Earlier transitions give the size of the diffs at the start
+	transitions, err := heartbeat.NewStore(heartbeat.Dir(strings.TrimSpace(string(repoRoot)))).Transitions(time.Time{})
+	if err != nil {
+		return fmt.Errorf("error reading status transitions: %w", err)
+	}
+
+	now := time.Now()
+	start := now.Add(-*since)
+	home, err := os.UserHomeDir()
+	if err != nil {
+		log.Debug("Skipping transcripts", "error", err)
+	}
+	aggregator := state.NewAggregator(state.WithTmuxStatus(), state.WithDiffs(), state.WithRemoteProbe(hosts.RemoteProbe))
+	sessions := make(map[string]digestSession, len(states))
+	for sessionName, agentState := range states {
+		info := aggregator.Session(sessionName, agentState)
+		session := digestSession{
+			state:  agentState,
+			status: info.Status,
+			diff:   lineCount{Insertions: info.Insertions, Deletions: info.Deletions},
+		}
+		if !alive[sessionName] {
+			session.status = state.StatusDead
+		}
+		if home != "" && !agentState.IsRemote() && agentState.WorktreePath != "" {
+			errs, err := scrollback.TranscriptErrors(scrollback.TranscriptDir(home, agentState.WorktreePath), start)
+			if err != nil {
+				log.Warn("Could not read transcripts", "session", sessionName, "error", err)
+			}
+			session.errors = errs
+		}
+		sessions[sessionName] = session
+	}
+
+	report := buildDigest(sessions, transitions, start, now)
+	var out bytes.Buffer
+	switch *format {
+	case "json":
+		encoder := json.NewEncoder(&out)
+		encoder.SetIndent("", "  ")
+		if err := encoder.Encode(report); err != nil {
+			return err
+		}
+	default:
+		writeDigest(&out, report, *format == "markdown")
+	}
+	if _, err := os.Stdout.Write(out.Bytes()); err != nil {
+		return err
+	}
+	if *slackWebhook != "" {
+		if err := postSlack(ctx, *slackWebhook, out.String()); err != nil {
+			return fmt.Errorf("error posting to Slack: %w", err)
+		}
+	}
+	return nil
+}
+
+// buildDigest summarizes the period from start to now. Sessions that left
+// state in the period are listed from their transitions alone.
+func buildDigest(sessions map[string]digestSession, transitions []heartbeat.Transition, start, now time.Time) digestReport {
+	report := digestReport{Since: start, Until: now, Agents: []agentDigest{}}
+
+	bySession := make(map[string][]heartbeat.Transition)
+	for _, transition := range transitions {
+		bySession[transition.Session] = append(bySession[transition.Session], transition)
+	}
+	names := make(map[string]bool)
+	for sessionName := range sessions {
+		names[sessionName] = true
+	}
+	for sessionName, sessionTransitions := range bySession {
+		if !sessionTransitions[len(sessionTransitions)-1].At.Before(start) {
+			names[sessionName] = true
+		}
+	}
+
+	for sessionName := range names {
+		history := bySession[sessionName]
+		agent := agentDigest{Agent: state.AgentNameFromSession(sessionName), Session: sessionName}
+		var baseline *lineCount
+		for _, transition := range history {
+			if transition.At.Before(start) {
+				baseline = &lineCount{Insertions: transition.Insertions, Deletions: transition.Deletions}
+			} else {
+				agent.Transitions = append(agent.Transitions, transition)
+			}
+		}
+
+		session, inState := sessions[sessionName]
+		if inState {
+			agent.Model = session.state.Model
+			agent.Status = session.status
+			agent.Diff = session.diff
+			if !session.state.CheckpointedAt.Before(start) {
+				agent.CheckpointedAt = session.state.CheckpointedAt
+				agent.CheckpointError = session.state.CheckpointError
+			}
+			if session.state.Done && !session.state.DoneAt.Before(start) {
+				agent.DoneAt = session.state.DoneAt
+			}
+			agent.Errors = session.errors
+		} else {
+			last := history[len(history)-1]
+			agent.Status = statusGone
+			agent.Diff = lineCount{Insertions: last.Insertions, Deletions: last.Deletions}
+		}
+
+		// Without a transition before the period, a session started in it
+		// grew from nothing, and the first transition in it is the best guess
+		// for an older one
+		switch {
+		case baseline != nil:
+		case inState && !session.state.CreatedAt.IsZero() && !session.state.CreatedAt.Before(start):
+			baseline = &lineCount{}
+		case len(agent.Transitions) > 0 && agent.Transitions[0].From == "":
+			baseline = &lineCount{}
+		case len(agent.Transitions) > 0:
+			baseline = &lineCount{Insertions: agent.Transitions[0].Insertions, Deletions: agent.Transitions[0].Deletions}
+		}
+		if baseline != nil {
+			agent.Growth = &lineCount{Insertions: agent.Diff.Insertions - baseline.Insertions, Deletions: agent.Diff.Deletions - baseline.Deletions}
+		}
+		report.Agents = append(report.Agents, agent)
+	}
+
+	sort.Slice(report.Agents, func(i, j int) bool { return report.Agents[i].Agent < report.Agents[j].Agent })
+	return report
+}
+
+// writeDigest renders the digest with a section per agent, with markdown
+// headings or as plain text
+func writeDigest(out io.Writer, report digestReport, markdown bool) {
+	heading, section, item := "", "", "  "
+	if markdown {
+		heading, section, item = "# ", "## ", "- "
+	}
+	clock := func(t time.Time) string {
+		t = t.Local()
+		if y, m, d := t.Date(); y == report.Until.Local().Year() && m == report.Until.Local().Month() && d == report.Until.Local().Day() {
+			return t.Format("15:04")
+		}
+		return t.Format("Jan 2 15:04")
+	}
+
+	fmt.Fprintf(out, "%sAgent activity since %s (%s)\n\n", heading, clock(report.Since), formatDuration(report.Until.Sub(report.Since)))
+	if len(report.Agents) == 0 {
+		fmt.Fprintln(out, "No agent activity.")
+		return
+	}
+	done, checkpointed, errors := 0, 0, 0
+	for _, agent := range report.Agents {
+		if !agent.DoneAt.IsZero() {
+			done++
+		}
+		if !agent.CheckpointedAt.IsZero() {
+			checkpointed++
+		}
+		errors += len(agent.Errors)
+	}
+	fmt.Fprintf(out, "%d %s: %d done, %d checkpointed, %d transcript %s\n",
+		len(report.Agents), plural(len(report.Agents), "agent"), done, checkpointed, errors, plural(errors, "error"))
+
+	for _, agent := range report.Agents {
+		title := agent.Agent + " (" + agent.Status + ")"
+		if agent.Model != "" {
+			title = agent.Agent + " (" + agent.Model + ", " + agent.Status + ")"
+		}
+		fmt.Fprintf(out, "\n%s%s\n", section, title)
+		if markdown {
+			fmt.Fprintln(out)
+		}
+
+		if len(agent.Transitions) > 0 {
+			from := agent.Transitions[0].From
+			if from == "" {
+				from = "started"
+			}
+			var line strings.Builder
+			line.WriteString(from)
+			for _, transition := range agent.Transitions {
+				fmt.Fprintf(&line, " → %s %s", transition.To, clock(transition.At))
+			}
+			fmt.Fprintf(out, "%sStatus: %s\n", item, line.String())
+		}
+		diff := fmt.Sprintf("+%d -%d", agent.Diff.Insertions, agent.Diff.Deletions)
+		if agent.Growth != nil {
+			diff += fmt.Sprintf(" (was +%d -%d at the start)", agent.Diff.Insertions-agent.Growth.Insertions, agent.Diff.Deletions-agent.Growth.Deletions)
+		}
+		fmt.Fprintf(out, "%sDiff: %s\n", item, diff)
+		if !agent.CheckpointedAt.IsZero() {
+			if agent.CheckpointError != "" {
+				fmt.Fprintf(out, "%sCheckpoint failed at %s: %s\n", item, clock(agent.CheckpointedAt), agent.CheckpointError)
+			} else {
+				fmt.Fprintf(out, "%sCheckpointed at %s\n", item, clock(agent.CheckpointedAt))
+			}
+		}
+		if !agent.DoneAt.IsZero() {
+			fmt.Fprintf(out, "%sDone at %s\n", item, clock(agent.DoneAt))
+		}
+		if len(agent.Errors) > 0 {
+			fmt.Fprintf(out, "%sErrors: %d\n", item, len(agent.Errors))
+			shown := agent.Errors
+			if len(shown) > maxErrors {
+				shown = shown[len(shown)-maxErrors:]
+				fmt.Fprintf(out, "  %s(%d earlier not shown)\n", item, len(agent.Errors)-maxErrors)
+			}
+			for _, e := range shown {
+				fmt.Fprintf(out, "  %s%s %s\n", item, clock(e.At), e.Text)
+			}
+		}
+	}
+}
+
+// postSlack posts text to a Slack incoming webhook
+func postSlack(ctx context.Context, url, text string) error {
+	body, err := json.Marshal(map[string]string{"text": text})
+	if err != nil {
+		return err
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, url, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	resp, err := httpClient.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode/100 != 2 {
+		message, _ := io.ReadAll(io.LimitReader(resp.Body, 512))
+		return fmt.Errorf("webhook returned %s: %s", resp.Status, strings.TrimSpace(string(message)))
+	}
+	return nil
+}
+
+// formatDuration renders a duration in hours and minutes, e.g. 8h or 1h30m
+func formatDuration(d time.Duration) string {
+	d = d.Round(time.Minute)
+	switch {
+	case d < time.Hour:
+		return fmt.Sprintf("%dm", int(d.Minutes()))
+	case d%time.Hour == 0:
+		return fmt.Sprintf("%dh", int(d.Hours()))
+	default:
+		return fmt.Sprintf("%dh%02dm", int(d.Hours()), int(d.Minutes())%60)
+	}
+}
+
+func plural(n int, word string) string {
+	if n == 1 {
+		return word
+	}
+	return word + "s"
+}
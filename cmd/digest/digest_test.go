@@ -0,0 +1,168 @@
+package digest
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/nehpz/claudicus/pkg/heartbeat"
+	"github.com/nehpz/claudicus/pkg/scrollback"
+	"github.com/nehpz/claudicus/pkg/state"
+)
+
+func TestBuildDigest(t *testing.T) {
+	now := time.Date(2025, 1, 1, 9, 0, 0, 0, time.Local)
+	start := now.Add(-8 * time.Hour)
+	sessions := map[string]digestSession{
+		"agent-app-abc123-sarah": {
+			state:  state.AgentState{Model: "claude", CreatedAt: start.Add(-time.Hour), Done: true, DoneAt: now.Add(-time.Hour), CheckpointedAt: now.Add(-30 * time.Minute)},
+			status: state.StatusDone,
+			diff:   lineCount{Insertions: 120, Deletions: 30},
+			errors: []scrollback.TranscriptError{{At: start.Add(time.Hour), Text: "Exit code 1"}},
+		},
+		"agent-app-abc123-john": {
+			state:  state.AgentState{Model: "codex", CreatedAt: start.Add(time.Hour), CheckpointedAt: start.Add(-time.Hour), CheckpointError: "old failure"},
+			status: state.StatusRunning,
+			diff:   lineCount{Insertions: 40},
+		},
+		"agent-app-abc123-idle": {
+			state:  state.AgentState{CreatedAt: start.Add(-time.Hour)},
+			status: state.StatusReady,
+		},
+	}
+	transitions := []heartbeat.Transition{
+		{Session: "agent-app-abc123-sarah", To: state.StatusRunning, At: start.Add(-time.Hour)},
+		{Session: "agent-app-abc123-sarah", From: state.StatusRunning, To: state.StatusReady, At: start.Add(-time.Minute), Insertions: 20, Deletions: 5},
+		{Session: "agent-app-abc123-sarah", From: state.StatusReady, To: state.StatusRunning, At: start.Add(time.Hour), Insertions: 20, Deletions: 5},
+		{Session: "agent-app-abc123-sarah", From: state.StatusRunning, To: state.StatusDone, At: now.Add(-time.Hour), Insertions: 120, Deletions: 30},
+		{Session: "agent-app-abc123-emily", To: state.StatusRunning, At: start.Add(2 * time.Hour)},
+		{Session: "agent-app-abc123-emily", From: state.StatusRunning, To: state.StatusDead, At: start.Add(3 * time.Hour), Insertions: 7},
+		{Session: "agent-app-abc123-old", To: state.StatusDead, At: start.Add(-time.Hour)},
+	}
+
+	report := buildDigest(sessions, transitions, start, now)
+	var names []string
+	agents := make(map[string]agentDigest)
+	for _, agent := range report.Agents {
+		names = append(names, agent.Agent)
+		agents[agent.Agent] = agent
+	}
+	if strings.Join(names, ",") != "emily,idle,john,sarah" {
+		t.Fatalf("Expected the agents in state and those seen in the period, in order, got %v", names)
+	}
+
+	sarah := agents["sarah"]
+	if len(sarah.Transitions) != 2 || sarah.Transitions[0].From != state.StatusReady {
+		t.Errorf("Expected sarah's transitions in the period, got %+v", sarah.Transitions)
+	}
+	if sarah.Growth == nil || *sarah.Growth != (lineCount{Insertions: 100, Deletions: 25}) {
+		t.Errorf("Expected sarah's diff to grow from the last transition before the period, got %+v", sarah.Growth)
+	}
+	if sarah.DoneAt.IsZero() || sarah.CheckpointedAt.IsZero() || len(sarah.Errors) != 1 {
+		t.Errorf("Expected sarah's completion, checkpoint and error, got %+v", sarah)
+	}
+
+	john := agents["john"]
+	if john.Growth == nil || *john.Growth != (lineCount{Insertions: 40}) {
+		t.Errorf("Expected john, started in the period, to grow from nothing, got %+v", john.Growth)
+	}
+	if !john.CheckpointedAt.IsZero() || john.CheckpointError != "" {
+		t.Errorf("Expected john's checkpoint before the period left out, got %+v", john)
+	}
+	if idle := agents["idle"]; idle.Growth != nil {
+		t.Errorf("Expected an unknown growth without transitions, got %+v", idle.Growth)
+	}
+
+	emily := agents["emily"]
+	if emily.Status != statusGone || emily.Diff != (lineCount{Insertions: 7}) || emily.Growth == nil || emily.Growth.Insertions != 7 {
+		t.Errorf("Expected emily, killed in the period, from her transitions, got %+v", emily)
+	}
+}
+
+func TestWriteDigest(t *testing.T) {
+	now := time.Date(2025, 1, 1, 9, 0, 0, 0, time.Local)
+	report := digestReport{Since: now.Add(-8 * time.Hour), Until: now, Agents: []agentDigest{{
+		Agent:  "sarah",
+		Model:  "claude",
+		Status: state.StatusDone,
+		Transitions: []heartbeat.Transition{
+			{From: state.StatusReady, To: state.StatusRunning, At: now.Add(-7 * time.Hour)},
+			{From: state.StatusRunning, To: state.StatusDone, At: now.Add(-time.Hour)},
+		},
+		Diff:            lineCount{Insertions: 120, Deletions: 30},
+		Growth:          &lineCount{Insertions: 100, Deletions: 25},
+		CheckpointedAt:  now.Add(-30 * time.Minute),
+		CheckpointError: "error rebasing agent changes",
+		DoneAt:          now.Add(-time.Hour),
+		Errors: []scrollback.TranscriptError{
+			{At: now.Add(-6 * time.Hour), Text: "first"},
+			{At: now.Add(-5 * time.Hour), Text: "e2"},
+			{At: now.Add(-5 * time.Hour), Text: "e3"},
+			{At: now.Add(-5 * time.Hour), Text: "e4"},
+			{At: now.Add(-5 * time.Hour), Text: "e5"},
+			{At: now.Add(-4 * time.Hour), Text: "Exit code 1"},
+		},
+	}}}
+
+	var out bytes.Buffer
+	writeDigest(&out, report, true)
+	got := out.String()
+	for _, want := range []string{
+		"# Agent activity since 01:00 (8h)\n",
+		"1 agent: 1 done, 1 checkpointed, 6 transcript errors\n",
+		"## sarah (claude, done)\n",
+		"- Status: ready → running 02:00 → done 08:00\n",
+		"- Diff: +120 -30 (was +20 -5 at the start)\n",
+		"- Checkpoint failed at 08:30: error rebasing agent changes\n",
+		"- Done at 08:00\n",
+		"- Errors: 6\n  - (1 earlier not shown)\n",
+		"  - 05:00 Exit code 1\n",
+	} {
+		if !strings.Contains(got, want) {
+			t.Errorf("Expected %q in the digest:\n%s", want, got)
+		}
+	}
+	if strings.Contains(got, "first") {
+		t.Errorf("Expected only the latest %d errors:\n%s", maxErrors, got)
+	}
+
+	out.Reset()
+	writeDigest(&out, report, false)
+	if got := out.String(); strings.Contains(got, "#") || !strings.Contains(got, "\nsarah (claude, done)\n  Status: ready") {
+		t.Errorf("Expected a plain text digest:\n%s", got)
+	}
+
+	out.Reset()
+	writeDigest(&out, digestReport{Since: now.Add(-time.Hour), Until: now}, true)
+	if got := out.String(); !strings.Contains(got, "(1h)") || !strings.Contains(got, "No agent activity.") {
+		t.Errorf("Unexpected empty digest:\n%s", got)
+	}
+}
+
+func TestPostSlack(t *testing.T) {
+	var posted map[string]string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		json.Unmarshal(body, &posted)
+		if posted["text"] == "fail" {
+			http.Error(w, "invalid_payload", http.StatusBadRequest)
+		}
+	}))
+	defer server.Close()
+
+	if err := postSlack(context.Background(), server.URL, "# digest"); err != nil {
+		t.Fatalf("postSlack() error = %v", err)
+	}
+	if posted["text"] != "# digest" {
+		t.Errorf("Expected the digest posted as text, got %v", posted)
+	}
+	if err := postSlack(context.Background(), server.URL, "fail"); err == nil || !strings.Contains(err.Error(), "invalid_payload") {
+		t.Errorf("Expected the webhook's error, got %v", err)
+	}
+}
@@ -0,0 +1,148 @@
+package export
+
+import (
+	"context"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/nehpz/claudicus/pkg/cmdctx"
+	"github.com/nehpz/claudicus/pkg/state"
+
+	"github.com/peterbourgon/ff/v3/ffcli"
+)
+
+var (
+	fs        = flag.NewFlagSet("uzi export", flag.ExitOnError)
+	outFlag   = fs.String("out", "", "file to write the export to (defaults to stdout)")
+	pushFlag  = fs.Bool("push", false, "push every agent branch to origin so teammates can fetch it")
+	CmdExport = &ffcli.Command{
+		Name:       "export",
+		ShortUsage: "uzi export [--out fleet.json] [--push]",
+		ShortHelp:  "Export this repository's agent sessions for a teammate to import",
+		LongHelp: `Write the prompts, models, branches, tags, and runtime budgets of this
+repository's active agent sessions as JSON. A teammate recreates equivalent
+sessions with "uzi import fleet.json" after the agent branches are pushed;
+--push pushes them to origin. Worktrees, ports, and hosts are not exported.`,
+		FlagSet: fs,
+		Exec:    executeExport,
+	}
+)
+
+// sessionStates is the part of the state manager exporting needs
+type sessionStates interface {
+	GetActiveSessionsForRepo() ([]string, error)
+	GetWorktreeInfo(sessionName string) (*state.AgentState, error)
+}
+
+// exporter writes fleet exports; git and now are swappable so exports can be
+// tested without a repository
+type exporter struct {
+	sessions sessionStates
+	git      func(ctx context.Context, args ...string) error
+	now      func() time.Time
+	status   io.Writer // progress messages, kept off the export itself
+}
+
+func executeExport(ctx context.Context, args []string) error {
+	if len(args) > 0 {
+		return fmt.Errorf("unexpected arguments: %s", strings.Join(args, " "))
+	}
+
+	sm, err := cmdctx.From(ctx).State()
+	if err != nil {
+		return err
+	}
+
+	e := &exporter{
+		sessions: sm,
+		git: func(ctx context.Context, args ...string) error {
+			cmd := exec.CommandContext(ctx, "git", args...)
+			cmd.Dir = filepath.Dir(os.Args[0])
+			if output, err := cmd.CombinedOutput(); err != nil {
+				return fmt.Errorf("git %s: %w: %s", args[0], err, strings.TrimSpace(string(output)))
+			}
+			return nil
+		},
+		now:    time.Now,
+		status: os.Stderr,
+	}
+
+	if *outFlag == "" {
+		return e.run(ctx, os.Stdout, *pushFlag)
+	}
+	f, err := os.Create(*outFlag)
+	if err != nil {
+		return err
+	}
+	if err := e.run(ctx, f, *pushFlag); err != nil {
+		f.Close()
+		return err
+	}
+	if err := f.Close(); err != nil {
+		return err
+	}
+	fmt.Fprintf(e.status, "Wrote %s\n", *outFlag)
+	return nil
+}
+
+// run writes the export of every active session to w, pushing the agent
+// branches first if push is set
+func (e *exporter) run(ctx context.Context, w io.Writer, push bool) error {
+	sessionNames, err := e.sessions.GetActiveSessionsForRepo()
+	if err != nil {
+		return fmt.Errorf("error reading state: %w", err)
+	}
+
+	states := make(map[string]state.AgentState)
+	for _, sessionName := range sessionNames {
+		info, err := e.sessions.GetWorktreeInfo(sessionName)
+		if err != nil {
+			return fmt.Errorf("error reading state of %s: %w", sessionName, err)
+		}
+		states[sessionName] = *info
+	}
+	export := state.NewExport(states, e.now())
+
+	if push {
+		if err := e.pushBranches(ctx, states); err != nil {
+			return err
+		}
+	}
+
+	data, err := json.MarshalIndent(export, "", "  ")
+	if err != nil {
+		return err
+	}
+	if _, err := fmt.Fprintf(w, "%s\n", data); err != nil {
+		return err
+	}
+	fmt.Fprintf(e.status, "Exported %d session(s)\n", len(export.Sessions))
+	return nil
+}
+
+// pushBranches pushes the branch of every local worktree session to origin.
+// Branches of remote sessions live on their host and must be pushed there.
+func (e *exporter) pushBranches(ctx context.Context, states map[string]state.AgentState) error {
+	for sessionName, info := range states {
+		agentName := state.AgentNameFromSession(sessionName)
+		switch {
+		case info.IsShared() || info.BranchName == "":
+			continue
+		case info.IsRemote():
+			fmt.Fprintf(e.status, "Not pushing %s: push %s from host %s\n", agentName, info.BranchName, info.Host)
+			continue
+		}
+		if err := e.git(ctx, "push", "origin", info.BranchName); err != nil {
+			return fmt.Errorf("failed to push %s: %w", info.BranchName, err)
+		}
+		fmt.Fprintf(e.status, "Pushed %s\n", info.BranchName)
+	}
+	return nil
+}
@@ -0,0 +1,92 @@
+package export
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"sort"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/nehpz/claudicus/pkg/state"
+)
+
+// fakeStates serves fixed agent states as the active sessions
+type fakeStates map[string]state.AgentState
+
+func (f fakeStates) GetActiveSessionsForRepo() ([]string, error) {
+	var names []string
+	for name := range f {
+		names = append(names, name)
+	}
+	return names, nil
+}
+
+func (f fakeStates) GetWorktreeInfo(sessionName string) (*state.AgentState, error) {
+	info, ok := f[sessionName]
+	if !ok {
+		return nil, errors.New("no state found")
+	}
+	return &info, nil
+}
+
+func newTestExporter(states fakeStates, status *bytes.Buffer) (*exporter, *[]string) {
+	var pushed []string
+	return &exporter{
+		sessions: states,
+		git: func(ctx context.Context, args ...string) error {
+			pushed = append(pushed, strings.Join(args, " "))
+			return nil
+		},
+		now:    func() time.Time { return time.Date(2025, 1, 1, 12, 0, 0, 0, time.UTC) },
+		status: status,
+	}, &pushed
+}
+
+func TestExport(t *testing.T) {
+	states := fakeStates{
+		"agent-app-abc123-sarah":  {Model: "claude", Prompt: "fix login", BranchName: "sarah-app-abc123-1", Tags: []string{"github#1"}},
+		"agent-app-abc123-emily":  {Model: "codex", Prompt: "review", Mode: state.ModeShared},
+		"agent-app-abc123-remote": {Model: "claude", Prompt: "profile", BranchName: "remote-app-abc123-1", Host: "gpu1", SSH: "dev@gpu1"},
+	}
+	var out, status bytes.Buffer
+	e, pushed := newTestExporter(states, &status)
+
+	if err := e.run(context.Background(), &out, true); err != nil {
+		t.Fatalf("run() error = %v", err)
+	}
+
+	export, err := state.ReadExport(&out)
+	if err != nil {
+		t.Fatalf("Expected a readable export, got %v", err)
+	}
+	if len(export.Sessions) != 3 || export.Sessions[2].Agent != "sarah" || export.Sessions[2].Prompt != "fix login" {
+		t.Errorf("Unexpected export: %+v", export.Sessions)
+	}
+
+	sort.Strings(*pushed)
+	if len(*pushed) != 1 || (*pushed)[0] != "push origin sarah-app-abc123-1" {
+		t.Errorf("Expected only the local worktree branch pushed, got %v", *pushed)
+	}
+	for _, want := range []string{"Not pushing remote: push remote-app-abc123-1 from host gpu1", "Exported 3 session(s)"} {
+		if !strings.Contains(status.String(), want) {
+			t.Errorf("Expected %q in status, got %q", want, status.String())
+		}
+	}
+}
+
+func TestExportWithoutPush(t *testing.T) {
+	var out, status bytes.Buffer
+	e, pushed := newTestExporter(fakeStates{"agent-app-abc123-sarah": {BranchName: "b"}}, &status)
+
+	if err := e.run(context.Background(), &out, false); err != nil {
+		t.Fatalf("run() error = %v", err)
+	}
+	if len(*pushed) != 0 {
+		t.Errorf("Expected nothing pushed, got %v", *pushed)
+	}
+	if strings.Contains(out.String(), "Exported") {
+		t.Error("Expected status messages kept out of the export")
+	}
+}
@@ -0,0 +1,150 @@
+package grep
+
+import (
+	"context"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"regexp"
+
+	"github.com/nehpz/claudicus/pkg/cmdctx"
+	"github.com/nehpz/claudicus/pkg/scrollback"
+
+	"github.com/charmbracelet/log"
+	"github.com/peterbourgon/ff/v3/ffcli"
+)
+
+var (
+	fs            = flag.NewFlagSet("uzi grep", flag.ExitOnError)
+	ignoreCase    = fs.Bool("i", false, "match case-insensitively")
+	lines         = fs.Int("lines", 0, "search only the last N lines of each pane's history (0 searches all of it)")
+	noTranscripts = fs.Bool("no-transcripts", false, "search only tmux pane history, not agent transcripts")
+	jsonOutput    = fs.Bool("json", false, "output in JSON format")
+	CmdGrep       = &ffcli.Command{
+		Name:       "grep",
+		ShortUsage: "uzi grep [-i] [--lines N] [--no-transcripts] [--json] <pattern> [agent-name]",
+		ShortHelp:  "Search the output of agents for a pattern",
+		LongHelp: `The grep command searches what the agents of the repository have printed
+for a regular expression, for example to find which agent ran into a
+particular error. It searches the history of each agent's tmux pane, as far
+back as tmux keeps it (--lines limits that), and the transcripts the claude
+CLI keeps of local sessions under ~/.claude/projects.
+
+Each match is printed as agent:location: text, where the location is the
+line of the pane history or the entry of the transcript. Give an agent name
+to search only that agent.
+
+uzi grep exits with status 1 when nothing matches, like grep.`,
+		FlagSet: fs,
+		Exec:    executeGrep,
+	}
+)
+
+// noMatchError is returned when nothing matches; uzi exits with ExitCode
+type noMatchError struct {
+	pattern string
+}
+
+func (e *noMatchError) Error() string {
+	return fmt.Sprintf("no output matches %q", e.pattern)
+}
+
+func (e *noMatchError) ExitCode() int { return 1 }
+
+func executeGrep(ctx context.Context, args []string) error {
+	if len(args) == 0 || len(args) > 2 {
+		return fmt.Errorf("a pattern and at most one agent name are required")
+	}
+	re, err := compile(args[0], *ignoreCase)
+	if err != nil {
+		return err
+	}
+
+	sm, err := cmdctx.From(ctx).State()
+	if err != nil {
+		return err
+	}
+	activeSessions, err := sm.GetActiveSessionsForRepo()
+	if err != nil {
+		return fmt.Errorf("error getting active sessions: %w", err)
+	}
+	agentName := ""
+	if len(args) == 2 {
+		agentName = args[1]
+	}
+	sessionNames, err := scrollback.SelectSessions(activeSessions, agentName)
+	if err != nil {
+		return err
+	}
+
+	home := ""
+	if !*noTranscripts {
+		if home, err = os.UserHomeDir(); err != nil {
+			log.Debug("Skipping transcripts", "error", err)
+		}
+	}
+	var sessions []scrollback.Session
+	for _, sessionName := range sessionNames {
+		agentState, err := sm.GetWorktreeInfo(sessionName)
+		if err != nil {
+			log.Warn("Skipping session without state", "session", sessionName, "error", err)
+			continue
+		}
+		sessions = append(sessions, scrollback.NewSession(sessionName, *agentState, home))
+	}
+
+	matches := search(sessions, re, *lines)
+	if cmdctx.From(ctx).WantJSON(*jsonOutput) {
+		encoder := json.NewEncoder(os.Stdout)
+		encoder.SetIndent("", "  ")
+		if matches == nil {
+			matches = []scrollback.Match{}
+		}
+		if err := encoder.Encode(matches); err != nil {
+			return err
+		}
+	} else {
+		printMatches(os.Stdout, matches)
+	}
+	if len(matches) == 0 {
+		return &noMatchError{pattern: args[0]}
+	}
+	return nil
+}
+
+// compile compiles the pattern, case-insensitively with -i
+func compile(pattern string, ignoreCase bool) (*regexp.Regexp, error) {
+	if ignoreCase {
+		pattern = "(?i)" + pattern
+	}
+	re, err := regexp.Compile(pattern)
+	if err != nil {
+		return nil, fmt.Errorf("invalid pattern: %w", err)
+	}
+	return re, nil
+}
+
+// search returns the matches of every session in order. Sessions that can't
+// be searched, such as one whose tmux session just ended, are skipped with a
+// warning.
+func search(sessions []scrollback.Session, re *regexp.Regexp, lines int) []scrollback.Match {
+	var matches []scrollback.Match
+	for _, session := range sessions {
+		sessionMatches, err := scrollback.Search(session, re, lines)
+		if err != nil {
+			log.Warn("Skipping session", "session", session.Name, "error", err)
+			continue
+		}
+		matches = append(matches, sessionMatches...)
+	}
+	return matches
+}
+
+// printMatches prints each match as agent:location: text
+func printMatches(out io.Writer, matches []scrollback.Match) {
+	for _, m := range matches {
+		fmt.Fprintf(out, "%s:%s: %s\n", m.Agent, m.Location(), m.Text)
+	}
+}
@@ -0,0 +1,53 @@
+package grep
+
+import (
+	"bytes"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/nehpz/claudicus/pkg/scrollback"
+)
+
+// paneExecutor prints a fixed pane history, or fails like a tmux session that
+// just ended
+type paneExecutor struct {
+	pane string
+	err  error
+}
+
+func (e paneExecutor) ExecuteCommand(name string, args ...string) ([]byte, error) {
+	return []byte(e.pane), e.err
+}
+
+func (e paneExecutor) RunCommand(name string, args ...string) error {
+	return e.err
+}
+
+func TestCompile(t *testing.T) {
+	re, err := compile("connection refused", true)
+	if err != nil || !re.MatchString("Error: Connection Refused") {
+		t.Errorf("compile(-i) = %v, %v", re, err)
+	}
+	if re, _ := compile("connection refused", false); re.MatchString("Connection Refused") {
+		t.Error("Expected a case-sensitive match without -i")
+	}
+	if _, err := compile("(", false); err == nil || !strings.Contains(err.Error(), "invalid pattern") {
+		t.Errorf("compile(\"(\") error = %v", err)
+	}
+}
+
+func TestSearchAndPrint(t *testing.T) {
+	sessions := []scrollback.Session{
+		{Name: "agent-repo-abc123-john", Executor: paneExecutor{err: errors.New("can't find session")}},
+		{Name: "agent-repo-abc123-sarah", Executor: paneExecutor{pane: "npm test\nError: connection refused\n"}},
+	}
+	re, _ := compile("refused", false)
+	matches := search(sessions, re, 0)
+
+	var out bytes.Buffer
+	printMatches(&out, matches)
+	if got, want := out.String(), "sarah:pane:2: Error: connection refused\n"; got != want {
+		t.Errorf("printMatches() = %q, want %q", got, want)
+	}
+}
@@ -0,0 +1,151 @@
+package health
+
+import (
+	"context"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"sort"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"github.com/nehpz/claudicus/pkg/cmdctx"
+	"github.com/nehpz/claudicus/pkg/heartbeat"
+	"github.com/nehpz/claudicus/pkg/state"
+
+	"github.com/peterbourgon/ff/v3/ffcli"
+)
+
+var (
+	fs         = flag.NewFlagSet("uzi health", flag.ExitOnError)
+	exitCode   = fs.Bool("exit-code", false, "exit with status 1 if any session is unhealthy")
+	jsonOutput = fs.Bool("json", false, "output in JSON format")
+	CmdHealth  = &ffcli.Command{
+		Name:       "health",
+		ShortUsage: "uzi health [--exit-code] [--json]",
+		ShortHelp:  "Check agent sessions against their heartbeats",
+		LongHelp: `The health command checks every session of the repository against the
+heartbeat uzi auto writes to .uzi/heartbeats/<session>. A session is unhealthy
+when its heartbeat is missing, says its agent is dead, or is past its
+stale_at time because uzi auto stopped writing it.
+
+With --exit-code, uzi health exits with status 1 when any session is
+unhealthy, for cron jobs and other scripted checks.`,
+		FlagSet: fs,
+		Exec:    executeHealth,
+	}
+)
+
+// sessionHealth is one session's row of uzi health
+type sessionHealth struct {
+	Session   string `json:"session"`
+	Agent     string `json:"agent"`
+	Status    string `json:"status,omitempty"` // from the heartbeat; empty without one
+	Healthy   bool   `json:"healthy"`
+	Problem   string `json:"problem,omitempty"`
+	UpdatedAt string `json:"updated_at,omitempty"`
+}
+
+// unhealthyError is returned with --exit-code when sessions are unhealthy;
+// uzi exits with ExitCode
+type unhealthyError struct {
+	unhealthy int
+	total     int
+}
+
+func (e *unhealthyError) Error() string {
+	return fmt.Sprintf("%d of %d sessions unhealthy", e.unhealthy, e.total)
+}
+
+func (e *unhealthyError) ExitCode() int { return 1 }
+
+// check compares each session with its heartbeat at now, in session order
+func check(sessionNames []string, store *heartbeat.Store, now time.Time) []sessionHealth {
+	sort.Strings(sessionNames)
+	results := make([]sessionHealth, 0, len(sessionNames))
+	for _, sessionName := range sessionNames {
+		result := sessionHealth{Session: sessionName, Agent: state.AgentNameFromSession(sessionName)}
+		beat, err := store.Read(sessionName)
+		switch {
+		case os.IsNotExist(err):
+			result.Problem = "no heartbeat (is uzi auto running?)"
+		case err != nil:
+			result.Problem = err.Error()
+		default:
+			result.Status = beat.Status
+			result.UpdatedAt = beat.UpdatedAt.Format(time.RFC3339)
+			result.Problem = beat.Problem(now)
+		}
+		result.Healthy = result.Problem == ""
+		results = append(results, result)
+	}
+	return results
+}
+
+func executeHealth(ctx context.Context, args []string) error {
+	sm, err := cmdctx.From(ctx).State()
+	if err != nil {
+		return err
+	}
+	states, err := sm.StatesForRepo()
+	if err != nil {
+		return fmt.Errorf("error loading sessions: %w", err)
+	}
+	repoRoot, err := exec.CommandContext(ctx, "git", "rev-parse", "--show-toplevel").Output()
+	if err != nil {
+		return fmt.Errorf("uzi health must run in a git repository: %w", err)
+	}
+	store := heartbeat.NewStore(heartbeat.Dir(strings.TrimSpace(string(repoRoot))))
+
+	sessionNames := make([]string, 0, len(states))
+	for sessionName := range states {
+		sessionNames = append(sessionNames, sessionName)
+	}
+	results := check(sessionNames, store, time.Now())
+
+	if cmdctx.From(ctx).WantJSON(*jsonOutput) {
+		encoder := json.NewEncoder(os.Stdout)
+		encoder.SetIndent("", "  ")
+		if err := encoder.Encode(results); err != nil {
+			return err
+		}
+	} else {
+		printResults(os.Stdout, results)
+	}
+
+	unhealthy := 0
+	for _, result := range results {
+		if !result.Healthy {
+			unhealthy++
+		}
+	}
+	if *exitCode && unhealthy > 0 {
+		return &unhealthyError{unhealthy: unhealthy, total: len(results)}
+	}
+	return nil
+}
+
+// printResults lists each session with its heartbeat status and problem
+func printResults(out io.Writer, results []sessionHealth) {
+	if len(results) == 0 {
+		fmt.Fprintln(out, "No sessions found")
+		return
+	}
+	w := tabwriter.NewWriter(out, 0, 0, 2, ' ', 0)
+	fmt.Fprintf(w, "AGENT\tSTATUS\tHEALTH\tPROBLEM\n")
+	for _, result := range results {
+		status, health := result.Status, "ok"
+		if status == "" {
+			status = "-"
+		}
+		if !result.Healthy {
+			health = "unhealthy"
+		}
+		fmt.Fprintf(w, "%s\t%s\t%s\t%s\n", result.Agent, status, health, result.Problem)
+	}
+	w.Flush()
+}
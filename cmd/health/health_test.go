@@ -0,0 +1,62 @@
+package health
+
+import (
+	"bytes"
+	"os"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/nehpz/claudicus/pkg/heartbeat"
+)
+
+func TestCheck(t *testing.T) {
+	store := heartbeat.NewStore(t.TempDir())
+	now := time.Date(2025, 1, 1, 12, 0, 0, 0, time.UTC)
+	for _, beat := range []heartbeat.Beat{
+		heartbeat.NewBeat("agent-app-abc123-sarah", "sarah", "running", now, 10*time.Second),
+		heartbeat.NewBeat("agent-app-abc123-john", "john", "dead", now, 10*time.Second),
+		heartbeat.NewBeat("agent-app-abc123-emily", "emily", "ready", now.Add(-time.Hour), 10*time.Second),
+	} {
+		if err := store.Write(beat); err != nil {
+			t.Fatal(err)
+		}
+	}
+	os.WriteFile(store.Path("agent-app-abc123-bad"), []byte("{"), 0644)
+
+	results := check([]string{"agent-app-abc123-sarah", "agent-app-abc123-john", "agent-app-abc123-emily", "agent-app-abc123-ana", "agent-app-abc123-bad"}, store, now)
+	problems := map[string]string{}
+	for _, result := range results {
+		if result.Healthy != (result.Problem == "") {
+			t.Errorf("Healthy and problem disagree for %+v", result)
+		}
+		problems[result.Agent] = result.Problem
+	}
+	if results[0].Agent != "ana" {
+		t.Errorf("Expected sessions in name order, got %+v", results)
+	}
+	for agent, want := range map[string]string{
+		"sarah": "",
+		"john":  "agent is dead",
+		"emily": "stale",
+		"ana":   "no heartbeat",
+		"bad":   "error parsing heartbeat",
+	} {
+		if got := problems[agent]; (want == "") != (got == "") || !strings.Contains(got, want) {
+			t.Errorf("%s: problem = %q, want %q", agent, got, want)
+		}
+	}
+
+	var out bytes.Buffer
+	printResults(&out, results)
+	if !strings.Contains(out.String(), "unhealthy") || !strings.Contains(out.String(), "sarah  running  ok") {
+		t.Errorf("Unexpected output:\n%s", out.String())
+	}
+}
+
+func TestUnhealthyErrorExitCode(t *testing.T) {
+	err := &unhealthyError{unhealthy: 2, total: 3}
+	if err.ExitCode() != 1 || err.Error() != "2 of 3 sessions unhealthy" {
+		t.Errorf("Unexpected error %q with exit code %d", err, err.ExitCode())
+	}
+}
@@ -0,0 +1,251 @@
+package importer
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+
+	"github.com/nehpz/claudicus/cmd/prompt"
+	"github.com/nehpz/claudicus/pkg/agents"
+	"github.com/nehpz/claudicus/pkg/cmdctx"
+	"github.com/nehpz/claudicus/pkg/state"
+)
+
+// Ways to resolve an agent name or branch that already exists on this machine
+const (
+	conflictRename = "rename"
+	conflictSkip   = "skip"
+)
+
+// activeSessions is the part of the state manager the fleet import needs
+type activeSessions interface {
+	GetActiveSessionsForRepo() ([]string, error)
+	GetWorktreeInfo(sessionName string) (*state.AgentState, error)
+}
+
+// fleetImporter recreates the sessions of a fleet export; sessions, git,
+// recreate, and newName are swappable so the import can be tested without
+// git or tmux
+type fleetImporter struct {
+	sessions activeSessions
+	git      func(ctx context.Context, args ...string) (string, error)
+	recreate func(ctx context.Context, opts prompt.RecreateOptions) error
+	newName  func() string
+	out      io.Writer
+}
+
+// fleetOptions holds the flags of a fleet import
+type fleetOptions struct {
+	configPath string
+	dryRun     bool
+	onConflict string
+}
+
+func newFleetImporter(ctx context.Context) (*fleetImporter, error) {
+	sm, err := cmdctx.From(ctx).State()
+	if err != nil {
+		return nil, err
+	}
+	return &fleetImporter{
+		sessions: sm,
+		git: func(ctx context.Context, args ...string) (string, error) {
+			cmd := exec.CommandContext(ctx, "git", args...)
+			cmd.Dir = filepath.Dir(os.Args[0])
+			output, err := cmd.Output()
+			return strings.TrimSpace(string(output)), err
+		},
+		recreate: prompt.Recreate,
+		newName:  agents.GetRandomAgent,
+		out:      os.Stdout,
+	}, nil
+}
+
+// importFleet reads the fleet export at path and recreates its sessions
+func importFleet(ctx context.Context, path string, opts fleetOptions) error {
+	if opts.onConflict != conflictRename && opts.onConflict != conflictSkip {
+		return fmt.Errorf("--on-conflict must be %q or %q", conflictRename, conflictSkip)
+	}
+
+	f, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	export, err := state.ReadExport(f)
+	if err != nil {
+		return err
+	}
+
+	imp, err := newFleetImporter(ctx)
+	if err != nil {
+		return err
+	}
+	return imp.run(ctx, export, opts)
+}
+
+// run recreates every exported session whose branch has no session here yet.
+// Agent names and local branches that collide with existing ones are renamed
+// or skipped according to opts.onConflict.
+func (imp *fleetImporter) run(ctx context.Context, export state.Export, opts fleetOptions) error {
+	sessionNames, err := imp.sessions.GetActiveSessionsForRepo()
+	if err != nil {
+		return fmt.Errorf("error reading state: %w", err)
+	}
+	takenNames := make(map[string]bool)
+	branchAgents := make(map[string]string)
+	for _, sessionName := range sessionNames {
+		agentName := state.AgentNameFromSession(sessionName)
+		takenNames[agentName] = true
+		if info, err := imp.sessions.GetWorktreeInfo(sessionName); err == nil && info.BranchName != "" {
+			branchAgents[info.BranchName] = agentName
+		}
+	}
+
+	if origin, err := imp.git(ctx, "config", "--get", "remote.origin.url"); err == nil && export.GitRepo != "" && origin != export.GitRepo {
+		fmt.Fprintf(imp.out, "Warning: fleet was exported from %s, this repository's origin is %s\n", export.GitRepo, origin)
+	}
+
+	var failed []string
+	imported := 0
+	for _, session := range export.Sessions {
+		if agentName, ok := branchAgents[session.Branch]; ok {
+			fmt.Fprintf(imp.out, "%s: skipped, branch %s already has agent %s\n", session.Agent, session.Branch, agentName)
+			continue
+		}
+
+		agentName, ok := imp.agentName(session.Agent, takenNames, opts.onConflict)
+		if !ok {
+			fmt.Fprintf(imp.out, "%s: skipped, an agent with that name already exists\n", session.Agent)
+			continue
+		}
+
+		if opts.dryRun {
+			fmt.Fprintf(imp.out, "%s: %s\n", agentName, describeSession(session))
+			takenNames[agentName] = true
+			continue
+		}
+
+		branch := ""
+		if !session.IsShared() {
+			branch, err = imp.localBranch(ctx, session.Branch, opts.onConflict)
+			if err != nil {
+				fmt.Fprintf(imp.out, "%s: %v\n", session.Agent, err)
+				failed = append(failed, session.Agent)
+				continue
+			}
+			if branch == "" {
+				fmt.Fprintf(imp.out, "%s: skipped, local branch %s differs from origin\n", session.Agent, session.Branch)
+				continue
+			}
+		}
+
+		if err := imp.recreate(ctx, prompt.RecreateOptions{
+			ConfigPath: opts.configPath,
+			AgentName:  agentName,
+			Model:      session.Model,
+			Prompt:     session.Prompt,
+			Branch:     branch,
+			MaxRuntime: session.MaxRuntime,
+			Tags:       session.Tags,
+		}); err != nil {
+			fmt.Fprintf(imp.out, "%s: %v\n", session.Agent, err)
+			failed = append(failed, session.Agent)
+			continue
+		}
+		takenNames[agentName] = true
+		if branch != "" {
+			branchAgents[session.Branch] = agentName
+		}
+		imported++
+
+		if agentName != session.Agent {
+			fmt.Fprintf(imp.out, "%s: recreated as %s\n", session.Agent, agentName)
+		} else {
+			fmt.Fprintf(imp.out, "%s: recreated\n", agentName)
+		}
+	}
+
+	if opts.dryRun {
+		return nil
+	}
+	fmt.Fprintf(imp.out, "Imported %d of %d session(s)\n", imported, len(export.Sessions))
+	if len(failed) > 0 {
+		return fmt.Errorf("failed to import %s", strings.Join(failed, ", "))
+	}
+	return nil
+}
+
+// agentName returns the name to recreate an agent under, picking a fresh one
+// if the exported name is taken and renaming is allowed
+func (imp *fleetImporter) agentName(name string, taken map[string]bool, onConflict string) (string, bool) {
+	if !taken[name] {
+		return name, true
+	}
+	if onConflict == conflictSkip {
+		return "", false
+	}
+	for i := 0; i < 100; i++ {
+		if candidate := imp.newName(); !taken[candidate] {
+			return candidate, true
+		}
+	}
+	return "", false
+}
+
+// localBranch fetches an exported branch from origin and returns the local
+// branch the agent continues on. A local branch of the same name that points
+// elsewhere is left alone: the fetched commit goes on <branch>-imported
+// instead, or an empty name is returned when conflicts are skipped.
+func (imp *fleetImporter) localBranch(ctx context.Context, branch, onConflict string) (string, error) {
+	local, localErr := imp.git(ctx, "rev-parse", "--verify", "--quiet", "refs/heads/"+branch)
+	if _, err := imp.git(ctx, "fetch", "origin", branch); err != nil {
+		if localErr == nil {
+			// Not pushed, but this machine has it, e.g. the exporter's own checkout
+			return branch, nil
+		}
+		return "", fmt.Errorf("branch %s is not on origin; the exporter can push it with uzi export --push", branch)
+	}
+	fetched, err := imp.git(ctx, "rev-parse", "FETCH_HEAD")
+	if err != nil {
+		return "", fmt.Errorf("failed to read fetched branch %s: %w", branch, err)
+	}
+
+	if localErr == nil {
+		if local == fetched {
+			return branch, nil
+		}
+		if onConflict == conflictSkip {
+			return "", nil
+		}
+		branch = imp.freeBranchName(ctx, branch+"-imported")
+	}
+	if _, err := imp.git(ctx, "branch", branch, fetched); err != nil {
+		return "", fmt.Errorf("failed to create branch %s: %w", branch, err)
+	}
+	return branch, nil
+}
+
+// freeBranchName returns name, or name with a numeric suffix, whichever is
+// not a local branch yet
+func (imp *fleetImporter) freeBranchName(ctx context.Context, name string) string {
+	candidate := name
+	for i := 2; ; i++ {
+		if _, err := imp.git(ctx, "rev-parse", "--verify", "--quiet", "refs/heads/"+candidate); err != nil {
+			return candidate
+		}
+		candidate = fmt.Sprintf("%s-%d", name, i)
+	}
+}
+
+// describeSession summarizes an exported session for a dry run
+func describeSession(session state.ExportedSession) string {
+	where := "on branch " + session.Branch
+	if session.IsShared() {
+		where = "in the main checkout"
+	}
+	return fmt.Sprintf("%s %s: %s", session.Model, where, session.Prompt)
+}
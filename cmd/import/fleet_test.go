@@ -0,0 +1,218 @@
+package importer
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/nehpz/claudicus/cmd/prompt"
+	"github.com/nehpz/claudicus/pkg/state"
+)
+
+// MockActiveSessions serves fixed agent states as the active sessions
+type MockActiveSessions map[string]state.AgentState
+
+func (m MockActiveSessions) GetActiveSessionsForRepo() ([]string, error) {
+	var names []string
+	for name := range m {
+		names = append(names, name)
+	}
+	return names, nil
+}
+
+func (m MockActiveSessions) GetWorktreeInfo(sessionName string) (*state.AgentState, error) {
+	s, ok := m[sessionName]
+	if !ok {
+		return nil, errors.New("no state found")
+	}
+	return &s, nil
+}
+
+// MockGit fakes a repository with local branches and branches on origin
+type MockGit struct {
+	origin    string
+	local     map[string]string // branch -> commit
+	remote    map[string]string // branch -> commit
+	fetchHead string
+	created   []string
+}
+
+func (g *MockGit) run(ctx context.Context, args ...string) (string, error) {
+	switch args[0] {
+	case "config":
+		return g.origin, nil
+	case "fetch":
+		commit, ok := g.remote[args[2]]
+		if !ok {
+			return "", errors.New("couldn't find remote ref")
+		}
+		g.fetchHead = commit
+		return "", nil
+	case "rev-parse":
+		ref := args[len(args)-1]
+		if ref == "FETCH_HEAD" {
+			return g.fetchHead, nil
+		}
+		commit, ok := g.local[strings.TrimPrefix(ref, "refs/heads/")]
+		if !ok {
+			return "", errors.New("exit status 1")
+		}
+		return commit, nil
+	case "branch":
+		g.local[args[1]] = args[2]
+		g.created = append(g.created, args[1]+"@"+args[2])
+		return "", nil
+	}
+	return "", errors.New("unexpected git command")
+}
+
+func newTestFleetImporter(active MockActiveSessions, git *MockGit) (*fleetImporter, *[]prompt.RecreateOptions, *bytes.Buffer) {
+	var recreated []prompt.RecreateOptions
+	names := []string{"emily", "liam"}
+	out := &bytes.Buffer{}
+	return &fleetImporter{
+		sessions: active,
+		git:      git.run,
+		recreate: func(ctx context.Context, opts prompt.RecreateOptions) error {
+			recreated = append(recreated, opts)
+			return nil
+		},
+		newName: func() string {
+			name := names[0]
+			names = names[1:]
+			return name
+		},
+		out: out,
+	}, &recreated, out
+}
+
+func testFleet() state.Export {
+	return state.Export{
+		Version: state.ExportVersion,
+		GitRepo: "git@github.com:org/app.git",
+		Sessions: []state.ExportedSession{
+			{Agent: "noah", Model: "codex", Prompt: "review", Mode: state.ModeShared},
+			{Agent: "sarah", Model: "claude", Prompt: "fix login", Branch: "sarah-app-1", Tags: []string{"github#1"}},
+		},
+	}
+}
+
+func TestFleetImport(t *testing.T) {
+	git := &MockGit{
+		origin: "git@github.com:org/app.git",
+		local:  map[string]string{},
+		remote: map[string]string{"sarah-app-1": "c1"},
+	}
+	imp, recreated, out := newTestFleetImporter(MockActiveSessions{}, git)
+
+	if err := imp.run(context.Background(), testFleet(), fleetOptions{onConflict: conflictRename}); err != nil {
+		t.Fatalf("run() error = %v", err)
+	}
+
+	if len(*recreated) != 2 {
+		t.Fatalf("Expected 2 sessions recreated, got %+v", *recreated)
+	}
+	noah, sarah := (*recreated)[0], (*recreated)[1]
+	if noah.AgentName != "noah" || noah.Branch != "" || noah.Model != "codex" {
+		t.Errorf("Expected the shared session recreated without a branch, got %+v", noah)
+	}
+	if sarah.AgentName != "sarah" || sarah.Branch != "sarah-app-1" || sarah.Prompt != "fix login" || sarah.Tags[0] != "github#1" {
+		t.Errorf("Unexpected recreated session: %+v", sarah)
+	}
+	if len(git.created) != 1 || git.created[0] != "sarah-app-1@c1" {
+		t.Errorf("Expected the branch created from origin, got %v", git.created)
+	}
+	if strings.Contains(out.String(), "Warning") || !strings.Contains(out.String(), "Imported 2 of 2 session(s)") {
+		t.Errorf("Unexpected output: %q", out.String())
+	}
+}
+
+func TestFleetImportConflicts(t *testing.T) {
+	active := MockActiveSessions{
+		"agent-app-abc123-noah":  {Mode: state.ModeShared},
+		"agent-app-abc123-sarah": {BranchName: "sarah-other"},
+	}
+	newGit := func() *MockGit {
+		return &MockGit{
+			origin: "git@github.com:me/fork.git",
+			local:  map[string]string{"sarah-app-1": "local", "sarah-app-1-imported": "older"},
+			remote: map[string]string{"sarah-app-1": "c1"},
+		}
+	}
+
+	t.Run("rename", func(t *testing.T) {
+		git := newGit()
+		imp, recreated, out := newTestFleetImporter(active, git)
+		if err := imp.run(context.Background(), testFleet(), fleetOptions{onConflict: conflictRename}); err != nil {
+			t.Fatalf("run() error = %v", err)
+		}
+		if len(*recreated) != 2 || (*recreated)[0].AgentName != "emily" || (*recreated)[1].AgentName != "liam" {
+			t.Fatalf("Expected taken agent names replaced, got %+v", *recreated)
+		}
+		if (*recreated)[1].Branch != "sarah-app-1-imported-2" || git.local["sarah-app-1"] != "local" {
+			t.Errorf("Expected the differing local branch kept, got %+v and %v", (*recreated)[1], git.local)
+		}
+		for _, want := range []string{"Warning: fleet was exported from git@github.com:org/app.git", "sarah: recreated as liam"} {
+			if !strings.Contains(out.String(), want) {
+				t.Errorf("Expected %q in output, got %q", want, out.String())
+			}
+		}
+	})
+
+	t.Run("skip", func(t *testing.T) {
+		git := newGit()
+		imp, recreated, out := newTestFleetImporter(active, git)
+		if err := imp.run(context.Background(), testFleet(), fleetOptions{onConflict: conflictSkip}); err != nil {
+			t.Fatalf("run() error = %v", err)
+		}
+		if len(*recreated) != 0 || len(git.created) != 0 {
+			t.Errorf("Expected nothing recreated, got %+v and branches %v", *recreated, git.created)
+		}
+		if !strings.Contains(out.String(), "noah: skipped") || !strings.Contains(out.String(), "sarah: skipped") {
+			t.Errorf("Unexpected output: %q", out.String())
+		}
+	})
+}
+
+func TestFleetImportBranches(t *testing.T) {
+	fleet := state.Export{Version: state.ExportVersion, Sessions: []state.ExportedSession{
+		{Agent: "sarah", Model: "claude", Branch: "running"},
+		{Agent: "noah", Model: "claude", Branch: "unpushed"},
+		{Agent: "emma", Model: "claude", Branch: "missing"},
+	}}
+	active := MockActiveSessions{"agent-app-abc123-liam": {BranchName: "running"}}
+	git := &MockGit{local: map[string]string{"unpushed": "c2"}, remote: map[string]string{}}
+	imp, recreated, out := newTestFleetImporter(active, git)
+
+	err := imp.run(context.Background(), fleet, fleetOptions{onConflict: conflictRename})
+	if err == nil || !strings.Contains(err.Error(), "emma") {
+		t.Fatalf("Expected emma to fail, got %v", err)
+	}
+	if len(*recreated) != 1 || (*recreated)[0].Branch != "unpushed" {
+		t.Errorf("Expected only the local branch session recreated, got %+v", *recreated)
+	}
+	for _, want := range []string{"sarah: skipped, branch running already has agent liam", "uzi export --push"} {
+		if !strings.Contains(out.String(), want) {
+			t.Errorf("Expected %q in output, got %q", want, out.String())
+		}
+	}
+}
+
+func TestFleetImportDryRun(t *testing.T) {
+	git := &MockGit{local: map[string]string{}, remote: map[string]string{}}
+	imp, recreated, out := newTestFleetImporter(MockActiveSessions{}, git)
+
+	if err := imp.run(context.Background(), testFleet(), fleetOptions{dryRun: true, onConflict: conflictRename}); err != nil {
+		t.Fatalf("run() error = %v", err)
+	}
+	if len(*recreated) != 0 || len(git.created) != 0 {
+		t.Error("Expected a dry run to change nothing")
+	}
+	for _, want := range []string{"noah: codex in the main checkout: review", "sarah: claude on branch sarah-app-1: fix login"} {
+		if !strings.Contains(out.String(), want) {
+			t.Errorf("Expected %q in output, got %q", want, out.String())
+		}
+	}
+}
@@ -0,0 +1,68 @@
+package importer
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os/exec"
+	"strconv"
+	"strings"
+)
+
+// GitHubProvider reads issues of the current repository through the gh CLI,
+// which takes care of authentication and of resolving the repository from the
+// git remotes
+type GitHubProvider struct {
+	executor CommandExecutor
+}
+
+// NewGitHubProvider creates a GitHubProvider that runs gh with executor
+func NewGitHubProvider(executor CommandExecutor) *GitHubProvider {
+	return &GitHubProvider{executor: executor}
+}
+
+// Name implements Provider
+func (g *GitHubProvider) Name() string {
+	return "github"
+}
+
+// ListIssues implements Provider
+func (g *GitHubProvider) ListIssues(ctx context.Context, label string, limit int) ([]Issue, error) {
+	output, err := g.gh(ctx, "issue", "list",
+		"--state", "open",
+		"--label", label,
+		"--limit", strconv.Itoa(limit),
+		"--json", "number,title,body,url")
+	if err != nil {
+		return nil, err
+	}
+
+	var issues []Issue
+	if err := json.Unmarshal(output, &issues); err != nil {
+		return nil, fmt.Errorf("error parsing gh issue list output: %w", err)
+	}
+	return issues, nil
+}
+
+// Comment implements Provider
+func (g *GitHubProvider) Comment(ctx context.Context, issue Issue, body string) error {
+	_, err := g.gh(ctx, "issue", "comment", strconv.Itoa(issue.Number), "--body", body)
+	return err
+}
+
+// gh runs the gh CLI and folds its stderr into the returned error
+func (g *GitHubProvider) gh(ctx context.Context, args ...string) ([]byte, error) {
+	output, err := g.executor.ExecuteCommandContext(ctx, "gh", args...)
+	if err == nil {
+		return output, nil
+	}
+	if errors.Is(err, exec.ErrNotFound) {
+		return nil, fmt.Errorf("gh CLI not found; install it from https://cli.github.com and run gh auth login")
+	}
+	var exitErr *exec.ExitError
+	if errors.As(err, &exitErr) && len(exitErr.Stderr) > 0 {
+		return nil, fmt.Errorf("gh %s: %s", args[0]+" "+args[1], strings.TrimSpace(string(exitErr.Stderr)))
+	}
+	return nil, fmt.Errorf("gh %s: %w", args[0]+" "+args[1], err)
+}
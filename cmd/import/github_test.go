@@ -0,0 +1,59 @@
+package importer
+
+import (
+	"context"
+	"fmt"
+	"os/exec"
+	"strings"
+	"testing"
+)
+
+// MockCommandExecutor answers commands from a table keyed by the joined arguments
+type MockCommandExecutor struct {
+	outputs map[string]string
+	err     error
+	calls   []string
+}
+
+// ExecuteCommandContext returns the configured output or an error for unknown commands
+func (m *MockCommandExecutor) ExecuteCommandContext(ctx context.Context, command string, args ...string) ([]byte, error) {
+	key := command + " " + strings.Join(args, " ")
+	m.calls = append(m.calls, key)
+	if m.err != nil {
+		return nil, m.err
+	}
+	if out, ok := m.outputs[key]; ok {
+		return []byte(out), nil
+	}
+	return nil, fmt.Errorf("unexpected command: %s", key)
+}
+
+func TestGitHubListIssues(t *testing.T) {
+	executor := &MockCommandExecutor{outputs: map[string]string{
+		"gh issue list --state open --label agent-task --limit 5 --json number,title,body,url": `[{"number":7,"title":"Fix login","body":"details","url":"https://github.com/o/r/issues/7"}]`,
+	}}
+	issues, err := NewGitHubProvider(executor).ListIssues(context.Background(), "agent-task", 5)
+	if err != nil {
+		t.Fatalf("ListIssues() error = %v", err)
+	}
+	if len(issues) != 1 || issues[0].Number != 7 || issues[0].Body != "details" {
+		t.Errorf("Unexpected issues %+v", issues)
+	}
+}
+
+func TestGitHubComment(t *testing.T) {
+	executor := &MockCommandExecutor{outputs: map[string]string{
+		"gh issue comment 7 --body hello": "",
+	}}
+	if err := NewGitHubProvider(executor).Comment(context.Background(), Issue{Number: 7}, "hello"); err != nil {
+		t.Fatalf("Comment() error = %v", err)
+	}
+}
+
+func TestGitHubMissingCLI(t *testing.T) {
+	executor := &MockCommandExecutor{err: &exec.Error{Name: "gh", Err: exec.ErrNotFound}}
+	_, err := NewGitHubProvider(executor).ListIssues(context.Background(), "agent-task", 5)
+	if err == nil || !strings.Contains(err.Error(), "gh CLI not found") {
+		t.Errorf("Expected missing gh error, got %v", err)
+	}
+}
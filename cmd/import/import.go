@@ -0,0 +1,222 @@
+package importer
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"github.com/nehpz/claudicus/cmd/prompt"
+	"github.com/nehpz/claudicus/pkg/cmdctx"
+	"github.com/nehpz/claudicus/pkg/config"
+	"github.com/nehpz/claudicus/pkg/state"
+
+	"github.com/charmbracelet/log"
+	"github.com/peterbourgon/ff/v3/ffcli"
+)
+
+var (
+	fs              = flag.NewFlagSet("uzi import", flag.ExitOnError)
+	fleetConfigPath = fs.String("config", config.GetDefaultConfigPath(), "path to config file")
+	fleetDryRun     = fs.Bool("dry-run", false, "list the sessions that would be recreated without spawning agents")
+	onConflict      = fs.String("on-conflict", conflictRename, "how to handle agent names and local branches that already exist: rename or skip")
+	githubFs        = flag.NewFlagSet("uzi import github", flag.ExitOnError)
+	label           = githubFs.String("label", "", "import open issues carrying this label (required)")
+	agentsFlag      = githubFs.String("agents", "claude:1", "agents to run per issue with their commands and counts (e.g., 'claude:1,codex:2')")
+	limit           = githubFs.Int("limit", 20, "maximum number of issues to import")
+	configPath      = githubFs.String("config", config.GetDefaultConfigPath(), "path to config file")
+	dryRun          = githubFs.Bool("dry-run", false, "list the issues that would be imported without spawning agents")
+	noComment       = githubFs.Bool("no-comment", false, "do not comment on the issues with the agent branches")
+	CmdImport       = &ffcli.Command{
+		Name:       "import",
+		ShortUsage: "uzi import <provider> [flags] | uzi import [--dry-run] [--on-conflict rename|skip] <fleet.json>",
+		ShortHelp:  "Spawn agents for tracker issues or recreate a teammate's exported sessions",
+		LongHelp: `With a provider, spawn one agent per issue imported from an issue tracker.
+
+With a file written by uzi export, recreate its sessions on this machine: each
+agent branch is fetched from origin and a new worktree and agent session are
+started on it with the exported prompt, model, tags, and runtime budget.
+Sessions whose branch already has an agent are skipped. Agent names that are
+taken get a new name, and a local branch that differs from origin is kept while
+the fetched one is created as <branch>-imported; with --on-conflict skip such
+sessions are skipped instead.`,
+		FlagSet: fs,
+		Subcommands: []*ffcli.Command{
+			{
+				Name:       "github",
+				ShortUsage: "uzi import github --label agent-task [--agents claude:1] [--limit 20] [--dry-run] [--no-comment]",
+				ShortHelp:  "Spawn agents for open GitHub issues with a label",
+				LongHelp: `Fetch the open issues of the current repository that carry --label using
+the gh CLI, and spawn agents for each of them with the issue title and body as
+the prompt. Sessions are tagged with the issue (e.g. github#42), so issues that
+already have a session are skipped when the import runs again. A comment naming
+the agent branches is posted on every imported issue unless --no-comment is set.`,
+				FlagSet: githubFs,
+				Exec: func(ctx context.Context, args []string) error {
+					if *label == "" {
+						return fmt.Errorf("--label is required")
+					}
+					if *limit <= 0 {
+						return fmt.Errorf("--limit must be positive")
+					}
+					sm, err := cmdctx.From(ctx).State()
+					if err != nil {
+						return err
+					}
+					imp := &issueImporter{
+						provider: NewGitHubProvider(&RealCommandExecutor{}),
+						sessions: sm,
+						spawn:    prompt.Spawn,
+						out:      os.Stdout,
+					}
+					return imp.run(ctx, importOptions{
+						label:     *label,
+						agents:    *agentsFlag,
+						limit:     *limit,
+						dryRun:    *dryRun,
+						noComment: *noComment,
+					})
+				},
+			},
+		},
+		Exec: func(ctx context.Context, args []string) error {
+			if len(args) != 1 {
+				return flag.ErrHelp
+			}
+			return importFleet(ctx, args[0], fleetOptions{
+				configPath: *fleetConfigPath,
+				dryRun:     *fleetDryRun,
+				onConflict: *onConflict,
+			})
+		},
+	}
+)
+
+// sessionStore is the part of the state manager the importer needs
+type sessionStore interface {
+	SessionsWithTag(tag string) ([]string, error)
+	GetWorktreeInfo(sessionName string) (*state.AgentState, error)
+}
+
+// issueImporter spawns agents for issues; provider, sessions, and spawn are
+// swappable so the import can be tested without gh, git, or tmux
+type issueImporter struct {
+	provider Provider
+	sessions sessionStore
+	spawn    func(ctx context.Context, opts prompt.SpawnOptions) ([]string, error)
+	out      io.Writer
+}
+
+// importOptions holds the flags of an import run
+type importOptions struct {
+	label     string
+	agents    string
+	limit     int
+	dryRun    bool
+	noComment bool
+}
+
+// run imports every open issue with the label that has no session yet
+func (imp *issueImporter) run(ctx context.Context, opts importOptions) error {
+	issues, err := imp.provider.ListIssues(ctx, opts.label, opts.limit)
+	if err != nil {
+		return err
+	}
+	if len(issues) == 0 {
+		fmt.Fprintf(imp.out, "No open issues labeled %q\n", opts.label)
+		return nil
+	}
+
+	imported := 0
+	for _, issue := range issues {
+		tag := issueTag(imp.provider, issue)
+		existing, err := imp.sessions.SessionsWithTag(tag)
+		if err != nil {
+			return fmt.Errorf("error reading state: %w", err)
+		}
+		if len(existing) > 0 {
+			fmt.Fprintf(imp.out, "%s: skipped, already imported as %s\n", tag, strings.Join(existing, ", "))
+			continue
+		}
+
+		if opts.dryRun {
+			fmt.Fprintf(imp.out, "%s: %s\n", tag, issue.Title)
+			continue
+		}
+
+		if _, err := imp.spawn(ctx, prompt.SpawnOptions{
+			ConfigPath: *configPath,
+			Agents:     opts.agents,
+			Prompt:     issuePrompt(issue),
+			Tags:       []string{tag},
+		}); err != nil {
+			return err
+		}
+
+		// Spawned sessions are looked up by tag so the branches come straight from state
+		sessions, err := imp.sessions.SessionsWithTag(tag)
+		if err != nil {
+			return fmt.Errorf("error reading state: %w", err)
+		}
+		if len(sessions) == 0 {
+			log.Error("No agents could be spawned", "issue", tag)
+			continue
+		}
+		imported++
+		fmt.Fprintf(imp.out, "%s: spawned %s\n", tag, strings.Join(sessions, ", "))
+
+		if opts.noComment {
+			continue
+		}
+		if err := imp.provider.Comment(ctx, issue, imp.branchComment(sessions)); err != nil {
+			log.Warn("Failed to comment on issue", "issue", tag, "error", err)
+		}
+	}
+
+	if opts.dryRun {
+		return nil
+	}
+	fmt.Fprintf(imp.out, "Imported %d issue(s)\n", imported)
+	return nil
+}
+
+// branchComment builds the issue comment that links the agent branches
+func (imp *issueImporter) branchComment(sessions []string) string {
+	var b strings.Builder
+	b.WriteString("uzi started working on this issue:\n")
+	for _, sessionName := range sessions {
+		agentName := state.AgentNameFromSession(sessionName)
+		info, err := imp.sessions.GetWorktreeInfo(sessionName)
+		if err != nil || info.BranchName == "" {
+			fmt.Fprintf(&b, "\n- %s", agentName)
+			continue
+		}
+		fmt.Fprintf(&b, "\n- %s on branch `%s`", agentName, info.BranchName)
+	}
+	return b.String()
+}
+
+// issueTag returns the session tag that ties a session to its issue, e.g. github#42
+func issueTag(provider Provider, issue Issue) string {
+	return fmt.Sprintf("%s#%d", provider.Name(), issue.Number)
+}
+
+// issuePrompt turns an issue into a single-line agent prompt. The prompt is typed
+// into the agent pane's shell inside quotes, and issue text is untrusted, so
+// quotes and characters the shell would expand are dropped.
+func issuePrompt(issue Issue) string {
+	text := issue.Title
+	if body := strings.TrimSpace(issue.Body); body != "" {
+		text += ": " + body
+	}
+	text = strings.Map(func(r rune) rune {
+		switch r {
+		case '\'', '"', '`', '$', '\\':
+			return -1
+		}
+		return r
+	}, text)
+	return strings.Join(strings.Fields(text), " ")
+}
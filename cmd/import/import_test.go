@@ -0,0 +1,137 @@
+package importer
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/nehpz/claudicus/cmd/prompt"
+	"github.com/nehpz/claudicus/pkg/state"
+)
+
+// MockProvider serves a fixed list of issues and records comments
+type MockProvider struct {
+	issues   []Issue
+	comments map[int]string
+}
+
+func (m *MockProvider) Name() string { return "github" }
+
+func (m *MockProvider) ListIssues(ctx context.Context, label string, limit int) ([]Issue, error) {
+	return m.issues, nil
+}
+
+func (m *MockProvider) Comment(ctx context.Context, issue Issue, body string) error {
+	m.comments[issue.Number] = body
+	return nil
+}
+
+// MockSessionStore keeps tagged sessions in memory
+type MockSessionStore struct {
+	states map[string]state.AgentState
+}
+
+func (m *MockSessionStore) SessionsWithTag(tag string) ([]string, error) {
+	var sessions []string
+	for name, s := range m.states {
+		if s.HasTag(tag) {
+			sessions = append(sessions, name)
+		}
+	}
+	return sessions, nil
+}
+
+func (m *MockSessionStore) GetWorktreeInfo(sessionName string) (*state.AgentState, error) {
+	s, ok := m.states[sessionName]
+	if !ok {
+		return nil, fmt.Errorf("no state found for session: %s", sessionName)
+	}
+	return &s, nil
+}
+
+func newTestImporter(issues []Issue) (*issueImporter, *MockProvider, *[]prompt.SpawnOptions, *bytes.Buffer) {
+	provider := &MockProvider{issues: issues, comments: map[int]string{}}
+	store := &MockSessionStore{states: map[string]state.AgentState{
+		"agent-repo-abc123-old": {BranchName: "old-branch", Tags: []string{"github#1"}},
+	}}
+	var spawned []prompt.SpawnOptions
+	out := &bytes.Buffer{}
+	imp := &issueImporter{
+		provider: provider,
+		sessions: store,
+		spawn: func(ctx context.Context, opts prompt.SpawnOptions) ([]string, error) {
+			spawned = append(spawned, opts)
+			name := fmt.Sprintf("agent-repo-abc123-agent%d", len(spawned))
+			store.states[name] = state.AgentState{BranchName: fmt.Sprintf("branch-%d", len(spawned)), Tags: opts.Tags}
+			return []string{name}, nil
+		},
+		out: out,
+	}
+	return imp, provider, &spawned, out
+}
+
+func TestImportSkipsImportedIssues(t *testing.T) {
+	imp, provider, spawned, out := newTestImporter([]Issue{
+		{Number: 1, Title: "Already running"},
+		{Number: 2, Title: "Fix login", Body: "The form rejects valid emails"},
+	})
+
+	if err := imp.run(context.Background(), importOptions{label: "agent-task", agents: "claude:1"}); err != nil {
+		t.Fatalf("run() error = %v", err)
+	}
+
+	if len(*spawned) != 1 {
+		t.Fatalf("Expected one spawn, got %d", len(*spawned))
+	}
+	opts := (*spawned)[0]
+	if opts.Prompt != "Fix login: The form rejects valid emails" || opts.Agents != "claude:1" {
+		t.Errorf("Unexpected spawn options %+v", opts)
+	}
+	if len(opts.Tags) != 1 || opts.Tags[0] != "github#2" {
+		t.Errorf("Expected session tagged github#2, got %v", opts.Tags)
+	}
+
+	if comment := provider.comments[2]; !strings.Contains(comment, "agent1 on branch `branch-1`") {
+		t.Errorf("Expected comment linking the branch, got %q", comment)
+	}
+	if _, ok := provider.comments[1]; ok {
+		t.Error("Expected no comment on the skipped issue")
+	}
+	if !strings.Contains(out.String(), "github#1: skipped") {
+		t.Errorf("Expected skipped issue to be reported, got %q", out.String())
+	}
+}
+
+func TestImportDryRunAndNoComment(t *testing.T) {
+	imp, provider, spawned, _ := newTestImporter([]Issue{{Number: 3, Title: "Add dark mode"}})
+	if err := imp.run(context.Background(), importOptions{label: "agent-task", dryRun: true}); err != nil {
+		t.Fatalf("run() error = %v", err)
+	}
+	if len(*spawned) != 0 {
+		t.Errorf("Expected dry run not to spawn, got %d", len(*spawned))
+	}
+
+	if err := imp.run(context.Background(), importOptions{label: "agent-task", noComment: true}); err != nil {
+		t.Fatalf("run() error = %v", err)
+	}
+	if len(*spawned) != 1 || len(provider.comments) != 0 {
+		t.Errorf("Expected a spawn without comments, got %d spawns and %v", len(*spawned), provider.comments)
+	}
+}
+
+func TestIssuePrompt(t *testing.T) {
+	got := issuePrompt(Issue{
+		Title: "Don't crash",
+		Body:  "Run `rm -rf $HOME`\n\nand \"quote\" \\ it",
+	})
+	want := "Dont crash: Run rm -rf HOME and quote it"
+	if got != want {
+		t.Errorf("issuePrompt() = %q, want %q", got, want)
+	}
+
+	if got := issuePrompt(Issue{Title: "Title only"}); got != "Title only" {
+		t.Errorf("issuePrompt() = %q, want title only", got)
+	}
+}
@@ -0,0 +1,33 @@
+package importer
+
+import (
+	"context"
+
+	"github.com/nehpz/claudicus/pkg/tmuxops"
+)
+
+// Issue is a task fetched from an issue tracker
+type Issue struct {
+	Number int    `json:"number"`
+	Title  string `json:"title"`
+	Body   string `json:"body"`
+	URL    string `json:"url"`
+}
+
+// Provider fetches open issues from an issue tracker and reports back on them
+type Provider interface {
+	// Name identifies the provider and prefixes the session tags, e.g. "github"
+	Name() string
+
+	// ListIssues returns up to limit open issues carrying label
+	ListIssues(ctx context.Context, label string, limit int) ([]Issue, error)
+
+	// Comment posts body as a comment on issue
+	Comment(ctx context.Context, issue Issue, body string) error
+}
+
+// CommandExecutor abstracts the execution of external commands
+type CommandExecutor = tmuxops.ContextExecutor
+
+// RealCommandExecutor implements CommandExecutor using exec.CommandContext
+type RealCommandExecutor = tmuxops.RealCommandExecutor
@@ -0,0 +1,373 @@
+package initcmd
+
+import (
+	"bufio"
+	"context"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"github.com/nehpz/claudicus/cmd/prompt"
+	"github.com/nehpz/claudicus/pkg/config"
+	"github.com/nehpz/claudicus/pkg/tmuxops"
+
+	"github.com/peterbourgon/ff/v3/ffcli"
+)
+
+// demoPrompt is given to the demo agent; it only reads, so trying uzi out
+// leaves the repository as it was
+const demoPrompt = "Read through this repository and summarize how it is organized: the main packages, how to build and test it, and where to start for a new contributor. Do not change any files."
+
+// knownAgents are the agent CLIs uzi can drive, in order of preference
+var knownAgents = []string{"claude", "codex", "cursor", "gemini"}
+
+var (
+	fs         = flag.NewFlagSet("uzi init", flag.ExitOnError)
+	configPath = fs.String("config", config.GetDefaultConfigPath(), "path of the config file to create")
+	force      = fs.Bool("force", false, "overwrite an existing config file")
+	defaults   = fs.Bool("defaults", false, "accept the suggested answers without asking and skip the demo agent")
+	CmdInit    = &ffcli.Command{
+		Name:       "init",
+		ShortUsage: "uzi init [--config PATH] [--force] [--defaults]",
+		ShortHelp:  "Create uzi.yaml interactively and check prerequisites",
+		LongHelp: `
+The init command checks that git, tmux and at least one agent CLI are
+installed, then asks for the dev command, port range, preferred agents and
+worktree directory and writes them to uzi.yaml. Each question suggests an
+answer based on the repository; press Enter to accept it.
+
+At the end it offers to spawn a demo agent that summarizes the repository
+without changing any files.
+`,
+		FlagSet: fs,
+		Exec:    executeInit,
+	}
+)
+
+// check is the outcome of one prerequisite check
+type check struct {
+	name     string
+	ok       bool
+	detail   string
+	required bool
+}
+
+// wizard asks the onboarding questions. Its lookups are swappable so tests
+// don't depend on the tools installed on the machine.
+type wizard struct {
+	in       *bufio.Reader
+	out      io.Writer
+	defaults bool
+	lookPath func(file string) (string, error)
+	git      func(args ...string) (string, error)
+	tmux     func(args ...string) (string, error)
+	exists   func(path string) bool
+	spawn    func(ctx context.Context, opts prompt.SpawnOptions) ([]string, error)
+}
+
+// answers are the settings written to uzi.yaml
+type answers struct {
+	devCommand  string
+	portRange   string
+	agents      string
+	worktreeDir string
+}
+
+func executeInit(ctx context.Context, args []string) error {
+	if _, err := os.Stat(*configPath); err == nil && !*force {
+		return fmt.Errorf("%s already exists; use --force to replace it", *configPath)
+	}
+
+	w := &wizard{
+		in:       bufio.NewReader(os.Stdin),
+		out:      os.Stdout,
+		defaults: *defaults,
+		lookPath: exec.LookPath,
+		git: func(args ...string) (string, error) {
+			cmd := exec.CommandContext(ctx, "git", args...)
+			cmd.Dir = filepath.Dir(os.Args[0])
+			out, err := cmd.Output()
+			return strings.TrimSpace(string(out)), err
+		},
+		tmux: func(args ...string) (string, error) {
+			out, err := exec.CommandContext(ctx, "tmux", args...).Output()
+			return strings.TrimSpace(string(out)), err
+		},
+		exists: func(path string) bool {
+			_, err := os.Stat(path)
+			return err == nil
+		},
+		spawn: prompt.Spawn,
+	}
+	return w.run(ctx, *configPath)
+}
+
+// run checks the prerequisites, asks the questions, writes the config and
+// offers the demo agent
+func (w *wizard) run(ctx context.Context, path string) error {
+	fmt.Fprintln(w.out, "Checking prerequisites...")
+	checks := w.checkPrerequisites()
+	failed := false
+	for _, c := range checks {
+		mark := "✓"
+		if !c.ok {
+			mark = "!"
+			if c.required {
+				mark = "✗"
+				failed = true
+			}
+		}
+		fmt.Fprintf(w.out, "  %s %s: %s\n", mark, c.name, c.detail)
+	}
+	if failed {
+		return fmt.Errorf("install the missing prerequisites and run uzi init again")
+	}
+	fmt.Fprintln(w.out)
+
+	a, err := w.ask()
+	if err != nil {
+		return err
+	}
+	if err := writeConfig(path, a); err != nil {
+		return err
+	}
+	fmt.Fprintf(w.out, "\nWrote %s\n", path)
+
+	if w.defaults {
+		fmt.Fprintf(w.out, "Spawn your first agent with: uzi prompt \"<task>\"\n")
+		return nil
+	}
+	if !w.confirm("Spawn a demo agent that summarizes this repository?") {
+		fmt.Fprintf(w.out, "Spawn your first agent with: uzi prompt \"<task>\"\n")
+		return nil
+	}
+	demoAgents := strings.SplitN(a.agents, ",", 2)[0]
+	demoAgents = strings.SplitN(demoAgents, ":", 2)[0] + ":1"
+	spawned, err := w.spawn(ctx, prompt.SpawnOptions{ConfigPath: path, Agents: demoAgents, Prompt: demoPrompt, Tags: []string{"demo"}})
+	if err != nil {
+		return fmt.Errorf("failed to spawn the demo agent: %w", err)
+	}
+	if len(spawned) == 0 {
+		return fmt.Errorf("the demo agent could not be spawned; see the errors above")
+	}
+	fmt.Fprintf(w.out, "Spawned %s. Watch it with `uzi tui` or attach with `uzi attach %s`, and remove it with `uzi kill %s`.\n", spawned[0], spawned[0], spawned[0])
+	return nil
+}
+
+// checkPrerequisites checks for the tools and repository setup agents need
+func (w *wizard) checkPrerequisites() []check {
+	var checks []check
+	for _, tool := range []string{"git", "tmux"} {
+		c := check{name: tool, required: true}
+		if path, err := w.lookPath(tool); err == nil {
+			c.ok, c.detail = true, path
+		} else {
+			c.detail = "not found in PATH"
+		}
+		if c.ok && tool == "tmux" {
+			c = w.checkTmuxVersion(c)
+		}
+		checks = append(checks, c)
+	}
+
+	repo := check{name: "repository", required: true}
+	if root, err := w.git("rev-parse", "--show-toplevel"); err == nil {
+		repo.ok, repo.detail = true, root
+	} else {
+		repo.detail = "not inside a git repository"
+	}
+	checks = append(checks, repo)
+
+	origin := check{name: "origin remote", required: true}
+	if url, err := w.git("remote", "get-url", "origin"); err == nil {
+		origin.ok, origin.detail = true, url
+	} else {
+		origin.detail = "no origin remote; sessions are named after the origin repository"
+	}
+	checks = append(checks, origin)
+
+	found := w.installedAgents()
+	agentCheck := check{name: "agent CLIs", required: true, ok: len(found) > 0}
+	if agentCheck.ok {
+		agentCheck.detail = strings.Join(found, ", ")
+	} else {
+		agentCheck.detail = "none of " + strings.Join(knownAgents, ", ") + " found in PATH"
+	}
+	return append(checks, agentCheck)
+}
+
+// checkTmuxVersion adds the installed tmux version to its check, failing it
+// when the version is older than uzi supports
+func (w *wizard) checkTmuxVersion(c check) check {
+	output, err := w.tmux("-V")
+	if err != nil {
+		c.ok, c.detail = false, fmt.Sprintf("%s does not run: %v", c.detail, err)
+		return c
+	}
+	version, err := tmuxops.ParseVersion(output)
+	if err != nil {
+		// An unrecognized version string is no reason to stop
+		return c
+	}
+	if err := version.Supported(); err != nil {
+		c.ok, c.detail = false, fmt.Sprintf("tmux %s is too old; uzi needs tmux %s or newer", version, tmuxops.MinVersion)
+		return c
+	}
+	c.detail += " (tmux " + version.String() + ")"
+	return c
+}
+
+// installedAgents returns the known agent CLIs found in PATH
+func (w *wizard) installedAgents() []string {
+	var found []string
+	for _, agent := range knownAgents {
+		if _, err := w.lookPath(agent); err == nil {
+			found = append(found, agent)
+		}
+	}
+	return found
+}
+
+// ask asks for each setting, suggesting an answer based on the repository
+func (w *wizard) ask() (answers, error) {
+	var a answers
+	var err error
+
+	a.devCommand, err = w.question("Dev command, with $PORT where the port goes", w.suggestDevCommand(), func(s string) error {
+		if !strings.Contains(s, "$PORT") {
+			return fmt.Errorf("the command must contain $PORT so each agent gets its own port")
+		}
+		return nil
+	})
+	if err != nil {
+		return a, err
+	}
+
+	a.portRange, err = w.question("Port range for dev servers", "3000-3010", func(s string) error {
+		_, _, err := config.ParsePortRange(s)
+		return err
+	})
+	if err != nil {
+		return a, err
+	}
+
+	suggested := "claude:1"
+	if found := w.installedAgents(); len(found) > 0 {
+		suggested = found[0] + ":1"
+	}
+	a.agents, err = w.question("Preferred agents, as agent:count[,agent:count...]", suggested, validateAgents)
+	if err != nil {
+		return a, err
+	}
+
+	a.worktreeDir, err = w.question("Worktree directory (empty for ~/.local/share/uzi/worktrees)", "", nil)
+	return a, err
+}
+
+// suggestDevCommand guesses the dev command from the files in the repository
+func (w *wizard) suggestDevCommand() string {
+	switch {
+	case w.exists("pnpm-lock.yaml"):
+		return "pnpm dev --port $PORT"
+	case w.exists("yarn.lock"):
+		return "yarn dev --port $PORT"
+	case w.exists("package.json"):
+		return "npm run dev -- --port $PORT"
+	case w.exists("manage.py"):
+		return "python manage.py runserver $PORT"
+	case w.exists("Gemfile"):
+		return "bin/rails server -p $PORT"
+	default:
+		return "python3 -m http.server $PORT"
+	}
+}
+
+// question asks for one setting until the answer passes validate. An empty
+// answer takes the suggestion; with --defaults the suggestion is taken
+// without asking.
+func (w *wizard) question(label, suggestion string, validate func(string) error) (string, error) {
+	for {
+		answer := suggestion
+		if !w.defaults {
+			if suggestion != "" {
+				fmt.Fprintf(w.out, "%s [%s]: ", label, suggestion)
+			} else {
+				fmt.Fprintf(w.out, "%s: ", label)
+			}
+			line, err := w.in.ReadString('\n')
+			if err != nil && line == "" {
+				fmt.Fprintln(w.out)
+				return "", fmt.Errorf("no answer for %q; use --defaults to accept the suggestions", label)
+			}
+			if line = strings.TrimSpace(line); line != "" {
+				answer = line
+			}
+		}
+		if validate == nil {
+			return answer, nil
+		}
+		err := validate(answer)
+		if err == nil {
+			return answer, nil
+		}
+		if w.defaults {
+			return "", err
+		}
+		fmt.Fprintf(w.out, "  %v\n", err)
+	}
+}
+
+// confirm asks a yes/no question that defaults to no
+func (w *wizard) confirm(label string) bool {
+	fmt.Fprintf(w.out, "%s (y/N): ", label)
+	line, _ := w.in.ReadString('\n')
+	line = strings.ToLower(strings.TrimSpace(line))
+	return line == "y" || line == "yes"
+}
+
+// validateAgents checks an answer in the --agents format
+func validateAgents(s string) error {
+	for _, pair := range strings.Split(s, ",") {
+		name, count, ok := strings.Cut(strings.TrimSpace(pair), ":")
+		if !ok || name == "" {
+			return fmt.Errorf("%q is not in agent:count format", pair)
+		}
+		if n, err := strconv.Atoi(count); err != nil || n < 1 {
+			return fmt.Errorf("count for %s must be a positive number", name)
+		}
+	}
+	return nil
+}
+
+// writeConfig writes the answers as a commented uzi.yaml and checks that it loads
+func writeConfig(path string, a answers) error {
+	var b strings.Builder
+	b.WriteString("# Generated by uzi init. See the README for every setting.\n\n")
+	b.WriteString("# Command each agent's dev server runs with; $PORT is replaced with a free port\n")
+	fmt.Fprintf(&b, "devCommand: %s\n", strconv.Quote(a.devCommand))
+	b.WriteString("# Ports handed out to dev servers, one per agent\n")
+	fmt.Fprintf(&b, "portRange: %s\n", strconv.Quote(a.portRange))
+	b.WriteString("# Agents uzi prompt spawns when --agents is not given, and the TUI's default\n")
+	fmt.Fprintf(&b, "defaultAgents: %s\n", strconv.Quote(a.agents))
+	if a.worktreeDir != "" {
+		b.WriteString("# Where agent worktrees are created\n")
+		fmt.Fprintf(&b, "worktreeDir: %s\n", strconv.Quote(a.worktreeDir))
+	}
+
+	if err := os.WriteFile(path, []byte(b.String()), 0644); err != nil {
+		return fmt.Errorf("failed to write %s: %w", path, err)
+	}
+	cfg, err := config.LoadConfig(path)
+	if err == nil {
+		err = cfg.Validate()
+	}
+	if err != nil {
+		return fmt.Errorf("%s was written but is not valid: %w", path, err)
+	}
+	return nil
+}
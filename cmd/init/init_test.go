@@ -0,0 +1,156 @@
+package initcmd
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/nehpz/claudicus/cmd/prompt"
+	"github.com/nehpz/claudicus/pkg/config"
+)
+
+// newTestWizard answers from input on a machine with the given tools installed
+// and files in the repository
+func newTestWizard(input string, tools []string, files []string) (*wizard, *bytes.Buffer, *[]prompt.SpawnOptions) {
+	out := &bytes.Buffer{}
+	var spawned []prompt.SpawnOptions
+	w := &wizard{
+		in:  bufio.NewReader(strings.NewReader(input)),
+		out: out,
+		lookPath: func(file string) (string, error) {
+			for _, tool := range tools {
+				if tool == file {
+					return "/usr/bin/" + file, nil
+				}
+			}
+			return "", errors.New("not found")
+		},
+		git: func(args ...string) (string, error) {
+			if args[0] == "remote" {
+				return "git@github.com:acme/app.git", nil
+			}
+			return "/src/app", nil
+		},
+		tmux: func(args ...string) (string, error) {
+			return "tmux 3.4", nil
+		},
+		exists: func(path string) bool {
+			for _, file := range files {
+				if file == path {
+					return true
+				}
+			}
+			return false
+		},
+		spawn: func(ctx context.Context, opts prompt.SpawnOptions) ([]string, error) {
+			spawned = append(spawned, opts)
+			return []string{"sarah"}, nil
+		},
+	}
+	return w, out, &spawned
+}
+
+func TestRunWritesConfig(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "uzi.yaml")
+	// Accept the dev command, retry a bad port range, pick two agents,
+	// keep the default worktree dir, then spawn the demo
+	input := "\n3010-3000\n4000-4005\nclaude:1,codex:2\n\ny\n"
+	w, out, spawned := newTestWizard(input, []string{"git", "tmux", "codex", "claude"}, []string{"package.json"})
+
+	if err := w.run(context.Background(), path); err != nil {
+		t.Fatalf("run() error = %v\n%s", err, out)
+	}
+	cfg, err := config.LoadConfig(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if *cfg.DevCommand != "npm run dev -- --port $PORT" || *cfg.PortRange != "4000-4005" || *cfg.DefaultAgents != "claude:1,codex:2" || cfg.WorktreeDir != nil {
+		t.Errorf("Unexpected config: %+v", cfg)
+	}
+	if !strings.Contains(out.String(), "Preferred agents, as agent:count[,agent:count...] [claude:1]") {
+		t.Errorf("Expected claude suggested first, got:\n%s", out)
+	}
+	if !strings.Contains(out.String(), "invalid portRange") {
+		t.Errorf("Expected the bad port range to be rejected, got:\n%s", out)
+	}
+	if len(*spawned) != 1 || (*spawned)[0].Agents != "claude:1" || (*spawned)[0].ConfigPath != path {
+		t.Errorf("Expected one demo agent spawned with the new config, got %+v", *spawned)
+	}
+}
+
+func TestRunDefaults(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "uzi.yaml")
+	w, out, spawned := newTestWizard("", []string{"git", "tmux", "gemini"}, nil)
+	w.defaults = true
+
+	if err := w.run(context.Background(), path); err != nil {
+		t.Fatalf("run() error = %v\n%s", err, out)
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(string(data), `defaultAgents: "gemini:1"`) || !strings.Contains(string(data), `portRange: "3000-3010"`) {
+		t.Errorf("Expected the suggested answers, got:\n%s", data)
+	}
+	if len(*spawned) != 0 {
+		t.Error("Expected --defaults to skip the demo agent")
+	}
+}
+
+func TestRunMissingPrerequisites(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "uzi.yaml")
+	w, out, _ := newTestWizard("", []string{"git"}, nil)
+
+	if err := w.run(context.Background(), path); err == nil {
+		t.Fatal("Expected missing tmux and agents to stop the wizard")
+	}
+	for _, want := range []string{"✗ tmux: not found in PATH", "✗ agent CLIs: none of claude, codex, cursor, gemini"} {
+		if !strings.Contains(out.String(), want) {
+			t.Errorf("Expected %q in output:\n%s", want, out)
+		}
+	}
+	if _, err := os.Stat(path); err == nil {
+		t.Error("Expected no config written")
+	}
+}
+
+func TestCheckTmuxVersion(t *testing.T) {
+	for _, tt := range []struct {
+		output string
+		ok     bool
+		detail string
+	}{
+		{"tmux 3.4", true, "/usr/bin/tmux (tmux 3.4)"},
+		{"tmux next-3.5", true, "/usr/bin/tmux (tmux next-3.5)"},
+		{"tmux 1.8", false, "tmux 1.8 is too old; uzi needs tmux 1.9 or newer"},
+		{"something else", true, "/usr/bin/tmux"},
+	} {
+		w, _, _ := newTestWizard("", []string{"git", "tmux", "claude"}, nil)
+		w.tmux = func(args ...string) (string, error) { return tt.output, nil }
+		for _, c := range w.checkPrerequisites() {
+			if c.name == "tmux" && (c.ok != tt.ok || c.detail != tt.detail) {
+				t.Errorf("%q: got ok=%v %q, want ok=%v %q", tt.output, c.ok, c.detail, tt.ok, tt.detail)
+			}
+		}
+	}
+}
+
+func TestValidateAgents(t *testing.T) {
+	for input, valid := range map[string]bool{
+		"claude:1":          true,
+		"claude:1, codex:2": true,
+		"claude":            false,
+		"claude:0":          false,
+		":1":                false,
+	} {
+		if err := validateAgents(input); (err == nil) != valid {
+			t.Errorf("validateAgents(%q) = %v, want valid %v", input, err, valid)
+		}
+	}
+}
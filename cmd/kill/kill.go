@@ -1,35 +1,258 @@
 package kill
 
 import (
+	"bufio"
 	"context"
+	"errors"
 	"flag"
 	"fmt"
+	"io"
 	"os"
 	"os/exec"
 	"path/filepath"
 	"strings"
+	"time"
 
+	"github.com/nehpz/claudicus/cmd/checkpoint"
+	"github.com/nehpz/claudicus/cmd/prompt"
+	"github.com/nehpz/claudicus/pkg/cmdctx"
+	"github.com/nehpz/claudicus/pkg/config"
+	"github.com/nehpz/claudicus/pkg/events"
+	"github.com/nehpz/claudicus/pkg/hosts"
 	"github.com/nehpz/claudicus/pkg/state"
+	"github.com/nehpz/claudicus/pkg/trash"
+	"github.com/nehpz/claudicus/pkg/tui"
+	"github.com/nehpz/claudicus/pkg/vcs"
 
 	"github.com/charmbracelet/log"
 	"github.com/peterbourgon/ff/v3/ffcli"
 )
 
 var (
-	fs      = flag.NewFlagSet("uzi kill", flag.ExitOnError)
-	CmdKill = &ffcli.Command{
+	fs         = flag.NewFlagSet("uzi kill", flag.ExitOnError)
+	force      = fs.Bool("force", false, "kill without checking the agent for uncommitted or unmerged work")
+	permanent  = fs.Bool("permanent", false, "delete the worktree and branch right away instead of moving them to the trash")
+	yes        = fs.Bool("yes", false, "confirm the kill without asking when policy.confirmKill is always")
+	runFlag    = fs.String("run", "", "kill every agent of the run spawned with uzi prompt --run-name")
+	configPath = fs.String("config", config.GetDefaultConfigPath(), "path to config file")
+	CmdKill    = &ffcli.Command{
 		Name:       "kill",
-		ShortUsage: "uzi kill [<agent-name>|all]",
+		ShortUsage: "uzi kill [--force] [<agent-name>|all] | uzi kill --run <run-name>",
 		ShortHelp:  "Delete tmux session and git worktree for the specified agent",
-		FlagSet:    fs,
-		Exec:       executeKill,
+		LongHelp: `Delete the tmux session, git worktree, and branch of an agent. Without an
+agent name on a terminal, the agent is picked from a list.
+
+By default a kill can be undone: the tmux session is killed, but the worktree,
+with its branch and uncommitted work, and the agent's state are moved to the
+trash for trashRetention (24h unless set in uzi.yaml). uzi undo brings back the
+last agent killed and uzi trash lists, restores, and purges the others.
+Expired agents are purged on the next kill.
+
+With --permanent, or trashRetention: 0, and for sessions on remote hosts, the
+worktree and branch are deleted right away. Before such a kill of a single
+agent, its worktree is checked for uncommitted changes and commits not merged
+into the branch it started from. If there are any, you are asked to
+[c]heckpoint first, [k]ill anyway, or [a]bort. Without a terminal to ask on,
+the kill is refused; pass --force to skip the check.
+
+The policy: section of uzi.yaml can change when kills ask. With confirmKill:
+always every kill, including uzi kill all, asks to be confirmed first; --yes
+confirms it up front, and without a terminal and --yes the kill is refused.
+confirmKill: never skips the pending work check as --force does.
+
+--run kills the agents of one run, as uzi kill all does for every agent.`,
+		FlagSet: fs,
+		Exec:    executeKill,
 	}
+
+	// repo removes the worktrees and branches of killed agents
+	repo vcs.VCS = vcs.Git{}
 )
 
-// killSession kills a single session and cleans up its associated resources
-func killSession(ctx context.Context, sessionName, agentName string, sm *state.StateManager) error {
+// killChoice is the answer to the pending work warning
+type killChoice int
+
+const (
+	choiceAbort killChoice = iota
+	choiceKill
+	choiceCheckpoint
+)
+
+// askPendingWork warns that killing an agent would lose work and asks what to
+// do. For a checkpoint it also asks for the commit message.
+func askPendingWork(agentName string, work state.PendingWork, in io.Reader, out io.Writer) (killChoice, string, error) {
+	reader := bufio.NewReader(in)
+	fmt.Fprintf(out, "%s [c]heckpoint / [k]ill anyway / [a]bort: ", work.KillWarning(agentName))
+	answer, err := reader.ReadString('\n')
+	if err != nil && answer == "" {
+		// No terminal to answer on
+		fmt.Fprintln(out)
+		return choiceAbort, "", fmt.Errorf("%s has %s; checkpoint first or use --force to kill anyway", agentName, work)
+	}
+
+	switch strings.ToLower(strings.TrimSpace(answer)) {
+	case "c", "checkpoint":
+		fmt.Fprint(out, "Commit message: ")
+		message, err := reader.ReadString('\n')
+		message = strings.TrimSpace(message)
+		if message == "" {
+			if err != nil {
+				fmt.Fprintln(out)
+			}
+			return choiceAbort, "", fmt.Errorf("a commit message is required to checkpoint %s", agentName)
+		}
+		return choiceCheckpoint, message, nil
+	case "k", "kill":
+		return choiceKill, "", nil
+	default:
+		return choiceAbort, "", nil
+	}
+}
+
+// askConfirmKill asks to confirm a kill under confirmKill: always. Without a
+// terminal to ask on, the kill is refused with a policy violation.
+func askConfirmKill(what string, in io.Reader, out io.Writer) (bool, error) {
+	fmt.Fprintf(out, "Kill %s? [y/N]: ", what)
+	answer, err := bufio.NewReader(in).ReadString('\n')
+	if err != nil && answer == "" {
+		fmt.Fprintln(out)
+		return false, &config.PolicyViolation{Rule: "confirmKill", Operation: "kill", Reason: fmt.Sprintf("killing %s must be confirmed; pass --yes to confirm without a terminal", what)}
+	}
+	switch strings.ToLower(strings.TrimSpace(answer)) {
+	case "y", "yes":
+		return true, nil
+	}
+	return false, nil
+}
+
+// confirmKill checks the agent for pending work and, if there is any, asks
+// whether to checkpoint it first. It returns false if the kill was aborted.
+func confirmKill(ctx context.Context, sessionName, agentName string, sm *state.StateManager) (bool, error) {
+	agentState, err := sm.GetWorktreeInfo(sessionName)
+	if err != nil {
+		// Without state there is no worktree left to lose
+		return true, nil
+	}
+	var executor state.CommandExecutor = &state.DefaultCommandExecutor{}
+	if agentState.IsRemote() {
+		executor = hosts.ForState(*agentState)
+	}
+	work, err := state.InspectWork(executor, *agentState)
+	if err != nil {
+		return false, fmt.Errorf("could not check %s for pending work: %w; use --force to kill anyway", agentName, err)
+	}
+	if work.Empty() {
+		return true, nil
+	}
+
+	choice, message, err := askPendingWork(agentName, work, os.Stdin, os.Stdout)
+	if err != nil {
+		return false, err
+	}
+	switch choice {
+	case choiceCheckpoint:
+		if err := checkpoint.CmdCheckpoint.Exec(ctx, []string{agentName, message}); err != nil {
+			return false, fmt.Errorf("checkpoint failed, %s was not killed: %w", agentName, err)
+		}
+		return true, nil
+	case choiceKill:
+		return true, nil
+	default:
+		return false, nil
+	}
+}
+
+// openTrash returns the trash killed agents are moved to, after purging the
+// agents that have been in it longer than trashRetention. It returns nil when
+// kills are permanent.
+func openTrash(ctx context.Context) *trash.Store {
+	if *permanent {
+		return nil
+	}
+	// Without a config file the default retention is used
+	cfg, _ := cmdctx.From(ctx).LoadConfig(*configPath)
+	retention := cfg.TrashRetention()
+	if retention <= 0 {
+		return nil
+	}
+	bin, err := trash.NewStore()
+	if err != nil {
+		log.Warn("Could not open the trash, killing permanently", "error", err)
+		return nil
+	}
+	purged, err := bin.PurgeExpired(ctx, retention, time.Now())
+	if err != nil {
+		log.Warn("Failed to purge expired agents from the trash", "error", err)
+	}
+	for _, entry := range purged {
+		log.Debug("Purged expired agent from the trash", "session", entry.Session)
+	}
+	return bin
+}
+
+// trashable reports whether a kill moves the session to the trash: only
+// local sessions can be restored
+func trashable(bin *trash.Store, sessionName string, sm *state.StateManager) bool {
+	if bin == nil {
+		return false
+	}
+	info, err := sm.GetWorktreeInfo(sessionName)
+	return err == nil && !info.IsRemote()
+}
+
+// trashSession kills the tmux session of a local agent and moves its
+// worktree, with the branch and any uncommitted work, and its state to the
+// trash, where uzi undo can restore them
+func trashSession(ctx context.Context, sessionName string, info state.AgentState, sm *state.StateManager, bin *trash.Store) error {
+	if exec.CommandContext(ctx, "tmux", "has-session", "-t", sessionName).Run() == nil {
+		if err := exec.CommandContext(ctx, "tmux", "kill-session", "-t", sessionName).Run(); err != nil {
+			log.Error("Error killing tmux session", "session", sessionName, "error", err)
+		} else {
+			log.Debug("Killed tmux session", "session", sessionName)
+		}
+	}
+
+	entry := trash.Entry{Repo: info.GitRepo, Session: sessionName, State: info}
+	if !info.IsShared() && info.WorktreePath != "" {
+		if _, err := os.Stat(info.WorktreePath); err == nil {
+			entry.TrashPath = bin.WorktreePath(sessionName)
+			if err := trash.MoveWorktree(ctx, info.WorktreePath, entry.TrashPath); err != nil {
+				return fmt.Errorf("failed to move worktree to the trash: %w", err)
+			}
+			log.Debug("Moved worktree to the trash", "from", info.WorktreePath, "to", entry.TrashPath)
+		}
+	}
+	if _, err := bin.Add(entry); err != nil {
+		return fmt.Errorf("failed to save %s in the trash (its worktree is in %s): %w", sessionName, entry.TrashPath, err)
+	}
+
+	if err := sm.RemoveState(sessionName); err != nil {
+		log.Error("Error removing state entry", "session", sessionName, "error", err)
+	}
+	return nil
+}
+
+// killSession kills a single session and cleans up its associated resources.
+// Local sessions are moved to bin unless it is nil; it reports whether the
+// session was.
+func killSession(ctx context.Context, sessionName, agentName string, sm *state.StateManager, bin *trash.Store) (bool, error) {
 	log.Debug("Deleting tmux session and git worktree", "session", sessionName, "agent", agentName)
 
+	lock, err := sm.LockSession(sessionName, "kill")
+	if err != nil {
+		return false, err
+	}
+	defer lock.Release()
+
+	if info, err := sm.GetWorktreeInfo(sessionName); err == nil {
+		if info.IsRemote() {
+			return false, killRemoteSession(ctx, sessionName, *info, sm)
+		}
+		if bin != nil {
+			return true, trashSession(ctx, sessionName, *info, sm, bin)
+		}
+	}
+
 	// Kill tmux session if it exists
 	checkSession := exec.CommandContext(ctx, "tmux", "has-session", "-t", sessionName)
 	if err := checkSession.Run(); err == nil {
@@ -42,48 +265,57 @@ func killSession(ctx context.Context, sessionName, agentName string, sm *state.S
 		}
 	}
 
+	// Shared sessions run in the main checkout, which must never be removed
+	shared := false
+	if info, err := sm.GetWorktreeInfo(sessionName); err == nil && info.IsShared() {
+		shared = true
+		log.Debug("Skipping worktree removal for shared session", "session", sessionName)
+	}
+
 	// Remove git worktree
 	worktreePath := filepath.Join(filepath.Dir(os.Args[0]), "..", agentName)
-	if _, err := os.Stat(worktreePath); err == nil {
+	if _, err := os.Stat(worktreePath); err == nil && !shared {
 		// Get worktree path from state
 		worktreeInfo, err := sm.GetWorktreeInfo(sessionName)
 		if err != nil {
 			log.Error("Error getting worktree info", "session", sessionName, "error", err)
-			return fmt.Errorf("failed to get worktree info: %w", err)
+			return false, fmt.Errorf("failed to get worktree info: %w", err)
 		}
 
 		// First, remove the worktree
-		removeCmd := exec.CommandContext(ctx, "git", "worktree", "remove", "--force", worktreeInfo.WorktreePath)
-		removeCmd.Dir = filepath.Dir(os.Args[0])
-		if err := removeCmd.Run(); err != nil {
+		repoDir := filepath.Dir(os.Args[0])
+		if err := repo.RemoveWorktree(ctx, repoDir, worktreeInfo.WorktreePath); err != nil {
 			log.Error("Error removing git worktree", "path", worktreeInfo.WorktreePath, "error", err)
-			return fmt.Errorf("failed to remove git worktree: %w", err)
+			return false, fmt.Errorf("failed to remove git worktree: %w", err)
 		}
 		log.Debug("Removed git worktree", "path", worktreeInfo.WorktreePath)
 
-		// Then delete the branch
-		deleteBranchCmd := exec.CommandContext(ctx, "git", "branch", "-D", agentName)
-		deleteBranchCmd.Dir = filepath.Dir(os.Args[0])
-		if err := deleteBranchCmd.Run(); err != nil {
+		// Then delete the branch, unless the session adopted it
+		if ownedBranch(*worktreeInfo) == "" {
+			log.Debug("Keeping adopted git branch", "branch", worktreeInfo.BranchName)
+		} else if err := repo.DeleteBranch(ctx, repoDir, agentName); err != nil {
 			log.Error("Error deleting git branch", "branch", agentName, "error", err)
-			return fmt.Errorf("failed to delete git branch: %w", err)
+			return false, fmt.Errorf("failed to delete git branch: %w", err)
+		} else {
+			log.Debug("Deleted git branch", "branch", agentName)
 		}
-		log.Debug("Deleted git branch", "branch", agentName)
 	}
 
-	// Delete from config store (~/.local/share/uzi/)
-	homeDir, err := os.UserHomeDir()
-	if err == nil {
-		// Remove worktree directory from config store
-		configWorktreePath := filepath.Join(homeDir, ".local", "share", "uzi", "worktrees", agentName)
-		if _, err := os.Stat(configWorktreePath); err == nil {
-			if err := os.RemoveAll(configWorktreePath); err != nil {
-				log.Error("Error removing config worktree", "path", configWorktreePath, "error", err)
+	// Remove any worktree directory git left behind
+	if dir, err := worktreesDir(ctx); err == nil {
+		leftoverPath := filepath.Join(dir, agentName)
+		if _, err := os.Stat(leftoverPath); err == nil {
+			if err := os.RemoveAll(leftoverPath); err != nil {
+				log.Error("Error removing leftover worktree", "path", leftoverPath, "error", err)
 			} else {
-				log.Debug("Removed config worktree", "path", configWorktreePath)
+				log.Debug("Removed leftover worktree", "path", leftoverPath)
 			}
 		}
+	}
 
+	// Delete from config store (~/.local/share/uzi/)
+	homeDir, err := os.UserHomeDir()
+	if err == nil {
 		// Remove worktree state directory
 		worktreeStatePath := filepath.Join(homeDir, ".local", "share", "uzi", "worktree", sessionName)
 		if _, err := os.Stat(worktreeStatePath); err == nil {
@@ -102,11 +334,70 @@ func killSession(ctx context.Context, sessionName, agentName string, sm *state.S
 		}
 	}
 
+	return false, nil
+}
+
+// worktreesDir returns the directory agent worktrees are stored in, from
+// worktreeDir in uzi.yaml or the default
+func worktreesDir(ctx context.Context) (string, error) {
+	// Without a config file the default directory is used
+	cfg, _ := cmdctx.From(ctx).LoadConfig(*configPath)
+	topLevelCmd := exec.CommandContext(ctx, "git", "rev-parse", "--show-toplevel")
+	topLevelCmd.Dir = filepath.Dir(os.Args[0])
+	output, err := topLevelCmd.Output()
+	if err != nil {
+		return "", err
+	}
+	return cfg.ResolveWorktreeDir(strings.TrimSpace(string(output)))
+}
+
+// ownedBranch returns the branch uzi created for a session, which goes with
+// it, or "" for a session on a branch it adopted, which is kept
+func ownedBranch(info state.AgentState) string {
+	if info.Adopted {
+		return ""
+	}
+	return info.BranchName
+}
+
+// removeRemoteWorktreeScript builds the script that removes a worktree and its
+// branch from the repository the worktree belongs to, found through the worktree itself
+func removeRemoteWorktreeScript(worktreePath, branchName string) string {
+	script := fmt.Sprintf(`repo=$(git -C %[1]s rev-parse --path-format=absolute --git-common-dir) && git -C "$repo" worktree remove --force %[1]s`, hosts.Quote(worktreePath))
+	if branchName != "" {
+		script += " && git -C \"$repo\" branch -D " + hosts.Quote(branchName)
+	}
+	return script
+}
+
+// killRemoteSession kills a session on a remote host, removes its worktree and
+// branch there, and forgets it locally
+func killRemoteSession(ctx context.Context, sessionName string, info state.AgentState, sm *state.StateManager) error {
+	target := hosts.ForState(info)
+	if err := target.Command(ctx, "", "tmux", "kill-session", "-t", sessionName).Run(); err != nil {
+		// The session may already be gone; the worktree still needs cleaning up
+		log.Debug("Could not kill remote tmux session", "session", sessionName, "host", target, "error", err)
+	} else {
+		log.Debug("Killed remote tmux session", "session", sessionName, "host", target)
+	}
+
+	if info.WorktreePath != "" && !info.IsShared() {
+		script := removeRemoteWorktreeScript(info.WorktreePath, ownedBranch(info))
+		if output, err := target.Shell(ctx, "", script).CombinedOutput(); err != nil {
+			log.Error("Error removing remote worktree", "path", info.WorktreePath, "host", target, "error", err)
+			return fmt.Errorf("failed to remove worktree on %s: %w: %s", target, err, strings.TrimSpace(string(output)))
+		}
+		log.Debug("Removed remote worktree and branch", "path", info.WorktreePath, "host", target)
+	}
+
+	if err := sm.RemoveState(sessionName); err != nil {
+		log.Error("Error removing state entry", "session", sessionName, "error", err)
+	}
 	return nil
 }
 
 // killAll kills all sessions for the current git repository
-func killAll(ctx context.Context, sm *state.StateManager) error {
+func killAll(ctx context.Context, sm *state.StateManager, cfg *config.Config) error {
 	log.Debug("Deleting all agents for repository")
 
 	// Get active sessions from state
@@ -120,8 +411,55 @@ func killAll(ctx context.Context, sm *state.StateManager) error {
 		fmt.Println("No active sessions found")
 		return nil
 	}
+	return killSessions(ctx, sm, cfg, activeSessions, fmt.Sprintf("all %d agents", len(activeSessions)))
+}
+
+// killRun kills the active sessions of the current git repository spawned in run
+func killRun(ctx context.Context, sm *state.StateManager, cfg *config.Config, run string) error {
+	log.Debug("Deleting all agents of run", "run", run)
+
+	activeSessions, err := sm.GetActiveSessionsForRepo()
+	if err != nil {
+		log.Error("Error getting active sessions", "error", err)
+		return err
+	}
+	runSessions, err := sm.SessionsInRun(run)
+	if err != nil {
+		return err
+	}
+	var sessions []string
+	for _, session := range runSessions {
+		for _, active := range activeSessions {
+			if session == active {
+				sessions = append(sessions, session)
+				break
+			}
+		}
+	}
+
+	if len(sessions) == 0 {
+		fmt.Printf("No active sessions found in run %s\n", run)
+		return nil
+	}
+	return killSessions(ctx, sm, cfg, sessions, fmt.Sprintf("all %d agents of run %s", len(sessions), run))
+}
+
+// killSessions kills several sessions without checking them for pending
+// work, after confirming the kill of what under confirmKill: always
+func killSessions(ctx context.Context, sm *state.StateManager, cfg *config.Config, activeSessions []string, what string) error {
+	if cfg.KillConfirmation() == config.KillConfirmAlways && !*yes {
+		proceed, err := askConfirmKill(what, os.Stdin, os.Stdout)
+		if err != nil {
+			return err
+		}
+		if !proceed {
+			fmt.Println("Kill aborted")
+			return nil
+		}
+	}
 
-	killedCount := 0
+	bin := openTrash(ctx)
+	killedCount, trashedCount := 0, 0
 	for _, sessionName := range activeSessions {
 		// Extract agent name from session name (assuming format: repo-agentName)
 		parts := strings.Split(sessionName, "-")
@@ -131,35 +469,95 @@ func killAll(ctx context.Context, sm *state.StateManager) error {
 		}
 		agentName := parts[len(parts)-1] // Get the last part as agent name
 
-		if err := killSession(ctx, sessionName, agentName, sm); err != nil {
+		trashed, err := killSession(ctx, sessionName, agentName, sm, bin)
+		if err != nil {
 			log.Error("Error killing session", "session", sessionName, "error", err)
 			continue
 		}
 
 		killedCount++
-		fmt.Printf("Deleted agent: %s\n", agentName)
+		cmdctx.From(ctx).Events(cfg).Dispatch(events.NewEvent(events.EventKill, sessionName, ""))
+		if trashed {
+			trashedCount++
+			fmt.Printf("Moved agent to the trash: %s\n", agentName)
+		} else {
+			fmt.Printf("Deleted agent: %s\n", agentName)
+		}
 	}
 
 	fmt.Printf("Successfully deleted %d agent(s)\n", killedCount)
+	if trashedCount > 0 {
+		fmt.Println("Restore them with uzi trash restore <agent-name>")
+	}
+	if killedCount > 0 {
+		startQueued(ctx)
+	}
 	return nil
 }
 
+// startQueued spawns the agents queued under maxConcurrentAgents into the
+// slots a kill freed
+func startQueued(ctx context.Context) {
+	spawned, err := prompt.DrainQueue(ctx, *configPath)
+	if err != nil {
+		log.Warn("Failed to start queued agents", "error", err)
+		return
+	}
+	if len(spawned) > 0 {
+		fmt.Printf("Started %d queued agent(s): %s\n", len(spawned), strings.Join(spawned, ", "))
+	}
+}
+
+// interactive and pickAgent let the agent to kill be picked on a terminal
+// when none is named
+var (
+	interactive = tui.Interactive
+	pickAgent   = tui.PickAgent
+)
+
 func executeKill(ctx context.Context, args []string) error {
+	if *runFlag != "" {
+		if len(args) > 0 {
+			return fmt.Errorf("--run cannot be combined with an agent name")
+		}
+		sm, err := cmdctx.From(ctx).State()
+		if err != nil {
+			return err
+		}
+		// Without a config file the default policy applies
+		cfg, _ := cmdctx.From(ctx).LoadConfig(*configPath)
+		return killRun(ctx, sm, cfg, *runFlag)
+	}
+
 	if len(args) == 0 {
-		return fmt.Errorf("agent name argument is required")
+		if !interactive() {
+			return fmt.Errorf("agent name argument is required")
+		}
+		agentName, err := pickAgent("Kill which agent?")
+		if errors.Is(err, tui.ErrNoAgentPicked) {
+			fmt.Println("Kill aborted")
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+		args = []string{agentName}
 	}
 
 	agentName := args[0]
 
 	// Get state manager to read from config
-	sm := state.NewStateManager()
-	if sm == nil {
-		return fmt.Errorf("could not initialize state manager")
+	sm, err := cmdctx.From(ctx).State()
+	if err != nil {
+		return err
 	}
 
+	// Without a config file the default policy applies
+	cfg, _ := cmdctx.From(ctx).LoadConfig(*configPath)
+
 	// Handle "all" case
 	if agentName == "all" {
-		return killAll(ctx, sm)
+		return killAll(ctx, sm, cfg)
 	}
 
 	// Get active sessions from state
@@ -183,11 +581,42 @@ func executeKill(ctx context.Context, args []string) error {
 		return fmt.Errorf("no active session found for agent: %s", agentName)
 	}
 
+	if cfg.KillConfirmation() == config.KillConfirmAlways && !*yes {
+		proceed, err := askConfirmKill(agentName, os.Stdin, os.Stdout)
+		if err != nil {
+			return err
+		}
+		if !proceed {
+			fmt.Println("Kill aborted")
+			return nil
+		}
+	}
+
+	// Agents moved to the trash keep their work, so only permanent kills are checked
+	bin := openTrash(ctx)
+	if !*force && cfg.KillConfirmation() != config.KillConfirmNever && !trashable(bin, sessionToKill, sm) {
+		proceed, err := confirmKill(ctx, sessionToKill, agentName, sm)
+		if err != nil {
+			return err
+		}
+		if !proceed {
+			fmt.Println("Kill aborted")
+			return nil
+		}
+	}
+
 	// Kill the specific session
-	if err := killSession(ctx, sessionToKill, agentName, sm); err != nil {
+	trashed, err := killSession(ctx, sessionToKill, agentName, sm, bin)
+	if err != nil {
 		return err
 	}
+	cmdctx.From(ctx).Events(cfg).Dispatch(events.NewEvent(events.EventKill, sessionToKill, ""))
 
-	fmt.Printf("Deleted agent: %s\n", agentName)
+	if trashed {
+		fmt.Printf("Moved agent to the trash: %s (uzi undo to restore it)\n", agentName)
+	} else {
+		fmt.Printf("Deleted agent: %s\n", agentName)
+	}
+	startQueued(ctx)
 	return nil
 }
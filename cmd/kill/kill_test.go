@@ -1,13 +1,26 @@
 package kill
 
 import (
+	"bytes"
 	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
 	"os"
+	"os/exec"
+	"path/filepath"
 	"strings"
 	"testing"
 
+	"github.com/nehpz/claudicus/pkg/cmdctx"
+	"github.com/nehpz/claudicus/pkg/config"
+	"github.com/nehpz/claudicus/pkg/events"
+	"github.com/nehpz/claudicus/pkg/state"
 	"github.com/nehpz/claudicus/pkg/testutil"
 	"github.com/nehpz/claudicus/pkg/testutil/fsmock"
+	"github.com/nehpz/claudicus/pkg/trash"
+	"github.com/nehpz/claudicus/pkg/tui"
 )
 
 func TestExecuteKill(t *testing.T) {
@@ -310,13 +323,42 @@ func TestKillAll(t *testing.T) {
 	})
 }
 
+func TestKillRun(t *testing.T) {
+	require := testutil.NewRequire(t)
+	ctx := context.Background()
+	*runFlag = "auth-refactor"
+	t.Cleanup(func() { *runFlag = "" })
+
+	t.Run("run with an agent name", func(t *testing.T) {
+		err := executeKill(ctx, []string{"sarah"})
+		require.Error(err)
+		require.Equal("--run cannot be combined with an agent name", err.Error())
+	})
+
+	t.Run("run without active sessions", func(t *testing.T) {
+		fs := fsmock.NewTempFS(t)
+		defer fs.Cleanup()
+
+		// The run's only session has no tmux session left
+		fs.MkdirAll(fs.Path(".local/share/uzi"), 0755)
+		fs.WriteFileString(fs.Path(".local/share/uzi/state.json"), `{"repo-abc-sarah": {"git_repo": "repo", "run": "auth-refactor"}}`, 0644)
+		fs.CreateGitRepo(".")
+
+		originalHome := os.Getenv("HOME")
+		os.Setenv("HOME", fs.RootDir())
+		defer os.Setenv("HOME", originalHome)
+
+		require.NoError(executeKill(ctx, nil))
+	})
+}
+
 func TestCmdKillGlobalVariable(t *testing.T) {
 	require := testutil.NewRequire(t)
 
 	// Test global command configuration
 	require.NotNil(CmdKill)
 	require.Equal("kill", CmdKill.Name)
-	require.Equal("uzi kill [<agent-name>|all]", CmdKill.ShortUsage)
+	require.Equal("uzi kill [--force] [<agent-name>|all] | uzi kill --run <run-name>", CmdKill.ShortUsage)
 	require.Equal("Delete tmux session and git worktree for the specified agent", CmdKill.ShortHelp)
 	require.NotNil(CmdKill.FlagSet)
 	require.NotNil(CmdKill.Exec)
@@ -579,3 +621,187 @@ func TestComprehensiveCoverage(t *testing.T) {
 		})
 	}
 }
+
+func TestAskPendingWork(t *testing.T) {
+	require := testutil.NewRequire(t)
+	work := state.PendingWork{UncommittedChanges: 42}
+
+	t.Run("prompt", func(t *testing.T) {
+		var out bytes.Buffer
+		choice, _, err := askPendingWork("sarah", work, strings.NewReader("k\n"), &out)
+		require.NoError(err)
+		require.Equal(choiceKill, choice)
+		require.Equal("sarah has 42 uncommitted changes — checkpoint first? [c]heckpoint / [k]ill anyway / [a]bort: ", out.String())
+	})
+
+	t.Run("checkpoint asks for a message", func(t *testing.T) {
+		var out bytes.Buffer
+		choice, message, err := askPendingWork("sarah", work, strings.NewReader("c\nsave login work\n"), &out)
+		require.NoError(err)
+		require.Equal(choiceCheckpoint, choice)
+		require.Equal("save login work", message)
+		require.True(strings.HasSuffix(out.String(), "Commit message: "))
+	})
+
+	t.Run("checkpoint without a message", func(t *testing.T) {
+		_, _, err := askPendingWork("sarah", work, strings.NewReader("c\n\n"), &bytes.Buffer{})
+		require.Error(err)
+	})
+
+	t.Run("abort", func(t *testing.T) {
+		for _, answer := range []string{"a\n", "\n", "whatever\n"} {
+			choice, _, err := askPendingWork("sarah", work, strings.NewReader(answer), &bytes.Buffer{})
+			require.NoError(err)
+			require.Equal(choiceAbort, choice)
+		}
+	})
+
+	t.Run("no terminal", func(t *testing.T) {
+		_, _, err := askPendingWork("sarah", work, strings.NewReader(""), &bytes.Buffer{})
+		require.Error(err)
+		require.True(strings.Contains(err.Error(), "use --force"))
+	})
+}
+
+func TestAskConfirmKill(t *testing.T) {
+	require := testutil.NewRequire(t)
+
+	var out bytes.Buffer
+	proceed, err := askConfirmKill("sarah", strings.NewReader("y\n"), &out)
+	require.NoError(err)
+	require.True(proceed)
+	require.Equal("Kill sarah? [y/N]: ", out.String())
+
+	for _, answer := range []string{"\n", "n\n", "whatever\n"} {
+		proceed, err := askConfirmKill("sarah", strings.NewReader(answer), &bytes.Buffer{})
+		require.NoError(err)
+		require.False(proceed)
+	}
+
+	_, err = askConfirmKill("all 3 agents", strings.NewReader(""), &bytes.Buffer{})
+	var violation *config.PolicyViolation
+	require.True(errors.As(err, &violation))
+	require.Equal("confirmKill", violation.Rule)
+	require.True(strings.Contains(err.Error(), "--yes"))
+}
+
+func TestExecuteKillPicksAgent(t *testing.T) {
+	require := testutil.NewRequire(t)
+	ctx := context.Background()
+	defer func(i func() bool, p func(string) (string, error)) { interactive, pickAgent = i, p }(interactive, pickAgent)
+	interactive = func() bool { return true }
+
+	pickAgent = func(string) (string, error) { return "", tui.ErrNoAgentPicked }
+	require.NoError(executeKill(ctx, nil))
+
+	pickAgent = func(string) (string, error) { return "", errors.New("no active agents") }
+	err := executeKill(ctx, nil)
+	require.Error(err)
+	require.Equal("no active agents", err.Error())
+
+	var title string
+	pickAgent = func(t string) (string, error) {
+		title = t
+		return "nonexistent-picked-agent", nil
+	}
+	err = executeKill(ctx, nil)
+	require.Equal("Kill which agent?", title)
+	if err != nil {
+		require.False(strings.Contains(err.Error(), "agent name argument is required"))
+	}
+}
+
+func TestRemoveRemoteWorktreeScript(t *testing.T) {
+	require := testutil.NewRequire(t)
+
+	script := removeRemoteWorktreeScript("/home/dev/.local/share/uzi/worktrees/sarah-app", "sarah-app-abc123")
+	require.Equal(`repo=$(git -C /home/dev/.local/share/uzi/worktrees/sarah-app rev-parse --path-format=absolute --git-common-dir) && `+
+		`git -C "$repo" worktree remove --force /home/dev/.local/share/uzi/worktrees/sarah-app && git -C "$repo" branch -D sarah-app-abc123`, script)
+
+	// Without a recorded branch only the worktree is removed
+	require.False(strings.Contains(removeRemoteWorktreeScript("/wt", ""), "branch -D"))
+
+	// Adopted branches are kept
+	require.Equal("sarah-app-abc123", ownedBranch(state.AgentState{BranchName: "sarah-app-abc123"}))
+	adopted := state.AgentState{BranchName: "feature/login", Adopted: true}
+	require.False(strings.Contains(removeRemoteWorktreeScript("/wt", ownedBranch(adopted)), "branch -D"))
+}
+
+func TestTrashSession(t *testing.T) {
+	require := testutil.NewRequire(t)
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git not available")
+	}
+	dir := t.TempDir()
+	t.Setenv("HOME", dir)
+
+	repo := filepath.Join(dir, "repo")
+	git := func(args ...string) {
+		t.Helper()
+		cmd := exec.Command("git", append([]string{"-c", "user.email=uzi@example.com", "-c", "user.name=uzi"}, args...)...)
+		cmd.Dir = repo
+		if output, err := cmd.CombinedOutput(); err != nil {
+			t.Fatalf("git %v: %v: %s", args, err, output)
+		}
+	}
+	require.NoError(os.MkdirAll(repo, 0755))
+	git("init", "-q")
+	git("commit", "-q", "--allow-empty", "-m", "initial")
+	worktree := filepath.Join(dir, "worktrees", "sarah")
+	git("worktree", "add", "-q", "-b", "sarah-branch", worktree)
+	require.NoError(os.WriteFile(filepath.Join(worktree, "work.txt"), []byte("uncommitted\n"), 0644))
+
+	sessionName := "agent-repo-abc123-sarah"
+	info := state.AgentState{GitRepo: "app", BranchName: "sarah-branch", WorktreePath: worktree, Model: "claude", Prompt: "fix it"}
+	sm := state.NewStateManager()
+	require.NoError(sm.RestoreState(sessionName, info))
+
+	bin := trash.NewStoreAt(filepath.Join(dir, "trash.json"), filepath.Join(dir, "trash"))
+	require.NoError(trashSession(context.Background(), sessionName, info, sm, bin))
+
+	entries, err := bin.ListRepo("app")
+	require.NoError(err)
+	require.Equal(1, len(entries))
+	require.Equal("fix it", entries[0].State.Prompt)
+	_, err = os.Stat(filepath.Join(entries[0].TrashPath, "work.txt"))
+	require.NoError(err)
+	_, err = os.Stat(worktree)
+	require.True(os.IsNotExist(err))
+	_, err = sm.GetWorktreeInfo(sessionName)
+	require.Error(err)
+}
+
+func TestKillSessionsFiresWebhook(t *testing.T) {
+	require := testutil.NewRequire(t)
+	dir := t.TempDir()
+	t.Setenv("HOME", dir)
+	*permanent = true
+	t.Cleanup(func() { *permanent = false })
+
+	received := make(chan events.Event, 1)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var event events.Event
+		json.NewDecoder(r.Body).Decode(&event)
+		received <- event
+	}))
+	defer server.Close()
+
+	sessionName := "agent-app-abc123-sarah"
+	sm := state.NewStateManager()
+	require.NoError(sm.RestoreState(sessionName, state.AgentState{GitRepo: "app", Mode: state.ModeShared}))
+
+	cc := cmdctx.Default()
+	ctx := cmdctx.With(context.Background(), cc)
+	cfg := &config.Config{Webhooks: &config.WebhooksConfig{OnKill: server.URL}}
+	require.NoError(killSessions(ctx, sm, cfg, []string{sessionName}, "1 agent"))
+	// uzi exits only once the webhook is delivered
+	cc.Wait()
+
+	select {
+	case event := <-received:
+		require.Equal(events.EventKill, event.Type)
+		require.Equal(sessionName, event.Session)
+	default:
+		t.Fatal("Expected a kill webhook before Wait returned")
+	}
+}
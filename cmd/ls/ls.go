@@ -1,23 +1,24 @@
 package ls
 
 import (
-	"bytes"
+	"bufio"
 	"context"
 	"encoding/json"
 	"flag"
 	"fmt"
+	"io"
 	"os"
-	"os/exec"
-	"regexp"
-	"sort"
 	"strings"
-	"text/tabwriter"
 	"time"
 
+	"github.com/nehpz/claudicus/pkg/activity"
+	"github.com/nehpz/claudicus/pkg/cmdctx"
 	"github.com/nehpz/claudicus/pkg/config"
+	"github.com/nehpz/claudicus/pkg/hosts"
 	"github.com/nehpz/claudicus/pkg/state"
 
 	"github.com/peterbourgon/ff/v3/ffcli"
+	"golang.org/x/term"
 )
 
 var (
@@ -26,94 +27,74 @@ var (
 	allSessions = fs.Bool("a", false, "show all sessions including inactive")
 	watchMode   = fs.Bool("w", false, "watch mode - refresh output every second")
 	jsonOutput  = fs.Bool("json", false, "output in JSON format")
+	sortKey     = fs.String("sort", "", "order sessions by "+strings.Join(state.SessionSortKeys, ", ")+" (default updated, or port with --json)")
+	utcOutput   = fs.Bool("utc", false, "write --json timestamps in UTC instead of local time")
+	prune       = fs.Bool("prune", false, "remove the state of sessions whose tmux session and worktree are gone without asking")
+	filters     filtersFlag
 	CmdLs       = &ffcli.Command{
 		Name:       "ls",
 		ShortUsage: "uzi ls [-a] [-w] [--json]",
 		ShortHelp:  "List active agent sessions",
-		FlagSet:    fs,
-		Exec:       executeLs,
+		LongHelp: `
+The ls command lists the active agent sessions of the repository.
+
+The AGE and ACTIVE columns show how long ago each session was created and
+last updated. --json writes the same times as RFC 3339 timestamps in local
+time, or in UTC with --utc.
+
+--sort orders them by name, agent, status, diff (most lines changed first),
+created or updated (newest first), age (oldest first), or port.
+
+--filter FIELD=VALUE keeps only matching sessions and may be repeated; all
+filters must match. Fields are status (e.g. running), agent (the agent CLI,
+e.g. claude, or the agent's name), tag, channel, run, and host (local for
+sessions on this machine).
+
+When state still tracks sessions whose tmux session has ended and whose
+worktree is gone, ls run from a terminal offers to prune them from
+state.json; --prune prunes them without asking. Every change to state.json
+first keeps the previous version as state.json.1, up to state.json.5.
+`,
+		FlagSet: fs,
+		Exec:    executeLs,
 	}
 )
 
-func getGitDiffTotals(sessionName string, stateManager *state.StateManager) (int, int) {
-	// Get session state to find worktree path
-	states := make(map[string]state.AgentState)
-	if data, err := os.ReadFile(stateManager.GetStatePath()); err != nil {
-		return 0, 0
-	} else {
-		if err := json.Unmarshal(data, &states); err != nil {
-			return 0, 0
-		}
-	}
-
-	sessionState, ok := states[sessionName]
-	if !ok || sessionState.WorktreePath == "" {
-		return 0, 0
-	}
-
-	shellCmdString := "git add -A . && git diff --cached --shortstat HEAD && git reset HEAD > /dev/null"
-
-	cmd := exec.Command("sh", "-c", shellCmdString)
-	cmd.Dir = sessionState.WorktreePath
-
-	var out bytes.Buffer
-	var stderr bytes.Buffer
-	cmd.Stdout = &out
-	cmd.Stderr = &stderr
-
-	if err := cmd.Run(); err != nil {
-		return 0, 0
-	}
-
-	output := out.String()
-
-	insertions := 0
-	deletions := 0
+func init() {
+	fs.Var(&filters, "filter", "only list sessions matching FIELD=VALUE (repeatable)")
+}
 
-	insRe := regexp.MustCompile(`(\d+) insertion(?:s)?\(\+\)`)
-	delRe := regexp.MustCompile(`(\d+) deletion(?:s)?\(\-\)`)
+// filtersFlag collects --filter expressions
+type filtersFlag []state.SessionFilter
 
-	if m := insRe.FindStringSubmatch(output); len(m) > 1 {
-		fmt.Sscanf(m[1], "%d", &insertions)
+func (f *filtersFlag) String() string {
+	exprs := make([]string, len(*f))
+	for i, filter := range *f {
+		exprs[i] = filter.Field + "=" + filter.Value
 	}
-	if m := delRe.FindStringSubmatch(output); len(m) > 1 {
-		fmt.Sscanf(m[1], "%d", &deletions)
-	}
-
-	return insertions, deletions
+	return strings.Join(exprs, ",")
 }
 
-func getPaneContent(sessionName string) (string, error) {
-	cmd := exec.Command("tmux", "capture-pane", "-t", sessionName+":agent", "-p")
-	output, err := cmd.Output()
+func (f *filtersFlag) Set(value string) error {
+	filter, err := state.ParseSessionFilter(value)
 	if err != nil {
-		return "", err
+		return err
 	}
-	return string(output), nil
+	*f = append(*f, filter)
+	return nil
 }
 
-func getAgentStatus(sessionName string) string {
-	content, err := getPaneContent(sessionName)
-	if err != nil {
-		return "unknown"
-	}
-
-	if strings.Contains(content, "esc to interrupt") || strings.Contains(content, "Thinking") {
-		return "running"
-	}
-	return "ready"
-}
+// diffCacheTTL bounds how often watch mode recomputes each worktree's diff
+const diffCacheTTL = 3 * time.Second
 
-func formatStatus(status string) string {
-	switch status {
-	case "ready":
-		return "\033[32mready\033[0m" // Green
-	case "running":
-		return "\033[33mrunning\033[0m" // Orange/Yellow
-	default:
-		return status
-	}
-}
+// aggregator fills in status, diff counts, and dev URLs for listed sessions
+var aggregator = state.NewAggregator(
+	state.WithTmuxStatus(),
+	state.WithDiffs(),
+	state.WithDevURLs(),
+	state.WithCacheTTL(diffCacheTTL),
+	state.WithRemoteProbe(hosts.RemoteProbe),
+)
 
 func formatTime(t time.Time) string {
 	now := time.Now()
@@ -129,23 +110,76 @@ func formatTime(t time.Time) string {
 	return t.Format("Jan 02")
 }
 
+// formatAgo humanizes a timestamp for the AGE and ACTIVE columns, or returns
+// "-" if it is unknown
+func formatAgo(value string, now time.Time) string {
+	if ago := state.Ago(value, now); ago != "" {
+		return ago
+	}
+	return "-"
+}
+
 // SessionInfo represents session data for JSON output
 // This matches the struct used in pkg/tui/uzi_interface.go
 type SessionInfo struct {
-	Name         string `json:"name"`
-	AgentName    string `json:"agent_name"`
-	Model        string `json:"model"`
-	Status       string `json:"status"`
-	Prompt       string `json:"prompt"`
-	Insertions   int    `json:"insertions"`
-	Deletions    int    `json:"deletions"`
-	WorktreePath string `json:"worktree_path"`
-	Port         int    `json:"port,omitempty"`
-	UpdatedAt    string `json:"updated_at"`
+	Name            string   `json:"name"`
+	AgentName       string   `json:"agent_name"`
+	Model           string   `json:"model"`
+	Status          string   `json:"status"`
+	Prompt          string   `json:"prompt"`
+	Insertions      int      `json:"insertions"`
+	Deletions       int      `json:"deletions"`
+	FilesChanged    int      `json:"files_changed,omitempty"`
+	WorktreePath    string   `json:"worktree_path"`
+	Port            int      `json:"port,omitempty"`
+	DevServerStatus string   `json:"dev_server_status,omitempty"` // "conflict" when another process holds the port
+	CreatedAt       string   `json:"created_at,omitempty"`
+	UpdatedAt       string   `json:"updated_at"`
+	Deadline        string   `json:"deadline,omitempty"` // end of the --max-runtime budget
+	Tags            []string `json:"tags,omitempty"`
+	Run             string   `json:"run,omitempty"`    // run the session was spawned in with uzi prompt --run-name
+	Host            string   `json:"host,omitempty"`   // remote host from uzi.yaml; empty for local sessions
+	Paused          bool     `json:"paused,omitempty"` // stopped by uzi pause until uzi resume
+	Done            bool     `json:"done,omitempty"`   // the agent signalled its task is complete
+
+	// Activity metrics, as the TUI's activity monitor reports them
+	ActivityStatus     string `json:"activity_status,omitempty"`       // working, idle, stuck, or done
+	Commits            int    `json:"commits,omitempty"`               // commits in the last 24 hours
+	LastCommitAt       string `json:"last_commit_at,omitempty"`        // time of the newest commit
+	LastFileActivityAt string `json:"last_file_activity_at,omitempty"` // only known to a running monitor
 }
 
-func getSessionsAsJSON(stateManager *state.StateManager, activeSessions []string) ([]SessionInfo, error) {
-	// Load all states
+// withMetrics folds activity metrics into a session: stuck and done findings
+// refine its status, and the last-activity times are filled in
+func withMetrics(session SessionInfo, metrics *activity.Metrics) SessionInfo {
+	if metrics == nil {
+		return session
+	}
+	session.Status = metrics.SessionStatus(session.Status)
+	session.ActivityStatus = metrics.Status.String()
+	session.Commits = metrics.Commits
+	if !metrics.LastCommitAt.IsZero() {
+		session.LastCommitAt = metrics.LastCommitAt.Format(time.RFC3339)
+	}
+	if !metrics.LastFileActivityAt.IsZero() {
+		session.LastFileActivityAt = metrics.LastFileActivityAt.Format(time.RFC3339)
+	}
+	return session
+}
+
+// withUTC converts the timestamps of a session to UTC, for --utc
+func withUTC(session SessionInfo) SessionInfo {
+	for _, value := range []*string{&session.CreatedAt, &session.UpdatedAt, &session.Deadline, &session.LastCommitAt, &session.LastFileActivityAt} {
+		if t, err := time.Parse(time.RFC3339, *value); err == nil {
+			*value = t.UTC().Format(time.RFC3339)
+		}
+	}
+	return session
+}
+
+// listSessions returns the active sessions that match --filter, ordered by
+// --sort or else by defaultSort
+func listSessions(stateManager *state.StateManager, activeSessions []string, defaultSort string) ([]state.SessionInfo, error) {
 	states := make(map[string]state.AgentState)
 	if data, err := os.ReadFile(stateManager.GetStatePath()); err == nil {
 		if err := json.Unmarshal(data, &states); err != nil {
@@ -153,50 +187,67 @@ func getSessionsAsJSON(stateManager *state.StateManager, activeSessions []string
 		}
 	}
 
-	var sessions []SessionInfo
-	for _, sessionName := range activeSessions {
-		state, ok := states[sessionName]
-		if !ok {
-			continue
-		}
-
-		// Extract agent name from session name
-		parts := strings.Split(sessionName, "-")
-		agentName := sessionName
-		if len(parts) >= 4 && parts[0] == "agent" {
-			agentName = strings.Join(parts[3:], "-")
-		}
+	sessions := state.FilterSessions(aggregator.Sessions(states, activeSessions), filters)
+	order := *sortKey
+	if order == "" {
+		order = defaultSort
+	}
+	if err := state.SortSessions(sessions, order); err != nil {
+		return nil, err
+	}
+	return sessions, nil
+}
 
-		status := getAgentStatus(sessionName)
-		insertions, deletions := getGitDiffTotals(sessionName, stateManager)
+func getSessionsAsJSON(stateManager *state.StateManager, activeSessions []string) ([]SessionInfo, error) {
+	// JSON output is ordered by port unless --sort is given, for consistent ordering in the TUI
+	infos, err := listSessions(stateManager, activeSessions, "port")
+	if err != nil {
+		return nil, err
+	}
 
+	// Remote worktrees can't be measured from here
+	monitor := activity.NewAgentActivityMonitor()
+	if cfg, err := config.LoadConfig(*configPath); err == nil {
+		monitor.SetStatusThresholds(cfg.ActiveThreshold(), cfg.StuckThreshold())
+	}
+	sessions := make([]SessionInfo, 0, len(infos))
+	for _, info := range infos {
 		// Get model name, default to "unknown" if empty
-		model := state.Model
+		model := info.Model
 		if model == "" {
 			model = "unknown"
 		}
 
-		sessionInfo := SessionInfo{
-			Name:         sessionName,
-			AgentName:    agentName,
-			Model:        model,
-			Status:       status,
-			Prompt:       state.Prompt,
-			Insertions:   insertions,
-			Deletions:    deletions,
-			WorktreePath: state.WorktreePath,
-			Port:         state.Port,
-			UpdatedAt:    state.UpdatedAt.Format(time.RFC3339),
+		session := SessionInfo{
+			Name:            info.SessionName,
+			AgentName:       info.AgentName,
+			Model:           model,
+			Status:          info.Status,
+			Prompt:          info.Prompt,
+			Insertions:      info.Insertions,
+			Deletions:       info.Deletions,
+			FilesChanged:    info.FilesChanged,
+			WorktreePath:    info.WorktreePath,
+			Port:            info.Port,
+			DevServerStatus: info.DevServerStatus,
+			CreatedAt:       info.CreatedAt,
+			UpdatedAt:       info.UpdatedAt,
+			Deadline:        info.Deadline,
+			Tags:            info.Tags,
+			Run:             info.Run,
+			Host:            info.Host,
+			Paused:          info.Paused,
+			Done:            info.Done,
+		}
+		if info.Host == "" {
+			session = withMetrics(session, monitor.Measure(info.WorktreePath))
+		}
+		if *utcOutput {
+			session = withUTC(session)
 		}
-		sessions = append(sessions, sessionInfo)
+		sessions = append(sessions, session)
 	}
 
-	// Sort by UpdatedAt (most recent first)
-	// Sort by port (ascending) for consistent ordering in TUI
-	sort.Slice(sessions, func(i, j int) bool {
-		return sessions[i].Port < sessions[j].Port
-	})
-
 	return sessions, nil
 }
 
@@ -213,94 +264,81 @@ func printSessionsJSON(stateManager *state.StateManager, activeSessions []string
 }
 
 func printSessions(stateManager *state.StateManager, activeSessions []string) error {
-	// Load all states to sort by UpdatedAt
-	states := make(map[string]state.AgentState)
-	if data, err := os.ReadFile(stateManager.GetStatePath()); err == nil {
-		if err := json.Unmarshal(data, &states); err != nil {
-			return fmt.Errorf("error parsing state file: %w", err)
-		}
-	}
-
-	// Create a slice of sessions with their states for sorting
-	type sessionInfo struct {
-		name  string
-		state state.AgentState
+	// Most recently updated sessions come first unless --sort is given
+	sessions, err := listSessions(stateManager, activeSessions, "updated")
+	if err != nil {
+		return err
 	}
-	var sessions []sessionInfo
-	for _, sessionName := range activeSessions {
-		if state, ok := states[sessionName]; ok {
-			sessions = append(sessions, sessionInfo{name: sessionName, state: state})
-		}
+	if len(sessions) == 0 {
+		fmt.Println("No sessions match the filters")
+		return nil
 	}
 
-	// Sort by UpdatedAt (most recent first)
-	sort.Slice(sessions, func(i, j int) bool {
-		return sessions[i].state.UpdatedAt.After(sessions[j].state.UpdatedAt)
-	})
-
-	// Long format with tabwriter for alignment
-	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
-
-	// Print header
-	fmt.Fprintf(w, "AGENT\tMODEL\tSTATUS    DIFF\tADDR\tPROMPT\n")
-
-	// Print sessions
-	for _, session := range sessions {
-		sessionName := session.name
-		state := session.state
-
-		// Extract agent name from session name
-		parts := strings.Split(sessionName, "-")
-		agentName := sessionName
-		if len(parts) >= 4 && parts[0] == "agent" {
-			agentName = strings.Join(parts[3:], "-")
-		}
-
-		status := getAgentStatus(sessionName)
-		insertions, deletions := getGitDiffTotals(sessionName, stateManager)
-
-		// Format diff stats with colors
-		var changes string
-		if insertions == 0 && deletions == 0 {
-			changes = "\033[32m+0\033[0m/\033[31m-0\033[0m"
-		} else {
-			// ANSI color codes: green for additions, red for deletions
-			changes = fmt.Sprintf("\033[32m+%d\033[0m/\033[31m-%d\033[0m", insertions, deletions)
-		}
-
-		// Get model name, default to "unknown" if empty (for backward compatibility)
-		model := state.Model
-		if model == "" {
-			model = "unknown"
-		}
+	renderSessions(os.Stdout, sessions, stdoutTableStyle(), time.Now())
+	return nil
+}
 
-		// Format: agent model status addr changes prompt
-		addr := ""
-		if state.Port != 0 {
-			addr = fmt.Sprintf("http://localhost:%d", state.Port)
+// validSortKey reports whether key is one of state.SessionSortKeys
+func validSortKey(key string) bool {
+	for _, k := range state.SessionSortKeys {
+		if k == key {
+			return true
 		}
-		fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%s\t%s\n",
-			agentName,
-			model,
-			formatStatus(status),
-			changes,
-			addr,
-			state.Prompt,
-		)
 	}
-	w.Flush()
-
-	return nil
+	return false
 }
 
 func clearScreen() {
 	fmt.Print("\033[H\033[2J")
 }
 
+// staleStates finds and prunes the state of sessions that left nothing behind
+type staleStates interface {
+	StaleSessions() ([]string, error)
+	PruneSessions(sessionNames []string) error
+	BackupPath(n int) string
+}
+
+// pruneStale removes the state of stale sessions, after asking on in unless
+// it is nil. Failures are reported without stopping the listing.
+func pruneStale(states staleStates, in io.Reader, out io.Writer) {
+	stale, err := states.StaleSessions()
+	if err != nil || len(stale) == 0 {
+		return
+	}
+	names := make([]string, len(stale))
+	for i, sessionName := range stale {
+		names[i] = state.AgentNameFromSession(sessionName)
+	}
+	if in != nil {
+		fmt.Fprintf(out, "%d session(s) in state have no tmux session or worktree left: %s\nPrune them? [y/N]: ", len(stale), strings.Join(names, ", "))
+		answer, _ := bufio.NewReader(in).ReadString('\n')
+		if answer := strings.ToLower(strings.TrimSpace(answer)); answer != "y" && answer != "yes" {
+			return
+		}
+	}
+	if err := states.PruneSessions(stale); err != nil {
+		fmt.Fprintf(os.Stderr, "Failed to prune stale sessions: %v\n", err)
+		return
+	}
+	fmt.Fprintf(out, "Pruned %d stale session(s): %s (the previous state is in %s)\n", len(stale), strings.Join(names, ", "), states.BackupPath(1))
+}
+
 func executeLs(ctx context.Context, args []string) error {
-	stateManager := state.NewStateManager()
-	if stateManager == nil {
-		return fmt.Errorf("failed to create state manager")
+	if *sortKey != "" && !validSortKey(*sortKey) {
+		return fmt.Errorf("unknown sort key %q: use one of %s", *sortKey, strings.Join(state.SessionSortKeys, ", "))
+	}
+	cc := cmdctx.From(ctx)
+	stateManager, err := cc.State()
+	if err != nil {
+		return err
+	}
+	asJSON := cc.WantJSON(*jsonOutput)
+
+	if *prune {
+		pruneStale(stateManager, nil, os.Stdout)
+	} else if !asJSON && term.IsTerminal(int(os.Stdin.Fd())) {
+		pruneStale(stateManager, os.Stdin, os.Stdout)
 	}
 
 	if *watchMode {
@@ -353,7 +391,7 @@ func executeLs(ctx context.Context, args []string) error {
 			return fmt.Errorf("error getting active sessions: %w", err)
 		}
 		if len(activeSessions) == 0 {
-			if *jsonOutput {
+			if asJSON {
 				// Return empty JSON array
 				fmt.Println("[]")
 			} else {
@@ -362,7 +400,7 @@ func executeLs(ctx context.Context, args []string) error {
 			return nil
 		}
 
-		if *jsonOutput {
+		if asJSON {
 			return printSessionsJSON(stateManager, activeSessions)
 		} else {
 			return printSessions(stateManager, activeSessions)
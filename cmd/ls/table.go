@@ -0,0 +1,187 @@
+package ls
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"github.com/nehpz/claudicus/pkg/state"
+
+	"golang.org/x/term"
+)
+
+// ANSI colors of the session table
+const (
+	colorReset  = "\033[0m"
+	colorRed    = "\033[31m"
+	colorGreen  = "\033[32m"
+	colorYellow = "\033[33m"
+	colorCyan   = "\033[36m"
+	colorGray   = "\033[90m"
+	colorBold   = "\033[1m"
+)
+
+// statusColors color-codes session statuses; other statuses are left plain
+var statusColors = map[string]string{
+	state.StatusReady:    colorGreen,
+	state.StatusRunning:  colorYellow,
+	state.StatusStarting: colorGray,
+	state.StatusPaused:   colorGray,
+	state.StatusDone:     colorCyan,
+	state.StatusStuck:    colorRed,
+}
+
+// columnGap separates the columns of the session table
+const columnGap = "  "
+
+// minPromptWidth is the narrowest the prompt is truncated to, however little
+// of the terminal the other columns leave
+const minPromptWidth = 20
+
+// tableStyle is how the session table is rendered
+type tableStyle struct {
+	color bool // ANSI colors
+	width int  // terminal width the prompt is truncated to; 0 leaves it whole
+}
+
+// stdoutTableStyle colors the table and fits it to the terminal when stdout
+// is one, unless NO_COLOR is set, and degrades to plain text otherwise
+func stdoutTableStyle() tableStyle {
+	fd := int(os.Stdout.Fd())
+	if !term.IsTerminal(fd) {
+		return tableStyle{}
+	}
+	style := tableStyle{color: os.Getenv("NO_COLOR") == ""}
+	if width, _, err := term.GetSize(fd); err == nil {
+		style.width = width
+	}
+	return style
+}
+
+// paint wraps text in an ANSI color when the style has colors
+func (s tableStyle) paint(color, text string) string {
+	if !s.color || color == "" || text == "" {
+		return text
+	}
+	return color + text + colorReset
+}
+
+// tableCell is a cell's text and the color it is painted in
+type tableCell struct {
+	text  string
+	color string
+}
+
+// tableColumn is a column of the session table
+type tableColumn struct {
+	header     string
+	rightAlign bool
+}
+
+// sessionColumns are the columns of `uzi ls`; the prompt comes last so it can
+// take the rest of the line
+var sessionColumns = []tableColumn{
+	{header: "AGENT"},
+	{header: "MODEL"},
+	{header: "STATUS"},
+	{header: "+", rightAlign: true},
+	{header: "-", rightAlign: true},
+	{header: "ADDR"},
+	{header: "AGE", rightAlign: true},
+	{header: "ACTIVE", rightAlign: true},
+	{header: "PROMPT"},
+}
+
+// sessionRow returns the cells of a session, in the order of sessionColumns
+func sessionRow(info state.SessionInfo, now time.Time) []tableCell {
+	// Remote agents show the host they run on
+	agent := info.AgentName
+	if info.Host != "" {
+		agent += "@" + info.Host
+	}
+	// Get model name, default to "unknown" if empty (for backward compatibility)
+	model := info.Model
+	if model == "" {
+		model = "unknown"
+	}
+	// A port taken over by another process is flagged next to the address
+	addr, addrColor := info.DevServerURL, ""
+	if info.DevServerStatus == state.DevServerConflict {
+		addr, addrColor = addr+" (port conflict)", colorRed
+	}
+	return []tableCell{
+		{text: agent, color: colorBold},
+		{text: model},
+		{text: info.Status, color: statusColors[info.Status]},
+		{text: fmt.Sprint(info.Insertions), color: colorGreen},
+		{text: fmt.Sprint(info.Deletions), color: colorRed},
+		{text: addr, color: addrColor},
+		{text: formatAgo(info.CreatedAt, now)},
+		{text: formatAgo(info.UpdatedAt, now)},
+		{text: strings.Join(strings.Fields(info.Prompt), " ")},
+	}
+}
+
+// renderSessions writes sessions as an aligned table. Widths are measured on
+// the text alone, so colors don't throw off the alignment, and the prompt is
+// cut to the terminal width with an ellipsis.
+func renderSessions(out io.Writer, sessions []state.SessionInfo, style tableStyle, now time.Time) {
+	rows := make([][]tableCell, len(sessions))
+	widths := make([]int, len(sessionColumns))
+	for i, column := range sessionColumns {
+		widths[i] = utf8.RuneCountInString(column.header)
+	}
+	for i, info := range sessions {
+		rows[i] = sessionRow(info, now)
+		for j, cell := range rows[i][:len(sessionColumns)-1] {
+			widths[j] = max(widths[j], utf8.RuneCountInString(cell.text))
+		}
+	}
+
+	promptWidth := 0
+	if style.width > 0 {
+		used := 0
+		for _, width := range widths[:len(widths)-1] {
+			used += width + len(columnGap)
+		}
+		promptWidth = max(style.width-used, minPromptWidth)
+	}
+
+	line := func(cells []tableCell) {
+		var b strings.Builder
+		last := len(cells) - 1
+		for i, cell := range cells[:last] {
+			pad := strings.Repeat(" ", widths[i]-utf8.RuneCountInString(cell.text))
+			if sessionColumns[i].rightAlign {
+				b.WriteString(pad + style.paint(cell.color, cell.text))
+			} else {
+				b.WriteString(style.paint(cell.color, cell.text) + pad)
+			}
+			b.WriteString(columnGap)
+		}
+		b.WriteString(style.paint(cells[last].color, truncate(cells[last].text, promptWidth)))
+		fmt.Fprintln(out, strings.TrimRight(b.String(), " "))
+	}
+
+	header := make([]tableCell, len(sessionColumns))
+	for i, column := range sessionColumns {
+		header[i] = tableCell{text: column.header, color: colorGray}
+	}
+	line(header)
+	for _, row := range rows {
+		line(row)
+	}
+}
+
+// truncate cuts text to width runes, ending it with an ellipsis when it is
+// cut; a width of 0 leaves it whole
+func truncate(text string, width int) string {
+	if width <= 0 || utf8.RuneCountInString(text) <= width {
+		return text
+	}
+	runes := []rune(text)
+	return string(runes[:width-1]) + "…"
+}
@@ -0,0 +1,75 @@
+package ls
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/nehpz/claudicus/pkg/state"
+)
+
+func TestRenderSessions(t *testing.T) {
+	now := time.Date(2025, 1, 1, 12, 0, 0, 0, time.UTC)
+	sessions := []state.SessionInfo{
+		{
+			AgentName:    "sarah",
+			Model:        "claude",
+			Status:       state.StatusRunning,
+			Insertions:   120,
+			Deletions:    4,
+			DevServerURL: "http://localhost:3001",
+			Prompt:       "Fix the login form\nand add a regression test for it",
+			CreatedAt:    "2025-01-01T10:00:00Z",
+			UpdatedAt:    "2025-01-01T11:55:00Z",
+		},
+		{
+			AgentName:       "emily",
+			Host:            "build-box",
+			Status:          state.StatusReady,
+			DevServerURL:    "http://localhost:3002",
+			DevServerStatus: state.DevServerConflict,
+			Prompt:          "Docs",
+			CreatedAt:       "2025-01-01T11:00:00Z",
+			UpdatedAt:       "2025-01-01T11:00:00Z",
+		},
+	}
+
+	var out bytes.Buffer
+	renderSessions(&out, sessions, tableStyle{}, now)
+	want := "AGENT            MODEL    STATUS     +  -  ADDR                                      AGE  ACTIVE  PROMPT\n" +
+		"sarah            claude   running  120  4  http://localhost:3001                  2h ago  5m ago  Fix the login form and add a regression test for it\n" +
+		"emily@build-box  unknown  ready      0  0  http://localhost:3002 (port conflict)  1h ago  1h ago  Docs\n"
+	if got := out.String(); got != want {
+		t.Errorf("Plain table:\n%s\nwant:\n%s", got, want)
+	}
+
+	// A terminal gets colors and a prompt cut to its width
+	out.Reset()
+	renderSessions(&out, sessions[:1], tableStyle{color: true, width: 100}, now)
+	lines := strings.Split(strings.TrimRight(out.String(), "\n"), "\n")
+	if !strings.Contains(lines[1], colorYellow+"running"+colorReset) || !strings.Contains(lines[1], colorGreen+"120"+colorReset) {
+		t.Errorf("Expected the status and diff colored, got %q", lines[1])
+	}
+	if !strings.HasSuffix(lines[1], "Fix the login form and add a…") {
+		t.Errorf("Expected the prompt truncated with an ellipsis, got %q", lines[1])
+	}
+}
+
+func TestTruncate(t *testing.T) {
+	for _, tt := range []struct {
+		text  string
+		width int
+		want  string
+	}{
+		{"short", 10, "short"},
+		{"exactly10!", 10, "exactly10!"},
+		{"a longer prompt", 10, "a longer …"},
+		{"héllo wörld", 6, "héllo…"},
+		{"whole", 0, "whole"},
+	} {
+		if got := truncate(tt.text, tt.width); got != tt.want {
+			t.Errorf("truncate(%q, %d) = %q, want %q", tt.text, tt.width, got, tt.want)
+		}
+	}
+}
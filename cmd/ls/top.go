@@ -0,0 +1,104 @@
+package ls
+
+import (
+	"context"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"strings"
+	"time"
+
+	"github.com/nehpz/claudicus/pkg/cmdctx"
+	"github.com/nehpz/claudicus/pkg/fleet"
+
+	"github.com/peterbourgon/ff/v3/ffcli"
+)
+
+// stuckAfter matches the TUI: an agent with no diff and no update for this long is stuck
+const stuckAfter = 3 * time.Minute
+
+var (
+	topFs   = flag.NewFlagSet("uzi top", flag.ExitOnError)
+	topJSON = topFs.Bool("json", false, "output in JSON format")
+	CmdTop  = &ffcli.Command{
+		Name:       "top",
+		ShortUsage: "uzi top [--json]",
+		ShortHelp:  "Show a one-line summary of all agent sessions",
+		FlagSet:    topFs,
+		Exec:       executeTop,
+	}
+)
+
+// getAttachedSessions returns the tmux sessions that have a client attached
+func getAttachedSessions() map[string]bool {
+	attached := make(map[string]bool)
+	output, err := exec.Command("tmux", "list-sessions", "-F", "#{session_name} #{session_attached}").Output()
+	if err != nil {
+		return attached
+	}
+	for _, line := range strings.Split(strings.TrimSpace(string(output)), "\n") {
+		fields := strings.Fields(line)
+		if len(fields) == 2 && fields[1] != "0" {
+			attached[fields[0]] = true
+		}
+	}
+	return attached
+}
+
+// fleetAgents converts listed sessions into aggregator input
+func fleetAgents(sessions []SessionInfo, attached map[string]bool, now time.Time) []fleet.Agent {
+	agents := make([]fleet.Agent, 0, len(sessions))
+	for _, session := range sessions {
+		status := session.Status
+		if status == "ready" && session.Insertions == 0 && session.Deletions == 0 {
+			if updatedAt, err := time.Parse(time.RFC3339, session.UpdatedAt); err == nil && now.Sub(updatedAt) > stuckAfter {
+				status = "stuck"
+			}
+		}
+		agents = append(agents, fleet.Agent{
+			Name:         session.Name,
+			Status:       status,
+			Insertions:   session.Insertions,
+			Deletions:    session.Deletions,
+			Port:         session.Port,
+			WorktreePath: session.WorktreePath,
+			Attached:     attached[session.Name],
+		})
+	}
+	return agents
+}
+
+// printSummary writes the summary as a dashboard line or as JSON
+func printSummary(w io.Writer, summary fleet.Summary, asJSON bool) error {
+	if asJSON {
+		encoder := json.NewEncoder(w)
+		encoder.SetIndent("", "  ")
+		return encoder.Encode(summary)
+	}
+	_, err := fmt.Fprintln(w, summary.String())
+	return err
+}
+
+func executeTop(ctx context.Context, args []string) error {
+	stateManager, err := cmdctx.From(ctx).State()
+	if err != nil {
+		return err
+	}
+
+	activeSessions, err := stateManager.GetActiveSessionsForRepo()
+	if err != nil {
+		return fmt.Errorf("error getting active sessions: %w", err)
+	}
+
+	sessions, err := getSessionsAsJSON(stateManager, activeSessions)
+	if err != nil {
+		return err
+	}
+
+	agents := fleetAgents(sessions, getAttachedSessions(), time.Now())
+	summary := fleet.NewAggregator().Summarize(agents)
+	return printSummary(os.Stdout, summary, cmdctx.From(ctx).WantJSON(*topJSON))
+}
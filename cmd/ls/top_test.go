@@ -0,0 +1,75 @@
+package ls
+
+import (
+	"bytes"
+	"encoding/json"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/nehpz/claudicus/pkg/fleet"
+)
+
+func TestFleetAgents(t *testing.T) {
+	now := time.Date(2025, 1, 1, 12, 0, 0, 0, time.UTC)
+	recent := now.Add(-time.Minute).Format(time.RFC3339)
+	stale := now.Add(-10 * time.Minute).Format(time.RFC3339)
+
+	sessions := []SessionInfo{
+		{Name: "agent-p-1-sarah", Status: "running", UpdatedAt: stale},
+		{Name: "agent-p-1-john", Status: "ready", UpdatedAt: recent},
+		{Name: "agent-p-1-emily", Status: "ready", UpdatedAt: stale},
+		{Name: "agent-p-1-mike", Status: "ready", Insertions: 4, UpdatedAt: stale},
+		{Name: "agent-p-1-lucy", Status: "ready", UpdatedAt: "not a time"},
+	}
+	agents := fleetAgents(sessions, map[string]bool{"agent-p-1-john": true}, now)
+
+	want := map[string]string{
+		"agent-p-1-sarah": "running",
+		"agent-p-1-john":  "ready",
+		"agent-p-1-emily": "stuck",
+		"agent-p-1-mike":  "ready",
+		"agent-p-1-lucy":  "ready",
+	}
+	for _, agent := range agents {
+		if agent.Status != want[agent.Name] {
+			t.Errorf("%s: status = %q, want %q", agent.Name, agent.Status, want[agent.Name])
+		}
+		if agent.Attached != (agent.Name == "agent-p-1-john") {
+			t.Errorf("%s: attached = %v", agent.Name, agent.Attached)
+		}
+	}
+}
+
+func TestPrintSummary(t *testing.T) {
+	summary := fleet.Summary{Agents: 2, Running: 1, Ready: 1, Insertions: 5, Ports: []int{3000, 3001}}
+
+	var out bytes.Buffer
+	if err := printSummary(&out, summary, false); err != nil {
+		t.Fatalf("printSummary() error = %v", err)
+	}
+	if !strings.HasPrefix(out.String(), "2 agents (1 running, 1 ready, 0 stuck)") || !strings.Contains(out.String(), "ports 3000-3001") {
+		t.Errorf("Unexpected summary line: %q", out.String())
+	}
+
+	out.Reset()
+	if err := printSummary(&out, summary, true); err != nil {
+		t.Fatalf("printSummary() error = %v", err)
+	}
+	var decoded fleet.Summary
+	if err := json.Unmarshal(out.Bytes(), &decoded); err != nil {
+		t.Fatalf("Invalid JSON output %q: %v", out.String(), err)
+	}
+	if decoded.Agents != 2 || decoded.Insertions != 5 || len(decoded.Ports) != 2 {
+		t.Errorf("Unexpected decoded summary: %+v", decoded)
+	}
+}
+
+func TestCmdTopGlobalVariable(t *testing.T) {
+	if CmdTop.Name != "top" {
+		t.Errorf("Expected command name 'top', got %q", CmdTop.Name)
+	}
+	if CmdTop.Exec == nil {
+		t.Error("Expected Exec function to be set")
+	}
+}
@@ -0,0 +1,114 @@
+package nudge
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"strings"
+
+	"github.com/nehpz/claudicus/pkg/cmdctx"
+	"github.com/nehpz/claudicus/pkg/config"
+	"github.com/nehpz/claudicus/pkg/tmuxops"
+
+	"github.com/charmbracelet/log"
+	"github.com/peterbourgon/ff/v3/ffcli"
+)
+
+// CommandExecutor abstracts the execution of external commands; nudge also
+// reads the agent pane to tell whether the agent is busy
+type CommandExecutor interface {
+	tmuxops.CommandExecutor
+	tmuxops.OutputExecutor
+}
+
+// RealCommandExecutor implements CommandExecutor using exec.Command
+type RealCommandExecutor = tmuxops.RealCommandExecutor
+
+var (
+	fs         = flag.NewFlagSet("uzi nudge", flag.ExitOnError)
+	configPath = fs.String("config", config.GetDefaultConfigPath(), "path to config file")
+	force      = fs.Bool("force", false, "nudge the agent even if it appears to be working")
+	CmdNudge   = &ffcli.Command{
+		Name:       "nudge",
+		ShortUsage: "uzi nudge [--force] <agent-name>",
+		ShortHelp:  "Send the configured continue keystrokes to an idle agent",
+		LongHelp: `Send a keystroke sequence to an agent that is waiting for input, such as
+a confirmation prompt. The sequence defaults to Enter and can be configured
+per agent name or model in uzi.yaml:
+
+  nudge:
+    default: ["Enter"]
+    agents:
+      codex: ["continue", "Enter"]
+
+Each entry is passed to tmux send-keys, so key names like Enter or Escape
+are pressed and any other text is typed literally.`,
+		FlagSet: fs,
+		Exec: func(ctx context.Context, args []string) error {
+			return executeNudge(ctx, args, &RealCommandExecutor{})
+		},
+	}
+)
+
+func executeNudge(ctx context.Context, args []string, executor CommandExecutor) error {
+	if len(args) == 0 {
+		return fmt.Errorf("agent name argument is required")
+	}
+
+	agentName := args[0]
+	log.Debug("Nudging agent", "agent", agentName)
+
+	// Get state manager to read from config
+	sm, err := cmdctx.From(ctx).State()
+	if err != nil {
+		return err
+	}
+
+	sessionName, err := sm.SessionForAgent(agentName)
+	if err != nil {
+		return err
+	}
+
+	model := ""
+	if agentState, err := sm.GetWorktreeInfo(sessionName); err == nil {
+		model = agentState.Model
+	}
+
+	cfg, err := cmdctx.From(ctx).LoadConfig(*configPath)
+	if err != nil {
+		log.Debug("Using default nudge keys", "config", *configPath, "error", err)
+		cfg = nil
+	}
+
+	if !*force && isBusy(executor, sessionName) {
+		return fmt.Errorf("agent %s is still working; use --force to nudge anyway", agentName)
+	}
+
+	keys := cfg.NudgeKeys(agentName, model)
+	if err := sendNudge(executor, sessionName, keys); err != nil {
+		return err
+	}
+
+	fmt.Printf("Nudged %s (%s)\n", agentName, strings.Join(keys, " "))
+	return nil
+}
+
+// isBusy reports whether the agent pane shows that the agent is still working
+func isBusy(executor CommandExecutor, sessionName string) bool {
+	output, err := executor.ExecuteCommand("tmux", "capture-pane", "-t", tmuxops.AgentTarget(sessionName), "-p")
+	if err != nil {
+		return false
+	}
+	content := string(output)
+	return strings.Contains(content, "esc to interrupt") || strings.Contains(content, "Thinking")
+}
+
+// sendNudge sends each key of the sequence to the agent window in order
+func sendNudge(executor CommandExecutor, sessionName string, keys []string) error {
+	for _, k := range keys {
+		if err := executor.Execute("tmux", tmuxops.SendKeysArgs(sessionName, k)...); err != nil {
+			return fmt.Errorf("failed to send %q to %s: %w", k, sessionName, err)
+		}
+	}
+	return nil
+}
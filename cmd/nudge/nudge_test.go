@@ -0,0 +1,96 @@
+package nudge
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+// MockCommandExecutor implements CommandExecutor for testing
+type MockCommandExecutor struct {
+	shouldFail bool
+	paneOutput string
+	commands   [][]string
+}
+
+// Execute records the command and returns an error if shouldFail is true
+func (m *MockCommandExecutor) Execute(command string, args ...string) error {
+	m.commands = append(m.commands, append([]string{command}, args...))
+	if m.shouldFail {
+		return fmt.Errorf("mock command execution failed")
+	}
+	return nil
+}
+
+// Output records the command and returns the configured pane content
+func (m *MockCommandExecutor) ExecuteCommand(command string, args ...string) ([]byte, error) {
+	m.commands = append(m.commands, append([]string{command}, args...))
+	return []byte(m.paneOutput), nil
+}
+
+func TestExecuteNudgeRequiresAgentName(t *testing.T) {
+	err := executeNudge(context.Background(), []string{}, &MockCommandExecutor{})
+	if err == nil || !strings.Contains(err.Error(), "agent name argument is required") {
+		t.Errorf("Expected agent name error, got %v", err)
+	}
+}
+
+func TestIsBusy(t *testing.T) {
+	tests := []struct {
+		name    string
+		content string
+		want    bool
+	}{
+		{"working", "✻ Reticulating… (esc to interrupt)", true},
+		{"thinking", "Thinking...", true},
+		{"waiting for confirmation", "Do you want to proceed?\n❯ 1. Yes", false},
+		{"empty pane", "", false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			executor := &MockCommandExecutor{paneOutput: tt.content}
+			if got := isBusy(executor, "agent-repo-abc123-sarah"); got != tt.want {
+				t.Errorf("isBusy() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestSendNudge(t *testing.T) {
+	executor := &MockCommandExecutor{}
+	err := sendNudge(executor, "agent-repo-abc123-sarah", []string{"continue", "Enter"})
+	if err != nil {
+		t.Fatalf("sendNudge() error = %v", err)
+	}
+
+	want := [][]string{
+		{"tmux", "send-keys", "-t", "agent-repo-abc123-sarah:{start}", "continue"},
+		{"tmux", "send-keys", "-t", "agent-repo-abc123-sarah:{start}", "Enter"},
+	}
+	if !reflect.DeepEqual(executor.commands, want) {
+		t.Errorf("sendNudge() commands = %v, want %v", executor.commands, want)
+	}
+}
+
+func TestSendNudgeStopsOnError(t *testing.T) {
+	executor := &MockCommandExecutor{shouldFail: true}
+	err := sendNudge(executor, "agent-repo-abc123-sarah", []string{"continue", "Enter"})
+	if err == nil || !strings.Contains(err.Error(), `failed to send "continue"`) {
+		t.Errorf("Expected send failure, got %v", err)
+	}
+	if len(executor.commands) != 1 {
+		t.Errorf("Expected sending to stop after first failure, got %d commands", len(executor.commands))
+	}
+}
+
+func TestCmdNudge(t *testing.T) {
+	if CmdNudge.Name != "nudge" {
+		t.Errorf("CmdNudge.Name = %v, want nudge", CmdNudge.Name)
+	}
+	if CmdNudge.FlagSet.Lookup("force") == nil {
+		t.Error("CmdNudge should define a force flag")
+	}
+}
@@ -0,0 +1,134 @@
+package overlap
+
+import (
+	"context"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"github.com/nehpz/claudicus/pkg/cmdctx"
+	"github.com/nehpz/claudicus/pkg/hosts"
+	"github.com/nehpz/claudicus/pkg/state"
+
+	"github.com/charmbracelet/log"
+	"github.com/peterbourgon/ff/v3/ffcli"
+)
+
+var (
+	fs         = flag.NewFlagSet("uzi overlap", flag.ExitOnError)
+	jsonOutput = fs.Bool("json", false, "output in JSON format")
+	CmdOverlap = &ffcli.Command{
+		Name:       "overlap",
+		ShortUsage: "uzi overlap [--json] [agent-name]",
+		ShortHelp:  "Show files that more than one agent has changed",
+		LongHelp: `The overlap command compares the pending changes of every active agent and
+lists the files that more than one of them changed. Changes clash when two
+agents changed the same or adjacent lines of a file, both added it, or one
+deleted it while another changed it: checkpointing both will conflict, so
+checkpoint one, then have the other rebase onto it.
+
+With an agent name, only files that agent changed are listed.`,
+		FlagSet: fs,
+		Exec:    executeOverlap,
+	}
+)
+
+func executeOverlap(ctx context.Context, args []string) error {
+	if len(args) > 1 {
+		return fmt.Errorf("usage: uzi overlap [--json] [agent-name]")
+	}
+
+	sm, err := cmdctx.From(ctx).State()
+	if err != nil {
+		return err
+	}
+	activeSessions, err := sm.GetActiveSessionsForRepo()
+	if err != nil {
+		return fmt.Errorf("error getting active sessions: %w", err)
+	}
+
+	aggregator := state.NewAggregator(state.WithRemoteProbe(hosts.RemoteProbe))
+	changes := make(map[string]state.WorktreeChanges, len(activeSessions))
+	for _, sessionName := range activeSessions {
+		agentState, err := sm.GetWorktreeInfo(sessionName)
+		if err != nil {
+			log.Debug("Skipping session without state", "session", sessionName, "error", err)
+			continue
+		}
+		agentChanges, err := aggregator.Changes(*agentState)
+		if err != nil {
+			log.Warn("Could not read changes", "session", sessionName, "error", err)
+			continue
+		}
+		changes[state.AgentNameFromSession(sessionName)] = agentChanges
+	}
+
+	agentName := ""
+	if len(args) == 1 {
+		agentName = args[0]
+		if _, ok := changes[agentName]; !ok {
+			return fmt.Errorf("no active session found for agent: %s", agentName)
+		}
+	}
+	overlaps := filterOverlaps(state.Overlaps(changes), agentName)
+
+	if cmdctx.From(ctx).WantJSON(*jsonOutput) {
+		if overlaps == nil {
+			overlaps = []state.FileOverlap{}
+		}
+		encoder := json.NewEncoder(os.Stdout)
+		encoder.SetIndent("", "  ")
+		return encoder.Encode(overlaps)
+	}
+	printOverlaps(os.Stdout, overlaps)
+	return nil
+}
+
+// filterOverlaps keeps the files the agent changed, or all of them for no agent
+func filterOverlaps(overlaps []state.FileOverlap, agentName string) []state.FileOverlap {
+	if agentName == "" {
+		return overlaps
+	}
+	var filtered []state.FileOverlap
+	for _, overlap := range overlaps {
+		if overlap.Involves(agentName) {
+			filtered = append(filtered, overlap)
+		}
+	}
+	return filtered
+}
+
+// printOverlaps writes one aligned line per shared file, marking the files
+// with clashing changes, and a summary
+func printOverlaps(out io.Writer, overlaps []state.FileOverlap) {
+	if len(overlaps) == 0 {
+		fmt.Fprintln(out, "No file was changed by more than one agent.")
+		return
+	}
+	pathWidth, agentsWidth := 0, 0
+	for _, overlap := range overlaps {
+		pathWidth = max(pathWidth, len(overlap.Path))
+		agentsWidth = max(agentsWidth, len(strings.Join(overlap.Agents, ", ")))
+	}
+	conflicting := 0
+	for _, overlap := range overlaps {
+		line := fmt.Sprintf("%-*s  %-*s", pathWidth, overlap.Path, agentsWidth, strings.Join(overlap.Agents, ", "))
+		if len(overlap.Conflicting) > 0 {
+			conflicting++
+			line += "  conflicting: " + strings.Join(overlap.Conflicting, ", ")
+		}
+		fmt.Fprintln(out, strings.TrimRight(line, " "))
+	}
+	fmt.Fprintf(out, "\n%d %s changed by more than one agent, %d with conflicting changes\n",
+		len(overlaps), plural(len(overlaps), "file"), conflicting)
+}
+
+func plural(n int, word string) string {
+	if n == 1 {
+		return word
+	}
+	return word + "s"
+}
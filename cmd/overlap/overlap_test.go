@@ -0,0 +1,46 @@
+package overlap
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/nehpz/claudicus/pkg/state"
+)
+
+func TestPrintOverlaps(t *testing.T) {
+	overlaps := []state.FileOverlap{
+		{Path: "README.md", Agents: []string{"emily", "sarah"}},
+		{Path: "pkg/auth/login.go", Agents: []string{"emily", "john", "sarah"}, Conflicting: []string{"john", "sarah"}},
+	}
+
+	var out bytes.Buffer
+	printOverlaps(&out, overlaps)
+	want := "README.md          emily, sarah\n" +
+		"pkg/auth/login.go  emily, john, sarah  conflicting: john, sarah\n" +
+		"\n2 files changed by more than one agent, 1 with conflicting changes\n"
+	if got := out.String(); got != want {
+		t.Errorf("printOverlaps() =\n%s\nwant:\n%s", got, want)
+	}
+
+	out.Reset()
+	printOverlaps(&out, nil)
+	if got := out.String(); got != "No file was changed by more than one agent.\n" {
+		t.Errorf("Expected a note for no overlaps, got %q", got)
+	}
+}
+
+func TestFilterOverlaps(t *testing.T) {
+	overlaps := []state.FileOverlap{
+		{Path: "README.md", Agents: []string{"emily", "sarah"}},
+		{Path: "main.go", Agents: []string{"john", "sarah"}},
+	}
+	if got := filterOverlaps(overlaps, ""); len(got) != 2 {
+		t.Errorf("Expected every overlap without an agent, got %+v", got)
+	}
+	if got := filterOverlaps(overlaps, "john"); len(got) != 1 || got[0].Path != "main.go" {
+		t.Errorf("Expected only john's files, got %+v", got)
+	}
+	if got := filterOverlaps(overlaps, "bob"); len(got) != 0 {
+		t.Errorf("Expected no files for an agent without overlaps, got %+v", got)
+	}
+}
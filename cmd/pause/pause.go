@@ -0,0 +1,177 @@
+package pause
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"strings"
+
+	"github.com/nehpz/claudicus/pkg/cmdctx"
+	"github.com/nehpz/claudicus/pkg/config"
+	"github.com/nehpz/claudicus/pkg/hosts"
+	"github.com/nehpz/claudicus/pkg/state"
+	"github.com/nehpz/claudicus/pkg/tmuxops"
+
+	"github.com/charmbracelet/log"
+	"github.com/peterbourgon/ff/v3/ffcli"
+)
+
+// CommandExecutor abstracts the execution of external commands
+type CommandExecutor = tmuxops.CommandExecutor
+
+const keysHelp = `
+The keys sent default to Escape for pausing and "continue" then Enter for
+resuming, and can be configured per agent name or model in uzi.yaml:
+
+  pause:
+    stop:
+      default: ["Escape"]
+      agents:
+        codex: ["C-c"]
+    resume:
+      default: ["continue", "Enter"]
+
+Each entry is passed to tmux send-keys, so key names like Enter or Escape
+are pressed and any other text is typed literally.`
+
+var (
+	pauseFs         = flag.NewFlagSet("uzi pause", flag.ExitOnError)
+	pauseAll        = pauseFs.Bool("all", false, "pause every active agent session")
+	pauseConfigPath = pauseFs.String("config", config.GetDefaultConfigPath(), "path to config file")
+	CmdPause        = &ffcli.Command{
+		Name:       "pause",
+		ShortUsage: "uzi pause [--all] [agent-name...]",
+		ShortHelp:  "Interrupt agents and hold them until uzi resume",
+		LongHelp: `Send the configured stop keys to agents and mark their sessions paused.
+Paused sessions show as paused in uzi ls and the TUI, and broadcasts and
+uzi auto skip them until they are resumed. Useful when stepping away or
+when hitting API rate limits.
+` + keysHelp,
+		FlagSet: pauseFs,
+		Exec: func(ctx context.Context, args []string) error {
+			return executePause(ctx, args, *pauseAll, *pauseConfigPath, true)
+		},
+	}
+
+	resumeFs         = flag.NewFlagSet("uzi resume", flag.ExitOnError)
+	resumeAll        = resumeFs.Bool("all", false, "resume every paused agent session")
+	resumeConfigPath = resumeFs.String("config", config.GetDefaultConfigPath(), "path to config file")
+	CmdResume        = &ffcli.Command{
+		Name:       "resume",
+		ShortUsage: "uzi resume [--all] [agent-name...]",
+		ShortHelp:  "Resume agents stopped by uzi pause",
+		LongHelp: `Send the configured resume keys to paused agents and clear their paused
+mark, so broadcasts and uzi auto reach them again.
+` + keysHelp,
+		FlagSet: resumeFs,
+		Exec: func(ctx context.Context, args []string) error {
+			return executePause(ctx, args, *resumeAll, *resumeConfigPath, false)
+		},
+	}
+)
+
+// executePause pauses or resumes the named agents, or every active agent with all
+func executePause(ctx context.Context, args []string, all bool, configPath string, pause bool) error {
+	sm, err := cmdctx.From(ctx).State()
+	if err != nil {
+		return err
+	}
+
+	activeSessions, err := sm.GetActiveSessionsForRepo()
+	if err != nil {
+		log.Error("Error getting active sessions", "error", err)
+		return err
+	}
+	sessions, err := selectSessions(activeSessions, args, all)
+	if err != nil {
+		return err
+	}
+
+	cfg, err := cmdctx.From(ctx).LoadConfig(configPath)
+	if err != nil {
+		log.Debug("Using default pause keys", "config", configPath, "error", err)
+		cfg = nil
+	}
+
+	return setPaused(sm, cfg, sessions, pause, func(agentState state.AgentState) CommandExecutor {
+		return hosts.ForState(agentState)
+	})
+}
+
+// selectSessions returns the active sessions of the named agents, or every
+// active session with all
+func selectSessions(activeSessions, agentNames []string, all bool) ([]string, error) {
+	if all {
+		if len(agentNames) > 0 {
+			return nil, fmt.Errorf("give either agent names or --all, not both")
+		}
+		if len(activeSessions) == 0 {
+			return nil, fmt.Errorf("no active agent sessions found")
+		}
+		return activeSessions, nil
+	}
+	if len(agentNames) == 0 {
+		return nil, fmt.Errorf("agent name argument or --all is required")
+	}
+
+	var sessions []string
+	for _, agentName := range agentNames {
+		session, err := state.FindAgentSession(activeSessions, agentName)
+		if err != nil {
+			return nil, err
+		}
+		sessions = append(sessions, session)
+	}
+	return sessions, nil
+}
+
+// setPaused sends the stop or resume keys to each session that is not
+// already in the requested state and records the change. route picks the
+// executor that reaches a session's tmux server. Sessions that fail are
+// reported together once the rest are done.
+func setPaused(sm *state.StateManager, cfg *config.Config, sessions []string, pause bool, route func(state.AgentState) CommandExecutor) error {
+	verb := "Resumed"
+	if pause {
+		verb = "Paused"
+	}
+
+	var errs []error
+	for _, session := range sessions {
+		agentName := state.AgentNameFromSession(session)
+		agentState, err := sm.GetWorktreeInfo(session)
+		if err != nil {
+			errs = append(errs, err)
+			continue
+		}
+		if agentState.Paused == pause {
+			fmt.Printf("%s is already %s\n", agentName, strings.ToLower(verb))
+			continue
+		}
+
+		keys := cfg.ResumeKeys(agentName, agentState.Model)
+		if pause {
+			keys = cfg.PauseKeys(agentName, agentState.Model)
+		}
+		if err := sendKeys(route(*agentState), session, keys); err != nil {
+			errs = append(errs, err)
+			continue
+		}
+		if err := sm.SetPaused(session, pause); err != nil {
+			errs = append(errs, fmt.Errorf("failed to record %s as %s: %w", agentName, strings.ToLower(verb), err))
+			continue
+		}
+		fmt.Printf("%s %s (%s)\n", verb, agentName, strings.Join(keys, " "))
+	}
+	return errors.Join(errs...)
+}
+
+// sendKeys sends each key of the sequence to the agent window in order
+func sendKeys(executor CommandExecutor, sessionName string, keys []string) error {
+	for _, k := range keys {
+		if err := executor.Execute("tmux", tmuxops.SendKeysArgs(sessionName, k)...); err != nil {
+			return fmt.Errorf("failed to send %q to %s: %w", k, sessionName, err)
+		}
+	}
+	return nil
+}
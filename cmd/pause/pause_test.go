@@ -0,0 +1,96 @@
+package pause
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/nehpz/claudicus/pkg/config"
+	"github.com/nehpz/claudicus/pkg/state"
+)
+
+// recordingExecutor records every command it is asked to run
+type recordingExecutor struct {
+	commands [][]string
+}
+
+func (r *recordingExecutor) Execute(command string, args ...string) error {
+	r.commands = append(r.commands, append([]string{command}, args...))
+	return nil
+}
+
+func TestSelectSessions(t *testing.T) {
+	active := []string{"agent-repo-abc123-sarah", "agent-repo-abc123-john"}
+
+	got, err := selectSessions(active, []string{"john"}, false)
+	if err != nil || !reflect.DeepEqual(got, []string{"agent-repo-abc123-john"}) {
+		t.Errorf("selectSessions(john) = %v, %v", got, err)
+	}
+	if got, err := selectSessions(active, nil, true); err != nil || !reflect.DeepEqual(got, active) {
+		t.Errorf("selectSessions(--all) = %v, %v", got, err)
+	}
+	for _, tt := range []struct {
+		names []string
+		all   bool
+		want  string
+	}{
+		{nil, false, "agent name argument or --all is required"},
+		{[]string{"sarah"}, true, "not both"},
+		{[]string{"emily"}, false, "no active session found for agent: emily"},
+	} {
+		if _, err := selectSessions(active, tt.names, tt.all); err == nil || !strings.Contains(err.Error(), tt.want) {
+			t.Errorf("selectSessions(%v, %v) error = %v, want %q", tt.names, tt.all, err, tt.want)
+		}
+	}
+}
+
+func TestSetPaused(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+	sm := state.NewStateManager()
+	sessions := []string{"agent-repo-abc123-sarah", "agent-repo-abc123-john"}
+	for _, session := range sessions {
+		if err := sm.SaveState("fix it", "branch", session, "/worktrees/"+session, "claude"); err != nil {
+			t.Fatal(err)
+		}
+	}
+	cfg := &config.Config{Pause: &config.PauseConfig{
+		Stop: &config.KeySequences{Agents: map[string][]string{"john": {"C-c"}}},
+	}}
+	executor := &recordingExecutor{}
+	route := func(state.AgentState) CommandExecutor { return executor }
+
+	if err := setPaused(sm, cfg, sessions, true, route); err != nil {
+		t.Fatalf("setPaused() error = %v", err)
+	}
+	want := [][]string{
+		{"tmux", "send-keys", "-t", "agent-repo-abc123-sarah:{start}", "Escape"},
+		{"tmux", "send-keys", "-t", "agent-repo-abc123-john:{start}", "C-c"},
+	}
+	if !reflect.DeepEqual(executor.commands, want) {
+		t.Errorf("Expected commands %v, got %v", want, executor.commands)
+	}
+	for _, session := range sessions {
+		if info, _ := sm.GetWorktreeInfo(session); !info.Paused {
+			t.Errorf("Expected %s marked paused", session)
+		}
+	}
+
+	// Pausing again sends nothing; resuming sends the resume keys and clears the mark
+	executor.commands = nil
+	if err := setPaused(sm, cfg, sessions[:1], true, route); err != nil || len(executor.commands) != 0 {
+		t.Errorf("Expected a paused session left alone, got %v, %v", executor.commands, err)
+	}
+	if err := setPaused(sm, cfg, sessions[:1], false, route); err != nil {
+		t.Fatalf("setPaused() error = %v", err)
+	}
+	want = [][]string{
+		{"tmux", "send-keys", "-t", "agent-repo-abc123-sarah:{start}", "continue"},
+		{"tmux", "send-keys", "-t", "agent-repo-abc123-sarah:{start}", "Enter"},
+	}
+	if !reflect.DeepEqual(executor.commands, want) {
+		t.Errorf("Expected commands %v, got %v", want, executor.commands)
+	}
+	if info, _ := sm.GetWorktreeInfo(sessions[0]); info.Paused {
+		t.Error("Expected sarah resumed")
+	}
+}
@@ -0,0 +1,100 @@
+package ports
+
+import (
+	"context"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/nehpz/claudicus/pkg/cmdctx"
+	"github.com/nehpz/claudicus/pkg/config"
+	"github.com/nehpz/claudicus/pkg/state"
+
+	"github.com/peterbourgon/ff/v3/ffcli"
+)
+
+var (
+	fs         = flag.NewFlagSet("uzi ports", flag.ExitOnError)
+	jsonOutput = fs.Bool("json", false, "output in JSON format")
+	release    = fs.Int("release", 0, "clear the stale leases of this port so new agents can use it")
+	configPath = fs.String("config", config.GetDefaultConfigPath(), "path to config file")
+	CmdPorts   = &ffcli.Command{
+		Name:       "ports",
+		ShortUsage: "uzi ports [--json] [--release <port>]",
+		ShortHelp:  "List the dev server ports held by agent sessions",
+		LongHelp: `List the dev server port each session holds, with its agent, whether its
+tmux session is still running, and whether the port answers. Ports are
+picked from portRange in uzi.yaml, or from ` + config.AutoPortRange + ` when it is
+unset.
+
+A port stays held while the session's state is kept, so a session that died
+without uzi kill keeps its port from new agents. Such leases are listed as
+stale; --release <port> clears them. Ports of running sessions are only
+freed by killing the session.`,
+		FlagSet: fs,
+		Exec:    executePorts,
+	}
+)
+
+func executePorts(ctx context.Context, args []string) error {
+	sm, err := cmdctx.From(ctx).State()
+	if err != nil {
+		return err
+	}
+
+	if *release != 0 {
+		released, err := sm.ReleasePort(*release)
+		if err != nil {
+			return err
+		}
+		fmt.Printf("Released port %d from %s\n", *release, strings.Join(released, ", "))
+		return nil
+	}
+
+	leases, err := sm.PortLeases()
+	if err != nil {
+		return err
+	}
+	if cmdctx.From(ctx).WantJSON(*jsonOutput) {
+		encoder := json.NewEncoder(os.Stdout)
+		encoder.SetIndent("", "  ")
+		return encoder.Encode(leases)
+	}
+	// Without a config file the automatic range is in use
+	cfg, _ := cmdctx.From(ctx).LoadConfig(*configPath)
+	printLeases(os.Stdout, cfg, leases)
+	return nil
+}
+
+// printLeases writes the port range in use and a table of the leases
+func printLeases(out io.Writer, cfg *config.Config, leases []state.PortLease) {
+	if start, end, auto, err := cfg.DevPortRange(); err == nil {
+		source := "portRange"
+		if auto {
+			source = "automatic; set portRange in uzi.yaml to choose"
+		}
+		fmt.Fprintf(out, "Port range: %d-%d (%s)\n\n", start, end, source)
+	}
+	if len(leases) == 0 {
+		fmt.Fprintln(out, "No ports held by agent sessions")
+		return
+	}
+	w := tabwriter.NewWriter(out, 0, 0, 2, ' ', 0)
+	fmt.Fprintf(w, "PORT\tAGENT\tHOST\tSESSION\tHEALTH\n")
+	for _, lease := range leases {
+		host := lease.Host
+		if host == "" {
+			host = "local"
+		}
+		session := "running"
+		if lease.Stale() {
+			session = "stale"
+		}
+		fmt.Fprintf(w, "%d\t%s\t%s\t%s\t%s\n", lease.Port, lease.Agent, host, session, lease.Health)
+	}
+	w.Flush()
+}
@@ -0,0 +1,37 @@
+package ports
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"github.com/nehpz/claudicus/pkg/config"
+	"github.com/nehpz/claudicus/pkg/state"
+)
+
+func TestPrintLeases(t *testing.T) {
+	leases := []state.PortLease{
+		{Port: 49152, Session: "agent-app-abc123-sarah", Agent: "sarah", Active: true, Health: state.DevServerUp},
+		{Port: 49153, Session: "agent-app-abc123-bob", Agent: "bob", Host: "build", Health: state.DevServerUnknown},
+	}
+
+	var out bytes.Buffer
+	printLeases(&out, nil, leases)
+	lines := strings.Split(strings.TrimSpace(out.String()), "\n")
+	if len(lines) != 5 || lines[0] != "Port range: 49152-65535 (automatic; set portRange in uzi.yaml to choose)" {
+		t.Fatalf("Expected the range, a header and 2 leases, got %q", out.String())
+	}
+	if fields := strings.Fields(lines[3]); strings.Join(fields, " ") != "49152 sarah local running up" {
+		t.Errorf("Unexpected first lease: %q", lines[3])
+	}
+	if fields := strings.Fields(lines[4]); strings.Join(fields, " ") != "49153 bob build stale unknown" {
+		t.Errorf("Unexpected second lease: %q", lines[4])
+	}
+
+	out.Reset()
+	portRange := "3000-3010"
+	printLeases(&out, &config.Config{PortRange: &portRange}, nil)
+	if out.String() != "Port range: 3000-3010 (portRange)\n\nNo ports held by agent sessions\n" {
+		t.Errorf("Unexpected output without leases: %q", out.String())
+	}
+}
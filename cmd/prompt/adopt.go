@@ -0,0 +1,80 @@
+package prompt
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+
+	"github.com/nehpz/claudicus/pkg/agents"
+	"github.com/nehpz/claudicus/pkg/config"
+
+	"github.com/charmbracelet/log"
+	"github.com/peterbourgon/ff/v3/ffcli"
+)
+
+var (
+	adoptFs         = flag.NewFlagSet("uzi adopt", flag.ExitOnError)
+	adoptAgentFlag  = adoptFs.String("agent", "claude", "agent command to run on the adopted branch")
+	adoptConfigPath = adoptFs.String("config", config.GetDefaultConfigPath(), "path to config file")
+	CmdAdopt        = &ffcli.Command{
+		Name:       "adopt",
+		ShortUsage: "uzi adopt [--agent AGENT] BRANCH [prompt text...]",
+		ShortHelp:  "Spawn an agent that continues work on an existing branch",
+		FlagSet:    adoptFs,
+		Exec:       executeAdopt,
+	}
+)
+
+// verifyLocalBranch checks that branch exists as a local branch
+func verifyLocalBranch(ctx context.Context, branch string) error {
+	checkBranchCmd := exec.CommandContext(ctx, "git", "show-ref", "--verify", "--quiet", "refs/heads/"+branch)
+	checkBranchCmd.Dir = filepath.Dir(os.Args[0])
+	if err := checkBranchCmd.Run(); err != nil {
+		return fmt.Errorf("branch %q does not exist", branch)
+	}
+	return nil
+}
+
+func executeAdopt(ctx context.Context, args []string) error {
+	if len(args) == 0 {
+		return fmt.Errorf("branch argument is required")
+	}
+
+	cfg, err := loadSpawnConfig(ctx, *adoptConfigPath)
+	if err != nil {
+		return err
+	}
+
+	branch := args[0]
+	promptText := strings.Join(args[1:], " ")
+	log.Debug("Running adopt command", "branch", branch, "prompt", promptText)
+
+	if err := verifyLocalBranch(ctx, branch); err != nil {
+		return err
+	}
+
+	existingPorts, err := getExistingSessionPorts(stateStore(ctx))
+	if err != nil {
+		log.Warn("Failed to load existing session ports, proceeding without collision check", "error", err)
+		existingPorts = []int{}
+	}
+
+	agentName := agents.GetRandomAgent()
+	command := getCommandForAgent(*adoptAgentFlag)
+	if _, err := spawnAgent(ctx, cfg, spawnRequest{
+		agentName: agentName,
+		command:   command,
+		modelArgs: cfg.AgentModelArgs(*adoptAgentFlag, command),
+		prompt:    promptText,
+		base:      branch,
+		adopt:     true,
+	}, existingPorts); err != nil {
+		return fmt.Errorf("failed to adopt branch %s: %w", branch, err)
+	}
+
+	return nil
+}
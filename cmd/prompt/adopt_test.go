@@ -0,0 +1,156 @@
+package prompt
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/nehpz/claudicus/pkg/hosts"
+	"github.com/nehpz/claudicus/pkg/vcs"
+)
+
+func TestExecuteAdopt(t *testing.T) {
+	tests := []struct {
+		name          string
+		args          []string
+		config        string
+		errorContains string
+	}{
+		{
+			name:          "no arguments provided",
+			args:          []string{},
+			config:        "devCommand: npm start --port $PORT\nportRange: 3000-3010\n",
+			errorContains: "branch argument is required",
+		},
+		{
+			name:          "config missing devCommand",
+			args:          []string{"feature-x"},
+			config:        "portRange: 3000-3010\n",
+			errorContains: "devCommand is required in uzi.yaml",
+		},
+		{
+			name:          "branch does not exist",
+			args:          []string{"uzi-no-such-branch-for-adopt", "keep", "going"},
+			config:        "devCommand: npm start --port $PORT\nportRange: 3000-3010\n",
+			errorContains: "does not exist",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			originalConfigPath := *adoptConfigPath
+			defer func() { *adoptConfigPath = originalConfigPath }()
+
+			configFile := filepath.Join(t.TempDir(), "uzi.yaml")
+			os.WriteFile(configFile, []byte(tt.config), 0644)
+			*adoptConfigPath = configFile
+
+			err := executeAdopt(context.Background(), tt.args)
+			if err == nil {
+				t.Fatalf("Expected error containing %q, but got no error", tt.errorContains)
+			}
+			if !strings.Contains(err.Error(), tt.errorContains) {
+				t.Errorf("Expected error containing %q, but got %q", tt.errorContains, err.Error())
+			}
+		})
+	}
+}
+
+func TestExecutePromptUnknownBase(t *testing.T) {
+	originalConfigPath := *configPath
+	originalBase := *baseFlag
+	defer func() {
+		*configPath = originalConfigPath
+		*baseFlag = originalBase
+	}()
+
+	configFile := filepath.Join(t.TempDir(), "uzi.yaml")
+	os.WriteFile(configFile, []byte("devCommand: npm start --port $PORT\nportRange: 3000-3010\n"), 0644)
+	*configPath = configFile
+	*baseFlag = "uzi-no-such-base-branch"
+
+	err := executePrompt(context.Background(), []string{"test", "prompt"})
+	if err == nil || !strings.Contains(err.Error(), "not found in repository") {
+		t.Errorf("Expected unknown base error, got %v", err)
+	}
+}
+
+func TestResolveBaseCommit(t *testing.T) {
+	defer func(original func(hosts.Target) vcs.VCS) { newRepo = original }(newRepo)
+	fake := vcs.NewFake(repoDir(hosts.Target{}), "main")
+	newRepo = func(hosts.Target) vcs.VCS { return fake }
+
+	commit, err := resolveBaseCommit(context.Background(), hosts.Target{}, "main")
+	if err != nil || commit != "c1" {
+		t.Errorf("Expected main resolved to its commit c1, got %q, %v", commit, err)
+	}
+	if _, err := resolveBaseCommit(context.Background(), hosts.Target{}, "no-such-commit"); err == nil || !strings.Contains(err.Error(), "base commit \"no-such-commit\" not found") {
+		t.Errorf("Expected an unknown base commit error, got %v", err)
+	}
+}
+
+func TestExecutePromptBaseCommitConflicts(t *testing.T) {
+	originalConfigPath, originalBase, originalCommit := *configPath, *baseFlag, *baseCommit
+	defer func() {
+		*configPath, *baseFlag, *baseCommit = originalConfigPath, originalBase, originalCommit
+	}()
+
+	configFile := filepath.Join(t.TempDir(), "uzi.yaml")
+	os.WriteFile(configFile, []byte("devCommand: npm start --port $PORT\nportRange: 3000-3010\n"), 0644)
+	*configPath = configFile
+	*baseFlag = "HEAD"
+	*baseCommit = "HEAD"
+
+	err := executePrompt(context.Background(), []string{"test", "prompt"})
+	if err == nil || !strings.Contains(err.Error(), "--base-commit cannot be combined") {
+		t.Errorf("Expected --base and --base-commit to conflict, got %v", err)
+	}
+}
+
+func TestWorktreeOptions(t *testing.T) {
+	tests := []struct {
+		name string
+		req  spawnRequest
+		want vcs.WorktreeOptions
+	}{
+		{"from HEAD", spawnRequest{}, vcs.WorktreeOptions{Branch: "agent-branch"}},
+		{"from base", spawnRequest{base: "feature-x"}, vcs.WorktreeOptions{Branch: "agent-branch", Base: "feature-x"}},
+		{"adopt branch", spawnRequest{base: "feature-x", adopt: true}, vcs.WorktreeOptions{Base: "feature-x"}},
+		{"pinned commit", spawnRequest{baseCommit: "3f2a9c1e"}, vcs.WorktreeOptions{Branch: "agent-branch", Base: "3f2a9c1e"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := worktreeOptions(tt.req, "agent-branch"); got != tt.want {
+				t.Errorf("worktreeOptions() = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestAgentSendKeysCommand(t *testing.T) {
+	tests := []struct {
+		name      string
+		command   string
+		modelArgs string
+		prompt    string
+		want      string
+	}{
+		{"claude with prompt", "claude", "", "fix it", `tmux send-keys -t 's:{start}' 'claude "fix it"' C-m`},
+		{"gemini with prompt", "gemini", "", "fix it", `tmux send-keys -t 's:{start}' 'gemini -p "fix it"' C-m`},
+		{"no prompt", "claude", "", "", `tmux send-keys -t 's:{start}' 'claude' C-m`},
+		{"model args", "claude", "--model claude-3-opus", "fix it", `tmux send-keys -t 's:{start}' 'claude --model claude-3-opus "fix it"' C-m`},
+		{"gemini model args", "gemini", "-m gemini-2.5-pro", "fix it", `tmux send-keys -t 's:{start}' 'gemini -m gemini-2.5-pro -p "fix it"' C-m`},
+		{"model args without prompt", "codex", "-m o3", "", `tmux send-keys -t 's:{start}' 'codex -m o3' C-m`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := agentSendKeysCommand("s", tt.command, tt.modelArgs, tt.prompt); got != tt.want {
+				t.Errorf("agentSendKeysCommand() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,354 @@
+package prompt
+
+import (
+	"context"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"github.com/nehpz/claudicus/pkg/cmdctx"
+	"github.com/nehpz/claudicus/pkg/config"
+	"github.com/nehpz/claudicus/pkg/pipeline"
+	"github.com/nehpz/claudicus/pkg/state"
+
+	"github.com/charmbracelet/log"
+	"github.com/peterbourgon/ff/v3/ffcli"
+)
+
+var (
+	pipelineFs         = flag.NewFlagSet("uzi pipeline", flag.ExitOnError)
+	pipelineRunFs      = flag.NewFlagSet("uzi pipeline run", flag.ExitOnError)
+	pipelineConfigPath = pipelineRunFs.String("config", config.GetDefaultConfigPath(), "path to config file")
+	pipelinePoll       = pipelineRunFs.Duration("poll", 5*time.Second, "how often to check whether the current stage has checkpointed")
+	pipelineLsFs       = flag.NewFlagSet("uzi pipeline ls", flag.ExitOnError)
+	pipelineLsJSON     = pipelineLsFs.Bool("json", false, "output in JSON format")
+	CmdPipeline        = &ffcli.Command{
+		Name:       "pipeline",
+		ShortUsage: "uzi pipeline <run|ls> [flags]",
+		ShortHelp:  "Run chains of agents where each stage starts after the previous one checkpoints",
+		FlagSet:    pipelineFs,
+		Subcommands: []*ffcli.Command{
+			{
+				Name:       "run",
+				ShortUsage: "uzi pipeline run [--poll 5s] pipeline.yaml",
+				ShortHelp:  "Spawn each stage's agents once every agent of the previous stage has checkpointed",
+				LongHelp: `Run a pipeline definition such as:
+
+  name: feature
+  stages:
+    - name: plan
+      agents: claude:1
+      prompt: Write PLAN.md for the feature
+    - name: implement
+      agents: claude:2
+      prompt: Implement PLAN.md
+
+Each stage waits until all of its agents have been checkpointed with
+"uzi checkpoint" before the next stage is spawned from the updated branch.
+Agents that maxConcurrentAgents has no slot for start as slots free up,
+and their stage waits for them as well. The run fails if an agent session
+ends without checkpointing.`,
+				FlagSet: pipelineRunFs,
+				Exec:    executePipelineRun,
+			},
+			{
+				Name:       "ls",
+				ShortUsage: "uzi pipeline ls [--json]",
+				ShortHelp:  "List pipeline runs and their stage progress",
+				FlagSet:    pipelineLsFs,
+				Exec:       executePipelineLs,
+			},
+		},
+		Exec: func(ctx context.Context, args []string) error {
+			return flag.ErrHelp
+		},
+	}
+)
+
+// pipelineRunner drives a pipeline run; spawnStage and activeAgents are
+// swappable so the stage sequencing can be tested without git or tmux
+type pipelineRunner struct {
+	store        *pipeline.Store
+	spawnStage   func(ctx context.Context, stage pipeline.Stage) ([]string, deferredSpawn, error)
+	activeAgents func() (map[string]bool, error)
+	poll         time.Duration
+	out          io.Writer
+}
+
+func executePipelineRun(ctx context.Context, args []string) error {
+	if len(args) != 1 {
+		return fmt.Errorf("pipeline file argument is required")
+	}
+
+	def, err := pipeline.Load(args[0])
+	if err != nil {
+		return err
+	}
+
+	cfg, err := loadSpawnConfig(ctx, *pipelineConfigPath)
+	if err != nil {
+		return err
+	}
+
+	store, err := pipeline.NewStore()
+	if err != nil {
+		return fmt.Errorf("could not open pipeline state: %w", err)
+	}
+
+	runner := &pipelineRunner{
+		store: store,
+		spawnStage: func(ctx context.Context, stage pipeline.Stage) ([]string, deferredSpawn, error) {
+			return spawnPipelineStage(ctx, cfg, stage)
+		},
+		activeAgents: func() (map[string]bool, error) {
+			return activeAgentNames(ctx)
+		},
+		poll: *pipelinePoll,
+		out:  os.Stdout,
+	}
+
+	_, err = runner.run(ctx, def, args[0])
+	return err
+}
+
+// deferredSpawn spawns the agents of a stage that maxConcurrentAgents held
+// back and that now have a slot. It returns their names and whether agents
+// are still held back.
+type deferredSpawn func(ctx context.Context) (spawned []string, waiting bool)
+
+// spawnPipelineStage spawns the agents of one stage from the current HEAD.
+// Agents without a slot under maxConcurrentAgents are held back instead of
+// queued, since the stage waits for them by name; the returned deferredSpawn
+// starts them as slots free up and is nil when every agent got a slot.
+func spawnPipelineStage(ctx context.Context, cfg *config.Config, stage pipeline.Stage) ([]string, deferredSpawn, error) {
+	agentConfigs, err := parseAgents(stage.AgentsOrDefault())
+	if err != nil {
+		return nil, nil, fmt.Errorf("error parsing agents: %s", err)
+	}
+
+	sm := stateStore(ctx)
+	if sm == nil {
+		return nil, nil, fmt.Errorf("could not initialize state manager")
+	}
+	slots, err := newSpawnSlots(cfg, sm, nil)
+	if err != nil {
+		return nil, nil, err
+	}
+	existingPorts, err := getExistingSessionPorts(sm)
+	if err != nil {
+		log.Warn("Failed to load existing session ports, proceeding without collision check", "error", err)
+		existingPorts = []int{}
+	}
+
+	spawned := spawnAgents(ctx, cfg, []agentTask{{configs: agentConfigs, prompt: stage.Prompt}}, spawnRequest{}, existingPorts, slots)
+	if len(slots.held) == 0 {
+		return spawned, nil, nil
+	}
+
+	held := slots.held
+	return spawned, func(ctx context.Context) ([]string, bool) {
+		slots, err := newSpawnSlots(cfg, sm, nil)
+		if err != nil {
+			log.Warn("Could not count free agent slots", "error", err)
+			return nil, true
+		}
+		assignedPorts, err := getExistingSessionPorts(sm)
+		if err != nil {
+			log.Warn("Failed to load existing session ports, proceeding without collision check", "error", err)
+			assignedPorts = []int{}
+		}
+		var started []string
+		for len(held) > 0 && slots.take() {
+			req := held[0]
+			held = held[1:]
+			port, err := spawnAgent(ctx, cfg, req, assignedPorts)
+			if port > 0 {
+				assignedPorts = append(assignedPorts, port)
+			}
+			if err != nil {
+				log.Error("Dropping held back agent that failed to spawn", "agent", req.agentName, "error", err)
+				continue
+			}
+			started = append(started, req.agentName)
+		}
+		return started, len(held) > 0
+	}, nil
+}
+
+// activeAgentNames returns the agent names of the repository's live tmux sessions
+func activeAgentNames(ctx context.Context) (map[string]bool, error) {
+	sm, err := cmdctx.From(ctx).State()
+	if err != nil {
+		return nil, err
+	}
+	sessions, err := sm.GetActiveSessionsForRepo()
+	if err != nil {
+		return nil, err
+	}
+
+	active := make(map[string]bool, len(sessions))
+	for _, session := range sessions {
+		active[state.AgentNameFromSession(session)] = true
+	}
+	return active, nil
+}
+
+// run executes the stages in order and returns the final run record
+func (r *pipelineRunner) run(ctx context.Context, def *pipeline.Definition, file string) (*pipeline.Run, error) {
+	run := pipeline.NewRun(def, file, time.Now())
+	if err := r.store.Save(run); err != nil {
+		return nil, fmt.Errorf("error saving pipeline run: %w", err)
+	}
+	fmt.Fprintf(r.out, "Started pipeline %s (%d stages)\n", run.ID, len(def.Stages))
+
+	for i, stage := range def.Stages {
+		agents, deferred, err := r.spawnStage(ctx, stage)
+		if err == nil && len(agents) == 0 && deferred == nil {
+			err = fmt.Errorf("no agents could be spawned")
+		}
+		if err != nil {
+			return r.fail(run, i, fmt.Errorf("stage %s: %w", stage.Name, err))
+		}
+
+		run.Stages[i].Status = pipeline.StatusRunning
+		run.Stages[i].Agents = agents
+		if err := r.store.Save(run); err != nil {
+			return run, fmt.Errorf("error saving pipeline run: %w", err)
+		}
+		switch {
+		case deferred != nil && len(agents) == 0:
+			fmt.Fprintf(r.out, "Stage %s: waiting for agent slots to free up\n", stage.Name)
+		case deferred != nil:
+			fmt.Fprintf(r.out, "Stage %s: spawned %s; more agents start as slots free up\n", stage.Name, strings.Join(agents, ", "))
+		default:
+			fmt.Fprintf(r.out, "Stage %s: spawned %s; waiting for checkpoints\n", stage.Name, strings.Join(agents, ", "))
+		}
+
+		if run, err = r.waitForStage(ctx, run.ID, i, deferred); err != nil {
+			return run, err
+		}
+		fmt.Fprintf(r.out, "Stage %s: all agents checkpointed\n", stage.Name)
+	}
+
+	run.Status = pipeline.StatusCompleted
+	if err := r.store.Save(run); err != nil {
+		return run, fmt.Errorf("error saving pipeline run: %w", err)
+	}
+	fmt.Fprintf(r.out, "Pipeline %s completed\n", run.ID)
+	return run, nil
+}
+
+// waitForStage polls the store until every agent of the stage has checkpointed,
+// spawning the agents deferred holds back as slots free up. Checkpoints are
+// recorded by `uzi checkpoint` in a separate process.
+func (r *pipelineRunner) waitForStage(ctx context.Context, runID string, stageIdx int, deferred deferredSpawn) (*pipeline.Run, error) {
+	ticker := time.NewTicker(r.poll)
+	defer ticker.Stop()
+
+	for {
+		run, err := r.store.Get(runID)
+		if err != nil {
+			return nil, fmt.Errorf("error reading pipeline run: %w", err)
+		}
+
+		stage := &run.Stages[stageIdx]
+		if deferred != nil {
+			started, waiting := deferred(ctx)
+			if !waiting {
+				deferred = nil
+			}
+			if len(started) > 0 {
+				stage.Agents = append(stage.Agents, started...)
+				if err := r.store.Save(run); err != nil {
+					return run, fmt.Errorf("error saving pipeline run: %w", err)
+				}
+				fmt.Fprintf(r.out, "Stage %s: spawned %s\n", stage.Name, strings.Join(started, ", "))
+			}
+			if deferred == nil && len(stage.Agents) == 0 {
+				return r.fail(run, stageIdx, fmt.Errorf("stage %s: no agents could be spawned", stage.Name))
+			}
+		}
+		if deferred == nil && stage.Done() {
+			stage.Status = pipeline.StatusCompleted
+			if err := r.store.Save(run); err != nil {
+				return run, fmt.Errorf("error saving pipeline run: %w", err)
+			}
+			return run, nil
+		}
+
+		active, err := r.activeAgents()
+		if err != nil {
+			log.Warn("Could not check agent sessions", "error", err)
+		} else {
+			for _, agent := range stage.Agents {
+				if active[agent] || stage.IsCheckpointed(agent) {
+					continue
+				}
+				// The agent may have checkpointed and exited since the run was read
+				if latest, err := r.store.Get(runID); err == nil {
+					run, stage = latest, &latest.Stages[stageIdx]
+				}
+				if !stage.IsCheckpointed(agent) {
+					return r.fail(run, stageIdx, fmt.Errorf("stage %s: agent %s exited before checkpointing", stage.Name, agent))
+				}
+			}
+		}
+
+		select {
+		case <-ctx.Done():
+			r.fail(run, stageIdx, fmt.Errorf("stage %s: cancelled", stage.Name))
+			return run, ctx.Err()
+		case <-ticker.C:
+		}
+	}
+}
+
+// fail marks the stage and run as failed and records the reason
+func (r *pipelineRunner) fail(run *pipeline.Run, stageIdx int, err error) (*pipeline.Run, error) {
+	run.Status = pipeline.StatusFailed
+	run.Stages[stageIdx].Status = pipeline.StatusFailed
+	run.Error = err.Error()
+	if saveErr := r.store.Save(run); saveErr != nil {
+		log.Error("Error saving pipeline run", "error", saveErr)
+	}
+	return run, err
+}
+
+func executePipelineLs(ctx context.Context, args []string) error {
+	store, err := pipeline.NewStore()
+	if err != nil {
+		return fmt.Errorf("could not open pipeline state: %w", err)
+	}
+	runs, err := store.List()
+	if err != nil {
+		return err
+	}
+	return printPipelineRuns(os.Stdout, runs, cmdctx.From(ctx).WantJSON(*pipelineLsJSON))
+}
+
+// printPipelineRuns writes the runs as a table or as JSON
+func printPipelineRuns(w io.Writer, runs []pipeline.Run, asJSON bool) error {
+	if asJSON {
+		return json.NewEncoder(w).Encode(runs)
+	}
+	if len(runs) == 0 {
+		_, err := fmt.Fprintln(w, "No pipeline runs")
+		return err
+	}
+
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(tw, "RUN\tSTATUS\tSTAGES")
+	for _, run := range runs {
+		stages := make([]string, 0, len(run.Stages))
+		for _, stage := range run.Stages {
+			stages = append(stages, fmt.Sprintf("%s:%s(%d/%d)", stage.Name, stage.Status, len(stage.Checkpointed), len(stage.Agents)))
+		}
+		fmt.Fprintf(tw, "%s\t%s\t%s\n", run.ID, run.Status, strings.Join(stages, " → "))
+	}
+	return tw.Flush()
+}
@@ -0,0 +1,244 @@
+package prompt
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/nehpz/claudicus/pkg/pipeline"
+)
+
+func testPipeline() *pipeline.Definition {
+	return &pipeline.Definition{
+		Name: "chain",
+		Stages: []pipeline.Stage{
+			{Name: "plan", Agents: "claude:1", Prompt: "plan"},
+			{Name: "implement", Agents: "claude:2", Prompt: "implement"},
+		},
+	}
+}
+
+// newTestRunner returns a runner whose agents checkpoint as soon as they are spawned
+func newTestRunner(t *testing.T) (*pipelineRunner, *[]string) {
+	t.Helper()
+	store := pipeline.NewStoreAt(filepath.Join(t.TempDir(), "pipelines.json"))
+	var spawnedStages []string
+
+	runner := &pipelineRunner{
+		store: store,
+		poll:  time.Millisecond,
+		out:   &bytes.Buffer{},
+		activeAgents: func() (map[string]bool, error) {
+			return map[string]bool{}, nil
+		},
+	}
+	runner.spawnStage = func(ctx context.Context, stage pipeline.Stage) ([]string, deferredSpawn, error) {
+		spawnedStages = append(spawnedStages, stage.Name)
+		agent := stage.Name + "-agent"
+		// Simulate `uzi checkpoint` landing from another process after the stage is saved
+		go func() {
+			for {
+				if updated, _ := store.MarkCheckpointed(agent); updated {
+					return
+				}
+				time.Sleep(time.Millisecond)
+			}
+		}()
+		return []string{agent}, nil, nil
+	}
+	return runner, &spawnedStages
+}
+
+func TestPipelineRunner_RunsStagesInOrder(t *testing.T) {
+	runner, spawned := newTestRunner(t)
+	runner.activeAgents = func() (map[string]bool, error) {
+		return map[string]bool{"plan-agent": true, "implement-agent": true}, nil
+	}
+
+	run, err := runner.run(context.Background(), testPipeline(), "chain.yaml")
+	if err != nil {
+		t.Fatalf("run() error = %v", err)
+	}
+	if strings.Join(*spawned, ",") != "plan,implement" {
+		t.Errorf("Expected stages spawned in order, got %v", *spawned)
+	}
+	if run.Status != pipeline.StatusCompleted {
+		t.Errorf("Run status = %q, want completed", run.Status)
+	}
+
+	stored, err := runner.store.Get(run.ID)
+	if err != nil {
+		t.Fatalf("Get() error = %v", err)
+	}
+	for _, stage := range stored.Stages {
+		if stage.Status != pipeline.StatusCompleted {
+			t.Errorf("Stage %s status = %q, want completed", stage.Name, stage.Status)
+		}
+	}
+}
+
+func TestPipelineRunner_FailsWhenAgentExits(t *testing.T) {
+	runner, spawned := newTestRunner(t)
+	runner.spawnStage = func(ctx context.Context, stage pipeline.Stage) ([]string, deferredSpawn, error) {
+		*spawned = append(*spawned, stage.Name)
+		return []string{"ghost"}, nil, nil
+	}
+
+	run, err := runner.run(context.Background(), testPipeline(), "chain.yaml")
+	if err == nil || !strings.Contains(err.Error(), "agent ghost exited before checkpointing") {
+		t.Fatalf("Expected exited agent error, got %v", err)
+	}
+	if len(*spawned) != 1 {
+		t.Errorf("Expected later stages not to spawn, got %v", *spawned)
+	}
+	if run.Status != pipeline.StatusFailed || run.Stages[0].Status != pipeline.StatusFailed {
+		t.Errorf("Expected failed run and stage, got %q/%q", run.Status, run.Stages[0].Status)
+	}
+	if run.Stages[1].Status != pipeline.StatusPending {
+		t.Errorf("Expected next stage to stay pending, got %q", run.Stages[1].Status)
+	}
+}
+
+func TestPipelineRunner_AgentCheckpointsThenExits(t *testing.T) {
+	runner, _ := newTestRunner(t)
+	runner.spawnStage = func(ctx context.Context, stage pipeline.Stage) ([]string, deferredSpawn, error) {
+		return []string{stage.Name + "-agent"}, nil, nil
+	}
+	// The agent checkpoints and is killed between the read of the run and the
+	// check of the sessions
+	runner.activeAgents = func() (map[string]bool, error) {
+		runner.store.MarkCheckpointed("plan-agent")
+		runner.store.MarkCheckpointed("implement-agent")
+		return map[string]bool{}, nil
+	}
+
+	run, err := runner.run(context.Background(), testPipeline(), "chain.yaml")
+	if err != nil {
+		t.Fatalf("Expected the checkpoint to count, got %v", err)
+	}
+	if run.Status != pipeline.StatusCompleted {
+		t.Errorf("Run status = %q, want completed", run.Status)
+	}
+}
+
+func TestPipelineRunner_SpawnsDeferredAgents(t *testing.T) {
+	runner, _ := newTestRunner(t)
+	runner.activeAgents = func() (map[string]bool, error) {
+		return map[string]bool{"plan-a": true, "plan-b": true, "implement-agent": true}, nil
+	}
+	checkpoint := func(agent string) {
+		go func() {
+			for {
+				if updated, _ := runner.store.MarkCheckpointed(agent); updated {
+					return
+				}
+				time.Sleep(time.Millisecond)
+			}
+		}()
+	}
+	runner.spawnStage = func(ctx context.Context, stage pipeline.Stage) ([]string, deferredSpawn, error) {
+		if stage.Name != "plan" {
+			checkpoint("implement-agent")
+			return []string{"implement-agent"}, nil, nil
+		}
+		checkpoint("plan-a")
+		// plan-b waits a few polls for a slot
+		polls := 0
+		return []string{"plan-a"}, func(ctx context.Context) ([]string, bool) {
+			if polls++; polls < 3 {
+				return nil, true
+			}
+			checkpoint("plan-b")
+			return []string{"plan-b"}, false
+		}, nil
+	}
+
+	run, err := runner.run(context.Background(), testPipeline(), "chain.yaml")
+	if err != nil {
+		t.Fatalf("run() error = %v", err)
+	}
+	plan := run.Stages[0]
+	if strings.Join(plan.Agents, ",") != "plan-a,plan-b" || !plan.IsCheckpointed("plan-b") {
+		t.Errorf("Expected the stage to wait for its deferred agent, got %+v", plan)
+	}
+}
+
+func TestPipelineRunner_SpawnFailure(t *testing.T) {
+	runner, _ := newTestRunner(t)
+	runner.spawnStage = func(ctx context.Context, stage pipeline.Stage) ([]string, deferredSpawn, error) {
+		return nil, nil, errors.New("boom")
+	}
+
+	run, err := runner.run(context.Background(), testPipeline(), "chain.yaml")
+	if err == nil || !strings.Contains(err.Error(), "stage plan: boom") {
+		t.Fatalf("Expected spawn error, got %v", err)
+	}
+	if run.Error == "" {
+		t.Error("Expected failure reason to be recorded")
+	}
+
+	runner.spawnStage = func(ctx context.Context, stage pipeline.Stage) ([]string, deferredSpawn, error) {
+		return nil, nil, nil
+	}
+	if _, err := runner.run(context.Background(), testPipeline(), "chain.yaml"); err == nil || !strings.Contains(err.Error(), "no agents could be spawned") {
+		t.Errorf("Expected empty stage error, got %v", err)
+	}
+}
+
+func TestPipelineRunner_Cancelled(t *testing.T) {
+	runner, _ := newTestRunner(t)
+	runner.spawnStage = func(ctx context.Context, stage pipeline.Stage) ([]string, deferredSpawn, error) {
+		return []string{"busy"}, nil, nil
+	}
+	runner.activeAgents = func() (map[string]bool, error) {
+		return map[string]bool{"busy": true}, nil
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+
+	run, err := runner.run(ctx, testPipeline(), "chain.yaml")
+	if !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("Expected deadline error, got %v", err)
+	}
+	if run.Status != pipeline.StatusFailed {
+		t.Errorf("Expected cancelled run to be marked failed, got %q", run.Status)
+	}
+}
+
+func TestPrintPipelineRuns(t *testing.T) {
+	var buf bytes.Buffer
+	if err := printPipelineRuns(&buf, nil, false); err != nil {
+		t.Fatalf("printPipelineRuns() error = %v", err)
+	}
+	if !strings.Contains(buf.String(), "No pipeline runs") {
+		t.Errorf("Expected empty message, got %q", buf.String())
+	}
+
+	run := pipeline.NewRun(testPipeline(), "chain.yaml", time.Unix(100, 0))
+	run.Stages[0].Status = pipeline.StatusRunning
+	run.Stages[0].Agents = []string{"sarah", "john"}
+	run.Stages[0].Checkpointed = []string{"sarah"}
+
+	buf.Reset()
+	printPipelineRuns(&buf, []pipeline.Run{*run}, false)
+	if !strings.Contains(buf.String(), "chain-100") || !strings.Contains(buf.String(), "plan:running(1/2)") {
+		t.Errorf("Unexpected table output: %q", buf.String())
+	}
+
+	buf.Reset()
+	printPipelineRuns(&buf, []pipeline.Run{*run}, true)
+	if !strings.Contains(buf.String(), `"id":"chain-100"`) {
+		t.Errorf("Unexpected JSON output: %q", buf.String())
+	}
+}
+
+func TestExecutePipelineRun_RequiresFile(t *testing.T) {
+	if err := executePipelineRun(context.Background(), nil); err == nil || !strings.Contains(err.Error(), "pipeline file argument is required") {
+		t.Errorf("Expected missing file error, got %v", err)
+	}
+}
@@ -14,8 +14,15 @@ import (
 	"time"
 
 	"github.com/nehpz/claudicus/pkg/agents"
+	"github.com/nehpz/claudicus/pkg/cmdctx"
 	"github.com/nehpz/claudicus/pkg/config"
+	"github.com/nehpz/claudicus/pkg/events"
+	"github.com/nehpz/claudicus/pkg/hosts"
+	"github.com/nehpz/claudicus/pkg/keyring"
+	"github.com/nehpz/claudicus/pkg/spawnqueue"
 	"github.com/nehpz/claudicus/pkg/state"
+	"github.com/nehpz/claudicus/pkg/tmuxops"
+	"github.com/nehpz/claudicus/pkg/vcs"
 
 	"github.com/charmbracelet/log"
 	"github.com/peterbourgon/ff/v3/ffcli"
@@ -28,17 +35,170 @@ type AgentConfig struct {
 
 var (
 	fs         = flag.NewFlagSet("uzi prompt", flag.ExitOnError)
-	agentsFlag = fs.String("agents", "claude:1", "agents to run with their commands and counts (e.g., 'claude:1,codex:2'). Use 'random' as agent name to select a random agent name.")
+	agentsFlag = new(string)
+	taskFlags  = &promptTasks{agents: agentsFlag}
 	configPath = fs.String("config", config.GetDefaultConfigPath(), "path to config file")
+	baseFlag   = fs.String("base", "", "existing branch or commit to create agent worktrees from (defaults to HEAD)")
+	baseCommit = fs.String("base-commit", "", "commit to pin agent worktrees to, recorded in state so agents spawned at different times start from and are compared against the same commit")
+	maxRuntime = fs.Duration("max-runtime", 0, "runtime budget per agent (e.g. 2h); uzi auto warns at 80% and acts once it is exceeded")
+	noWorktree = fs.Bool("no-worktree", false, "run agents in the main checkout without a worktree, branch, or dev server (for read-only reviewers); these sessions cannot be checkpointed")
+	hostFlag   = fs.String("host", "", "spawn agents over ssh on a host from the hosts: section of uzi.yaml")
+	devCmdFlag = fs.String("dev-command", "", "dev server command for these agents, with $PORT where the port goes, or the name of a devCommands preset from uzi.yaml; replaces devCommand")
+	modelArgs  = fs.String("model-args", "", "extra arguments for every agent CLI, e.g. \"--model claude-3-opus\"; replaces modelArgs from uzi.yaml")
+	channels   channelsFlag
+	runName    = fs.String("run-name", "", "group the spawned agents under this run, for uzi run-status and uzi kill --run")
+	allowDirty = fs.Bool("allow-dirty", false, "spawn even if the main checkout has uncommitted changes or is behind its upstream branch")
+	keepFailed = fs.Bool("keep-on-failure", false, "leave the branch, worktree and tmux session of an agent that fails to spawn in place for debugging instead of removing them")
 	CmdPrompt  = &ffcli.Command{
 		Name:       "prompt",
-		ShortUsage: "uzi prompt --agents=AGENT:COUNT[,AGENT:COUNT...] prompt text...",
+		ShortUsage: "uzi prompt --agents=AGENT:COUNT[,AGENT:COUNT...] [--base BRANCH | --base-commit SHA | --no-worktree] [--host HOST] [--max-runtime DURATION] [--model-args ARGS] [--dev-command CMD|PRESET] [--channel NAME] [--run-name NAME] [--allow-dirty] [--keep-on-failure] prompt text...",
 		ShortHelp:  "Run the prompt command with specified agents and counts",
-		FlagSet:    fs,
-		Exec:       executePrompt,
+		LongHelp: `
+The prompt command spawns the agents given by --agents, each in its own
+worktree and tmux session, and starts them on the prompt text.
+
+To give different agents different tasks in one invocation, pair each
+--agents with a --prompt instead of passing the prompt text:
+
+  uzi prompt --agents claude:1 --prompt "Fix the login bug" \
+             --agents codex:2 --prompt "Write tests for the login flow"
+
+A --prompt before any --agents uses the default agents. The other flags
+apply to every agent spawned.
+
+Agents branch from HEAD, so they don't see uncommitted changes in the main
+checkout or commits it has yet to pull. uzi prompt warns about such a
+checkout, or refuses to spawn with dirtyCheckout: block in uzi.yaml;
+--allow-dirty spawns anyway.
+
+For controlled comparisons, --base-commit pins agents to a commit instead:
+agents spawned with the same commit, even hours apart while main moves on,
+start from identical code. The commit is recorded with each session, and
+uzi status counts the agent's commits and main's new commits against it.
+
+--run-name groups the agents under a named run, such as one experiment
+with several agents: uzi run-status shows how the run's agents are doing,
+uzi kill --run kills them together, and the TUI lists them under one
+collapsible header. Agents of later uzi prompt calls with the same
+--run-name join the run.
+`,
+		FlagSet: fs,
+		Exec:    executePrompt,
 	}
 )
 
+func init() {
+	*agentsFlag = "claude:1"
+	fs.Var(agentsValue{taskFlags}, "agents", "agents to run with their commands and counts (e.g., 'claude:1,codex:2'); defaults to defaultAgents from uzi.yaml, or claude:1. Use 'random' as agent name to select a random agent name. Repeat with --prompt to give each group of agents its own prompt")
+	fs.Var(promptValue{taskFlags}, "prompt", "prompt for the agents of the preceding --agents, instead of the prompt text (repeatable)")
+	fs.Var(&channels, "channel", "subscribe the agents to this broadcast channel for `uzi broadcast --channel` (repeatable)")
+}
+
+// channelsFlag collects --channel names; it may be repeated or given a comma-separated list
+type channelsFlag []string
+
+func (c *channelsFlag) String() string {
+	return strings.Join(*c, ",")
+}
+
+func (c *channelsFlag) Set(value string) error {
+	for _, channel := range strings.Split(value, ",") {
+		if channel = strings.TrimSpace(channel); channel == "" {
+			continue
+		}
+		if err := config.ValidateChannel(channel); err != nil {
+			return err
+		}
+		*c = append(*c, channel)
+	}
+	return nil
+}
+
+// spawnTask is a group of agents given the same prompt
+type spawnTask struct {
+	agents string // agents and counts in the --agents format; empty uses the default agents
+	prompt string // empty until the --prompt of the group is given
+}
+
+// promptTasks pairs each --agents with the --prompt that follows it. The
+// latest --agents value is also kept in agents for the single-prompt form.
+type promptTasks struct {
+	agents *string
+	list   []spawnTask
+}
+
+// agentsValue is the flag.Value of --agents
+type agentsValue struct{ t *promptTasks }
+
+func (v agentsValue) String() string {
+	if v.t == nil || v.t.agents == nil {
+		return ""
+	}
+	return *v.t.agents
+}
+
+func (v agentsValue) Set(value string) error {
+	*v.t.agents = value
+	v.t.list = append(v.t.list, spawnTask{agents: value})
+	return nil
+}
+
+// promptValue is the flag.Value of --prompt
+type promptValue struct{ t *promptTasks }
+
+func (v promptValue) String() string {
+	return ""
+}
+
+func (v promptValue) Set(value string) error {
+	if strings.TrimSpace(value) == "" {
+		return fmt.Errorf("--prompt must not be empty")
+	}
+	n := len(v.t.list)
+	switch {
+	case n == 0:
+		v.t.list = append(v.t.list, spawnTask{prompt: value})
+	case v.t.list[n-1].prompt == "":
+		v.t.list[n-1].prompt = value
+	default:
+		return fmt.Errorf("--prompt %q must follow its own --agents; each --agents takes one --prompt", value)
+	}
+	return nil
+}
+
+// resolveTasks returns the agent groups to spawn and their prompts. Without
+// any --prompt the prompt text from args goes to defaultAgents; with --prompt
+// every --agents needs its own and no prompt text may be given.
+func resolveTasks(list []spawnTask, args []string, defaultAgents string) ([]spawnTask, error) {
+	paired := false
+	for _, task := range list {
+		if task.prompt != "" {
+			paired = true
+		}
+	}
+	if !paired {
+		if len(args) == 0 {
+			return nil, fmt.Errorf("prompt argument is required")
+		}
+		return []spawnTask{{agents: defaultAgents, prompt: strings.Join(args, " ")}}, nil
+	}
+
+	if len(args) > 0 {
+		return nil, fmt.Errorf("give prompts either with --prompt or as prompt text, not both")
+	}
+	resolved := make([]spawnTask, 0, len(list))
+	for _, task := range list {
+		if task.prompt == "" {
+			return nil, fmt.Errorf("--agents %s has no --prompt after it", task.agents)
+		}
+		if task.agents == "" {
+			task.agents = defaultAgents
+		}
+		resolved = append(resolved, task)
+	}
+	return resolved, nil
+}
+
 // parseAgents parses the agents flag value into a map of agent configs
 func parseAgents(agentsStr string) (map[string]AgentConfig, error) {
 	agentConfigs := make(map[string]AgentConfig)
@@ -76,23 +236,10 @@ func parseAgents(agentsStr string) (map[string]AgentConfig, error) {
 	return agentConfigs, nil
 }
 
-// getCommandForAgent maps agent names to their actual CLI commands
+// getCommandForAgent maps agent names to their actual CLI commands, from
+// the built-in and configured agent definitions
 func getCommandForAgent(agent string) string {
-	switch agent {
-	case "claude":
-		return "claude"
-	case "cursor":
-		return "cursor"
-	case "codex":
-		return "codex"
-	case "gemini":
-		return "gemini"
-	case "random":
-		return "claude" // Default for random agents
-	default:
-		// For unknown agents, assume the agent name is the command
-		return agent
-	}
+	return agents.Command(agent)
 }
 
 // isPortAvailable checks if a port is available for use
@@ -106,6 +253,16 @@ func isPortAvailable(port int) bool {
 	return true
 }
 
+// stateStore returns the state store of the command context ctx carries, or
+// nil if it cannot be created; callers go on without state then
+func stateStore(ctx context.Context) *state.StateManager {
+	sm, err := cmdctx.From(ctx).State()
+	if err != nil {
+		return nil
+	}
+	return sm
+}
+
 // getExistingSessionPorts reads the state file and returns all currently assigned ports
 func getExistingSessionPorts(stateManager *state.StateManager) ([]int, error) {
 	if stateManager == nil {
@@ -140,8 +297,8 @@ func getExistingSessionPorts(stateManager *state.StateManager) ([]int, error) {
 	return existingPorts, nil
 }
 
-// findAvailablePort finds the first available port in the given range, excluding already assigned ports
-func findAvailablePort(startPort, endPort int, assignedPorts []int) (int, error) {
+// FindAvailablePort finds the first available port in the given range, excluding already assigned ports
+func FindAvailablePort(startPort, endPort int, assignedPorts []int) (int, error) {
 	for port := startPort; port <= endPort; port++ {
 		// Check if port is already assigned in this execution
 		alreadyAssigned := false
@@ -163,28 +320,93 @@ func findAvailablePort(startPort, endPort int, assignedPorts []int) (int, error)
 	return 0, fmt.Errorf("no available ports in range %d-%d", startPort, endPort)
 }
 
-func executePrompt(ctx context.Context, args []string) error {
-	if len(args) == 0 {
-		return fmt.Errorf("prompt argument is required")
-	}
-
+// loadSpawnConfig loads uzi.yaml and validates the fields required to spawn agents
+func loadSpawnConfig(ctx context.Context, path string) (*config.Config, error) {
 	// Load config - uzi.yaml is required for standardized dev environment setup
-	cfg, err := config.LoadConfig(*configPath)
+	cfg, err := cmdctx.From(ctx).LoadConfig(path)
 	if err != nil {
-		return fmt.Errorf("uzi.yaml configuration file is required but could not be loaded: %w\n\nThe uzi.yaml file is critical for:\n1. Standardizing the development environment setup\n2. Providing an available range of ports for the application\n\nPlease create a uzi.yaml file with:\n  devCommand: your-dev-command --port $PORT\n  portRange: 3000-3010", err)
+		return nil, fmt.Errorf("uzi.yaml configuration file is required but could not be loaded: %w\n\nThe uzi.yaml file is critical for:\n1. Standardizing the development environment setup\n2. Providing an available range of ports for the application\n\nPlease create a uzi.yaml file with:\n  devCommand: your-dev-command --port $PORT\n  portRange: 3000-3010  # optional; ports are picked from %s without it", err, config.AutoPortRange)
 	}
 	if cfg.DevCommand == nil || *cfg.DevCommand == "" {
-		return fmt.Errorf("devCommand is required in uzi.yaml for standardized development environment setup")
+		return nil, fmt.Errorf("devCommand is required in uzi.yaml for standardized development environment setup")
+	}
+	if err := cfg.Validate(); err != nil {
+		return nil, fmt.Errorf("invalid uzi.yaml: %w", err)
+	}
+	state.SetSessionTemplate(cfg.SessionTemplate())
+	return cfg, nil
+}
+
+// verifyBase checks that the given branch or commit exists in the repository
+// agents are spawned from
+func verifyBase(ctx context.Context, target hosts.Target, base string) error {
+	if _, err := newRepo(target).RevParse(ctx, repoDir(target), base); err != nil {
+		if !target.IsLocal() {
+			return fmt.Errorf("base %q not found in %s on %s", base, target.RepoPath, target)
+		}
+		return fmt.Errorf("base %q not found in repository", base)
+	}
+	return nil
+}
+
+// resolveBaseCommit resolves the revision given to --base-commit to the full
+// id of its commit, so the agents stay pinned to it when a branch it names
+// moves on
+func resolveBaseCommit(ctx context.Context, target hosts.Target, rev string) (string, error) {
+	commit, err := newRepo(target).RevParse(ctx, repoDir(target), rev)
+	if err != nil {
+		if !target.IsLocal() {
+			return "", fmt.Errorf("base commit %q not found in %s on %s", rev, target.RepoPath, target)
+		}
+		return "", fmt.Errorf("base commit %q not found in repository", rev)
+	}
+	return commit, nil
+}
+
+// checkCheckout applies the dirtyCheckout policy of uzi.yaml to the checkout
+// in dir that agents are about to branch from: uncommitted changes or missing
+// upstream commits are warned about, or refused under block. A checkout that
+// can't be inspected is not held against the spawn.
+func checkCheckout(cfg *config.Config, executor state.CommandExecutor, dir string, allowDirty bool) error {
+	policy := cfg.DirtyCheckout()
+	if allowDirty || policy == config.DirtyCheckoutAllow {
+		return nil
+	}
+	status, err := state.InspectCheckout(executor, dir)
+	if err != nil {
+		log.Debug("Could not inspect the main checkout", "error", err)
+		return nil
+	}
+	if status.Clean() {
+		return nil
 	}
-	if cfg.PortRange == nil || *cfg.PortRange == "" {
-		return fmt.Errorf("portRange is required in uzi.yaml to define available ports for agent sessions")
+	if policy == config.DirtyCheckoutBlock {
+		return fmt.Errorf("%s; pass --allow-dirty to spawn anyway", status.SpawnWarning())
 	}
+	log.Warn(status.SpawnWarning())
+	return nil
+}
 
-	promptText := strings.Join(args, " ")
-	log.Debug("Running prompt command", "prompt", promptText)
+// repoDir returns the repository agents are spawned from on the target
+func repoDir(target hosts.Target) string {
+	if target.IsLocal() {
+		return filepath.Dir(os.Args[0])
+	}
+	return target.RepoPath
+}
+
+func executePrompt(ctx context.Context, args []string) error {
+	if len(args) == 0 && len(taskFlags.list) == 0 {
+		return fmt.Errorf("prompt argument is required")
+	}
+
+	cfg, err := loadSpawnConfig(ctx, *configPath)
+	if err != nil {
+		return err
+	}
 
 	// Load existing session ports to prevent collisions with existing agents
-	stateManager := state.NewStateManager()
+	stateManager := stateStore(ctx)
 	existingPorts, err := getExistingSessionPorts(stateManager)
 	if err != nil {
 		log.Warn("Failed to load existing session ports, proceeding without collision check", "error", err)
@@ -194,12 +416,239 @@ func executePrompt(ctx context.Context, args []string) error {
 	// Track assigned ports to prevent collisions between iterations and with existing sessions
 	assignedPorts := existingPorts
 
-	// Parse agents
-	agentConfigs, err := parseAgents(*agentsFlag)
+	// Parse agents, preferring defaultAgents from uzi.yaml unless --agents is given
+	agents := *agentsFlag
+	if configured, ok := cfg.SpawnAgents(); ok && !flagSet(fs, "agents") {
+		agents = configured
+	}
+	resolved, err := resolveTasks(taskFlags.list, args, agents)
+	if err != nil {
+		return err
+	}
+	agentTasks := make([]agentTask, 0, len(resolved))
+	for _, task := range resolved {
+		agentConfigs, err := parseAgents(task.agents)
+		if err != nil {
+			return fmt.Errorf("error parsing agents: %s", err)
+		}
+		log.Debug("Running prompt command", "agents", task.agents, "prompt", task.prompt, "base", *baseFlag)
+		agentTasks = append(agentTasks, agentTask{configs: agentConfigs, prompt: task.prompt})
+	}
+
+	if err := checkAgentCount(cfg, stateManager, agentTasks); err != nil {
+		return err
+	}
+
+	if *maxRuntime < 0 {
+		return fmt.Errorf("--max-runtime must not be negative")
+	}
+	if *runName != "" {
+		if err := state.ValidateRunName(*runName); err != nil {
+			return fmt.Errorf("--run-name: %w", err)
+		}
+	}
+	if err := config.CheckModelArgs(*modelArgs); err != nil {
+		return fmt.Errorf("--model-args: %w", err)
+	}
+
+	target, err := hosts.FromConfig(cfg, *hostFlag)
+	if err != nil {
+		return err
+	}
+	if !target.IsLocal() && *noWorktree {
+		return fmt.Errorf("--host cannot be combined with --no-worktree: shared agents run in the local checkout")
+	}
+	devCmd := cfg.ResolveDevCommand(strings.TrimSpace(*devCmdFlag))
+	if devCmd != "" && (*noWorktree || !target.IsLocal()) {
+		return fmt.Errorf("--dev-command needs local worktree agents: shared and remote agents run without a dev server")
+	}
+
+	// Shared agents run on the checkout as it is, and --base and
+	// --base-commit don't use HEAD
+	if !*noWorktree && *baseFlag == "" && *baseCommit == "" {
+		if err := checkCheckout(cfg, target, repoDir(target), *allowDirty); err != nil {
+			return err
+		}
+	}
+
+	if *baseCommit != "" && (*baseFlag != "" || *noWorktree) {
+		return fmt.Errorf("--base-commit cannot be combined with --base or --no-worktree")
+	}
+	if *baseFlag != "" {
+		if *noWorktree {
+			return fmt.Errorf("--base cannot be combined with --no-worktree: shared agents run on the main checkout as it is")
+		}
+		if err := verifyBase(ctx, target, *baseFlag); err != nil {
+			return err
+		}
+	}
+	pinnedCommit := ""
+	if *baseCommit != "" {
+		if pinnedCommit, err = resolveBaseCommit(ctx, target, *baseCommit); err != nil {
+			return err
+		}
+	}
+
+	queue, err := spawnqueue.NewStore()
+	if err != nil {
+		return err
+	}
+	slots, err := newSpawnSlots(cfg, stateManager, queue)
+	if err != nil {
+		return err
+	}
+	// Agents queued earlier get the free slots before the new ones
+	slots.drain(ctx, cfg, &assignedPorts)
+	spawnAgents(ctx, cfg, agentTasks, spawnRequest{
+		base:       *baseFlag,
+		baseCommit: pinnedCommit,
+		shared:     *noWorktree,
+		maxRuntime: *maxRuntime,
+		modelArgs:  strings.TrimSpace(*modelArgs),
+		devCommand: devCmd,
+		target:     target,
+		channels:   channels,
+		run:        *runName,
+		keepFailed: *keepFailed,
+	}, assignedPorts, slots)
+	if slots.queued > 0 {
+		fmt.Printf("maxConcurrentAgents is %d: queued %d agent(s); they start as running agents are killed or finish (see uzi queue)\n", cfg.AgentLimit(), slots.queued)
+	}
+	return nil
+}
+
+// checkAgentCount applies policy.maxAgents to spawning the agents of tasks
+// next to the repository's active ones
+func checkAgentCount(cfg *config.Config, sm *state.StateManager, tasks []agentTask) error {
+	if cfg.MaxAgents() == 0 || sm == nil {
+		return nil
+	}
+	adding := 0
+	for _, task := range tasks {
+		for _, agentConfig := range task.configs {
+			adding += agentConfig.Count
+		}
+	}
+	active, err := sm.GetActiveSessionsForRepo()
+	if err != nil {
+		return fmt.Errorf("failed to count active agents: %w", err)
+	}
+	return cfg.CheckAgentCount(len(active), adding)
+}
+
+// flagSet reports whether the named flag was passed on the command line
+func flagSet(fs *flag.FlagSet, name string) bool {
+	set := false
+	fs.Visit(func(f *flag.Flag) {
+		if f.Name == name {
+			set = true
+		}
+	})
+	return set
+}
+
+// SpawnOptions describes agents started on behalf of another command, such as uzi import
+type SpawnOptions struct {
+	ConfigPath string   // uzi.yaml with the dev command and port range
+	Agents     string   // agents and counts in the --agents format, e.g. "claude:1"
+	Prompt     string   // initial prompt for every agent
+	Tags       []string // tags saved with every spawned session
+}
+
+// Spawn starts the agents described by opts from HEAD and returns the names of
+// the agents that were spawned successfully
+func Spawn(ctx context.Context, opts SpawnOptions) ([]string, error) {
+	cfg, err := loadSpawnConfig(ctx, opts.ConfigPath)
+	if err != nil {
+		return nil, err
+	}
+
+	agentConfigs, err := parseAgents(opts.Agents)
+	if err != nil {
+		return nil, fmt.Errorf("error parsing agents: %s", err)
+	}
+
+	tasks := []agentTask{{configs: agentConfigs, prompt: opts.Prompt}}
+	stateManager := stateStore(ctx)
+	if err := checkAgentCount(cfg, stateManager, tasks); err != nil {
+		return nil, err
+	}
+
+	existingPorts, err := getExistingSessionPorts(stateManager)
+	if err != nil {
+		log.Warn("Failed to load existing session ports, proceeding without collision check", "error", err)
+		existingPorts = []int{}
+	}
+
+	return spawnAgents(ctx, cfg, tasks, spawnRequest{tags: opts.Tags}, existingPorts, nil), nil
+}
+
+// RecreateOptions describes an agent recreated from a session exported on
+// another machine, such as by uzi import
+type RecreateOptions struct {
+	ConfigPath string        // uzi.yaml with the dev command and port range
+	AgentName  string        // name for the session
+	Model      string        // agent command, e.g. "claude"
+	Prompt     string        // initial prompt
+	Branch     string        // existing local branch the agent continues; empty runs in the main checkout
+	MaxRuntime time.Duration // runtime budget; zero means unlimited
+	Tags       []string      // tags saved with the session
+}
+
+// Recreate starts one agent on an existing branch, or in the main checkout
+// when opts.Branch is empty
+func Recreate(ctx context.Context, opts RecreateOptions) error {
+	cfg, err := loadSpawnConfig(ctx, opts.ConfigPath)
+	if err != nil {
+		return err
+	}
+
+	existingPorts, err := getExistingSessionPorts(stateStore(ctx))
 	if err != nil {
-		return fmt.Errorf("error parsing agents: %s", err)
+		log.Warn("Failed to load existing session ports, proceeding without collision check", "error", err)
+		existingPorts = []int{}
 	}
 
+	command := getCommandForAgent(opts.Model)
+	_, err = spawnAgent(ctx, cfg, spawnRequest{
+		agentName:  opts.AgentName,
+		command:    command,
+		modelArgs:  cfg.AgentModelArgs(opts.Model, command),
+		prompt:     opts.Prompt,
+		base:       opts.Branch,
+		adopt:      opts.Branch != "",
+		shared:     opts.Branch == "",
+		maxRuntime: opts.MaxRuntime,
+		tags:       opts.Tags,
+	}, existingPorts)
+	return err
+}
+
+// agentTask is a group of parsed agents spawned with the same prompt
+type agentTask struct {
+	configs map[string]AgentConfig
+	prompt  string
+}
+
+// spawnAgents starts the agents of every task and returns the names of the
+// agents that were spawned successfully. Each agent is spawned from a copy of
+// tmpl with its own name, command, model arguments, iteration, and its task's
+// prompt filled in. Model arguments set on tmpl apply to every agent;
+// otherwise each agent gets its own from modelArgs in uzi.yaml. Agents that
+// don't get one of slots are queued instead; nil slots spawns them all.
+func spawnAgents(ctx context.Context, cfg *config.Config, tasks []agentTask, tmpl spawnRequest, assignedPorts []int, slots *spawnSlots) []string {
+	var spawned []string
+	for _, task := range tasks {
+		tmpl.prompt = task.prompt
+		spawned = append(spawned, spawnTaskAgents(ctx, cfg, task.configs, tmpl, &assignedPorts, slots)...)
+	}
+	return spawned
+}
+
+// spawnTaskAgents starts the agents of one task, recording the ports they
+// are given in assignedPorts
+func spawnTaskAgents(ctx context.Context, cfg *config.Config, agentConfigs map[string]AgentConfig, tmpl spawnRequest, assignedPorts *[]int, slots *spawnSlots) []string {
+	var spawned []string
 	for agent, config := range agentConfigs {
 		for i := 0; i < config.Count; i++ {
 			// Always get a random agent name for the session/branch/worktree names
@@ -212,185 +661,470 @@ func executePrompt(ctx context.Context, args []string) error {
 				commandToUse = randomAgentName
 			}
 
-			fmt.Printf("%s: %s: %s\n", randomAgentName, commandToUse, promptText)
-
-			// Check if git worktree exists
-			// Get the current git hash
-			gitHashCmd := exec.CommandContext(ctx, "git", "rev-parse", "--short", "HEAD")
-			gitHashCmd.Dir = filepath.Dir(os.Args[0])
-			gitHashOutput, err := gitHashCmd.Output()
-			if err != nil {
-				log.Error("Error getting git hash", "error", err)
+			req := tmpl
+			req.agentName = randomAgentName
+			req.command = commandToUse
+			if req.modelArgs == "" {
+				req.modelArgs = cfg.AgentModelArgs(agent, commandToUse)
+			}
+			req.iteration = i
+			if !slots.take() {
+				if err := slots.enqueue(agent, req); err != nil {
+					log.Error("Error queueing agent", "agent", agent, "error", err)
+				}
 				continue
 			}
-			gitHash := strings.TrimSpace(string(gitHashOutput))
-
-			// Get the git repository name from remote URL
-			gitRemoteCmd := exec.CommandContext(ctx, "git", "remote", "get-url", "origin")
-			gitRemoteCmd.Dir = filepath.Dir(os.Args[0])
-			gitRemoteOutput, err := gitRemoteCmd.Output()
+			port, err := spawnAgent(ctx, cfg, req, *assignedPorts)
+			if port > 0 {
+				*assignedPorts = append(*assignedPorts, port)
+			}
 			if err != nil {
-				log.Error("Error getting git remote", "error", err)
 				continue
 			}
-			remoteURL := strings.TrimSpace(string(gitRemoteOutput))
-			// Extract repository name from URL (handle both https and ssh formats)
-			repoName := filepath.Base(remoteURL)
-			projectDir := strings.TrimSuffix(repoName, ".git")
+			spawned = append(spawned, randomAgentName)
+		}
+	}
 
-			// Create unique identifier using timestamp and iteration
-			timestamp := time.Now().Unix()
-			uniqueId := fmt.Sprintf("%d-%d", timestamp, i)
+	return spawned
+}
 
-			// Create unique branch and worktree names using the random agent name
-			branchName := fmt.Sprintf("%s-%s-%s-%s", randomAgentName, projectDir, gitHash, uniqueId)
-			worktreeName := fmt.Sprintf("%s-%s-%s-%s", randomAgentName, projectDir, gitHash, uniqueId)
+// spawnRequest describes a single agent session to create
+type spawnRequest struct {
+	agentName  string        // name used for the session, branch, and worktree
+	command    string        // agent CLI command to run
+	modelArgs  string        // extra agent CLI arguments, such as the model to use
+	devCommand string        // dev server command with $PORT; empty uses devCommand from uzi.yaml
+	prompt     string        // initial prompt; empty starts the agent without one
+	base       string        // branch or commit to start from; empty means HEAD
+	baseCommit string        // commit id the worktree is pinned to, recorded in state; overrides base
+	adopt      bool          // check out base directly instead of creating a new branch
+	shared     bool          // run in the main checkout without a worktree or branch
+	maxRuntime time.Duration // runtime budget enforced by `uzi auto`; zero means unlimited
+	tags       []string      // tags saved with the session, such as the issue it was imported from
+	channels   []string      // broadcast channels the session subscribes to, besides those its tags map to
+	run        string        // run the session is grouped under; empty for none
+	target     hosts.Target  // machine the session runs on; the zero value is the local machine
+	windowName string        // name of the agent's tmux window, from the window naming template
+	keepFailed bool          // leave the artifacts of a failed spawn in place instead of rolling them back
+	iteration  int
+}
 
-			// Prefix the tmux session name with the git hash and use random agent name
-			sessionName := fmt.Sprintf("agent-%s-%s-%s", projectDir, gitHash, randomAgentName)
+// newRepo returns the version control of the repository agents are spawned
+// from on target
+var newRepo = func(target hosts.Target) vcs.VCS {
+	return vcs.Git{Command: target.Command}
+}
 
-			// Get home directory for worktree storage
-			homeDir, err := os.UserHomeDir()
-			if err != nil {
-				log.Error("Error getting home directory", "error", err)
-				continue
-			}
+// worktreeOptions chooses what the agent worktree checks out: a new branch,
+// or the adopted branch as it is
+func worktreeOptions(req spawnRequest, branchName string) vcs.WorktreeOptions {
+	if req.adopt {
+		return vcs.WorktreeOptions{Base: req.base}
+	}
+	if req.baseCommit != "" {
+		return vcs.WorktreeOptions{Branch: branchName, Base: req.baseCommit}
+	}
+	return vcs.WorktreeOptions{Branch: branchName, Base: req.base}
+}
 
-			worktreesDir := filepath.Join(homeDir, ".local", "share", "uzi", "worktrees")
-			if err := os.MkdirAll(worktreesDir, 0755); err != nil {
-				log.Error("Error creating worktrees directory", "error", err)
-				continue
-			}
+// agentSendKeysCommand builds the tmux command that starts the agent in its
+// pane: the command, its model arguments, then the prompt in the form the
+// agent CLI expects
+func agentSendKeysCommand(sessionName, command, modelArgs, prompt string) string {
+	agentTarget := hosts.Quote(tmuxops.AgentTarget(sessionName))
+	return fmt.Sprintf("tmux send-keys -t %s '%s' C-m", agentTarget, agents.Invocation(command, modelArgs, prompt))
+}
 
-			worktreePath := filepath.Join(worktreesDir, worktreeName)
-			var selectedPort int
-			// Create git worktree
-			cmd := fmt.Sprintf("git worktree add -b %s %s", branchName, worktreePath)
-			cmdExec := exec.CommandContext(ctx, "sh", "-c", cmd)
-			cmdExec.Dir = filepath.Dir(os.Args[0])
-			if err := cmdExec.Run(); err != nil {
-				log.Error("Error creating git worktree", "command", cmd, "error", err)
-				continue
-			}
+// newAgentSession creates the detached tmux session for an agent running the
+// agent CLI. The session is marked as a uzi session, and its first window is
+// named windowName and marked as the agent window, which is how uzi finds them
+// whatever they are named.
+func newAgentSession(ctx context.Context, target hosts.Target, sessionName, windowName, agent, dir string) error {
+	// The environment, with the API key from the keyring, goes to tmux on
+	// stdin so it is never logged or visible in the process list
+	env := append(agents.EnvFor(agent), agentKeyEnv(ctx, target, agent)...)
+	cmdExec := target.Command(ctx, "", "tmux", tmuxops.SourceStdinArgs()...)
+	cmdExec.Stdin = strings.NewReader(tmuxops.NewSessionScript(sessionName, dir, env))
+	if output, err := cmdExec.CombinedOutput(); err != nil {
+		log.Error("Error creating tmux session", "session", sessionName, "dir", dir, "host", target, "error", err, "output", strings.TrimSpace(string(output)))
+		return err
+	}
 
-			// Create tmux session
-			cmd = fmt.Sprintf("tmux new-session -d -s %s -c %s", sessionName, worktreePath)
-			cmdExec = exec.CommandContext(ctx, "sh", "-c", cmd)
-			if err := cmdExec.Run(); err != nil {
-				log.Error("Error creating tmux session", "command", cmd, "error", err)
-				continue
-			}
+	renameExec := target.Command(ctx, "", "tmux", "rename-window", "-t", tmuxops.AgentTarget(sessionName), windowName)
+	if err := renameExec.Run(); err != nil {
+		log.Error("Error renaming tmux window", "window", windowName, "error", err)
+		return err
+	}
+	markExec := target.Command(ctx, "", "tmux", tmuxops.MarkAgentWindowArgs(sessionName)...)
+	if err := markExec.Run(); err != nil {
+		log.Error("Error marking the agent window", "session", sessionName, "error", err)
+		return err
+	}
+	markSessionExec := target.Command(ctx, "", "tmux", tmuxops.MarkSessionArgs(sessionName, agent)...)
+	if err := markSessionExec.Run(); err != nil {
+		log.Error("Error marking the tmux session", "session", sessionName, "error", err)
+		return err
+	}
+	return nil
+}
 
-			// Rename the first window to "agent"
-			renameCmd := fmt.Sprintf("tmux rename-window -t %s:0 agent", sessionName)
-			renameExec := exec.CommandContext(ctx, "sh", "-c", renameCmd)
-			if err := renameExec.Run(); err != nil {
-				log.Error("Error renaming tmux window", "command", renameCmd, "error", err)
-				continue
-			}
+// apiKeys is where `uzi auth set` stores the API keys of agent types
+var apiKeys = keyring.System()
 
-			// Create uzi-dev pane and run dev command if configured
-			if cfg.DevCommand == nil || *cfg.DevCommand == "" || cfg.PortRange == nil || *cfg.PortRange == "" {
-				// Hit enter in the agent pane
-				hitEnterCmd := fmt.Sprintf("tmux send-keys -t %s:agent C-m", sessionName)
-				hitEnterExec := exec.CommandContext(ctx, "sh", "-c", hitEnterCmd)
-				if err := hitEnterExec.Run(); err != nil {
-					log.Error("Error hitting enter in tmux", "command", hitEnterCmd, "error", err)
-				}
+// agentKeyEnv returns the environment entry exporting the stored API key of
+// an agent type into its session. Keys only go to local sessions; remote
+// hosts keep their own credentials.
+func agentKeyEnv(ctx context.Context, target hosts.Target, agent string) []string {
+	if !target.IsLocal() {
+		return nil
+	}
+	// Without a config file the default variables are used
+	cfg, _ := cmdctx.From(ctx).Config()
+	return keyring.AgentEnv(apiKeys, cfg, agent)
+}
 
-				// Always run send-keys command to the agent pane
-				var tmuxCmd string
-				if commandToUse == "gemini" {
-					tmuxCmd = fmt.Sprintf("tmux send-keys -t %s:agent '%s -p \"%%s\"' C-m", sessionName, commandToUse)
-				} else {
-					tmuxCmd = fmt.Sprintf("tmux send-keys -t %s:agent '%s \"%%s\"' C-m", sessionName, commandToUse)
-				}
-				tmuxCmdExec := exec.CommandContext(ctx, "sh", "-c", fmt.Sprintf(tmuxCmd, promptText))
-				tmuxCmdExec.Dir = worktreePath
-				if err := tmuxCmdExec.Run(); err != nil {
-					log.Error("Error sending keys to tmux", "command", tmuxCmd, "error", err)
-					continue
-				}
+// startAgentCommand launches the agent CLI with its prompt in the agent pane
+func startAgentCommand(ctx context.Context, sessionName, dir string, req spawnRequest) error {
+	// Hit enter in the agent pane
+	hitEnterCmd := fmt.Sprintf("tmux send-keys -t %s C-m", hosts.Quote(tmuxops.AgentTarget(sessionName)))
+	hitEnterExec := req.target.Shell(ctx, "", hitEnterCmd)
+	if err := hitEnterExec.Run(); err != nil {
+		log.Error("Error hitting enter in tmux", "command", hitEnterCmd, "error", err)
+	}
 
-				// Save state before continuing (no port since dev server not started)
-				stateManager := state.NewStateManager()
-				if stateManager != nil {
-					if err := stateManager.SaveState(promptText, branchName, sessionName, worktreePath, commandToUse); err != nil {
-						log.Error("Error saving state", "error", err)
-					}
-				}
-				continue
-			}
+	// Always run send-keys command to the agent pane
+	tmuxCmd := agentSendKeysCommand(sessionName, req.command, req.modelArgs, req.prompt)
+	tmuxCmdExec := req.target.Shell(ctx, dir, tmuxCmd)
+	if err := tmuxCmdExec.Run(); err != nil {
+		log.Error("Error sending keys to tmux", "command", tmuxCmd, "error", err)
+		return err
+	}
+	return nil
+}
 
-			ports := strings.Split(*cfg.PortRange, "-")
-			if len(ports) != 2 {
-				log.Warn("Invalid port range format in config", "portRange", *cfg.PortRange)
-				continue
-			}
+// spawnSharedAgent starts an agent in the main checkout. No worktree, branch, or
+// dev server is created, and the session is saved as shared so it is never
+// checkpointed or cleaned up like a worktree.
+func spawnSharedAgent(ctx context.Context, req spawnRequest, sessionName string, rb *rollback) error {
+	checkoutPath, err := mainCheckout(ctx)
+	if err != nil {
+		log.Error("Error finding main checkout", "error", err)
+		return err
+	}
 
-			startPort, _ := strconv.Atoi(ports[0])
-			endPort, _ := strconv.Atoi(ports[1])
-			if startPort <= 0 || endPort <= 0 || endPort < startPort {
-				log.Warn("Invalid port range in config", "portRange", *cfg.PortRange)
-				continue
-			}
+	rb.add("tmux session "+sessionName, killSessionUndo(ctx, req.target, sessionName))
+	if err := newAgentSession(ctx, req.target, sessionName, req.windowName, req.command, checkoutPath); err != nil {
+		return err
+	}
+	if err := startAgentCommand(ctx, sessionName, checkoutPath, req); err != nil {
+		return err
+	}
 
-			selectedPort, err = findAvailablePort(startPort, endPort, assignedPorts)
-			if err != nil {
-				log.Error("Error finding available port", "error", err)
-				continue
-			}
+	return saveSpawnState(ctx, req, "", sessionName, "", 0, state.ModeShared, rb)
+}
+
+// saveSpawnState records a spawned agent in state, including its runtime
+// budget. An agent whose state can't be saved completely fails to spawn,
+// since uzi would lose track of it or of its host or budget.
+func saveSpawnState(ctx context.Context, req spawnRequest, branchName, sessionName, worktreePath string, port int, mode string, rb *rollback) error {
+	stateManager := stateStore(ctx)
+	if stateManager == nil {
+		return nil
+	}
+	if err := stateManager.SaveStateWithMode(req.prompt, branchName, sessionName, worktreePath, req.command, port, req.base, mode); err != nil {
+		log.Error("Error saving state", "error", err)
+		return err
+	}
+	rb.add("state entry", func() error { return stateManager.RemoveState(sessionName) })
+	if !req.target.IsLocal() {
+		if err := stateManager.SetHost(sessionName, req.target.Name, req.target.SSH); err != nil {
+			log.Error("Error saving host", "error", err)
+			return err
+		}
+	}
+	if req.baseCommit != "" {
+		if err := stateManager.SetBaseCommit(sessionName, req.baseCommit); err != nil {
+			log.Error("Error saving base commit", "error", err)
+			return err
+		}
+	}
+	if req.adopt {
+		if err := stateManager.SetAdopted(sessionName, true); err != nil {
+			log.Error("Error saving adopted branch", "error", err)
+			return err
+		}
+	}
+	if req.devCommand != "" {
+		if err := stateManager.SetDevCommand(sessionName, req.devCommand); err != nil {
+			log.Error("Error saving dev command", "error", err)
+			return err
+		}
+	}
+	if req.maxRuntime > 0 {
+		if err := stateManager.SetMaxRuntime(sessionName, req.maxRuntime); err != nil {
+			log.Error("Error saving runtime budget", "error", err)
+			return err
+		}
+	}
+	if len(req.tags) > 0 {
+		if err := stateManager.SetTags(sessionName, req.tags); err != nil {
+			log.Error("Error saving tags", "error", err)
+			return err
+		}
+	}
+	if len(req.channels) > 0 {
+		if err := stateManager.SetChannels(sessionName, req.channels); err != nil {
+			log.Error("Error saving channels", "error", err)
+			return err
+		}
+	}
+	if req.run != "" {
+		if err := stateManager.SetRun(sessionName, req.run); err != nil {
+			log.Error("Error saving run", "error", err)
+			return err
+		}
+	}
+	return nil
+}
 
-			devCmdTemplate := *cfg.DevCommand
-			devCmd := strings.Replace(devCmdTemplate, "$PORT", strconv.Itoa(selectedPort), 1)
+// killSessionUndo returns a rollback step that kills the tmux session if it
+// was created, which also stops the dev server holding the agent's port
+func killSessionUndo(ctx context.Context, target hosts.Target, sessionName string) func() error {
+	return func() error {
+		if target.Shell(ctx, "", fmt.Sprintf("tmux has-session -t %s", sessionName)).Run() != nil {
+			return nil
+		}
+		return target.Shell(ctx, "", fmt.Sprintf("tmux kill-session -t %s", sessionName)).Run()
+	}
+}
 
-			// Create new window named uzi-dev
-			newWindowCmd := fmt.Sprintf("tmux new-window -t %s -n uzi-dev -c %s", sessionName, worktreePath)
-			newWindowExec := exec.CommandContext(ctx, "sh", "-c", newWindowCmd)
-			if err := newWindowExec.Run(); err != nil {
-				log.Error("Error creating new tmux window for dev server", "command", newWindowCmd, "error", err)
-				continue
-			}
+// mainCheckout returns the top level of the local checkout uzi runs in
+func mainCheckout(ctx context.Context) (string, error) {
+	topLevelCmd := exec.CommandContext(ctx, "git", "rev-parse", "--show-toplevel")
+	topLevelCmd.Dir = filepath.Dir(os.Args[0])
+	output, err := topLevelCmd.Output()
+	if err != nil {
+		return "", err
+	}
+	return strings.TrimSpace(string(output)), nil
+}
 
-			// Send dev command to the new window
-			sendDevCmd := fmt.Sprintf("tmux send-keys -t %s:uzi-dev '%s' C-m", sessionName, devCmd)
-			sendDevCmdExec := exec.CommandContext(ctx, "sh", "-c", sendDevCmd)
-			if err := sendDevCmdExec.Run(); err != nil {
-				log.Error("Error sending dev command to tmux", "command", sendDevCmd, "error", err)
-			}
+// worktreesDir creates and returns the directory agent worktrees are stored in
+// on the target: worktreeDir from uzi.yaml locally, and
+// ~/.local/share/uzi/worktrees on remote hosts
+func worktreesDir(ctx context.Context, cfg *config.Config, target hosts.Target) (string, error) {
+	if target.IsLocal() {
+		checkout, err := mainCheckout(ctx)
+		if err != nil {
+			return "", err
+		}
+		dir, err := cfg.ResolveWorktreeDir(checkout)
+		if err != nil {
+			return "", err
+		}
+		return dir, os.MkdirAll(dir, 0755)
+	}
 
-			// Hit enter in the agent pane
-			hitEnterCmd := fmt.Sprintf("tmux send-keys -t %s:agent C-m", sessionName)
-			hitEnterExec := exec.CommandContext(ctx, "sh", "-c", hitEnterCmd)
-			if err := hitEnterExec.Run(); err != nil {
-				log.Error("Error hitting enter in tmux", "command", hitEnterCmd, "error", err)
-			}
+	// Remote commands start in the remote home directory
+	output, err := target.Shell(ctx, "", "mkdir -p .local/share/uzi/worktrees && cd .local/share/uzi/worktrees && pwd").Output()
+	if err != nil {
+		return "", err
+	}
+	return strings.TrimSpace(string(output)), nil
+}
 
-			assignedPorts = append(assignedPorts, selectedPort)
+// spawnAgent creates the worktree, tmux session, and dev server for one agent
+// and saves its state. It returns the dev server port, or 0 if none was started.
+// If a step fails, the steps before it are rolled back unless req.keepFailed
+// is set.
+func spawnAgent(ctx context.Context, cfg *config.Config, req spawnRequest, assignedPorts []int) (port int, err error) {
+	fmt.Printf("%s: %s: %s\n", req.agentName, req.command, req.prompt)
+	req.channels = cfg.SessionChannels(req.channels, req.tags)
 
-			// Always run send-keys command to the agent pane
-			var tmuxCmd string
-			if commandToUse == "gemini" {
-				tmuxCmd = fmt.Sprintf("tmux send-keys -t %s:agent '%s -p \"%%s\"' C-m", sessionName, commandToUse)
-			} else {
-				tmuxCmd = fmt.Sprintf("tmux send-keys -t %s:agent '%s \"%%s\"' C-m", sessionName, commandToUse)
-			}
-			tmuxCmdExec := exec.CommandContext(ctx, "sh", "-c", fmt.Sprintf(tmuxCmd, promptText))
-			tmuxCmdExec.Dir = worktreePath
-			if err := tmuxCmdExec.Run(); err != nil {
-				log.Error("Error sending keys to tmux", "command", tmuxCmd, "error", err)
-				continue
-			}
+	// Get the git hash of the starting point
+	rev := "HEAD"
+	if req.base != "" {
+		rev = req.base
+	}
+	gitHashCmd := req.target.Command(ctx, repoDir(req.target), "git", "rev-parse", "--short", rev)
+	gitHashOutput, err := gitHashCmd.Output()
+	if err != nil {
+		log.Error("Error getting git hash", "error", err)
+		return 0, err
+	}
+	gitHash := strings.TrimSpace(string(gitHashOutput))
 
-			// Save state after successful prompt execution
-			stateManager := state.NewStateManager()
-			if stateManager != nil {
-				if err := stateManager.SaveStateWithPort(promptText, branchName, sessionName, worktreePath, commandToUse, selectedPort); err != nil {
-					log.Error("Error saving state", "error", err)
-				}
-			}
+	// Get the git repository name from remote URL
+	gitRemoteCmd := exec.CommandContext(ctx, "git", "remote", "get-url", "origin")
+	gitRemoteCmd.Dir = filepath.Dir(os.Args[0])
+	gitRemoteOutput, err := gitRemoteCmd.Output()
+	if err != nil {
+		log.Error("Error getting git remote", "error", err)
+		return 0, err
+	}
+	remoteURL := strings.TrimSpace(string(gitRemoteOutput))
+	// Extract repository name from URL (handle both https and ssh formats)
+	repoName := filepath.Base(remoteURL)
+	projectDir := strings.TrimSuffix(repoName, ".git")
+
+	// Build the branch, worktree and session names from the naming templates;
+	// the timestamp and iteration keep branch names unique by default
+	fields := config.NameFields{Agent: req.agentName, Project: projectDir, Hash: gitHash, Time: time.Now(), Iteration: req.iteration, Model: req.command, Prompt: req.prompt}
+	branchName := config.RenderName(cfg.BranchTemplate(), fields)
+	worktreeName := strings.ReplaceAll(branchName, "/", "-")
+	if req.adopt {
+		// Adopted agents keep working on the existing branch; its commit now
+		// is the base their diffs are measured against
+		branchName = req.base
+		if req.baseCommit, err = resolveBaseCommit(ctx, req.target, req.base); err != nil {
+			return 0, err
 		}
 	}
+	sessionName := config.RenderName(cfg.SessionTemplate(), fields)
+	req.windowName = config.RenderName(cfg.WindowTemplate(), fields)
 
-	return nil
+	if stateManager := stateStore(ctx); stateManager != nil {
+		lock, err := stateManager.LockSession(sessionName, "spawn")
+		if err != nil {
+			log.Error("Error locking session", "session", sessionName, "error", err)
+			return 0, err
+		}
+		defer lock.Release()
+	}
+
+	// Undo whatever was created if a later step fails; this runs before the
+	// lock is released so no other spawn sees the half-created session
+	rb := &rollback{}
+	defer func() {
+		if err == nil {
+			cmdctx.From(ctx).Events(cfg).Dispatch(events.NewEvent(events.EventSpawn, sessionName, req.prompt))
+			return
+		}
+		if len(rb.steps) == 0 {
+			return
+		}
+		if req.keepFailed {
+			log.Warn("Keeping the partially spawned agent for debugging", "agent", req.agentName, "kept", rb.describe())
+			return
+		}
+		if failed := rb.run(); len(failed) > 0 {
+			log.Warn("Rolled back the failed spawn, but some steps remain", "agent", req.agentName, "remaining", strings.Join(failed, ", "))
+		} else {
+			log.Info("Rolled back the failed spawn", "agent", req.agentName)
+		}
+		// The dev server went with the tmux session, so its port is free again
+		port = 0
+	}()
+
+	if req.shared {
+		return 0, spawnSharedAgent(ctx, req, sessionName, rb)
+	}
+
+	worktreesDir, err := worktreesDir(ctx, cfg, req.target)
+	if err != nil {
+		log.Error("Error creating worktrees directory", "host", req.target, "error", err)
+		return 0, err
+	}
+
+	worktreePath := filepath.Join(worktreesDir, worktreeName)
+	// Create git worktree
+	repo := newRepo(req.target)
+	if err := repo.CreateWorktree(ctx, repoDir(req.target), worktreePath, worktreeOptions(req, branchName)); err != nil {
+		log.Error("Error creating git worktree", "path", worktreePath, "host", req.target, "error", err)
+		return 0, err
+	}
+	if !req.adopt {
+		// Adopted branches existed before the spawn and are kept
+		rb.add("branch "+branchName, func() error {
+			return repo.DeleteBranch(ctx, repoDir(req.target), branchName)
+		})
+	}
+	rb.add("worktree "+worktreePath, func() error {
+		return repo.RemoveWorktree(ctx, repoDir(req.target), worktreePath)
+	})
+
+	// Install the agent's dependencies before anything runs in the worktree
+	if setup := cfg.WorktreeSetupCommand(); setup != "" {
+		if output, err := req.target.Shell(ctx, worktreePath, setup).CombinedOutput(); err != nil {
+			log.Error("Error running setup command", "command", setup, "output", strings.TrimSpace(string(output)), "error", err)
+			return 0, fmt.Errorf("setup command failed: %w", err)
+		}
+	}
+
+	// Create tmux session
+	rb.add("tmux session "+sessionName, killSessionUndo(ctx, req.target, sessionName))
+	if err := newAgentSession(ctx, req.target, sessionName, req.windowName, req.command, worktreePath); err != nil {
+		return 0, err
+	}
+
+	// Create uzi-dev pane and run dev command if configured. Ports on remote
+	// hosts can't be checked from here, so remote agents run without one.
+	if !devServerConfigured(cfg, req.devCommand) || !req.target.IsLocal() {
+		if err := startAgentCommand(ctx, sessionName, worktreePath, req); err != nil {
+			return 0, err
+		}
+
+		// Save state before continuing (no port since dev server not started)
+		return 0, saveSpawnState(ctx, req, branchName, sessionName, worktreePath, 0, "", rb)
+	}
+
+	selectedPort, err := startDevServer(ctx, cfg, req.devCommand, sessionName, worktreePath, assignedPorts)
+	if err != nil {
+		return 0, err
+	}
+
+	if err := startAgentCommand(ctx, sessionName, worktreePath, req); err != nil {
+		// The port is still held by the dev server window
+		return selectedPort, err
+	}
+
+	// Save state after successful prompt execution
+	return selectedPort, saveSpawnState(ctx, req, branchName, sessionName, worktreePath, selectedPort, "", rb)
+}
+
+// devServerConfigured reports whether an agent with the given --dev-command
+// gets a dev server: it has a dev command
+func devServerConfigured(cfg *config.Config, devCommand string) bool {
+	return cfg.DevCommandFor(devCommand) != ""
+}
+
+// startDevServer picks a free port from portRange, or the automatic range
+// without one, and runs the agent's dev command, or else devCommand, with it
+// in a uzi-dev window of the session. It returns the port, which the caller
+// records in the session's state as its lease.
+func startDevServer(ctx context.Context, cfg *config.Config, devCommand, sessionName, worktreePath string, assignedPorts []int) (int, error) {
+	startPort, endPort, auto, err := cfg.DevPortRange()
+	if err != nil {
+		log.Warn("Invalid port range in config", "error", err)
+		return 0, err
+	}
+
+	selectedPort, err := FindAvailablePort(startPort, endPort, assignedPorts)
+	if err != nil {
+		log.Error("Error finding available port", "error", err)
+		return 0, err
+	}
+	if auto {
+		log.Debug("Picked dev server port without portRange", "port", selectedPort, "range", config.AutoPortRange)
+	}
+
+	devCmdTemplate := cfg.DevCommandFor(devCommand)
+	devCmd := strings.Replace(devCmdTemplate, "$PORT", strconv.Itoa(selectedPort), 1)
+
+	// Create new window named uzi-dev
+	newWindowCmd := fmt.Sprintf("tmux new-window -t %s -n uzi-dev -c %s", sessionName, worktreePath)
+	newWindowExec := exec.CommandContext(ctx, "sh", "-c", newWindowCmd)
+	if err := newWindowExec.Run(); err != nil {
+		log.Error("Error creating new tmux window for dev server", "command", newWindowCmd, "error", err)
+		return 0, err
+	}
+
+	// Send dev command to the new window
+	sendDevCmd := fmt.Sprintf("tmux send-keys -t %s:uzi-dev '%s' C-m", sessionName, devCmd)
+	sendDevCmdExec := exec.CommandContext(ctx, "sh", "-c", sendDevCmd)
+	if err := sendDevCmdExec.Run(); err != nil {
+		log.Error("Error sending dev command to tmux", "command", sendDevCmd, "error", err)
+	}
+
+	return selectedPort, nil
 }
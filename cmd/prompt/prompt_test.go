@@ -2,10 +2,18 @@ package prompt
 
 import (
 	"context"
+	"errors"
+	"flag"
 	"os"
 	"path/filepath"
+	"reflect"
 	"strings"
 	"testing"
+
+	"github.com/nehpz/claudicus/pkg/config"
+	"github.com/nehpz/claudicus/pkg/hosts"
+	"github.com/nehpz/claudicus/pkg/keyring"
+	"github.com/nehpz/claudicus/pkg/testutil/harness"
 )
 
 func TestParseAgents(t *testing.T) {
@@ -149,20 +157,6 @@ func TestExecutePrompt(t *testing.T) {
 			expectError:   true,
 			errorContains: "devCommand is required in uzi.yaml",
 		},
-		{
-			name: "config missing portRange",
-			args: []string{"test", "prompt"},
-			setupConfig: func() string {
-				tempDir := t.TempDir()
-				configFile := filepath.Join(tempDir, "uzi.yaml")
-				content := "devCommand: npm start --port $PORT\n"
-				os.WriteFile(configFile, []byte(content), 0644)
-				return configFile
-			},
-			setupFlags:    func() {},
-			expectError:   true,
-			errorContains: "portRange is required in uzi.yaml",
-		},
 		{
 			name: "invalid agents flag",
 			args: []string{"test", "prompt"},
@@ -222,6 +216,19 @@ func TestExecutePrompt(t *testing.T) {
 	}
 }
 
+func TestLoadSpawnConfigWithoutPortRange(t *testing.T) {
+	configFile := filepath.Join(t.TempDir(), "uzi.yaml")
+	os.WriteFile(configFile, []byte("devCommand: npm start --port $PORT\n"), 0644)
+
+	cfg, err := loadSpawnConfig(context.Background(), configFile)
+	if err != nil {
+		t.Fatalf("Expected portRange to be optional, got %v", err)
+	}
+	if !devServerConfigured(cfg, "") {
+		t.Error("Expected a dev server without portRange, on a port from the automatic range")
+	}
+}
+
 func TestIsPortAvailable(t *testing.T) {
 	tests := []struct {
 		name string
@@ -277,7 +284,7 @@ func TestFindAvailablePort(t *testing.T) {
 
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			port, err := findAvailablePort(tt.startPort, tt.endPort, tt.assignedPorts)
+			port, err := FindAvailablePort(tt.startPort, tt.endPort, tt.assignedPorts)
 
 			if tt.expectError {
 				if err == nil {
@@ -364,3 +371,163 @@ func TestExecutePromptSuccessCase(t *testing.T) {
 		}
 	})
 }
+
+func TestChannelsFlag(t *testing.T) {
+	var c channelsFlag
+	if err := c.Set("frontend, backend"); err != nil {
+		t.Fatal(err)
+	}
+	if err := c.Set("docs"); err != nil {
+		t.Fatal(err)
+	}
+	if c.String() != "frontend,backend,docs" {
+		t.Errorf("channelsFlag = %q", c.String())
+	}
+	if err := c.Set("#bad"); err == nil {
+		t.Error("Expected an invalid channel name to be rejected")
+	}
+}
+
+func TestPromptTaskFlags(t *testing.T) {
+	agents := "claude:1"
+	pt := &promptTasks{agents: &agents}
+	fs := flag.NewFlagSet("test", flag.ContinueOnError)
+	fs.Var(agentsValue{pt}, "agents", "")
+	fs.Var(promptValue{pt}, "prompt", "")
+
+	err := fs.Parse([]string{"--prompt", "Triage the issues", "--agents", "claude:1", "--prompt", "Fix the login bug", "--agents", "codex:2", "--prompt", "Write tests"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []spawnTask{
+		{prompt: "Triage the issues"},
+		{agents: "claude:1", prompt: "Fix the login bug"},
+		{agents: "codex:2", prompt: "Write tests"},
+	}
+	if !reflect.DeepEqual(pt.list, want) {
+		t.Errorf("tasks = %+v, want %+v", pt.list, want)
+	}
+	if agents != "codex:2" {
+		t.Errorf("Expected the latest --agents kept, got %q", agents)
+	}
+
+	if err := (promptValue{pt}).Set("Another prompt"); err == nil {
+		t.Error("Expected a second --prompt for the same --agents to be rejected")
+	}
+	if err := (promptValue{pt}).Set("  "); err == nil {
+		t.Error("Expected an empty --prompt to be rejected")
+	}
+}
+
+func TestResolveTasks(t *testing.T) {
+	single, err := resolveTasks([]spawnTask{{agents: "codex:1"}}, []string{"Fix", "the", "bug"}, "codex:1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := []spawnTask{{agents: "codex:1", prompt: "Fix the bug"}}; !reflect.DeepEqual(single, want) {
+		t.Errorf("Expected the prompt text for the agents, got %+v", single)
+	}
+
+	paired, err := resolveTasks([]spawnTask{{prompt: "A"}, {agents: "codex:1", prompt: "B"}}, nil, "claude:2")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := []spawnTask{{agents: "claude:2", prompt: "A"}, {agents: "codex:1", prompt: "B"}}; !reflect.DeepEqual(paired, want) {
+		t.Errorf("Expected each group with its prompt, got %+v", paired)
+	}
+
+	for name, tc := range map[string]struct {
+		list []spawnTask
+		args []string
+	}{
+		"no prompt":           {nil, nil},
+		"prompt text too":     {[]spawnTask{{agents: "claude:1", prompt: "A"}}, []string{"B"}},
+		"agents left without": {[]spawnTask{{agents: "claude:1", prompt: "A"}, {agents: "codex:1"}}, nil},
+	} {
+		if _, err := resolveTasks(tc.list, tc.args, "claude:1"); err == nil {
+			t.Errorf("%s: expected an error", name)
+		}
+	}
+}
+
+// checkoutExecutor reports a checkout with one uncommitted change and no upstream
+type checkoutExecutor struct {
+	calls int
+}
+
+func (e *checkoutExecutor) ExecuteCommand(name string, args ...string) ([]byte, error) {
+	e.calls++
+	if args[2] == "status" {
+		return []byte(" M main.go\n"), nil
+	}
+	return nil, errors.New("no upstream")
+}
+
+func (e *checkoutExecutor) RunCommand(name string, args ...string) error {
+	return nil
+}
+
+func TestCheckCheckout(t *testing.T) {
+	policy := func(p string) *config.Config { return &config.Config{DirtyCheckoutPolicy: &p} }
+
+	executor := &checkoutExecutor{}
+	if err := checkCheckout(nil, executor, "/repo", false); err != nil {
+		t.Errorf("Expected a warning only by default, got %v", err)
+	}
+	err := checkCheckout(policy("block"), executor, "/repo", false)
+	if err == nil || !strings.Contains(err.Error(), "1 uncommitted change") || !strings.Contains(err.Error(), "--allow-dirty") {
+		t.Errorf("Expected block to refuse the dirty checkout, got %v", err)
+	}
+
+	executor.calls = 0
+	if err := checkCheckout(policy("block"), executor, "/repo", true); err != nil || executor.calls != 0 {
+		t.Errorf("Expected --allow-dirty to skip the check, got %v after %d commands", err, executor.calls)
+	}
+	if err := checkCheckout(policy("allow"), executor, "/repo", false); err != nil || executor.calls != 0 {
+		t.Errorf("Expected allow to skip the check, got %v after %d commands", err, executor.calls)
+	}
+}
+
+func TestCheckAgentCount(t *testing.T) {
+	h := harness.New(t)
+	h.Spawn("sarah", "claude", "fix the flaky tests")
+	h.Spawn("emily", "codex", "document the config")
+	tasks := []agentTask{
+		{configs: map[string]AgentConfig{"claude": {Command: "claude", Count: 1}}, prompt: "a"},
+		{configs: map[string]AgentConfig{"codex": {Command: "codex", Count: 1}}, prompt: "b"},
+	}
+
+	if err := checkAgentCount(&config.Config{}, h.State, tasks); err != nil {
+		t.Errorf("Expected no limit without policy.maxAgents, got %v", err)
+	}
+	cfg := &config.Config{Policy: &config.PolicyConfig{MaxAgents: 4}}
+	if err := checkAgentCount(cfg, h.State, tasks); err != nil {
+		t.Errorf("Expected 4 of 4 agents to be allowed, got %v", err)
+	}
+	cfg.Policy.MaxAgents = 3
+	var violation *config.PolicyViolation
+	if err := checkAgentCount(cfg, h.State, tasks); !errors.As(err, &violation) || violation.Rule != "maxAgents" {
+		t.Errorf("Expected a maxAgents violation, got %v", err)
+	}
+}
+
+func TestAgentKeyEnv(t *testing.T) {
+	saved := apiKeys
+	defer func() { apiKeys = saved }()
+	apiKeys = keyring.ForOS("linux", func(stdin, name string, args ...string) (string, error) {
+		if args[len(args)-1] == "claude" {
+			return "sk-ant-123\n", nil
+		}
+		return "", nil
+	})
+
+	if got, want := agentKeyEnv(context.Background(), hosts.Local(), "claude"), []string{"ANTHROPIC_API_KEY=sk-ant-123"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("agentKeyEnv(claude) = %v, want %v", got, want)
+	}
+	if got := agentKeyEnv(context.Background(), hosts.Local(), "codex"); got != nil {
+		t.Errorf("Expected no key for codex, got %v", got)
+	}
+	if got := agentKeyEnv(context.Background(), hosts.Target{Name: "gpu", SSH: "dev@gpu"}, "claude"); got != nil {
+		t.Errorf("Expected keys to stay off remote hosts, got %v", got)
+	}
+}
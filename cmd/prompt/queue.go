@@ -0,0 +1,187 @@
+package prompt
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/nehpz/claudicus/pkg/agents"
+	"github.com/nehpz/claudicus/pkg/config"
+	"github.com/nehpz/claudicus/pkg/hosts"
+	"github.com/nehpz/claudicus/pkg/spawnqueue"
+	"github.com/nehpz/claudicus/pkg/state"
+
+	"github.com/charmbracelet/log"
+)
+
+// spawnSlots hands out the slots maxConcurrentAgents leaves free and queues
+// the agents that don't fit, or holds them in held without a queue. A nil
+// *spawnSlots spawns every agent.
+type spawnSlots struct {
+	free   int // slots left; negative means unlimited
+	repo   string
+	queue  *spawnqueue.Store
+	queued int
+	held   []spawnRequest
+}
+
+// newSpawnSlots counts the slots left for the current repository; agents
+// that signalled they are done don't hold one
+func newSpawnSlots(cfg *config.Config, sm *state.StateManager, queue *spawnqueue.Store) (*spawnSlots, error) {
+	slots := &spawnSlots{free: -1, repo: sm.GitRepo(), queue: queue}
+	limit := cfg.AgentLimit()
+	if limit == 0 {
+		return slots, nil
+	}
+	running, err := sm.GetRunningSessionsForRepo()
+	if err != nil {
+		return nil, fmt.Errorf("failed to count running agents: %w", err)
+	}
+	slots.free = limit - len(running)
+	if slots.free < 0 {
+		slots.free = 0
+	}
+	return slots, nil
+}
+
+// take claims a slot, reporting false when none is left
+func (s *spawnSlots) take() bool {
+	if s == nil || s.free < 0 {
+		return true
+	}
+	if s.free == 0 {
+		return false
+	}
+	s.free--
+	return true
+}
+
+// enqueue queues an agent that did not get a slot
+func (s *spawnSlots) enqueue(agent string, req spawnRequest) error {
+	if s.queue == nil {
+		s.held = append(s.held, req)
+		fmt.Printf("%s: waiting until an agent slot frees up: %s\n", req.command, req.prompt)
+		return nil
+	}
+	queued, err := s.queue.Push(queueEntry(s.repo, agent, req))
+	if err != nil {
+		return err
+	}
+	s.queued++
+	fmt.Printf("%s: queued as #%d until an agent slot frees up: %s\n", req.command, queued[0].ID, req.prompt)
+	return nil
+}
+
+// drain spawns the repository's queued agents, oldest first, while slots are
+// free, and returns the names of the agents spawned
+func (s *spawnSlots) drain(ctx context.Context, cfg *config.Config, assignedPorts *[]int) []string {
+	if s.free == 0 {
+		return nil
+	}
+	entries, err := s.queue.Pop(s.repo, s.free)
+	if err != nil {
+		log.Error("Error reading the spawn queue", "error", err)
+		return nil
+	}
+
+	var spawned []string
+	for _, entry := range entries {
+		s.take()
+		target, err := hosts.FromConfig(cfg, entry.Host)
+		if err != nil {
+			log.Error("Dropping queued agent", "id", entry.ID, "error", err)
+			continue
+		}
+		req := queuedRequest(entry)
+		req.target = target
+		req.agentName = agents.GetRandomAgent()
+		if entry.Agent == "random" {
+			req.command = req.agentName
+		}
+		port, err := spawnAgent(ctx, cfg, req, *assignedPorts)
+		if port > 0 {
+			*assignedPorts = append(*assignedPorts, port)
+		}
+		if err != nil {
+			// The entry already left the queue; say so instead of losing it quietly
+			log.Error("Dropping queued agent that failed to spawn", "id", entry.ID, "agent", entry.Agent, "prompt", entry.Prompt, "error", err)
+			continue
+		}
+		spawned = append(spawned, req.agentName)
+	}
+	return spawned
+}
+
+// queueEntry records a spawn request in the queue
+func queueEntry(repo, agent string, req spawnRequest) spawnqueue.Entry {
+	return spawnqueue.Entry{
+		Repo:       repo,
+		Agent:      agent,
+		Command:    req.command,
+		ModelArgs:  req.modelArgs,
+		DevCommand: req.devCommand,
+		Prompt:     req.prompt,
+		Base:       req.base,
+		BaseCommit: req.baseCommit,
+		Shared:     req.shared,
+		Host:       req.target.Name,
+		MaxRuntime: req.maxRuntime,
+		Tags:       req.tags,
+		Channels:   req.channels,
+		Run:        req.run,
+		KeepFailed: req.keepFailed,
+		Iteration:  req.iteration,
+	}
+}
+
+// queuedRequest rebuilds the spawn request of a queued entry; its name and
+// target are filled in when it is spawned
+func queuedRequest(entry spawnqueue.Entry) spawnRequest {
+	return spawnRequest{
+		command:    entry.Command,
+		modelArgs:  entry.ModelArgs,
+		devCommand: entry.DevCommand,
+		prompt:     entry.Prompt,
+		base:       entry.Base,
+		baseCommit: entry.BaseCommit,
+		shared:     entry.Shared,
+		maxRuntime: entry.MaxRuntime,
+		tags:       entry.Tags,
+		channels:   entry.Channels,
+		run:        entry.Run,
+		keepFailed: entry.KeepFailed,
+		iteration:  entry.Iteration,
+	}
+}
+
+// DrainQueue spawns the queued agents of the current repository that fit in
+// the slots maxConcurrentAgents leaves free, oldest first, and returns the
+// names of the agents spawned. uzi.yaml is only loaded when agents are queued.
+func DrainQueue(ctx context.Context, configPath string) ([]string, error) {
+	queue, err := spawnqueue.NewStore()
+	if err != nil {
+		return nil, err
+	}
+	sm := stateStore(ctx)
+	if sm == nil {
+		return nil, fmt.Errorf("could not initialize state manager")
+	}
+	pending, err := queue.ListRepo(sm.GitRepo())
+	if err != nil || len(pending) == 0 {
+		return nil, err
+	}
+
+	cfg, err := loadSpawnConfig(ctx, configPath)
+	if err != nil {
+		return nil, err
+	}
+	slots, err := newSpawnSlots(cfg, sm, queue)
+	if err != nil {
+		return nil, err
+	}
+	assignedPorts, err := getExistingSessionPorts(sm)
+	if err != nil {
+		log.Warn("Failed to load existing session ports, proceeding without collision check", "error", err)
+		assignedPorts = []int{}
+	}
+	return slots.drain(ctx, cfg, &assignedPorts), nil
+}
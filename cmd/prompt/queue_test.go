@@ -0,0 +1,123 @@
+package prompt
+
+import (
+	"context"
+	"path/filepath"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/nehpz/claudicus/pkg/config"
+	"github.com/nehpz/claudicus/pkg/hosts"
+	"github.com/nehpz/claudicus/pkg/spawnqueue"
+	"github.com/nehpz/claudicus/pkg/testutil/harness"
+)
+
+func TestNewSpawnSlots(t *testing.T) {
+	h := harness.New(t)
+	queue := spawnqueue.NewStoreAt(filepath.Join(t.TempDir(), "queue.json"))
+	sarah := h.Spawn("sarah", "claude", "fix the flaky tests")
+	h.Spawn("emily", "codex", "document the config")
+
+	slots, err := newSpawnSlots(&config.Config{}, h.State, queue)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if slots.free >= 0 || !slots.take() {
+		t.Errorf("Expected unlimited slots without maxConcurrentAgents, got %d", slots.free)
+	}
+
+	limit := 3
+	cfg := &config.Config{MaxConcurrentAgents: &limit}
+	if slots, _ = newSpawnSlots(cfg, h.State, queue); slots.free != 1 {
+		t.Errorf("Expected 1 free slot with 2 of 3 agents running, got %d", slots.free)
+	}
+
+	// Done agents give their slot back
+	h.State.SetDone(sarah, true)
+	if slots, _ = newSpawnSlots(cfg, h.State, queue); slots.free != 2 {
+		t.Errorf("Expected 2 free slots once sarah is done, got %d", slots.free)
+	}
+	if !slots.take() || !slots.take() || slots.take() {
+		t.Error("Expected exactly 2 slots to be taken")
+	}
+	if slots.repo != harness.DefaultRepoURL {
+		t.Errorf("Expected the slots of %s, got %s", harness.DefaultRepoURL, slots.repo)
+	}
+
+	limit = 1
+	if slots, _ = newSpawnSlots(cfg, h.State, queue); slots.free != 0 {
+		t.Errorf("Expected no free slots over the limit, got %d", slots.free)
+	}
+}
+
+func TestSpawnTaskAgentsQueuesWithoutSlots(t *testing.T) {
+	queue := spawnqueue.NewStoreAt(filepath.Join(t.TempDir(), "queue.json"))
+	slots := &spawnSlots{free: 0, repo: "git@github.com:acme/app.git", queue: queue}
+	tmpl := spawnRequest{prompt: "fix the login bug", base: "develop", maxRuntime: time.Hour, tags: []string{"issue-12"}}
+	assignedPorts := []int{}
+
+	spawned := spawnTaskAgents(context.Background(), &config.Config{}, map[string]AgentConfig{"claude": {Command: "claude", Count: 3}}, tmpl, &assignedPorts, slots)
+	if len(spawned) != 0 {
+		t.Errorf("Expected nothing spawned without a slot, got %v", spawned)
+	}
+	if slots.queued != 3 {
+		t.Errorf("Expected 3 agents queued, got %d", slots.queued)
+	}
+
+	entries, err := queue.ListRepo("git@github.com:acme/app.git")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(entries) != 3 {
+		t.Fatalf("Expected 3 queued entries, got %+v", entries)
+	}
+	for i, entry := range entries {
+		if entry.Agent != "claude" || entry.Command != "claude" || entry.Prompt != "fix the login bug" || entry.Base != "develop" || entry.Iteration != i {
+			t.Errorf("Unexpected entry %d: %+v", i, entry)
+		}
+	}
+}
+
+func TestSpawnTaskAgentsHoldsWithoutQueue(t *testing.T) {
+	slots := &spawnSlots{free: 0, repo: "git@github.com:acme/app.git"}
+	assignedPorts := []int{}
+
+	spawned := spawnTaskAgents(context.Background(), &config.Config{}, map[string]AgentConfig{"claude": {Command: "claude", Count: 2}}, spawnRequest{prompt: "plan"}, &assignedPorts, slots)
+	if len(spawned) != 0 || slots.queued != 0 {
+		t.Errorf("Expected nothing spawned or queued, got %v and %d queued", spawned, slots.queued)
+	}
+	if len(slots.held) != 2 || slots.held[0].agentName == "" || slots.held[1].iteration != 1 {
+		t.Errorf("Expected 2 named agents held back, got %+v", slots.held)
+	}
+}
+
+func TestQueuedRequest(t *testing.T) {
+	req := spawnRequest{
+		agentName:  "sarah",
+		command:    "codex",
+		modelArgs:  "--model o3",
+		devCommand: "npm run storybook -- --port $PORT",
+		prompt:     "write tests",
+		base:       "develop",
+		maxRuntime: 2 * time.Hour,
+		tags:       []string{"issue-12"},
+		channels:   []string{"backend"},
+		run:        "auth-refactor",
+		target:     hosts.Target{Name: "gpu", SSH: "dev@gpu"},
+		keepFailed: true,
+		iteration:  2,
+	}
+	entry := queueEntry("repo", "codex", req)
+	if entry.Host != "gpu" || entry.Repo != "repo" {
+		t.Errorf("Expected the host and repository recorded, got %+v", entry)
+	}
+
+	// The name and target are chosen again when the agent is spawned
+	want := req
+	want.agentName = ""
+	want.target = hosts.Target{}
+	if got := queuedRequest(entry); !reflect.DeepEqual(got, want) {
+		t.Errorf("queuedRequest() = %+v, want %+v", got, want)
+	}
+}
@@ -0,0 +1,84 @@
+package prompt
+
+import (
+	"context"
+	"fmt"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/nehpz/claudicus/pkg/config"
+	"github.com/nehpz/claudicus/pkg/hosts"
+	"github.com/nehpz/claudicus/pkg/state"
+
+	"github.com/charmbracelet/log"
+)
+
+// Restart starts the agent of a local session whose tmux session is gone,
+// such as one restored from the trash by uzi undo, in its existing worktree or
+// the main checkout for shared sessions. The tmux session is recreated with
+// the agent's command, its model arguments, and its original prompt, and
+// worktree sessions get a dev server again. It returns the dev server port,
+// or 0 if none was started. If a step fails, the tmux session is killed.
+func Restart(ctx context.Context, configPath, sessionName string, agentState state.AgentState) (port int, err error) {
+	if agentState.IsRemote() {
+		return 0, fmt.Errorf("%s runs on host %s; only local sessions can be restarted", sessionName, agentState.Host)
+	}
+	cfg, err := loadSpawnConfig(ctx, configPath)
+	if err != nil {
+		return 0, err
+	}
+
+	dir := agentState.WorktreePath
+	if agentState.IsShared() {
+		if dir, err = mainCheckout(ctx); err != nil {
+			return 0, err
+		}
+	}
+
+	agentName := state.AgentNameFromSession(sessionName)
+	command := getCommandForAgent(agentState.Model)
+	req := spawnRequest{
+		agentName: agentName,
+		command:   command,
+		modelArgs: cfg.AgentModelArgs(agentState.Model, command),
+		prompt:    agentState.Prompt,
+		target:    hosts.Target{},
+		windowName: config.RenderName(cfg.WindowTemplate(), config.NameFields{
+			Agent:   agentName,
+			Project: strings.TrimSuffix(filepath.Base(agentState.GitRepo), ".git"),
+			Time:    time.Now(),
+			Model:   command,
+			Prompt:  agentState.Prompt,
+		}),
+	}
+
+	rb := &rollback{}
+	defer func() {
+		if err == nil {
+			return
+		}
+		if failed := rb.run(); len(failed) > 0 {
+			log.Warn("Could not clean up the failed restart", "session", sessionName, "remaining", strings.Join(failed, ", "))
+		}
+		port = 0
+	}()
+
+	rb.add("tmux session "+sessionName, killSessionUndo(ctx, req.target, sessionName))
+	if err := newAgentSession(ctx, req.target, sessionName, req.windowName, command, dir); err != nil {
+		return 0, err
+	}
+	if !agentState.IsShared() && devServerConfigured(cfg, agentState.DevCommand) {
+		assignedPorts, err := getExistingSessionPorts(stateStore(ctx))
+		if err != nil {
+			log.Warn("Failed to load existing session ports, proceeding without collision check", "error", err)
+		}
+		if port, err = startDevServer(ctx, cfg, agentState.DevCommand, sessionName, dir, assignedPorts); err != nil {
+			return 0, err
+		}
+	}
+	if err := startAgentCommand(ctx, sessionName, dir, req); err != nil {
+		return 0, err
+	}
+	return port, nil
+}
@@ -0,0 +1,47 @@
+package prompt
+
+import (
+	"strings"
+
+	"github.com/charmbracelet/log"
+)
+
+// rollback records how to undo each step of a spawn, so that an agent that
+// fails part way leaves no branch, worktree, tmux session or state entry behind
+type rollback struct {
+	steps []rollbackStep
+}
+
+type rollbackStep struct {
+	what string
+	undo func() error
+}
+
+// add records how to undo a step that succeeded
+func (r *rollback) add(what string, undo func() error) {
+	r.steps = append(r.steps, rollbackStep{what: what, undo: undo})
+}
+
+// describe lists the recorded steps, oldest first
+func (r *rollback) describe() string {
+	whats := make([]string, len(r.steps))
+	for i, step := range r.steps {
+		whats[i] = step.what
+	}
+	return strings.Join(whats, ", ")
+}
+
+// run undoes the recorded steps, newest first, continuing past failures. It
+// returns the steps that could not be undone.
+func (r *rollback) run() []string {
+	var failed []string
+	for i := len(r.steps) - 1; i >= 0; i-- {
+		step := r.steps[i]
+		if err := step.undo(); err != nil {
+			log.Error("Error rolling back spawn", "step", step.what, "error", err)
+			failed = append(failed, step.what)
+		}
+	}
+	r.steps = nil
+	return failed
+}
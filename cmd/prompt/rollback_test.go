@@ -0,0 +1,36 @@
+package prompt
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+)
+
+func TestRollbackRun(t *testing.T) {
+	var undone []string
+	rb := &rollback{}
+	for _, what := range []string{"branch", "worktree", "tmux session"} {
+		what := what
+		rb.add(what, func() error {
+			undone = append(undone, what)
+			if what == "worktree" {
+				return errors.New("worktree is locked")
+			}
+			return nil
+		})
+	}
+
+	if got := rb.describe(); got != "branch, worktree, tmux session" {
+		t.Errorf("describe() = %q", got)
+	}
+	failed := rb.run()
+	if want := []string{"tmux session", "worktree", "branch"}; !reflect.DeepEqual(undone, want) {
+		t.Errorf("Expected steps undone newest first %v, got %v", want, undone)
+	}
+	if !reflect.DeepEqual(failed, []string{"worktree"}) {
+		t.Errorf("Expected the failed step reported, got %v", failed)
+	}
+	if len(rb.steps) != 0 || rb.run() != nil {
+		t.Error("Expected a rollback to run only once")
+	}
+}
@@ -0,0 +1,204 @@
+package queue
+
+import (
+	"context"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"strconv"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"github.com/nehpz/claudicus/cmd/prompt"
+	"github.com/nehpz/claudicus/pkg/cmdctx"
+	"github.com/nehpz/claudicus/pkg/config"
+	"github.com/nehpz/claudicus/pkg/spawnqueue"
+
+	"github.com/peterbourgon/ff/v3/ffcli"
+)
+
+var (
+	fs         = flag.NewFlagSet("uzi queue", flag.ExitOnError)
+	jsonOutput = fs.Bool("json", false, "output in JSON format")
+	runFs      = flag.NewFlagSet("uzi queue run", flag.ExitOnError)
+	configPath = runFs.String("config", config.GetDefaultConfigPath(), "path to config file")
+	rmFs       = flag.NewFlagSet("uzi queue rm", flag.ExitOnError)
+	clearFs    = flag.NewFlagSet("uzi queue clear", flag.ExitOnError)
+	CmdQueue   = &ffcli.Command{
+		Name:       "queue",
+		ShortUsage: "uzi queue [--json] | uzi queue <run|rm|clear>",
+		ShortHelp:  "List the agents waiting for a slot under maxConcurrentAgents",
+		LongHelp: `With maxConcurrentAgents set in uzi.yaml, uzi prompt spawns agents only
+while fewer than that many agents of the repository are working; the rest are
+queued. Agents that signalled they are done don't count. Queued agents start,
+oldest first, when uzi kill frees a slot, when the TUI sees an agent finish, on
+the next uzi prompt, or with uzi queue run.
+
+Without a subcommand, the queued agents of this repository are listed.`,
+		FlagSet: fs,
+		Subcommands: []*ffcli.Command{
+			{
+				Name:       "run",
+				ShortUsage: "uzi queue run [--config uzi.yaml]",
+				ShortHelp:  "Spawn queued agents while slots are free",
+				FlagSet:    runFs,
+				Exec:       executeRun,
+			},
+			{
+				Name:       "rm",
+				ShortUsage: "uzi queue rm <id>...",
+				ShortHelp:  "Remove agents from the queue without spawning them",
+				FlagSet:    rmFs,
+				Exec:       executeRemove,
+			},
+			{
+				Name:       "clear",
+				ShortUsage: "uzi queue clear",
+				ShortHelp:  "Remove every queued agent of this repository",
+				FlagSet:    clearFs,
+				Exec:       executeClear,
+			},
+		},
+		Exec: executeList,
+	}
+)
+
+// entryJSON is an entry of `uzi queue --json`
+type entryJSON struct {
+	ID       int      `json:"id"`
+	Agent    string   `json:"agent"`
+	Command  string   `json:"command"`
+	Prompt   string   `json:"prompt"`
+	Base     string   `json:"base,omitempty"`
+	Host     string   `json:"host,omitempty"`
+	Tags     []string `json:"tags,omitempty"`
+	QueuedAt string   `json:"queued_at"`
+}
+
+// repoQueue opens the queue and returns the current repository
+func repoQueue(ctx context.Context) (*spawnqueue.Store, string, error) {
+	queue, err := spawnqueue.NewStore()
+	if err != nil {
+		return nil, "", err
+	}
+	sm, err := cmdctx.From(ctx).State()
+	if err != nil {
+		return nil, "", err
+	}
+	return queue, sm.GitRepo(), nil
+}
+
+func executeList(ctx context.Context, args []string) error {
+	queue, repo, err := repoQueue(ctx)
+	if err != nil {
+		return err
+	}
+	entries, err := queue.ListRepo(repo)
+	if err != nil {
+		return err
+	}
+	if cmdctx.From(ctx).WantJSON(*jsonOutput) {
+		return printJSON(os.Stdout, entries)
+	}
+	printEntries(os.Stdout, entries, time.Now())
+	return nil
+}
+
+// printEntries lists queued agents, oldest first
+func printEntries(out io.Writer, entries []spawnqueue.Entry, now time.Time) {
+	if len(entries) == 0 {
+		fmt.Fprintln(out, "No agents queued")
+		return
+	}
+	w := tabwriter.NewWriter(out, 0, 0, 2, ' ', 0)
+	fmt.Fprintf(w, "ID\tAGENT\tWAITING\tPROMPT\n")
+	for _, entry := range entries {
+		fmt.Fprintf(w, "#%d\t%s\t%s\t%s\n", entry.ID, entry.Command, formatWait(now.Sub(entry.QueuedAt)), entry.Prompt)
+	}
+	w.Flush()
+}
+
+// formatWait renders how long an agent has been queued
+func formatWait(d time.Duration) string {
+	switch {
+	case d < time.Minute:
+		return fmt.Sprintf("%ds", int(d.Seconds()))
+	case d < time.Hour:
+		return fmt.Sprintf("%dm", int(d.Minutes()))
+	default:
+		return fmt.Sprintf("%dh", int(d.Hours()))
+	}
+}
+
+func printJSON(out io.Writer, entries []spawnqueue.Entry) error {
+	list := make([]entryJSON, 0, len(entries))
+	for _, entry := range entries {
+		list = append(list, entryJSON{
+			ID:       entry.ID,
+			Agent:    entry.Agent,
+			Command:  entry.Command,
+			Prompt:   entry.Prompt,
+			Base:     entry.Base,
+			Host:     entry.Host,
+			Tags:     entry.Tags,
+			QueuedAt: entry.QueuedAt.Format(time.RFC3339),
+		})
+	}
+	encoder := json.NewEncoder(out)
+	encoder.SetIndent("", "  ")
+	return encoder.Encode(list)
+}
+
+func executeRun(ctx context.Context, args []string) error {
+	spawned, err := prompt.DrainQueue(ctx, *configPath)
+	if err != nil {
+		return err
+	}
+	if len(spawned) == 0 {
+		fmt.Println("No queued agents started")
+		return nil
+	}
+	fmt.Printf("Started %d queued agent(s): %s\n", len(spawned), strings.Join(spawned, ", "))
+	return nil
+}
+
+func executeRemove(ctx context.Context, args []string) error {
+	if len(args) == 0 {
+		return fmt.Errorf("usage: uzi queue rm <id>...")
+	}
+	queue, _, err := repoQueue(ctx)
+	if err != nil {
+		return err
+	}
+	for _, arg := range args {
+		id, err := strconv.Atoi(strings.TrimPrefix(arg, "#"))
+		if err != nil {
+			return fmt.Errorf("invalid queue id %q", arg)
+		}
+		removed, err := queue.Remove(id)
+		if err != nil {
+			return err
+		}
+		if !removed {
+			return fmt.Errorf("no queued agent #%d", id)
+		}
+		fmt.Printf("Removed #%d from the queue\n", id)
+	}
+	return nil
+}
+
+func executeClear(ctx context.Context, args []string) error {
+	queue, repo, err := repoQueue(ctx)
+	if err != nil {
+		return err
+	}
+	cleared, err := queue.Clear(repo)
+	if err != nil {
+		return err
+	}
+	fmt.Printf("Removed %d queued agent(s)\n", cleared)
+	return nil
+}
@@ -0,0 +1,61 @@
+package queue
+
+import (
+	"bytes"
+	"encoding/json"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/nehpz/claudicus/pkg/spawnqueue"
+)
+
+func TestPrintEntries(t *testing.T) {
+	now := time.Unix(1700000000, 0)
+	entries := []spawnqueue.Entry{
+		{ID: 3, Agent: "claude", Command: "claude", Prompt: "fix the login bug", QueuedAt: now.Add(-90 * time.Second)},
+		{ID: 4, Agent: "random", Command: "codex", Prompt: "write tests", QueuedAt: now.Add(-2 * time.Hour)},
+	}
+
+	var out bytes.Buffer
+	printEntries(&out, entries, now)
+	lines := strings.Split(strings.TrimSpace(out.String()), "\n")
+	if len(lines) != 3 {
+		t.Fatalf("Expected a header and 2 entries, got %q", out.String())
+	}
+	if fields := strings.Fields(lines[1]); len(fields) < 3 || fields[0] != "#3" || fields[1] != "claude" || fields[2] != "1m" {
+		t.Errorf("Unexpected first entry: %q", lines[1])
+	}
+	if !strings.Contains(lines[2], "2h") || !strings.HasSuffix(lines[2], "write tests") {
+		t.Errorf("Unexpected second entry: %q", lines[2])
+	}
+
+	out.Reset()
+	printEntries(&out, nil, now)
+	if out.String() != "No agents queued\n" {
+		t.Errorf("Unexpected output for an empty queue: %q", out.String())
+	}
+}
+
+func TestPrintJSON(t *testing.T) {
+	var out bytes.Buffer
+	if err := printJSON(&out, nil); err != nil {
+		t.Fatal(err)
+	}
+	if strings.TrimSpace(out.String()) != "[]" {
+		t.Errorf("Expected an empty list, got %q", out.String())
+	}
+
+	out.Reset()
+	queuedAt := time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC)
+	if err := printJSON(&out, []spawnqueue.Entry{{ID: 1, Agent: "claude", Command: "claude", Prompt: "fix it", Host: "gpu", QueuedAt: queuedAt}}); err != nil {
+		t.Fatal(err)
+	}
+	var list []entryJSON
+	if err := json.Unmarshal(out.Bytes(), &list); err != nil {
+		t.Fatal(err)
+	}
+	if len(list) != 1 || list[0].Host != "gpu" || list[0].QueuedAt != "2024-05-01T12:00:00Z" {
+		t.Errorf("Unexpected JSON: %+v", list)
+	}
+}
@@ -0,0 +1,208 @@
+package recover
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/nehpz/claudicus/pkg/cmdctx"
+	"github.com/nehpz/claudicus/pkg/config"
+	"github.com/nehpz/claudicus/pkg/state"
+	"github.com/nehpz/claudicus/pkg/tmuxops"
+	"github.com/nehpz/claudicus/pkg/tui"
+
+	"github.com/charmbracelet/log"
+	"github.com/peterbourgon/ff/v3/ffcli"
+)
+
+// CommandExecutor abstracts the execution of external commands
+type CommandExecutor = tmuxops.OutputExecutor
+
+// RealCommandExecutor implements CommandExecutor using exec.Command
+type RealCommandExecutor = tmuxops.RealCommandExecutor
+
+var (
+	fs         = flag.NewFlagSet("uzi recover", flag.ExitOnError)
+	dryRun     = fs.Bool("dry-run", false, "list orphaned sessions without writing them to state")
+	configPath = fs.String("config", config.GetDefaultConfigPath(), "path to config file")
+	CmdRecover = &ffcli.Command{
+		Name:       "recover",
+		ShortUsage: "uzi recover [--dry-run] [--config PATH]",
+		ShortHelp:  "Re-adopt running agent tmux sessions that are missing from state",
+		LongHelp: `Scan tmux for agent sessions of this repository that uzi no longer tracks,
+for example after state.json was deleted or a spawn crashed midway, and add
+them back to state. The worktree is taken from the agent pane's working
+directory and the model from the command running in it. Prompts and dev
+server ports cannot be recovered.`,
+		FlagSet: fs,
+		Exec: func(ctx context.Context, args []string) error {
+			return executeRecover(ctx, &RealCommandExecutor{}, tui.NewTmuxDiscovery())
+		},
+	}
+)
+
+// shells are pane commands that mean the agent has exited and only its shell is left
+var shells = map[string]bool{"sh": true, "bash": true, "zsh": true, "fish": true}
+
+// recoveredSession is the state reconstructed for one orphaned tmux session
+type recoveredSession struct {
+	SessionName  string
+	BranchName   string
+	WorktreePath string
+	Model        string
+	Mode         string
+}
+
+func executeRecover(ctx context.Context, executor CommandExecutor, discovery *tui.TmuxDiscovery) error {
+	sm, err := cmdctx.From(ctx).State()
+	if err != nil {
+		return err
+	}
+	// Without a config file the default session names are matched
+	cfg, _ := cmdctx.From(ctx).LoadConfig(*configPath)
+
+	projectDir, err := projectName(executor)
+	if err != nil {
+		return err
+	}
+
+	mainCheckout, err := gitOutput(executor, "", "rev-parse", "--show-toplevel")
+	if err != nil {
+		return fmt.Errorf("failed to find main checkout: %w", err)
+	}
+
+	sessions, err := discovery.GetUziSessions()
+	if err != nil {
+		return fmt.Errorf("failed to list tmux sessions: %w", err)
+	}
+
+	var orphaned []string
+	for name := range sessions {
+		if !repoSession(cfg.SessionTemplate(), projectDir, name, sessions[name]) {
+			continue
+		}
+		if _, err := sm.GetWorktreeInfo(name); err == nil {
+			continue // already tracked
+		}
+		orphaned = append(orphaned, name)
+	}
+	sort.Strings(orphaned)
+
+	if len(orphaned) == 0 {
+		fmt.Println("No orphaned agent sessions found")
+		return nil
+	}
+
+	recovered := 0
+	for _, sessionName := range orphaned {
+		pane, err := discovery.GetAgentPane(sessionName)
+		if err != nil {
+			log.Error("Error reading agent pane", "session", sessionName, "error", err)
+			continue
+		}
+
+		session := recoverSession(executor, sessionName, pane, mainCheckout)
+		if agent := sessions[sessionName].Agent; agent != "" {
+			// The agent CLI recorded at spawn is more reliable than the pane's command
+			session.Model = agent
+		}
+		fmt.Printf("%s: %s (%s)\n", state.AgentNameFromSession(sessionName), session.Model, describeLocation(session))
+		if *dryRun {
+			continue
+		}
+
+		if err := sm.SaveStateWithMode("", session.BranchName, session.SessionName, session.WorktreePath, session.Model, 0, "", session.Mode); err != nil {
+			log.Error("Error saving state", "session", sessionName, "error", err)
+			continue
+		}
+		recovered++
+	}
+
+	if *dryRun {
+		fmt.Printf("Found %d orphaned session(s); run without --dry-run to recover them\n", len(orphaned))
+		return nil
+	}
+	fmt.Printf("Recovered %d of %d orphaned session(s)\n", recovered, len(orphaned))
+	return nil
+}
+
+// repoSession reports whether a tmux session is an agent session of the
+// repository: its name fits the session naming template, with {project} the
+// repository's name, and uzi marked it when it created it. Unmarked sessions
+// predate the marker and are only recognized in the default name format.
+func repoSession(template, projectDir, name string, session tui.TmuxSessionInfo) bool {
+	if _, ok := config.AgentFromName(strings.ReplaceAll(template, "{project}", projectDir), name); !ok {
+		return false
+	}
+	return session.Uzi || strings.HasPrefix(name, "agent-"+projectDir+"-")
+}
+
+// recoverSession reconstructs the state of a session from its agent pane. Panes
+// sitting in the main checkout are recovered as shared sessions.
+func recoverSession(executor CommandExecutor, sessionName string, pane tui.AgentPaneInfo, mainCheckout string) recoveredSession {
+	session := recoveredSession{
+		SessionName: sessionName,
+		Model:       modelFromCommand(pane.Command),
+	}
+
+	worktreePath, err := gitOutput(executor, pane.Path, "rev-parse", "--show-toplevel")
+	if err != nil {
+		// Not inside a git checkout anymore; keep the pane directory so kill can still clean up
+		session.WorktreePath = pane.Path
+		return session
+	}
+
+	if filepath.Clean(worktreePath) == filepath.Clean(mainCheckout) {
+		session.Mode = state.ModeShared
+		return session
+	}
+
+	session.WorktreePath = worktreePath
+	if branch, err := gitOutput(executor, worktreePath, "branch", "--show-current"); err == nil {
+		session.BranchName = branch
+	}
+	return session
+}
+
+// modelFromCommand maps the agent pane's foreground command to a model name
+func modelFromCommand(command string) string {
+	if command == "" || shells[command] {
+		return "unknown"
+	}
+	return command
+}
+
+// describeLocation returns a short description of where a recovered session runs
+func describeLocation(session recoveredSession) string {
+	if session.Mode == state.ModeShared {
+		return "main checkout"
+	}
+	if session.BranchName == "" {
+		return session.WorktreePath
+	}
+	return session.BranchName
+}
+
+// projectName returns the repository name that prefixes agent session names
+func projectName(executor CommandExecutor) (string, error) {
+	remoteURL, err := gitOutput(executor, "", "remote", "get-url", "origin")
+	if err != nil {
+		return "", fmt.Errorf("failed to get git remote: %w", err)
+	}
+	return strings.TrimSuffix(filepath.Base(remoteURL), ".git"), nil
+}
+
+// gitOutput runs git in dir (or the current directory when empty) and returns its trimmed output
+func gitOutput(executor CommandExecutor, dir string, args ...string) (string, error) {
+	if dir != "" {
+		args = append([]string{"-C", dir}, args...)
+	}
+	output, err := executor.ExecuteCommand("git", args...)
+	if err != nil {
+		return "", err
+	}
+	return strings.TrimSpace(string(output)), nil
+}
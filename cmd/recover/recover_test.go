@@ -0,0 +1,115 @@
+package recover
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/nehpz/claudicus/pkg/state"
+	"github.com/nehpz/claudicus/pkg/tui"
+)
+
+// MockCommandExecutor answers git commands from a table keyed by the joined arguments
+type MockCommandExecutor struct {
+	outputs map[string]string
+}
+
+// ExecuteCommand returns the configured output or an error for unknown commands
+func (m *MockCommandExecutor) ExecuteCommand(command string, args ...string) ([]byte, error) {
+	key := command + " " + strings.Join(args, " ")
+	if out, ok := m.outputs[key]; ok {
+		return []byte(out + "\n"), nil
+	}
+	return nil, fmt.Errorf("not a git repository")
+}
+
+func TestRecoverSession(t *testing.T) {
+	executor := &MockCommandExecutor{outputs: map[string]string{
+		"git -C /wt/sarah/src rev-parse --show-toplevel": "/wt/sarah",
+		"git -C /wt/sarah branch --show-current":         "sarah-repo-abc123-1-0",
+		"git -C /repo rev-parse --show-toplevel":         "/repo",
+	}}
+
+	tests := []struct {
+		name string
+		pane tui.AgentPaneInfo
+		want recoveredSession
+	}{
+		{
+			name: "worktree",
+			pane: tui.AgentPaneInfo{Path: "/wt/sarah/src", Command: "claude"},
+			want: recoveredSession{WorktreePath: "/wt/sarah", BranchName: "sarah-repo-abc123-1-0", Model: "claude"},
+		},
+		{
+			name: "main checkout",
+			pane: tui.AgentPaneInfo{Path: "/repo", Command: "codex"},
+			want: recoveredSession{Model: "codex", Mode: state.ModeShared},
+		},
+		{
+			name: "deleted worktree",
+			pane: tui.AgentPaneInfo{Path: "/wt/gone", Command: "zsh"},
+			want: recoveredSession{WorktreePath: "/wt/gone", Model: "unknown"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := recoverSession(executor, "agent-repo-abc123-sarah", tt.pane, "/repo")
+			tt.want.SessionName = "agent-repo-abc123-sarah"
+			if got != tt.want {
+				t.Errorf("recoverSession() = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestModelFromCommand(t *testing.T) {
+	tests := map[string]string{
+		"claude": "claude",
+		"bash":   "unknown",
+		"":       "unknown",
+	}
+	for command, want := range tests {
+		if got := modelFromCommand(command); got != want {
+			t.Errorf("modelFromCommand(%q) = %q, want %q", command, got, want)
+		}
+	}
+}
+
+func TestRepoSession(t *testing.T) {
+	marked := tui.TmuxSessionInfo{Uzi: true}
+	for _, tt := range []struct {
+		template string
+		name     string
+		session  tui.TmuxSessionInfo
+		want     bool
+	}{
+		{"agent-{project}-{hash}-{agent}", "agent-claudicus-abc123-sarah", marked, true},
+		// Sessions from before the marker in the default format
+		{"agent-{project}-{hash}-{agent}", "agent-claudicus-abc123-sarah", tui.TmuxSessionInfo{}, true},
+		// Another repository's sessions
+		{"agent-{project}-{hash}-{agent}", "agent-website-abc123-sarah", marked, false},
+		{"{agent}-{hash:4}", "sarah-abc1", marked, true},
+		{"{agent}-{hash:4}", "sarah-abc1", tui.TmuxSessionInfo{}, false},
+		{"{agent}-{hash:4}", "scratch", marked, false},
+		{"{project}/{agent}", "claudicus/sarah", marked, true},
+		{"{project}/{agent}", "website/sarah", marked, false},
+	} {
+		if got := repoSession(tt.template, "claudicus", tt.name, tt.session); got != tt.want {
+			t.Errorf("repoSession(%q, %q, uzi=%v) = %v, want %v", tt.template, tt.name, tt.session.Uzi, got, tt.want)
+		}
+	}
+}
+
+func TestProjectName(t *testing.T) {
+	executor := &MockCommandExecutor{outputs: map[string]string{
+		"git remote get-url origin": "git@github.com:nehpz/claudicus.git",
+	}}
+	if got, err := projectName(executor); err != nil || got != "claudicus" {
+		t.Errorf("projectName() = %q, %v", got, err)
+	}
+
+	if _, err := projectName(&MockCommandExecutor{}); err == nil {
+		t.Error("Expected error without a remote")
+	}
+}
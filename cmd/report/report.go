@@ -0,0 +1,240 @@
+package report
+
+import (
+	"context"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/nehpz/claudicus/pkg/cmdctx"
+	"github.com/nehpz/claudicus/pkg/hosts"
+	"github.com/nehpz/claudicus/pkg/state"
+
+	"github.com/charmbracelet/log"
+	"github.com/peterbourgon/ff/v3/ffcli"
+)
+
+var (
+	fs         = flag.NewFlagSet("uzi report", flag.ExitOnError)
+	task       = fs.String("task", "", "compare the agents tagged with this tag (required)")
+	jsonOutput = fs.Bool("json", false, "output in JSON format instead of markdown")
+	CmdReport  = &ffcli.Command{
+		Name:       "report",
+		ShortUsage: "uzi report --task <tag> [--json]",
+		ShortHelp:  "Compare the agents working on a task",
+		LongHelp: `The report command compares every active agent tagged with --task, such as
+agents spawned together with uzi prompt --tag issue-12, to pick the one whose
+work to keep. For each agent it shows:
+
+- the wall-clock time from spawn until the agent signalled it was done, or
+  until now for agents still working
+- the size of its diff: files changed, insertions and deletions
+- overlap: changed files that other agents of the task changed too
+- the outcome of its last uzi checkpoint
+
+Agents are listed done first, fastest first. The report is markdown, ready to
+paste into an issue or pull request, or JSON with --json.`,
+		FlagSet: fs,
+		Exec:    executeReport,
+	}
+)
+
+// Checkpoint outcomes of an agent
+const (
+	checkpointOK     = "ok"
+	checkpointFailed = "failed"
+	checkpointNone   = "none"
+)
+
+// agentReport is one agent's row of the report
+type agentReport struct {
+	Agent           string   `json:"agent"`
+	Session         string   `json:"session"`
+	Model           string   `json:"model"`
+	Status          string   `json:"status"`
+	Done            bool     `json:"done"`
+	Seconds         int64    `json:"seconds"` // from spawn until done, or until now
+	Files           int      `json:"files"`
+	Insertions      int      `json:"insertions"`
+	Deletions       int      `json:"deletions"`
+	Overlap         []string `json:"overlap,omitempty"` // changed files other agents of the task changed too
+	Checkpoint      string   `json:"checkpoint"`        // ok, failed, or none
+	CheckpointError string   `json:"checkpoint_error,omitempty"`
+	DiffError       string   `json:"diff_error,omitempty"` // why the diff could not be read
+}
+
+// taskReport compares the agents of a task
+type taskReport struct {
+	Task   string        `json:"task"`
+	Agents []agentReport `json:"agents"`
+	// Overlap maps each file changed by more than one agent to those agents
+	Overlap map[string][]string `json:"overlap"`
+}
+
+// taskSession is an agent of the task with what is known about its work
+type taskSession struct {
+	name    string
+	state   state.AgentState
+	status  string
+	diff    state.DiffStat
+	diffErr error
+}
+
+func executeReport(ctx context.Context, args []string) error {
+	if *task == "" {
+		return fmt.Errorf("--task is required")
+	}
+
+	sm, err := cmdctx.From(ctx).State()
+	if err != nil {
+		return err
+	}
+	activeSessions, err := sm.GetActiveSessionsForRepo()
+	if err != nil {
+		return fmt.Errorf("error getting active sessions: %w", err)
+	}
+
+	aggregator := state.NewAggregator(state.WithTmuxStatus(), state.WithRemoteProbe(hosts.RemoteProbe))
+	var sessions []taskSession
+	for _, sessionName := range activeSessions {
+		agentState, err := sm.GetWorktreeInfo(sessionName)
+		if err != nil {
+			log.Debug("Skipping session without state", "session", sessionName, "error", err)
+			continue
+		}
+		if !agentState.HasTag(*task) {
+			continue
+		}
+		info := aggregator.Session(sessionName, *agentState)
+		diff, diffErr := aggregator.FileDiffs(*agentState)
+		sessions = append(sessions, taskSession{name: sessionName, state: *agentState, status: info.Status, diff: diff, diffErr: diffErr})
+	}
+	if len(sessions) == 0 {
+		return fmt.Errorf("no active agents tagged %q", *task)
+	}
+
+	report := buildReport(*task, sessions, time.Now())
+	if cmdctx.From(ctx).WantJSON(*jsonOutput) {
+		encoder := json.NewEncoder(os.Stdout)
+		encoder.SetIndent("", "  ")
+		return encoder.Encode(report)
+	}
+	writeMarkdown(os.Stdout, report)
+	return nil
+}
+
+// buildReport compares the sessions of a task as of now
+func buildReport(task string, sessions []taskSession, now time.Time) taskReport {
+	diffs := make(map[string]state.DiffStat, len(sessions))
+	for _, session := range sessions {
+		diffs[state.AgentNameFromSession(session.name)] = session.diff
+	}
+	overlap := state.Overlap(diffs)
+
+	report := taskReport{Task: task, Overlap: overlap}
+	for _, session := range sessions {
+		end := now
+		if session.state.Done && !session.state.DoneAt.IsZero() {
+			end = session.state.DoneAt
+		}
+		row := agentReport{
+			Agent:      state.AgentNameFromSession(session.name),
+			Session:    session.name,
+			Model:      session.state.Model,
+			Status:     session.status,
+			Done:       session.state.Done,
+			Seconds:    int64(end.Sub(session.state.CreatedAt).Seconds()),
+			Files:      len(session.diff.Files),
+			Insertions: session.diff.Insertions(),
+			Deletions:  session.diff.Deletions(),
+			Checkpoint: checkpointNone,
+		}
+		if row.Seconds < 0 {
+			row.Seconds = 0
+		}
+		for _, file := range session.diff.Files {
+			if _, ok := overlap[file.Path]; ok {
+				row.Overlap = append(row.Overlap, file.Path)
+			}
+		}
+		if !session.state.CheckpointedAt.IsZero() {
+			row.Checkpoint = checkpointOK
+			if session.state.CheckpointError != "" {
+				row.Checkpoint = checkpointFailed
+				row.CheckpointError = session.state.CheckpointError
+			}
+		}
+		if session.diffErr != nil {
+			row.DiffError = session.diffErr.Error()
+		}
+		report.Agents = append(report.Agents, row)
+	}
+
+	// Done agents first, then the fastest
+	sort.Slice(report.Agents, func(i, j int) bool {
+		a, b := report.Agents[i], report.Agents[j]
+		if a.Done != b.Done {
+			return a.Done
+		}
+		if a.Seconds != b.Seconds {
+			return a.Seconds < b.Seconds
+		}
+		return a.Agent < b.Agent
+	})
+	return report
+}
+
+// writeMarkdown renders the report as a markdown table and overlap list
+func writeMarkdown(out io.Writer, report taskReport) {
+	fmt.Fprintf(out, "# Task %s: %d agents\n\n", report.Task, len(report.Agents))
+	fmt.Fprintln(out, "| Agent | Model | Status | Time | Files | Lines | Overlap | Checkpoint |")
+	fmt.Fprintln(out, "|-------|-------|--------|------|-------|-------|---------|------------|")
+	for _, row := range report.Agents {
+		elapsed := formatElapsed(time.Duration(row.Seconds) * time.Second)
+		if !row.Done {
+			elapsed += " (working)"
+		}
+		files, lines := fmt.Sprint(row.Files), fmt.Sprintf("+%d -%d", row.Insertions, row.Deletions)
+		if row.DiffError != "" {
+			files, lines = "?", "?"
+		}
+		checkpoint := row.Checkpoint
+		if row.CheckpointError != "" {
+			checkpoint += ": " + strings.ReplaceAll(row.CheckpointError, "|", `\|`)
+		}
+		fmt.Fprintf(out, "| %s | %s | %s | %s | %s | %s | %d | %s |\n",
+			row.Agent, row.Model, row.Status, elapsed, files, lines, len(row.Overlap), checkpoint)
+	}
+
+	if len(report.Overlap) == 0 {
+		fmt.Fprintln(out, "\nNo file was changed by more than one agent.")
+		return
+	}
+	paths := make([]string, 0, len(report.Overlap))
+	for path := range report.Overlap {
+		paths = append(paths, path)
+	}
+	sort.Strings(paths)
+	fmt.Fprintln(out, "\n## Files changed by several agents")
+	fmt.Fprintln(out)
+	for _, path := range paths {
+		fmt.Fprintf(out, "- `%s`: %s\n", path, strings.Join(report.Overlap[path], ", "))
+	}
+}
+
+// formatElapsed renders a duration to the minute, or in seconds under one
+func formatElapsed(d time.Duration) string {
+	switch {
+	case d < time.Minute:
+		return fmt.Sprintf("%ds", int(d.Seconds()))
+	case d < time.Hour:
+		return fmt.Sprintf("%dm", int(d.Minutes()))
+	default:
+		return fmt.Sprintf("%dh%02dm", int(d.Hours()), int(d.Minutes())%60)
+	}
+}
@@ -0,0 +1,85 @@
+package report
+
+import (
+	"bytes"
+	"fmt"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/nehpz/claudicus/pkg/state"
+)
+
+func TestBuildReport(t *testing.T) {
+	start := time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC)
+	now := start.Add(time.Hour)
+	sessions := []taskSession{
+		{
+			name:   "agent-app-abc123-sarah",
+			state:  state.AgentState{Model: "claude", CreatedAt: start},
+			status: "running",
+			diff: state.DiffStat{Files: []state.FileDiff{
+				{Path: "login.go", Insertions: 10, Deletions: 2},
+				{Path: "login_test.go", Insertions: 30},
+			}},
+		},
+		{
+			name:   "agent-app-abc123-emily",
+			state:  state.AgentState{Model: "codex", CreatedAt: start, Done: true, DoneAt: start.Add(20 * time.Minute), CheckpointedAt: now},
+			status: state.StatusDone,
+			diff:   state.DiffStat{Files: []state.FileDiff{{Path: "login.go", Insertions: 4, Deletions: 1}}},
+		},
+		{
+			name:    "agent-app-abc123-john",
+			state:   state.AgentState{Model: "claude", CreatedAt: start, Done: true, DoneAt: start.Add(45 * time.Minute), CheckpointedAt: now, CheckpointError: "error rebasing agent changes: exit status 1"},
+			status:  state.StatusDone,
+			diffErr: fmt.Errorf("worktree is gone"),
+		},
+	}
+
+	report := buildReport("issue-12", sessions, now)
+	var order []string
+	for _, row := range report.Agents {
+		order = append(order, row.Agent)
+	}
+	if !reflect.DeepEqual(order, []string{"emily", "john", "sarah"}) {
+		t.Errorf("Expected done agents first, fastest first, got %v", order)
+	}
+
+	emily, john, sarah := report.Agents[0], report.Agents[1], report.Agents[2]
+	if emily.Seconds != 20*60 || emily.Checkpoint != checkpointOK || emily.Files != 1 {
+		t.Errorf("Unexpected row for emily: %+v", emily)
+	}
+	if sarah.Seconds != 60*60 || sarah.Insertions != 40 || sarah.Deletions != 2 || sarah.Checkpoint != checkpointNone {
+		t.Errorf("Expected sarah still working for an hour, got %+v", sarah)
+	}
+	if john.Checkpoint != checkpointFailed || john.CheckpointError == "" || john.DiffError != "worktree is gone" {
+		t.Errorf("Expected john's failed checkpoint and diff error, got %+v", john)
+	}
+	if !reflect.DeepEqual(sarah.Overlap, []string{"login.go"}) || !reflect.DeepEqual(report.Overlap, map[string][]string{"login.go": {"emily", "sarah"}}) {
+		t.Errorf("Expected login.go to overlap between emily and sarah, got %v and %v", sarah.Overlap, report.Overlap)
+	}
+
+	var out bytes.Buffer
+	writeMarkdown(&out, report)
+	for _, want := range []string{
+		"# Task issue-12: 3 agents",
+		"| emily | codex | done | 20m | 1 | +4 -1 | 1 | ok |",
+		"| john | claude | done | 45m | ? | ? | 0 | failed: error rebasing agent changes: exit status 1 |",
+		"| sarah | claude | running | 1h00m (working) | 2 | +40 -2 | 1 | none |",
+		"- `login.go`: emily, sarah",
+	} {
+		if !strings.Contains(out.String(), want) {
+			t.Errorf("Expected %q in the report, got:\n%s", want, out.String())
+		}
+	}
+}
+
+func TestWriteMarkdownWithoutOverlap(t *testing.T) {
+	var out bytes.Buffer
+	writeMarkdown(&out, buildReport("docs", []taskSession{{name: "agent-app-abc123-sarah", status: "ready"}}, time.Now()))
+	if !strings.Contains(out.String(), "No file was changed by more than one agent.") {
+		t.Errorf("Expected a note that no files overlap, got:\n%s", out.String())
+	}
+}
@@ -5,8 +5,8 @@ import (
 	"context"
 	"flag"
 	"fmt"
+	"github.com/nehpz/claudicus/pkg/cmdctx"
 	"github.com/nehpz/claudicus/pkg/config"
-	"github.com/nehpz/claudicus/pkg/state"
 	"os/exec"
 	"strings"
 
@@ -37,9 +37,9 @@ func executeRun(ctx context.Context, args []string) error {
 	command := strings.Join(args, " ")
 
 	// Get state manager to read from config
-	sm := state.NewStateManager()
-	if sm == nil {
-		return fmt.Errorf("could not initialize state manager")
+	sm, err := cmdctx.From(ctx).State()
+	if err != nil {
+		return err
 	}
 
 	// Get active sessions from state
@@ -0,0 +1,192 @@
+package status
+
+import (
+	"context"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"sort"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/nehpz/claudicus/pkg/cmdctx"
+	"github.com/nehpz/claudicus/pkg/hosts"
+	"github.com/nehpz/claudicus/pkg/state"
+
+	"github.com/peterbourgon/ff/v3/ffcli"
+)
+
+var (
+	runFs        = flag.NewFlagSet("uzi run-status", flag.ExitOnError)
+	runJSON      = runFs.Bool("json", false, "output the runs in JSON format")
+	CmdRunStatus = &ffcli.Command{
+		Name:       "run-status",
+		ShortUsage: "uzi run-status [--json] [<run-name>]",
+		ShortHelp:  "Show how the agents of a run are doing",
+		LongHelp: `A run groups the agents spawned with uzi prompt --run-name, such as the
+agents of one experiment. run-status prints each agent of the run with its
+status and diff, and how many agents are in each status; agents whose tmux
+session has ended are listed as dead until they are killed.
+
+Without a run name, every run of the repository is summarized on one line.
+uzi kill --run kills the agents of a run together.`,
+		FlagSet: runFs,
+		Exec:    executeRunStatus,
+	}
+)
+
+// RunAgent is one agent of a run
+type RunAgent struct {
+	Agent      string `json:"agent"`
+	Session    string `json:"session"`
+	Model      string `json:"model"`
+	Status     string `json:"status"`
+	Insertions int    `json:"insertions"`
+	Deletions  int    `json:"deletions"`
+	CreatedAt  string `json:"created_at"`
+}
+
+// RunStatus is the state of a run's agents
+type RunStatus struct {
+	Run        string         `json:"run"`
+	Agents     []RunAgent     `json:"agents"`
+	Statuses   map[string]int `json:"statuses"` // number of agents in each status
+	Insertions int            `json:"insertions"`
+	Deletions  int            `json:"deletions"`
+}
+
+func executeRunStatus(ctx context.Context, args []string) error {
+	sm, err := cmdctx.From(ctx).State()
+	if err != nil {
+		return err
+	}
+	states, err := sm.StatesForRepo()
+	if err != nil {
+		return fmt.Errorf("error loading sessions: %w", err)
+	}
+	activeSessions, err := sm.GetActiveSessionsForRepo()
+	if err != nil {
+		return fmt.Errorf("error getting active sessions: %w", err)
+	}
+	alive := make(map[string]bool, len(activeSessions))
+	for _, sessionName := range activeSessions {
+		alive[sessionName] = true
+	}
+
+	aggregator := state.NewAggregator(state.WithTmuxStatus(), state.WithDiffs(), state.WithRemoteProbe(hosts.RemoteProbe))
+	runs := runStatuses(states, func(sessionName string, agentState state.AgentState) state.SessionInfo {
+		info := aggregator.Session(sessionName, agentState)
+		if !alive[sessionName] {
+			info.Status = state.StatusDead
+		}
+		return info
+	})
+
+	if len(args) > 0 {
+		run, ok := findRun(runs, args[0])
+		if !ok {
+			return fmt.Errorf("no agents found in run: %s", args[0])
+		}
+		runs = []RunStatus{run}
+	}
+	if cmdctx.From(ctx).WantJSON(*runJSON) {
+		encoder := json.NewEncoder(os.Stdout)
+		encoder.SetIndent("", "  ")
+		return encoder.Encode(runs)
+	}
+	if len(args) > 0 {
+		printRun(os.Stdout, runs[0])
+	} else {
+		printRuns(os.Stdout, runs)
+	}
+	return nil
+}
+
+// runStatuses groups the sessions of states by run, sorted by run name, with
+// their agents sorted by name. session reads a session's status and diff.
+func runStatuses(states map[string]state.AgentState, session func(string, state.AgentState) state.SessionInfo) []RunStatus {
+	byName := make(map[string]*RunStatus)
+	for sessionName, agentState := range states {
+		if agentState.Run == "" {
+			continue
+		}
+		run := byName[agentState.Run]
+		if run == nil {
+			run = &RunStatus{Run: agentState.Run, Statuses: map[string]int{}}
+			byName[agentState.Run] = run
+		}
+		info := session(sessionName, agentState)
+		run.Agents = append(run.Agents, RunAgent{
+			Agent:      info.AgentName,
+			Session:    sessionName,
+			Model:      info.Model,
+			Status:     info.Status,
+			Insertions: info.Insertions,
+			Deletions:  info.Deletions,
+			CreatedAt:  info.CreatedAt,
+		})
+		run.Statuses[info.Status]++
+		run.Insertions += info.Insertions
+		run.Deletions += info.Deletions
+	}
+
+	runs := make([]RunStatus, 0, len(byName))
+	for _, run := range byName {
+		sort.Slice(run.Agents, func(i, j int) bool { return run.Agents[i].Agent < run.Agents[j].Agent })
+		runs = append(runs, *run)
+	}
+	sort.Slice(runs, func(i, j int) bool { return runs[i].Run < runs[j].Run })
+	return runs
+}
+
+// findRun returns the run with the name
+func findRun(runs []RunStatus, name string) (RunStatus, bool) {
+	for _, run := range runs {
+		if run.Run == name {
+			return run, true
+		}
+	}
+	return RunStatus{}, false
+}
+
+// statusSummary lists how many agents are in each status, e.g. "2 running, 1 done"
+func statusSummary(statuses map[string]int) string {
+	names := make([]string, 0, len(statuses))
+	for status := range statuses {
+		names = append(names, status)
+	}
+	sort.Strings(names)
+	parts := make([]string, 0, len(names))
+	for _, status := range names {
+		parts = append(parts, fmt.Sprintf("%d %s", statuses[status], status))
+	}
+	return strings.Join(parts, ", ")
+}
+
+// printRuns writes one line per run
+func printRuns(out io.Writer, runs []RunStatus) {
+	if len(runs) == 0 {
+		fmt.Fprintln(out, "No runs found; spawn agents with uzi prompt --run-name to start one")
+		return
+	}
+	w := tabwriter.NewWriter(out, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "RUN\tAGENTS\tSTATUS\tDIFF")
+	for _, run := range runs {
+		fmt.Fprintf(w, "%s\t%d\t%s\t+%d -%d\n", run.Run, len(run.Agents), statusSummary(run.Statuses), run.Insertions, run.Deletions)
+	}
+	w.Flush()
+}
+
+// printRun writes a run's agents and the run's totals
+func printRun(out io.Writer, run RunStatus) {
+	fmt.Fprintf(out, "Run %s: %d %s, %s, +%d -%d\n\n", run.Run, len(run.Agents), plural(len(run.Agents), "agent", "agents"),
+		statusSummary(run.Statuses), run.Insertions, run.Deletions)
+	w := tabwriter.NewWriter(out, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "AGENT\tMODEL\tSTATUS\tDIFF")
+	for _, agent := range run.Agents {
+		fmt.Fprintf(w, "%s\t%s\t%s\t+%d -%d\n", agent.Agent, agent.Model, agent.Status, agent.Insertions, agent.Deletions)
+	}
+	w.Flush()
+}
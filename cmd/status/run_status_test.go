@@ -0,0 +1,59 @@
+package status
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/nehpz/claudicus/pkg/state"
+)
+
+func TestRunStatuses(t *testing.T) {
+	states := map[string]state.AgentState{
+		"agent-repo-abc-sarah": {Run: "auth-refactor", Model: "claude"},
+		"agent-repo-abc-john":  {Run: "auth-refactor", Model: "codex"},
+		"agent-repo-abc-emily": {Run: "docs", Model: "claude"},
+		"agent-repo-abc-bob":   {Model: "claude"},
+	}
+	statuses := map[string]string{"sarah": "running", "john": "dead", "emily": "ready"}
+	runs := runStatuses(states, func(sessionName string, agentState state.AgentState) state.SessionInfo {
+		agent := state.AgentNameFromSession(sessionName)
+		return state.SessionInfo{AgentName: agent, Model: agentState.Model, Status: statuses[agent], Insertions: 10, Deletions: 2}
+	})
+
+	if len(runs) != 2 || runs[0].Run != "auth-refactor" || runs[1].Run != "docs" {
+		t.Fatalf("Expected the two runs sorted by name, got %+v", runs)
+	}
+	run := runs[0]
+	if len(run.Agents) != 2 || run.Agents[0].Agent != "john" || run.Agents[1].Agent != "sarah" {
+		t.Errorf("Expected john and sarah in the run, got %+v", run.Agents)
+	}
+	if run.Insertions != 20 || run.Deletions != 4 {
+		t.Errorf("Expected the diffs summed, got +%d -%d", run.Insertions, run.Deletions)
+	}
+
+	var out bytes.Buffer
+	printRun(&out, run)
+	want := `Run auth-refactor: 2 agents, 1 dead, 1 running, +20 -4
+
+AGENT  MODEL   STATUS   DIFF
+john   codex   dead     +10 -2
+sarah  claude  running  +10 -2
+`
+	if out.String() != want {
+		t.Errorf("printRun() =\n%s\nwant\n%s", out.String(), want)
+	}
+
+	out.Reset()
+	printRuns(&out, runs)
+	want = `RUN            AGENTS  STATUS             DIFF
+auth-refactor  2       1 dead, 1 running  +20 -4
+docs           1       1 ready            +10 -2
+`
+	if out.String() != want {
+		t.Errorf("printRuns() =\n%s\nwant\n%s", out.String(), want)
+	}
+
+	if _, ok := findRun(runs, "missing"); ok {
+		t.Error("Expected no run found for an unknown name")
+	}
+}
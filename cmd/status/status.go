@@ -0,0 +1,174 @@
+package status
+
+import (
+	"context"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"github.com/nehpz/claudicus/pkg/cmdctx"
+	"github.com/nehpz/claudicus/pkg/hosts"
+	"github.com/nehpz/claudicus/pkg/state"
+
+	"github.com/peterbourgon/ff/v3/ffcli"
+)
+
+var (
+	fs         = flag.NewFlagSet("uzi status", flag.ExitOnError)
+	tailLines  = fs.Int("lines", 10, "number of lines of the agent's pane to show")
+	jsonOutput = fs.Bool("json", false, "output the details in JSON format")
+	CmdStatus  = &ffcli.Command{
+		Name:       "status",
+		ShortUsage: "uzi status [--lines N] [--json] <agent-name>",
+		ShortHelp:  "Show everything about one agent session",
+		LongHelp: `Status prints one session in full: its saved state, tmux session, changed
+files, whether its dev server accepts connections, its last checkpoint, and
+the last lines of its agent pane. It is a quick check of one agent without
+opening the TUI; --json prints the same details as JSON.`,
+		FlagSet: fs,
+		Exec:    executeStatus,
+	}
+)
+
+func executeStatus(ctx context.Context, args []string) error {
+	if len(args) == 0 {
+		return fmt.Errorf("agent name argument is required")
+	}
+
+	sm, err := cmdctx.From(ctx).State()
+	if err != nil {
+		return err
+	}
+	sessionName, agentState, err := findAgent(sm, args[0])
+	if err != nil {
+		return err
+	}
+
+	aggregator := state.NewAggregator(state.WithRemoteProbe(hosts.RemoteProbe))
+	details := aggregator.GetSessionDetails(sessionName, agentState, *tailLines)
+	if cmdctx.From(ctx).WantJSON(*jsonOutput) {
+		encoder := json.NewEncoder(os.Stdout)
+		encoder.SetIndent("", "  ")
+		return encoder.Encode(details)
+	}
+	printDetails(os.Stdout, details)
+	return nil
+}
+
+// findAgent returns the session and saved state of the repository's agent
+// with the name
+func findAgent(sm *state.StateManager, agentName string) (string, state.AgentState, error) {
+	activeSessions, err := sm.GetActiveSessionsForRepo()
+	if err != nil {
+		return "", state.AgentState{}, fmt.Errorf("error getting active sessions: %w", err)
+	}
+	for _, session := range activeSessions {
+		if state.AgentNameFromSession(session) != agentName {
+			continue
+		}
+		agentState, err := sm.GetWorktreeInfo(session)
+		if err != nil {
+			return "", state.AgentState{}, err
+		}
+		return session, *agentState, nil
+	}
+	return "", state.AgentState{}, fmt.Errorf("no active session found for agent: %s", agentName)
+}
+
+// printDetails writes the details as aligned fields, followed by the changed
+// files and the pane tail
+func printDetails(out io.Writer, details state.SessionDetails) {
+	field := func(name, value string) {
+		if value != "" {
+			fmt.Fprintf(out, "%-12s %s\n", name+":", value)
+		}
+	}
+
+	field("Agent", details.AgentName)
+	field("Session", details.SessionName)
+	field("Status", details.Status)
+	field("Model", details.Model)
+	field("Mode", details.Mode)
+	branch := details.BranchName
+	if details.Adopted {
+		branch = details.BranchName + " (adopted)"
+	} else if details.BranchFrom != "" {
+		branch = fmt.Sprintf("%s (from %s)", details.BranchName, details.BranchFrom)
+	}
+	field("Branch", branch)
+	if details.BaseCommit != "" {
+		base := fmt.Sprintf("%s, %d %s since", shortCommit(details.BaseCommit),
+			details.CommitsAhead, plural(details.CommitsAhead, "commit", "commits"))
+		if details.BranchFrom != "" && !details.Adopted {
+			base += fmt.Sprintf("; %s has %d new %s", details.BranchFrom,
+				details.CommitsBehind, plural(details.CommitsBehind, "commit", "commits"))
+		}
+		field("Base", base)
+	}
+	field("Worktree", details.WorktreePath)
+	field("Host", details.Host)
+	field("Tags", strings.Join(details.Tags, ", "))
+	field("Run", details.Run)
+	field("Created", details.CreatedAt)
+	field("Updated", details.UpdatedAt)
+	field("Deadline", details.Deadline)
+
+	if details.Tmux != nil {
+		field("Tmux", fmt.Sprintf("%d %s (%s), %d attached",
+			len(details.Tmux.Windows), plural(len(details.Tmux.Windows), "window", "windows"),
+			strings.Join(details.Tmux.Windows, ", "), details.Tmux.Attached))
+	} else {
+		field("Tmux", "unavailable")
+	}
+
+	if details.DevServerURL != "" {
+		field("Dev server", fmt.Sprintf("%s (%s)", details.DevServerURL, details.DevServerHealth))
+	}
+
+	switch {
+	case details.CheckpointError != "":
+		field("Checkpoint", "failed: "+details.CheckpointError)
+	case details.LastCheckpoint != "":
+		field("Checkpoint", details.LastCheckpoint)
+	default:
+		field("Checkpoint", "never")
+	}
+
+	field("Changes", fmt.Sprintf("+%d -%d in %d %s", details.Insertions, details.Deletions,
+		len(details.Files), plural(len(details.Files), "file", "files")))
+	for _, file := range details.Files {
+		if file.Binary {
+			fmt.Fprintf(out, "  %-8s %s (binary)\n", file.Change, file.Path)
+			continue
+		}
+		fmt.Fprintf(out, "  %-8s %s +%d -%d\n", file.Change, file.Path, file.Insertions, file.Deletions)
+	}
+
+	if details.Prompt != "" {
+		fmt.Fprintf(out, "\nPrompt:\n  %s\n", strings.ReplaceAll(details.Prompt, "\n", "\n  "))
+	}
+	if len(details.PaneTail) > 0 {
+		fmt.Fprintln(out, "\nPane:")
+		for _, line := range details.PaneTail {
+			fmt.Fprintf(out, "  %s\n", line)
+		}
+	}
+}
+
+// shortCommit abbreviates a commit id as git does
+func shortCommit(commit string) string {
+	if len(commit) > 7 {
+		return commit[:7]
+	}
+	return commit
+}
+
+func plural(n int, one, many string) string {
+	if n == 1 {
+		return one
+	}
+	return many
+}
@@ -0,0 +1,86 @@
+package status
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"github.com/nehpz/claudicus/pkg/state"
+)
+
+func TestPrintDetails(t *testing.T) {
+	var out bytes.Buffer
+	printDetails(&out, state.SessionDetails{
+		SessionInfo: state.SessionInfo{
+			SessionName:  "agent-repo-abc123-sarah",
+			AgentName:    "sarah",
+			Status:       "running",
+			Model:        "claude",
+			Prompt:       "fix login\nand add a test",
+			Insertions:   4,
+			Deletions:    1,
+			WorktreePath: "/worktrees/sarah",
+			DevServerURL: "http://localhost:3001",
+			Tags:         []string{"auth", "bug"},
+			Files: []state.FileDiff{
+				{Path: "logo.png", Change: state.ChangeAdded, Binary: true},
+				{Path: "main.go", Change: state.ChangeModified, Insertions: 4, Deletions: 1},
+			},
+		},
+		BranchName:      "sarah",
+		BranchFrom:      "main",
+		Tmux:            &state.TmuxDetails{Attached: 1, Windows: []string{"agent", "uzi-dev"}},
+		PaneTail:        []string{"Editing main.go", "esc to interrupt"},
+		DevServerHealth: state.DevServerUp,
+		CheckpointError: "rebase conflict",
+	})
+
+	want := `Agent:       sarah
+Session:     agent-repo-abc123-sarah
+Status:      running
+Model:       claude
+Branch:      sarah (from main)
+Worktree:    /worktrees/sarah
+Tags:        auth, bug
+Tmux:        2 windows (agent, uzi-dev), 1 attached
+Dev server:  http://localhost:3001 (up)
+Checkpoint:  failed: rebase conflict
+Changes:     +4 -1 in 2 files
+  added    logo.png (binary)
+  modified main.go +4 -1
+
+Prompt:
+  fix login
+  and add a test
+
+Pane:
+  Editing main.go
+  esc to interrupt
+`
+	if out.String() != want {
+		t.Errorf("printDetails() = %q, want %q", out.String(), want)
+	}
+
+	out.Reset()
+	printDetails(&out, state.SessionDetails{SessionInfo: state.SessionInfo{AgentName: "emily", Status: "unknown"}})
+	for _, line := range []string{"Tmux:        unavailable", "Checkpoint:  never", "Changes:     +0 -0 in 0 files"} {
+		if !strings.Contains(out.String(), line) {
+			t.Errorf("Expected %q for a session that can't be inspected, got %q", line, out.String())
+		}
+	}
+	if strings.Contains(out.String(), "Pane:") || strings.Contains(out.String(), "Dev server:") || strings.Contains(out.String(), "Base:") {
+		t.Errorf("Expected empty sections left out, got %q", out.String())
+	}
+
+	out.Reset()
+	printDetails(&out, state.SessionDetails{
+		BranchName:    "sarah",
+		BranchFrom:    "main",
+		BaseCommit:    "3f2a9c1e8b7d6a5f4e3d2c1b0a9f8e7d6c5b4a39",
+		CommitsAhead:  1,
+		CommitsBehind: 5,
+	})
+	if line := "Base:        3f2a9c1, 1 commit since; main has 5 new commits\n"; !strings.Contains(out.String(), line) {
+		t.Errorf("Expected %q for a pinned session, got %q", line, out.String())
+	}
+}
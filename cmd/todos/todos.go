@@ -0,0 +1,125 @@
+package todos
+
+import (
+	"context"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+
+	"github.com/nehpz/claudicus/pkg/cmdctx"
+	"github.com/nehpz/claudicus/pkg/scrollback"
+
+	"github.com/charmbracelet/log"
+	"github.com/peterbourgon/ff/v3/ffcli"
+)
+
+var (
+	fs            = flag.NewFlagSet("uzi todos", flag.ExitOnError)
+	lines         = fs.Int("lines", 0, "read only the last N lines of each pane's history (0 reads all of it)")
+	noTranscripts = fs.Bool("no-transcripts", false, "read only tmux pane history, not agent transcripts")
+	jsonOutput    = fs.Bool("json", false, "output in JSON format")
+	CmdTodos      = &ffcli.Command{
+		Name:       "todos",
+		ShortUsage: "uzi todos [--lines N] [--no-transcripts] [--json] [agent-name]",
+		ShortHelp:  "List the outstanding items agents have mentioned",
+		LongHelp: `The todos command collects what each agent believes remains to be done
+from its output: TODO and FIXME markers it printed, and the entries of lists
+under headings such as "Next steps", "Remaining work", or "Follow-ups".
+Checked checkbox entries are left out, and an item an agent repeated is
+listed once.
+
+Like uzi grep, it reads the history of each agent's tmux pane, as far back
+as tmux keeps it (--lines limits that), and the transcripts the claude CLI
+keeps of local sessions under ~/.claude/projects. Give an agent name to list
+only that agent's items.`,
+		FlagSet: fs,
+		Exec:    executeTodos,
+	}
+)
+
+func executeTodos(ctx context.Context, args []string) error {
+	if len(args) > 1 {
+		return fmt.Errorf("at most one agent name is accepted")
+	}
+
+	sm, err := cmdctx.From(ctx).State()
+	if err != nil {
+		return err
+	}
+	activeSessions, err := sm.GetActiveSessionsForRepo()
+	if err != nil {
+		return fmt.Errorf("error getting active sessions: %w", err)
+	}
+	agentName := ""
+	if len(args) == 1 {
+		agentName = args[0]
+	}
+	sessionNames, err := scrollback.SelectSessions(activeSessions, agentName)
+	if err != nil {
+		return err
+	}
+
+	home := ""
+	if !*noTranscripts {
+		if home, err = os.UserHomeDir(); err != nil {
+			log.Debug("Skipping transcripts", "error", err)
+		}
+	}
+	var sessions []scrollback.Session
+	for _, sessionName := range sessionNames {
+		agentState, err := sm.GetWorktreeInfo(sessionName)
+		if err != nil {
+			log.Warn("Skipping session without state", "session", sessionName, "error", err)
+			continue
+		}
+		sessions = append(sessions, scrollback.NewSession(sessionName, *agentState, home))
+	}
+
+	todos := collect(sessions, *lines)
+	if cmdctx.From(ctx).WantJSON(*jsonOutput) {
+		encoder := json.NewEncoder(os.Stdout)
+		encoder.SetIndent("", "  ")
+		if todos == nil {
+			todos = []scrollback.Todo{}
+		}
+		return encoder.Encode(todos)
+	}
+	printTodos(os.Stdout, todos)
+	return nil
+}
+
+// collect returns the items of every session in order. Sessions that can't
+// be read, such as one whose tmux session just ended, are skipped with a
+// warning.
+func collect(sessions []scrollback.Session, lines int) []scrollback.Todo {
+	var todos []scrollback.Todo
+	for _, session := range sessions {
+		sessionTodos, err := scrollback.Todos(session, lines)
+		if err != nil {
+			log.Warn("Skipping session", "session", session.Name, "error", err)
+			continue
+		}
+		todos = append(todos, sessionTodos...)
+	}
+	return todos
+}
+
+// printTodos prints the items grouped under each agent, with where each was
+// found
+func printTodos(out io.Writer, todos []scrollback.Todo) {
+	if len(todos) == 0 {
+		fmt.Fprintln(out, "No outstanding items found")
+		return
+	}
+	for i, todo := range todos {
+		if i == 0 || todo.Session != todos[i-1].Session {
+			if i > 0 {
+				fmt.Fprintln(out)
+			}
+			fmt.Fprintf(out, "%s:\n", todo.Agent)
+		}
+		fmt.Fprintf(out, "  [%s] %s  (%s)\n", todo.Kind, todo.Text, todo.Location())
+	}
+}
@@ -0,0 +1,53 @@
+package todos
+
+import (
+	"bytes"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/nehpz/claudicus/pkg/scrollback"
+)
+
+// paneExecutor prints a fixed pane history, or fails like a tmux session that
+// just ended
+type paneExecutor struct {
+	pane string
+	err  error
+}
+
+func (e paneExecutor) ExecuteCommand(name string, args ...string) ([]byte, error) {
+	return []byte(e.pane), e.err
+}
+
+func (e paneExecutor) RunCommand(name string, args ...string) error {
+	return e.err
+}
+
+func TestCollectAndPrint(t *testing.T) {
+	sessions := []scrollback.Session{
+		{Name: "agent-repo-abc123-emily", Executor: paneExecutor{err: errors.New("can't find session")}},
+		{Name: "agent-repo-abc123-john", Executor: paneExecutor{pane: "FIXME: flaky login test\n"}},
+		{Name: "agent-repo-abc123-sarah", Executor: paneExecutor{pane: "Next steps:\n- add pagination\n- update the docs\n"}},
+	}
+	todos := collect(sessions, 0)
+
+	var out bytes.Buffer
+	printTodos(&out, todos)
+	want := `john:
+  [FIXME] flaky login test  (pane:1)
+
+sarah:
+  [next step] add pagination  (pane:2)
+  [next step] update the docs  (pane:3)
+`
+	if got := out.String(); got != want {
+		t.Errorf("printTodos() = %q, want %q", got, want)
+	}
+
+	out.Reset()
+	printTodos(&out, nil)
+	if !strings.Contains(out.String(), "No outstanding items") {
+		t.Errorf("printTodos(nil) = %q", out.String())
+	}
+}
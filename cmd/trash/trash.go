@@ -0,0 +1,330 @@
+package trash
+
+import (
+	"context"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"text/tabwriter"
+	"time"
+
+	"github.com/nehpz/claudicus/cmd/prompt"
+	"github.com/nehpz/claudicus/pkg/cmdctx"
+	"github.com/nehpz/claudicus/pkg/config"
+	"github.com/nehpz/claudicus/pkg/state"
+	"github.com/nehpz/claudicus/pkg/trash"
+
+	"github.com/charmbracelet/log"
+	"github.com/peterbourgon/ff/v3/ffcli"
+)
+
+var (
+	fs             = flag.NewFlagSet("uzi trash", flag.ExitOnError)
+	jsonOutput     = fs.Bool("json", false, "output in JSON format")
+	listConfigPath = fs.String("config", config.GetDefaultConfigPath(), "path to config file")
+	restoreFs      = flag.NewFlagSet("uzi trash restore", flag.ExitOnError)
+	restoreConfig  = restoreFs.String("config", config.GetDefaultConfigPath(), "path to config file")
+	purgeFs        = flag.NewFlagSet("uzi trash purge", flag.ExitOnError)
+	purgeAll       = purgeFs.Bool("all", false, "purge every killed agent of this repository")
+	CmdTrash       = &ffcli.Command{
+		Name:       "trash",
+		ShortUsage: "uzi trash [--json] | uzi trash <restore|purge>",
+		ShortHelp:  "List, restore, or purge killed agents",
+		LongHelp: `uzi kill moves local agents to the trash instead of deleting them: the tmux
+session is killed, but the worktree, with its branch and uncommitted work,
+and the agent's state are kept for trashRetention (24h unless set in
+uzi.yaml). Agents older than that are purged on the next kill or trash
+command.
+
+Without a subcommand, the killed agents of this repository are listed.`,
+		FlagSet: fs,
+		Subcommands: []*ffcli.Command{
+			{
+				Name:       "restore",
+				ShortUsage: "uzi trash restore [--config uzi.yaml] <agent-name|#id>...",
+				ShortHelp:  "Bring killed agents back",
+				LongHelp: `Move the worktree of each agent back where it was and start the agent
+again in a new tmux session, with its original command and prompt. Given an
+agent name killed more than once, the most recent kill is restored.`,
+				FlagSet: restoreFs,
+				Exec: func(ctx context.Context, args []string) error {
+					if len(args) == 0 {
+						return fmt.Errorf("usage: uzi trash restore <agent-name|#id>...")
+					}
+					return executeRestore(ctx, args, *restoreConfig)
+				},
+			},
+			{
+				Name:       "purge",
+				ShortUsage: "uzi trash purge [--all] [<agent-name|#id>...]",
+				ShortHelp:  "Delete killed agents for good",
+				FlagSet:    purgeFs,
+				Exec:       executePurge,
+			},
+		},
+		Exec: executeList,
+	}
+
+	undoFs     = flag.NewFlagSet("uzi undo", flag.ExitOnError)
+	undoConfig = undoFs.String("config", config.GetDefaultConfigPath(), "path to config file")
+	CmdUndo    = &ffcli.Command{
+		Name:       "undo",
+		ShortUsage: "uzi undo [--config uzi.yaml]",
+		ShortHelp:  "Restore the agent killed last",
+		LongHelp: `Bring back the agent of this repository that uzi kill moved to the trash
+most recently, like uzi trash restore. Run it again to restore the one killed
+before that.`,
+		FlagSet: undoFs,
+		Exec:    executeUndo,
+	}
+)
+
+// entryJSON is an entry of `uzi trash --json`
+type entryJSON struct {
+	ID        int    `json:"id"`
+	Agent     string `json:"agent"`
+	Session   string `json:"session"`
+	Model     string `json:"model"`
+	Branch    string `json:"branch,omitempty"`
+	Prompt    string `json:"prompt"`
+	KilledAt  string `json:"killed_at"`
+	ExpiresAt string `json:"expires_at"`
+}
+
+// openTrash opens the trash, purges the agents kept longer than the retention
+// from uzi.yaml, and returns the current repository
+func openTrash(ctx context.Context, configPath string) (*trash.Store, string, time.Duration, error) {
+	bin, err := trash.NewStore()
+	if err != nil {
+		return nil, "", 0, err
+	}
+	sm, err := cmdctx.From(ctx).State()
+	if err != nil {
+		return nil, "", 0, err
+	}
+	// Without a config file the default retention is used
+	cfg, _ := cmdctx.From(ctx).LoadConfig(configPath)
+	retention := cfg.TrashRetention()
+	if retention > 0 {
+		if _, err := bin.PurgeExpired(ctx, retention, time.Now()); err != nil {
+			log.Warn("Failed to purge expired agents from the trash", "error", err)
+		}
+	}
+	return bin, sm.GitRepo(), retention, nil
+}
+
+func executeList(ctx context.Context, args []string) error {
+	bin, repo, retention, err := openTrash(ctx, *listConfigPath)
+	if err != nil {
+		return err
+	}
+	entries, err := bin.ListRepo(repo)
+	if err != nil {
+		return err
+	}
+	if cmdctx.From(ctx).WantJSON(*jsonOutput) {
+		return printJSON(os.Stdout, entries, retention)
+	}
+	printEntries(os.Stdout, entries, retention, time.Now())
+	return nil
+}
+
+// printEntries lists killed agents, oldest first, with how long ago they were
+// killed and how long until they are purged
+func printEntries(out io.Writer, entries []trash.Entry, retention time.Duration, now time.Time) {
+	if len(entries) == 0 {
+		fmt.Fprintln(out, "No killed agents in the trash")
+		return
+	}
+	w := tabwriter.NewWriter(out, 0, 0, 2, ' ', 0)
+	fmt.Fprintf(w, "ID\tAGENT\tMODEL\tKILLED\tPURGED IN\tPROMPT\n")
+	for _, entry := range entries {
+		purgedIn := "-"
+		if retention > 0 {
+			purgedIn = formatAge(entry.KilledAt.Add(retention).Sub(now))
+		}
+		fmt.Fprintf(w, "#%d\t%s\t%s\t%s ago\t%s\t%s\n", entry.ID, entry.Agent(), entry.State.Model,
+			formatAge(now.Sub(entry.KilledAt)), purgedIn, entry.State.Prompt)
+	}
+	w.Flush()
+}
+
+// formatAge renders a duration to the largest whole unit
+func formatAge(d time.Duration) string {
+	switch {
+	case d < time.Minute:
+		return fmt.Sprintf("%ds", max(int(d.Seconds()), 0))
+	case d < time.Hour:
+		return fmt.Sprintf("%dm", int(d.Minutes()))
+	default:
+		return fmt.Sprintf("%dh", int(d.Hours()))
+	}
+}
+
+func printJSON(out io.Writer, entries []trash.Entry, retention time.Duration) error {
+	list := make([]entryJSON, 0, len(entries))
+	for _, entry := range entries {
+		list = append(list, entryJSON{
+			ID:        entry.ID,
+			Agent:     entry.Agent(),
+			Session:   entry.Session,
+			Model:     entry.State.Model,
+			Branch:    entry.State.BranchName,
+			Prompt:    entry.State.Prompt,
+			KilledAt:  entry.KilledAt.Format(time.RFC3339),
+			ExpiresAt: entry.KilledAt.Add(retention).Format(time.RFC3339),
+		})
+	}
+	encoder := json.NewEncoder(out)
+	encoder.SetIndent("", "  ")
+	return encoder.Encode(list)
+}
+
+func executeRestore(ctx context.Context, args []string, configPath string) error {
+	bin, repo, _, err := openTrash(ctx, configPath)
+	if err != nil {
+		return err
+	}
+	sm, err := cmdctx.From(ctx).State()
+	if err != nil {
+		return err
+	}
+	for _, arg := range args {
+		entry, ok, err := bin.Find(repo, arg)
+		if err != nil {
+			return err
+		}
+		if !ok {
+			return fmt.Errorf("no killed agent %s in the trash", arg)
+		}
+		if err := restore(ctx, sm, bin, entry, configPath); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func executeUndo(ctx context.Context, args []string) error {
+	bin, repo, _, err := openTrash(ctx, *undoConfig)
+	if err != nil {
+		return err
+	}
+	entries, err := bin.ListRepo(repo)
+	if err != nil {
+		return err
+	}
+	if len(entries) == 0 {
+		return fmt.Errorf("nothing to undo: no killed agents in the trash")
+	}
+	sm, err := cmdctx.From(ctx).State()
+	if err != nil {
+		return err
+	}
+	return restore(ctx, sm, bin, entries[len(entries)-1], *undoConfig)
+}
+
+// restore moves a killed agent's worktree back, starts the agent again in a
+// new tmux session, and saves its state. If the agent can't be started, its
+// worktree goes back to the trash.
+func restore(ctx context.Context, sm *state.StateManager, bin *trash.Store, entry trash.Entry, configPath string) error {
+	agentName := entry.Agent()
+	lock, err := sm.LockSession(entry.Session, "restore")
+	if err != nil {
+		return err
+	}
+	defer lock.Release()
+
+	if exec.CommandContext(ctx, "tmux", "has-session", "-t", entry.Session).Run() == nil {
+		return fmt.Errorf("cannot restore %s: a tmux session named %s is running", agentName, entry.Session)
+	}
+	if _, err := sm.GetWorktreeInfo(entry.Session); err == nil {
+		return fmt.Errorf("cannot restore %s: session %s already exists", agentName, entry.Session)
+	}
+
+	if entry.TrashPath != "" {
+		if _, err := os.Stat(entry.State.WorktreePath); err == nil {
+			return fmt.Errorf("cannot restore %s: %s already exists", agentName, entry.State.WorktreePath)
+		}
+		if err := trash.MoveWorktree(ctx, entry.TrashPath, entry.State.WorktreePath); err != nil {
+			return fmt.Errorf("failed to restore the worktree of %s: %w", agentName, err)
+		}
+	}
+	moveBack := func() {
+		if entry.TrashPath == "" {
+			return
+		}
+		if err := trash.MoveWorktree(ctx, entry.State.WorktreePath, entry.TrashPath); err != nil {
+			log.Error("Could not move the worktree back to the trash", "path", entry.State.WorktreePath, "error", err)
+		}
+	}
+
+	port, err := prompt.Restart(ctx, configPath, entry.Session, entry.State)
+	if err != nil {
+		moveBack()
+		return fmt.Errorf("failed to start %s: %w", agentName, err)
+	}
+
+	restored := entry.State
+	restored.Port = port
+	if err := sm.RestoreState(entry.Session, restored); err != nil {
+		if killErr := exec.CommandContext(ctx, "tmux", "kill-session", "-t", entry.Session).Run(); killErr != nil {
+			log.Error("Could not kill the restarted session", "session", entry.Session, "error", killErr)
+		}
+		moveBack()
+		return fmt.Errorf("failed to save the state of %s: %w", agentName, err)
+	}
+	if _, err := bin.Remove(entry.ID); err != nil {
+		log.Warn("Restored agent is still listed in the trash", "agent", agentName, "error", err)
+	}
+	fmt.Printf("Restored agent: %s\n", agentName)
+	return nil
+}
+
+func executePurge(ctx context.Context, args []string) error {
+	if len(args) == 0 && !*purgeAll {
+		return fmt.Errorf("usage: uzi trash purge [--all] [<agent-name|#id>...]")
+	}
+	if len(args) > 0 && *purgeAll {
+		return fmt.Errorf("give either agent names or --all, not both")
+	}
+	bin, err := trash.NewStore()
+	if err != nil {
+		return err
+	}
+	sm, err := cmdctx.From(ctx).State()
+	if err != nil {
+		return err
+	}
+	repo := sm.GitRepo()
+
+	var entries []trash.Entry
+	if *purgeAll {
+		if entries, err = bin.ListRepo(repo); err != nil {
+			return err
+		}
+	}
+	for _, arg := range args {
+		entry, ok, err := bin.Find(repo, arg)
+		if err != nil {
+			return err
+		}
+		if !ok {
+			return fmt.Errorf("no killed agent %s in the trash", arg)
+		}
+		entries = append(entries, entry)
+	}
+
+	for _, entry := range entries {
+		if err := bin.Purge(ctx, entry); err != nil {
+			return fmt.Errorf("failed to purge %s: %w", entry.Agent(), err)
+		}
+		fmt.Printf("Purged agent: %s\n", entry.Agent())
+	}
+	if len(entries) == 0 {
+		fmt.Println("No killed agents in the trash")
+	}
+	return nil
+}
@@ -0,0 +1,75 @@
+package trash
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/nehpz/claudicus/pkg/state"
+	"github.com/nehpz/claudicus/pkg/trash"
+)
+
+func TestPrintEntries(t *testing.T) {
+	now := time.Unix(1700000000, 0)
+	entries := []trash.Entry{
+		{ID: 3, Session: "agent-app-abc123-sarah", State: state.AgentState{Model: "claude", Prompt: "fix the login bug"}, KilledAt: now.Add(-90 * time.Second)},
+		{ID: 4, Session: "agent-app-abc123-bob", State: state.AgentState{Model: "codex", Prompt: "write tests"}, KilledAt: now.Add(-2 * time.Hour)},
+	}
+
+	var out bytes.Buffer
+	printEntries(&out, entries, 24*time.Hour, now)
+	lines := strings.Split(strings.TrimSpace(out.String()), "\n")
+	if len(lines) != 3 {
+		t.Fatalf("Expected a header and 2 entries, got %q", out.String())
+	}
+	if fields := strings.Fields(lines[1]); len(fields) < 6 || fields[0] != "#3" || fields[1] != "sarah" || fields[3] != "1m" || fields[5] != "23h" {
+		t.Errorf("Unexpected first entry: %q", lines[1])
+	}
+	if !strings.Contains(lines[2], "2h ago") || !strings.Contains(lines[2], "22h") || !strings.HasSuffix(lines[2], "write tests") {
+		t.Errorf("Unexpected second entry: %q", lines[2])
+	}
+
+	out.Reset()
+	printEntries(&out, nil, 24*time.Hour, now)
+	if out.String() != "No killed agents in the trash\n" {
+		t.Errorf("Unexpected output for an empty trash: %q", out.String())
+	}
+}
+
+func TestPrintJSON(t *testing.T) {
+	var out bytes.Buffer
+	if err := printJSON(&out, nil, time.Hour); err != nil {
+		t.Fatal(err)
+	}
+	if strings.TrimSpace(out.String()) != "[]" {
+		t.Errorf("Expected an empty list, got %q", out.String())
+	}
+
+	out.Reset()
+	killedAt := time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC)
+	entry := trash.Entry{ID: 1, Session: "agent-app-abc123-sarah", State: state.AgentState{Model: "claude", BranchName: "sarah-app"}, KilledAt: killedAt}
+	if err := printJSON(&out, []trash.Entry{entry}, 24*time.Hour); err != nil {
+		t.Fatal(err)
+	}
+	var list []entryJSON
+	if err := json.Unmarshal(out.Bytes(), &list); err != nil {
+		t.Fatal(err)
+	}
+	if len(list) != 1 || list[0].Agent != "sarah" || list[0].Branch != "sarah-app" || list[0].ExpiresAt != "2024-05-02T12:00:00Z" {
+		t.Errorf("Unexpected JSON: %+v", list)
+	}
+}
+
+func TestExecutePurgeUsage(t *testing.T) {
+	if err := executePurge(context.Background(), nil); err == nil || !strings.Contains(err.Error(), "usage") {
+		t.Errorf("Expected a usage error without agents or --all, got %v", err)
+	}
+	*purgeAll = true
+	defer func() { *purgeAll = false }()
+	if err := executePurge(context.Background(), []string{"sarah"}); err == nil {
+		t.Error("Expected agents and --all together to be rejected")
+	}
+}
@@ -5,11 +5,14 @@ package tui
 
 import (
 	"context"
+	"errors"
 	"flag"
 	"fmt"
+	"io"
 	"os"
 
 	tea "github.com/charmbracelet/bubbletea"
+	"github.com/nehpz/claudicus/pkg/cmdctx"
 	"github.com/nehpz/claudicus/pkg/config"
 	"github.com/nehpz/claudicus/pkg/tui"
 	"github.com/peterbourgon/ff/v3/ffcli"
@@ -19,9 +22,14 @@ import (
 var (
 	fs         = flag.NewFlagSet("uzi tui", flag.ExitOnError)
 	configPath = fs.String("config", config.GetDefaultConfigPath(), "path to config file")
+	noColor    = fs.Bool("no-color", false, "render without colors (also enabled by the NO_COLOR environment variable)")
+	plain      = fs.Bool("plain", false, "screen-reader friendly mode: no colors, no unicode glyphs, text status labels")
+	scriptPath = fs.String("script", "", "run headless, feeding the actions in this file (- for stdin) and printing rendered frames")
+	width      = fs.Int("width", tui.DefaultScriptWidth, "terminal width for --script")
+	height     = fs.Int("height", tui.DefaultScriptHeight, "terminal height for --script")
 	CmdTui     = &ffcli.Command{
 		Name:       "tui",
-		ShortUsage: "uzi tui",
+		ShortUsage: "uzi tui [--no-color] [--plain] [--script actions.txt]",
 		ShortHelp:  "Launch the interactive TUI interface",
 		LongHelp: `Launch the interactive Terminal User Interface (TUI) for managing agent sessions.
 
@@ -35,10 +43,25 @@ Navigation:
 - Use arrow keys or vim-style keys (h/j/k/l) to navigate
 - Press Enter to select an item
 - Press 'q' to quit
-- Press '?' for help`,
+- Press '?' for help
+
+Accessibility:
+- --no-color (or NO_COLOR) drops colors but keeps the layout
+- --plain also replaces glyphs with text labels, removes borders,
+  and stacks the split view vertically for screen readers
+
+Scripting:
+- --script actions.txt runs the TUI without a terminal, one action per line:
+    resize 100 30       set the terminal size (--width and --height, 120x40)
+    key j j enter       press keys by name (enter, esc, up, ctrl+p, alt+x, space)
+    type fix the tests  type text into the open prompt
+    wait 500ms          let sessions load and background work finish
+    snapshot list       print the rendered frame under "--- list ---"
+  The final frame is always printed, so the output can be compared
+  against a golden file or replayed for a demo. --script - reads stdin.`,
 		FlagSet: fs,
 		Exec: func(ctx context.Context, args []string) error {
-			return Run()
+			return Run(ctx)
 		},
 	}
 )
@@ -51,20 +74,43 @@ func isTerminal() bool {
 }
 
 // Run launches the TUI interface
-func Run() error {
+func Run(ctx context.Context) error {
+	if *scriptPath != "" {
+		return runScript(ctx, *scriptPath)
+	}
+
 	// Check if we're in a terminal environment
 	if !isTerminal() {
 		return fmt.Errorf("TUI requires a terminal environment")
 	}
 
-	// TODO: Load configuration
-	_ = configPath
+	// Use the UziCLI of the command context
+	uziCLI := cmdctx.From(ctx).Uzi()
+	if err := uziCLI.CheckTmux(); err != nil {
+		return err
+	}
+
+	// Make sure the uzi binary on PATH speaks the same session schema
+	if err := uziCLI.CheckVersion(); errors.Is(err, tui.ErrVersionMismatch) {
+		fmt.Fprintf(os.Stderr, "uzi tui: warning: %v\nFalling back to reading session state directly.\n", err)
+	}
 
-	// Create a UziCLI instance
-	uziCLI := tui.NewUziCLI()
+	if tui.ColorDisabled(*noColor) || *plain {
+		tui.DisableColor()
+	}
 
 	// Create the TUI application
 	app := tui.NewApp(uziCLI)
+	if *plain {
+		app.SetTheme(tui.PlainTheme())
+	}
+
+	// Reload uzi.yaml into the running TUI whenever it changes
+	if err := app.WatchConfig(*configPath); err != nil {
+		fmt.Fprintf(os.Stderr, "uzi tui: warning: not watching %s for changes: %v\n", *configPath, err)
+	}
+	// Walk new users through the core keys; scripted runs never show it
+	app.ShowTutorialOnFirstRun()
 
 	// Create the Bubble Tea program with more conservative options
 	program := tea.NewProgram(
@@ -74,16 +120,65 @@ func Run() error {
 	)
 
 	// Run the program
-	if _, err := program.Run(); err != nil {
+	_, err := program.Run()
+
+	// Background checkpoints and kills keep running after the screen closes
+	if n := app.PendingJobs(); n > 0 {
+		fmt.Fprintf(os.Stderr, "uzi tui: waiting for %d background job(s) to finish\n", n)
+	}
+	app.Cleanup()
+
+	if err != nil {
 		return fmt.Errorf("error running TUI: %w", err)
 	}
+	return nil
+}
 
+// runScript runs the TUI headless through the actions in path, printing
+// frames to stdout
+func runScript(ctx context.Context, path string) error {
+	if *width <= 0 || *height <= 0 {
+		return fmt.Errorf("invalid size %dx%d: --width and --height must be positive", *width, *height)
+	}
+	input := io.Reader(os.Stdin)
+	if path != "-" {
+		file, err := os.Open(path)
+		if err != nil {
+			return err
+		}
+		defer file.Close()
+		input = file
+	}
+	steps, err := tui.ParseScript(input)
+	if err != nil {
+		return fmt.Errorf("%s: %w", path, err)
+	}
+
+	if tui.ColorDisabled(*noColor) || *plain {
+		tui.DisableColor()
+	}
+	uziCLI := cmdctx.From(ctx).Uzi()
+	if err := uziCLI.CheckTmux(); err != nil {
+		return err
+	}
+	app := tui.NewApp(uziCLI)
+	if *plain {
+		app.SetTheme(tui.PlainTheme())
+	}
+	if err := app.WatchConfig(*configPath); err != nil {
+		fmt.Fprintf(os.Stderr, "uzi tui: warning: not loading %s: %v\n", *configPath, err)
+	}
+	defer app.Cleanup()
+
+	if err := tui.RunScript(app, steps, os.Stdout, *width, *height); err != nil {
+		return fmt.Errorf("error running TUI script: %w", err)
+	}
 	return nil
 }
 
 // main function for standalone execution (if needed for testing)
 func main() {
-	if err := Run(); err != nil {
+	if err := Run(context.Background()); err != nil {
 		fmt.Fprintf(os.Stderr, "uzi tui: error: %v\n", err)
 		os.Exit(1)
 	}
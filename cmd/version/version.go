@@ -0,0 +1,38 @@
+package version
+
+import (
+	"context"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+
+	"github.com/nehpz/claudicus/pkg/cmdctx"
+	"github.com/nehpz/claudicus/pkg/version"
+
+	"github.com/peterbourgon/ff/v3/ffcli"
+)
+
+var (
+	fs         = flag.NewFlagSet("uzi version", flag.ExitOnError)
+	jsonOutput = fs.Bool("json", false, "output in JSON format")
+	CmdVersion = &ffcli.Command{
+		Name:       "version",
+		ShortUsage: "uzi version [--json]",
+		ShortHelp:  "Print the uzi version and session schema version",
+		FlagSet:    fs,
+		Exec: func(ctx context.Context, args []string) error {
+			return printVersion(os.Stdout, version.Current(), cmdctx.From(ctx).WantJSON(*jsonOutput))
+		},
+	}
+)
+
+// printVersion writes the version information in text or JSON form
+func printVersion(w io.Writer, info version.Info, asJSON bool) error {
+	if asJSON {
+		return json.NewEncoder(w).Encode(info)
+	}
+	_, err := fmt.Fprintf(w, "uzi %s (schema %d)\n", info.Version, info.SchemaVersion)
+	return err
+}
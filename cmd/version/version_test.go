@@ -0,0 +1,42 @@
+package version
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/nehpz/claudicus/pkg/version"
+)
+
+func TestPrintVersion(t *testing.T) {
+	info := version.Info{Version: "v1.2.3", SchemaVersion: 2}
+
+	tests := []struct {
+		name   string
+		asJSON bool
+		want   string
+	}{
+		{"text", false, "uzi v1.2.3 (schema 2)\n"},
+		{"json", true, "{\"version\":\"v1.2.3\",\"schema_version\":2}\n"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var buf bytes.Buffer
+			if err := printVersion(&buf, info, tt.asJSON); err != nil {
+				t.Fatalf("printVersion() error = %v", err)
+			}
+			if buf.String() != tt.want {
+				t.Errorf("printVersion() = %q, want %q", buf.String(), tt.want)
+			}
+		})
+	}
+}
+
+func TestCmdVersion(t *testing.T) {
+	if CmdVersion.Name != "version" {
+		t.Errorf("CmdVersion.Name = %v, want version", CmdVersion.Name)
+	}
+	if CmdVersion.FlagSet.Lookup("json") == nil {
+		t.Error("CmdVersion should define a json flag")
+	}
+}
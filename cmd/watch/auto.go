@@ -14,17 +14,49 @@ import (
 	"syscall"
 	"time"
 
+	"github.com/nehpz/claudicus/pkg/cmdctx"
+	"github.com/nehpz/claudicus/pkg/config"
+	"github.com/nehpz/claudicus/pkg/heartbeat"
 	"github.com/nehpz/claudicus/pkg/state"
+	"github.com/nehpz/claudicus/pkg/tmuxops"
 
 	"github.com/charmbracelet/log"
 	"github.com/peterbourgon/ff/v3/ffcli"
 )
 
+// Actions `uzi auto` can take when a session exceeds its runtime budget
+const (
+	TimeoutWarn  = "warn"  // only log that the budget is exhausted
+	TimeoutPause = "pause" // interrupt the agent with Escape and mark it paused
+	TimeoutKill  = "kill"  // kill the session like `uzi kill`
+)
+
+// budgetWarnFraction is the share of the runtime budget after which a warning is logged
+const budgetWarnFraction = 0.8
+
+// budgetStage is how far a session has progressed through its runtime budget
+type budgetStage int
+
+const (
+	budgetOK budgetStage = iota
+	budgetWarning
+	budgetExceeded
+)
+
 type AgentWatcher struct {
-	stateManager    *state.StateManager
-	watchedSessions map[string]*SessionMonitor
-	mu              sync.RWMutex
-	quit            chan bool
+	stateManager      *state.StateManager
+	watchedSessions   map[string]*SessionMonitor
+	budgetStages      map[string]budgetStage // last budget stage acted on per session
+	paused            map[string]bool        // sessions stopped by `uzi pause`, left alone until resumed
+	onTimeout         string
+	onPortConflict    string
+	ports             *portChecker
+	devConfig         *config.Config   // uzi.yaml, for reassigning ports; nil unless reassigning
+	heartbeats        *heartbeat.Store // nil when heartbeats are disabled
+	heartbeatInterval time.Duration
+	paneTails         map[string]string // last pane content read from each live session, for snapshots
+	mu                sync.RWMutex
+	quit              chan bool
 }
 
 type SessionMonitor struct {
@@ -35,10 +67,15 @@ type SessionMonitor struct {
 	noUpdateCount  int
 }
 
-func NewAgentWatcher() *AgentWatcher {
+func NewAgentWatcher(sm *state.StateManager) *AgentWatcher {
 	return &AgentWatcher{
-		stateManager:    state.NewStateManager(),
+		stateManager:    sm,
 		watchedSessions: make(map[string]*SessionMonitor),
+		budgetStages:    make(map[string]budgetStage),
+		paused:          make(map[string]bool),
+		onTimeout:       TimeoutWarn,
+		onPortConflict:  PortConflictWarn,
+		ports:           newPortChecker(),
 		quit:            make(chan bool),
 	}
 }
@@ -49,7 +86,7 @@ func (aw *AgentWatcher) hashContent(content []byte) []byte {
 }
 
 func (aw *AgentWatcher) capturePaneContent(sessionName string) (string, error) {
-	cmd := exec.Command("tmux", "capture-pane", "-t", sessionName+":agent", "-p")
+	cmd := exec.Command("tmux", "capture-pane", "-t", tmuxops.AgentTarget(sessionName), "-p")
 	output, err := cmd.Output()
 	if err != nil {
 		return "", err
@@ -58,7 +95,7 @@ func (aw *AgentWatcher) capturePaneContent(sessionName string) (string, error) {
 }
 
 func (aw *AgentWatcher) sendKeys(sessionName string, keys string) error {
-	cmd := exec.Command("tmux", "send-keys", "-t", sessionName+":agent", keys)
+	cmd := exec.Command("tmux", "send-keys", "-t", tmuxops.AgentTarget(sessionName), keys)
 	return cmd.Run()
 }
 
@@ -129,6 +166,14 @@ func (aw *AgentWatcher) watchSession(sessionName string) {
 		case <-aw.quit:
 			return
 		default:
+			aw.mu.RLock()
+			paused := aw.paused[sessionName]
+			aw.mu.RUnlock()
+			if paused {
+				time.Sleep(2 * time.Second)
+				continue
+			}
+
 			updated, hasPrompt, err := aw.hasUpdated(sessionName)
 			if err != nil {
 				log.Error("Error checking session update", "session", sessionName, "error", err)
@@ -175,6 +220,7 @@ func (aw *AgentWatcher) refreshActiveSessions() error {
 		if !found {
 			log.Info("Session no longer active, stopping watch", "session", sessionName)
 			delete(aw.watchedSessions, sessionName)
+			delete(aw.budgetStages, sessionName)
 		}
 	}
 	aw.mu.Unlock()
@@ -189,9 +235,100 @@ func (aw *AgentWatcher) refreshActiveSessions() error {
 		}
 	}
 
+	aw.checkBudgets(aw.updatePaused(activeSessions), time.Now())
+	aw.checkPorts(activeSessions)
+
 	return nil
 }
 
+// updatePaused records which active sessions are paused and returns the rest.
+// Paused sessions get no confirmations pressed and no timeout actions until
+// they are resumed.
+func (aw *AgentWatcher) updatePaused(activeSessions []string) []string {
+	paused := make(map[string]bool)
+	var running []string
+	for _, sessionName := range activeSessions {
+		if agentState, err := aw.stateManager.GetWorktreeInfo(sessionName); err == nil && agentState.Paused {
+			paused[sessionName] = true
+			continue
+		}
+		running = append(running, sessionName)
+	}
+
+	aw.mu.Lock()
+	aw.paused = paused
+	aw.mu.Unlock()
+	return running
+}
+
+// runtimeBudgetStage reports how much of a session's runtime budget has been used
+func runtimeBudgetStage(agentState state.AgentState, now time.Time) budgetStage {
+	deadline, ok := agentState.RuntimeDeadline()
+	if !ok {
+		return budgetOK
+	}
+	if !now.Before(deadline) {
+		return budgetExceeded
+	}
+	elapsed := now.Sub(agentState.CreatedAt)
+	if float64(elapsed) >= budgetWarnFraction*float64(agentState.MaxRuntime) {
+		return budgetWarning
+	}
+	return budgetOK
+}
+
+// checkBudgets warns about sessions nearing their runtime budget and applies the
+// timeout action to sessions that exceeded it. Each stage is acted on once.
+func (aw *AgentWatcher) checkBudgets(activeSessions []string, now time.Time) {
+	for _, sessionName := range activeSessions {
+		agentState, err := aw.stateManager.GetWorktreeInfo(sessionName)
+		if err != nil {
+			continue
+		}
+
+		stage := runtimeBudgetStage(*agentState, now)
+		aw.mu.Lock()
+		previous := aw.budgetStages[sessionName]
+		if stage > previous {
+			aw.budgetStages[sessionName] = stage
+		}
+		aw.mu.Unlock()
+		if stage <= previous {
+			continue
+		}
+
+		deadline, _ := agentState.RuntimeDeadline()
+		switch stage {
+		case budgetWarning:
+			log.Warn("Session is nearing its runtime budget", "session", sessionName, "remaining", deadline.Sub(now).Round(time.Minute))
+		case budgetExceeded:
+			log.Warn("Session exceeded its runtime budget", "session", sessionName, "budget", agentState.MaxRuntime, "action", aw.onTimeout)
+			if err := aw.applyTimeout(sessionName); err != nil {
+				log.Error("Failed to apply timeout action", "session", sessionName, "action", aw.onTimeout, "error", err)
+			}
+		}
+	}
+}
+
+// applyTimeout runs the configured timeout action for a session
+func (aw *AgentWatcher) applyTimeout(sessionName string) error {
+	switch aw.onTimeout {
+	case TimeoutPause:
+		if err := aw.sendKeys(sessionName, "Escape"); err != nil {
+			return err
+		}
+		return aw.stateManager.SetPaused(sessionName, true)
+	case TimeoutKill:
+		executable, err := os.Executable()
+		if err != nil {
+			return err
+		}
+		return exec.Command(executable, "kill", state.AgentNameFromSession(sessionName)).Run()
+	default:
+		return nil
+	}
+}
+
 func (aw *AgentWatcher) Start() {
 	log.Info("Starting Agent Watcher")
 
@@ -221,15 +358,27 @@ func (aw *AgentWatcher) Start() {
 		log.Error("Failed initial session refresh", "error", err)
 	}
 
+	if aw.heartbeats != nil {
+		go aw.runHeartbeats()
+	}
+
 	// Wait for signal
 	<-sigChan
 	log.Info("Shutting down Agent Watcher")
 	close(aw.quit)
 }
 
+var (
+	autoFs         = flag.NewFlagSet("auto", flag.ExitOnError)
+	onTimeout      = autoFs.String("on-timeout", TimeoutWarn, "action when a session exceeds its --max-runtime budget: warn, pause, or kill")
+	onPortConflict = autoFs.String("on-port-conflict", PortConflictWarn, "action when another process holds a session's dev server port: warn or reassign")
+	configPath     = autoFs.String("config", config.GetDefaultConfigPath(), "path to config file, for --on-port-conflict reassign")
+	heartbeatEvery = autoFs.Duration("heartbeat-interval", heartbeat.DefaultInterval, "how often to write session heartbeats to .uzi/heartbeats; 0 disables them")
+)
+
 var CmdWatch = &ffcli.Command{
 	Name:       "auto",
-	ShortUsage: "uzi auto",
+	ShortUsage: "uzi auto [--on-timeout warn|pause|kill] [--on-port-conflict warn|reassign] [--heartbeat-interval 10s]",
 	ShortHelp:  "Automatically manage active agent sessions",
 	LongHelp: `
 The auto command monitors all active agent sessions in the current repository
@@ -237,14 +386,68 @@ and automatically presses Enter when it detects prompts that require user input,
 such as trust prompts or continuation confirmations. It can also handle other
 automated tasks in the future.
 
+Sessions started with --max-runtime are also watched against their budget: a
+warning is logged at 80% and, once the budget is exceeded, the --on-timeout
+action is applied (warn, pause to interrupt the agent, or kill).
+
+Sessions paused with uzi pause, or by --on-timeout pause, are left alone
+until uzi resume.
+
+Dev server ports are checked too. When a process outside the session holds a
+session's port, the session is marked with a port conflict in uzi ls. With
+--on-port-conflict reassign, its dev server is restarted on a free port from
+the portRange in uzi.yaml, or the automatic range without one.
+
+Every --heartbeat-interval, the status of each session is written as JSON
+to .uzi/heartbeats/<session> in the repository, so process supervisors can
+check agents without running uzi. A session is unhealthy when its heartbeat
+says dead or is past its stale_at time; uzi health --exit-code runs the same
+check. Status changes are logged to .uzi/transitions.jsonl for uzi digest.
+When a session becomes stuck, done, or dead, the last 200 lines of its agent
+pane are saved to .uzi/snapshots/<session>-<time>.txt and listed in its
+state, so why it stalled can be read after its scrollback is gone.
+
 This is useful for hands-free operation of multiple agents.
 `,
-	FlagSet: func() *flag.FlagSet {
-		fs := flag.NewFlagSet("auto", flag.ExitOnError)
-		return fs
-	}(),
+	FlagSet: autoFs,
 	Exec: func(ctx context.Context, args []string) error {
-		watcher := NewAgentWatcher()
+		switch *onTimeout {
+		case TimeoutWarn, TimeoutPause, TimeoutKill:
+		default:
+			return fmt.Errorf("invalid --on-timeout %q: must be warn, pause, or kill", *onTimeout)
+		}
+		sm, err := cmdctx.From(ctx).State()
+		if err != nil {
+			return err
+		}
+		watcher := NewAgentWatcher(sm)
+		watcher.onTimeout = *onTimeout
+		switch *onPortConflict {
+		case PortConflictWarn:
+		case PortConflictReassign:
+			cfg, err := cmdctx.From(ctx).LoadConfig(*configPath)
+			if err != nil {
+				return fmt.Errorf("--on-port-conflict reassign needs uzi.yaml: %w", err)
+			}
+			if cfg.DevCommand == nil {
+				return fmt.Errorf("--on-port-conflict reassign needs devCommand in %s", *configPath)
+			}
+			watcher.devConfig = cfg
+		default:
+			return fmt.Errorf("invalid --on-port-conflict %q: must be warn or reassign", *onPortConflict)
+		}
+		watcher.onPortConflict = *onPortConflict
+		if *heartbeatEvery < 0 {
+			return fmt.Errorf("invalid --heartbeat-interval %s: must not be negative", *heartbeatEvery)
+		}
+		if *heartbeatEvery > 0 {
+			repoRoot, err := exec.CommandContext(ctx, "git", "rev-parse", "--show-toplevel").Output()
+			if err != nil {
+				return fmt.Errorf("heartbeats need a git repository: %w", err)
+			}
+			watcher.heartbeats = heartbeat.NewStore(heartbeat.Dir(strings.TrimSpace(string(repoRoot))))
+			watcher.heartbeatInterval = *heartbeatEvery
+		}
 		watcher.Start()
 		return nil
 	},
@@ -0,0 +1,56 @@
+package watch
+
+import (
+	"testing"
+	"time"
+
+	"github.com/nehpz/claudicus/pkg/state"
+)
+
+func TestRuntimeBudgetStage(t *testing.T) {
+	created := time.Date(2025, 1, 1, 20, 0, 0, 0, time.UTC)
+	budgeted := state.AgentState{CreatedAt: created, MaxRuntime: 2 * time.Hour}
+
+	tests := []struct {
+		name  string
+		state state.AgentState
+		now   time.Time
+		want  budgetStage
+	}{
+		{"no budget", state.AgentState{CreatedAt: created}, created.Add(48 * time.Hour), budgetOK},
+		{"early", budgeted, created.Add(time.Hour), budgetOK},
+		{"at 80%", budgeted, created.Add(96 * time.Minute), budgetWarning},
+		{"at deadline", budgeted, created.Add(2 * time.Hour), budgetExceeded},
+		{"overnight", budgeted, created.Add(12 * time.Hour), budgetExceeded},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := runtimeBudgetStage(tt.state, tt.now); got != tt.want {
+				t.Errorf("runtimeBudgetStage() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestUpdatePaused(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+	sm := state.NewStateManager()
+	for _, session := range []string{"agent-app-abc123-sarah", "agent-app-abc123-john"} {
+		if err := sm.SaveState("fix it", "branch", session, "/worktrees/"+session, "claude"); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := sm.SetPaused("agent-app-abc123-john", true); err != nil {
+		t.Fatal(err)
+	}
+
+	aw := NewAgentWatcher(sm)
+	running := aw.updatePaused([]string{"agent-app-abc123-sarah", "agent-app-abc123-john"})
+	if len(running) != 1 || running[0] != "agent-app-abc123-sarah" {
+		t.Errorf("Expected only sarah left running, got %v", running)
+	}
+	if !aw.paused["agent-app-abc123-john"] || aw.paused["agent-app-abc123-sarah"] {
+		t.Errorf("Expected only john recorded as paused, got %v", aw.paused)
+	}
+}
@@ -0,0 +1,305 @@
+package watch
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"os/exec"
+	"os/signal"
+	"regexp"
+	"strings"
+	"syscall"
+	"time"
+
+	"github.com/nehpz/claudicus/pkg/cmdctx"
+	"github.com/nehpz/claudicus/pkg/state"
+	"github.com/nehpz/claudicus/pkg/tmuxops"
+
+	"github.com/peterbourgon/ff/v3/ffcli"
+)
+
+var (
+	followFs       = flag.NewFlagSet("uzi watch", flag.ExitOnError)
+	followInterval = followFs.Duration("interval", 2*time.Second, "how often to check status, diff, and dev server health")
+	followQuiet    = followFs.Bool("no-output", false, "only show status, diff, and dev server changes, not pane output")
+)
+
+var CmdFollow = &ffcli.Command{
+	Name:       "watch",
+	ShortUsage: "uzi watch [--interval 2s] [--no-output] <agent-name>",
+	ShortHelp:  "Follow a single agent's output and status changes",
+	LongHelp: `
+The watch command follows one agent in a compact live view for small
+terminals. New pane output is streamed as it is written (via tmux pipe-pane),
+and a timestamped line is printed whenever the agent's status, diff stat, or
+dev server health changes. It exits when the agent's session ends or on Ctrl-C.
+`,
+	FlagSet: followFs,
+	Exec: func(ctx context.Context, args []string) error {
+		if len(args) == 0 {
+			return fmt.Errorf("agent name argument is required")
+		}
+		if *followInterval <= 0 {
+			return fmt.Errorf("invalid --interval %s: must be positive", *followInterval)
+		}
+
+		sm, err := cmdctx.From(ctx).State()
+		if err != nil {
+			return err
+		}
+		sessionName, err := sm.SessionForAgent(args[0])
+		if err != nil {
+			return err
+		}
+
+		ctx, stop := signal.NotifyContext(ctx, os.Interrupt, syscall.SIGTERM)
+		defer stop()
+
+		f := newFollower(sessionName, sm, os.Stdout)
+		if !*followQuiet {
+			stream, err := startPipe(sessionName)
+			if err != nil {
+				return err
+			}
+			defer stream.Close()
+			f.output = stream
+		}
+		return f.run(ctx, *followInterval)
+	},
+}
+
+// ansiRe matches terminal escape sequences in raw pane output
+var ansiRe = regexp.MustCompile(`\x1b(\[[0-9;?]*[ -/]*[@-~]|\][^\x07\x1b]*(\x07|\x1b\\)|[()][0-9A-Za-z]|[=>78])`)
+
+// outputPollInterval is how often new pane output is read
+const outputPollInterval = 250 * time.Millisecond
+
+// followSnapshot is what the follower last reported about the agent
+type followSnapshot struct {
+	status     string
+	insertions int
+	deletions  int
+	devServer  string // "up", "down", or "" without a dev server
+}
+
+// agentStateSource looks up a session's saved state
+type agentStateSource interface {
+	GetWorktreeInfo(sessionName string) (*state.AgentState, error)
+}
+
+// follower prints one agent's output and status changes
+type follower struct {
+	sessionName string
+	agentName   string
+	states      agentStateSource
+	aggregator  *state.Aggregator
+	out         io.Writer
+	output      io.Reader // New pane output, or nil when not streaming
+
+	sessionAlive func(sessionName string) bool
+	devHealth    func(port int) string
+	now          func() time.Time
+
+	last    followSnapshot
+	partial string // Output after the last newline, held until the line completes
+}
+
+// newFollower creates a follower for a session that checks tmux and the dev server directly
+func newFollower(sessionName string, sm *state.StateManager, out io.Writer) *follower {
+	return &follower{
+		sessionName:  sessionName,
+		agentName:    state.AgentNameFromSession(sessionName),
+		states:       sm,
+		aggregator:   state.NewAggregator(state.WithTmuxStatus(), state.WithDiffs(), state.WithDevURLs()),
+		out:          out,
+		sessionAlive: tmuxSessionAlive,
+		devHealth:    checkDevServer,
+		now:          time.Now,
+	}
+}
+
+// run follows the session until it ends or ctx is cancelled
+func (f *follower) run(ctx context.Context, interval time.Duration) error {
+	fmt.Fprintf(f.out, "Watching %s (Ctrl-C to stop)\n", f.agentName)
+	if !f.check() {
+		return nil
+	}
+
+	statusTicker := time.NewTicker(interval)
+	defer statusTicker.Stop()
+	outputTicker := time.NewTicker(outputPollInterval)
+	defer outputTicker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			f.flushOutput()
+			return nil
+		case <-outputTicker.C:
+			f.readOutput()
+		case <-statusTicker.C:
+			f.readOutput()
+			if !f.check() {
+				return nil
+			}
+		}
+	}
+}
+
+// check reports changes since the last check and returns false once the session has ended
+func (f *follower) check() bool {
+	if !f.sessionAlive(f.sessionName) {
+		f.flushOutput()
+		f.event("session ended")
+		return false
+	}
+	agentState, err := f.states.GetWorktreeInfo(f.sessionName)
+	if err != nil {
+		f.event("session ended")
+		return false
+	}
+
+	info := f.aggregator.Session(f.sessionName, *agentState)
+	current := followSnapshot{
+		status:     info.Status,
+		insertions: info.Insertions,
+		deletions:  info.Deletions,
+	}
+	if agentState.Port != 0 {
+		current.devServer = f.devHealth(agentState.Port)
+	}
+
+	if current.status != f.last.status {
+		if f.last.status == "" {
+			f.event("status %s", current.status)
+		} else {
+			f.event("status %s → %s", f.last.status, current.status)
+		}
+	}
+	if current.insertions != f.last.insertions || current.deletions != f.last.deletions || f.last.status == "" {
+		f.event("diff +%d/-%d", current.insertions, current.deletions)
+	}
+	if current.devServer != f.last.devServer {
+		f.event("dev server %s %s", info.DevServerURL, current.devServer)
+	}
+	f.last = current
+	return true
+}
+
+// event prints a timestamped status line
+func (f *follower) event(format string, args ...any) {
+	fmt.Fprintf(f.out, "%s ● %s\n", f.now().Format("15:04:05"), fmt.Sprintf(format, args...))
+}
+
+// readOutput prints complete lines of new pane output
+func (f *follower) readOutput() {
+	if f.output == nil {
+		return
+	}
+	buf := make([]byte, 32*1024)
+	for {
+		n, err := f.output.Read(buf)
+		if n > 0 {
+			f.partial += string(buf[:n])
+		}
+		if n == 0 || err != nil {
+			break
+		}
+	}
+
+	lines := strings.Split(f.partial, "\n")
+	f.partial = lines[len(lines)-1]
+	for _, line := range lines[:len(lines)-1] {
+		f.printLine(line)
+	}
+}
+
+// flushOutput prints any incomplete output line
+func (f *follower) flushOutput() {
+	if f.partial != "" {
+		f.printLine(f.partial)
+		f.partial = ""
+	}
+}
+
+// printLine prints one line of pane output without terminal control sequences
+func (f *follower) printLine(line string) {
+	line = cleanOutput(line)
+	if strings.TrimSpace(line) == "" {
+		return
+	}
+	fmt.Fprintf(f.out, "│ %s\n", line)
+}
+
+// cleanOutput strips escape sequences and keeps the text after the last
+// carriage return, which is what the terminal would show
+func cleanOutput(line string) string {
+	line = ansiRe.ReplaceAllString(line, "")
+	line = strings.TrimRight(line, "\r")
+	if i := strings.LastIndex(line, "\r"); i >= 0 {
+		line = line[i+1:]
+	}
+	return strings.Map(func(r rune) rune {
+		if r < ' ' && r != '\t' {
+			return -1
+		}
+		return r
+	}, line)
+}
+
+// paneStream is pane output piped by tmux into a temporary file
+type paneStream struct {
+	sessionName string
+	file        *os.File
+}
+
+// startPipe starts piping the agent pane's output into a temporary file and
+// returns a reader positioned at its end
+func startPipe(sessionName string) (*paneStream, error) {
+	file, err := os.CreateTemp("", "uzi-watch-*.log")
+	if err != nil {
+		return nil, fmt.Errorf("failed to create output file: %w", err)
+	}
+
+	// The file name comes from CreateTemp, but quote it for the shell anyway
+	quoted := "'" + strings.ReplaceAll(file.Name(), "'", `'\''`) + "'"
+	cmd := exec.Command("tmux", "pipe-pane", "-t", tmuxops.AgentTarget(sessionName), "cat >> "+quoted)
+	if output, err := cmd.CombinedOutput(); err != nil {
+		file.Close()
+		os.Remove(file.Name())
+		return nil, fmt.Errorf("failed to stream pane output: %w: %s", err, strings.TrimSpace(string(output)))
+	}
+	return &paneStream{sessionName: sessionName, file: file}, nil
+}
+
+// Read implements io.Reader, returning io.EOF when no new output is available
+func (p *paneStream) Read(b []byte) (int, error) {
+	return p.file.Read(b)
+}
+
+// Close stops piping and removes the temporary file
+func (p *paneStream) Close() error {
+	// pipe-pane without a command stops the pipe
+	exec.Command("tmux", "pipe-pane", "-t", tmuxops.AgentTarget(p.sessionName)).Run()
+	p.file.Close()
+	return os.Remove(p.file.Name())
+}
+
+// tmuxSessionAlive reports whether the tmux session still exists
+func tmuxSessionAlive(sessionName string) bool {
+	return exec.Command("tmux", "has-session", "-t", sessionName).Run() == nil
+}
+
+// checkDevServer reports "up" if the dev server answers HTTP requests and "down" otherwise
+func checkDevServer(port int) string {
+	client := &http.Client{Timeout: time.Second}
+	resp, err := client.Get(state.DevServerURL(port))
+	if err != nil {
+		return "down"
+	}
+	resp.Body.Close()
+	return "up"
+}
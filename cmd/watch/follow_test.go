@@ -0,0 +1,134 @@
+package watch
+
+import (
+	"bytes"
+	"errors"
+	"io"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/nehpz/claudicus/pkg/state"
+)
+
+// fakeProbe serves pane content and diff output set by the test
+type fakeProbe struct {
+	pane string
+	diff string
+}
+
+func (p *fakeProbe) PaneContent(string) (string, error) { return p.pane, nil }
+func (p *fakeProbe) DiffStat(string) (string, error)    { return p.diff, nil }
+
+// fakeStates returns a fixed agent state, or an error once removed
+type fakeStates struct {
+	state *state.AgentState
+}
+
+func (s *fakeStates) GetWorktreeInfo(string) (*state.AgentState, error) {
+	if s.state == nil {
+		return nil, errors.New("session not found")
+	}
+	return s.state, nil
+}
+
+// chunkReader returns queued chunks one Read at a time, then io.EOF
+type chunkReader struct {
+	chunks []string
+}
+
+func (r *chunkReader) Read(b []byte) (int, error) {
+	if len(r.chunks) == 0 {
+		return 0, io.EOF
+	}
+	n := copy(b, r.chunks[0])
+	r.chunks = r.chunks[1:]
+	return n, nil
+}
+
+func newTestFollower(probe *fakeProbe, states *fakeStates, out *bytes.Buffer) *follower {
+	return &follower{
+		sessionName:  "agent-repo-abc123-sarah",
+		agentName:    "sarah",
+		states:       states,
+		aggregator:   state.NewAggregator(state.WithProbe(probe), state.WithTmuxStatus(), state.WithDiffs(), state.WithDevURLs()),
+		out:          out,
+		sessionAlive: func(string) bool { return states.state != nil },
+		devHealth:    func(int) string { return "down" },
+		now:          func() time.Time { return time.Date(2025, 1, 1, 12, 0, 0, 0, time.UTC) },
+	}
+}
+
+func TestFollowerCheckReportsChanges(t *testing.T) {
+	var out bytes.Buffer
+	probe := &fakeProbe{pane: "> ", diff: ""}
+	states := &fakeStates{state: &state.AgentState{WorktreePath: "/worktrees/sarah", Port: 3000}}
+	f := newTestFollower(probe, states, &out)
+
+	if !f.check() {
+		t.Fatal("Expected the session to be alive")
+	}
+	for _, want := range []string{"12:00:00 ● status ready", "diff +0/-0", "dev server http://localhost:3000 down"} {
+		if !strings.Contains(out.String(), want) {
+			t.Errorf("Expected %q in initial output, got %q", want, out.String())
+		}
+	}
+
+	// Nothing changed, nothing printed
+	out.Reset()
+	f.check()
+	if out.Len() != 0 {
+		t.Errorf("Expected no output without changes, got %q", out.String())
+	}
+
+	probe.pane = "Thinking... esc to interrupt"
+	probe.diff = " 1 file changed, 7 insertions(+), 2 deletions(-)"
+	f.devHealth = func(int) string { return "up" }
+	f.check()
+	for _, want := range []string{"status ready → running", "diff +7/-2", "dev server http://localhost:3000 up"} {
+		if !strings.Contains(out.String(), want) {
+			t.Errorf("Expected %q after changes, got %q", want, out.String())
+		}
+	}
+
+	out.Reset()
+	states.state = nil
+	if f.check() {
+		t.Error("Expected check to stop once the session ends")
+	}
+	if !strings.Contains(out.String(), "session ended") {
+		t.Errorf("Expected session ended, got %q", out.String())
+	}
+}
+
+func TestFollowerReadOutput(t *testing.T) {
+	var out bytes.Buffer
+	f := newTestFollower(&fakeProbe{}, &fakeStates{}, &out)
+	f.output = &chunkReader{chunks: []string{"\x1b[32mbuilding\x1b[0m\r\n", "50%\r100% done\n", "partial"}}
+
+	f.readOutput()
+	if got, want := out.String(), "│ building\n│ 100% done\n"; got != want {
+		t.Errorf("readOutput() printed %q, want %q", got, want)
+	}
+
+	// Incomplete lines are held until the line ends or the follower stops
+	f.flushOutput()
+	if !strings.HasSuffix(out.String(), "│ partial\n") {
+		t.Errorf("Expected flushed partial line, got %q", out.String())
+	}
+}
+
+func TestCleanOutput(t *testing.T) {
+	tests := map[string]string{
+		"plain":                      "plain",
+		"\x1b[1;31merror\x1b[0m":     "error",
+		"\x1b]0;title\x07text":       "text",
+		"spinner |\rspinner /\r":     "spinner /",
+		"\x1b[?25lhidden cursor\x08": "hidden cursor",
+	}
+	for input, want := range tests {
+		if got := cleanOutput(input); got != want {
+			t.Errorf("cleanOutput(%q) = %q, want %q", input, got, want)
+		}
+	}
+}
@@ -0,0 +1,149 @@
+package watch
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/nehpz/claudicus/pkg/heartbeat"
+	"github.com/nehpz/claudicus/pkg/hosts"
+	"github.com/nehpz/claudicus/pkg/state"
+	"github.com/nehpz/claudicus/pkg/tmuxops"
+
+	"github.com/charmbracelet/log"
+)
+
+// heartbeatStatus resolves the status written to live sessions' heartbeats
+var heartbeatStatus = state.NewAggregator(state.WithTmuxStatus(), state.WithRemoteProbe(hosts.RemoteProbe))
+
+// transitionDiffs measures the diff logged with a status transition
+var transitionDiffs = state.NewAggregator(state.WithDiffs(), state.WithRemoteProbe(hosts.RemoteProbe))
+
+// snapshotStatuses are the statuses a session's agent pane is saved on
+// entering, so why an agent stalled or ended can be read after its
+// scrollback is gone
+var snapshotStatuses = map[string]bool{state.StatusStuck: true, state.StatusDone: true, state.StatusDead: true}
+
+// capturePaneTail returns the last heartbeat.SnapshotLines lines of a
+// session's agent pane; tests replace it
+var capturePaneTail = func(sessionName string, agentState state.AgentState) (string, error) {
+	output, err := hosts.ForState(agentState).ExecuteCommand("tmux", tmuxops.ScrollbackArgs(sessionName, heartbeat.SnapshotLines)...)
+	return string(output), err
+}
+
+// heartbeats builds the heartbeat of every session of the repository: live
+// sessions get the status status returns, the others are dead
+func heartbeats(states map[string]state.AgentState, alive map[string]bool, status func(string, state.AgentState) string, now time.Time, interval time.Duration) []heartbeat.Beat {
+	beats := make([]heartbeat.Beat, 0, len(states))
+	for sessionName, agentState := range states {
+		sessionStatus := state.StatusDead
+		if alive[sessionName] {
+			sessionStatus = status(sessionName, agentState)
+		}
+		beat := heartbeat.NewBeat(sessionName, state.AgentNameFromSession(sessionName), sessionStatus, now, interval)
+		beat.Host = agentState.Host
+		beat.Port = agentState.Port
+		beats = append(beats, beat)
+	}
+	return beats
+}
+
+// writeHeartbeats rewrites the heartbeat of every session of the repository
+// and removes those of sessions no longer in state. Sessions whose status
+// changed since their last heartbeat get a transition logged for uzi digest,
+// and their agent pane saved when they became stuck, done, or dead.
+func (aw *AgentWatcher) writeHeartbeats(now time.Time) error {
+	states, err := aw.stateManager.StatesForRepo()
+	if err != nil {
+		return fmt.Errorf("failed to load sessions: %w", err)
+	}
+	activeSessions, err := aw.stateManager.GetActiveSessionsForRepo()
+	if err != nil {
+		return fmt.Errorf("failed to get active sessions: %w", err)
+	}
+	alive := make(map[string]bool, len(activeSessions))
+	for _, sessionName := range activeSessions {
+		alive[sessionName] = true
+	}
+
+	status := func(sessionName string, agentState state.AgentState) string {
+		return heartbeatStatus.Session(sessionName, agentState).Status
+	}
+	if aw.paneTails == nil {
+		aw.paneTails = make(map[string]string)
+	}
+	keep := make(map[string]bool, len(states))
+	for _, beat := range heartbeats(states, alive, status, now, aw.heartbeatInterval) {
+		// A dead session's pane can't be read anymore, so its snapshot is
+		// the last one read while it was alive
+		if alive[beat.Session] {
+			if tail, err := capturePaneTail(beat.Session, states[beat.Session]); err == nil {
+				aw.paneTails[beat.Session] = tail
+			}
+		}
+		if transition, changed := statusTransition(aw.heartbeats, beat); changed {
+			info := transitionDiffs.Session(beat.Session, states[beat.Session])
+			transition.Insertions, transition.Deletions = info.Insertions, info.Deletions
+			if snapshotStatuses[beat.Status] {
+				transition.Snapshot = aw.snapshotPane(beat.Session, now)
+			}
+			if err := aw.heartbeats.AppendTransition(transition); err != nil {
+				log.Warn("Failed to log status transition", "session", beat.Session, "error", err)
+			}
+		}
+		if err := aw.heartbeats.Write(beat); err != nil {
+			return fmt.Errorf("failed to write heartbeat of %s: %w", beat.Session, err)
+		}
+		keep[beat.Session] = true
+		if !alive[beat.Session] {
+			delete(aw.paneTails, beat.Session)
+		}
+	}
+	return aw.heartbeats.Prune(keep)
+}
+
+// snapshotPane saves the last pane content read from a session and records
+// it in the session's state. It returns the snapshot's path, or "" when
+// there is no content to save.
+func (aw *AgentWatcher) snapshotPane(sessionName string, now time.Time) string {
+	tail, ok := aw.paneTails[sessionName]
+	if !ok {
+		return ""
+	}
+	path, err := aw.heartbeats.WriteSnapshot(sessionName, now, tail)
+	if err != nil {
+		log.Warn("Failed to save pane snapshot", "session", sessionName, "error", err)
+		return ""
+	}
+	if err := aw.stateManager.AddSnapshot(sessionName, path); err != nil {
+		log.Warn("Failed to record pane snapshot", "session", sessionName, "error", err)
+	}
+	return path
+}
+
+// statusTransition returns the transition to the beat's status, and whether
+// it differs from the session's last heartbeat or there was none
+func statusTransition(store *heartbeat.Store, beat heartbeat.Beat) (heartbeat.Transition, bool) {
+	transition := heartbeat.Transition{Session: beat.Session, Agent: beat.Agent, To: beat.Status, At: beat.UpdatedAt}
+	previous, err := store.Read(beat.Session)
+	if err != nil {
+		return transition, true
+	}
+	transition.From = previous.Status
+	return transition, previous.Status != beat.Status
+}
+
+// runHeartbeats writes heartbeats every heartbeatInterval until the watcher quits
+func (aw *AgentWatcher) runHeartbeats() {
+	ticker := time.NewTicker(aw.heartbeatInterval)
+	defer ticker.Stop()
+	for {
+		if err := aw.writeHeartbeats(time.Now()); err != nil {
+			log.Error("Failed to write heartbeats", "error", err)
+		}
+		select {
+		case <-ticker.C:
+		case <-aw.quit:
+			return
+		}
+	}
+}
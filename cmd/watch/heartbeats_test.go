@@ -0,0 +1,93 @@
+package watch
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/nehpz/claudicus/pkg/heartbeat"
+	"github.com/nehpz/claudicus/pkg/state"
+)
+
+func TestHeartbeats(t *testing.T) {
+	now := time.Date(2025, 1, 1, 12, 0, 0, 0, time.UTC)
+	states := map[string]state.AgentState{
+		"agent-app-abc123-sarah": {Port: 3000},
+		"agent-app-abc123-john":  {Host: "box"},
+	}
+	alive := map[string]bool{"agent-app-abc123-sarah": true}
+	status := func(string, state.AgentState) string { return state.StatusReady }
+
+	beats := heartbeats(states, alive, status, now, 10*time.Second)
+	if len(beats) != 2 {
+		t.Fatalf("Expected a heartbeat per session, got %+v", beats)
+	}
+	for _, beat := range beats {
+		switch beat.Session {
+		case "agent-app-abc123-sarah":
+			if beat.Status != state.StatusReady || beat.Agent != "sarah" || beat.Port != 3000 {
+				t.Errorf("Unexpected heartbeat for the live session: %+v", beat)
+			}
+		case "agent-app-abc123-john":
+			if beat.Status != state.StatusDead || beat.Host != "box" {
+				t.Errorf("Expected the session without tmux dead, got %+v", beat)
+			}
+		}
+		if !beat.StaleAt.Equal(now.Add(30 * time.Second)) {
+			t.Errorf("Expected the heartbeat stale after three intervals, got %v", beat.StaleAt)
+		}
+	}
+}
+
+func TestStatusTransition(t *testing.T) {
+	store := heartbeat.NewStore(heartbeat.Dir(t.TempDir()))
+	now := time.Date(2025, 1, 1, 12, 0, 0, 0, time.UTC)
+	beat := heartbeat.NewBeat("agent-app-abc123-sarah", "sarah", state.StatusRunning, now, time.Second)
+
+	if transition, changed := statusTransition(store, beat); !changed || transition.From != "" || transition.To != state.StatusRunning {
+		t.Errorf("Expected a transition for the first heartbeat, got %+v, %v", transition, changed)
+	}
+	if err := store.Write(beat); err != nil {
+		t.Fatal(err)
+	}
+	if _, changed := statusTransition(store, beat); changed {
+		t.Error("Expected no transition while the status stays the same")
+	}
+	beat.Status = state.StatusReady
+	if transition, changed := statusTransition(store, beat); !changed || transition.From != state.StatusRunning || transition.To != state.StatusReady || !transition.At.Equal(now) {
+		t.Errorf("Expected a transition from running to ready, got %+v, %v", transition, changed)
+	}
+}
+
+func TestSnapshotPane(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+	sm := state.NewStateManager()
+	session := "agent-app-abc123-sarah"
+	if err := sm.SaveState("fix it", "branch", session, "/worktrees/"+session, "claude"); err != nil {
+		t.Fatal(err)
+	}
+	aw := NewAgentWatcher(sm)
+	aw.heartbeats = heartbeat.NewStore(heartbeat.Dir(t.TempDir()))
+	now := time.Date(2025, 1, 1, 12, 0, 0, 0, time.UTC)
+
+	if path := aw.snapshotPane(session, now); path != "" {
+		t.Errorf("Expected no snapshot before the pane was read, got %s", path)
+	}
+
+	aw.paneTails = map[string]string{session: "Error: rate limited\n"}
+	path := aw.snapshotPane(session, now)
+	if filepath.Base(path) != session+"-20250101T120000Z.txt" || filepath.Base(filepath.Dir(path)) != "snapshots" {
+		t.Fatalf("Unexpected snapshot path %s", path)
+	}
+	if data, err := os.ReadFile(path); err != nil || string(data) != "Error: rate limited\n" {
+		t.Errorf("Expected the pane content saved, got %q, %v", data, err)
+	}
+	agentState, err := sm.GetWorktreeInfo(session)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(agentState.Snapshots) != 1 || agentState.Snapshots[0] != path {
+		t.Errorf("Expected the snapshot recorded in state, got %v", agentState.Snapshots)
+	}
+}
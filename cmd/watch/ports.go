@@ -0,0 +1,208 @@
+package watch
+
+import (
+	"errors"
+	"fmt"
+	"os/exec"
+	"strconv"
+	"strings"
+
+	"github.com/nehpz/claudicus/cmd/prompt"
+	"github.com/nehpz/claudicus/pkg/state"
+
+	"github.com/charmbracelet/log"
+)
+
+// Actions `uzi auto` can take when another process holds a session's dev server port
+const (
+	PortConflictWarn     = "warn"     // only mark the session and log the foreign process
+	PortConflictReassign = "reassign" // restart the dev server on a free port from the dev port range
+)
+
+// portChecker finds out which processes hold a session's dev server port.
+// The lookups are swappable so tests don't need real processes.
+type portChecker struct {
+	listeners func(port int) ([]int, error)              // PIDs listening on the port
+	panePIDs  func(sessionName string) ([]int, error)    // PIDs of the shells in the session's panes
+	parentPID func(pid int) (int, error)                 // parent of a process, 0 at the top
+	restart   func(sessionName, devCommand string) error // rerun the dev server in the uzi-dev window
+}
+
+func newPortChecker() *portChecker {
+	return &portChecker{
+		listeners: listeningPIDs,
+		panePIDs:  sessionPanePIDs,
+		parentPID: parentPID,
+		restart:   restartDevServer,
+	}
+}
+
+// foreignListener returns the PID of a process listening on the session's port
+// that is not started from one of the session's panes, or 0 if there is none
+func (pc *portChecker) foreignListener(sessionName string, port int) (int, error) {
+	pids, err := pc.listeners(port)
+	if err != nil || len(pids) == 0 {
+		return 0, err
+	}
+	roots, err := pc.panePIDs(sessionName)
+	if err != nil {
+		return 0, err
+	}
+	for _, pid := range pids {
+		if !pc.descendsFrom(pid, roots) {
+			return pid, nil
+		}
+	}
+	return 0, nil
+}
+
+// descendsFrom reports whether pid is one of roots or was started by one of them
+func (pc *portChecker) descendsFrom(pid int, roots []int) bool {
+	// The depth limit guards against cycles from PIDs reused mid-walk
+	for depth := 0; pid > 1 && depth < 64; depth++ {
+		for _, root := range roots {
+			if pid == root {
+				return true
+			}
+		}
+		parent, err := pc.parentPID(pid)
+		if err != nil {
+			return false
+		}
+		pid = parent
+	}
+	return false
+}
+
+// listeningPIDs asks lsof for the processes listening on a TCP port
+func listeningPIDs(port int) ([]int, error) {
+	out, err := exec.Command("lsof", "-nP", "-t", fmt.Sprintf("-iTCP:%d", port), "-sTCP:LISTEN").Output()
+	if err != nil {
+		// lsof exits 1 without output when nothing listens
+		var exitErr *exec.ExitError
+		if errors.As(err, &exitErr) && len(out) == 0 {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("lsof failed: %w", err)
+	}
+	return parsePIDs(string(out)), nil
+}
+
+// sessionPanePIDs returns the PIDs of the processes running in every pane of a session
+func sessionPanePIDs(sessionName string) ([]int, error) {
+	out, err := exec.Command("tmux", "list-panes", "-s", "-t", sessionName, "-F", "#{pane_pid}").Output()
+	if err != nil {
+		return nil, fmt.Errorf("failed to list panes of %s: %w", sessionName, err)
+	}
+	return parsePIDs(string(out)), nil
+}
+
+// parentPID returns the parent of a process
+func parentPID(pid int) (int, error) {
+	out, err := exec.Command("ps", "-o", "ppid=", "-p", strconv.Itoa(pid)).Output()
+	if err != nil {
+		return 0, err
+	}
+	return strconv.Atoi(strings.TrimSpace(string(out)))
+}
+
+// restartDevServer interrupts the dev server in the uzi-dev window and runs devCommand in its place
+func restartDevServer(sessionName, devCommand string) error {
+	target := sessionName + ":uzi-dev"
+	if err := exec.Command("tmux", "send-keys", "-t", target, "C-c").Run(); err != nil {
+		return fmt.Errorf("failed to stop the dev server: %w", err)
+	}
+	return exec.Command("tmux", "send-keys", "-t", target, devCommand, "C-m").Run()
+}
+
+// parsePIDs reads one PID per line, skipping anything else
+func parsePIDs(output string) []int {
+	var pids []int
+	for _, line := range strings.Fields(output) {
+		if pid, err := strconv.Atoi(line); err == nil {
+			pids = append(pids, pid)
+		}
+	}
+	return pids
+}
+
+// checkPorts marks local sessions whose dev server port was taken by another
+// process and, with --on-port-conflict reassign, moves them to a free port.
+// Sessions whose port is free again or back with their own dev server are
+// cleared.
+func (aw *AgentWatcher) checkPorts(activeSessions []string) {
+	var assigned []int
+	states := make(map[string]*state.AgentState)
+	for _, sessionName := range activeSessions {
+		agentState, err := aw.stateManager.GetWorktreeInfo(sessionName)
+		if err != nil {
+			continue
+		}
+		states[sessionName] = agentState
+		if agentState.Port > 0 {
+			assigned = append(assigned, agentState.Port)
+		}
+	}
+
+	for _, sessionName := range activeSessions {
+		agentState, ok := states[sessionName]
+		if !ok || agentState.Port == 0 || agentState.IsRemote() {
+			continue
+		}
+
+		pid, err := aw.ports.foreignListener(sessionName, agentState.Port)
+		if err != nil {
+			log.Debug("Failed to check dev server port", "session", sessionName, "port", agentState.Port, "error", err)
+			continue
+		}
+		if pid == 0 {
+			if agentState.DevServerStatus == state.DevServerConflict {
+				log.Info("Dev server port conflict resolved", "session", sessionName, "port", agentState.Port)
+				if err := aw.stateManager.SetDevServerStatus(sessionName, ""); err != nil {
+					log.Error("Failed to clear port conflict", "session", sessionName, "error", err)
+				}
+			}
+			continue
+		}
+
+		if agentState.DevServerStatus != state.DevServerConflict {
+			log.Warn("Dev server port is held by another process", "session", sessionName, "port", agentState.Port, "pid", pid, "action", aw.onPortConflict)
+			if err := aw.stateManager.SetDevServerStatus(sessionName, state.DevServerConflict); err != nil {
+				log.Error("Failed to record port conflict", "session", sessionName, "error", err)
+			}
+		}
+		if aw.onPortConflict != PortConflictReassign {
+			continue
+		}
+
+		port, err := aw.reassignPort(sessionName, agentState.DevCommand, assigned)
+		if err != nil {
+			log.Error("Failed to reassign dev server port", "session", sessionName, "port", agentState.Port, "error", err)
+			continue
+		}
+		assigned = append(assigned, port)
+		log.Info("Moved dev server to a free port", "session", sessionName, "from", agentState.Port, "to", port)
+	}
+}
+
+// reassignPort restarts a session's dev server with its dev command, or else
+// devCommand, on a free port from the dev port range and records the new port
+func (aw *AgentWatcher) reassignPort(sessionName, devCommand string, assigned []int) (int, error) {
+	cfg := aw.devConfig
+	if cfg == nil || cfg.DevCommand == nil {
+		return 0, fmt.Errorf("devCommand is required in uzi.yaml")
+	}
+	start, end, _, err := cfg.DevPortRange()
+	if err != nil {
+		return 0, err
+	}
+	port, err := prompt.FindAvailablePort(start, end, assigned)
+	if err != nil {
+		return 0, err
+	}
+	devCmd := strings.Replace(cfg.DevCommandFor(devCommand), "$PORT", strconv.Itoa(port), 1)
+	if err := aw.ports.restart(sessionName, devCmd); err != nil {
+		return 0, err
+	}
+	return port, aw.stateManager.SetPort(sessionName, port)
+}
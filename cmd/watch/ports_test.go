@@ -0,0 +1,110 @@
+package watch
+
+import (
+	"strconv"
+	"testing"
+
+	"github.com/nehpz/claudicus/pkg/config"
+	"github.com/nehpz/claudicus/pkg/state"
+)
+
+// fakeProcesses is a process tree: the dev server 300 runs under the shell
+// 200 of the session's uzi-dev pane, and 900 is an unrelated process
+func fakeProcesses(listeners map[int][]int) (*portChecker, *[]string) {
+	parents := map[int]int{300: 250, 250: 200, 200: 1, 900: 1}
+	var restarts []string
+	return &portChecker{
+		listeners: func(port int) ([]int, error) { return listeners[port], nil },
+		panePIDs:  func(sessionName string) ([]int, error) { return []int{100, 200}, nil },
+		parentPID: func(pid int) (int, error) { return parents[pid], nil },
+		restart: func(sessionName, devCommand string) error {
+			restarts = append(restarts, devCommand)
+			return nil
+		},
+	}, &restarts
+}
+
+func TestForeignListener(t *testing.T) {
+	tests := []struct {
+		name      string
+		listeners []int
+		want      int
+	}{
+		{"nothing listening", nil, 0},
+		{"own dev server", []int{300}, 0},
+		{"pane shell itself", []int{200}, 0},
+		{"other process", []int{900}, 900},
+		{"own and other", []int{300, 900}, 900},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			checker, _ := fakeProcesses(map[int][]int{3000: tt.listeners})
+			got, err := checker.foreignListener("agent-app-abc123-sarah", 3000)
+			if err != nil || got != tt.want {
+				t.Errorf("foreignListener() = %d, %v, want %d", got, err, tt.want)
+			}
+		})
+	}
+}
+
+func TestCheckPorts(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+	sm := state.NewStateManager()
+	session := "agent-app-abc123-sarah"
+	if err := sm.SaveStateWithPort("fix it", "sarah", session, "/worktrees/sarah", "claude", 65400); err != nil {
+		t.Fatal(err)
+	}
+
+	listeners := map[int][]int{65400: {900}}
+	checker, restarts := fakeProcesses(listeners)
+	aw := NewAgentWatcher(sm)
+	aw.ports = checker
+
+	aw.checkPorts([]string{session})
+	info, _ := sm.GetWorktreeInfo(session)
+	if info.DevServerStatus != state.DevServerConflict || info.Port != 65400 {
+		t.Fatalf("Expected the conflict recorded on the same port, got %+v", info)
+	}
+	if len(*restarts) != 0 {
+		t.Errorf("Expected warn to leave the dev server alone, got %v", *restarts)
+	}
+
+	// The conflict clears once the other process lets go
+	delete(listeners, 65400)
+	aw.checkPorts([]string{session})
+	if info, _ := sm.GetWorktreeInfo(session); info.DevServerStatus != "" {
+		t.Errorf("Expected the conflict cleared, got %+v", info)
+	}
+
+	// Reassigning restarts the dev server on the next free port
+	listeners[65400] = []int{900}
+	devCommand, portRange := "npm run dev -- --port $PORT", "65400-65410"
+	aw.onPortConflict = PortConflictReassign
+	aw.devConfig = &config.Config{DevCommand: &devCommand, PortRange: &portRange}
+	aw.checkPorts([]string{session})
+	info, _ = sm.GetWorktreeInfo(session)
+	if info.Port == 65400 || info.DevServerStatus != "" {
+		t.Fatalf("Expected the session moved off the taken port, got %+v", info)
+	}
+	if len(*restarts) != 1 || (*restarts)[0] != "npm run dev -- --port "+strconv.Itoa(info.Port) {
+		t.Errorf("Expected the dev server restarted on port %d, got %v", info.Port, *restarts)
+	}
+
+	// A session spawned with its own dev command is restarted with it
+	if err := sm.SetDevCommand(session, "npm run storybook -- --port $PORT"); err != nil {
+		t.Fatal(err)
+	}
+	listeners[info.Port] = []int{900}
+	aw.checkPorts([]string{session})
+	info, _ = sm.GetWorktreeInfo(session)
+	if len(*restarts) != 2 || (*restarts)[1] != "npm run storybook -- --port "+strconv.Itoa(info.Port) {
+		t.Errorf("Expected the storybook server restarted on port %d, got %v", info.Port, *restarts)
+	}
+}
+
+func TestParsePIDs(t *testing.T) {
+	got := parsePIDs("123\n456\n\nlsof: warning\n")
+	if len(got) != 2 || got[0] != 123 || got[1] != 456 {
+		t.Errorf("parsePIDs() = %v", got)
+	}
+}
@@ -0,0 +1,203 @@
+package worktrees
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/nehpz/claudicus/pkg/cmdctx"
+	"github.com/nehpz/claudicus/pkg/config"
+	"github.com/nehpz/claudicus/pkg/state"
+
+	"github.com/charmbracelet/log"
+	"github.com/peterbourgon/ff/v3/ffcli"
+)
+
+var (
+	fs           = flag.NewFlagSet("uzi worktrees", flag.ExitOnError)
+	moveFs       = flag.NewFlagSet("uzi worktrees move", flag.ExitOnError)
+	moveDryRun   = moveFs.Bool("dry-run", false, "list the moves without making them")
+	CmdWorktrees = &ffcli.Command{
+		Name:       "worktrees",
+		ShortUsage: "uzi worktrees <move> [flags]",
+		ShortHelp:  "Manage the directory agent worktrees are stored in",
+		FlagSet:    fs,
+		Subcommands: []*ffcli.Command{
+			{
+				Name:       "move",
+				ShortUsage: "uzi worktrees move [--dry-run] <newdir>",
+				ShortHelp:  "Move the worktrees of running agents to a new directory",
+				LongHelp: `Move the worktree of every running agent of this repository into newdir
+with "git worktree move" and update state to match. Like worktreeDir in
+uzi.yaml, newdir may be relative to the main checkout and may use
+environment variables. Shared and remote sessions are skipped.
+
+Set worktreeDir in uzi.yaml to the same directory so new agents are created
+there too. git cannot move a worktree to another filesystem.`,
+				FlagSet: moveFs,
+				Exec:    executeMove,
+			},
+		},
+		Exec: func(ctx context.Context, args []string) error {
+			return flag.ErrHelp
+		},
+	}
+)
+
+// sessionStates is the part of the state manager moving worktrees needs
+type sessionStates interface {
+	GetWorktreeInfo(sessionName string) (*state.AgentState, error)
+	SetWorktreePath(sessionName, worktreePath string) error
+	LockSession(sessionName, operation string) (*state.SessionLock, error)
+}
+
+// mover moves agent worktrees; git is swappable so moves can be tested
+// without real worktrees
+type mover struct {
+	states sessionStates
+	git    func(ctx context.Context, args ...string) error
+	out    io.Writer
+	dryRun bool
+}
+
+func executeMove(ctx context.Context, args []string) error {
+	if len(args) != 1 {
+		return fmt.Errorf("usage: uzi worktrees move [--dry-run] <newdir>")
+	}
+
+	sm, err := cmdctx.From(ctx).State()
+	if err != nil {
+		return err
+	}
+
+	topLevel, err := exec.CommandContext(ctx, "git", "rev-parse", "--show-toplevel").Output()
+	if err != nil {
+		return fmt.Errorf("failed to find main checkout: %w", err)
+	}
+	checkout := strings.TrimSpace(string(topLevel))
+
+	newDir, err := (&config.Config{WorktreeDir: &args[0]}).ResolveWorktreeDir(checkout)
+	if err != nil {
+		return err
+	}
+
+	sessions, err := sm.GetActiveSessionsForRepo()
+	if err != nil {
+		log.Error("Error getting active sessions", "error", err)
+		return err
+	}
+
+	m := &mover{
+		states: sm,
+		git: func(ctx context.Context, args ...string) error {
+			cmd := exec.CommandContext(ctx, "git", args...)
+			cmd.Dir = checkout
+			if output, err := cmd.CombinedOutput(); err != nil {
+				return fmt.Errorf("git %s: %w: %s", args[0], err, strings.TrimSpace(string(output)))
+			}
+			return nil
+		},
+		out:    os.Stdout,
+		dryRun: *moveDryRun,
+	}
+	if err := m.move(ctx, sessions, newDir); err != nil {
+		return err
+	}
+	if !m.dryRun {
+		fmt.Fprintf(m.out, "Set worktreeDir: %s in uzi.yaml so new agents are created there too\n", args[0])
+	}
+	return nil
+}
+
+// move moves the worktree of each session into newDir, keeping its directory
+// name. Sessions that fail to move are reported and the rest still move.
+func (m *mover) move(ctx context.Context, sessions []string, newDir string) error {
+	sessions = append([]string(nil), sessions...)
+	sort.Strings(sessions)
+
+	if !m.dryRun {
+		if err := os.MkdirAll(newDir, 0755); err != nil {
+			return fmt.Errorf("failed to create %s: %w", newDir, err)
+		}
+	}
+
+	var failed []string
+	moved := 0
+	for _, sessionName := range sessions {
+		agentName := state.AgentNameFromSession(sessionName)
+		ok, err := m.moveSession(ctx, sessionName, newDir)
+		if err != nil {
+			fmt.Fprintf(m.out, "Failed to move %s: %v\n", agentName, err)
+			failed = append(failed, agentName)
+			continue
+		}
+		if ok {
+			moved++
+		}
+	}
+
+	if m.dryRun {
+		fmt.Fprintf(m.out, "Would move %d worktree(s) to %s\n", moved, newDir)
+	} else {
+		fmt.Fprintf(m.out, "Moved %d worktree(s) to %s\n", moved, newDir)
+	}
+	if len(failed) > 0 {
+		return errors.New("failed to move worktrees of " + strings.Join(failed, ", "))
+	}
+	return nil
+}
+
+// moveSession moves one session's worktree, reporting whether it was moved
+func (m *mover) moveSession(ctx context.Context, sessionName, newDir string) (bool, error) {
+	agentName := state.AgentNameFromSession(sessionName)
+	info, err := m.states.GetWorktreeInfo(sessionName)
+	if err != nil {
+		return false, err
+	}
+
+	switch {
+	case info.IsRemote():
+		fmt.Fprintf(m.out, "Skipped %s: runs on host %s\n", agentName, info.Host)
+		return false, nil
+	case info.IsShared() || info.WorktreePath == "":
+		fmt.Fprintf(m.out, "Skipped %s: works in the main checkout\n", agentName)
+		return false, nil
+	case filepath.Dir(info.WorktreePath) == newDir:
+		return false, nil
+	}
+	if _, err := os.Stat(info.WorktreePath); err != nil {
+		fmt.Fprintf(m.out, "Skipped %s: worktree %s no longer exists\n", agentName, info.WorktreePath)
+		return false, nil
+	}
+
+	dest := filepath.Join(newDir, filepath.Base(info.WorktreePath))
+	if _, err := os.Stat(dest); err == nil {
+		return false, fmt.Errorf("%s already exists", dest)
+	}
+	if m.dryRun {
+		fmt.Fprintf(m.out, "Would move %s: %s → %s\n", agentName, info.WorktreePath, dest)
+		return true, nil
+	}
+
+	lock, err := m.states.LockSession(sessionName, "move")
+	if err != nil {
+		return false, err
+	}
+	defer lock.Release()
+
+	if err := m.git(ctx, "worktree", "move", info.WorktreePath, dest); err != nil {
+		return false, err
+	}
+	if err := m.states.SetWorktreePath(sessionName, dest); err != nil {
+		return false, fmt.Errorf("moved to %s but failed to update state: %w", dest, err)
+	}
+	fmt.Fprintf(m.out, "Moved %s: %s → %s\n", agentName, info.WorktreePath, dest)
+	return true, nil
+}
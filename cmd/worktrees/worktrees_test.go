@@ -0,0 +1,149 @@
+package worktrees
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/nehpz/claudicus/pkg/state"
+)
+
+// fakeStates keeps agent states in memory
+type fakeStates struct {
+	states map[string]*state.AgentState
+	locked []string
+}
+
+func (f *fakeStates) GetWorktreeInfo(sessionName string) (*state.AgentState, error) {
+	info, ok := f.states[sessionName]
+	if !ok {
+		return nil, errors.New("no state found")
+	}
+	return info, nil
+}
+
+func (f *fakeStates) SetWorktreePath(sessionName, worktreePath string) error {
+	f.states[sessionName].WorktreePath = worktreePath
+	return nil
+}
+
+func (f *fakeStates) LockSession(sessionName, operation string) (*state.SessionLock, error) {
+	f.locked = append(f.locked, sessionName)
+	return nil, nil
+}
+
+// newTestMover returns a mover whose git renames directories like git worktree move
+func newTestMover(states *fakeStates, out *bytes.Buffer) (*mover, *[]string) {
+	var calls []string
+	return &mover{
+		states: states,
+		git: func(ctx context.Context, args ...string) error {
+			calls = append(calls, strings.Join(args, " "))
+			return os.Rename(args[2], args[3])
+		},
+		out: out,
+	}, &calls
+}
+
+func TestMove(t *testing.T) {
+	oldDir, newDir := t.TempDir(), filepath.Join(t.TempDir(), "worktrees")
+	sarah := filepath.Join(oldDir, "sarah-repo-abc123-1")
+	if err := os.Mkdir(sarah, 0755); err != nil {
+		t.Fatal(err)
+	}
+	states := &fakeStates{states: map[string]*state.AgentState{
+		"agent-repo-abc123-sarah":  {WorktreePath: sarah},
+		"agent-repo-abc123-shared": {WorktreePath: "/repo", Mode: state.ModeShared},
+		"agent-repo-abc123-remote": {WorktreePath: "/home/dev/worktrees/remote", Host: "box", SSH: "dev@box"},
+		"agent-repo-abc123-gone":   {WorktreePath: filepath.Join(oldDir, "gone")},
+	}}
+	var out bytes.Buffer
+	m, calls := newTestMover(states, &out)
+
+	sessions := []string{"agent-repo-abc123-sarah", "agent-repo-abc123-shared", "agent-repo-abc123-remote", "agent-repo-abc123-gone"}
+	if err := m.move(context.Background(), sessions, newDir); err != nil {
+		t.Fatalf("move() error = %v", err)
+	}
+
+	dest := filepath.Join(newDir, "sarah-repo-abc123-1")
+	if got := states.states["agent-repo-abc123-sarah"].WorktreePath; got != dest {
+		t.Errorf("Expected state updated to %s, got %s", dest, got)
+	}
+	if _, err := os.Stat(dest); err != nil {
+		t.Errorf("Expected the worktree at %s: %v", dest, err)
+	}
+	if len(*calls) != 1 || (*calls)[0] != "worktree move "+sarah+" "+dest {
+		t.Errorf("Expected one git worktree move, got %v", *calls)
+	}
+	if len(states.locked) != 1 || states.locked[0] != "agent-repo-abc123-sarah" {
+		t.Errorf("Expected only the moved session locked, got %v", states.locked)
+	}
+	for _, want := range []string{"Skipped remote: runs on host box", "Skipped shared", "Skipped gone", "Moved 1 worktree(s)"} {
+		if !strings.Contains(out.String(), want) {
+			t.Errorf("Expected %q in output, got %q", want, out.String())
+		}
+	}
+
+	// Moving again finds everything in place
+	out.Reset()
+	*calls = nil
+	if err := m.move(context.Background(), []string{"agent-repo-abc123-sarah"}, newDir); err != nil || len(*calls) != 0 {
+		t.Errorf("Expected nothing to move, got %v, %v", err, *calls)
+	}
+}
+
+func TestMoveDryRun(t *testing.T) {
+	sarah := filepath.Join(t.TempDir(), "sarah")
+	if err := os.Mkdir(sarah, 0755); err != nil {
+		t.Fatal(err)
+	}
+	newDir := filepath.Join(t.TempDir(), "worktrees")
+	states := &fakeStates{states: map[string]*state.AgentState{"agent-repo-abc123-sarah": {WorktreePath: sarah}}}
+	var out bytes.Buffer
+	m, calls := newTestMover(states, &out)
+	m.dryRun = true
+
+	if err := m.move(context.Background(), []string{"agent-repo-abc123-sarah"}, newDir); err != nil {
+		t.Fatalf("move() error = %v", err)
+	}
+	if len(*calls) != 0 || states.states["agent-repo-abc123-sarah"].WorktreePath != sarah {
+		t.Errorf("Expected a dry run to change nothing, got %v", *calls)
+	}
+	if _, err := os.Stat(newDir); !os.IsNotExist(err) {
+		t.Error("Expected a dry run not to create the directory")
+	}
+	if !strings.Contains(out.String(), "Would move 1 worktree(s)") {
+		t.Errorf("Expected the planned moves, got %q", out.String())
+	}
+}
+
+func TestMoveFailures(t *testing.T) {
+	oldDir, newDir := t.TempDir(), t.TempDir()
+	for _, name := range []string{"sarah", "emily"} {
+		if err := os.Mkdir(filepath.Join(oldDir, name), 0755); err != nil {
+			t.Fatal(err)
+		}
+	}
+	// emily's destination is taken
+	if err := os.Mkdir(filepath.Join(newDir, "emily"), 0755); err != nil {
+		t.Fatal(err)
+	}
+	states := &fakeStates{states: map[string]*state.AgentState{
+		"agent-repo-abc123-sarah": {WorktreePath: filepath.Join(oldDir, "sarah")},
+		"agent-repo-abc123-emily": {WorktreePath: filepath.Join(oldDir, "emily")},
+	}}
+	var out bytes.Buffer
+	m, _ := newTestMover(states, &out)
+
+	err := m.move(context.Background(), []string{"agent-repo-abc123-sarah", "agent-repo-abc123-emily"}, newDir)
+	if err == nil || !strings.Contains(err.Error(), "emily") {
+		t.Errorf("Expected emily reported as failed, got %v", err)
+	}
+	if got := states.states["agent-repo-abc123-sarah"].WorktreePath; got != filepath.Join(newDir, "sarah") {
+		t.Errorf("Expected sarah still moved, got %s", got)
+	}
+}
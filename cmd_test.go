@@ -9,9 +9,12 @@ import (
 	"flag"
 	"fmt"
 	"os"
+	"path/filepath"
 	"strings"
 	"testing"
 
+	"github.com/nehpz/claudicus/pkg/config"
+
 	"github.com/peterbourgon/ff/v3/ffcli"
 )
 
@@ -123,7 +126,8 @@ func TestCommandStructure(t *testing.T) {
 	// Test that all expected subcommands are present
 	expectedCommands := []string{
 		"prompt", "ls", "kill", "reset", "run",
-		"checkpoint", "auto", "broadcast", "tui",
+		"checkpoint", "auto", "broadcast", "ask", "tui", "completion",
+		"adopt", "nudge", "version", "pipeline", "top", "recover", "import", "watch", "worktrees", "export", "ci", "init", "pause", "resume", "queue", "diff", "report", "trash", "undo", "health", "grep", "auth", "todos", "status", "run-status", "overlap", "digest", "attach", "ports",
 	}
 
 	if len(subcommands) != len(expectedCommands) {
@@ -425,3 +429,126 @@ func TestBadConfigNoPanics(t *testing.T) {
 		})
 	}
 }
+
+// TestResolveAliasesWithGlobalFlags tests that aliases are resolved after global flags
+func TestResolveAliasesWithGlobalFlags(t *testing.T) {
+	tests := []struct {
+		name     string
+		input    []string
+		expected []string
+	}{
+		{"no global flags", []string{"l"}, []string{"ls"}},
+		{"repo flag with value", []string{"--repo", "/tmp/repo", "k", "all"}, []string{"--repo", "/tmp/repo", "kill", "all"}},
+		{"single dash repo flag", []string{"-repo", "/tmp/repo", "l"}, []string{"-repo", "/tmp/repo", "ls"}},
+		{"repo flag with equals", []string{"--repo=/tmp/repo", "t"}, []string{"--repo=/tmp/repo", "tui"}},
+		{"only global flags", []string{"--repo", "/tmp/repo"}, []string{"--repo", "/tmp/repo"}},
+		{"profile flag with value", []string{"--profile", "work", "--repo", "/tmp/repo", "l"}, []string{"--profile", "work", "--repo", "/tmp/repo", "ls"}},
+		{"log level and json flags", []string{"--log-level", "debug", "--json", "l"}, []string{"--log-level", "debug", "--json", "ls"}},
+		{"empty args", []string{}, []string{}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			args := resolveAliases(append([]string{}, tt.input...))
+
+			if strings.Join(args, " ") != strings.Join(tt.expected, " ") {
+				t.Errorf("resolveAliases(%v) = %v, want %v", tt.input, args, tt.expected)
+			}
+		})
+	}
+}
+
+// TestUseProfile tests selecting a config profile for every command
+func TestUseProfile(t *testing.T) {
+	origDir, err := os.Getwd()
+	if err != nil {
+		t.Fatalf("Failed to get working directory: %v", err)
+	}
+	defer os.Chdir(origDir)
+	t.Setenv("UZI_PROFILE", "")
+	t.Cleanup(func() { config.SetProfile("") })
+
+	dir := t.TempDir()
+	if err := os.Chdir(dir); err != nil {
+		t.Fatal(err)
+	}
+	if err := useProfile(""); err != nil {
+		t.Errorf("Expected no profile to be a no-op, got: %v", err)
+	}
+	if err := useProfile("work"); err == nil || !strings.Contains(err.Error(), "no uzi.yaml") {
+		t.Errorf("Expected an error without uzi.yaml, got: %v", err)
+	}
+
+	if err := os.WriteFile(filepath.Join(dir, "uzi.yaml"), []byte("agents: claude:1\nprofiles:\n  work:\n    agents: codex:1\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if err := useProfile("home"); err == nil || !strings.Contains(err.Error(), `unknown profile "home"`) {
+		t.Errorf("Expected an error for an unknown profile, got: %v", err)
+	}
+	if err := useProfile("work"); err != nil {
+		t.Fatalf("Expected no error, got: %v", err)
+	}
+	if got := os.Getenv("UZI_PROFILE"); got != "work" {
+		t.Errorf("Expected UZI_PROFILE=work, got %s", got)
+	}
+	cfg, err := config.LoadConfig("uzi.yaml")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if *cfg.Agents != "codex:1" {
+		t.Errorf("Expected the work profile's agents, got %q", *cfg.Agents)
+	}
+}
+
+// TestUseRepo tests switching the working directory to a different repository
+func TestUseRepo(t *testing.T) {
+	origDir, err := os.Getwd()
+	if err != nil {
+		t.Fatalf("Failed to get working directory: %v", err)
+	}
+	defer os.Chdir(origDir)
+	t.Setenv("UZI_REPO", "")
+
+	t.Run("empty path is a no-op", func(t *testing.T) {
+		if err := useRepo(""); err != nil {
+			t.Errorf("Expected no error, got: %v", err)
+		}
+		if dir, _ := os.Getwd(); dir != origDir {
+			t.Errorf("Expected working directory to stay %s, got %s", origDir, dir)
+		}
+	})
+
+	t.Run("switches to repo directory", func(t *testing.T) {
+		repoDir, err := filepath.EvalSymlinks(t.TempDir())
+		if err != nil {
+			t.Fatalf("Failed to resolve temp dir: %v", err)
+		}
+		if err := useRepo(repoDir); err != nil {
+			t.Fatalf("Expected no error, got: %v", err)
+		}
+		if dir, _ := os.Getwd(); dir != repoDir {
+			t.Errorf("Expected working directory %s, got %s", repoDir, dir)
+		}
+		if got := os.Getenv("UZI_REPO"); got != repoDir {
+			t.Errorf("Expected UZI_REPO=%s, got %s", repoDir, got)
+		}
+	})
+
+	t.Run("missing path", func(t *testing.T) {
+		err := useRepo(filepath.Join(t.TempDir(), "missing"))
+		if err == nil {
+			t.Error("Expected error for missing repo path")
+		}
+	})
+
+	t.Run("file instead of directory", func(t *testing.T) {
+		file := filepath.Join(t.TempDir(), "file")
+		if err := os.WriteFile(file, []byte("x"), 0644); err != nil {
+			t.Fatalf("Failed to create file: %v", err)
+		}
+		err := useRepo(file)
+		if err == nil || !strings.Contains(err.Error(), "not a directory") {
+			t.Errorf("Expected not a directory error, got: %v", err)
+		}
+	})
+}
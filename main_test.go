@@ -250,7 +250,8 @@ func TestAliasRegexPatterns(t *testing.T) {
 func TestSubcommandStructureAndNaming(t *testing.T) {
 	expectedCommands := []string{
 		"prompt", "ls", "kill", "reset", "run",
-		"checkpoint", "auto", "broadcast", "tui",
+		"checkpoint", "auto", "broadcast", "ask", "tui", "completion",
+		"adopt", "nudge", "version", "pipeline", "top", "recover", "import", "watch", "worktrees", "export", "ci", "init", "pause", "resume", "queue", "diff", "report", "trash", "undo", "health", "grep", "auth", "todos", "status", "run-status", "overlap", "digest", "attach", "ports",
 	}
 
 	if len(subcommands) != len(expectedCommands) {
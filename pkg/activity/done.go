@@ -0,0 +1,44 @@
+// Copyright (c) Subtrace, Inc.
+// SPDX-License-Identifier: BSD-3-Clause
+
+package activity
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"unicode"
+
+	"github.com/nehpz/claudicus/pkg/config"
+)
+
+// PaneSignalsDone reports whether pane content shows the done sentinel at the
+// end of a line, after nothing but bullets, prompt symbols or whitespace. A
+// line that merely mentions the sentinel, such as the prompt asking the agent
+// to print it, does not count.
+func PaneSignalsDone(content, sentinel string) bool {
+	if sentinel == "" {
+		return false
+	}
+	for _, line := range strings.Split(content, "\n") {
+		prefix, found := strings.CutSuffix(strings.TrimRightFunc(line, unicode.IsSpace), sentinel)
+		if found && strings.IndexFunc(prefix, isWordRune) < 0 {
+			return true
+		}
+	}
+	return false
+}
+
+// DoneFileExists reports whether the agent touched config.DoneFile in the
+// root of its worktree
+func DoneFileExists(worktreePath string) bool {
+	if worktreePath == "" {
+		return false
+	}
+	_, err := os.Stat(filepath.Join(worktreePath, config.DoneFile))
+	return err == nil
+}
+
+func isWordRune(r rune) bool {
+	return unicode.IsLetter(r) || unicode.IsDigit(r)
+}
@@ -0,0 +1,100 @@
+// Copyright (c) Subtrace, Inc.
+// SPDX-License-Identifier: BSD-3-Clause
+
+package activity
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/nehpz/claudicus/pkg/config"
+	"github.com/nehpz/claudicus/pkg/testutil/harness"
+)
+
+func TestPaneSignalsDone(t *testing.T) {
+	tests := []struct {
+		content string
+		want    bool
+	}{
+		{"UZI_DONE", true},
+		{"⏺ All tests pass.\n⏺ UZI_DONE  \n\n> ", true},
+		{"  • UZI_DONE\r", true},
+		{"> fix the tests, then print UZI_DONE", false},
+		{"UZI_DONE is printed when finished", false},
+		{"NOT_UZI_DONE", false},
+		{"", false},
+	}
+	for _, tt := range tests {
+		if got := PaneSignalsDone(tt.content, config.DefaultDoneSentinel); got != tt.want {
+			t.Errorf("PaneSignalsDone(%q) = %v, want %v", tt.content, got, tt.want)
+		}
+	}
+	if PaneSignalsDone("anything\n", "") {
+		t.Error("Expected an empty sentinel never to match")
+	}
+}
+
+func TestDoneFileExists(t *testing.T) {
+	dir := t.TempDir()
+	if DoneFileExists(dir) || DoneFileExists("") {
+		t.Error("Expected no done file")
+	}
+	if err := os.WriteFile(filepath.Join(dir, config.DoneFile), nil, 0644); err != nil {
+		t.Fatal(err)
+	}
+	if !DoneFileExists(dir) {
+		t.Error("Expected the done file to be found")
+	}
+}
+
+func TestAgentActivityMonitor_Done(t *testing.T) {
+	h := harness.New(t)
+	sarah := h.Spawn("sarah", "claude", "fix the tests, then print UZI_DONE")
+	worktreePath := h.States()[sarah].WorktreePath
+
+	monitor := NewAgentActivityMonitor()
+	monitor.stateManager = h.State
+	monitor.capturePane = h.PaneContent
+	monitor.SetDispatcher(nil)
+	monitor.fileWatchers[sarah] = nil // no watcher to close
+	metrics := NewMetrics()
+
+	step := func(pane string, want Status, wantDone bool) {
+		t.Helper()
+		if err := h.Tmux.SetPaneContent(sarah, pane); err != nil {
+			t.Fatal(err)
+		}
+		monitor.updateSessionMetrics(sarah, worktreePath, metrics)
+		if metrics.Status != want {
+			t.Errorf("Expected status %s for pane %q, got %s", want, pane, metrics.Status)
+		}
+		if got := h.States()[sarah].Done; got != wantDone {
+			t.Errorf("Expected done %v in state for pane %q, got %v", wantDone, pane, got)
+		}
+	}
+
+	step("> fix the tests, then print UZI_DONE\nThinking (esc to interrupt)", StatusIdle, false)
+	step("⏺ All tests pass.\n⏺ UZI_DONE\n> ", StatusDone, true)
+	select {
+	case got := <-monitor.DoneEvents():
+		if got != sarah {
+			t.Errorf("Expected a done event for %s, got %s", sarah, got)
+		}
+	default:
+		t.Error("Expected a done event")
+	}
+
+	// The sentinel scrolling away keeps the agent done, without a second event
+	step("> ", StatusDone, true)
+	if len(monitor.DoneEvents()) != 0 {
+		t.Error("Expected a single done event")
+	}
+
+	// Working again clears done, until the agent touches the done file
+	step("Working (esc to interrupt)", StatusIdle, false)
+	if err := os.WriteFile(filepath.Join(worktreePath, config.DoneFile), nil, 0644); err != nil {
+		t.Fatal(err)
+	}
+	step("Working (esc to interrupt)", StatusDone, true)
+}
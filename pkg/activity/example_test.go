@@ -44,6 +44,8 @@ func Example() {
 	//   "deletions": 12,
 	//   "files_changed": 3,
 	//   "last_commit_at": "2025-01-15T10:30:00Z",
+	//   "files_touched": 0,
+	//   "last_file_activity_at": "0001-01-01T00:00:00Z",
 	//   "status": "working"
 	// }
 }
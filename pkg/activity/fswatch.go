@@ -0,0 +1,201 @@
+// Copyright (c) Subtrace, Inc.
+// SPDX-License-Identifier: BSD-3-Clause
+
+package activity
+
+import (
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+
+	"github.com/fsnotify/fsnotify"
+	"github.com/nehpz/claudicus/pkg/state"
+)
+
+// DefaultFileActivityWindow is how long a file modification counts as recent work
+const DefaultFileActivityWindow = 5 * time.Minute
+
+// fileActivityDebounce coalesces the burst of events a single save or build
+// step emits into one touch per file
+const fileActivityDebounce = 250 * time.Millisecond
+
+// fileActivityRetention is how long touched files are remembered
+const fileActivityRetention = time.Hour
+
+// maxWatchedDirs caps the directories watched per worktree so that large trees
+// don't exhaust the system's inotify watches
+const maxWatchedDirs = 2048
+
+// skippedDirs are never watched: git bookkeeping and dependency trees that
+// change on installs rather than on the agent's work
+var skippedDirs = map[string]bool{".git": true, "node_modules": true}
+
+// FileWatcher records which files in a worktree were modified and when. New
+// directories are watched as they appear, since fsnotify is not recursive.
+// Paths in the worktree's .uziignore are neither watched nor recorded.
+type FileWatcher struct {
+	root    string
+	ignore  *state.Ignore
+	watcher *fsnotify.Watcher
+	mu      sync.Mutex
+	touched map[string]time.Time // file -> last modification after debouncing
+	pending map[string]time.Time // file -> last event awaiting the debounce
+	dirs    int
+	done    chan struct{}
+	changes chan struct{}
+}
+
+// NewFileWatcher starts watching the worktree at root
+func NewFileWatcher(root string) (*FileWatcher, error) {
+	fsw, err := fsnotify.NewWatcher()
+	if err != nil {
+		return nil, err
+	}
+	w := &FileWatcher{
+		root:    root,
+		ignore:  state.LoadIgnore(root),
+		watcher: fsw,
+		touched: make(map[string]time.Time),
+		pending: make(map[string]time.Time),
+		done:    make(chan struct{}),
+		changes: make(chan struct{}, 1),
+	}
+	if err := fsw.Add(root); err != nil {
+		fsw.Close()
+		return nil, err
+	}
+	w.dirs = 1
+	w.addTree(root)
+	go w.loop()
+	return w, nil
+}
+
+// Close stops watching
+func (w *FileWatcher) Close() error {
+	select {
+	case <-w.done:
+		return nil
+	default:
+		close(w.done)
+	}
+	return w.watcher.Close()
+}
+
+// Changes receives after files in the worktree were modified, at most once
+// per debounce interval. Changes made while nothing reads it are coalesced
+// into one. It is closed once the watcher is.
+func (w *FileWatcher) Changes() <-chan struct{} {
+	return w.changes
+}
+
+// FilesTouchedSince returns how many files were modified at or after since,
+// and when the worktree was last modified at all
+func (w *FileWatcher) FilesTouchedSince(since time.Time) (int, time.Time) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	count := 0
+	var last time.Time
+	for _, at := range w.touched {
+		if !at.Before(since) {
+			count++
+		}
+		if at.After(last) {
+			last = at
+		}
+	}
+	return count, last
+}
+
+// addTree watches the directories below dir, up to maxWatchedDirs in total
+func (w *FileWatcher) addTree(dir string) {
+	filepath.WalkDir(dir, func(path string, d fs.DirEntry, err error) error {
+		if err != nil || !d.IsDir() || path == dir {
+			return nil
+		}
+		if skippedDirs[d.Name()] || w.ignored(path) || w.dirs >= maxWatchedDirs {
+			return filepath.SkipDir
+		}
+		if w.watcher.Add(path) == nil {
+			w.dirs++
+		}
+		return nil
+	})
+}
+
+// ignored reports whether a path below the root is in the worktree's .uziignore
+func (w *FileWatcher) ignored(path string) bool {
+	rel, err := filepath.Rel(w.root, path)
+	if err != nil {
+		return false
+	}
+	return w.ignore.Match(filepath.ToSlash(rel))
+}
+
+func (w *FileWatcher) loop() {
+	defer close(w.changes)
+	var debounce <-chan time.Time
+	for {
+		select {
+		case <-w.done:
+			return
+
+		case event, ok := <-w.watcher.Events:
+			if !ok {
+				return
+			}
+			if skippedDirs[filepath.Base(event.Name)] || w.ignored(event.Name) {
+				continue
+			}
+			if event.Op&fsnotify.Create != 0 {
+				if info, err := os.Stat(event.Name); err == nil && info.IsDir() {
+					// Watch directories created after the watcher started
+					if w.dirs < maxWatchedDirs && w.watcher.Add(event.Name) == nil {
+						w.dirs++
+						w.addTree(event.Name)
+					}
+					continue
+				}
+			}
+			if event.Op&(fsnotify.Write|fsnotify.Create|fsnotify.Rename|fsnotify.Remove) == 0 {
+				continue
+			}
+			w.mu.Lock()
+			w.pending[event.Name] = time.Now()
+			w.mu.Unlock()
+			if debounce == nil {
+				debounce = time.After(fileActivityDebounce)
+			}
+
+		case _, ok := <-w.watcher.Errors:
+			if !ok {
+				return
+			}
+
+		case <-debounce:
+			debounce = nil
+			w.flush(time.Now())
+			select {
+			case w.changes <- struct{}{}:
+			default:
+			}
+		}
+	}
+}
+
+// flush records the pending touches and forgets touches older than the retention
+func (w *FileWatcher) flush(now time.Time) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	for path, at := range w.pending {
+		w.touched[path] = at
+		delete(w.pending, path)
+	}
+	for path, at := range w.touched {
+		if now.Sub(at) > fileActivityRetention {
+			delete(w.touched, path)
+		}
+	}
+}
@@ -0,0 +1,152 @@
+// Copyright (c) Subtrace, Inc.
+// SPDX-License-Identifier: BSD-3-Clause
+
+package activity
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+// waitForTouches polls the watcher until it reports want files touched since
+// start or the deadline passes
+func waitForTouches(t *testing.T, w *FileWatcher, start time.Time, want int) int {
+	t.Helper()
+	deadline := time.Now().Add(3 * time.Second)
+	for {
+		count, _ := w.FilesTouchedSince(start)
+		if count >= want || time.Now().After(deadline) {
+			return count
+		}
+		time.Sleep(20 * time.Millisecond)
+	}
+}
+
+func TestFileWatcher(t *testing.T) {
+	root := t.TempDir()
+	if err := os.MkdirAll(filepath.Join(root, "src"), 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.MkdirAll(filepath.Join(root, "node_modules", "left-pad"), 0755); err != nil {
+		t.Fatal(err)
+	}
+
+	w, err := NewFileWatcher(root)
+	if err != nil {
+		t.Fatalf("NewFileWatcher() error = %v", err)
+	}
+	defer w.Close()
+	start := time.Now()
+
+	// Repeated writes to one file count once
+	for i := 0; i < 3; i++ {
+		if err := os.WriteFile(filepath.Join(root, "src", "main.go"), []byte("package main\n"), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if got := waitForTouches(t, w, start, 1); got != 1 {
+		t.Fatalf("Expected one file touched, got %d", got)
+	}
+
+	// Directories created later are watched too; dependency trees are not
+	if err := os.WriteFile(filepath.Join(root, "node_modules", "left-pad", "index.js"), []byte("x"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.MkdirAll(filepath.Join(root, "build"), 0755); err != nil {
+		t.Fatal(err)
+	}
+	time.Sleep(100 * time.Millisecond) // let the new directory be added
+	if err := os.WriteFile(filepath.Join(root, "build", "app"), []byte("binary"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if got := waitForTouches(t, w, start, 2); got != 2 {
+		t.Errorf("Expected the build output counted and node_modules skipped, got %d files", got)
+	}
+
+	_, last := w.FilesTouchedSince(time.Now())
+	if last.Before(start) {
+		t.Errorf("Expected the last touch after the start, got %v", last)
+	}
+	if count, _ := w.FilesTouchedSince(time.Now().Add(time.Minute)); count != 0 {
+		t.Errorf("Expected no touches after now, got %d", count)
+	}
+}
+
+func TestFileWatcherIgnoreFile(t *testing.T) {
+	root := t.TempDir()
+	if err := os.MkdirAll(filepath.Join(root, "dist"), 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(root, ".uziignore"), []byte("/dist\n*.log\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	w, err := NewFileWatcher(root)
+	if err != nil {
+		t.Fatalf("NewFileWatcher() error = %v", err)
+	}
+	defer w.Close()
+	start := time.Now()
+
+	for _, name := range []string{filepath.Join("dist", "bundle.js"), "server.log", "main.go"} {
+		if err := os.WriteFile(filepath.Join(root, name), []byte("x"), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	waitForTouches(t, w, start, 1)
+	time.Sleep(2 * fileActivityDebounce) // let any ignored touch land
+	if count, _ := w.FilesTouchedSince(start); count != 1 {
+		t.Errorf("Expected only main.go counted, got %d files", count)
+	}
+}
+
+func TestFileWatcherFlushForgetsOldTouches(t *testing.T) {
+	w, err := NewFileWatcher(t.TempDir())
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer w.Close()
+
+	now := time.Now()
+	w.mu.Lock()
+	w.touched["old.go"] = now.Add(-2 * fileActivityRetention)
+	w.pending["new.go"] = now
+	w.mu.Unlock()
+
+	w.flush(now)
+	if count, last := w.FilesTouchedSince(time.Time{}); count != 1 || !last.Equal(now) {
+		t.Errorf("Expected only the new touch kept, got %d touches, last %v", count, last)
+	}
+}
+
+func TestFileWatcherChanges(t *testing.T) {
+	root := t.TempDir()
+	w, err := NewFileWatcher(root)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if err := os.WriteFile(filepath.Join(root, "main.go"), []byte("package main\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	select {
+	case <-w.Changes():
+	case <-time.After(3 * time.Second):
+		t.Fatal("Expected a change after writing a file")
+	}
+
+	w.Close()
+	select {
+	case _, ok := <-w.Changes():
+		if ok {
+			// A change may still have been queued; the channel closes next
+			if _, ok := <-w.Changes(); ok {
+				t.Error("Expected the changes channel closed with the watcher")
+			}
+		}
+	case <-time.After(3 * time.Second):
+		t.Error("Expected the changes channel closed with the watcher")
+	}
+}
@@ -5,6 +5,8 @@ package activity
 
 import (
 	"time"
+
+	"github.com/nehpz/claudicus/pkg/state"
 )
 
 // Status represents the current activity status of an agent or process
@@ -19,6 +21,9 @@ const (
 
 	// StatusStuck indicates the agent appears to be stuck or blocked
 	StatusStuck Status = "stuck"
+
+	// StatusDone indicates the agent signalled its task is complete
+	StatusDone Status = "done"
 )
 
 // Metrics captures activity metrics for monitoring agent behavior
@@ -31,6 +36,10 @@ type Metrics struct {
 	FilesChanged int       `json:"files_changed"`  // Number of files modified
 	LastCommitAt time.Time `json:"last_commit_at"` // Timestamp of most recent commit
 
+	// File system metrics, from watching the worktree
+	FilesTouched       int       `json:"files_touched"`         // Files modified within the activity window
+	LastFileActivityAt time.Time `json:"last_file_activity_at"` // Timestamp of most recent file modification
+
 	// Current status
 	Status Status `json:"status"` // Current activity status
 }
@@ -63,6 +72,13 @@ func (m *Metrics) TotalChanges() int {
 	return m.Insertions + m.Deletions
 }
 
+// SessionStatus folds the activity status into a session status resolved by
+// the state package's status engine, such as the one `uzi ls` reports: a done
+// agent is done and a ready agent the monitor classified as stuck is stuck
+func (m *Metrics) SessionStatus(status string) string {
+	return state.WithActivity(status, m.Status == StatusStuck, m.Status == StatusDone)
+}
+
 // String returns a string representation of the status
 func (s Status) String() string {
 	return string(s)
@@ -71,7 +87,7 @@ func (s Status) String() string {
 // IsValid checks if the status is one of the defined constants
 func (s Status) IsValid() bool {
 	switch s {
-	case StatusWorking, StatusIdle, StatusStuck:
+	case StatusWorking, StatusIdle, StatusStuck, StatusDone:
 		return true
 	default:
 		return false
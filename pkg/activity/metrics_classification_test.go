@@ -455,3 +455,68 @@ func TestMetricsIsActiveThresholds(t *testing.T) {
 		})
 	}
 }
+
+// TestClassifyFileActivity tests that file modifications count as work even
+// when nothing reaches git, as during a long silent build
+func TestClassifyFileActivity(t *testing.T) {
+	monitor := NewAgentActivityMonitor()
+	now := time.Now()
+
+	tests := []struct {
+		name     string
+		metrics  *Metrics
+		expected Status
+	}{
+		{
+			name:     "Working_FilesTouchedInWindow",
+			metrics:  &Metrics{FilesTouched: 3, LastFileActivityAt: now.Add(-time.Minute), LastCommitAt: now.Add(-3 * time.Hour)},
+			expected: StatusWorking,
+		},
+		{
+			name:     "Idle_FilesTouchedAfterLastCommit",
+			metrics:  &Metrics{LastFileActivityAt: now.Add(-30 * time.Minute), LastCommitAt: now.Add(-3 * time.Hour)},
+			expected: StatusIdle,
+		},
+		{
+			name:     "Stuck_NoFileActivitySinceCommit",
+			metrics:  &Metrics{LastFileActivityAt: now.Add(-4 * time.Hour), LastCommitAt: now.Add(-3 * time.Hour)},
+			expected: StatusStuck,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := monitor.ClassifyAtTime(tt.metrics, now); got != tt.expected {
+				t.Errorf("ClassifyAtTime() = %v, want %v", got, tt.expected)
+			}
+		})
+	}
+
+	monitor.SetFileActivityWindow(30 * time.Second)
+	recent := &Metrics{LastFileActivityAt: now.Add(-time.Minute)}
+	if got := monitor.ClassifyAtTime(recent, now); got != StatusIdle {
+		t.Errorf("Expected a touch outside a shorter window to be idle, got %v", got)
+	}
+}
+
+func TestClassifyStatusThresholds(t *testing.T) {
+	monitor := NewAgentActivityMonitor()
+	monitor.SetStatusThresholds(3*time.Minute, 15*time.Minute)
+	now := time.Now()
+
+	for _, tt := range []struct {
+		name    string
+		metrics *Metrics
+		want    Status
+	}{
+		{"touch within the active window", &Metrics{LastFileActivityAt: now.Add(-2 * time.Minute)}, StatusWorking},
+		{"commit within half the stuck threshold", &Metrics{LastCommitAt: now.Add(-5 * time.Minute)}, StatusWorking},
+		{"touch since the commit", &Metrics{LastCommitAt: now.Add(-20 * time.Minute), LastFileActivityAt: now.Add(-10 * time.Minute)}, StatusIdle},
+		{"no commit for the stuck threshold", &Metrics{LastCommitAt: now.Add(-15 * time.Minute)}, StatusStuck},
+		{"touch older than the stuck threshold", &Metrics{LastCommitAt: now.Add(-time.Hour), LastFileActivityAt: now.Add(-20 * time.Minute)}, StatusStuck},
+	} {
+		if got := monitor.ClassifyAtTime(tt.metrics, now); got != tt.want {
+			t.Errorf("%s: got %v, want %v", tt.name, got, tt.want)
+		}
+	}
+}
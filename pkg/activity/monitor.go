@@ -9,13 +9,14 @@ import (
 	"fmt"
 	"os"
 	"os/exec"
-	"regexp"
 	"strconv"
 	"strings"
 	"sync"
 	"time"
 
 	"github.com/charmbracelet/log"
+	"github.com/nehpz/claudicus/pkg/config"
+	"github.com/nehpz/claudicus/pkg/events"
 	"github.com/nehpz/claudicus/pkg/state"
 )
 
@@ -27,17 +28,87 @@ type AgentActivityMonitor struct {
 	metrics      map[string]*Metrics
 	mu           sync.RWMutex
 	running      bool
+	dispatcher   *events.Dispatcher
+
+	// fileWatchers watch each session's worktree; a nil entry marks a
+	// worktree that could not be watched so it isn't retried every tick
+	fileWatchers       map[string]*FileWatcher
+	fileActivityWindow time.Duration
+
+	// stuckThreshold is how long an agent may go without commits or file
+	// changes before it is classified as stuck
+	stuckThreshold time.Duration
+
+	// doneSentinel is the line agents print when their task is complete;
+	// capturePane reads a session's agent pane to look for it
+	doneSentinel string
+	capturePane  func(sessionName string) (string, error)
+	doneEvents   chan string
 }
 
+// DefaultStuckThreshold is how long an agent may go without commits or file
+// changes before it is classified as stuck
+const DefaultStuckThreshold = 2 * time.Hour
+
+// doneEventBuffer is how many done sessions DoneEvents holds for a slow reader
+const doneEventBuffer = 16
+
 // NewAgentActivityMonitor creates a new activity monitor
 func NewAgentActivityMonitor() *AgentActivityMonitor {
 	return &AgentActivityMonitor{
 		stateManager: state.NewStateManager(),
 		metrics:      make(map[string]*Metrics),
 		done:         make(chan struct{}),
+		dispatcher:   events.LoadDispatcher(config.GetDefaultConfigPath()),
+
+		fileWatchers:       make(map[string]*FileWatcher),
+		fileActivityWindow: DefaultFileActivityWindow,
+		stuckThreshold:     DefaultStuckThreshold,
+
+		doneSentinel: config.DefaultDoneSentinel,
+		capturePane:  state.DefaultSessionProbe{}.PaneContent,
+		doneEvents:   make(chan string, doneEventBuffer),
 	}
 }
 
+// SetDoneSentinel sets the line agents print when their task is complete
+func (m *AgentActivityMonitor) SetDoneSentinel(sentinel string) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.doneSentinel = sentinel
+}
+
+// DoneEvents delivers the name of each session whose agent becomes done.
+// Sessions are dropped while the buffer is full.
+func (m *AgentActivityMonitor) DoneEvents() <-chan string {
+	return m.doneEvents
+}
+
+// SetFileActivityWindow sets how long a file modification counts as recent work
+func (m *AgentActivityMonitor) SetFileActivityWindow(window time.Duration) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.fileActivityWindow = window
+}
+
+// SetStatusThresholds sets the activity window, how long a file modification
+// counts as recent work, and how long an agent may go without progress before
+// it is classified as stuck, as the statusThresholds: section of uzi.yaml
+// configures them
+func (m *AgentActivityMonitor) SetStatusThresholds(active, stuck time.Duration) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.fileActivityWindow = active
+	m.stuckThreshold = stuck
+}
+
+// SetDispatcher sets the dispatcher notified of ready and stuck transitions
+func (m *AgentActivityMonitor) SetDispatcher(d *events.Dispatcher) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.dispatcher = d
+}
+
 // Start begins monitoring with a 500ms ticker
 func (m *AgentActivityMonitor) Start(ctx context.Context) error {
 	m.mu.Lock()
@@ -72,6 +143,13 @@ func (m *AgentActivityMonitor) Stop() {
 		m.ticker.Stop()
 	}
 
+	for sessionName, watcher := range m.fileWatchers {
+		if watcher != nil {
+			watcher.Close()
+		}
+		delete(m.fileWatchers, sessionName)
+	}
+
 	log.Debug("AgentActivityMonitor stopped")
 }
 
@@ -118,6 +196,10 @@ func (m *AgentActivityMonitor) updateMetrics() {
 	// Update metrics for each active session
 	for _, sessionName := range activeSessions {
 		if agentState, exists := states[sessionName]; exists {
+			if _, tracked := m.metrics[sessionName]; !tracked && agentState.Done {
+				// Sessions already done when monitoring started were announced before
+				m.getOrCreateMetrics(sessionName).Status = StatusDone
+			}
 			metrics := m.getOrCreateMetrics(sessionName)
 			m.updateSessionMetrics(sessionName, agentState.WorktreePath, metrics)
 		}
@@ -134,6 +216,10 @@ func (m *AgentActivityMonitor) updateMetrics() {
 		}
 		if !found {
 			delete(m.metrics, sessionName)
+			if watcher := m.fileWatchers[sessionName]; watcher != nil {
+				watcher.Close()
+			}
+			delete(m.fileWatchers, sessionName)
 		}
 	}
 }
@@ -166,6 +252,12 @@ func (m *AgentActivityMonitor) updateSessionMetrics(sessionName, worktreePath st
 	// Get git diff stats
 	insertions, deletions, filesChanged := m.getGitDiffStats(worktreePath)
 
+	// Files modified recently show work that hasn't reached git yet, such as
+	// a long build that prints nothing
+	if watcher := m.fileWatcher(sessionName, worktreePath); watcher != nil {
+		metrics.FilesTouched, metrics.LastFileActivityAt = watcher.FilesTouchedSince(time.Now().Add(-m.fileActivityWindow))
+	}
+
 	// Update metrics
 	metrics.Commits = commits
 	metrics.Insertions = insertions
@@ -176,7 +268,91 @@ func (m *AgentActivityMonitor) updateSessionMetrics(sessionName, worktreePath st
 	}
 
 	// Classify status based on activity
+	previous := metrics.Status
 	metrics.Status = m.Classify(metrics)
+	if m.detectDone(sessionName, worktreePath, previous == StatusDone) {
+		metrics.Status = StatusDone
+	}
+	m.notifyTransition(sessionName, previous, metrics.Status)
+}
+
+// Measure returns the metrics of a worktree without tracking its session, for
+// one-off callers such as uzi ls that don't run the monitor. No file watcher
+// runs, so file activity is unknown and only git and the done file count.
+func (m *AgentActivityMonitor) Measure(worktreePath string) *Metrics {
+	metrics := NewMetrics()
+	if worktreePath == "" {
+		return metrics
+	}
+	if _, err := os.Stat(worktreePath); os.IsNotExist(err) {
+		return metrics
+	}
+	metrics.Commits, metrics.LastCommitAt = m.getGitLogInfo(worktreePath)
+	metrics.Insertions, metrics.Deletions, metrics.FilesChanged = m.getGitDiffStats(worktreePath)
+	metrics.Status = m.Classify(metrics)
+	if DoneFileExists(worktreePath) {
+		metrics.Status = StatusDone
+	}
+	return metrics
+}
+
+// fileWatcher returns the watcher of a session's worktree, starting it on first use
+func (m *AgentActivityMonitor) fileWatcher(sessionName, worktreePath string) *FileWatcher {
+	if watcher, exists := m.fileWatchers[sessionName]; exists {
+		return watcher
+	}
+	watcher, err := NewFileWatcher(worktreePath)
+	if err != nil {
+		log.Debug("Failed to watch worktree", "session", sessionName, "path", worktreePath, "error", err)
+	}
+	m.fileWatchers[sessionName] = watcher
+	return watcher
+}
+
+// detectDone reports whether a session's agent has signalled completion by
+// touching the done file or printing the done sentinel. The done file keeps
+// the agent done until it is removed; the sentinel keeps it done until the
+// pane shows the agent working again, so scrolling out of view doesn't undo it.
+func (m *AgentActivityMonitor) detectDone(sessionName, worktreePath string, wasDone bool) bool {
+	if DoneFileExists(worktreePath) {
+		return true
+	}
+	content, err := m.capturePane(sessionName)
+	if err != nil {
+		return wasDone
+	}
+	if state.AgentStatusFromPane(content) == state.StatusRunning {
+		return false
+	}
+	return wasDone || PaneSignalsDone(content, m.doneSentinel)
+}
+
+// notifyTransition dispatches lifecycle events for status changes:
+// working -> idle means the agent is ready, and any change into stuck is reported.
+// Changes into and out of done are also recorded in the session's state.
+func (m *AgentActivityMonitor) notifyTransition(sessionName string, previous, current Status) {
+	if previous == current {
+		return
+	}
+
+	if current == StatusDone || previous == StatusDone {
+		if err := m.stateManager.SetDone(sessionName, current == StatusDone); err != nil {
+			log.Debug("Failed to record done status", "session", sessionName, "error", err)
+		}
+	}
+
+	switch {
+	case current == StatusDone:
+		m.dispatcher.Dispatch(events.NewEvent(events.EventDone, sessionName, "agent is done"))
+		select {
+		case m.doneEvents <- sessionName:
+		default:
+		}
+	case current == StatusStuck:
+		m.dispatcher.Dispatch(events.NewEvent(events.EventStuck, sessionName, "agent appears to be stuck"))
+	case previous == StatusWorking && current == StatusIdle:
+		m.dispatcher.Dispatch(events.NewEvent(events.EventReady, sessionName, "agent is ready"))
+	}
 }
 
 // getGitLogInfo gets commit count and last commit time using git log --since
@@ -214,8 +390,7 @@ func (m *AgentActivityMonitor) getGitLogInfo(worktreePath string) (int, time.Tim
 
 // getGitDiffStats gets diff statistics using git diff --shortstat
 func (m *AgentActivityMonitor) getGitDiffStats(worktreePath string) (int, int, int) {
-	// Stage all changes temporarily to show in diff, then reset
-	cmd := exec.Command("sh", "-c", "git add -A . && git diff --cached --shortstat HEAD && git reset HEAD > /dev/null")
+	cmd := exec.Command("sh", "-c", state.DiffScript)
 	cmd.Dir = worktreePath
 
 	output, err := cmd.Output()
@@ -226,39 +401,9 @@ func (m *AgentActivityMonitor) getGitDiffStats(worktreePath string) (int, int, i
 	return m.parseShortstat(string(output))
 }
 
-// parseShortstat parses git diff --shortstat output
+// parseShortstat parses the output of state.DiffScript
 func (m *AgentActivityMonitor) parseShortstat(output string) (insertions, deletions, filesChanged int) {
-	// Example output: " 3 files changed, 15 insertions(+), 7 deletions(-)"
-	output = strings.TrimSpace(output)
-	if output == "" {
-		return 0, 0, 0
-	}
-
-	// Parse files changed
-	fileRe := regexp.MustCompile(`(\d+) files? changed`)
-	if match := fileRe.FindStringSubmatch(output); len(match) > 1 {
-		if count, err := strconv.Atoi(match[1]); err == nil {
-			filesChanged = count
-		}
-	}
-
-	// Parse insertions
-	insRe := regexp.MustCompile(`(\d+) insertions?\(\+\)`)
-	if match := insRe.FindStringSubmatch(output); len(match) > 1 {
-		if count, err := strconv.Atoi(match[1]); err == nil {
-			insertions = count
-		}
-	}
-
-	// Parse deletions
-	delRe := regexp.MustCompile(`(\d+) deletions?\(\-\)`)
-	if match := delRe.FindStringSubmatch(output); len(match) > 1 {
-		if count, err := strconv.Atoi(match[1]); err == nil {
-			deletions = count
-		}
-	}
-
-	return insertions, deletions, filesChanged
+	return state.ParseDiffStatFiles(output)
 }
 
 // UpdateAll returns a snapshot of current metrics for all sessions
@@ -277,6 +422,9 @@ func (m *AgentActivityMonitor) UpdateAll() map[string]*Metrics {
 			FilesChanged: metrics.FilesChanged,
 			LastCommitAt: metrics.LastCommitAt,
 			Status:       metrics.Status,
+
+			FilesTouched:       metrics.FilesTouched,
+			LastFileActivityAt: metrics.LastFileActivityAt,
 		}
 		result[sessionName] = metricsCopy
 	}
@@ -301,13 +449,24 @@ func (m *AgentActivityMonitor) ClassifyAtTime(metrics *Metrics, now time.Time) S
 		return StatusWorking
 	}
 
-	// If there are recent commits (within last hour), agent is working
-	if !metrics.LastCommitAt.IsZero() && now.Sub(metrics.LastCommitAt) <= time.Hour {
+	// If files were modified within the activity window, agent is working
+	if !metrics.LastFileActivityAt.IsZero() && now.Sub(metrics.LastFileActivityAt) <= m.fileActivityWindow {
+		return StatusWorking
+	}
+
+	// If there are recent commits (within half the stuck threshold, an hour
+	// by default), agent is working
+	if !metrics.LastCommitAt.IsZero() && now.Sub(metrics.LastCommitAt) <= m.stuckThreshold/2 {
 		return StatusWorking
 	}
 
-	// If no recent commits and no activity for more than 2 hours, agent might be stuck
-	if !metrics.LastCommitAt.IsZero() && now.Sub(metrics.LastCommitAt) >= 2*time.Hour {
+	// Files modified since the last commit mean the agent is not stuck yet
+	if metrics.LastFileActivityAt.After(metrics.LastCommitAt) && now.Sub(metrics.LastFileActivityAt) < m.stuckThreshold {
+		return StatusIdle
+	}
+
+	// If no recent commits and no activity for the stuck threshold, agent might be stuck
+	if !metrics.LastCommitAt.IsZero() && now.Sub(metrics.LastCommitAt) >= m.stuckThreshold {
 		return StatusStuck
 	}
 
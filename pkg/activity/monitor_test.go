@@ -5,8 +5,17 @@ package activity
 
 import (
 	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"sync"
 	"testing"
 	"time"
+
+	"github.com/nehpz/claudicus/pkg/config"
+	"github.com/nehpz/claudicus/pkg/events"
 )
 
 func TestAgentActivityMonitor_NewAgentActivityMonitor(t *testing.T) {
@@ -487,3 +496,78 @@ func TestAgentActivityMonitor_ClassifyEdgeCases(t *testing.T) {
 		})
 	}
 }
+
+func TestAgentActivityMonitor_NotifyTransition(t *testing.T) {
+	var mu sync.Mutex
+	received := []events.EventType{}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var event events.Event
+		json.NewDecoder(r.Body).Decode(&event)
+		mu.Lock()
+		received = append(received, event.Type)
+		mu.Unlock()
+	}))
+	defer server.Close()
+
+	dispatcher := events.NewDispatcher(&config.WebhooksConfig{
+		OnReady: server.URL,
+		OnStuck: server.URL,
+	})
+	monitor := NewAgentActivityMonitor()
+	monitor.SetDispatcher(dispatcher)
+
+	tests := []struct {
+		name     string
+		previous Status
+		current  Status
+		want     []events.EventType
+	}{
+		{"working to idle is ready", StatusWorking, StatusIdle, []events.EventType{events.EventReady}},
+		{"idle to stuck", StatusIdle, StatusStuck, []events.EventType{events.EventStuck}},
+		{"no change", StatusStuck, StatusStuck, []events.EventType{}},
+		{"idle to working", StatusIdle, StatusWorking, []events.EventType{}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mu.Lock()
+			received = []events.EventType{}
+			mu.Unlock()
+
+			monitor.notifyTransition("agent-repo-abc123-sarah", tt.previous, tt.current)
+			dispatcher.Wait()
+
+			mu.Lock()
+			defer mu.Unlock()
+			if len(received) != len(tt.want) {
+				t.Fatalf("Expected events %v, got %v", tt.want, received)
+			}
+			for i := range tt.want {
+				if received[i] != tt.want[i] {
+					t.Errorf("Expected event %s, got %s", tt.want[i], received[i])
+				}
+			}
+		})
+	}
+}
+
+func TestAgentActivityMonitor_Measure(t *testing.T) {
+	monitor := NewAgentActivityMonitor()
+
+	for _, worktreePath := range []string{"", filepath.Join(t.TempDir(), "missing")} {
+		if metrics := monitor.Measure(worktreePath); metrics.Status != StatusIdle || metrics.TotalChanges() != 0 {
+			t.Errorf("Measure(%q) = %+v, want idle without changes", worktreePath, metrics)
+		}
+	}
+
+	dir := t.TempDir()
+	if err := os.WriteFile(filepath.Join(dir, config.DoneFile), nil, 0644); err != nil {
+		t.Fatal(err)
+	}
+	if metrics := monitor.Measure(dir); metrics.Status != StatusDone {
+		t.Errorf("Expected the done file to mark the worktree done, got %s", metrics.Status)
+	}
+	if len(monitor.UpdateAll()) != 0 {
+		t.Error("Expected Measure not to track the session")
+	}
+}
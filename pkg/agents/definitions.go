@@ -0,0 +1,271 @@
+package agents
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+	"sync"
+
+	"gopkg.in/yaml.v3"
+)
+
+// Definition describes an agent CLI uzi can spawn. The built-in agents are
+// defined here; more are read from YAML files in Dir, one per agent:
+//
+//	name: aider
+//	command: aider --yes-always
+//	promptArgs: --message {prompt}
+//	status:
+//	  running: '^Tokens: .* sent'
+//	  ready: '^> $'
+//	nudge: ["Enter"]
+//	env:
+//	  AIDER_DARK_MODE: "true"
+type Definition struct {
+	// Name is the agent name used with --agents, e.g. "aider"; files
+	// without one are named after the file
+	Name string `yaml:"name"`
+	// Command starts the agent, with any arguments it always needs
+	Command string `yaml:"command"`
+	// PromptArgs is the template of the arguments that pass the initial
+	// prompt, where {prompt} is the quoted prompt; empty means "{prompt}"
+	PromptArgs string `yaml:"promptArgs"`
+	// Status holds regular expressions that tell from the agent pane what
+	// the agent is doing, checked before uzi's built-in rules
+	Status StatusPatterns `yaml:"status"`
+	// Nudge is the tmux key sequence uzi nudge sends when uzi.yaml sets none
+	// for the agent
+	Nudge []string `yaml:"nudge"`
+	// Env is set in the agent's tmux session
+	Env map[string]string `yaml:"env"`
+
+	running, ready *regexp.Regexp
+}
+
+// StatusPatterns are regular expressions matched against the lines of an
+// agent pane
+type StatusPatterns struct {
+	Running string `yaml:"running"` // the agent is working on a turn
+	Ready   string `yaml:"ready"`   // the agent is waiting for input
+}
+
+// builtins are the agents uzi supports without definition files
+var builtins = []Definition{
+	{Name: "claude", Command: "claude"},
+	{Name: "cursor", Command: "cursor"},
+	{Name: "codex", Command: "codex"},
+	{Name: "gemini", Command: "gemini", PromptArgs: "-p {prompt}"},
+}
+
+// RandomAgent is the agent name that spawns claude under a random name
+const RandomAgent = "random"
+
+var (
+	mu          sync.RWMutex
+	definitions = indexDefinitions(builtins)
+)
+
+func indexDefinitions(defs []Definition) map[string]Definition {
+	index := make(map[string]Definition, len(defs))
+	for _, def := range defs {
+		index[def.Name] = def
+	}
+	return index
+}
+
+// Dir returns the directory agent definitions are read from:
+// $XDG_CONFIG_HOME/uzi/agents, or ~/.config/uzi/agents
+func Dir() (string, error) {
+	if configHome := os.Getenv("XDG_CONFIG_HOME"); configHome != "" {
+		return filepath.Join(configHome, "uzi", "agents"), nil
+	}
+	homeDir, err := os.UserHomeDir()
+	if err != nil {
+		return "", fmt.Errorf("error getting home directory: %w", err)
+	}
+	return filepath.Join(homeDir, ".config", "uzi", "agents"), nil
+}
+
+// LoadDir reads the agent definitions in the *.yaml files of dir. A missing
+// directory has none.
+func LoadDir(dir string) ([]Definition, error) {
+	paths, err := filepath.Glob(filepath.Join(dir, "*.yaml"))
+	if err != nil {
+		return nil, err
+	}
+	sort.Strings(paths)
+	var defs []Definition
+	for _, path := range paths {
+		data, err := os.ReadFile(path)
+		if err != nil {
+			return nil, err
+		}
+		var def Definition
+		if err := yaml.Unmarshal(data, &def); err != nil {
+			return nil, fmt.Errorf("%s: %w", path, err)
+		}
+		if def.Name == "" {
+			def.Name = strings.TrimSuffix(filepath.Base(path), ".yaml")
+		}
+		if err := def.compile(); err != nil {
+			return nil, fmt.Errorf("%s: %w", path, err)
+		}
+		defs = append(defs, def)
+	}
+	return defs, nil
+}
+
+// compile checks a definition and compiles its status patterns
+func (d *Definition) compile() error {
+	if d.Name == RandomAgent {
+		return fmt.Errorf("%q is reserved for agents spawned under a random name", RandomAgent)
+	}
+	if strings.TrimSpace(d.Command) == "" {
+		return fmt.Errorf("agent %s has no command", d.Name)
+	}
+	if d.PromptArgs != "" && !strings.Contains(d.PromptArgs, "{prompt}") {
+		return fmt.Errorf("promptArgs of agent %s has no {prompt}", d.Name)
+	}
+	var err error
+	if d.Status.Running != "" {
+		if d.running, err = regexp.Compile("(?m)" + d.Status.Running); err != nil {
+			return fmt.Errorf("status.running of agent %s: %w", d.Name, err)
+		}
+	}
+	if d.Status.Ready != "" {
+		if d.ready, err = regexp.Compile("(?m)" + d.Status.Ready); err != nil {
+			return fmt.Errorf("status.ready of agent %s: %w", d.Name, err)
+		}
+	}
+	return nil
+}
+
+// Load reads the agent definitions in Dir and adds them to the built-in
+// agents, replacing those of the same name. uzi calls it at startup.
+func Load() error {
+	dir, err := Dir()
+	if err != nil {
+		return err
+	}
+	defs, err := LoadDir(dir)
+	if err != nil {
+		return err
+	}
+	Register(defs...)
+	return nil
+}
+
+// Register adds agent definitions read with LoadDir, replacing those of the
+// same name
+func Register(defs ...Definition) {
+	mu.Lock()
+	defer mu.Unlock()
+	for _, def := range defs {
+		definitions[def.Name] = def
+	}
+}
+
+// Names returns the names of the defined agents, sorted
+func Names() []string {
+	mu.RLock()
+	defer mu.RUnlock()
+	names := make([]string, 0, len(definitions))
+	for name := range definitions {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// Lookup returns the definition of an agent by name or by command, as
+// sessions record the command they were started with
+func Lookup(agent string) (Definition, bool) {
+	mu.RLock()
+	defer mu.RUnlock()
+	if def, ok := definitions[agent]; ok {
+		return def, true
+	}
+	for _, def := range definitions {
+		if def.Command == agent {
+			return def, true
+		}
+	}
+	return Definition{}, false
+}
+
+// Command returns the command that starts an agent. Agents without a
+// definition are taken to be commands themselves, and random agents run
+// claude.
+func Command(agent string) string {
+	if agent == RandomAgent {
+		agent = "claude"
+	}
+	if def, ok := Lookup(agent); ok {
+		return def.Command
+	}
+	return agent
+}
+
+// Invocation returns the command line that starts an agent command with its
+// model arguments and, unless it is empty, the initial prompt in double
+// quotes, as the agent's PromptArgs place it
+func Invocation(command, modelArgs, prompt string) string {
+	invocation := command
+	if modelArgs != "" {
+		invocation += " " + modelArgs
+	}
+	if prompt == "" {
+		return invocation
+	}
+	promptArgs := "{prompt}"
+	if def, ok := Lookup(command); ok && def.PromptArgs != "" {
+		promptArgs = def.PromptArgs
+	}
+	return invocation + " " + strings.ReplaceAll(promptArgs, "{prompt}", `"`+prompt+`"`)
+}
+
+// EnvFor returns the environment of an agent command's definition as
+// sorted KEY=value entries
+func EnvFor(command string) []string {
+	def, ok := Lookup(command)
+	if !ok {
+		return nil
+	}
+	env := make([]string, 0, len(def.Env))
+	for key, value := range def.Env {
+		env = append(env, key+"="+value)
+	}
+	sort.Strings(env)
+	return env
+}
+
+// NudgeFor returns the nudge keys of an agent's definition, by name or
+// command, or nil when it sets none
+func NudgeFor(agent string) []string {
+	if def, ok := Lookup(agent); ok {
+		return def.Nudge
+	}
+	return nil
+}
+
+// PaneStatus matches an agent pane against the status patterns of every
+// definition, since a pane does not say which agent drew it. Ready patterns
+// are checked first.
+func PaneStatus(content string) (running, ready bool) {
+	mu.RLock()
+	defer mu.RUnlock()
+	for _, def := range definitions {
+		if def.ready != nil && def.ready.MatchString(content) {
+			return false, true
+		}
+	}
+	for _, def := range definitions {
+		if def.running != nil && def.running.MatchString(content) {
+			return true, false
+		}
+	}
+	return false, false
+}
@@ -0,0 +1,120 @@
+package agents
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+// withDefinitions restores the built-in definitions after a test
+func withDefinitions(t *testing.T) {
+	t.Helper()
+	mu.Lock()
+	saved := definitions
+	definitions = indexDefinitions(builtins)
+	mu.Unlock()
+	t.Cleanup(func() {
+		mu.Lock()
+		definitions = saved
+		mu.Unlock()
+	})
+}
+
+func writeDefinition(t *testing.T, dir, file, content string) {
+	t.Helper()
+	if err := os.WriteFile(filepath.Join(dir, file), []byte(content), 0644); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestBuiltinDefinitions(t *testing.T) {
+	withDefinitions(t)
+	for agent, want := range map[string]string{"claude": "claude", "gemini": "gemini", "random": "claude", "my-cli": "my-cli"} {
+		if got := Command(agent); got != want {
+			t.Errorf("Command(%q) = %q, want %q", agent, got, want)
+		}
+	}
+	if got := Invocation("gemini", "--model pro", "fix it"); got != `gemini --model pro -p "fix it"` {
+		t.Errorf("Unexpected gemini invocation %q", got)
+	}
+	if got := Invocation("claude", "", "fix it"); got != `claude "fix it"` {
+		t.Errorf("Unexpected claude invocation %q", got)
+	}
+	if got := Invocation("claude", "--model opus", ""); got != "claude --model opus" {
+		t.Errorf("Expected no prompt arguments without a prompt, got %q", got)
+	}
+}
+
+func TestLoadDir(t *testing.T) {
+	withDefinitions(t)
+	dir := t.TempDir()
+	writeDefinition(t, dir, "aider.yaml", `command: aider --yes-always
+promptArgs: --message {prompt}
+status:
+  running: '^Tokens: .* sent'
+  ready: '^aider> $'
+nudge: ["y", "Enter"]
+env:
+  AIDER_DARK_MODE: "true"
+  AIDER_AUTO_COMMITS: "false"
+`)
+	writeDefinition(t, dir, "notes.txt", "not a definition")
+
+	defs, err := LoadDir(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(defs) != 1 || defs[0].Name != "aider" {
+		t.Fatalf("Expected the aider definition named after its file, got %+v", defs)
+	}
+	Register(defs...)
+
+	if got := Command("aider"); got != "aider --yes-always" {
+		t.Errorf("Command(aider) = %q", got)
+	}
+	if got := Invocation("aider --yes-always", "", "fix it"); got != `aider --yes-always --message "fix it"` {
+		t.Errorf("Unexpected aider invocation %q", got)
+	}
+	if got := EnvFor("aider --yes-always"); !reflect.DeepEqual(got, []string{"AIDER_AUTO_COMMITS=false", "AIDER_DARK_MODE=true"}) {
+		t.Errorf("Unexpected aider env %v", got)
+	}
+	if got := NudgeFor("aider --yes-always"); !reflect.DeepEqual(got, []string{"y", "Enter"}) {
+		t.Errorf("Unexpected aider nudge keys %v", got)
+	}
+	if !strings.Contains(strings.Join(Names(), ","), "aider") {
+		t.Errorf("Expected aider among the agents, got %v", Names())
+	}
+
+	if running, ready := PaneStatus("Tokens: 2.1k sent, 340 received\n"); !running || ready {
+		t.Errorf("Expected the running pattern to match, got %v, %v", running, ready)
+	}
+	if running, ready := PaneStatus("Done.\naider> \n"); running || !ready {
+		t.Errorf("Expected the ready pattern to match, got %v, %v", running, ready)
+	}
+	if running, ready := PaneStatus("> "); running || ready {
+		t.Errorf("Expected no pattern to match, got %v, %v", running, ready)
+	}
+}
+
+func TestLoadDirRejectsInvalidDefinitions(t *testing.T) {
+	for name, content := range map[string]string{
+		"no command":    "name: broken\n",
+		"bad pattern":   "command: x\nstatus:\n  running: '('\n",
+		"no prompt":     "command: x\npromptArgs: --message\n",
+		"reserved name": "name: random\ncommand: x\n",
+	} {
+		t.Run(name, func(t *testing.T) {
+			dir := t.TempDir()
+			writeDefinition(t, dir, "agent.yaml", content)
+			if _, err := LoadDir(dir); err == nil {
+				t.Error("Expected an error")
+			}
+		})
+	}
+
+	if defs, err := LoadDir(filepath.Join(t.TempDir(), "missing")); err != nil || len(defs) != 0 {
+		t.Errorf("Expected no definitions from a missing directory, got %v, %v", defs, err)
+	}
+}
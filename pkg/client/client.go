@@ -0,0 +1,218 @@
+// Package client is the supported way for Go programs outside uzi to read
+// the state of a repository's agents. It is read-only: sessions are listed
+// from uzi's state file and their status and diffs read from tmux and git, as
+// uzi ls does, and status changes are streamed from the log uzi auto keeps.
+//
+// The Session and Event types are stable: fields may be added, but existing
+// fields and their JSON names are not renamed or removed.
+//
+//	c := client.New("/path/to/repo")
+//	sessions, err := c.ListSessions()
+package client
+
+import (
+	"errors"
+	"fmt"
+	"os/exec"
+	"sort"
+	"time"
+
+	"github.com/nehpz/claudicus/pkg/heartbeat"
+	"github.com/nehpz/claudicus/pkg/hosts"
+	"github.com/nehpz/claudicus/pkg/state"
+)
+
+// ErrNotFound is returned by GetSession for an agent the repository has no
+// session for
+var ErrNotFound = errors.New("session not found")
+
+// Statuses a Session can have
+const (
+	StatusStarting = state.StatusStarting // the agent CLI is still loading
+	StatusRunning  = state.StatusRunning  // the agent is working on a turn
+	StatusReady    = state.StatusReady    // the agent is waiting for input
+	StatusStuck    = state.StatusStuck    // the agent has made no progress for a while
+	StatusDone     = state.StatusDone     // the agent signalled its task is complete
+	StatusPaused   = state.StatusPaused   // stopped by uzi pause
+	StatusDead     = state.StatusDead     // the tmux session has ended
+	StatusUnknown  = state.StatusUnknown  // the agent pane could not be read
+)
+
+// Session is one agent session of a repository
+type Session struct {
+	Name         string    `json:"name"`  // tmux session name
+	Agent        string    `json:"agent"` // agent name, e.g. "sarah"
+	Model        string    `json:"model"` // agent CLI, e.g. "claude"
+	Status       string    `json:"status"`
+	Prompt       string    `json:"prompt"`
+	Branch       string    `json:"branch,omitempty"`
+	BranchFrom   string    `json:"branch_from,omitempty"`
+	WorktreePath string    `json:"worktree_path,omitempty"`
+	Port         int       `json:"port,omitempty"`
+	DevServerURL string    `json:"dev_server_url,omitempty"`
+	Insertions   int       `json:"insertions"`
+	Deletions    int       `json:"deletions"`
+	Tags         []string  `json:"tags,omitempty"`
+	Channels     []string  `json:"channels,omitempty"`
+	Run          string    `json:"run,omitempty"`  // run from uzi prompt --run-name
+	Host         string    `json:"host,omitempty"` // remote host; empty for local sessions
+	Paused       bool      `json:"paused,omitempty"`
+	Done         bool      `json:"done,omitempty"`
+	Deadline     time.Time `json:"deadline,omitzero"` // end of the --max-runtime budget
+	CreatedAt    time.Time `json:"created_at"`
+	UpdatedAt    time.Time `json:"updated_at"`
+}
+
+// Client reads the agent sessions of one repository
+type Client struct {
+	repoRoot     string
+	statePath    string
+	pollInterval time.Duration
+	aggregator   *state.Aggregator
+}
+
+// Option configures a Client
+type Option func(*Client)
+
+// WithStateFile reads sessions from a state file other than uzi's default
+// ~/.local/share/uzi/state.json
+func WithStateFile(path string) Option {
+	return func(c *Client) { c.statePath = path }
+}
+
+// WithPollInterval sets how often StreamEvents checks for new events; the
+// default is a second
+func WithPollInterval(interval time.Duration) Option {
+	return func(c *Client) { c.pollInterval = interval }
+}
+
+// New returns a client for the repository at repoRoot
+func New(repoRoot string, opts ...Option) *Client {
+	c := &Client{
+		repoRoot:     repoRoot,
+		pollInterval: time.Second,
+		aggregator:   state.NewAggregator(state.WithTmuxStatus(), state.WithDiffs(), state.WithDevURLs(), state.WithCacheTTL(3*time.Second), state.WithRemoteProbe(hosts.RemoteProbe)),
+	}
+	for _, opt := range opts {
+		opt(c)
+	}
+	return c
+}
+
+// repoExecutor runs the state manager's git and tmux commands in the
+// client's repository rather than the working directory
+type repoExecutor struct {
+	dir string
+}
+
+func (e repoExecutor) ExecuteCommand(name string, args ...string) ([]byte, error) {
+	cmd := exec.Command(name, args...)
+	cmd.Dir = e.dir
+	return cmd.Output()
+}
+
+func (e repoExecutor) RunCommand(name string, args ...string) error {
+	cmd := exec.Command(name, args...)
+	cmd.Dir = e.dir
+	return cmd.Run()
+}
+
+// stateManager returns a state manager for the repository's sessions
+func (c *Client) stateManager() (*state.StateManager, error) {
+	if c.statePath != "" {
+		return state.NewStateManagerAt(c.statePath, repoExecutor{dir: c.repoRoot}), nil
+	}
+	sm := state.NewStateManagerWithDeps(state.NewDefaultFileSystem(), repoExecutor{dir: c.repoRoot})
+	if sm == nil {
+		return nil, fmt.Errorf("could not initialize state manager")
+	}
+	return sm, nil
+}
+
+// ListSessions returns the repository's sessions sorted by agent name,
+// including those whose tmux session has ended, with StatusDead
+func (c *Client) ListSessions() ([]Session, error) {
+	sm, err := c.stateManager()
+	if err != nil {
+		return nil, err
+	}
+	states, err := sm.StatesForRepo()
+	if err != nil {
+		return nil, fmt.Errorf("error loading sessions: %w", err)
+	}
+	activeSessions, err := sm.GetActiveSessionsForRepo()
+	if err != nil {
+		return nil, fmt.Errorf("error getting active sessions: %w", err)
+	}
+	alive := make(map[string]bool, len(activeSessions))
+	for _, sessionName := range activeSessions {
+		alive[sessionName] = true
+	}
+
+	sessions := make([]Session, 0, len(states))
+	for sessionName, agentState := range states {
+		sessions = append(sessions, c.session(sessionName, agentState, alive[sessionName]))
+	}
+	sort.Slice(sessions, func(i, j int) bool {
+		if sessions[i].Agent != sessions[j].Agent {
+			return sessions[i].Agent < sessions[j].Agent
+		}
+		return sessions[i].Name < sessions[j].Name
+	})
+	return sessions, nil
+}
+
+// GetSession returns the repository's session with the agent or session
+// name, or an error wrapping ErrNotFound
+func (c *Client) GetSession(name string) (Session, error) {
+	sessions, err := c.ListSessions()
+	if err != nil {
+		return Session{}, err
+	}
+	for _, session := range sessions {
+		if session.Name == name || session.Agent == name {
+			return session, nil
+		}
+	}
+	return Session{}, fmt.Errorf("%w: %s", ErrNotFound, name)
+}
+
+// session converts a saved session, reading the status and diff of a live one
+func (c *Client) session(sessionName string, agentState state.AgentState, alive bool) Session {
+	session := Session{
+		Name:         sessionName,
+		Agent:        state.AgentNameFromSession(sessionName),
+		Model:        agentState.Model,
+		Status:       StatusDead,
+		Prompt:       agentState.Prompt,
+		Branch:       agentState.BranchName,
+		BranchFrom:   agentState.BranchFrom,
+		WorktreePath: agentState.WorktreePath,
+		Port:         agentState.Port,
+		Tags:         agentState.Tags,
+		Channels:     agentState.Channels,
+		Run:          agentState.Run,
+		Host:         agentState.Host,
+		Paused:       agentState.Paused,
+		Done:         agentState.Done,
+		CreatedAt:    agentState.CreatedAt,
+		UpdatedAt:    agentState.UpdatedAt,
+	}
+	if deadline, ok := agentState.RuntimeDeadline(); ok {
+		session.Deadline = deadline
+	}
+	if !alive {
+		return session
+	}
+	info := c.aggregator.Session(sessionName, agentState)
+	session.Status = info.Status
+	session.Insertions = info.Insertions
+	session.Deletions = info.Deletions
+	session.DevServerURL = info.DevServerURL
+	return session
+}
+
+// heartbeatStore returns the store uzi auto logs status changes to
+func (c *Client) heartbeatStore() *heartbeat.Store {
+	return heartbeat.NewStore(heartbeat.Dir(c.repoRoot))
+}
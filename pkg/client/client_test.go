@@ -0,0 +1,221 @@
+package client
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/nehpz/claudicus/pkg/heartbeat"
+	"github.com/nehpz/claudicus/pkg/state"
+)
+
+// newTestRepo creates a git repository with an origin remote and a state file
+// holding sessions of it and of another repository
+func newTestRepo(t *testing.T) (string, string) {
+	t.Helper()
+	repoRoot := t.TempDir()
+	for _, args := range [][]string{
+		{"init", "-q"},
+		{"remote", "add", "origin", "git@example.com:acme/widgets.git"},
+	} {
+		cmd := exec.Command("git", args...)
+		cmd.Dir = repoRoot
+		if out, err := cmd.CombinedOutput(); err != nil {
+			t.Skipf("git %v: %v: %s", args, err, out)
+		}
+	}
+
+	created := time.Date(2026, 1, 2, 3, 4, 5, 0, time.UTC)
+	states := map[string]state.AgentState{
+		"agent-widgets-abc123-sarah": {
+			GitRepo:    "git@example.com:acme/widgets.git",
+			BranchName: "sarah",
+			Prompt:     "fix the login form",
+			Model:      "claude",
+			Run:        "login",
+			Tags:       []string{"frontend"},
+			CreatedAt:  created,
+			UpdatedAt:  created,
+		},
+		"agent-widgets-abc123-emily": {
+			GitRepo:    "git@example.com:acme/widgets.git",
+			BranchName: "emily",
+			Prompt:     "write the changelog",
+			Model:      "codex",
+			Done:       true,
+			CreatedAt:  created,
+			UpdatedAt:  created,
+		},
+		"agent-gadgets-def456-john": {
+			GitRepo:   "git@example.com:acme/gadgets.git",
+			Model:     "claude",
+			CreatedAt: created,
+			UpdatedAt: created,
+		},
+	}
+	data, err := json.Marshal(states)
+	if err != nil {
+		t.Fatal(err)
+	}
+	statePath := filepath.Join(t.TempDir(), "state.json")
+	if err := os.WriteFile(statePath, data, 0644); err != nil {
+		t.Fatal(err)
+	}
+	return repoRoot, statePath
+}
+
+func TestListSessions(t *testing.T) {
+	repoRoot, statePath := newTestRepo(t)
+	c := New(repoRoot, WithStateFile(statePath))
+
+	sessions, err := c.ListSessions()
+	if err != nil {
+		t.Fatalf("ListSessions() error = %v", err)
+	}
+	if len(sessions) != 2 {
+		t.Fatalf("ListSessions() returned %d sessions, want the 2 of the repository: %+v", len(sessions), sessions)
+	}
+	if sessions[0].Agent != "emily" || sessions[1].Agent != "sarah" {
+		t.Errorf("ListSessions() agents = %q, %q, want sorted emily, sarah", sessions[0].Agent, sessions[1].Agent)
+	}
+
+	sarah := sessions[1]
+	if sarah.Name != "agent-widgets-abc123-sarah" || sarah.Model != "claude" || sarah.Run != "login" || sarah.Prompt != "fix the login form" {
+		t.Errorf("ListSessions() sarah = %+v", sarah)
+	}
+	// No tmux session exists for the test sessions
+	if sarah.Status != StatusDead {
+		t.Errorf("ListSessions() sarah status = %q, want %q", sarah.Status, StatusDead)
+	}
+	if !sessions[0].Done {
+		t.Errorf("ListSessions() emily Done = false, want true")
+	}
+}
+
+func TestListSessionsMissingStateFile(t *testing.T) {
+	repoRoot, _ := newTestRepo(t)
+	c := New(repoRoot, WithStateFile(filepath.Join(t.TempDir(), "state.json")))
+
+	sessions, err := c.ListSessions()
+	if err != nil {
+		t.Fatalf("ListSessions() error = %v", err)
+	}
+	if len(sessions) != 0 {
+		t.Errorf("ListSessions() = %+v, want none", sessions)
+	}
+}
+
+func TestGetSession(t *testing.T) {
+	repoRoot, statePath := newTestRepo(t)
+	c := New(repoRoot, WithStateFile(statePath))
+
+	for _, name := range []string{"sarah", "agent-widgets-abc123-sarah"} {
+		session, err := c.GetSession(name)
+		if err != nil {
+			t.Fatalf("GetSession(%q) error = %v", name, err)
+		}
+		if session.Name != "agent-widgets-abc123-sarah" {
+			t.Errorf("GetSession(%q) = %q, want agent-widgets-abc123-sarah", name, session.Name)
+		}
+	}
+
+	// Sessions of other repositories are not found
+	if _, err := c.GetSession("john"); !errors.Is(err, ErrNotFound) {
+		t.Errorf("GetSession(\"john\") error = %v, want ErrNotFound", err)
+	}
+}
+
+func TestSessionJSON(t *testing.T) {
+	data, err := json.Marshal(Session{Name: "agent-widgets-abc123-sarah", Agent: "sarah", Status: StatusReady})
+	if err != nil {
+		t.Fatal(err)
+	}
+	var fields map[string]any
+	if err := json.Unmarshal(data, &fields); err != nil {
+		t.Fatal(err)
+	}
+	for _, key := range []string{"name", "agent", "model", "status", "prompt", "insertions", "deletions", "created_at", "updated_at"} {
+		if _, ok := fields[key]; !ok {
+			t.Errorf("Session JSON has no %q field: %s", key, data)
+		}
+	}
+	if _, ok := fields["deadline"]; ok {
+		t.Errorf("Session JSON has a deadline without a runtime budget: %s", data)
+	}
+}
+
+func TestStreamEvents(t *testing.T) {
+	repoRoot := t.TempDir()
+	store := heartbeat.NewStore(heartbeat.Dir(repoRoot))
+	start := time.Now().Add(-time.Hour).Truncate(time.Second)
+	if err := store.AppendTransition(heartbeat.Transition{Session: "agent-widgets-abc123-sarah", Agent: "sarah", From: "", To: "running", At: start.Add(-time.Minute)}); err != nil {
+		t.Fatal(err)
+	}
+	if err := store.AppendTransition(heartbeat.Transition{Session: "agent-widgets-abc123-sarah", Agent: "sarah", From: "running", To: "ready", At: start.Add(time.Minute), Insertions: 3}); err != nil {
+		t.Fatal(err)
+	}
+
+	c := New(repoRoot, WithPollInterval(10*time.Millisecond))
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	var events []Event
+	appended := false
+	err := c.StreamEvents(ctx, start, func(event Event) error {
+		events = append(events, event)
+		if !appended {
+			// Logged after streaming started; both share a timestamp
+			appended = true
+			at := start.Add(2 * time.Minute)
+			for _, to := range []string{"stuck", "done"} {
+				if err := store.AppendTransition(heartbeat.Transition{Session: "agent-widgets-abc123-sarah", Agent: "sarah", From: "ready", To: to, At: at}); err != nil {
+					return err
+				}
+			}
+		}
+		if len(events) == 3 {
+			cancel()
+		}
+		return nil
+	})
+	if !errors.Is(err, context.Canceled) {
+		t.Fatalf("StreamEvents() error = %v, want context.Canceled", err)
+	}
+
+	var got []string
+	for _, event := range events {
+		got = append(got, event.To)
+	}
+	want := []string{"ready", "stuck", "done"}
+	if len(got) != len(want) {
+		t.Fatalf("StreamEvents() delivered %v, want %v", got, want)
+	}
+	for i := range want {
+		if got[i] != want[i] {
+			t.Fatalf("StreamEvents() delivered %v, want %v", got, want)
+		}
+	}
+	if events[0].Insertions != 3 || events[0].From != "running" {
+		t.Errorf("StreamEvents() first event = %+v", events[0])
+	}
+}
+
+func TestStreamEventsStopsOnError(t *testing.T) {
+	repoRoot := t.TempDir()
+	store := heartbeat.NewStore(heartbeat.Dir(repoRoot))
+	if err := store.AppendTransition(heartbeat.Transition{Session: "agent-widgets-abc123-sarah", Agent: "sarah", To: "running", At: time.Now()}); err != nil {
+		t.Fatal(err)
+	}
+
+	stop := errors.New("stop")
+	c := New(repoRoot, WithPollInterval(10*time.Millisecond))
+	err := c.StreamEvents(context.Background(), time.Time{}, func(Event) error { return stop })
+	if !errors.Is(err, stop) {
+		t.Errorf("StreamEvents() error = %v, want the callback's", err)
+	}
+}
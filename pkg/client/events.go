@@ -0,0 +1,78 @@
+package client
+
+import (
+	"context"
+	"time"
+)
+
+// Event is a change of a session's status, as seen by uzi auto
+type Event struct {
+	Session    string    `json:"session"`
+	Agent      string    `json:"agent"`
+	From       string    `json:"from,omitempty"` // empty for the first status seen
+	To         string    `json:"to"`
+	At         time.Time `json:"at"`
+	Insertions int       `json:"insertions"` // size of the agent's diff at the time
+	Deletions  int       `json:"deletions"`
+	Snapshot   string    `json:"snapshot,omitempty"` // file the agent pane was saved to, if it was
+}
+
+// Events returns the status changes logged since a time, oldest first.
+// uzi auto logs them while it runs and keeps them for a week.
+func (c *Client) Events(since time.Time) ([]Event, error) {
+	transitions, err := c.heartbeatStore().Transitions(since)
+	if err != nil {
+		return nil, err
+	}
+	events := make([]Event, 0, len(transitions))
+	for _, t := range transitions {
+		events = append(events, Event{
+			Session:    t.Session,
+			Agent:      t.Agent,
+			From:       t.From,
+			To:         t.To,
+			At:         t.At,
+			Insertions: t.Insertions,
+			Deletions:  t.Deletions,
+			Snapshot:   t.Snapshot,
+		})
+	}
+	return events, nil
+}
+
+// StreamEvents calls fn with every status change logged since a time, oldest
+// first, then with each new one as uzi auto logs it, until ctx is done or fn
+// returns an error. It returns that error, or the context's.
+func (c *Client) StreamEvents(ctx context.Context, since time.Time, fn func(Event) error) error {
+	// Events logged at the same instant as the last one delivered are told
+	// apart by what they record
+	cursor := since
+	delivered := map[Event]bool{}
+	ticker := time.NewTicker(c.pollInterval)
+	defer ticker.Stop()
+	for {
+		events, err := c.Events(cursor)
+		if err != nil {
+			return err
+		}
+		for _, event := range events {
+			if delivered[event] {
+				continue
+			}
+			if err := fn(event); err != nil {
+				return err
+			}
+			if event.At.After(cursor) {
+				cursor = event.At
+				clear(delivered)
+			}
+			delivered[event] = true
+		}
+
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-ticker.C:
+		}
+	}
+}
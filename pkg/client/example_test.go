@@ -0,0 +1,35 @@
+package client_test
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"time"
+
+	"github.com/nehpz/claudicus/pkg/client"
+)
+
+func Example() {
+	c := client.New("/path/to/repo")
+
+	sessions, err := c.ListSessions()
+	if err != nil {
+		log.Fatal(err)
+	}
+	for _, session := range sessions {
+		fmt.Printf("%s\t%s\t+%d -%d\n", session.Agent, session.Status, session.Insertions, session.Deletions)
+	}
+}
+
+func ExampleClient_StreamEvents() {
+	c := client.New("/path/to/repo")
+
+	// Print each status change from the last hour on, until interrupted
+	err := c.StreamEvents(context.Background(), time.Now().Add(-time.Hour), func(event client.Event) error {
+		fmt.Printf("%s: %s -> %s\n", event.Agent, event.From, event.To)
+		return nil
+	})
+	if err != nil {
+		log.Fatal(err)
+	}
+}
@@ -0,0 +1,172 @@
+// Package cmdctx holds what every uzi command works with: the loaded
+// uzi.yaml, the state store, the logger, and the TUI's UziCLI backend. The
+// root command creates one CommandContext from the global flags and passes
+// it to the subcommands through their context.Context, so every command
+// sees the same repository, config, and log level.
+package cmdctx
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"sync"
+	"time"
+
+	"github.com/nehpz/claudicus/pkg/config"
+	"github.com/nehpz/claudicus/pkg/events"
+	"github.com/nehpz/claudicus/pkg/state"
+	"github.com/nehpz/claudicus/pkg/tui"
+
+	"github.com/charmbracelet/log"
+)
+
+// CommandContext is shared by the commands of one uzi invocation. Its parts
+// are created when first asked for, so a command only pays for what it uses.
+type CommandContext struct {
+	// ConfigPath is the uzi.yaml Config loads; commands with their own
+	// --config flag pass that path to LoadConfig instead
+	ConfigPath string
+	// JSON is set by the global --json flag; commands that can print JSON do
+	// so when it or their own --json is set
+	JSON bool
+	// Logger is the logger commands write to, also installed as the default
+	// logger of the log package
+	Logger *log.Logger
+
+	mu      sync.Mutex
+	configs map[string]loadedConfig
+	state   *state.StateManager
+	uzi     *tui.UziCLI
+	events  map[*config.WebhooksConfig]*events.Dispatcher
+}
+
+// loadedConfig is the result of loading one config path, as of the
+// modification time of the file
+type loadedConfig struct {
+	modTime time.Time
+	cfg     *config.Config
+	err     error
+}
+
+// New creates a CommandContext that loads config from configPath and logs at
+// level. The session naming template of the config, if it loads, is applied
+// right away, since session names are read back with it.
+func New(configPath string, level log.Level) *CommandContext {
+	logger := log.NewWithOptions(os.Stderr, log.Options{Level: level})
+	c := &CommandContext{ConfigPath: configPath, Logger: logger}
+	if cfg, err := c.Config(); err == nil {
+		state.SetSessionTemplate(cfg.SessionTemplate())
+	}
+	return c
+}
+
+// Default returns a CommandContext for the default config path that logs
+// with the default logger, for commands run without the root command such
+// as in tests
+func Default() *CommandContext {
+	return &CommandContext{ConfigPath: config.GetDefaultConfigPath(), Logger: log.Default()}
+}
+
+// WantJSON reports whether a command prints JSON, given whether its own
+// --json flag is set
+func (c *CommandContext) WantJSON(flag bool) bool {
+	return flag || c.JSON
+}
+
+// Config returns the config at ConfigPath
+func (c *CommandContext) Config() (*config.Config, error) {
+	return c.LoadConfig(c.ConfigPath)
+}
+
+// LoadConfig returns the config at path. A path is loaded again only once
+// the file changed, so long-running commands such as uzi auto see edits.
+func (c *CommandContext) LoadConfig(path string) (*config.Config, error) {
+	var modTime time.Time
+	if info, err := os.Stat(path); err == nil {
+		modTime = info.ModTime()
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if loaded, ok := c.configs[path]; ok && loaded.modTime.Equal(modTime) {
+		return loaded.cfg, loaded.err
+	}
+	cfg, err := config.LoadConfig(path)
+	if c.configs == nil {
+		c.configs = make(map[string]loadedConfig)
+	}
+	c.configs[path] = loadedConfig{modTime: modTime, cfg: cfg, err: err}
+	return cfg, err
+}
+
+// State returns the state store, creating it on first use
+func (c *CommandContext) State() (*state.StateManager, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.state == nil {
+		c.state = state.NewStateManager()
+		if c.state == nil {
+			return nil, fmt.Errorf("could not initialize state manager")
+		}
+	}
+	return c.state, nil
+}
+
+// Uzi returns the UziCLI backend the TUI drives uzi with, creating it on
+// first use
+func (c *CommandContext) Uzi() *tui.UziCLI {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.uzi == nil {
+		c.uzi = tui.NewUziCLI()
+	}
+	return c.uzi
+}
+
+// Events returns the dispatcher for the webhooks of cfg, creating it on
+// first use. It is nil, and drops every event, when cfg has no webhooks.
+func (c *CommandContext) Events(cfg *config.Config) *events.Dispatcher {
+	if cfg == nil || cfg.Webhooks == nil {
+		return nil
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if d, ok := c.events[cfg.Webhooks]; ok {
+		return d
+	}
+	if c.events == nil {
+		c.events = make(map[*config.WebhooksConfig]*events.Dispatcher)
+	}
+	d := events.NewDispatcher(cfg.Webhooks)
+	c.events[cfg.Webhooks] = d
+	return d
+}
+
+// Wait blocks until the webhook events dispatched through Events have been
+// delivered, so the process does not exit with them in flight
+func (c *CommandContext) Wait() {
+	c.mu.Lock()
+	dispatchers := make([]*events.Dispatcher, 0, len(c.events))
+	for _, d := range c.events {
+		dispatchers = append(dispatchers, d)
+	}
+	c.mu.Unlock()
+	for _, d := range dispatchers {
+		d.Wait()
+	}
+}
+
+type contextKey struct{}
+
+// With returns a copy of ctx that carries c to the commands run with it
+func With(ctx context.Context, c *CommandContext) context.Context {
+	return context.WithValue(ctx, contextKey{}, c)
+}
+
+// From returns the CommandContext ctx carries, or Default when it carries
+// none
+func From(ctx context.Context) *CommandContext {
+	if c, ok := ctx.Value(contextKey{}).(*CommandContext); ok {
+		return c
+	}
+	return Default()
+}
@@ -0,0 +1,93 @@
+package cmdctx
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/nehpz/claudicus/pkg/config"
+
+	"github.com/charmbracelet/log"
+)
+
+func TestFrom(t *testing.T) {
+	if c := From(context.Background()); c == nil || c.Logger == nil {
+		t.Fatal("Expected a default context when none is carried")
+	}
+
+	c := New(filepath.Join(t.TempDir(), "uzi.yaml"), log.DebugLevel)
+	if got := From(With(context.Background(), c)); got != c {
+		t.Error("Expected the carried context back")
+	}
+	if c.Logger.GetLevel() != log.DebugLevel {
+		t.Errorf("Expected the logger at debug level, got %v", c.Logger.GetLevel())
+	}
+}
+
+func TestWantJSON(t *testing.T) {
+	c := Default()
+	if c.WantJSON(false) {
+		t.Error("Expected no JSON without flags")
+	}
+	if !c.WantJSON(true) {
+		t.Error("Expected JSON with the command's own flag")
+	}
+	c.JSON = true
+	if !c.WantJSON(false) {
+		t.Error("Expected JSON with the global flag")
+	}
+}
+
+func TestLoadConfigReloadsChangedFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "uzi.yaml")
+	if err := os.WriteFile(path, []byte("devCommand: npm run dev\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	c := New(path, log.InfoLevel)
+
+	first, err := c.Config()
+	if err != nil {
+		t.Fatal(err)
+	}
+	again, err := c.LoadConfig(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if again != first {
+		t.Error("Expected an unchanged file to be loaded once")
+	}
+
+	if err := os.WriteFile(path, []byte("devCommand: yarn dev\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	later := time.Now().Add(time.Minute)
+	if err := os.Chtimes(path, later, later); err != nil {
+		t.Fatal(err)
+	}
+	changed, err := c.Config()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if changed.DevCommand == nil || *changed.DevCommand != "yarn dev" {
+		t.Errorf("Expected the edited config, got %+v", changed.DevCommand)
+	}
+}
+
+func TestEvents(t *testing.T) {
+	c := Default()
+	if c.Events(nil) != nil || c.Events(&config.Config{}) != nil {
+		t.Error("Expected no dispatcher without webhooks")
+	}
+
+	cfg := &config.Config{Webhooks: &config.WebhooksConfig{OnKill: "http://127.0.0.1:0/hook"}}
+	d := c.Events(cfg)
+	if d == nil {
+		t.Fatal("Expected a dispatcher for the configured webhooks")
+	}
+	if c.Events(cfg) != d {
+		t.Error("Expected the dispatcher to be reused")
+	}
+	c.Wait()
+}
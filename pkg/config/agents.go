@@ -0,0 +1,37 @@
+package config
+
+import (
+	"strconv"
+	"strings"
+)
+
+// SpawnAgents returns the agents to spawn when none are given, from
+// defaultAgents or else its older name agents, and whether either is set
+func (c *Config) SpawnAgents() (string, bool) {
+	if c == nil {
+		return "", false
+	}
+	if c.DefaultAgents != nil {
+		return strings.TrimSpace(*c.DefaultAgents), true
+	}
+	if c.Agents != nil {
+		return strings.TrimSpace(*c.Agents), true
+	}
+	return "", false
+}
+
+// DefaultAgent returns the first agent of SpawnAgents and how many of it to
+// spawn, for forms that spawn a single kind of agent
+func (c *Config) DefaultAgent() (agent string, count int, ok bool) {
+	agents, ok := c.SpawnAgents()
+	if !ok {
+		return "", 0, false
+	}
+	first := strings.SplitN(agents, ",", 2)[0]
+	agent, countStr, found := strings.Cut(strings.TrimSpace(first), ":")
+	count, err := strconv.Atoi(strings.TrimSpace(countStr))
+	if !found || strings.TrimSpace(agent) == "" || err != nil || count < 1 {
+		return "", 0, false
+	}
+	return strings.TrimSpace(agent), count, true
+}
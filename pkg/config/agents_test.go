@@ -0,0 +1,49 @@
+package config
+
+import (
+	"testing"
+
+	"gopkg.in/yaml.v3"
+)
+
+func TestSpawnAgents(t *testing.T) {
+	var unset *Config
+	if _, ok := unset.SpawnAgents(); ok {
+		t.Error("Expected no default agents without config")
+	}
+
+	for _, tt := range []struct {
+		yaml      string
+		want      string
+		wantOK    bool
+		wantAgent string
+		wantCount int
+	}{
+		{"defaultAgents: claude:2\n", "claude:2", true, "claude", 2},
+		{"defaultAgents: \" codex:1, claude:3\"\n", "codex:1, claude:3", true, "codex", 1},
+		{"agents: gemini:1\n", "gemini:1", true, "gemini", 1},
+		{"defaultAgents: claude:2\nagents: codex:1\n", "claude:2", true, "claude", 2},
+		{"defaultAgents: claude\n", "claude", true, "", 0},
+		{"devCommand: make dev\n", "", false, "", 0},
+	} {
+		var cfg Config
+		if err := yaml.Unmarshal([]byte(tt.yaml), &cfg); err != nil {
+			t.Fatal(err)
+		}
+		if got, ok := cfg.SpawnAgents(); got != tt.want || ok != tt.wantOK {
+			t.Errorf("%q: SpawnAgents() = %q, %v, want %q, %v", tt.yaml, got, ok, tt.want, tt.wantOK)
+		}
+		agent, count, ok := cfg.DefaultAgent()
+		if agent != tt.wantAgent || count != tt.wantCount || ok != (tt.wantAgent != "") {
+			t.Errorf("%q: DefaultAgent() = %q, %d, %v, want %q, %d", tt.yaml, agent, count, ok, tt.wantAgent, tt.wantCount)
+		}
+	}
+
+	var empty Config
+	if err := yaml.Unmarshal([]byte("defaultAgents: \" \"\n"), &empty); err != nil {
+		t.Fatal(err)
+	}
+	if err := empty.Validate(); err == nil {
+		t.Error("Expected an empty defaultAgents to be rejected")
+	}
+}
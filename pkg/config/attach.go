@@ -0,0 +1,38 @@
+package config
+
+import "fmt"
+
+// AttachMode is how the TUI opens the selected agent on Enter
+type AttachMode string
+
+const (
+	// AttachModeAttach replaces the TUI with tmux attach-session
+	AttachModeAttach AttachMode = "attach"
+	// AttachModeWindow opens the agent in a new window of the tmux client the
+	// TUI runs in
+	AttachModeWindow AttachMode = "window"
+	// AttachModePane opens the agent in a pane split off the TUI's own
+	AttachModePane AttachMode = "pane"
+)
+
+// ParseAttachMode parses a tui.attachMode value
+func ParseAttachMode(mode string) (AttachMode, error) {
+	switch AttachMode(mode) {
+	case AttachModeAttach, AttachModeWindow, AttachModePane:
+		return AttachMode(mode), nil
+	}
+	return "", fmt.Errorf("invalid attachMode %q (use attach, window, or pane)", mode)
+}
+
+// AttachMode returns how the TUI opens agents, attach unless tui.attachMode
+// is set
+func (c *Config) AttachMode() AttachMode {
+	if c == nil || c.TUI == nil {
+		return AttachModeAttach
+	}
+	mode, err := ParseAttachMode(c.TUI.AttachMode)
+	if err != nil {
+		return AttachModeAttach
+	}
+	return mode
+}
@@ -0,0 +1,37 @@
+package config
+
+import (
+	"testing"
+
+	"gopkg.in/yaml.v3"
+)
+
+func TestAttachMode(t *testing.T) {
+	var unset *Config
+	if got := unset.AttachMode(); got != AttachModeAttach {
+		t.Errorf("Expected attach without config, got %q", got)
+	}
+
+	for _, tt := range []struct {
+		yaml    string
+		want    AttachMode
+		wantErr bool
+	}{
+		{"tui:\n  keys:\n    kill: x\n", AttachModeAttach, false},
+		{"tui:\n  attachMode: attach\n", AttachModeAttach, false},
+		{"tui:\n  attachMode: window\n", AttachModeWindow, false},
+		{"tui:\n  attachMode: pane\n", AttachModePane, false},
+		{"tui:\n  attachMode: split\n", AttachModeAttach, true},
+	} {
+		var cfg Config
+		if err := yaml.Unmarshal([]byte(tt.yaml), &cfg); err != nil {
+			t.Fatal(err)
+		}
+		if got := cfg.AttachMode(); got != tt.want {
+			t.Errorf("%q: AttachMode() = %q, want %q", tt.yaml, got, tt.want)
+		}
+		if err := cfg.Validate(); (err != nil) != tt.wantErr {
+			t.Errorf("%q: Validate() error = %v, wantErr %v", tt.yaml, err, tt.wantErr)
+		}
+	}
+}
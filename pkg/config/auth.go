@@ -0,0 +1,54 @@
+package config
+
+import (
+	"regexp"
+	"sort"
+)
+
+// DefaultAPIKeyEnv maps the agent types uzi knows to the environment
+// variable their CLI reads its API key from
+var DefaultAPIKeyEnv = map[string]string{
+	"claude": "ANTHROPIC_API_KEY",
+	"codex":  "OPENAI_API_KEY",
+	"gemini": "GEMINI_API_KEY",
+	"cursor": "CURSOR_API_KEY",
+}
+
+// AuthConfig sets how API keys stored with `uzi auth set` reach the agents
+type AuthConfig struct {
+	// Env maps an agent type to the environment variable its stored API key
+	// is exported as, adding to or overriding DefaultAPIKeyEnv
+	Env map[string]string `yaml:"env"`
+}
+
+var envNameRe = regexp.MustCompile(`^[A-Za-z_][A-Za-z0-9_]*$`)
+
+// APIKeyEnv returns the environment variable the API key of an agent type is
+// exported as in its pane, or "" when uzi doesn't know one
+func (c *Config) APIKeyEnv(agent string) string {
+	if c != nil && c.Auth != nil {
+		if name, ok := c.Auth.Env[agent]; ok {
+			return name
+		}
+	}
+	return DefaultAPIKeyEnv[agent]
+}
+
+// APIKeyAgents returns, sorted, the agent types an API key can be stored for
+func (c *Config) APIKeyAgents() []string {
+	seen := make(map[string]bool)
+	for agent := range DefaultAPIKeyEnv {
+		seen[agent] = true
+	}
+	if c != nil && c.Auth != nil {
+		for agent := range c.Auth.Env {
+			seen[agent] = true
+		}
+	}
+	agents := make([]string, 0, len(seen))
+	for agent := range seen {
+		agents = append(agents, agent)
+	}
+	sort.Strings(agents)
+	return agents
+}
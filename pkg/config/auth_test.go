@@ -0,0 +1,42 @@
+package config
+
+import (
+	"reflect"
+	"testing"
+
+	"gopkg.in/yaml.v3"
+)
+
+func TestAPIKeyEnv(t *testing.T) {
+	var unset *Config
+	if got := unset.APIKeyEnv("claude"); got != "ANTHROPIC_API_KEY" {
+		t.Errorf("Expected the default variable without config, got %q", got)
+	}
+
+	var cfg Config
+	if err := yaml.Unmarshal([]byte("auth:\n  env:\n    codex: CODEX_API_KEY\n    aider: AIDER_OPENAI_KEY\n"), &cfg); err != nil {
+		t.Fatal(err)
+	}
+	if err := cfg.Validate(); err != nil {
+		t.Fatalf("Validate() = %v", err)
+	}
+	for agent, want := range map[string]string{
+		"claude":  "ANTHROPIC_API_KEY",
+		"codex":   "CODEX_API_KEY",
+		"aider":   "AIDER_OPENAI_KEY",
+		"unknown": "",
+	} {
+		if got := cfg.APIKeyEnv(agent); got != want {
+			t.Errorf("APIKeyEnv(%q) = %q, want %q", agent, got, want)
+		}
+	}
+	want := []string{"aider", "claude", "codex", "cursor", "gemini"}
+	if got := cfg.APIKeyAgents(); !reflect.DeepEqual(got, want) {
+		t.Errorf("APIKeyAgents() = %v, want %v", got, want)
+	}
+
+	bad := &Config{Auth: &AuthConfig{Env: map[string]string{"claude": "API KEY"}}}
+	if err := bad.Validate(); err == nil {
+		t.Error("Expected Validate to reject an invalid variable name")
+	}
+}
@@ -0,0 +1,200 @@
+package config
+
+import (
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+	"time"
+)
+
+// Defaults of the broadcastDelivery: section
+const (
+	DefaultBroadcastAckTimeout    = 5 * time.Second
+	DefaultBroadcastSerialTimeout = 10 * time.Minute
+)
+
+// BroadcastDeliveryConfig paces `uzi broadcast` for fleets of busy agents
+type BroadcastDeliveryConfig struct {
+	// Delay is the pause between sessions, e.g. "500ms"
+	Delay string `yaml:"delay"`
+	// Ack types each message and submits it only once it shows in the
+	// agent's pane
+	Ack bool `yaml:"ack"`
+	// AckTimeout is how long an acked message may take to show, e.g. "5s"
+	AckTimeout string `yaml:"ackTimeout"`
+	// Serial waits for each agent to return to ready before messaging the next
+	Serial bool `yaml:"serial"`
+	// SerialTimeout is how long to wait for an agent to return to ready
+	SerialTimeout string `yaml:"serialTimeout"`
+}
+
+// BroadcastTemplate is a named message from the broadcasts: section of uzi.yaml
+type BroadcastTemplate struct {
+	Name    string
+	Message string
+}
+
+// BroadcastTemplate returns the message of the named broadcast template
+func (c *Config) BroadcastTemplate(name string) (string, error) {
+	if c != nil {
+		if message, ok := c.Broadcasts[name]; ok {
+			return message, nil
+		}
+	}
+	return "", fmt.Errorf("unknown broadcast template %q: add it under broadcasts: in uzi.yaml", name)
+}
+
+// BroadcastTemplates returns the configured broadcast templates sorted by name
+func (c *Config) BroadcastTemplates() []BroadcastTemplate {
+	if c == nil {
+		return nil
+	}
+	templates := make([]BroadcastTemplate, 0, len(c.Broadcasts))
+	for name, message := range c.Broadcasts {
+		templates = append(templates, BroadcastTemplate{Name: name, Message: message})
+	}
+	sort.Slice(templates, func(i, j int) bool { return templates[i].Name < templates[j].Name })
+	return templates
+}
+
+// ExpandBroadcast fills in the {agent} and {branch} placeholders of a
+// broadcast message for one target session
+func ExpandBroadcast(message, agent, branch string) string {
+	return strings.NewReplacer("{agent}", agent, "{branch}", branch).Replace(message)
+}
+
+// ParseBroadcastDuration parses a duration of the broadcastDelivery: section
+// such as "500ms" or "2m"
+func ParseBroadcastDuration(field, value string) (time.Duration, error) {
+	d, err := time.ParseDuration(strings.TrimSpace(value))
+	if err != nil || d < 0 {
+		return 0, fmt.Errorf("invalid broadcastDelivery.%s %q (e.g. 500ms or 2m)", field, value)
+	}
+	return d, nil
+}
+
+// broadcastDuration returns a duration of the broadcastDelivery: section, or
+// fallback when it is unset or invalid
+func broadcastDuration(field, value string, fallback time.Duration) time.Duration {
+	if value == "" {
+		return fallback
+	}
+	d, err := ParseBroadcastDuration(field, value)
+	if err != nil {
+		return fallback
+	}
+	return d
+}
+
+// BroadcastDelay returns the pause between the sessions of a broadcast
+func (c *Config) BroadcastDelay() time.Duration {
+	if c == nil || c.BroadcastDelivery == nil {
+		return 0
+	}
+	return broadcastDuration("delay", c.BroadcastDelivery.Delay, 0)
+}
+
+// BroadcastAck reports whether broadcasts wait for each message to show in
+// the agent's pane before submitting it
+func (c *Config) BroadcastAck() bool {
+	return c != nil && c.BroadcastDelivery != nil && c.BroadcastDelivery.Ack
+}
+
+// BroadcastAckTimeout returns how long an acked message may take to show
+func (c *Config) BroadcastAckTimeout() time.Duration {
+	if c == nil || c.BroadcastDelivery == nil {
+		return DefaultBroadcastAckTimeout
+	}
+	return broadcastDuration("ackTimeout", c.BroadcastDelivery.AckTimeout, DefaultBroadcastAckTimeout)
+}
+
+// BroadcastSerial reports whether broadcasts wait for each agent to return to
+// ready before messaging the next
+func (c *Config) BroadcastSerial() bool {
+	return c != nil && c.BroadcastDelivery != nil && c.BroadcastDelivery.Serial
+}
+
+// BroadcastSerialTimeout returns how long a serial broadcast waits for an
+// agent to return to ready
+func (c *Config) BroadcastSerialTimeout() time.Duration {
+	if c == nil || c.BroadcastDelivery == nil {
+		return DefaultBroadcastSerialTimeout
+	}
+	return broadcastDuration("serialTimeout", c.BroadcastDelivery.SerialTimeout, DefaultBroadcastSerialTimeout)
+}
+
+// validate checks the durations of the broadcastDelivery: section
+func (b *BroadcastDeliveryConfig) validate() error {
+	for field, value := range map[string]string{"delay": b.Delay, "ackTimeout": b.AckTimeout, "serialTimeout": b.SerialTimeout} {
+		if value == "" {
+			continue
+		}
+		if _, err := ParseBroadcastDuration(field, value); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+var channelRe = regexp.MustCompile(`^[A-Za-z0-9][A-Za-z0-9_.-]*$`)
+
+// ValidateChannel checks a broadcast channel name: letters, digits, '.', '_'
+// and '-', so it can follow '#' in a TUI broadcast
+func ValidateChannel(name string) error {
+	if !channelRe.MatchString(name) {
+		return fmt.Errorf("invalid channel name %q: use letters, digits, '.', '_' and '-'", name)
+	}
+	return nil
+}
+
+// ChannelNames returns the channels configured under channels: sorted by name
+func (c *Config) ChannelNames() []string {
+	if c == nil {
+		return nil
+	}
+	names := make([]string, 0, len(c.Channels))
+	for name := range c.Channels {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// SessionChannels returns the channels a session subscribes to: the ones
+// given explicitly plus those its tags map to under channels:, sorted and
+// without duplicates
+func (c *Config) SessionChannels(channels, tags []string) []string {
+	seen := map[string]bool{}
+	var subscribed []string
+	add := func(channel string) {
+		if !seen[channel] {
+			seen[channel] = true
+			subscribed = append(subscribed, channel)
+		}
+	}
+	for _, channel := range channels {
+		add(channel)
+	}
+	if c != nil {
+		for channel, channelTags := range c.Channels {
+			for _, tag := range channelTags {
+				if hasString(tags, tag) {
+					add(channel)
+					break
+				}
+			}
+		}
+	}
+	sort.Strings(subscribed)
+	return subscribed
+}
+
+func hasString(list []string, s string) bool {
+	for _, item := range list {
+		if item == s {
+			return true
+		}
+	}
+	return false
+}
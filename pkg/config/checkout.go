@@ -0,0 +1,38 @@
+package config
+
+import "fmt"
+
+// DirtyCheckoutPolicy is what spawning agents does when the main checkout has
+// uncommitted changes or is behind its upstream branch
+type DirtyCheckoutPolicy string
+
+const (
+	// DirtyCheckoutWarn spawns the agents after printing a warning
+	DirtyCheckoutWarn DirtyCheckoutPolicy = "warn"
+	// DirtyCheckoutBlock refuses to spawn unless --allow-dirty is given
+	DirtyCheckoutBlock DirtyCheckoutPolicy = "block"
+	// DirtyCheckoutAllow spawns without checking the checkout
+	DirtyCheckoutAllow DirtyCheckoutPolicy = "allow"
+)
+
+// ParseDirtyCheckoutPolicy parses a dirtyCheckout value
+func ParseDirtyCheckoutPolicy(policy string) (DirtyCheckoutPolicy, error) {
+	switch DirtyCheckoutPolicy(policy) {
+	case DirtyCheckoutWarn, DirtyCheckoutBlock, DirtyCheckoutAllow:
+		return DirtyCheckoutPolicy(policy), nil
+	}
+	return "", fmt.Errorf("invalid dirtyCheckout %q (use warn, block, or allow)", policy)
+}
+
+// DirtyCheckout returns what spawning does from a dirty main checkout, warn
+// unless dirtyCheckout is set
+func (c *Config) DirtyCheckout() DirtyCheckoutPolicy {
+	if c == nil || c.DirtyCheckoutPolicy == nil {
+		return DirtyCheckoutWarn
+	}
+	policy, err := ParseDirtyCheckoutPolicy(*c.DirtyCheckoutPolicy)
+	if err != nil {
+		return DirtyCheckoutWarn
+	}
+	return policy
+}
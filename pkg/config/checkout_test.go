@@ -0,0 +1,37 @@
+package config
+
+import (
+	"testing"
+
+	"gopkg.in/yaml.v3"
+)
+
+func TestDirtyCheckout(t *testing.T) {
+	var unset *Config
+	if got := unset.DirtyCheckout(); got != DirtyCheckoutWarn {
+		t.Errorf("Expected warn without config, got %q", got)
+	}
+
+	for _, tt := range []struct {
+		yaml    string
+		want    DirtyCheckoutPolicy
+		wantErr bool
+	}{
+		{"agents: claude:1\n", DirtyCheckoutWarn, false},
+		{"dirtyCheckout: warn\n", DirtyCheckoutWarn, false},
+		{"dirtyCheckout: block\n", DirtyCheckoutBlock, false},
+		{"dirtyCheckout: allow\n", DirtyCheckoutAllow, false},
+		{"dirtyCheckout: stash\n", DirtyCheckoutWarn, true},
+	} {
+		var cfg Config
+		if err := yaml.Unmarshal([]byte(tt.yaml), &cfg); err != nil {
+			t.Fatal(err)
+		}
+		if got := cfg.DirtyCheckout(); got != tt.want {
+			t.Errorf("%q: DirtyCheckout() = %q, want %q", tt.yaml, got, tt.want)
+		}
+		if err := cfg.Validate(); (err != nil) != tt.wantErr {
+			t.Errorf("%q: Validate() error = %v, wantErr %v", tt.yaml, err, tt.wantErr)
+		}
+	}
+}
@@ -0,0 +1,77 @@
+package config
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+// CheckpointConfig sets how the commits `uzi checkpoint` creates are
+// attributed and signed, independent of the user's global git config
+type CheckpointConfig struct {
+	// Author is the "Name <email>" checkpoint commits are made as
+	Author string `yaml:"author"`
+	// Signoff adds a Signed-off-by trailer for the author
+	Signoff bool `yaml:"signoff"`
+	// Sign signs the commits with the signing key from the user's git config
+	Sign bool `yaml:"sign"`
+	// MessageTemplate builds the commit message from the one given to
+	// `uzi checkpoint`, e.g. "agent({agent}): {message}\n\nPrompt: {prompt}",
+	// so checkpoint commits record which agent and task they came from
+	MessageTemplate string `yaml:"messageTemplate"`
+}
+
+// CheckpointMessage is what the placeholders of a checkpoint message
+// template are filled in with
+type CheckpointMessage struct {
+	Message string // {message}: the message given to the checkpoint
+	Agent   string // {agent}: the agent's name
+	Session string // {session}: the agent's tmux session
+	Prompt  string // {prompt}: the prompt the agent was started with
+	Model   string // {model}: the agent CLI, e.g. claude
+}
+
+var messagePlaceholderRe = regexp.MustCompile(`\{([a-z]+)\}`)
+
+// checkpointPlaceholders are the placeholders a message template may use
+var checkpointPlaceholders = map[string]bool{"message": true, "agent": true, "session": true, "prompt": true, "model": true}
+
+// ValidateMessageTemplate checks that a checkpoint message template keeps
+// the given message and only uses known placeholders
+func ValidateMessageTemplate(template string) error {
+	if !strings.Contains(template, "{message}") {
+		return fmt.Errorf("messageTemplate must include {message}")
+	}
+	for _, m := range messagePlaceholderRe.FindAllStringSubmatch(template, -1) {
+		if !checkpointPlaceholders[m[1]] {
+			return fmt.Errorf("unknown placeholder {%s} in messageTemplate (use {message}, {agent}, {session}, {prompt}, or {model})", m[1])
+		}
+	}
+	return nil
+}
+
+// RenderMessage returns the commit message of a checkpoint: the message
+// template filled in with fields, or fields.Message without a template
+func (c *CheckpointConfig) RenderMessage(fields CheckpointMessage) string {
+	if c == nil || strings.TrimSpace(c.MessageTemplate) == "" {
+		return fields.Message
+	}
+	return strings.TrimSpace(strings.NewReplacer(
+		"{message}", fields.Message,
+		"{agent}", fields.Agent,
+		"{session}", fields.Session,
+		"{prompt}", strings.TrimSpace(fields.Prompt),
+		"{model}", fields.Model,
+	).Replace(c.MessageTemplate))
+}
+
+var authorRe = regexp.MustCompile(`^([^<>]+?)\s*<([^<>\s]+)>$`)
+
+// ParseAuthor splits an author in "Name <email>" form
+func ParseAuthor(author string) (name, email string, err error) {
+	m := authorRe.FindStringSubmatch(strings.TrimSpace(author))
+	if m == nil {
+		return "", "", fmt.Errorf("author %q must be in \"Name <email>\" form", author)
+	}
+	return m[1], m[2], nil
+}
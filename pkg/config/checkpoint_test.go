@@ -0,0 +1,64 @@
+package config
+
+import (
+	"testing"
+
+	"gopkg.in/yaml.v3"
+)
+
+func TestParseAuthor(t *testing.T) {
+	name, email, err := ParseAuthor("Agent Claude <agents@team>")
+	if err != nil || name != "Agent Claude" || email != "agents@team" {
+		t.Errorf("ParseAuthor() = %q, %q, %v", name, email, err)
+	}
+	for _, bad := range []string{"", "Agent Claude", "<agents@team>", "Agent <a b>"} {
+		if _, _, err := ParseAuthor(bad); err == nil {
+			t.Errorf("ParseAuthor(%q) should fail", bad)
+		}
+	}
+}
+
+func TestCheckpointAuthorValidation(t *testing.T) {
+	cfg := &Config{Checkpoint: &CheckpointConfig{Author: "nobody"}}
+	if err := cfg.Validate(); err == nil {
+		t.Error("Expected Validate to reject an author without an email")
+	}
+}
+
+func TestCheckpointRenderMessage(t *testing.T) {
+	fields := CheckpointMessage{
+		Message: "Add login page",
+		Agent:   "sarah",
+		Session: "agent-app-abc123-sarah",
+		Prompt:  "add a login page\n",
+		Model:   "claude",
+	}
+	var none *CheckpointConfig
+	if got := none.RenderMessage(fields); got != "Add login page" {
+		t.Errorf("Expected the message unchanged without checkpoint config, got %q", got)
+	}
+
+	for _, tt := range []struct {
+		yaml    string
+		want    string
+		wantErr bool
+	}{
+		{"checkpoint:\n  signoff: true\n", "Add login page", false},
+		{"checkpoint:\n  messageTemplate: \"agent({agent}): {message}\\n\\nPrompt: {prompt}\\nSession: {session}\"\n",
+			"agent(sarah): Add login page\n\nPrompt: add a login page\nSession: agent-app-abc123-sarah", false},
+		{"checkpoint:\n  messageTemplate: \"[{model}] {message}\"\n", "[claude] Add login page", false},
+		{"checkpoint:\n  messageTemplate: \"agent({agent}) checkpoint\"\n", "agent(sarah) checkpoint", true},
+		{"checkpoint:\n  messageTemplate: \"{message} ({branch})\"\n", "Add login page ({branch})", true},
+	} {
+		var cfg Config
+		if err := yaml.Unmarshal([]byte(tt.yaml), &cfg); err != nil {
+			t.Fatal(err)
+		}
+		if got := cfg.Checkpoint.RenderMessage(fields); got != tt.want {
+			t.Errorf("%q: RenderMessage() = %q, want %q", tt.yaml, got, tt.want)
+		}
+		if err := cfg.Validate(); (err != nil) != tt.wantErr {
+			t.Errorf("%q: Validate() error = %v, wantErr %v", tt.yaml, err, tt.wantErr)
+		}
+	}
+}
@@ -0,0 +1,138 @@
+package config
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// Column is a field shown for each agent in the TUI list
+type Column string
+
+const (
+	// ColumnAgent is the agent name and model, always the first line of a row
+	ColumnAgent Column = "agent"
+	// ColumnStatus is the agent's status
+	ColumnStatus Column = "status"
+	// ColumnHost is the remote host the agent runs on
+	ColumnHost Column = "host"
+	// ColumnDiff is the insertions and deletions in the agent's worktree
+	ColumnDiff Column = "diff"
+	// ColumnFiles is the number of files the agent changed
+	ColumnFiles Column = "files"
+	// ColumnActivity is how long ago the agent was last active
+	ColumnActivity Column = "activity"
+	// ColumnAge is how long ago the agent was started
+	ColumnAge Column = "age"
+	// ColumnRuntime is the time left in the agent's --max-runtime budget
+	ColumnRuntime Column = "runtime"
+	// ColumnPort is the agent's dev server address
+	ColumnPort Column = "port"
+	// ColumnPrompt is the start of the agent's prompt
+	ColumnPrompt Column = "prompt"
+	// ColumnCost is the estimated API cost of the agent's claude transcripts
+	ColumnCost Column = "cost"
+)
+
+// Columns lists every column tui.columns accepts
+var Columns = []Column{
+	ColumnAgent, ColumnStatus, ColumnHost, ColumnDiff, ColumnFiles, ColumnActivity,
+	ColumnAge, ColumnRuntime, ColumnPort, ColumnPrompt, ColumnCost,
+}
+
+// ColumnSpec is a column of the TUI list with the width it is padded or
+// truncated to; a zero width fits the content
+type ColumnSpec struct {
+	Column Column
+	Width  int
+}
+
+// DefaultPromptWidth is how much of the prompt is shown when the prompt
+// column has no width
+const DefaultPromptWidth = 40
+
+// DefaultColumns are the columns of the TUI list when tui.columns is not set
+var DefaultColumns = []ColumnSpec{
+	{Column: ColumnAgent},
+	{Column: ColumnStatus},
+	{Column: ColumnHost},
+	{Column: ColumnDiff},
+	{Column: ColumnActivity},
+	{Column: ColumnAge},
+	{Column: ColumnRuntime},
+	{Column: ColumnPort},
+	{Column: ColumnPrompt, Width: DefaultPromptWidth},
+}
+
+// ParseColumns parses tui.columns entries such as "status" or "prompt:60",
+// a column name with an optional width
+func ParseColumns(entries []string) ([]ColumnSpec, error) {
+	if len(entries) == 0 {
+		return nil, fmt.Errorf("columns is empty")
+	}
+	specs := make([]ColumnSpec, 0, len(entries))
+	seen := make(map[Column]bool, len(entries))
+	for _, entry := range entries {
+		name, width, hasWidth := strings.Cut(strings.TrimSpace(entry), ":")
+		spec := ColumnSpec{Column: Column(strings.TrimSpace(name))}
+		if !isColumn(spec.Column) {
+			return nil, fmt.Errorf("unknown column %q (use %s)", name, columnNames())
+		}
+		if seen[spec.Column] {
+			return nil, fmt.Errorf("column %q is listed twice", name)
+		}
+		seen[spec.Column] = true
+		if hasWidth {
+			n, err := strconv.Atoi(strings.TrimSpace(width))
+			if err != nil || n <= 0 {
+				return nil, fmt.Errorf("invalid width %q for column %s", width, name)
+			}
+			spec.Width = n
+		}
+		specs = append(specs, spec)
+	}
+	return specs, nil
+}
+
+// isColumn reports whether column is one of Columns
+func isColumn(column Column) bool {
+	for _, c := range Columns {
+		if c == column {
+			return true
+		}
+	}
+	return false
+}
+
+// columnNames lists the column names for error messages
+func columnNames() string {
+	names := make([]string, len(Columns))
+	for i, c := range Columns {
+		names[i] = string(c)
+	}
+	return strings.Join(names, ", ")
+}
+
+// TUIColumns returns the columns of the TUI list, DefaultColumns unless
+// tui.columns is set
+func (c *Config) TUIColumns() []ColumnSpec {
+	if c == nil || c.TUI == nil || len(c.TUI.Columns) == 0 {
+		return DefaultColumns
+	}
+	specs, err := ParseColumns(c.TUI.Columns)
+	if err != nil {
+		return DefaultColumns
+	}
+	return specs
+}
+
+// HasColumn reports whether the TUI list shows column, so data only it needs
+// is gathered only then
+func (c *Config) HasColumn(column Column) bool {
+	for _, spec := range c.TUIColumns() {
+		if spec.Column == column {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,76 @@
+package config
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+
+	"gopkg.in/yaml.v3"
+)
+
+func TestParseColumns(t *testing.T) {
+	got, err := ParseColumns([]string{"agent", "status", "diff:9", " prompt : 60 ", "cost"})
+	if err != nil {
+		t.Fatalf("ParseColumns() error = %v", err)
+	}
+	want := []ColumnSpec{
+		{Column: ColumnAgent},
+		{Column: ColumnStatus},
+		{Column: ColumnDiff, Width: 9},
+		{Column: ColumnPrompt, Width: 60},
+		{Column: ColumnCost},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("ParseColumns() = %+v, want %+v", got, want)
+	}
+
+	for entries, wantErr := range map[string]string{
+		"":              "columns is empty",
+		"status,tokens": `unknown column "tokens"`,
+		"status,status": "listed twice",
+		"prompt:wide":   "invalid width",
+		"prompt:0":      "invalid width",
+	} {
+		var list []string
+		if entries != "" {
+			list = strings.Split(entries, ",")
+		}
+		if _, err := ParseColumns(list); err == nil || !strings.Contains(err.Error(), wantErr) {
+			t.Errorf("ParseColumns(%q) error = %v, want %q", entries, err, wantErr)
+		}
+	}
+}
+
+func TestTUIColumns(t *testing.T) {
+	var unset *Config
+	if got := unset.TUIColumns(); !reflect.DeepEqual(got, DefaultColumns) {
+		t.Errorf("Expected the default columns without config, got %+v", got)
+	}
+	if unset.HasColumn(ColumnCost) || !unset.HasColumn(ColumnPrompt) {
+		t.Error("Expected the default columns to show the prompt and not the cost")
+	}
+
+	var cfg Config
+	if err := yaml.Unmarshal([]byte("tui:\n  columns: [agent, status, files, \"prompt:20\"]\n"), &cfg); err != nil {
+		t.Fatal(err)
+	}
+	if err := cfg.Validate(); err != nil {
+		t.Fatalf("Validate() error = %v", err)
+	}
+	if got := cfg.TUIColumns(); len(got) != 4 || got[2].Column != ColumnFiles || got[3].Width != 20 {
+		t.Errorf("TUIColumns() = %+v", got)
+	}
+
+	cfg.TUI.Columns = []string{"status", "tokens"}
+	if err := cfg.Validate(); err == nil || !strings.Contains(err.Error(), "tui.columns") {
+		t.Errorf("Expected an unknown column rejected, got %v", err)
+	}
+	if got := cfg.TUIColumns(); !reflect.DeepEqual(got, DefaultColumns) {
+		t.Errorf("Expected invalid columns to fall back to the defaults, got %+v", got)
+	}
+
+	cfg.TUI.Columns = []string{}
+	if err := cfg.Validate(); err == nil {
+		t.Error("Expected an empty column list rejected")
+	}
+}
@@ -1,14 +1,392 @@
 package config
 
 import (
+	"fmt"
 	"os"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/nehpz/claudicus/pkg/agents"
 
 	"gopkg.in/yaml.v3"
 )
 
 type Config struct {
-	DevCommand *string `yaml:"devCommand"`
-	PortRange  *string `yaml:"portRange"`
+	DevCommand *string               `yaml:"devCommand"`
+	PortRange  *string               `yaml:"portRange"`
+	Webhooks   *WebhooksConfig       `yaml:"webhooks"`
+	Nudge      *NudgeConfig          `yaml:"nudge"`
+	TUI        *TUIConfig            `yaml:"tui"`
+	Hosts      map[string]HostConfig `yaml:"hosts"`
+	// DevCommands are named dev server commands, such as a storybook server
+	// for frontend agents, that `uzi prompt --dev-command NAME` runs instead
+	// of devCommand
+	DevCommands map[string]string `yaml:"devCommands"`
+	// SetupCommand installs an agent's dependencies in its new worktree, such
+	// as "npm ci", before the dev server and agent start
+	SetupCommand *string `yaml:"setupCommand"`
+	// WorktreeDir is where agent worktrees are created; see ResolveWorktreeDir
+	WorktreeDir *string `yaml:"worktreeDir"`
+	// ModelArgs maps an agent name or command (e.g. "claude", "codex") to the
+	// extra arguments its CLI is started with, such as "--model claude-3-opus"
+	ModelArgs map[string]string `yaml:"modelArgs"`
+	// Naming sets the templates session and branch names are built from
+	Naming *NamingConfig `yaml:"naming"`
+	// DefaultAgents are the agents `uzi prompt` spawns without --agents, in
+	// the same format, e.g. "claude:2"; the TUI's new agent form starts with
+	// the first of them
+	DefaultAgents *string `yaml:"defaultAgents"`
+	// Agents is the older name of defaultAgents, read when it is not set
+	Agents *string `yaml:"agents"`
+	// Broadcasts are named messages for `uzi broadcast --template NAME`;
+	// {agent} and {branch} are filled in for each target session
+	Broadcasts map[string]string `yaml:"broadcasts"`
+	// Channels maps broadcast channel names to the tags that subscribe
+	// sessions to them at spawn
+	Channels map[string][]string `yaml:"channels"`
+	// BroadcastDelivery paces broadcasts and confirms their delivery
+	BroadcastDelivery *BroadcastDeliveryConfig `yaml:"broadcastDelivery"`
+	// Checkpoint sets the author, signing, and message template of
+	// checkpoint commits
+	Checkpoint *CheckpointConfig `yaml:"checkpoint"`
+	// Pause sets the key sequences `uzi pause` and `uzi resume` send
+	Pause *PauseConfig `yaml:"pause"`
+	// Done sets the sentinel agents print when their task is complete and
+	// whether done agents are checkpointed automatically
+	Done *DoneConfig `yaml:"done"`
+	// MaxConcurrentAgents caps how many agents of the repository work at
+	// once; further spawns are queued. Zero or unset means unlimited.
+	MaxConcurrentAgents *int `yaml:"maxConcurrentAgents"`
+	// DirtyCheckoutPolicy is what spawning does when the main checkout has
+	// uncommitted changes or is behind its upstream: warn, block, or allow
+	DirtyCheckoutPolicy *string `yaml:"dirtyCheckout"`
+	// TrashRetentionPeriod is how long killed agents can be restored with
+	// `uzi undo`, e.g. "24h"; "0" makes kills permanent
+	TrashRetentionPeriod *string `yaml:"trashRetention"`
+	// Policy sets guardrails on killing, broadcasting, and spawning
+	Policy *PolicyConfig `yaml:"policy"`
+	// Auth sets the environment variables API keys from the keyring are
+	// exported as
+	Auth *AuthConfig `yaml:"auth"`
+	// StatusThresholds sets how long agents stay active after their last
+	// activity and how long they may go without progress before they are stuck
+	StatusThresholds *StatusThresholdsConfig `yaml:"statusThresholds"`
+	// Profiles are named sets of settings merged over the rest of the file
+	// when selected with --profile or UZI_PROFILE
+	Profiles map[string]Config `yaml:"profiles"`
+}
+
+// DefaultWorktreeDir returns ~/.local/share/uzi/worktrees, where agent
+// worktrees are created unless worktreeDir is set
+func DefaultWorktreeDir() (string, error) {
+	homeDir, err := os.UserHomeDir()
+	if err != nil {
+		return "", fmt.Errorf("error getting home directory: %w", err)
+	}
+	return filepath.Join(homeDir, ".local", "share", "uzi", "worktrees"), nil
+}
+
+// ResolveWorktreeDir returns the directory agent worktrees are created in.
+// worktreeDir may use environment variables and a leading ~; relative paths
+// are resolved against repoRoot, the main checkout of the repository.
+func (c *Config) ResolveWorktreeDir(repoRoot string) (string, error) {
+	if c == nil || c.WorktreeDir == nil {
+		return DefaultWorktreeDir()
+	}
+	dir := os.ExpandEnv(strings.TrimSpace(*c.WorktreeDir))
+	if dir == "" {
+		return "", fmt.Errorf("worktreeDir is empty")
+	}
+	if dir == "~" || strings.HasPrefix(dir, "~/") {
+		homeDir, err := os.UserHomeDir()
+		if err != nil {
+			return "", fmt.Errorf("error getting home directory: %w", err)
+		}
+		dir = filepath.Join(homeDir, dir[1:])
+	}
+	if !filepath.IsAbs(dir) {
+		dir = filepath.Join(repoRoot, dir)
+	}
+	return filepath.Clean(dir), nil
+}
+
+// HostConfig describes a remote machine that agents can be spawned on with
+// `uzi prompt --host <name>`
+type HostConfig struct {
+	// SSH is the ssh destination, e.g. "dev@build-box" or a Host alias from ~/.ssh/config
+	SSH string `yaml:"ssh"`
+	// RepoPath is the clone of this repository on the host that agent worktrees are created from
+	RepoPath string `yaml:"repoPath"`
+}
+
+// Host returns the configured host with the given name
+func (c *Config) Host(name string) (HostConfig, error) {
+	if c != nil {
+		if host, ok := c.Hosts[name]; ok {
+			return host, nil
+		}
+	}
+	return HostConfig{}, fmt.Errorf("unknown host %q: add it under hosts: in uzi.yaml", name)
+}
+
+// TUIConfig holds settings for the interactive TUI
+type TUIConfig struct {
+	// Keys remaps TUI actions by name, e.g. {kill: "x", down: ["down", "j"]}
+	Keys map[string]KeyList `yaml:"keys"`
+	// AttachMode is how Enter opens an agent when the TUI runs inside tmux:
+	// attach (the default), window, or pane
+	AttachMode string `yaml:"attachMode"`
+	// Columns are the fields shown for each agent in the list, in order,
+	// each optionally with a width, e.g. [agent, status, diff, "prompt:60"]
+	Columns []string `yaml:"columns"`
+}
+
+// KeyList is one or more keys bound to a TUI action; YAML accepts a single
+// string or a list of strings
+type KeyList []string
+
+// UnmarshalYAML accepts both `kill: x` and `kill: [x, delete]`
+func (k *KeyList) UnmarshalYAML(value *yaml.Node) error {
+	if value.Kind == yaml.ScalarNode {
+		*k = KeyList{value.Value}
+		return nil
+	}
+	var keys []string
+	if err := value.Decode(&keys); err != nil {
+		return fmt.Errorf("line %d: keys must be a string or a list of strings", value.Line)
+	}
+	*k = keys
+	return nil
+}
+
+// KeyOverrides returns the configured key remappings, or nil when none are set
+func (c *Config) KeyOverrides() map[string]KeyList {
+	if c == nil || c.TUI == nil {
+		return nil
+	}
+	return c.TUI.Keys
+}
+
+// WebhooksConfig holds the URLs that receive a JSON POST for each agent lifecycle event
+type WebhooksConfig struct {
+	OnSpawn      string `yaml:"onSpawn"`
+	OnReady      string `yaml:"onReady"`
+	OnStuck      string `yaml:"onStuck"`
+	OnCheckpoint string `yaml:"onCheckpoint"`
+	OnKill       string `yaml:"onKill"`
+	OnDone       string `yaml:"onDone"`
+}
+
+// NudgeConfig holds the tmux key sequences sent to an agent that is waiting for input.
+// Agents maps an agent name or model (e.g. "claude", "codex") to its own sequence.
+type NudgeConfig struct {
+	Default []string            `yaml:"default"`
+	Agents  map[string][]string `yaml:"agents"`
+}
+
+// DefaultNudgeKeys is sent when no nudge sequence is configured
+var DefaultNudgeKeys = []string{"Enter"}
+
+// NudgeKeys returns the key sequence used to nudge an agent, preferring a
+// template for the agent name, then its model, then the nudge keys of the
+// model's agent definition, then the configured default
+func (c *Config) NudgeKeys(agentName, model string) []string {
+	if c != nil && c.Nudge != nil {
+		if keys := c.Nudge.Agents[agentName]; len(keys) > 0 {
+			return keys
+		}
+		if keys := c.Nudge.Agents[model]; len(keys) > 0 {
+			return keys
+		}
+	}
+	if keys := agents.NudgeFor(model); len(keys) > 0 {
+		return keys
+	}
+	if c != nil && c.Nudge != nil && len(c.Nudge.Default) > 0 {
+		return c.Nudge.Default
+	}
+	return DefaultNudgeKeys
+}
+
+// AgentModelArgs returns the extra CLI arguments an agent is started with,
+// preferring arguments for the agent name from --agents, then its command
+func (c *Config) AgentModelArgs(agent, command string) string {
+	if c == nil {
+		return ""
+	}
+	if args, ok := c.ModelArgs[agent]; ok {
+		return strings.TrimSpace(args)
+	}
+	return strings.TrimSpace(c.ModelArgs[command])
+}
+
+// CheckModelArgs reports model arguments that cannot be passed to an agent.
+// The agent command line is typed into tmux inside single quotes, so the
+// arguments must not contain any.
+func CheckModelArgs(args string) error {
+	if strings.Contains(args, "'") {
+		return fmt.Errorf("model arguments %q must not contain single quotes; use double quotes instead", args)
+	}
+	return nil
+}
+
+// Validate checks the fields that would otherwise fail only when an agent is spawned
+func (c *Config) Validate() error {
+	if c.DevCommand != nil && strings.TrimSpace(*c.DevCommand) == "" {
+		return fmt.Errorf("devCommand is empty")
+	}
+	if c.PortRange != nil {
+		if _, _, err := ParsePortRange(*c.PortRange); err != nil {
+			return err
+		}
+	}
+	for name, command := range c.DevCommands {
+		if strings.TrimSpace(command) == "" {
+			return fmt.Errorf("devCommands.%s is empty", name)
+		}
+	}
+	if c.SetupCommand != nil && strings.TrimSpace(*c.SetupCommand) == "" {
+		return fmt.Errorf("setupCommand is empty")
+	}
+	if c.DefaultAgents != nil && strings.TrimSpace(*c.DefaultAgents) == "" {
+		return fmt.Errorf("defaultAgents is empty")
+	}
+	if c.Agents != nil && strings.TrimSpace(*c.Agents) == "" {
+		return fmt.Errorf("agents is empty")
+	}
+	if c.WorktreeDir != nil && strings.TrimSpace(os.ExpandEnv(*c.WorktreeDir)) == "" {
+		return fmt.Errorf("worktreeDir is empty")
+	}
+	for agent, args := range c.ModelArgs {
+		if err := CheckModelArgs(args); err != nil {
+			return fmt.Errorf("modelArgs.%s: %w", agent, err)
+		}
+	}
+	if c.Checkpoint != nil && c.Checkpoint.Author != "" {
+		if _, _, err := ParseAuthor(c.Checkpoint.Author); err != nil {
+			return fmt.Errorf("checkpoint: %w", err)
+		}
+	}
+	if c.Checkpoint != nil && c.Checkpoint.MessageTemplate != "" {
+		if err := ValidateMessageTemplate(c.Checkpoint.MessageTemplate); err != nil {
+			return fmt.Errorf("checkpoint: %w", err)
+		}
+	}
+	for name, message := range c.Broadcasts {
+		if strings.TrimSpace(message) == "" {
+			return fmt.Errorf("broadcasts.%s is empty", name)
+		}
+	}
+	for name := range c.Channels {
+		if err := ValidateChannel(name); err != nil {
+			return fmt.Errorf("channels: %w", err)
+		}
+	}
+	if c.BroadcastDelivery != nil {
+		if err := c.BroadcastDelivery.validate(); err != nil {
+			return err
+		}
+	}
+	if c.StatusThresholds != nil {
+		if err := c.StatusThresholds.validate(); err != nil {
+			return err
+		}
+	}
+	if c.Naming != nil {
+		if err := ValidateNameTemplate(c.SessionTemplate(), true); err != nil {
+			return fmt.Errorf("naming.session: %w", err)
+		}
+		if err := ValidateNameTemplate(c.BranchTemplate(), false); err != nil {
+			return fmt.Errorf("naming.branch: %w", err)
+		}
+		if err := ValidateWindowTemplate(c.WindowTemplate()); err != nil {
+			return fmt.Errorf("naming.window: %w", err)
+		}
+	}
+	if c.TUI != nil && c.TUI.AttachMode != "" {
+		if _, err := ParseAttachMode(c.TUI.AttachMode); err != nil {
+			return fmt.Errorf("tui: %w", err)
+		}
+	}
+	if c.TUI != nil && c.TUI.Columns != nil {
+		if _, err := ParseColumns(c.TUI.Columns); err != nil {
+			return fmt.Errorf("tui.columns: %w", err)
+		}
+	}
+	if c.MaxConcurrentAgents != nil && *c.MaxConcurrentAgents < 0 {
+		return fmt.Errorf("maxConcurrentAgents must not be negative")
+	}
+	if c.DirtyCheckoutPolicy != nil {
+		if _, err := ParseDirtyCheckoutPolicy(*c.DirtyCheckoutPolicy); err != nil {
+			return err
+		}
+	}
+	if c.TrashRetentionPeriod != nil {
+		if _, err := ParseTrashRetention(*c.TrashRetentionPeriod); err != nil {
+			return err
+		}
+	}
+	if c.Policy != nil {
+		if c.Policy.ConfirmKill != "" {
+			if _, err := ParseKillConfirmPolicy(c.Policy.ConfirmKill); err != nil {
+				return fmt.Errorf("policy: %w", err)
+			}
+		}
+		for _, pattern := range c.Policy.ForbidBroadcastPatterns {
+			if _, err := regexp.Compile(pattern); err != nil {
+				return fmt.Errorf("policy.forbidBroadcastPatterns: invalid pattern %q: %w", pattern, err)
+			}
+		}
+		if c.Policy.MaxAgents < 0 {
+			return fmt.Errorf("policy.maxAgents must not be negative")
+		}
+	}
+	if c.Auth != nil {
+		for agent, name := range c.Auth.Env {
+			if !envNameRe.MatchString(name) {
+				return fmt.Errorf("auth.env.%s: %q is not an environment variable name", agent, name)
+			}
+		}
+	}
+	for name, profile := range c.Profiles {
+		if len(profile.Profiles) > 0 {
+			return fmt.Errorf("profiles.%s: profiles cannot be nested", name)
+		}
+		if err := profile.Validate(); err != nil {
+			return fmt.Errorf("profiles.%s: %w", name, err)
+		}
+	}
+	for name, host := range c.Hosts {
+		if strings.TrimSpace(host.SSH) == "" {
+			return fmt.Errorf("hosts.%s: ssh is empty", name)
+		}
+		if strings.TrimSpace(host.RepoPath) == "" {
+			return fmt.Errorf("hosts.%s: repoPath is empty", name)
+		}
+	}
+	return nil
+}
+
+// ParsePortRange parses a "start-end" port range
+func ParsePortRange(portRange string) (int, int, error) {
+	parts := strings.Split(portRange, "-")
+	if len(parts) != 2 {
+		return 0, 0, fmt.Errorf("invalid portRange %q (expected start-end)", portRange)
+	}
+	start, err := strconv.Atoi(strings.TrimSpace(parts[0]))
+	if err != nil {
+		return 0, 0, fmt.Errorf("invalid portRange %q (expected start-end)", portRange)
+	}
+	end, err := strconv.Atoi(strings.TrimSpace(parts[1]))
+	if err != nil {
+		return 0, 0, fmt.Errorf("invalid portRange %q (expected start-end)", portRange)
+	}
+	if start <= 0 || end > 65535 || end < start {
+		return 0, 0, fmt.Errorf("invalid portRange %q (ports must be 1-65535 and start <= end)", portRange)
+	}
+	return start, end, nil
 }
 
 func DefaultConfig() Config {
@@ -18,19 +396,14 @@ func DefaultConfig() Config {
 	}
 }
 
-// LoadConfig loads the configuration from the specified path
+// LoadConfig loads the configuration from the specified path, with the
+// profile selected by SetProfile merged over the base settings
 func LoadConfig(path string) (*Config, error) {
 	data, err := os.ReadFile(path)
 	if err != nil {
 		return nil, err
 	}
-
-	var config Config
-	if err := yaml.Unmarshal(data, &config); err != nil {
-		return nil, err
-	}
-
-	return &config, nil
+	return parseConfig(data, activeProfile)
 }
 
 // GetDefaultConfigPath returns the default path for the config file
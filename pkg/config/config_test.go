@@ -3,7 +3,10 @@ package config
 import (
 	"os"
 	"path/filepath"
+	"reflect"
+	"strings"
 	"testing"
+	"time"
 )
 
 func TestDefaultConfig(t *testing.T) {
@@ -239,3 +242,284 @@ func TestLoadConfig_PermissionDenied(t *testing.T) {
 		t.Errorf("Expected config to be nil for permission denied, got %v", config)
 	}
 }
+
+func TestLoadConfig_Webhooks(t *testing.T) {
+	tempDir := t.TempDir()
+	configPath := filepath.Join(tempDir, "uzi.yaml")
+
+	configContent := `devCommand: "npm start"
+portRange: "3000-4000"
+webhooks:
+  onSpawn: https://hooks.example.com/spawn
+  onStuck: https://hooks.example.com/stuck
+`
+	if err := os.WriteFile(configPath, []byte(configContent), 0644); err != nil {
+		t.Fatalf("Failed to create test config file: %v", err)
+	}
+
+	config, err := LoadConfig(configPath)
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	if config.Webhooks == nil {
+		t.Fatal("Expected Webhooks to be non-nil")
+	}
+	if config.Webhooks.OnSpawn != "https://hooks.example.com/spawn" {
+		t.Errorf("Expected OnSpawn URL, got %q", config.Webhooks.OnSpawn)
+	}
+	if config.Webhooks.OnStuck != "https://hooks.example.com/stuck" {
+		t.Errorf("Expected OnStuck URL, got %q", config.Webhooks.OnStuck)
+	}
+	if config.Webhooks.OnKill != "" {
+		t.Errorf("Expected OnKill to be empty, got %q", config.Webhooks.OnKill)
+	}
+}
+
+func TestLoadConfig_Nudge(t *testing.T) {
+	tempDir := t.TempDir()
+	configPath := filepath.Join(tempDir, "uzi.yaml")
+
+	configContent := `devCommand: "npm start"
+portRange: "3000-4000"
+nudge:
+  default: ["continue", "Enter"]
+  agents:
+    codex: ["y", "Enter"]
+    sarah: ["Escape", "please continue", "Enter"]
+`
+	if err := os.WriteFile(configPath, []byte(configContent), 0644); err != nil {
+		t.Fatalf("Failed to create test config file: %v", err)
+	}
+
+	config, err := LoadConfig(configPath)
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	tests := []struct {
+		name      string
+		agentName string
+		model     string
+		want      []string
+	}{
+		{"agent name template", "sarah", "claude", []string{"Escape", "please continue", "Enter"}},
+		{"model template", "john", "codex", []string{"y", "Enter"}},
+		{"configured default", "john", "claude", []string{"continue", "Enter"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := config.NudgeKeys(tt.agentName, tt.model); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("NudgeKeys(%q, %q) = %v, want %v", tt.agentName, tt.model, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestNudgeKeys_Defaults(t *testing.T) {
+	var nilConfig *Config
+	if got := nilConfig.NudgeKeys("sarah", "claude"); !reflect.DeepEqual(got, DefaultNudgeKeys) {
+		t.Errorf("Expected default keys for nil config, got %v", got)
+	}
+
+	empty := &Config{Nudge: &NudgeConfig{}}
+	if got := empty.NudgeKeys("sarah", "claude"); !reflect.DeepEqual(got, DefaultNudgeKeys) {
+		t.Errorf("Expected default keys for empty nudge config, got %v", got)
+	}
+}
+
+func TestAgentModelArgs(t *testing.T) {
+	cfg := &Config{ModelArgs: map[string]string{
+		"claude": " --model claude-3-opus ",
+		"sarah":  "--model claude-3-haiku",
+		"codex":  "",
+	}}
+
+	tests := []struct {
+		agent, command, want string
+	}{
+		{"claude", "claude", "--model claude-3-opus"},
+		{"sarah", "claude", "--model claude-3-haiku"},
+		{"random", "claude", "--model claude-3-opus"},
+		{"codex", "codex", ""},
+		{"gemini", "gemini", ""},
+	}
+	for _, tt := range tests {
+		if got := cfg.AgentModelArgs(tt.agent, tt.command); got != tt.want {
+			t.Errorf("AgentModelArgs(%q, %q) = %q, want %q", tt.agent, tt.command, got, tt.want)
+		}
+	}
+
+	var nilConfig *Config
+	if got := nilConfig.AgentModelArgs("claude", "claude"); got != "" {
+		t.Errorf("Expected no args for nil config, got %q", got)
+	}
+}
+
+func TestLoadConfig_TUIKeys(t *testing.T) {
+	tempDir := t.TempDir()
+	configPath := filepath.Join(tempDir, "uzi.yaml")
+
+	configContent := `tui:
+  keys:
+    kill: "x"
+    down: ["j", "ctrl+n"]
+`
+	if err := os.WriteFile(configPath, []byte(configContent), 0644); err != nil {
+		t.Fatalf("Failed to create test config file: %v", err)
+	}
+
+	config, err := LoadConfig(configPath)
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	want := map[string]KeyList{
+		"kill": {"x"},
+		"down": {"j", "ctrl+n"},
+	}
+	if got := config.KeyOverrides(); !reflect.DeepEqual(got, want) {
+		t.Errorf("KeyOverrides() = %v, want %v", got, want)
+	}
+
+	var nilConfig *Config
+	if got := nilConfig.KeyOverrides(); got != nil {
+		t.Errorf("Expected no overrides for nil config, got %v", got)
+	}
+}
+
+func TestLoadConfig_Hosts(t *testing.T) {
+	tempDir := t.TempDir()
+	configPath := filepath.Join(tempDir, "uzi.yaml")
+
+	configContent := `hosts:
+  build-box:
+    ssh: dev@build-box
+    repoPath: /home/dev/src/app
+`
+	if err := os.WriteFile(configPath, []byte(configContent), 0644); err != nil {
+		t.Fatalf("Failed to create test config file: %v", err)
+	}
+
+	config, err := LoadConfig(configPath)
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	host, err := config.Host("build-box")
+	if err != nil {
+		t.Fatalf("Host() error = %v", err)
+	}
+	if want := (HostConfig{SSH: "dev@build-box", RepoPath: "/home/dev/src/app"}); host != want {
+		t.Errorf("Host() = %+v, want %+v", host, want)
+	}
+	if _, err := config.Host("other"); err == nil || !strings.Contains(err.Error(), `unknown host "other"`) {
+		t.Errorf("Expected unknown host error, got %v", err)
+	}
+}
+
+func TestResolveWorktreeDir(t *testing.T) {
+	home := t.TempDir()
+	t.Setenv("HOME", home)
+	t.Setenv("UZI_TEST_SCRATCH", "/scratch")
+
+	defaultDir, err := (*Config)(nil).ResolveWorktreeDir("/src/app")
+	if err != nil || defaultDir != filepath.Join(home, ".local", "share", "uzi", "worktrees") {
+		t.Errorf("Expected the default worktree dir without a config, got %q, %v", defaultDir, err)
+	}
+
+	tests := map[string]string{
+		"/var/uzi/worktrees":               "/var/uzi/worktrees",
+		"../app-worktrees":                 "/src/app-worktrees",
+		".worktrees":                       "/src/app/.worktrees",
+		"~/worktrees":                      filepath.Join(home, "worktrees"),
+		"$UZI_TEST_SCRATCH/uzi":            "/scratch/uzi",
+		"${UZI_TEST_SCRATCH}/../worktrees": "/worktrees",
+	}
+	for worktreeDir, want := range tests {
+		cfg := &Config{WorktreeDir: &worktreeDir}
+		if got, err := cfg.ResolveWorktreeDir("/src/app"); err != nil || got != want {
+			t.Errorf("ResolveWorktreeDir(%q) = %q, %v, want %q", worktreeDir, got, err, want)
+		}
+	}
+
+	empty := "$UZI_TEST_UNSET_DIR"
+	if _, err := (&Config{WorktreeDir: &empty}).ResolveWorktreeDir("/src/app"); err == nil {
+		t.Error("Expected an error when worktreeDir expands to nothing")
+	}
+}
+
+func TestBroadcastTemplates(t *testing.T) {
+	cfg := &Config{Broadcasts: map[string]string{"status": "Summarize your progress", "rebase": "Rebase {branch}"}}
+	if msg, err := cfg.BroadcastTemplate("status"); err != nil || msg != "Summarize your progress" {
+		t.Errorf("BroadcastTemplate(status) = %q, %v", msg, err)
+	}
+	if _, err := cfg.BroadcastTemplate("missing"); err == nil {
+		t.Error("Expected an error for an unknown template")
+	}
+	if templates := cfg.BroadcastTemplates(); len(templates) != 2 || templates[0].Name != "rebase" {
+		t.Errorf("Expected templates sorted by name, got %v", templates)
+	}
+	var nilCfg *Config
+	if len(nilCfg.BroadcastTemplates()) != 0 {
+		t.Error("Expected no templates without a config")
+	}
+
+	cfg.Broadcasts["empty"] = " "
+	if err := cfg.Validate(); err == nil {
+		t.Error("Expected an empty template to fail validation")
+	}
+}
+
+func TestExpandBroadcast(t *testing.T) {
+	got := ExpandBroadcast("{agent}: rebase {branch}, then ping {agent}", "sarah", "sarah-app-abc123")
+	if want := "sarah: rebase sarah-app-abc123, then ping sarah"; got != want {
+		t.Errorf("ExpandBroadcast() = %q, want %q", got, want)
+	}
+}
+
+func TestBroadcastDelivery(t *testing.T) {
+	var nilCfg *Config
+	if nilCfg.BroadcastDelay() != 0 || nilCfg.BroadcastAck() || nilCfg.BroadcastSerial() {
+		t.Error("Expected broadcasts unpaced without config")
+	}
+	if nilCfg.BroadcastAckTimeout() != DefaultBroadcastAckTimeout || nilCfg.BroadcastSerialTimeout() != DefaultBroadcastSerialTimeout {
+		t.Error("Expected the default timeouts without config")
+	}
+
+	cfg := &Config{BroadcastDelivery: &BroadcastDeliveryConfig{Delay: "250ms", Ack: true, AckTimeout: "2s", Serial: true}}
+	if cfg.BroadcastDelay() != 250*time.Millisecond || cfg.BroadcastAckTimeout() != 2*time.Second {
+		t.Errorf("BroadcastDelay() = %s, BroadcastAckTimeout() = %s", cfg.BroadcastDelay(), cfg.BroadcastAckTimeout())
+	}
+	if !cfg.BroadcastAck() || !cfg.BroadcastSerial() || cfg.BroadcastSerialTimeout() != DefaultBroadcastSerialTimeout {
+		t.Error("Expected ack and serial delivery with the default serial timeout")
+	}
+	if err := cfg.Validate(); err != nil {
+		t.Errorf("Validate() error = %v", err)
+	}
+
+	cfg.BroadcastDelivery.SerialTimeout = "soon"
+	if err := cfg.Validate(); err == nil || !strings.Contains(err.Error(), "broadcastDelivery.serialTimeout") {
+		t.Errorf("Expected an invalid serialTimeout to fail validation, got %v", err)
+	}
+}
+
+func TestSessionChannels(t *testing.T) {
+	cfg := &Config{Channels: map[string][]string{"frontend": {"ui", "css"}, "backend": {"api"}}}
+	if got := strings.Join(cfg.SessionChannels([]string{"docs"}, []string{"css", "urgent"}), ","); got != "docs,frontend" {
+		t.Errorf("SessionChannels() = %q, want docs,frontend", got)
+	}
+	if got := strings.Join(cfg.ChannelNames(), ","); got != "backend,frontend" {
+		t.Errorf("ChannelNames() = %q", got)
+	}
+	var nilCfg *Config
+	if got := nilCfg.SessionChannels([]string{"docs"}, []string{"ui"}); len(got) != 1 || got[0] != "docs" {
+		t.Errorf("Expected only the explicit channel without a config, got %v", got)
+	}
+
+	cfg.Channels["front end"] = []string{"ui"}
+	if err := cfg.Validate(); err == nil {
+		t.Error("Expected a channel name with a space to fail validation")
+	}
+}
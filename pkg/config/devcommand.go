@@ -0,0 +1,50 @@
+package config
+
+// ResolveDevCommand returns the dev server command for a --dev-command
+// value: the command of the devCommands preset it names, or else the value
+// itself. An empty value resolves to "", leaving the session on devCommand.
+func (c *Config) ResolveDevCommand(value string) string {
+	if c != nil {
+		if command, ok := c.DevCommands[value]; ok {
+			return command
+		}
+	}
+	return value
+}
+
+// DevCommandFor returns the dev server command of a session: the command it
+// was spawned with, if any, or else devCommand
+func (c *Config) DevCommandFor(sessionCommand string) string {
+	if sessionCommand != "" {
+		return sessionCommand
+	}
+	if c == nil || c.DevCommand == nil {
+		return ""
+	}
+	return *c.DevCommand
+}
+
+// WorktreeSetupCommand returns setupCommand, or "" when agent worktrees need
+// no setup
+func (c *Config) WorktreeSetupCommand() string {
+	if c == nil || c.SetupCommand == nil {
+		return ""
+	}
+	return *c.SetupCommand
+}
+
+// AutoPortRange is the range dev server ports are picked from when portRange
+// is unset: the dynamic ports no well-known service listens on
+const AutoPortRange = "49152-65535"
+
+// DevPortRange returns the range dev server ports are picked from: portRange,
+// or else AutoPortRange. auto reports whether the range was picked for the
+// user rather than configured.
+func (c *Config) DevPortRange() (start, end int, auto bool, err error) {
+	if c == nil || c.PortRange == nil || *c.PortRange == "" {
+		start, end, err = ParsePortRange(AutoPortRange)
+		return start, end, true, err
+	}
+	start, end, err = ParsePortRange(*c.PortRange)
+	return start, end, false, err
+}
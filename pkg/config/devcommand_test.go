@@ -0,0 +1,81 @@
+package config
+
+import (
+	"testing"
+
+	"gopkg.in/yaml.v3"
+)
+
+func TestDevCommand(t *testing.T) {
+	var unset *Config
+	if got := unset.ResolveDevCommand("npm run storybook"); got != "npm run storybook" {
+		t.Errorf("Expected a literal command without config, got %q", got)
+	}
+	if got := unset.DevCommandFor(""); got != "" {
+		t.Errorf("Expected no dev command without config, got %q", got)
+	}
+
+	var cfg Config
+	data := "devCommand: npm run dev -- --port $PORT\ndevCommands:\n  storybook: npm run storybook -- --port $PORT\n"
+	if err := yaml.Unmarshal([]byte(data), &cfg); err != nil {
+		t.Fatal(err)
+	}
+	for value, want := range map[string]string{
+		"":                          "",
+		"storybook":                 "npm run storybook -- --port $PORT",
+		"go run ./api -addr :$PORT": "go run ./api -addr :$PORT",
+	} {
+		if got := cfg.ResolveDevCommand(value); got != want {
+			t.Errorf("ResolveDevCommand(%q) = %q, want %q", value, got, want)
+		}
+	}
+	if got := cfg.DevCommandFor(""); got != "npm run dev -- --port $PORT" {
+		t.Errorf("Expected devCommand for sessions without their own, got %q", got)
+	}
+	if got := cfg.DevCommandFor("npm run storybook -- --port $PORT"); got != "npm run storybook -- --port $PORT" {
+		t.Errorf("Expected the session's own command, got %q", got)
+	}
+
+	cfg.DevCommands["api"] = " "
+	if err := cfg.Validate(); err == nil {
+		t.Error("Expected an empty preset to be invalid")
+	}
+}
+
+func TestWorktreeSetupCommand(t *testing.T) {
+	var unset *Config
+	if got := unset.WorktreeSetupCommand(); got != "" {
+		t.Errorf("Expected no setup without config, got %q", got)
+	}
+
+	var cfg Config
+	if err := yaml.Unmarshal([]byte("setupCommand: npm ci\n"), &cfg); err != nil {
+		t.Fatal(err)
+	}
+	if got := cfg.WorktreeSetupCommand(); got != "npm ci" {
+		t.Errorf("WorktreeSetupCommand() = %q, want npm ci", got)
+	}
+
+	*cfg.SetupCommand = " "
+	if err := cfg.Validate(); err == nil {
+		t.Error("Expected an empty setupCommand to be invalid")
+	}
+}
+
+func TestDevPortRange(t *testing.T) {
+	var unset *Config
+	if start, end, auto, err := unset.DevPortRange(); err != nil || !auto || start != 49152 || end != 65535 {
+		t.Errorf("Expected the automatic range without config, got %d-%d, %v, %v", start, end, auto, err)
+	}
+
+	portRange := "3000-3010"
+	cfg := &Config{PortRange: &portRange}
+	if start, end, auto, err := cfg.DevPortRange(); err != nil || auto || start != 3000 || end != 3010 {
+		t.Errorf("Expected portRange, got %d-%d, %v, %v", start, end, auto, err)
+	}
+
+	portRange = "3010-3000"
+	if _, _, _, err := cfg.DevPortRange(); err == nil {
+		t.Error("Expected an invalid portRange to be reported")
+	}
+}
@@ -0,0 +1,45 @@
+package config
+
+// DefaultDoneSentinel is the line an agent prints to signal its task is
+// complete when no sentinel is configured
+const DefaultDoneSentinel = "UZI_DONE"
+
+// DoneFile is the file an agent touches in its worktree root to signal its
+// task is complete
+const DoneFile = ".uzi-done"
+
+// DefaultDoneCheckpointMessage is the commit message of the checkpoint made
+// when a done agent is checkpointed automatically
+const DefaultDoneCheckpointMessage = "Checkpoint completed agent work"
+
+// DoneConfig sets how agents signal completion and what happens when they do
+type DoneConfig struct {
+	// Sentinel is the line an agent prints, alone, when its task is complete
+	Sentinel string `yaml:"sentinel"`
+	// Checkpoint checkpoints an agent's work as soon as it is done
+	Checkpoint bool `yaml:"checkpoint"`
+	// Message is the commit message of that checkpoint
+	Message string `yaml:"message"`
+}
+
+// DoneSentinel returns the line that marks an agent as done
+func (c *Config) DoneSentinel() string {
+	if c == nil || c.Done == nil || c.Done.Sentinel == "" {
+		return DefaultDoneSentinel
+	}
+	return c.Done.Sentinel
+}
+
+// AutoCheckpointDone reports whether done agents are checkpointed automatically
+func (c *Config) AutoCheckpointDone() bool {
+	return c != nil && c.Done != nil && c.Done.Checkpoint
+}
+
+// DoneCheckpointMessage returns the commit message of automatic checkpoints
+// of done agents
+func (c *Config) DoneCheckpointMessage() string {
+	if c == nil || c.Done == nil || c.Done.Message == "" {
+		return DefaultDoneCheckpointMessage
+	}
+	return c.Done.Message
+}
@@ -0,0 +1,32 @@
+package config
+
+import (
+	"testing"
+
+	"gopkg.in/yaml.v3"
+)
+
+func TestDoneConfig(t *testing.T) {
+	var unset *Config
+	if unset.DoneSentinel() != DefaultDoneSentinel || unset.AutoCheckpointDone() || unset.DoneCheckpointMessage() != DefaultDoneCheckpointMessage {
+		t.Error("Expected the defaults without config")
+	}
+
+	var cfg Config
+	data := "done:\n  sentinel: ALL_DONE\n  checkpoint: true\n  message: Finish task\n"
+	if err := yaml.Unmarshal([]byte(data), &cfg); err != nil {
+		t.Fatal(err)
+	}
+	if got := cfg.DoneSentinel(); got != "ALL_DONE" {
+		t.Errorf("DoneSentinel() = %q, want ALL_DONE", got)
+	}
+	if !cfg.AutoCheckpointDone() {
+		t.Error("Expected done agents checkpointed automatically")
+	}
+	if got := cfg.DoneCheckpointMessage(); got != "Finish task" {
+		t.Errorf("DoneCheckpointMessage() = %q, want Finish task", got)
+	}
+	if got := (&Config{Done: &DoneConfig{Checkpoint: true}}).DoneSentinel(); got != DefaultDoneSentinel {
+		t.Errorf("Expected the default sentinel when only checkpoint is set, got %q", got)
+	}
+}
@@ -0,0 +1,10 @@
+package config
+
+// AgentLimit returns how many agents of the repository may work at once
+// before `uzi prompt` queues further spawns, or 0 for no limit
+func (c *Config) AgentLimit() int {
+	if c == nil || c.MaxConcurrentAgents == nil || *c.MaxConcurrentAgents < 0 {
+		return 0
+	}
+	return *c.MaxConcurrentAgents
+}
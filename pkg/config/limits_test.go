@@ -0,0 +1,34 @@
+package config
+
+import (
+	"testing"
+
+	"gopkg.in/yaml.v3"
+)
+
+func TestAgentLimit(t *testing.T) {
+	var unset *Config
+	if unset.AgentLimit() != 0 {
+		t.Error("Expected no limit without config")
+	}
+
+	var cfg Config
+	if err := yaml.Unmarshal([]byte("maxConcurrentAgents: 4\n"), &cfg); err != nil {
+		t.Fatal(err)
+	}
+	if got := cfg.AgentLimit(); got != 4 {
+		t.Errorf("AgentLimit() = %d, want 4", got)
+	}
+	if err := cfg.Validate(); err != nil {
+		t.Errorf("Validate() error = %v", err)
+	}
+
+	negative := -1
+	cfg.MaxConcurrentAgents = &negative
+	if err := cfg.Validate(); err == nil {
+		t.Error("Expected a negative limit to be rejected")
+	}
+	if got := cfg.AgentLimit(); got != 0 {
+		t.Errorf("Expected a negative limit to mean no limit, got %d", got)
+	}
+}
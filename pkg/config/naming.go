@@ -0,0 +1,223 @@
+package config
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+	"unicode/utf8"
+)
+
+// Default naming templates, matching the names uzi has always generated
+const (
+	DefaultSessionTemplate = "agent-{project}-{hash}-{agent}"
+	DefaultBranchTemplate  = "{agent}-{project}-{hash}-{timestamp}-{n}"
+	DefaultWindowTemplate  = "agent"
+)
+
+// defaultPromptLength is how many characters of the prompt {prompt} keeps
+// when no length is given
+const defaultPromptLength = 30
+
+// NamingConfig holds the templates tmux session, branch and agent window
+// names are built from. Templates mix literal text with placeholders:
+//
+//	{agent}      agent name, e.g. sarah
+//	{project}    repository name from the origin remote
+//	{hash}       short git hash of the starting point; {hash:4} keeps 4 characters
+//	{date}       spawn date as YYYYMMDD
+//	{time}       spawn time as HHMMSS
+//	{timestamp}  spawn time in Unix seconds
+//	{n}          index of the agent among those spawned together
+//
+// Window templates may also use:
+//
+//	{model}      agent CLI, e.g. claude
+//	{prompt}     first line of the prompt, cut to 30 characters; {prompt:20} keeps 20
+type NamingConfig struct {
+	Session string `yaml:"session"`
+	Branch  string `yaml:"branch"`
+	Window  string `yaml:"window"`
+}
+
+// NameFields are the values substituted into a naming template
+type NameFields struct {
+	Agent     string
+	Project   string
+	Hash      string
+	Time      time.Time
+	Iteration int
+	Model     string
+	Prompt    string
+}
+
+var placeholderRe = regexp.MustCompile(`\{([a-z]+)(?::(\d+))?\}`)
+
+// SessionTemplate returns the configured session name template, or the default
+func (c *Config) SessionTemplate() string {
+	if c == nil || c.Naming == nil || strings.TrimSpace(c.Naming.Session) == "" {
+		return DefaultSessionTemplate
+	}
+	return strings.TrimSpace(c.Naming.Session)
+}
+
+// BranchTemplate returns the configured branch name template, or the default
+func (c *Config) BranchTemplate() string {
+	if c == nil || c.Naming == nil || strings.TrimSpace(c.Naming.Branch) == "" {
+		return DefaultBranchTemplate
+	}
+	return strings.TrimSpace(c.Naming.Branch)
+}
+
+// WindowTemplate returns the configured agent window name template, or the default
+func (c *Config) WindowTemplate() string {
+	if c == nil || c.Naming == nil || strings.TrimSpace(c.Naming.Window) == "" {
+		return DefaultWindowTemplate
+	}
+	return strings.TrimSpace(c.Naming.Window)
+}
+
+// RenderName fills in the placeholders of a naming template
+func RenderName(template string, f NameFields) string {
+	return placeholderRe.ReplaceAllStringFunc(template, func(match string) string {
+		sub := placeholderRe.FindStringSubmatch(match)
+		switch sub[1] {
+		case "agent":
+			return f.Agent
+		case "project":
+			return f.Project
+		case "hash":
+			if n, err := strconv.Atoi(sub[2]); err == nil && n < len(f.Hash) {
+				return f.Hash[:n]
+			}
+			return f.Hash
+		case "date":
+			return f.Time.Format("20060102")
+		case "time":
+			return f.Time.Format("150405")
+		case "timestamp":
+			return strconv.FormatInt(f.Time.Unix(), 10)
+		case "n":
+			return strconv.Itoa(f.Iteration)
+		case "model":
+			return f.Model
+		case "prompt":
+			n, err := strconv.Atoi(sub[2])
+			if err != nil {
+				n = defaultPromptLength
+			}
+			return shortPrompt(f.Prompt, n)
+		}
+		return match
+	})
+}
+
+// shortPrompt returns the first line of prompt with its whitespace collapsed,
+// cut to n characters
+func shortPrompt(prompt string, n int) string {
+	line, _, _ := strings.Cut(strings.TrimSpace(prompt), "\n")
+	line = strings.Join(strings.Fields(line), " ")
+	if utf8.RuneCountInString(line) <= n {
+		return line
+	}
+	return strings.TrimSpace(string([]rune(line)[:n])) + "…"
+}
+
+// ValidateNameTemplate checks that a template only uses known placeholders.
+// Session templates must contain {agent}, which is read back from session
+// names, and no characters tmux rejects in session names.
+func ValidateNameTemplate(template string, session bool) error {
+	if err := validatePlaceholders(template, false); err != nil {
+		return err
+	}
+	if !session {
+		return nil
+	}
+	if strings.Count(template, "{agent}") != 1 {
+		return fmt.Errorf("session template %q must contain {agent} exactly once", template)
+	}
+	if strings.ContainsAny(placeholderRe.ReplaceAllString(template, ""), ".: ") {
+		return fmt.Errorf("session template %q must not contain '.', ':' or spaces", template)
+	}
+	return nil
+}
+
+// ValidateWindowTemplate checks that an agent window template only uses known
+// placeholders, including {model} and {prompt}
+func ValidateWindowTemplate(template string) error {
+	return validatePlaceholders(template, true)
+}
+
+// validatePlaceholders checks the placeholders of a template; window templates
+// may also use {model} and {prompt}
+func validatePlaceholders(template string, window bool) error {
+	for _, sub := range placeholderRe.FindAllStringSubmatch(template, -1) {
+		switch sub[1] {
+		case "agent", "project", "hash", "date", "time", "timestamp", "n":
+		case "model", "prompt":
+			if !window {
+				return fmt.Errorf("%s is only available in window templates, got %q", sub[0], template)
+			}
+		default:
+			return fmt.Errorf("unknown placeholder %s in %q", sub[0], template)
+		}
+		if sub[2] != "" && sub[1] != "hash" && sub[1] != "prompt" {
+			return fmt.Errorf("only {hash} and {prompt} take a length, got %s in %q", sub[0], template)
+		}
+		if sub[2] != "" {
+			if n, _ := strconv.Atoi(sub[2]); n < 1 {
+				return fmt.Errorf("%s length must be at least 1, got %s in %q", sub[1], sub[0], template)
+			}
+		}
+	}
+	return nil
+}
+
+// AgentFromName reads the agent name back out of a name built from template,
+// reporting false when the name does not fit the template
+func AgentFromName(template, name string) (string, bool) {
+	re, err := templateRegexp(template)
+	if err != nil {
+		return "", false
+	}
+	m, i := re.FindStringSubmatch(name), re.SubexpIndex("agent")
+	if m == nil || i < 0 {
+		return "", false
+	}
+	return m[i], true
+}
+
+// templateRegexp turns a naming template into an anchored regexp with an
+// "agent" group
+func templateRegexp(template string) (*regexp.Regexp, error) {
+	var b strings.Builder
+	b.WriteString("^")
+	last := 0
+	for _, loc := range placeholderRe.FindAllStringSubmatchIndex(template, -1) {
+		b.WriteString(regexp.QuoteMeta(template[last:loc[0]]))
+		last = loc[1]
+		name := template[loc[2]:loc[3]]
+		switch name {
+		case "agent":
+			b.WriteString("(?P<agent>.+)")
+		case "hash":
+			if loc[4] >= 0 {
+				b.WriteString("[0-9a-f]{1," + template[loc[4]:loc[5]] + "}")
+			} else {
+				b.WriteString("[0-9a-f]+")
+			}
+		case "date":
+			b.WriteString(`\d{8}`)
+		case "time":
+			b.WriteString(`\d{6}`)
+		case "timestamp", "n":
+			b.WriteString(`\d+`)
+		default:
+			b.WriteString(".+?")
+		}
+	}
+	b.WriteString(regexp.QuoteMeta(template[last:]))
+	b.WriteString("$")
+	return regexp.Compile(b.String())
+}
@@ -0,0 +1,130 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestRenderName(t *testing.T) {
+	fields := NameFields{
+		Agent:     "sarah",
+		Project:   "app",
+		Hash:      "abc1234",
+		Time:      time.Date(2025, 3, 9, 14, 5, 30, 0, time.UTC),
+		Iteration: 2,
+	}
+	tests := map[string]string{
+		DefaultSessionTemplate:     "agent-app-abc1234-sarah",
+		DefaultBranchTemplate:      "sarah-app-abc1234-1741529130-2",
+		"{agent}-{hash:4}":         "sarah-abc1",
+		"uzi/{agent}/{date}":       "uzi/sarah/20250309",
+		"{agent}-{time}-{hash:20}": "sarah-140530-abc1234",
+	}
+	for template, want := range tests {
+		if got := RenderName(template, fields); got != want {
+			t.Errorf("RenderName(%q) = %q, want %q", template, got, want)
+		}
+	}
+}
+
+func TestValidateNameTemplate(t *testing.T) {
+	tests := []struct {
+		template string
+		session  bool
+		valid    bool
+	}{
+		{DefaultSessionTemplate, true, true},
+		{"{agent}-{hash:4}", true, true},
+		{"uzi/{agent}/{date}", false, true},
+		{"{project}-{hash}", true, false},  // no agent to read back
+		{"{agent}-{agent}", true, false},   // ambiguous agent
+		{"{agent}.{hash}", true, false},    // tmux rejects dots
+		{"{agent}-{branch}", false, false}, // unknown placeholder
+		{"{agent}-{date:4}", false, false}, // only hash takes a length
+		{"{agent}-{hash:0}", false, false}, // empty hash
+	}
+	for _, tt := range tests {
+		if err := ValidateNameTemplate(tt.template, tt.session); (err == nil) != tt.valid {
+			t.Errorf("ValidateNameTemplate(%q, %v) = %v, want valid %v", tt.template, tt.session, err, tt.valid)
+		}
+	}
+}
+
+func TestRenderWindowName(t *testing.T) {
+	fields := NameFields{Agent: "sarah", Model: "claude", Prompt: "  fix the   auth bug\nthen add tests"}
+	tests := map[string]string{
+		DefaultWindowTemplate:  "agent",
+		"{model}: {prompt}":    "claude: fix the auth bug",
+		"{agent}: {prompt:8}":  "sarah: fix the…",
+		"{model}-{prompt:100}": "claude-fix the auth bug",
+	}
+	for template, want := range tests {
+		if got := RenderName(template, fields); got != want {
+			t.Errorf("RenderName(%q) = %q, want %q", template, got, want)
+		}
+	}
+
+	for template, valid := range map[string]bool{
+		"{model}: {prompt:20}": true,
+		"{agent}-{hash:4}":     true,
+		"{prompt:0}":           false,
+		"{model:3}":            false,
+		"{branch}":             false,
+	} {
+		if err := ValidateWindowTemplate(template); (err == nil) != valid {
+			t.Errorf("ValidateWindowTemplate(%q) = %v, want valid %v", template, err, valid)
+		}
+	}
+	if err := ValidateNameTemplate("{agent}-{prompt}", false); err == nil {
+		t.Error("Expected {prompt} to be rejected in branch templates")
+	}
+}
+
+func TestAgentFromName(t *testing.T) {
+	tests := []struct {
+		template, name, agent string
+		ok                    bool
+	}{
+		{DefaultSessionTemplate, "agent-my-app-abc1234-mary-jane", "mary-jane", true},
+		{"{agent}-{hash:4}", "sarah-abc1", "sarah", true},
+		{"{agent}-{hash:4}", "sarah-abc12", "", false},
+		{"uzi-{date}-{agent}", "uzi-20250309-sarah", "sarah", true},
+		{"uzi-{date}-{agent}", "agent-app-abc1234-sarah", "", false},
+	}
+	for _, tt := range tests {
+		agent, ok := AgentFromName(tt.template, tt.name)
+		if agent != tt.agent || ok != tt.ok {
+			t.Errorf("AgentFromName(%q, %q) = %q, %v, want %q, %v", tt.template, tt.name, agent, ok, tt.agent, tt.ok)
+		}
+	}
+}
+
+func TestLoadConfig_Naming(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "uzi.yaml")
+	content := "naming:\n  session: \"{agent}-{hash:4}\"\n  branch: \"uzi/{agent}/{date}\"\n"
+	if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+		t.Fatal(err)
+	}
+	cfg, err := LoadConfig(path)
+	if err != nil {
+		t.Fatalf("LoadConfig() error = %v", err)
+	}
+	if cfg.SessionTemplate() != "{agent}-{hash:4}" || cfg.BranchTemplate() != "uzi/{agent}/{date}" {
+		t.Errorf("Unexpected templates %q and %q", cfg.SessionTemplate(), cfg.BranchTemplate())
+	}
+	if err := cfg.Validate(); err != nil {
+		t.Errorf("Validate() error = %v", err)
+	}
+
+	cfg.Naming.Session = "{project}"
+	if err := cfg.Validate(); err == nil {
+		t.Error("Expected a session template without {agent} to be rejected")
+	}
+
+	var empty *Config
+	if empty.SessionTemplate() != DefaultSessionTemplate || empty.BranchTemplate() != DefaultBranchTemplate {
+		t.Error("Expected the default templates without a config")
+	}
+}
@@ -0,0 +1,56 @@
+package config
+
+// Key sequences sent when no pause or resume sequence is configured. Escape
+// interrupts claude and codex mid-turn; typing continue picks the task back up.
+var (
+	DefaultPauseKeys  = []string{"Escape"}
+	DefaultResumeKeys = []string{"continue", "Enter"}
+)
+
+// PauseConfig holds the tmux key sequences `uzi pause` sends to stop an agent
+// and `uzi resume` sends to start it again
+type PauseConfig struct {
+	Stop   *KeySequences `yaml:"stop"`
+	Resume *KeySequences `yaml:"resume"`
+}
+
+// KeySequences is a default tmux key sequence with overrides keyed by agent
+// name or model (e.g. "claude", "codex")
+type KeySequences struct {
+	Default []string            `yaml:"default"`
+	Agents  map[string][]string `yaml:"agents"`
+}
+
+// keys returns the sequence for an agent, preferring its name, then its
+// model, then the configured default, then fallback
+func (k *KeySequences) keys(agentName, model string, fallback []string) []string {
+	if k == nil {
+		return fallback
+	}
+	if keys := k.Agents[agentName]; len(keys) > 0 {
+		return keys
+	}
+	if keys := k.Agents[model]; len(keys) > 0 {
+		return keys
+	}
+	if len(k.Default) > 0 {
+		return k.Default
+	}
+	return fallback
+}
+
+// PauseKeys returns the key sequence that stops an agent for `uzi pause`
+func (c *Config) PauseKeys(agentName, model string) []string {
+	if c == nil || c.Pause == nil {
+		return DefaultPauseKeys
+	}
+	return c.Pause.Stop.keys(agentName, model, DefaultPauseKeys)
+}
+
+// ResumeKeys returns the key sequence that starts a paused agent again
+func (c *Config) ResumeKeys(agentName, model string) []string {
+	if c == nil || c.Pause == nil {
+		return DefaultResumeKeys
+	}
+	return c.Pause.Resume.keys(agentName, model, DefaultResumeKeys)
+}
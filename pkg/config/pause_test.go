@@ -0,0 +1,41 @@
+package config
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestPauseKeys(t *testing.T) {
+	var unset *Config
+	if got := unset.PauseKeys("sarah", "claude"); !reflect.DeepEqual(got, DefaultPauseKeys) {
+		t.Errorf("PauseKeys() without config = %v, want %v", got, DefaultPauseKeys)
+	}
+	if got := (&Config{Pause: &PauseConfig{}}).ResumeKeys("sarah", "claude"); !reflect.DeepEqual(got, DefaultResumeKeys) {
+		t.Errorf("ResumeKeys() without sequences = %v, want %v", got, DefaultResumeKeys)
+	}
+
+	cfg := &Config{Pause: &PauseConfig{
+		Stop: &KeySequences{
+			Default: []string{"C-c"},
+			Agents:  map[string][]string{"codex": {"Escape", "Escape"}, "emily": {"Escape"}},
+		},
+		Resume: &KeySequences{Agents: map[string][]string{"codex": {"go on", "Enter"}}},
+	}}
+	tests := []struct {
+		agent, model string
+		pause        []string
+		resume       []string
+	}{
+		{"sarah", "claude", []string{"C-c"}, DefaultResumeKeys},
+		{"john", "codex", []string{"Escape", "Escape"}, []string{"go on", "Enter"}},
+		{"emily", "codex", []string{"Escape"}, []string{"go on", "Enter"}},
+	}
+	for _, tt := range tests {
+		if got := cfg.PauseKeys(tt.agent, tt.model); !reflect.DeepEqual(got, tt.pause) {
+			t.Errorf("PauseKeys(%s, %s) = %v, want %v", tt.agent, tt.model, got, tt.pause)
+		}
+		if got := cfg.ResumeKeys(tt.agent, tt.model); !reflect.DeepEqual(got, tt.resume) {
+			t.Errorf("ResumeKeys(%s, %s) = %v, want %v", tt.agent, tt.model, got, tt.resume)
+		}
+	}
+}
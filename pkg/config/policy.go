@@ -0,0 +1,110 @@
+package config
+
+import (
+	"fmt"
+	"regexp"
+)
+
+// PolicyExitCode is the status uzi exits with when a policy refuses an
+// operation, so scripts can tell a guardrail from a failure
+const PolicyExitCode = 3
+
+// PolicyConfig sets guardrails on operations that are hard to undo. They are
+// checked by the uzi commands and by the TUI alike.
+type PolicyConfig struct {
+	// ConfirmKill is when killing an agent asks for confirmation: always,
+	// dirty (only when it has pending work), or never
+	ConfirmKill string `yaml:"confirmKill"`
+	// ForbidBroadcastPatterns are regular expressions a broadcast message
+	// must not match, such as "rm -rf"
+	ForbidBroadcastPatterns []string `yaml:"forbidBroadcastPatterns"`
+	// MaxAgents is how many agents the repository may have at once; spawns
+	// beyond it are refused. Zero or unset means unlimited.
+	MaxAgents int `yaml:"maxAgents"`
+}
+
+// KillConfirmPolicy is when killing an agent asks for confirmation
+type KillConfirmPolicy string
+
+const (
+	// KillConfirmAlways asks before every kill
+	KillConfirmAlways KillConfirmPolicy = "always"
+	// KillConfirmDirty asks only before a kill that would lose uncommitted
+	// or unmerged work
+	KillConfirmDirty KillConfirmPolicy = "dirty"
+	// KillConfirmNever kills without asking, as --force does
+	KillConfirmNever KillConfirmPolicy = "never"
+)
+
+// ParseKillConfirmPolicy parses a policy.confirmKill value
+func ParseKillConfirmPolicy(policy string) (KillConfirmPolicy, error) {
+	switch KillConfirmPolicy(policy) {
+	case KillConfirmAlways, KillConfirmDirty, KillConfirmNever:
+		return KillConfirmPolicy(policy), nil
+	}
+	return "", fmt.Errorf("invalid confirmKill %q (use always, dirty, or never)", policy)
+}
+
+// PolicyViolation is the error returned when a policy refuses an operation
+type PolicyViolation struct {
+	Rule      string // policy setting that refused it, e.g. "maxAgents"
+	Operation string // kill, broadcast, or spawn
+	Reason    string
+}
+
+func (e *PolicyViolation) Error() string {
+	return fmt.Sprintf("%s blocked by policy %s: %s", e.Operation, e.Rule, e.Reason)
+}
+
+// ExitCode makes uzi exit with PolicyExitCode
+func (e *PolicyViolation) ExitCode() int { return PolicyExitCode }
+
+// KillConfirmation returns when kills ask for confirmation, dirty unless
+// policy.confirmKill is set
+func (c *Config) KillConfirmation() KillConfirmPolicy {
+	if c == nil || c.Policy == nil || c.Policy.ConfirmKill == "" {
+		return KillConfirmDirty
+	}
+	policy, err := ParseKillConfirmPolicy(c.Policy.ConfirmKill)
+	if err != nil {
+		return KillConfirmDirty
+	}
+	return policy
+}
+
+// CheckBroadcast refuses a broadcast message that matches one of
+// policy.forbidBroadcastPatterns
+func (c *Config) CheckBroadcast(message string) error {
+	if c == nil || c.Policy == nil {
+		return nil
+	}
+	for _, pattern := range c.Policy.ForbidBroadcastPatterns {
+		re, err := regexp.Compile(pattern)
+		if err != nil {
+			// Validate reports it; an unusable pattern must not let everything through
+			return &PolicyViolation{Rule: "forbidBroadcastPatterns", Operation: "broadcast", Reason: fmt.Sprintf("invalid pattern %q: %v", pattern, err)}
+		}
+		if re.MatchString(message) {
+			return &PolicyViolation{Rule: "forbidBroadcastPatterns", Operation: "broadcast", Reason: fmt.Sprintf("the message matches %q", pattern)}
+		}
+	}
+	return nil
+}
+
+// MaxAgents returns how many agents the repository may have before spawns
+// are refused, or 0 for no limit
+func (c *Config) MaxAgents() int {
+	if c == nil || c.Policy == nil || c.Policy.MaxAgents < 0 {
+		return 0
+	}
+	return c.Policy.MaxAgents
+}
+
+// CheckAgentCount refuses spawning adding agents next to existing ones when
+// that would exceed policy.maxAgents
+func (c *Config) CheckAgentCount(existing, adding int) error {
+	if limit := c.MaxAgents(); limit > 0 && existing+adding > limit {
+		return &PolicyViolation{Rule: "maxAgents", Operation: "spawn", Reason: fmt.Sprintf("%d agent(s) running and %d requested, at most %d allowed", existing, adding, limit)}
+	}
+	return nil
+}
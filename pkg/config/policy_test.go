@@ -0,0 +1,90 @@
+package config
+
+import (
+	"errors"
+	"testing"
+
+	"gopkg.in/yaml.v3"
+)
+
+func TestKillConfirmation(t *testing.T) {
+	var unset *Config
+	if got := unset.KillConfirmation(); got != KillConfirmDirty {
+		t.Errorf("Expected dirty without config, got %q", got)
+	}
+
+	for _, tt := range []struct {
+		yaml    string
+		want    KillConfirmPolicy
+		wantErr bool
+	}{
+		{"policy:\n  maxAgents: 3\n", KillConfirmDirty, false},
+		{"policy:\n  confirmKill: always\n", KillConfirmAlways, false},
+		{"policy:\n  confirmKill: dirty\n", KillConfirmDirty, false},
+		{"policy:\n  confirmKill: never\n", KillConfirmNever, false},
+		{"policy:\n  confirmKill: sometimes\n", KillConfirmDirty, true},
+		{"policy:\n  maxAgents: -1\n", KillConfirmDirty, true},
+		{"policy:\n  forbidBroadcastPatterns: [\"rm -rf\", \"(\"]\n", KillConfirmDirty, true},
+	} {
+		var cfg Config
+		if err := yaml.Unmarshal([]byte(tt.yaml), &cfg); err != nil {
+			t.Fatal(err)
+		}
+		if got := cfg.KillConfirmation(); got != tt.want {
+			t.Errorf("%q: KillConfirmation() = %q, want %q", tt.yaml, got, tt.want)
+		}
+		if err := cfg.Validate(); (err != nil) != tt.wantErr {
+			t.Errorf("%q: Validate() error = %v, wantErr %v", tt.yaml, err, tt.wantErr)
+		}
+	}
+}
+
+func TestCheckBroadcast(t *testing.T) {
+	var unset *Config
+	if err := unset.CheckBroadcast("rm -rf /"); err != nil {
+		t.Errorf("Expected no policy without config, got %v", err)
+	}
+
+	cfg := &Config{Policy: &PolicyConfig{ForbidBroadcastPatterns: []string{"rm -rf", `git push .*--force`}}}
+	for message, blocked := range map[string]bool{
+		"please run rm -rf node_modules":   true,
+		"git push origin main --force now": true,
+		"run the tests":                    false,
+		"git push origin main":             false,
+	} {
+		err := cfg.CheckBroadcast(message)
+		if (err != nil) != blocked {
+			t.Errorf("CheckBroadcast(%q) = %v, want blocked %v", message, err, blocked)
+			continue
+		}
+		var violation *PolicyViolation
+		if blocked && (!errors.As(err, &violation) || violation.Rule != "forbidBroadcastPatterns") {
+			t.Errorf("CheckBroadcast(%q) = %#v, want a forbidBroadcastPatterns violation", message, err)
+		}
+	}
+
+	cfg.Policy.ForbidBroadcastPatterns = []string{"("}
+	if err := cfg.CheckBroadcast("hello"); err == nil {
+		t.Error("Expected an invalid pattern to refuse broadcasts")
+	}
+}
+
+func TestCheckAgentCount(t *testing.T) {
+	var unset *Config
+	if err := unset.CheckAgentCount(100, 1); err != nil {
+		t.Errorf("Expected no limit without config, got %v", err)
+	}
+
+	cfg := &Config{Policy: &PolicyConfig{MaxAgents: 3}}
+	if err := cfg.CheckAgentCount(1, 2); err != nil {
+		t.Errorf("Expected 3 agents to be allowed, got %v", err)
+	}
+	err := cfg.CheckAgentCount(2, 2)
+	var violation *PolicyViolation
+	if !errors.As(err, &violation) || violation.Operation != "spawn" || violation.ExitCode() != PolicyExitCode {
+		t.Fatalf("Expected a spawn violation, got %#v", err)
+	}
+	if want := "spawn blocked by policy maxAgents: 2 agent(s) running and 2 requested, at most 3 allowed"; err.Error() != want {
+		t.Errorf("Error() = %q, want %q", err.Error(), want)
+	}
+}
@@ -0,0 +1,112 @@
+package config
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// activeProfile is the profile LoadConfig merges over the base settings
+var activeProfile string
+
+// SetProfile selects the profile under profiles: in uzi.yaml that LoadConfig
+// merges over the base settings; an empty name uses the base settings alone
+func SetProfile(name string) {
+	activeProfile = strings.TrimSpace(name)
+}
+
+// ActiveProfile returns the profile selected with SetProfile
+func ActiveProfile() string {
+	return activeProfile
+}
+
+// ProfileNames returns the profiles defined in the config, sorted
+func (c *Config) ProfileNames() []string {
+	if c == nil {
+		return nil
+	}
+	names := make([]string, 0, len(c.Profiles))
+	for name := range c.Profiles {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// parseConfig decodes uzi.yaml with the named profile merged over the base
+// settings. Profiles merge key by key: maps such as modelArgs or hosts keep
+// the base entries the profile does not set, and any other value the profile
+// sets replaces the base one.
+func parseConfig(data []byte, profile string) (*Config, error) {
+	var config Config
+	if err := yaml.Unmarshal(data, &config); err != nil {
+		return nil, err
+	}
+	if profile == "" {
+		return &config, nil
+	}
+	if _, ok := config.Profiles[profile]; !ok {
+		if len(config.Profiles) == 0 {
+			return nil, fmt.Errorf("unknown profile %q: add it under profiles: in uzi.yaml", profile)
+		}
+		return nil, fmt.Errorf("unknown profile %q (profiles: %s)", profile, strings.Join(config.ProfileNames(), ", "))
+	}
+
+	var doc yaml.Node
+	if err := yaml.Unmarshal(data, &doc); err != nil {
+		return nil, err
+	}
+	root := doc.Content[0]
+	override := mappingValue(mappingValue(root, "profiles"), profile)
+	if override.Kind != yaml.MappingNode {
+		// An empty profile, such as `oss:`, changes nothing
+		return &config, nil
+	}
+
+	var merged Config
+	if err := mergeNodes(root, override).Decode(&merged); err != nil {
+		return nil, fmt.Errorf("profile %q: %w", profile, err)
+	}
+	return &merged, nil
+}
+
+// mappingValue returns the value of key in a YAML mapping, or nil
+func mappingValue(node *yaml.Node, key string) *yaml.Node {
+	if node == nil || node.Kind != yaml.MappingNode {
+		return nil
+	}
+	for i := 0; i+1 < len(node.Content); i += 2 {
+		if node.Content[i].Value == key {
+			return node.Content[i+1]
+		}
+	}
+	return nil
+}
+
+// mergeNodes returns base with override merged over it: mappings are merged
+// key by key and any other override value replaces the base value. Neither
+// node is modified.
+func mergeNodes(base, override *yaml.Node) *yaml.Node {
+	if base == nil || base.Kind != yaml.MappingNode || override.Kind != yaml.MappingNode {
+		return override
+	}
+	merged := *base
+	merged.Content = append([]*yaml.Node(nil), base.Content...)
+	for i := 0; i+1 < len(override.Content); i += 2 {
+		key, value := override.Content[i], override.Content[i+1]
+		replaced := false
+		for j := 0; j+1 < len(merged.Content); j += 2 {
+			if merged.Content[j].Value == key.Value {
+				merged.Content[j+1] = mergeNodes(merged.Content[j+1], value)
+				replaced = true
+				break
+			}
+		}
+		if !replaced {
+			merged.Content = append(merged.Content, key, value)
+		}
+	}
+	return &merged
+}
@@ -0,0 +1,119 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+const profilesYAML = `devCommand: npm run dev -- --port $PORT
+agents: claude:1
+modelArgs:
+  claude: --model sonnet
+  codex: --model o3
+nudge:
+  default: [Enter]
+profiles:
+  work:
+    agents: claude:2,codex:1
+    modelArgs:
+      claude: --model opus
+    nudge:
+      agents:
+        codex: [Escape, Enter]
+  oss:
+`
+
+func TestParseConfigProfile(t *testing.T) {
+	base, err := parseConfig([]byte(profilesYAML), "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if *base.Agents != "claude:1" || base.AgentModelArgs("claude", "claude") != "--model sonnet" {
+		t.Errorf("Expected the base settings without a profile, got %+v", base)
+	}
+	if got := base.ProfileNames(); !reflect.DeepEqual(got, []string{"oss", "work"}) {
+		t.Errorf("ProfileNames() = %v", got)
+	}
+
+	work, err := parseConfig([]byte(profilesYAML), "work")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if *work.Agents != "claude:2,codex:1" {
+		t.Errorf("Expected the profile's agents, got %q", *work.Agents)
+	}
+	// Maps merge key by key, other values keep their base setting
+	if got := work.AgentModelArgs("claude", "claude"); got != "--model opus" {
+		t.Errorf("Expected claude's args from the profile, got %q", got)
+	}
+	if got := work.AgentModelArgs("codex", "codex"); got != "--model o3" {
+		t.Errorf("Expected codex's args from the base settings, got %q", got)
+	}
+	if work.DevCommand == nil || *work.DevCommand != "npm run dev -- --port $PORT" {
+		t.Errorf("Expected the base devCommand, got %v", work.DevCommand)
+	}
+	if got := work.NudgeKeys("codex", "codex"); !reflect.DeepEqual(got, []string{"Escape", "Enter"}) {
+		t.Errorf("Expected the profile's nudge keys, got %v", got)
+	}
+	if got := work.NudgeKeys("claude", "claude"); !reflect.DeepEqual(got, []string{"Enter"}) {
+		t.Errorf("Expected the base nudge default kept, got %v", got)
+	}
+
+	oss, err := parseConfig([]byte(profilesYAML), "oss")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if *oss.Agents != "claude:1" {
+		t.Errorf("Expected an empty profile to change nothing, got %q", *oss.Agents)
+	}
+
+	if _, err := parseConfig([]byte(profilesYAML), "home"); err == nil || !strings.Contains(err.Error(), "profiles: oss, work") {
+		t.Errorf("Expected an unknown profile to list the profiles, got %v", err)
+	}
+	if _, err := parseConfig([]byte("agents: claude:1\n"), "work"); err == nil || !strings.Contains(err.Error(), "add it under profiles:") {
+		t.Errorf("Expected an error for a profile without profiles:, got %v", err)
+	}
+}
+
+func TestLoadConfigActiveProfile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "uzi.yaml")
+	if err := os.WriteFile(path, []byte(profilesYAML), 0644); err != nil {
+		t.Fatal(err)
+	}
+	SetProfile(" work ")
+	t.Cleanup(func() { SetProfile("") })
+	if ActiveProfile() != "work" {
+		t.Errorf("Expected the profile name trimmed, got %q", ActiveProfile())
+	}
+
+	cfg, err := LoadConfig(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if *cfg.Agents != "claude:2,codex:1" {
+		t.Errorf("Expected LoadConfig to apply the active profile, got %q", *cfg.Agents)
+	}
+	if err := cfg.Validate(); err != nil {
+		t.Errorf("Validate() error = %v", err)
+	}
+}
+
+func TestValidateProfiles(t *testing.T) {
+	for _, tt := range []struct {
+		yaml, err string
+	}{
+		{"profiles:\n  work:\n    portRange: 9000\n", "profiles.work: invalid portRange"},
+		{"profiles:\n  work:\n    profiles:\n      home: {}\n", "profiles.work: profiles cannot be nested"},
+	} {
+		cfg, err := parseConfig([]byte(tt.yaml), "")
+		if err != nil {
+			t.Fatal(err)
+		}
+		if err := cfg.Validate(); err == nil || !strings.Contains(err.Error(), tt.err) {
+			t.Errorf("Validate(%q) = %v, want %q", tt.yaml, err, tt.err)
+		}
+	}
+}
@@ -0,0 +1,85 @@
+package config
+
+import (
+	"fmt"
+	"strings"
+	"time"
+)
+
+// Defaults of the statusThresholds: section
+const (
+	DefaultActiveThreshold = 5 * time.Minute
+	DefaultStuckThreshold  = 2 * time.Hour
+)
+
+// StatusThresholdsConfig tunes how quickly agents are reported active or
+// stuck, for agents that work slowly or chatter constantly
+type StatusThresholdsConfig struct {
+	// Active is how long after its last tmux or file activity an agent still
+	// counts as active, e.g. "3m"
+	Active string `yaml:"active"`
+	// Stuck is how long an agent may go without commits or file changes
+	// before it is reported stuck, e.g. "15m"
+	Stuck string `yaml:"stuck"`
+}
+
+// ParseStatusThreshold parses a duration of the statusThresholds: section
+// such as "3m" or "1h"
+func ParseStatusThreshold(field, value string) (time.Duration, error) {
+	d, err := time.ParseDuration(strings.TrimSpace(value))
+	if err != nil || d <= 0 {
+		return 0, fmt.Errorf("invalid statusThresholds.%s %q (e.g. 3m or 1h)", field, value)
+	}
+	return d, nil
+}
+
+// statusThreshold returns a duration of the statusThresholds: section, or
+// fallback when it is unset or invalid
+func statusThreshold(field, value string, fallback time.Duration) time.Duration {
+	if value == "" {
+		return fallback
+	}
+	d, err := ParseStatusThreshold(field, value)
+	if err != nil {
+		return fallback
+	}
+	return d
+}
+
+// ActiveThreshold returns how long after its last activity an agent counts
+// as active
+func (c *Config) ActiveThreshold() time.Duration {
+	if c == nil || c.StatusThresholds == nil {
+		return DefaultActiveThreshold
+	}
+	return statusThreshold("active", c.StatusThresholds.Active, DefaultActiveThreshold)
+}
+
+// StuckThreshold returns how long an agent may go without progress before it
+// is reported stuck
+func (c *Config) StuckThreshold() time.Duration {
+	if c == nil || c.StatusThresholds == nil {
+		return DefaultStuckThreshold
+	}
+	return statusThreshold("stuck", c.StatusThresholds.Stuck, DefaultStuckThreshold)
+}
+
+// validate checks the durations of the statusThresholds: section
+func (s *StatusThresholdsConfig) validate() error {
+	active, stuck := DefaultActiveThreshold, DefaultStuckThreshold
+	var err error
+	if s.Active != "" {
+		if active, err = ParseStatusThreshold("active", s.Active); err != nil {
+			return err
+		}
+	}
+	if s.Stuck != "" {
+		if stuck, err = ParseStatusThreshold("stuck", s.Stuck); err != nil {
+			return err
+		}
+	}
+	if stuck <= active {
+		return fmt.Errorf("statusThresholds.stuck (%s) must be longer than statusThresholds.active (%s)", stuck, active)
+	}
+	return nil
+}
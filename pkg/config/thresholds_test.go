@@ -0,0 +1,43 @@
+package config
+
+import (
+	"testing"
+	"time"
+
+	"gopkg.in/yaml.v3"
+)
+
+func TestStatusThresholds(t *testing.T) {
+	var unset *Config
+	if unset.ActiveThreshold() != DefaultActiveThreshold || unset.StuckThreshold() != DefaultStuckThreshold {
+		t.Errorf("Expected the default thresholds without config, got %v and %v", unset.ActiveThreshold(), unset.StuckThreshold())
+	}
+
+	for _, tt := range []struct {
+		yaml       string
+		wantActive time.Duration
+		wantStuck  time.Duration
+		wantErr    bool
+	}{
+		{"statusThresholds: {active: 3m, stuck: 15m}\n", 3 * time.Minute, 15 * time.Minute, false},
+		{"statusThresholds: {stuck: 30m}\n", DefaultActiveThreshold, 30 * time.Minute, false},
+		{"statusThresholds: {active: 1h}\n", time.Hour, DefaultStuckThreshold, false},
+		{"statusThresholds: {active: soon}\n", DefaultActiveThreshold, DefaultStuckThreshold, true},
+		{"statusThresholds: {stuck: \"0\"}\n", DefaultActiveThreshold, DefaultStuckThreshold, true},
+		{"statusThresholds: {active: 20m, stuck: 10m}\n", 20 * time.Minute, 10 * time.Minute, true},
+	} {
+		var cfg Config
+		if err := yaml.Unmarshal([]byte(tt.yaml), &cfg); err != nil {
+			t.Fatal(err)
+		}
+		if got := cfg.ActiveThreshold(); got != tt.wantActive {
+			t.Errorf("%q: ActiveThreshold() = %v, want %v", tt.yaml, got, tt.wantActive)
+		}
+		if got := cfg.StuckThreshold(); got != tt.wantStuck {
+			t.Errorf("%q: StuckThreshold() = %v, want %v", tt.yaml, got, tt.wantStuck)
+		}
+		if err := cfg.Validate(); (err != nil) != tt.wantErr {
+			t.Errorf("%q: Validate() error = %v, wantErr %v", tt.yaml, err, tt.wantErr)
+		}
+	}
+}
@@ -0,0 +1,34 @@
+package config
+
+import (
+	"fmt"
+	"strings"
+	"time"
+)
+
+// DefaultTrashRetention is how long killed agents stay in the trash when
+// trashRetention is not set
+const DefaultTrashRetention = 24 * time.Hour
+
+// ParseTrashRetention parses a trashRetention such as "24h" or "90m"; zero
+// means kills are permanent
+func ParseTrashRetention(retention string) (time.Duration, error) {
+	d, err := time.ParseDuration(strings.TrimSpace(retention))
+	if err != nil || d < 0 {
+		return 0, fmt.Errorf("invalid trashRetention %q (e.g. 24h, or 0 to disable the trash)", retention)
+	}
+	return d, nil
+}
+
+// TrashRetention returns how long `uzi kill` keeps a killed agent's worktree,
+// branch, and state for `uzi undo`, or 0 when kills are permanent
+func (c *Config) TrashRetention() time.Duration {
+	if c == nil || c.TrashRetentionPeriod == nil {
+		return DefaultTrashRetention
+	}
+	d, err := ParseTrashRetention(*c.TrashRetentionPeriod)
+	if err != nil {
+		return DefaultTrashRetention
+	}
+	return d
+}
@@ -0,0 +1,38 @@
+package config
+
+import (
+	"testing"
+	"time"
+
+	"gopkg.in/yaml.v3"
+)
+
+func TestTrashRetention(t *testing.T) {
+	var unset *Config
+	if got := unset.TrashRetention(); got != DefaultTrashRetention {
+		t.Errorf("Expected the default retention without config, got %v", got)
+	}
+
+	for _, tt := range []struct {
+		yaml    string
+		want    time.Duration
+		wantErr bool
+	}{
+		{"trashRetention: 2h\n", 2 * time.Hour, false},
+		{"trashRetention: 90m\n", 90 * time.Minute, false},
+		{"trashRetention: \"0\"\n", 0, false},
+		{"trashRetention: 1d\n", DefaultTrashRetention, true},
+		{"trashRetention: -1h\n", DefaultTrashRetention, true},
+	} {
+		var cfg Config
+		if err := yaml.Unmarshal([]byte(tt.yaml), &cfg); err != nil {
+			t.Fatal(err)
+		}
+		if got := cfg.TrashRetention(); got != tt.want {
+			t.Errorf("%q: TrashRetention() = %v, want %v", tt.yaml, got, tt.want)
+		}
+		if err := cfg.Validate(); (err != nil) != tt.wantErr {
+			t.Errorf("%q: Validate() error = %v, wantErr %v", tt.yaml, err, tt.wantErr)
+		}
+	}
+}
@@ -0,0 +1,125 @@
+package config
+
+import (
+	"path/filepath"
+	"time"
+
+	"github.com/fsnotify/fsnotify"
+)
+
+// WatchEvent is sent by a Watcher after the config file changes. Err is set
+// when the new file could not be loaded or failed validation.
+type WatchEvent struct {
+	Config *Config
+	Err    error
+}
+
+// watchDebounce coalesces the burst of events editors emit for a single save
+const watchDebounce = 100 * time.Millisecond
+
+// Watcher reloads a config file whenever it changes on disk
+type Watcher struct {
+	path    string
+	watcher *fsnotify.Watcher
+	events  chan WatchEvent
+	done    chan struct{}
+}
+
+// NewWatcher starts watching path. The parent directory is watched so that
+// editors which save by renaming a temporary file are picked up too.
+func NewWatcher(path string) (*Watcher, error) {
+	fsw, err := fsnotify.NewWatcher()
+	if err != nil {
+		return nil, err
+	}
+	if err := fsw.Add(filepath.Dir(path)); err != nil {
+		fsw.Close()
+		return nil, err
+	}
+
+	w := &Watcher{
+		path:    path,
+		watcher: fsw,
+		events:  make(chan WatchEvent, 1),
+		done:    make(chan struct{}),
+	}
+	go w.loop()
+	return w, nil
+}
+
+// Events returns the channel of reload results
+func (w *Watcher) Events() <-chan WatchEvent {
+	return w.events
+}
+
+// Close stops watching and closes the Events channel
+func (w *Watcher) Close() error {
+	select {
+	case <-w.done:
+		return nil
+	default:
+		close(w.done)
+	}
+	return w.watcher.Close()
+}
+
+func (w *Watcher) loop() {
+	defer close(w.events)
+
+	var debounce <-chan time.Time
+	for {
+		select {
+		case <-w.done:
+			return
+
+		case event, ok := <-w.watcher.Events:
+			if !ok {
+				return
+			}
+			if filepath.Clean(event.Name) != filepath.Clean(w.path) {
+				continue
+			}
+			if event.Op&(fsnotify.Write|fsnotify.Create|fsnotify.Rename|fsnotify.Remove) != 0 {
+				debounce = time.After(watchDebounce)
+			}
+
+		case err, ok := <-w.watcher.Errors:
+			if !ok {
+				return
+			}
+			w.send(WatchEvent{Err: err})
+
+		case <-debounce:
+			debounce = nil
+			w.send(w.reload())
+		}
+	}
+}
+
+func (w *Watcher) reload() WatchEvent {
+	cfg, err := LoadConfig(w.path)
+	if err != nil {
+		return WatchEvent{Err: err}
+	}
+	if err := cfg.Validate(); err != nil {
+		return WatchEvent{Err: err}
+	}
+	return WatchEvent{Config: cfg}
+}
+
+// send delivers the event, replacing an unread one so the newest result wins
+func (w *Watcher) send(event WatchEvent) {
+	for {
+		select {
+		case w.events <- event:
+			return
+		case <-w.done:
+			return
+		default:
+			select {
+			case <-w.events:
+			default:
+			}
+		}
+	}
+}
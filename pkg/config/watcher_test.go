@@ -0,0 +1,139 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func strPtr(s string) *string { return &s }
+
+func TestValidate(t *testing.T) {
+	tests := []struct {
+		name          string
+		config        Config
+		errorContains string
+	}{
+		{name: "empty config", config: Config{}},
+		{name: "valid", config: Config{DevCommand: strPtr("npm start --port $PORT"), PortRange: strPtr("3000-3010")}},
+		{name: "blank devCommand", config: Config{DevCommand: strPtr("  ")}, errorContains: "devCommand is empty"},
+		{name: "single port", config: Config{PortRange: strPtr("3000")}, errorContains: "invalid portRange"},
+		{name: "reversed range", config: Config{PortRange: strPtr("3010-3000")}, errorContains: "start <= end"},
+		{name: "out of range", config: Config{PortRange: strPtr("3000-70000")}, errorContains: "1-65535"},
+		{name: "not numeric", config: Config{PortRange: strPtr("a-b")}, errorContains: "invalid portRange"},
+		{name: "host", config: Config{Hosts: map[string]HostConfig{"box": {SSH: "dev@box", RepoPath: "/src/app"}}}},
+		{name: "host without ssh", config: Config{Hosts: map[string]HostConfig{"box": {RepoPath: "/src/app"}}}, errorContains: "hosts.box: ssh is empty"},
+		{name: "blank worktreeDir", config: Config{WorktreeDir: strPtr("$UZI_TEST_UNSET_DIR")}, errorContains: "worktreeDir is empty"},
+		{name: "model args", config: Config{ModelArgs: map[string]string{"claude": `--model claude-3-opus --append-system-prompt "be brief"`}}},
+		{name: "quoted model args", config: Config{ModelArgs: map[string]string{"codex": "-c 'model=o3'"}}, errorContains: "modelArgs.codex"},
+		{name: "host without repoPath", config: Config{Hosts: map[string]HostConfig{"box": {SSH: "dev@box"}}}, errorContains: "hosts.box: repoPath is empty"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := tt.config.Validate()
+			if tt.errorContains == "" {
+				if err != nil {
+					t.Errorf("Validate() error = %v", err)
+				}
+				return
+			}
+			if err == nil || !strings.Contains(err.Error(), tt.errorContains) {
+				t.Errorf("Validate() error = %v, want error containing %q", err, tt.errorContains)
+			}
+		})
+	}
+}
+
+func TestParsePortRange(t *testing.T) {
+	start, end, err := ParsePortRange("3000-3010")
+	if err != nil || start != 3000 || end != 3010 {
+		t.Errorf("ParsePortRange() = %d, %d, %v", start, end, err)
+	}
+}
+
+func nextWatchEvent(t *testing.T, w *Watcher) WatchEvent {
+	t.Helper()
+	select {
+	case event := <-w.Events():
+		return event
+	case <-time.After(5 * time.Second):
+		t.Fatal("Timed out waiting for config reload")
+		return WatchEvent{}
+	}
+}
+
+func TestWatcher_ReloadsOnChange(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "uzi.yaml")
+	if err := os.WriteFile(path, []byte("devCommand: npm start\nportRange: 3000-3010\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	w, err := NewWatcher(path)
+	if err != nil {
+		t.Fatalf("NewWatcher() error = %v", err)
+	}
+	defer w.Close()
+
+	// Unrelated files in the same directory are ignored
+	os.WriteFile(filepath.Join(dir, "other.yaml"), []byte("x: 1\n"), 0644)
+
+	os.WriteFile(path, []byte("devCommand: yarn dev\nportRange: 4000-4010\n"), 0644)
+	event := nextWatchEvent(t, w)
+	if event.Err != nil {
+		t.Fatalf("Unexpected reload error: %v", event.Err)
+	}
+	if *event.Config.DevCommand != "yarn dev" || *event.Config.PortRange != "4000-4010" {
+		t.Errorf("Unexpected reloaded config: %+v", event.Config)
+	}
+
+	// Invalid values surface as errors rather than a config
+	os.WriteFile(path, []byte("portRange: 5000\n"), 0644)
+	event = nextWatchEvent(t, w)
+	if event.Err == nil || event.Config != nil {
+		t.Errorf("Expected validation error, got %+v", event)
+	}
+
+	// Editors that save via rename are picked up too
+	tmp := filepath.Join(dir, "uzi.yaml.swp")
+	os.WriteFile(tmp, []byte("devCommand: make dev\nportRange: 3000-3010\n"), 0644)
+	if err := os.Rename(tmp, path); err != nil {
+		t.Fatal(err)
+	}
+	event = nextWatchEvent(t, w)
+	if event.Err != nil || *event.Config.DevCommand != "make dev" {
+		t.Errorf("Expected reload after rename, got %+v", event)
+	}
+}
+
+func TestWatcher_Close(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "uzi.yaml")
+	w, err := NewWatcher(path)
+	if err != nil {
+		t.Fatalf("NewWatcher() error = %v", err)
+	}
+	if err := w.Close(); err != nil {
+		t.Errorf("Close() error = %v", err)
+	}
+	if err := w.Close(); err != nil {
+		t.Errorf("Second Close() error = %v", err)
+	}
+
+	select {
+	case _, ok := <-w.Events():
+		if ok {
+			t.Error("Expected Events channel to be closed")
+		}
+	case <-time.After(time.Second):
+		t.Error("Events channel not closed after Close")
+	}
+}
+
+func TestNewWatcher_MissingDirectory(t *testing.T) {
+	if _, err := NewWatcher(filepath.Join(t.TempDir(), "missing", "uzi.yaml")); err == nil {
+		t.Error("Expected error watching a missing directory")
+	}
+}
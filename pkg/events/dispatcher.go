@@ -0,0 +1,157 @@
+package events
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/charmbracelet/log"
+	"github.com/nehpz/claudicus/pkg/config"
+	"github.com/nehpz/claudicus/pkg/state"
+)
+
+// EventType identifies an agent lifecycle event
+type EventType string
+
+const (
+	// EventSpawn fires when a new agent session is created
+	EventSpawn EventType = "spawn"
+
+	// EventReady fires when an agent stops working and is waiting for input
+	EventReady EventType = "ready"
+
+	// EventStuck fires when an agent is classified as stuck
+	EventStuck EventType = "stuck"
+
+	// EventCheckpoint fires when an agent's changes are checkpointed
+	EventCheckpoint EventType = "checkpoint"
+
+	// EventKill fires when an agent session is killed
+	EventKill EventType = "kill"
+
+	// EventDone fires when an agent signals its task is complete
+	EventDone EventType = "done"
+)
+
+// Event is the JSON payload POSTed to webhook URLs
+type Event struct {
+	Type      EventType `json:"event"`
+	Session   string    `json:"session,omitempty"`
+	Agent     string    `json:"agent,omitempty"`
+	Message   string    `json:"message,omitempty"`
+	Timestamp time.Time `json:"timestamp"`
+}
+
+// NewEvent creates an event for the given session, deriving the agent name
+func NewEvent(eventType EventType, sessionName, message string) Event {
+	agent := ""
+	if sessionName != "" {
+		agent = state.AgentNameFromSession(sessionName)
+	}
+	return Event{
+		Type:      eventType,
+		Session:   sessionName,
+		Agent:     agent,
+		Message:   message,
+		Timestamp: time.Now(),
+	}
+}
+
+// Dispatcher POSTs lifecycle events to the webhook URLs configured in uzi.yaml.
+// A nil Dispatcher is valid and drops every event.
+type Dispatcher struct {
+	hooks  map[EventType]string
+	client *http.Client
+	wg     sync.WaitGroup
+}
+
+// NewDispatcher creates a Dispatcher for the given webhook configuration.
+// It returns nil when no webhook URLs are configured.
+func NewDispatcher(cfg *config.WebhooksConfig) *Dispatcher {
+	if cfg == nil {
+		return nil
+	}
+
+	hooks := make(map[EventType]string)
+	for eventType, url := range map[EventType]string{
+		EventSpawn:      cfg.OnSpawn,
+		EventReady:      cfg.OnReady,
+		EventStuck:      cfg.OnStuck,
+		EventCheckpoint: cfg.OnCheckpoint,
+		EventKill:       cfg.OnKill,
+		EventDone:       cfg.OnDone,
+	} {
+		if url != "" {
+			hooks[eventType] = url
+		}
+	}
+	if len(hooks) == 0 {
+		return nil
+	}
+
+	return &Dispatcher{
+		hooks:  hooks,
+		client: &http.Client{Timeout: 5 * time.Second},
+	}
+}
+
+// LoadDispatcher creates a Dispatcher from the config file at path.
+// A missing or invalid config file yields a nil Dispatcher.
+func LoadDispatcher(path string) *Dispatcher {
+	cfg, err := config.LoadConfig(path)
+	if err != nil {
+		log.Debug("No webhook configuration loaded", "path", path, "error", err)
+		return nil
+	}
+	return NewDispatcher(cfg.Webhooks)
+}
+
+// Dispatch sends the event to its webhook URL in the background
+func (d *Dispatcher) Dispatch(event Event) {
+	if d == nil {
+		return
+	}
+
+	url, ok := d.hooks[event.Type]
+	if !ok {
+		return
+	}
+
+	d.wg.Add(1)
+	go func() {
+		defer d.wg.Done()
+		if err := d.post(url, event); err != nil {
+			log.Warn("Failed to deliver webhook", "event", event.Type, "url", url, "error", err)
+		}
+	}()
+}
+
+// Wait blocks until all in-flight webhook deliveries have finished
+func (d *Dispatcher) Wait() {
+	if d == nil {
+		return
+	}
+	d.wg.Wait()
+}
+
+// post delivers a single event payload
+func (d *Dispatcher) post(url string, event Event) error {
+	payload, err := json.Marshal(event)
+	if err != nil {
+		return fmt.Errorf("failed to marshal event: %w", err)
+	}
+
+	resp, err := d.client.Post(url, "application/json", bytes.NewReader(payload))
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode >= 300 {
+		return fmt.Errorf("unexpected status: %s", resp.Status)
+	}
+	return nil
+}
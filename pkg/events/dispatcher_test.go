@@ -0,0 +1,126 @@
+package events
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"sync"
+	"testing"
+
+	"github.com/nehpz/claudicus/pkg/config"
+)
+
+// recorder is a webhook endpoint that records the events it receives
+type recorder struct {
+	mu     sync.Mutex
+	events []Event
+	server *httptest.Server
+}
+
+func newRecorder(t *testing.T, status int) *recorder {
+	t.Helper()
+	r := &recorder{}
+	r.server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		var event Event
+		if err := json.NewDecoder(req.Body).Decode(&event); err != nil {
+			t.Errorf("Failed to decode webhook payload: %v", err)
+		}
+		if ct := req.Header.Get("Content-Type"); ct != "application/json" {
+			t.Errorf("Expected application/json content type, got %q", ct)
+		}
+		r.mu.Lock()
+		r.events = append(r.events, event)
+		r.mu.Unlock()
+		w.WriteHeader(status)
+	}))
+	t.Cleanup(r.server.Close)
+	return r
+}
+
+func (r *recorder) received() []Event {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return append([]Event{}, r.events...)
+}
+
+func TestNewDispatcherNoHooks(t *testing.T) {
+	if d := NewDispatcher(nil); d != nil {
+		t.Error("Expected nil dispatcher for nil config")
+	}
+	if d := NewDispatcher(&config.WebhooksConfig{}); d != nil {
+		t.Error("Expected nil dispatcher when no URLs are configured")
+	}
+}
+
+func TestNilDispatcherIsSafe(t *testing.T) {
+	var d *Dispatcher
+	d.Dispatch(NewEvent(EventSpawn, "agent-repo-abc123-sarah", ""))
+	d.Wait()
+}
+
+func TestDispatchPostsToConfiguredHook(t *testing.T) {
+	spawn := newRecorder(t, http.StatusOK)
+	kill := newRecorder(t, http.StatusNoContent)
+
+	d := NewDispatcher(&config.WebhooksConfig{
+		OnSpawn: spawn.server.URL,
+		OnKill:  kill.server.URL,
+	})
+	if d == nil {
+		t.Fatal("Expected non-nil dispatcher")
+	}
+
+	d.Dispatch(NewEvent(EventSpawn, "agent-repo-abc123-sarah", "build a todo app"))
+	d.Dispatch(NewEvent(EventKill, "agent-repo-abc123-john", ""))
+	// No hook configured for stuck, so this is dropped
+	d.Dispatch(NewEvent(EventStuck, "agent-repo-abc123-john", ""))
+	d.Wait()
+
+	spawnEvents := spawn.received()
+	if len(spawnEvents) != 1 {
+		t.Fatalf("Expected 1 spawn event, got %d", len(spawnEvents))
+	}
+	got := spawnEvents[0]
+	if got.Type != EventSpawn || got.Agent != "sarah" || got.Message != "build a todo app" {
+		t.Errorf("Unexpected spawn payload: %+v", got)
+	}
+	if got.Timestamp.IsZero() {
+		t.Error("Expected timestamp to be set")
+	}
+
+	killEvents := kill.received()
+	if len(killEvents) != 1 || killEvents[0].Type != EventKill || killEvents[0].Agent != "john" {
+		t.Errorf("Unexpected kill events: %+v", killEvents)
+	}
+}
+
+func TestPostReportsErrorStatus(t *testing.T) {
+	hook := newRecorder(t, http.StatusInternalServerError)
+	d := NewDispatcher(&config.WebhooksConfig{OnReady: hook.server.URL})
+
+	if err := d.post(hook.server.URL, NewEvent(EventReady, "", "")); err == nil {
+		t.Error("Expected error for 500 response")
+	}
+}
+
+func TestLoadDispatcher(t *testing.T) {
+	if d := LoadDispatcher(filepath.Join(t.TempDir(), "missing.yaml")); d != nil {
+		t.Error("Expected nil dispatcher for missing config")
+	}
+
+	configPath := filepath.Join(t.TempDir(), "uzi.yaml")
+	content := "devCommand: npm start\nportRange: 3000-3010\nwebhooks:\n  onCheckpoint: http://localhost:9/hook\n"
+	if err := os.WriteFile(configPath, []byte(content), 0644); err != nil {
+		t.Fatalf("Failed to write config: %v", err)
+	}
+
+	d := LoadDispatcher(configPath)
+	if d == nil {
+		t.Fatal("Expected non-nil dispatcher")
+	}
+	if d.hooks[EventCheckpoint] != "http://localhost:9/hook" {
+		t.Errorf("Expected checkpoint hook to be loaded, got %v", d.hooks)
+	}
+}
@@ -0,0 +1,225 @@
+package fleet
+
+import (
+	"fmt"
+	"io/fs"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+// DefaultDiskTTL is how long a worktree's measured size is reused before the
+// directory is walked again
+const DefaultDiskTTL = time.Minute
+
+// Agent is the per-session input to the aggregator
+type Agent struct {
+	Name         string
+	Status       string
+	Insertions   int
+	Deletions    int
+	Port         int
+	WorktreePath string
+	Attached     bool
+}
+
+// Summary holds the aggregate stats for a set of agents
+type Summary struct {
+	Agents     int   `json:"agents"`
+	Running    int   `json:"running"`
+	Ready      int   `json:"ready"`
+	Stuck      int   `json:"stuck"`
+	Insertions int   `json:"insertions"`
+	Deletions  int   `json:"deletions"`
+	Attached   int   `json:"attached"`
+	Ports      []int `json:"ports"`
+	DiskBytes  int64 `json:"disk_bytes"`
+}
+
+type diskEntry struct {
+	size     int64
+	measured time.Time
+}
+
+// Aggregator computes fleet summaries. Worktree sizes are cached because
+// walking a worktree (node_modules and all) is far slower than the rest.
+type Aggregator struct {
+	mu      sync.Mutex
+	diskTTL time.Duration
+	dirSize func(path string) (int64, error)
+	now     func() time.Time
+	disk    map[string]diskEntry
+}
+
+// NewAggregator creates an aggregator that measures worktrees on disk
+func NewAggregator() *Aggregator {
+	return &Aggregator{
+		diskTTL: DefaultDiskTTL,
+		dirSize: DirSize,
+		now:     time.Now,
+		disk:    make(map[string]diskEntry),
+	}
+}
+
+// Summarize aggregates the given agents
+func (a *Aggregator) Summarize(agents []Agent) Summary {
+	var s Summary
+	s.Agents = len(agents)
+
+	seenPorts := make(map[int]bool)
+	seenPaths := make(map[string]bool)
+	for _, agent := range agents {
+		switch NormalizeStatus(agent.Status) {
+		case "running":
+			s.Running++
+		case "ready":
+			s.Ready++
+		case "stuck":
+			s.Stuck++
+		}
+
+		s.Insertions += agent.Insertions
+		s.Deletions += agent.Deletions
+		if agent.Attached {
+			s.Attached++
+		}
+		if agent.Port != 0 && !seenPorts[agent.Port] {
+			seenPorts[agent.Port] = true
+			s.Ports = append(s.Ports, agent.Port)
+		}
+		if agent.WorktreePath != "" && !seenPaths[agent.WorktreePath] {
+			seenPaths[agent.WorktreePath] = true
+			s.DiskBytes += a.worktreeSize(agent.WorktreePath)
+		}
+	}
+	sort.Ints(s.Ports)
+
+	a.pruneDisk(seenPaths)
+	return s
+}
+
+// worktreeSize returns the cached size of path, measuring it when stale
+func (a *Aggregator) worktreeSize(path string) int64 {
+	a.mu.Lock()
+	entry, ok := a.disk[path]
+	a.mu.Unlock()
+	if ok && a.now().Sub(entry.measured) < a.diskTTL {
+		return entry.size
+	}
+
+	size, err := a.dirSize(path)
+	if err != nil {
+		// A worktree that vanished between listing and measuring counts as empty
+		size = 0
+	}
+
+	a.mu.Lock()
+	a.disk[path] = diskEntry{size: size, measured: a.now()}
+	a.mu.Unlock()
+	return size
+}
+
+// pruneDisk forgets worktrees that are no longer part of the fleet
+func (a *Aggregator) pruneDisk(keep map[string]bool) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	for path := range a.disk {
+		if !keep[path] {
+			delete(a.disk, path)
+		}
+	}
+}
+
+// NormalizeStatus maps the statuses reported by `uzi ls` (running/ready) and by
+// the activity monitor (working/idle/stuck) onto running, ready and stuck.
+// Anything else is returned unchanged.
+func NormalizeStatus(status string) string {
+	switch status {
+	case "running", "working":
+		return "running"
+	case "ready", "idle":
+		return "ready"
+	default:
+		return status
+	}
+}
+
+// DirSize returns the total size of the regular files under path. Entries that
+// cannot be read are skipped.
+func DirSize(path string) (int64, error) {
+	var total int64
+	err := filepath.WalkDir(path, func(_ string, d fs.DirEntry, err error) error {
+		if err != nil {
+			if d == nil {
+				return err
+			}
+			return nil
+		}
+		if d.Type().IsRegular() {
+			if info, err := d.Info(); err == nil {
+				total += info.Size()
+			}
+		}
+		return nil
+	})
+	return total, err
+}
+
+// String renders the summary as a single dashboard line, e.g.
+// "3 agents (1 running, 1 ready, 1 stuck) · +120/-30 · 1 attached · ports 3000-3002 · 1.2 GB in worktrees"
+func (s Summary) String() string {
+	parts := []string{
+		fmt.Sprintf("%s (%d running, %d ready, %d stuck)", plural(s.Agents, "agent"), s.Running, s.Ready, s.Stuck),
+		fmt.Sprintf("+%d/-%d", s.Insertions, s.Deletions),
+		fmt.Sprintf("%d attached", s.Attached),
+	}
+	if ports := FormatPorts(s.Ports); ports != "" {
+		parts = append(parts, "ports "+ports)
+	} else {
+		parts = append(parts, "no ports")
+	}
+	parts = append(parts, FormatBytes(s.DiskBytes)+" in worktrees")
+	return strings.Join(parts, " · ")
+}
+
+// FormatPorts renders sorted ports compactly, collapsing consecutive runs
+// into ranges: [3000 3001 3002 3005] becomes "3000-3002,3005"
+func FormatPorts(ports []int) string {
+	var ranges []string
+	for i := 0; i < len(ports); {
+		j := i
+		for j+1 < len(ports) && ports[j+1] == ports[j]+1 {
+			j++
+		}
+		if i == j {
+			ranges = append(ranges, fmt.Sprintf("%d", ports[i]))
+		} else {
+			ranges = append(ranges, fmt.Sprintf("%d-%d", ports[i], ports[j]))
+		}
+		i = j + 1
+	}
+	return strings.Join(ranges, ",")
+}
+
+// FormatBytes renders a byte count with a binary unit, e.g. "1.5 GB"
+func FormatBytes(n int64) string {
+	const unit = 1024
+	if n < unit {
+		return fmt.Sprintf("%d B", n)
+	}
+	div, exp := int64(unit), 0
+	for m := n / unit; m >= unit; m /= unit {
+		div *= unit
+		exp++
+	}
+	return fmt.Sprintf("%.1f %cB", float64(n)/float64(div), "KMGTPE"[exp])
+}
+
+func plural(n int, noun string) string {
+	if n == 1 {
+		return fmt.Sprintf("%d %s", n, noun)
+	}
+	return fmt.Sprintf("%d %ss", n, noun)
+}
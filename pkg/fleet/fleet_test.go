@@ -0,0 +1,152 @@
+package fleet
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestSummarize(t *testing.T) {
+	agg := NewAggregator()
+	agg.dirSize = func(path string) (int64, error) {
+		if path == "/gone" {
+			return 0, errors.New("no such directory")
+		}
+		return 1024, nil
+	}
+
+	summary := agg.Summarize([]Agent{
+		{Name: "sarah", Status: "running", Insertions: 10, Deletions: 2, Port: 3001, WorktreePath: "/wt/sarah", Attached: true},
+		{Name: "john", Status: "idle", Insertions: 5, Port: 3000, WorktreePath: "/wt/john"},
+		{Name: "emily", Status: "stuck", Deletions: 7, WorktreePath: "/gone"},
+		{Name: "mike", Status: "working", Port: 3005, WorktreePath: "/wt/mike"},
+		{Name: "lucy", Status: "unknown"},
+	})
+
+	want := Summary{
+		Agents:     5,
+		Running:    2,
+		Ready:      1,
+		Stuck:      1,
+		Insertions: 15,
+		Deletions:  9,
+		Attached:   1,
+		Ports:      []int{3000, 3001, 3005},
+		DiskBytes:  3 * 1024,
+	}
+	if !reflect.DeepEqual(summary, want) {
+		t.Errorf("Summarize() = %+v, want %+v", summary, want)
+	}
+}
+
+func TestSummarizeCachesDiskUsage(t *testing.T) {
+	now := time.Unix(1000, 0)
+	calls := 0
+	agg := NewAggregator()
+	agg.now = func() time.Time { return now }
+	agg.dirSize = func(string) (int64, error) {
+		calls++
+		return int64(calls * 100), nil
+	}
+
+	agents := []Agent{{Name: "sarah", WorktreePath: "/wt/sarah"}}
+	if got := agg.Summarize(agents).DiskBytes; got != 100 {
+		t.Errorf("DiskBytes = %d, want 100", got)
+	}
+
+	now = now.Add(DefaultDiskTTL / 2)
+	if got := agg.Summarize(agents).DiskBytes; got != 100 || calls != 1 {
+		t.Errorf("Expected cached size within TTL, got %d after %d walks", got, calls)
+	}
+
+	now = now.Add(DefaultDiskTTL)
+	if got := agg.Summarize(agents).DiskBytes; got != 200 || calls != 2 {
+		t.Errorf("Expected worktree to be measured again after TTL, got %d after %d walks", got, calls)
+	}
+
+	// Worktrees that leave the fleet are dropped from the cache
+	agg.Summarize(nil)
+	if len(agg.disk) != 0 {
+		t.Errorf("Expected disk cache to be pruned, got %v", agg.disk)
+	}
+}
+
+func TestDirSize(t *testing.T) {
+	dir := t.TempDir()
+	os.WriteFile(filepath.Join(dir, "a.txt"), make([]byte, 100), 0644)
+	os.MkdirAll(filepath.Join(dir, "sub"), 0755)
+	os.WriteFile(filepath.Join(dir, "sub", "b.txt"), make([]byte, 50), 0644)
+
+	size, err := DirSize(dir)
+	if err != nil {
+		t.Fatalf("DirSize() error = %v", err)
+	}
+	if size != 150 {
+		t.Errorf("DirSize() = %d, want 150", size)
+	}
+
+	if _, err := DirSize(filepath.Join(dir, "missing")); err == nil {
+		t.Error("Expected error for missing directory")
+	}
+}
+
+func TestSummaryString(t *testing.T) {
+	summary := Summary{
+		Agents:     3,
+		Running:    1,
+		Ready:      1,
+		Stuck:      1,
+		Insertions: 120,
+		Deletions:  30,
+		Attached:   1,
+		Ports:      []int{3000, 3001, 3002},
+		DiskBytes:  1288490189,
+	}
+	want := "3 agents (1 running, 1 ready, 1 stuck) · +120/-30 · 1 attached · ports 3000-3002 · 1.2 GB in worktrees"
+	if got := summary.String(); got != want {
+		t.Errorf("String() = %q, want %q", got, want)
+	}
+
+	empty := Summary{Agents: 1}
+	want = "1 agent (0 running, 0 ready, 0 stuck) · +0/-0 · 0 attached · no ports · 0 B in worktrees"
+	if got := empty.String(); got != want {
+		t.Errorf("String() = %q, want %q", got, want)
+	}
+}
+
+func TestFormatPorts(t *testing.T) {
+	tests := []struct {
+		ports []int
+		want  string
+	}{
+		{nil, ""},
+		{[]int{3000}, "3000"},
+		{[]int{3000, 3001, 3002, 3005}, "3000-3002,3005"},
+		{[]int{3000, 3002, 3003}, "3000,3002-3003"},
+	}
+	for _, tt := range tests {
+		if got := FormatPorts(tt.ports); got != tt.want {
+			t.Errorf("FormatPorts(%v) = %q, want %q", tt.ports, got, tt.want)
+		}
+	}
+}
+
+func TestFormatBytes(t *testing.T) {
+	tests := []struct {
+		n    int64
+		want string
+	}{
+		{0, "0 B"},
+		{512, "512 B"},
+		{1536, "1.5 KB"},
+		{5 * 1024 * 1024, "5.0 MB"},
+	}
+	for _, tt := range tests {
+		if got := FormatBytes(tt.n); got != tt.want {
+			t.Errorf("FormatBytes(%d) = %q, want %q", tt.n, got, tt.want)
+		}
+	}
+}
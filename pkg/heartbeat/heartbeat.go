@@ -0,0 +1,253 @@
+// Package heartbeat keeps one status file per agent session under
+// .uzi/heartbeats in the repository's main checkout. `uzi auto` rewrites them
+// on every interval, so process supervisors such as systemd, Kubernetes
+// sidecars, or cron checks can tell dead agents apart without running uzi: a
+// session is unhealthy when its file says it is dead or has not been
+// rewritten since its stale_at time.
+package heartbeat
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/nehpz/claudicus/pkg/state"
+)
+
+// DefaultInterval is how often `uzi auto` rewrites heartbeats
+const DefaultInterval = 10 * time.Second
+
+// staleIntervals is how many intervals a heartbeat may be missed before it is
+// stale, so that a slow refresh is not taken for a stopped watcher
+const staleIntervals = 3
+
+// Beat is the content of a heartbeat file
+type Beat struct {
+	Session string `json:"session"`
+	Agent   string `json:"agent"`
+	// Status is the session status, as in uzi ls, or dead when its tmux
+	// session is gone
+	Status string `json:"status"`
+	Host   string `json:"host,omitempty"`
+	Port   int    `json:"port,omitempty"`
+	// PID is the process id of the `uzi auto` writing the heartbeat
+	PID       int       `json:"pid"`
+	UpdatedAt time.Time `json:"updated_at"`
+	// StaleAt is when the heartbeat goes stale unless it is rewritten
+	StaleAt time.Time `json:"stale_at"`
+}
+
+// NewBeat returns a heartbeat written at now that goes stale after
+// staleIntervals missed intervals
+func NewBeat(session, agent, status string, now time.Time, interval time.Duration) Beat {
+	return Beat{
+		Session:   session,
+		Agent:     agent,
+		Status:    status,
+		PID:       os.Getpid(),
+		UpdatedAt: now,
+		StaleAt:   now.Add(staleIntervals * interval),
+	}
+}
+
+// Problem returns why the session is unhealthy at now, or "" if it is healthy
+func (b Beat) Problem(now time.Time) string {
+	switch {
+	case b.Status == state.StatusDead:
+		return "agent is dead"
+	case now.After(b.StaleAt):
+		return fmt.Sprintf("heartbeat is stale since %s (is uzi auto running?)", b.StaleAt.Format(time.RFC3339))
+	}
+	return ""
+}
+
+// Dir returns the heartbeat directory of a main checkout
+func Dir(repoRoot string) string {
+	return filepath.Join(repoRoot, ".uzi", "heartbeats")
+}
+
+// Store reads and writes the heartbeat files of one repository
+type Store struct {
+	dir string
+}
+
+// NewStore returns a store of the heartbeats in dir
+func NewStore(dir string) *Store {
+	return &Store{dir: dir}
+}
+
+// Path returns the heartbeat file of a session
+func (s *Store) Path(session string) string {
+	return filepath.Join(s.dir, session)
+}
+
+// Write replaces the heartbeat file of the beat's session. The file is
+// renamed into place, so readers never see a partial write.
+func (s *Store) Write(beat Beat) error {
+	if err := os.MkdirAll(s.dir, 0755); err != nil {
+		return err
+	}
+	// Keep .uzi out of git status in the main checkout
+	ignore := filepath.Join(filepath.Dir(s.dir), ".gitignore")
+	if _, err := os.Stat(ignore); os.IsNotExist(err) {
+		if err := os.WriteFile(ignore, []byte("*\n"), 0644); err != nil {
+			return err
+		}
+	}
+	data, err := json.MarshalIndent(beat, "", "  ")
+	if err != nil {
+		return err
+	}
+	tmp := s.Path(beat.Session) + ".tmp"
+	if err := os.WriteFile(tmp, append(data, '\n'), 0644); err != nil {
+		return err
+	}
+	return os.Rename(tmp, s.Path(beat.Session))
+}
+
+// Read returns the heartbeat of a session; the error satisfies os.IsNotExist
+// if there is none
+func (s *Store) Read(session string) (Beat, error) {
+	data, err := os.ReadFile(s.Path(session))
+	if err != nil {
+		return Beat{}, err
+	}
+	var beat Beat
+	if err := json.Unmarshal(data, &beat); err != nil {
+		return Beat{}, fmt.Errorf("error parsing heartbeat of %s: %w", session, err)
+	}
+	return beat, nil
+}
+
+// Sessions returns the names of the sessions with a heartbeat file, sorted
+func (s *Store) Sessions() ([]string, error) {
+	entries, err := os.ReadDir(s.dir)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	var sessions []string
+	for _, entry := range entries {
+		if entry.IsDir() || strings.HasSuffix(entry.Name(), ".tmp") {
+			continue
+		}
+		sessions = append(sessions, entry.Name())
+	}
+	sort.Strings(sessions)
+	return sessions, nil
+}
+
+// Prune removes the heartbeats of sessions not in keep, such as killed ones
+func (s *Store) Prune(keep map[string]bool) error {
+	sessions, err := s.Sessions()
+	if err != nil {
+		return err
+	}
+	for _, session := range sessions {
+		if keep[session] {
+			continue
+		}
+		if err := os.Remove(s.Path(session)); err != nil && !os.IsNotExist(err) {
+			return err
+		}
+	}
+	return nil
+}
+
+// transitionRetention is how long transitions are kept
+const transitionRetention = 7 * 24 * time.Hour
+
+// Transition is a change of a session's status seen by `uzi auto`, with the
+// size of its diff at the time, for `uzi digest`
+type Transition struct {
+	Session    string    `json:"session"`
+	Agent      string    `json:"agent"`
+	From       string    `json:"from,omitempty"` // empty for the first status seen
+	To         string    `json:"to"`
+	At         time.Time `json:"at"`
+	Insertions int       `json:"insertions"`
+	Deletions  int       `json:"deletions"`
+	// Snapshot is the file the agent pane was saved to on the transition,
+	// if it was
+	Snapshot string `json:"snapshot,omitempty"`
+}
+
+// SnapshotLines is how many lines of the agent pane a snapshot keeps
+const SnapshotLines = 200
+
+// WriteSnapshot saves the agent pane content of a session at a time to
+// .uzi/snapshots/<session>-<time>.txt, beside the heartbeat directory, and
+// returns the file's path
+func (s *Store) WriteSnapshot(session string, at time.Time, content string) (string, error) {
+	dir := filepath.Join(filepath.Dir(s.dir), "snapshots")
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return "", err
+	}
+	path := filepath.Join(dir, fmt.Sprintf("%s-%s.txt", session, at.UTC().Format("20060102T150405Z")))
+	if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+		return "", err
+	}
+	return path, nil
+}
+
+// transitionsPath returns the file transitions are logged to, beside the
+// heartbeat directory
+func (s *Store) transitionsPath() string {
+	return filepath.Join(filepath.Dir(s.dir), "transitions.jsonl")
+}
+
+// AppendTransition logs a status change, dropping transitions older than
+// transitionRetention
+func (s *Store) AppendTransition(transition Transition) error {
+	transitions, err := s.Transitions(transition.At.Add(-transitionRetention))
+	if err != nil {
+		return err
+	}
+	transitions = append(transitions, transition)
+
+	var data []byte
+	for _, t := range transitions {
+		line, err := json.Marshal(t)
+		if err != nil {
+			return err
+		}
+		data = append(append(data, line...), '\n')
+	}
+	if err := os.MkdirAll(filepath.Dir(s.transitionsPath()), 0755); err != nil {
+		return err
+	}
+	tmp := s.transitionsPath() + ".tmp"
+	if err := os.WriteFile(tmp, data, 0644); err != nil {
+		return err
+	}
+	return os.Rename(tmp, s.transitionsPath())
+}
+
+// Transitions returns the logged status changes since a time, oldest first
+func (s *Store) Transitions(since time.Time) ([]Transition, error) {
+	data, err := os.ReadFile(s.transitionsPath())
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	var transitions []Transition
+	for _, line := range strings.Split(string(data), "\n") {
+		var t Transition
+		if line == "" || json.Unmarshal([]byte(line), &t) != nil {
+			continue
+		}
+		if !t.At.Before(since) {
+			transitions = append(transitions, t)
+		}
+	}
+	sort.SliceStable(transitions, func(i, j int) bool { return transitions[i].At.Before(transitions[j].At) })
+	return transitions, nil
+}
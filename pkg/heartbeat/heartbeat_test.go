@@ -0,0 +1,108 @@
+package heartbeat
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestStoreWriteRead(t *testing.T) {
+	repo := t.TempDir()
+	store := NewStore(Dir(repo))
+	now := time.Date(2025, 1, 1, 12, 0, 0, 0, time.UTC)
+
+	beat := NewBeat("agent-app-abc123-sarah", "sarah", "running", now, 10*time.Second)
+	beat.Port = 3000
+	if err := store.Write(beat); err != nil {
+		t.Fatalf("Write() error = %v", err)
+	}
+	if _, err := os.Stat(filepath.Join(repo, ".uzi", "heartbeats", "agent-app-abc123-sarah")); err != nil {
+		t.Errorf("Expected the heartbeat under .uzi/heartbeats: %v", err)
+	}
+	if ignore, err := os.ReadFile(filepath.Join(repo, ".uzi", ".gitignore")); err != nil || string(ignore) != "*\n" {
+		t.Errorf("Expected .uzi to be ignored by git, got %q, %v", ignore, err)
+	}
+
+	got, err := store.Read("agent-app-abc123-sarah")
+	if err != nil {
+		t.Fatalf("Read() error = %v", err)
+	}
+	if got.Status != "running" || got.Port != 3000 || got.PID != os.Getpid() || !got.StaleAt.Equal(now.Add(30*time.Second)) {
+		t.Errorf("Unexpected heartbeat %+v", got)
+	}
+	if _, err := store.Read("agent-app-abc123-john"); !os.IsNotExist(err) {
+		t.Errorf("Expected a not-exist error for a missing heartbeat, got %v", err)
+	}
+}
+
+func TestStorePrune(t *testing.T) {
+	store := NewStore(Dir(t.TempDir()))
+	if sessions, err := store.Sessions(); err != nil || len(sessions) != 0 {
+		t.Fatalf("Expected no heartbeats before the first write, got %v, %v", sessions, err)
+	}
+	now := time.Now()
+	for _, session := range []string{"agent-app-abc123-sarah", "agent-app-abc123-john"} {
+		if err := store.Write(NewBeat(session, "", "ready", now, time.Second)); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := store.Prune(map[string]bool{"agent-app-abc123-sarah": true}); err != nil {
+		t.Fatalf("Prune() error = %v", err)
+	}
+	if sessions, _ := store.Sessions(); len(sessions) != 1 || sessions[0] != "agent-app-abc123-sarah" {
+		t.Errorf("Expected only sarah's heartbeat kept, got %v", sessions)
+	}
+}
+
+func TestBeatProblem(t *testing.T) {
+	now := time.Date(2025, 1, 1, 12, 0, 0, 0, time.UTC)
+	fresh := NewBeat("s", "a", "running", now, 10*time.Second)
+	if problem := fresh.Problem(now.Add(20 * time.Second)); problem != "" {
+		t.Errorf("Expected a fresh heartbeat to be healthy, got %q", problem)
+	}
+	if problem := fresh.Problem(now.Add(31 * time.Second)); !strings.Contains(problem, "stale") {
+		t.Errorf("Expected a stale heartbeat, got %q", problem)
+	}
+	dead := NewBeat("s", "a", "dead", now, 10*time.Second)
+	if problem := dead.Problem(now); problem != "agent is dead" {
+		t.Errorf("Expected a dead agent, got %q", problem)
+	}
+}
+
+func TestStoreTransitions(t *testing.T) {
+	repo := t.TempDir()
+	store := NewStore(Dir(repo))
+	now := time.Date(2025, 1, 10, 12, 0, 0, 0, time.UTC)
+	if transitions, err := store.Transitions(time.Time{}); err != nil || len(transitions) != 0 {
+		t.Fatalf("Expected no transitions before the first append, got %v, %v", transitions, err)
+	}
+
+	for _, transition := range []Transition{
+		{Session: "agent-app-abc123-sarah", To: "running", At: now.Add(-8 * 24 * time.Hour)},
+		{Session: "agent-app-abc123-sarah", From: "running", To: "ready", At: now.Add(-time.Hour), Insertions: 10},
+		{Session: "agent-app-abc123-john", To: "running", At: now},
+	} {
+		if err := store.AppendTransition(transition); err != nil {
+			t.Fatalf("AppendTransition() error = %v", err)
+		}
+	}
+	if _, err := os.Stat(filepath.Join(repo, ".uzi", "transitions.jsonl")); err != nil {
+		t.Errorf("Expected transitions in .uzi/transitions.jsonl: %v", err)
+	}
+	if sessions, _ := store.Sessions(); len(sessions) != 0 {
+		t.Errorf("Expected the transition log not taken for a heartbeat, got %v", sessions)
+	}
+
+	all, err := store.Transitions(time.Time{})
+	if err != nil {
+		t.Fatalf("Transitions() error = %v", err)
+	}
+	if len(all) != 2 || all[0].To != "ready" || all[0].Insertions != 10 || all[1].Session != "agent-app-abc123-john" {
+		t.Errorf("Expected the week-old transition dropped and the rest in order, got %+v", all)
+	}
+	if recent, _ := store.Transitions(now.Add(-time.Minute)); len(recent) != 1 || recent[0].Session != "agent-app-abc123-john" {
+		t.Errorf("Expected only the transition since the time, got %+v", recent)
+	}
+}
@@ -0,0 +1,212 @@
+package hosts
+
+import (
+	"context"
+	"os/exec"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/nehpz/claudicus/pkg/config"
+	"github.com/nehpz/claudicus/pkg/state"
+	"github.com/nehpz/claudicus/pkg/tmuxops"
+)
+
+// Target is the machine an agent session runs on: the local machine, or a
+// remote host reached with ssh. Remote commands run through the remote user's
+// login shell, so every argument is quoted.
+type Target struct {
+	Name     string // Host name from uzi.yaml; empty for the local machine
+	SSH      string // ssh destination; empty for the local machine
+	RepoPath string // Clone of the repository on the host; only set for spawning
+}
+
+// Local returns the local machine
+func Local() Target {
+	return Target{}
+}
+
+// FromConfig returns the named host from uzi.yaml, or the local machine for an empty name
+func FromConfig(cfg *config.Config, name string) (Target, error) {
+	if name == "" {
+		return Local(), nil
+	}
+	host, err := cfg.Host(name)
+	if err != nil {
+		return Target{}, err
+	}
+	return Target{Name: name, SSH: host.SSH, RepoPath: host.RepoPath}, nil
+}
+
+// Remotes returns every host configured in uzi.yaml, sorted by name
+func Remotes(cfg *config.Config) []Target {
+	if cfg == nil {
+		return nil
+	}
+	var targets []Target
+	for name, host := range cfg.Hosts {
+		targets = append(targets, Target{Name: name, SSH: host.SSH, RepoPath: host.RepoPath})
+	}
+	sort.Slice(targets, func(i, j int) bool { return targets[i].Name < targets[j].Name })
+	return targets
+}
+
+// ForState returns the target a saved session runs on
+func ForState(agentState state.AgentState) Target {
+	return Target{Name: agentState.Host, SSH: agentState.SSH}
+}
+
+// IsLocal reports whether the target is the local machine
+func (t Target) IsLocal() bool {
+	return t.SSH == ""
+}
+
+// String returns the host name, or "local"
+func (t Target) String() string {
+	if t.IsLocal() {
+		return "local"
+	}
+	if t.Name == "" {
+		return t.SSH
+	}
+	return t.Name
+}
+
+// Command runs name with args in dir on the target. An empty dir is the
+// working directory locally and the home directory on a remote host.
+func (t Target) Command(ctx context.Context, dir, name string, args ...string) *exec.Cmd {
+	if t.IsLocal() {
+		cmd := exec.CommandContext(ctx, name, args...)
+		cmd.Dir = dir
+		return cmd
+	}
+	words := append([]string{name}, args...)
+	for i, word := range words {
+		words[i] = Quote(word)
+	}
+	return t.remote(ctx, dir, strings.Join(words, " "))
+}
+
+// Shell runs a shell script in dir on the target
+func (t Target) Shell(ctx context.Context, dir, script string) *exec.Cmd {
+	if t.IsLocal() {
+		cmd := exec.CommandContext(ctx, "sh", "-c", script)
+		cmd.Dir = dir
+		return cmd
+	}
+	return t.remote(ctx, dir, script)
+}
+
+// remote runs a command line through ssh, changing to dir first
+func (t Target) remote(ctx context.Context, dir, commandLine string) *exec.Cmd {
+	if dir != "" {
+		commandLine = "cd " + Quote(dir) + " && " + commandLine
+	}
+	// BatchMode fails instead of prompting for a password nobody can type
+	return exec.CommandContext(ctx, "ssh", "-o", "BatchMode=yes", t.SSH, commandLine)
+}
+
+// AttachCommand attaches the terminal to a session on the target
+func (t Target) AttachCommand(sessionName string) *exec.Cmd {
+	if t.IsLocal() {
+		return exec.Command("tmux", "attach-session", "-t", sessionName)
+	}
+	// -t allocates the terminal tmux needs on the remote side
+	return exec.Command("ssh", "-t", t.SSH, "tmux", "attach-session", "-t", Quote(sessionName))
+}
+
+// AttachCommandLine is AttachCommand as a line to paste into a shell, so
+// anyone who can reach the target can attach to the session too
+func (t Target) AttachCommandLine(sessionName string) string {
+	args := t.AttachCommand(sessionName).Args
+	quoted := make([]string, len(args))
+	for i, arg := range args {
+		quoted[i] = Quote(arg)
+	}
+	return strings.Join(quoted, " ")
+}
+
+// Execute implements tmuxops.CommandExecutor
+func (t Target) Execute(command string, args ...string) error {
+	return t.Command(context.Background(), "", command, args...).Run()
+}
+
+// ExecuteCommand implements state.CommandExecutor
+func (t Target) ExecuteCommand(name string, args ...string) ([]byte, error) {
+	return t.Command(context.Background(), "", name, args...).Output()
+}
+
+// RunCommand implements state.CommandExecutor
+func (t Target) RunCommand(name string, args ...string) error {
+	return t.Command(context.Background(), "", name, args...).Run()
+}
+
+// PaneContent implements state.SessionProbe
+func (t Target) PaneContent(sessionName string) (string, error) {
+	output, err := t.ExecuteCommand("tmux", "capture-pane", "-t", tmuxops.AgentTarget(sessionName), "-p")
+	if err != nil {
+		return "", err
+	}
+	return string(output), nil
+}
+
+// DiffStat implements state.SessionProbe
+func (t Target) DiffStat(worktreePath string) (string, error) {
+	output, err := t.Shell(context.Background(), worktreePath, state.DiffScript).Output()
+	if err != nil {
+		return "", err
+	}
+	return string(output), nil
+}
+
+// SessionWindows implements state.TmuxProbe
+func (t Target) SessionWindows(sessionName string) (string, error) {
+	output, err := t.ExecuteCommand("tmux", tmuxops.SessionWindowsArgs(sessionName)...)
+	if err != nil {
+		return "", err
+	}
+	return string(output), nil
+}
+
+// FileDiffStat implements state.FileDiffProbe
+func (t Target) FileDiffStat(worktreePath string) (string, error) {
+	output, err := t.Shell(context.Background(), worktreePath, state.FileDiffScript).Output()
+	if err != nil {
+		return "", err
+	}
+	return string(output), nil
+}
+
+// CountCommits implements state.CommitCountProbe
+func (t Target) CountCommits(worktreePath, from, to string) (int, error) {
+	output, err := t.ExecuteCommand("git", state.CountCommitsArgs(worktreePath, from, to)...)
+	if err != nil {
+		return 0, err
+	}
+	return strconv.Atoi(strings.TrimSpace(string(output)))
+}
+
+// Hunks implements state.HunkProbe
+func (t Target) Hunks(worktreePath string) (string, error) {
+	output, err := t.Shell(context.Background(), worktreePath, state.HunkScript).Output()
+	if err != nil {
+		return "", err
+	}
+	return string(output), nil
+}
+
+// RemoteProbe inspects sessions on the host with the given ssh destination;
+// pass it to state.WithRemoteProbe
+func RemoteProbe(ssh string) state.SessionProbe {
+	return Target{SSH: ssh}
+}
+
+// Quote quotes s for a POSIX shell
+func Quote(s string) string {
+	if s != "" && strings.IndexFunc(s, func(r rune) bool {
+		return !(r >= 'a' && r <= 'z' || r >= 'A' && r <= 'Z' || r >= '0' && r <= '9' || strings.ContainsRune("-_./:=@%+,", r))
+	}) < 0 {
+		return s
+	}
+	return "'" + strings.ReplaceAll(s, "'", `'\''`) + "'"
+}
@@ -0,0 +1,105 @@
+package hosts
+
+import (
+	"context"
+	"reflect"
+	"testing"
+
+	"github.com/nehpz/claudicus/pkg/config"
+	"github.com/nehpz/claudicus/pkg/state"
+)
+
+func TestTargetCommand(t *testing.T) {
+	ctx := context.Background()
+
+	local := Local().Command(ctx, "/src/app", "tmux", "send-keys", "-t", "agent-app-abc123-sarah:agent", "fix it", "Enter")
+	if want := []string{"tmux", "send-keys", "-t", "agent-app-abc123-sarah:agent", "fix it", "Enter"}; !reflect.DeepEqual(local.Args, want) {
+		t.Errorf("local Args = %q, want %q", local.Args, want)
+	}
+	if local.Dir != "/src/app" {
+		t.Errorf("local Dir = %q", local.Dir)
+	}
+
+	box := Target{Name: "box", SSH: "dev@box"}
+	remote := box.Command(ctx, "/src/my app", "tmux", "send-keys", "-t", "agent-app-abc123-sarah:agent", "don't stop", "Enter")
+	want := []string{"ssh", "-o", "BatchMode=yes", "dev@box",
+		`cd '/src/my app' && tmux send-keys -t agent-app-abc123-sarah:agent 'don'\''t stop' Enter`}
+	if !reflect.DeepEqual(remote.Args, want) {
+		t.Errorf("remote Args = %q, want %q", remote.Args, want)
+	}
+
+	shell := box.Shell(ctx, "", "tmux new-session -d -s s && tmux rename-window -t s:0 agent")
+	if got := shell.Args[len(shell.Args)-1]; got != "tmux new-session -d -s s && tmux rename-window -t s:0 agent" {
+		t.Errorf("Expected the script unchanged without a dir, got %q", got)
+	}
+}
+
+func TestTargetAttachCommand(t *testing.T) {
+	if got := Local().AttachCommand("s").Args; !reflect.DeepEqual(got, []string{"tmux", "attach-session", "-t", "s"}) {
+		t.Errorf("local attach = %q", got)
+	}
+	if got := (Target{SSH: "dev@box"}).AttachCommand("s").Args; !reflect.DeepEqual(got, []string{"ssh", "-t", "dev@box", "tmux", "attach-session", "-t", "s"}) {
+		t.Errorf("remote attach = %q", got)
+	}
+}
+
+func TestTargetAttachCommandLine(t *testing.T) {
+	if got, want := Local().AttachCommandLine("agent-app-abc-claude"), "tmux attach-session -t agent-app-abc-claude"; got != want {
+		t.Errorf("local attach line = %q, want %q", got, want)
+	}
+	if got, want := (Target{SSH: "dev@box"}).AttachCommandLine("agent-app-abc-claude"), "ssh -t dev@box tmux attach-session -t agent-app-abc-claude"; got != want {
+		t.Errorf("remote attach line = %q, want %q", got, want)
+	}
+}
+
+func TestFromConfig(t *testing.T) {
+	cfg := &config.Config{Hosts: map[string]config.HostConfig{
+		"box":  {SSH: "dev@box", RepoPath: "/src/app"},
+		"gpu1": {SSH: "gpu1", RepoPath: "/work/app"},
+	}}
+
+	target, err := FromConfig(cfg, "box")
+	if err != nil {
+		t.Fatalf("FromConfig() error = %v", err)
+	}
+	if want := (Target{Name: "box", SSH: "dev@box", RepoPath: "/src/app"}); target != want {
+		t.Errorf("FromConfig() = %+v, want %+v", target, want)
+	}
+	if local, err := FromConfig(nil, ""); err != nil || !local.IsLocal() {
+		t.Errorf("Expected the local machine for an empty name, got %+v, %v", local, err)
+	}
+	if _, err := FromConfig(cfg, "missing"); err == nil {
+		t.Error("Expected an error for an unknown host")
+	}
+
+	remotes := Remotes(cfg)
+	if len(remotes) != 2 || remotes[0].Name != "box" || remotes[1].Name != "gpu1" {
+		t.Errorf("Remotes() = %+v, want box and gpu1 in order", remotes)
+	}
+}
+
+func TestForState(t *testing.T) {
+	target := ForState(state.AgentState{Host: "box", SSH: "dev@box"})
+	if target.IsLocal() || target.String() != "box" {
+		t.Errorf("ForState() = %+v", target)
+	}
+	if !ForState(state.AgentState{}).IsLocal() || Local().String() != "local" {
+		t.Error("Expected sessions without a host to be local")
+	}
+}
+
+func TestQuote(t *testing.T) {
+	tests := map[string]string{
+		"plain":              "plain",
+		"dev@box:/src/app-1": "dev@box:/src/app-1",
+		"":                   "''",
+		"two words":          "'two words'",
+		"it's":               `'it'\''s'`,
+		"$HOME":              "'$HOME'",
+	}
+	for input, want := range tests {
+		if got := Quote(input); got != want {
+			t.Errorf("Quote(%q) = %q, want %q", input, got, want)
+		}
+	}
+}
@@ -0,0 +1,306 @@
+package jobs
+
+import (
+	"sync"
+	"time"
+)
+
+// Kind names the operation a job performs
+type Kind string
+
+const (
+	KindCheckpoint Kind = "checkpoint"
+	KindKill       Kind = "kill"
+	KindSpawn      Kind = "spawn"
+	KindRespawn    Kind = "respawn"
+)
+
+// Status is the lifecycle state of a job
+type Status string
+
+const (
+	StatusPending   Status = "pending"
+	StatusRunning   Status = "running"
+	StatusCompleted Status = "completed"
+	StatusFailed    Status = "failed"
+)
+
+// maxFinished is how many finished jobs are kept for display
+const maxFinished = 50
+
+// maxOutputLines is how many of the latest output lines are kept per job
+const maxOutputLines = 200
+
+// Job is a snapshot of an operation submitted to a Queue
+type Job struct {
+	ID         int
+	Kind       Kind
+	Target     string // agent or session the job operates on
+	Status     Status
+	Error      string
+	Output     []string // latest lines of output, for jobs that report it
+	EnqueuedAt time.Time
+	StartedAt  time.Time
+	FinishedAt time.Time
+}
+
+// Done reports whether the job has finished, successfully or not
+func (j Job) Done() bool {
+	return j.Status == StatusCompleted || j.Status == StatusFailed
+}
+
+// Duration returns how long the job ran, or has been running at now.
+// Pending jobs report how long they have been waiting.
+func (j Job) Duration(now time.Time) time.Duration {
+	switch {
+	case j.Done():
+		return j.FinishedAt.Sub(j.StartedAt)
+	case j.Status == StatusRunning:
+		return now.Sub(j.StartedAt)
+	default:
+		return now.Sub(j.EnqueuedAt)
+	}
+}
+
+// entry is a job together with the function that performs it
+type entry struct {
+	job Job
+	run func(output func(line string)) error
+}
+
+// Queue runs submitted jobs on a fixed number of workers. Jobs for the same
+// target run one at a time in the order they were enqueued, so a kill queued
+// after a checkpoint of the same agent waits for the checkpoint to finish.
+type Queue struct {
+	mu      sync.Mutex
+	cond    *sync.Cond
+	entries []*entry // every job, oldest first
+	busy    map[string]bool
+	nextID  int
+	closed  bool
+	changed chan struct{}
+	now     func() time.Time
+	wg      sync.WaitGroup
+}
+
+// NewQueue starts a queue with the given number of workers
+func NewQueue(workers int) *Queue {
+	if workers < 1 {
+		workers = 1
+	}
+	q := &Queue{
+		busy:    make(map[string]bool),
+		changed: make(chan struct{}, 1),
+		now:     time.Now,
+	}
+	q.cond = sync.NewCond(&q.mu)
+	for i := 0; i < workers; i++ {
+		q.wg.Add(1)
+		go q.work()
+	}
+	return q
+}
+
+// Enqueue submits run as a job of the given kind for target and returns it.
+// Jobs submitted after Close fail immediately.
+func (q *Queue) Enqueue(kind Kind, target string, run func() error) Job {
+	return q.EnqueueWithOutput(kind, target, func(func(string)) error { return run() })
+}
+
+// EnqueueWithOutput is Enqueue for jobs that report progress: each line run
+// passes to output is added to the job's Output while it runs
+func (q *Queue) EnqueueWithOutput(kind Kind, target string, run func(output func(line string)) error) Job {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	q.nextID++
+	e := &entry{
+		job: Job{
+			ID:         q.nextID,
+			Kind:       kind,
+			Target:     target,
+			Status:     StatusPending,
+			EnqueuedAt: q.now(),
+		},
+		run: run,
+	}
+	if q.closed {
+		e.job.Status = StatusFailed
+		e.job.Error = "job queue is closed"
+		e.job.StartedAt = e.job.EnqueuedAt
+		e.job.FinishedAt = e.job.EnqueuedAt
+	}
+	q.entries = append(q.entries, e)
+	q.notify()
+	// Wait shares the cond with the workers, so waking a single goroutine
+	// could wake a waiter and leave the job for an idle worker unnoticed
+	q.cond.Broadcast()
+	return e.job
+}
+
+// Jobs returns a snapshot of all jobs, newest first
+func (q *Queue) Jobs() []Job {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	jobs := make([]Job, 0, len(q.entries))
+	for i := len(q.entries) - 1; i >= 0; i-- {
+		jobs = append(jobs, q.entries[i].job)
+	}
+	return jobs
+}
+
+// Get returns the job with the given ID
+func (q *Queue) Get(id int) (Job, bool) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	if e := q.find(id); e != nil {
+		return e.job, true
+	}
+	return Job{}, false
+}
+
+// find returns the entry of the job with the given ID. Called with mu held.
+func (q *Queue) find(id int) *entry {
+	for _, e := range q.entries {
+		if e.job.ID == id {
+			return e
+		}
+	}
+	return nil
+}
+
+// Wait blocks until the job with the given ID has finished and returns it. It
+// returns false if the job is unknown.
+func (q *Queue) Wait(id int) (Job, bool) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	for {
+		e := q.find(id)
+		if e == nil {
+			return Job{}, false
+		}
+		if e.job.Done() {
+			return e.job, true
+		}
+		q.cond.Wait()
+	}
+}
+
+// Changed returns a channel that receives a value after jobs were added or
+// changed status. Notifications are coalesced, so readers should re-read Jobs.
+func (q *Queue) Changed() <-chan struct{} {
+	return q.changed
+}
+
+// Close stops accepting work and waits until every queued job has finished
+func (q *Queue) Close() {
+	q.mu.Lock()
+	q.closed = true
+	q.cond.Broadcast()
+	q.mu.Unlock()
+	q.wg.Wait()
+}
+
+// work runs jobs until the queue is closed
+func (q *Queue) work() {
+	defer q.wg.Done()
+	for {
+		q.mu.Lock()
+		e := q.next()
+		for e == nil && !q.closed {
+			q.cond.Wait()
+			e = q.next()
+		}
+		if e == nil {
+			// Closed with nothing runnable; jobs still waiting on a busy target
+			// are picked up by the worker that runs that target
+			q.mu.Unlock()
+			return
+		}
+		e.job.Status = StatusRunning
+		e.job.StartedAt = q.now()
+		q.busy[e.job.Target] = true
+		q.notify()
+		q.mu.Unlock()
+
+		err := e.run(func(line string) { q.appendOutput(e, line) })
+
+		q.mu.Lock()
+		e.job.FinishedAt = q.now()
+		if err != nil {
+			e.job.Status = StatusFailed
+			e.job.Error = err.Error()
+		} else {
+			e.job.Status = StatusCompleted
+		}
+		delete(q.busy, e.job.Target)
+		q.pruneFinished()
+		q.notify()
+		// Another job for the same target may be waiting on this one
+		q.cond.Broadcast()
+		q.mu.Unlock()
+	}
+}
+
+// appendOutput adds a line to a job's output, dropping the oldest lines past
+// maxOutputLines. Trimming copies, so snapshots already handed out keep their lines.
+func (q *Queue) appendOutput(e *entry, line string) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	if len(e.job.Output) >= maxOutputLines {
+		e.job.Output = append([]string(nil), e.job.Output[len(e.job.Output)-maxOutputLines+1:]...)
+	}
+	e.job.Output = append(e.job.Output, line)
+	q.notify()
+}
+
+// next returns the oldest pending job whose target is idle. Called with mu held.
+func (q *Queue) next() *entry {
+	waiting := make(map[string]bool)
+	for _, e := range q.entries {
+		if e.job.Status != StatusPending {
+			continue
+		}
+		// Keep per-target order: only the oldest pending job of a target may start
+		if !q.busy[e.job.Target] && !waiting[e.job.Target] {
+			return e
+		}
+		waiting[e.job.Target] = true
+	}
+	return nil
+}
+
+// pruneFinished drops the oldest finished jobs beyond maxFinished. Called with mu held.
+func (q *Queue) pruneFinished() {
+	finished := 0
+	for _, e := range q.entries {
+		if e.job.Done() {
+			finished++
+		}
+	}
+	if finished <= maxFinished {
+		return
+	}
+
+	drop := finished - maxFinished
+	kept := q.entries[:0]
+	for _, e := range q.entries {
+		if drop > 0 && e.job.Done() {
+			drop--
+			continue
+		}
+		kept = append(kept, e)
+	}
+	q.entries = kept
+}
+
+// notify signals Changed without blocking. Called with mu held.
+func (q *Queue) notify() {
+	select {
+	case q.changed <- struct{}{}:
+	default:
+	}
+}
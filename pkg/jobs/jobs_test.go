@@ -0,0 +1,224 @@
+package jobs
+
+import (
+	"errors"
+	"sync"
+	"testing"
+	"time"
+)
+
+// waitFor polls the queue until the job reaches a finished state
+func waitFor(t *testing.T, q *Queue, id int) Job {
+	t.Helper()
+	deadline := time.Now().Add(2 * time.Second)
+	for time.Now().Before(deadline) {
+		if job, ok := q.Get(id); ok && job.Done() {
+			return job
+		}
+		time.Sleep(time.Millisecond)
+	}
+	t.Fatalf("job %d did not finish", id)
+	return Job{}
+}
+
+func TestQueueRunsJobs(t *testing.T) {
+	q := NewQueue(2)
+	defer q.Close()
+
+	ok := q.Enqueue(KindCheckpoint, "sarah", func() error { return nil })
+	failed := q.Enqueue(KindKill, "emily", func() error { return errors.New("tmux not running") })
+	if ok.Status != StatusPending || ok.ID == failed.ID {
+		t.Errorf("Unexpected enqueued jobs %+v %+v", ok, failed)
+	}
+
+	if job := waitFor(t, q, ok.ID); job.Status != StatusCompleted || job.Error != "" {
+		t.Errorf("Expected completed job, got %+v", job)
+	}
+	if job := waitFor(t, q, failed.ID); job.Status != StatusFailed || job.Error != "tmux not running" {
+		t.Errorf("Expected failed job with error, got %+v", job)
+	}
+
+	jobs := q.Jobs()
+	if len(jobs) != 2 || jobs[0].ID != failed.ID {
+		t.Errorf("Expected jobs newest first, got %+v", jobs)
+	}
+}
+
+func TestQueueSerializesTarget(t *testing.T) {
+	q := NewQueue(4)
+	defer q.Close()
+
+	release := make(chan struct{})
+	var mu sync.Mutex
+	var order []string
+
+	first := q.Enqueue(KindCheckpoint, "sarah", func() error {
+		<-release
+		mu.Lock()
+		order = append(order, "checkpoint")
+		mu.Unlock()
+		return nil
+	})
+	second := q.Enqueue(KindKill, "sarah", func() error {
+		mu.Lock()
+		order = append(order, "kill")
+		mu.Unlock()
+		return nil
+	})
+	other := q.Enqueue(KindSpawn, "emily", func() error { return nil })
+
+	// Other targets are not held up by sarah's checkpoint
+	waitFor(t, q, other.ID)
+	if job, _ := q.Get(second.ID); job.Status != StatusPending {
+		t.Errorf("Expected kill to wait for the checkpoint, got %s", job.Status)
+	}
+
+	close(release)
+	waitFor(t, q, first.ID)
+	waitFor(t, q, second.ID)
+	if len(order) != 2 || order[0] != "checkpoint" || order[1] != "kill" {
+		t.Errorf("Expected checkpoint before kill, got %v", order)
+	}
+}
+
+func TestQueueCollectsOutput(t *testing.T) {
+	q := NewQueue(1)
+	defer q.Close()
+
+	started, release := make(chan struct{}), make(chan struct{})
+	job := q.EnqueueWithOutput(KindCheckpoint, "sarah", func(output func(string)) error {
+		output("Committing changes")
+		close(started)
+		<-release
+		for i := 0; i < maxOutputLines+5; i++ {
+			output("line")
+		}
+		output("Merged")
+		return nil
+	})
+
+	<-started
+	if running, _ := q.Get(job.ID); len(running.Output) != 1 || running.Output[0] != "Committing changes" {
+		t.Errorf("Expected output while the job runs, got %v", running.Output)
+	}
+	close(release)
+
+	done := waitFor(t, q, job.ID)
+	if len(done.Output) != maxOutputLines || done.Output[len(done.Output)-1] != "Merged" {
+		t.Errorf("Expected the latest %d lines kept, got %d ending in %q", maxOutputLines, len(done.Output), done.Output[len(done.Output)-1])
+	}
+}
+
+func TestQueueWait(t *testing.T) {
+	q := NewQueue(1)
+
+	job := q.Enqueue(KindKill, "sarah", func() error {
+		time.Sleep(10 * time.Millisecond)
+		return errors.New("boom")
+	})
+	done, ok := q.Wait(job.ID)
+	if !ok || done.Status != StatusFailed || done.Error != "boom" {
+		t.Errorf("Wait() = %+v, %v", done, ok)
+	}
+	if _, ok := q.Wait(999); ok {
+		t.Error("Expected Wait on an unknown job to fail")
+	}
+
+}
+
+func TestQueueStartsJobWhileOthersWait(t *testing.T) {
+	q := NewQueue(2)
+	release := make(chan struct{})
+	defer q.Close()
+	defer close(release)
+
+	long := q.Enqueue(KindCheckpoint, "sarah", func() error {
+		<-release
+		return nil
+	})
+	// Goroutines waiting on the long job sleep on the same cond as the idle worker
+	for i := 0; i < 4; i++ {
+		go q.Wait(long.ID)
+	}
+	time.Sleep(10 * time.Millisecond)
+
+	other := q.Enqueue(KindSpawn, "emily", func() error { return nil })
+	waitFor(t, q, other.ID)
+	if job, _ := q.Get(long.ID); job.Done() {
+		t.Errorf("Expected the long job to still run, got %s", job.Status)
+	}
+}
+
+func TestQueueCloseDrainsJobs(t *testing.T) {
+	q := NewQueue(1)
+
+	var ran []int
+	for i := 0; i < 3; i++ {
+		i := i
+		q.Enqueue(KindCheckpoint, "sarah", func() error {
+			time.Sleep(time.Millisecond)
+			ran = append(ran, i)
+			return nil
+		})
+	}
+	q.Close()
+	if len(ran) != 3 {
+		t.Errorf("Expected queued jobs to finish before Close returns, ran %v", ran)
+	}
+
+	late := q.Enqueue(KindKill, "sarah", func() error { return nil })
+	if late.Status != StatusFailed {
+		t.Errorf("Expected job enqueued after Close to fail, got %+v", late)
+	}
+}
+
+func TestQueueChanged(t *testing.T) {
+	q := NewQueue(1)
+	defer q.Close()
+
+	job := q.Enqueue(KindSpawn, "sarah", func() error { return nil })
+	select {
+	case <-q.Changed():
+	case <-time.After(time.Second):
+		t.Fatal("Expected a change notification")
+	}
+	waitFor(t, q, job.ID)
+}
+
+func TestQueuePrunesFinishedJobs(t *testing.T) {
+	q := NewQueue(1)
+	defer q.Close()
+
+	var last Job
+	for i := 0; i < maxFinished+5; i++ {
+		last = q.Enqueue(KindSpawn, "sarah", func() error { return nil })
+	}
+	waitFor(t, q, last.ID)
+
+	jobs := q.Jobs()
+	if len(jobs) != maxFinished {
+		t.Errorf("Expected %d jobs kept, got %d", maxFinished, len(jobs))
+	}
+	if jobs[0].ID != last.ID {
+		t.Errorf("Expected newest job kept, got %+v", jobs[0])
+	}
+}
+
+func TestJobDuration(t *testing.T) {
+	start := time.Date(2025, 1, 1, 12, 0, 0, 0, time.UTC)
+	now := start.Add(time.Minute)
+
+	pending := Job{Status: StatusPending, EnqueuedAt: start}
+	running := Job{Status: StatusRunning, StartedAt: start.Add(10 * time.Second)}
+	done := Job{Status: StatusCompleted, StartedAt: start, FinishedAt: start.Add(3 * time.Second)}
+
+	if got := pending.Duration(now); got != time.Minute {
+		t.Errorf("pending Duration() = %v", got)
+	}
+	if got := running.Duration(now); got != 50*time.Second {
+		t.Errorf("running Duration() = %v", got)
+	}
+	if got := done.Duration(now); got != 3*time.Second {
+		t.Errorf("completed Duration() = %v", got)
+	}
+}
@@ -0,0 +1,27 @@
+package keyring
+
+import (
+	"errors"
+
+	"github.com/charmbracelet/log"
+	"github.com/nehpz/claudicus/pkg/config"
+)
+
+// AgentEnv returns the NAME=value environment entry exporting the stored API
+// key of an agent type, or nil when no key is stored or the agent type has
+// no variable. Agents without a stored key keep relying on their own login,
+// so a keyring that can't be read is not an error.
+func AgentEnv(k Keyring, cfg *config.Config, agent string) []string {
+	name := cfg.APIKeyEnv(agent)
+	if name == "" {
+		return nil
+	}
+	key, err := k.Get(agent)
+	if err != nil {
+		if !errors.Is(err, ErrNotFound) {
+			log.Debug("Could not read the API key from the keyring", "agent", agent, "error", err)
+		}
+		return nil
+	}
+	return []string{name + "=" + key}
+}
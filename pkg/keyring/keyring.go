@@ -0,0 +1,147 @@
+// Package keyring stores the API keys of agent CLIs in the OS keyring, so
+// they don't need to live in shell profiles or uzi.yaml on shared machines.
+// It drives the keyring tools the OS ships rather than linking a keyring
+// library: security(1) for the macOS login keychain and secret-tool(1) for
+// the Secret Service (GNOME Keyring, KWallet) on Linux.
+package keyring
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"os/exec"
+	"runtime"
+	"strings"
+)
+
+// Service is the keyring service the keys are stored under; the account is
+// the agent type, e.g. claude
+const Service = "uzi"
+
+// ErrNotFound is returned by Get when no key is stored for an agent type
+var ErrNotFound = errors.New("no API key stored")
+
+// ErrUnsupported is returned on platforms without a supported keyring
+var ErrUnsupported = errors.New("no supported keyring on " + runtime.GOOS + " (uzi uses security on macOS and secret-tool on Linux)")
+
+// Keyring stores one secret per agent type
+type Keyring interface {
+	Get(agent string) (string, error)
+	Set(agent, key string) error
+	Delete(agent string) error
+}
+
+// Runner runs a keyring tool with stdin and returns its stdout
+type Runner func(stdin string, name string, args ...string) (string, error)
+
+// runCommand is the Runner that executes the tool
+func runCommand(stdin string, name string, args ...string) (string, error) {
+	cmd := exec.Command(name, args...)
+	cmd.Stdin = strings.NewReader(stdin)
+	var stderr bytes.Buffer
+	cmd.Stderr = &stderr
+	out, err := cmd.Output()
+	if err != nil {
+		if msg := strings.TrimSpace(stderr.String()); msg != "" {
+			return string(out), fmt.Errorf("%s: %w: %s", name, err, msg)
+		}
+		return string(out), fmt.Errorf("%s: %w", name, err)
+	}
+	return string(out), nil
+}
+
+// System returns the keyring of the current OS
+func System() Keyring {
+	return ForOS(runtime.GOOS, runCommand)
+}
+
+// ForOS returns the keyring of goos driven through run
+func ForOS(goos string, run Runner) Keyring {
+	switch goos {
+	case "darwin":
+		return macKeychain{run: run}
+	case "linux":
+		return secretService{run: run}
+	}
+	return unsupported{}
+}
+
+// macKeychain stores keys as generic passwords in the login keychain. Keys
+// are written through `security -i` so they never appear in a process list.
+type macKeychain struct {
+	run Runner
+}
+
+func (k macKeychain) Get(agent string) (string, error) {
+	out, err := k.run("", "security", "find-generic-password", "-s", Service, "-a", agent, "-w")
+	if err != nil {
+		// security exits 44 when the item doesn't exist
+		var exitErr *exec.ExitError
+		if errors.As(err, &exitErr) && exitErr.ExitCode() == 44 {
+			return "", ErrNotFound
+		}
+		return "", err
+	}
+	return strings.TrimRight(out, "\n"), nil
+}
+
+func (k macKeychain) Set(agent, key string) error {
+	command := fmt.Sprintf("add-generic-password -U -s %s -a %s -w %s\n", securityQuote(Service), securityQuote(agent), securityQuote(key))
+	_, err := k.run(command, "security", "-i")
+	return err
+}
+
+func (k macKeychain) Delete(agent string) error {
+	if _, err := k.Get(agent); err != nil {
+		return err
+	}
+	_, err := k.run("", "security", "delete-generic-password", "-s", Service, "-a", agent)
+	return err
+}
+
+// securityQuote quotes a word for the interactive mode of security(1)
+func securityQuote(s string) string {
+	return `"` + strings.NewReplacer(`\`, `\\`, `"`, `\"`).Replace(s) + `"`
+}
+
+// secretService stores keys with the service and account attributes through
+// the Secret Service; secret-tool reads the key from stdin
+type secretService struct {
+	run Runner
+}
+
+func (k secretService) Get(agent string) (string, error) {
+	out, err := k.run("", "secret-tool", "lookup", "service", Service, "account", agent)
+	if err != nil {
+		// secret-tool exits 1 without output when nothing matches
+		var exitErr *exec.ExitError
+		if errors.As(err, &exitErr) && out == "" {
+			return "", ErrNotFound
+		}
+		return "", err
+	}
+	if out == "" {
+		return "", ErrNotFound
+	}
+	return strings.TrimRight(out, "\n"), nil
+}
+
+func (k secretService) Set(agent, key string) error {
+	_, err := k.run(key, "secret-tool", "store", "--label", "uzi API key for "+agent, "service", Service, "account", agent)
+	return err
+}
+
+func (k secretService) Delete(agent string) error {
+	if _, err := k.Get(agent); err != nil {
+		return err
+	}
+	_, err := k.run("", "secret-tool", "clear", "service", Service, "account", agent)
+	return err
+}
+
+// unsupported is the keyring of platforms uzi can't store keys on
+type unsupported struct{}
+
+func (unsupported) Get(string) (string, error) { return "", ErrUnsupported }
+func (unsupported) Set(string, string) error   { return ErrUnsupported }
+func (unsupported) Delete(string) error        { return ErrUnsupported }
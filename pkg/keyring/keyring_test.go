@@ -0,0 +1,101 @@
+package keyring
+
+import (
+	"errors"
+	"os/exec"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/nehpz/claudicus/pkg/config"
+)
+
+// fakeTool records the keyring tool invocations
+type fakeTool struct {
+	calls []string
+	err   error
+}
+
+func (f *fakeTool) run(stdin string, name string, args ...string) (string, error) {
+	f.calls = append(f.calls, strings.Join(append([]string{name}, args...), " ")+" <"+stdin)
+	return "", f.err
+}
+
+func TestSecretService(t *testing.T) {
+	tool := &fakeTool{}
+	k := ForOS("linux", tool.run)
+	if err := k.Set("claude", "sk-ant-123"); err != nil {
+		t.Fatal(err)
+	}
+	want := "secret-tool store --label uzi API key for claude service uzi account claude <sk-ant-123"
+	if len(tool.calls) != 1 || tool.calls[0] != want {
+		t.Errorf("Set ran %q, want %q with the key on stdin", tool.calls, want)
+	}
+
+	// secret-tool prints nothing for a missing item
+	if _, err := k.Get("claude"); !errors.Is(err, ErrNotFound) {
+		t.Errorf("Expected ErrNotFound, got %v", err)
+	}
+	lookup := ForOS("linux", func(stdin string, name string, args ...string) (string, error) {
+		return "sk-ant-123\n", nil
+	})
+	if key, err := lookup.Get("claude"); err != nil || key != "sk-ant-123" {
+		t.Errorf("Get() = %q, %v", key, err)
+	}
+}
+
+func TestMacKeychain(t *testing.T) {
+	tool := &fakeTool{}
+	k := ForOS("darwin", tool.run)
+	if err := k.Set("codex", `sk-"quoted"`); err != nil {
+		t.Fatal(err)
+	}
+	want := `security -i <add-generic-password -U -s "uzi" -a "codex" -w "sk-\"quoted\""` + "\n"
+	if len(tool.calls) != 1 || tool.calls[0] != want {
+		t.Errorf("Set ran %q, want %q", tool.calls, want)
+	}
+	if strings.Contains(strings.SplitN(tool.calls[0], "<", 2)[0], "sk-") {
+		t.Error("Expected the key to stay out of the command line")
+	}
+}
+
+func TestUnsupported(t *testing.T) {
+	if err := ForOS("plan9", nil).Set("claude", "key"); !errors.Is(err, ErrUnsupported) {
+		t.Errorf("Expected ErrUnsupported, got %v", err)
+	}
+}
+
+// mapKeyring is an in-memory Keyring
+type mapKeyring map[string]string
+
+func (m mapKeyring) Get(agent string) (string, error) {
+	if key, ok := m[agent]; ok {
+		return key, nil
+	}
+	return "", ErrNotFound
+}
+func (m mapKeyring) Set(agent, key string) error { m[agent] = key; return nil }
+func (m mapKeyring) Delete(agent string) error   { delete(m, agent); return nil }
+
+func TestAgentEnv(t *testing.T) {
+	keys := mapKeyring{"claude": "sk-ant-123", "aider": "sk-456"}
+	if got, want := AgentEnv(keys, nil, "claude"), []string{"ANTHROPIC_API_KEY=sk-ant-123"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("AgentEnv(claude) = %v, want %v", got, want)
+	}
+	if got := AgentEnv(keys, nil, "codex"); got != nil {
+		t.Errorf("Expected no entry without a stored key, got %v", got)
+	}
+	if got := AgentEnv(keys, nil, "aider"); got != nil {
+		t.Errorf("Expected no entry for an agent type without a variable, got %v", got)
+	}
+	cfg := &config.Config{Auth: &config.AuthConfig{Env: map[string]string{"aider": "OPENAI_API_KEY"}}}
+	if got, want := AgentEnv(keys, cfg, "aider"), []string{"OPENAI_API_KEY=sk-456"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("AgentEnv(aider) = %v, want %v", got, want)
+	}
+	broken := ForOS("linux", func(string, string, ...string) (string, error) {
+		return "", &exec.Error{Name: "secret-tool", Err: exec.ErrNotFound}
+	})
+	if got := AgentEnv(broken, nil, "claude"); got != nil {
+		t.Errorf("Expected no entry when the keyring can't be read, got %v", got)
+	}
+}
@@ -0,0 +1,82 @@
+package pipeline
+
+import (
+	"fmt"
+	"os"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// Definition is a pipeline file: stages run in order, and each stage's agents are
+// only spawned after every agent of the previous stage has been checkpointed.
+//
+//	name: feature
+//	stages:
+//	  - name: plan
+//	    agents: claude:1
+//	    prompt: Write PLAN.md for the feature
+//	  - name: implement
+//	    agents: claude:2
+//	    prompt: Implement PLAN.md
+type Definition struct {
+	Name   string  `yaml:"name"`
+	Stages []Stage `yaml:"stages"`
+}
+
+// Stage is one step of a pipeline
+type Stage struct {
+	Name   string `yaml:"name"`
+	Agents string `yaml:"agents"` // same format as `uzi prompt --agents`, e.g. "claude:1,codex:2"
+	Prompt string `yaml:"prompt"`
+}
+
+// Load reads and validates a pipeline definition
+func Load(path string) (*Definition, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	var def Definition
+	if err := yaml.Unmarshal(data, &def); err != nil {
+		return nil, fmt.Errorf("error parsing pipeline %s: %w", path, err)
+	}
+	if err := def.Validate(); err != nil {
+		return nil, fmt.Errorf("invalid pipeline %s: %w", path, err)
+	}
+	return &def, nil
+}
+
+// Validate checks that the definition can be run
+func (d *Definition) Validate() error {
+	if strings.TrimSpace(d.Name) == "" {
+		return fmt.Errorf("name is required")
+	}
+	if len(d.Stages) == 0 {
+		return fmt.Errorf("at least one stage is required")
+	}
+
+	seen := make(map[string]bool)
+	for i, stage := range d.Stages {
+		if strings.TrimSpace(stage.Name) == "" {
+			return fmt.Errorf("stage %d: name is required", i+1)
+		}
+		if seen[stage.Name] {
+			return fmt.Errorf("stage %q is defined more than once", stage.Name)
+		}
+		seen[stage.Name] = true
+		if strings.TrimSpace(stage.Prompt) == "" {
+			return fmt.Errorf("stage %q: prompt is required", stage.Name)
+		}
+	}
+	return nil
+}
+
+// AgentsOrDefault returns the stage's agents spec, defaulting to a single claude agent
+func (s Stage) AgentsOrDefault() string {
+	if strings.TrimSpace(s.Agents) == "" {
+		return "claude:1"
+	}
+	return s.Agents
+}
@@ -0,0 +1,72 @@
+package pipeline
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func writePipeline(t *testing.T, content string) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), "pipeline.yaml")
+	if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+		t.Fatalf("Failed to write pipeline file: %v", err)
+	}
+	return path
+}
+
+func TestLoad(t *testing.T) {
+	path := writePipeline(t, `name: feature
+stages:
+  - name: plan
+    agents: claude:1
+    prompt: Write PLAN.md
+  - name: implement
+    prompt: Implement PLAN.md
+`)
+
+	def, err := Load(path)
+	if err != nil {
+		t.Fatalf("Load() error = %v", err)
+	}
+	if def.Name != "feature" || len(def.Stages) != 2 {
+		t.Fatalf("Unexpected definition: %+v", def)
+	}
+	if def.Stages[0].AgentsOrDefault() != "claude:1" {
+		t.Errorf("Expected explicit agents, got %q", def.Stages[0].AgentsOrDefault())
+	}
+	if def.Stages[1].AgentsOrDefault() != "claude:1" {
+		t.Errorf("Expected default agents, got %q", def.Stages[1].AgentsOrDefault())
+	}
+}
+
+func TestLoad_Errors(t *testing.T) {
+	tests := []struct {
+		name          string
+		content       string
+		errorContains string
+	}{
+		{"invalid yaml", "name: [", "error parsing pipeline"},
+		{"missing name", "stages:\n  - name: a\n    prompt: x\n", "name is required"},
+		{"no stages", "name: p\n", "at least one stage"},
+		{"stage without name", "name: p\nstages:\n  - prompt: x\n", "stage 1: name is required"},
+		{"duplicate stage", "name: p\nstages:\n  - name: a\n    prompt: x\n  - name: a\n    prompt: y\n", "more than once"},
+		{"stage without prompt", "name: p\nstages:\n  - name: a\n", "prompt is required"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := Load(writePipeline(t, tt.content))
+			if err == nil || !strings.Contains(err.Error(), tt.errorContains) {
+				t.Errorf("Load() error = %v, want error containing %q", err, tt.errorContains)
+			}
+		})
+	}
+}
+
+func TestLoad_MissingFile(t *testing.T) {
+	if _, err := Load(filepath.Join(t.TempDir(), "missing.yaml")); !os.IsNotExist(err) {
+		t.Errorf("Expected not-exist error, got %v", err)
+	}
+}
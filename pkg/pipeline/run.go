@@ -0,0 +1,227 @@
+package pipeline
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"time"
+)
+
+// Status is the lifecycle state of a pipeline run or stage
+type Status string
+
+const (
+	StatusPending   Status = "pending"
+	StatusRunning   Status = "running"
+	StatusCompleted Status = "completed"
+	StatusFailed    Status = "failed"
+)
+
+// Run tracks one execution of a pipeline definition
+type Run struct {
+	ID        string     `json:"id"`
+	Name      string     `json:"name"`
+	File      string     `json:"file"`
+	Status    Status     `json:"status"`
+	Error     string     `json:"error,omitempty"`
+	Stages    []StageRun `json:"stages"`
+	CreatedAt time.Time  `json:"created_at"`
+	UpdatedAt time.Time  `json:"updated_at"`
+}
+
+// StageRun tracks the agents spawned for one stage and which of them have checkpointed
+type StageRun struct {
+	Name         string   `json:"name"`
+	Status       Status   `json:"status"`
+	Agents       []string `json:"agents,omitempty"`
+	Checkpointed []string `json:"checkpointed,omitempty"`
+}
+
+// NewRun creates a running pipeline run with every stage pending
+func NewRun(def *Definition, file string, now time.Time) *Run {
+	run := &Run{
+		ID:        fmt.Sprintf("%s-%d", def.Name, now.Unix()),
+		Name:      def.Name,
+		File:      file,
+		Status:    StatusRunning,
+		CreatedAt: now,
+		UpdatedAt: now,
+	}
+	for _, stage := range def.Stages {
+		run.Stages = append(run.Stages, StageRun{Name: stage.Name, Status: StatusPending})
+	}
+	return run
+}
+
+// CurrentStage returns the index of the first stage that has not completed, or -1
+func (r *Run) CurrentStage() int {
+	for i, stage := range r.Stages {
+		if stage.Status != StatusCompleted {
+			return i
+		}
+	}
+	return -1
+}
+
+// Done reports whether every agent spawned for the stage has checkpointed
+func (s *StageRun) Done() bool {
+	if len(s.Agents) == 0 {
+		return false
+	}
+	for _, agent := range s.Agents {
+		if !s.IsCheckpointed(agent) {
+			return false
+		}
+	}
+	return true
+}
+
+// IsCheckpointed reports whether the agent has checkpointed in this stage
+func (s *StageRun) IsCheckpointed(agent string) bool {
+	for _, name := range s.Checkpointed {
+		if name == agent {
+			return true
+		}
+	}
+	return false
+}
+
+func (s *StageRun) hasAgent(agent string) bool {
+	for _, name := range s.Agents {
+		if name == agent {
+			return true
+		}
+	}
+	return false
+}
+
+// Store persists pipeline runs as JSON next to the agent state file
+type Store struct {
+	path string
+}
+
+// NewStore returns a store at ~/.local/share/uzi/pipelines.json
+func NewStore() (*Store, error) {
+	homeDir, err := os.UserHomeDir()
+	if err != nil {
+		return nil, err
+	}
+	return NewStoreAt(filepath.Join(homeDir, ".local", "share", "uzi", "pipelines.json")), nil
+}
+
+// NewStoreAt returns a store backed by the given file
+func NewStoreAt(path string) *Store {
+	return &Store{path: path}
+}
+
+// Path returns the file backing the store
+func (s *Store) Path() string {
+	return s.path
+}
+
+func (s *Store) load() (map[string]*Run, error) {
+	runs := make(map[string]*Run)
+	data, err := os.ReadFile(s.path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return runs, nil
+		}
+		return nil, err
+	}
+	if err := json.Unmarshal(data, &runs); err != nil {
+		return nil, fmt.Errorf("error parsing pipeline state: %w", err)
+	}
+	return runs, nil
+}
+
+func (s *Store) save(runs map[string]*Run) error {
+	if err := os.MkdirAll(filepath.Dir(s.path), 0755); err != nil {
+		return err
+	}
+	data, err := json.MarshalIndent(runs, "", "  ")
+	if err != nil {
+		return err
+	}
+	// Write then rename so a concurrent `uzi checkpoint` never reads a partial file
+	tmp := s.path + ".tmp"
+	if err := os.WriteFile(tmp, data, 0644); err != nil {
+		return err
+	}
+	return os.Rename(tmp, s.path)
+}
+
+// Save inserts or replaces a run
+func (s *Store) Save(run *Run) error {
+	runs, err := s.load()
+	if err != nil {
+		return err
+	}
+	run.UpdatedAt = time.Now()
+	runs[run.ID] = run
+	return s.save(runs)
+}
+
+// Get returns the run with the given ID
+func (s *Store) Get(id string) (*Run, error) {
+	runs, err := s.load()
+	if err != nil {
+		return nil, err
+	}
+	run, ok := runs[id]
+	if !ok {
+		return nil, fmt.Errorf("no pipeline run found: %s", id)
+	}
+	return run, nil
+}
+
+// List returns all runs, newest first
+func (s *Store) List() ([]Run, error) {
+	runs, err := s.load()
+	if err != nil {
+		return nil, err
+	}
+	list := make([]Run, 0, len(runs))
+	for _, run := range runs {
+		list = append(list, *run)
+	}
+	sort.Slice(list, func(i, j int) bool {
+		if !list[i].CreatedAt.Equal(list[j].CreatedAt) {
+			return list[i].CreatedAt.After(list[j].CreatedAt)
+		}
+		return list[i].ID < list[j].ID
+	})
+	return list, nil
+}
+
+// MarkCheckpointed records a successful checkpoint of agent in every running
+// stage that spawned it. It reports whether any stage was updated.
+func (s *Store) MarkCheckpointed(agent string) (bool, error) {
+	runs, err := s.load()
+	if err != nil {
+		return false, err
+	}
+
+	updated := false
+	now := time.Now()
+	for _, run := range runs {
+		if run.Status != StatusRunning {
+			continue
+		}
+		for i := range run.Stages {
+			stage := &run.Stages[i]
+			if stage.Status != StatusRunning || !stage.hasAgent(agent) || stage.IsCheckpointed(agent) {
+				continue
+			}
+			stage.Checkpointed = append(stage.Checkpointed, agent)
+			run.UpdatedAt = now
+			updated = true
+		}
+	}
+
+	if !updated {
+		return false, nil
+	}
+	return true, s.save(runs)
+}
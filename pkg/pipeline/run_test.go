@@ -0,0 +1,138 @@
+package pipeline
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func testDefinition() *Definition {
+	return &Definition{
+		Name: "feature",
+		Stages: []Stage{
+			{Name: "plan", Prompt: "plan it"},
+			{Name: "implement", Prompt: "build it"},
+		},
+	}
+}
+
+func TestNewRun(t *testing.T) {
+	now := time.Unix(1700000000, 0)
+	run := NewRun(testDefinition(), "pipeline.yaml", now)
+
+	if run.ID != "feature-1700000000" {
+		t.Errorf("ID = %q", run.ID)
+	}
+	if run.Status != StatusRunning {
+		t.Errorf("Status = %q, want running", run.Status)
+	}
+	if len(run.Stages) != 2 || run.Stages[0].Status != StatusPending {
+		t.Errorf("Unexpected stages: %+v", run.Stages)
+	}
+	if run.CurrentStage() != 0 {
+		t.Errorf("CurrentStage() = %d, want 0", run.CurrentStage())
+	}
+
+	run.Stages[0].Status = StatusCompleted
+	run.Stages[1].Status = StatusCompleted
+	if run.CurrentStage() != -1 {
+		t.Errorf("CurrentStage() = %d, want -1 when all stages completed", run.CurrentStage())
+	}
+}
+
+func TestStageRun_Done(t *testing.T) {
+	stage := StageRun{Name: "plan", Status: StatusRunning}
+	if stage.Done() {
+		t.Error("Stage without agents should not be done")
+	}
+
+	stage.Agents = []string{"sarah", "john"}
+	stage.Checkpointed = []string{"sarah"}
+	if stage.Done() {
+		t.Error("Stage should wait for all agents")
+	}
+
+	stage.Checkpointed = append(stage.Checkpointed, "john")
+	if !stage.Done() {
+		t.Error("Stage should be done once every agent checkpointed")
+	}
+}
+
+func TestStore_SaveGetList(t *testing.T) {
+	store := NewStoreAt(filepath.Join(t.TempDir(), "nested", "pipelines.json"))
+
+	runs, err := store.List()
+	if err != nil || len(runs) != 0 {
+		t.Fatalf("List() on missing file = %v, %v", runs, err)
+	}
+
+	older := NewRun(testDefinition(), "a.yaml", time.Unix(100, 0))
+	newer := NewRun(&Definition{Name: "other", Stages: testDefinition().Stages}, "b.yaml", time.Unix(200, 0))
+	for _, run := range []*Run{older, newer} {
+		if err := store.Save(run); err != nil {
+			t.Fatalf("Save() error = %v", err)
+		}
+	}
+
+	got, err := store.Get(older.ID)
+	if err != nil || got.File != "a.yaml" {
+		t.Errorf("Get() = %+v, %v", got, err)
+	}
+	if _, err := store.Get("missing"); err == nil {
+		t.Error("Expected error for unknown run")
+	}
+
+	runs, err = store.List()
+	if err != nil {
+		t.Fatalf("List() error = %v", err)
+	}
+	if len(runs) != 2 || runs[0].ID != newer.ID {
+		t.Errorf("Expected newest run first, got %+v", runs)
+	}
+}
+
+func TestStore_CorruptFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "pipelines.json")
+	os.WriteFile(path, []byte("{not json"), 0644)
+
+	if _, err := NewStoreAt(path).List(); err == nil {
+		t.Error("Expected parse error for corrupt pipeline state")
+	}
+}
+
+func TestStore_MarkCheckpointed(t *testing.T) {
+	store := NewStoreAt(filepath.Join(t.TempDir(), "pipelines.json"))
+
+	run := NewRun(testDefinition(), "pipeline.yaml", time.Now())
+	run.Stages[0].Status = StatusRunning
+	run.Stages[0].Agents = []string{"sarah", "john"}
+	store.Save(run)
+
+	finished := NewRun(&Definition{Name: "done", Stages: testDefinition().Stages}, "done.yaml", time.Now())
+	finished.Status = StatusFailed
+	finished.Stages[0].Status = StatusRunning
+	finished.Stages[0].Agents = []string{"sarah"}
+	store.Save(finished)
+
+	updated, err := store.MarkCheckpointed("sarah")
+	if err != nil || !updated {
+		t.Fatalf("MarkCheckpointed() = %v, %v", updated, err)
+	}
+
+	got, _ := store.Get(run.ID)
+	if !got.Stages[0].IsCheckpointed("sarah") || got.Stages[0].IsCheckpointed("john") {
+		t.Errorf("Unexpected checkpoints: %v", got.Stages[0].Checkpointed)
+	}
+	if other, _ := store.Get(finished.ID); len(other.Stages[0].Checkpointed) != 0 {
+		t.Error("Runs that are not running should be left alone")
+	}
+
+	// Repeating the checkpoint and unknown agents are no-ops
+	if updated, _ := store.MarkCheckpointed("sarah"); updated {
+		t.Error("Expected repeated checkpoint to be a no-op")
+	}
+	if updated, _ := store.MarkCheckpointed("emily"); updated {
+		t.Error("Expected unknown agent to be a no-op")
+	}
+}
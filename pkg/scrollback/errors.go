@@ -0,0 +1,115 @@
+package scrollback
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+)
+
+// maxErrorText bounds the text kept of an error, which can be a whole
+// command output
+const maxErrorText = 200
+
+// TranscriptError is an error recorded in a transcript: a tool call that
+// failed or an API error the claude CLI hit
+type TranscriptError struct {
+	File string    `json:"file"`
+	Line int       `json:"line"` // entry in the transcript, from 1
+	At   time.Time `json:"at"`
+	Text string    `json:"text"` // the first line of the error
+}
+
+// TranscriptErrors returns the errors recorded in the transcripts in dir at
+// or after since, oldest first. A missing directory has no errors.
+func TranscriptErrors(dir string, since time.Time) ([]TranscriptError, error) {
+	paths, err := filepath.Glob(filepath.Join(dir, "*.jsonl"))
+	if err != nil {
+		return nil, err
+	}
+	var errs []TranscriptError
+	for _, path := range paths {
+		fileErrs, err := transcriptErrors(path, since)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read transcript %s: %w", path, err)
+		}
+		errs = append(errs, fileErrs...)
+	}
+	sort.SliceStable(errs, func(i, j int) bool { return errs[i].At.Before(errs[j].At) })
+	return errs, nil
+}
+
+// transcriptErrors finds the errors in the timestamped entries of a JSONL
+// transcript: tool results flagged is_error, and entries flagged
+// isApiErrorMessage
+func transcriptErrors(path string, since time.Time) ([]TranscriptError, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	var errs []TranscriptError
+	scanner := bufio.NewScanner(file)
+	scanner.Buffer(make([]byte, 64*1024), maxTranscriptLine)
+	for entry := 1; scanner.Scan(); entry++ {
+		var value map[string]any
+		if err := json.Unmarshal(scanner.Bytes(), &value); err != nil {
+			continue
+		}
+		timestamp, _ := value["timestamp"].(string)
+		at, err := time.Parse(time.RFC3339, timestamp)
+		if err != nil || at.Before(since) {
+			continue
+		}
+		var texts []string
+		if apiError, _ := value["isApiErrorMessage"].(bool); apiError {
+			texts = append(texts, firstLine(messageText(value["message"])))
+		} else {
+			texts = failedToolResults(value["message"])
+		}
+		for _, text := range texts {
+			errs = append(errs, TranscriptError{File: filepath.Base(path), Line: entry, At: at, Text: text})
+		}
+	}
+	return errs, scanner.Err()
+}
+
+// failedToolResults returns the first line of every content block flagged
+// is_error in a transcript message
+func failedToolResults(value any) []string {
+	var texts []string
+	switch v := value.(type) {
+	case map[string]any:
+		if isError, _ := v["is_error"].(bool); isError {
+			return []string{firstLine(messageText(v))}
+		}
+		for _, item := range v {
+			texts = append(texts, failedToolResults(item)...)
+		}
+	case []any:
+		for _, item := range v {
+			texts = append(texts, failedToolResults(item)...)
+		}
+	}
+	return texts
+}
+
+// firstLine returns the first non-blank line of texts, cut to maxErrorText
+func firstLine(texts []string) string {
+	for _, text := range texts {
+		for _, line := range strings.Split(text, "\n") {
+			if line = strings.TrimSpace(line); line != "" {
+				if len(line) > maxErrorText {
+					line = line[:maxErrorText] + "…"
+				}
+				return line
+			}
+		}
+	}
+	return "(no message)"
+}
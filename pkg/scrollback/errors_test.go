@@ -0,0 +1,40 @@
+package scrollback
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestTranscriptErrors(t *testing.T) {
+	dir := t.TempDir()
+	transcript := strings.Join([]string{
+		`{"type":"user","timestamp":"2025-01-01T01:00:00Z","message":{"content":[{"type":"tool_result","is_error":true,"content":"too early"}]}}`,
+		`{"type":"user","timestamp":"2025-01-01T10:00:00Z","message":{"content":[{"type":"tool_result","content":"ok"}]}}`,
+		`{"type":"user","timestamp":"2025-01-01T10:05:00Z","message":{"content":[{"type":"tool_result","is_error":true,"content":"\nExit code 1\nFAIL ./pkg/parser"}]}}`,
+		`{"type":"assistant","timestamp":"2025-01-01T10:10:00Z","isApiErrorMessage":true,"message":{"content":[{"type":"text","text":"API Error: 529 Overloaded"}]}}`,
+		`{"type":"assistant","message":`,
+	}, "\n")
+	if err := os.WriteFile(filepath.Join(dir, "a1.jsonl"), []byte(transcript), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	errs, err := TranscriptErrors(dir, time.Date(2025, 1, 1, 9, 0, 0, 0, time.UTC))
+	if err != nil {
+		t.Fatalf("TranscriptErrors() error = %v", err)
+	}
+	want := []TranscriptError{
+		{File: "a1.jsonl", Line: 3, At: time.Date(2025, 1, 1, 10, 5, 0, 0, time.UTC), Text: "Exit code 1"},
+		{File: "a1.jsonl", Line: 4, At: time.Date(2025, 1, 1, 10, 10, 0, 0, time.UTC), Text: "API Error: 529 Overloaded"},
+	}
+	if !reflect.DeepEqual(errs, want) {
+		t.Errorf("TranscriptErrors() = %+v, want %+v", errs, want)
+	}
+
+	if errs, err := TranscriptErrors(filepath.Join(dir, "missing"), time.Time{}); err != nil || len(errs) != 0 {
+		t.Errorf("Expected no errors for a missing directory, got %v, %v", errs, err)
+	}
+}
@@ -0,0 +1,177 @@
+// Package scrollback searches what agents have printed: the history of their
+// tmux panes, which tmux keeps up to its history-limit, and the transcripts
+// the claude CLI writes for each worktree under ~/.claude/projects. It backs
+// `uzi grep` and the TUI's output search, for finding which agent mentioned
+// a particular error.
+package scrollback
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/nehpz/claudicus/pkg/state"
+	"github.com/nehpz/claudicus/pkg/tmuxops"
+)
+
+// Where a match was found
+const (
+	SourcePane       = "pane"
+	SourceTranscript = "transcript"
+)
+
+// maxTranscriptLine bounds a single transcript entry; entries with large tool
+// results can run to megabytes
+const maxTranscriptLine = 16 << 20
+
+// Match is one line of an agent's output that matched the pattern
+type Match struct {
+	Session string `json:"session"`
+	Agent   string `json:"agent"`
+	Source  string `json:"source"`         // pane or transcript
+	File    string `json:"file,omitempty"` // transcript file name; empty for the pane
+	Line    int    `json:"line"`           // line in the pane history, or entry in the transcript, from 1
+	Text    string `json:"text"`
+}
+
+// Location describes where a match was found, e.g. "pane:12" or
+// "transcript 0b1c.jsonl:40"
+func (m Match) Location() string {
+	if m.File != "" {
+		return fmt.Sprintf("%s %s:%d", m.Source, m.File, m.Line)
+	}
+	return fmt.Sprintf("%s:%d", m.Source, m.Line)
+}
+
+// Session is an agent session to search
+type Session struct {
+	Name          string
+	Executor      state.CommandExecutor // reaches the tmux server the session runs on
+	TranscriptDir string                // transcripts of the session's worktree; empty to skip them
+}
+
+// Search returns the lines of a session's pane history, at most lines back or
+// all of it when lines is 0, and of its transcripts that match re. Pane
+// matches come first, then transcripts in file name order.
+func Search(session Session, re *regexp.Regexp, lines int) ([]Match, error) {
+	output, err := session.Executor.ExecuteCommand("tmux", tmuxops.ScrollbackArgs(session.Name, lines)...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to capture the pane of %s: %w", session.Name, err)
+	}
+	var matches []Match
+	for i, line := range strings.Split(strings.TrimRight(string(output), "\n"), "\n") {
+		if line = strings.TrimRight(line, " "); re.MatchString(line) {
+			matches = append(matches, Match{Source: SourcePane, Line: i + 1, Text: line})
+		}
+	}
+
+	if session.TranscriptDir != "" {
+		transcripts, err := searchTranscripts(session.TranscriptDir, re)
+		if err != nil {
+			return nil, err
+		}
+		matches = append(matches, transcripts...)
+	}
+
+	agentName := state.AgentNameFromSession(session.Name)
+	for i := range matches {
+		matches[i].Session = session.Name
+		matches[i].Agent = agentName
+	}
+	return matches, nil
+}
+
+// TranscriptDir returns where the claude CLI keeps the transcripts of
+// sessions started in dir: a directory under ~/.claude/projects named after
+// dir with every character other than a letter or digit replaced by a dash
+func TranscriptDir(home, dir string) string {
+	name := strings.Map(func(r rune) rune {
+		if r >= 'a' && r <= 'z' || r >= 'A' && r <= 'Z' || r >= '0' && r <= '9' {
+			return r
+		}
+		return '-'
+	}, dir)
+	return filepath.Join(home, ".claude", "projects", name)
+}
+
+// searchTranscripts matches re against the message text of every transcript
+// in dir. A missing directory has no matches.
+func searchTranscripts(dir string, re *regexp.Regexp) ([]Match, error) {
+	paths, err := filepath.Glob(filepath.Join(dir, "*.jsonl"))
+	if err != nil {
+		return nil, err
+	}
+	sort.Strings(paths)
+
+	var matches []Match
+	for _, path := range paths {
+		fileMatches, err := searchTranscript(path, re)
+		if err != nil {
+			return nil, fmt.Errorf("failed to search transcript %s: %w", path, err)
+		}
+		matches = append(matches, fileMatches...)
+	}
+	return matches, nil
+}
+
+// searchTranscript matches re against each line of the message text of every
+// entry of a JSONL transcript
+func searchTranscript(path string, re *regexp.Regexp) ([]Match, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	var matches []Match
+	scanner := bufio.NewScanner(file)
+	scanner.Buffer(make([]byte, 64*1024), maxTranscriptLine)
+	for entry := 1; scanner.Scan(); entry++ {
+		var value any
+		if err := json.Unmarshal(scanner.Bytes(), &value); err != nil {
+			continue // A partly written last entry
+		}
+		for _, text := range messageText(value) {
+			for _, line := range strings.Split(text, "\n") {
+				if line = strings.TrimSpace(line); re.MatchString(line) {
+					matches = append(matches, Match{Source: SourceTranscript, File: filepath.Base(path), Line: entry, Text: line})
+				}
+			}
+		}
+	}
+	return matches, scanner.Err()
+}
+
+// messageText collects the text of a transcript entry: the "text" fields of
+// its content blocks and "content" given as plain text, such as user prompts
+// and tool results. Metadata like ids, paths, and timestamps is left out.
+func messageText(value any) []string {
+	var texts []string
+	switch v := value.(type) {
+	case map[string]any:
+		keys := make([]string, 0, len(v))
+		for k := range v {
+			keys = append(keys, k)
+		}
+		sort.Strings(keys)
+		for _, k := range keys {
+			if s, ok := v[k].(string); ok {
+				if k == "text" || k == "content" {
+					texts = append(texts, s)
+				}
+				continue
+			}
+			texts = append(texts, messageText(v[k])...)
+		}
+	case []any:
+		for _, item := range v {
+			texts = append(texts, messageText(item)...)
+		}
+	}
+	return texts
+}
@@ -0,0 +1,91 @@
+package scrollback
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"reflect"
+	"regexp"
+	"strings"
+	"testing"
+
+	"github.com/nehpz/claudicus/pkg/tmuxops"
+)
+
+// paneExecutor prints a fixed pane history for capture-pane
+type paneExecutor struct {
+	pane string
+	err  error
+	args []string
+}
+
+func (e *paneExecutor) ExecuteCommand(name string, args ...string) ([]byte, error) {
+	e.args = append([]string{name}, args...)
+	return []byte(e.pane), e.err
+}
+
+func (e *paneExecutor) RunCommand(name string, args ...string) error {
+	return errors.New("unexpected command")
+}
+
+func TestSearch(t *testing.T) {
+	dir := t.TempDir()
+	transcript := strings.Join([]string{
+		`{"type":"user","message":{"role":"user","content":"fix the panic in main.go"},"cwd":"/worktrees/panic"}`,
+		`{"type":"assistant","message":{"role":"assistant","content":[{"type":"text","text":"Looking into it.\nThe panic: nil map comes from init"},{"type":"tool_use","id":"panic-1","input":{"command":"go test"}}]}}`,
+		`{"type":"user","message":{"content":[{"type":"tool_result","content":"ok"}]}}`,
+		`{"type":"assistant","message":`,
+	}, "\n")
+	if err := os.WriteFile(filepath.Join(dir, "a1.jsonl"), []byte(transcript), 0644); err != nil {
+		t.Fatal(err)
+	}
+	executor := &paneExecutor{pane: "$ go build\npanic: runtime error   \nok\n\n"}
+
+	matches, err := Search(Session{Name: "agent-app-abc123-sarah", Executor: executor, TranscriptDir: dir}, regexp.MustCompile("panic"), 500)
+	if err != nil {
+		t.Fatalf("Search() error = %v", err)
+	}
+	want := []Match{
+		{Session: "agent-app-abc123-sarah", Agent: "sarah", Source: SourcePane, Line: 2, Text: "panic: runtime error"},
+		{Session: "agent-app-abc123-sarah", Agent: "sarah", Source: SourceTranscript, File: "a1.jsonl", Line: 1, Text: "fix the panic in main.go"},
+		{Session: "agent-app-abc123-sarah", Agent: "sarah", Source: SourceTranscript, File: "a1.jsonl", Line: 2, Text: "The panic: nil map comes from init"},
+	}
+	if !reflect.DeepEqual(matches, want) {
+		t.Errorf("Search() = %+v, want %+v", matches, want)
+	}
+	if got := strings.Join(executor.args, " "); got != "tmux capture-pane -p -J -S -500 -t "+tmuxops.AgentTarget("agent-app-abc123-sarah") {
+		t.Errorf("Captured with %q", got)
+	}
+	if got := matches[2].Location(); got != "transcript a1.jsonl:2" {
+		t.Errorf("Location() = %q", got)
+	}
+
+	// Without transcripts, and over the whole history
+	matches, err = Search(Session{Name: "agent-app-abc123-sarah", Executor: executor}, regexp.MustCompile("panic"), 0)
+	if err != nil || len(matches) != 1 || matches[0].Location() != "pane:2" {
+		t.Errorf("Search() without transcripts = %+v, %v", matches, err)
+	}
+	if !strings.Contains(strings.Join(executor.args, " "), "-S - ") {
+		t.Errorf("Expected the whole history to be captured, got %q", executor.args)
+	}
+
+	executor.err = errors.New("no server running")
+	if _, err := Search(Session{Name: "agent-app-abc123-sarah", Executor: executor}, regexp.MustCompile("panic"), 0); err == nil {
+		t.Error("Expected a capture failure to be reported")
+	}
+}
+
+func TestTranscriptDir(t *testing.T) {
+	got := TranscriptDir("/home/dev", "/home/dev/.local/share/uzi/worktrees/app_sarah")
+	want := "/home/dev/.claude/projects/-home-dev--local-share-uzi-worktrees-app-sarah"
+	if got != want {
+		t.Errorf("TranscriptDir() = %q, want %q", got, want)
+	}
+}
+
+func TestSearchMissingTranscripts(t *testing.T) {
+	matches, err := searchTranscripts(filepath.Join(t.TempDir(), "missing"), regexp.MustCompile("x"))
+	if err != nil || len(matches) != 0 {
+		t.Errorf("searchTranscripts() = %v, %v; want no matches", matches, err)
+	}
+}
@@ -0,0 +1,38 @@
+package scrollback
+
+import (
+	"fmt"
+	"sort"
+
+	"github.com/nehpz/claudicus/pkg/hosts"
+	"github.com/nehpz/claudicus/pkg/state"
+)
+
+// SelectSessions returns the active session of the named agent, or every
+// active session in name order without a name
+func SelectSessions(activeSessions []string, agentName string) ([]string, error) {
+	if agentName == "" {
+		if len(activeSessions) == 0 {
+			return nil, fmt.Errorf("no active agent sessions found")
+		}
+		sessions := append([]string(nil), activeSessions...)
+		sort.Strings(sessions)
+		return sessions, nil
+	}
+	session, err := state.FindAgentSession(activeSessions, agentName)
+	if err != nil {
+		return nil, err
+	}
+	return []string{session}, nil
+}
+
+// NewSession describes how to read a session's output: its pane is captured
+// on the host it runs on, and the transcripts of local worktrees under home
+// are read unless home is empty
+func NewSession(sessionName string, agentState state.AgentState, home string) Session {
+	session := Session{Name: sessionName, Executor: hosts.ForState(agentState)}
+	if home != "" && !agentState.IsRemote() && agentState.WorktreePath != "" {
+		session.TranscriptDir = TranscriptDir(home, agentState.WorktreePath)
+	}
+	return session
+}
@@ -0,0 +1,39 @@
+package scrollback
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/nehpz/claudicus/pkg/state"
+)
+
+func TestSelectSessions(t *testing.T) {
+	active := []string{"agent-repo-abc123-sarah", "agent-repo-abc123-john"}
+	if got, err := SelectSessions(active, ""); err != nil || !reflect.DeepEqual(got, []string{"agent-repo-abc123-john", "agent-repo-abc123-sarah"}) {
+		t.Errorf("SelectSessions() = %v, %v", got, err)
+	}
+	if got, err := SelectSessions(active, "sarah"); err != nil || !reflect.DeepEqual(got, []string{"agent-repo-abc123-sarah"}) {
+		t.Errorf("SelectSessions(sarah) = %v, %v", got, err)
+	}
+	if _, err := SelectSessions(active, "emily"); err == nil || !strings.Contains(err.Error(), "no active session found for agent: emily") {
+		t.Errorf("SelectSessions(emily) error = %v", err)
+	}
+	if _, err := SelectSessions(nil, ""); err == nil {
+		t.Error("Expected an error without active sessions")
+	}
+}
+
+func TestNewSession(t *testing.T) {
+	local := NewSession("agent-repo-abc123-sarah", state.AgentState{WorktreePath: "/wt/sarah"}, "/home/dev")
+	if local.TranscriptDir != TranscriptDir("/home/dev", "/wt/sarah") {
+		t.Errorf("Expected the local worktree's transcripts, got %q", local.TranscriptDir)
+	}
+	remote := NewSession("agent-repo-abc123-john", state.AgentState{WorktreePath: "/wt/john", Host: "gpu", SSH: "dev@gpu"}, "/home/dev")
+	if remote.TranscriptDir != "" {
+		t.Errorf("Expected no transcripts for a remote session, got %q", remote.TranscriptDir)
+	}
+	if skipped := NewSession("agent-repo-abc123-sarah", state.AgentState{WorktreePath: "/wt/sarah"}, ""); skipped.TranscriptDir != "" {
+		t.Errorf("Expected no transcripts without a home directory, got %q", skipped.TranscriptDir)
+	}
+}
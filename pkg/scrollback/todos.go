@@ -0,0 +1,106 @@
+package scrollback
+
+import (
+	"regexp"
+	"strings"
+)
+
+// Kinds of outstanding items an agent mentions
+const (
+	KindTodo     = "TODO"
+	KindFixme    = "FIXME"
+	KindNextStep = "next step"
+)
+
+// Todo is an item an agent believes remains: a TODO or FIXME it printed, or
+// an entry of a "Next steps" list
+type Todo struct {
+	Session string `json:"session"`
+	Agent   string `json:"agent"`
+	Kind    string `json:"kind"` // TODO, FIXME, or next step
+	Source  string `json:"source"`
+	File    string `json:"file,omitempty"`
+	Line    int    `json:"line"`
+	Text    string `json:"text"`
+}
+
+// Location describes where the item was found, as Match.Location does
+func (t Todo) Location() string {
+	return Match{Source: t.Source, File: t.File, Line: t.Line}.Location()
+}
+
+var (
+	everyLine = regexp.MustCompile(``)
+	markerRe  = regexp.MustCompile(`\b(TODO|FIXME)\b\s*(?:\([^)]*\))?\s*[:\-]?\s*(.*)$`)
+	// A heading that opens a list of remaining work, e.g. "## Next steps",
+	// "**Remaining work:**" or "Follow-ups:"
+	headingRe = regexp.MustCompile(`(?i)^[\s#*_>]*(next steps?|remaining (?:work|items|tasks)|follow[- ]ups?|still to do|todos?)[\s*_]*:?[\s*_]*$`)
+	// A list entry, optionally a checkbox; (x) marks a checked one
+	listItemRe = regexp.MustCompile(`^\s*(?:(?:[-*•]|\d+[.)])\s+(?:\[( |x|X)\]\s+)?|\[( |x|X)\]\s+)(.+)$`)
+)
+
+// Todos returns the outstanding items in a session's pane history, at most
+// lines back or all of it when lines is 0, and in its transcripts. An item
+// the agent repeated, such as one shown both in the pane and the transcript,
+// is listed once, where it was first seen.
+func Todos(session Session, lines int) ([]Todo, error) {
+	output, err := Search(session, everyLine, lines)
+	if err != nil {
+		return nil, err
+	}
+	return ExtractTodos(output), nil
+}
+
+// ExtractTodos finds the TODO and FIXME markers and "Next steps" list entries
+// in consecutive lines of output. A list runs from its heading until the
+// first line that is neither an entry nor blank, or a blank line after an
+// entry.
+func ExtractTodos(output []Match) []Todo {
+	var todos []Todo
+	seen := make(map[string]bool)
+	add := func(kind string, m Match, text string) {
+		text = strings.TrimSpace(strings.TrimLeft(text, "*_` "))
+		key := kind + "\x00" + strings.ToLower(strings.Join(strings.Fields(text), " "))
+		if text == "" || seen[key] {
+			return
+		}
+		seen[key] = true
+		todos = append(todos, Todo{Session: m.Session, Agent: m.Agent, Kind: kind, Source: m.Source, File: m.File, Line: m.Line, Text: text})
+	}
+
+	inList, listItems := false, 0
+	var previous Match
+	for i, m := range output {
+		// A list doesn't continue into another source or transcript
+		if i > 0 && (m.Source != previous.Source || m.File != previous.File) {
+			inList = false
+		}
+		previous = m
+
+		if inList {
+			if item := listItemRe.FindStringSubmatch(m.Text); item != nil {
+				if checked := item[1] + item[2]; checked != "x" && checked != "X" {
+					add(KindNextStep, m, item[3])
+				}
+				listItems++
+				continue
+			}
+			if strings.TrimSpace(m.Text) == "" && listItems == 0 {
+				continue
+			}
+			inList = false
+		}
+		if headingRe.MatchString(m.Text) {
+			inList, listItems = true, 0
+			continue
+		}
+		if marker := markerRe.FindStringSubmatch(m.Text); marker != nil {
+			text := marker[2]
+			if text == "" {
+				text = m.Text
+			}
+			add(marker[1], m, text)
+		}
+	}
+	return todos
+}
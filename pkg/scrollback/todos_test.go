@@ -0,0 +1,102 @@
+package scrollback
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestExtractTodos(t *testing.T) {
+	pane := func(lines ...string) []Match {
+		matches := make([]Match, len(lines))
+		for i, line := range lines {
+			matches[i] = Match{Session: "agent-app-abc123-sarah", Agent: "sarah", Source: SourcePane, Line: i + 1, Text: line}
+		}
+		return matches
+	}
+	todo := func(kind string, line int, text string) Todo {
+		return Todo{Session: "agent-app-abc123-sarah", Agent: "sarah", Kind: kind, Source: SourcePane, Line: line, Text: text}
+	}
+
+	tests := []struct {
+		name   string
+		output []Match
+		want   []Todo
+	}{
+		{
+			name:   "markers",
+			output: pane("// TODO: handle the timeout", "**FIXME(sarah):** flaky on CI", "nothing to see", "TODO handle the timeout"),
+			want:   []Todo{todo(KindTodo, 1, "handle the timeout"), todo(KindFixme, 2, "flaky on CI")},
+		},
+		{
+			name: "next steps list",
+			output: pane(
+				"## Next steps",
+				"",
+				"1. Add tests for the parser",
+				"2) Wire up **the CLI**",
+				"- [ ] update the README",
+				"- [x] bump the version",
+				"",
+				"- not part of the list",
+			),
+			want: []Todo{
+				todo(KindNextStep, 3, "Add tests for the parser"),
+				todo(KindNextStep, 4, "Wire up **the CLI**"),
+				todo(KindNextStep, 5, "update the README"),
+			},
+		},
+		{
+			name:   "list ends at prose",
+			output: pane("**Remaining work:**", "• migrate the schema", "All done otherwise.", "- stray bullet"),
+			want:   []Todo{todo(KindNextStep, 2, "migrate the schema")},
+		},
+		{
+			name:   "heading without items",
+			output: pane("Next steps:", "I think we're done."),
+			want:   nil,
+		},
+		{
+			name:   "words that only contain the markers",
+			output: pane("TODOS are tracked elsewhere", "the next steps are unclear"),
+			want:   nil,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := ExtractTodos(tt.output); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("ExtractTodos() = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestTodos(t *testing.T) {
+	dir := t.TempDir()
+	transcript := strings.Join([]string{
+		`{"type":"assistant","message":{"content":[{"type":"text","text":"Done with the parser.\n\nNext steps:\n- add fuzz tests\n- Handle the timeout"}]}}`,
+	}, "\n")
+	if err := os.WriteFile(filepath.Join(dir, "a1.jsonl"), []byte(transcript), 0644); err != nil {
+		t.Fatal(err)
+	}
+	executor := &paneExecutor{pane: "$ go test\n// TODO: handle the timeout\nok\n"}
+
+	todos, err := Todos(Session{Name: "agent-app-abc123-sarah", Executor: executor, TranscriptDir: dir}, 0)
+	if err != nil {
+		t.Fatalf("Todos() error = %v", err)
+	}
+	want := []Todo{
+		{Session: "agent-app-abc123-sarah", Agent: "sarah", Kind: KindTodo, Source: SourcePane, Line: 2, Text: "handle the timeout"},
+		{Session: "agent-app-abc123-sarah", Agent: "sarah", Kind: KindNextStep, Source: SourceTranscript, File: "a1.jsonl", Line: 1, Text: "add fuzz tests"},
+		{Session: "agent-app-abc123-sarah", Agent: "sarah", Kind: KindNextStep, Source: SourceTranscript, File: "a1.jsonl", Line: 1, Text: "Handle the timeout"},
+	}
+	if !reflect.DeepEqual(todos, want) {
+		t.Errorf("Todos() = %+v, want %+v", todos, want)
+	}
+	if got := todos[1].Location(); got != "transcript a1.jsonl:1" {
+		t.Errorf("Location() = %q", got)
+	}
+}
@@ -0,0 +1,184 @@
+package scrollback
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Usage is the API usage recorded in claude transcripts
+type Usage struct {
+	InputTokens      int64   `json:"input_tokens"`
+	OutputTokens     int64   `json:"output_tokens"`
+	CacheWriteTokens int64   `json:"cache_write_tokens"`
+	CacheReadTokens  int64   `json:"cache_read_tokens"`
+	CostUSD          float64 `json:"cost_usd"` // recorded cost, or an estimate at list prices
+}
+
+// Add returns the sum of two usages
+func (u Usage) Add(other Usage) Usage {
+	return Usage{
+		InputTokens:      u.InputTokens + other.InputTokens,
+		OutputTokens:     u.OutputTokens + other.OutputTokens,
+		CacheWriteTokens: u.CacheWriteTokens + other.CacheWriteTokens,
+		CacheReadTokens:  u.CacheReadTokens + other.CacheReadTokens,
+		CostUSD:          u.CostUSD + other.CostUSD,
+	}
+}
+
+// modelPrice is the list price of a model family in dollars per million
+// tokens; cache writes cost 1.25 times input and cache reads a tenth of it
+type modelPrice struct {
+	family string
+	input  float64
+	output float64
+}
+
+// modelPrices are matched against the model of each response, in order.
+// Models of other families are counted without a cost.
+var modelPrices = []modelPrice{
+	{family: "opus", input: 15, output: 75},
+	{family: "sonnet", input: 3, output: 15},
+	{family: "haiku", input: 0.8, output: 4},
+}
+
+// estimateCost prices the tokens of one response of model
+func estimateCost(model string, u Usage) float64 {
+	for _, price := range modelPrices {
+		if strings.Contains(model, price.family) {
+			input := float64(u.InputTokens) + 1.25*float64(u.CacheWriteTokens) + 0.1*float64(u.CacheReadTokens)
+			return (input*price.input + float64(u.OutputTokens)*price.output) / 1e6
+		}
+	}
+	return 0
+}
+
+// TranscriptUsage sums the usage of every response in the transcripts in
+// dir. A missing directory has no usage.
+func TranscriptUsage(dir string) (Usage, error) {
+	paths, err := filepath.Glob(filepath.Join(dir, "*.jsonl"))
+	if err != nil {
+		return Usage{}, err
+	}
+	var total Usage
+	for _, path := range paths {
+		usage, err := transcriptUsage(path)
+		if err != nil {
+			return Usage{}, fmt.Errorf("failed to read transcript %s: %w", path, err)
+		}
+		total = total.Add(usage)
+	}
+	return total, nil
+}
+
+// transcriptEntry is the part of a transcript entry usage is read from
+type transcriptEntry struct {
+	CostUSD *float64 `json:"costUSD"`
+	Message struct {
+		ID    string `json:"id"`
+		Model string `json:"model"`
+		Usage *struct {
+			InputTokens              int64 `json:"input_tokens"`
+			OutputTokens             int64 `json:"output_tokens"`
+			CacheCreationInputTokens int64 `json:"cache_creation_input_tokens"`
+			CacheReadInputTokens     int64 `json:"cache_read_input_tokens"`
+		} `json:"usage"`
+	} `json:"message"`
+}
+
+// transcriptUsage sums the usage of the responses in a JSONL transcript. A
+// response split over several entries repeats its message id and usage, so it
+// is counted once. Entries that record costUSD are priced at that cost.
+func transcriptUsage(path string) (Usage, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return Usage{}, err
+	}
+	defer file.Close()
+
+	var total Usage
+	seen := map[string]bool{}
+	scanner := bufio.NewScanner(file)
+	scanner.Buffer(make([]byte, 64*1024), maxTranscriptLine)
+	for scanner.Scan() {
+		var entry transcriptEntry
+		if err := json.Unmarshal(scanner.Bytes(), &entry); err != nil || entry.Message.Usage == nil {
+			continue
+		}
+		if id := entry.Message.ID; id != "" {
+			if seen[id] {
+				continue
+			}
+			seen[id] = true
+		}
+		usage := Usage{
+			InputTokens:      entry.Message.Usage.InputTokens,
+			OutputTokens:     entry.Message.Usage.OutputTokens,
+			CacheWriteTokens: entry.Message.Usage.CacheCreationInputTokens,
+			CacheReadTokens:  entry.Message.Usage.CacheReadInputTokens,
+		}
+		if entry.CostUSD != nil {
+			usage.CostUSD = *entry.CostUSD
+		} else {
+			usage.CostUSD = estimateCost(entry.Message.Model, usage)
+		}
+		total = total.Add(usage)
+	}
+	return total, scanner.Err()
+}
+
+// cachedUsage is the usage of a transcript file as of its size and
+// modification time
+type cachedUsage struct {
+	size    int64
+	modTime time.Time
+	usage   Usage
+}
+
+// UsageCache sums transcript usage like TranscriptUsage, reading again only
+// the transcripts that changed since the last call. It is safe for
+// concurrent use.
+type UsageCache struct {
+	mu    sync.Mutex
+	files map[string]cachedUsage
+}
+
+// NewUsageCache returns an empty UsageCache
+func NewUsageCache() *UsageCache {
+	return &UsageCache{files: map[string]cachedUsage{}}
+}
+
+// Usage returns the usage of the transcripts in dir
+func (c *UsageCache) Usage(dir string) (Usage, error) {
+	paths, err := filepath.Glob(filepath.Join(dir, "*.jsonl"))
+	if err != nil {
+		return Usage{}, err
+	}
+	var total Usage
+	for _, path := range paths {
+		info, err := os.Stat(path)
+		if err != nil {
+			continue // Removed since the glob
+		}
+		c.mu.Lock()
+		cached, ok := c.files[path]
+		c.mu.Unlock()
+		if !ok || cached.size != info.Size() || !cached.modTime.Equal(info.ModTime()) {
+			usage, err := transcriptUsage(path)
+			if err != nil {
+				return Usage{}, fmt.Errorf("failed to read transcript %s: %w", path, err)
+			}
+			cached = cachedUsage{size: info.Size(), modTime: info.ModTime(), usage: usage}
+			c.mu.Lock()
+			c.files[path] = cached
+			c.mu.Unlock()
+		}
+		total = total.Add(cached.usage)
+	}
+	return total, nil
+}
@@ -0,0 +1,65 @@
+package scrollback
+
+import (
+	"math"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestTranscriptUsage(t *testing.T) {
+	dir := t.TempDir()
+	transcript := strings.Join([]string{
+		`{"type":"user","message":{"content":"fix the login form"}}`,
+		`{"type":"assistant","message":{"id":"msg_1","model":"claude-sonnet-4-20250514","usage":{"input_tokens":1000000,"output_tokens":100000,"cache_creation_input_tokens":0,"cache_read_input_tokens":1000000}}}`,
+		`{"type":"assistant","message":{"id":"msg_1","model":"claude-sonnet-4-20250514","usage":{"input_tokens":1000000,"output_tokens":100000}}}`,
+		`{"type":"assistant","costUSD":0.5,"message":{"id":"msg_2","model":"claude-opus-4","usage":{"input_tokens":10,"output_tokens":20}}}`,
+		`{"type":"assistant","message":{"id":"msg_3","model":"gpt-5","usage":{"input_tokens":7,"output_tokens":3}}}`,
+		`{"type":"assistant","message":`,
+	}, "\n")
+	if err := os.WriteFile(filepath.Join(dir, "a1.jsonl"), []byte(transcript), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	usage, err := TranscriptUsage(dir)
+	if err != nil {
+		t.Fatalf("TranscriptUsage() error = %v", err)
+	}
+	if usage.InputTokens != 1000017 || usage.OutputTokens != 100023 || usage.CacheReadTokens != 1000000 {
+		t.Errorf("Expected each response counted once, got %+v", usage)
+	}
+	// sonnet: $3 input + $0.30 cache reads + $1.50 output, and the recorded $0.50
+	if math.Abs(usage.CostUSD-5.3) > 1e-9 {
+		t.Errorf("CostUSD = %v, want 5.3", usage.CostUSD)
+	}
+
+	if usage, err := TranscriptUsage(filepath.Join(dir, "missing")); err != nil || usage != (Usage{}) {
+		t.Errorf("Expected no usage for a missing directory, got %+v, %v", usage, err)
+	}
+}
+
+func TestUsageCache(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "a1.jsonl")
+	write := func(lines ...string) {
+		t.Helper()
+		if err := os.WriteFile(path, []byte(strings.Join(lines, "\n")), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	first := `{"costUSD":1,"message":{"id":"msg_1","usage":{"output_tokens":5}}}`
+	write(first)
+
+	cache := NewUsageCache()
+	if usage, err := cache.Usage(dir); err != nil || usage.CostUSD != 1 {
+		t.Fatalf("Usage() = %+v, %v", usage, err)
+	}
+
+	write(first, `{"costUSD":2,"message":{"id":"msg_2","usage":{"output_tokens":5}}}`)
+	os.Chtimes(path, time.Now(), time.Now().Add(time.Minute))
+	if usage, _ := cache.Usage(dir); usage.CostUSD != 3 || usage.OutputTokens != 10 {
+		t.Errorf("Expected the grown transcript read again, got %+v", usage)
+	}
+}
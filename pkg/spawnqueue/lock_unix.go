@@ -0,0 +1,18 @@
+//go:build !windows
+
+package spawnqueue
+
+import (
+	"os"
+	"syscall"
+)
+
+// lockFile takes an exclusive flock on f, blocking until it is free
+func lockFile(f *os.File) error {
+	return syscall.Flock(int(f.Fd()), syscall.LOCK_EX)
+}
+
+// unlockFile releases the flock on f
+func unlockFile(f *os.File) error {
+	return syscall.Flock(int(f.Fd()), syscall.LOCK_UN)
+}
@@ -0,0 +1,215 @@
+// Package spawnqueue persists the agent spawns `uzi prompt` deferred because
+// maxConcurrentAgents agents were already working, so that a later `uzi kill`,
+// `uzi queue run`, or the TUI can launch them as slots free up.
+package spawnqueue
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+// Entry is one agent waiting to be spawned, with everything `uzi prompt`
+// needs to spawn it later
+type Entry struct {
+	ID         int           `json:"id"`
+	Repo       string        `json:"repo"`  // origin remote of the repository the agent is spawned from
+	Agent      string        `json:"agent"` // agent from --agents, e.g. "claude" or "random"
+	Command    string        `json:"command"`
+	ModelArgs  string        `json:"model_args,omitempty"`
+	DevCommand string        `json:"dev_command,omitempty"`
+	Prompt     string        `json:"prompt,omitempty"`
+	Base       string        `json:"base,omitempty"`
+	BaseCommit string        `json:"base_commit,omitempty"`
+	Shared     bool          `json:"shared,omitempty"`
+	Host       string        `json:"host,omitempty"`
+	MaxRuntime time.Duration `json:"max_runtime,omitempty"`
+	Tags       []string      `json:"tags,omitempty"`
+	Channels   []string      `json:"channels,omitempty"`
+	Run        string        `json:"run,omitempty"`
+	KeepFailed bool          `json:"keep_failed,omitempty"`
+	Iteration  int           `json:"iteration"`
+	QueuedAt   time.Time     `json:"queued_at"`
+}
+
+// Store persists the queue as JSON next to the agent state file
+type Store struct {
+	path string
+}
+
+// NewStore returns a store at ~/.local/share/uzi/queue.json
+func NewStore() (*Store, error) {
+	homeDir, err := os.UserHomeDir()
+	if err != nil {
+		return nil, err
+	}
+	return NewStoreAt(filepath.Join(homeDir, ".local", "share", "uzi", "queue.json")), nil
+}
+
+// NewStoreAt returns a store backed by the given file
+func NewStoreAt(path string) *Store {
+	return &Store{path: path}
+}
+
+// Path returns the file backing the store
+func (s *Store) Path() string {
+	return s.path
+}
+
+func (s *Store) load() ([]Entry, error) {
+	var entries []Entry
+	data, err := os.ReadFile(s.path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return entries, nil
+		}
+		return nil, err
+	}
+	if err := json.Unmarshal(data, &entries); err != nil {
+		return nil, fmt.Errorf("error parsing spawn queue: %w", err)
+	}
+	return entries, nil
+}
+
+func (s *Store) save(entries []Entry) error {
+	if err := os.MkdirAll(filepath.Dir(s.path), 0755); err != nil {
+		return err
+	}
+	if entries == nil {
+		entries = []Entry{}
+	}
+	data, err := json.MarshalIndent(entries, "", "  ")
+	if err != nil {
+		return err
+	}
+	// Write then rename so a concurrent `uzi kill` never reads a partial file
+	tmp := s.path + ".tmp"
+	if err := os.WriteFile(tmp, data, 0644); err != nil {
+		return err
+	}
+	return os.Rename(tmp, s.path)
+}
+
+// lock takes the queue's lock file, waiting while another uzi process holds
+// it, so that changes to the queue don't overwrite each other. It returns
+// the function that releases the lock.
+func (s *Store) lock() (func(), error) {
+	if err := os.MkdirAll(filepath.Dir(s.path), 0755); err != nil {
+		return nil, err
+	}
+	f, err := os.OpenFile(s.path+".lock", os.O_CREATE|os.O_RDWR, 0644)
+	if err != nil {
+		return nil, fmt.Errorf("error opening spawn queue lock: %w", err)
+	}
+	if err := lockFile(f); err != nil {
+		f.Close()
+		return nil, fmt.Errorf("error locking spawn queue: %w", err)
+	}
+	return func() {
+		unlockFile(f)
+		f.Close()
+	}, nil
+}
+
+// Push appends entries to the end of the queue, giving each an ID and the
+// current time, and returns them as queued
+func (s *Store) Push(entries ...Entry) ([]Entry, error) {
+	unlock, err := s.lock()
+	if err != nil {
+		return nil, err
+	}
+	defer unlock()
+	queued, err := s.load()
+	if err != nil {
+		return nil, err
+	}
+	nextID := 1
+	for _, entry := range queued {
+		if entry.ID >= nextID {
+			nextID = entry.ID + 1
+		}
+	}
+	now := time.Now()
+	for i := range entries {
+		entries[i].ID = nextID
+		entries[i].QueuedAt = now
+		nextID++
+	}
+	if err := s.save(append(queued, entries...)); err != nil {
+		return nil, err
+	}
+	return entries, nil
+}
+
+// List returns the queued entries of every repository, oldest first
+func (s *Store) List() ([]Entry, error) {
+	return s.load()
+}
+
+// ListRepo returns the queued entries of one repository, oldest first
+func (s *Store) ListRepo(repo string) ([]Entry, error) {
+	entries, err := s.load()
+	if err != nil {
+		return nil, err
+	}
+	var matching []Entry
+	for _, entry := range entries {
+		if entry.Repo == repo {
+			matching = append(matching, entry)
+		}
+	}
+	return matching, nil
+}
+
+// Pop removes and returns up to n of the oldest entries of the repository;
+// a negative n takes all of them
+func (s *Store) Pop(repo string, n int) ([]Entry, error) {
+	unlock, err := s.lock()
+	if err != nil {
+		return nil, err
+	}
+	defer unlock()
+	entries, err := s.load()
+	if err != nil {
+		return nil, err
+	}
+	var popped, kept []Entry
+	for _, entry := range entries {
+		if entry.Repo == repo && (n < 0 || len(popped) < n) {
+			popped = append(popped, entry)
+			continue
+		}
+		kept = append(kept, entry)
+	}
+	if len(popped) == 0 {
+		return nil, nil
+	}
+	return popped, s.save(kept)
+}
+
+// Remove drops the entry with the given ID. It reports whether it was queued.
+func (s *Store) Remove(id int) (bool, error) {
+	unlock, err := s.lock()
+	if err != nil {
+		return false, err
+	}
+	defer unlock()
+	entries, err := s.load()
+	if err != nil {
+		return false, err
+	}
+	for i, entry := range entries {
+		if entry.ID == id {
+			return true, s.save(append(entries[:i], entries[i+1:]...))
+		}
+	}
+	return false, nil
+}
+
+// Clear drops every entry of the repository and returns how many there were
+func (s *Store) Clear(repo string) (int, error) {
+	entries, err := s.Pop(repo, -1)
+	return len(entries), err
+}
@@ -0,0 +1,132 @@
+package spawnqueue
+
+import (
+	"os"
+	"path/filepath"
+	"sync"
+	"testing"
+)
+
+func TestStore(t *testing.T) {
+	store := NewStoreAt(filepath.Join(t.TempDir(), "uzi", "queue.json"))
+
+	if entries, err := store.List(); err != nil || len(entries) != 0 {
+		t.Fatalf("Expected an empty queue without a file, got %v, %v", entries, err)
+	}
+	queued, err := store.Push(
+		Entry{Repo: "app", Agent: "claude", Command: "claude", Prompt: "fix the login bug"},
+		Entry{Repo: "app", Agent: "codex", Command: "codex", Iteration: 1},
+		Entry{Repo: "site", Agent: "claude", Command: "claude"},
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if queued[0].ID != 1 || queued[2].ID != 3 || queued[0].QueuedAt.IsZero() {
+		t.Errorf("Expected sequential IDs and a queue time, got %+v", queued)
+	}
+	if _, err := os.Stat(store.Path()); err != nil {
+		t.Errorf("Expected the queue saved, got %v", err)
+	}
+
+	app, err := store.ListRepo("app")
+	if err != nil || len(app) != 2 {
+		t.Fatalf("Expected 2 entries for app, got %v, %v", app, err)
+	}
+
+	popped, err := store.Pop("app", 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(popped) != 1 || popped[0].Prompt != "fix the login bug" {
+		t.Errorf("Expected the oldest entry popped first, got %+v", popped)
+	}
+	if popped, _ := store.Pop("other", 5); popped != nil {
+		t.Errorf("Expected nothing popped for another repository, got %+v", popped)
+	}
+
+	// IDs keep counting after entries leave the queue
+	more, _ := store.Push(Entry{Repo: "app", Agent: "claude", Command: "claude"})
+	if more[0].ID != 4 {
+		t.Errorf("Expected ID 4, got %d", more[0].ID)
+	}
+
+	if removed, err := store.Remove(2); err != nil || !removed {
+		t.Errorf("Remove(2) = %v, %v", removed, err)
+	}
+	if removed, _ := store.Remove(2); removed {
+		t.Error("Expected a second remove to find nothing")
+	}
+	if cleared, err := store.Clear("app"); err != nil || cleared != 1 {
+		t.Errorf("Clear(app) = %d, %v, want 1", cleared, err)
+	}
+	entries, _ := store.List()
+	if len(entries) != 1 || entries[0].Repo != "site" {
+		t.Errorf("Expected only the site entry left, got %+v", entries)
+	}
+}
+
+func TestStoreCorrupt(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "queue.json")
+	if err := os.WriteFile(path, []byte("{"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := NewStoreAt(path).Push(Entry{Repo: "app"}); err == nil {
+		t.Error("Expected a corrupt queue to fail")
+	}
+}
+
+func TestStoreConcurrentChanges(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "queue.json")
+
+	// Each store stands in for a separate uzi process changing the queue
+	var wg sync.WaitGroup
+	for i := 0; i < 20; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if _, err := NewStoreAt(path).Push(Entry{Repo: "app", Agent: "claude"}); err != nil {
+				t.Error(err)
+			}
+		}()
+	}
+	wg.Wait()
+
+	entries, err := NewStoreAt(path).List()
+	if err != nil || len(entries) != 20 {
+		t.Fatalf("Expected all 20 pushes kept, got %d, %v", len(entries), err)
+	}
+	ids := make(map[int]bool)
+	for _, entry := range entries {
+		ids[entry.ID] = true
+	}
+	if len(ids) != 20 {
+		t.Errorf("Expected unique IDs, got %d distinct", len(ids))
+	}
+
+	popped := make(chan int, 20)
+	for i := 0; i < 20; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			entries, err := NewStoreAt(path).Pop("app", 1)
+			if err != nil {
+				t.Error(err)
+			}
+			for _, entry := range entries {
+				popped <- entry.ID
+			}
+		}()
+	}
+	wg.Wait()
+	close(popped)
+	seen := make(map[int]bool)
+	for id := range popped {
+		if seen[id] {
+			t.Errorf("Entry %d popped twice", id)
+		}
+		seen[id] = true
+	}
+	if len(seen) != 20 {
+		t.Errorf("Expected every entry popped once, got %d", len(seen))
+	}
+}
@@ -0,0 +1,315 @@
+package state
+
+import (
+	"fmt"
+	"os"
+	"os/exec"
+	"regexp"
+	"sync"
+	"time"
+
+	"github.com/nehpz/claudicus/pkg/tmuxops"
+)
+
+// untrackedFilesScript lists the untracked, non-ignored files of a worktree, one
+// per line, unquoting names with spaces. --no-optional-locks keeps status from
+// refreshing the index. Only the pathspecs in "$@" are listed; none lists all.
+const untrackedFilesScript = `git --no-optional-locks status --porcelain --untracked-files=all -- "$@" | sed -n -e 's/^?? "\(.*\)"$/\1/p' -e 's/^?? //p'`
+
+// DiffScript prints `git diff --shortstat` totals for every change in a
+// worktree outside its IgnoreFile: a line for the tracked changes against
+// HEAD, then a line counting untracked files as added. It only reads, as the
+// agent may be using the index.
+const DiffScript = ignorePathspecsScript + `git diff --shortstat HEAD -- "$@" && ` + untrackedFilesScript +
+	` | while IFS= read -r f; do git diff --no-index --numstat /dev/null "$f"; done` +
+	` | awk '$1 ~ /^[0-9]+$/ { n += $1 } { files++ } END { if (files) printf " %d files changed, %d insertions(+)\n", files, n }'`
+
+// PatchScript prints the full diff of a worktree against HEAD, with untracked
+// files shown as added, without touching the index. Unlike the diff counts it
+// includes the paths of IgnoreFile, since they are committed all the same.
+const PatchScript = "git diff HEAD && " + untrackedFilesScript +
+	` | while IFS= read -r f; do git diff --no-index /dev/null "$f" || true; done`
+
+var (
+	insertionsRe = regexp.MustCompile(`(\d+) insertion(?:s)?\(\+\)`)
+	deletionsRe  = regexp.MustCompile(`(\d+) deletion(?:s)?\(\-\)`)
+	filesRe      = regexp.MustCompile(`(\d+) files? changed`)
+)
+
+// SessionProbe runs the tmux and git commands an Aggregator uses to inspect a session
+type SessionProbe interface {
+	// PaneContent returns the visible content of the session's agent pane
+	PaneContent(sessionName string) (string, error)
+	// DiffStat returns `git diff --shortstat` output for all changes in the worktree
+	DiffStat(worktreePath string) (string, error)
+}
+
+// DefaultSessionProbe runs tmux and git directly
+type DefaultSessionProbe struct{}
+
+// PaneContent captures the session's agent pane with tmux
+func (DefaultSessionProbe) PaneContent(sessionName string) (string, error) {
+	output, err := exec.Command("tmux", "capture-pane", "-t", tmuxops.AgentTarget(sessionName), "-p").Output()
+	if err != nil {
+		return "", err
+	}
+	return string(output), nil
+}
+
+// DiffStat runs the diff script in the worktree
+func (DefaultSessionProbe) DiffStat(worktreePath string) (string, error) {
+	if _, err := os.Stat(worktreePath); err != nil {
+		return "", err
+	}
+	cmd := exec.Command("sh", "-c", DiffScript)
+	cmd.Dir = worktreePath
+	output, err := cmd.Output()
+	if err != nil {
+		return "", err
+	}
+	return string(output), nil
+}
+
+// AggregatorOption configures an Aggregator
+type AggregatorOption func(*Aggregator)
+
+// WithDiffs fills in insertion and deletion counts from each session's worktree
+func WithDiffs() AggregatorOption {
+	return func(a *Aggregator) { a.diffs = true }
+}
+
+// WithTmuxStatus fills in running/ready/unknown from each session's tmux pane
+func WithTmuxStatus() AggregatorOption {
+	return func(a *Aggregator) { a.tmuxStatus = true }
+}
+
+// WithFileDiffs fills in the per-file breakdown of each session's changes,
+// for probes that implement FileDiffProbe. It is not cached.
+func WithFileDiffs() AggregatorOption {
+	return func(a *Aggregator) { a.fileDiffs = true }
+}
+
+// WithDevURLs fills in the dev server URL of sessions with a port
+func WithDevURLs() AggregatorOption {
+	return func(a *Aggregator) { a.devURLs = true }
+}
+
+// WithCacheTTL reuses diff counts for a worktree for up to ttl. Computing a
+// diff stages and unstages the whole worktree, so callers that refresh often
+// (watch mode, the TUI) should cache.
+func WithCacheTTL(ttl time.Duration) AggregatorOption {
+	return func(a *Aggregator) { a.cacheTTL = ttl }
+}
+
+// WithProbe replaces the commands used to inspect sessions
+func WithProbe(probe SessionProbe) AggregatorOption {
+	return func(a *Aggregator) { a.probe = probe }
+}
+
+// WithRemoteProbe inspects sessions on remote hosts with the probe returned
+// for their ssh destination. Without it, remote sessions show as unknown.
+func WithRemoteProbe(remote func(ssh string) SessionProbe) AggregatorOption {
+	return func(a *Aggregator) { a.remoteProbe = remote }
+}
+
+// cachedDiff is a diff count computed at a point in time
+type cachedDiff struct {
+	insertions int
+	deletions  int
+	files      int
+	at         time.Time
+}
+
+// Aggregator turns AgentState entries into SessionInfo for display. It is
+// shared by `uzi ls`, the TUI, and StateReader so they agree on agent names,
+// statuses, diff counts, and dev URLs. It is safe for concurrent use.
+type Aggregator struct {
+	probe       SessionProbe
+	remoteProbe func(ssh string) SessionProbe
+	diffs       bool
+	fileDiffs   bool
+	tmuxStatus  bool
+	devURLs     bool
+	cacheTTL    time.Duration
+	now         func() time.Time
+
+	mu        sync.Mutex
+	diffCache map[string]cachedDiff // by worktree path, prefixed with the ssh destination for remote sessions
+}
+
+// NewAggregator creates an Aggregator. Without options only the fields stored
+// in state.json are filled in.
+func NewAggregator(opts ...AggregatorOption) *Aggregator {
+	a := &Aggregator{
+		probe:     DefaultSessionProbe{},
+		now:       time.Now,
+		diffCache: make(map[string]cachedDiff),
+	}
+	for _, opt := range opts {
+		opt(a)
+	}
+	return a
+}
+
+// Session returns the display information for one session
+func (a *Aggregator) Session(sessionName string, agentState AgentState) SessionInfo {
+	info := SessionInfo{
+		SessionName:  sessionName,
+		AgentName:    AgentNameFromSession(sessionName),
+		Model:        agentState.Model,
+		Prompt:       agentState.Prompt,
+		WorktreePath: agentState.WorktreePath,
+		Port:         agentState.Port,
+		Tags:         agentState.Tags,
+		Channels:     agentState.Channels,
+		Run:          agentState.Run,
+		Host:         agentState.Host,
+		Paused:       agentState.Paused,
+		Done:         agentState.Done,
+		CreatedAt:    agentState.CreatedAt.Format(time.RFC3339),
+		UpdatedAt:    agentState.UpdatedAt.Format(time.RFC3339),
+	}
+	if deadline, ok := agentState.RuntimeDeadline(); ok {
+		info.Deadline = deadline.Format(time.RFC3339)
+	}
+	probe, cacheKey := a.probe, agentState.WorktreePath
+	if agentState.IsRemote() {
+		if a.remoteProbe == nil {
+			if a.tmuxStatus {
+				info.Status = StatusUnknown
+			}
+			return info
+		}
+		probe, cacheKey = a.remoteProbe(agentState.SSH), agentState.SSH+":"+agentState.WorktreePath
+	}
+	if a.tmuxStatus {
+		info.Status = a.signals(probe, sessionName, agentState).Resolve()
+	}
+	if a.diffs && agentState.WorktreePath != "" {
+		info.Insertions, info.Deletions, info.FilesChanged = a.diff(probe, cacheKey, agentState.WorktreePath)
+	}
+	if a.fileDiffs && agentState.WorktreePath != "" {
+		if stat, err := fileDiffStat(probe, agentState.WorktreePath); err == nil {
+			info.Files = stat.Files
+		}
+	}
+	if a.devURLs {
+		info.DevServerURL = DevServerURL(agentState.Port)
+		info.DevServerStatus = agentState.DevServerStatus
+	}
+	return info
+}
+
+// Sessions returns the display information for the named sessions, in order.
+// Names without an entry in states are skipped.
+func (a *Aggregator) Sessions(states map[string]AgentState, sessionNames []string) []SessionInfo {
+	sessions := make([]SessionInfo, 0, len(sessionNames))
+	for _, name := range sessionNames {
+		agentState, ok := states[name]
+		if !ok {
+			continue
+		}
+		sessions = append(sessions, a.Session(name, agentState))
+	}
+	return sessions
+}
+
+// Status returns the status of a live session from its pane alone: starting,
+// running, ready, or unknown if the pane can't be read
+func (a *Aggregator) Status(sessionName string) string {
+	return a.signals(a.probe, sessionName, AgentState{}).Resolve()
+}
+
+// signals gathers what the probe and the session's state tell about a live session
+func (a *Aggregator) signals(probe SessionProbe, sessionName string, agentState AgentState) StatusSignals {
+	content, err := probe.PaneContent(sessionName)
+	return StatusSignals{
+		Alive:   true,
+		Pane:    content,
+		PaneErr: err,
+		Paused:  agentState.Paused,
+		Done:    agentState.Done,
+	}
+}
+
+// Diff returns the insertions and deletions in a worktree, or zeros if they
+// can't be determined
+func (a *Aggregator) Diff(worktreePath string) (int, int) {
+	if worktreePath == "" {
+		return 0, 0
+	}
+	insertions, deletions, _ := a.diff(a.probe, worktreePath, worktreePath)
+	return insertions, deletions
+}
+
+// FileDiffs returns the per-file breakdown of a session's changes, inspecting
+// remote sessions on their host
+func (a *Aggregator) FileDiffs(agentState AgentState) (DiffStat, error) {
+	if agentState.WorktreePath == "" {
+		return DiffStat{}, fmt.Errorf("session has no worktree")
+	}
+	probe := a.probe
+	if agentState.IsRemote() {
+		if a.remoteProbe == nil {
+			return DiffStat{}, fmt.Errorf("cannot inspect sessions on host %s", agentState.Host)
+		}
+		probe = a.remoteProbe(agentState.SSH)
+	}
+	return fileDiffStat(probe, agentState.WorktreePath)
+}
+
+// diff computes a worktree's insertions, deletions, and files changed with
+// probe, caching them under cacheKey
+func (a *Aggregator) diff(probe SessionProbe, cacheKey, worktreePath string) (int, int, int) {
+	now := a.now()
+	if a.cacheTTL > 0 {
+		a.mu.Lock()
+		cached, ok := a.diffCache[cacheKey]
+		a.mu.Unlock()
+		if ok && now.Sub(cached.at) < a.cacheTTL {
+			return cached.insertions, cached.deletions, cached.files
+		}
+	}
+
+	output, err := probe.DiffStat(worktreePath)
+	if err != nil {
+		return 0, 0, 0
+	}
+	insertions, deletions, files := ParseDiffStatFiles(output)
+
+	if a.cacheTTL > 0 {
+		a.mu.Lock()
+		a.diffCache[cacheKey] = cachedDiff{insertions: insertions, deletions: deletions, files: files, at: now}
+		a.mu.Unlock()
+	}
+	return insertions, deletions, files
+}
+
+// ParseDiffStat extracts insertion and deletion counts from `git diff --shortstat`
+// output, summing them over every line such as the lines of DiffScript
+func ParseDiffStat(output string) (int, int) {
+	insertions, deletions, _ := ParseDiffStatFiles(output)
+	return insertions, deletions
+}
+
+// ParseDiffStatFiles is ParseDiffStat that also returns the number of files changed
+func ParseDiffStatFiles(output string) (insertions, deletions, files int) {
+	sum := func(re *regexp.Regexp) int {
+		total := 0
+		for _, m := range re.FindAllStringSubmatch(output, -1) {
+			n := 0
+			fmt.Sscanf(m[1], "%d", &n)
+			total += n
+		}
+		return total
+	}
+	return sum(insertionsRe), sum(deletionsRe), sum(filesRe)
+}
+
+// DevServerURL returns the local dev server URL for a port, or "" without one
+func DevServerURL(port int) string {
+	if port == 0 {
+		return ""
+	}
+	return fmt.Sprintf("http://localhost:%d", port)
+}
@@ -0,0 +1,293 @@
+package state
+
+import (
+	"errors"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// fakeProbe returns canned pane content and diff output and counts diff calls
+type fakeProbe struct {
+	panes     map[string]string
+	diff      string
+	diffCalls atomic.Int32
+	diffCost  time.Duration // Simulated cost of a git diff
+}
+
+func (p *fakeProbe) PaneContent(sessionName string) (string, error) {
+	content, ok := p.panes[sessionName]
+	if !ok {
+		return "", errors.New("can't find session")
+	}
+	return content, nil
+}
+
+func (p *fakeProbe) DiffStat(worktreePath string) (string, error) {
+	p.diffCalls.Add(1)
+	if p.diffCost > 0 {
+		time.Sleep(p.diffCost)
+	}
+	return p.diff, nil
+}
+
+func TestAggregatorSession(t *testing.T) {
+	created := time.Date(2025, 1, 1, 12, 0, 0, 0, time.UTC)
+	agentState := AgentState{
+		Model:        "claude",
+		Prompt:       "fix login",
+		WorktreePath: "/worktrees/sarah",
+		Port:         3001,
+		MaxRuntime:   time.Hour,
+		Tags:         []string{"github#12"},
+		CreatedAt:    created,
+		UpdatedAt:    created.Add(time.Minute),
+	}
+	probe := &fakeProbe{
+		panes: map[string]string{"agent-repo-abc123-sarah": "Thinking... esc to interrupt"},
+		diff:  " 2 files changed, 10 insertions(+), 3 deletions(-)",
+	}
+
+	bare := NewAggregator(WithProbe(probe)).Session("agent-repo-abc123-sarah", agentState)
+	want := SessionInfo{
+		SessionName:  "agent-repo-abc123-sarah",
+		AgentName:    "sarah",
+		Model:        "claude",
+		Prompt:       "fix login",
+		WorktreePath: "/worktrees/sarah",
+		Port:         3001,
+		Deadline:     "2025-01-01T13:00:00Z",
+		Tags:         []string{"github#12"},
+		CreatedAt:    "2025-01-01T12:00:00Z",
+		UpdatedAt:    "2025-01-01T12:01:00Z",
+	}
+	if !reflect.DeepEqual(bare, want) {
+		t.Errorf("Session() without options = %+v, want %+v", bare, want)
+	}
+	if probe.diffCalls.Load() != 0 {
+		t.Error("Expected no diff without WithDiffs")
+	}
+
+	full := NewAggregator(WithProbe(probe), WithTmuxStatus(), WithDiffs(), WithDevURLs()).Session("agent-repo-abc123-sarah", agentState)
+	want.Status = "running"
+	want.Insertions, want.Deletions, want.FilesChanged = 10, 3, 2
+	want.DevServerURL = "http://localhost:3001"
+	if !reflect.DeepEqual(full, want) {
+		t.Errorf("Session() with all options = %+v, want %+v", full, want)
+	}
+}
+
+func TestAggregatorSessions(t *testing.T) {
+	probe := &fakeProbe{panes: map[string]string{"agent-repo-abc123-john": "$ "}}
+	states := map[string]AgentState{
+		"agent-repo-abc123-john":  {},
+		"agent-repo-abc123-sarah": {},
+	}
+
+	sessions := NewAggregator(WithProbe(probe), WithTmuxStatus()).Sessions(states, []string{
+		"agent-repo-abc123-sarah", "agent-repo-abc123-gone", "agent-repo-abc123-john",
+	})
+	if len(sessions) != 2 {
+		t.Fatalf("Expected sessions without state to be skipped, got %+v", sessions)
+	}
+	if sessions[0].AgentName != "sarah" || sessions[0].Status != "unknown" {
+		t.Errorf("Expected sarah with unknown status first, got %+v", sessions[0])
+	}
+	if sessions[1].AgentName != "john" || sessions[1].Status != "ready" {
+		t.Errorf("Expected john ready second, got %+v", sessions[1])
+	}
+}
+
+func TestAggregatorPausedSession(t *testing.T) {
+	probe := &fakeProbe{panes: map[string]string{"agent-repo-abc123-sarah": "esc to interrupt"}}
+	info := NewAggregator(WithProbe(probe), WithTmuxStatus()).Session("agent-repo-abc123-sarah", AgentState{Paused: true})
+	if info.Status != StatusPaused || !info.Paused {
+		t.Errorf("Expected a paused session whatever its pane shows, got %+v", info)
+	}
+}
+
+func TestAggregatorDoneSession(t *testing.T) {
+	probe := &fakeProbe{panes: map[string]string{"agent-repo-abc123-sarah": "> "}}
+	a := NewAggregator(WithProbe(probe), WithTmuxStatus())
+	info := a.Session("agent-repo-abc123-sarah", AgentState{Done: true})
+	if info.Status != StatusDone || !info.Done {
+		t.Errorf("Expected a done session, got %+v", info)
+	}
+	if info := a.Session("agent-repo-abc123-sarah", AgentState{Done: true, Paused: true}); info.Status != StatusPaused {
+		t.Errorf("Expected a pause to outrank done, got %q", info.Status)
+	}
+}
+
+func TestAggregatorDiffCache(t *testing.T) {
+	now := time.Date(2025, 1, 1, 12, 0, 0, 0, time.UTC)
+	probe := &fakeProbe{diff: " 1 file changed, 1 insertion(+)"}
+	a := NewAggregator(WithProbe(probe), WithCacheTTL(3*time.Second))
+	a.now = func() time.Time { return now }
+
+	for i := 0; i < 3; i++ {
+		if ins, del := a.Diff("/worktrees/sarah"); ins != 1 || del != 0 {
+			t.Fatalf("Diff() = %d, %d", ins, del)
+		}
+	}
+	if calls := probe.diffCalls.Load(); calls != 1 {
+		t.Errorf("Expected one diff within the TTL, got %d", calls)
+	}
+
+	now = now.Add(3 * time.Second)
+	a.Diff("/worktrees/sarah")
+	a.Diff("/worktrees/john")
+	if calls := probe.diffCalls.Load(); calls != 3 {
+		t.Errorf("Expected expired and new worktrees to be diffed, got %d calls", calls)
+	}
+
+	if ins, del := a.Diff(""); ins != 0 || del != 0 || probe.diffCalls.Load() != 3 {
+		t.Error("Expected no diff for sessions without a worktree")
+	}
+}
+
+func TestAggregatorRemoteSession(t *testing.T) {
+	agentState := AgentState{WorktreePath: "/home/dev/worktrees/sarah", Host: "box", SSH: "dev@box"}
+
+	// Without a remote probe the local tmux server can't tell anything about the session
+	local := &fakeProbe{panes: map[string]string{"agent-repo-abc123-sarah": "$ "}}
+	info := NewAggregator(WithProbe(local), WithTmuxStatus(), WithDiffs()).Session("agent-repo-abc123-sarah", agentState)
+	if info.Host != "box" || info.Status != "unknown" || local.diffCalls.Load() != 0 {
+		t.Errorf("Expected unknown status on box without a remote probe, got %+v", info)
+	}
+
+	remote := &fakeProbe{
+		panes: map[string]string{"agent-repo-abc123-sarah": "Thinking... esc to interrupt"},
+		diff:  " 1 file changed, 4 insertions(+)",
+	}
+	var probed []string
+	a := NewAggregator(WithProbe(local), WithTmuxStatus(), WithDiffs(), WithRemoteProbe(func(ssh string) SessionProbe {
+		probed = append(probed, ssh)
+		return remote
+	}))
+	info = a.Session("agent-repo-abc123-sarah", agentState)
+	if info.Status != "running" || info.Insertions != 4 || info.FilesChanged != 1 {
+		t.Errorf("Expected status and diff from the remote probe, got %+v", info)
+	}
+	if len(probed) == 0 || probed[0] != "dev@box" || local.diffCalls.Load() != 0 {
+		t.Errorf("Expected only dev@box probed, got %v", probed)
+	}
+}
+
+func TestAgentStatusFromPane(t *testing.T) {
+	tests := map[string]string{
+		"Thinking...":                  "running",
+		"* Working (esc to interrupt)": "running",
+		"> waiting for input":          "ready",
+		"":                             "starting",
+		" \n\n":                        "starting",
+	}
+	for content, want := range tests {
+		if got := AgentStatusFromPane(content); got != want {
+			t.Errorf("AgentStatusFromPane(%q) = %q, want %q", content, got, want)
+		}
+	}
+}
+
+func TestParseDiffStat(t *testing.T) {
+	output := " 2 files changed, 10 insertions(+), 3 deletions(-)\n 1 files changed, 4 insertions(+)\n"
+	insertions, deletions, files := ParseDiffStatFiles(output)
+	if insertions != 14 || deletions != 3 || files != 3 {
+		t.Errorf("ParseDiffStatFiles() = %d, %d, %d; want 14, 3, 3", insertions, deletions, files)
+	}
+	if insertions, deletions := ParseDiffStat(""); insertions != 0 || deletions != 0 {
+		t.Errorf("ParseDiffStat(\"\") = %d, %d", insertions, deletions)
+	}
+}
+
+func TestDiffScriptLeavesIndex(t *testing.T) {
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git not installed")
+	}
+	dir := t.TempDir()
+	git := func(args ...string) string {
+		cmd := exec.Command("git", args...)
+		cmd.Dir = dir
+		cmd.Env = append(os.Environ(), "GIT_AUTHOR_NAME=t", "GIT_AUTHOR_EMAIL=t@t", "GIT_COMMITTER_NAME=t", "GIT_COMMITTER_EMAIL=t@t")
+		output, err := cmd.CombinedOutput()
+		if err != nil {
+			t.Fatalf("git %v: %v: %s", args, err, output)
+		}
+		return string(output)
+	}
+	write := func(name, content string) {
+		if err := os.WriteFile(filepath.Join(dir, name), []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	git("init", "-q")
+	write("a.txt", "one\ntwo\n")
+	git("add", "a.txt")
+	git("commit", "-qm", "init")
+
+	// One line changed and staged by the agent, plus an untracked file
+	write("a.txt", "one\n2\n")
+	git("add", "a.txt")
+	write("new file.txt", "x\ny\nz\n")
+	staged := git("diff", "--cached", "--name-only")
+
+	output, err := DefaultSessionProbe{}.DiffStat(dir)
+	if err != nil {
+		t.Fatalf("DiffStat() error = %v", err)
+	}
+	insertions, deletions, files := ParseDiffStatFiles(output)
+	if insertions != 4 || deletions != 1 || files != 2 {
+		t.Errorf("Expected 4 insertions, 1 deletion in 2 files, got %d, %d, %d from %q", insertions, deletions, files, output)
+	}
+	if after := git("diff", "--cached", "--name-only"); after != staged {
+		t.Errorf("Expected the index untouched, staged files went from %q to %q", staged, after)
+	}
+
+	patch, err := exec.Command("sh", "-c", "cd "+dir+" && "+PatchScript).Output()
+	if err != nil {
+		t.Fatalf("PatchScript error = %v", err)
+	}
+	if !strings.Contains(string(patch), "+2") || !strings.Contains(string(patch), "+z") {
+		t.Errorf("Expected tracked and untracked changes in the patch, got %s", patch)
+	}
+}
+
+func TestDevServerURL(t *testing.T) {
+	if got := DevServerURL(0); got != "" {
+		t.Errorf("DevServerURL(0) = %q", got)
+	}
+	if got := DevServerURL(3000); got != "http://localhost:3000" {
+		t.Errorf("DevServerURL(3000) = %q", got)
+	}
+}
+
+// benchmarkSessions aggregates ten sessions whose diffs each take 100µs
+func benchmarkSessions(b *testing.B, opts ...AggregatorOption) {
+	states := make(map[string]AgentState)
+	var names []string
+	for i := 0; i < 10; i++ {
+		name := "agent-repo-abc123-agent" + string(rune('a'+i))
+		states[name] = AgentState{WorktreePath: "/worktrees/" + name}
+		names = append(names, name)
+	}
+	probe := &fakeProbe{diff: " 1 file changed, 4 insertions(+)", diffCost: 100 * time.Microsecond}
+	a := NewAggregator(append([]AggregatorOption{WithProbe(probe), WithDiffs()}, opts...)...)
+
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		a.Sessions(states, names)
+	}
+}
+
+func BenchmarkAggregatorSessions(b *testing.B) {
+	benchmarkSessions(b)
+}
+
+func BenchmarkAggregatorSessionsCached(b *testing.B) {
+	benchmarkSessions(b, WithCacheTTL(time.Minute))
+}
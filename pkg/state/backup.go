@@ -0,0 +1,63 @@
+package state
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+
+	"github.com/charmbracelet/log"
+)
+
+// StateBackups is how many earlier versions of state.json are kept next to
+// it, from state.json.1 (the newest) to state.json.5, so a corrupted or
+// wrongly edited state file can be recovered
+const StateBackups = 5
+
+// BackupPath returns the path of the nth most recent backup of the state
+// file, from 1 to StateBackups
+func (sm *StateManager) BackupPath(n int) string {
+	return fmt.Sprintf("%s.%d", sm.statePath, n)
+}
+
+// writeStates saves states to the state file after rotating the version it
+// replaces into the backups
+func (sm *StateManager) writeStates(states map[string]AgentState) error {
+	data, err := json.MarshalIndent(states, "", "  ")
+	if err != nil {
+		return err
+	}
+	sm.rotateBackups(data)
+	return sm.fs.WriteFile(sm.statePath, data, 0644)
+}
+
+// rotateBackups shifts state.json.1 through .4 to .2 through .5 and copies
+// the current state file to .1, unless it already holds next. A backup that
+// can't be written is logged rather than failing the change it precedes.
+func (sm *StateManager) rotateBackups(next []byte) {
+	current, err := sm.fs.ReadFile(sm.statePath)
+	if err != nil || bytes.Equal(current, next) {
+		return
+	}
+	for n := StateBackups; n > 1; n-- {
+		data, err := sm.fs.ReadFile(sm.BackupPath(n - 1))
+		if err != nil {
+			continue
+		}
+		if err := sm.fs.WriteFile(sm.BackupPath(n), data, 0644); err != nil {
+			log.Warn("Failed to rotate state backup", "path", sm.BackupPath(n), "error", err)
+			return
+		}
+	}
+	if err := sm.fs.WriteFile(sm.BackupPath(1), current, 0644); err != nil {
+		log.Warn("Failed to back up state file", "path", sm.BackupPath(1), "error", err)
+	}
+}
+
+// parseStates parses the state file, pointing at the backups when it is
+// corrupted
+func (sm *StateManager) parseStates(data []byte, states map[string]AgentState) error {
+	if err := json.Unmarshal(data, &states); err != nil {
+		return fmt.Errorf("error parsing state file %s: %w (earlier versions are kept in %s to .%d)", sm.statePath, err, sm.BackupPath(1), StateBackups)
+	}
+	return nil
+}
@@ -0,0 +1,88 @@
+package state
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestWriteStatesRotatesBackups(t *testing.T) {
+	sm := &StateManager{
+		statePath: filepath.Join(t.TempDir(), "state.json"),
+		fs:        NewDefaultFileSystem(),
+		cmdExec:   &fakeCommandExecutor{repo: "git@github.com:me/app.git"},
+	}
+
+	// The first write has nothing to back up
+	if err := sm.writeStates(map[string]AgentState{"v0": {}}); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := os.Stat(sm.BackupPath(1)); !os.IsNotExist(err) {
+		t.Errorf("Expected no backup of a new state file, got %v", err)
+	}
+
+	for i := 1; i <= StateBackups+2; i++ {
+		if err := sm.writeStates(map[string]AgentState{fmt.Sprintf("v%d", i): {}}); err != nil {
+			t.Fatal(err)
+		}
+	}
+	// Writing unchanged states doesn't push out a backup
+	if err := sm.writeStates(map[string]AgentState{fmt.Sprintf("v%d", StateBackups+2): {}}); err != nil {
+		t.Fatal(err)
+	}
+
+	for n := 1; n <= StateBackups; n++ {
+		data, err := os.ReadFile(sm.BackupPath(n))
+		if err != nil {
+			t.Fatalf("Expected backup %d, got %v", n, err)
+		}
+		var states map[string]AgentState
+		if err := json.Unmarshal(data, &states); err != nil {
+			t.Fatal(err)
+		}
+		want := fmt.Sprintf("v%d", StateBackups+2-n)
+		if _, ok := states[want]; !ok || len(states) != 1 {
+			t.Errorf("Backup %d = %v, want %s", n, states, want)
+		}
+	}
+	if _, err := os.Stat(sm.BackupPath(StateBackups + 1)); !os.IsNotExist(err) {
+		t.Errorf("Expected at most %d backups", StateBackups)
+	}
+}
+
+func TestMutationsBackUpState(t *testing.T) {
+	sm := &StateManager{
+		statePath: filepath.Join(t.TempDir(), "state.json"),
+		fs:        NewDefaultFileSystem(),
+		cmdExec:   &fakeCommandExecutor{repo: "git@github.com:me/app.git"},
+	}
+	writeTestStates(t, sm.statePath, map[string]AgentState{
+		"agent-app-abc123-sarah": {GitRepo: "git@github.com:me/app.git"},
+	})
+
+	if err := sm.RemoveState("agent-app-abc123-sarah"); err != nil {
+		t.Fatal(err)
+	}
+	data, err := os.ReadFile(sm.BackupPath(1))
+	if err != nil || !strings.Contains(string(data), "agent-app-abc123-sarah") {
+		t.Errorf("Expected the removed session in the backup, got %q, %v", data, err)
+	}
+}
+
+func TestParseStatesPointsAtBackups(t *testing.T) {
+	sm := &StateManager{
+		statePath: filepath.Join(t.TempDir(), "state.json"),
+		fs:        NewDefaultFileSystem(),
+		cmdExec:   &fakeCommandExecutor{repo: "git@github.com:me/app.git"},
+	}
+	if err := os.WriteFile(sm.statePath, []byte(`{"agent-app-abc123-sarah": {`), 0644); err != nil {
+		t.Fatal(err)
+	}
+	_, err := sm.StatesForRepo()
+	if err == nil || !strings.Contains(err.Error(), sm.BackupPath(1)) {
+		t.Errorf("Expected the error to point at the backups, got %v", err)
+	}
+}
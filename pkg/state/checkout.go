@@ -0,0 +1,80 @@
+package state
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// CheckoutStatus is how far the main checkout is from the state agents
+// branching from HEAD are expected to start from
+type CheckoutStatus struct {
+	UncommittedChanges int    // Tracked files with staged or unstaged changes
+	Behind             int    // Commits on Upstream that HEAD doesn't have, as of the last fetch
+	Upstream           string // Branch HEAD tracks, e.g. origin/main; empty if none
+}
+
+// Clean reports whether agents branched from HEAD get what the checkout shows
+func (s CheckoutStatus) Clean() bool {
+	return s.UncommittedChanges == 0 && s.Behind == 0
+}
+
+// String describes the status, e.g. "3 uncommitted changes and 2 commits behind origin/main"
+func (s CheckoutStatus) String() string {
+	var parts []string
+	if s.UncommittedChanges > 0 {
+		parts = append(parts, plural(s.UncommittedChanges, "uncommitted change"))
+	}
+	if s.Behind > 0 {
+		parts = append(parts, fmt.Sprintf("%s behind %s", plural(s.Behind, "commit"), s.Upstream))
+	}
+	if len(parts) == 0 {
+		return "clean"
+	}
+	return strings.Join(parts, " and ")
+}
+
+// SpawnWarning explains what agents spawned from the checkout would miss and
+// how to fix it
+func (s CheckoutStatus) SpawnWarning() string {
+	var fixes []string
+	if s.UncommittedChanges > 0 {
+		fixes = append(fixes, "commit or `git stash` the changes")
+	}
+	if s.Behind > 0 {
+		fixes = append(fixes, "`git pull`")
+	}
+	return fmt.Sprintf("main checkout has %s; new agents branch from HEAD without them (%s first)", s, strings.Join(fixes, " and "))
+}
+
+// InspectCheckout counts the uncommitted changes of the checkout in dir and
+// the commits its upstream branch is ahead by. Untracked files are left out,
+// since scratch files are common and rarely meant for agents.
+func InspectCheckout(executor CommandExecutor, dir string) (CheckoutStatus, error) {
+	var status CheckoutStatus
+	changes, err := executor.ExecuteCommand("git", "-C", dir, "status", "--porcelain", "--untracked-files=no")
+	if err != nil {
+		return status, fmt.Errorf("failed to check checkout status: %w", err)
+	}
+	for _, line := range strings.Split(string(changes), "\n") {
+		if strings.TrimSpace(line) != "" {
+			status.UncommittedChanges++
+		}
+	}
+
+	// Branches without an upstream, and detached heads, can't be behind
+	upstream, err := executor.ExecuteCommand("git", "-C", dir, "rev-parse", "--abbrev-ref", "--symbolic-full-name", "@{upstream}")
+	if err != nil {
+		return status, nil
+	}
+	status.Upstream = strings.TrimSpace(string(upstream))
+	count, err := executor.ExecuteCommand("git", "-C", dir, "rev-list", "--count", "HEAD..@{upstream}")
+	if err != nil {
+		return status, fmt.Errorf("failed to count commits behind %s: %w", status.Upstream, err)
+	}
+	status.Behind, err = strconv.Atoi(strings.TrimSpace(string(count)))
+	if err != nil {
+		return status, fmt.Errorf("failed to count commits behind %s: %w", status.Upstream, err)
+	}
+	return status, nil
+}
@@ -0,0 +1,47 @@
+package state
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestInspectCheckout(t *testing.T) {
+	executor := &gitOutputExecutor{outputs: map[string]string{
+		"status":    " M main.go\nA  new.go\n",
+		"rev-parse": "origin/main\n",
+		"rev-list":  "2\n",
+	}}
+	status, err := InspectCheckout(executor, "/repo")
+	if err != nil {
+		t.Fatalf("InspectCheckout() error = %v", err)
+	}
+	if status.UncommittedChanges != 2 || status.Behind != 2 || status.Upstream != "origin/main" {
+		t.Errorf("InspectCheckout() = %+v, want 2 changes, 2 commits behind origin/main", status)
+	}
+	if got := executor.calls[0]; got != "-C /repo status --porcelain --untracked-files=no" {
+		t.Errorf("Expected untracked files left out, got %q", got)
+	}
+	if status.Clean() {
+		t.Error("Expected a dirty checkout")
+	}
+	want := "main checkout has 2 uncommitted changes and 2 commits behind origin/main; new agents branch from HEAD without them (commit or `git stash` the changes and `git pull` first)"
+	if got := status.SpawnWarning(); got != want {
+		t.Errorf("SpawnWarning() = %q, want %q", got, want)
+	}
+}
+
+func TestInspectCheckoutWithoutUpstream(t *testing.T) {
+	// rev-parse fails for branches without an upstream
+	executor := &gitOutputExecutor{outputs: map[string]string{"status": ""}}
+	status, err := InspectCheckout(executor, "/repo")
+	if err != nil {
+		t.Fatalf("InspectCheckout() error = %v", err)
+	}
+	if !status.Clean() || status.Upstream != "" || status.String() != "clean" {
+		t.Errorf("Expected a clean checkout without upstream, got %+v", status)
+	}
+
+	if _, err := InspectCheckout(&gitOutputExecutor{}, "/repo"); err == nil || !strings.Contains(err.Error(), "checkout status") {
+		t.Errorf("Expected the status error, got %v", err)
+	}
+}
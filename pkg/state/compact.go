@@ -0,0 +1,54 @@
+package state
+
+import (
+	"os"
+	"sort"
+)
+
+// StaleSessions returns, in name order, the sessions of the current
+// repository that state still tracks but that left nothing behind: their tmux
+// session has ended and their worktree is gone, such as after a crash or a
+// worktree removed by hand. Remote sessions are not checked.
+func (sm *StateManager) StaleSessions() ([]string, error) {
+	states, err := sm.StatesForRepo()
+	if err != nil {
+		return nil, err
+	}
+	var stale []string
+	for sessionName, agentState := range states {
+		if agentState.IsRemote() || sm.isActiveInTmux(sessionName) {
+			continue
+		}
+		if agentState.WorktreePath != "" {
+			if _, err := sm.fs.Stat(agentState.WorktreePath); !os.IsNotExist(err) {
+				continue
+			}
+		}
+		stale = append(stale, sessionName)
+	}
+	sort.Strings(stale)
+	return stale, nil
+}
+
+// PruneSessions removes the state of the given sessions in a single write,
+// compacting state.json after StaleSessions
+func (sm *StateManager) PruneSessions(sessionNames []string) error {
+	if len(sessionNames) == 0 {
+		return nil
+	}
+	states := make(map[string]AgentState)
+	data, err := sm.fs.ReadFile(sm.statePath)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil
+		}
+		return err
+	}
+	if err := sm.parseStates(data, states); err != nil {
+		return err
+	}
+	for _, sessionName := range sessionNames {
+		delete(states, sessionName)
+	}
+	return sm.writeStates(states)
+}
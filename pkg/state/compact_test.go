@@ -0,0 +1,97 @@
+package state
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+// liveSessionsExecutor reports a fixed git remote and the given tmux sessions
+// as running
+type liveSessionsExecutor struct {
+	fakeCommandExecutor
+	live map[string]bool
+}
+
+func (l *liveSessionsExecutor) RunCommand(name string, args ...string) error {
+	if len(args) > 0 && l.live[args[len(args)-1]] {
+		return nil
+	}
+	return os.ErrNotExist
+}
+
+func TestStaleSessionsAndPrune(t *testing.T) {
+	tmpDir := t.TempDir()
+	repo := "git@github.com:me/app.git"
+	sm := &StateManager{
+		statePath: filepath.Join(tmpDir, "state.json"),
+		fs:        NewDefaultFileSystem(),
+		cmdExec: &liveSessionsExecutor{
+			fakeCommandExecutor: fakeCommandExecutor{repo: repo},
+			live:                map[string]bool{"agent-app-abc123-sarah": true},
+		},
+	}
+	worktree := t.TempDir()
+	writeTestStates(t, sm.statePath, map[string]AgentState{
+		"agent-app-abc123-sarah": {GitRepo: repo, WorktreePath: filepath.Join(tmpDir, "gone")}, // running
+		"agent-app-abc123-john":  {GitRepo: repo, WorktreePath: worktree},                      // worktree left
+		"agent-app-abc123-emily": {GitRepo: repo, WorktreePath: filepath.Join(tmpDir, "gone")}, // stale
+		"agent-app-abc123-mary":  {GitRepo: repo, Mode: ModeShared},                            // stale
+		"agent-app-abc123-bob":   {GitRepo: repo, SSH: "dev@build"},                            // remote
+		"agent-lib-abc123-alex":  {GitRepo: "git@github.com:me/lib.git"},                       // other repository
+	})
+
+	stale, err := sm.StaleSessions()
+	if err != nil {
+		t.Fatalf("StaleSessions() error = %v", err)
+	}
+	want := []string{"agent-app-abc123-emily", "agent-app-abc123-mary"}
+	if !reflect.DeepEqual(stale, want) {
+		t.Fatalf("StaleSessions() = %v, want %v", stale, want)
+	}
+
+	if err := sm.PruneSessions(stale); err != nil {
+		t.Fatalf("PruneSessions() error = %v", err)
+	}
+	states, err := sm.StatesForRepo()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(states) != 3 {
+		t.Errorf("Expected 3 sessions of this repository left, got %v", states)
+	}
+	if stale, _ := sm.StaleSessions(); len(stale) != 0 {
+		t.Errorf("Expected no stale sessions after pruning, got %v", stale)
+	}
+	if _, err := os.Stat(sm.BackupPath(1)); err != nil {
+		t.Errorf("Expected pruning to back up state first, got %v", err)
+	}
+}
+
+func TestSessionForAgent(t *testing.T) {
+	tmpDir := t.TempDir()
+	repo := "git@github.com:me/app.git"
+	sm := &StateManager{
+		statePath: filepath.Join(tmpDir, "state.json"),
+		fs:        NewDefaultFileSystem(),
+		cmdExec: &liveSessionsExecutor{
+			fakeCommandExecutor: fakeCommandExecutor{repo: repo},
+			live:                map[string]bool{"agent-app-abc123-sarah": true, "agent-app-abc123-mary-jane": true},
+		},
+	}
+	writeTestStates(t, sm.statePath, map[string]AgentState{
+		"agent-app-abc123-sarah":     {GitRepo: repo},
+		"agent-app-abc123-mary-jane": {GitRepo: repo},
+		"agent-app-abc123-john":      {GitRepo: repo}, // tmux session ended
+	})
+
+	if got, err := sm.SessionForAgent("mary-jane"); err != nil || got != "agent-app-abc123-mary-jane" {
+		t.Errorf("SessionForAgent(mary-jane) = %q, %v", got, err)
+	}
+	for _, agent := range []string{"jane", "john"} {
+		if _, err := sm.SessionForAgent(agent); err == nil || err.Error() != "no active session found for agent: "+agent {
+			t.Errorf("SessionForAgent(%s) error = %v", agent, err)
+		}
+	}
+}
@@ -0,0 +1,70 @@
+package state
+
+import (
+	"sort"
+	"strings"
+
+	"github.com/nehpz/claudicus/pkg/config"
+)
+
+// sessionTemplate is the naming.session template from uzi.yaml that session
+// names are read back with
+var sessionTemplate = config.DefaultSessionTemplate
+
+// SetSessionTemplate sets the template AgentNameFromSession parses session
+// names with, from naming.session in uzi.yaml
+func SetSessionTemplate(template string) {
+	sessionTemplate = template
+}
+
+// Completer provides shell completion candidates derived from agent state
+type Completer struct {
+	stateManager *StateManager
+}
+
+// NewCompleter creates a Completer backed by the given StateManager
+func NewCompleter(sm *StateManager) *Completer {
+	return &Completer{stateManager: sm}
+}
+
+// AgentNames returns the sorted agent names of all active sessions in the
+// current repository that start with the given prefix
+func (c *Completer) AgentNames(prefix string) ([]string, error) {
+	if c.stateManager == nil {
+		return []string{}, nil
+	}
+
+	activeSessions, err := c.stateManager.GetActiveSessionsForRepo()
+	if err != nil {
+		return nil, err
+	}
+
+	names := []string{}
+	for _, sessionName := range activeSessions {
+		agentName := AgentNameFromSession(sessionName)
+		if strings.HasPrefix(agentName, prefix) {
+			names = append(names, agentName)
+		}
+	}
+	sort.Strings(names)
+
+	return names, nil
+}
+
+// AgentNameFromSession extracts the agent name from a session name by matching
+// it against the session naming template, agent-{project}-{hash}-{agent} unless
+// naming.session in uzi.yaml sets another. Sessions named before the template
+// changed still parse with the default format. Other agent-a-b-rest names
+// yield rest, and names fitting none of these are returned unchanged.
+func AgentNameFromSession(sessionName string) string {
+	for _, template := range []string{sessionTemplate, config.DefaultSessionTemplate} {
+		if agent, ok := config.AgentFromName(template, sessionName); ok {
+			return agent
+		}
+	}
+	parts := strings.SplitN(sessionName, "-", 4)
+	if len(parts) < 4 || parts[0] != "agent" {
+		return sessionName
+	}
+	return parts[3]
+}
@@ -0,0 +1,119 @@
+package state
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/nehpz/claudicus/pkg/config"
+)
+
+// fakeCommandExecutor reports a fixed git remote and treats every tmux session as alive
+type fakeCommandExecutor struct {
+	repo string
+}
+
+func (f *fakeCommandExecutor) ExecuteCommand(name string, args ...string) ([]byte, error) {
+	return []byte(f.repo + "\n"), nil
+}
+
+func (f *fakeCommandExecutor) RunCommand(name string, args ...string) error {
+	return nil
+}
+
+func writeTestStates(t *testing.T, path string, states map[string]AgentState) {
+	t.Helper()
+	data, err := json.MarshalIndent(states, "", "  ")
+	if err != nil {
+		t.Fatalf("Failed to marshal states: %v", err)
+	}
+	if err := os.WriteFile(path, data, 0644); err != nil {
+		t.Fatalf("Failed to write state file: %v", err)
+	}
+}
+
+func TestCompleterAgentNames(t *testing.T) {
+	tmpDir := t.TempDir()
+	sm := &StateManager{
+		statePath: filepath.Join(tmpDir, "state.json"),
+		fs:        NewDefaultFileSystem(),
+		cmdExec:   &fakeCommandExecutor{repo: "git@github.com:org/repo.git"},
+	}
+
+	now := time.Now()
+	writeTestStates(t, sm.statePath, map[string]AgentState{
+		"agent-repo-abc123-sarah":     {GitRepo: "git@github.com:org/repo.git", UpdatedAt: now},
+		"agent-repo-abc123-john":      {GitRepo: "git@github.com:org/repo.git", UpdatedAt: now},
+		"agent-repo-abc123-mary-jane": {GitRepo: "git@github.com:org/repo.git", UpdatedAt: now},
+		"agent-other-def456-steve":    {GitRepo: "git@github.com:org/other.git", UpdatedAt: now},
+	})
+
+	completer := NewCompleter(sm)
+
+	tests := []struct {
+		name   string
+		prefix string
+		want   []string
+	}{
+		{"all agents", "", []string{"john", "mary-jane", "sarah"}},
+		{"prefix match", "s", []string{"sarah"}},
+		{"hyphenated agent", "mary", []string{"mary-jane"}},
+		{"no match", "zed", []string{}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := completer.AgentNames(tt.prefix)
+			if err != nil {
+				t.Fatalf("AgentNames() error = %v", err)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("AgentNames(%q) = %v, want %v", tt.prefix, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestCompleterNilStateManager(t *testing.T) {
+	names, err := NewCompleter(nil).AgentNames("")
+	if err != nil {
+		t.Errorf("Expected no error, got: %v", err)
+	}
+	if len(names) != 0 {
+		t.Errorf("Expected no names, got: %v", names)
+	}
+}
+
+func TestAgentNameFromSession(t *testing.T) {
+	tests := map[string]string{
+		"agent-repo-abc123-sarah":     "sarah",
+		"agent-repo-abc123-mary-jane": "mary-jane",
+		"agent-my-app-1234567-sarah":  "sarah",
+		"agent-my-app-abc1234-sarah":  "sarah",
+		"custom-session":              "custom-session",
+	}
+	for session, want := range tests {
+		if got := AgentNameFromSession(session); got != want {
+			t.Errorf("AgentNameFromSession(%q) = %q, want %q", session, got, want)
+		}
+	}
+}
+
+func TestAgentNameFromSessionTemplate(t *testing.T) {
+	SetSessionTemplate("{agent}-{hash:4}")
+	defer SetSessionTemplate(config.DefaultSessionTemplate)
+
+	tests := map[string]string{
+		"sarah-ab12":              "sarah",
+		"mary-jane-ab12":          "mary-jane",
+		"agent-repo-abc123-sarah": "sarah", // named before the template changed
+	}
+	for session, want := range tests {
+		if got := AgentNameFromSession(session); got != want {
+			t.Errorf("AgentNameFromSession(%q) = %q, want %q", session, got, want)
+		}
+	}
+}
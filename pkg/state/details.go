@@ -0,0 +1,203 @@
+package state
+
+import (
+	"fmt"
+	"net"
+	"os/exec"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/nehpz/claudicus/pkg/tmuxops"
+)
+
+// Dev server health in SessionDetails
+const (
+	DevServerUp      = "up"
+	DevServerDown    = "down"
+	DevServerUnknown = "unknown" // on a remote host, where uzi can't reach the port
+)
+
+// devServerDialTimeout bounds the check that a dev server accepts connections
+const devServerDialTimeout = 500 * time.Millisecond
+
+// dialDevServer reports whether something accepts connections on a local port
+var dialDevServer = func(port int) bool {
+	conn, err := net.DialTimeout("tcp", fmt.Sprintf("localhost:%d", port), devServerDialTimeout)
+	if err != nil {
+		return false
+	}
+	conn.Close()
+	return true
+}
+
+// TmuxProbe is implemented by SessionProbes that can describe a session's
+// tmux windows and clients
+type TmuxProbe interface {
+	// SessionWindows returns the output of tmuxops.SessionWindowsArgs
+	SessionWindows(sessionName string) (string, error)
+}
+
+// SessionWindows lists the session's windows with tmux
+func (DefaultSessionProbe) SessionWindows(sessionName string) (string, error) {
+	output, err := exec.Command("tmux", tmuxops.SessionWindowsArgs(sessionName)...).Output()
+	if err != nil {
+		return "", err
+	}
+	return string(output), nil
+}
+
+// CommitCountProbe is implemented by SessionProbes that can count the
+// commits of a worktree's repository
+type CommitCountProbe interface {
+	// CountCommits returns how many commits to has that from doesn't
+	CountCommits(worktreePath, from, to string) (int, error)
+}
+
+// CountCommits counts the commits with git rev-list
+func (DefaultSessionProbe) CountCommits(worktreePath, from, to string) (int, error) {
+	output, err := exec.Command("git", CountCommitsArgs(worktreePath, from, to)...).Output()
+	if err != nil {
+		return 0, err
+	}
+	return strconv.Atoi(strings.TrimSpace(string(output)))
+}
+
+// CountCommitsArgs builds the git arguments that count the commits to has
+// that from doesn't
+func CountCommitsArgs(worktreePath, from, to string) []string {
+	return []string{"-C", worktreePath, "rev-list", "--count", from + ".." + to}
+}
+
+// TmuxDetails describes the tmux session an agent runs in
+type TmuxDetails struct {
+	Attached  int      `json:"attached"` // clients attached to the session
+	Windows   []string `json:"windows"`
+	CreatedAt string   `json:"created_at,omitempty"`
+}
+
+// SessionDetails is everything uzi knows about one session: its SessionInfo
+// with status, diff counts, and per-file changes filled in, plus its branch,
+// tmux session, the tail of its agent pane, whether its dev server answers,
+// and its last checkpoint
+type SessionDetails struct {
+	SessionInfo
+	BranchName string `json:"branch_name,omitempty"`
+	BranchFrom string `json:"branch_from,omitempty"`
+	BaseCommit string `json:"base_commit,omitempty"`
+	Adopted    bool   `json:"adopted,omitempty"`
+	// CommitsAhead and CommitsBehind compare a session pinned to a base
+	// commit with it: the commits the agent made since, and the commits
+	// BranchFrom gained since
+	CommitsAhead    int          `json:"commits_ahead,omitempty"`
+	CommitsBehind   int          `json:"commits_behind,omitempty"`
+	Mode            string       `json:"mode,omitempty"`
+	Tmux            *TmuxDetails `json:"tmux,omitempty"` // nil if the tmux session can't be inspected
+	PaneTail        []string     `json:"pane_tail,omitempty"`
+	DevServerHealth string       `json:"dev_server_health,omitempty"` // up, down, conflict, or unknown; empty without a port
+	LastCheckpoint  string       `json:"last_checkpoint,omitempty"`   // when `uzi checkpoint` last ran
+	CheckpointError string       `json:"checkpoint_error,omitempty"`  // why the last checkpoint failed
+}
+
+// GetSessionDetails gathers everything known about one session for
+// `uzi status`. Unlike Session it always inspects the session, whatever
+// options the Aggregator has, and keeps the last tailLines lines of its
+// agent pane. Parts that can't be determined are left empty.
+func (a *Aggregator) GetSessionDetails(sessionName string, agentState AgentState, tailLines int) SessionDetails {
+	details := SessionDetails{
+		SessionInfo:     a.Session(sessionName, agentState),
+		BranchName:      agentState.BranchName,
+		BranchFrom:      agentState.BranchFrom,
+		BaseCommit:      agentState.BaseCommit,
+		Adopted:         agentState.Adopted,
+		Mode:            agentState.Mode,
+		CheckpointError: agentState.CheckpointError,
+	}
+	if !agentState.CheckpointedAt.IsZero() {
+		details.LastCheckpoint = agentState.CheckpointedAt.Format(time.RFC3339)
+	}
+	if agentState.Port > 0 {
+		details.DevServerURL = DevServerURL(agentState.Port)
+		details.DevServerStatus = agentState.DevServerStatus
+		switch {
+		case agentState.DevServerStatus == DevServerConflict:
+			details.DevServerHealth = DevServerConflict
+		case agentState.IsRemote():
+			details.DevServerHealth = DevServerUnknown
+		case dialDevServer(agentState.Port):
+			details.DevServerHealth = DevServerUp
+		default:
+			details.DevServerHealth = DevServerDown
+		}
+	}
+
+	probe, cacheKey := a.probe, agentState.WorktreePath
+	if agentState.IsRemote() {
+		if a.remoteProbe == nil {
+			details.Status = StatusUnknown
+			return details
+		}
+		probe, cacheKey = a.remoteProbe(agentState.SSH), agentState.SSH+":"+agentState.WorktreePath
+	}
+
+	content, err := probe.PaneContent(sessionName)
+	details.Status = StatusSignals{Alive: true, Pane: content, PaneErr: err, Paused: agentState.Paused, Done: agentState.Done}.Resolve()
+	if err == nil {
+		details.PaneTail = paneTail(content, tailLines)
+	}
+	if agentState.WorktreePath != "" {
+		details.Insertions, details.Deletions, _ = a.diff(probe, cacheKey, agentState.WorktreePath)
+		if stat, err := fileDiffStat(probe, agentState.WorktreePath); err == nil {
+			details.Files = stat.Files
+		}
+	}
+	if counter, ok := probe.(CommitCountProbe); ok && agentState.BaseCommit != "" && agentState.WorktreePath != "" {
+		details.CommitsAhead, _ = counter.CountCommits(agentState.WorktreePath, agentState.BaseCommit, "HEAD")
+		// An adopted branch is its own BranchFrom; nothing else moves under it
+		if agentState.BranchFrom != "" && !agentState.Adopted {
+			details.CommitsBehind, _ = counter.CountCommits(agentState.WorktreePath, agentState.BaseCommit, agentState.BranchFrom)
+		}
+	}
+	if tmuxProbe, ok := probe.(TmuxProbe); ok {
+		if output, err := tmuxProbe.SessionWindows(sessionName); err == nil {
+			details.Tmux = parseSessionWindows(output)
+		}
+	}
+	return details
+}
+
+// paneTail returns the last n lines of pane content, leaving out the blank
+// lines at the bottom of a pane that hasn't filled up
+func paneTail(content string, n int) []string {
+	if n <= 0 {
+		return nil
+	}
+	lines := strings.Split(strings.TrimRight(content, " \n"), "\n")
+	if len(lines) == 1 && strings.TrimSpace(lines[0]) == "" {
+		return nil
+	}
+	if len(lines) > n {
+		lines = lines[len(lines)-n:]
+	}
+	for i, line := range lines {
+		lines[i] = strings.TrimRight(line, " ")
+	}
+	return lines
+}
+
+// parseSessionWindows parses the output of tmuxops.SessionWindowsArgs
+func parseSessionWindows(output string) *TmuxDetails {
+	details := &TmuxDetails{Windows: []string{}}
+	for _, line := range strings.Split(strings.TrimRight(output, "\n"), "\n") {
+		fields := strings.SplitN(line, "\t", 3)
+		if len(fields) != 3 {
+			continue
+		}
+		details.Attached, _ = strconv.Atoi(fields[0])
+		if created, err := strconv.ParseInt(fields[1], 10, 64); err == nil {
+			details.CreatedAt = time.Unix(created, 0).Format(time.RFC3339)
+		}
+		details.Windows = append(details.Windows, fields[2])
+	}
+	return details
+}
@@ -0,0 +1,138 @@
+package state
+
+import (
+	"reflect"
+	"testing"
+	"time"
+)
+
+// detailsProbe adds per-file diffs, tmux windows, and commit counts to fakeProbe
+type detailsProbe struct {
+	fakeProbe
+	files   string
+	windows string
+	commits map[string]int // by from..to range
+}
+
+func (p *detailsProbe) FileDiffStat(worktreePath string) (string, error) {
+	return p.files, nil
+}
+
+func (p *detailsProbe) SessionWindows(sessionName string) (string, error) {
+	return p.windows, nil
+}
+
+func (p *detailsProbe) CountCommits(worktreePath, from, to string) (int, error) {
+	return p.commits[from+".."+to], nil
+}
+
+func TestGetSessionDetails(t *testing.T) {
+	dialed := 0
+	defer func(dial func(int) bool) { dialDevServer = dial }(dialDevServer)
+	dialDevServer = func(port int) bool {
+		dialed++
+		return port == 3001
+	}
+
+	checkpointed := time.Date(2025, 1, 1, 12, 30, 0, 0, time.UTC)
+	agentState := AgentState{
+		Model:          "claude",
+		Prompt:         "fix login",
+		BranchName:     "sarah",
+		BranchFrom:     "main",
+		WorktreePath:   "/worktrees/sarah",
+		Port:           3001,
+		CheckpointedAt: checkpointed,
+	}
+	probe := &detailsProbe{
+		fakeProbe: fakeProbe{
+			panes: map[string]string{"agent-repo-abc123-sarah": "one\ntwo  \nthree\nesc to interrupt\n\n\n"},
+			diff:  " 1 file changed, 4 insertions(+), 1 deletion(-)",
+		},
+		files:   "M\tmain.go\n4\t1\tmain.go\n",
+		windows: "1\t1735732800\tagent\n1\t1735732800\tuzi-dev\n",
+	}
+
+	// No aggregator options: details are always gathered
+	details := NewAggregator(WithProbe(probe)).GetSessionDetails("agent-repo-abc123-sarah", agentState, 3)
+	if details.Status != "running" || details.Insertions != 4 || details.Deletions != 1 {
+		t.Errorf("Expected a running session with +4 -1, got %+v", details)
+	}
+	if want := []FileDiff{{Path: "main.go", Change: ChangeModified, Insertions: 4, Deletions: 1}}; !reflect.DeepEqual(details.Files, want) {
+		t.Errorf("Files = %+v, want %+v", details.Files, want)
+	}
+	if want := []string{"two", "three", "esc to interrupt"}; !reflect.DeepEqual(details.PaneTail, want) {
+		t.Errorf("PaneTail = %q, want %q", details.PaneTail, want)
+	}
+	wantTmux := &TmuxDetails{Attached: 1, Windows: []string{"agent", "uzi-dev"}, CreatedAt: time.Unix(1735732800, 0).Format(time.RFC3339)}
+	if !reflect.DeepEqual(details.Tmux, wantTmux) {
+		t.Errorf("Tmux = %+v, want %+v", details.Tmux, wantTmux)
+	}
+	if details.DevServerURL != "http://localhost:3001" || details.DevServerHealth != DevServerUp {
+		t.Errorf("Expected the dev server up at port 3001, got %q (%q)", details.DevServerURL, details.DevServerHealth)
+	}
+	if details.BranchName != "sarah" || details.BranchFrom != "main" || details.LastCheckpoint != "2025-01-01T12:30:00Z" {
+		t.Errorf("Unexpected branch or checkpoint: %+v", details)
+	}
+	if details.BaseCommit != "" || details.CommitsAhead != 0 || details.CommitsBehind != 0 {
+		t.Errorf("Expected no commit counts without a base commit, got %+v", details)
+	}
+
+	// A session pinned to a base commit is compared with it
+	probe.commits = map[string]int{"3f2a9c1..HEAD": 2, "3f2a9c1..main": 5, "main..HEAD": 1}
+	pinned := agentState
+	pinned.BaseCommit = "3f2a9c1"
+	details = NewAggregator(WithProbe(probe)).GetSessionDetails("agent-repo-abc123-sarah", pinned, 3)
+	if details.BaseCommit != "3f2a9c1" || details.CommitsAhead != 2 || details.CommitsBehind != 5 {
+		t.Errorf("Expected 2 commits ahead of the base commit and main 5 past it, got %+v", details)
+	}
+
+	// An adopted branch is compared with the commit it was adopted at, and
+	// not with itself
+	adopted := pinned
+	adopted.Adopted = true
+	adopted.BranchFrom = "sarah"
+	probe.commits["3f2a9c1..sarah"] = 2
+	details = NewAggregator(WithProbe(probe)).GetSessionDetails("agent-repo-abc123-sarah", adopted, 3)
+	if !details.Adopted || details.CommitsAhead != 2 || details.CommitsBehind != 0 {
+		t.Errorf("Expected the adopted branch 2 commits ahead and none behind, got %+v", details)
+	}
+
+	// A dev server that doesn't answer, and one whose port was taken
+	agentState.Port = 3002
+	if details := NewAggregator(WithProbe(probe)).GetSessionDetails("agent-repo-abc123-sarah", agentState, 3); details.DevServerHealth != DevServerDown {
+		t.Errorf("Expected the dev server down, got %q", details.DevServerHealth)
+	}
+	agentState.DevServerStatus = DevServerConflict
+	if details := NewAggregator(WithProbe(probe)).GetSessionDetails("agent-repo-abc123-sarah", agentState, 3); details.DevServerHealth != DevServerConflict {
+		t.Errorf("Expected the port conflict reported, got %q", details.DevServerHealth)
+	}
+
+	// Remote sessions can't be inspected without a remote probe
+	agentState.SSH = "build-box"
+	details = NewAggregator(WithProbe(probe)).GetSessionDetails("agent-repo-abc123-sarah", agentState, 3)
+	if details.Status != StatusUnknown || details.Tmux != nil || details.PaneTail != nil {
+		t.Errorf("Expected nothing probed for a remote session, got %+v", details)
+	}
+	if dialed != 4 {
+		t.Errorf("Expected the dev server dialed only for local sessions without a conflict, got %d dials", dialed)
+	}
+}
+
+func TestPaneTail(t *testing.T) {
+	for _, tt := range []struct {
+		content string
+		n       int
+		want    []string
+	}{
+		{"a\nb\nc\n", 2, []string{"b", "c"}},
+		{"a\nb\n", 5, []string{"a", "b"}},
+		{"a\n  \n\n", 5, []string{"a"}},
+		{"\n\n", 5, nil},
+		{"a\nb\n", 0, nil},
+	} {
+		if got := paneTail(tt.content, tt.n); !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("paneTail(%q, %d) = %q, want %q", tt.content, tt.n, got, tt.want)
+		}
+	}
+}
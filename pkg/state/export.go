@@ -0,0 +1,82 @@
+package state
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"sort"
+	"time"
+)
+
+// ExportVersion is the version of the fleet export format written by uzi export
+const ExportVersion = 1
+
+// Export describes a repository's agent sessions for a teammate to recreate
+// with uzi import. It carries session metadata only: worktrees, ports, and
+// hosts stay on the machine they were created on, and the agent branches are
+// shared through the git remote.
+type Export struct {
+	Version    int               `json:"version"`
+	GitRepo    string            `json:"git_repo"`
+	ExportedAt time.Time         `json:"exported_at"`
+	Sessions   []ExportedSession `json:"sessions"`
+}
+
+// ExportedSession is the shareable part of one session's state
+type ExportedSession struct {
+	Agent      string        `json:"agent"`
+	Model      string        `json:"model"`
+	Prompt     string        `json:"prompt"`
+	Branch     string        `json:"branch,omitempty"` // empty for shared sessions
+	BranchFrom string        `json:"branch_from,omitempty"`
+	Mode       string        `json:"mode,omitempty"`
+	Tags       []string      `json:"tags,omitempty"`
+	MaxRuntime time.Duration `json:"max_runtime,omitempty"`
+}
+
+// IsShared reports whether the session ran in the main checkout without a branch
+func (s ExportedSession) IsShared() bool {
+	return s.Mode == ModeShared
+}
+
+// NewExport describes the given sessions, keyed by session name, sorted by agent name
+func NewExport(sessions map[string]AgentState, exportedAt time.Time) Export {
+	export := Export{Version: ExportVersion, ExportedAt: exportedAt, Sessions: []ExportedSession{}}
+	for sessionName, agentState := range sessions {
+		if export.GitRepo == "" {
+			export.GitRepo = agentState.GitRepo
+		}
+		export.Sessions = append(export.Sessions, ExportedSession{
+			Agent:      AgentNameFromSession(sessionName),
+			Model:      agentState.Model,
+			Prompt:     agentState.Prompt,
+			Branch:     agentState.BranchName,
+			BranchFrom: agentState.BranchFrom,
+			Mode:       agentState.Mode,
+			Tags:       agentState.Tags,
+			MaxRuntime: agentState.MaxRuntime,
+		})
+	}
+	sort.Slice(export.Sessions, func(i, j int) bool { return export.Sessions[i].Agent < export.Sessions[j].Agent })
+	return export
+}
+
+// ReadExport parses a fleet export written by uzi export
+func ReadExport(r io.Reader) (Export, error) {
+	var export Export
+	if err := json.NewDecoder(r).Decode(&export); err != nil {
+		return Export{}, fmt.Errorf("error parsing fleet export: %w", err)
+	}
+	if export.Version != ExportVersion {
+		return Export{}, fmt.Errorf("unsupported fleet export version %d (expected %d)", export.Version, ExportVersion)
+	}
+	for i, session := range export.Sessions {
+		if session.Agent == "" {
+			return Export{}, fmt.Errorf("session %d in fleet export has no agent name", i+1)
+		}
+		if session.Branch == "" && !session.IsShared() {
+			return Export{}, fmt.Errorf("session %s in fleet export has no branch", session.Agent)
+		}
+	}
+	return export, nil
+}
@@ -0,0 +1,65 @@
+package state
+
+import (
+	"bytes"
+	"encoding/json"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestNewExport(t *testing.T) {
+	exportedAt := time.Date(2025, 1, 1, 12, 0, 0, 0, time.UTC)
+	export := NewExport(map[string]AgentState{
+		"agent-app-abc123-sarah": {
+			GitRepo: "git@github.com:org/app.git", Model: "claude", Prompt: "fix login",
+			BranchName: "sarah-app-abc123-1", BranchFrom: "main", WorktreePath: "/home/me/worktrees/sarah",
+			Port: 3000, Tags: []string{"github#1"}, MaxRuntime: 2 * time.Hour, Host: "box", SSH: "dev@box",
+		},
+		"agent-app-abc123-emily": {GitRepo: "git@github.com:org/app.git", Model: "codex", Prompt: "review", Mode: ModeShared},
+	}, exportedAt)
+
+	if export.Version != ExportVersion || export.GitRepo != "git@github.com:org/app.git" || !export.ExportedAt.Equal(exportedAt) {
+		t.Errorf("Unexpected export header: %+v", export)
+	}
+	if len(export.Sessions) != 2 || export.Sessions[0].Agent != "emily" || export.Sessions[1].Agent != "sarah" {
+		t.Fatalf("Expected sessions sorted by agent, got %+v", export.Sessions)
+	}
+	sarah := export.Sessions[1]
+	if sarah.Branch != "sarah-app-abc123-1" || sarah.BranchFrom != "main" || sarah.Model != "claude" || sarah.MaxRuntime != 2*time.Hour {
+		t.Errorf("Unexpected exported session: %+v", sarah)
+	}
+
+	// Machine-specific state is left out
+	data, err := json.Marshal(export)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, local := range []string{"/home/me/worktrees", "3000", "dev@box"} {
+		if strings.Contains(string(data), local) {
+			t.Errorf("Expected %q left out of the export, got %s", local, data)
+		}
+	}
+
+	read, err := ReadExport(bytes.NewReader(data))
+	if err != nil {
+		t.Fatalf("ReadExport() error = %v", err)
+	}
+	if len(read.Sessions) != 2 || !read.Sessions[0].IsShared() || read.Sessions[1].Tags[0] != "github#1" {
+		t.Errorf("Expected the export to round trip, got %+v", read)
+	}
+}
+
+func TestReadExportErrors(t *testing.T) {
+	tests := map[string]string{
+		"not json":        "fleet",
+		"unknown version": `{"version": 2, "sessions": []}`,
+		"no agent":        `{"version": 1, "sessions": [{"branch": "b"}]}`,
+		"no branch":       `{"version": 1, "sessions": [{"agent": "sarah"}]}`,
+	}
+	for name, input := range tests {
+		if _, err := ReadExport(strings.NewReader(input)); err == nil {
+			t.Errorf("%s: expected an error", name)
+		}
+	}
+}
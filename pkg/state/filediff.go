@@ -0,0 +1,203 @@
+package state
+
+import (
+	"fmt"
+	"os"
+	"os/exec"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// Kinds of change to a file in an agent's worktree
+const (
+	ChangeAdded    = "added"
+	ChangeModified = "modified"
+	ChangeDeleted  = "deleted"
+)
+
+// FileDiffScript prints the per-file changes of a worktree against HEAD: the
+// `git diff --numstat` and `git diff --name-status` lines of tracked files,
+// then a numstat line per untracked file, diffed against /dev/null. Like
+// DiffScript it leaves out the paths of IgnoreFile and only reads, as the
+// agent may be using the index.
+const FileDiffScript = ignorePathspecsScript + `git -c core.quotePath=false diff --numstat --no-renames HEAD -- "$@" && ` +
+	`git -c core.quotePath=false diff --name-status --no-renames HEAD -- "$@" && ` + untrackedFilesScript +
+	` | while IFS= read -r f; do git -c core.quotePath=false diff --no-index --numstat /dev/null "$f" || true; done`
+
+// untrackedPrefix starts the path of a numstat line for an untracked file
+const untrackedPrefix = "/dev/null => "
+
+// FileDiff is the change to one file of a worktree
+type FileDiff struct {
+	Path       string `json:"path"`
+	Change     string `json:"change"` // added, modified, or deleted
+	Insertions int    `json:"insertions"`
+	Deletions  int    `json:"deletions"`
+	Binary     bool   `json:"binary,omitempty"` // git counts no lines for binary files
+}
+
+// DiffStat breaks the changes of a worktree down by file, in path order
+type DiffStat struct {
+	Files []FileDiff `json:"files"`
+}
+
+// Insertions returns the lines added over every file
+func (d DiffStat) Insertions() int {
+	total := 0
+	for _, file := range d.Files {
+		total += file.Insertions
+	}
+	return total
+}
+
+// Deletions returns the lines removed over every file
+func (d DiffStat) Deletions() int {
+	total := 0
+	for _, file := range d.Files {
+		total += file.Deletions
+	}
+	return total
+}
+
+// Overlap returns the files changed in more than one of the diffs, keyed by
+// name, each with the sorted names of the diffs that changed it
+func Overlap(diffs map[string]DiffStat) map[string][]string {
+	changedBy := make(map[string][]string)
+	for name, diff := range diffs {
+		for _, file := range diff.Files {
+			changedBy[file.Path] = append(changedBy[file.Path], name)
+		}
+	}
+	overlap := make(map[string][]string)
+	for path, names := range changedBy {
+		if len(names) > 1 {
+			sort.Strings(names)
+			overlap[path] = names
+		}
+	}
+	return overlap
+}
+
+// ParseFileDiffs parses the output of FileDiffScript. Files listed by
+// numstat without a name-status line are untracked, and count as added.
+func ParseFileDiffs(output string) DiffStat {
+	files := make(map[string]*FileDiff)
+	file := func(path string) *FileDiff {
+		if files[path] == nil {
+			files[path] = &FileDiff{Path: path, Change: ChangeAdded}
+		}
+		return files[path]
+	}
+
+	for _, line := range strings.Split(output, "\n") {
+		fields := strings.SplitN(line, "\t", 3)
+		switch len(fields) {
+		case 2:
+			// name-status: a status letter, then the path
+			if fields[0] == "" || fields[1] == "" {
+				continue
+			}
+			f := file(fields[1])
+			switch fields[0][0] {
+			case 'A':
+				f.Change = ChangeAdded
+			case 'D':
+				f.Change = ChangeDeleted
+			default:
+				f.Change = ChangeModified
+			}
+		case 3:
+			// numstat: insertions, deletions ("-" for binary files), then the path
+			path := strings.TrimPrefix(fields[2], untrackedPrefix)
+			if path == "" {
+				continue
+			}
+			f := file(path)
+			if fields[0] == "-" && fields[1] == "-" {
+				f.Binary = true
+				continue
+			}
+			f.Insertions, _ = strconv.Atoi(fields[0])
+			f.Deletions, _ = strconv.Atoi(fields[1])
+		}
+	}
+
+	stat := DiffStat{Files: make([]FileDiff, 0, len(files))}
+	for _, f := range files {
+		stat.Files = append(stat.Files, *f)
+	}
+	sort.Slice(stat.Files, func(i, j int) bool { return stat.Files[i].Path < stat.Files[j].Path })
+	return stat
+}
+
+// FileDiffProbe is implemented by SessionProbes that can break a worktree's
+// changes down by file
+type FileDiffProbe interface {
+	// FileDiffStat returns FileDiffScript output for the worktree
+	FileDiffStat(worktreePath string) (string, error)
+}
+
+// fileDiffStat breaks a worktree's changes down by file with probe
+func fileDiffStat(probe SessionProbe, worktreePath string) (DiffStat, error) {
+	fileProbe, ok := probe.(FileDiffProbe)
+	if !ok {
+		return DiffStat{}, fmt.Errorf("per-file diffs are not supported here")
+	}
+	output, err := fileProbe.FileDiffStat(worktreePath)
+	if err != nil {
+		return DiffStat{}, err
+	}
+	return ParseFileDiffs(output), nil
+}
+
+// FileDiffStat runs FileDiffScript in the worktree
+func (DefaultSessionProbe) FileDiffStat(worktreePath string) (string, error) {
+	if _, err := os.Stat(worktreePath); err != nil {
+		return "", err
+	}
+	cmd := exec.Command("sh", "-c", FileDiffScript)
+	cmd.Dir = worktreePath
+	output, err := cmd.Output()
+	if err != nil {
+		return "", err
+	}
+	return string(output), nil
+}
+
+// ScaleBar splits up to width columns between insertions and deletions in
+// proportion to largest, the biggest change of any file, the way
+// `git diff --stat` draws its bars. A changed file always gets a column.
+func ScaleBar(insertions, deletions, largest, width int) (plus, minus int) {
+	total := insertions + deletions
+	if total == 0 || largest <= 0 {
+		return 0, 0
+	}
+	if largest <= width {
+		return insertions, deletions
+	}
+	columns := total * width / largest
+	if columns == 0 {
+		columns = 1
+	}
+	plus = insertions * columns / total
+	if insertions > 0 && plus == 0 {
+		plus = 1
+	}
+	minus = columns - plus
+	if deletions > 0 && minus == 0 && plus > 1 {
+		plus, minus = plus-1, 1
+	}
+	return plus, minus
+}
+
+// Largest returns the biggest change of any file, in lines
+func (d DiffStat) Largest() int {
+	largest := 0
+	for _, file := range d.Files {
+		if n := file.Insertions + file.Deletions; n > largest {
+			largest = n
+		}
+	}
+	return largest
+}
@@ -0,0 +1,129 @@
+package state
+
+import (
+	"os"
+	"os/exec"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func TestParseFileDiffs(t *testing.T) {
+	output := "0\t1\tgone.txt\n" +
+		"2\t1\tmain.go\n" +
+		"-\t-\tlogo.png\n" +
+		"D\tgone.txt\n" +
+		"M\tmain.go\n" +
+		"M\tlogo.png\n" +
+		"3\t0\t/dev/null => new file.txt\n"
+
+	stat := ParseFileDiffs(output)
+	want := []FileDiff{
+		{Path: "gone.txt", Change: ChangeDeleted, Deletions: 1},
+		{Path: "logo.png", Change: ChangeModified, Binary: true},
+		{Path: "main.go", Change: ChangeModified, Insertions: 2, Deletions: 1},
+		{Path: "new file.txt", Change: ChangeAdded, Insertions: 3},
+	}
+	if !reflect.DeepEqual(stat.Files, want) {
+		t.Errorf("ParseFileDiffs() = %+v, want %+v", stat.Files, want)
+	}
+	if stat.Insertions() != 5 || stat.Deletions() != 2 {
+		t.Errorf("Expected +5 -2 in total, got +%d -%d", stat.Insertions(), stat.Deletions())
+	}
+	if stat := ParseFileDiffs(""); len(stat.Files) != 0 {
+		t.Errorf("Expected no files for an empty diff, got %+v", stat.Files)
+	}
+}
+
+func TestDefaultSessionProbeFileDiffStat(t *testing.T) {
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git not available")
+	}
+	dir := t.TempDir()
+	git := func(args ...string) {
+		t.Helper()
+		cmd := exec.Command("git", append([]string{"-c", "user.email=uzi@example.com", "-c", "user.name=uzi"}, args...)...)
+		cmd.Dir = dir
+		if output, err := cmd.CombinedOutput(); err != nil {
+			t.Fatalf("git %v: %v: %s", args, err, output)
+		}
+	}
+	write := func(name, content string) {
+		t.Helper()
+		if err := os.MkdirAll(filepath.Dir(filepath.Join(dir, name)), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(filepath.Join(dir, name), []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	git("init", "-q")
+	write("main.go", "a\nb\n")
+	write("old.txt", "x\n")
+	git("add", ".")
+	git("commit", "-qm", "initial")
+
+	write("main.go", "a\nc\nd\n")
+	os.Remove(filepath.Join(dir, "old.txt"))
+	write("docs/new guide.md", "1\n2\n")
+
+	aggregator := NewAggregator()
+	stat, err := aggregator.FileDiffs(AgentState{WorktreePath: dir})
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []FileDiff{
+		{Path: "docs/new guide.md", Change: ChangeAdded, Insertions: 2},
+		{Path: "main.go", Change: ChangeModified, Insertions: 2, Deletions: 1},
+		{Path: "old.txt", Change: ChangeDeleted, Deletions: 1},
+	}
+	if !reflect.DeepEqual(stat.Files, want) {
+		t.Errorf("FileDiffs() = %+v, want %+v", stat.Files, want)
+	}
+
+	if _, err := aggregator.FileDiffs(AgentState{}); err == nil {
+		t.Error("Expected an error for a session without a worktree")
+	}
+	if _, err := aggregator.FileDiffs(AgentState{WorktreePath: dir, Host: "gpu", SSH: "dev@gpu"}); err == nil {
+		t.Error("Expected an error for a remote session without a remote probe")
+	}
+}
+
+func TestScaleBar(t *testing.T) {
+	for _, tt := range []struct {
+		insertions, deletions, largest int
+		plus, minus                    int
+	}{
+		{0, 0, 10, 0, 0},
+		{3, 2, 5, 3, 2},    // Fits: one column per line
+		{40, 0, 40, 20, 0}, // The largest file fills the bar
+		{20, 20, 40, 10, 10},
+		{1, 0, 400, 1, 0},    // A changed file always shows
+		{10, 1, 400, 1, 0},   // Rounded down to one column, the insertions win
+		{30, 10, 80, 7, 3},   // Half the bar, split in proportion
+		{100, 1, 101, 19, 1}, // Deletions keep a column when they are rounded away
+	} {
+		plus, minus := ScaleBar(tt.insertions, tt.deletions, tt.largest, 20)
+		if plus != tt.plus || minus != tt.minus {
+			t.Errorf("ScaleBar(%d, %d, %d) = %d, %d, want %d, %d", tt.insertions, tt.deletions, tt.largest, plus, minus, tt.plus, tt.minus)
+		}
+	}
+}
+
+func TestOverlap(t *testing.T) {
+	diffs := map[string]DiffStat{
+		"sarah": {Files: []FileDiff{{Path: "main.go"}, {Path: "README.md"}}},
+		"emily": {Files: []FileDiff{{Path: "main.go"}, {Path: "util.go"}}},
+		"john":  {Files: []FileDiff{{Path: "util.go"}, {Path: "main.go"}}},
+	}
+	want := map[string][]string{
+		"main.go": {"emily", "john", "sarah"},
+		"util.go": {"emily", "john"},
+	}
+	if got := Overlap(diffs); !reflect.DeepEqual(got, want) {
+		t.Errorf("Overlap() = %v, want %v", got, want)
+	}
+	if got := Overlap(map[string]DiffStat{"sarah": diffs["sarah"]}); len(got) != 0 {
+		t.Errorf("A single diff should not overlap, got %v", got)
+	}
+}
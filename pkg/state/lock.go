@@ -0,0 +1,116 @@
+package state
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"syscall"
+	"time"
+)
+
+// LockError reports that another process is running a destructive operation on a session
+type LockError struct {
+	SessionName string
+	Operation   string
+	PID         int
+}
+
+func (e *LockError) Error() string {
+	return fmt.Sprintf("%s of %s already in progress by PID %d", e.Operation, AgentNameFromSession(e.SessionName), e.PID)
+}
+
+// SessionLock is a held per-session operation lock
+type SessionLock struct {
+	path string
+}
+
+// lockInfo is the content of a lock file
+type lockInfo struct {
+	PID       int       `json:"pid"`
+	Operation string    `json:"operation"`
+	StartedAt time.Time `json:"started_at"`
+}
+
+// LockSession takes the operation lock of a session so that kill, checkpoint,
+// and spawn never run on the same session at once, even from separate TUIs.
+// Locks left behind by processes that no longer exist are taken over. It returns
+// a *LockError if a live process holds the lock.
+func (sm *StateManager) LockSession(sessionName, operation string) (*SessionLock, error) {
+	dir := filepath.Join(filepath.Dir(sm.statePath), "locks")
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return nil, fmt.Errorf("error creating lock directory: %w", err)
+	}
+	path := filepath.Join(dir, sessionName+".lock")
+
+	data, err := json.Marshal(lockInfo{PID: os.Getpid(), Operation: operation, StartedAt: time.Now()})
+	if err != nil {
+		return nil, err
+	}
+
+	// The lock is written to a temporary file and linked into place, so other
+	// processes never see a partially written lock
+	tmp := fmt.Sprintf("%s.%d.tmp", path, os.Getpid())
+	if err := os.WriteFile(tmp, data, 0644); err != nil {
+		return nil, fmt.Errorf("error writing lock file: %w", err)
+	}
+	defer os.Remove(tmp)
+
+	// Retry once after removing a stale lock
+	for attempt := 0; attempt < 2; attempt++ {
+		err := os.Link(tmp, path)
+		if err == nil {
+			return &SessionLock{path: path}, nil
+		}
+		if !os.IsExist(err) {
+			return nil, fmt.Errorf("error creating lock file: %w", err)
+		}
+
+		holder, err := readLock(path)
+		if err == nil && processAlive(holder.PID) {
+			return nil, &LockError{SessionName: sessionName, Operation: holder.Operation, PID: holder.PID}
+		}
+		if err := os.Remove(path); err != nil && !os.IsNotExist(err) {
+			return nil, fmt.Errorf("error removing stale lock: %w", err)
+		}
+	}
+	return nil, fmt.Errorf("could not lock session %s", sessionName)
+}
+
+// Release removes the lock file. Releasing a nil or zero lock is a no-op.
+func (l *SessionLock) Release() error {
+	if l == nil || l.path == "" {
+		return nil
+	}
+	if err := os.Remove(l.path); err != nil && !os.IsNotExist(err) {
+		return err
+	}
+	return nil
+}
+
+// readLock parses the lock file at path
+func readLock(path string) (lockInfo, error) {
+	var info lockInfo
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return info, err
+	}
+	if err := json.Unmarshal(data, &info); err != nil {
+		return info, err
+	}
+	return info, nil
+}
+
+// processAlive reports whether a process with the given PID exists
+func processAlive(pid int) bool {
+	if pid <= 0 {
+		return false
+	}
+	proc, err := os.FindProcess(pid)
+	if err != nil {
+		return false
+	}
+	err = proc.Signal(syscall.Signal(0))
+	return err == nil || errors.Is(err, syscall.EPERM)
+}
@@ -0,0 +1,95 @@
+package state
+
+import (
+	"encoding/json"
+	"errors"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func newLockTestManager(t *testing.T) *StateManager {
+	t.Helper()
+	return &StateManager{
+		statePath: filepath.Join(t.TempDir(), "state.json"),
+		fs:        NewDefaultFileSystem(),
+		cmdExec:   &DefaultCommandExecutor{},
+	}
+}
+
+func TestLockSession(t *testing.T) {
+	sm := newLockTestManager(t)
+	session := "agent-repo-abc123-sarah"
+
+	lock, err := sm.LockSession(session, "kill")
+	if err != nil {
+		t.Fatalf("LockSession() error = %v", err)
+	}
+
+	_, err = sm.LockSession(session, "checkpoint")
+	var lockErr *LockError
+	if !errors.As(err, &lockErr) {
+		t.Fatalf("Expected LockError while locked, got %v", err)
+	}
+	if lockErr.PID != os.Getpid() || lockErr.Operation != "kill" {
+		t.Errorf("Unexpected lock holder %+v", lockErr)
+	}
+	if !strings.Contains(err.Error(), "kill of sarah already in progress by PID") {
+		t.Errorf("Unexpected error message %q", err.Error())
+	}
+
+	// Other sessions are not affected
+	other, err := sm.LockSession("agent-repo-abc123-emily", "spawn")
+	if err != nil {
+		t.Fatalf("Expected independent lock for another session, got %v", err)
+	}
+	other.Release()
+
+	if err := lock.Release(); err != nil {
+		t.Fatalf("Release() error = %v", err)
+	}
+	relocked, err := sm.LockSession(session, "checkpoint")
+	if err != nil {
+		t.Fatalf("Expected lock to be free after release, got %v", err)
+	}
+	relocked.Release()
+}
+
+func TestLockSessionTakesOverStaleLock(t *testing.T) {
+	sm := newLockTestManager(t)
+	session := "agent-repo-abc123-sarah"
+
+	// A finished process leaves a lock with a PID that no longer exists
+	cmd := exec.Command("true")
+	if err := cmd.Run(); err != nil {
+		t.Skipf("cannot run helper process: %v", err)
+	}
+	data, _ := json.Marshal(lockInfo{PID: cmd.Process.Pid, Operation: "kill"})
+	dir := filepath.Join(filepath.Dir(sm.statePath), "locks")
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(dir, session+".lock"), data, 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	lock, err := sm.LockSession(session, "checkpoint")
+	if err != nil {
+		t.Fatalf("Expected stale lock to be taken over, got %v", err)
+	}
+	defer lock.Release()
+
+	holder, err := readLock(filepath.Join(dir, session+".lock"))
+	if err != nil || holder.PID != os.Getpid() || holder.Operation != "checkpoint" {
+		t.Errorf("Expected lock owned by this process, got %+v, %v", holder, err)
+	}
+}
+
+func TestReleaseNilLock(t *testing.T) {
+	var lock *SessionLock
+	if err := lock.Release(); err != nil {
+		t.Errorf("Release() on nil lock = %v", err)
+	}
+}
@@ -0,0 +1,222 @@
+package state
+
+import (
+	"fmt"
+	"os"
+	"os/exec"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// HunkScript prints the file headers and hunk headers of a worktree's
+// changes to tracked files against HEAD, without context lines, so the
+// changed line ranges can be compared across worktrees. Like FileDiffScript it
+// leaves out the paths of IgnoreFile and only reads.
+const HunkScript = ignorePathspecsScript + `git -c core.quotePath=false diff -U0 --no-renames --no-color --no-ext-diff HEAD -- "$@"` +
+	` | grep -E '^(diff --git |--- |\+\+\+ |@@ )' || true`
+
+// Hunk is a range of lines of a file's HEAD version that a worktree changed.
+// An insertion between two lines changes none of them: Lines is 0 and Start
+// is the line the insertion follows.
+type Hunk struct {
+	Start int `json:"start"`
+	Lines int `json:"lines"`
+}
+
+// span returns the hunk as a half-open range of line positions; insertions
+// get an empty range just after the line they follow
+func (h Hunk) span() (lo, hi int) {
+	if h.Lines == 0 {
+		return h.Start + 1, h.Start + 1
+	}
+	return h.Start, h.Start + h.Lines
+}
+
+// Clashes reports whether two hunks change the same or adjacent lines, which
+// git can't merge without a conflict
+func (h Hunk) Clashes(other Hunk) bool {
+	lo, hi := h.span()
+	otherLo, otherHi := other.span()
+	return lo <= otherHi && otherLo <= hi
+}
+
+// ParseHunks parses the output of HunkScript into the hunks of each file
+func ParseHunks(output string) map[string][]Hunk {
+	hunks := make(map[string][]Hunk)
+	path, inHeader := "", false
+	for _, line := range strings.Split(output, "\n") {
+		switch {
+		case strings.HasPrefix(line, "diff --git "):
+			path, inHeader = "", true
+		case inHeader && strings.HasPrefix(line, "--- a/"):
+			path = strings.TrimPrefix(line, "--- a/")
+		case inHeader && strings.HasPrefix(line, "+++ b/"):
+			path = strings.TrimPrefix(line, "+++ b/")
+		case strings.HasPrefix(line, "@@ "):
+			inHeader = false
+			if hunk, ok := parseHunkHeader(line); ok && path != "" {
+				hunks[path] = append(hunks[path], hunk)
+			}
+		}
+	}
+	return hunks
+}
+
+// parseHunkHeader reads the HEAD side of a hunk header such as
+// "@@ -12,3 +12,5 @@"
+func parseHunkHeader(line string) (Hunk, bool) {
+	fields := strings.Fields(line)
+	if len(fields) < 3 || !strings.HasPrefix(fields[1], "-") {
+		return Hunk{}, false
+	}
+	startStr, linesStr, found := strings.Cut(fields[1][1:], ",")
+	start, err := strconv.Atoi(startStr)
+	if err != nil {
+		return Hunk{}, false
+	}
+	hunk := Hunk{Start: start, Lines: 1}
+	if found {
+		if hunk.Lines, err = strconv.Atoi(linesStr); err != nil {
+			return Hunk{}, false
+		}
+	}
+	return hunk, true
+}
+
+// HunkProbe is implemented by SessionProbes that can list the changed line
+// ranges of a worktree
+type HunkProbe interface {
+	// Hunks returns HunkScript output for the worktree
+	Hunks(worktreePath string) (string, error)
+}
+
+// Hunks runs HunkScript in the worktree
+func (DefaultSessionProbe) Hunks(worktreePath string) (string, error) {
+	if _, err := os.Stat(worktreePath); err != nil {
+		return "", err
+	}
+	cmd := exec.Command("sh", "-c", HunkScript)
+	cmd.Dir = worktreePath
+	output, err := cmd.Output()
+	if err != nil {
+		return "", err
+	}
+	return string(output), nil
+}
+
+// WorktreeChanges are a worktree's pending changes: each changed file and,
+// when they could be read, the lines changed in its tracked files
+type WorktreeChanges struct {
+	Diff  DiffStat
+	Hunks map[string][]Hunk // nil if the line ranges are unknown
+}
+
+// Changes reads a session's pending changes for an overlap check, inspecting
+// remote sessions on their host
+func (a *Aggregator) Changes(agentState AgentState) (WorktreeChanges, error) {
+	if agentState.WorktreePath == "" {
+		return WorktreeChanges{}, fmt.Errorf("session has no worktree")
+	}
+	probe := a.probe
+	if agentState.IsRemote() {
+		if a.remoteProbe == nil {
+			return WorktreeChanges{}, fmt.Errorf("cannot inspect sessions on host %s", agentState.Host)
+		}
+		probe = a.remoteProbe(agentState.SSH)
+	}
+	diff, err := fileDiffStat(probe, agentState.WorktreePath)
+	if err != nil {
+		return WorktreeChanges{}, err
+	}
+	changes := WorktreeChanges{Diff: diff}
+	if hunkProbe, ok := probe.(HunkProbe); ok {
+		if output, err := hunkProbe.Hunks(agentState.WorktreePath); err == nil {
+			changes.Hunks = ParseHunks(output)
+		}
+	}
+	return changes, nil
+}
+
+// FileOverlap is a file with pending changes in more than one worktree
+type FileOverlap struct {
+	Path   string   `json:"path"`
+	Agents []string `json:"agents"` // sorted names of the worktrees that changed it
+	// Conflicting are the agents whose changes to the file clash with
+	// another's: the same or adjacent lines, a file both added, or a file one
+	// deleted that another changed. Merging their work will conflict.
+	Conflicting []string `json:"conflicting,omitempty"`
+}
+
+// Involves reports whether the agent has pending changes to the file
+func (o FileOverlap) Involves(agent string) bool {
+	for _, name := range o.Agents {
+		if name == agent {
+			return true
+		}
+	}
+	return false
+}
+
+// Conflicts reports whether the agent's changes to the file clash with
+// another agent's
+func (o FileOverlap) Conflicts(agent string) bool {
+	for _, name := range o.Conflicting {
+		if name == agent {
+			return true
+		}
+	}
+	return false
+}
+
+// Overlaps finds the files changed in more than one of the worktrees, keyed
+// by agent name, and which of their changes clash. Files are in path order.
+func Overlaps(changes map[string]WorktreeChanges) []FileOverlap {
+	diffs := make(map[string]DiffStat, len(changes))
+	files := make(map[string]map[string]FileDiff, len(changes))
+	for agent, c := range changes {
+		diffs[agent] = c.Diff
+		files[agent] = make(map[string]FileDiff, len(c.Diff.Files))
+		for _, file := range c.Diff.Files {
+			files[agent][file.Path] = file
+		}
+	}
+
+	var overlaps []FileOverlap
+	for path, agents := range Overlap(diffs) {
+		overlap := FileOverlap{Path: path, Agents: agents}
+		clashing := make(map[string]bool)
+		for i, a := range agents {
+			for _, b := range agents[i+1:] {
+				if changesClash(files[a][path], files[b][path], changes[a].Hunks, changes[b].Hunks) {
+					clashing[a], clashing[b] = true, true
+				}
+			}
+		}
+		for _, agent := range agents {
+			if clashing[agent] {
+				overlap.Conflicting = append(overlap.Conflicting, agent)
+			}
+		}
+		overlaps = append(overlaps, overlap)
+	}
+	sort.Slice(overlaps, func(i, j int) bool { return overlaps[i].Path < overlaps[j].Path })
+	return overlaps
+}
+
+// changesClash reports whether two worktrees' changes to the same file can't
+// be merged cleanly. Modifications only clash when their line ranges are
+// known and touch.
+func changesClash(a, b FileDiff, aHunks, bHunks map[string][]Hunk) bool {
+	if a.Change != ChangeModified || b.Change != ChangeModified {
+		return true
+	}
+	for _, aHunk := range aHunks[a.Path] {
+		for _, bHunk := range bHunks[b.Path] {
+			if aHunk.Clashes(bHunk) {
+				return true
+			}
+		}
+	}
+	return false
+}
@@ -0,0 +1,151 @@
+package state
+
+import (
+	"os"
+	"os/exec"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func TestParseHunks(t *testing.T) {
+	output := "diff --git a/main.go b/main.go\n" +
+		"--- a/main.go\n" +
+		"+++ b/main.go\n" +
+		"@@ -3 +3 @@ func main() {\n" +
+		"@@ -10,0 +11,2 @@\n" +
+		"@@ -20,4 +22,0 @@\n" +
+		"diff --git a/gone.txt b/gone.txt\n" +
+		"--- a/gone.txt\n" +
+		"+++ /dev/null\n" +
+		"@@ -1,2 +0,0 @@\n"
+
+	want := map[string][]Hunk{
+		"main.go":  {{Start: 3, Lines: 1}, {Start: 10, Lines: 0}, {Start: 20, Lines: 4}},
+		"gone.txt": {{Start: 1, Lines: 2}},
+	}
+	if got := ParseHunks(output); !reflect.DeepEqual(got, want) {
+		t.Errorf("ParseHunks() = %+v, want %+v", got, want)
+	}
+	if got := ParseHunks(""); len(got) != 0 {
+		t.Errorf("Expected no hunks for an empty diff, got %+v", got)
+	}
+}
+
+func TestHunkClashes(t *testing.T) {
+	for _, tt := range []struct {
+		a, b Hunk
+		want bool
+	}{
+		{Hunk{Start: 3, Lines: 2}, Hunk{Start: 4, Lines: 1}, true},  // same line
+		{Hunk{Start: 3, Lines: 2}, Hunk{Start: 5, Lines: 1}, true},  // adjacent lines
+		{Hunk{Start: 3, Lines: 2}, Hunk{Start: 7, Lines: 1}, false}, // a line apart
+		{Hunk{Start: 4, Lines: 0}, Hunk{Start: 5, Lines: 1}, true},  // insertion right before a change
+		{Hunk{Start: 4, Lines: 0}, Hunk{Start: 4, Lines: 0}, true},  // insertions at the same place
+		{Hunk{Start: 4, Lines: 0}, Hunk{Start: 9, Lines: 0}, false},
+	} {
+		if got := tt.a.Clashes(tt.b); got != tt.want {
+			t.Errorf("%+v.Clashes(%+v) = %v, want %v", tt.a, tt.b, got, tt.want)
+		}
+		if got := tt.b.Clashes(tt.a); got != tt.want {
+			t.Errorf("%+v.Clashes(%+v) = %v, want %v", tt.b, tt.a, got, tt.want)
+		}
+	}
+}
+
+func TestOverlaps(t *testing.T) {
+	modified := func(path string) FileDiff { return FileDiff{Path: path, Change: ChangeModified} }
+	changes := map[string]WorktreeChanges{
+		"sarah": {
+			Diff: DiffStat{Files: []FileDiff{modified("main.go"), modified("README.md"), {Path: "new.go", Change: ChangeAdded}}},
+			Hunks: map[string][]Hunk{
+				"main.go":   {{Start: 10, Lines: 2}},
+				"README.md": {{Start: 1, Lines: 1}},
+			},
+		},
+		"emily": {
+			Diff: DiffStat{Files: []FileDiff{modified("main.go"), modified("README.md"), {Path: "new.go", Change: ChangeAdded}}},
+			Hunks: map[string][]Hunk{
+				"main.go":   {{Start: 40, Lines: 1}},
+				"README.md": {{Start: 1, Lines: 0}},
+			},
+		},
+		"john": {
+			Diff:  DiffStat{Files: []FileDiff{modified("main.go"), modified("other.go")}},
+			Hunks: map[string][]Hunk{"main.go": {{Start: 11, Lines: 1}}},
+		},
+	}
+
+	want := []FileOverlap{
+		{Path: "README.md", Agents: []string{"emily", "sarah"}, Conflicting: []string{"emily", "sarah"}},
+		{Path: "main.go", Agents: []string{"emily", "john", "sarah"}, Conflicting: []string{"john", "sarah"}},
+		{Path: "new.go", Agents: []string{"emily", "sarah"}, Conflicting: []string{"emily", "sarah"}},
+	}
+	got := Overlaps(changes)
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("Overlaps() = %+v, want %+v", got, want)
+	}
+	if !got[1].Involves("john") || got[0].Involves("john") {
+		t.Error("Expected Involves to match the agents that changed the file")
+	}
+	if !got[1].Conflicts("sarah") || got[1].Conflicts("emily") {
+		t.Error("Expected Conflicts to match the agents whose changes clash")
+	}
+
+	// Without line ranges, modifications of the same file aren't flagged
+	delete(changes, "sarah")
+	john := changes["john"]
+	john.Hunks = nil
+	changes["john"] = john
+	if got := Overlaps(changes); len(got) != 1 || got[0].Conflicting != nil {
+		t.Errorf("Expected main.go shared without conflicts, got %+v", got)
+	}
+}
+
+func TestAggregatorChanges(t *testing.T) {
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git not available")
+	}
+	dir := t.TempDir()
+	git := func(args ...string) {
+		t.Helper()
+		cmd := exec.Command("git", append([]string{"-c", "user.email=uzi@example.com", "-c", "user.name=uzi"}, args...)...)
+		cmd.Dir = dir
+		if output, err := cmd.CombinedOutput(); err != nil {
+			t.Fatalf("git %v: %v: %s", args, err, output)
+		}
+	}
+	write := func(name, content string) {
+		t.Helper()
+		if err := os.WriteFile(filepath.Join(dir, name), []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	git("init", "-q")
+	write("main.go", "a\nb\nc\nd\n")
+	git("add", ".")
+	git("commit", "-qm", "initial")
+
+	write("main.go", "a\nB\nc\nd\ne\n")
+	write("new.txt", "1\n")
+
+	aggregator := NewAggregator()
+	changes, err := aggregator.Changes(AgentState{WorktreePath: dir})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(changes.Diff.Files) != 2 {
+		t.Errorf("Expected 2 changed files, got %+v", changes.Diff.Files)
+	}
+	wantHunks := map[string][]Hunk{"main.go": {{Start: 2, Lines: 1}, {Start: 4, Lines: 0}}}
+	if !reflect.DeepEqual(changes.Hunks, wantHunks) {
+		t.Errorf("Changes().Hunks = %+v, want %+v", changes.Hunks, wantHunks)
+	}
+
+	if _, err := aggregator.Changes(AgentState{}); err == nil {
+		t.Error("Expected an error for a session without a worktree")
+	}
+	if _, err := aggregator.Changes(AgentState{WorktreePath: dir, Host: "gpu", SSH: "dev@gpu"}); err == nil {
+		t.Error("Expected an error for a remote session without a remote probe")
+	}
+}
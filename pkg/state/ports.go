@@ -0,0 +1,110 @@
+package state
+
+import (
+	"fmt"
+	"os"
+	"sort"
+)
+
+// PortLease is a dev server port held by a session's saved state. Spawns skip
+// the ports held by every session, of any repository, so a lease outlives
+// its tmux session until the state is removed or the port released.
+type PortLease struct {
+	Port    int    `json:"port"`
+	Session string `json:"session"`
+	Agent   string `json:"agent"`
+	Host    string `json:"host,omitempty"`
+	Active  bool   `json:"active"` // the session's tmux session is still running
+	Health  string `json:"health"` // up, down, conflict, or unknown, as in SessionDetails
+}
+
+// Stale reports whether the lease is held by a session that is gone
+func (l PortLease) Stale() bool {
+	return !l.Active
+}
+
+// PortLeases returns the ports held by saved sessions, in port order
+func (sm *StateManager) PortLeases() ([]PortLease, error) {
+	states, err := sm.allStates()
+	if err != nil {
+		return nil, err
+	}
+	var leases []PortLease
+	for sessionName, agentState := range states {
+		if agentState.Port <= 0 {
+			continue
+		}
+		lease := PortLease{
+			Port:    agentState.Port,
+			Session: sessionName,
+			Agent:   AgentNameFromSession(sessionName),
+			Host:    agentState.Host,
+			Active:  sm.isActive(sessionName, agentState),
+		}
+		switch {
+		case agentState.DevServerStatus == DevServerConflict:
+			lease.Health = DevServerConflict
+		case agentState.IsRemote():
+			lease.Health = DevServerUnknown
+		case dialDevServer(agentState.Port):
+			lease.Health = DevServerUp
+		default:
+			lease.Health = DevServerDown
+		}
+		leases = append(leases, lease)
+	}
+	sort.Slice(leases, func(i, j int) bool {
+		if leases[i].Port != leases[j].Port {
+			return leases[i].Port < leases[j].Port
+		}
+		return leases[i].Session < leases[j].Session
+	})
+	return leases, nil
+}
+
+// ReleasePort clears the stale leases of a port so spawns can pick it again,
+// and returns the sessions it was taken from. A port held by a running
+// session is not released; kill the session instead.
+func (sm *StateManager) ReleasePort(port int) ([]string, error) {
+	states, err := sm.allStates()
+	if err != nil {
+		return nil, err
+	}
+	var released []string
+	for sessionName, agentState := range states {
+		if agentState.Port != port {
+			continue
+		}
+		if sm.isActive(sessionName, agentState) {
+			return nil, fmt.Errorf("port %d is held by running session %s; kill it to free the port", port, sessionName)
+		}
+		released = append(released, sessionName)
+	}
+	if len(released) == 0 {
+		return nil, fmt.Errorf("no session holds port %d", port)
+	}
+	sort.Strings(released)
+	for _, sessionName := range released {
+		agentState := states[sessionName]
+		agentState.Port = 0
+		agentState.DevServerStatus = ""
+		states[sessionName] = agentState
+	}
+	return released, sm.writeStates(states)
+}
+
+// allStates loads the saved state of every session, of any repository
+func (sm *StateManager) allStates() (map[string]AgentState, error) {
+	states := make(map[string]AgentState)
+	data, err := sm.fs.ReadFile(sm.statePath)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return states, nil
+		}
+		return nil, fmt.Errorf("error reading state file: %w", err)
+	}
+	if err := sm.parseStates(data, states); err != nil {
+		return nil, err
+	}
+	return states, nil
+}
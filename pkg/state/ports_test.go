@@ -0,0 +1,54 @@
+package state
+
+import (
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func TestPortLeasesAndRelease(t *testing.T) {
+	defer func(dial func(int) bool) { dialDevServer = dial }(dialDevServer)
+	dialDevServer = func(port int) bool { return port == 3000 }
+
+	sm := &StateManager{
+		statePath: filepath.Join(t.TempDir(), "state.json"),
+		fs:        NewDefaultFileSystem(),
+		cmdExec:   &liveSessionsExecutor{live: map[string]bool{"agent-app-abc123-sarah": true}},
+	}
+	writeTestStates(t, sm.statePath, map[string]AgentState{
+		"agent-app-abc123-sarah": {Port: 3000},
+		"agent-app-abc123-emily": {Port: 3001},
+		"agent-lib-def456-john":  {Port: 49152, DevServerStatus: DevServerConflict},
+		"agent-app-abc123-bob":   {Port: 3002, Host: "build", SSH: "dev@build"},
+		"agent-app-abc123-mary":  {},
+	})
+
+	leases, err := sm.PortLeases()
+	if err != nil {
+		t.Fatalf("PortLeases() error = %v", err)
+	}
+	want := []PortLease{
+		{Port: 3000, Session: "agent-app-abc123-sarah", Agent: "sarah", Active: true, Health: DevServerUp},
+		{Port: 3001, Session: "agent-app-abc123-emily", Agent: "emily", Health: DevServerDown},
+		{Port: 3002, Session: "agent-app-abc123-bob", Agent: "bob", Host: "build", Health: DevServerUnknown},
+		{Port: 49152, Session: "agent-lib-def456-john", Agent: "john", Health: DevServerConflict},
+	}
+	if !reflect.DeepEqual(leases, want) {
+		t.Fatalf("PortLeases() = %+v, want %+v", leases, want)
+	}
+
+	if _, err := sm.ReleasePort(3000); err == nil {
+		t.Error("Expected a port held by a running session not to be released")
+	}
+	if _, err := sm.ReleasePort(4000); err == nil {
+		t.Error("Expected an error for a port no session holds")
+	}
+	released, err := sm.ReleasePort(49152)
+	if err != nil || !reflect.DeepEqual(released, []string{"agent-lib-def456-john"}) {
+		t.Fatalf("ReleasePort() = %v, %v", released, err)
+	}
+	info, err := sm.GetWorktreeInfo("agent-lib-def456-john")
+	if err != nil || info.Port != 0 || info.DevServerStatus != "" {
+		t.Errorf("Expected the lease and its conflict cleared, got %+v, %v", info, err)
+	}
+}
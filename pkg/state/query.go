@@ -0,0 +1,143 @@
+package state
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+)
+
+// SessionFilter is a field=value condition listed sessions must meet
+type SessionFilter struct {
+	Field string
+	Value string
+}
+
+// SessionFilterFields are the fields a SessionFilter can test
+var SessionFilterFields = []string{"status", "agent", "tag", "channel", "run", "host"}
+
+// SessionSortKeys are the orders SortSessions accepts
+var SessionSortKeys = []string{"name", "agent", "status", "diff", "created", "updated", "age", "port"}
+
+// ParseSessionFilter parses a filter expression such as status=running
+func ParseSessionFilter(expr string) (SessionFilter, error) {
+	field, value, ok := strings.Cut(expr, "=")
+	field, value = strings.TrimSpace(field), strings.TrimSpace(value)
+	if !ok || value == "" {
+		return SessionFilter{}, fmt.Errorf("filter %q must be in field=value form", expr)
+	}
+	if !hasString(SessionFilterFields, field) {
+		return SessionFilter{}, fmt.Errorf("unknown filter field %q: use one of %s", field, strings.Join(SessionFilterFields, ", "))
+	}
+	return SessionFilter{Field: field, Value: value}, nil
+}
+
+// Match reports whether a session meets the filter. agent matches the agent
+// CLI, as in --agents claude:1, or the agent's name; host=local matches the
+// sessions on this machine.
+func (f SessionFilter) Match(info SessionInfo) bool {
+	switch f.Field {
+	case "status":
+		return info.Status == f.Value
+	case "agent":
+		return info.Model == f.Value || info.AgentName == f.Value
+	case "tag":
+		return hasString(info.Tags, f.Value)
+	case "channel":
+		return hasString(info.Channels, f.Value)
+	case "run":
+		return info.Run == f.Value
+	case "host":
+		if f.Value == "local" {
+			return info.Host == ""
+		}
+		return info.Host == f.Value
+	}
+	return false
+}
+
+// FilterSessions returns the sessions that meet every filter, in order
+func FilterSessions(sessions []SessionInfo, filters []SessionFilter) []SessionInfo {
+	if len(filters) == 0 {
+		return sessions
+	}
+	var matched []SessionInfo
+	for _, info := range sessions {
+		ok := true
+		for _, f := range filters {
+			if !f.Match(info) {
+				ok = false
+				break
+			}
+		}
+		if ok {
+			matched = append(matched, info)
+		}
+	}
+	return matched
+}
+
+// SortSessions orders sessions by key: name, agent and status alphabetically,
+// diff by most lines changed, created and updated newest first, age oldest
+// first, and port ascending. Sessions that compare equal keep their order.
+func SortSessions(sessions []SessionInfo, key string) error {
+	var less func(a, b SessionInfo) bool
+	switch key {
+	case "name":
+		less = func(a, b SessionInfo) bool { return a.AgentName < b.AgentName }
+	case "agent":
+		less = func(a, b SessionInfo) bool { return a.Model < b.Model }
+	case "status":
+		less = func(a, b SessionInfo) bool { return a.Status < b.Status }
+	case "diff":
+		less = func(a, b SessionInfo) bool { return a.Insertions+a.Deletions > b.Insertions+b.Deletions }
+	case "created":
+		less = func(a, b SessionInfo) bool { return parseTime(a.CreatedAt).After(parseTime(b.CreatedAt)) }
+	case "updated":
+		less = func(a, b SessionInfo) bool { return parseTime(a.UpdatedAt).After(parseTime(b.UpdatedAt)) }
+	case "age":
+		less = func(a, b SessionInfo) bool { return parseTime(a.CreatedAt).Before(parseTime(b.CreatedAt)) }
+	case "port":
+		less = func(a, b SessionInfo) bool { return a.Port < b.Port }
+	default:
+		return fmt.Errorf("unknown sort key %q: use one of %s", key, strings.Join(SessionSortKeys, ", "))
+	}
+	sort.SliceStable(sessions, func(i, j int) bool { return less(sessions[i], sessions[j]) })
+	return nil
+}
+
+// parseTime parses an RFC 3339 time from SessionInfo, or returns the zero time
+func parseTime(value string) time.Time {
+	t, _ := time.Parse(time.RFC3339, value)
+	return t
+}
+
+// Ago humanizes the time from an RFC 3339 timestamp to now, e.g. "2h ago",
+// whatever zone the timestamp was written in. It returns an empty string if
+// the timestamp is missing or invalid.
+func Ago(value string, now time.Time) string {
+	t := parseTime(value)
+	if t.IsZero() {
+		return ""
+	}
+	elapsed := max(now.Sub(t), 0)
+	switch {
+	case elapsed < time.Minute:
+		return fmt.Sprintf("%ds ago", int(elapsed.Seconds()))
+	case elapsed < time.Hour:
+		return fmt.Sprintf("%dm ago", int(elapsed.Minutes()))
+	case elapsed < 24*time.Hour:
+		return fmt.Sprintf("%dh ago", int(elapsed.Hours()))
+	default:
+		return fmt.Sprintf("%dd ago", int(elapsed.Hours()/24))
+	}
+}
+
+func hasString(list []string, s string) bool {
+	for _, item := range list {
+		if item == s {
+			return true
+		}
+	}
+	return false
+}
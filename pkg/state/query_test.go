@@ -0,0 +1,105 @@
+package state
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestParseSessionFilter(t *testing.T) {
+	f, err := ParseSessionFilter(" status = running ")
+	if err != nil || f != (SessionFilter{Field: "status", Value: "running"}) {
+		t.Errorf("ParseSessionFilter() = %+v, %v", f, err)
+	}
+	for _, bad := range []string{"status", "status=", "color=red"} {
+		if _, err := ParseSessionFilter(bad); err == nil {
+			t.Errorf("ParseSessionFilter(%q) should fail", bad)
+		}
+	}
+}
+
+func TestFilterSessions(t *testing.T) {
+	sessions := []SessionInfo{
+		{AgentName: "sarah", Model: "claude", Status: "running", Tags: []string{"ui"}},
+		{AgentName: "john", Model: "codex", Status: "running", Host: "gpu1", Run: "auth-refactor"},
+		{AgentName: "emily", Model: "claude", Status: "ready", Channels: []string{"frontend"}},
+	}
+	tests := []struct {
+		filters []string
+		want    string
+	}{
+		{nil, "sarah,john,emily"},
+		{[]string{"status=running"}, "sarah,john"},
+		{[]string{"status=running", "agent=claude"}, "sarah"},
+		{[]string{"agent=emily"}, "emily"},
+		{[]string{"tag=ui"}, "sarah"},
+		{[]string{"channel=frontend"}, "emily"},
+		{[]string{"run=auth-refactor"}, "john"},
+		{[]string{"host=local"}, "sarah,emily"},
+		{[]string{"host=gpu1", "status=ready"}, ""},
+	}
+	for _, tt := range tests {
+		var filters []SessionFilter
+		for _, expr := range tt.filters {
+			f, err := ParseSessionFilter(expr)
+			if err != nil {
+				t.Fatal(err)
+			}
+			filters = append(filters, f)
+		}
+		if got := agentNames(FilterSessions(sessions, filters)); got != tt.want {
+			t.Errorf("FilterSessions(%v) = %s, want %s", tt.filters, got, tt.want)
+		}
+	}
+}
+
+func TestSortSessions(t *testing.T) {
+	sessions := []SessionInfo{
+		{AgentName: "sarah", Insertions: 5, Port: 3002, CreatedAt: "2025-03-09T09:00:00Z", UpdatedAt: "2025-03-09T10:00:00Z"},
+		{AgentName: "john", Insertions: 40, Deletions: 2, Port: 3001, CreatedAt: "2025-03-09T10:30:00+02:00", UpdatedAt: "2025-03-09T12:00:00Z"},
+		{AgentName: "emily", Port: 3000, CreatedAt: "2025-03-09T08:00:00Z", UpdatedAt: "2025-03-09T11:00:00Z"},
+	}
+	for key, want := range map[string]string{
+		"diff":    "john,sarah,emily",
+		"name":    "emily,john,sarah",
+		"port":    "emily,john,sarah",
+		"updated": "john,emily,sarah",
+		"age":     "emily,john,sarah",
+		"created": "sarah,john,emily",
+	} {
+		if err := SortSessions(sessions, key); err != nil {
+			t.Fatal(err)
+		}
+		if got := agentNames(sessions); got != want {
+			t.Errorf("SortSessions(%s) = %s, want %s", key, got, want)
+		}
+	}
+	if err := SortSessions(sessions, "size"); err == nil {
+		t.Error("Expected an unknown sort key to fail")
+	}
+}
+
+func agentNames(sessions []SessionInfo) string {
+	names := make([]string, len(sessions))
+	for i, s := range sessions {
+		names[i] = s.AgentName
+	}
+	return strings.Join(names, ",")
+}
+
+func TestAgo(t *testing.T) {
+	now := time.Date(2025, 3, 9, 12, 0, 0, 0, time.UTC)
+	for value, want := range map[string]string{
+		"2025-03-09T11:59:30Z":      "30s ago",
+		"2025-03-09T11:55:00Z":      "5m ago",
+		"2025-03-09T12:00:00+02:00": "2h ago",
+		"2025-03-07T12:00:00Z":      "2d ago",
+		"2025-03-09T12:05:00Z":      "0s ago",
+		"":                          "",
+		"yesterday":                 "",
+	} {
+		if got := Ago(value, now); got != want {
+			t.Errorf("Ago(%q) = %q, want %q", value, got, want)
+		}
+	}
+}
@@ -6,37 +6,48 @@ import (
 	"os"
 	"os/exec"
 	"path/filepath"
-	"regexp"
-	"strings"
 )
 
 // SessionInfo represents a session with all required display information
 type SessionInfo struct {
-	SessionName  string `json:"session_name"`
-	AgentName    string `json:"agent_name"`
-	Status       string `json:"status"`
-	DevServerURL string `json:"dev_server_url,omitempty"`
-	Model        string `json:"model"`
-	Prompt       string `json:"prompt"`
-	Insertions   int    `json:"insertions"`
-	Deletions    int    `json:"deletions"`
-	WorktreePath string `json:"worktree_path"`
-	CreatedAt    string `json:"created_at"`
-	UpdatedAt    string `json:"updated_at"`
+	SessionName     string     `json:"session_name"`
+	AgentName       string     `json:"agent_name"`
+	Status          string     `json:"status"`
+	DevServerURL    string     `json:"dev_server_url,omitempty"`
+	DevServerStatus string     `json:"dev_server_status,omitempty"` // "conflict" when another process holds the port
+	Model           string     `json:"model"`
+	Prompt          string     `json:"prompt"`
+	Insertions      int        `json:"insertions"`
+	Deletions       int        `json:"deletions"`
+	FilesChanged    int        `json:"files_changed,omitempty"`
+	WorktreePath    string     `json:"worktree_path"`
+	Port            int        `json:"port,omitempty"`
+	Deadline        string     `json:"deadline,omitempty"` // end of the --max-runtime budget
+	Tags            []string   `json:"tags,omitempty"`
+	Channels        []string   `json:"channels,omitempty"` // broadcast channels the session subscribes to
+	Run             string     `json:"run,omitempty"`      // run the session was spawned in
+	Host            string     `json:"host,omitempty"`     // remote host the session runs on; empty for local sessions
+	Paused          bool       `json:"paused,omitempty"`   // stopped by `uzi pause` until `uzi resume`
+	Done            bool       `json:"done,omitempty"`     // the agent signalled its task is complete
+	Files           []FileDiff `json:"files,omitempty"`    // per-file changes, filled in by WithFileDiffs
+	CreatedAt       string     `json:"created_at"`
+	UpdatedAt       string     `json:"updated_at"`
 }
 
 // StateReader provides functionality to read and parse state information
 type StateReader struct {
-	repoRoot  string
-	statePath string
+	repoRoot   string
+	statePath  string
+	aggregator *Aggregator
 }
 
 // NewStateReader creates a new StateReader for the given repository root
 func NewStateReader(repoRoot string) *StateReader {
 	statePath := filepath.Join(repoRoot, ".uzi", "state.json")
 	return &StateReader{
-		repoRoot:  repoRoot,
-		statePath: statePath,
+		repoRoot:   repoRoot,
+		statePath:  statePath,
+		aggregator: NewAggregator(WithDiffs(), WithDevURLs()),
 	}
 }
 
@@ -62,35 +73,9 @@ func (sr *StateReader) LoadSessions() ([]SessionInfo, error) {
 	// Convert to SessionInfo slice
 	var sessions []SessionInfo
 	for sessionName, agentState := range states {
-		// Extract agent name from session name
-		agentName := sr.extractAgentName(sessionName)
-
-		// Get session status
-		status := sr.getSessionStatus(sessionName)
-
-		// Get git diff stats
-		insertions, deletions := sr.getGitDiffStats(agentState.WorktreePath)
-
-		// Format dev server URL
-		devServerURL := ""
-		if agentState.Port != 0 {
-			devServerURL = fmt.Sprintf("http://localhost:%d", agentState.Port)
-		}
-
-		sessionInfo := SessionInfo{
-			SessionName:  sessionName,
-			AgentName:    agentName,
-			Status:       status,
-			DevServerURL: devServerURL,
-			Model:        agentState.Model,
-			Prompt:       agentState.Prompt,
-			Insertions:   insertions,
-			Deletions:    deletions,
-			WorktreePath: agentState.WorktreePath,
-			CreatedAt:    agentState.CreatedAt.Format("2006-01-02T15:04:05Z"),
-			UpdatedAt:    agentState.UpdatedAt.Format("2006-01-02T15:04:05Z"),
-		}
-
+		sessionInfo := sr.aggregator.Session(sessionName, agentState)
+		// Unlike `uzi ls`, the reader lists sessions whose tmux session is gone
+		sessionInfo.Status = sr.getSessionStatus(sessionName, agentState)
 		sessions = append(sessions, sessionInfo)
 	}
 
@@ -98,91 +83,33 @@ func (sr *StateReader) LoadSessions() ([]SessionInfo, error) {
 }
 
 // extractAgentName extracts the agent name from a session name
-// Session format: agent-projectDir-gitHash-agentName
 func (sr *StateReader) extractAgentName(sessionName string) string {
-	parts := strings.Split(sessionName, "-")
-	if len(parts) >= 4 && parts[0] == "agent" {
-		// Join all parts after the first 3 (in case agent name contains hyphens)
-		return strings.Join(parts[3:], "-")
-	}
-	return sessionName
+	return AgentNameFromSession(sessionName)
 }
 
-// getSessionStatus determines the current status of a session by checking tmux
-func (sr *StateReader) getSessionStatus(sessionName string) string {
+// getSessionStatus determines the current status of a session from tmux and its state
+func (sr *StateReader) getSessionStatus(sessionName string, agentState AgentState) string {
 	// First check if tmux session exists
 	checkCmd := exec.Command("tmux", "has-session", "-t", sessionName)
 	if err := checkCmd.Run(); err != nil {
-		return "inactive"
+		return StatusSignals{Alive: false}.Resolve()
 	}
-
-	// Get pane content to determine if agent is running
-	content, err := sr.getPaneContent(sessionName)
-	if err != nil {
-		return "unknown"
-	}
-
-	// Check for running indicators
-	if strings.Contains(content, "esc to interrupt") ||
-		strings.Contains(content, "Thinking") ||
-		strings.Contains(content, "Working") {
-		return "running"
-	}
-
-	return "ready"
+	return sr.aggregator.signals(sr.aggregator.probe, sessionName, agentState).Resolve()
 }
 
 // getPaneContent gets the content of a tmux pane
 func (sr *StateReader) getPaneContent(sessionName string) (string, error) {
-	cmd := exec.Command("tmux", "capture-pane", "-t", sessionName+":agent", "-p")
-	output, err := cmd.Output()
-	if err != nil {
-		return "", err
-	}
-	return string(output), nil
+	return sr.aggregator.probe.PaneContent(sessionName)
 }
 
 // getGitDiffStats calculates git diff statistics for a worktree
 func (sr *StateReader) getGitDiffStats(worktreePath string) (int, int) {
-	if worktreePath == "" {
-		return 0, 0
-	}
-
-	// Check if worktree path exists
-	if _, err := os.Stat(worktreePath); os.IsNotExist(err) {
-		return 0, 0
-	}
-
-	// Use git diff --stat to get changes
-	shellCmd := "git add -A . && git diff --cached --shortstat HEAD && git reset HEAD > /dev/null"
-	cmd := exec.Command("sh", "-c", shellCmd)
-	cmd.Dir = worktreePath
-
-	output, err := cmd.Output()
-	if err != nil {
-		return 0, 0
-	}
-
-	return sr.parseGitDiffStats(string(output))
+	return sr.aggregator.Diff(worktreePath)
 }
 
 // parseGitDiffStats parses git diff --shortstat output to extract insertions and deletions
 func (sr *StateReader) parseGitDiffStats(output string) (int, int) {
-	insertions := 0
-	deletions := 0
-
-	// Regex patterns to match insertions and deletions
-	insRe := regexp.MustCompile(`(\d+) insertion(?:s)?\(\+\)`)
-	delRe := regexp.MustCompile(`(\d+) deletion(?:s)?\(\-\)`)
-
-	if m := insRe.FindStringSubmatch(output); len(m) > 1 {
-		fmt.Sscanf(m[1], "%d", &insertions)
-	}
-	if m := delRe.FindStringSubmatch(output); len(m) > 1 {
-		fmt.Sscanf(m[1], "%d", &deletions)
-	}
-
-	return insertions, deletions
+	return ParseDiffStat(output)
 }
 
 // GetActiveSessions returns only sessions that are currently active in tmux
@@ -194,7 +121,7 @@ func (sr *StateReader) GetActiveSessions() ([]SessionInfo, error) {
 
 	var activeSessions []SessionInfo
 	for _, session := range allSessions {
-		if session.Status != "inactive" {
+		if session.Status != StatusDead {
 			activeSessions = append(activeSessions, session)
 		}
 	}
@@ -272,10 +272,10 @@ func TestGetSessionStatusFunctions(t *testing.T) {
 	reader := NewStateReader("/test")
 
 	// Test getSessionStatus with non-existent tmux session
-	// This will likely return "inactive" since tmux session doesn't exist
-	status := reader.getSessionStatus("non-existent-session")
-	if status != "inactive" {
-		t.Logf("getSessionStatus returned '%s' for non-existent session (expected 'inactive' but may vary)", status)
+	// This will likely return "dead" since tmux session doesn't exist
+	status := reader.getSessionStatus("non-existent-session", AgentState{})
+	if status != StatusDead {
+		t.Logf("getSessionStatus returned '%s' for non-existent session (expected 'dead' but may vary)", status)
 	}
 
 	// Test getPaneContent with non-existent session
@@ -5,22 +5,98 @@ import (
 	"fmt"
 	"os"
 	"path/filepath"
+	"regexp"
+	"sort"
 	"strings"
 	"time"
 
 	"github.com/charmbracelet/log"
 )
 
+// ModeShared marks a session that runs in the main checkout instead of its own
+// worktree and branch, such as a read-only reviewer. Shared sessions have no
+// worktree path and cannot be checkpointed.
+const ModeShared = "shared"
+
+// DevServerConflict marks a session whose dev server port is held by a process
+// outside the session, detected by `uzi auto`
+const DevServerConflict = "conflict"
+
 type AgentState struct {
-	GitRepo      string    `json:"git_repo"`
-	BranchFrom   string    `json:"branch_from"`
-	BranchName   string    `json:"branch_name"`
-	Prompt       string    `json:"prompt"`
-	WorktreePath string    `json:"worktree_path"`
-	Port         int       `json:"port,omitempty"`
-	Model        string    `json:"model"`
-	CreatedAt    time.Time `json:"created_at"`
-	UpdatedAt    time.Time `json:"updated_at"`
+	GitRepo         string        `json:"git_repo"`
+	BranchFrom      string        `json:"branch_from"`
+	BaseCommit      string        `json:"base_commit,omitempty"` // commit the worktree was pinned to with `uzi prompt --base-commit`, or the adopted branch's commit when it was adopted
+	Adopted         bool          `json:"adopted,omitempty"`     // created by `uzi adopt` on an existing branch, which outlives the session
+	BranchName      string        `json:"branch_name"`
+	Prompt          string        `json:"prompt"`
+	WorktreePath    string        `json:"worktree_path"`
+	Port            int           `json:"port,omitempty"`
+	DevServerStatus string        `json:"dev_server_status,omitempty"` // DevServerConflict, or empty when the port is fine
+	DevCommand      string        `json:"dev_command,omitempty"`       // dev server command from `uzi prompt --dev-command`; empty uses devCommand
+	Model           string        `json:"model"`
+	Mode            string        `json:"mode,omitempty"`
+	MaxRuntime      time.Duration `json:"max_runtime,omitempty"` // runtime budget in nanoseconds; zero means unlimited
+	Tags            []string      `json:"tags,omitempty"`
+	Channels        []string      `json:"channels,omitempty"`         // broadcast channels the session subscribes to
+	Run             string        `json:"run,omitempty"`              // run the session was spawned in with `uzi prompt --run-name`
+	Host            string        `json:"host,omitempty"`             // name of the remote host from uzi.yaml; empty for local sessions
+	SSH             string        `json:"ssh,omitempty"`              // ssh destination of the remote host the session runs on
+	Paused          bool          `json:"paused,omitempty"`           // stopped by `uzi pause`; skipped by broadcast and `uzi auto`
+	Done            bool          `json:"done,omitempty"`             // the agent signalled its task is complete
+	DoneAt          time.Time     `json:"done_at,omitzero"`           // when the agent last signalled completion
+	CheckpointedAt  time.Time     `json:"checkpointed_at,omitzero"`   // when `uzi checkpoint` last ran
+	CheckpointError string        `json:"checkpoint_error,omitempty"` // why the last checkpoint failed; empty if it succeeded
+	Snapshots       []string      `json:"snapshots,omitempty"`        // agent pane snapshots `uzi auto` saved on stuck, done, and dead transitions, oldest first
+	CreatedAt       time.Time     `json:"created_at"`
+	UpdatedAt       time.Time     `json:"updated_at"`
+}
+
+// HasTag reports whether the session is tagged with tag
+func (s AgentState) HasTag(tag string) bool {
+	for _, t := range s.Tags {
+		if t == tag {
+			return true
+		}
+	}
+	return false
+}
+
+// InChannel reports whether the session subscribes to the broadcast channel
+func (s AgentState) InChannel(channel string) bool {
+	for _, c := range s.Channels {
+		if c == channel {
+			return true
+		}
+	}
+	return false
+}
+
+// IsRemote reports whether the session runs on a remote host over ssh
+func (s AgentState) IsRemote() bool {
+	return s.SSH != ""
+}
+
+// IsShared reports whether the session runs in the main checkout without a worktree
+func (s AgentState) IsShared() bool {
+	return s.Mode == ModeShared
+}
+
+// DiffBase returns the revision the agent's changes are measured against: the
+// commit it was pinned to, or else the branch it was created from
+func (s AgentState) DiffBase() string {
+	if s.BaseCommit != "" {
+		return s.BaseCommit
+	}
+	return s.BranchFrom
+}
+
+// RuntimeDeadline returns when the session's runtime budget runs out, and false
+// if the session has no budget
+func (s AgentState) RuntimeDeadline() (time.Time, bool) {
+	if s.MaxRuntime <= 0 {
+		return time.Time{}, false
+	}
+	return s.CreatedAt.Add(s.MaxRuntime), true
 }
 
 type StateManager struct {
@@ -52,6 +128,15 @@ func NewStateManagerWithDeps(fs FileSystem, cmdExec CommandExecutor) *StateManag
 	}
 }
 
+// NewStateManagerAt creates a StateManager for the state file at statePath
+func NewStateManagerAt(statePath string, cmdExec CommandExecutor) *StateManager {
+	return &StateManager{
+		statePath: statePath,
+		fs:        NewDefaultFileSystem(),
+		cmdExec:   cmdExec,
+	}
+}
+
 func (sm *StateManager) ensureStateDir() error {
 	dir := filepath.Dir(sm.statePath)
 	return sm.fs.MkdirAll(dir, 0755)
@@ -90,34 +175,110 @@ func (sm *StateManager) isActiveInTmux(sessionName string) bool {
 	return err == nil
 }
 
+// isActive checks the session's tmux server, over ssh for remote sessions
+func (sm *StateManager) isActive(sessionName string, agentState AgentState) bool {
+	if agentState.IsRemote() {
+		return sm.cmdExec.RunCommand("ssh", "-o", "BatchMode=yes", agentState.SSH, "tmux", "has-session", "-t", sessionName) == nil
+	}
+	return sm.isActiveInTmux(sessionName)
+}
+
 func (sm *StateManager) GetActiveSessionsForRepo() ([]string, error) {
+	states, err := sm.activeStatesForRepo()
+	if err != nil {
+		return nil, err
+	}
+	activeSessions := []string{}
+	for sessionName := range states {
+		activeSessions = append(activeSessions, sessionName)
+	}
+	return activeSessions, nil
+}
+
+// GetRunningSessionsForRepo returns the active sessions of the current
+// repository whose agents have not signalled they are done. These are the
+// sessions that count against maxConcurrentAgents.
+func (sm *StateManager) GetRunningSessionsForRepo() ([]string, error) {
+	states, err := sm.activeStatesForRepo()
+	if err != nil {
+		return nil, err
+	}
+	running := []string{}
+	for sessionName, agentState := range states {
+		if !agentState.Done {
+			running = append(running, sessionName)
+		}
+	}
+	return running, nil
+}
+
+// SessionForAgent returns the active session of the current repository that
+// runs the named agent
+func (sm *StateManager) SessionForAgent(agentName string) (string, error) {
+	activeSessions, err := sm.GetActiveSessionsForRepo()
+	if err != nil {
+		return "", fmt.Errorf("error getting active sessions: %w", err)
+	}
+	return FindAgentSession(activeSessions, agentName)
+}
+
+// FindAgentSession returns the session among sessions that runs the named
+// agent, for callers that already listed the active sessions
+func FindAgentSession(sessions []string, agentName string) (string, error) {
+	for _, session := range sessions {
+		if AgentNameFromSession(session) == agentName {
+			return session, nil
+		}
+	}
+	return "", fmt.Errorf("no active session found for agent: %s", agentName)
+}
+
+// GitRepo returns the origin remote of the current repository, which the
+// sessions spawned from it are saved with
+func (sm *StateManager) GitRepo() string {
+	return sm.getGitRepo()
+}
+
+// StatesForRepo returns the states of every session of the current
+// repository, including sessions whose tmux session is gone
+func (sm *StateManager) StatesForRepo() (map[string]AgentState, error) {
+	return sm.statesForRepo(false)
+}
+
+// activeStatesForRepo loads the states of the current repository's sessions
+// whose tmux session is still running
+func (sm *StateManager) activeStatesForRepo() (map[string]AgentState, error) {
+	return sm.statesForRepo(true)
+}
+
+// statesForRepo loads the states of the current repository's sessions, only
+// those whose tmux session is still running if activeOnly is set
+func (sm *StateManager) statesForRepo(activeOnly bool) (map[string]AgentState, error) {
 	// Load existing state using injected filesystem
 	states := make(map[string]AgentState)
 	data, err := sm.fs.ReadFile(sm.statePath)
 	if err != nil {
 		if os.IsNotExist(err) {
-			return []string{}, nil
+			return states, nil
 		}
 		return nil, err
 	}
 
-	if err := json.Unmarshal(data, &states); err != nil {
+	if err := sm.parseStates(data, states); err != nil {
 		return nil, err
 	}
 
+	active := make(map[string]AgentState)
 	currentRepo := sm.getGitRepo()
 	if currentRepo == "" {
-		return []string{}, nil
+		return active, nil
 	}
-
-	var activeSessions []string
 	for sessionName, state := range states {
-		if state.GitRepo == currentRepo && sm.isActiveInTmux(sessionName) {
-			activeSessions = append(activeSessions, sessionName)
+		if state.GitRepo == currentRepo && (!activeOnly || sm.isActive(sessionName, state)) {
+			active[sessionName] = state
 		}
 	}
-
-	return activeSessions, nil
+	return active, nil
 }
 
 func (sm *StateManager) SaveState(prompt, branchName, sessionName, worktreePath, model string) error {
@@ -125,6 +286,18 @@ func (sm *StateManager) SaveState(prompt, branchName, sessionName, worktreePath,
 }
 
 func (sm *StateManager) SaveStateWithPort(prompt, branchName, sessionName, worktreePath, model string, port int) error {
+	return sm.SaveStateWithBase(prompt, branchName, sessionName, worktreePath, model, port, "")
+}
+
+// SaveStateWithBase saves agent state recording the branch the worktree was created from.
+// An empty branchFrom falls back to the repository's default branch.
+func (sm *StateManager) SaveStateWithBase(prompt, branchName, sessionName, worktreePath, model string, port int, branchFrom string) error {
+	return sm.SaveStateWithMode(prompt, branchName, sessionName, worktreePath, model, port, branchFrom, "")
+}
+
+// SaveStateWithMode saves agent state with a session mode such as ModeShared.
+// An empty mode is a regular worktree session.
+func (sm *StateManager) SaveStateWithMode(prompt, branchName, sessionName, worktreePath, model string, port int, branchFrom, mode string) error {
 	if err := sm.ensureStateDir(); err != nil {
 		return err
 	}
@@ -135,16 +308,21 @@ func (sm *StateManager) SaveStateWithPort(prompt, branchName, sessionName, workt
 		json.Unmarshal(data, &states)
 	}
 
+	if branchFrom == "" {
+		branchFrom = sm.getBranchFrom()
+	}
+
 	// Create new state entry
 	now := time.Now()
 	agentState := AgentState{
 		GitRepo:      sm.getGitRepo(),
-		BranchFrom:   sm.getBranchFrom(),
+		BranchFrom:   branchFrom,
 		BranchName:   branchName,
 		Prompt:       prompt,
 		WorktreePath: worktreePath,
 		Port:         port,
 		Model:        model,
+		Mode:         mode,
 		UpdatedAt:    now,
 	}
 
@@ -162,13 +340,7 @@ func (sm *StateManager) SaveStateWithPort(prompt, branchName, sessionName, workt
 		log.Error("Error storing worktree branch", "error", err)
 	}
 
-	// Save to file using injected filesystem
-	data, err := json.MarshalIndent(states, "", "  ")
-	if err != nil {
-		return err
-	}
-
-	return sm.fs.WriteFile(sm.statePath, data, 0644)
+	return sm.writeStates(states)
 }
 
 // getCurrentBranch uses injected CommandExecutor for testability
@@ -209,7 +381,7 @@ func (sm *StateManager) GetStatePath() string {
 func (sm *StateManager) RemoveState(sessionName string) error {
 	// Load existing state
 	states := make(map[string]AgentState)
-	if data, err := os.ReadFile(sm.statePath); err != nil {
+	if data, err := sm.fs.ReadFile(sm.statePath); err != nil {
 		if os.IsNotExist(err) {
 			return nil // No state file, nothing to remove
 		}
@@ -223,24 +395,257 @@ func (sm *StateManager) RemoveState(sessionName string) error {
 	// Remove the session from the state
 	delete(states, sessionName)
 
-	// Save updated state to file
-	data, err := json.MarshalIndent(states, "", "  ")
+	return sm.writeStates(states)
+}
+
+// RestoreState saves the state of a session brought back after a kill, such
+// as by `uzi undo`. The agent starts over, so it is no longer paused or done.
+func (sm *StateManager) RestoreState(sessionName string, agentState AgentState) error {
+	if err := sm.ensureStateDir(); err != nil {
+		return err
+	}
+	states := make(map[string]AgentState)
+	if data, err := sm.fs.ReadFile(sm.statePath); err == nil {
+		if err := sm.parseStates(data, states); err != nil {
+			return err
+		}
+	} else if !os.IsNotExist(err) {
+		return err
+	}
+	if _, exists := states[sessionName]; exists {
+		return fmt.Errorf("session %s already exists", sessionName)
+	}
+
+	agentState.Paused = false
+	agentState.Done = false
+	agentState.DoneAt = time.Time{}
+	agentState.DevServerStatus = ""
+	agentState.UpdatedAt = time.Now()
+	states[sessionName] = agentState
+
+	return sm.writeStates(states)
+}
+
+// SetMaxRuntime sets the runtime budget of an existing session
+func (sm *StateManager) SetMaxRuntime(sessionName string, maxRuntime time.Duration) error {
+	return sm.updateAgentState(sessionName, func(s *AgentState) {
+		s.MaxRuntime = maxRuntime
+	})
+}
+
+// SetBaseCommit records the commit an existing session's worktree was pinned to
+func (sm *StateManager) SetBaseCommit(sessionName, commit string) error {
+	return sm.updateAgentState(sessionName, func(s *AgentState) {
+		s.BaseCommit = commit
+	})
+}
+
+// SetAdopted marks an existing session as working on a branch it adopted,
+// so the branch is kept when the session is killed or purged
+func (sm *StateManager) SetAdopted(sessionName string, adopted bool) error {
+	return sm.updateAgentState(sessionName, func(s *AgentState) {
+		s.Adopted = adopted
+	})
+}
+
+// SetDevCommand records the dev server command an existing session was
+// spawned with, which restarts of its dev server reuse
+func (sm *StateManager) SetDevCommand(sessionName, devCommand string) error {
+	return sm.updateAgentState(sessionName, func(s *AgentState) {
+		s.DevCommand = devCommand
+	})
+}
+
+// SetTags replaces the tags of an existing session
+func (sm *StateManager) SetTags(sessionName string, tags []string) error {
+	return sm.updateAgentState(sessionName, func(s *AgentState) {
+		s.Tags = tags
+	})
+}
+
+// SetChannels replaces the broadcast channels an existing session subscribes to
+func (sm *StateManager) SetChannels(sessionName string, channels []string) error {
+	return sm.updateAgentState(sessionName, func(s *AgentState) {
+		s.Channels = channels
+	})
+}
+
+// SetPaused marks an existing session as paused or resumed
+func (sm *StateManager) SetPaused(sessionName string, paused bool) error {
+	return sm.updateAgentState(sessionName, func(s *AgentState) {
+		s.Paused = paused
+	})
+}
+
+// SetDone marks an existing session as done when its agent signals completion,
+// or clears the mark when the signal goes away
+func (sm *StateManager) SetDone(sessionName string, done bool) error {
+	return sm.updateAgentState(sessionName, func(s *AgentState) {
+		if done && !s.Done {
+			s.DoneAt = time.Now()
+		}
+		s.Done = done
+	})
+}
+
+// AddSnapshot records a snapshot of an existing session's agent pane
+func (sm *StateManager) AddSnapshot(sessionName, path string) error {
+	return sm.updateAgentState(sessionName, func(s *AgentState) {
+		s.Snapshots = append(s.Snapshots, path)
+	})
+}
+
+// SetCheckpointResult records the outcome of checkpointing an existing
+// session: when it was attempted and the error, nil if it succeeded
+func (sm *StateManager) SetCheckpointResult(sessionName string, checkpointErr error) error {
+	return sm.updateAgentState(sessionName, func(s *AgentState) {
+		s.CheckpointedAt = time.Now()
+		s.CheckpointError = ""
+		if checkpointErr != nil {
+			s.CheckpointError = checkpointErr.Error()
+		}
+	})
+}
+
+// SetHost records the remote host an existing session was spawned on
+func (sm *StateManager) SetHost(sessionName, host, ssh string) error {
+	return sm.updateAgentState(sessionName, func(s *AgentState) {
+		s.Host = host
+		s.SSH = ssh
+	})
+}
+
+// SetWorktreePath records where an existing session's worktree was moved to
+func (sm *StateManager) SetWorktreePath(sessionName, worktreePath string) error {
+	return sm.updateAgentState(sessionName, func(s *AgentState) {
+		s.WorktreePath = worktreePath
+	})
+}
+
+// SetPort records the dev server port of an existing session and clears any
+// conflict recorded for the previous port
+func (sm *StateManager) SetPort(sessionName string, port int) error {
+	return sm.updateAgentState(sessionName, func(s *AgentState) {
+		s.Port = port
+		s.DevServerStatus = ""
+	})
+}
+
+// SetDevServerStatus records the dev server status of an existing session
+func (sm *StateManager) SetDevServerStatus(sessionName, status string) error {
+	return sm.updateAgentState(sessionName, func(s *AgentState) {
+		s.DevServerStatus = status
+	})
+}
+
+// SetRun records the run an existing session was spawned in
+func (sm *StateManager) SetRun(sessionName, run string) error {
+	return sm.updateAgentState(sessionName, func(s *AgentState) {
+		s.Run = run
+	})
+}
+
+// SessionsInRun returns the names of all sessions spawned in run, sorted
+func (sm *StateManager) SessionsInRun(run string) ([]string, error) {
+	runs, err := sm.Runs()
 	if err != nil {
+		return nil, err
+	}
+	return runs[run], nil
+}
+
+// Runs returns the names of the sessions of every run, sorted, by run name
+func (sm *StateManager) Runs() (map[string][]string, error) {
+	states := make(map[string]AgentState)
+	data, err := sm.fs.ReadFile(sm.statePath)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return map[string][]string{}, nil
+		}
+		return nil, fmt.Errorf("error reading state file: %w", err)
+	}
+	if err := json.Unmarshal(data, &states); err != nil {
+		return nil, fmt.Errorf("error parsing state file: %w", err)
+	}
+
+	runs := make(map[string][]string)
+	for sessionName, agentState := range states {
+		if agentState.Run != "" {
+			runs[agentState.Run] = append(runs[agentState.Run], sessionName)
+		}
+	}
+	for _, sessions := range runs {
+		sort.Strings(sessions)
+	}
+	return runs, nil
+}
+
+// runNameRe matches run names: like channel names, they go on the command
+// line and in TUI group headers
+var runNameRe = regexp.MustCompile(`^[A-Za-z0-9][A-Za-z0-9_.-]*$`)
+
+// ValidateRunName checks a run name: letters, digits, '.', '_' and '-'
+func ValidateRunName(name string) error {
+	if !runNameRe.MatchString(name) {
+		return fmt.Errorf("invalid run name %q: use letters, digits, '.', '_' and '-'", name)
+	}
+	return nil
+}
+
+// SessionsWithTag returns the names of all sessions tagged with tag, sorted
+func (sm *StateManager) SessionsWithTag(tag string) ([]string, error) {
+	states := make(map[string]AgentState)
+	data, err := sm.fs.ReadFile(sm.statePath)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("error reading state file: %w", err)
+	}
+	if err := json.Unmarshal(data, &states); err != nil {
+		return nil, fmt.Errorf("error parsing state file: %w", err)
+	}
+
+	var sessions []string
+	for sessionName, agentState := range states {
+		if agentState.HasTag(tag) {
+			sessions = append(sessions, sessionName)
+		}
+	}
+	sort.Strings(sessions)
+	return sessions, nil
+}
+
+// updateAgentState applies update to the stored state of an existing session
+func (sm *StateManager) updateAgentState(sessionName string, update func(*AgentState)) error {
+	states := make(map[string]AgentState)
+	data, err := sm.fs.ReadFile(sm.statePath)
+	if err != nil {
+		return fmt.Errorf("error reading state file: %w", err)
+	}
+	if err := sm.parseStates(data, states); err != nil {
 		return err
 	}
 
-	return os.WriteFile(sm.statePath, data, 0644)
+	agentState, ok := states[sessionName]
+	if !ok {
+		return fmt.Errorf("no state found for session: %s", sessionName)
+	}
+	update(&agentState)
+	states[sessionName] = agentState
+
+	return sm.writeStates(states)
 }
 
 // GetWorktreeInfo returns the worktree information for a given session
 func (sm *StateManager) GetWorktreeInfo(sessionName string) (*AgentState, error) {
 	// Load existing state
 	states := make(map[string]AgentState)
-	if data, err := os.ReadFile(sm.statePath); err != nil {
+	if data, err := sm.fs.ReadFile(sm.statePath); err != nil {
 		return nil, fmt.Errorf("error reading state file: %w", err)
 	} else {
-		if err := json.Unmarshal(data, &states); err != nil {
-			return nil, fmt.Errorf("error parsing state file: %w", err)
+		if err := sm.parseStates(data, states); err != nil {
+			return nil, err
 		}
 	}
 
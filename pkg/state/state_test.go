@@ -2,6 +2,7 @@ package state
 
 import (
 	"encoding/json"
+	"fmt"
 	"os"
 	"path/filepath"
 	"strings"
@@ -156,6 +157,469 @@ func TestSaveStateWithPort(t *testing.T) {
 	}
 }
 
+func TestSaveStateWithBase(t *testing.T) {
+	tmpDir := t.TempDir()
+	sm := &StateManager{
+		statePath: filepath.Join(tmpDir, "state.json"),
+		fs:        NewDefaultFileSystem(),
+		cmdExec:   &DefaultCommandExecutor{},
+	}
+
+	err := sm.SaveStateWithBase("test prompt", "feature-x", "test-session", "/test/path", "test-model", 3000, "feature-x")
+	if err != nil {
+		t.Errorf("Expected SaveStateWithBase to succeed, got: %v", err)
+	}
+
+	data, err := os.ReadFile(sm.statePath)
+	if err != nil {
+		t.Errorf("Expected to read state file, got: %v", err)
+	}
+
+	var states map[string]AgentState
+	err = json.Unmarshal(data, &states)
+	if err != nil {
+		t.Errorf("Expected to parse state JSON, got: %v", err)
+	}
+
+	state := states["test-session"]
+	if state.BranchFrom != "feature-x" {
+		t.Errorf("Expected branch_from feature-x, got %s", state.BranchFrom)
+	}
+	if state.Port != 3000 {
+		t.Errorf("Expected port 3000, got %d", state.Port)
+	}
+}
+
+func TestSaveStateWithMode(t *testing.T) {
+	tmpDir := t.TempDir()
+	sm := &StateManager{
+		statePath: filepath.Join(tmpDir, "state.json"),
+		fs:        NewDefaultFileSystem(),
+		cmdExec:   &DefaultCommandExecutor{},
+	}
+
+	if err := sm.SaveStateWithMode("review it", "", "review-session", "", "claude", 0, "main", ModeShared); err != nil {
+		t.Fatalf("Expected SaveStateWithMode to succeed, got: %v", err)
+	}
+	if err := sm.SaveStateWithBase("build it", "feature-x", "worktree-session", "/test/path", "claude", 0, "main"); err != nil {
+		t.Fatalf("Expected SaveStateWithBase to succeed, got: %v", err)
+	}
+
+	data, err := os.ReadFile(sm.statePath)
+	if err != nil {
+		t.Fatalf("Expected to read state file, got: %v", err)
+	}
+
+	var states map[string]AgentState
+	if err := json.Unmarshal(data, &states); err != nil {
+		t.Fatalf("Expected to parse state JSON, got: %v", err)
+	}
+
+	if shared := states["review-session"]; !shared.IsShared() || shared.WorktreePath != "" {
+		t.Errorf("Expected shared session without worktree, got %+v", shared)
+	}
+	if regular := states["worktree-session"]; regular.IsShared() || regular.Mode != "" {
+		t.Errorf("Expected regular worktree session, got %+v", regular)
+	}
+}
+
+func TestSetMaxRuntime(t *testing.T) {
+	tmpDir := t.TempDir()
+	sm := &StateManager{
+		statePath: filepath.Join(tmpDir, "state.json"),
+		fs:        NewDefaultFileSystem(),
+		cmdExec:   &DefaultCommandExecutor{},
+	}
+
+	if err := sm.SetMaxRuntime("missing", time.Hour); err == nil {
+		t.Error("Expected error without a state file")
+	}
+
+	if err := sm.SaveState("build it", "feature-x", "budget-session", "/test/path", "claude"); err != nil {
+		t.Fatalf("Expected SaveState to succeed, got: %v", err)
+	}
+	if err := sm.SetMaxRuntime("budget-session", 2*time.Hour); err != nil {
+		t.Fatalf("Expected SetMaxRuntime to succeed, got: %v", err)
+	}
+	if err := sm.SetMaxRuntime("missing", time.Hour); err == nil {
+		t.Error("Expected error for unknown session")
+	}
+
+	info, err := sm.GetWorktreeInfo("budget-session")
+	if err != nil {
+		t.Fatalf("Expected GetWorktreeInfo to succeed, got: %v", err)
+	}
+	deadline, ok := info.RuntimeDeadline()
+	if !ok || !deadline.Equal(info.CreatedAt.Add(2*time.Hour)) {
+		t.Errorf("Expected deadline 2h after creation, got %v (ok=%v)", deadline, ok)
+	}
+
+	if _, ok := (AgentState{}).RuntimeDeadline(); ok {
+		t.Error("Expected no deadline without a runtime budget")
+	}
+}
+
+// recordingExecutor records RunCommand calls and reports every session as alive
+type recordingExecutor struct {
+	runs []string
+}
+
+func (r *recordingExecutor) ExecuteCommand(name string, args ...string) ([]byte, error) {
+	return nil, nil
+}
+
+func (r *recordingExecutor) RunCommand(name string, args ...string) error {
+	r.runs = append(r.runs, name+" "+strings.Join(args, " "))
+	return nil
+}
+
+func TestSetHost(t *testing.T) {
+	tmpDir := t.TempDir()
+	executor := &recordingExecutor{}
+	sm := &StateManager{
+		statePath: filepath.Join(tmpDir, "state.json"),
+		fs:        NewDefaultFileSystem(),
+		cmdExec:   executor,
+	}
+
+	if err := sm.SaveState("fix it", "branch", "remote-session", "/home/dev/worktrees/a", "claude"); err != nil {
+		t.Fatalf("Expected SaveState to succeed, got: %v", err)
+	}
+	if err := sm.SetHost("remote-session", "box", "dev@box"); err != nil {
+		t.Fatalf("Expected SetHost to succeed, got: %v", err)
+	}
+	info, err := sm.GetWorktreeInfo("remote-session")
+	if err != nil {
+		t.Fatalf("Expected GetWorktreeInfo to succeed, got: %v", err)
+	}
+	if !info.IsRemote() || info.Host != "box" || info.SSH != "dev@box" {
+		t.Errorf("Expected the session recorded on box, got %+v", info)
+	}
+
+	if !sm.isActive("remote-session", *info) {
+		t.Error("Expected the remote session to be active")
+	}
+	if want := "ssh -o BatchMode=yes dev@box tmux has-session -t remote-session"; len(executor.runs) != 1 || executor.runs[0] != want {
+		t.Errorf("Expected the remote tmux server checked over ssh, got %v", executor.runs)
+	}
+}
+
+func TestSetWorktreePath(t *testing.T) {
+	sm := &StateManager{
+		statePath: filepath.Join(t.TempDir(), "state.json"),
+		fs:        NewDefaultFileSystem(),
+		cmdExec:   &DefaultCommandExecutor{},
+	}
+
+	if err := sm.SetWorktreePath("missing", "/new/worktrees/a"); err == nil {
+		t.Error("Expected an error for an unknown session")
+	}
+	if err := sm.SaveState("fix it", "branch", "session", "/old/worktrees/a", "claude"); err != nil {
+		t.Fatalf("Expected SaveState to succeed, got: %v", err)
+	}
+	if err := sm.SetWorktreePath("session", "/new/worktrees/a"); err != nil {
+		t.Fatalf("Expected SetWorktreePath to succeed, got: %v", err)
+	}
+	info, err := sm.GetWorktreeInfo("session")
+	if err != nil || info.WorktreePath != "/new/worktrees/a" || info.Prompt != "fix it" {
+		t.Errorf("Expected only the worktree path updated, got %+v, %v", info, err)
+	}
+}
+
+func TestSetDevServerStatus(t *testing.T) {
+	sm := &StateManager{
+		statePath: filepath.Join(t.TempDir(), "state.json"),
+		fs:        NewDefaultFileSystem(),
+		cmdExec:   &DefaultCommandExecutor{},
+	}
+
+	if err := sm.SaveStateWithPort("fix it", "branch", "session", "/worktrees/a", "claude", 3000); err != nil {
+		t.Fatalf("Expected SaveStateWithPort to succeed, got: %v", err)
+	}
+	if err := sm.SetDevServerStatus("session", DevServerConflict); err != nil {
+		t.Fatalf("Expected SetDevServerStatus to succeed, got: %v", err)
+	}
+	info, err := sm.GetWorktreeInfo("session")
+	if err != nil || info.DevServerStatus != DevServerConflict || info.Port != 3000 {
+		t.Fatalf("Expected the conflict recorded, got %+v, %v", info, err)
+	}
+
+	// Moving to a new port clears the conflict
+	if err := sm.SetPort("session", 3001); err != nil {
+		t.Fatalf("Expected SetPort to succeed, got: %v", err)
+	}
+	info, err = sm.GetWorktreeInfo("session")
+	if err != nil || info.DevServerStatus != "" || info.Port != 3001 {
+		t.Errorf("Expected the new port without a conflict, got %+v, %v", info, err)
+	}
+}
+
+func TestSetDevCommand(t *testing.T) {
+	tmpDir := t.TempDir()
+	sm := &StateManager{
+		statePath: filepath.Join(tmpDir, "state.json"),
+		fs:        NewDefaultFileSystem(),
+		cmdExec:   &DefaultCommandExecutor{},
+	}
+
+	if err := sm.SaveState("build the storybook", "feature-x", "storybook-session", "/test/path", "claude"); err != nil {
+		t.Fatalf("Expected SaveState to succeed, got: %v", err)
+	}
+	if err := sm.SetDevCommand("storybook-session", "npm run storybook -- --port $PORT"); err != nil {
+		t.Fatalf("Expected SetDevCommand to succeed, got: %v", err)
+	}
+	if err := sm.SetDevCommand("missing", "npm run dev"); err == nil {
+		t.Error("Expected error for unknown session")
+	}
+
+	info, err := sm.GetWorktreeInfo("storybook-session")
+	if err != nil {
+		t.Fatalf("Expected GetWorktreeInfo to succeed, got: %v", err)
+	}
+	if info.DevCommand != "npm run storybook -- --port $PORT" {
+		t.Errorf("Expected the dev command to be saved, got %q", info.DevCommand)
+	}
+}
+
+func TestSetBaseCommit(t *testing.T) {
+	tmpDir := t.TempDir()
+	sm := &StateManager{
+		statePath: filepath.Join(tmpDir, "state.json"),
+		fs:        NewDefaultFileSystem(),
+		cmdExec:   &DefaultCommandExecutor{},
+	}
+
+	if err := sm.SaveStateWithBase("compare parsers", "agent-a", "pinned-session", "/test/path", "claude", 0, "main"); err != nil {
+		t.Fatalf("Expected SaveStateWithBase to succeed, got: %v", err)
+	}
+	info, _ := sm.GetWorktreeInfo("pinned-session")
+	if info.DiffBase() != "main" {
+		t.Errorf("Expected an unpinned session measured against its branch, got %q", info.DiffBase())
+	}
+
+	commit := "3f2a9c1e8b7d6a5f4e3d2c1b0a9f8e7d6c5b4a39"
+	if err := sm.SetBaseCommit("pinned-session", commit); err != nil {
+		t.Fatalf("Expected SetBaseCommit to succeed, got: %v", err)
+	}
+	if err := sm.SetBaseCommit("missing", commit); err == nil {
+		t.Error("Expected error for unknown session")
+	}
+	info, _ = sm.GetWorktreeInfo("pinned-session")
+	if info.BaseCommit != commit || info.BranchFrom != "main" || info.DiffBase() != commit {
+		t.Errorf("Expected the session pinned to %s and still from main, got %+v", commit, info)
+	}
+}
+
+func TestSetAdopted(t *testing.T) {
+	tmpDir := t.TempDir()
+	sm := &StateManager{
+		statePath: filepath.Join(tmpDir, "state.json"),
+		fs:        NewDefaultFileSystem(),
+		cmdExec:   &DefaultCommandExecutor{},
+	}
+
+	if err := sm.SaveStateWithBase("keep going", "feature-x", "adopted-session", "/test/path", "claude", 0, "feature-x"); err != nil {
+		t.Fatalf("Expected SaveStateWithBase to succeed, got: %v", err)
+	}
+	if info, _ := sm.GetWorktreeInfo("adopted-session"); info.Adopted {
+		t.Error("Expected a new session not to be adopted")
+	}
+	if err := sm.SetAdopted("adopted-session", true); err != nil {
+		t.Fatalf("Expected SetAdopted to succeed, got: %v", err)
+	}
+	if err := sm.SetAdopted("missing", true); err == nil {
+		t.Error("Expected error for unknown session")
+	}
+	if info, _ := sm.GetWorktreeInfo("adopted-session"); !info.Adopted || info.BranchName != "feature-x" {
+		t.Errorf("Expected the session marked adopted, got %+v", info)
+	}
+}
+
+func TestSetTags(t *testing.T) {
+	tmpDir := t.TempDir()
+	sm := &StateManager{
+		statePath: filepath.Join(tmpDir, "state.json"),
+		fs:        NewDefaultFileSystem(),
+		cmdExec:   &DefaultCommandExecutor{},
+	}
+
+	if sessions, err := sm.SessionsWithTag("github#1"); err != nil || len(sessions) != 0 {
+		t.Errorf("Expected no tagged sessions without a state file, got %v, %v", sessions, err)
+	}
+
+	for _, session := range []string{"session-b", "session-a", "session-c"} {
+		if err := sm.SaveState("fix it", "branch", session, "/test/path", "claude"); err != nil {
+			t.Fatalf("Expected SaveState to succeed, got: %v", err)
+		}
+	}
+	if err := sm.SetTags("session-b", []string{"github#1"}); err != nil {
+		t.Fatalf("Expected SetTags to succeed, got: %v", err)
+	}
+	if err := sm.SetTags("session-a", []string{"github#1", "urgent"}); err != nil {
+		t.Fatalf("Expected SetTags to succeed, got: %v", err)
+	}
+	if err := sm.SetTags("missing", []string{"github#1"}); err == nil {
+		t.Error("Expected error for unknown session")
+	}
+
+	sessions, err := sm.SessionsWithTag("github#1")
+	if err != nil {
+		t.Fatalf("Expected SessionsWithTag to succeed, got: %v", err)
+	}
+	if strings.Join(sessions, ",") != "session-a,session-b" {
+		t.Errorf("Expected session-a,session-b, got %v", sessions)
+	}
+
+	info, err := sm.GetWorktreeInfo("session-a")
+	if err != nil {
+		t.Fatalf("Expected GetWorktreeInfo to succeed, got: %v", err)
+	}
+	if !info.HasTag("urgent") || info.HasTag("github#2") {
+		t.Errorf("Unexpected tags %v", info.Tags)
+	}
+}
+
+func TestSetRun(t *testing.T) {
+	tmpDir := t.TempDir()
+	sm := &StateManager{
+		statePath: filepath.Join(tmpDir, "state.json"),
+		fs:        NewDefaultFileSystem(),
+		cmdExec:   &DefaultCommandExecutor{},
+	}
+
+	if runs, err := sm.Runs(); err != nil || len(runs) != 0 {
+		t.Errorf("Expected no runs without a state file, got %v, %v", runs, err)
+	}
+
+	for _, session := range []string{"session-b", "session-a", "session-c"} {
+		if err := sm.SaveState("fix it", "branch", session, "/test/path", "claude"); err != nil {
+			t.Fatalf("Expected SaveState to succeed, got: %v", err)
+		}
+	}
+	for _, session := range []string{"session-b", "session-a"} {
+		if err := sm.SetRun(session, "auth-refactor"); err != nil {
+			t.Fatalf("Expected SetRun to succeed, got: %v", err)
+		}
+	}
+	if err := sm.SetRun("missing", "auth-refactor"); err == nil {
+		t.Error("Expected error for unknown session")
+	}
+
+	sessions, err := sm.SessionsInRun("auth-refactor")
+	if err != nil {
+		t.Fatalf("Expected SessionsInRun to succeed, got: %v", err)
+	}
+	if strings.Join(sessions, ",") != "session-a,session-b" {
+		t.Errorf("Expected session-a,session-b, got %v", sessions)
+	}
+	if runs, _ := sm.Runs(); len(runs) != 1 {
+		t.Errorf("Expected one run, got %v", runs)
+	}
+
+	for _, name := range []string{"auth-refactor", "exp.2", "v1_b"} {
+		if err := ValidateRunName(name); err != nil {
+			t.Errorf("ValidateRunName(%q) = %v", name, err)
+		}
+	}
+	for _, name := range []string{"", "-x", "auth refactor", "a/b"} {
+		if err := ValidateRunName(name); err == nil {
+			t.Errorf("Expected ValidateRunName(%q) to fail", name)
+		}
+	}
+}
+
+func TestSetDoneAndCheckpointResult(t *testing.T) {
+	tmpDir := t.TempDir()
+	sm := &StateManager{
+		statePath: filepath.Join(tmpDir, "state.json"),
+		fs:        NewDefaultFileSystem(),
+		cmdExec:   &DefaultCommandExecutor{},
+	}
+	if err := sm.SaveState("fix it", "branch", "session", "/test/path", "claude"); err != nil {
+		t.Fatalf("Expected SaveState to succeed, got: %v", err)
+	}
+
+	if err := sm.SetDone("session", true); err != nil {
+		t.Fatalf("Expected SetDone to succeed, got: %v", err)
+	}
+	info, _ := sm.GetWorktreeInfo("session")
+	doneAt := info.DoneAt
+	if doneAt.IsZero() {
+		t.Fatal("Expected the completion time recorded")
+	}
+	// Repeated signals keep the first completion time
+	sm.SetDone("session", true)
+	if info, _ = sm.GetWorktreeInfo("session"); !info.DoneAt.Equal(doneAt) {
+		t.Errorf("Expected DoneAt to stay %v, got %v", doneAt, info.DoneAt)
+	}
+
+	if err := sm.SetCheckpointResult("session", fmt.Errorf("rebase conflict")); err != nil {
+		t.Fatalf("Expected SetCheckpointResult to succeed, got: %v", err)
+	}
+	if info, _ = sm.GetWorktreeInfo("session"); info.CheckpointedAt.IsZero() || info.CheckpointError != "rebase conflict" {
+		t.Errorf("Expected the failed checkpoint recorded, got %+v", info)
+	}
+	sm.SetCheckpointResult("session", nil)
+	if info, _ = sm.GetWorktreeInfo("session"); info.CheckpointError != "" {
+		t.Errorf("Expected a successful checkpoint to clear the error, got %q", info.CheckpointError)
+	}
+	if err := sm.SetCheckpointResult("missing", nil); err == nil {
+		t.Error("Expected error for unknown session")
+	}
+}
+
+func TestRestoreState(t *testing.T) {
+	sm := &StateManager{
+		statePath: filepath.Join(t.TempDir(), "state.json"),
+		fs:        NewDefaultFileSystem(),
+		cmdExec:   &DefaultCommandExecutor{},
+	}
+	created := time.Now().Add(-time.Hour)
+	killed := AgentState{Prompt: "fix it", BranchName: "branch", WorktreePath: "/test/path", Model: "claude", Tags: []string{"issue-12"},
+		Paused: true, Done: true, DoneAt: time.Now(), CreatedAt: created}
+	if err := sm.RestoreState("session", killed); err != nil {
+		t.Fatalf("Expected RestoreState to succeed without a state file, got: %v", err)
+	}
+	info, err := sm.GetWorktreeInfo("session")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if info.Paused || info.Done || !info.DoneAt.IsZero() {
+		t.Errorf("Expected the restored agent to start over, got %+v", info)
+	}
+	if info.Prompt != "fix it" || info.WorktreePath != "/test/path" || !info.HasTag("issue-12") || !info.CreatedAt.Equal(created) {
+		t.Errorf("Expected the saved state kept, got %+v", info)
+	}
+	if err := sm.RestoreState("session", killed); err == nil {
+		t.Error("Expected restoring over a live session to fail")
+	}
+}
+
+func TestSetChannels(t *testing.T) {
+	sm := &StateManager{
+		statePath: filepath.Join(t.TempDir(), "state.json"),
+		fs:        NewDefaultFileSystem(),
+		cmdExec:   &DefaultCommandExecutor{},
+	}
+	if err := sm.SaveState("fix it", "branch", "session-a", "/test/path", "claude"); err != nil {
+		t.Fatalf("Expected SaveState to succeed, got: %v", err)
+	}
+	if err := sm.SetChannels("session-a", []string{"frontend"}); err != nil {
+		t.Fatalf("Expected SetChannels to succeed, got: %v", err)
+	}
+	if err := sm.SetChannels("missing", []string{"frontend"}); err == nil {
+		t.Error("Expected error for unknown session")
+	}
+
+	info, err := sm.GetWorktreeInfo("session-a")
+	if err != nil {
+		t.Fatalf("Expected GetWorktreeInfo to succeed, got: %v", err)
+	}
+	if !info.InChannel("frontend") || info.InChannel("backend") {
+		t.Errorf("Unexpected channels %v", info.Channels)
+	}
+}
+
 func TestGetWorktreeInfo(t *testing.T) {
 	tmpDir := t.TempDir()
 	sm := &StateManager{
@@ -194,6 +658,7 @@ func TestRemoveStateNonExistent(t *testing.T) {
 	tmpDir := t.TempDir()
 	sm := &StateManager{
 		statePath: filepath.Join(tmpDir, "non-existent.json"),
+		fs:        NewDefaultFileSystem(),
 	}
 
 	// Should not error when trying to remove from non-existent file
@@ -386,6 +851,7 @@ func TestRemoveStateCorruptedJSON(t *testing.T) {
 	tmpDir := t.TempDir()
 	sm := &StateManager{
 		statePath: filepath.Join(tmpDir, "state.json"),
+		fs:        NewDefaultFileSystem(),
 	}
 
 	// Write corrupted JSON
@@ -405,6 +871,7 @@ func TestGetWorktreeInfoCorruptedJSON(t *testing.T) {
 	tmpDir := t.TempDir()
 	sm := &StateManager{
 		statePath: filepath.Join(tmpDir, "state.json"),
+		fs:        NewDefaultFileSystem(),
 	}
 
 	// Write corrupted JSON
@@ -450,3 +917,36 @@ func TestSaveStateExistingCorruptedJSON(t *testing.T) {
 		t.Errorf("Expected prompt 'test prompt', got '%s'", info.Prompt)
 	}
 }
+
+// deadSessionExecutor reports a fixed git remote and no running tmux sessions
+type deadSessionExecutor struct {
+	fakeCommandExecutor
+}
+
+func (d *deadSessionExecutor) RunCommand(name string, args ...string) error {
+	return fmt.Errorf("can't find session")
+}
+
+func TestStatesForRepo(t *testing.T) {
+	tmpDir := t.TempDir()
+	sm := &StateManager{
+		statePath: filepath.Join(tmpDir, "state.json"),
+		fs:        NewDefaultFileSystem(),
+		cmdExec:   &deadSessionExecutor{fakeCommandExecutor{repo: "git@github.com:me/app.git"}},
+	}
+	writeTestStates(t, sm.statePath, map[string]AgentState{
+		"agent-app-abc123-sarah": {GitRepo: "git@github.com:me/app.git"},
+		"agent-lib-abc123-john":  {GitRepo: "git@github.com:me/lib.git"},
+	})
+
+	states, err := sm.StatesForRepo()
+	if err != nil {
+		t.Fatalf("StatesForRepo() error = %v", err)
+	}
+	if _, ok := states["agent-app-abc123-sarah"]; !ok || len(states) != 1 {
+		t.Errorf("Expected the dead session of this repository only, got %v", states)
+	}
+	if active, _ := sm.GetActiveSessionsForRepo(); len(active) != 0 {
+		t.Errorf("Expected no active sessions, got %v", active)
+	}
+}
@@ -0,0 +1,100 @@
+package state
+
+import (
+	"strings"
+
+	"github.com/nehpz/claudicus/pkg/agents"
+)
+
+// Session statuses. Every status uzi reports for a session, in `uzi ls`, the
+// TUI and the activity monitor, comes from one state machine:
+//
+//	unknown → starting → running → ready → stuck → done → dead
+//
+// where
+//
+//   - unknown: the session exists but its agent pane can't be read
+//   - starting: the agent pane is still blank; the agent CLI hasn't drawn yet
+//   - running: the agent is working on a turn
+//   - ready: the agent is waiting for input
+//   - stuck: the agent is waiting but the activity monitor has seen no
+//     progress for too long
+//   - done: the agent signalled its task is complete
+//   - dead: the tmux session is gone
+//
+// A session moves forward through the machine, except that a live agent
+// returns to running whenever it picks up work again. Only dead is final.
+// Paused is not part of the machine: a session stopped by `uzi pause`
+// reports paused, whatever its pane shows, until `uzi resume`.
+const (
+	StatusUnknown  = "unknown"
+	StatusStarting = "starting"
+	StatusRunning  = "running"
+	StatusReady    = "ready"
+	StatusStuck    = "stuck"
+	StatusDone     = "done"
+	StatusDead     = "dead"
+	StatusPaused   = "paused"
+)
+
+// StatusSignals is what is known about a session when its status is resolved
+type StatusSignals struct {
+	Alive   bool   // the tmux session exists
+	Pane    string // visible content of the agent pane
+	PaneErr error  // why the pane couldn't be read, if it couldn't
+	Stuck   bool   // the activity monitor classified the agent as stuck
+	Done    bool   // the agent signalled its task is complete
+	Paused  bool   // the session was stopped by `uzi pause`
+}
+
+// Resolve returns the session's status in the state machine
+func (s StatusSignals) Resolve() string {
+	switch {
+	case !s.Alive:
+		return StatusDead
+	case s.Paused:
+		return StatusPaused
+	}
+	status := StatusUnknown
+	if s.PaneErr == nil {
+		status = AgentStatusFromPane(s.Pane)
+	}
+	return WithActivity(status, s.Stuck, s.Done)
+}
+
+// WithActivity advances a status read from tmux with what the activity
+// monitor found: a done agent is done, and a ready agent that has made no
+// progress is stuck. Dead and paused sessions are left as they are.
+func WithActivity(status string, stuck, done bool) string {
+	switch {
+	case status == StatusDead || status == StatusPaused:
+		return status
+	case done:
+		return StatusDone
+	case stuck && status == StatusReady:
+		return StatusStuck
+	}
+	return status
+}
+
+// AgentStatusFromPane classifies an agent's pane content as starting,
+// running or ready. The status patterns of agent definitions are checked
+// before the built-in rules.
+func AgentStatusFromPane(content string) string {
+	if strings.TrimSpace(content) == "" {
+		return StatusStarting
+	}
+	switch running, ready := agents.PaneStatus(content); {
+	case ready:
+		return StatusReady
+	case running:
+		return StatusRunning
+	}
+	switch {
+	case strings.Contains(content, "esc to interrupt") ||
+		strings.Contains(content, "Thinking") ||
+		strings.Contains(content, "Working"):
+		return StatusRunning
+	}
+	return StatusReady
+}
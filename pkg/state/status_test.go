@@ -0,0 +1,51 @@
+package state
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestStatusSignalsResolve(t *testing.T) {
+	paneErr := errors.New("can't find pane")
+	tests := []struct {
+		name    string
+		signals StatusSignals
+		want    string
+	}{
+		{"unreadable pane", StatusSignals{Alive: true, PaneErr: paneErr}, StatusUnknown},
+		{"blank pane", StatusSignals{Alive: true, Pane: "\n\n"}, StatusStarting},
+		{"working", StatusSignals{Alive: true, Pane: "Thinking... (esc to interrupt)"}, StatusRunning},
+		{"waiting", StatusSignals{Alive: true, Pane: "> "}, StatusReady},
+		{"waiting without progress", StatusSignals{Alive: true, Pane: "> ", Stuck: true}, StatusStuck},
+		{"working is never stuck", StatusSignals{Alive: true, Pane: "Working", Stuck: true}, StatusRunning},
+		{"signalled completion", StatusSignals{Alive: true, Pane: "> ", Stuck: true, Done: true}, StatusDone},
+		{"done with an unreadable pane", StatusSignals{Alive: true, PaneErr: paneErr, Done: true}, StatusDone},
+		{"paused", StatusSignals{Alive: true, Pane: "Working", Done: true, Paused: true}, StatusPaused},
+		{"session gone", StatusSignals{Pane: "> ", Done: true, Paused: true}, StatusDead},
+	}
+	for _, tt := range tests {
+		if got := tt.signals.Resolve(); got != tt.want {
+			t.Errorf("%s: Resolve() = %q, want %q", tt.name, got, tt.want)
+		}
+	}
+}
+
+func TestWithActivity(t *testing.T) {
+	tests := []struct {
+		status      string
+		stuck, done bool
+		want        string
+	}{
+		{StatusReady, false, false, StatusReady},
+		{StatusReady, true, false, StatusStuck},
+		{StatusStarting, true, false, StatusStarting},
+		{StatusRunning, false, true, StatusDone},
+		{StatusDead, true, true, StatusDead},
+		{StatusPaused, false, true, StatusPaused},
+	}
+	for _, tt := range tests {
+		if got := WithActivity(tt.status, tt.stuck, tt.done); got != tt.want {
+			t.Errorf("WithActivity(%q, %v, %v) = %q, want %q", tt.status, tt.stuck, tt.done, got, tt.want)
+		}
+	}
+}
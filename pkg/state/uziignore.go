@@ -0,0 +1,84 @@
+package state
+
+import (
+	"bufio"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+)
+
+// IgnoreFile lists, in gitignore syntax, the paths of a worktree left out
+// of its diff counts and file activity, such as generated directories.
+// Patterns with a slash other than a trailing one are relative to the
+// worktree root; others match a file or directory name at any depth. A
+// matched directory excludes everything under it. Negated (!) patterns are
+// not supported and are skipped.
+const IgnoreFile = ".uziignore"
+
+// ignorePathspecsScript sets the positional parameters to git pathspecs
+// covering the worktree except the paths of IgnoreFile, for scripts to pass
+// as -- "$@". It is shell rather than Go so the diff scripts can run as is
+// on remote hosts.
+const ignorePathspecsScript = `set -- .; if [ -f ` + IgnoreFile + ` ]; then cr=$(printf '\r'); while IFS= read -r p || [ -n "$p" ]; do ` +
+	`p=${p%"$cr"}; p=${p%/}; case "$p" in ""|"#"*|"!"*) continue;; esac; ` +
+	`case "$p" in */*) p=${p#/}; set -- "$@" ":(exclude,glob)$p" ":(exclude,glob)$p/**";; ` +
+	`*) set -- "$@" ":(exclude,glob)**/$p" ":(exclude,glob)**/$p/**";; esac; ` +
+	`done < ` + IgnoreFile + `; fi; `
+
+// Ignore matches worktree paths against the patterns of an IgnoreFile
+type Ignore struct {
+	anchored []string // patterns relative to the root
+	names    []string // patterns matching a name at any depth
+}
+
+// LoadIgnore reads the IgnoreFile at the root of a worktree. It returns nil,
+// which matches nothing, when the worktree has none.
+func LoadIgnore(root string) *Ignore {
+	file, err := os.Open(filepath.Join(root, IgnoreFile))
+	if err != nil {
+		return nil
+	}
+	defer file.Close()
+
+	ignore := &Ignore{}
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		pattern := strings.TrimSuffix(strings.TrimRight(scanner.Text(), "\r"), "/")
+		if pattern == "" || strings.HasPrefix(pattern, "#") || strings.HasPrefix(pattern, "!") {
+			continue
+		}
+		if strings.Contains(pattern, "/") {
+			ignore.anchored = append(ignore.anchored, strings.TrimPrefix(pattern, "/"))
+		} else {
+			ignore.names = append(ignore.names, pattern)
+		}
+	}
+	return ignore
+}
+
+// Match reports whether rel, a slash-separated path relative to the
+// worktree root, is ignored itself or lies in an ignored directory
+func (i *Ignore) Match(rel string) bool {
+	if i == nil {
+		return false
+	}
+	segments := strings.Split(path.Clean(rel), "/")
+	for _, name := range i.names {
+		for _, segment := range segments {
+			if ok, _ := path.Match(name, segment); ok {
+				return true
+			}
+		}
+	}
+	for _, pattern := range i.anchored {
+		depth := strings.Count(pattern, "/") + 1
+		if depth > len(segments) {
+			continue
+		}
+		if ok, _ := path.Match(pattern, strings.Join(segments[:depth], "/")); ok {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,95 @@
+package state
+
+import (
+	"os"
+	"os/exec"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func TestIgnoreMatch(t *testing.T) {
+	dir := t.TempDir()
+	if LoadIgnore(dir).Match("node_modules/x.js") {
+		t.Error("Expected nothing ignored without an ignore file")
+	}
+	content := "# generated\r\nnode_modules/\n*.gen.go\n/dist\nweb/build/\n!keep.gen.go\n\n"
+	if err := os.WriteFile(filepath.Join(dir, IgnoreFile), []byte(content), 0644); err != nil {
+		t.Fatal(err)
+	}
+	ignore := LoadIgnore(dir)
+
+	for rel, want := range map[string]bool{
+		"node_modules":                true,
+		"node_modules/react/index.js": true,
+		"web/node_modules/x.js":       true,
+		"api/types.gen.go":            true,
+		"keep.gen.go":                 true, // Negations are skipped
+		"dist/app.js":                 true,
+		"src/dist/app.js":             false, // Anchored to the root
+		"web/build/index.html":        true,
+		"build/index.html":            false,
+		"main.go":                     false,
+		"# generated":                 false,
+	} {
+		if got := ignore.Match(rel); got != want {
+			t.Errorf("Match(%q) = %v, want %v", rel, got, want)
+		}
+	}
+}
+
+func TestDiffScriptsHonorIgnoreFile(t *testing.T) {
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git not available")
+	}
+	dir := t.TempDir()
+	git := func(args ...string) {
+		t.Helper()
+		cmd := exec.Command("git", append([]string{"-c", "user.email=uzi@example.com", "-c", "user.name=uzi"}, args...)...)
+		cmd.Dir = dir
+		if output, err := cmd.CombinedOutput(); err != nil {
+			t.Fatalf("git %v: %v: %s", args, err, output)
+		}
+	}
+	write := func(name, content string) {
+		t.Helper()
+		if err := os.MkdirAll(filepath.Dir(filepath.Join(dir, name)), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(filepath.Join(dir, name), []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	git("init", "-q")
+	write(IgnoreFile, "node_modules/\n/dist\n*.gen.go\n")
+	write("main.go", "a\n")
+	write("dist/app.js", "x\n")
+	git("add", ".")
+	git("commit", "-qm", "initial")
+
+	write("main.go", "a\nb\n")
+	write("dist/app.js", "x\ny\nz\n")
+	write("node_modules/react/index.js", "1\n2\n3\n4\n")
+	write("api/types.gen.go", "1\n2\n")
+	write("api/handler.go", "1\n")
+
+	output, err := DefaultSessionProbe{}.DiffStat(dir)
+	if err != nil {
+		t.Fatalf("DiffStat() error = %v", err)
+	}
+	if insertions, deletions, files := ParseDiffStatFiles(output); insertions != 2 || deletions != 0 || files != 2 {
+		t.Errorf("Expected 2 insertions in 2 files outside %s, got %d, %d, %d from %q", IgnoreFile, insertions, deletions, files, output)
+	}
+
+	stat, err := NewAggregator().FileDiffs(AgentState{WorktreePath: dir})
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []FileDiff{
+		{Path: "api/handler.go", Change: ChangeAdded, Insertions: 1},
+		{Path: "main.go", Change: ChangeModified, Insertions: 1},
+	}
+	if !reflect.DeepEqual(stat.Files, want) {
+		t.Errorf("FileDiffs() = %+v, want %+v", stat.Files, want)
+	}
+}
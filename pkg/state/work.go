@@ -0,0 +1,85 @@
+package state
+
+import (
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+)
+
+// PendingWork is agent work that killing its session would throw away
+type PendingWork struct {
+	UncommittedChanges int // Files with staged, unstaged, or untracked changes
+	UnmergedCommits    int // Commits on the agent branch that are not on the branch it started from
+}
+
+// Empty reports whether there is nothing to lose
+func (w PendingWork) Empty() bool {
+	return w.UncommittedChanges == 0 && w.UnmergedCommits == 0
+}
+
+// String describes the pending work, e.g. "42 uncommitted changes and 3 unmerged commits"
+func (w PendingWork) String() string {
+	var parts []string
+	if w.UncommittedChanges > 0 {
+		parts = append(parts, plural(w.UncommittedChanges, "uncommitted change"))
+	}
+	if w.UnmergedCommits > 0 {
+		parts = append(parts, plural(w.UnmergedCommits, "unmerged commit"))
+	}
+	if len(parts) == 0 {
+		return "no pending work"
+	}
+	return strings.Join(parts, " and ")
+}
+
+// KillWarning is the prompt shown before killing an agent with pending work
+func (w PendingWork) KillWarning(agentName string) string {
+	return fmt.Sprintf("%s has %s — checkpoint first?", agentName, w)
+}
+
+func plural(n int, noun string) string {
+	if n == 1 {
+		return fmt.Sprintf("1 %s", noun)
+	}
+	return fmt.Sprintf("%d %ss", n, noun)
+}
+
+// InspectWork counts the uncommitted changes in an agent's worktree and the
+// commits it has not yet merged into the branch it started from. Shared
+// sessions and sessions whose worktree is already gone have nothing to lose,
+// since kill never removes the main checkout. For remote sessions, executor
+// must run commands on the session's host.
+func InspectWork(executor CommandExecutor, agentState AgentState) (PendingWork, error) {
+	var work PendingWork
+	if agentState.IsShared() || agentState.WorktreePath == "" {
+		return work, nil
+	}
+	if !agentState.IsRemote() {
+		if _, err := os.Stat(agentState.WorktreePath); err != nil {
+			return work, nil
+		}
+	}
+
+	status, err := executor.ExecuteCommand("git", "-C", agentState.WorktreePath, "status", "--porcelain")
+	if err != nil {
+		return work, fmt.Errorf("failed to check worktree status: %w", err)
+	}
+	for _, line := range strings.Split(string(status), "\n") {
+		if strings.TrimSpace(line) != "" {
+			work.UncommittedChanges++
+		}
+	}
+
+	if agentState.BranchFrom != "" {
+		count, err := executor.ExecuteCommand("git", "-C", agentState.WorktreePath, "rev-list", "--count", agentState.BranchFrom+"..HEAD")
+		if err != nil {
+			return work, fmt.Errorf("failed to count unmerged commits: %w", err)
+		}
+		work.UnmergedCommits, err = strconv.Atoi(strings.TrimSpace(string(count)))
+		if err != nil {
+			return work, fmt.Errorf("failed to count unmerged commits: %w", err)
+		}
+	}
+	return work, nil
+}
@@ -0,0 +1,99 @@
+package state
+
+import (
+	"errors"
+	"strings"
+	"testing"
+)
+
+// gitOutputExecutor returns canned output for git subcommands
+type gitOutputExecutor struct {
+	outputs map[string]string // by subcommand
+	calls   []string
+}
+
+func (g *gitOutputExecutor) ExecuteCommand(name string, args ...string) ([]byte, error) {
+	g.calls = append(g.calls, strings.Join(args, " "))
+	// args are -C <worktree> <subcommand> ...
+	output, ok := g.outputs[args[2]]
+	if !ok {
+		return nil, errors.New("unexpected command")
+	}
+	return []byte(output), nil
+}
+
+func (g *gitOutputExecutor) RunCommand(name string, args ...string) error {
+	return nil
+}
+
+func TestInspectWork(t *testing.T) {
+	worktree := t.TempDir()
+	executor := &gitOutputExecutor{outputs: map[string]string{
+		"status":   " M main.go\n?? notes.md\nA  new.go\n",
+		"rev-list": "3\n",
+	}}
+
+	work, err := InspectWork(executor, AgentState{WorktreePath: worktree, BranchFrom: "main"})
+	if err != nil {
+		t.Fatalf("InspectWork() error = %v", err)
+	}
+	if work.UncommittedChanges != 3 || work.UnmergedCommits != 3 {
+		t.Errorf("InspectWork() = %+v, want 3 changes and 3 commits", work)
+	}
+	if got := executor.calls[1]; got != "-C "+worktree+" rev-list --count main..HEAD" {
+		t.Errorf("Expected commits counted against the base branch, got %q", got)
+	}
+
+	// Without a base branch only uncommitted changes are counted
+	executor.calls = nil
+	work, err = InspectWork(executor, AgentState{WorktreePath: worktree})
+	if err != nil || work.UnmergedCommits != 0 || len(executor.calls) != 1 {
+		t.Errorf("Expected no commit count without BranchFrom, got %+v, %v, %v", work, err, executor.calls)
+	}
+}
+
+func TestInspectWorkNothingToLose(t *testing.T) {
+	executor := &gitOutputExecutor{}
+	for name, agentState := range map[string]AgentState{
+		"no worktree":      {},
+		"removed worktree": {WorktreePath: "/nonexistent/worktree"},
+		"shared session":   {WorktreePath: t.TempDir(), Mode: ModeShared},
+	} {
+		work, err := InspectWork(executor, agentState)
+		if err != nil || !work.Empty() {
+			t.Errorf("%s: InspectWork() = %+v, %v, want empty", name, work, err)
+		}
+	}
+	if len(executor.calls) != 0 {
+		t.Errorf("Expected no git commands, got %v", executor.calls)
+	}
+}
+
+func TestInspectWorkGitError(t *testing.T) {
+	_, err := InspectWork(&gitOutputExecutor{}, AgentState{WorktreePath: t.TempDir()})
+	if err == nil {
+		t.Error("Expected an error when git status fails")
+	}
+}
+
+func TestPendingWorkString(t *testing.T) {
+	tests := []struct {
+		work PendingWork
+		want string
+	}{
+		{PendingWork{}, "no pending work"},
+		{PendingWork{UncommittedChanges: 1}, "1 uncommitted change"},
+		{PendingWork{UncommittedChanges: 42}, "42 uncommitted changes"},
+		{PendingWork{UncommittedChanges: 2, UnmergedCommits: 1}, "2 uncommitted changes and 1 unmerged commit"},
+	}
+	for _, tt := range tests {
+		if got := tt.work.String(); got != tt.want {
+			t.Errorf("String() = %q, want %q", got, tt.want)
+		}
+	}
+
+	warning := PendingWork{UncommittedChanges: 42}.KillWarning("sarah")
+	if warning != "sarah has 42 uncommitted changes — checkpoint first?" {
+		t.Errorf("KillWarning() = %q", warning)
+	}
+}
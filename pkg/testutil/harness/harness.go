@@ -0,0 +1,295 @@
+package harness
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/nehpz/claudicus/pkg/config"
+	"github.com/nehpz/claudicus/pkg/state"
+	"github.com/nehpz/claudicus/pkg/tmuxops"
+)
+
+// DefaultRepoURL is the origin remote the harness's git reports
+const DefaultRepoURL = "git@github.com:nehpz/claudicus.git"
+
+// baseBranch is the default branch agents start from
+const baseBranch = "main"
+
+// startHash is the short hash of the commit agents start from
+const startHash = "abc1234"
+
+// Harness wires a fake tmux server, an in-memory state store and a
+// StateManager together, so a test can spawn agents, message them, inspect
+// their work and kill them through the same code paths uzi uses.
+//
+// The fake tmux server also answers the git commands StateManager and
+// state.InspectWork run, from the worktrees the harness tracks. Worktree
+// directories are real, empty temporary directories; their changes are
+// scripted with Change and Commit.
+type Harness struct {
+	Tmux    *Tmux
+	Store   *StateStore
+	State   *state.StateManager
+	RepoURL string // origin remote reported by git; set it before Spawn
+
+	t            testing.TB
+	worktreeRoot string
+	mu           sync.Mutex
+	worktrees    map[string]*worktree // by session name
+	spawned      int
+}
+
+// worktree is the scripted git state of an agent's worktree
+type worktree struct {
+	path    string
+	changes map[string][2]int // uncommitted file -> insertions, deletions
+	commits int               // commits not yet merged into the base branch
+}
+
+// New creates a harness with no agents. The state store's home directory is
+// a temporary directory, so code that keeps lock files beside state.json
+// still works.
+func New(t testing.TB) *Harness {
+	t.Helper()
+	h := &Harness{
+		Tmux:         NewTmux(),
+		Store:        NewStateStore(t.TempDir()),
+		RepoURL:      DefaultRepoURL,
+		t:            t,
+		worktreeRoot: t.TempDir(),
+		worktrees:    make(map[string]*worktree),
+	}
+	h.Tmux.Handle("git", h.git)
+	h.State = state.NewStateManagerWithDeps(h.Store, h.Tmux)
+	return h
+}
+
+// Spawn starts an agent like uzi prompt: it creates the agent's worktree and
+// tmux session, marks the session and its agent window, launches the agent
+// CLI with the prompt and saves the agent's state. It returns the session name.
+func (h *Harness) Spawn(agentName, model, prompt string) string {
+	h.t.Helper()
+	h.mu.Lock()
+	fields := config.NameFields{
+		Agent:     agentName,
+		Project:   strings.TrimSuffix(filepath.Base(h.RepoURL), ".git"),
+		Hash:      startHash,
+		Time:      h.Tmux.Now(),
+		Iteration: h.spawned,
+	}
+	h.spawned++
+	h.mu.Unlock()
+
+	sessionName := config.RenderName(config.DefaultSessionTemplate, fields)
+	branchName := config.RenderName(config.DefaultBranchTemplate, fields)
+	dir := filepath.Join(h.worktreeRoot, branchName)
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		h.t.Fatalf("harness: failed to create worktree for %s: %v", agentName, err)
+	}
+
+	invocation := model
+	if prompt != "" {
+		invocation += ` "` + prompt + `"`
+	}
+	for _, args := range [][]string{
+		{"new-session", "-d", "-s", sessionName, "-c", dir},
+		{"rename-window", "-t", tmuxops.AgentTarget(sessionName), config.DefaultWindowTemplate},
+		tmuxops.MarkAgentWindowArgs(sessionName),
+		tmuxops.MarkSessionArgs(sessionName, model),
+		tmuxops.SendKeysArgs(sessionName, invocation, "C-m"),
+	} {
+		if err := h.Tmux.Execute("tmux", args...); err != nil {
+			h.t.Fatalf("harness: tmux %s failed: %v", strings.Join(args, " "), err)
+		}
+	}
+	if err := h.State.SaveState(prompt, branchName, sessionName, dir, model); err != nil {
+		h.t.Fatalf("harness: failed to save state for %s: %v", sessionName, err)
+	}
+
+	h.mu.Lock()
+	h.worktrees[sessionName] = &worktree{path: dir, changes: make(map[string][2]int)}
+	h.mu.Unlock()
+	return sessionName
+}
+
+// Change records an uncommitted change to a file in the session's worktree
+func (h *Harness) Change(sessionName, file string, insertions, deletions int) {
+	h.t.Helper()
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	h.mustWorktree(sessionName).changes[file] = [2]int{insertions, deletions}
+}
+
+// Commit commits the session's uncommitted changes to the agent's branch
+func (h *Harness) Commit(sessionName string) {
+	h.t.Helper()
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	wt := h.mustWorktree(sessionName)
+	if len(wt.changes) > 0 {
+		wt.commits++
+		wt.changes = make(map[string][2]int)
+	}
+}
+
+// Checkpoint merges the agent's work into the base branch like uzi
+// checkpoint: uncommitted changes are committed, then the agent's commits are
+// rebased onto the base branch, leaving no pending work
+func (h *Harness) Checkpoint(sessionName string) {
+	h.t.Helper()
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	wt := h.mustWorktree(sessionName)
+	wt.changes = make(map[string][2]int)
+	wt.commits = 0
+}
+
+// Kill removes an agent like uzi kill: its tmux session, state and worktree
+// are removed
+func (h *Harness) Kill(sessionName string) {
+	h.t.Helper()
+	if err := h.Tmux.Execute("tmux", "kill-session", "-t", sessionName); err != nil {
+		h.t.Fatalf("harness: failed to kill %s: %v", sessionName, err)
+	}
+	if err := h.State.RemoveState(sessionName); err != nil {
+		h.t.Fatalf("harness: failed to remove state of %s: %v", sessionName, err)
+	}
+	h.mu.Lock()
+	wt := h.worktrees[sessionName]
+	delete(h.worktrees, sessionName)
+	h.mu.Unlock()
+	if wt != nil {
+		os.RemoveAll(wt.path)
+	}
+}
+
+// States returns the agent states saved in the store
+func (h *Harness) States() map[string]state.AgentState {
+	h.t.Helper()
+	states := make(map[string]state.AgentState)
+	data, err := h.Store.ReadFile(h.State.GetStatePath())
+	if os.IsNotExist(err) {
+		return states
+	}
+	if err != nil {
+		h.t.Fatalf("harness: failed to read state: %v", err)
+	}
+	if err := json.Unmarshal(data, &states); err != nil {
+		h.t.Fatalf("harness: failed to parse state: %v", err)
+	}
+	return states
+}
+
+// PaneContent implements state.SessionProbe
+func (h *Harness) PaneContent(sessionName string) (string, error) {
+	output, err := h.Tmux.CapturePane(sessionName)
+	return string(output), err
+}
+
+// DiffStat implements state.SessionProbe with the uncommitted changes of the
+// worktree, in `git diff --shortstat` form
+func (h *Harness) DiffStat(worktreePath string) (string, error) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	wt := h.worktreeAt(worktreePath)
+	if wt == nil {
+		return "", fmt.Errorf("harness: no worktree at %s", worktreePath)
+	}
+	if len(wt.changes) == 0 {
+		return "", nil
+	}
+	var insertions, deletions int
+	for _, change := range wt.changes {
+		insertions += change[0]
+		deletions += change[1]
+	}
+	return fmt.Sprintf(" %d files changed, %d insertions(+), %d deletions(-)\n", len(wt.changes), insertions, deletions), nil
+}
+
+// FileDiffStat implements state.FileDiffProbe with the uncommitted changes
+// of the worktree, as modified files in `git diff --numstat` and
+// `git diff --name-status` form
+func (h *Harness) FileDiffStat(worktreePath string) (string, error) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	wt := h.worktreeAt(worktreePath)
+	if wt == nil {
+		return "", fmt.Errorf("harness: no worktree at %s", worktreePath)
+	}
+	files := make([]string, 0, len(wt.changes))
+	for file := range wt.changes {
+		files = append(files, file)
+	}
+	sort.Strings(files)
+	var numstat, nameStatus strings.Builder
+	for _, file := range files {
+		fmt.Fprintf(&numstat, "%d\t%d\t%s\n", wt.changes[file][0], wt.changes[file][1], file)
+		fmt.Fprintf(&nameStatus, "M\t%s\n", file)
+	}
+	return numstat.String() + nameStatus.String(), nil
+}
+
+// git answers the git commands uzi runs against the repository and the
+// agents' worktrees
+func (h *Harness) git(args ...string) ([]byte, error) {
+	switch strings.Join(args, " ") {
+	case "config --get remote.origin.url":
+		return []byte(h.RepoURL + "\n"), nil
+	case "symbolic-ref refs/remotes/origin/HEAD":
+		return []byte("refs/remotes/origin/" + baseBranch + "\n"), nil
+	case "branch --show-current":
+		return []byte(baseBranch + "\n"), nil
+	}
+
+	if len(args) >= 3 && args[0] == "-C" {
+		h.mu.Lock()
+		defer h.mu.Unlock()
+		wt := h.worktreeAt(args[1])
+		if wt == nil {
+			return nil, fmt.Errorf("fatal: cannot change to '%s': No such file or directory", args[1])
+		}
+		switch args[2] {
+		case "status":
+			files := make([]string, 0, len(wt.changes))
+			for file := range wt.changes {
+				files = append(files, file)
+			}
+			sort.Strings(files)
+			var b strings.Builder
+			for _, file := range files {
+				b.WriteString(" M " + file + "\n")
+			}
+			return []byte(b.String()), nil
+		case "rev-list":
+			return []byte(strconv.Itoa(wt.commits) + "\n"), nil
+		}
+	}
+	return nil, fmt.Errorf("harness: unhandled git %s", strings.Join(args, " "))
+}
+
+// mustWorktree returns the worktree of a spawned session, failing the test
+// for unknown sessions. h.mu must be held.
+func (h *Harness) mustWorktree(sessionName string) *worktree {
+	wt, ok := h.worktrees[sessionName]
+	if !ok {
+		h.t.Fatalf("harness: %s was not spawned", sessionName)
+	}
+	return wt
+}
+
+// worktreeAt returns the worktree at path, or nil. h.mu must be held.
+func (h *Harness) worktreeAt(path string) *worktree {
+	for _, wt := range h.worktrees {
+		if wt.path == filepath.Clean(path) {
+			return wt
+		}
+	}
+	return nil
+}
@@ -0,0 +1,192 @@
+package harness
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"sort"
+	"testing"
+
+	"github.com/nehpz/claudicus/pkg/state"
+	"github.com/nehpz/claudicus/pkg/tmuxops"
+)
+
+func TestSpawnBroadcastCheckpointKill(t *testing.T) {
+	h := New(t)
+	sarah := h.Spawn("sarah", "claude", "fix the flaky tests")
+	emily := h.Spawn("emily", "codex", "document the config")
+
+	// Spawn: both sessions run their agent and are found for the repository
+	active, err := h.State.GetActiveSessionsForRepo()
+	if err != nil {
+		t.Fatal(err)
+	}
+	sort.Strings(active)
+	if want := []string{emily, sarah}; !reflect.DeepEqual(active, want) {
+		t.Fatalf("Expected active sessions %v, got %v", want, active)
+	}
+	if got := h.Tmux.Option(sarah, tmuxops.SessionAgentOption, false); got != "claude" {
+		t.Errorf("Expected the session marked with its agent, got %q", got)
+	}
+	if got := h.Tmux.Option(sarah, tmuxops.WindowRoleOption, true); got != tmuxops.AgentWindowRole {
+		t.Errorf("Expected the agent window marked, got %q", got)
+	}
+	if got := h.Tmux.Submitted(sarah); !reflect.DeepEqual(got, []string{`claude "fix the flaky tests"`}) {
+		t.Errorf("Expected the agent launched with its prompt, got %q", got)
+	}
+	pane, err := h.Tmux.DisplayAgentPane(emily)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := h.States()[emily].WorktreePath + "|codex\n"; string(pane) != want {
+		t.Errorf("Expected the agent pane to run codex in its worktree, got %q", pane)
+	}
+
+	// Broadcast: agents start working when the message arrives
+	h.Tmux.OnSubmit(func(sessionName, line string) {
+		if line == "run the linter" {
+			h.Tmux.SetPaneContent(sessionName, "Running linter... (esc to interrupt)")
+		}
+	})
+	if err := tmuxops.NewBroadcaster(h.Tmux).Broadcast(active, "run the linter"); err != nil {
+		t.Fatalf("Broadcast() error = %v", err)
+	}
+	h.Change(sarah, "main.go", 12, 3)
+	h.Change(sarah, "main_test.go", 30, 0)
+
+	aggregator := state.NewAggregator(state.WithTmuxStatus(), state.WithDiffs(), state.WithProbe(h))
+	infos := aggregator.Sessions(h.States(), []string{sarah, emily})
+	if len(infos) != 2 {
+		t.Fatalf("Expected 2 sessions, got %d", len(infos))
+	}
+	if infos[0].Status != "running" || infos[1].Status != "running" {
+		t.Errorf("Expected both agents running after the broadcast, got %q and %q", infos[0].Status, infos[1].Status)
+	}
+	if infos[0].Insertions != 42 || infos[0].Deletions != 3 {
+		t.Errorf("Expected sarah's diff +42 -3, got +%d -%d", infos[0].Insertions, infos[0].Deletions)
+	}
+	files, err := state.NewAggregator(state.WithProbe(h)).FileDiffs(h.States()[sarah])
+	if err != nil {
+		t.Fatal(err)
+	}
+	wantFiles := []state.FileDiff{
+		{Path: "main.go", Change: state.ChangeModified, Insertions: 12, Deletions: 3},
+		{Path: "main_test.go", Change: state.ChangeModified, Insertions: 30},
+	}
+	if !reflect.DeepEqual(files.Files, wantFiles) {
+		t.Errorf("Expected sarah's changes by file %+v, got %+v", wantFiles, files.Files)
+	}
+
+	// Checkpoint: pending work is merged and nothing is left to lose
+	work, err := state.InspectWork(h.Tmux, h.States()[sarah])
+	if err != nil {
+		t.Fatal(err)
+	}
+	if work.UncommittedChanges != 2 || work.UnmergedCommits != 0 {
+		t.Errorf("Expected 2 uncommitted changes, got %+v", work)
+	}
+	h.Commit(sarah)
+	if work, _ = state.InspectWork(h.Tmux, h.States()[sarah]); work.UncommittedChanges != 0 || work.UnmergedCommits != 1 {
+		t.Errorf("Expected 1 unmerged commit after committing, got %+v", work)
+	}
+	h.Checkpoint(sarah)
+	if work, _ = state.InspectWork(h.Tmux, h.States()[sarah]); !work.Empty() {
+		t.Errorf("Expected no pending work after the checkpoint, got %+v", work)
+	}
+
+	// Kill: the session, its state and its worktree are gone
+	worktreePath := h.States()[sarah].WorktreePath
+	h.Kill(sarah)
+	if h.Tmux.HasSession(sarah) {
+		t.Error("Expected the tmux session killed")
+	}
+	if _, ok := h.States()[sarah]; ok {
+		t.Error("Expected the state removed")
+	}
+	if _, err := os.Stat(worktreePath); !os.IsNotExist(err) {
+		t.Errorf("Expected the worktree removed, got %v", err)
+	}
+	if active, _ = h.State.GetActiveSessionsForRepo(); !reflect.DeepEqual(active, []string{emily}) {
+		t.Errorf("Expected only emily active, got %v", active)
+	}
+}
+
+func TestSpawnOtherRepository(t *testing.T) {
+	h := New(t)
+	h.RepoURL = "git@github.com:acme/app.git"
+	sessionName := h.Spawn("sarah", "claude", "")
+
+	if want := "agent-app-abc1234-sarah"; sessionName != want {
+		t.Errorf("Expected session %s, got %s", want, sessionName)
+	}
+	if got := h.Tmux.Submitted(sessionName); !reflect.DeepEqual(got, []string{"claude"}) {
+		t.Errorf("Expected the agent launched without a prompt, got %q", got)
+	}
+
+	// Sessions of another repository are not active here
+	h.RepoURL = DefaultRepoURL
+	if active, _ := h.State.GetActiveSessionsForRepo(); len(active) != 0 {
+		t.Errorf("Expected no active sessions for another repository, got %v", active)
+	}
+}
+
+func TestStateStore(t *testing.T) {
+	store := NewStateStore("/home/uzi")
+	path := filepath.Join("/home/uzi", ".local", "share", "uzi", "state.json")
+
+	if _, err := store.ReadFile(path); !os.IsNotExist(err) {
+		t.Errorf("Expected a not-exist error for a missing file, got %v", err)
+	}
+	if err := store.WriteFile(path, []byte("{}"), 0644); err == nil {
+		t.Error("Expected writing into a missing directory to fail")
+	}
+	if err := store.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := store.WriteFile(path, []byte("{}"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if data, err := store.ReadFile(path); err != nil || string(data) != "{}" {
+		t.Errorf("ReadFile() = %q, %v", data, err)
+	}
+	if info, err := store.Stat(filepath.Dir(path)); err != nil || !info.IsDir() {
+		t.Errorf("Expected the state directory, got %v, %v", info, err)
+	}
+	if info, err := store.Stat(path); err != nil || info.IsDir() || info.Size() != 2 {
+		t.Errorf("Expected the state file, got %v, %v", info, err)
+	}
+
+	if err := store.RemoveAll(filepath.Join("/home/uzi", ".local")); err != nil {
+		t.Fatal(err)
+	}
+	if files := store.Files(); len(files) != 0 {
+		t.Errorf("Expected RemoveAll to remove the tree, got %v", files)
+	}
+	if _, err := store.Stat(filepath.Dir(path)); !os.IsNotExist(err) {
+		t.Errorf("Expected the directory removed, got %v", err)
+	}
+	if home, _ := store.UserHomeDir(); home != "/home/uzi" {
+		t.Errorf("UserHomeDir() = %q", home)
+	}
+}
+
+func TestRunningSessions(t *testing.T) {
+	h := New(t)
+	sarah := h.Spawn("sarah", "claude", "fix the flaky tests")
+	emily := h.Spawn("emily", "codex", "document the config")
+
+	// Done agents no longer count as running
+	if err := h.State.SetDone(sarah, true); err != nil {
+		t.Fatal(err)
+	}
+	running, err := h.State.GetRunningSessionsForRepo()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(running, []string{emily}) {
+		t.Errorf("Expected only emily running, got %v", running)
+	}
+	if got := h.State.GitRepo(); got != DefaultRepoURL {
+		t.Errorf("GitRepo() = %q, want %q", got, DefaultRepoURL)
+	}
+}
@@ -0,0 +1,143 @@
+package harness
+
+import (
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+// StateStore is an in-memory state.FileSystem. It keeps state.json and the
+// files beside it out of the real home directory, and like the real
+// filesystem it refuses to write into a directory that was never created.
+type StateStore struct {
+	home  string
+	mu    sync.Mutex
+	files map[string][]byte
+	dirs  map[string]bool
+}
+
+// NewStateStore creates an empty store whose home directory is home. Only
+// the home directory exists at first.
+func NewStateStore(home string) *StateStore {
+	home = filepath.Clean(home)
+	return &StateStore{
+		home:  home,
+		files: make(map[string][]byte),
+		dirs:  map[string]bool{home: true},
+	}
+}
+
+// ReadFile implements state.FileSystem
+func (s *StateStore) ReadFile(filename string) ([]byte, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	data, ok := s.files[filepath.Clean(filename)]
+	if !ok {
+		return nil, &fs.PathError{Op: "open", Path: filename, Err: fs.ErrNotExist}
+	}
+	return append([]byte{}, data...), nil
+}
+
+// WriteFile implements state.FileSystem
+func (s *StateStore) WriteFile(filename string, data []byte, perm os.FileMode) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	filename = filepath.Clean(filename)
+	if !s.dirs[filepath.Dir(filename)] {
+		return &fs.PathError{Op: "open", Path: filename, Err: fs.ErrNotExist}
+	}
+	if s.dirs[filename] {
+		return &fs.PathError{Op: "open", Path: filename, Err: fs.ErrInvalid}
+	}
+	s.files[filename] = append([]byte{}, data...)
+	return nil
+}
+
+// MkdirAll implements state.FileSystem
+func (s *StateStore) MkdirAll(path string, perm os.FileMode) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for dir := filepath.Clean(path); !s.dirs[dir]; dir = filepath.Dir(dir) {
+		if _, isFile := s.files[dir]; isFile {
+			return &fs.PathError{Op: "mkdir", Path: dir, Err: fs.ErrExist}
+		}
+		s.dirs[dir] = true
+		if dir == filepath.Dir(dir) {
+			break
+		}
+	}
+	return nil
+}
+
+// Stat implements state.FileSystem
+func (s *StateStore) Stat(name string) (fs.FileInfo, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	name = filepath.Clean(name)
+	if s.dirs[name] {
+		return fileInfo{name: filepath.Base(name), dir: true}, nil
+	}
+	if data, ok := s.files[name]; ok {
+		return fileInfo{name: filepath.Base(name), size: int64(len(data))}, nil
+	}
+	return nil, &fs.PathError{Op: "stat", Path: name, Err: fs.ErrNotExist}
+}
+
+// UserHomeDir implements state.FileSystem
+func (s *StateStore) UserHomeDir() (string, error) {
+	return s.home, nil
+}
+
+// RemoveAll implements state.FileSystem
+func (s *StateStore) RemoveAll(path string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	path = filepath.Clean(path)
+	prefix := path + string(filepath.Separator)
+	for name := range s.files {
+		if name == path || strings.HasPrefix(name, prefix) {
+			delete(s.files, name)
+		}
+	}
+	for dir := range s.dirs {
+		if dir == path || strings.HasPrefix(dir, prefix) {
+			delete(s.dirs, dir)
+		}
+	}
+	return nil
+}
+
+// Files returns the paths of the files in the store, in order
+func (s *StateStore) Files() []string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	names := make([]string, 0, len(s.files))
+	for name := range s.files {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// fileInfo describes a file or directory in a StateStore
+type fileInfo struct {
+	name string
+	size int64
+	dir  bool
+}
+
+func (fi fileInfo) Name() string { return fi.name }
+func (fi fileInfo) Size() int64  { return fi.size }
+func (fi fileInfo) Mode() fs.FileMode {
+	if fi.dir {
+		return fs.ModeDir | 0755
+	}
+	return 0644
+}
+func (fi fileInfo) ModTime() time.Time { return time.Time{} }
+func (fi fileInfo) IsDir() bool        { return fi.dir }
+func (fi fileInfo) Sys() any           { return nil }
@@ -0,0 +1,814 @@
+// Package harness provides an in-process fake tmux server and an in-memory
+// state store, so tests can drive whole agent workflows (spawn, broadcast,
+// checkpoint, kill) through the same tmuxops and state code uzi runs, without
+// scripting each exec call.
+package harness
+
+import (
+	"fmt"
+	"os/exec"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/nehpz/claudicus/pkg/tmuxops"
+)
+
+// defaultShell is the command a pane runs until an agent CLI is started in it
+const defaultShell = "zsh"
+
+// CommandHandler answers a non-tmux command run through the fake, such as git
+type CommandHandler func(args ...string) ([]byte, error)
+
+// Tmux is a scriptable fake tmux server. It keeps sessions, windows and panes
+// in memory and answers the tmux commands uzi runs: new-session, new-window,
+// kill-session, has-session, rename-window, set-option, send-keys,
+// capture-pane, list-sessions, list-windows, list-panes and display-message,
+// including -F formats with #{variable}, #{@option} and #{?cond,a,b}.
+//
+// Keys sent to a pane are recorded; text followed by Enter or C-m is submitted
+// as a line, and the first line submitted to a shell pane starts that command,
+// so `claude "prompt"` leaves claude as the pane's current command.
+type Tmux struct {
+	// Now is the clock used for session creation and activity times
+	Now func() time.Time
+
+	mu       sync.Mutex
+	sessions []*fakeSession
+	handlers map[string]CommandHandler
+	onSubmit []func(sessionName, line string)
+	nextID   int
+}
+
+type fakeSession struct {
+	id       int
+	name     string
+	created  time.Time
+	activity time.Time
+	attached bool
+	options  map[string]string
+	windows  []*fakeWindow
+}
+
+type fakeWindow struct {
+	id      int
+	index   int
+	name    string
+	options map[string]string
+	panes   []*fakePane
+}
+
+type fakePane struct {
+	id        int
+	path      string
+	command   string
+	content   string
+	input     string
+	keys      [][]string
+	submitted []string
+}
+
+// NewTmux creates a fake tmux server with no sessions
+func NewTmux() *Tmux {
+	return &Tmux{
+		Now:      time.Now,
+		handlers: make(map[string]CommandHandler),
+	}
+}
+
+// Handle answers commands named name, such as git, with fn. Commands without
+// a handler fail.
+func (t *Tmux) Handle(name string, fn CommandHandler) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.handlers[name] = fn
+}
+
+// OnSubmit calls fn whenever a line is submitted to a session's agent window,
+// so tests can script how an agent reacts to a prompt or broadcast
+func (t *Tmux) OnSubmit(fn func(sessionName, line string)) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.onSubmit = append(t.onSubmit, fn)
+}
+
+// Run runs a tmux command line. Commands may be chained with ";" arguments,
+// as with tmux itself.
+func (t *Tmux) Run(args ...string) ([]byte, error) {
+	t.mu.Lock()
+	var out strings.Builder
+	var submitted [][2]string
+	var err error
+	for _, command := range splitCommands(args) {
+		var output string
+		var lines [][2]string
+		output, lines, err = t.run(command)
+		out.WriteString(output)
+		submitted = append(submitted, lines...)
+		if err != nil {
+			break
+		}
+	}
+	hooks := append([]func(string, string){}, t.onSubmit...)
+	t.mu.Unlock()
+
+	for _, line := range submitted {
+		for _, hook := range hooks {
+			hook(line[0], line[1])
+		}
+	}
+	return []byte(out.String()), err
+}
+
+// Execute implements tmuxops.CommandExecutor
+func (t *Tmux) Execute(command string, args ...string) error {
+	_, err := t.ExecuteCommand(command, args...)
+	return err
+}
+
+// ExecuteCommand implements state.CommandExecutor
+func (t *Tmux) ExecuteCommand(name string, args ...string) ([]byte, error) {
+	if name == "tmux" {
+		return t.Run(args...)
+	}
+	t.mu.Lock()
+	handler, ok := t.handlers[name]
+	t.mu.Unlock()
+	if !ok {
+		return nil, fmt.Errorf("harness: no handler for %s %s", name, strings.Join(args, " "))
+	}
+	return handler(args...)
+}
+
+// RunCommand implements state.CommandExecutor
+func (t *Tmux) RunCommand(name string, args ...string) error {
+	_, err := t.ExecuteCommand(name, args...)
+	return err
+}
+
+// Command is a drop-in for exec.Command variables swapped in tests. The fake
+// runs the command immediately; the returned Cmd replays its output and exit
+// status when run.
+func (t *Tmux) Command(name string, args ...string) *exec.Cmd {
+	output, err := t.ExecuteCommand(name, args...)
+	var stderr string
+	code := 0
+	if err != nil {
+		stderr, code = err.Error()+"\n", 1
+	}
+	return exec.Command("sh", "-c", `printf '%s' "$1"; printf '%s' "$2" >&2; exit "$3"`, "sh", string(output), stderr, strconv.Itoa(code))
+}
+
+// ListSessions implements the TUI's TmuxInterface with the same format it
+// asks real tmux for
+func (t *Tmux) ListSessions() ([]byte, error) {
+	return t.Run("list-sessions", "-F", "#{session_name}|#{session_windows}|#{?session_attached,1,0}|#{session_created}|#{session_activity}|#{"+tmuxops.SessionOption+"}|#{"+tmuxops.SessionAgentOption+"}")
+}
+
+// ListWindows implements the TUI's TmuxInterface
+func (t *Tmux) ListWindows(sessionName string) ([]byte, error) {
+	role := tmuxops.WindowRoleOption
+	return t.Run("list-windows", "-t", sessionName, "-F", "#{?"+role+",#{"+role+"},#{window_name}}")
+}
+
+// ListPanes implements the TUI's TmuxInterface
+func (t *Tmux) ListPanes(sessionName string) ([]byte, error) {
+	return t.Run("list-panes", "-t", sessionName, "-a", "-F", "#{pane_id}")
+}
+
+// CapturePane implements the TUI's TmuxInterface
+func (t *Tmux) CapturePane(sessionName string) ([]byte, error) {
+	return t.Run("capture-pane", "-t", tmuxops.AgentTarget(sessionName), "-p")
+}
+
+// DisplayAgentPane implements the TUI's TmuxInterface
+func (t *Tmux) DisplayAgentPane(sessionName string) ([]byte, error) {
+	return t.Run("display-message", "-p", "-t", tmuxops.AgentTarget(sessionName), "#{pane_current_path}|#{pane_current_command}")
+}
+
+// Sessions returns the names of the sessions on the server, in creation order
+func (t *Tmux) Sessions() []string {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	names := make([]string, 0, len(t.sessions))
+	for _, s := range t.sessions {
+		names = append(names, s.name)
+	}
+	return names
+}
+
+// HasSession reports whether the session exists
+func (t *Tmux) HasSession(sessionName string) bool {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	return t.session(sessionName) != nil
+}
+
+// SetPaneContent sets what capture-pane shows for the session's agent window
+func (t *Tmux) SetPaneContent(sessionName, content string) error {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	p, err := t.pane(tmuxops.AgentTarget(sessionName))
+	if err != nil {
+		return err
+	}
+	p.content = content
+	return nil
+}
+
+// SetAttached marks the session as attached by a client
+func (t *Tmux) SetAttached(sessionName string, attached bool) error {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	s := t.session(sessionName)
+	if s == nil {
+		return fmt.Errorf("can't find session: %s", sessionName)
+	}
+	s.attached = attached
+	return nil
+}
+
+// SentKeys returns the arguments of each send-keys to the session's agent window
+func (t *Tmux) SentKeys(sessionName string) [][]string {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	p, err := t.pane(tmuxops.AgentTarget(sessionName))
+	if err != nil {
+		return nil
+	}
+	return append([][]string{}, p.keys...)
+}
+
+// Submitted returns the lines submitted to the session's agent window with
+// Enter or C-m. Empty lines are left out.
+func (t *Tmux) Submitted(sessionName string) []string {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	p, err := t.pane(tmuxops.AgentTarget(sessionName))
+	if err != nil {
+		return nil
+	}
+	return append([]string{}, p.submitted...)
+}
+
+// Option returns a session option, or a window option of the agent window
+// when window is set
+func (t *Tmux) Option(sessionName, option string, window bool) string {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	if window {
+		w, err := t.window(tmuxops.AgentTarget(sessionName))
+		if err != nil {
+			return ""
+		}
+		return w.options[option]
+	}
+	if s := t.session(sessionName); s != nil {
+		return s.options[option]
+	}
+	return ""
+}
+
+// splitCommands splits a tmux argument vector at ";" separators
+func splitCommands(args []string) [][]string {
+	var commands [][]string
+	var current []string
+	for _, arg := range args {
+		if arg == ";" || arg == `\;` {
+			commands = append(commands, current)
+			current = nil
+			continue
+		}
+		current = append(current, arg)
+	}
+	return append(commands, current)
+}
+
+// parseArgs splits tmux command arguments into flags and positional
+// arguments. The letters in valued are flags that take a value.
+func parseArgs(args []string, valued string) (map[byte]string, []string, error) {
+	flags := make(map[byte]string)
+	for i := 0; i < len(args); i++ {
+		arg := args[i]
+		if arg == "--" {
+			return flags, args[i+1:], nil
+		}
+		if len(arg) < 2 || arg[0] != '-' {
+			return flags, args[i:], nil
+		}
+		for j := 1; j < len(arg); j++ {
+			letter := arg[j]
+			if !strings.ContainsRune(valued, rune(letter)) {
+				flags[letter] = ""
+				continue
+			}
+			if j+1 < len(arg) {
+				flags[letter] = arg[j+1:]
+			} else if i+1 < len(args) {
+				i++
+				flags[letter] = args[i]
+			} else {
+				return nil, nil, fmt.Errorf("-%c expects an argument", letter)
+			}
+			break
+		}
+	}
+	return flags, nil, nil
+}
+
+// run runs one tmux command, returning its output and the lines submitted to
+// agent windows as session and line pairs
+func (t *Tmux) run(args []string) (string, [][2]string, error) {
+	if len(args) == 0 {
+		return "", nil, nil
+	}
+	command, args := args[0], args[1:]
+	switch command {
+	case "new-session", "new":
+		flags, rest, err := parseArgs(args, "scnxyFe")
+		if err != nil {
+			return "", nil, err
+		}
+		return "", nil, t.newSession(flags, rest)
+
+	case "new-window", "neww":
+		flags, rest, err := parseArgs(args, "tncF")
+		if err != nil {
+			return "", nil, err
+		}
+		return "", nil, t.newWindow(flags, rest)
+
+	case "kill-session":
+		flags, _, err := parseArgs(args, "t")
+		if err != nil {
+			return "", nil, err
+		}
+		name := strings.TrimPrefix(flags['t'], "=")
+		for i, s := range t.sessions {
+			if s.name == name {
+				t.sessions = append(t.sessions[:i], t.sessions[i+1:]...)
+				return "", nil, nil
+			}
+		}
+		return "", nil, fmt.Errorf("can't find session: %s", name)
+
+	case "kill-server":
+		t.sessions = nil
+		return "", nil, nil
+
+	case "has-session", "has":
+		flags, _, err := parseArgs(args, "t")
+		if err != nil {
+			return "", nil, err
+		}
+		if t.session(flags['t']) == nil {
+			return "", nil, fmt.Errorf("can't find session: %s", flags['t'])
+		}
+		return "", nil, nil
+
+	case "rename-window", "renamew":
+		flags, rest, err := parseArgs(args, "t")
+		if err != nil {
+			return "", nil, err
+		}
+		if len(rest) != 1 {
+			return "", nil, fmt.Errorf("rename-window expects a new name")
+		}
+		w, err := t.window(flags['t'])
+		if err != nil {
+			return "", nil, err
+		}
+		w.name = rest[0]
+		return "", nil, nil
+
+	case "set-option", "set":
+		flags, rest, err := parseArgs(args, "t")
+		if err != nil {
+			return "", nil, err
+		}
+		return "", nil, t.setOption(flags, rest)
+
+	case "send-keys", "send":
+		flags, keys, err := parseArgs(args, "tN")
+		if err != nil {
+			return "", nil, err
+		}
+		return t.sendKeys(flags['t'], keys)
+
+	case "capture-pane", "capturep":
+		flags, _, err := parseArgs(args, "tSEb")
+		if err != nil {
+			return "", nil, err
+		}
+		p, err := t.pane(flags['t'])
+		if err != nil {
+			return "", nil, err
+		}
+		if p.content == "" || strings.HasSuffix(p.content, "\n") {
+			return p.content, nil, nil
+		}
+		return p.content + "\n", nil, nil
+
+	case "list-sessions", "ls":
+		flags, _, err := parseArgs(args, "Ff")
+		if err != nil {
+			return "", nil, err
+		}
+		if len(t.sessions) == 0 {
+			return "", nil, fmt.Errorf("no server running")
+		}
+		format := flagOr(flags, 'F', "#{session_name}: #{session_windows} windows")
+		var b strings.Builder
+		for _, s := range t.sessions {
+			b.WriteString(expandFormat(format, t.vars(s, nil, nil)) + "\n")
+		}
+		return b.String(), nil, nil
+
+	case "list-windows", "lsw":
+		flags, _, err := parseArgs(args, "tFf")
+		if err != nil {
+			return "", nil, err
+		}
+		s := t.session(sessionPart(flags['t']))
+		if s == nil {
+			return "", nil, fmt.Errorf("can't find session: %s", flags['t'])
+		}
+		format := flagOr(flags, 'F', "#{window_index}: #{window_name}")
+		var b strings.Builder
+		for _, w := range s.windows {
+			b.WriteString(expandFormat(format, t.vars(s, w, nil)) + "\n")
+		}
+		return b.String(), nil, nil
+
+	case "list-panes", "lsp":
+		flags, _, err := parseArgs(args, "tFf")
+		if err != nil {
+			return "", nil, err
+		}
+		return t.listPanes(flags)
+
+	case "display-message", "display":
+		flags, rest, err := parseArgs(args, "tFc")
+		if err != nil {
+			return "", nil, err
+		}
+		target := flags['t']
+		s := t.session(sessionPart(target))
+		if s == nil {
+			return "", nil, fmt.Errorf("can't find session: %s", target)
+		}
+		w, err := t.window(target)
+		if err != nil {
+			return "", nil, err
+		}
+		format := flagOr(flags, 'F', strings.Join(rest, " "))
+		return expandFormat(format, t.vars(s, w, w.panes[0])) + "\n", nil, nil
+	}
+	return "", nil, fmt.Errorf("unknown command: %s", command)
+}
+
+func (t *Tmux) newSession(flags map[byte]string, rest []string) error {
+	name, ok := flags['s']
+	if !ok {
+		name = strconv.Itoa(len(t.sessions))
+	}
+	if t.session(name) != nil {
+		return fmt.Errorf("duplicate session: %s", name)
+	}
+	now := t.Now()
+	t.nextID++
+	s := &fakeSession{id: t.nextID, name: name, created: now, activity: now, options: make(map[string]string)}
+	t.addWindow(s, flags['n'], flags['c'], rest)
+	t.sessions = append(t.sessions, s)
+	return nil
+}
+
+func (t *Tmux) newWindow(flags map[byte]string, rest []string) error {
+	s := t.session(sessionPart(flags['t']))
+	if s == nil {
+		return fmt.Errorf("can't find session: %s", flags['t'])
+	}
+	t.addWindow(s, flags['n'], flags['c'], rest)
+	return nil
+}
+
+// addWindow appends a window with one pane running command, or the shell.
+// An empty name names the window after the command.
+func (t *Tmux) addWindow(s *fakeSession, name, dir string, command []string) {
+	index := 0
+	if n := len(s.windows); n > 0 {
+		index = s.windows[n-1].index + 1
+	}
+	paneCommand := defaultShell
+	if len(command) > 0 {
+		if fields := strings.Fields(strings.Join(command, " ")); len(fields) > 0 {
+			paneCommand = filepath.Base(fields[0])
+		}
+	}
+	if name == "" {
+		name = paneCommand
+	}
+	t.nextID++
+	w := &fakeWindow{id: t.nextID, index: index, name: name, options: make(map[string]string)}
+	t.nextID++
+	w.panes = []*fakePane{{id: t.nextID, path: dir, command: paneCommand}}
+	s.windows = append(s.windows, w)
+}
+
+func (t *Tmux) setOption(flags map[byte]string, rest []string) error {
+	if len(rest) == 0 {
+		return fmt.Errorf("set-option expects an option")
+	}
+	target, ok := flags['t']
+	if !ok {
+		return fmt.Errorf("no current session")
+	}
+	options := map[string]string(nil)
+	if _, window := flags['w']; window {
+		w, err := t.window(target)
+		if err != nil {
+			return err
+		}
+		options = w.options
+	} else {
+		s := t.session(sessionPart(target))
+		if s == nil {
+			return fmt.Errorf("can't find session: %s", target)
+		}
+		options = s.options
+	}
+	if _, unset := flags['u']; unset {
+		delete(options, rest[0])
+		return nil
+	}
+	if len(rest) < 2 {
+		return fmt.Errorf("set-option %s expects a value", rest[0])
+	}
+	options[rest[0]] = rest[1]
+	return nil
+}
+
+func (t *Tmux) sendKeys(target string, keys []string) (string, [][2]string, error) {
+	s := t.session(sessionPart(target))
+	if s == nil {
+		return "", nil, fmt.Errorf("can't find session: %s", target)
+	}
+	p, err := t.pane(target)
+	if err != nil {
+		return "", nil, err
+	}
+	s.activity = t.Now()
+	p.keys = append(p.keys, append([]string{}, keys...))
+
+	agentPane := s.windows[0].panes[0] == p
+	var submitted [][2]string
+	for _, key := range keys {
+		if key != "Enter" && key != "C-m" {
+			p.input += key
+			continue
+		}
+		line := p.input
+		p.input = ""
+		if strings.TrimSpace(line) == "" {
+			continue
+		}
+		p.submitted = append(p.submitted, line)
+		if p.command == defaultShell {
+			p.command = filepath.Base(strings.Fields(line)[0])
+		}
+		if agentPane {
+			submitted = append(submitted, [2]string{s.name, line})
+		}
+	}
+	return "", submitted, nil
+}
+
+func (t *Tmux) listPanes(flags map[byte]string) (string, [][2]string, error) {
+	format := flagOr(flags, 'F', "#{pane_index}: #{pane_id}")
+	var b strings.Builder
+	write := func(s *fakeSession, w *fakeWindow) {
+		for _, p := range w.panes {
+			b.WriteString(expandFormat(format, t.vars(s, w, p)) + "\n")
+		}
+	}
+
+	if _, all := flags['a']; all {
+		for _, s := range t.sessions {
+			for _, w := range s.windows {
+				write(s, w)
+			}
+		}
+		return b.String(), nil, nil
+	}
+	target := flags['t']
+	s := t.session(sessionPart(target))
+	if s == nil {
+		return "", nil, fmt.Errorf("can't find session: %s", target)
+	}
+	if _, wholeSession := flags['s']; wholeSession {
+		for _, w := range s.windows {
+			write(s, w)
+		}
+		return b.String(), nil, nil
+	}
+	w, err := t.window(target)
+	if err != nil {
+		return "", nil, err
+	}
+	write(s, w)
+	return b.String(), nil, nil
+}
+
+// session finds a session by name; a leading "=" asks for an exact match,
+// which is the only kind of match the fake makes
+func (t *Tmux) session(name string) *fakeSession {
+	name = strings.TrimPrefix(name, "=")
+	for _, s := range t.sessions {
+		if s.name == name {
+			return s
+		}
+	}
+	return nil
+}
+
+// sessionPart returns the session of a session:window.pane target
+func sessionPart(target string) string {
+	name, _, _ := strings.Cut(target, ":")
+	return name
+}
+
+// window resolves a session, session:{start}, session:index or session:name
+// target; a bare session targets its first window
+func (t *Tmux) window(target string) (*fakeWindow, error) {
+	name, spec, _ := strings.Cut(target, ":")
+	s := t.session(name)
+	if s == nil {
+		return nil, fmt.Errorf("can't find session: %s", name)
+	}
+	spec, _, _ = strings.Cut(spec, ".")
+	if spec == "" || spec == "{start}" || spec == "^" {
+		return s.windows[0], nil
+	}
+	if spec == "{end}" || spec == "$" {
+		return s.windows[len(s.windows)-1], nil
+	}
+	for _, w := range s.windows {
+		if strconv.Itoa(w.index) == spec || w.name == spec {
+			return w, nil
+		}
+	}
+	return nil, fmt.Errorf("can't find window: %s", spec)
+}
+
+// pane resolves a target to its window's first pane, or the pane given by
+// index after a "."
+func (t *Tmux) pane(target string) (*fakePane, error) {
+	w, err := t.window(target)
+	if err != nil {
+		return nil, err
+	}
+	_, spec, _ := strings.Cut(target, ":")
+	if _, index, ok := strings.Cut(spec, "."); ok {
+		i, err := strconv.Atoi(index)
+		if err != nil || i < 0 || i >= len(w.panes) {
+			return nil, fmt.Errorf("can't find pane: %s", index)
+		}
+		return w.panes[i], nil
+	}
+	return w.panes[0], nil
+}
+
+// vars returns the format variables of a session, window and pane; window
+// and pane may be nil
+func (t *Tmux) vars(s *fakeSession, w *fakeWindow, p *fakePane) func(string) string {
+	return func(name string) string {
+		if strings.HasPrefix(name, "@") {
+			if w != nil {
+				if value, ok := w.options[name]; ok {
+					return value
+				}
+			}
+			return s.options[name]
+		}
+		switch name {
+		case "session_name":
+			return s.name
+		case "session_id":
+			return "$" + strconv.Itoa(s.id)
+		case "session_windows":
+			return strconv.Itoa(len(s.windows))
+		case "session_attached":
+			if s.attached {
+				return "1"
+			}
+			return "0"
+		case "session_created":
+			return strconv.FormatInt(s.created.Unix(), 10)
+		case "session_activity":
+			return strconv.FormatInt(s.activity.Unix(), 10)
+		}
+		if w != nil {
+			switch name {
+			case "window_name":
+				return w.name
+			case "window_index":
+				return strconv.Itoa(w.index)
+			case "window_id":
+				return "@" + strconv.Itoa(w.id)
+			case "window_panes":
+				return strconv.Itoa(len(w.panes))
+			}
+		}
+		if p != nil {
+			switch name {
+			case "pane_id":
+				return "%" + strconv.Itoa(p.id)
+			case "pane_current_path":
+				return p.path
+			case "pane_current_command":
+				return p.command
+			}
+		}
+		return ""
+	}
+}
+
+// expandFormat expands the #{variable} and #{?condition,then,else} items of a
+// tmux format. A condition is true when its variable is set and not 0.
+func expandFormat(format string, lookup func(string) string) string {
+	var b strings.Builder
+	for i := 0; i < len(format); {
+		if !strings.HasPrefix(format[i:], "#{") {
+			b.WriteByte(format[i])
+			i++
+			continue
+		}
+		end := closingBrace(format, i+1)
+		if end < 0 {
+			b.WriteString(format[i:])
+			break
+		}
+		expr := format[i+2 : end]
+		if strings.HasPrefix(expr, "?") {
+			parts := splitTopLevel(expr[1:])
+			if len(parts) == 3 {
+				if cond := lookup(parts[0]); cond != "" && cond != "0" {
+					b.WriteString(expandFormat(parts[1], lookup))
+				} else {
+					b.WriteString(expandFormat(parts[2], lookup))
+				}
+			}
+		} else {
+			b.WriteString(lookup(expr))
+		}
+		i = end + 1
+	}
+	return b.String()
+}
+
+// closingBrace returns the index of the brace closing the one at open
+func closingBrace(s string, open int) int {
+	depth := 0
+	for i := open; i < len(s); i++ {
+		switch s[i] {
+		case '{':
+			depth++
+		case '}':
+			depth--
+			if depth == 0 {
+				return i
+			}
+		}
+	}
+	return -1
+}
+
+// splitTopLevel splits s at the commas outside of #{...} items
+func splitTopLevel(s string) []string {
+	var parts []string
+	depth, start := 0, 0
+	for i := 0; i < len(s); i++ {
+		switch s[i] {
+		case '{':
+			depth++
+		case '}':
+			depth--
+		case ',':
+			if depth == 0 {
+				parts = append(parts, s[start:i])
+				start = i + 1
+			}
+		}
+	}
+	return append(parts, s[start:])
+}
+
+// flagOr returns the value of a flag, or fallback when it is not given
+func flagOr(flags map[byte]string, letter byte, fallback string) string {
+	if value, ok := flags[letter]; ok {
+		return value
+	}
+	return fallback
+}
@@ -0,0 +1,169 @@
+package harness
+
+import (
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestTmuxSessions(t *testing.T) {
+	tmux := NewTmux()
+	created := time.Unix(1700000000, 0)
+	tmux.Now = func() time.Time { return created }
+
+	if _, err := tmux.ListSessions(); err == nil {
+		t.Error("Expected list-sessions to fail without a server")
+	}
+	if err := tmux.Execute("tmux", "new-session", "-d", "-s", "work", "-c", "/src"); err != nil {
+		t.Fatal(err)
+	}
+	if err := tmux.Execute("tmux", "new-session", "-d", "-s", "work"); err == nil {
+		t.Error("Expected a duplicate session to fail")
+	}
+	if err := tmux.RunCommand("tmux", "new-window", "-t", "work", "-n", "uzi-dev", "-c", "/src", "npm run dev"); err != nil {
+		t.Fatal(err)
+	}
+	if err := tmux.Execute("tmux", "set-option", "-t", "work", "@uzi", "1", ";", "set-option", "-t", "work", "@uzi_agent", "claude"); err != nil {
+		t.Fatal(err)
+	}
+
+	output, err := tmux.ListSessions()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := "work|2|0|1700000000|1700000000|1|claude\n"; string(output) != want {
+		t.Errorf("ListSessions() = %q, want %q", output, want)
+	}
+	output, _ = tmux.Run("list-windows", "-t", "work", "-F", "#{window_index}:#{window_name}")
+	if want := "0:zsh\n1:uzi-dev\n"; string(output) != want {
+		t.Errorf("list-windows = %q, want %q", output, want)
+	}
+	output, _ = tmux.Run("display-message", "-p", "-t", "work:uzi-dev", "#{pane_current_path}|#{pane_current_command}")
+	if want := "/src|npm\n"; string(output) != want {
+		t.Errorf("display-message = %q, want %q", output, want)
+	}
+	output, _ = tmux.Run("list-panes", "-t", "work", "-s", "-F", "#{pane_id}")
+	if lines := strings.Fields(string(output)); len(lines) != 2 {
+		t.Errorf("Expected a pane per window, got %q", output)
+	}
+
+	if err := tmux.Execute("tmux", "kill-session", "-t", "work"); err != nil {
+		t.Fatal(err)
+	}
+	if err := tmux.Execute("tmux", "has-session", "-t", "work"); err == nil {
+		t.Error("Expected has-session to fail after kill-session")
+	}
+	if err := tmux.Execute("tmux", "kill-session", "-t", "work"); err == nil {
+		t.Error("Expected killing a missing session to fail")
+	}
+}
+
+func TestTmuxAgentWindow(t *testing.T) {
+	tmux := NewTmux()
+	tmux.Execute("tmux", "new-session", "-d", "-s", "s", "-c", "/wt")
+	tmux.Execute("tmux", "rename-window", "-t", "s:{start}", "sarah-fix-tests")
+
+	output, _ := tmux.ListWindows("s")
+	if string(output) != "sarah-fix-tests\n" {
+		t.Errorf("Expected an unmarked window listed by name, got %q", output)
+	}
+	tmux.Execute("tmux", "set-option", "-w", "-t", "s:{start}", "@uzi-window", "agent")
+	output, _ = tmux.ListWindows("s")
+	if string(output) != "agent\n" {
+		t.Errorf("Expected a marked window listed by role, got %q", output)
+	}
+	tmux.Execute("tmux", "set-option", "-w", "-u", "-t", "s:0", "@uzi-window")
+	if got := tmux.Option("s", "@uzi-window", true); got != "" {
+		t.Errorf("Expected the option unset, got %q", got)
+	}
+
+	if err := tmux.SetPaneContent("s", "> ready"); err != nil {
+		t.Fatal(err)
+	}
+	output, _ = tmux.CapturePane("s")
+	if string(output) != "> ready\n" {
+		t.Errorf("CapturePane() = %q", output)
+	}
+	if err := tmux.SetPaneContent("missing", "x"); err == nil {
+		t.Error("Expected scripting a missing session to fail")
+	}
+	if _, err := tmux.Run("rename-window", "-t", "s:nope", "x"); err == nil {
+		t.Error("Expected a missing window to fail")
+	}
+}
+
+func TestTmuxSendKeys(t *testing.T) {
+	tmux := NewTmux()
+	tmux.Execute("tmux", "new-session", "-d", "-s", "s")
+	var heard []string
+	tmux.OnSubmit(func(sessionName, line string) {
+		heard = append(heard, sessionName+": "+line)
+	})
+
+	tmux.Execute("tmux", "send-keys", "-t", "s:{start}", "C-m")
+	tmux.Execute("tmux", "send-keys", "-t", "s:{start}", `claude "hi"`, "C-m")
+	tmux.Execute("tmux", "send-keys", "-t", "s:{start}", "half ")
+	tmux.Execute("tmux", "send-keys", "-t", "s:{start}", "a line", "Enter")
+	tmux.Execute("tmux", "send-keys", "-t", "s:{start}", "Enter")
+
+	if got := tmux.SentKeys("s"); len(got) != 5 || !reflect.DeepEqual(got[1], []string{`claude "hi"`, "C-m"}) {
+		t.Errorf("Unexpected keys: %q", got)
+	}
+	if want := []string{`claude "hi"`, "half a line"}; !reflect.DeepEqual(tmux.Submitted("s"), want) {
+		t.Errorf("Submitted() = %q, want %q", tmux.Submitted("s"), want)
+	}
+	if want := []string{`s: claude "hi"`, "s: half a line"}; !reflect.DeepEqual(heard, want) {
+		t.Errorf("OnSubmit heard %q, want %q", heard, want)
+	}
+	output, _ := tmux.DisplayAgentPane("s")
+	if string(output) != "|claude\n" {
+		t.Errorf("Expected the first submitted line to start claude, got %q", output)
+	}
+	if err := tmux.Execute("tmux", "send-keys", "-t", "missing:{start}", "x"); err == nil {
+		t.Error("Expected sending to a missing session to fail")
+	}
+}
+
+func TestTmuxCommand(t *testing.T) {
+	tmux := NewTmux()
+	tmux.Execute("tmux", "new-session", "-d", "-s", "s")
+	tmux.Handle("git", func(args ...string) ([]byte, error) {
+		if args[0] == "fail" {
+			return nil, errors.New("fatal: nope")
+		}
+		return []byte("it's a 'quoted' %s\n"), nil
+	})
+
+	output, err := tmux.Command("tmux", "list-sessions", "-F", "#{session_name}").Output()
+	if err != nil || string(output) != "s\n" {
+		t.Errorf("Command(tmux) = %q, %v", output, err)
+	}
+	output, err = tmux.Command("git", "log").Output()
+	if err != nil || string(output) != "it's a 'quoted' %s\n" {
+		t.Errorf("Command(git) = %q, %v", output, err)
+	}
+	if err := tmux.Command("git", "fail").Run(); err == nil {
+		t.Error("Expected a failing handler to fail the command")
+	}
+	if err := tmux.Command("ssh", "host").Run(); err == nil {
+		t.Error("Expected a command without a handler to fail")
+	}
+}
+
+func TestExpandFormat(t *testing.T) {
+	vars := map[string]string{"name": "s", "attached": "0", "@role": "agent"}
+	lookup := func(name string) string { return vars[name] }
+	for format, want := range map[string]string{
+		"#{name}|#{missing}":               "s|",
+		"#{?attached,yes,no}":              "no",
+		"#{?@role,#{@role},#{name}}":       "agent",
+		"#{?@other,#{@other},#{name}}:end": "s:end",
+		"plain #{":                         "plain #{",
+	} {
+		if got := expandFormat(format, lookup); got != want {
+			t.Errorf("expandFormat(%q) = %q, want %q", format, got, want)
+		}
+	}
+}
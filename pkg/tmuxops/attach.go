@@ -0,0 +1,51 @@
+package tmuxops
+
+import "strings"
+
+// SwitchClientArgs builds the tmux argument vector that shows a session in
+// the tmux client the command runs in, in place of its current session
+func SwitchClientArgs(sessionName string) []string {
+	return []string{"switch-client", "-t", sessionName}
+}
+
+// NewWindowArgs builds the tmux argument vector that runs shellCommand in a
+// new window, named name, of the current session
+func NewWindowArgs(name, shellCommand string) []string {
+	return []string{"new-window", "-n", name, shellCommand}
+}
+
+// SplitWindowArgs builds the tmux argument vector that runs shellCommand in a
+// new pane beside the current one
+func SplitWindowArgs(shellCommand string) []string {
+	return []string{"split-window", "-h", shellCommand}
+}
+
+// SessionWindowsArgs builds the tmux argument vector that prints a line per
+// window of a session: how many clients are attached to the session, when
+// it was created in Unix seconds, and the window name, separated by tabs
+func SessionWindowsArgs(sessionName string) []string {
+	return []string{"list-windows", "-t", sessionName, "-F", "#{session_attached}\t#{session_created}\t#{window_name}"}
+}
+
+// NewSessionScript builds the tmux command that creates a detached session
+// starting in dir, with the NAME=value entries of env in its environment.
+// It is fed to tmux on stdin with SourceStdinArgs, so the environment, and
+// the API keys in it, never appear on a command line in the process list.
+func NewSessionScript(sessionName, dir string, env []string) string {
+	words := []string{"new-session", "-d", "-s", quote(sessionName), "-c", quote(dir)}
+	for _, entry := range env {
+		words = append(words, "-e", quote(entry))
+	}
+	return strings.Join(words, " ") + "\n"
+}
+
+// SourceStdinArgs builds the tmux argument vector that starts the tmux server
+// if it isn't running and runs the tmux commands read from stdin
+func SourceStdinArgs() []string {
+	return []string{"start-server", ";", "source-file", "-"}
+}
+
+// quote quotes s as a single word of a tmux command file
+func quote(s string) string {
+	return "'" + strings.ReplaceAll(s, "'", `'\''`) + "'"
+}
@@ -0,0 +1,315 @@
+package tmuxops
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os/exec"
+	"strings"
+	"time"
+	"unicode"
+)
+
+// CommandExecutor abstracts the execution of external commands
+type CommandExecutor interface {
+	Execute(command string, args ...string) error
+}
+
+// OutputExecutor is implemented by CommandExecutors that can return what a
+// command prints, which delivery acks need to read agent panes
+type OutputExecutor interface {
+	ExecuteCommand(name string, args ...string) ([]byte, error)
+}
+
+// ContextExecutor is implemented by CommandExecutors that can run a command
+// under a context and return what it prints, for commands that must stop
+// when the operation they serve is cancelled
+type ContextExecutor interface {
+	ExecuteCommandContext(ctx context.Context, name string, args ...string) ([]byte, error)
+}
+
+// RealCommandExecutor implements CommandExecutor using exec.Command
+type RealCommandExecutor struct{}
+
+// Execute runs the command using exec.Command
+func (r *RealCommandExecutor) Execute(command string, args ...string) error {
+	cmd := exec.Command(command, args...)
+	return cmd.Run()
+}
+
+// ExecuteCommand implements OutputExecutor
+func (r *RealCommandExecutor) ExecuteCommand(name string, args ...string) ([]byte, error) {
+	return exec.Command(name, args...).Output()
+}
+
+// ExecuteCommandContext implements ContextExecutor
+func (r *RealCommandExecutor) ExecuteCommandContext(ctx context.Context, name string, args ...string) ([]byte, error) {
+	return exec.CommandContext(ctx, name, args...).Output()
+}
+
+// WindowRoleOption is the tmux window option uzi marks its agent window with,
+// so the window is recognized whatever it is named
+const WindowRoleOption = "@uzi-window"
+
+// AgentWindowRole is the WindowRoleOption value of the agent window
+const AgentWindowRole = "agent"
+
+// SessionOption is the tmux session option uzi marks its sessions with, so
+// they are recognized whatever they are named
+const SessionOption = "@uzi"
+
+// SessionAgentOption is the tmux session option holding the agent CLI a uzi
+// session runs, e.g. claude
+const SessionAgentOption = "@uzi_agent"
+
+// AgentTarget returns the tmux target of the agent window in a session. The
+// agent window is the first window of the session, so it is targeted by
+// position and keeps working when the window is renamed.
+func AgentTarget(sessionName string) string {
+	return sessionName + ":{start}"
+}
+
+// MarkAgentWindowArgs builds the tmux argument vector that marks the agent
+// window of a session with AgentWindowRole
+func MarkAgentWindowArgs(sessionName string) []string {
+	return []string{"set-option", "-w", "-t", AgentTarget(sessionName), WindowRoleOption, AgentWindowRole}
+}
+
+// MarkSessionArgs builds the tmux argument vector that marks a session as
+// created by uzi and records the agent CLI it runs. Both options are set by
+// one tmux invocation.
+func MarkSessionArgs(sessionName, agent string) []string {
+	return []string{
+		"set-option", "-t", sessionName, SessionOption, "1", ";",
+		"set-option", "-t", sessionName, SessionAgentOption, agent,
+	}
+}
+
+// SendKeysArgs builds the tmux argument vector that sends keys to the agent window.
+// Keys are passed as separate arguments, so no shell quoting is involved.
+func SendKeysArgs(sessionName string, keys ...string) []string {
+	return append([]string{"send-keys", "-t", AgentTarget(sessionName)}, keys...)
+}
+
+// LiteralKeysArgs builds the tmux argument vector that types text into the
+// agent window without looking up key names, so "Enter" or "C-c" in the text
+// is typed rather than pressed
+func LiteralKeysArgs(sessionName, text string) []string {
+	return []string{"send-keys", "-t", AgentTarget(sessionName), "-l", text}
+}
+
+// HasSessionArgs builds the tmux argument vector that succeeds only while
+// the session exists. The = prefix matches the name exactly, so a session is
+// not mistaken for another whose name it starts.
+func HasSessionArgs(sessionName string) []string {
+	return []string{"has-session", "-t", "=" + sessionName}
+}
+
+// CapturePaneArgs builds the tmux argument vector that prints the agent
+// window of a session, with wrapped lines joined
+func CapturePaneArgs(sessionName string) []string {
+	return []string{"capture-pane", "-p", "-J", "-t", AgentTarget(sessionName)}
+}
+
+// ScrollbackArgs builds the tmux argument vector that prints the last lines
+// of the agent window's history, or all of it when lines is 0, with wrapped
+// lines joined
+func ScrollbackArgs(sessionName string, lines int) []string {
+	start := "-"
+	if lines > 0 {
+		start = fmt.Sprintf("-%d", lines)
+	}
+	return []string{"capture-pane", "-p", "-J", "-S", start, "-t", AgentTarget(sessionName)}
+}
+
+// Delivery says how a message is typed into agent windows
+type Delivery struct {
+	Literal bool // type key names in the message as text
+	NoEnter bool // leave the message in the agent's input without submitting it
+	// AckTimeout, when set, holds the message back from submission until it
+	// shows in the agent's pane, waiting at most this long for it
+	AckTimeout time.Duration
+}
+
+// ackPoll is how often an unacknowledged delivery captures the agent pane
+const ackPoll = 100 * time.Millisecond
+
+// ackTail is how many characters from the end of a message must show in the
+// pane to acknowledge it
+const ackTail = 40
+
+// ErrSessionGone matches the errors of sessions whose tmux session no longer
+// exists, such as one killed while a broadcast was under way
+var ErrSessionGone = errors.New("tmux session is gone")
+
+// SessionGoneError reports a session that was gone by the time a message or
+// keys were to be sent to it
+type SessionGoneError struct {
+	Session string
+}
+
+func (e *SessionGoneError) Error() string {
+	return e.Session + ": " + ErrSessionGone.Error()
+}
+
+// Is makes SessionGoneError match ErrSessionGone
+func (e *SessionGoneError) Is(target error) bool {
+	return target == ErrSessionGone
+}
+
+// GoneSessions returns the sessions of the SessionGoneErrors in err,
+// including those joined by Broadcast and wrapped since
+func GoneSessions(err error) []string {
+	switch e := err.(type) {
+	case *SessionGoneError:
+		return []string{e.Session}
+	case interface{ Unwrap() []error }:
+		var gone []string
+		for _, joined := range e.Unwrap() {
+			gone = append(gone, GoneSessions(joined)...)
+		}
+		return gone
+	case interface{ Unwrap() error }:
+		return GoneSessions(e.Unwrap())
+	}
+	return nil
+}
+
+// Broadcaster delivers messages to agent windows through tmux send-keys
+type Broadcaster struct {
+	route func(sessionName string) CommandExecutor
+	poll  time.Duration
+}
+
+// NewBroadcaster creates a Broadcaster that runs tmux through the given executor
+func NewBroadcaster(executor CommandExecutor) *Broadcaster {
+	return NewRoutedBroadcaster(func(string) CommandExecutor { return executor })
+}
+
+// NewRoutedBroadcaster creates a Broadcaster that picks the executor for each
+// session, so sessions on remote hosts can be reached over ssh
+func NewRoutedBroadcaster(route func(sessionName string) CommandExecutor) *Broadcaster {
+	return &Broadcaster{route: route, poll: ackPoll}
+}
+
+// CheckSession returns a SessionGoneError unless the session still exists.
+// A remote session whose host cannot be reached counts as gone too.
+func (b *Broadcaster) CheckSession(sessionName string) error {
+	if err := b.route(sessionName).Execute("tmux", HasSessionArgs(sessionName)...); err != nil {
+		return &SessionGoneError{Session: sessionName}
+	}
+	return nil
+}
+
+// SendMessage types the message into a session's agent window and submits it.
+// A second Enter is sent because some agents treat the first one as part of the pasted input.
+func (b *Broadcaster) SendMessage(sessionName, message string) error {
+	return b.Deliver(sessionName, message, Delivery{})
+}
+
+// Deliver types the message into a session's agent window as delivery says,
+// submitting it like SendMessage unless NoEnter is set. With an AckTimeout
+// the message is only submitted once it shows in the pane; a message that
+// never does is left unsubmitted and reported.
+func (b *Broadcaster) Deliver(sessionName, message string, delivery Delivery) error {
+	executor := b.route(sessionName)
+	enterWithMessage := !delivery.Literal && !delivery.NoEnter && delivery.AckTimeout == 0
+	args := SendKeysArgs(sessionName, message)
+	if delivery.Literal {
+		args = LiteralKeysArgs(sessionName, message)
+	} else if enterWithMessage {
+		args = append(args, "Enter")
+	}
+	if err := executor.Execute("tmux", args...); err != nil {
+		// The session may have died since it was last checked
+		if goneErr := b.CheckSession(sessionName); goneErr != nil {
+			return goneErr
+		}
+		return fmt.Errorf("failed to send message to %s: %w", sessionName, err)
+	}
+	if delivery.AckTimeout > 0 {
+		if err := b.awaitEcho(executor, sessionName, message, delivery.AckTimeout); err != nil {
+			return err
+		}
+	}
+	if delivery.NoEnter {
+		return nil
+	}
+	if !enterWithMessage {
+		executor.Execute("tmux", SendKeysArgs(sessionName, "Enter")...)
+	}
+	executor.Execute("tmux", SendKeysArgs(sessionName, "Enter")...)
+	return nil
+}
+
+// awaitEcho captures a session's agent pane until the message shows in it
+func (b *Broadcaster) awaitEcho(executor CommandExecutor, sessionName, message string, timeout time.Duration) error {
+	capturer, ok := executor.(OutputExecutor)
+	if !ok {
+		return fmt.Errorf("cannot read the pane of %s to acknowledge the message", sessionName)
+	}
+	deadline := time.Now().Add(timeout)
+	for {
+		pane, err := capturer.ExecuteCommand("tmux", CapturePaneArgs(sessionName)...)
+		if err == nil && Echoed(string(pane), message) {
+			return nil
+		}
+		if time.Now().After(deadline) {
+			return fmt.Errorf("message did not show in %s within %s; it was typed but not submitted", sessionName, timeout)
+		}
+		time.Sleep(b.poll)
+	}
+}
+
+// Echoed reports whether the end of a message shows in pane content.
+// Whitespace and box-drawing characters are ignored on both sides, because
+// agent CLIs wrap long input inside a bordered box.
+func Echoed(pane, message string) bool {
+	strip := func(r rune) rune {
+		if unicode.IsSpace(r) || (r >= 0x2500 && r <= 0x257F) {
+			return -1
+		}
+		return r
+	}
+	tail := []rune(strings.Map(strip, message))
+	if len(tail) > ackTail {
+		tail = tail[len(tail)-ackTail:]
+	}
+	return strings.Contains(strings.Map(strip, pane), string(tail))
+}
+
+// SendKeys presses tmux keys such as C-c or Escape in a session's agent
+// window, without typing a message or submitting anything
+func (b *Broadcaster) SendKeys(sessionName string, keys ...string) error {
+	if err := b.route(sessionName).Execute("tmux", SendKeysArgs(sessionName, keys...)...); err != nil {
+		if goneErr := b.CheckSession(sessionName); goneErr != nil {
+			return goneErr
+		}
+		return fmt.Errorf("failed to send keys to %s: %w", sessionName, err)
+	}
+	return nil
+}
+
+// Broadcast sends the message to every session, continuing past failures.
+// Each session is checked right before its message is sent, and sessions
+// that are gone by then are skipped. The returned error joins the failures of
+// all sessions that could not be reached; GoneSessions picks out the skipped.
+func (b *Broadcaster) Broadcast(sessions []string, message string) error {
+	return b.BroadcastEach(sessions, func(string) string { return message })
+}
+
+// BroadcastEach is Broadcast with a message built for each session, such as a
+// template with the session's agent name filled in
+func (b *Broadcaster) BroadcastEach(sessions []string, message func(sessionName string) string) error {
+	var errs []error
+	for _, session := range sessions {
+		if err := b.CheckSession(session); err != nil {
+			errs = append(errs, err)
+			continue
+		}
+		if err := b.SendMessage(session, message(session)); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	return errors.Join(errs...)
+}
@@ -0,0 +1,329 @@
+package tmuxops
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+// recordingExecutor records every command and fails for the configured
+// targets, or for failAfter targets once they were used that many times
+type recordingExecutor struct {
+	commands  [][]string
+	failFor   map[string]bool
+	failAfter map[string]int
+}
+
+func (r *recordingExecutor) Execute(command string, args ...string) error {
+	r.commands = append(r.commands, append([]string{command}, args...))
+	if len(args) > 2 && r.failFor[args[2]] {
+		return fmt.Errorf("can't find session")
+	}
+	if len(args) > 2 {
+		if n, ok := r.failAfter[args[2]]; ok {
+			if n <= 0 {
+				return fmt.Errorf("can't find session")
+			}
+			r.failAfter[args[2]] = n - 1
+		}
+	}
+	return nil
+}
+
+func TestSendKeysArgs(t *testing.T) {
+	got := SendKeysArgs("agent-repo-abc123-sarah", "hello world", "Enter")
+	want := []string{"send-keys", "-t", "agent-repo-abc123-sarah:{start}", "hello world", "Enter"}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("SendKeysArgs() = %v, want %v", got, want)
+	}
+}
+
+func TestMarkAgentWindowArgs(t *testing.T) {
+	got := MarkAgentWindowArgs("agent-repo-abc123-sarah")
+	want := []string{"set-option", "-w", "-t", "agent-repo-abc123-sarah:{start}", "@uzi-window", "agent"}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("MarkAgentWindowArgs() = %v, want %v", got, want)
+	}
+}
+
+func TestMarkSessionArgs(t *testing.T) {
+	got := MarkSessionArgs("agent-repo-abc123-sarah", "claude")
+	want := []string{
+		"set-option", "-t", "agent-repo-abc123-sarah", "@uzi", "1", ";",
+		"set-option", "-t", "agent-repo-abc123-sarah", "@uzi_agent", "claude",
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("MarkSessionArgs() = %v, want %v", got, want)
+	}
+}
+
+func TestNewSessionScript(t *testing.T) {
+	script := NewSessionScript("agent-app-abc123-sarah", "/wt/sarah app", []string{"ANTHROPIC_API_KEY=sk-it's", "EMPTY="})
+	want := `new-session -d -s 'agent-app-abc123-sarah' -c '/wt/sarah app' -e 'ANTHROPIC_API_KEY=sk-it'\''s' -e 'EMPTY='` + "\n"
+	if script != want {
+		t.Errorf("NewSessionScript() = %q, want %q", script, want)
+	}
+	if args := SourceStdinArgs(); !reflect.DeepEqual(args, []string{"start-server", ";", "source-file", "-"}) {
+		t.Errorf("SourceStdinArgs() = %q", args)
+	}
+}
+
+func TestSendMessage(t *testing.T) {
+	executor := &recordingExecutor{}
+	b := NewBroadcaster(executor)
+
+	if err := b.SendMessage("agent-repo-abc123-sarah", `it's "quoted" $HOME`); err != nil {
+		t.Fatalf("SendMessage() error = %v", err)
+	}
+
+	want := [][]string{
+		{"tmux", "send-keys", "-t", "agent-repo-abc123-sarah:{start}", `it's "quoted" $HOME`, "Enter"},
+		{"tmux", "send-keys", "-t", "agent-repo-abc123-sarah:{start}", "Enter"},
+	}
+	if !reflect.DeepEqual(executor.commands, want) {
+		t.Errorf("SendMessage() commands = %v, want %v", executor.commands, want)
+	}
+}
+
+func TestDeliver(t *testing.T) {
+	const target = "agent-repo-abc123-sarah:{start}"
+	for _, tt := range []struct {
+		name     string
+		delivery Delivery
+		want     [][]string
+	}{
+		{"no enter", Delivery{NoEnter: true}, [][]string{
+			{"tmux", "send-keys", "-t", target, "Enter"},
+		}},
+		{"literal", Delivery{Literal: true}, [][]string{
+			{"tmux", "send-keys", "-t", target, "-l", "Enter"},
+			{"tmux", "send-keys", "-t", target, "Enter"},
+			{"tmux", "send-keys", "-t", target, "Enter"},
+		}},
+		{"literal without enter", Delivery{Literal: true, NoEnter: true}, [][]string{
+			{"tmux", "send-keys", "-t", target, "-l", "Enter"},
+		}},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			executor := &recordingExecutor{}
+			// The message is a key name, to tell typing it from pressing it
+			if err := NewBroadcaster(executor).Deliver("agent-repo-abc123-sarah", "Enter", tt.delivery); err != nil {
+				t.Fatalf("Deliver() error = %v", err)
+			}
+			if !reflect.DeepEqual(executor.commands, tt.want) {
+				t.Errorf("Deliver() commands = %v, want %v", executor.commands, tt.want)
+			}
+		})
+	}
+}
+
+// echoingExecutor is a recordingExecutor whose pane shows what was typed
+// after the given number of captures
+type echoingExecutor struct {
+	recordingExecutor
+	typed    string
+	captures int
+	echoAt   int // capture that first shows the typed text; 0 never does
+}
+
+func (e *echoingExecutor) Execute(command string, args ...string) error {
+	if len(args) > 3 && args[len(args)-1] != "Enter" {
+		e.typed = args[len(args)-1]
+	}
+	return e.recordingExecutor.Execute(command, args...)
+}
+
+func (e *echoingExecutor) ExecuteCommand(name string, args ...string) ([]byte, error) {
+	e.commands = append(e.commands, append([]string{name}, args...))
+	e.captures++
+	if e.echoAt > 0 && e.captures >= e.echoAt {
+		return []byte("╭────╮\n│ > " + e.typed + " │\n╰────╯"), nil
+	}
+	return []byte("╭────╮\n│ >  │\n╰────╯"), nil
+}
+
+func TestDeliverAck(t *testing.T) {
+	const target = "agent-repo-abc123-sarah:{start}"
+	capture := []string{"tmux", "capture-pane", "-p", "-J", "-t", target}
+
+	executor := &echoingExecutor{echoAt: 2}
+	b := NewBroadcaster(executor)
+	b.poll = time.Millisecond
+	if err := b.Deliver("agent-repo-abc123-sarah", "run the tests", Delivery{AckTimeout: time.Second}); err != nil {
+		t.Fatalf("Deliver() error = %v", err)
+	}
+	want := [][]string{
+		{"tmux", "send-keys", "-t", target, "run the tests"},
+		capture,
+		capture,
+		{"tmux", "send-keys", "-t", target, "Enter"},
+		{"tmux", "send-keys", "-t", target, "Enter"},
+	}
+	if !reflect.DeepEqual(executor.commands, want) {
+		t.Errorf("Deliver() commands = %v, want %v", executor.commands, want)
+	}
+
+	// A message that never shows is left unsubmitted
+	executor = &echoingExecutor{}
+	b = NewBroadcaster(executor)
+	b.poll = time.Millisecond
+	err := b.Deliver("agent-repo-abc123-sarah", "run the tests", Delivery{AckTimeout: 20 * time.Millisecond})
+	if err == nil || !strings.Contains(err.Error(), "not submitted") {
+		t.Errorf("Expected an unacknowledged message to fail, got %v", err)
+	}
+	for _, command := range executor.commands {
+		if command[len(command)-1] == "Enter" {
+			t.Errorf("Expected no Enter for an unacknowledged message, got %v", executor.commands)
+		}
+	}
+
+	// Without a way to read the pane there is nothing to acknowledge with
+	if err := NewBroadcaster(&recordingExecutor{}).Deliver("agent-repo-abc123-sarah", "hi", Delivery{AckTimeout: time.Second}); err == nil {
+		t.Error("Expected an ack without pane capture to fail")
+	}
+}
+
+func TestEchoed(t *testing.T) {
+	message := "Please rebase onto main and rerun the whole integration test suite"
+	wrapped := "│ > Please rebase onto main and rerun the whole   │\n│ integration test suite                        │"
+	if !Echoed(wrapped, message) {
+		t.Error("Expected a message wrapped inside the input box to be echoed")
+	}
+	if Echoed("│ > Please rebase onto main │", message) {
+		t.Error("Expected a partly typed message not to be echoed")
+	}
+	if !Echoed("", "") {
+		t.Error("Expected an empty message to be echoed")
+	}
+}
+
+func TestSendKeys(t *testing.T) {
+	executor := &recordingExecutor{}
+	if err := NewBroadcaster(executor).SendKeys("agent-repo-abc123-sarah", "Escape", "C-c"); err != nil {
+		t.Fatalf("SendKeys() error = %v", err)
+	}
+	want := [][]string{{"tmux", "send-keys", "-t", "agent-repo-abc123-sarah:{start}", "Escape", "C-c"}}
+	if !reflect.DeepEqual(executor.commands, want) {
+		t.Errorf("SendKeys() commands = %v, want %v", executor.commands, want)
+	}
+
+	failing := &recordingExecutor{failFor: map[string]bool{"agent-repo-abc123-sarah:{start}": true}}
+	if err := NewBroadcaster(failing).SendKeys("agent-repo-abc123-sarah", "C-c"); err == nil || !strings.Contains(err.Error(), "agent-repo-abc123-sarah") {
+		t.Errorf("Expected failure naming the session, got %v", err)
+	}
+}
+
+func TestSendMessageFailureSkipsEnter(t *testing.T) {
+	executor := &recordingExecutor{failFor: map[string]bool{"agent-repo-abc123-sarah:{start}": true}}
+	b := NewBroadcaster(executor)
+
+	err := b.SendMessage("agent-repo-abc123-sarah", "hello")
+	if err == nil || !strings.Contains(err.Error(), "agent-repo-abc123-sarah") {
+		t.Errorf("Expected failure naming the session, got %v", err)
+	}
+	// The failed send is followed by a check of the session, not by Enter
+	want := [][]string{
+		{"tmux", "send-keys", "-t", "agent-repo-abc123-sarah:{start}", "hello", "Enter"},
+		{"tmux", "has-session", "-t", "=agent-repo-abc123-sarah"},
+	}
+	if !reflect.DeepEqual(executor.commands, want) {
+		t.Errorf("Expected only the message send to be attempted, got %v", executor.commands)
+	}
+	if errors.Is(err, ErrSessionGone) {
+		t.Errorf("Expected a failed send to an existing session, got %v", err)
+	}
+}
+
+func TestBroadcastContinuesPastFailures(t *testing.T) {
+	executor := &recordingExecutor{failFor: map[string]bool{"agent-repo-abc123-john:{start}": true}}
+	b := NewBroadcaster(executor)
+
+	err := b.Broadcast([]string{"agent-repo-abc123-john", "agent-repo-abc123-sarah"}, "status?")
+	if err == nil || !strings.Contains(err.Error(), "agent-repo-abc123-john") {
+		t.Errorf("Expected joined error for john, got %v", err)
+	}
+
+	want := [][]string{
+		{"tmux", "has-session", "-t", "=agent-repo-abc123-john"},
+		{"tmux", "send-keys", "-t", "agent-repo-abc123-john:{start}", "status?", "Enter"},
+		{"tmux", "has-session", "-t", "=agent-repo-abc123-john"},
+		{"tmux", "has-session", "-t", "=agent-repo-abc123-sarah"},
+		{"tmux", "send-keys", "-t", "agent-repo-abc123-sarah:{start}", "status?", "Enter"},
+		{"tmux", "send-keys", "-t", "agent-repo-abc123-sarah:{start}", "Enter"},
+	}
+	if !reflect.DeepEqual(executor.commands, want) {
+		t.Errorf("Broadcast() commands = %v, want %v", executor.commands, want)
+	}
+}
+
+func TestBroadcastSkipsGoneSessions(t *testing.T) {
+	executor := &recordingExecutor{failFor: map[string]bool{"=agent-repo-abc123-john": true}}
+	b := NewBroadcaster(executor)
+
+	err := b.Broadcast([]string{"agent-repo-abc123-john", "agent-repo-abc123-sarah"}, "status?")
+	if !errors.Is(err, ErrSessionGone) {
+		t.Errorf("Expected john reported as gone, got %v", err)
+	}
+	if gone := GoneSessions(fmt.Errorf("broadcast: %w", err)); !reflect.DeepEqual(gone, []string{"agent-repo-abc123-john"}) {
+		t.Errorf("GoneSessions() = %v, want john", gone)
+	}
+	for _, command := range executor.commands {
+		if command[1] == "send-keys" && command[3] == "agent-repo-abc123-john:{start}" {
+			t.Errorf("Expected nothing sent to the gone session, got %v", command)
+		}
+	}
+	if last := executor.commands[len(executor.commands)-1]; last[3] != "agent-repo-abc123-sarah:{start}" {
+		t.Errorf("Expected sarah still messaged, got %v", executor.commands)
+	}
+
+	// A session that dies between the check and the send is reported as gone too
+	dying := &recordingExecutor{failFor: map[string]bool{"agent-repo-abc123-sarah:{start}": true}}
+	dying.failAfter = map[string]int{"=agent-repo-abc123-sarah": 1}
+	if err := NewBroadcaster(dying).Broadcast([]string{"agent-repo-abc123-sarah"}, "hi"); !reflect.DeepEqual(GoneSessions(err), []string{"agent-repo-abc123-sarah"}) {
+		t.Errorf("Expected the session that died mid-send reported as gone, got %v", err)
+	}
+	if GoneSessions(nil) != nil || GoneSessions(fmt.Errorf("boom")) != nil {
+		t.Error("Expected no gone sessions without SessionGoneErrors")
+	}
+}
+
+func TestBroadcastNoSessions(t *testing.T) {
+	if err := NewBroadcaster(&recordingExecutor{}).Broadcast(nil, "hello"); err != nil {
+		t.Errorf("Expected no error for empty session list, got %v", err)
+	}
+}
+
+func TestRoutedBroadcaster(t *testing.T) {
+	local, remote := &recordingExecutor{}, &recordingExecutor{}
+	b := NewRoutedBroadcaster(func(sessionName string) CommandExecutor {
+		if strings.HasSuffix(sessionName, "-remote") {
+			return remote
+		}
+		return local
+	})
+
+	if err := b.Broadcast([]string{"agent-repo-abc123-sarah", "agent-repo-abc123-remote"}, "hi"); err != nil {
+		t.Fatalf("Broadcast() error = %v", err)
+	}
+	if len(local.commands) != 3 || local.commands[1][3] != "agent-repo-abc123-sarah:{start}" {
+		t.Errorf("Expected the local session on the local executor, got %v", local.commands)
+	}
+	if len(remote.commands) != 3 || remote.commands[1][3] != "agent-repo-abc123-remote:{start}" {
+		t.Errorf("Expected the remote session on its own executor, got %v", remote.commands)
+	}
+}
+
+func TestAttachArgs(t *testing.T) {
+	if got, want := SwitchClientArgs("agent-repo-abc123-sarah"), []string{"switch-client", "-t", "agent-repo-abc123-sarah"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("SwitchClientArgs() = %v, want %v", got, want)
+	}
+	if got, want := NewWindowArgs("sarah", "ssh -t box tmux attach"), []string{"new-window", "-n", "sarah", "ssh -t box tmux attach"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("NewWindowArgs() = %v, want %v", got, want)
+	}
+	if got, want := SplitWindowArgs("TMUX= tmux attach"), []string{"split-window", "-h", "TMUX= tmux attach"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("SplitWindowArgs() = %v, want %v", got, want)
+	}
+}
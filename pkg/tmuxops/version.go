@@ -0,0 +1,92 @@
+package tmuxops
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// MinVersion is the oldest tmux uzi supports. new-session -c, which starts
+// agents in their worktree, and the pane_current_path and
+// pane_current_command formats need tmux 1.9.
+var MinVersion = Version{Major: 1, Minor: 9}
+
+// ErrUnsupportedVersion reports a tmux older than MinVersion
+var ErrUnsupportedVersion = errors.New("unsupported tmux version")
+
+// Version is a tmux release as reported by tmux -V
+type Version struct {
+	Major int
+	Minor int
+	Raw   string // as printed by tmux, e.g. "3.3a", "next-3.5" or "master"
+	Dev   bool   // a development or OS-bundled build without a release number, assumed current
+}
+
+// ParseVersion parses the output of tmux -V, such as "tmux 3.3a",
+// "tmux next-3.5", "tmux openbsd-7.4" or "tmux master"
+func ParseVersion(output string) (Version, error) {
+	raw, ok := strings.CutPrefix(strings.TrimSpace(output), "tmux ")
+	if !ok || raw == "" {
+		return Version{}, fmt.Errorf("unrecognized tmux -V output %q", strings.TrimSpace(output))
+	}
+	v := Version{Raw: raw}
+
+	number := raw
+	if prefix, rest, found := strings.Cut(raw, "-"); found {
+		if prefix != "next" {
+			// OS builds such as openbsd-7.4 number the OS release, not tmux
+			v.Dev = true
+			return v, nil
+		}
+		v.Dev = true
+		number = rest
+	}
+	majorText, minorText, _ := strings.Cut(number, ".")
+	major, err := strconv.Atoi(majorText)
+	if err != nil {
+		// Builds from git report "master" or a commit
+		v.Dev = true
+		return v, nil
+	}
+	// The minor version may carry a letter for patch releases, as in 3.3a
+	minorText = strings.TrimRight(minorText, "abcdefghijklmnopqrstuvwxyz")
+	minor, _ := strconv.Atoi(minorText)
+	v.Major, v.Minor = major, minor
+	return v, nil
+}
+
+// AtLeast reports whether the version is major.minor or newer. Development
+// builds without a release number count as newer than any release.
+func (v Version) AtLeast(major, minor int) bool {
+	if v.Dev && v.Major == 0 {
+		return true
+	}
+	if v.Major != major {
+		return v.Major > major
+	}
+	return v.Minor >= minor
+}
+
+// Supported returns an error wrapping ErrUnsupportedVersion if the version is
+// older than MinVersion
+func (v Version) Supported() error {
+	if v.AtLeast(MinVersion.Major, MinVersion.Minor) {
+		return nil
+	}
+	return fmt.Errorf("%w: tmux %s is installed but uzi needs tmux %s or newer; upgrade it with your package manager",
+		ErrUnsupportedVersion, v, MinVersion)
+}
+
+func (v Version) String() string {
+	if v.Raw != "" {
+		return v.Raw
+	}
+	return fmt.Sprintf("%d.%d", v.Major, v.Minor)
+}
+
+// HasSessionActivity reports whether tmux knows the session_activity format,
+// added in tmux 2.1
+func (v Version) HasSessionActivity() bool {
+	return v.AtLeast(2, 1)
+}
@@ -0,0 +1,71 @@
+package tmuxops
+
+import (
+	"errors"
+	"strings"
+	"testing"
+)
+
+func TestParseVersion(t *testing.T) {
+	tests := []struct {
+		output string
+		want   Version
+	}{
+		{"tmux 3.3a\n", Version{Major: 3, Minor: 3, Raw: "3.3a"}},
+		{"tmux 1.8", Version{Major: 1, Minor: 8, Raw: "1.8"}},
+		{"tmux 2.1", Version{Major: 2, Minor: 1, Raw: "2.1"}},
+		{"tmux next-3.5", Version{Major: 3, Minor: 5, Raw: "next-3.5", Dev: true}},
+		{"tmux openbsd-7.4", Version{Raw: "openbsd-7.4", Dev: true}},
+		{"tmux master", Version{Raw: "master", Dev: true}},
+	}
+	for _, tt := range tests {
+		got, err := ParseVersion(tt.output)
+		if err != nil {
+			t.Errorf("ParseVersion(%q) error: %v", tt.output, err)
+			continue
+		}
+		if got != tt.want {
+			t.Errorf("ParseVersion(%q) = %+v, want %+v", tt.output, got, tt.want)
+		}
+	}
+
+	for _, output := range []string{"", "screen 4.09", "tmux "} {
+		if _, err := ParseVersion(output); err == nil {
+			t.Errorf("ParseVersion(%q) should fail", output)
+		}
+	}
+}
+
+func TestVersionSupported(t *testing.T) {
+	for _, output := range []string{"tmux 1.9", "tmux 1.9a", "tmux 3.3a", "tmux next-3.5", "tmux openbsd-7.4", "tmux master"} {
+		version, _ := ParseVersion(output)
+		if err := version.Supported(); err != nil {
+			t.Errorf("%s should be supported: %v", output, err)
+		}
+	}
+
+	version, _ := ParseVersion("tmux 1.8")
+	err := version.Supported()
+	if !errors.Is(err, ErrUnsupportedVersion) {
+		t.Fatalf("tmux 1.8 should be unsupported, got %v", err)
+	}
+	if !strings.Contains(err.Error(), "tmux 1.8 is installed but uzi needs tmux 1.9 or newer") {
+		t.Errorf("Unexpected error message: %v", err)
+	}
+}
+
+func TestVersionHasSessionActivity(t *testing.T) {
+	tests := map[string]bool{
+		"tmux 1.9":    false,
+		"tmux 2.0":    false,
+		"tmux 2.1":    true,
+		"tmux 3.3a":   true,
+		"tmux master": true,
+	}
+	for output, want := range tests {
+		version, _ := ParseVersion(output)
+		if got := version.HasSessionActivity(); got != want {
+			t.Errorf("%s HasSessionActivity() = %v, want %v", output, got, want)
+		}
+	}
+}
@@ -0,0 +1,260 @@
+// Package trash keeps the agents `uzi kill` removed for a while, so that
+// `uzi undo` or `uzi trash restore` can bring them back. The tmux session of
+// a trashed agent is gone, but its worktree is moved under the trash
+// directory with its branch and uncommitted work intact, and its saved state
+// is kept here until it is restored or purged.
+package trash
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/nehpz/claudicus/pkg/state"
+	"github.com/nehpz/claudicus/pkg/vcs"
+)
+
+// Entry is one killed agent waiting in the trash
+type Entry struct {
+	ID        int              `json:"id"`
+	Repo      string           `json:"repo"` // origin remote of the agent's repository
+	Session   string           `json:"session"`
+	State     state.AgentState `json:"state"`                // as saved when the agent was killed
+	TrashPath string           `json:"trash_path,omitempty"` // where the worktree was moved; empty for shared sessions
+	KilledAt  time.Time        `json:"killed_at"`
+}
+
+// Agent returns the name of the trashed agent
+func (e Entry) Agent() string {
+	return state.AgentNameFromSession(e.Session)
+}
+
+// Expired reports whether the entry has been in the trash longer than retention
+func (e Entry) Expired(retention time.Duration, now time.Time) bool {
+	return now.Sub(e.KilledAt) >= retention
+}
+
+// Store persists the trash as JSON next to the agent state file, with the
+// trashed worktrees in a directory beside it
+type Store struct {
+	path string
+	dir  string
+}
+
+// NewStore returns a store at ~/.local/share/uzi/trash.json, keeping
+// worktrees in ~/.local/share/uzi/trash
+func NewStore() (*Store, error) {
+	homeDir, err := os.UserHomeDir()
+	if err != nil {
+		return nil, err
+	}
+	dataDir := filepath.Join(homeDir, ".local", "share", "uzi")
+	return NewStoreAt(filepath.Join(dataDir, "trash.json"), filepath.Join(dataDir, "trash")), nil
+}
+
+// NewStoreAt returns a store backed by the given file and worktree directory
+func NewStoreAt(path, dir string) *Store {
+	return &Store{path: path, dir: dir}
+}
+
+// WorktreePath returns a free path to move the worktree of a session to when
+// it is trashed; a session trashed again gets a numbered path
+func (s *Store) WorktreePath(sessionName string) string {
+	path := filepath.Join(s.dir, sessionName)
+	for n := 2; ; n++ {
+		if _, err := os.Stat(path); os.IsNotExist(err) {
+			return path
+		}
+		path = filepath.Join(s.dir, fmt.Sprintf("%s-%d", sessionName, n))
+	}
+}
+
+func (s *Store) load() ([]Entry, error) {
+	var entries []Entry
+	data, err := os.ReadFile(s.path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return entries, nil
+		}
+		return nil, err
+	}
+	if err := json.Unmarshal(data, &entries); err != nil {
+		return nil, fmt.Errorf("error parsing trash: %w", err)
+	}
+	return entries, nil
+}
+
+func (s *Store) save(entries []Entry) error {
+	if err := os.MkdirAll(filepath.Dir(s.path), 0755); err != nil {
+		return err
+	}
+	if entries == nil {
+		entries = []Entry{}
+	}
+	data, err := json.MarshalIndent(entries, "", "  ")
+	if err != nil {
+		return err
+	}
+	// Write then rename so a concurrent kill never reads a partial file
+	tmp := s.path + ".tmp"
+	if err := os.WriteFile(tmp, data, 0644); err != nil {
+		return err
+	}
+	return os.Rename(tmp, s.path)
+}
+
+// Add puts an entry in the trash, giving it an ID and the current time, and
+// returns it as trashed
+func (s *Store) Add(entry Entry) (Entry, error) {
+	entries, err := s.load()
+	if err != nil {
+		return Entry{}, err
+	}
+	entry.ID = 1
+	for _, trashed := range entries {
+		if trashed.ID >= entry.ID {
+			entry.ID = trashed.ID + 1
+		}
+	}
+	if entry.KilledAt.IsZero() {
+		entry.KilledAt = time.Now()
+	}
+	return entry, s.save(append(entries, entry))
+}
+
+// List returns the trashed entries of every repository, oldest first
+func (s *Store) List() ([]Entry, error) {
+	return s.load()
+}
+
+// ListRepo returns the trashed entries of one repository, oldest first
+func (s *Store) ListRepo(repo string) ([]Entry, error) {
+	entries, err := s.load()
+	if err != nil {
+		return nil, err
+	}
+	var matching []Entry
+	for _, entry := range entries {
+		if entry.Repo == repo {
+			matching = append(matching, entry)
+		}
+	}
+	return matching, nil
+}
+
+// Remove drops the entry with the given ID. It reports whether it was trashed.
+func (s *Store) Remove(id int) (bool, error) {
+	entries, err := s.load()
+	if err != nil {
+		return false, err
+	}
+	for i, entry := range entries {
+		if entry.ID == id {
+			return true, s.save(append(entries[:i], entries[i+1:]...))
+		}
+	}
+	return false, nil
+}
+
+// Find returns the newest entry of the repository for an agent name or a
+// "#id", and false if there is none
+func (s *Store) Find(repo, agentOrID string) (Entry, bool, error) {
+	entries, err := s.ListRepo(repo)
+	if err != nil {
+		return Entry{}, false, err
+	}
+	for i := len(entries) - 1; i >= 0; i-- {
+		entry := entries[i]
+		if agentOrID == fmt.Sprintf("#%d", entry.ID) || agentOrID == entry.Agent() || agentOrID == entry.Session {
+			return entry, true, nil
+		}
+	}
+	return Entry{}, false, nil
+}
+
+// Purge deletes a trashed entry for good: its worktree and branch, the
+// worktree state uzi kept for the session, and the entry itself. The branch
+// of an adopted session existed before uzi and is kept.
+func (s *Store) Purge(ctx context.Context, entry Entry) error {
+	if entry.TrashPath != "" {
+		branch := entry.State.BranchName
+		if entry.State.Adopted {
+			branch = ""
+		}
+		if err := DeleteWorktree(ctx, entry.TrashPath, branch); err != nil {
+			return err
+		}
+	}
+	if err := os.RemoveAll(filepath.Join(filepath.Dir(s.path), "worktree", entry.Session)); err != nil {
+		return err
+	}
+	_, err := s.Remove(entry.ID)
+	return err
+}
+
+// PurgeExpired purges the entries of every repository that have been in the
+// trash for retention or longer and returns the ones it purged. Entries that
+// fail to purge are left for the next attempt.
+func (s *Store) PurgeExpired(ctx context.Context, retention time.Duration, now time.Time) ([]Entry, error) {
+	entries, err := s.load()
+	if err != nil {
+		return nil, err
+	}
+	var purged []Entry
+	var errs []string
+	for _, entry := range entries {
+		if !entry.Expired(retention, now) {
+			continue
+		}
+		if err := s.Purge(ctx, entry); err != nil {
+			errs = append(errs, fmt.Sprintf("%s: %v", entry.Agent(), err))
+			continue
+		}
+		purged = append(purged, entry)
+	}
+	if len(errs) > 0 {
+		return purged, fmt.Errorf("could not purge %s", strings.Join(errs, "; "))
+	}
+	return purged, nil
+}
+
+// MoveWorktree moves a git worktree to a new path, keeping its branch and any
+// uncommitted work. The parent directory of to is created if needed.
+func MoveWorktree(ctx context.Context, from, to string) error {
+	if err := os.MkdirAll(filepath.Dir(to), 0755); err != nil {
+		return err
+	}
+	cmd := exec.CommandContext(ctx, "git", "-C", from, "worktree", "move", from, to)
+	if output, err := cmd.CombinedOutput(); err != nil {
+		return fmt.Errorf("git worktree move: %w: %s", err, strings.TrimSpace(string(output)))
+	}
+	return nil
+}
+
+// DeleteWorktree removes a git worktree and then its branch from the
+// repository the worktree belongs to; an empty branch keeps the branch. A
+// worktree that no longer exists is skipped.
+func DeleteWorktree(ctx context.Context, worktreePath, branch string) error {
+	if _, err := os.Stat(worktreePath); os.IsNotExist(err) {
+		return nil
+	}
+	output, err := exec.CommandContext(ctx, "git", "-C", worktreePath, "rev-parse", "--path-format=absolute", "--git-common-dir").Output()
+	if err != nil {
+		return fmt.Errorf("could not find the repository of %s: %w", worktreePath, err)
+	}
+	repoDir := strings.TrimSpace(string(output))
+
+	repo := vcs.Git{}
+	if err := repo.RemoveWorktree(ctx, repoDir, worktreePath); err != nil {
+		return err
+	}
+	if branch == "" {
+		return nil
+	}
+	return repo.DeleteBranch(ctx, repoDir, branch)
+}
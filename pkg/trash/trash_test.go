@@ -0,0 +1,165 @@
+package trash
+
+import (
+	"context"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/nehpz/claudicus/pkg/state"
+)
+
+func TestStore(t *testing.T) {
+	dir := t.TempDir()
+	store := NewStoreAt(filepath.Join(dir, "trash.json"), filepath.Join(dir, "trash"))
+
+	if entries, err := store.List(); err != nil || len(entries) != 0 {
+		t.Fatalf("Expected an empty trash without a file, got %v, %v", entries, err)
+	}
+	first, err := store.Add(Entry{Repo: "app", Session: "agent-app-abc123-sarah", State: state.AgentState{Prompt: "fix the login bug"}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if first.ID != 1 || first.KilledAt.IsZero() {
+		t.Errorf("Expected ID 1 and a kill time, got %+v", first)
+	}
+	second, _ := store.Add(Entry{Repo: "app", Session: "agent-app-def456-sarah"})
+	store.Add(Entry{Repo: "site", Session: "agent-site-abc123-bob"})
+
+	app, err := store.ListRepo("app")
+	if err != nil || len(app) != 2 {
+		t.Fatalf("Expected 2 entries for app, got %v, %v", app, err)
+	}
+
+	// The newest entry of an agent wins
+	if found, ok, _ := store.Find("app", "sarah"); !ok || found.ID != second.ID {
+		t.Errorf("Find(sarah) = %+v, %v, want #%d", found, ok, second.ID)
+	}
+	if found, ok, _ := store.Find("app", "#1"); !ok || found.State.Prompt != "fix the login bug" {
+		t.Errorf("Find(#1) = %+v, %v", found, ok)
+	}
+	if _, ok, _ := store.Find("app", "bob"); ok {
+		t.Error("Expected entries of another repository to be skipped")
+	}
+
+	if removed, err := store.Remove(1); err != nil || !removed {
+		t.Errorf("Remove(1) = %v, %v", removed, err)
+	}
+	if removed, _ := store.Remove(1); removed {
+		t.Error("Expected a second remove to find nothing")
+	}
+	// IDs keep counting after entries leave the trash
+	if third, _ := store.Add(Entry{Repo: "app", Session: "agent-app-abc123-sarah"}); third.ID != 4 {
+		t.Errorf("Expected ID 4, got %d", third.ID)
+	}
+
+	path := store.WorktreePath("agent-app-abc123-sarah")
+	if path != filepath.Join(dir, "trash", "agent-app-abc123-sarah") {
+		t.Errorf("WorktreePath() = %s", path)
+	}
+	os.MkdirAll(path, 0755)
+	if again := store.WorktreePath("agent-app-abc123-sarah"); again != path+"-2" {
+		t.Errorf("Expected a numbered path for a session trashed again, got %s", again)
+	}
+}
+
+func TestEntryExpired(t *testing.T) {
+	now := time.Now()
+	entry := Entry{KilledAt: now.Add(-2 * time.Hour)}
+	if entry.Expired(3*time.Hour, now) {
+		t.Error("Expected an entry inside the retention to be kept")
+	}
+	if !entry.Expired(time.Hour, now) {
+		t.Error("Expected an entry past the retention to expire")
+	}
+}
+
+func TestTrashAndPurgeWorktree(t *testing.T) {
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git not available")
+	}
+	dir := t.TempDir()
+	repo := filepath.Join(dir, "repo")
+	git := func(args ...string) {
+		t.Helper()
+		cmd := exec.Command("git", append([]string{"-c", "user.email=uzi@example.com", "-c", "user.name=uzi"}, args...)...)
+		cmd.Dir = repo
+		if output, err := cmd.CombinedOutput(); err != nil {
+			t.Fatalf("git %v: %v: %s", args, err, output)
+		}
+	}
+	os.MkdirAll(repo, 0755)
+	git("init", "-q")
+	git("commit", "-q", "--allow-empty", "-m", "initial")
+	worktree := filepath.Join(dir, "worktrees", "sarah")
+	git("worktree", "add", "-q", "-b", "sarah-branch", worktree)
+	os.WriteFile(filepath.Join(worktree, "work.txt"), []byte("uncommitted\n"), 0644)
+
+	ctx := context.Background()
+	store := NewStoreAt(filepath.Join(dir, "uzi", "trash.json"), filepath.Join(dir, "uzi", "trash"))
+	trashed := store.WorktreePath("agent-repo-abc123-sarah")
+	if err := MoveWorktree(ctx, worktree, trashed); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := os.Stat(filepath.Join(trashed, "work.txt")); err != nil {
+		t.Errorf("Expected the uncommitted work moved along, got %v", err)
+	}
+
+	entry, _ := store.Add(Entry{Repo: "app", Session: "agent-repo-abc123-sarah", TrashPath: trashed,
+		State: state.AgentState{BranchName: "sarah-branch"}, KilledAt: time.Now().Add(-2 * time.Hour)})
+	purged, err := store.PurgeExpired(ctx, time.Hour, time.Now())
+	if err != nil || len(purged) != 1 || purged[0].ID != entry.ID {
+		t.Fatalf("PurgeExpired() = %+v, %v", purged, err)
+	}
+	if _, err := os.Stat(trashed); !os.IsNotExist(err) {
+		t.Errorf("Expected the trashed worktree removed, got %v", err)
+	}
+	if err := exec.Command("git", "-C", repo, "rev-parse", "--verify", "-q", "sarah-branch").Run(); err == nil {
+		t.Error("Expected the branch deleted")
+	}
+	if entries, _ := store.List(); len(entries) != 0 {
+		t.Errorf("Expected the entry removed, got %+v", entries)
+	}
+}
+
+func TestPurgeKeepsAdoptedBranch(t *testing.T) {
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git not available")
+	}
+	dir := t.TempDir()
+	repo := filepath.Join(dir, "repo")
+	git := func(args ...string) {
+		t.Helper()
+		cmd := exec.Command("git", append([]string{"-c", "user.email=uzi@example.com", "-c", "user.name=uzi"}, args...)...)
+		cmd.Dir = repo
+		if output, err := cmd.CombinedOutput(); err != nil {
+			t.Fatalf("git %v: %v: %s", args, err, output)
+		}
+	}
+	os.MkdirAll(repo, 0755)
+	git("init", "-q")
+	git("commit", "-q", "--allow-empty", "-m", "initial")
+	git("branch", "feature-x")
+	worktree := filepath.Join(dir, "worktrees", "sarah")
+	git("worktree", "add", "-q", worktree, "feature-x")
+
+	ctx := context.Background()
+	store := NewStoreAt(filepath.Join(dir, "uzi", "trash.json"), filepath.Join(dir, "uzi", "trash"))
+	trashed := store.WorktreePath("agent-repo-abc123-sarah")
+	if err := MoveWorktree(ctx, worktree, trashed); err != nil {
+		t.Fatal(err)
+	}
+	entry, _ := store.Add(Entry{Repo: "app", Session: "agent-repo-abc123-sarah", TrashPath: trashed,
+		State: state.AgentState{BranchName: "feature-x", Adopted: true}})
+	if err := store.Purge(ctx, entry); err != nil {
+		t.Fatalf("Purge() error = %v", err)
+	}
+	if _, err := os.Stat(trashed); !os.IsNotExist(err) {
+		t.Errorf("Expected the trashed worktree removed, got %v", err)
+	}
+	if err := exec.Command("git", "-C", repo, "rev-parse", "--verify", "-q", "feature-x").Run(); err != nil {
+		t.Error("Expected the adopted branch kept")
+	}
+}
@@ -226,6 +226,15 @@ func TestFormatLastActivity(t *testing.T) {
 			},
 			expectedContains: "d ago",
 		},
+		{
+			name: "Timestamp with a zone offset",
+			session: SessionInfo{
+				Name:      "test-session",
+				AgentName: "claude",
+				UpdatedAt: now.Add(-2 * time.Hour).In(time.FixedZone("CEST", 2*60*60)).Format(time.RFC3339),
+			},
+			expectedContains: "2h ago",
+		},
 		{
 			name: "Invalid UpdatedAt, fallback to CreatedAt",
 			session: SessionInfo{
@@ -266,6 +275,19 @@ func TestFormatLastActivity(t *testing.T) {
 	}
 }
 
+func TestFormatAge(t *testing.T) {
+	item := NewSessionListItem(SessionInfo{Name: "test-session", CreatedAt: time.Now().Add(-3 * time.Hour).Format(time.RFC3339)})
+	if got := item.formatAge(); got != "started 3h ago" {
+		t.Errorf("formatAge() = %q, want %q", got, "started 3h ago")
+	}
+	if !strings.Contains(item.Description(), "started 3h ago") {
+		t.Errorf("Expected the age in the description, got %q", item.Description())
+	}
+	if got := NewSessionListItem(SessionInfo{Name: "test-session"}).formatAge(); got != "" {
+		t.Errorf("formatAge() without CreatedAt = %q, want empty", got)
+	}
+}
+
 func TestActivityBarInTitle(t *testing.T) {
 	now := time.Now().UTC()
 	session := SessionInfo{
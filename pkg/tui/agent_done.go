@@ -0,0 +1,40 @@
+// Copyright (c) Subtrace, Inc.
+// SPDX-License-Identifier: BSD-3-Clause
+
+package tui
+
+import (
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// AgentDoneMsg is sent when the activity monitor sees an agent signal that
+// its task is complete
+type AgentDoneMsg struct {
+	SessionName string
+}
+
+// waitForAgentDone turns the next agent the activity monitor finds done into
+// an AgentDoneMsg
+func (a *App) waitForAgentDone() tea.Cmd {
+	if a.activityMonitor == nil {
+		return nil
+	}
+	done := a.activityMonitor.DoneEvents()
+	return func() tea.Msg {
+		return AgentDoneMsg{SessionName: <-done}
+	}
+}
+
+// handleAgentDone announces a done agent, starts an agent queued for its
+// slot and, when uzi.yaml asks for it, checkpoints its work
+func (a *App) handleAgentDone(sessionName string) tea.Cmd {
+	agentName := extractAgentName(sessionName)
+	if !a.config.AutoCheckpointDone() {
+		return tea.Batch(a.showNotice(agentName+" is done", false), a.refreshSessions(), a.startQueued())
+	}
+	return tea.Batch(
+		a.showNotice(agentName+" is done; checkpointing", false),
+		a.checkpointJob(agentName, a.config.DoneCheckpointMessage(), nil),
+		a.startQueued(),
+	)
+}
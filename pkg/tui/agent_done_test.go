@@ -0,0 +1,108 @@
+// Copyright (c) Subtrace, Inc.
+// SPDX-License-Identifier: BSD-3-Clause
+
+package tui
+
+import (
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/nehpz/claudicus/pkg/config"
+	"github.com/nehpz/claudicus/pkg/jobs"
+	"github.com/nehpz/claudicus/pkg/spawnqueue"
+)
+
+// doneMockUzi records the checkpoints the TUI runs
+type doneMockUzi struct {
+	MockUziInterface
+	mu           sync.Mutex
+	checkpointed []string
+}
+
+func (m *doneMockUzi) RunCheckpoint(agentName string, message string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.checkpointed = append(m.checkpointed, agentName+": "+message)
+	return nil
+}
+
+func TestApp_AgentDone(t *testing.T) {
+	mock := &doneMockUzi{}
+	app := NewApp(mock)
+	defer app.Cleanup()
+
+	app.Update(AgentDoneMsg{SessionName: "agent-proj-abc123-sarah"})
+	if app.notice != "sarah is done" {
+		t.Errorf("Expected the done agent announced, got %q", app.notice)
+	}
+	if len(app.jobs.Jobs()) != 0 {
+		t.Error("Expected no checkpoint without done.checkpoint")
+	}
+
+	app.config = &config.Config{Done: &config.DoneConfig{Checkpoint: true, Message: "Finish task"}}
+	app.Update(AgentDoneMsg{SessionName: "agent-proj-abc123-sarah"})
+	if !strings.Contains(app.notice, "checkpointing") {
+		t.Errorf("Expected the checkpoint announced, got %q", app.notice)
+	}
+	list := app.jobs.Jobs()
+	if len(list) != 1 {
+		t.Fatalf("Expected one checkpoint job, got %d", len(list))
+	}
+	app.jobs.Wait(list[0].ID)
+
+	mock.mu.Lock()
+	defer mock.mu.Unlock()
+	if len(mock.checkpointed) != 1 || mock.checkpointed[0] != "sarah: Finish task" {
+		t.Errorf("Expected sarah checkpointed with the configured message, got %v", mock.checkpointed)
+	}
+}
+
+// queueMockUzi counts how often the TUI starts queued agents
+type queueMockUzi struct {
+	MockUziInterface
+	mu   sync.Mutex
+	runs int
+}
+
+func (m *queueMockUzi) RunQueue() error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.runs++
+	return nil
+}
+
+func TestApp_AgentDoneStartsQueued(t *testing.T) {
+	mock := &queueMockUzi{}
+	app := NewApp(mock)
+	defer app.Cleanup()
+	app.spawnQueue = spawnqueue.NewStoreAt(filepath.Join(t.TempDir(), "queue.json"))
+
+	app.Update(AgentDoneMsg{SessionName: "agent-proj-abc123-sarah"})
+	if len(app.jobs.Jobs()) != 0 {
+		t.Error("Expected nothing started while the queue is empty")
+	}
+
+	if _, err := app.spawnQueue.Push(spawnqueue.Entry{Repo: "app", Agent: "claude", Command: "claude"}); err != nil {
+		t.Fatal(err)
+	}
+	app.Update(AgentDoneMsg{SessionName: "agent-proj-abc123-sarah"})
+	list := app.jobs.Jobs()
+	if len(list) != 1 || list[0].Kind != jobs.KindSpawn || list[0].Target != "queue" {
+		t.Fatalf("Expected a spawn job for the queue, got %+v", list)
+	}
+	app.jobs.Wait(list[0].ID)
+
+	mock.mu.Lock()
+	defer mock.mu.Unlock()
+	if mock.runs != 1 {
+		t.Errorf("Expected the queue run once, got %d", mock.runs)
+	}
+
+	// The open jobs view lists what is still queued
+	app.Update(SpawnQueueMsg{Entries: []spawnqueue.Entry{{ID: 1, Command: "claude"}}})
+	if len(app.jobsView.queued) != 1 {
+		t.Errorf("Expected the queued spawn in the jobs view, got %+v", app.jobsView.queued)
+	}
+}
@@ -1,7 +1,11 @@
 package tui
 
 import (
+	"context"
+	"fmt"
+	"strings"
 	"testing"
+	"time"
 
 	tea "github.com/charmbracelet/bubbletea"
 )
@@ -152,3 +156,59 @@ func TestAgentFormEscapeKey(t *testing.T) {
 		t.Error("Progress modal should not be active after escape")
 	}
 }
+
+// cancellableSpawnUzi reports the first step of a spawn, then waits to be
+// cancelled like a spawn stuck installing dependencies
+type cancellableSpawnUzi struct {
+	MockUziInterface
+}
+
+func (m *cancellableSpawnUzi) SpawnAgentInteractive(ctx context.Context, opts string, progress chan<- SpawnEvent) error {
+	progress <- SpawnEvent{Step: ProgressStepSetupWorktree, Status: SpawnStepStarted, Agent: 1, Agents: 1, At: time.Now()}
+	<-ctx.Done()
+	return ctx.Err()
+}
+
+func TestAgentFormSpawnCancel(t *testing.T) {
+	app := NewApp(&cancellableSpawnUzi{})
+	defer app.Cleanup()
+	app.width, app.height = 80, 24
+
+	_, cmd := app.Update(AgentFormSubmitMsg{AgentType: "claude", Count: "1", Prompt: "add a login page"})
+	batch, ok := cmd().(tea.BatchMsg)
+	if !ok {
+		t.Fatalf("Expected a batch of commands, got %T", cmd)
+	}
+	msgs := make(chan tea.Msg, len(batch))
+	for _, c := range batch {
+		go func(c tea.Cmd) { msgs <- c() }(c)
+	}
+	next := func(want string) tea.Msg {
+		t.Helper()
+		for {
+			select {
+			case msg := <-msgs:
+				if fmt.Sprintf("%T", msg) == want {
+					return msg
+				}
+			case <-time.After(5 * time.Second):
+				t.Fatalf("Timed out waiting for %s", want)
+			}
+		}
+	}
+
+	app.Update(next("tui.SpawnProgressMsg"))
+	if app.progressModal.currentStep != ProgressStepSetupWorktree || app.progressModal.progress[ProgressStepSetupWorktree].started.IsZero() {
+		t.Error("Expected the worktree step started by the spawn event")
+	}
+
+	_, cmd = app.Update(tea.KeyMsg{Type: tea.KeyEsc})
+	if cmd == nil {
+		t.Fatal("Expected Esc to cancel the spawn")
+	}
+	app.Update(cmd())
+	app.Update(next("tui.ProgressErrorMsg"))
+	if !app.progressModal.cancelled || !strings.Contains(app.progressModal.View(), "rolled back") {
+		t.Errorf("Expected the spawn shown as cancelled:\n%s", app.progressModal.View())
+	}
+}
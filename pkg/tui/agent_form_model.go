@@ -7,6 +7,8 @@ import (
 	"strconv"
 	"strings"
 
+	"github.com/nehpz/claudicus/pkg/agents"
+
 	"github.com/charmbracelet/bubbles/textinput"
 	tea "github.com/charmbracelet/bubbletea"
 	"github.com/charmbracelet/lipgloss"
@@ -32,6 +34,11 @@ type AgentFormModel struct {
 	error       string
 	width       int
 	height      int
+	theme       *Theme
+
+	// Pre-filled into empty fields when the form opens, from defaultAgents
+	defaultAgentType string
+	defaultCount     string
 }
 
 // NewAgentFormModel creates and initializes an AgentFormModel
@@ -54,13 +61,35 @@ func NewAgentFormModel() AgentFormModel {
 		currentStep: StepAgentType,
 		active:      false,
 		error:       "",
+		theme:       DefaultTheme(),
+	}
+}
+
+// SetTheme switches the style profile used to render the form
+func (m *AgentFormModel) SetTheme(theme *Theme) {
+	m.theme = theme
+}
+
+// SetDefaults sets the agent type and count the form starts with, such as the
+// first of the default agents in uzi.yaml; an empty agent type pre-fills nothing
+func (m *AgentFormModel) SetDefaults(agentType string, count int) {
+	m.defaultAgentType, m.defaultCount = agentType, ""
+	if agentType != "" && count > 0 {
+		m.defaultCount = strconv.Itoa(count)
 	}
 }
 
-// SetActive sets the form's active state
+// SetActive sets the form's active state, pre-filling the default agent type
+// and count into empty fields when it opens
 func (m *AgentFormModel) SetActive(active bool) {
 	m.active = active
 	if active {
+		if m.agentType.Value() == "" {
+			m.agentType.SetValue(m.defaultAgentType)
+		}
+		if m.count.Value() == "" {
+			m.count.SetValue(m.defaultCount)
+		}
 		m.currentStep = StepAgentType
 		m.agentType.Focus()
 		m.count.Blur()
@@ -197,7 +226,7 @@ func (m *AgentFormModel) validateAgentType() error {
 		return errors.New("Agent type is required")
 	}
 
-	validTypes := []string{"claude", "cursor", "codex", "gemini", "random"}
+	validTypes := append(agents.Names(), agents.RandomAgent)
 	for _, validType := range validTypes {
 		if strings.EqualFold(value, validType) {
 			return nil
@@ -260,14 +289,19 @@ func (m AgentFormModel) View() string {
 		return ""
 	}
 
+	t := resolveTheme(m.theme)
 	style := lipgloss.NewStyle().
 		Border(lipgloss.RoundedBorder()).
 		BorderForeground(ClaudeSquadAccent).
 		Padding(1, 2).
 		Width(m.width - 4)
+	if t.Plain {
+		style = t.Border
+	}
+	marker := t.Glyph("► ", "> ")
 
-	title := ClaudeSquadPrimaryStyle.Bold(true).Render("Create New Agent")
-	stepInfo := ClaudeSquadMutedStyle.Render(fmt.Sprintf("Step %d of 3", int(m.currentStep)+1))
+	title := t.Primary.Bold(true).Render("Create New Agent")
+	stepInfo := t.Muted.Render(fmt.Sprintf("Step %d of 3", int(m.currentStep)+1))
 	header := fmt.Sprintf("%s\n%s\n", title, stepInfo)
 
 	var content strings.Builder
@@ -276,7 +310,7 @@ func (m AgentFormModel) View() string {
 
 	// Agent Type input
 	if m.currentStep == StepAgentType {
-		content.WriteString(ClaudeSquadAccentStyle.Render("► Agent Type:"))
+		content.WriteString(t.Accent.Render(marker + "Agent Type:"))
 	} else {
 		content.WriteString("  Agent Type:")
 	}
@@ -286,7 +320,7 @@ func (m AgentFormModel) View() string {
 
 	// Count input
 	if m.currentStep == StepCount {
-		content.WriteString(ClaudeSquadAccentStyle.Render("► Count:"))
+		content.WriteString(t.Accent.Render(marker + "Count:"))
 	} else {
 		content.WriteString("  Count:")
 	}
@@ -296,7 +330,7 @@ func (m AgentFormModel) View() string {
 
 	// Prompt input
 	if m.currentStep == StepPrompt {
-		content.WriteString(ClaudeSquadAccentStyle.Render("► Prompt:"))
+		content.WriteString(t.Accent.Render(marker + "Prompt:"))
 	} else {
 		content.WriteString("  Prompt:")
 	}
@@ -306,13 +340,13 @@ func (m AgentFormModel) View() string {
 
 	// Error display
 	if m.error != "" {
-		content.WriteString(ErrorStyle.Render("Error: " + m.error))
+		content.WriteString(t.Error.Render("Error: " + m.error))
 		content.WriteString("\n\n")
 	}
 
 	// Instructions
-	instructions := ClaudeSquadMutedStyle.Render(
-		"Enter: Next step  •  Tab: Next field  •  Shift+Tab: Previous field  •  Esc: Cancel")
+	instructions := t.Muted.Render(
+		strings.Join([]string{"Enter: Next step", "Tab: Next field", "Shift+Tab: Previous field", "Esc: Cancel"}, t.Glyph("  •  ", ", ")))
 	content.WriteString(instructions)
 
 	return style.Render(content.String())
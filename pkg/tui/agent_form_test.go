@@ -1,7 +1,9 @@
 package tui
 
 import (
+	"strings"
 	"testing"
+	"time"
 
 	tea "github.com/charmbracelet/bubbletea"
 )
@@ -26,6 +28,31 @@ func TestAgentFormBasicFunctionality(t *testing.T) {
 	}
 }
 
+func TestAgentFormDefaults(t *testing.T) {
+	form := NewAgentFormModel()
+	form.SetDefaults("codex", 2)
+	form.SetActive(true)
+	if form.agentType.Value() != "codex" || form.count.Value() != "2" {
+		t.Errorf("Expected the form pre-filled with codex x2, got %q x%q", form.agentType.Value(), form.count.Value())
+	}
+
+	// What was typed is kept over the defaults when the form reopens
+	form.agentType.SetValue("gemini")
+	form.SetActive(false)
+	form.SetDefaults("claude", 3)
+	form.SetActive(true)
+	if form.agentType.Value() != "gemini" || form.count.Value() != "2" {
+		t.Errorf("Expected the typed values kept, got %q x%q", form.agentType.Value(), form.count.Value())
+	}
+
+	empty := NewAgentFormModel()
+	empty.SetDefaults("", 0)
+	empty.SetActive(true)
+	if empty.agentType.Value() != "" || empty.count.Value() != "" {
+		t.Errorf("Expected no pre-fill without defaults, got %q x%q", empty.agentType.Value(), empty.count.Value())
+	}
+}
+
 func TestAgentFormStepNavigation(t *testing.T) {
 	form := NewAgentFormModel()
 	form.SetActive(true)
@@ -169,6 +196,87 @@ func TestProgressModalKeyHandling(t *testing.T) {
 	}
 }
 
+func TestProgressModalSpawnEvents(t *testing.T) {
+	modal := NewProgressModal()
+	modal.SetTheme(PlainTheme())
+	modal.SetActive(true)
+	start := time.Date(2026, 1, 2, 15, 4, 0, 0, time.UTC)
+	modal.now = func() time.Time { return start.Add(10 * time.Second) }
+	at := func(seconds float64) time.Time { return start.Add(time.Duration(seconds * float64(time.Second))) }
+
+	for _, event := range []SpawnEvent{
+		{Step: ProgressStepSetupWorktree, Status: SpawnStepStarted, At: at(0)},
+		{Step: ProgressStepSetupWorktree, Status: SpawnStepDone, At: at(1.5)},
+		{Step: ProgressStepCreateTmux, Status: SpawnStepStarted, At: at(1.5)},
+		{Step: ProgressStepCreateTmux, Status: SpawnStepDone, At: at(2)},
+		{Step: ProgressStepInstallDeps, Status: SpawnStepSkipped, At: at(2)},
+		{Step: ProgressStepDevServer, Status: SpawnStepStarted, At: at(2)},
+		{Step: ProgressStepDevServer, Status: SpawnStepDone, Note: "no answer on port 3000 after 30s", At: at(7)},
+		{Step: ProgressStepStartAgent, Status: SpawnStepStarted, At: at(7)},
+	} {
+		event.Agent, event.Agents = 1, 1
+		modal.Apply(event)
+	}
+
+	view := modal.View()
+	for _, want := range []string{
+		"done: Setting up git worktree (1.5s)",
+		"done: Creating tmux session (0.5s)",
+		"skipped: Installing dependencies",
+		"done: Starting dev server (5.0s)",
+		"no answer on port 3000 after 30s",
+		"in progress: Starting agent... (3.0s)",
+		"Press Esc to cancel",
+	} {
+		if !strings.Contains(view, want) {
+			t.Errorf("Expected %q in the progress modal:\n%s", want, view)
+		}
+	}
+	if strings.Contains(view, "Creating Agent 1 of") {
+		t.Error("Expected no agent count for a single agent")
+	}
+
+	// The steps start over for the next agent of the spawn
+	modal.Apply(SpawnEvent{Step: ProgressStepSetupWorktree, Status: SpawnStepStarted, Agent: 2, Agents: 2, At: at(9)})
+	view = modal.View()
+	if !strings.Contains(view, "Creating Agent 2 of 2") || !strings.Contains(view, "pending: Creating tmux session") {
+		t.Errorf("Expected the second agent's steps:\n%s", view)
+	}
+
+	modal.Complete()
+	if view := modal.View(); !strings.Contains(view, "Agent created successfully!") {
+		t.Errorf("Expected completion:\n%s", view)
+	}
+}
+
+func TestProgressModalCancel(t *testing.T) {
+	modal := NewProgressModal()
+	modal.SetTheme(PlainTheme())
+	modal.SetActive(true)
+
+	modal, cmd := modal.Update(tea.KeyMsg{Type: tea.KeyEsc})
+	if cmd == nil {
+		t.Fatal("Expected Esc to cancel the spawn under way")
+	}
+	if _, ok := cmd().(SpawnCancelMsg); !ok || !modal.Cancelling() || !modal.IsActive() {
+		t.Fatal("Expected a SpawnCancelMsg with the modal left open")
+	}
+	if !strings.Contains(modal.View(), "Cancelling and rolling back...") {
+		t.Errorf("Expected the cancellation shown:\n%s", modal.View())
+	}
+	if _, cmd := modal.Update(tea.KeyMsg{Type: tea.KeyEsc}); cmd != nil {
+		t.Error("Expected a second Esc not to cancel again")
+	}
+
+	modal.SetCancelled()
+	if !strings.Contains(modal.View(), "rolled back") {
+		t.Errorf("Expected the rollback shown:\n%s", modal.View())
+	}
+	if modal, _ = modal.Update(tea.KeyMsg{Type: tea.KeyEsc}); modal.IsActive() {
+		t.Error("Expected Esc to close a cancelled spawn")
+	}
+}
+
 func TestAgentFormKeyHandling(t *testing.T) {
 	form := NewAgentFormModel()
 	form.SetActive(true)
@@ -5,10 +5,12 @@ package tui
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"os"
 	"os/exec"
 	"regexp"
+	"sort"
 	"strconv"
 	"strings"
 	"time"
@@ -18,34 +20,113 @@ import (
 	"github.com/charmbracelet/lipgloss"
 	"github.com/nehpz/claudicus/pkg/activity"
 	"github.com/nehpz/claudicus/pkg/config"
+	"github.com/nehpz/claudicus/pkg/fleet"
+	"github.com/nehpz/claudicus/pkg/hosts"
+	"github.com/nehpz/claudicus/pkg/jobs"
+	"github.com/nehpz/claudicus/pkg/scrollback"
+	"github.com/nehpz/claudicus/pkg/spawnqueue"
+	"github.com/nehpz/claudicus/pkg/state"
+	"github.com/nehpz/claudicus/pkg/tmuxops"
 	"gopkg.in/yaml.v3"
 )
 
-// RefreshMsg is sent by the ticker to refresh sessions without clearing screen
-type RefreshMsg struct{}
+// PolicyViolationMsg reports an operation refused by the policy: section of
+// uzi.yaml
+type PolicyViolationMsg struct {
+	Violation *config.PolicyViolation
+}
+
+// BroadcastSkippedMsg reports the sessions a broadcast skipped because their
+// tmux session was gone by the time they were to be messaged
+type BroadcastSkippedMsg struct {
+	Sessions []string
+}
+
+// CommandErrorMsg reports an action run in the background, such as a nudge,
+// that failed
+type CommandErrorMsg struct {
+	Action string
+	Err    error
+}
+
+// RefreshMsg is sent by the ticker to refresh sessions without clearing screen.
+// Summary is set when sessions were loaded successfully.
+type RefreshMsg struct {
+	Summary *fleet.Summary
+}
 
 // TickMsg wraps time.Time for ticker messages
 type TickMsg time.Time
 
+// jobWorkers is how many checkpoints, kills, and spawns run at the same time
+const jobWorkers = 2
+
 // App represents the main TUI application
 type App struct {
-	uzi             UziInterface
-	list            *ListModel
-	diffPreview     *DiffPreviewModel
-	broadcastInput  *BroadcastInputModel
-	confirmModal    *ConfirmationModal
-	checkpointModal CheckpointModal
-	agentForm       AgentFormModel
-	progressModal   ProgressModal
-	keys            KeyMap
-	ticker          *time.Ticker
-	activityMonitor *activity.AgentActivityMonitor
-	monitorCtx      context.Context
-	monitorCancel   context.CancelFunc
-	width           int
-	height          int
-	loading         bool
-	splitView       bool // Toggle between list-only and split view
+	uzi               UziInterface
+	list              *ListModel
+	diffPreview       *DiffPreviewModel
+	broadcastInput    *BroadcastInputModel
+	searchInput       *SearchInputModel
+	presetInput       *PresetInputModel
+	retryPrompt       *RetryPromptModel
+	confirmModal      *ConfirmationModal
+	checkpointModal   CheckpointModal
+	agentForm         AgentFormModel
+	progressModal     ProgressModal
+	modals            *ModalStack
+	confirmOverlay    Modal
+	checkpointOverlay Modal
+	agentFormOverlay  Modal
+	progressOverlay   Modal
+	broadcastOverlay  Modal
+	searchOverlay     Modal
+	presetOverlay     Modal
+	retryOverlay      Modal
+	pipelineView      *PipelineView
+	helpView          *HelpView
+	jobsView          *JobsView
+	devLogView        *DevLogView
+	compareView       *CompareView
+	grepView          *GrepView
+	todosView         *TodosView
+	tutorialView      *TutorialView
+	palette           *CommandPalette
+	jobs              *jobs.Queue
+	spawnQueue        *spawnqueue.Store // Agents waiting under maxConcurrentAgents; nil if unavailable
+	announcedJobs     map[int]bool      // Finished jobs already reported on the status line
+	fleet             *fleet.Aggregator
+	summary           *fleet.Summary // Fleet header; nil until the first refresh
+	keys              KeyMap
+	theme             *Theme
+	config            *config.Config
+	configWatcher     *config.Watcher
+	notice            string // Transient status-line message
+	noticeIsError     bool
+	noticeSeq         int
+	tuiState          *TUIState
+	tuiStatePath      string
+	ticker            *time.Ticker
+	activityMonitor   *activity.AgentActivityMonitor
+	usage             *scrollback.UsageCache // Transcript usage behind the cost column
+	monitorCtx        context.Context
+	monitorCancel     context.CancelFunc
+	spawnCancel       context.CancelFunc // Cancels the spawn the progress modal shows
+	width             int
+	height            int
+	loading           bool
+	enriching         int                   // Rows of the first load still waiting for their status and diff counts
+	splitView         bool                  // Toggle between list-only and split view
+	diffWatcher       *activity.FileWatcher // Watches the worktree of the session the split view shows
+	diffWatchSession  string                // Session diffWatcher watches
+	diffWatchSeq      int                   // Bumped when diffWatcher is replaced, to drop its pending messages
+	diffRefreshDue    bool                  // A throttled diff reload is scheduled
+}
+
+// activityMonitorUser is implemented by UziInterface backends that fold the
+// TUI's activity monitor metrics into GetSessionsWithMetrics
+type activityMonitorUser interface {
+	SetActivityMonitor(monitor *activity.AgentActivityMonitor)
 }
 
 // NewApp creates a new TUI application instance
@@ -54,11 +135,20 @@ func NewApp(uzi UziInterface) *App {
 	list := NewListModel(80, 24)               // Default size, will be updated on first render
 	diffPreview := NewDiffPreviewModel(40, 24) // Default size, will be updated on first render
 	broadcastInput := NewBroadcastInputModel()
+	searchInput := NewSearchInputModel()
 	confirmModal := NewConfirmationModal()
 	checkpointModal := NewCheckpointModal()
 	agentForm := NewAgentFormModel()
 	progressModal := NewProgressModal()
 
+	// Restore pinned sessions from the previous run; a missing or unreadable file starts empty
+	tuiStatePath := DefaultTUIStatePath()
+	tuiState, _ := LoadTUIState(tuiStatePath)
+	list.SetPinned(tuiState.Pinned)
+
+	// Without a home directory the jobs view just shows no queued spawns
+	spawnQueue, _ := spawnqueue.NewStore()
+
 	activityMonitor := activity.NewAgentActivityMonitor()
 	// Create context for the monitor with cancellation
 	monitorCtx, monitorCancel := context.WithCancel(context.Background())
@@ -70,23 +160,342 @@ func NewApp(uzi UziInterface) *App {
 		monitorCancel()
 	}
 
-	return &App{
+	app := &App{
 		uzi:             uzi,
 		list:            &list,
 		diffPreview:     diffPreview,
 		broadcastInput:  broadcastInput,
+		searchInput:     searchInput,
+		presetInput:     NewPresetInputModel(),
+		retryPrompt:     NewRetryPromptModel(),
 		confirmModal:    confirmModal,
 		checkpointModal: checkpointModal,
 		agentForm:       agentForm,
 		progressModal:   progressModal,
 		keys:            DefaultKeyMap(),
+		theme:           DefaultTheme(),
+		jobs:            jobs.NewQueue(jobWorkers),
+		spawnQueue:      spawnQueue,
+		announcedJobs:   make(map[int]bool),
+		fleet:           fleet.NewAggregator(),
+		tuiState:        tuiState,
+		tuiStatePath:    tuiStatePath,
 		ticker:          nil, // Will be created in Init
 		activityMonitor: activityMonitor,
+		usage:           scrollback.NewUsageCache(),
 		monitorCtx:      monitorCtx,
 		monitorCancel:   monitorCancel,
 		loading:         true,
 		splitView:       false, // Start in list view
 	}
+	// Let the backend fold the monitor's metrics into the sessions it lists
+	if user, ok := uzi.(activityMonitorUser); ok && err == nil {
+		user.SetActivityMonitor(activityMonitor)
+	}
+	app.initModals()
+	return app
+}
+
+// initModals wraps the overlay components so they can be opened on the modal stack
+func (a *App) initModals() {
+	a.modals = NewModalStack()
+	a.confirmOverlay = &confirmModalAdapter{modal: a.confirmModal}
+	a.checkpointOverlay = &checkpointModalAdapter{modal: &a.checkpointModal}
+	a.agentFormOverlay = &agentFormAdapter{form: &a.agentForm}
+	a.progressOverlay = &progressModalAdapter{modal: &a.progressModal}
+	a.broadcastOverlay = &broadcastModal{
+		input:     a.broadcastInput,
+		keys:      &a.keys,
+		templates: func() []config.BroadcastTemplate { return a.config.BroadcastTemplates() },
+		channels:  a.channelNames,
+		onSubmit:  a.broadcastCmd,
+	}
+	a.pipelineView = NewPipelineView(&a.keys)
+	a.helpView = NewHelpView(&a.keys)
+	a.jobsView = NewJobsView(&a.keys)
+	a.devLogView = NewDevLogView(&a.keys)
+	a.compareView = NewCompareView(&a.keys)
+	a.grepView = NewGrepView(&a.keys, a.searchOutput)
+	a.todosView = NewTodosView(&a.keys, a.listTodos)
+	a.tutorialView = NewTutorialView(&a.keys, a.finishTutorial)
+	a.palette = NewCommandPalette(&a.keys)
+	a.searchOverlay = &searchModal{
+		input: a.searchInput,
+		list:  a.list,
+		keys:  &a.keys,
+	}
+	a.presetOverlay = &presetModal{
+		input:    a.presetInput,
+		keys:     &a.keys,
+		onSubmit: a.savePreset,
+	}
+	a.retryOverlay = &retryModal{
+		editor:   a.retryPrompt,
+		keys:     &a.keys,
+		onSubmit: a.retryCmd,
+	}
+}
+
+// SetTheme switches the style profile of the App and every view and modal it renders
+func (a *App) SetTheme(theme *Theme) {
+	a.theme = theme
+	a.list.SetTheme(theme)
+	a.diffPreview.SetTheme(theme)
+	a.broadcastInput.SetTheme(theme)
+	a.searchInput.SetTheme(theme)
+	a.presetInput.SetTheme(theme)
+	a.retryPrompt.SetTheme(theme)
+	a.confirmModal.SetTheme(theme)
+	a.checkpointModal.SetTheme(theme)
+	a.agentForm.SetTheme(theme)
+	a.progressModal.SetTheme(theme)
+	a.pipelineView.SetTheme(theme)
+	a.helpView.SetTheme(theme)
+	a.jobsView.SetTheme(theme)
+	a.devLogView.SetTheme(theme)
+	a.compareView.SetTheme(theme)
+	a.grepView.SetTheme(theme)
+	a.todosView.SetTheme(theme)
+	a.tutorialView.SetTheme(theme)
+	a.palette.SetTheme(theme)
+}
+
+// loadPipelineRuns fetches pipeline runs for the pipeline view
+func (a *App) loadPipelineRuns() tea.Cmd {
+	return func() tea.Msg {
+		runs, err := a.uzi.GetPipelineRuns()
+		if err != nil {
+			return PipelineRunsMsg{Error: err.Error()}
+		}
+		return PipelineRunsMsg{Runs: runs}
+	}
+}
+
+// showDiff loads a session's diff and its per-file breakdown into the split view
+func (a *App) showDiff(session *SessionInfo) {
+	a.diffPreview.LoadDiff(session)
+	var stat *state.DiffStat
+	if session != nil {
+		// Without a breakdown the preview lists the files git status reports
+		stat, _ = a.uzi.GetSessionDiffStat(session.Name)
+	}
+	a.diffPreview.SetDiffStat(stat)
+	a.diffPreview.SetAttachCommand(a.attachCommandLine(session))
+}
+
+// attachCommandLine is the shell command that attaches another terminal to a
+// session, over ssh for remote agents, or empty when it cannot be told
+func (a *App) attachCommandLine(session *SessionInfo) string {
+	if session == nil {
+		return ""
+	}
+	if agentState, err := a.uzi.GetSessionState(session.Name); err == nil && agentState != nil {
+		return hosts.ForState(*agentState).AttachCommandLine(session.Name)
+	}
+	if session.Host != "" {
+		// Without its state the ssh destination of the host is unknown
+		return ""
+	}
+	return hosts.Local().AttachCommandLine(session.Name)
+}
+
+// loadDevLog captures the dev server window of a session for the log view
+func (a *App) loadDevLog(sessionName string) tea.Cmd {
+	return func() tea.Msg {
+		content, err := a.uzi.GetDevLog(sessionName, devLogLines)
+		if err != nil {
+			return DevLogMsg{SessionName: sessionName, Error: err.Error()}
+		}
+		return DevLogMsg{SessionName: sessionName, Content: content}
+	}
+}
+
+// loadCompare loads the diffs of two sessions for the compare view
+func (a *App) loadCompare(left, right string) tea.Cmd {
+	return func() tea.Msg {
+		return CompareMsg{Left: a.compareSide(left), Right: a.compareSide(right)}
+	}
+}
+
+// compareSide loads one session's full diff and its per-file breakdown
+func (a *App) compareSide(sessionName string) CompareSide {
+	side := CompareSide{SessionName: sessionName}
+	patcher, ok := a.uzi.(sessionPatcher)
+	if !ok {
+		side.Error = "comparing diffs is not supported by this backend"
+		return side
+	}
+	patch, err := patcher.GetSessionPatch(sessionName)
+	if err != nil {
+		side.Error = err.Error()
+		return side
+	}
+	side.Patch = patch
+	// Without a breakdown the diff still shows, but overlapping files are unknown
+	side.Stat, _ = a.uzi.GetSessionDiffStat(sessionName)
+	return side
+}
+
+// searchOutput searches the output of every agent for the grep view
+func (a *App) searchOutput(pattern string) tea.Cmd {
+	return func() tea.Msg {
+		searcher, ok := a.uzi.(outputSearcher)
+		if !ok {
+			return GrepMsg{Pattern: pattern, Error: "searching agent output is not supported by this backend"}
+		}
+		matches, err := searcher.SearchOutput(pattern)
+		if err != nil {
+			return GrepMsg{Pattern: pattern, Error: err.Error()}
+		}
+		return GrepMsg{Pattern: pattern, Matches: matches}
+	}
+}
+
+// listTodos collects the outstanding items of every agent for the todos view
+func (a *App) listTodos() tea.Cmd {
+	return func() tea.Msg {
+		lister, ok := a.uzi.(todoLister)
+		if !ok {
+			return TodosMsg{Error: "listing agent todos is not supported by this backend"}
+		}
+		todos, err := lister.ListTodos()
+		if err != nil {
+			return TodosMsg{Error: err.Error()}
+		}
+		return TodosMsg{Todos: todos}
+	}
+}
+
+// ShowTutorialOnFirstRun opens the tutorial unless it was finished or
+// skipped in an earlier run
+func (a *App) ShowTutorialOnFirstRun() {
+	if !a.tuiState.TutorialSeen {
+		a.modals.Open(a.tutorialView)
+	}
+}
+
+// finishTutorial remembers that the tutorial was seen so later runs start
+// straight on the list
+func (a *App) finishTutorial() tea.Cmd {
+	a.tuiState.TutorialSeen = true
+	if err := a.tuiState.Save(a.tuiStatePath); err != nil {
+		return a.showNotice(fmt.Sprintf("tutorial will show again next run: %v", err), true)
+	}
+	return nil
+}
+
+// savePreset saves the list's current filter, tag, and search under name and
+// remembers it across runs
+func (a *App) savePreset(name string) tea.Cmd {
+	preset := a.list.FilterPreset()
+	preset.Name = name
+	number, err := a.tuiState.SavePreset(preset)
+	if err != nil {
+		return a.showNotice(err.Error(), true)
+	}
+	if err := a.tuiState.Save(a.tuiStatePath); err != nil {
+		// The preset still applies for this run even if it can't be persisted
+		return a.showNotice(fmt.Sprintf("preset %q saved on %d for this run only: %v", name, number, err), true)
+	}
+	return a.showNotice(fmt.Sprintf("preset %q saved on %d", name, number), false)
+}
+
+// applyPreset replaces the list's filter, tag, and search with a saved preset
+func (a *App) applyPreset(number int) tea.Cmd {
+	preset, ok := a.tuiState.Preset(number)
+	if !ok {
+		return a.showNotice(fmt.Sprintf("no preset on %d (F saves the current filter)", number), true)
+	}
+	a.list.ApplyFilterPreset(preset)
+	a.searchInput.SetValue(preset.Search)
+	return a.showNotice(fmt.Sprintf("preset %q", preset.Name), false)
+}
+
+// paletteCommands lists every action for the command palette along with the
+// saved filter presets. Each runs by replaying its key, so remapped keys and
+// the key handlers stay the single source of behavior.
+func (a *App) paletteCommands() []PaletteCommand {
+	k := a.keys
+	bindings := []key.Binding{
+		k.NewAgent, k.Kill, k.Checkpoint, k.Nudge, k.Retry, k.Pin, k.Mark, k.Broadcast,
+		k.Filter, k.FilterStuck, k.FilterWorking, k.FilterTag, k.SavePreset, k.Clear,
+		k.Tab, k.ToggleCommits, k.Config, k.Pipelines, k.Jobs, k.DevLog, k.Compare, k.Grep, k.Todos, k.Help, k.Quit,
+	}
+
+	// Enter's help says "select"; on the list it attaches
+	commands := []PaletteCommand{{Title: "attach to agent", Keys: k.Enter.Help().Key, Msg: bindingKeyMsg(k.Enter)}}
+	for _, binding := range bindings {
+		if binding.Enabled() {
+			commands = append(commands, PaletteCommand{Title: binding.Help().Desc, Keys: binding.Help().Key, Msg: bindingKeyMsg(binding)})
+		}
+	}
+
+	presetKeys := k.Presets.Keys()
+	for i, preset := range a.tuiState.Presets {
+		if i >= len(presetKeys) {
+			break
+		}
+		commands = append(commands, PaletteCommand{
+			Title: fmt.Sprintf("apply preset %q", preset.Name),
+			Keys:  presetKeys[i],
+			Msg:   keyMsgFor(presetKeys[i]),
+		})
+	}
+	return commands
+}
+
+// retryCmd replaces a session with a new agent running the edited prompt in
+// the background and refreshes the list once it is up
+func (a *App) retryCmd(sessionName, prompt string) tea.Cmd {
+	job := a.jobs.Enqueue(jobs.KindRespawn, extractAgentName(sessionName), func() error {
+		_, err := a.uzi.RespawnWithPrompt(sessionName, prompt)
+		return err
+	})
+	return a.awaitJob(job.ID, func(jobs.Job) tea.Msg { return RefreshMsg{} })
+}
+
+// broadcastCmd sends a message to all agents, or to the subscribers of the
+// channel it starts with, and refreshes the session list
+func (a *App) broadcastCmd(message string) tea.Cmd {
+	return func() tea.Msg {
+		var err error
+		if channel, text := parseChannelMessage(message); channel != "" {
+			cb, ok := a.uzi.(channelBroadcaster)
+			if !ok || text == "" {
+				return nil
+			}
+			err = cb.RunChannelBroadcast(channel, text)
+		} else {
+			err = a.uzi.RunBroadcast(message)
+		}
+		if err != nil {
+			var violation *config.PolicyViolation
+			if errors.As(err, &violation) {
+				return PolicyViolationMsg{Violation: violation}
+			}
+			if gone := tmuxops.GoneSessions(err); len(gone) > 0 {
+				return BroadcastSkippedMsg{Sessions: gone}
+			}
+			return CommandErrorMsg{Action: "broadcast", Err: err}
+		}
+		// Refresh sessions after broadcast
+		return RefreshMsg{}
+	}
+}
+
+// channelNames returns the broadcast channels "#" completes: those configured
+// in uzi.yaml and those loaded sessions subscribe to
+func (a *App) channelNames() []string {
+	seen := map[string]bool{}
+	var channels []string
+	for _, channel := range append(a.config.ChannelNames(), a.list.Channels()...) {
+		if !seen[channel] {
+			seen[channel] = true
+			channels = append(channels, channel)
+		}
+	}
+	sort.Strings(channels)
+	return channels
 }
 
 // tickEvery returns a command that sends TickMsg every duration
@@ -99,46 +508,55 @@ func tickEvery(d time.Duration) tea.Cmd {
 // refreshSessions returns a command that fetches sessions and sends RefreshMsg
 func (a *App) refreshSessions() tea.Cmd {
 	return func() tea.Msg {
-		// Load sessions via UziInterface
-		sessions, err := a.uzi.GetSessions()
+		// Load sessions with the activity monitor's metrics via UziInterface
+		sessions, err := a.uzi.GetSessionsWithMetrics()
 		if err != nil {
 			// For now, just return the refresh message even on error
 			// In a production app, you might want to handle errors differently
 			return RefreshMsg{}
 		}
-		// Get metrics from activity monitor (safe call - won't block)
-		monitorMetrics := make(map[string]*activity.Metrics)
-		if a.activityMonitor != nil {
-			monitorMetrics = a.activityMonitor.UpdateAll()
-		}
-
-		// Merge monitor metrics into sessions (create new slice to avoid mutation)
-		updatedSessions := make([]SessionInfo, len(sessions))
-		copy(updatedSessions, sessions)
-		for i, session := range updatedSessions {
-			if metrics, exists := monitorMetrics[session.Name]; exists {
-				updatedSessions[i].Insertions = metrics.Insertions
-				updatedSessions[i].Deletions = metrics.Deletions
-				// Map activity status to session status
-				updatedSessions[i].Status = string(metrics.Status)
-			}
+
+		// Costs are read from transcripts only when the list shows them
+		if a.list.ShowsColumn(config.ColumnCost) {
+			sessions = a.withCosts(sessions)
 		}
-		sessions = updatedSessions
 
 		// Update the list with new sessions
 		a.list.LoadSessions(sessions)
 		a.loading = false
 
-		return RefreshMsg{}
+		summary := a.summarizeFleet(sessions)
+		return RefreshMsg{Summary: &summary}
+	}
+}
+
+// withCosts fills in the estimated cost of each local session from the
+// claude transcripts of its worktree
+func (a *App) withCosts(sessions []SessionInfo) []SessionInfo {
+	home, err := os.UserHomeDir()
+	if err != nil || a.usage == nil {
+		return sessions
+	}
+	for i, session := range sessions {
+		if session.Host != "" || session.WorktreePath == "" {
+			continue // Transcripts of remote agents stay on their host
+		}
+		if usage, err := a.usage.Usage(scrollback.TranscriptDir(home, session.WorktreePath)); err == nil {
+			sessions[i].CostUSD = usage.CostUSD
+		}
 	}
+	return sessions
 }
 
 // Init implements tea.Model interface
 func (a *App) Init() tea.Cmd {
 	// Start the 2-second ticker and initial session load
 	return tea.Batch(
-		a.refreshSessions(),      // Load sessions immediately
+		a.loadSessions(),         // Load sessions immediately
 		tickEvery(2*time.Second), // Start ticker for smooth updates
+		a.waitForConfigChange(),  // Pick up uzi.yaml edits while running
+		a.waitForJobChange(),     // Track background checkpoints, kills, and spawns
+		a.waitForAgentDone(),     // Announce agents that finish their task
 	)
 }
 
@@ -148,66 +566,9 @@ func (a *App) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 
 	switch msg := msg.(type) {
 	case tea.KeyMsg:
-		// Handle confirmation modal when visible
-		if a.confirmModal != nil && a.confirmModal.IsVisible() {
-			var modalCmd tea.Cmd
-			a.confirmModal, modalCmd = a.confirmModal.Update(msg)
-			return a, modalCmd
-		}
-
-		// Handle checkpoint modal when visible
-		if a.checkpointModal.IsVisible() {
-			var modalCmd tea.Cmd
-			a.checkpointModal, modalCmd = a.checkpointModal.Update(msg)
-			return a, modalCmd
-		}
-
-		// Handle agent form when active
-		if a.agentForm.IsActive() {
-			var formCmd tea.Cmd
-			a.agentForm, formCmd = a.agentForm.Update(msg)
-			return a, formCmd
-		}
-
-		// Handle progress modal when active
-		if a.progressModal.IsActive() {
-			var progressCmd tea.Cmd
-			a.progressModal, progressCmd = a.progressModal.Update(msg)
-			return a, progressCmd
-		}
-
-		// Handle broadcast input when active
-		if a.broadcastInput.IsActive() {
-			switch {
-			case key.Matches(msg, a.keys.Enter):
-				// Execute broadcast and deactivate input
-				message := a.broadcastInput.Value()
-				a.broadcastInput.SetActive(false)
-
-				if message != "" {
-					return a, func() tea.Msg {
-						err := a.uzi.RunBroadcast(message)
-						if err != nil {
-							// Handle error - for now just continue
-							return nil
-						}
-						// Refresh sessions after broadcast
-						return RefreshMsg{}
-					}
-				}
-				return a, nil
-
-			case key.Matches(msg, a.keys.Escape):
-				// Cancel broadcast input
-				a.broadcastInput.SetActive(false)
-				return a, nil
-
-			default:
-				// Delegate to broadcast input
-				var cmd tea.Cmd
-				a.broadcastInput, cmd = a.broadcastInput.Update(msg)
-				return a, cmd
-			}
+		// Only the top modal receives key events while any overlay is open
+		if cmd, handled := a.modals.HandleKey(msg); handled {
+			return a, cmd
 		}
 
 		// Handle key events
@@ -215,6 +576,17 @@ func (a *App) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 		case key.Matches(msg, a.keys.Quit):
 			return a, tea.Quit
 
+		case key.Matches(msg, a.keys.Palette):
+			// Search every action instead of remembering its key
+			a.palette.SetCommands(a.paletteCommands())
+			a.modals.Open(a.palette)
+			return a, nil
+
+		case key.Matches(msg, a.keys.Help):
+			// Show all key bindings
+			a.modals.Open(a.helpView)
+			return a, nil
+
 		case key.Matches(msg, a.keys.Tab):
 			// Toggle between list view and split view
 			a.splitView = !a.splitView
@@ -222,9 +594,11 @@ func (a *App) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 			// When entering split view, load diff for selected session
 			if a.splitView {
 				if selected := a.list.SelectedSession(); selected != nil {
-					a.diffPreview.LoadDiff(selected)
+					return a, a.loadDiffPreview(selected)
 				}
+				return a, nil
 			}
+			a.stopDiffWatch()
 			return a, nil
 
 		case key.Matches(msg, a.keys.Config):
@@ -248,29 +622,21 @@ func (a *App) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 				return a, func() tea.Msg { return err }
 			}
 
-			// Validate configuration
-			if cfg.DevCommand == nil || *cfg.DevCommand == "" {
-				return a, func() tea.Msg { return fmt.Errorf("devCommand is empty") }
-			}
-
-			if cfg.PortRange == nil || !isValidPortRange(*cfg.PortRange) {
-				return a, func() tea.Msg { return fmt.Errorf("Invalid portRange") }
+			if err := validateConfig(cfg); err != nil {
+				return a, func() tea.Msg { return err }
 			}
 
 			return a, nil
 
 		case key.Matches(msg, a.keys.Enter):
+			// Enter on a run header collapses or expands the run
+			if run := a.list.SelectedRun(); run != "" {
+				a.list.ToggleRun(run)
+				return a, nil
+			}
 			// Handle session selection/attachment
 			if selected := a.list.SelectedSession(); selected != nil {
-				// Attach to the selected session
-				return a, func() tea.Msg {
-					err := a.uzi.AttachToSession(selected.Name)
-					if err != nil {
-						// Handle error - for now just continue
-						return nil
-					}
-					return tea.Quit // Exit TUI after attaching
-				}
+				return a, a.attachSession(selected.Name)
 			}
 
 		case key.Matches(msg, a.keys.Kill):
@@ -279,13 +645,17 @@ func (a *App) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 				// Extract agent name from session name for fat-finger protection
 				agentName := extractAgentName(selected.Name)
 				a.confirmModal.SetRequiredAgentName(agentName)
-				a.confirmModal.SetVisible(true)
+				// Offer a checkpoint first if the kill would lose work
+				if work, err := a.uzi.InspectKill(selected.Name); err == nil && !work.Empty() {
+					a.confirmModal.SetKillWarning(work.KillWarning(agentName))
+				}
+				a.modals.Open(a.confirmOverlay)
 				return a, nil
 			}
 
 		case key.Matches(msg, a.keys.Broadcast):
 			// Activate broadcast input prompt
-			a.broadcastInput.SetActive(true)
+			a.modals.Open(a.broadcastOverlay)
 			a.broadcastInput.SetWidth(a.width)
 			return a, nil
 
@@ -307,8 +677,40 @@ func (a *App) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 			return a, nil
 
 		case key.Matches(msg, a.keys.Clear):
-			// Clear any active filter
+			// Clear any active filter and search
 			a.list.ClearFilter()
+			a.searchInput.Reset()
+			a.list.ClearSearch()
+			return a, nil
+
+		case key.Matches(msg, a.keys.FilterTag):
+			// Show the agents with the next tag in use
+			a.list.CycleTagFilter()
+			return a, nil
+
+		case key.Matches(msg, a.keys.SavePreset):
+			// Name the current filter combination so a number key brings it back
+			if a.list.FilterPreset().IsEmpty() {
+				return a, a.showNotice("nothing to save: set a status filter, tag, or search first", true)
+			}
+			a.modals.Open(a.presetOverlay)
+			a.presetInput.SetWidth(a.width)
+			return a, nil
+
+		case key.Matches(msg, a.keys.Presets):
+			// Switch to a saved filter preset
+			return a, a.applyPreset(a.keys.PresetNumber(msg))
+
+		case key.Matches(msg, a.keys.Filter):
+			// Open fuzzy search input
+			a.modals.Open(a.searchOverlay)
+			a.searchInput.SetWidth(a.width)
+			return a, nil
+
+		case key.Matches(msg, a.keys.Escape) && a.list.SearchQuery() != "":
+			// Clear an applied search
+			a.searchInput.Reset()
+			a.list.ClearSearch()
 			return a, nil
 
 		case key.Matches(msg, a.keys.Checkpoint):
@@ -319,14 +721,103 @@ func (a *App) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 				if err == nil {
 					a.checkpointModal.SetAgents(sessions)
 					a.checkpointModal.SetSize(a.width, a.height)
-					a.checkpointModal.SetVisible(true)
+					a.modals.Open(a.checkpointOverlay)
 				}
 				return a, nil
 			}
 
+		case key.Matches(msg, a.keys.Nudge):
+			// Nudge the selected agent past a waiting prompt
+			if selected := a.list.SelectedSession(); selected != nil {
+				agentName := extractAgentName(selected.Name)
+				return a, func() tea.Msg {
+					if err := a.uzi.RunNudge(agentName); err != nil {
+						return CommandErrorMsg{Action: "nudge " + agentName, Err: err}
+					}
+					// Refresh sessions so the new status shows up
+					return RefreshMsg{}
+				}
+			}
+
+		case key.Matches(msg, a.keys.Retry):
+			// Edit the prompt of a stuck or failed agent and start over with it
+			if selected := a.list.SelectedSession(); selected != nil {
+				agentName := extractAgentName(selected.Name)
+				if NewSessionListItem(*selected).getActivityStatus() == "working" {
+					return a, a.showNotice(agentName+" is still working; retry agents that are stuck or failed", true)
+				}
+				a.retryPrompt.Edit(selected.Name, selected.Prompt)
+				a.retryPrompt.SetWidth(a.width)
+				a.modals.Open(a.retryOverlay)
+			}
+			return a, nil
+
+		case key.Matches(msg, a.keys.Pipelines):
+			// Show pipeline runs and load their progress
+			a.modals.Open(a.pipelineView)
+			return a, a.loadPipelineRuns()
+
+		case key.Matches(msg, a.keys.Jobs):
+			// Show background jobs
+			a.jobsView.SetJobs(a.jobs.Jobs())
+			a.modals.Open(a.jobsView)
+			return a, a.loadSpawnQueue()
+
+		case key.Matches(msg, a.keys.DevLog):
+			// Tail the selected agent's dev server without attaching to tmux
+			if selected := a.list.SelectedSession(); selected != nil {
+				a.devLogView.Open(selected.Name)
+				a.devLogView.SetSize(a.width, a.height)
+				a.modals.Open(a.devLogView)
+				return a, a.loadDevLog(selected.Name)
+			}
+			return a, nil
+
+		case key.Matches(msg, a.keys.Mark):
+			// Mark or unmark the selected session for compare
+			if selected := a.list.SelectedSession(); selected != nil {
+				a.list.ToggleMark(selected.Name)
+			}
+			return a, nil
+
+		case key.Matches(msg, a.keys.Compare):
+			// Show the diffs of the two marked agents side by side
+			marked := a.list.MarkedSessions()
+			if len(marked) != 2 {
+				return a, a.showNotice(fmt.Sprintf("%d agent(s) marked; mark exactly two to compare (%s marks the selected agent)", len(marked), a.keys.Mark.Help().Key), true)
+			}
+			a.compareView.Open(marked[0].Name, marked[1].Name)
+			a.compareView.SetSize(a.width, a.height)
+			a.modals.Open(a.compareView)
+			return a, a.loadCompare(marked[0].Name, marked[1].Name)
+
+		case key.Matches(msg, a.keys.Grep):
+			// Search what every agent printed
+			a.grepView.SetSize(a.width, a.height)
+			a.modals.Open(a.grepView)
+			return a, nil
+
+		case key.Matches(msg, a.keys.Todos):
+			// List what every agent believes remains
+			a.todosView.SetSize(a.width, a.height)
+			a.modals.Open(a.todosView)
+			return a, a.todosView.Reload()
+
+		case key.Matches(msg, a.keys.Pin):
+			// Pin or unpin the selected session and remember it across runs
+			if selected := a.list.SelectedSession(); selected != nil {
+				a.list.TogglePin(selected.Name)
+				a.tuiState.SetPinned(a.list.PinnedSessions())
+				if err := a.tuiState.Save(a.tuiStatePath); err != nil {
+					// The pin still applies for this run even if it can't be persisted
+					return a, a.showNotice(fmt.Sprintf("pin applies for this run only: %v", err), true)
+				}
+			}
+			return a, nil
+
 		case key.Matches(msg, a.keys.NewAgent):
 			// Show agent creation form
-			a.agentForm.SetActive(true)
+			a.modals.Open(a.agentFormOverlay)
 			a.agentForm.SetSize(a.width, a.height)
 			return a, spinnerTick() // Start spinner for form
 		}
@@ -347,7 +838,7 @@ func (a *App) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 			// If selection changed, update diff view
 			if newSelected := a.list.SelectedSession(); newSelected != nil {
 				if prevSelected == nil || prevSelected.Name != newSelected.Name {
-					a.diffPreview.LoadDiff(newSelected)
+					cmds = append(cmds, a.loadDiffPreview(newSelected))
 				}
 			}
 
@@ -366,17 +857,21 @@ func (a *App) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 		// Update dimensions
 		a.width = msg.Width
 		a.height = msg.Height
+		a.devLogView.SetSize(msg.Width, msg.Height)
+		a.compareView.SetSize(msg.Width, msg.Height)
+		a.grepView.SetSize(msg.Width, msg.Height)
+		a.todosView.SetSize(msg.Width, msg.Height)
 
 		if a.splitView {
 			// In split view, allocate space for both list and diff
 			listWidth := msg.Width / 2
 			diffWidth := msg.Width - listWidth
 
-			a.list.SetSize(listWidth, msg.Height-2)
-			a.diffPreview.SetSize(diffWidth, msg.Height-2)
+			a.list.SetSize(listWidth, msg.Height-3)
+			a.diffPreview.SetSize(diffWidth, msg.Height-3)
 		} else {
 			// In list view, use full width
-			a.list.SetSize(msg.Width, msg.Height-2)
+			a.list.SetSize(msg.Width, msg.Height-3)
 		}
 
 		// Delegate to components for their own size handling
@@ -394,60 +889,104 @@ func (a *App) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 		return a, tea.Batch(cmds...)
 
 	case TickMsg:
-		// Ticker fired - refresh sessions smoothly without clearing screen
-		return a, tea.Batch(
-			a.refreshSessions(),      // Refresh session data
-			tickEvery(2*time.Second), // Schedule next tick
-		)
+		// Ticker fired - refresh sessions smoothly without clearing screen,
+		// unless the first load is still filling in its rows
+		if a.enriching == 0 {
+			cmds = append(cmds, a.refreshSessions())
+		}
+		cmds = append(cmds, tickEvery(2*time.Second)) // Schedule next tick
+		if a.jobsView.Focused() {
+			// Keep the queued spawns in the open jobs view current
+			cmds = append(cmds, a.loadSpawnQueue())
+		}
+		return a, tea.Batch(cmds...)
+
+	case SpawnQueueMsg:
+		a.jobsView.SetQueued(msg.Entries)
+		return a, nil
+
+	case ConfigReloadedMsg:
+		if err := a.applyConfig(msg.Config); err != nil {
+			return a, tea.Batch(a.showNotice("config error: "+err.Error(), true), a.waitForConfigChange())
+		}
+		return a, tea.Batch(a.showNotice("config reloaded", false), a.waitForConfigChange())
+
+	case ConfigErrorMsg:
+		// Keep the last good config but make the problem visible
+		return a, tea.Batch(a.showNotice("config error: "+msg.Err.Error(), true), a.waitForConfigChange())
+
+	case noticeExpiredMsg:
+		if msg.seq == a.noticeSeq {
+			a.notice = ""
+		}
+		return a, nil
+
+	case SessionsListedMsg:
+		return a, a.showListedSessions(msg.Sessions)
+
+	case SessionEnrichedMsg:
+		a.applyEnrichedSession(msg.Session)
+		return a, nil
 
 	case RefreshMsg:
-		// Sessions have been refreshed - no action needed
-		// The list has already been updated in refreshSessions()
+		// The list has already been updated in refreshSessions(); keep the
+		// previous header if this refresh failed
+		if msg.Summary != nil {
+			a.summary = msg.Summary
+		}
 		return a, nil
 
 	case AgentFormSubmitMsg:
 		// Handle agent form submission
-		a.agentForm.SetActive(false)
-		a.progressModal.SetActive(true)
+		a.modals.Close(a.agentFormOverlay)
+		a.modals.Open(a.progressOverlay)
 		a.progressModal.SetSize(a.width, a.height)
 
-		// Start async agent creation
+		// Spawn in the background, following its steps in the progress modal;
+		// Esc cancels the spawn and rolls back what it created
 		opts := msg.AgentType + ":" + msg.Count + ":" + msg.Prompt
-		progressChan, err := a.uzi.SpawnAgentInteractive(opts)
-		if err != nil {
-			a.progressModal.SetError(err.Error())
-			return a, nil
-		}
+		ctx, cancel := context.WithCancel(context.Background())
+		a.spawnCancel = cancel
+		events := make(chan SpawnEvent, spawnEventBuffer)
+		job := a.jobs.Enqueue(jobs.KindSpawn, msg.AgentType+" x"+msg.Count, func() error {
+			defer close(events)
+			return a.uzi.SpawnAgentInteractive(ctx, opts, events)
+		})
 
-		// Start monitoring progress
-		return a, func() tea.Msg {
-			// Wait for completion in a goroutine
-			go func() {
-				select {
-				case <-progressChan:
-					// Agent creation completed successfully
-					return // Success handled by the channel
+		return a, tea.Batch(
+			spinnerTick(),
+			waitForSpawnEvent(events),
+			a.awaitJob(job.ID, func(job jobs.Job) tea.Msg {
+				cancel()
+				if job.Status == jobs.StatusFailed {
+					return ProgressErrorMsg{Error: job.Error}
 				}
-			}()
-			return ProgressStepMsg{Step: ProgressStepSetupWorktree}
-		}
+				return ProgressCompleteMsg{}
+			}),
+		)
 
-	case ProgressStepMsg:
-		// Update progress modal
-		a.progressModal.NextStep()
-		if msg.Message != "" {
-			a.progressModal.SetMessage(msg.Message)
+	case SpawnProgressMsg:
+		a.progressModal.Apply(msg.Event)
+		return a, waitForSpawnEvent(msg.Events)
+
+	case SpawnCancelMsg:
+		if a.spawnCancel != nil {
+			a.spawnCancel()
 		}
 		return a, nil
 
 	case ProgressErrorMsg:
 		// Handle progress error
+		if a.progressModal.Cancelling() {
+			a.progressModal.SetCancelled()
+			return a, a.refreshSessions()
+		}
 		a.progressModal.SetError(msg.Error)
 		return a, nil
 
 	case ProgressCompleteMsg:
 		// Handle completion
-		a.progressModal.NextStep() // Move to complete step
+		a.progressModal.Complete()
 		return a, tea.Batch(
 			a.refreshSessions(), // Refresh to show new session
 			func() tea.Msg {
@@ -465,14 +1004,116 @@ func (a *App) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 		return a, progressCmd
 
 	case CheckpointMsg:
-		// Handle checkpoint request
+		// Run the checkpoint in the background so git doesn't hold up the TUI
+		a.modals.Close(a.checkpointOverlay)
+		return a, a.checkpointJob(msg.AgentName, msg.CommitMessage, msg.Paths)
+
+	case AgentDoneMsg:
+		return a, tea.Batch(a.handleAgentDone(msg.SessionName), a.waitForAgentDone())
+
+	case DiffChangedMsg:
+		return a, a.handleDiffChanged(msg.Seq)
+
+	case DiffRefreshMsg:
+		return a, a.handleDiffRefresh(msg.Seq)
+
+	case KillCheckpointMsg:
+		// Checkpoint instead of killing: open the checkpoint modal on that agent
+		sessions, err := a.uzi.GetSessions()
+		if err == nil {
+			a.checkpointModal.SetAgents(sessions)
+			a.checkpointModal.SetSize(a.width, a.height)
+			a.modals.Open(a.checkpointOverlay)
+			a.checkpointModal.SelectAgent(msg.AgentName)
+		}
+		return a, nil
+
+	case PipelineRunsMsg:
+		a.pipelineView.SetRuns(msg.Runs, msg.Error)
+		return a, nil
+
+	case DevLogMsg:
+		// Show the capture and take the next one while the view stays open
+		if msg.SessionName != a.devLogView.SessionName() {
+			return a, nil
+		}
+		a.devLogView.SetLog(msg.Content, msg.Error)
+		if !a.devLogView.Focused() {
+			return a, nil
+		}
+		return a, tea.Tick(devLogRefresh, func(time.Time) tea.Msg {
+			return devLogTickMsg{SessionName: msg.SessionName}
+		})
+
+	case CompareMsg:
+		// Ignore diffs of a comparison that was since replaced
+		if left, right := a.compareView.Sessions(); msg.Left.SessionName != left || msg.Right.SessionName != right {
+			return a, nil
+		}
+		a.compareView.SetDiffs(msg.Left, msg.Right)
+		return a, nil
+
+	case PolicyViolationMsg:
+		return a, a.showNotice(msg.Violation.Error(), true)
+
+	case CommandErrorMsg:
+		return a, a.showNotice(fmt.Sprintf("%s failed: %v", msg.Action, msg.Err), true)
+
+	case BroadcastSkippedMsg:
+		agents := make([]string, len(msg.Sessions))
+		for i, sessionName := range msg.Sessions {
+			agents[i] = state.AgentNameFromSession(sessionName)
+		}
+		notice := fmt.Sprintf("Broadcast skipped %s: tmux session gone", strings.Join(agents, ", "))
+		return a, tea.Batch(a.showNotice(notice, true), a.refreshSessions())
+
+	case GrepMsg:
+		// Ignore results of a search that was since replaced
+		if msg.Pattern == a.grepView.Pattern() {
+			a.grepView.SetResults(msg.Matches, msg.Error)
+		}
+		return a, nil
+
+	case TodosMsg:
+		a.todosView.SetTodos(msg.Todos, msg.Error)
+		return a, nil
+
+	case devLogTickMsg:
+		if a.devLogView.Focused() && msg.SessionName == a.devLogView.SessionName() {
+			return a, a.loadDevLog(msg.SessionName)
+		}
+		return a, nil
+
+	case CheckpointFilesRequestMsg:
+		// Load the agent's changed files for the checkpoint file picker
 		return a, func() tea.Msg {
-			err := a.uzi.RunCheckpoint(msg.AgentName, msg.CommitMessage)
+			files, err := a.uzi.GetChangedFiles(msg.SessionName)
 			if err != nil {
-				return CheckpointCompleteMsg{Success: false, Error: err.Error()}
+				return CheckpointFilesMsg{SessionName: msg.SessionName, Error: err.Error()}
 			}
-			return CheckpointCompleteMsg{Success: true}
+			return CheckpointFilesMsg{SessionName: msg.SessionName, Files: files}
+		}
+
+	case CheckpointOverlapRequestMsg:
+		// Check which of the agent's files other agents changed too
+		finder, ok := a.uzi.(overlapFinder)
+		if !ok {
+			return a, nil
 		}
+		return a, func() tea.Msg {
+			// Without overlaps the checkpoint goes ahead unwarned
+			overlaps, _ := finder.PendingOverlaps(msg.SessionName)
+			return CheckpointOverlapMsg{SessionName: msg.SessionName, Overlaps: overlaps}
+		}
+
+	case CheckpointOverlapMsg:
+		a.checkpointModal, _ = a.checkpointModal.Update(msg)
+		return a, nil
+
+	case CheckpointFilesMsg:
+		// Hand the changed files to the checkpoint modal
+		a.checkpointModal, _ = a.checkpointModal.Update(msg)
+		return a, nil
 
 	case CheckpointCompleteMsg:
 		// Handle checkpoint completion
@@ -492,43 +1133,45 @@ func (a *App) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 		// Handle confirmation modal response
 		if msg.Confirmed {
 			if selected := a.list.SelectedSession(); selected != nil {
-				return a, func() tea.Msg {
-					// Step 1: Kill the session
-					err := a.uzi.KillSession(selected.Name)
-					if err != nil {
-						// Handle error - for now just continue
-						return RefreshMsg{}
+				agentName := extractAgentName(selected.Name)
+				job := a.jobs.Enqueue(jobs.KindKill, agentName, func() error {
+					if err := a.uzi.KillSession(selected.Name); err != nil {
+						return err
 					}
 
-					// Step 2: Remove worktree state if this is a kill & replace
+					// Kill & replace: clear the old state and queue the replacement,
+					// which runs after this job since it targets the same agent
 					if msg.SpawnReplacement {
-						// Get state manager and remove state
 						if stateManager := getStateManager(); stateManager != nil {
 							if err := stateManager.RemoveState(selected.Name); err != nil {
 								// Log error but continue
 							}
 						}
-
-						// Step 3: Spawn replacement agent
-						newSessionName, err := a.uzi.SpawnAgent(msg.Prompt, msg.Model)
-						if err != nil {
-							// Handle spawn error - for now just continue
-							return RefreshMsg{}
-						}
-
-						// Step 4: Add new session to state
-						// The SpawnAgent call should have already handled this via RunPrompt
-						_ = newSessionName // Session is now tracked automatically
+						a.jobs.Enqueue(jobs.KindSpawn, agentName, func() error {
+							_, err := a.uzi.SpawnAgent(msg.Prompt, msg.Model)
+							return err
+						})
 					}
+					return nil
+				})
 
-					// Refresh sessions to show updated list
-					return RefreshMsg{}
-				}
+				// Refresh sessions to show the updated list once the kill is done
+				return a, a.awaitJob(job.ID, func(jobs.Job) tea.Msg { return RefreshMsg{} })
 			}
 		}
 		// If not confirmed, just continue - modal is already hidden
 		return a, nil
 
+	case JobsChangedMsg:
+		list := a.jobs.Jobs()
+		a.jobsView.SetJobs(list)
+		cmds = append(cmds, a.waitForJobChange())
+		if notice := a.announceJobs(list); notice != nil {
+			// Finished jobs change the session list
+			cmds = append(cmds, notice, a.refreshSessions())
+		}
+		return a, tea.Batch(cmds...)
+
 	default:
 		// Update confirmation modal
 		if a.confirmModal != nil {
@@ -556,91 +1199,145 @@ func (a *App) View() string {
 		return "Loading..."
 	}
 
+	t := resolveTheme(a.theme)
+
+	var content string
 	if a.splitView {
-		// Split view: show list on left and diff on right
+		// Split view: show list on left and diff on right. Join horizontally
+		// with Claude Squad styling; the plain theme stacks the panels so
+		// they read top to bottom
 		listView := a.list.View()
 		diffView := a.diffPreview.View()
-
-		// Join horizontally with Claude Squad styling
-		splitContent := lipgloss.JoinHorizontal(lipgloss.Top, listView, diffView)
-
-		// Add status line if loading
-		if a.loading {
-			statusLine := ClaudeSquadMutedStyle.Render("Refreshing sessions...")
-			return splitContent + "\n" + statusLine
-		}
-
-		// Add broadcast input if active
-		content := splitContent
-		if a.broadcastInput.IsActive() {
-			broadcastView := a.broadcastInput.View()
-			content = lipgloss.JoinVertical(lipgloss.Left, content, broadcastView)
+		if t.Plain {
+			content = lipgloss.JoinVertical(lipgloss.Left, listView, diffView)
+		} else {
+			content = lipgloss.JoinHorizontal(lipgloss.Top, listView, diffView)
 		}
-
-		return content
 	} else {
 		// List view: delegate to the list view for rendering
-		listView := a.list.View()
+		content = a.list.View()
+	}
 
-		// Add status lines
-		var statusLines []string
+	// Add any open overlays, topmost last
+	for _, modalView := range a.modals.Views() {
+		content = lipgloss.JoinVertical(lipgloss.Left, content, modalView)
+	}
 
-		if a.loading {
-			statusLines = append(statusLines, ClaudeSquadMutedStyle.Render("Refreshing sessions..."))
-		}
+	// Fleet summary header above, status bar below
+	if header := a.summaryView(); header != "" {
+		content = header + "\n" + content
+	}
+	return content + "\n" + a.statusBarView()
+}
 
-		// Add filter status if active
-		if filterStatus := a.list.GetFilterStatus(); filterStatus != "" {
-			statusLines = append(statusLines, ClaudeSquadAccentStyle.Render(filterStatus))
-		}
+// Cleanup stops the activity monitor and releases resources
+func (a *App) Cleanup() {
+	if a.activityMonitor != nil {
+		a.activityMonitor.Stop()
+	}
+	if a.monitorCancel != nil {
+		a.monitorCancel()
+	}
+	if a.configWatcher != nil {
+		a.configWatcher.Close()
+	}
+	a.stopDiffWatch()
+	// Let queued checkpoints and kills finish rather than abandoning them halfway
+	a.jobs.Close()
+}
 
-		if len(statusLines) > 0 {
-			statusLine := strings.Join(statusLines, " │ ")
-			listView = listView + "\n" + statusLine
+// PendingJobs returns how many background jobs have not finished yet
+func (a *App) PendingJobs() int {
+	pending := 0
+	for _, job := range a.jobs.Jobs() {
+		if !job.Done() {
+			pending++
 		}
+	}
+	return pending
+}
 
-		// Add broadcast input if active
-		if a.broadcastInput.IsActive() {
-			broadcastView := a.broadcastInput.View()
-			listView = lipgloss.JoinVertical(lipgloss.Left, listView, broadcastView)
-		}
+// spawnEventBuffer is how many spawn events wait for the progress modal
+// before more are dropped
+const spawnEventBuffer = 128
 
-		// Add agent form if active
-		if a.agentForm.IsActive() {
-			formView := a.agentForm.View()
-			listView = lipgloss.JoinVertical(lipgloss.Left, listView, formView)
+// waitForSpawnEvent turns the next event of a spawn into a SpawnProgressMsg,
+// until the spawn closes events
+func waitForSpawnEvent(events <-chan SpawnEvent) tea.Cmd {
+	return func() tea.Msg {
+		event, ok := <-events
+		if !ok {
+			return nil
 		}
+		return SpawnProgressMsg{Event: event, Events: events}
+	}
+}
 
-		// Add progress modal if active
-		if a.progressModal.IsActive() {
-			modalView := a.progressModal.View()
-			listView = lipgloss.JoinVertical(lipgloss.Left, listView, modalView)
+// awaitJob waits for the job in the background and turns the finished job into a message
+func (a *App) awaitJob(id int, done func(jobs.Job) tea.Msg) tea.Cmd {
+	return func() tea.Msg {
+		job, ok := a.jobs.Wait(id)
+		if !ok {
+			return nil
 		}
+		return done(job)
+	}
+}
 
-		// Add confirmation modal if visible
-		if a.confirmModal != nil && a.confirmModal.IsVisible() {
-			modalView := a.confirmModal.View()
-			listView = lipgloss.JoinVertical(lipgloss.Left, listView, modalView)
+// checkpointJob checkpoints an agent in the background, limited to paths when
+// any are given, and refreshes the list when it finishes
+func (a *App) checkpointJob(agentName, message string, paths []string) tea.Cmd {
+	job := a.jobs.EnqueueWithOutput(jobs.KindCheckpoint, agentName, func(output func(string)) error {
+		if streamer, ok := a.uzi.(checkpointStreamer); ok {
+			return streamer.RunCheckpointStreaming(agentName, message, paths, output)
 		}
-
-		// Add checkpoint modal if visible
-		if a.checkpointModal.IsVisible() {
-			modalView := a.checkpointModal.View()
-			listView = lipgloss.JoinVertical(lipgloss.Left, listView, modalView)
+		if len(paths) > 0 {
+			return a.uzi.RunPartialCheckpoint(agentName, message, paths)
 		}
+		return a.uzi.RunCheckpoint(agentName, message)
+	})
+	return a.awaitJob(job.ID, func(jobs.Job) tea.Msg { return RefreshMsg{} })
+}
 
-		return listView
+// waitForJobChange turns the next job queue update into a JobsChangedMsg
+func (a *App) waitForJobChange() tea.Cmd {
+	changed := a.jobs.Changed()
+	return func() tea.Msg {
+		<-changed
+		return JobsChangedMsg{}
 	}
 }
 
-// Cleanup stops the activity monitor and releases resources
-func (a *App) Cleanup() {
-	if a.activityMonitor != nil {
-		a.activityMonitor.Stop()
+// announceJobs shows a notice for jobs that finished since the last update
+func (a *App) announceJobs(list []jobs.Job) tea.Cmd {
+	var finished []jobs.Job
+	announced := make(map[int]bool, len(a.announcedJobs))
+	for _, job := range list {
+		if !job.Done() {
+			continue
+		}
+		announced[job.ID] = true
+		if !a.announcedJobs[job.ID] {
+			finished = append(finished, job)
+		}
 	}
-	if a.monitorCancel != nil {
-		a.monitorCancel()
+	a.announcedJobs = announced
+	if len(finished) == 0 {
+		return nil
+	}
+
+	// The newest failure wins the status line since it needs attention
+	job := finished[0]
+	for _, candidate := range finished {
+		if candidate.Status == jobs.StatusFailed {
+			job = candidate
+			break
+		}
+	}
+	if job.Status == jobs.StatusFailed {
+		return a.showNotice(fmt.Sprintf("%s %s failed (J for details)", job.Kind, job.Target), true)
 	}
+	return a.showNotice(fmt.Sprintf("%s %s completed", job.Kind, job.Target), false)
 }
 
 // getStateManager returns a state manager instance for worktree operations
@@ -686,6 +1383,18 @@ func writeStarterConfig(filename string, config config.Config) error {
 	return os.WriteFile(filename, data, 0644)
 }
 
+// validateConfig checks the settings the config editor can't leave out. An
+// unset portRange is fine: ports are then picked from config.AutoPortRange.
+func validateConfig(cfg *config.Config) error {
+	if cfg.DevCommand == nil || *cfg.DevCommand == "" {
+		return fmt.Errorf("devCommand is empty")
+	}
+	if cfg.PortRange != nil && *cfg.PortRange != "" && !isValidPortRange(*cfg.PortRange) {
+		return fmt.Errorf("Invalid portRange")
+	}
+	return nil
+}
+
 // isValidPortRange validates a port range string (e.g., "3000-3010")
 func isValidPortRange(portRange string) bool {
 	if portRange == "" {
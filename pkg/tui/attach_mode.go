@@ -0,0 +1,45 @@
+// Copyright (c) Subtrace, Inc.
+// SPDX-License-Identifier: BSD-3-Clause
+
+package tui
+
+import (
+	"fmt"
+	"os"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/nehpz/claudicus/pkg/config"
+)
+
+// tmuxOpener is implemented by UziInterface backends that can open a session
+// in the tmux client the TUI runs in
+type tmuxOpener interface {
+	OpenInTmux(sessionName string, mode config.AttachMode) error
+}
+
+// insideTmux reports whether the TUI runs in a tmux client
+var insideTmux = func() bool {
+	return os.Getenv("TMUX") != ""
+}
+
+// attachSession opens the selected agent on Enter. With tui.attachMode set
+// to window or pane and the TUI inside tmux, the agent opens next to the TUI,
+// which keeps running; otherwise the TUI exits into tmux attach-session.
+func (a *App) attachSession(sessionName string) tea.Cmd {
+	mode := a.config.AttachMode()
+	opener, canOpen := a.uzi.(tmuxOpener)
+	if mode == config.AttachModeAttach || !canOpen || !insideTmux() {
+		return func() tea.Msg {
+			if err := a.uzi.AttachToSession(sessionName); err != nil {
+				return CommandErrorMsg{Action: "attach to " + extractAgentName(sessionName), Err: err}
+			}
+			return tea.Quit // Exit TUI after attaching
+		}
+	}
+
+	agentName := extractAgentName(sessionName)
+	if err := opener.OpenInTmux(sessionName, mode); err != nil {
+		return a.showNotice(fmt.Sprintf("could not open %s: %v", agentName, err), true)
+	}
+	return a.showNotice(fmt.Sprintf("Opened %s in a tmux %s", agentName, mode), false)
+}
@@ -0,0 +1,122 @@
+// Copyright (c) Subtrace, Inc.
+// SPDX-License-Identifier: BSD-3-Clause
+
+package tui
+
+import (
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/nehpz/claudicus/pkg/config"
+	"github.com/nehpz/claudicus/pkg/hosts"
+	"github.com/nehpz/claudicus/pkg/testutil/cmdmock"
+)
+
+// openerMockUzi records the sessions opened inside tmux
+type openerMockUzi struct {
+	MockUziInterface
+	opened    []string
+	attached  []string
+	err       error
+	attachErr error
+}
+
+func (m *openerMockUzi) AttachToSession(sessionName string) error {
+	m.attached = append(m.attached, sessionName)
+	return m.attachErr
+}
+
+func (m *openerMockUzi) OpenInTmux(sessionName string, mode config.AttachMode) error {
+	m.opened = append(m.opened, string(mode)+":"+sessionName)
+	return m.err
+}
+
+func withInsideTmux(t *testing.T, inside bool) {
+	previous := insideTmux
+	insideTmux = func() bool { return inside }
+	t.Cleanup(func() { insideTmux = previous })
+}
+
+func TestApp_AttachSessionModes(t *testing.T) {
+	mock := &openerMockUzi{}
+	app := NewApp(mock)
+	defer app.Cleanup()
+	app.config = &config.Config{TUI: &config.TUIConfig{AttachMode: "pane"}}
+
+	withInsideTmux(t, true)
+	if cmd := app.attachSession("agent-repo-abc123-sarah"); cmd == nil {
+		t.Fatal("Expected a command to expire the notice")
+	}
+	if len(mock.opened) != 1 || mock.opened[0] != "pane:agent-repo-abc123-sarah" || len(mock.attached) != 0 {
+		t.Errorf("Expected the agent opened in a pane, got opened=%v attached=%v", mock.opened, mock.attached)
+	}
+	if app.notice != "Opened sarah in a tmux pane" || app.noticeIsError {
+		t.Errorf("Unexpected notice %q", app.notice)
+	}
+
+	mock.err = errors.New("no current client")
+	app.attachSession("agent-repo-abc123-sarah")
+	if !app.noticeIsError || !strings.Contains(app.notice, "no current client") {
+		t.Errorf("Expected an error notice, got %q", app.notice)
+	}
+
+	// Outside tmux there is no client to open a pane in
+	withInsideTmux(t, false)
+	app.attachSession("agent-repo-abc123-sarah")()
+	if len(mock.opened) != 2 || len(mock.attached) != 1 {
+		t.Errorf("Expected attach-session outside tmux, got opened=%v attached=%v", mock.opened, mock.attached)
+	}
+
+	withInsideTmux(t, true)
+	app.config = nil
+	app.attachSession("agent-repo-abc123-sarah")()
+	if len(mock.opened) != 2 || len(mock.attached) != 2 {
+		t.Errorf("Expected attach-session by default, got opened=%v attached=%v", mock.opened, mock.attached)
+	}
+
+	// A failed attach keeps the TUI running and says why
+	mock.attachErr = errors.New("session not found")
+	result := app.attachSession("agent-repo-abc123-sarah")()
+	msg, ok := result.(CommandErrorMsg)
+	if !ok {
+		t.Fatalf("Expected a failed attach to be reported, got %T", result)
+	}
+	app.Update(msg)
+	if !app.noticeIsError || !strings.Contains(app.notice, "attach to sarah failed: session not found") {
+		t.Errorf("Expected an error notice for the failed attach, got %q", app.notice)
+	}
+}
+
+func TestUziCLI_OpenInTmux(t *testing.T) {
+	setupUziTest()
+	cli := NewUziCLI()
+	cmdmock.SetResponseWithArgs("tmux", []string{"switch-client", "-t", "agent-repo-abc123-sarah"}, "", "", false)
+	cmdmock.SetResponseWithArgs("tmux", []string{"split-window", "-h", "TMUX= tmux attach-session -t agent-repo-abc123-sarah"}, "", "", false)
+
+	if err := cli.OpenInTmux("agent-repo-abc123-sarah", config.AttachModeWindow); err != nil {
+		t.Fatalf("OpenInTmux(window) error = %v", err)
+	}
+	if !cmdmock.WasCommandCalled("tmux", "switch-client", "-t", "agent-repo-abc123-sarah") {
+		t.Errorf("Expected the client switched to the session, got %+v", cmdmock.GetCalls())
+	}
+
+	if err := cli.OpenInTmux("agent-repo-abc123-sarah", config.AttachModePane); err != nil {
+		t.Fatalf("OpenInTmux(pane) error = %v", err)
+	}
+	if !cmdmock.WasCommandCalled("tmux", "split-window", "-h", "TMUX= tmux attach-session -t agent-repo-abc123-sarah") {
+		t.Errorf("Expected a nested attach in a split pane, got %+v", cmdmock.GetCalls())
+	}
+
+	cmdmock.SetResponseWithArgs("tmux", []string{"switch-client", "-t", "agent-repo-abc123-sarah"}, "", "no current client", true)
+	if err := cli.OpenInTmux("agent-repo-abc123-sarah", config.AttachModeWindow); err == nil || !strings.Contains(err.Error(), "no current client") {
+		t.Errorf("Expected the tmux error, got %v", err)
+	}
+}
+
+func TestAttachShellCommand(t *testing.T) {
+	got := attachShellCommand(hosts.Target{Name: "box", SSH: "dev@box"}, "agent-repo-abc123-sarah")
+	if want := "TMUX= ssh -t dev@box tmux attach-session -t agent-repo-abc123-sarah"; got != want {
+		t.Errorf("attachShellCommand() = %q, want %q", got, want)
+	}
+}
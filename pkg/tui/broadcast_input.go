@@ -4,15 +4,33 @@
 package tui
 
 import (
+	"fmt"
+	"strings"
+
+	"github.com/nehpz/claudicus/pkg/config"
+
 	"github.com/charmbracelet/bubbles/textinput"
 	tea "github.com/charmbracelet/bubbletea"
 )
 
+// channelBroadcaster is implemented by UziInterface backends that can send a
+// broadcast to the subscribers of one channel
+type channelBroadcaster interface {
+	RunChannelBroadcast(channel, message string) error
+}
+
 // BroadcastInputModel handles the broadcast message input prompt
 type BroadcastInputModel struct {
 	textInput textinput.Model
 	active    bool
 	width     int
+	theme     *Theme
+	// templates are the broadcasts from uzi.yaml that Tab cycles through;
+	// template is the index of the one in the input, or -1
+	templates []config.BroadcastTemplate
+	template  int
+	// channels are the broadcast channels "#" completes
+	channels []string
 }
 
 // NewBroadcastInputModel creates a new broadcast input model
@@ -27,15 +45,23 @@ func NewBroadcastInputModel() *BroadcastInputModel {
 		textInput: ti,
 		active:    false,
 		width:     50,
+		theme:     DefaultTheme(),
+		template:  -1,
 	}
 }
 
+// SetTheme switches the style profile used to render the broadcast input
+func (m *BroadcastInputModel) SetTheme(theme *Theme) {
+	m.theme = theme
+}
+
 // SetActive activates or deactivates the broadcast input
 func (m *BroadcastInputModel) SetActive(active bool) {
 	m.active = active
 	if active {
 		m.textInput.Focus()
 		m.textInput.SetValue("")
+		m.template = -1
 	} else {
 		m.textInput.Blur()
 	}
@@ -46,6 +72,78 @@ func (m *BroadcastInputModel) IsActive() bool {
 	return m.active
 }
 
+// SetTemplates sets the broadcast templates the picker offers
+func (m *BroadcastInputModel) SetTemplates(templates []config.BroadcastTemplate) {
+	m.templates = templates
+	m.template = -1
+}
+
+// SetChannels sets the broadcast channels Tab completes after "#"
+func (m *BroadcastInputModel) SetChannels(channels []string) {
+	m.channels = channels
+}
+
+// CompletingChannel reports whether the input is a "#" channel name still
+// being typed, which Tab completes instead of picking a template
+func (m *BroadcastInputModel) CompletingChannel() bool {
+	value := m.textInput.Value()
+	return len(m.channels) > 0 && strings.HasPrefix(value, "#") && !strings.Contains(value, " ")
+}
+
+// CompleteChannel completes the "#" channel name being typed to the longest
+// prefix shared by the matching channels, adding a space once it is unique
+func (m *BroadcastInputModel) CompleteChannel() {
+	if !m.CompletingChannel() {
+		return
+	}
+	prefix := strings.TrimPrefix(m.textInput.Value(), "#")
+	var matches []string
+	for _, channel := range m.channels {
+		if strings.HasPrefix(channel, prefix) {
+			matches = append(matches, channel)
+		}
+	}
+	if len(matches) == 0 {
+		return
+	}
+	completed := matches[0]
+	for _, match := range matches[1:] {
+		for !strings.HasPrefix(match, completed) {
+			completed = completed[:len(completed)-1]
+		}
+	}
+	if len(matches) == 1 {
+		completed += " "
+	}
+	m.textInput.SetValue("#" + completed)
+	m.textInput.CursorEnd()
+}
+
+// parseChannelMessage splits a broadcast of the form "#channel message" into
+// the channel and the message; other broadcasts go to every session and are
+// returned with an empty channel
+func parseChannelMessage(value string) (channel, message string) {
+	if !strings.HasPrefix(value, "#") {
+		return "", value
+	}
+	name, rest, _ := strings.Cut(value[1:], " ")
+	if config.ValidateChannel(name) != nil {
+		return "", value
+	}
+	return name, strings.TrimSpace(rest)
+}
+
+// NextTemplate fills the input with the next template, wrapping around, so
+// it can be sent as is or edited first
+func (m *BroadcastInputModel) NextTemplate() {
+	if len(m.templates) == 0 {
+		return
+	}
+	m.template = (m.template + 1) % len(m.templates)
+	m.textInput.SetValue(m.templates[m.template].Message)
+	m.textInput.CursorEnd()
+}
+
 // Value returns the current input value
 func (m *BroadcastInputModel) Value() string {
 	return m.textInput.Value()
@@ -75,8 +173,9 @@ func (m *BroadcastInputModel) View() string {
 	}
 
 	// Create prompt style
-	promptStyle := ClaudeSquadAccentStyle.Copy().Bold(true)
-	inputStyle := ClaudeSquadBorderStyle.Copy().
+	t := resolveTheme(m.theme)
+	promptStyle := t.Accent.Copy().Bold(true)
+	inputStyle := t.Border.Copy().
 		Width(m.width-2).
 		Padding(0, 1)
 
@@ -84,5 +183,18 @@ func (m *BroadcastInputModel) View() string {
 	input := m.textInput.View()
 
 	content := prompt + input
+	if len(m.templates) > 0 {
+		names := make([]string, len(m.templates))
+		for i, tmpl := range m.templates {
+			names[i] = tmpl.Name
+			if i == m.template {
+				names[i] = t.Accent.Render("[" + tmpl.Name + "]")
+			}
+		}
+		content += "\n" + t.Muted.Render(fmt.Sprintf("tab: template (%s)", strings.Join(names, " ")))
+	}
+	if len(m.channels) > 0 {
+		content += "\n" + t.Muted.Render(fmt.Sprintf("#name: channel (%s)", strings.Join(m.channels, " ")))
+	}
 	return inputStyle.Render(content)
 }
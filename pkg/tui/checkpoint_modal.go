@@ -8,6 +8,8 @@ import (
 	"github.com/charmbracelet/bubbles/textinput"
 	tea "github.com/charmbracelet/bubbletea"
 	"github.com/charmbracelet/lipgloss"
+	"github.com/nehpz/claudicus/pkg/config"
+	"github.com/nehpz/claudicus/pkg/state"
 )
 
 type CheckpointStep int
@@ -15,6 +17,7 @@ type CheckpointStep int
 const (
 	CheckpointStepSelectAgent CheckpointStep = iota
 	CheckpointStepCommitMessage
+	CheckpointStepSelectFiles
 	CheckpointStepProgress
 	CheckpointStepComplete
 )
@@ -32,12 +35,59 @@ type CheckpointModal struct {
 	width        int
 	height       int
 	completed    bool
+
+	// Partial checkpoint file picker
+	files        []string        // Files changed by the selected agent
+	fileSelected map[string]bool // Files chosen for the checkpoint
+	fileIdx      int             // Cursor position in the file list
+	filesLoaded  bool            // Whether files were loaded for the selected agent
+	filesError   string          // Error from loading changed files
+
+	// overlaps are the selected agent's changed files that other agents have
+	// pending changes to as well
+	overlaps []state.FileOverlap
+
+	// checkpoint holds the message template the commit message is wrapped in
+	checkpoint *config.CheckpointConfig
+
+	theme *Theme
 }
 
 // CheckpointMsg is sent when checkpoint operation is initiated
 type CheckpointMsg struct {
 	AgentName     string
 	CommitMessage string
+	Paths         []string // Globs to checkpoint; empty checkpoints everything
+}
+
+// CheckpointFilesRequestMsg asks the App to load the files changed by an agent
+type CheckpointFilesRequestMsg struct {
+	SessionName string
+}
+
+// CheckpointFilesMsg delivers the files changed by an agent to the file picker
+type CheckpointFilesMsg struct {
+	SessionName string
+	Files       []string
+	Error       string
+}
+
+// overlapFinder is implemented by UziInterface backends that can find the
+// files other agents changed too, as `uzi overlap` does
+type overlapFinder interface {
+	PendingOverlaps(sessionName string) ([]state.FileOverlap, error)
+}
+
+// CheckpointOverlapRequestMsg asks the App to check which of an agent's
+// changed files other agents have pending changes to
+type CheckpointOverlapRequestMsg struct {
+	SessionName string
+}
+
+// CheckpointOverlapMsg delivers an agent's overlapping files to the modal
+type CheckpointOverlapMsg struct {
+	SessionName string
+	Overlaps    []state.FileOverlap
 }
 
 // CheckpointProgressMsg is sent during git rebase progress
@@ -72,6 +122,7 @@ func NewCheckpointModal() CheckpointModal {
 		commitInput: commitInput,
 		spinner:     s,
 		selectedIdx: 0,
+		theme:       DefaultTheme(),
 	}
 }
 
@@ -93,6 +144,23 @@ func (m *CheckpointModal) SetAgents(agents []SessionInfo) {
 	}
 }
 
+// SelectAgent moves the agent selection to the named agent, if it is listed
+func (m *CheckpointModal) SelectAgent(agentName string) {
+	for i, agent := range m.agents {
+		if agent.AgentName == agentName {
+			m.selectedIdx = i
+			return
+		}
+	}
+}
+
+// SetCheckpointConfig sets the checkpoint settings of uzi.yaml. With a
+// messageTemplate, the commit message is pre-filled from the agent's prompt
+// and the full message is previewed.
+func (m *CheckpointModal) SetCheckpointConfig(checkpoint *config.CheckpointConfig) {
+	m.checkpoint = checkpoint
+}
+
 func (m *CheckpointModal) SetSize(width, height int) {
 	m.width = width
 	m.height = height
@@ -123,6 +191,67 @@ func (m *CheckpointModal) reset() {
 	m.conflicts = nil
 	m.error = ""
 	m.completed = false
+	m.resetFiles()
+}
+
+// resetFiles clears the file picker and overlap warning so they are
+// reloaded for the next agent
+func (m *CheckpointModal) resetFiles() {
+	m.overlaps = nil
+	m.files = nil
+	m.fileSelected = nil
+	m.fileIdx = 0
+	m.filesLoaded = false
+	m.filesError = ""
+}
+
+// SetFiles loads the changed files for the picker, selecting all of them
+func (m *CheckpointModal) SetFiles(files []string, errorMsg string) {
+	m.files = files
+	m.fileSelected = make(map[string]bool, len(files))
+	for _, file := range files {
+		m.fileSelected[file] = true
+	}
+	m.fileIdx = 0
+	m.filesLoaded = true
+	m.filesError = errorMsg
+}
+
+// SelectedPaths returns the globs for the chosen files, or nil when every file
+// is selected and the whole agent branch should be checkpointed
+func (m *CheckpointModal) SelectedPaths() []string {
+	if !m.filesLoaded || m.selectedFileCount() == len(m.files) {
+		return nil
+	}
+	var paths []string
+	for _, file := range m.files {
+		if m.fileSelected[file] {
+			paths = append(paths, escapeGlob(file))
+		}
+	}
+	return paths
+}
+
+func (m *CheckpointModal) selectedFileCount() int {
+	count := 0
+	for _, file := range m.files {
+		if m.fileSelected[file] {
+			count++
+		}
+	}
+	return count
+}
+
+// escapeGlob quotes glob metacharacters so a file name only matches itself
+func escapeGlob(file string) string {
+	var b strings.Builder
+	for _, r := range file {
+		if strings.ContainsRune(`*?[\`, r) {
+			b.WriteRune('\\')
+		}
+		b.WriteRune(r)
+	}
+	return b.String()
 }
 
 func (m CheckpointModal) Update(msg tea.Msg) (CheckpointModal, tea.Cmd) {
@@ -148,7 +277,13 @@ func (m CheckpointModal) Update(msg tea.Msg) (CheckpointModal, tea.Cmd) {
 			case "enter":
 				if len(m.agents) > 0 {
 					m.currentStep = CheckpointStepCommitMessage
+					m.prefillMessage()
 					m.commitInput.Focus()
+					// Warn before committing if other agents changed the same files
+					sessionName := m.agents[m.selectedIdx].Name
+					return m, func() tea.Msg {
+						return CheckpointOverlapRequestMsg{SessionName: sessionName}
+					}
 				}
 			case "esc":
 				m.visible = false
@@ -157,7 +292,23 @@ func (m CheckpointModal) Update(msg tea.Msg) (CheckpointModal, tea.Cmd) {
 
 		case CheckpointStepCommitMessage:
 			switch msg.String() {
+			case "tab":
+				// Open the file picker, loading the agent's changed files the first time
+				if len(m.agents) > 0 {
+					m.currentStep = CheckpointStepSelectFiles
+					m.commitInput.Blur()
+					if !m.filesLoaded {
+						sessionName := m.agents[m.selectedIdx].Name
+						return m, func() tea.Msg {
+							return CheckpointFilesRequestMsg{SessionName: sessionName}
+						}
+					}
+				}
 			case "enter":
+				if m.filesLoaded && m.selectedFileCount() == 0 {
+					m.filesError = "Select at least one file to checkpoint"
+					break
+				}
 				if strings.TrimSpace(m.commitInput.Value()) != "" && len(m.agents) > 0 {
 					m.currentStep = CheckpointStepProgress
 					m.commitInput.Blur()
@@ -165,22 +316,52 @@ func (m CheckpointModal) Update(msg tea.Msg) (CheckpointModal, tea.Cmd) {
 					cmds = append(cmds, m.spinner.Tick)
 					// Send checkpoint message
 					selectedAgent := m.agents[m.selectedIdx]
+					paths := m.SelectedPaths()
 					return m, tea.Batch(append(cmds, func() tea.Msg {
 						return CheckpointMsg{
 							AgentName:     selectedAgent.AgentName,
 							CommitMessage: strings.TrimSpace(m.commitInput.Value()),
+							Paths:         paths,
 						}
 					})...)
 				}
 			case "esc":
 				m.currentStep = CheckpointStepSelectAgent
 				m.commitInput.Blur()
+				m.resetFiles()
 			default:
 				var cmd tea.Cmd
 				m.commitInput, cmd = m.commitInput.Update(msg)
 				cmds = append(cmds, cmd)
 			}
 
+		case CheckpointStepSelectFiles:
+			switch msg.String() {
+			case "up", "k":
+				if m.fileIdx > 0 {
+					m.fileIdx--
+				}
+			case "down", "j":
+				if m.fileIdx < len(m.files)-1 {
+					m.fileIdx++
+				}
+			case " ":
+				if m.fileIdx < len(m.files) {
+					file := m.files[m.fileIdx]
+					m.fileSelected[file] = !m.fileSelected[file]
+				}
+			case "a":
+				// Toggle all: select everything unless everything is already selected
+				selectAll := m.selectedFileCount() < len(m.files)
+				for _, file := range m.files {
+					m.fileSelected[file] = selectAll
+				}
+			case "enter", "tab", "esc":
+				m.currentStep = CheckpointStepCommitMessage
+				m.filesError = ""
+				m.commitInput.Focus()
+			}
+
 		case CheckpointStepProgress:
 			switch msg.String() {
 			case "esc":
@@ -209,6 +390,17 @@ func (m CheckpointModal) Update(msg tea.Msg) (CheckpointModal, tea.Cmd) {
 	case CheckpointCompleteMsg:
 		m.SetComplete(msg.Success, msg.Error)
 
+	case CheckpointFilesMsg:
+		// Ignore results for an agent that is no longer selected
+		if len(m.agents) > 0 && m.agents[m.selectedIdx].Name == msg.SessionName {
+			m.SetFiles(msg.Files, msg.Error)
+		}
+
+	case CheckpointOverlapMsg:
+		if len(m.agents) > 0 && m.agents[m.selectedIdx].Name == msg.SessionName {
+			m.overlaps = msg.Overlaps
+		}
+
 	case spinner.TickMsg:
 		if m.currentStep == CheckpointStepProgress && !m.completed {
 			var cmd tea.Cmd
@@ -225,8 +417,9 @@ func (m CheckpointModal) View() string {
 		return ""
 	}
 
+	t := resolveTheme(m.theme)
 	var content string
-	title := ClaudeSquadAccentStyle.Render("🔄 Checkpoint Agent")
+	title := t.Accent.Render(t.Glyph("🔄 ", "") + "Checkpoint Agent")
 
 	switch m.currentStep {
 	case CheckpointStepSelectAgent:
@@ -237,23 +430,31 @@ func (m CheckpointModal) View() string {
 		if len(m.agents) > 0 && m.selectedIdx < len(m.agents) {
 			selectedAgent = m.agents[m.selectedIdx].AgentName
 		}
-		content = fmt.Sprintf("Agent: %s\n\n%s\n\n%s",
-			ClaudeSquadSelectedStyle.Render(selectedAgent),
+		content = fmt.Sprintf("Agent: %s\n%s%s\n\n%s%s\n\n%s",
+			t.Selected.Render(selectedAgent),
+			m.renderFileSummary(),
+			m.renderOverlapWarning(),
 			m.commitInput.View(),
-			ClaudeSquadMutedStyle.Render("Press Enter to commit, Esc to go back"))
+			m.renderMessagePreview(),
+			t.Muted.Render("Press Enter to commit, Tab to choose files, Esc to go back"))
+
+	case CheckpointStepSelectFiles:
+		content = m.renderFileSelection()
 
 	case CheckpointStepProgress:
 		content = m.renderProgress()
 
 	case CheckpointStepComplete:
 		if m.error != "" {
-			content = fmt.Sprintf("❌ Error: %s\n\n%s",
-				ErrorStyle.Render(m.error),
-				ClaudeSquadMutedStyle.Render("Press Enter or Esc to close"))
+			content = fmt.Sprintf("%sError: %s\n\n%s",
+				t.Glyph("❌ ", ""),
+				t.Error.Render(m.error),
+				t.Muted.Render("Press Enter or Esc to close"))
 		} else {
-			content = fmt.Sprintf("✅ %s\n\n%s",
-				ClaudeSquadAccentStyle.Render("Checkpoint completed successfully!"),
-				ClaudeSquadMutedStyle.Render("Press Enter or Esc to close"))
+			content = fmt.Sprintf("%s%s\n\n%s",
+				t.Glyph("✅ ", ""),
+				t.Accent.Render("Checkpoint completed successfully!"),
+				t.Muted.Render("Press Enter or Esc to close"))
 		}
 	}
 
@@ -265,6 +466,9 @@ func (m CheckpointModal) View() string {
 		Background(lipgloss.Color("235")).
 		Width(60).
 		Align(lipgloss.Center)
+	if t.Plain {
+		modalStyle = t.Border.Copy().Width(60)
+	}
 
 	modalContent := fmt.Sprintf("%s\n\n%s", title, content)
 	modal := modalStyle.Render(modalContent)
@@ -274,20 +478,21 @@ func (m CheckpointModal) View() string {
 }
 
 func (m CheckpointModal) renderAgentSelection() string {
+	t := resolveTheme(m.theme)
 	if len(m.agents) == 0 {
-		return ErrorStyle.Render("No agents available for checkpoint")
+		return t.Error.Render("No agents available for checkpoint")
 	}
 
 	var items []string
-	items = append(items, ClaudeSquadMutedStyle.Render("Select an agent to checkpoint:"))
+	items = append(items, t.Muted.Render("Select an agent to checkpoint:"))
 	items = append(items, "")
 
 	for i, agent := range m.agents {
 		prefix := "  "
-		style := ClaudeSquadPrimaryStyle
+		style := t.Primary
 		if i == m.selectedIdx {
-			prefix = "▶ "
-			style = ClaudeSquadSelectedStyle
+			prefix = t.Glyph("▶ ", "> ")
+			style = t.Selected
 		}
 
 		status := agent.Status
@@ -300,12 +505,13 @@ func (m CheckpointModal) renderAgentSelection() string {
 	}
 
 	items = append(items, "")
-	items = append(items, ClaudeSquadMutedStyle.Render("↑/↓ to navigate, Enter to select, Esc to cancel"))
+	items = append(items, t.Muted.Render(t.Glyph("↑/↓", "Up/Down")+" to navigate, Enter to select, Esc to cancel"))
 
 	return strings.Join(items, "\n")
 }
 
 func (m CheckpointModal) renderProgress() string {
+	t := resolveTheme(m.theme)
 	var lines []string
 
 	selectedAgent := ""
@@ -315,29 +521,29 @@ func (m CheckpointModal) renderProgress() string {
 	}
 	commitMsg = strings.TrimSpace(m.commitInput.Value())
 
-	lines = append(lines, fmt.Sprintf("Agent: %s", ClaudeSquadSelectedStyle.Render(selectedAgent)))
-	lines = append(lines, fmt.Sprintf("Message: %s", ClaudeSquadPrimaryStyle.Render(commitMsg)))
+	lines = append(lines, fmt.Sprintf("Agent: %s", t.Selected.Render(selectedAgent)))
+	lines = append(lines, fmt.Sprintf("Message: %s", t.Primary.Render(commitMsg)))
 	lines = append(lines, "")
 
 	if m.error != "" {
-		lines = append(lines, ErrorStyle.Render("❌ Error: "+m.error))
+		lines = append(lines, t.Error.Render(t.Glyph("❌ ", "")+"Error: "+m.error))
 	} else if m.completed {
-		lines = append(lines, ClaudeSquadAccentStyle.Render("✅ Checkpoint completed!"))
+		lines = append(lines, t.Accent.Render(t.Glyph("✅ ", "")+"Checkpoint completed!"))
 	} else {
 		lines = append(lines, fmt.Sprintf("%s Running git rebase...", m.spinner.View()))
 	}
 
 	if len(m.conflicts) > 0 {
 		lines = append(lines, "")
-		lines = append(lines, ErrorStyle.Render("⚠️  Conflicts detected:"))
+		lines = append(lines, t.Error.Render(t.Glyph("⚠️  ", "Warning: ")+"Conflicts detected:"))
 		for _, conflict := range m.conflicts {
-			lines = append(lines, "  "+ClaudeSquadMutedStyle.Render(conflict))
+			lines = append(lines, "  "+t.Muted.Render(conflict))
 		}
 	}
 
 	if m.progressText != "" {
 		lines = append(lines, "")
-		lines = append(lines, ClaudeSquadMutedStyle.Render("Output:"))
+		lines = append(lines, t.Muted.Render("Output:"))
 		// Limit output length to avoid huge modals
 		outputLines := strings.Split(m.progressText, "\n")
 		maxLines := 10
@@ -346,15 +552,157 @@ func (m CheckpointModal) renderProgress() string {
 		}
 		for _, line := range outputLines {
 			if strings.TrimSpace(line) != "" {
-				lines = append(lines, "  "+ClaudeSquadMutedStyle.Render(line))
+				lines = append(lines, "  "+t.Muted.Render(line))
 			}
 		}
 	}
 
 	if m.completed {
 		lines = append(lines, "")
-		lines = append(lines, ClaudeSquadMutedStyle.Render("Press Enter or Esc to close"))
+		lines = append(lines, t.Muted.Render("Press Enter or Esc to close"))
+	}
+
+	return strings.Join(lines, "\n")
+}
+
+// prefillMessage starts an empty commit message from the first line of the
+// selected agent's prompt when a message template is configured
+func (m *CheckpointModal) prefillMessage() {
+	if m.checkpoint == nil || m.checkpoint.MessageTemplate == "" || m.commitInput.Value() != "" {
+		return
 	}
+	prompt := strings.TrimSpace(m.agents[m.selectedIdx].Prompt)
+	if line, _, _ := strings.Cut(prompt, "\n"); line != "" {
+		// SetValue cuts it to the input's CharLimit
+		m.commitInput.SetValue(strings.TrimSpace(line))
+	}
+}
 
+// renderMessagePreview shows the commit message the template will produce,
+// or "" without a template
+func (m CheckpointModal) renderMessagePreview() string {
+	if m.checkpoint == nil || m.checkpoint.MessageTemplate == "" || len(m.agents) == 0 {
+		return ""
+	}
+	t := resolveTheme(m.theme)
+	agent := m.agents[m.selectedIdx]
+	message := m.checkpoint.RenderMessage(config.CheckpointMessage{
+		Message: strings.TrimSpace(m.commitInput.Value()),
+		Agent:   agent.AgentName,
+		Session: agent.Name,
+		Prompt:  agent.Prompt,
+		Model:   agent.Model,
+	})
+	lines := []string{"", "", t.Muted.Render("Commit message:")}
+	for _, line := range strings.Split(message, "\n") {
+		lines = append(lines, t.Muted.Render("  "+truncateLine(line, 52)))
+	}
 	return strings.Join(lines, "\n")
 }
+
+// renderFileSummary describes which files the checkpoint will include
+func (m CheckpointModal) renderFileSummary() string {
+	t := resolveTheme(m.theme)
+	if m.filesError != "" {
+		return t.Error.Render(m.filesError)
+	}
+	if !m.filesLoaded || m.selectedFileCount() == len(m.files) {
+		return t.Muted.Render("Files: all changes")
+	}
+	return t.Primary.Render(fmt.Sprintf("Files: %d of %d selected", m.selectedFileCount(), len(m.files)))
+}
+
+// maxOverlapLines caps the overlapping files listed in the commit step
+const maxOverlapLines = 3
+
+// renderOverlapWarning lists the files being checkpointed that other agents
+// have pending changes to, conflicting ones first, so the operator can decide
+// which agent to merge first
+func (m CheckpointModal) renderOverlapWarning() string {
+	t := resolveTheme(m.theme)
+	agentName := ""
+	if len(m.agents) > 0 && m.selectedIdx < len(m.agents) {
+		agentName = m.agents[m.selectedIdx].AgentName
+	}
+	others := func(names []string) string {
+		var filtered []string
+		for _, name := range names {
+			if name != agentName {
+				filtered = append(filtered, name)
+			}
+		}
+		return strings.Join(filtered, ", ")
+	}
+
+	var conflicting, shared []string
+	for _, overlap := range m.overlaps {
+		if m.filesLoaded && !m.fileSelected[overlap.Path] {
+			continue
+		}
+		if overlap.Conflicts(agentName) {
+			conflicting = append(conflicting, t.Error.Render(truncateLine(
+				fmt.Sprintf("%s%s conflicts with %s", t.Glyph("⚠ ", "! "), overlap.Path, others(overlap.Conflicting)), 56)))
+		} else {
+			shared = append(shared, t.Muted.Render(truncateLine(
+				fmt.Sprintf("  %s also changed by %s", overlap.Path, others(overlap.Agents)), 56)))
+		}
+	}
+	lines := append(conflicting, shared...)
+	if len(lines) == 0 {
+		return ""
+	}
+	if len(lines) > maxOverlapLines {
+		more := len(lines) - maxOverlapLines
+		lines = append(lines[:maxOverlapLines], t.Muted.Render(fmt.Sprintf("  …and %d more (uzi overlap)", more)))
+	}
+	if len(conflicting) > 0 {
+		lines = append(lines, t.Muted.Render("Checkpoint one agent, then rebase the others"))
+	}
+	return "\n" + strings.Join(lines, "\n")
+}
+
+// renderFileSelection renders the partial checkpoint file picker
+func (m CheckpointModal) renderFileSelection() string {
+	t := resolveTheme(m.theme)
+	if !m.filesLoaded {
+		return fmt.Sprintf("%s Loading changed files...", m.spinner.View())
+	}
+	if m.filesError != "" {
+		return fmt.Sprintf("%s\n\n%s",
+			t.Error.Render(m.filesError),
+			t.Muted.Render("Esc to go back"))
+	}
+	if len(m.files) == 0 {
+		return fmt.Sprintf("%s\n\n%s",
+			t.Muted.Render("No changed files"),
+			t.Muted.Render("Esc to go back"))
+	}
+
+	var items []string
+	items = append(items, t.Muted.Render("Select files to checkpoint:"))
+	items = append(items, "")
+
+	for i, file := range m.files {
+		prefix := "  "
+		style := t.Primary
+		if i == m.fileIdx {
+			prefix = t.Glyph("▶ ", "> ")
+			style = t.Selected
+		}
+		check := "[ ]"
+		if m.fileSelected[file] {
+			check = "[x]"
+		}
+		items = append(items, style.Render(fmt.Sprintf("%s%s %s", prefix, check, file)))
+	}
+
+	items = append(items, "")
+	items = append(items, t.Muted.Render(t.Glyph("↑/↓", "Up/Down")+" to navigate, Space to toggle, a for all, Enter when done"))
+
+	return strings.Join(items, "\n")
+}
+
+// SetTheme switches the style profile used to render the modal
+func (m *CheckpointModal) SetTheme(theme *Theme) {
+	m.theme = theme
+}
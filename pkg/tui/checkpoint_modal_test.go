@@ -1,10 +1,13 @@
 package tui
 
 import (
+	"reflect"
 	"strings"
 	"testing"
 
 	tea "github.com/charmbracelet/bubbletea"
+	"github.com/nehpz/claudicus/pkg/config"
+	"github.com/nehpz/claudicus/pkg/state"
 )
 
 func TestCheckpointModal_New(t *testing.T) {
@@ -134,3 +137,197 @@ func TestCheckpointModal_View_WhenVisible(t *testing.T) {
 		t.Error("Expected view to contain title")
 	}
 }
+
+func TestCheckpointModal_FilePicker(t *testing.T) {
+	modal := NewCheckpointModal()
+	modal.SetVisible(true)
+	modal.SetAgents([]SessionInfo{
+		{Name: "agent-test-abc123-claude", AgentName: "claude", Status: "ready"},
+	})
+
+	modal, _ = modal.Update(tea.KeyMsg{Type: tea.KeyEnter})
+
+	// Tab opens the picker and requests the agent's changed files
+	modal, cmd := modal.Update(tea.KeyMsg{Type: tea.KeyTab})
+	if modal.currentStep != CheckpointStepSelectFiles {
+		t.Fatal("Expected Tab to open the file picker")
+	}
+	if cmd == nil {
+		t.Fatal("Expected a command requesting changed files")
+	}
+	request, ok := cmd().(CheckpointFilesRequestMsg)
+	if !ok || request.SessionName != "agent-test-abc123-claude" {
+		t.Fatalf("Expected CheckpointFilesRequestMsg for the agent, got %#v", request)
+	}
+
+	modal, _ = modal.Update(CheckpointFilesMsg{
+		SessionName: "agent-test-abc123-claude",
+		Files:       []string{"main.go", "scratch/[draft].txt", "README.md"},
+	})
+	if len(modal.files) != 3 || modal.SelectedPaths() != nil {
+		t.Fatal("Expected all files to be selected initially")
+	}
+
+	// Deselect main.go
+	modal, _ = modal.Update(tea.KeyMsg{Type: tea.KeySpace, Runes: []rune{' '}})
+	if modal.fileSelected["main.go"] {
+		t.Error("Expected Space to deselect the file under the cursor")
+	}
+
+	want := []string{`scratch/\[draft].txt`, "README.md"}
+	if got := modal.SelectedPaths(); !reflect.DeepEqual(got, want) {
+		t.Errorf("SelectedPaths() = %v, want %v", got, want)
+	}
+
+	// Done picking, commit with the selected paths
+	modal, _ = modal.Update(tea.KeyMsg{Type: tea.KeyEnter})
+	if modal.currentStep != CheckpointStepCommitMessage {
+		t.Fatal("Expected Enter to return to the commit message step")
+	}
+	modal.commitInput.SetValue("partial work")
+	modal, cmd = modal.Update(tea.KeyMsg{Type: tea.KeyEnter})
+	if modal.currentStep != CheckpointStepProgress {
+		t.Fatal("Expected checkpoint to start")
+	}
+
+	var checkpoint CheckpointMsg
+	for _, m := range cmd().(tea.BatchMsg) {
+		if m == nil {
+			continue
+		}
+		if msg, ok := m().(CheckpointMsg); ok {
+			checkpoint = msg
+		}
+	}
+	if !reflect.DeepEqual(checkpoint.Paths, want) {
+		t.Errorf("CheckpointMsg.Paths = %v, want %v", checkpoint.Paths, want)
+	}
+}
+
+func TestCheckpointModal_FilePickerRequiresSelection(t *testing.T) {
+	modal := NewCheckpointModal()
+	modal.SetVisible(true)
+	modal.SetAgents([]SessionInfo{
+		{Name: "agent-test-abc123-claude", AgentName: "claude", Status: "ready"},
+	})
+	modal, _ = modal.Update(tea.KeyMsg{Type: tea.KeyEnter})
+	modal, _ = modal.Update(tea.KeyMsg{Type: tea.KeyTab})
+	modal, _ = modal.Update(CheckpointFilesMsg{SessionName: "agent-test-abc123-claude", Files: []string{"a.go", "b.go"}})
+
+	// 'a' toggles all files off since they start selected
+	modal, _ = modal.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune{'a'}})
+	if modal.selectedFileCount() != 0 {
+		t.Fatalf("Expected no files selected, got %d", modal.selectedFileCount())
+	}
+
+	modal, _ = modal.Update(tea.KeyMsg{Type: tea.KeyEsc})
+	modal.commitInput.SetValue("nothing")
+	modal, _ = modal.Update(tea.KeyMsg{Type: tea.KeyEnter})
+	if modal.currentStep != CheckpointStepCommitMessage {
+		t.Error("Expected checkpoint to be blocked with no files selected")
+	}
+	if modal.filesError == "" {
+		t.Error("Expected an error explaining that a file must be selected")
+	}
+}
+
+func TestCheckpointModal_IgnoresFilesForOtherAgent(t *testing.T) {
+	modal := NewCheckpointModal()
+	modal.SetVisible(true)
+	modal.SetAgents([]SessionInfo{
+		{Name: "agent-test-abc123-claude", AgentName: "claude", Status: "ready"},
+	})
+
+	modal, _ = modal.Update(CheckpointFilesMsg{SessionName: "agent-test-def456-cursor", Files: []string{"x.go"}})
+	if modal.filesLoaded {
+		t.Error("Expected files for another agent to be ignored")
+	}
+}
+
+func TestCheckpointModal_OverlapWarning(t *testing.T) {
+	modal := NewCheckpointModal()
+	modal.SetVisible(true)
+	modal.SetAgents([]SessionInfo{
+		{Name: "agent-test-abc123-claude", AgentName: "claude", Status: "ready"},
+	})
+
+	// Choosing the agent checks for other agents' changes to its files
+	modal, cmd := modal.Update(tea.KeyMsg{Type: tea.KeyEnter})
+	if cmd == nil {
+		t.Fatal("Expected a command requesting overlapping files")
+	}
+	request, ok := cmd().(CheckpointOverlapRequestMsg)
+	if !ok || request.SessionName != "agent-test-abc123-claude" {
+		t.Fatalf("Expected CheckpointOverlapRequestMsg for the agent, got %#v", request)
+	}
+
+	overlaps := []state.FileOverlap{
+		{Path: "README.md", Agents: []string{"claude", "codex"}},
+		{Path: "main.go", Agents: []string{"claude", "codex", "gemini"}, Conflicting: []string{"claude", "gemini"}},
+	}
+	modal, _ = modal.Update(CheckpointOverlapMsg{SessionName: "agent-test-def456-cursor", Overlaps: overlaps})
+	if modal.overlaps != nil {
+		t.Error("Expected overlaps for another agent to be ignored")
+	}
+	modal, _ = modal.Update(CheckpointOverlapMsg{SessionName: "agent-test-abc123-claude", Overlaps: overlaps})
+	view := modal.View()
+	if !strings.Contains(view, "main.go conflicts with gemini") || !strings.Contains(view, "README.md also changed by codex") {
+		t.Errorf("Expected the overlapping files in the commit step, got:\n%s", view)
+	}
+
+	// Files left out of a partial checkpoint don't warn
+	modal.SetFiles([]string{"main.go", "README.md"}, "")
+	modal.fileSelected["main.go"] = false
+	if view := modal.View(); strings.Contains(view, "main.go conflicts") {
+		t.Errorf("Expected no warning for a deselected file, got:\n%s", view)
+	}
+
+	// Going back to choose another agent clears the warning
+	modal, _ = modal.Update(tea.KeyMsg{Type: tea.KeyEsc})
+	if modal.overlaps != nil {
+		t.Error("Expected the overlaps cleared when going back")
+	}
+}
+
+func TestCheckpointModal_MessageTemplate(t *testing.T) {
+	modal := NewCheckpointModal()
+	modal.SetCheckpointConfig(&config.CheckpointConfig{MessageTemplate: "agent({agent}): {message}\n\nSession: {session}"})
+	modal.SetAgents([]SessionInfo{{
+		Name:      "agent-test-abc123-sarah",
+		AgentName: "sarah",
+		Prompt:    "Add a login page\nwith OAuth support",
+	}})
+	modal.SetVisible(true)
+
+	modal, _ = modal.Update(tea.KeyMsg{Type: tea.KeyEnter})
+	if got := modal.commitInput.Value(); got != "Add a login page" {
+		t.Errorf("Expected the input pre-filled with the prompt's first line, got %q", got)
+	}
+	view := modal.View()
+	for _, want := range []string{"Commit message:", "agent(sarah): Add a login page", "Session: agent-test-abc123-sarah"} {
+		if !strings.Contains(view, want) {
+			t.Errorf("Expected the preview to contain %q, got:\n%s", want, view)
+		}
+	}
+
+	// The modal sends only the message; uzi checkpoint applies the template
+	modal, cmd := modal.Update(tea.KeyMsg{Type: tea.KeyEnter})
+	var checkpoint CheckpointMsg
+	for _, msg := range cmd().(tea.BatchMsg) {
+		if m, ok := msg().(CheckpointMsg); ok {
+			checkpoint = m
+		}
+	}
+	if checkpoint.CommitMessage != "Add a login page" {
+		t.Errorf("Expected CheckpointMsg with the untemplated message, got %+v", checkpoint)
+	}
+
+	// Without a template nothing is pre-filled or previewed
+	plain := NewCheckpointModal()
+	plain.SetAgents([]SessionInfo{{Name: "agent-test-abc123-sarah", AgentName: "sarah", Prompt: "Add a login page"}})
+	plain.SetVisible(true)
+	plain, _ = plain.Update(tea.KeyMsg{Type: tea.KeyEnter})
+	if plain.commitInput.Value() != "" || strings.Contains(plain.View(), "Commit message:") {
+		t.Error("Expected no pre-fill or preview without a message template")
+	}
+}
@@ -0,0 +1,201 @@
+// Copyright (c) Subtrace, Inc.
+// SPDX-License-Identifier: BSD-3-Clause
+
+package tui
+
+import (
+	"fmt"
+
+	"github.com/charmbracelet/bubbles/key"
+	"github.com/charmbracelet/bubbles/textinput"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+)
+
+// PaletteCommand is an action listed in the command palette. Running it
+// replays Msg, a key press of the action's binding, so the palette goes
+// through the same handlers as the keys; actions that need arguments open
+// their own prompt from there.
+type PaletteCommand struct {
+	Title string
+	Keys  string // Keys shown next to the title
+	Msg   tea.KeyMsg
+}
+
+// CommandPalette is an overlay listing every action, filtered by fuzzy search
+// as the user types. Enter runs the action under the cursor and Esc closes it.
+type CommandPalette struct {
+	visible  bool
+	input    textinput.Model
+	commands []PaletteCommand
+	matches  []paletteMatch
+	cursor   int
+	keys     *KeyMap
+	theme    *Theme
+}
+
+// paletteMatch is a command that matches the query, with the matched title runes
+type paletteMatch struct {
+	command PaletteCommand
+	indexes []int
+}
+
+// NewCommandPalette creates a hidden command palette
+func NewCommandPalette(keys *KeyMap) *CommandPalette {
+	ti := textinput.New()
+	ti.Placeholder = "Type a command..."
+	ti.CharLimit = 64
+	ti.Width = 50
+	return &CommandPalette{input: ti, keys: keys, theme: DefaultTheme()}
+}
+
+// SetTheme switches the style profile used to render the palette
+func (p *CommandPalette) SetTheme(theme *Theme) {
+	p.theme = theme
+}
+
+// SetCommands replaces the listed commands
+func (p *CommandPalette) SetCommands(commands []PaletteCommand) {
+	p.commands = commands
+	p.filter()
+}
+
+// Show opens the palette with an empty query
+func (p *CommandPalette) Show() {
+	p.visible = true
+	p.input.SetValue("")
+	p.input.Focus()
+	p.filter()
+}
+
+// Hide closes the palette
+func (p *CommandPalette) Hide() {
+	p.visible = false
+	p.input.Blur()
+}
+
+// Focused reports whether the palette is open
+func (p *CommandPalette) Focused() bool {
+	return p.visible
+}
+
+// Update filters the commands as the user types and runs the selected one on
+// Enter. Only the arrow keys and ctrl+n/ctrl+p move the cursor, since every
+// printable key is part of the query.
+func (p *CommandPalette) Update(msg tea.Msg) tea.Cmd {
+	keyMsg, ok := msg.(tea.KeyMsg)
+	if !ok {
+		return nil
+	}
+	switch {
+	case key.Matches(keyMsg, p.keys.Escape):
+		p.Hide()
+		return nil
+	case key.Matches(keyMsg, p.keys.Enter):
+		if len(p.matches) == 0 {
+			return nil
+		}
+		p.Hide()
+		run := p.matches[p.cursor].command.Msg
+		return func() tea.Msg { return run }
+	case keyMsg.Type == tea.KeyUp || keyMsg.Type == tea.KeyCtrlP:
+		if p.cursor > 0 {
+			p.cursor--
+		}
+		return nil
+	case keyMsg.Type == tea.KeyDown || keyMsg.Type == tea.KeyCtrlN:
+		if p.cursor < len(p.matches)-1 {
+			p.cursor++
+		}
+		return nil
+	}
+
+	var cmd tea.Cmd
+	p.input, cmd = p.input.Update(keyMsg)
+	p.filter()
+	return cmd
+}
+
+// filter matches the commands against the query and moves the cursor to the top
+func (p *CommandPalette) filter() {
+	query := p.input.Value()
+	p.matches = p.matches[:0]
+	for _, command := range p.commands {
+		if query == "" {
+			p.matches = append(p.matches, paletteMatch{command: command})
+			continue
+		}
+		if indexes := fuzzyMatch(query, command.Title); indexes != nil {
+			p.matches = append(p.matches, paletteMatch{command: command, indexes: indexes})
+		}
+	}
+	p.cursor = 0
+}
+
+// Selected returns the command under the cursor
+func (p *CommandPalette) Selected() (PaletteCommand, bool) {
+	if len(p.matches) == 0 {
+		return PaletteCommand{}, false
+	}
+	return p.matches[p.cursor].command, true
+}
+
+// View renders the query and the matching commands with their keys
+func (p *CommandPalette) View() string {
+	if !p.visible {
+		return ""
+	}
+
+	t := resolveTheme(p.theme)
+	lines := []string{t.Accent.Copy().Bold(true).Render("Command: ") + p.input.View(), ""}
+	if len(p.matches) == 0 {
+		lines = append(lines, t.Muted.Render("No matching commands"))
+	}
+	for i, match := range p.matches {
+		cursor := "  "
+		if i == p.cursor {
+			cursor = t.Glyph("▶ ", "> ")
+		}
+		title := highlightMatches(match.command.Title, match.indexes, t)
+		padding := 36 - lipgloss.Width(match.command.Title)
+		if padding < 1 {
+			padding = 1
+		}
+		line := fmt.Sprintf("%s%s%*s%s", cursor, title, padding, "", t.Muted.Render(match.command.Keys))
+		if i == p.cursor {
+			line = t.Primary.Render(line)
+		}
+		lines = append(lines, line)
+	}
+	lines = append(lines, "", t.Muted.Render("[↑/↓] select  [Enter] run  [ESC] close"))
+
+	return t.Border.Copy().
+		Width(60).
+		Render(lipgloss.JoinVertical(lipgloss.Left, lines...))
+}
+
+// bindingKeyMsg returns a press of the binding's first key, for replaying the
+// binding's action
+func bindingKeyMsg(binding key.Binding) tea.KeyMsg {
+	keys := binding.Keys()
+	if len(keys) == 0 {
+		return tea.KeyMsg{}
+	}
+	return keyMsgFor(keys[0])
+}
+
+// keyMsgFor returns the key press whose String() is name, e.g. "ctrl+p",
+// "enter", or "x"
+func keyMsgFor(name string) tea.KeyMsg {
+	// Named keys have a key type of their own, from KeyF20 (the lowest) up to
+	// backspace (DEL); everything else is typed runes
+	for keyType := tea.KeyF20; keyType <= tea.KeyBackspace; keyType++ {
+		if keyType == tea.KeyRunes {
+			continue
+		}
+		if msg := (tea.KeyMsg{Type: keyType}); msg.String() == name {
+			return msg
+		}
+	}
+	return tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune(name)}
+}
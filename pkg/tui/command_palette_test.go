@@ -0,0 +1,136 @@
+// Copyright (c) Subtrace, Inc.
+// SPDX-License-Identifier: BSD-3-Clause
+
+package tui
+
+import (
+	"strings"
+	"testing"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+func TestKeyMsgFor(t *testing.T) {
+	for _, name := range []string{"ctrl+p", "enter", "esc", "tab", "up", "x", "F", "1", "?", "/"} {
+		if got := keyMsgFor(name).String(); got != name {
+			t.Errorf("keyMsgFor(%q).String() = %q", name, got)
+		}
+	}
+	if keyMsgFor("enter").Type != tea.KeyEnter {
+		t.Error("Expected enter to be a named key, not typed runes")
+	}
+}
+
+// typeKeys sends each rune of s to the app as a key press
+func typeKeys(app *App, s string) {
+	for _, r := range s {
+		app.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune{r}})
+	}
+}
+
+// runPaletteCommand presses Enter in the palette and replays the key it runs
+func runPaletteCommand(t *testing.T, app *App) {
+	t.Helper()
+	_, cmd := app.Update(tea.KeyMsg{Type: tea.KeyEnter})
+	if cmd == nil {
+		t.Fatal("Expected the palette to run a command")
+	}
+	msg, ok := cmd().(tea.KeyMsg)
+	if !ok {
+		t.Fatalf("Expected the command to replay a key, got %T", msg)
+	}
+	app.Update(msg)
+}
+
+func TestCommandPaletteRunsAction(t *testing.T) {
+	app := NewApp(&MockUziInterface{})
+	defer app.Cleanup()
+
+	app.Update(tea.KeyMsg{Type: tea.KeyCtrlP})
+	if app.modals.Top() != app.palette {
+		t.Fatal("Expected ctrl+p to open the command palette")
+	}
+
+	typeKeys(app, "brdcst")
+	selected, ok := app.palette.Selected()
+	if !ok || selected.Title != "broadcast message" {
+		t.Fatalf("Expected a fuzzy match on broadcast, got %+v", selected)
+	}
+	if view := app.palette.View(); strings.Contains(view, "kill agent") {
+		t.Error("Expected non-matching commands filtered out")
+	}
+
+	// Broadcast needs a message, so running it opens its own prompt
+	runPaletteCommand(t, app)
+	if app.modals.Top() != app.broadcastOverlay {
+		t.Error("Expected the broadcast prompt to open from the palette")
+	}
+}
+
+func TestCommandPaletteNavigationAndEscape(t *testing.T) {
+	app := NewApp(&MockUziInterface{})
+	defer app.Cleanup()
+
+	app.Update(tea.KeyMsg{Type: tea.KeyCtrlP})
+	first, _ := app.palette.Selected()
+	app.Update(tea.KeyMsg{Type: tea.KeyDown})
+	second, _ := app.palette.Selected()
+	if first.Title == second.Title {
+		t.Error("Expected down to move to the next command")
+	}
+
+	// j is part of the query, not a cursor key
+	typeKeys(app, "j")
+	if selected, ok := app.palette.Selected(); ok && selected.Title == second.Title {
+		t.Errorf("Expected typing to filter, still on %q", selected.Title)
+	}
+
+	typeKeys(app, "zzzz")
+	if _, ok := app.palette.Selected(); ok {
+		t.Error("Expected no matches")
+	}
+	if _, cmd := app.Update(tea.KeyMsg{Type: tea.KeyEnter}); cmd != nil {
+		t.Error("Expected Enter without matches to do nothing")
+	}
+
+	app.Update(tea.KeyMsg{Type: tea.KeyEsc})
+	if app.modals.Active() {
+		t.Error("Expected Esc to close the palette")
+	}
+}
+
+func TestCommandPaletteFollowsKeyMapAndPresets(t *testing.T) {
+	app := NewApp(&MockUziInterface{})
+	defer app.Cleanup()
+
+	keys, err := app.keys.WithOverrides(map[string][]string{"filterStuck": {"S"}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	app.keys = keys
+	app.tuiState = &TUIState{Presets: []FilterPreset{{Name: "review", Tag: "github#1"}}}
+	app.tuiStatePath = t.TempDir() + "/tui-state.json"
+
+	var preset, stuck PaletteCommand
+	for _, command := range app.paletteCommands() {
+		switch command.Title {
+		case `apply preset "review"`:
+			preset = command
+		case "toggle stuck agents filter":
+			stuck = command
+		}
+	}
+	if stuck.Keys != "S" || stuck.Msg.String() != "S" {
+		t.Errorf("Expected the remapped key, got %+v", stuck)
+	}
+	if preset.Keys != "1" {
+		t.Fatalf("Expected the preset on 1, got %+v", preset)
+	}
+
+	app.Update(tea.KeyMsg{Type: tea.KeyCtrlP})
+	typeKeys(app, "review")
+	runPaletteCommand(t, app)
+	if app.list.TagFilter() != "github#1" {
+		t.Errorf("Expected the preset applied, got tag filter %q", app.list.TagFilter())
+	}
+}
@@ -0,0 +1,304 @@
+// Copyright (c) Subtrace, Inc.
+// SPDX-License-Identifier: BSD-3-Clause
+
+package tui
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/charmbracelet/bubbles/key"
+	"github.com/charmbracelet/bubbles/viewport"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+	"github.com/nehpz/claudicus/pkg/state"
+)
+
+// sessionPatcher is implemented by UziInterface backends that can print the
+// full diff of a session, which the compare view shows side by side
+type sessionPatcher interface {
+	GetSessionPatch(sessionName string) (string, error)
+}
+
+// compareOverlapShown is how many overlapping files the compare header names
+// before summarizing the rest
+const compareOverlapShown = 4
+
+// CompareSide is the diff of one of the two agents in the compare view
+type CompareSide struct {
+	SessionName string
+	Patch       string
+	Stat        *state.DiffStat // nil if the per-file breakdown failed
+	Error       string
+}
+
+// CompareMsg carries the diffs of both agents of the compare view
+type CompareMsg struct {
+	Left  CompareSide
+	Right CompareSide
+}
+
+// CompareView is an overlay showing the diffs of two agents side by side, so
+// two candidate solutions can be weighed without leaving the TUI. Both panes
+// scroll together, and the header names the files both agents changed.
+type CompareView struct {
+	visible bool
+	loaded  bool
+	left    CompareSide
+	right   CompareSide
+	overlap []string // Files changed by both agents, sorted
+	panes   [2]viewport.Model
+	keys    *KeyMap
+	theme   *Theme
+}
+
+// NewCompareView creates a hidden compare view
+func NewCompareView(keys *KeyMap) *CompareView {
+	return &CompareView{
+		keys:  keys,
+		theme: DefaultTheme(),
+		panes: [2]viewport.Model{viewport.New(40, 15), viewport.New(40, 15)},
+	}
+}
+
+// SetTheme switches the style profile used to render the view
+func (v *CompareView) SetTheme(theme *Theme) {
+	v.theme = theme
+	v.render()
+}
+
+// SetSize splits a terminal of the given size between the two panes
+func (v *CompareView) SetSize(width, height int) {
+	paneWidth := max(20, (min(width, 240)-7)/2)
+	paneHeight := max(5, height-12)
+	for i := range v.panes {
+		v.panes[i].Width = paneWidth
+		v.panes[i].Height = paneHeight
+	}
+	v.render()
+}
+
+// Open shows the view for two sessions; their diffs are filled in by SetDiffs
+// once loaded
+func (v *CompareView) Open(left, right string) {
+	v.left = CompareSide{SessionName: left}
+	v.right = CompareSide{SessionName: right}
+	v.loaded = false
+	v.overlap = nil
+	for i := range v.panes {
+		v.panes[i].SetContent("")
+		v.panes[i].GotoTop()
+	}
+}
+
+// Sessions returns the names of the compared sessions, left first
+func (v *CompareView) Sessions() (string, string) {
+	return v.left.SessionName, v.right.SessionName
+}
+
+// Show opens the view
+func (v *CompareView) Show() {
+	v.visible = true
+}
+
+// Hide closes the view
+func (v *CompareView) Hide() {
+	v.visible = false
+}
+
+// Focused reports whether the view is open
+func (v *CompareView) Focused() bool {
+	return v.visible
+}
+
+// SetDiffs fills both panes and works out which files both agents changed
+func (v *CompareView) SetDiffs(left, right CompareSide) {
+	v.left, v.right = left, right
+	v.loaded = true
+	v.overlap = nil
+	if left.Stat != nil && right.Stat != nil {
+		overlap := state.Overlap(map[string]state.DiffStat{
+			left.SessionName:  *left.Stat,
+			right.SessionName: *right.Stat,
+		})
+		for path := range overlap {
+			v.overlap = append(v.overlap, path)
+		}
+		sort.Strings(v.overlap)
+	}
+	v.render()
+	for i := range v.panes {
+		v.panes[i].GotoTop()
+	}
+}
+
+// Overlap returns the files both agents changed, sorted
+func (v *CompareView) Overlap() []string {
+	return v.overlap
+}
+
+// render styles both diffs into their panes at the current width
+func (v *CompareView) render() {
+	if !v.loaded {
+		return
+	}
+	shared := make(map[string]bool, len(v.overlap))
+	for _, path := range v.overlap {
+		shared[path] = true
+	}
+	t := resolveTheme(v.theme)
+	for i, side := range []CompareSide{v.left, v.right} {
+		offset := v.panes[i].YOffset
+		v.panes[i].SetContent(styleCompareDiff(side.Patch, v.panes[i].Width, shared, t))
+		v.panes[i].SetYOffset(offset)
+	}
+}
+
+// Update scrolls both panes together, jumps to either end with g and G, and
+// closes the view on Esc or the compare key
+func (v *CompareView) Update(msg tea.Msg) tea.Cmd {
+	keyMsg, ok := msg.(tea.KeyMsg)
+	if !ok {
+		return nil
+	}
+	switch {
+	case key.Matches(keyMsg, v.keys.Escape, v.keys.Compare):
+		v.Hide()
+		return nil
+	case keyMsg.String() == "g" || keyMsg.String() == "home":
+		v.panes[0].GotoTop()
+		v.panes[1].GotoTop()
+		return nil
+	case keyMsg.String() == "G" || keyMsg.String() == "end":
+		v.panes[0].GotoBottom()
+		v.panes[1].GotoBottom()
+		return nil
+	}
+
+	// Scroll the longer diff and keep the other at the same line, so both
+	// stay in step even after the shorter one runs out
+	lead, follow := 0, 1
+	if v.panes[1].TotalLineCount() > v.panes[0].TotalLineCount() {
+		lead, follow = 1, 0
+	}
+	var cmd tea.Cmd
+	v.panes[lead], cmd = v.panes[lead].Update(keyMsg)
+	v.panes[follow].SetYOffset(v.panes[lead].YOffset)
+	return cmd
+}
+
+// View renders the overlap header above the two diffs
+func (v *CompareView) View() string {
+	if !v.visible {
+		return ""
+	}
+
+	t := resolveTheme(v.theme)
+	leftName, rightName := extractAgentName(v.left.SessionName), extractAgentName(v.right.SessionName)
+	title := t.Accent.Render(fmt.Sprintf("Compare: %s vs %s", leftName, rightName))
+
+	var body string
+	if !v.loaded {
+		body = t.Muted.Render("Loading both diffs...")
+	} else {
+		header := lipgloss.JoinVertical(lipgloss.Left,
+			compareSummary(leftName, v.left.Stat, t),
+			compareSummary(rightName, v.right.Stat, t),
+			v.overlapSummary(t),
+		)
+		separator := t.Muted.Render(strings.TrimSuffix(strings.Repeat("│\n", v.panes[0].Height+1), "\n"))
+		panes := lipgloss.JoinHorizontal(lipgloss.Top,
+			v.paneView(0, leftName, v.left, t),
+			" "+separator+" ",
+			v.paneView(1, rightName, v.right, t),
+		)
+		body = lipgloss.JoinVertical(lipgloss.Left, header, "", panes)
+	}
+
+	footer := "[↑/↓ pgup/pgdn] scroll both  [g/G] top/bottom  [ESC] close"
+	return t.Border.Copy().
+		Width(2*v.panes[0].Width + 5).
+		Render(lipgloss.JoinVertical(lipgloss.Left, title, "", body, "", t.Muted.Render(footer)))
+}
+
+// paneView renders one agent's name above its diff
+func (v *CompareView) paneView(i int, agentName string, side CompareSide, t *Theme) string {
+	var content string
+	switch {
+	case side.Error != "":
+		content = t.Error.Render(truncateLine("Error: "+side.Error, v.panes[i].Width))
+	case strings.TrimSpace(side.Patch) == "":
+		content = t.Muted.Render("No changes in this session")
+	default:
+		content = v.panes[i].View()
+	}
+	return lipgloss.NewStyle().Width(v.panes[i].Width).Render(
+		lipgloss.JoinVertical(lipgloss.Left, t.Primary.Render(agentName), content))
+}
+
+// overlapSummary names the files both agents changed, which are the likely
+// merge conflicts between the two solutions
+func (v *CompareView) overlapSummary(t *Theme) string {
+	if v.left.Stat == nil || v.right.Stat == nil {
+		return t.Muted.Render("Overlapping files unknown: a per-file breakdown failed")
+	}
+	if len(v.overlap) == 0 {
+		return t.Added.Render("No overlapping files")
+	}
+	shown := v.overlap
+	more := ""
+	if len(shown) > compareOverlapShown {
+		shown = shown[:compareOverlapShown]
+		more = fmt.Sprintf(" and %d more", len(v.overlap)-compareOverlapShown)
+	}
+	return t.Warning.Render(fmt.Sprintf("Overlapping files (%d): %s%s", len(v.overlap), strings.Join(shown, ", "), more))
+}
+
+// compareSummary sums up one agent's changes for the compare header
+func compareSummary(agentName string, stat *state.DiffStat, t *Theme) string {
+	if stat == nil {
+		return fmt.Sprintf("%s  %s", agentName, t.Muted.Render("no per-file breakdown"))
+	}
+	return fmt.Sprintf("%s  %d file(s)  %s %s", agentName, len(stat.Files),
+		t.Added.Render(fmt.Sprintf("+%d", stat.Insertions())),
+		t.Removed.Render(fmt.Sprintf("-%d", stat.Deletions())))
+}
+
+// styleCompareDiff highlights a diff for a pane of the given width. Lines are
+// cut to the width rather than wrapped, so both panes keep one row per line;
+// the headers of files both agents changed stand out.
+func styleCompareDiff(patch string, width int, shared map[string]bool, t *Theme) string {
+	lines := strings.Split(strings.TrimRight(patch, "\n"), "\n")
+	for i, raw := range lines {
+		line := truncateLine(strings.ReplaceAll(raw, "\t", "    "), width)
+		switch {
+		case strings.HasPrefix(line, "diff --git "):
+			if shared[diffHeaderPath(raw)] {
+				lines[i] = t.Warning.Render(line)
+			} else {
+				lines[i] = t.Accent.Render(line)
+			}
+		case strings.HasPrefix(line, "+++") || strings.HasPrefix(line, "---"):
+			lines[i] = t.Accent.Render(line)
+		case strings.HasPrefix(line, "@@"):
+			lines[i] = t.Primary.Render(line)
+		case strings.HasPrefix(line, "+"):
+			lines[i] = t.Added.Render(line)
+		case strings.HasPrefix(line, "-"):
+			lines[i] = t.Removed.Render(line)
+		default:
+			lines[i] = line
+		}
+	}
+	return strings.Join(lines, "\n")
+}
+
+// diffHeaderPath returns the path of a "diff --git a/path b/path" line
+func diffHeaderPath(line string) string {
+	rest := strings.TrimPrefix(line, "diff --git ")
+	if i := strings.LastIndex(rest, " b/"); i >= 0 {
+		return rest[i+len(" b/"):]
+	}
+	return ""
+}
@@ -0,0 +1,229 @@
+// Copyright (c) Subtrace, Inc.
+// SPDX-License-Identifier: BSD-3-Clause
+
+package tui
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+	"testing"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/nehpz/claudicus/pkg/state"
+	"github.com/nehpz/claudicus/pkg/testutil/cmdmock"
+)
+
+// patchingUziMock serves a fixed patch and breakdown per session
+type patchingUziMock struct {
+	MockUziInterface
+	patches map[string]string
+	stats   map[string]*state.DiffStat
+}
+
+func (m *patchingUziMock) GetSessionPatch(sessionName string) (string, error) {
+	patch, ok := m.patches[sessionName]
+	if !ok {
+		return "", fmt.Errorf("session not found: %s", sessionName)
+	}
+	return patch, nil
+}
+
+func (m *patchingUziMock) GetSessionDiffStat(sessionName string) (*state.DiffStat, error) {
+	return m.stats[sessionName], nil
+}
+
+// comparePatch returns a diff of n added lines to path
+func comparePatch(path string, n int) string {
+	lines := []string{"diff --git a/" + path + " b/" + path, "--- a/" + path, "+++ b/" + path, fmt.Sprintf("@@ -0,0 +1,%d @@", n)}
+	for i := 0; i < n; i++ {
+		lines = append(lines, fmt.Sprintf("+line %d", i+1))
+	}
+	return strings.Join(lines, "\n")
+}
+
+func TestCompareView_View(t *testing.T) {
+	keys := DefaultKeyMap()
+	view := NewCompareView(&keys)
+	view.SetTheme(PlainTheme())
+	view.SetSize(120, 40)
+	if view.View() != "" {
+		t.Error("Hidden compare view should render nothing")
+	}
+
+	view.Open("agent-app-abc123-sarah", "agent-app-abc123-emily")
+	view.Show()
+	if output := view.View(); !strings.Contains(output, "Compare: sarah vs emily") || !strings.Contains(output, "Loading") {
+		t.Errorf("Expected the loading message, got %q", output)
+	}
+
+	view.SetDiffs(
+		CompareSide{SessionName: "agent-app-abc123-sarah", Patch: comparePatch("main.go", 2), Stat: &state.DiffStat{Files: []state.FileDiff{
+			{Path: "main.go", Insertions: 2}, {Path: "util.go", Insertions: 1, Deletions: 3},
+		}}},
+		CompareSide{SessionName: "agent-app-abc123-emily", Patch: comparePatch("main.go", 1), Stat: &state.DiffStat{Files: []state.FileDiff{
+			{Path: "main.go", Insertions: 1},
+		}}},
+	)
+	if got := view.Overlap(); !reflect.DeepEqual(got, []string{"main.go"}) {
+		t.Errorf("Overlap() = %v, want [main.go]", got)
+	}
+	output := view.View()
+	for _, want := range []string{"sarah  2 file(s)  +3 -3", "emily  1 file(s)  +1 -0", "Overlapping files (1): main.go", "+line 2"} {
+		if !strings.Contains(output, want) {
+			t.Errorf("Expected %q in the view, got %q", want, output)
+		}
+	}
+
+	view.SetDiffs(
+		CompareSide{SessionName: "agent-app-abc123-sarah", Error: "git diff failed"},
+		CompareSide{SessionName: "agent-app-abc123-emily", Stat: &state.DiffStat{}},
+	)
+	output = view.View()
+	for _, want := range []string{"Error: git diff failed", "No changes in this session", "Overlapping files unknown"} {
+		if !strings.Contains(output, want) {
+			t.Errorf("Expected %q in the view, got %q", want, output)
+		}
+	}
+}
+
+func TestCompareView_SynchronizedScrolling(t *testing.T) {
+	keys := DefaultKeyMap()
+	view := NewCompareView(&keys)
+	view.SetSize(120, 20)
+	view.Open("agent-app-abc123-sarah", "agent-app-abc123-emily")
+	view.Show()
+	view.SetDiffs(
+		CompareSide{SessionName: "agent-app-abc123-sarah", Patch: comparePatch("main.go", 20)},
+		CompareSide{SessionName: "agent-app-abc123-emily", Patch: comparePatch("main.go", 60)},
+	)
+
+	down := tea.KeyMsg{Type: tea.KeyDown}
+	for i := 0; i < 30; i++ {
+		view.Update(down)
+	}
+	// The shorter diff stops at its end while the longer one keeps scrolling
+	if view.panes[1].YOffset != 30 || !view.panes[0].AtBottom() {
+		t.Fatalf("Expected the longer pane at line 30 and the shorter at its end, got %d and %d", view.panes[1].YOffset, view.panes[0].YOffset)
+	}
+
+	view.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune{'g'}})
+	view.Update(down)
+	view.Update(down)
+	if view.panes[0].YOffset != 2 || view.panes[1].YOffset != 2 {
+		t.Errorf("Expected both panes at line 2, got %d and %d", view.panes[0].YOffset, view.panes[1].YOffset)
+	}
+
+	view.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune{'G'}})
+	if !view.panes[0].AtBottom() || !view.panes[1].AtBottom() {
+		t.Error("Expected G to jump both panes to the end")
+	}
+
+	view.Update(tea.KeyMsg{Type: tea.KeyEsc})
+	if view.Focused() {
+		t.Error("Expected Esc to close the compare view")
+	}
+}
+
+func TestStyleCompareDiff(t *testing.T) {
+	theme := DefaultTheme()
+	patch := "diff --git a/main.go b/main.go\ndiff --git a/util.go b/util.go\n+added\tline\n-removed"
+	lines := strings.Split(styleCompareDiff(patch, 12, map[string]bool{"main.go": true}, theme), "\n")
+	want := []string{
+		theme.Warning.Render("diff --git …"),
+		theme.Accent.Render("diff --git …"),
+		theme.Added.Render("+added    l…"),
+		theme.Removed.Render("-removed"),
+	}
+	if !reflect.DeepEqual(lines, want) {
+		t.Errorf("styleCompareDiff() = %q, want %q", lines, want)
+	}
+}
+
+func TestApp_CompareKey(t *testing.T) {
+	mock := &patchingUziMock{
+		patches: map[string]string{
+			"agent-app-abc123-sarah": comparePatch("main.go", 2),
+			"agent-app-abc123-emily": comparePatch("main.go", 1),
+		},
+		stats: map[string]*state.DiffStat{
+			"agent-app-abc123-sarah": {Files: []state.FileDiff{{Path: "main.go", Insertions: 2}}},
+			"agent-app-abc123-emily": {Files: []state.FileDiff{{Path: "main.go", Insertions: 1}}},
+		},
+	}
+	app := NewApp(mock)
+	defer app.Cleanup()
+	app.list.LoadSessions([]SessionInfo{
+		{Name: "agent-app-abc123-sarah", AgentName: "sarah", Status: "ready"},
+		{Name: "agent-app-abc123-emily", AgentName: "emily", Status: "ready"},
+		{Name: "agent-app-abc123-john", AgentName: "john", Status: "ready"},
+	})
+
+	compare := tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune{'C'}}
+	mark := tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune{'m'}}
+
+	app.Update(mark)
+	app.Update(compare)
+	if app.modals.Top() == app.compareView || !strings.Contains(app.notice, "1 agent(s) marked") {
+		t.Fatalf("Expected a notice with one agent marked, got %q", app.notice)
+	}
+
+	app.Update(tea.KeyMsg{Type: tea.KeyDown})
+	app.Update(mark)
+	if !app.list.IsMarked("agent-app-abc123-sarah") || !app.list.IsMarked("agent-app-abc123-emily") {
+		t.Fatal("Expected both selected sessions marked")
+	}
+	_, cmd := app.Update(compare)
+	if app.modals.Top() != app.compareView {
+		t.Fatal("Expected the compare view on top after 'C'")
+	}
+	msg, ok := cmd().(CompareMsg)
+	if !ok || msg.Left.SessionName != "agent-app-abc123-sarah" || msg.Right.SessionName != "agent-app-abc123-emily" {
+		t.Fatalf("Expected a CompareMsg for the marked sessions in mark order, got %+v", msg)
+	}
+	app.Update(msg)
+	if got := app.compareView.Overlap(); !reflect.DeepEqual(got, []string{"main.go"}) {
+		t.Errorf("Expected main.go to overlap, got %v", got)
+	}
+
+	// A third mark makes the comparison ambiguous again
+	app.Update(tea.KeyMsg{Type: tea.KeyEsc})
+	app.Update(tea.KeyMsg{Type: tea.KeyDown})
+	app.Update(mark)
+	if len(app.list.MarkedSessions()) != 3 {
+		t.Fatalf("Expected three marked sessions, got %+v", app.list.MarkedSessions())
+	}
+	if _, cmd := app.Update(compare); app.modals.Top() == app.compareView && cmd != nil {
+		t.Error("Expected no comparison of three agents")
+	}
+}
+
+func TestApp_CompareWithoutPatcher(t *testing.T) {
+	app := NewApp(&MockUziInterface{})
+	defer app.Cleanup()
+	side := app.compareSide("agent-app-abc123-sarah")
+	if !strings.Contains(side.Error, "not supported") {
+		t.Errorf("Expected an unsupported error, got %+v", side)
+	}
+}
+
+func TestUziCLI_GetSessionPatch(t *testing.T) {
+	setupUziTest()
+	cli := NewUziCLI()
+	cli.stateManager = &mockStateManagerForTest{statePath: createTempStateFile(t, map[string]state.AgentState{
+		"agent-app-abc123-sarah": {WorktreePath: t.TempDir()},
+	})}
+	cmdmock.SetResponseWithArgs("sh", []string{"-c", state.PatchScript}, comparePatch("main.go", 1), "", false)
+
+	patch, err := cli.GetSessionPatch("agent-app-abc123-sarah")
+	if err != nil {
+		t.Fatalf("GetSessionPatch() error = %v", err)
+	}
+	if patch != comparePatch("main.go", 1) {
+		t.Errorf("GetSessionPatch() = %q", patch)
+	}
+
+	if _, err := cli.GetSessionPatch("agent-app-abc123-emily"); err == nil {
+		t.Error("Expected an error for an unknown session")
+	}
+}
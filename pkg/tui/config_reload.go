@@ -0,0 +1,137 @@
+// Copyright (c) Subtrace, Inc.
+// SPDX-License-Identifier: BSD-3-Clause
+
+package tui
+
+import (
+	"fmt"
+	"os"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/nehpz/claudicus/pkg/config"
+)
+
+// noticeDuration is how long a status-line notice or error toast stays up
+const noticeDuration = 4 * time.Second
+
+// ConfigReloadedMsg is sent when uzi.yaml changed and the new file is valid
+type ConfigReloadedMsg struct {
+	Config *config.Config
+}
+
+// ConfigErrorMsg is sent when uzi.yaml changed but could not be loaded or validated
+type ConfigErrorMsg struct {
+	Err error
+}
+
+// noticeExpiredMsg clears the notice it was scheduled for
+type noticeExpiredMsg struct {
+	seq int
+}
+
+// configReceiver is implemented by UziInterface backends that cache uzi.yaml
+type configReceiver interface {
+	SetConfig(cfg *config.Config)
+}
+
+// WatchConfig loads the config at path and keeps it in sync with the file while the TUI
+// runs. Invalid edits leave the last good config in place and are reported in a toast;
+// a config that is already invalid at startup keeps its error on the status line.
+func (a *App) WatchConfig(path string) error {
+	cfg, err := config.LoadConfig(path)
+	if err == nil {
+		err = cfg.Validate()
+	}
+	if err == nil {
+		err = a.applyConfig(cfg)
+	}
+	if err != nil && !os.IsNotExist(err) {
+		a.setNotice("config error: "+err.Error(), true)
+	}
+
+	watcher, err := config.NewWatcher(path)
+	if err != nil {
+		return err
+	}
+	a.configWatcher = watcher
+	return nil
+}
+
+// waitForConfigChange blocks on the watcher and turns the next reload into a message
+func (a *App) waitForConfigChange() tea.Cmd {
+	if a.configWatcher == nil {
+		return nil
+	}
+	events := a.configWatcher.Events()
+	return func() tea.Msg {
+		event, ok := <-events
+		if !ok {
+			return nil
+		}
+		if event.Err != nil {
+			return ConfigErrorMsg{Err: event.Err}
+		}
+		return ConfigReloadedMsg{Config: event.Config}
+	}
+}
+
+// applyConfig makes cfg the active configuration for the running TUI, including
+// its key remappings. On error nothing is changed.
+func (a *App) applyConfig(cfg *config.Config) error {
+	keys := DefaultKeyMap()
+	if overrides := cfg.KeyOverrides(); len(overrides) > 0 {
+		remapped := make(map[string][]string, len(overrides))
+		for name, keyList := range overrides {
+			remapped[name] = keyList
+		}
+		var err error
+		if keys, err = keys.WithOverrides(remapped); err != nil {
+			return fmt.Errorf("tui.keys: %w", err)
+		}
+	}
+
+	a.config = cfg
+	a.keys = keys
+	a.list.SetNavigationKeys(keys)
+	a.list.SetColumns(cfg.TUIColumns())
+	a.checkpointModal.SetCheckpointConfig(cfg.Checkpoint)
+	agentType, count, _ := cfg.DefaultAgent()
+	a.agentForm.SetDefaults(agentType, count)
+	if a.activityMonitor != nil {
+		a.activityMonitor.SetDoneSentinel(cfg.DoneSentinel())
+		a.activityMonitor.SetStatusThresholds(cfg.ActiveThreshold(), cfg.StuckThreshold())
+	}
+	if receiver, ok := a.uzi.(configReceiver); ok {
+		receiver.SetConfig(cfg)
+	}
+	return nil
+}
+
+// setNotice puts a message on the status line until it is replaced
+func (a *App) setNotice(text string, isError bool) int {
+	a.noticeSeq++
+	a.notice = text
+	a.noticeIsError = isError
+	return a.noticeSeq
+}
+
+// showNotice puts a message on the status line and schedules its removal
+func (a *App) showNotice(text string, isError bool) tea.Cmd {
+	seq := a.setNotice(text, isError)
+	return tea.Tick(noticeDuration, func(time.Time) tea.Msg {
+		return noticeExpiredMsg{seq: seq}
+	})
+}
+
+// noticeView renders the current notice, or "" when there is none
+func (a *App) noticeView() string {
+	if a.notice == "" {
+		return ""
+	}
+	t := resolveTheme(a.theme)
+	if a.noticeIsError {
+		return t.Error.Render(t.Glyph("⚠ ", "Error: ") + a.notice)
+	}
+	return t.Accent.Render(a.notice)
+}
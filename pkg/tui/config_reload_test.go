@@ -0,0 +1,242 @@
+// Copyright (c) Subtrace, Inc.
+// SPDX-License-Identifier: BSD-3-Clause
+
+package tui
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/charmbracelet/bubbles/key"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/nehpz/claudicus/pkg/config"
+	"github.com/nehpz/claudicus/pkg/scrollback"
+)
+
+// configMockUzi records the config pushed by the TUI
+type configMockUzi struct {
+	MockUziInterface
+	received *config.Config
+}
+
+func (m *configMockUzi) SetConfig(cfg *config.Config) {
+	m.received = cfg
+}
+
+func TestApp_WatchConfigReloads(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "uzi.yaml")
+	os.WriteFile(path, []byte("devCommand: npm start\nportRange: 3000-3010\n"), 0644)
+
+	mock := &configMockUzi{}
+	app := NewApp(mock)
+	defer app.Cleanup()
+
+	if err := app.WatchConfig(path); err != nil {
+		t.Fatalf("WatchConfig() error = %v", err)
+	}
+	if app.config == nil || *app.config.DevCommand != "npm start" {
+		t.Fatalf("Expected initial config to be loaded, got %+v", app.config)
+	}
+	if mock.received != app.config {
+		t.Error("Expected initial config to be pushed to the backend")
+	}
+
+	wait := app.waitForConfigChange()
+	os.WriteFile(path, []byte("devCommand: yarn dev\nportRange: 4000-4010\n"), 0644)
+
+	done := make(chan tea.Msg, 1)
+	go func() { done <- wait() }()
+
+	var msg tea.Msg
+	select {
+	case msg = <-done:
+	case <-time.After(5 * time.Second):
+		t.Fatal("Timed out waiting for config reload")
+	}
+
+	reloaded, ok := msg.(ConfigReloadedMsg)
+	if !ok {
+		t.Fatalf("Expected ConfigReloadedMsg, got %T", msg)
+	}
+	_, cmd := app.Update(reloaded)
+	if cmd == nil {
+		t.Error("Expected commands to expire the notice and keep watching")
+	}
+	if *app.config.DevCommand != "yarn dev" || mock.received != app.config {
+		t.Errorf("Expected reloaded config to be applied, got %+v", app.config)
+	}
+	if app.notice != "config reloaded" || app.noticeIsError {
+		t.Errorf("Expected reload notice, got %q (error=%v)", app.notice, app.noticeIsError)
+	}
+}
+
+func TestApp_ConfigErrorKeepsLastGoodConfig(t *testing.T) {
+	app := NewApp(&MockUziInterface{})
+	defer app.Cleanup()
+	app.width, app.height = 80, 24
+
+	devCommand := "npm start"
+	good := &config.Config{DevCommand: &devCommand}
+	if err := app.applyConfig(good); err != nil {
+		t.Fatalf("applyConfig() error = %v", err)
+	}
+
+	app.Update(ConfigErrorMsg{Err: errors.New(`invalid portRange "5000"`)})
+	if app.config != good {
+		t.Error("Expected last good config to stay active")
+	}
+	if !app.noticeIsError || !strings.Contains(app.View(), `invalid portRange "5000"`) {
+		t.Errorf("Expected error toast in view, got notice %q", app.notice)
+	}
+}
+
+func TestApp_NoticeExpires(t *testing.T) {
+	app := NewApp(&MockUziInterface{})
+	defer app.Cleanup()
+
+	app.showNotice("first", false)
+	staleSeq := app.noticeSeq
+	app.showNotice("second", false)
+
+	// An expiry scheduled for an older notice must not clear the newer one
+	app.Update(noticeExpiredMsg{seq: staleSeq})
+	if app.notice != "second" {
+		t.Errorf("Expected newer notice to remain, got %q", app.notice)
+	}
+
+	app.Update(noticeExpiredMsg{seq: app.noticeSeq})
+	if app.notice != "" {
+		t.Errorf("Expected notice to clear, got %q", app.notice)
+	}
+}
+
+func TestApp_WaitForConfigChangeWithoutWatcher(t *testing.T) {
+	app := NewApp(&MockUziInterface{})
+	defer app.Cleanup()
+
+	if app.waitForConfigChange() != nil {
+		t.Error("Expected no command when config is not watched")
+	}
+}
+
+func TestUziCLI_SetConfig(t *testing.T) {
+	setupUziTest()
+	cli := NewUziCLI()
+
+	devCommand := "make dev"
+	cfg := &config.Config{DevCommand: &devCommand}
+	cli.SetConfig(cfg)
+
+	loaded, err := cli.loadDefaultConfig()
+	if err != nil || loaded != cfg {
+		t.Errorf("Expected hot-reloaded config from loadDefaultConfig, got %+v, %v", loaded, err)
+	}
+}
+
+func TestApp_ApplyConfigRemapsKeys(t *testing.T) {
+	app := NewApp(&MockUziInterface{})
+	defer app.Cleanup()
+
+	cfg := &config.Config{TUI: &config.TUIConfig{Keys: map[string]config.KeyList{
+		"help":      {"H", "?"},
+		"pipelines": {"ctrl+p"},
+		"palette":   {":"},
+	}}}
+	if err := app.applyConfig(cfg); err != nil {
+		t.Fatalf("applyConfig() error = %v", err)
+	}
+
+	app.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune{'H'}})
+	if app.modals.Top() != app.helpView {
+		t.Fatal("Expected remapped help key to open the help overlay")
+	}
+	if view := app.helpView.View(); !strings.Contains(view, "H/?") || !strings.Contains(view, "ctrl+p") {
+		t.Errorf("Expected help overlay to show remapped keys, got %q", view)
+	}
+
+	// The remapped help key closes the overlay too
+	app.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune{'H'}})
+	if app.modals.Active() {
+		t.Error("Expected help overlay to close")
+	}
+
+	app.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune{'p'}})
+	if app.modals.Active() {
+		t.Error("Expected old pipelines key to do nothing after remap")
+	}
+	app.Update(tea.KeyMsg{Type: tea.KeyCtrlP})
+	if app.modals.Top() != app.pipelineView {
+		t.Error("Expected remapped pipelines key to open the pipeline view")
+	}
+}
+
+func TestApp_ApplyConfigColumnsShowsCost(t *testing.T) {
+	home := t.TempDir()
+	t.Setenv("HOME", home)
+	transcripts := scrollback.TranscriptDir(home, "/worktrees/sarah")
+	os.MkdirAll(transcripts, 0755)
+	os.WriteFile(filepath.Join(transcripts, "a1.jsonl"), []byte(`{"costUSD":2.5,"message":{"id":"msg_1","usage":{"output_tokens":10}}}`+"\n"), 0644)
+
+	app := NewApp(&MockUziInterface{})
+	defer app.Cleanup()
+	if err := app.applyConfig(&config.Config{TUI: &config.TUIConfig{Columns: []string{"agent", "cost", "status"}}}); err != nil {
+		t.Fatalf("applyConfig() error = %v", err)
+	}
+	if !app.list.ShowsColumn(config.ColumnCost) || app.list.ShowsColumn(config.ColumnPrompt) {
+		t.Fatal("Expected the list to show the configured columns")
+	}
+
+	sessions := app.withCosts([]SessionInfo{
+		{Name: "agent-proj-abc123-sarah", WorktreePath: "/worktrees/sarah"},
+		{Name: "agent-proj-abc123-john", WorktreePath: "/worktrees/sarah", Host: "box"},
+	})
+	if sessions[0].CostUSD != 2.5 || sessions[1].CostUSD != 0 {
+		t.Errorf("Expected the cost of the local session only, got %+v", sessions)
+	}
+}
+
+func TestApp_ApplyConfigRejectsKeyConflicts(t *testing.T) {
+	app := NewApp(&MockUziInterface{})
+	defer app.Cleanup()
+
+	cfg := &config.Config{TUI: &config.TUIConfig{Keys: map[string]config.KeyList{
+		"kill": {"q"},
+	}}}
+	err := app.applyConfig(cfg)
+	if err == nil || !strings.Contains(err.Error(), "tui.keys") {
+		t.Fatalf("Expected tui.keys conflict error, got %v", err)
+	}
+	if app.config == cfg || !key.Matches(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune{'k'}}, app.keys.Kill) {
+		t.Error("Expected rejected config to leave the keymap unchanged")
+	}
+}
+
+func TestValidateConfig(t *testing.T) {
+	devCommand := "npm run dev -- --port $PORT"
+	empty, valid, invalid := "", "3000-3010", "3010-3000"
+	for _, tt := range []struct {
+		name    string
+		cfg     config.Config
+		wantErr string
+	}{
+		{"unset portRange picks ports automatically", config.Config{DevCommand: &devCommand}, ""},
+		{"empty portRange picks ports automatically", config.Config{DevCommand: &devCommand, PortRange: &empty}, ""},
+		{"valid portRange", config.Config{DevCommand: &devCommand, PortRange: &valid}, ""},
+		{"invalid portRange", config.Config{DevCommand: &devCommand, PortRange: &invalid}, "Invalid portRange"},
+		{"no devCommand", config.Config{PortRange: &valid}, "devCommand is empty"},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			err := validateConfig(&tt.cfg)
+			if tt.wantErr == "" && err != nil {
+				t.Errorf("validateConfig() error = %v, want none", err)
+			}
+			if tt.wantErr != "" && (err == nil || err.Error() != tt.wantErr) {
+				t.Errorf("validateConfig() error = %v, want %q", err, tt.wantErr)
+			}
+		})
+	}
+}
@@ -17,6 +17,12 @@ type ModalMsg struct {
 	SpawnReplacement bool
 }
 
+// KillCheckpointMsg is sent when the user chooses to checkpoint an agent
+// instead of killing it with pending work
+type KillCheckpointMsg struct {
+	AgentName string
+}
+
 type ConfirmationModal struct {
 	visible           bool
 	message           string
@@ -25,7 +31,9 @@ type ConfirmationModal struct {
 	mode              string // "kill" or "replace"
 	promptInput       textinput.Model
 	modelInput        textinput.Model
-	currentStep       int // 0: agent name, 1: prompt, 2: model
+	currentStep       int    // 0: agent name, 1: prompt, 2: model
+	killWarning       string // Pending work warning shown before step 0, if any
+	theme             *Theme
 }
 
 func NewConfirmationModal() *ConfirmationModal {
@@ -57,6 +65,7 @@ func NewConfirmationModal() *ConfirmationModal {
 		modelInput:  modelInput,
 		mode:        "replace", // Default to replace mode
 		currentStep: 0,
+		theme:       DefaultTheme(),
 	}
 }
 
@@ -67,6 +76,10 @@ func (m *ConfirmationModal) Update(msg tea.Msg) (*ConfirmationModal, tea.Cmd) {
 
 	switch msg := msg.(type) {
 	case tea.KeyMsg:
+		if m.killWarning != "" {
+			return m.handleKillWarning(msg)
+		}
+
 		switch msg.String() {
 		case "enter":
 			if m.mode == "replace" {
@@ -117,6 +130,31 @@ func (m *ConfirmationModal) Update(msg tea.Msg) (*ConfirmationModal, tea.Cmd) {
 	return m, nil
 }
 
+// handleKillWarning answers the pending work warning: checkpoint, kill anyway, or abort
+func (m *ConfirmationModal) handleKillWarning(msg tea.KeyMsg) (*ConfirmationModal, tea.Cmd) {
+	switch msg.String() {
+	case "c":
+		m.visible = false
+		agentName := m.requiredAgentName
+		m.reset()
+		return m, func() tea.Msg {
+			return KillCheckpointMsg{AgentName: agentName}
+		}
+
+	case "k":
+		// Continue to the usual agent name confirmation
+		m.killWarning = ""
+
+	case "a", "esc":
+		m.visible = false
+		m.reset()
+		return m, func() tea.Msg {
+			return ModalMsg{Confirmed: false}
+		}
+	}
+	return m, nil
+}
+
 func (m *ConfirmationModal) handleReplaceStep() (*ConfirmationModal, tea.Cmd) {
 	switch m.currentStep {
 	case 0:
@@ -168,6 +206,7 @@ func (m *ConfirmationModal) handleReplaceStep() (*ConfirmationModal, tea.Cmd) {
 
 func (m *ConfirmationModal) reset() {
 	m.currentStep = 0
+	m.killWarning = ""
 	m.textInput.SetValue("")
 	m.promptInput.SetValue("")
 	m.modelInput.SetValue("claude:1")
@@ -181,29 +220,37 @@ func (m *ConfirmationModal) View() string {
 		return ""
 	}
 
+	t := resolveTheme(m.theme)
+
 	// Create the modal content based on current step and mode
-	title := ClaudeSquadAccentStyle.Render("⚠️  " + m.message)
+	title := t.Accent.Render(t.Glyph("⚠️  ", "Warning: ") + m.message)
 	var content string
 
 	// Step indicator
 	stepIndicator := ""
 	if m.mode == "replace" {
-		stepIndicator = ClaudeSquadMutedStyle.Render(fmt.Sprintf("Step %d of 3", m.currentStep+1))
+		stepIndicator = t.Muted.Render(fmt.Sprintf("Step %d of 3", m.currentStep+1))
 	}
 
-	switch m.currentStep {
-	case 0:
+	switch {
+	case m.killWarning != "":
+		// Pending work warning before anything else
+		message := t.Primary.Render(m.killWarning)
+		choices := t.Muted.Render("[c]heckpoint / [k]ill anyway / [a]bort")
+		content = lipgloss.JoinVertical(lipgloss.Center, title, "", message, "", choices)
+
+	case m.currentStep == 0:
 		// Agent name confirmation step
-		message := ClaudeSquadPrimaryStyle.Render("Type agent name '" + m.requiredAgentName + "' to confirm:")
+		message := t.Primary.Render("Type agent name '" + m.requiredAgentName + "' to confirm:")
 		modeHint := ""
 		if m.mode == "kill" {
-			modeHint = ClaudeSquadMutedStyle.Render("[TAB] to switch to Kill & Replace mode")
+			modeHint = t.Muted.Render("[TAB] to switch to Kill & Replace mode")
 		} else {
-			modeHint = ClaudeSquadMutedStyle.Render("[TAB] to switch to Kill Only mode")
+			modeHint = t.Muted.Render("[TAB] to switch to Kill Only mode")
 		}
 
 		inputView := m.textInput.View()
-		escapeHint := ClaudeSquadMutedStyle.Render("(ESC to cancel)")
+		escapeHint := t.Muted.Render("(ESC to cancel)")
 
 		contentParts := []string{title}
 		if stepIndicator != "" {
@@ -212,30 +259,35 @@ func (m *ConfirmationModal) View() string {
 		contentParts = append(contentParts, "", message, "", inputView, "", modeHint, escapeHint)
 		content = lipgloss.JoinVertical(lipgloss.Center, contentParts...)
 
-	case 1:
+	case m.currentStep == 1:
 		// Prompt input step (replace mode only)
-		message := ClaudeSquadPrimaryStyle.Render("Enter prompt for replacement agent:")
+		message := t.Primary.Render("Enter prompt for replacement agent:")
 		inputView := m.promptInput.View()
-		hint := ClaudeSquadMutedStyle.Render("[ENTER] to continue | [ESC] to cancel")
+		hint := t.Muted.Render("[ENTER] to continue | [ESC] to cancel")
 
 		content = lipgloss.JoinVertical(lipgloss.Center, title, stepIndicator, "", message, "", inputView, "", hint)
 
-	case 2:
+	case m.currentStep == 2:
 		// Model input step (replace mode only)
-		message := ClaudeSquadPrimaryStyle.Render("Enter model for replacement agent:")
+		message := t.Primary.Render("Enter model for replacement agent:")
 		inputView := m.modelInput.View()
-		hint := ClaudeSquadMutedStyle.Render("[ENTER] to execute | [ESC] to cancel")
+		hint := t.Muted.Render("[ENTER] to execute | [ESC] to cancel")
 
 		content = lipgloss.JoinVertical(lipgloss.Center, title, stepIndicator, "", message, "", inputView, "", hint)
 	}
 
 	// Apply border with padding
-	return ClaudeSquadBorderStyle.Copy().
+	return t.Border.Copy().
 		Width(70).
 		Align(lipgloss.Center).
 		Render(content)
 }
 
+// SetTheme switches the style profile used to render the modal
+func (m *ConfirmationModal) SetTheme(theme *Theme) {
+	m.theme = theme
+}
+
 func (m *ConfirmationModal) SetVisible(v bool) {
 	m.visible = v
 }
@@ -248,3 +300,9 @@ func (m *ConfirmationModal) SetRequiredAgentName(agentName string) {
 	m.requiredAgentName = agentName
 	m.reset() // Reset state when setting new agent name
 }
+
+// SetKillWarning shows a pending work warning before the agent name
+// confirmation. Call it after SetRequiredAgentName, which clears it.
+func (m *ConfirmationModal) SetKillWarning(warning string) {
+	m.killWarning = warning
+}
@@ -0,0 +1,191 @@
+// Copyright (c) Subtrace, Inc.
+// SPDX-License-Identifier: BSD-3-Clause
+
+package tui
+
+import (
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/charmbracelet/bubbles/key"
+	"github.com/charmbracelet/bubbles/viewport"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+)
+
+// devLogLines is how many lines of the dev server window, scrollback
+// included, the log view captures
+const devLogLines = 2000
+
+// devLogRefresh is how often the open log view captures the window again
+const devLogRefresh = 2 * time.Second
+
+// DevLogMsg carries a capture of a session's dev server window
+type DevLogMsg struct {
+	SessionName string
+	Content     string
+	Error       string
+}
+
+// devLogTickMsg asks for the next capture of the open log view
+type devLogTickMsg struct {
+	SessionName string
+}
+
+var (
+	devLogErrorRe   = regexp.MustCompile(`(?i)\b(error|err!|fatal|panic|exception|failed|uncaught)\b|\s[45]\d\d\s`)
+	devLogWarningRe = regexp.MustCompile(`(?i)\b(warn|warning|deprecated)\b`)
+	devLogReadyRe   = regexp.MustCompile(`(?i)\b(ready|compiled|listening|started|local:|running at)\b`)
+	devLogRequestRe = regexp.MustCompile(`\b(GET|POST|PUT|PATCH|DELETE|HEAD|OPTIONS)\s+/`)
+)
+
+// DevLogView is an overlay that tails the uzi-dev tmux window of a session
+// in a scrollable viewport. It follows new output while scrolled to the
+// bottom, like tail -f, and stays put while scrolled up.
+type DevLogView struct {
+	visible     bool
+	sessionName string
+	loaded      bool
+	err         string
+	lines       int // Lines in the current capture, to tell when there is none
+	viewport    viewport.Model
+	keys        *KeyMap
+	theme       *Theme
+}
+
+// NewDevLogView creates a hidden dev server log view
+func NewDevLogView(keys *KeyMap) *DevLogView {
+	return &DevLogView{keys: keys, theme: DefaultTheme(), viewport: viewport.New(80, 15)}
+}
+
+// SetTheme switches the style profile used to render the view
+func (v *DevLogView) SetTheme(theme *Theme) {
+	v.theme = theme
+}
+
+// SetSize fits the viewport into the lower half of a terminal of the given size
+func (v *DevLogView) SetSize(width, height int) {
+	v.viewport.Width = max(20, min(width-4, 160))
+	v.viewport.Height = max(5, height/2-4)
+}
+
+// Open shows the view for a session; the log is filled in by SetLog once captured
+func (v *DevLogView) Open(sessionName string) {
+	v.sessionName = sessionName
+	v.loaded = false
+	v.err = ""
+	v.lines = 0
+	v.viewport.SetContent("")
+}
+
+// SessionName returns the session whose dev server is shown
+func (v *DevLogView) SessionName() string {
+	return v.sessionName
+}
+
+// Show opens the view
+func (v *DevLogView) Show() {
+	v.visible = true
+}
+
+// Hide closes the view
+func (v *DevLogView) Hide() {
+	v.visible = false
+}
+
+// Focused reports whether the view is open
+func (v *DevLogView) Focused() bool {
+	return v.visible
+}
+
+// SetLog replaces the displayed log with a new capture, keeping the scroll
+// position unless the view was following the end of the log
+func (v *DevLogView) SetLog(content, err string) {
+	following := !v.loaded || v.viewport.AtBottom()
+	v.loaded = true
+	v.err = err
+	if err != "" {
+		return
+	}
+	v.lines = len(strings.Split(content, "\n"))
+	if strings.TrimSpace(content) == "" {
+		v.lines = 0
+	}
+	v.viewport.SetContent(styleDevLog(content, resolveTheme(v.theme)))
+	if following {
+		v.viewport.GotoBottom()
+	}
+}
+
+// Update scrolls the log, jumps to either end with g and G, and closes the
+// view on Esc or the dev log key
+func (v *DevLogView) Update(msg tea.Msg) tea.Cmd {
+	keyMsg, ok := msg.(tea.KeyMsg)
+	if !ok {
+		return nil
+	}
+	switch {
+	case key.Matches(keyMsg, v.keys.Escape, v.keys.DevLog):
+		v.Hide()
+		return nil
+	case keyMsg.String() == "g" || keyMsg.String() == "home":
+		v.viewport.GotoTop()
+		return nil
+	case keyMsg.String() == "G" || keyMsg.String() == "end":
+		v.viewport.GotoBottom()
+		return nil
+	}
+	var cmd tea.Cmd
+	v.viewport, cmd = v.viewport.Update(keyMsg)
+	return cmd
+}
+
+// View renders the log in a bordered panel
+func (v *DevLogView) View() string {
+	if !v.visible {
+		return ""
+	}
+
+	t := resolveTheme(v.theme)
+	title := t.Accent.Render("Dev server: " + extractAgentName(v.sessionName))
+	var body string
+	switch {
+	case v.err != "":
+		body = t.Error.Render("Error: " + v.err)
+	case !v.loaded:
+		body = t.Muted.Render("Capturing the uzi-dev window...")
+	case v.lines == 0:
+		body = t.Muted.Render("The dev server has not printed anything yet.")
+	default:
+		body = v.viewport.View()
+	}
+
+	footer := "[↑/↓ pgup/pgdn] scroll  [g/G] top/bottom  [ESC] close"
+	if v.loaded && v.err == "" && !v.viewport.AtBottom() {
+		footer = "paused  " + footer
+	}
+
+	return t.Border.Copy().
+		Width(v.viewport.Width + 2).
+		Render(lipgloss.JoinVertical(lipgloss.Left, title, "", body, "", t.Muted.Render(footer)))
+}
+
+// styleDevLog highlights the lines of a dev server log: errors and failed
+// requests, warnings, ready and listening messages, and request lines
+func styleDevLog(content string, t *Theme) string {
+	lines := strings.Split(content, "\n")
+	for i, line := range lines {
+		switch {
+		case devLogErrorRe.MatchString(line):
+			lines[i] = t.Error.Render(line)
+		case devLogWarningRe.MatchString(line):
+			lines[i] = t.Warning.Render(line)
+		case devLogReadyRe.MatchString(line):
+			lines[i] = t.Added.Render(line)
+		case devLogRequestRe.MatchString(line):
+			lines[i] = t.Primary.Render(line)
+		}
+	}
+	return strings.Join(lines, "\n")
+}
@@ -0,0 +1,165 @@
+// Copyright (c) Subtrace, Inc.
+// SPDX-License-Identifier: BSD-3-Clause
+
+package tui
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/nehpz/claudicus/pkg/testutil/cmdmock"
+)
+
+func TestDevLogView_View(t *testing.T) {
+	keys := DefaultKeyMap()
+	view := NewDevLogView(&keys)
+	view.SetTheme(PlainTheme())
+	if view.View() != "" {
+		t.Error("Hidden dev log view should render nothing")
+	}
+
+	view.Open("agent-app-abc123-sarah")
+	view.Show()
+	output := view.View()
+	if !strings.Contains(output, "Dev server: sarah") || !strings.Contains(output, "Capturing") {
+		t.Errorf("Expected the capturing message, got %q", output)
+	}
+
+	view.SetLog("", "")
+	if !strings.Contains(view.View(), "has not printed anything") {
+		t.Errorf("Expected the empty message, got %q", view.View())
+	}
+
+	view.SetLog("> vite\n  ready in 300 ms", "")
+	if !strings.Contains(view.View(), "ready in 300 ms") {
+		t.Errorf("Expected the log in the view, got %q", view.View())
+	}
+
+	view.SetLog("", "no dev server window")
+	if !strings.Contains(view.View(), "no dev server window") {
+		t.Errorf("Expected the error in the view, got %q", view.View())
+	}
+}
+
+func TestDevLogView_FollowsEnd(t *testing.T) {
+	keys := DefaultKeyMap()
+	view := NewDevLogView(&keys)
+	view.SetSize(80, 20)
+	view.Open("agent-app-abc123-sarah")
+	view.Show()
+
+	logLines := func(n int) string {
+		lines := make([]string, n)
+		for i := range lines {
+			lines[i] = fmt.Sprintf("line %d", i+1)
+		}
+		return strings.Join(lines, "\n")
+	}
+
+	view.SetLog(logLines(50), "")
+	if !view.viewport.AtBottom() {
+		t.Fatal("Expected the first capture to start at the end of the log")
+	}
+	view.SetLog(logLines(60), "")
+	if !view.viewport.AtBottom() {
+		t.Error("Expected the view to follow new output at the end")
+	}
+
+	// Scrolling up pauses following until G
+	view.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune{'g'}})
+	view.SetLog(logLines(70), "")
+	if view.viewport.AtBottom() || !strings.Contains(view.View(), "paused") {
+		t.Error("Expected the scroll position kept while scrolled up")
+	}
+	view.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune{'G'}})
+	if !view.viewport.AtBottom() {
+		t.Error("Expected G to jump to the end")
+	}
+}
+
+func TestDevLogView_Close(t *testing.T) {
+	keys := DefaultKeyMap()
+	for _, msg := range []tea.KeyMsg{
+		{Type: tea.KeyEsc},
+		{Type: tea.KeyRunes, Runes: []rune{'L'}},
+	} {
+		view := NewDevLogView(&keys)
+		view.Show()
+		view.Update(msg)
+		if view.Focused() {
+			t.Errorf("Expected %q to close the dev log view", msg.String())
+		}
+	}
+}
+
+func TestStyleDevLog(t *testing.T) {
+	theme := DefaultTheme()
+	tests := map[string]string{
+		"error: Cannot find module './App'": theme.Error.Render("error: Cannot find module './App'"),
+		" GET /api/users 500 in 40ms":       theme.Error.Render(" GET /api/users 500 in 40ms"),
+		"warn - Fast Refresh had to reload": theme.Warning.Render("warn - Fast Refresh had to reload"),
+		"  ✓ Ready in 1.2s":                 theme.Added.Render("  ✓ Ready in 1.2s"),
+		" GET /dashboard 200 in 12ms":       theme.Primary.Render(" GET /dashboard 200 in 12ms"),
+		"  - Environments: .env.local":      "  - Environments: .env.local",
+	}
+	for line, want := range tests {
+		if got := styleDevLog(line, theme); got != want {
+			t.Errorf("styleDevLog(%q) = %q, want %q", line, got, want)
+		}
+	}
+}
+
+func TestApp_DevLogKey(t *testing.T) {
+	app := NewApp(&MockUziInterface{})
+	defer app.Cleanup()
+	app.list.LoadSessions([]SessionInfo{{Name: "agent-app-abc123-sarah", AgentName: "sarah", Status: "ready"}})
+
+	_, cmd := app.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune{'L'}})
+	if app.modals.Top() != app.devLogView {
+		t.Fatal("Expected the dev log view on top after 'L'")
+	}
+	msg, ok := cmd().(DevLogMsg)
+	if !ok || msg.SessionName != "agent-app-abc123-sarah" {
+		t.Fatalf("Expected a DevLogMsg for the selected session, got %+v", msg)
+	}
+
+	// Each capture schedules the next one while the view is open
+	_, cmd = app.Update(msg)
+	if !strings.Contains(app.devLogView.View(), "Ready in 1.2s") {
+		t.Errorf("Expected the captured log in the view, got %q", app.devLogView.View())
+	}
+	if cmd == nil {
+		t.Error("Expected the next capture to be scheduled")
+	}
+	_, cmd = app.Update(devLogTickMsg{SessionName: msg.SessionName})
+	if cmd == nil {
+		t.Error("Expected a tick to capture the log again")
+	}
+
+	app.Update(tea.KeyMsg{Type: tea.KeyEsc})
+	if _, cmd = app.Update(devLogTickMsg{SessionName: msg.SessionName}); cmd != nil {
+		t.Error("Expected no captures after the view is closed")
+	}
+}
+
+func TestUziCLI_GetDevLog(t *testing.T) {
+	setupUziTest()
+	cli := NewUziCLI()
+	args := []string{"capture-pane", "-p", "-J", "-t", "agent-app-abc123-sarah:uzi-dev", "-S", "-100"}
+	cmdmock.SetResponseWithArgs("tmux", args, "> next dev\n  ▲ Ready\n\n\n", "", false)
+
+	content, err := cli.GetDevLog("agent-app-abc123-sarah", 100)
+	if err != nil {
+		t.Fatalf("GetDevLog() error = %v", err)
+	}
+	if content != "> next dev\n  ▲ Ready" {
+		t.Errorf("Expected the capture without the trailing empty rows, got %q", content)
+	}
+
+	cmdmock.SetResponseWithArgs("tmux", args, "", "can't find window: uzi-dev", true)
+	if _, err := cli.GetDevLog("agent-app-abc123-sarah", 100); err == nil || !strings.Contains(err.Error(), "devCommand") {
+		t.Errorf("Expected a hint about devCommand, got %v", err)
+	}
+}
@@ -7,8 +7,10 @@ import (
 	"fmt"
 	"os/exec"
 	"strings"
+	"time"
 
 	"github.com/charmbracelet/lipgloss"
+	"github.com/nehpz/claudicus/pkg/state"
 )
 
 // DiffPreviewModel handles git diff display for agent sessions
@@ -16,10 +18,14 @@ type DiffPreviewModel struct {
 	content        string
 	commitMessages string
 	changedFiles   string
+	fileStat       *state.DiffStat // Per-file breakdown; changedFiles is shown without one
+	attachCommand  string          // Shell command that attaches to the session, shown under the title
 	error          string
+	loadedAt       time.Time // When the diff was last read, shown as "updated Xs ago"
 	width          int
 	height         int
 	showCommits    bool // Toggle to show commits and files or just diff
+	theme          *Theme
 }
 
 // NewDiffPreviewModel creates a new diff preview model
@@ -27,9 +33,15 @@ func NewDiffPreviewModel(width, height int) *DiffPreviewModel {
 	return &DiffPreviewModel{
 		width:  width,
 		height: height,
+		theme:  DefaultTheme(),
 	}
 }
 
+// SetTheme switches the style profile used to render the diff preview
+func (m *DiffPreviewModel) SetTheme(theme *Theme) {
+	m.theme = theme
+}
+
 // SetSize updates the dimensions of the diff preview
 func (m *DiffPreviewModel) SetSize(width, height int) {
 	m.width = width
@@ -42,12 +54,15 @@ func (m *DiffPreviewModel) LoadDiff(session *SessionInfo) {
 		m.content = ""
 		m.commitMessages = ""
 		m.changedFiles = ""
+		m.fileStat = nil
 		m.error = ""
+		m.loadedAt = time.Time{}
 		return
 	}
 
 	// Clear previous error
 	m.error = ""
+	m.loadedAt = time.Now()
 
 	// Get git diff for the session's worktree
 	diff, err := m.getGitDiff(session.WorktreePath)
@@ -79,14 +94,30 @@ func (m *DiffPreviewModel) LoadDiff(session *SessionInfo) {
 	m.content = diff
 }
 
+// LoadedAt returns when the diff was last loaded, or the zero time if none is
+func (m *DiffPreviewModel) LoadedAt() time.Time {
+	return m.loadedAt
+}
+
+// SetDiffStat sets the per-file breakdown shown in the commits and files
+// view; nil falls back to the git status listing
+func (m *DiffPreviewModel) SetDiffStat(stat *state.DiffStat) {
+	m.fileStat = stat
+}
+
+// SetAttachCommand sets the shell command shown for attaching to the
+// session, so it can be copied to another terminal; empty hides it
+func (m *DiffPreviewModel) SetAttachCommand(command string) {
+	m.attachCommand = command
+}
+
 // getGitDiff executes git diff command and returns the output
 func (m *DiffPreviewModel) getGitDiff(worktreePath string) (string, error) {
 	if worktreePath == "" {
 		return "No worktree path available", nil
 	}
 
-	// Stage all changes temporarily to show in diff
-	cmd := exec.Command("sh", "-c", "git add -A . && git diff --cached HEAD && git reset HEAD > /dev/null 2>&1")
+	cmd := exec.Command("sh", "-c", state.PatchScript)
 	cmd.Dir = worktreePath
 
 	output, err := cmd.Output()
@@ -159,8 +190,10 @@ func (m *DiffPreviewModel) View() string {
 		return ""
 	}
 
+	t := resolveTheme(m.theme)
+
 	// Create border style
-	borderStyle := ClaudeSquadBorderStyle.Copy().
+	borderStyle := t.Border.Copy().
 		Width(m.width - 2).
 		Height(m.height - 2)
 
@@ -169,18 +202,24 @@ func (m *DiffPreviewModel) View() string {
 	if m.showCommits {
 		title = "Commits & Files"
 	}
-	titleHeader := ClaudeSquadHeaderStyle.Render(title)
+	titleHeader := t.Header.Render(title)
+	if !m.loadedAt.IsZero() {
+		titleHeader += t.Muted.Render("  updated " + state.Ago(m.loadedAt.Format(time.RFC3339), time.Now()))
+	}
+	if m.attachCommand != "" {
+		titleHeader = lipgloss.JoinVertical(lipgloss.Left, titleHeader, t.Muted.Render("attach: "+m.attachCommand))
+	}
 
 	// Handle error case
 	if m.error != "" {
-		errorContent := ClaudeSquadMutedStyle.Render(m.error)
+		errorContent := t.Muted.Render(m.error)
 		content := lipgloss.JoinVertical(lipgloss.Left, titleHeader, errorContent)
 		return borderStyle.Render(content)
 	}
 
 	// Handle empty content
 	if m.content == "" && m.commitMessages == "" && m.changedFiles == "" {
-		emptyContent := ClaudeSquadMutedStyle.Render("Select an agent to view diff\nPress 'v' to toggle commits/files view")
+		emptyContent := t.Muted.Render("Select an agent to view diff\nPress 'v' to toggle commits/files view")
 		content := lipgloss.JoinVertical(lipgloss.Left, titleHeader, emptyContent)
 		return borderStyle.Render(content)
 	}
@@ -201,6 +240,7 @@ func (m *DiffPreviewModel) View() string {
 
 // formatDiffContent applies basic syntax highlighting to git diff output
 func (m *DiffPreviewModel) formatDiffContent(content string) string {
+	t := resolveTheme(m.theme)
 	lines := strings.Split(content, "\n")
 	var formatted []string
 
@@ -208,27 +248,30 @@ func (m *DiffPreviewModel) formatDiffContent(content string) string {
 		switch {
 		case strings.HasPrefix(line, "+++") || strings.HasPrefix(line, "---"):
 			// File headers
-			formatted = append(formatted, ClaudeSquadAccentStyle.Render(line))
+			formatted = append(formatted, t.Accent.Render(line))
 		case strings.HasPrefix(line, "@@"):
 			// Hunk headers
-			formatted = append(formatted, ClaudeSquadPrimaryStyle.Render(line))
+			formatted = append(formatted, t.Primary.Render(line))
 		case strings.HasPrefix(line, "+"):
 			// Additions
-			formatted = append(formatted, lipgloss.NewStyle().Foreground(lipgloss.Color("#00ff9d")).Render(line))
+			formatted = append(formatted, t.Added.Render(line))
 		case strings.HasPrefix(line, "-"):
 			// Deletions
-			formatted = append(formatted, lipgloss.NewStyle().Foreground(lipgloss.Color("#ff6b6b")).Render(line))
+			formatted = append(formatted, t.Removed.Render(line))
 		default:
 			// Context lines
-			formatted = append(formatted, ClaudeSquadMutedStyle.Render(line))
+			formatted = append(formatted, t.Muted.Render(line))
 		}
 	}
 
 	// Limit the number of lines to fit in the view
 	maxLines := m.height - 4 // Account for border and padding
+	if m.attachCommand != "" {
+		maxLines--
+	}
 	if len(formatted) > maxLines {
 		formatted = formatted[:maxLines]
-		formatted = append(formatted, ClaudeSquadMutedStyle.Render("... (truncated)"))
+		formatted = append(formatted, t.Muted.Render("... (truncated)"))
 	}
 
 	return strings.Join(formatted, "\n")
@@ -236,25 +279,28 @@ func (m *DiffPreviewModel) formatDiffContent(content string) string {
 
 // formatCommitsAndFiles formats commits and changed files for display
 func (m *DiffPreviewModel) formatCommitsAndFiles() string {
+	t := resolveTheme(m.theme)
 	var sections []string
 
 	// Format commit messages section
 	if m.commitMessages != "" {
-		commitHeader := ClaudeSquadAccentStyle.Render("Recent Commits:")
+		commitHeader := t.Accent.Render("Recent Commits:")
 		commitLines := strings.Split(m.commitMessages, "\n")
 		var formattedCommits []string
 		for _, line := range commitLines {
 			if strings.TrimSpace(line) != "" {
-				formattedCommits = append(formattedCommits, ClaudeSquadMutedStyle.Render("  "+line))
+				formattedCommits = append(formattedCommits, t.Muted.Render("  "+line))
 			}
 		}
 		commitSection := commitHeader + "\n" + strings.Join(formattedCommits, "\n")
 		sections = append(sections, commitSection)
 	}
 
-	// Format changed files section
-	if m.changedFiles != "" {
-		filesHeader := ClaudeSquadPrimaryStyle.Render("Changed Files:")
+	// Format changed files section, with lines changed per file when known
+	if m.fileStat != nil && len(m.fileStat.Files) > 0 {
+		sections = append(sections, formatFileStat(*m.fileStat, t))
+	} else if m.changedFiles != "" {
+		filesHeader := t.Primary.Render("Changed Files:")
 		fileLines := strings.Split(m.changedFiles, "\n")
 		var formattedFiles []string
 		for _, line := range fileLines {
@@ -263,21 +309,19 @@ func (m *DiffPreviewModel) formatCommitsAndFiles() string {
 				if len(line) >= 3 {
 					status := line[:2]
 					file := line[3:]
-					var statusColor lipgloss.Color
+					statusStyle := t.Muted // Gray for other
 					switch {
 					case strings.Contains(status, "A"):
-						statusColor = lipgloss.Color("#00ff9d") // Green for added
+						statusStyle = t.Added // Green for added
 					case strings.Contains(status, "M"):
-						statusColor = lipgloss.Color("#ffa500") // Orange for modified
+						statusStyle = t.Modified // Orange for modified
 					case strings.Contains(status, "D"):
-						statusColor = lipgloss.Color("#ff6b6b") // Red for deleted
-					default:
-						statusColor = lipgloss.Color("#888888") // Gray for other
+						statusStyle = t.Removed // Red for deleted
 					}
-					statusStyled := lipgloss.NewStyle().Foreground(statusColor).Render(status)
+					statusStyled := statusStyle.Render(status)
 					formattedFiles = append(formattedFiles, "  "+statusStyled+" "+file)
 				} else {
-					formattedFiles = append(formattedFiles, ClaudeSquadMutedStyle.Render("  "+line))
+					formattedFiles = append(formattedFiles, t.Muted.Render("  "+line))
 				}
 			}
 		}
@@ -287,7 +331,7 @@ func (m *DiffPreviewModel) formatCommitsAndFiles() string {
 
 	// Add helpful instructions at the bottom
 	if len(sections) > 0 {
-		instructions := ClaudeSquadMutedStyle.Render("\nPress 'v' to toggle back to diff view")
+		instructions := t.Muted.Render("\nPress 'v' to toggle back to diff view")
 		sections = append(sections, instructions)
 	}
 
@@ -299,9 +343,40 @@ func (m *DiffPreviewModel) formatCommitsAndFiles() string {
 	lines := strings.Split(result, "\n")
 	if len(lines) > maxLines {
 		lines = lines[:maxLines]
-		lines = append(lines, ClaudeSquadMutedStyle.Render("... (truncated)"))
+		lines = append(lines, t.Muted.Render("... (truncated)"))
 		result = strings.Join(lines, "\n")
 	}
 
 	return result
 }
+
+// fileStatBarWidth is the widest bar drawn for a file's changes
+const fileStatBarWidth = 20
+
+// formatFileStat lists each changed file with its change type, line counts,
+// and a bar scaled to the most changed file, so it is clear at a glance
+// where an agent is concentrating its changes
+func formatFileStat(stat state.DiffStat, t *Theme) string {
+	header := t.Primary.Render(fmt.Sprintf("Changed Files (%d, +%d -%d):", len(stat.Files), stat.Insertions(), stat.Deletions()))
+	lines := []string{header}
+	largest := stat.Largest()
+	for _, file := range stat.Files {
+		var change string
+		switch file.Change {
+		case state.ChangeAdded:
+			change = t.Added.Render("A")
+		case state.ChangeDeleted:
+			change = t.Removed.Render("D")
+		default:
+			change = t.Modified.Render("M")
+		}
+		counts := fmt.Sprintf("%5s %5s", fmt.Sprintf("+%d", file.Insertions), fmt.Sprintf("-%d", file.Deletions))
+		if file.Binary {
+			counts = fmt.Sprintf("%11s", "bin")
+		}
+		plus, minus := state.ScaleBar(file.Insertions, file.Deletions, largest, fileStatBarWidth)
+		bar := t.Added.Render(strings.Repeat("+", plus)) + t.Removed.Render(strings.Repeat("-", minus)) + strings.Repeat(" ", fileStatBarWidth-plus-minus)
+		lines = append(lines, fmt.Sprintf("  %s %s %s %s", change, t.Muted.Render(counts), bar, file.Path))
+	}
+	return strings.Join(lines, "\n")
+}
@@ -4,8 +4,13 @@
 package tui
 
 import (
+	"os"
+	"path/filepath"
 	"strings"
 	"testing"
+	"time"
+
+	"github.com/nehpz/claudicus/pkg/state"
 )
 
 func TestDiffPreviewModel_NewModel(t *testing.T) {
@@ -184,3 +189,128 @@ func TestDiffPreviewModel_ErrorHandling(t *testing.T) {
 		t.Error("Expected error message to be displayed")
 	}
 }
+
+func TestDiffPreviewModel_FileStat(t *testing.T) {
+	model := NewDiffPreviewModel(80, 24)
+	model.changedFiles = " M main.go"
+	model.SetDiffStat(&state.DiffStat{Files: []state.FileDiff{
+		{Path: "main.go", Change: state.ChangeModified, Insertions: 40, Deletions: 10},
+		{Path: "docs/guide.md", Change: state.ChangeAdded, Insertions: 5},
+		{Path: "logo.png", Change: state.ChangeModified, Binary: true},
+	}})
+
+	result := model.formatCommitsAndFiles()
+	for _, want := range []string{"Changed Files (3, +45 -10):", "+40", "-10", "main.go", "docs/guide.md", "bin", strings.Repeat("+", 16) + strings.Repeat("-", 4)} {
+		if !strings.Contains(result, want) {
+			t.Errorf("Expected %q in the file breakdown, got %q", want, result)
+		}
+	}
+
+	// Loading another session drops the breakdown until it is set again
+	model.LoadDiff(nil)
+	if model.fileStat != nil {
+		t.Error("Expected the breakdown cleared with the session")
+	}
+}
+
+func TestDiffPreviewModel_AttachCommand(t *testing.T) {
+	model := NewDiffPreviewModel(120, 24)
+	model.SetAttachCommand("ssh -t dev@box tmux attach-session -t agent-app-abc123-claude")
+	if view := model.View(); !strings.Contains(view, "attach: ssh -t dev@box tmux attach-session -t agent-app-abc123-claude") {
+		t.Errorf("Expected the attach command under the title, got:\n%s", view)
+	}
+
+	model.SetAttachCommand("")
+	if view := model.View(); strings.Contains(view, "attach:") {
+		t.Errorf("Expected no attach line without a command, got:\n%s", view)
+	}
+}
+
+func TestDiffPreviewModel_UpdatedAgo(t *testing.T) {
+	model := NewDiffPreviewModel(120, 24)
+	if view := model.View(); strings.Contains(view, "updated") {
+		t.Errorf("Expected no update time before a diff is loaded, got:\n%s", view)
+	}
+
+	model.LoadDiff(&SessionInfo{Name: "agent-app-abc123-claude", WorktreePath: t.TempDir()})
+	model.loadedAt = time.Now().Add(-5 * time.Second)
+	if view := model.View(); !strings.Contains(view, "updated 5s ago") {
+		t.Errorf("Expected the time since the diff was loaded, got:\n%s", view)
+	}
+
+	model.LoadDiff(nil)
+	if !model.LoadedAt().IsZero() {
+		t.Error("Expected the update time cleared with the session")
+	}
+}
+
+func TestApp_DiffWatchThrottle(t *testing.T) {
+	app := NewApp(&MockUziInterface{})
+	app.splitView = true
+	root := t.TempDir()
+	session := &SessionInfo{Name: "agent-app-abc123-claude", WorktreePath: root}
+
+	if cmd := app.loadDiffPreview(session); cmd == nil {
+		t.Fatal("Expected the worktree watched")
+	}
+	defer app.stopDiffWatch()
+	watcher, seq := app.diffWatcher, app.diffWatchSeq
+	if cmd := app.loadDiffPreview(session); cmd != nil || app.diffWatcher != watcher {
+		t.Error("Expected the watcher kept while the same session is shown")
+	}
+
+	// The worktree changing reports a DiffChangedMsg
+	wait := app.waitForDiffChange()
+	if err := os.WriteFile(filepath.Join(root, "main.go"), []byte("package main\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if msg, ok := wait().(DiffChangedMsg); !ok || msg.Seq != seq {
+		t.Fatalf("Expected a DiffChangedMsg for the watcher, got %#v", msg)
+	}
+
+	// A change right after a load is reloaded once the interval passes
+	if cmd := app.handleDiffChanged(seq); cmd == nil || !app.diffRefreshDue {
+		t.Error("Expected a reload scheduled for a change within the interval")
+	}
+	app.handleDiffRefresh(seq)
+	if app.diffRefreshDue {
+		t.Error("Expected the scheduled reload done")
+	}
+
+	// Messages of a replaced watcher are dropped
+	app.watchDiff(&SessionInfo{Name: "agent-app-abc123-codex", Host: "box", WorktreePath: root})
+	if app.diffWatcher != nil {
+		t.Error("Expected remote worktrees not watched")
+	}
+	if cmd := app.handleDiffChanged(seq); cmd != nil {
+		t.Error("Expected a change of the old watcher ignored")
+	}
+}
+
+// remoteStateUzi is a MockUziInterface whose sessions run on a remote host
+type remoteStateUzi struct {
+	MockUziInterface
+}
+
+func (m *remoteStateUzi) GetSessionState(sessionName string) (*state.AgentState, error) {
+	return &state.AgentState{Host: "box", SSH: "dev@box"}, nil
+}
+
+func TestApp_AttachCommandLine(t *testing.T) {
+	local := NewApp(&MockUziInterface{})
+	if got, want := local.attachCommandLine(&SessionInfo{Name: "agent-app-abc123-claude"}), "tmux attach-session -t agent-app-abc123-claude"; got != want {
+		t.Errorf("local attach line = %q, want %q", got, want)
+	}
+	// A remote session whose state cannot be read has no ssh destination to show
+	if got := local.attachCommandLine(&SessionInfo{Name: "agent-app-abc123-claude", Host: "box"}); got != "" {
+		t.Errorf("Expected no attach line without the remote's state, got %q", got)
+	}
+	if got := local.attachCommandLine(nil); got != "" {
+		t.Errorf("Expected no attach line without a session, got %q", got)
+	}
+
+	remote := NewApp(&remoteStateUzi{})
+	if got, want := remote.attachCommandLine(&SessionInfo{Name: "agent-app-abc123-claude", Host: "box"}), "ssh -t dev@box tmux attach-session -t agent-app-abc123-claude"; got != want {
+		t.Errorf("remote attach line = %q, want %q", got, want)
+	}
+}
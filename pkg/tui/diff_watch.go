@@ -0,0 +1,115 @@
+package tui
+
+import (
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/nehpz/claudicus/pkg/activity"
+)
+
+// diffRefreshInterval is the least time between two reloads of the split
+// view's diff, so an agent saving files in a burst doesn't rerun git on
+// every save
+var diffRefreshInterval = 2 * time.Second
+
+// DiffChangedMsg reports that the worktree of the session shown in the
+// split view changed. Seq tells messages of a replaced watcher apart.
+type DiffChangedMsg struct {
+	Seq int
+}
+
+// DiffRefreshMsg reloads the split view's diff once a throttled change is due
+type DiffRefreshMsg struct {
+	Seq int
+}
+
+// loadDiffPreview loads a session's diff into the split view and watches its
+// worktree so the diff is reloaded as the agent edits files
+func (a *App) loadDiffPreview(session *SessionInfo) tea.Cmd {
+	a.showDiff(session)
+	return a.watchDiff(session)
+}
+
+// watchDiff watches the worktree of the session shown in the split view,
+// replacing the watcher of the session shown before. Remote worktrees are
+// not watched; their diff is reloaded when they are selected again.
+func (a *App) watchDiff(session *SessionInfo) tea.Cmd {
+	if session != nil && session.Name == a.diffWatchSession && a.diffWatcher != nil {
+		return nil
+	}
+	a.stopDiffWatch()
+	if session == nil || session.Host != "" || session.WorktreePath == "" {
+		return nil
+	}
+	watcher, err := activity.NewFileWatcher(session.WorktreePath)
+	if err != nil {
+		return nil
+	}
+	a.diffWatcher = watcher
+	a.diffWatchSession = session.Name
+	return a.waitForDiffChange()
+}
+
+// stopDiffWatch stops watching the worktree shown in the split view
+func (a *App) stopDiffWatch() {
+	if a.diffWatcher != nil {
+		a.diffWatcher.Close()
+	}
+	a.diffWatcher = nil
+	a.diffWatchSession = ""
+	a.diffRefreshDue = false
+	a.diffWatchSeq++
+}
+
+// waitForDiffChange turns the next change of the watched worktree into a
+// DiffChangedMsg
+func (a *App) waitForDiffChange() tea.Cmd {
+	if a.diffWatcher == nil {
+		return nil
+	}
+	changes, seq := a.diffWatcher.Changes(), a.diffWatchSeq
+	return func() tea.Msg {
+		if _, ok := <-changes; !ok {
+			return nil
+		}
+		return DiffChangedMsg{Seq: seq}
+	}
+}
+
+// handleDiffChanged reloads the diff of a changed worktree, at most once per
+// diffRefreshInterval; a change within the interval is reloaded when it ends
+func (a *App) handleDiffChanged(seq int) tea.Cmd {
+	if seq != a.diffWatchSeq {
+		return nil
+	}
+	wait := a.waitForDiffChange()
+	if a.diffRefreshDue {
+		return wait
+	}
+	if remaining := diffRefreshInterval - time.Since(a.diffPreview.LoadedAt()); remaining > 0 {
+		a.diffRefreshDue = true
+		return tea.Batch(wait, tea.Tick(remaining, func(time.Time) tea.Msg { return DiffRefreshMsg{Seq: seq} }))
+	}
+	a.refreshDiff()
+	return wait
+}
+
+// handleDiffRefresh reloads the diff when a throttled change is due
+func (a *App) handleDiffRefresh(seq int) tea.Cmd {
+	if seq != a.diffWatchSeq {
+		return nil
+	}
+	a.diffRefreshDue = false
+	a.refreshDiff()
+	return nil
+}
+
+// refreshDiff reloads the diff of the watched session if it is still the
+// one selected in the split view
+func (a *App) refreshDiff() {
+	selected := a.list.SelectedSession()
+	if !a.splitView || selected == nil || selected.Name != a.diffWatchSession {
+		return
+	}
+	a.showDiff(selected)
+}
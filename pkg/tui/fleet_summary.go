@@ -0,0 +1,45 @@
+// Copyright (c) Subtrace, Inc.
+// SPDX-License-Identifier: BSD-3-Clause
+
+package tui
+
+import (
+	"github.com/nehpz/claudicus/pkg/fleet"
+)
+
+// attachmentReporter is implemented by UziInterface backends that can see tmux clients
+type attachmentReporter interface {
+	IsSessionAttached(sessionName string) bool
+}
+
+// summarizeFleet aggregates the refreshed sessions for the header line. Stuck agents
+// are classified the same way as the stuck filter so the two always agree.
+func (a *App) summarizeFleet(sessions []SessionInfo) fleet.Summary {
+	reporter, canReport := a.uzi.(attachmentReporter)
+
+	agents := make([]fleet.Agent, 0, len(sessions))
+	for _, session := range sessions {
+		status := session.Status
+		if NewSessionListItem(session).getActivityStatus() == "stuck" {
+			status = "stuck"
+		}
+		agents = append(agents, fleet.Agent{
+			Name:         session.Name,
+			Status:       status,
+			Insertions:   session.Insertions,
+			Deletions:    session.Deletions,
+			Port:         session.Port,
+			WorktreePath: session.WorktreePath,
+			Attached:     canReport && reporter.IsSessionAttached(session.Name),
+		})
+	}
+	return a.fleet.Summarize(agents)
+}
+
+// summaryView renders the fleet header, or "" before the first refresh
+func (a *App) summaryView() string {
+	if a.summary == nil {
+		return ""
+	}
+	return resolveTheme(a.theme).Muted.Render(a.summary.String())
+}
@@ -0,0 +1,98 @@
+// Copyright (c) Subtrace, Inc.
+// SPDX-License-Identifier: BSD-3-Clause
+
+package tui
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+// fleetMockUzi reports fixed sessions and which of them are attached
+type fleetMockUzi struct {
+	MockUziInterface
+	sessions []SessionInfo
+	attached map[string]bool
+}
+
+func (m *fleetMockUzi) GetSessions() ([]SessionInfo, error) {
+	return m.sessions, nil
+}
+
+func (m *fleetMockUzi) GetSessionsWithMetrics() ([]SessionInfo, error) {
+	return m.sessions, nil
+}
+
+func (m *fleetMockUzi) IsSessionAttached(sessionName string) bool {
+	return m.attached[sessionName]
+}
+
+func TestApp_SummarizeFleet(t *testing.T) {
+	recent := time.Now().Format(time.RFC3339)
+	stale := time.Now().Add(-10 * time.Minute).Format(time.RFC3339)
+	mock := &fleetMockUzi{
+		sessions: []SessionInfo{
+			{Name: "agent-p-1-sarah", Status: "running", Insertions: 10, Deletions: 2, Port: 3000, UpdatedAt: recent},
+			{Name: "agent-p-1-john", Status: "ready", Insertions: 3, Port: 3001, UpdatedAt: recent},
+			{Name: "agent-p-1-emily", Status: "ready", UpdatedAt: stale},
+		},
+		attached: map[string]bool{"agent-p-1-sarah": true},
+	}
+	app := NewApp(mock)
+	defer app.Cleanup()
+
+	summary := app.summarizeFleet(mock.sessions)
+	if summary.Agents != 3 || summary.Running != 1 || summary.Ready != 1 || summary.Stuck != 1 {
+		t.Errorf("Unexpected status counts: %+v", summary)
+	}
+	if summary.Insertions != 13 || summary.Deletions != 2 {
+		t.Errorf("Expected +13/-2 across the fleet, got +%d/-%d", summary.Insertions, summary.Deletions)
+	}
+	if summary.Attached != 1 {
+		t.Errorf("Expected 1 attached session, got %d", summary.Attached)
+	}
+	if len(summary.Ports) != 2 {
+		t.Errorf("Expected 2 ports in use, got %v", summary.Ports)
+	}
+}
+
+func TestApp_SummarizeFleetWithoutAttachmentInfo(t *testing.T) {
+	app := NewApp(&MockUziInterface{})
+	defer app.Cleanup()
+
+	sessions, _ := app.uzi.GetSessions()
+	if summary := app.summarizeFleet(sessions); summary.Attached != 0 || summary.Agents != 2 {
+		t.Errorf("Unexpected summary without attachment info: %+v", summary)
+	}
+}
+
+func TestApp_FleetHeader(t *testing.T) {
+	app := NewApp(&MockUziInterface{})
+	defer app.Cleanup()
+	app.width, app.height = 120, 24
+
+	if strings.Contains(app.View(), "agents (") {
+		t.Error("Expected no fleet header before the first refresh")
+	}
+
+	msg, ok := app.refreshSessions()().(RefreshMsg)
+	if !ok || msg.Summary == nil {
+		t.Fatalf("Expected RefreshMsg with a summary, got %+v", msg)
+	}
+	app.Update(msg)
+
+	for _, splitView := range []bool{false, true} {
+		app.splitView = splitView
+		view := app.View()
+		if !strings.Contains(view, "2 agents (1 running, 1 ready, 0 stuck)") {
+			t.Errorf("Expected fleet header (split=%v), got %q", splitView, view)
+		}
+	}
+
+	// A failed refresh keeps the previous header
+	app.Update(RefreshMsg{})
+	if app.summary == nil {
+		t.Error("Expected previous fleet summary to be kept")
+	}
+}
@@ -0,0 +1,213 @@
+// Copyright (c) Subtrace, Inc.
+// SPDX-License-Identifier: BSD-3-Clause
+
+package tui
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+
+	"github.com/charmbracelet/bubbles/key"
+	"github.com/charmbracelet/bubbles/textinput"
+	"github.com/charmbracelet/bubbles/viewport"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+	"github.com/nehpz/claudicus/pkg/scrollback"
+)
+
+// outputSearcher is implemented by UziInterface backends that can search
+// the pane history and transcripts of every session, as `uzi grep` does
+type outputSearcher interface {
+	SearchOutput(pattern string) ([]scrollback.Match, error)
+}
+
+// GrepMsg carries the results of an output search
+type GrepMsg struct {
+	Pattern string
+	Matches []scrollback.Match
+	Error   string
+}
+
+// GrepView is an overlay that searches what every agent printed for a
+// regular expression and lists the matching lines by agent, for finding
+// which agent ran into a particular error. Enter runs the search typed into
+// its input; the results scroll with the arrow keys.
+type GrepView struct {
+	visible  bool
+	input    textinput.Model
+	pattern  string // Pattern of the shown or pending results
+	loading  bool
+	loaded   bool
+	err      string
+	matches  []scrollback.Match
+	viewport viewport.Model
+	keys     *KeyMap
+	theme    *Theme
+	onSearch func(pattern string) tea.Cmd
+}
+
+// NewGrepView creates a hidden output search view that runs searches through
+// onSearch
+func NewGrepView(keys *KeyMap, onSearch func(pattern string) tea.Cmd) *GrepView {
+	ti := textinput.New()
+	ti.Placeholder = "regular expression, e.g. connection refused"
+	ti.CharLimit = 200
+	return &GrepView{keys: keys, theme: DefaultTheme(), input: ti, viewport: viewport.New(80, 15), onSearch: onSearch}
+}
+
+// SetTheme switches the style profile used to render the view
+func (v *GrepView) SetTheme(theme *Theme) {
+	v.theme = theme
+	v.render()
+}
+
+// SetSize fits the results into the lower half of a terminal of the given size
+func (v *GrepView) SetSize(width, height int) {
+	v.viewport.Width = max(20, min(width-4, 160))
+	v.viewport.Height = max(5, height/2-4)
+	v.input.Width = v.viewport.Width - 12
+	v.render()
+}
+
+// Show opens the view with the input focused, keeping the last results
+func (v *GrepView) Show() {
+	v.visible = true
+	v.input.Focus()
+}
+
+// Hide closes the view
+func (v *GrepView) Hide() {
+	v.visible = false
+	v.input.Blur()
+}
+
+// Focused reports whether the view is open
+func (v *GrepView) Focused() bool {
+	return v.visible
+}
+
+// Pattern returns the pattern of the shown or pending results
+func (v *GrepView) Pattern() string {
+	return v.pattern
+}
+
+// Matches returns the shown results
+func (v *GrepView) Matches() []scrollback.Match {
+	return v.matches
+}
+
+// Submit starts a search for the typed pattern and returns it, or returns
+// an empty string if the pattern is empty or invalid
+func (v *GrepView) Submit() string {
+	pattern := strings.TrimSpace(v.input.Value())
+	if pattern == "" {
+		return ""
+	}
+	if _, err := regexp.Compile("(?i)" + pattern); err != nil {
+		v.loaded = true
+		v.err = "invalid pattern: " + err.Error()
+		return ""
+	}
+	v.pattern = pattern
+	v.loading = true
+	v.err = ""
+	return pattern
+}
+
+// SetResults shows the matches of a search
+func (v *GrepView) SetResults(matches []scrollback.Match, err string) {
+	v.loading = false
+	v.loaded = true
+	v.err = err
+	v.matches = matches
+	v.render()
+	v.viewport.GotoTop()
+}
+
+// render lists the matches at the current width
+func (v *GrepView) render() {
+	t := resolveTheme(v.theme)
+	lines := make([]string, 0, len(v.matches))
+	previous := ""
+	for _, m := range v.matches {
+		if m.Session != previous {
+			if previous != "" {
+				lines = append(lines, "")
+			}
+			lines = append(lines, t.Primary.Render(m.Agent))
+			previous = m.Session
+		}
+		location := m.Location() + ": "
+		text := truncateLine(m.Text, max(10, v.viewport.Width-lipgloss.Width(location)-2))
+		lines = append(lines, "  "+t.Muted.Render(location)+text)
+	}
+	v.viewport.SetContent(strings.Join(lines, "\n"))
+}
+
+// Update runs the search on Enter, scrolls the results, and closes the view
+// on Esc; other keys edit the pattern
+func (v *GrepView) Update(msg tea.Msg) tea.Cmd {
+	keyMsg, ok := msg.(tea.KeyMsg)
+	if !ok {
+		return nil
+	}
+	switch {
+	case key.Matches(keyMsg, v.keys.Escape):
+		v.Hide()
+		return nil
+	case key.Matches(keyMsg, v.keys.Enter):
+		if pattern := v.Submit(); pattern != "" && v.onSearch != nil {
+			return v.onSearch(pattern)
+		}
+		return nil
+	case keyMsg.Type == tea.KeyUp, keyMsg.Type == tea.KeyDown, keyMsg.Type == tea.KeyPgUp, keyMsg.Type == tea.KeyPgDown:
+		var cmd tea.Cmd
+		v.viewport, cmd = v.viewport.Update(keyMsg)
+		return cmd
+	}
+	var cmd tea.Cmd
+	v.input, cmd = v.input.Update(keyMsg)
+	return cmd
+}
+
+// View renders the pattern input above the results in a bordered panel
+func (v *GrepView) View() string {
+	if !v.visible {
+		return ""
+	}
+
+	t := resolveTheme(v.theme)
+	title := t.Accent.Render("Search agent output")
+	prompt := t.Accent.Copy().Bold(true).Render("Pattern: ") + v.input.View()
+
+	var body string
+	switch {
+	case v.err != "":
+		body = t.Error.Render("Error: " + v.err)
+	case v.loading:
+		body = t.Muted.Render(fmt.Sprintf("Searching for %q...", v.pattern))
+	case !v.loaded:
+		body = t.Muted.Render("Searches the pane history and transcripts of every agent, ignoring case.")
+	case len(v.matches) == 0:
+		body = t.Muted.Render(fmt.Sprintf("No output matches %q", v.pattern))
+	default:
+		body = lipgloss.JoinVertical(lipgloss.Left,
+			t.Muted.Render(fmt.Sprintf("%d match(es) for %q in %d agent(s)", len(v.matches), v.pattern, countAgents(v.matches))),
+			v.viewport.View())
+	}
+
+	footer := "[enter] search  [↑/↓ pgup/pgdn] scroll  [ESC] close"
+	return t.Border.Copy().
+		Width(v.viewport.Width + 2).
+		Render(lipgloss.JoinVertical(lipgloss.Left, title, "", prompt, "", body, "", t.Muted.Render(footer)))
+}
+
+// countAgents counts the sessions with matches
+func countAgents(matches []scrollback.Match) int {
+	sessions := make(map[string]bool)
+	for _, m := range matches {
+		sessions[m.Session] = true
+	}
+	return len(sessions)
+}
@@ -0,0 +1,153 @@
+// Copyright (c) Subtrace, Inc.
+// SPDX-License-Identifier: BSD-3-Clause
+
+package tui
+
+import (
+	"strings"
+	"testing"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/nehpz/claudicus/pkg/scrollback"
+	"github.com/nehpz/claudicus/pkg/state"
+	"github.com/nehpz/claudicus/pkg/testutil/cmdmock"
+	"github.com/nehpz/claudicus/pkg/tmuxops"
+)
+
+// searchingUziMock serves fixed output search results
+type searchingUziMock struct {
+	MockUziInterface
+	matches  []scrollback.Match
+	patterns []string
+}
+
+func (m *searchingUziMock) SearchOutput(pattern string) ([]scrollback.Match, error) {
+	m.patterns = append(m.patterns, pattern)
+	return m.matches, nil
+}
+
+func typePattern(view *GrepView, pattern string) {
+	view.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune(pattern)})
+}
+
+func TestGrepView_View(t *testing.T) {
+	keys := DefaultKeyMap()
+	var searched []string
+	view := NewGrepView(&keys, func(pattern string) tea.Cmd {
+		searched = append(searched, pattern)
+		return nil
+	})
+	view.SetTheme(PlainTheme())
+	view.SetSize(120, 40)
+	if view.View() != "" {
+		t.Error("Hidden grep view should render nothing")
+	}
+
+	view.Show()
+	if output := view.View(); !strings.Contains(output, "Search agent output") || !strings.Contains(output, "ignoring case") {
+		t.Errorf("Expected the empty search view, got %q", output)
+	}
+
+	typePattern(view, "(")
+	view.Update(tea.KeyMsg{Type: tea.KeyEnter})
+	if len(searched) != 0 || !strings.Contains(view.View(), "invalid pattern") {
+		t.Errorf("Expected an invalid pattern to be refused, searched %v", searched)
+	}
+
+	view.Update(tea.KeyMsg{Type: tea.KeyBackspace})
+	typePattern(view, "refused")
+	view.Update(tea.KeyMsg{Type: tea.KeyEnter})
+	if len(searched) != 1 || searched[0] != "refused" || !strings.Contains(view.View(), `Searching for "refused"`) {
+		t.Fatalf("Expected a search for refused, searched %v", searched)
+	}
+
+	view.SetResults([]scrollback.Match{
+		{Session: "agent-app-abc123-emily", Agent: "emily", Source: scrollback.SourcePane, Line: 12, Text: "Error: connection refused"},
+		{Session: "agent-app-abc123-emily", Agent: "emily", Source: scrollback.SourceTranscript, File: "a1.jsonl", Line: 3, Text: "The connection was refused"},
+		{Session: "agent-app-abc123-sarah", Agent: "sarah", Source: scrollback.SourcePane, Line: 4, Text: "ECONNREFUSED: connection refused"},
+	}, "")
+	output := view.View()
+	for _, want := range []string{"3 match(es) for \"refused\" in 2 agent(s)", "emily", "pane:12: Error: connection refused", "transcript a1.jsonl:3: The connection was refused", "sarah"} {
+		if !strings.Contains(output, want) {
+			t.Errorf("Expected %q in the view, got %q", want, output)
+		}
+	}
+
+	view.SetResults(nil, "")
+	if output := view.View(); !strings.Contains(output, `No output matches "refused"`) {
+		t.Errorf("Expected the no matches message, got %q", output)
+	}
+
+	view.Update(tea.KeyMsg{Type: tea.KeyEsc})
+	if view.Focused() {
+		t.Error("Expected Esc to close the grep view")
+	}
+}
+
+func TestApp_GrepKey(t *testing.T) {
+	mock := &searchingUziMock{matches: []scrollback.Match{
+		{Session: "agent-app-abc123-sarah", Agent: "sarah", Source: scrollback.SourcePane, Line: 4, Text: "panic: nil map"},
+	}}
+	app := NewApp(mock)
+	defer app.Cleanup()
+
+	app.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune{'G'}})
+	if app.modals.Top() != app.grepView {
+		t.Fatal("Expected the grep view on top after 'G'")
+	}
+	app.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("panic")})
+	_, cmd := app.Update(tea.KeyMsg{Type: tea.KeyEnter})
+	if cmd == nil {
+		t.Fatal("Expected Enter to start a search")
+	}
+	msg, ok := cmd().(GrepMsg)
+	if !ok || msg.Pattern != "panic" || len(msg.Matches) != 1 {
+		t.Fatalf("Expected a GrepMsg for panic, got %+v", msg)
+	}
+
+	// Results of a replaced search are dropped
+	app.Update(GrepMsg{Pattern: "stale", Matches: mock.matches})
+	if len(app.grepView.Matches()) != 0 {
+		t.Error("Expected results of another pattern to be ignored")
+	}
+	app.Update(msg)
+	if len(app.grepView.Matches()) != 1 {
+		t.Errorf("Expected the search results in the view, got %+v", app.grepView.Matches())
+	}
+}
+
+func TestApp_GrepWithoutSearcher(t *testing.T) {
+	app := NewApp(&MockUziInterface{})
+	defer app.Cleanup()
+	msg := app.searchOutput("panic")().(GrepMsg)
+	if !strings.Contains(msg.Error, "not supported") {
+		t.Errorf("Expected an unsupported error, got %+v", msg)
+	}
+}
+
+func TestUziCLI_SearchOutput(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+	setupUziTest()
+	cli := NewUziCLI()
+	cli.stateManager = &mockStateManagerForTest{
+		statePath: createTempStateFile(t, map[string]state.AgentState{
+			"agent-app-abc123-sarah": {WorktreePath: t.TempDir()},
+			"agent-app-abc123-john":  {WorktreePath: t.TempDir()},
+		}),
+		activeSessions: []string{"agent-app-abc123-sarah", "agent-app-abc123-john"},
+	}
+	cmdmock.SetResponseWithArgs("tmux", tmuxops.ScrollbackArgs("agent-app-abc123-sarah", 0), "go test ./...\nError: Connection refused\n", "", false)
+	cmdmock.SetResponseWithArgs("tmux", tmuxops.ScrollbackArgs("agent-app-abc123-john", 0), "", "can't find session", true)
+
+	matches, err := cli.SearchOutput("connection refused")
+	if err != nil {
+		t.Fatalf("SearchOutput() error = %v", err)
+	}
+	if len(matches) != 1 || matches[0].Agent != "sarah" || matches[0].Line != 2 {
+		t.Errorf("SearchOutput() = %+v, want sarah's line 2", matches)
+	}
+
+	if _, err := cli.SearchOutput("("); err == nil {
+		t.Error("Expected an invalid pattern to fail")
+	}
+}
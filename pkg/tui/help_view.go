@@ -0,0 +1,68 @@
+// Copyright (c) Subtrace, Inc.
+// SPDX-License-Identifier: BSD-3-Clause
+
+package tui
+
+import (
+	"github.com/charmbracelet/bubbles/help"
+	"github.com/charmbracelet/bubbles/key"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+)
+
+// HelpView is an overlay listing every key binding. It renders from the App's
+// live KeyMap, so bindings remapped in uzi.yaml show their configured keys.
+type HelpView struct {
+	visible bool
+	keys    *KeyMap
+	help    help.Model
+	theme   *Theme
+}
+
+// NewHelpView creates a hidden help overlay for the given key map
+func NewHelpView(keys *KeyMap) *HelpView {
+	h := help.New()
+	h.ShowAll = true
+	return &HelpView{keys: keys, help: h, theme: DefaultTheme()}
+}
+
+// SetTheme switches the style profile used to render the overlay
+func (v *HelpView) SetTheme(theme *Theme) {
+	v.theme = theme
+}
+
+// Show opens the overlay
+func (v *HelpView) Show() { v.visible = true }
+
+// Hide closes the overlay
+func (v *HelpView) Hide() { v.visible = false }
+
+// Focused reports whether the overlay is open
+func (v *HelpView) Focused() bool { return v.visible }
+
+// Update closes the overlay on Esc or the help key
+func (v *HelpView) Update(msg tea.Msg) tea.Cmd {
+	if keyMsg, ok := msg.(tea.KeyMsg); ok {
+		if key.Matches(keyMsg, v.keys.Escape, v.keys.Help) {
+			v.Hide()
+		}
+	}
+	return nil
+}
+
+// View renders the full key binding table
+func (v *HelpView) View() string {
+	if !v.visible {
+		return ""
+	}
+
+	t := resolveTheme(v.theme)
+	content := lipgloss.JoinVertical(lipgloss.Left,
+		t.Accent.Render("Keyboard shortcuts"),
+		"",
+		v.help.FullHelpView(v.keys.FullHelp()),
+		"",
+		t.Muted.Render("Remap keys under tui.keys in uzi.yaml"),
+	)
+	return t.Border.Copy().Render(content)
+}
@@ -0,0 +1,224 @@
+// Copyright (c) Subtrace, Inc.
+// SPDX-License-Identifier: BSD-3-Clause
+
+package tui
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/charmbracelet/bubbles/key"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+	"github.com/nehpz/claudicus/pkg/jobs"
+	"github.com/nehpz/claudicus/pkg/spawnqueue"
+)
+
+// JobsChangedMsg is sent when background jobs were queued or changed status
+type JobsChangedMsg struct{}
+
+// checkpointStreamer is implemented by UziInterface backends that can report
+// a checkpoint's output while it runs, which the jobs view shows live
+type checkpointStreamer interface {
+	RunCheckpointStreaming(agentName, message string, paths []string, onLine func(string)) error
+}
+
+// jobOutputLines is how many of a job's latest output lines Enter shows
+const jobOutputLines = 10
+
+// JobsView is an overlay listing background checkpoints, kills, and spawns,
+// followed by the agents queued under maxConcurrentAgents. Running jobs show
+// their latest line of output; Enter on a job shows its recent output and
+// full error.
+type JobsView struct {
+	visible  bool
+	jobs     []jobs.Job
+	queued   []spawnqueue.Entry
+	cursor   int
+	expanded bool // Show the output and error of the job under the cursor
+	keys     *KeyMap
+	theme    *Theme
+	now      func() time.Time
+}
+
+// NewJobsView creates a hidden jobs view
+func NewJobsView(keys *KeyMap) *JobsView {
+	return &JobsView{keys: keys, theme: DefaultTheme(), now: time.Now}
+}
+
+// SetTheme switches the style profile used to render the view
+func (v *JobsView) SetTheme(theme *Theme) {
+	v.theme = theme
+}
+
+// Show opens the view
+func (v *JobsView) Show() {
+	v.visible = true
+	v.expanded = false
+}
+
+// Hide closes the view
+func (v *JobsView) Hide() {
+	v.visible = false
+}
+
+// Focused reports whether the view is open
+func (v *JobsView) Focused() bool {
+	return v.visible
+}
+
+// SetJobs replaces the displayed jobs, newest first, keeping the cursor on the same job
+func (v *JobsView) SetJobs(list []jobs.Job) {
+	selectedID := 0
+	if v.cursor < len(v.jobs) {
+		selectedID = v.jobs[v.cursor].ID
+	}
+	v.jobs = list
+	v.cursor = 0
+	for i, job := range list {
+		if job.ID == selectedID {
+			v.cursor = i
+			break
+		}
+	}
+}
+
+// SetQueued replaces the displayed queue of agents waiting for a slot, oldest first
+func (v *JobsView) SetQueued(entries []spawnqueue.Entry) {
+	v.queued = entries
+}
+
+// Update moves the cursor, toggles the error drill-down, and closes the view
+// on Esc or the jobs key
+func (v *JobsView) Update(msg tea.Msg) tea.Cmd {
+	keyMsg, ok := msg.(tea.KeyMsg)
+	if !ok {
+		return nil
+	}
+	switch {
+	case key.Matches(keyMsg, v.keys.Escape, v.keys.Jobs):
+		v.Hide()
+	case key.Matches(keyMsg, v.keys.Up):
+		if v.cursor > 0 {
+			v.cursor--
+			v.expanded = false
+		}
+	case key.Matches(keyMsg, v.keys.Down):
+		if v.cursor < len(v.jobs)-1 {
+			v.cursor++
+			v.expanded = false
+		}
+	case key.Matches(keyMsg, v.keys.Enter):
+		v.expanded = !v.expanded
+	}
+	return nil
+}
+
+// View renders one line per job with its status and duration
+func (v *JobsView) View() string {
+	if !v.visible {
+		return ""
+	}
+
+	t := resolveTheme(v.theme)
+	now := v.now()
+	lines := []string{t.Accent.Render("Jobs"), ""}
+	if len(v.jobs) == 0 && len(v.queued) == 0 {
+		lines = append(lines, t.Muted.Render("No background jobs yet. Checkpoints, kills, and spawns show up here."))
+	}
+	for i, job := range v.jobs {
+		cursor := "  "
+		if i == v.cursor {
+			cursor = t.Glyph("▶ ", "> ")
+		}
+		line := fmt.Sprintf("%s%s #%d %-10s %-20s %-9s %s", cursor, jobStatusIcon(job.Status, t), job.ID, job.Kind, job.Target, job.Status, formatJobDuration(job.Duration(now)))
+		switch {
+		case job.Status == jobs.StatusFailed:
+			lines = append(lines, t.Error.Render(line))
+		case job.Status == jobs.StatusRunning:
+			lines = append(lines, t.Primary.Render(line))
+		default:
+			lines = append(lines, t.Muted.Render(line))
+		}
+		if i == v.cursor && v.expanded {
+			output := job.Output
+			if len(output) > jobOutputLines {
+				output = output[len(output)-jobOutputLines:]
+			}
+			for _, outputLine := range output {
+				lines = append(lines, t.Muted.Copy().PaddingLeft(4).Render(truncateLine(outputLine, 62)))
+			}
+			if job.Error != "" {
+				lines = append(lines, t.Error.Copy().Width(66).PaddingLeft(4).Render(job.Error))
+			}
+		} else if job.Status == jobs.StatusRunning && len(job.Output) > 0 {
+			lines = append(lines, t.Muted.Copy().PaddingLeft(4).Render(truncateLine(job.Output[len(job.Output)-1], 62)))
+		}
+	}
+	if len(v.queued) > 0 {
+		lines = append(lines, "", t.Accent.Render(fmt.Sprintf("Queued spawns (%d)", len(v.queued))))
+		for _, entry := range v.queued {
+			line := fmt.Sprintf("  %s #%d %-10s %-30s %s", jobStatusIcon(jobs.StatusPending, t), entry.ID, entry.Command, truncateLine(entry.Prompt, 30), formatJobDuration(now.Sub(entry.QueuedAt)))
+			lines = append(lines, t.Muted.Render(line))
+		}
+	}
+	lines = append(lines, "", t.Muted.Render("[↑/↓] select  [Enter] show output  [ESC] close"))
+
+	return t.Border.Copy().
+		Width(70).
+		Render(lipgloss.JoinVertical(lipgloss.Left, lines...))
+}
+
+// jobsStatusLine summarizes unfinished jobs for the status line, or returns "" when idle
+func jobsStatusLine(list []jobs.Job) string {
+	running, pending := 0, 0
+	for _, job := range list {
+		switch job.Status {
+		case jobs.StatusRunning:
+			running++
+		case jobs.StatusPending:
+			pending++
+		}
+	}
+	if running == 0 && pending == 0 {
+		return ""
+	}
+	if pending == 0 {
+		return fmt.Sprintf("%d job(s) running", running)
+	}
+	return fmt.Sprintf("%d job(s) running, %d pending", running, pending)
+}
+
+// truncateLine shortens a line of output to width runes
+func truncateLine(line string, width int) string {
+	runes := []rune(line)
+	if len(runes) <= width {
+		return line
+	}
+	return string(runes[:width-1]) + "…"
+}
+
+// jobStatusIcon returns a compact marker for a job status
+func jobStatusIcon(status jobs.Status, t *Theme) string {
+	if t.Plain {
+		return "[" + string(status) + "]"
+	}
+	switch status {
+	case jobs.StatusCompleted:
+		return "✓"
+	case jobs.StatusRunning:
+		return "●"
+	case jobs.StatusFailed:
+		return "✗"
+	default:
+		return "○"
+	}
+}
+
+// formatJobDuration renders a duration with second precision
+func formatJobDuration(d time.Duration) string {
+	if d < time.Second {
+		return "<1s"
+	}
+	return d.Truncate(time.Second).String()
+}
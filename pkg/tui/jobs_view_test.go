@@ -0,0 +1,150 @@
+// Copyright (c) Subtrace, Inc.
+// SPDX-License-Identifier: BSD-3-Clause
+
+package tui
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/nehpz/claudicus/pkg/jobs"
+	"github.com/nehpz/claudicus/pkg/spawnqueue"
+)
+
+func TestJobsView_View(t *testing.T) {
+	keys := DefaultKeyMap()
+	view := NewJobsView(&keys)
+	if view.View() != "" {
+		t.Error("Hidden jobs view should render nothing")
+	}
+
+	view.Show()
+	if !strings.Contains(view.View(), "No background jobs") {
+		t.Errorf("Expected empty message, got %q", view.View())
+	}
+
+	start := time.Date(2025, 1, 1, 12, 0, 0, 0, time.UTC)
+	view.now = func() time.Time { return start.Add(time.Minute) }
+	view.SetJobs([]jobs.Job{
+		{ID: 2, Kind: jobs.KindKill, Target: "emily", Status: jobs.StatusFailed, Error: "tmux not running", StartedAt: start, FinishedAt: start.Add(3 * time.Second)},
+		{ID: 1, Kind: jobs.KindCheckpoint, Target: "sarah", Status: jobs.StatusRunning, StartedAt: start.Add(30 * time.Second)},
+	})
+
+	output := view.View()
+	for _, want := range []string{"#2", "kill", "emily", "failed", "3s", "#1", "checkpoint", "sarah", "running", "30s"} {
+		if !strings.Contains(output, want) {
+			t.Errorf("Expected %q in jobs view, got %q", want, output)
+		}
+	}
+	if strings.Contains(output, "tmux not running") {
+		t.Error("Error should only show after Enter")
+	}
+
+	view.Update(tea.KeyMsg{Type: tea.KeyEnter})
+	if !strings.Contains(view.View(), "tmux not running") {
+		t.Errorf("Expected error drill-down after Enter, got %q", view.View())
+	}
+}
+
+func TestJobsView_Output(t *testing.T) {
+	keys := DefaultKeyMap()
+	view := NewJobsView(&keys)
+	view.Show()
+	view.SetJobs([]jobs.Job{
+		{ID: 1, Kind: jobs.KindCheckpoint, Target: "sarah", Status: jobs.StatusRunning, Output: []string{"Rebasing onto main", "Running pre-commit hooks"}},
+	})
+
+	output := view.View()
+	if !strings.Contains(output, "Running pre-commit hooks") || strings.Contains(output, "Rebasing onto main") {
+		t.Errorf("Expected only the latest output line of a running job, got %q", output)
+	}
+
+	view.Update(tea.KeyMsg{Type: tea.KeyEnter})
+	if output := view.View(); !strings.Contains(output, "Rebasing onto main") {
+		t.Errorf("Expected the recent output after Enter, got %q", output)
+	}
+}
+
+func TestJobsView_SetJobsKeepsCursor(t *testing.T) {
+	keys := DefaultKeyMap()
+	view := NewJobsView(&keys)
+	view.Show()
+	view.SetJobs([]jobs.Job{{ID: 2}, {ID: 1}})
+	view.Update(tea.KeyMsg{Type: tea.KeyDown})
+
+	// A new job is added at the top; the cursor stays on job 1
+	view.SetJobs([]jobs.Job{{ID: 3}, {ID: 2}, {ID: 1}})
+	if view.cursor != 2 {
+		t.Errorf("Expected cursor to follow job 1, got %d", view.cursor)
+	}
+
+	// The selected job was pruned; the cursor falls back to the top
+	view.SetJobs([]jobs.Job{{ID: 3}})
+	if view.cursor != 0 {
+		t.Errorf("Expected cursor reset, got %d", view.cursor)
+	}
+}
+
+func TestJobsView_Close(t *testing.T) {
+	keys := DefaultKeyMap()
+	for _, msg := range []tea.KeyMsg{
+		{Type: tea.KeyEsc},
+		{Type: tea.KeyRunes, Runes: []rune{'J'}},
+	} {
+		view := NewJobsView(&keys)
+		view.Show()
+		view.Update(msg)
+		if view.Focused() {
+			t.Errorf("Expected %q to close the jobs view", msg.String())
+		}
+	}
+}
+
+func TestJobsStatusLine(t *testing.T) {
+	tests := []struct {
+		name string
+		list []jobs.Job
+		want string
+	}{
+		{"idle", []jobs.Job{{Status: jobs.StatusCompleted}}, ""},
+		{"running", []jobs.Job{{Status: jobs.StatusRunning}}, "1 job(s) running"},
+		{"pending", []jobs.Job{{Status: jobs.StatusRunning}, {Status: jobs.StatusPending}, {Status: jobs.StatusPending}}, "1 job(s) running, 2 pending"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := jobsStatusLine(tt.list); got != tt.want {
+				t.Errorf("jobsStatusLine() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestJobStatusIconPlain(t *testing.T) {
+	if got := jobStatusIcon(jobs.StatusFailed, PlainTheme()); got != "[failed]" {
+		t.Errorf("Expected plain status marker, got %q", got)
+	}
+}
+
+func TestJobsView_Queued(t *testing.T) {
+	keys := DefaultKeyMap()
+	view := NewJobsView(&keys)
+	view.Show()
+
+	start := time.Date(2025, 1, 1, 12, 0, 0, 0, time.UTC)
+	view.now = func() time.Time { return start.Add(2 * time.Minute) }
+	view.SetQueued([]spawnqueue.Entry{
+		{ID: 7, Command: "codex", Prompt: "write tests for the login flow", QueuedAt: start},
+	})
+
+	output := view.View()
+	if strings.Contains(output, "No background jobs") {
+		t.Error("Expected queued spawns instead of the empty message")
+	}
+	for _, want := range []string{"Queued spawns (1)", "#7", "codex", "write tests", "2m"} {
+		if !strings.Contains(output, want) {
+			t.Errorf("Expected %q in jobs view, got %q", want, output)
+		}
+	}
+}
@@ -4,6 +4,10 @@
 package tui
 
 import (
+	"fmt"
+	"sort"
+	"strings"
+
 	"github.com/charmbracelet/bubbles/key"
 	tea "github.com/charmbracelet/bubbletea"
 )
@@ -35,15 +39,29 @@ type KeyMap struct {
 	Quit    key.Binding
 	Refresh key.Binding
 	Kill    key.Binding
+	Palette key.Binding // Search and run any action
 	// List specific keys
 	Filter key.Binding
 	Clear  key.Binding
 	// Agent filtering keys
 	FilterStuck   key.Binding // Toggle stuck agents filter
 	FilterWorking key.Binding // Filter working agents
+	FilterTag     key.Binding // Cycle through the tags in use
+	SavePreset    key.Binding // Save the current filter, tag, and search as a preset
+	Presets       key.Binding // Apply a saved preset; the nth key applies the nth preset
+	Pin           key.Binding // Pin selected session to the top of the list
+	Mark          key.Binding // Mark selected session for multi-session actions
 
 	// Agent management keys
 	Checkpoint key.Binding // Create checkpoint for selected agent
+	Nudge      key.Binding // Send continue keystrokes to selected agent
+	Retry      key.Binding // Respawn a stuck or failed agent with an edited prompt
+	Pipelines  key.Binding // Show pipeline runs
+	Jobs       key.Binding // Show background jobs
+	DevLog     key.Binding // Tail the selected agent's dev server output
+	Compare    key.Binding // Compare the diffs of two marked agents side by side
+	Grep       key.Binding // Search the pane history and transcripts of every agent
+	Todos      key.Binding // List the outstanding items every agent mentioned
 	NewAgent   key.Binding // Create new agent interactively
 }
 
@@ -97,6 +115,38 @@ func DefaultKeyMap() KeyMap {
 			key.WithKeys("c"),
 			key.WithHelp("c", "checkpoint agent"),
 		),
+		Nudge: key.NewBinding(
+			key.WithKeys("u"),
+			key.WithHelp("u", "nudge agent"),
+		),
+		Retry: key.NewBinding(
+			key.WithKeys("e"),
+			key.WithHelp("e", "retry with edited prompt"),
+		),
+		Pipelines: key.NewBinding(
+			key.WithKeys("p"),
+			key.WithHelp("p", "pipelines"),
+		),
+		Jobs: key.NewBinding(
+			key.WithKeys("J"),
+			key.WithHelp("J", "background jobs"),
+		),
+		DevLog: key.NewBinding(
+			key.WithKeys("L"),
+			key.WithHelp("L", "dev server log"),
+		),
+		Compare: key.NewBinding(
+			key.WithKeys("C"),
+			key.WithHelp("C", "compare marked agents"),
+		),
+		Grep: key.NewBinding(
+			key.WithKeys("G"),
+			key.WithHelp("G", "search agent output"),
+		),
+		Todos: key.NewBinding(
+			key.WithKeys("T"),
+			key.WithHelp("T", "agent todos"),
+		),
 
 		// Diff preview
 		ToggleCommits: key.NewBinding(
@@ -121,15 +171,19 @@ func DefaultKeyMap() KeyMap {
 			key.WithKeys("k"),
 			key.WithHelp("k", "kill agent"),
 		),
+		Palette: key.NewBinding(
+			key.WithKeys("ctrl+p"),
+			key.WithHelp("ctrl+p", "command palette"),
+		),
 
 		// List specific
 		Filter: key.NewBinding(
 			key.WithKeys("/"),
-			key.WithHelp("/", "filter"),
+			key.WithHelp("/", "search"),
 		),
 		Clear: key.NewBinding(
 			key.WithKeys("x"),
-			key.WithHelp("x", "clear filter"),
+			key.WithHelp("x", "clear filter and search"),
 		),
 
 		// Agent filtering
@@ -141,6 +195,26 @@ func DefaultKeyMap() KeyMap {
 			key.WithKeys("w"),
 			key.WithHelp("w", "filter working agents"),
 		),
+		FilterTag: key.NewBinding(
+			key.WithKeys("t"),
+			key.WithHelp("t", "cycle tag filter"),
+		),
+		SavePreset: key.NewBinding(
+			key.WithKeys("F"),
+			key.WithHelp("F", "save filter preset"),
+		),
+		Presets: key.NewBinding(
+			key.WithKeys("1", "2", "3", "4", "5", "6", "7", "8", "9"),
+			key.WithHelp("1-9", "apply filter preset"),
+		),
+		Pin: key.NewBinding(
+			key.WithKeys("P"),
+			key.WithHelp("P", "pin session"),
+		),
+		Mark: key.NewBinding(
+			key.WithKeys("m"),
+			key.WithHelp("m", "mark session"),
+		),
 
 		// Agent creation
 		NewAgent: key.NewBinding(
@@ -158,12 +232,114 @@ func (k KeyMap) ShortHelp() []key.Binding {
 // FullHelp returns keybindings for the expanded help view
 func (k KeyMap) FullHelp() [][]key.Binding {
 	return [][]key.Binding{
-		{k.Up, k.Down, k.Left, k.Right},                                           // Navigation
-		{k.Enter, k.Escape, k.Refresh, k.Kill},                                    // Actions
-		{k.Tab, k.ToggleCommits, k.Config, k.Broadcast, k.Checkpoint, k.NewAgent}, // Views & Agent management
-		{k.Filter, k.Clear, k.FilterStuck, k.FilterWorking},                       // Filtering
-		{k.Help, k.Quit}, // Application
+		{k.Up, k.Down, k.Left, k.Right},        // Navigation
+		{k.Enter, k.Escape, k.Refresh, k.Kill}, // Actions
+		{k.Tab, k.ToggleCommits, k.Config, k.Broadcast, k.Checkpoint, k.Nudge, k.Retry, k.Pipelines, k.Jobs, k.DevLog, k.Compare, k.Grep, k.Todos, k.NewAgent}, // Views & Agent management
+		{k.Filter, k.Clear, k.FilterStuck, k.FilterWorking, k.FilterTag, k.SavePreset, k.Presets, k.Pin, k.Mark},                                               // Filtering
+		{k.Palette, k.Help, k.Quit}, // Application
+	}
+}
+
+// bindings maps the action names accepted under `tui.keys` in uzi.yaml to their bindings
+func (k *KeyMap) bindings() map[string]*key.Binding {
+	return map[string]*key.Binding{
+		"up":            &k.Up,
+		"down":          &k.Down,
+		"left":          &k.Left,
+		"right":         &k.Right,
+		"enter":         &k.Enter,
+		"escape":        &k.Escape,
+		"tab":           &k.Tab,
+		"config":        &k.Config,
+		"broadcast":     &k.Broadcast,
+		"toggleCommits": &k.ToggleCommits,
+		"help":          &k.Help,
+		"quit":          &k.Quit,
+		"refresh":       &k.Refresh,
+		"kill":          &k.Kill,
+		"palette":       &k.Palette,
+		"search":        &k.Filter,
+		"clear":         &k.Clear,
+		"filterStuck":   &k.FilterStuck,
+		"filterWorking": &k.FilterWorking,
+		"filterTag":     &k.FilterTag,
+		"savePreset":    &k.SavePreset,
+		"presets":       &k.Presets,
+		"pin":           &k.Pin,
+		"mark":          &k.Mark,
+		"checkpoint":    &k.Checkpoint,
+		"nudge":         &k.Nudge,
+		"retry":         &k.Retry,
+		"pipelines":     &k.Pipelines,
+		"jobs":          &k.Jobs,
+		"devLog":        &k.DevLog,
+		"compare":       &k.Compare,
+		"grep":          &k.Grep,
+		"todos":         &k.Todos,
+		"newAgent":      &k.NewAgent,
+	}
+}
+
+// WithOverrides returns a copy of the key map with the named actions rebound.
+// The help text follows the new keys. It fails on unknown action names, empty
+// key lists, and keys that end up bound to more than one action.
+func (k KeyMap) WithOverrides(overrides map[string][]string) (KeyMap, error) {
+	bindings := k.bindings()
+
+	names := make([]string, 0, len(overrides))
+	for name := range overrides {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	for _, name := range names {
+		binding, ok := bindings[name]
+		if !ok {
+			return k, fmt.Errorf("unknown TUI key action %q", name)
+		}
+		keys := overrides[name]
+		if len(keys) == 0 {
+			return k, fmt.Errorf("no keys given for TUI action %q", name)
+		}
+		binding.SetKeys(keys...)
+		binding.SetHelp(strings.Join(keys, "/"), binding.Help().Desc)
+	}
+
+	if err := k.checkConflicts(); err != nil {
+		return k, err
+	}
+	return k, nil
+}
+
+// checkConflicts reports keys that are bound to more than one action
+func (k *KeyMap) checkConflicts() error {
+	bindings := k.bindings()
+	names := make([]string, 0, len(bindings))
+	for name := range bindings {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	owners := make(map[string]string)
+	for _, name := range names {
+		for _, keyName := range bindings[name].Keys() {
+			if owner, taken := owners[keyName]; taken {
+				return fmt.Errorf("key %q is bound to both %s and %s", keyName, owner, name)
+			}
+			owners[keyName] = name
+		}
+	}
+	return nil
+}
+
+// PresetNumber returns which preset a Presets key applies, counting from 1
+func (k KeyMap) PresetNumber(msg tea.KeyMsg) int {
+	for i, keyName := range k.Presets.Keys() {
+		if msg.String() == keyName {
+			return i + 1
+		}
 	}
+	return 0
 }
 
 // CursorState represents the cursor position in a list
@@ -4,6 +4,7 @@
 package tui
 
 import (
+	"strings"
 	"testing"
 
 	"github.com/charmbracelet/bubbles/key"
@@ -342,3 +343,56 @@ func TestNavigationKeysOnly(t *testing.T) {
 		}
 	}
 }
+
+func TestKeyMapWithOverrides(t *testing.T) {
+	keys, err := DefaultKeyMap().WithOverrides(map[string][]string{
+		"kill":  {"x"},
+		"clear": {"ctrl+l"},
+		"down":  {"j", "ctrl+n"},
+	})
+	if err != nil {
+		t.Fatalf("WithOverrides() error = %v", err)
+	}
+
+	if !key.Matches(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune{'x'}}, keys.Kill) {
+		t.Error("Expected 'x' to trigger kill")
+	}
+	if key.Matches(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune{'k'}}, keys.Kill) {
+		t.Error("Expected 'k' to no longer trigger kill")
+	}
+	if !key.Matches(tea.KeyMsg{Type: tea.KeyCtrlN}, keys.Down) {
+		t.Error("Expected ctrl+n to move down")
+	}
+	if got := keys.Down.Help().Key; got != "j/ctrl+n" {
+		t.Errorf("Expected help key %q, got %q", "j/ctrl+n", got)
+	}
+	if got, want := keys.Kill.Help().Desc, DefaultKeyMap().Kill.Help().Desc; got != want {
+		t.Errorf("Expected kill help description %q to be kept, got %q", want, got)
+	}
+
+	// The default map must not be modified
+	if key.Matches(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune{'x'}}, DefaultKeyMap().Kill) {
+		t.Error("Expected DefaultKeyMap to be unchanged")
+	}
+}
+
+func TestKeyMapWithOverridesErrors(t *testing.T) {
+	tests := []struct {
+		name      string
+		overrides map[string][]string
+		want      string
+	}{
+		{"unknown action", map[string][]string{"explode": {"e"}}, `unknown TUI key action "explode"`},
+		{"empty key list", map[string][]string{"kill": {}}, `no keys given for TUI action "kill"`},
+		{"conflict with default", map[string][]string{"kill": {"j"}}, `key "j" is bound to both`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := DefaultKeyMap().WithOverrides(tt.overrides)
+			if err == nil || !strings.Contains(err.Error(), tt.want) {
+				t.Errorf("Expected error containing %q, got %v", tt.want, err)
+			}
+		})
+	}
+}
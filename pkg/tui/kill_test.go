@@ -4,18 +4,24 @@
 package tui
 
 import (
+	"context"
 	"errors"
+	"strings"
 	"testing"
 
 	"github.com/charmbracelet/bubbles/key"
 	tea "github.com/charmbracelet/bubbletea"
+	"github.com/nehpz/claudicus/pkg/pipeline"
 	"github.com/nehpz/claudicus/pkg/state"
 )
 
 // MockUziInterface for testing kill functionality
 type MockUziInterface struct {
 	killedSessions []string
+	nudgedAgents   []string
 	shouldFail     bool
+	pendingWork    state.PendingWork
+	respawned      map[string]string // new prompt by respawned session
 }
 
 func (m *MockUziInterface) GetSessions() ([]SessionInfo, error) {
@@ -25,6 +31,10 @@ func (m *MockUziInterface) GetSessions() ([]SessionInfo, error) {
 	}, nil
 }
 
+func (m *MockUziInterface) GetSessionsWithMetrics() ([]SessionInfo, error) {
+	return m.GetSessions()
+}
+
 func (m *MockUziInterface) GetSessionState(sessionName string) (*state.AgentState, error) {
 	return nil, nil // Not used in kill tests
 }
@@ -45,6 +55,10 @@ func (m *MockUziInterface) KillSession(sessionName string) error {
 	return nil
 }
 
+func (m *MockUziInterface) InspectKill(sessionName string) (state.PendingWork, error) {
+	return m.pendingWork, nil
+}
+
 func (m *MockUziInterface) RefreshSessions() error {
 	return nil
 }
@@ -65,17 +79,56 @@ func (m *MockUziInterface) RunCheckpoint(agentName string, message string) error
 	return nil // Mock implementation
 }
 
+func (m *MockUziInterface) RunPartialCheckpoint(agentName string, message string, paths []string) error {
+	return nil
+}
+
+func (m *MockUziInterface) GetChangedFiles(sessionName string) ([]string, error) {
+	return []string{}, nil
+}
+
+func (m *MockUziInterface) GetSessionDiffStat(sessionName string) (*state.DiffStat, error) {
+	return &state.DiffStat{}, nil
+}
+
+func (m *MockUziInterface) RunNudge(agentName string) error {
+	if m.shouldFail {
+		return errors.New("mock nudge failure")
+	}
+	m.nudgedAgents = append(m.nudgedAgents, agentName)
+	return nil
+}
+
+func (m *MockUziInterface) GetPipelineRuns() ([]pipeline.Run, error) {
+	return nil, nil
+}
+
+func (m *MockUziInterface) GetDevLog(sessionName string, lines int) (string, error) {
+	if m.shouldFail {
+		return "", errors.New("mock dev log failure")
+	}
+	return "> next dev\n  ▲ Ready in 1.2s\n GET / 200 in 12ms", nil
+}
+
 func (m *MockUziInterface) SpawnAgent(prompt, model string) (string, error) {
 	// Mock implementation - return a fake session name
 	return "agent-test-abc123-new-spawned", nil
 }
 
-func (m *MockUziInterface) SpawnAgentInteractive(opts string) (<-chan struct{}, error) {
-	// Mock implementation - return a channel that immediately signals completion
-	ch := make(chan struct{}, 1)
-	ch <- struct{}{}
-	close(ch)
-	return ch, nil
+func (m *MockUziInterface) RespawnWithPrompt(sessionName, newPrompt string) (string, error) {
+	if m.shouldFail {
+		return "", errors.New("mock respawn failure")
+	}
+	if m.respawned == nil {
+		m.respawned = map[string]string{}
+	}
+	m.respawned[sessionName] = newPrompt
+	return "agent-test-abc123-respawned", nil
+}
+
+func (m *MockUziInterface) SpawnAgentInteractive(ctx context.Context, opts string, progress chan<- SpawnEvent) error {
+	// Mock implementation - complete at once
+	return nil
 }
 
 func TestKillAgentHandling(t *testing.T) {
@@ -301,3 +354,74 @@ func TestKillAgentHandlingCancel(t *testing.T) {
 		t.Errorf("Expected 0 killed sessions after cancellation, got %d", len(mockUzi.killedSessions))
 	}
 }
+
+func TestKillAgentWarnsAboutPendingWork(t *testing.T) {
+	mockUzi := &MockUziInterface{pendingWork: state.PendingWork{UncommittedChanges: 42}}
+	app := NewApp(mockUzi)
+	app.Init()
+	app.list.LoadSessions([]SessionInfo{
+		{Name: "agent-repo-abc123-agent2", AgentName: "agent2", Status: "ready"},
+	})
+
+	killKeyMsg := tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune{'k'}}
+	app.Update(killKeyMsg)
+	view := app.confirmModal.View()
+	for _, want := range []string{"agent2 has 42 uncommitted changes — checkpoint first?", "[c]heckpoint / [k]ill anyway / [a]bort"} {
+		if !strings.Contains(view, want) {
+			t.Errorf("Expected %q in warning, got %q", want, view)
+		}
+	}
+
+	// [c]heckpoint opens the checkpoint modal on the agent instead of killing it
+	_, cmd := app.confirmModal.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune{'c'}})
+	if cmd == nil {
+		t.Fatal("Expected a command after choosing checkpoint")
+	}
+	msg, ok := cmd().(KillCheckpointMsg)
+	if !ok || msg.AgentName != "agent2" {
+		t.Fatalf("Expected KillCheckpointMsg for agent2, got %#v", msg)
+	}
+	app.Update(msg)
+	if !app.checkpointModal.IsVisible() {
+		t.Error("Expected the checkpoint modal to open")
+	}
+	if got := app.checkpointModal.agents[app.checkpointModal.selectedIdx].AgentName; got != "agent2" {
+		t.Errorf("Expected agent2 preselected for checkpoint, got %s", got)
+	}
+	if app.confirmModal.IsVisible() || len(mockUzi.killedSessions) != 0 {
+		t.Error("Expected no kill after choosing checkpoint")
+	}
+}
+
+func TestKillAgentWarningChoices(t *testing.T) {
+	modal := NewConfirmationModal()
+	modal.SetRequiredAgentName("agent1")
+	modal.SetKillWarning("agent1 has 1 unmerged commit — checkpoint first?")
+	modal.SetVisible(true)
+
+	// Typing is ignored while the warning is up
+	modal.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune{'x'}})
+	if modal.textInput.Value() != "" {
+		t.Errorf("Expected no text input during the warning, got %q", modal.textInput.Value())
+	}
+
+	// [k]ill anyway continues to the agent name confirmation
+	modal.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune{'k'}})
+	if !strings.Contains(modal.View(), "Type agent name 'agent1' to confirm") {
+		t.Errorf("Expected agent name confirmation after kill anyway, got %q", modal.View())
+	}
+
+	// [a]bort cancels the kill
+	modal.SetRequiredAgentName("agent1")
+	modal.SetKillWarning("agent1 has 1 unmerged commit — checkpoint first?")
+	_, cmd := modal.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune{'a'}})
+	if cmd == nil {
+		t.Fatal("Expected a command after abort")
+	}
+	if msg, ok := cmd().(ModalMsg); !ok || msg.Confirmed {
+		t.Errorf("Expected an unconfirmed ModalMsg, got %#v", msg)
+	}
+	if modal.IsVisible() {
+		t.Error("Expected the modal to close on abort")
+	}
+}
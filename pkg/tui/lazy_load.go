@@ -0,0 +1,94 @@
+package tui
+
+import (
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// enrichWorkers is how many sessions have their status and diff counts
+// loaded at the same time on startup
+const enrichWorkers = 4
+
+// lazySessionLister is implemented by UziInterface backends that can list
+// sessions before inspecting them, so the TUI shows a large fleet at once
+// and fills in each row as its git and tmux checks finish
+type lazySessionLister interface {
+	// ListSessions returns the sessions without their status and diff
+	// counts, each marked Enriching
+	ListSessions() ([]SessionInfo, error)
+	// EnrichSession fills in the status and diff counts of a listed session
+	EnrichSession(session SessionInfo) (SessionInfo, error)
+}
+
+// SessionsListedMsg delivers the sessions shown before they are enriched
+type SessionsListedMsg struct {
+	Sessions []SessionInfo
+}
+
+// SessionEnrichedMsg delivers one row whose status and diff counts are loaded
+type SessionEnrichedMsg struct {
+	Session SessionInfo
+}
+
+// loadSessions returns the command for the first load of the list. Backends
+// that can list lazily show the rows right away; others load them in one go.
+func (a *App) loadSessions() tea.Cmd {
+	lister, ok := a.uzi.(lazySessionLister)
+	if !ok {
+		return a.refreshSessions()
+	}
+	return func() tea.Msg {
+		sessions, err := lister.ListSessions()
+		if err != nil {
+			// Fall back to the full load, which reports nothing either way
+			return a.refreshSessions()()
+		}
+		return SessionsListedMsg{Sessions: sessions}
+	}
+}
+
+// showListedSessions puts the unenriched rows on screen and starts loading
+// each one's status and diff counts in the background
+func (a *App) showListedSessions(sessions []SessionInfo) tea.Cmd {
+	lister, ok := a.uzi.(lazySessionLister)
+	if !ok || !a.loading {
+		// A full refresh already landed; its rows are complete
+		return nil
+	}
+	a.list.LoadSessions(sessions)
+	if len(sessions) == 0 {
+		a.loading = false
+		return nil
+	}
+
+	a.enriching = len(sessions)
+	slots := make(chan struct{}, enrichWorkers)
+	cmds := make([]tea.Cmd, 0, len(sessions))
+	for _, session := range sessions {
+		session := session
+		cmds = append(cmds, func() tea.Msg {
+			slots <- struct{}{}
+			defer func() { <-slots }()
+			enriched, err := lister.EnrichSession(session)
+			if err != nil {
+				// Show the row as it is rather than loading forever
+				enriched = session
+				enriched.Enriching = false
+			}
+			return SessionEnrichedMsg{Session: enriched}
+		})
+	}
+	return tea.Batch(cmds...)
+}
+
+// applyEnrichedSession fills in one row once its checks finish. The first
+// full refresh waits until every row is in, so the two don't race.
+func (a *App) applyEnrichedSession(session SessionInfo) {
+	if a.enriching == 0 {
+		return
+	}
+	a.list.UpdateSession(session)
+	a.enriching--
+	if a.enriching == 0 {
+		a.loading = false
+	}
+}
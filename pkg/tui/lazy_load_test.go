@@ -0,0 +1,120 @@
+package tui
+
+import (
+	"strings"
+	"testing"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/nehpz/claudicus/pkg/state"
+	"github.com/nehpz/claudicus/pkg/testutil/cmdmock"
+	"github.com/nehpz/claudicus/pkg/tmuxops"
+)
+
+// lazyUziMock lists sessions before enriching them, as UziCLI does
+type lazyUziMock struct {
+	MockUziInterface
+}
+
+func (m *lazyUziMock) ListSessions() ([]SessionInfo, error) {
+	return []SessionInfo{
+		{Name: "agent-app-abc123-sarah", AgentName: "sarah", Enriching: true},
+		{Name: "agent-app-abc123-john", AgentName: "john", Enriching: true},
+	}, nil
+}
+
+func (m *lazyUziMock) EnrichSession(session SessionInfo) (SessionInfo, error) {
+	session.Status = "running"
+	session.Insertions, session.Deletions = 12, 3
+	session.Enriching = false
+	return session, nil
+}
+
+func TestApp_LazySessionLoad(t *testing.T) {
+	app := NewApp(&lazyUziMock{})
+	defer app.Cleanup()
+
+	listed, ok := app.loadSessions()().(SessionsListedMsg)
+	if !ok || len(listed.Sessions) != 2 {
+		t.Fatalf("Expected the sessions listed before enrichment, got %+v", listed)
+	}
+	_, cmd := app.Update(listed)
+	if app.enriching != 2 || !app.loading {
+		t.Errorf("Expected 2 rows waiting for enrichment, got %d (loading %v)", app.enriching, app.loading)
+	}
+	row := app.list.allSessions[0]
+	item := NewSessionListItem(row)
+	if !row.Enriching || !strings.Contains(item.Description(), "…") {
+		t.Errorf("Expected a placeholder while the row is enriched, got %q", item.Description())
+	}
+
+	// The ticker leaves the list alone until every row is in
+	_, tickCmd := app.Update(TickMsg{})
+	if batch, ok := tickCmd().(tea.BatchMsg); !ok || len(batch) != 1 {
+		t.Errorf("Expected only the next tick while rows are enriched, got %#v", tickCmd())
+	}
+
+	for _, enrich := range cmd().(tea.BatchMsg) {
+		app.Update(enrich())
+	}
+	if app.enriching != 0 || app.loading {
+		t.Errorf("Expected enrichment to finish, got %d pending (loading %v)", app.enriching, app.loading)
+	}
+	for _, session := range app.list.allSessions {
+		if session.Enriching || session.Status != "running" || session.Insertions != 12 {
+			t.Errorf("Expected %s to be enriched, got %+v", session.Name, session)
+		}
+	}
+
+	// A late row doesn't overwrite what a full refresh loaded
+	app.list.LoadSessions([]SessionInfo{{Name: "agent-app-abc123-sarah", AgentName: "sarah", Status: "ready"}})
+	app.Update(SessionEnrichedMsg{Session: SessionInfo{Name: "agent-app-abc123-sarah", Status: "running"}})
+	if got := app.list.allSessions[0].Status; got != "ready" {
+		t.Errorf("Expected the refreshed row to stay, got status %q", got)
+	}
+}
+
+func TestApp_LoadSessionsWithoutLazyLister(t *testing.T) {
+	app := NewApp(&MockUziInterface{})
+	defer app.Cleanup()
+
+	if _, ok := app.loadSessions()().(RefreshMsg); !ok {
+		t.Error("Expected a full load from a backend that can't list lazily")
+	}
+	if app.loading || len(app.list.allSessions) != 2 {
+		t.Errorf("Expected the sessions loaded in one go, got %+v", app.list.allSessions)
+	}
+}
+
+func TestUziCLI_ListAndEnrichSession(t *testing.T) {
+	setupUziTest()
+	session := "agent-app-abc123-sarah"
+	cli := NewUziCLI()
+	cli.stateManager = &mockStateManagerForTest{
+		statePath: createTempStateFile(t, map[string]state.AgentState{
+			session: {WorktreePath: "/tmp/test-worktree", Model: "claude", Prompt: "add a login page", Port: 3000},
+		}),
+		activeSessions: []string{session},
+	}
+
+	sessions, err := cli.ListSessions()
+	if err != nil || len(sessions) != 1 {
+		t.Fatalf("ListSessions() = %+v, %v", sessions, err)
+	}
+	listed := sessions[0]
+	if !listed.Enriching || listed.AgentName != "sarah" || listed.Prompt != "add a login page" || listed.Status != "" {
+		t.Errorf("Expected only the state fields, got %+v", listed)
+	}
+	if len(cmdmock.GetCalls()) != 0 {
+		t.Errorf("Expected no git or tmux commands while listing, got %+v", cmdmock.GetCalls())
+	}
+
+	cmdmock.SetResponseWithArgs("tmux", []string{"capture-pane", "-t", tmuxops.AgentTarget(session), "-p"}, "esc to interrupt", "", false)
+	cmdmock.SetResponseWithArgs("sh", []string{"-c", state.DiffScript}, " 2 files changed, 5 insertions(+), 1 deletion(-)\n", "", false)
+	enriched, err := cli.EnrichSession(listed)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if enriched.Enriching || enriched.Status != "running" || enriched.Insertions != 5 || enriched.Deletions != 1 {
+		t.Errorf("Expected status and diff counts filled in, got %+v", enriched)
+	}
+}
@@ -5,17 +5,55 @@ package tui
 
 import (
 	"fmt"
+	"reflect"
+	"sort"
 	"strings"
 	"time"
 
+	"github.com/charmbracelet/bubbles/key"
 	"github.com/charmbracelet/bubbles/list"
 	tea "github.com/charmbracelet/bubbletea"
 	"github.com/charmbracelet/lipgloss"
+	"github.com/nehpz/claudicus/pkg/config"
+	"github.com/nehpz/claudicus/pkg/state"
 )
 
 // SessionListItem represents a session in the TUI list with Claude Squad styling
 type SessionListItem struct {
 	session SessionInfo
+	match   searchMatch         // Highlighted characters from the active search
+	pinned  bool                // Pinned sessions stay at the top of the list
+	marked  bool                // Marked for a multi-session action such as compare
+	change  rowChange           // Set while the row is highlighted as added or removed
+	columns []config.ColumnSpec // Fields of the line below the title; nil shows config.DefaultColumns
+	theme   *Theme
+}
+
+// rowChange marks a list row that was recently added or removed
+type rowChange int
+
+const (
+	rowUnchanged rowChange = iota
+	rowAdded
+	rowRemoved
+)
+
+// rowChangeDuration is how long added rows stay highlighted and removed rows
+// stay visible after a refresh
+const rowChangeDuration = 4 * time.Second
+
+// recentChange records when a session appeared in or disappeared from the list
+type recentChange struct {
+	kind    rowChange
+	at      time.Time
+	session SessionInfo // Last known state of a removed session
+	index   int         // Row a removed session occupied
+}
+
+// searchMatch records the rune indexes of displayed fields that matched the search query
+type searchMatch struct {
+	agentName []int
+	prompt    []int
 }
 
 // NewSessionListItem creates a new session list item
@@ -27,54 +65,176 @@ func NewSessionListItem(session SessionInfo) SessionListItem {
 
 // Title implements list.Item interface for sessions
 func (s SessionListItem) Title() string {
-	// Get status icon and activity bar using Claude Squad styling
-	statusIcon := s.formatStatusIcon(s.session.Status)
-	activityBar := s.formatActivityBar()
+	t := resolveTheme(s.theme)
+	if s.change == rowRemoved {
+		// Removed rows linger briefly so the list doesn't jump under the cursor
+		style := t.Muted
+		if !t.Plain {
+			style = style.Copy().Strikethrough(true)
+		}
+		return style.Render(fmt.Sprintf("%s (%s)", s.session.AgentName, s.session.Model)) + " " + t.Muted.Render("removed")
+	}
+
+	agentName := highlightMatches(s.session.AgentName, s.match.agentName, t)
+	model := t.Accent.Render(fmt.Sprintf("(%s)", s.session.Model))
+	if s.session.Paused {
+		// Paused agents are grayed out until uzi resume
+		agentName = t.Muted.Render(s.session.AgentName)
+		model = t.Muted.Render(fmt.Sprintf("(%s)", s.session.Model))
+	}
+
+	if t.Plain {
+		// Format: agent-name (model) pinned marked new
+		title := agentName + " " + model
+		if s.pinned {
+			title += " pinned"
+		}
+		if s.marked {
+			title += " marked"
+		}
+		if s.change == rowAdded {
+			title += " new"
+		}
+		return title
+	}
 
 	// Format: [●] ▮▮▮ agent-name (model)
-	return fmt.Sprintf("%s %s %s %s",
+	statusIcon := s.formatStatusIcon(s.session.Status)
+	if s.session.Enriching {
+		statusIcon = t.Muted.Render("…") // Status still loading
+	}
+	title := fmt.Sprintf("%s %s %s %s",
 		statusIcon,
-		activityBar,
-		s.session.AgentName,
-		ClaudeSquadAccentStyle.Render(fmt.Sprintf("(%s)", s.session.Model)))
+		s.formatActivityBar(),
+		agentName,
+		model)
+	if s.pinned {
+		title += " " + t.Accent.Render("📌")
+	}
+	if s.marked {
+		title += " " + t.Accent.Render("✔ marked")
+	}
+	if s.change == rowAdded {
+		title += " " + t.Accent.Render("✚ new")
+	}
+	return title
 }
 
-// Description implements list.Item interface for sessions
+// Description implements list.Item interface for sessions. It shows the
+// row's columns, by default its status, diff stats, last activity, age, dev URL,
+// and prompt. Columns without a value are left out unless they have a width.
 func (s SessionListItem) Description() string {
-	// Build description with status, diff stats, dev URL, last activity, and prompt
-	var parts []string
-
-	// Status with Claude Squad colors
-	status := s.formatStatus(s.session.Status)
-	parts = append(parts, status)
-
-	// Git diff stats with Claude Squad green accent
-	if s.session.Insertions > 0 || s.session.Deletions > 0 {
-		diffStats := fmt.Sprintf("+%d/-%d", s.session.Insertions, s.session.Deletions)
-		parts = append(parts, ClaudeSquadAccentStyle.Render(diffStats))
+	t := resolveTheme(s.theme)
+	if s.change == rowRemoved {
+		return t.Muted.Render("session ended")
+	}
+	columns := s.columns
+	if columns == nil {
+		columns = config.DefaultColumns
 	}
 
-	// Last activity time with muted styling
-	if lastActivity := s.formatLastActivity(); lastActivity != "" {
-		parts = append(parts, ClaudeSquadMutedStyle.Render(lastActivity))
+	var parts []string
+	for _, spec := range columns {
+		cell := s.formatColumn(spec, t)
+		if spec.Width > 0 {
+			cell = fitCell(cell, spec.Width)
+		} else if cell == "" {
+			continue
+		}
+		parts = append(parts, cell)
 	}
+	return strings.Join(parts, t.Separator())
+}
+
+// formatColumn renders one column of the description, or "" when the
+// session has no value for it
+func (s SessionListItem) formatColumn(spec config.ColumnSpec, t *Theme) string {
+	// Rows of the first load show placeholders until their status and diff stats are in
+	placeholder := t.Muted.Render(t.Glyph("…", "..."))
 
-	// Dev server URL with Claude Squad accent
-	if s.session.Port > 0 {
-		devURL := fmt.Sprintf("localhost:%d", s.session.Port)
-		parts = append(parts, ClaudeSquadAccentStyle.Render(devURL))
+	switch spec.Column {
+	case config.ColumnStatus:
+		if s.session.Enriching {
+			return placeholder
+		}
+		status := s.formatStatus(s.session.Status)
+		// The plain theme spells out the activity shown by the bar in the default theme
+		if t.Plain {
+			status += t.Separator() + s.getActivityStatus()
+		}
+		return status
+	case config.ColumnHost:
+		// Remote host, so a mixed fleet shows where each agent runs
+		if s.session.Host != "" {
+			return t.Muted.Render("@" + s.session.Host)
+		}
+	case config.ColumnDiff:
+		if s.session.Enriching {
+			return placeholder
+		}
+		if s.session.Insertions > 0 || s.session.Deletions > 0 {
+			return t.Accent.Render(fmt.Sprintf("+%d/-%d", s.session.Insertions, s.session.Deletions))
+		}
+	case config.ColumnFiles:
+		if s.session.Enriching {
+			return placeholder
+		}
+		if n := s.session.FilesChanged; n == 1 {
+			return t.Muted.Render("1 file")
+		} else if n > 1 {
+			return t.Muted.Render(fmt.Sprintf("%d files", n))
+		}
+	case config.ColumnActivity:
+		if lastActivity := s.formatLastActivity(); lastActivity != "" {
+			return t.Muted.Render(lastActivity)
+		}
+	case config.ColumnAge:
+		// Session age, so long-running agents stand out
+		if age := s.formatAge(); age != "" {
+			return t.Muted.Render(age)
+		}
+	case config.ColumnRuntime:
+		// Remaining runtime budget, highlighted once it is nearly used up
+		return s.formatRemainingRuntime(time.Now())
+	case config.ColumnPort:
+		if s.session.Port > 0 {
+			return t.Accent.Render(fmt.Sprintf("localhost:%d", s.session.Port))
+		}
+	case config.ColumnPrompt:
+		width := spec.Width
+		if width == 0 {
+			width = config.DefaultPromptWidth
+		}
+		if prompt := truncateText(s.session.Prompt, width); prompt != "" {
+			return highlightPromptMatches(prompt, s.match.prompt, t)
+		}
+	case config.ColumnCost:
+		if s.session.CostUSD > 0 {
+			return t.Muted.Render(fmt.Sprintf("$%.2f", s.session.CostUSD))
+		}
 	}
+	return ""
+}
 
-	// Truncated prompt with muted styling
-	prompt := s.session.Prompt
-	if len(prompt) > 40 { // Reduced to make room for activity time
-		prompt = prompt[:37] + "..."
+// truncateText cuts text to width runes, ending in "..." when it was cut
+func truncateText(text string, width int) string {
+	runes := []rune(text)
+	if len(runes) <= width {
+		return text
 	}
-	if prompt != "" {
-		parts = append(parts, ClaudeSquadMutedStyle.Render(prompt))
+	if width <= 3 {
+		return string(runes[:width])
 	}
+	return string(runes[:width-3]) + "..."
+}
 
-	return strings.Join(parts, " │ ")
+// fitCell pads or truncates a rendered cell to exactly width columns, so
+// cells of the same column line up from row to row
+func fitCell(cell string, width int) string {
+	if lipgloss.Width(cell) > width {
+		cell = lipgloss.NewStyle().MaxWidth(width-1).Render(cell) + "…"
+	}
+	return cell + strings.Repeat(" ", max(width-lipgloss.Width(cell), 0))
 }
 
 // FilterValue implements list.Item interface for sessions
@@ -82,40 +242,103 @@ func (s SessionListItem) FilterValue() string {
 	return s.session.AgentName + " " + s.session.Model + " " + s.session.Prompt
 }
 
+// highlightPromptMatches renders the (possibly truncated) prompt with matches highlighted
+func highlightPromptMatches(prompt string, indexes []int, t *Theme) string {
+	visible := make([]int, 0, len(indexes))
+	runeCount := len([]rune(prompt))
+	for _, idx := range indexes {
+		if idx < runeCount {
+			visible = append(visible, idx)
+		}
+	}
+	if len(visible) == 0 {
+		return t.Muted.Render(prompt)
+	}
+	return lipgloss.StyleRunes(prompt, visible, t.SearchMatch, t.Muted)
+}
+
+// matchSearch reports whether the session matches the search query, and which
+// displayed characters to highlight. Session name, agent name, prompt, and tags are searched.
+func matchSearch(session SessionInfo, query string) (searchMatch, bool) {
+	var match searchMatch
+	if strings.TrimSpace(query) == "" {
+		return match, true
+	}
+
+	match.agentName = fuzzyMatch(query, session.AgentName)
+	match.prompt = fuzzyMatch(query, session.Prompt)
+	if match.agentName != nil || match.prompt != nil {
+		return match, true
+	}
+
+	if fuzzyMatch(query, session.Name) != nil {
+		return match, true
+	}
+	for _, tag := range session.Tags {
+		if fuzzyMatch(query, tag) != nil {
+			return match, true
+		}
+	}
+	return match, false
+}
+
 // formatStatusIcon returns a styled status icon using Claude Squad colors
 func (s SessionListItem) formatStatusIcon(status string) string {
+	t := resolveTheme(s.theme)
 	switch status {
 	case "attached":
-		return ClaudeSquadAccentStyle.Render("●") // Claude Squad green
+		return t.Accent.Render("●") // Claude Squad green
 	case "running":
-		return ClaudeSquadAccentStyle.Render("●") // Claude Squad green
+		return t.Accent.Render("●") // Claude Squad green
 	case "ready":
-		return ClaudeSquadAccentStyle.Render("○") // Claude Squad green outline
-	case "inactive":
-		return ClaudeSquadMutedStyle.Render("○") // Muted gray
+		return t.Accent.Render("○") // Claude Squad green outline
+	case "starting":
+		return t.Muted.Render("◌") // Agent CLI still loading
+	case "stuck":
+		return t.Error.Render("●") // Waiting without progress
+	case "inactive", "dead":
+		return t.Muted.Render("○") // Muted gray
+	case "paused":
+		return t.Muted.Render("⏸") // Stopped by uzi pause
+	case "done":
+		return t.Primary.Render("✓") // The agent signalled completion
 	default:
-		return ClaudeSquadMutedStyle.Render("?")
+		return t.Muted.Render("?")
 	}
 }
 
 // formatStatus returns a styled status string using Claude Squad colors
 func (s SessionListItem) formatStatus(status string) string {
+	t := resolveTheme(s.theme)
 	switch status {
 	case "attached":
-		return ClaudeSquadAccentStyle.Render("attached")
+		return t.Accent.Render("attached")
 	case "running":
-		return ClaudeSquadAccentStyle.Render("running")
+		return t.Accent.Render("running")
 	case "ready":
-		return ClaudeSquadPrimaryStyle.Render("ready")
+		return t.Primary.Render("ready")
 	case "inactive":
-		return ClaudeSquadMutedStyle.Render("inactive")
+		return t.Muted.Render("inactive")
+	case "stuck":
+		return t.Error.Render("stuck")
+	case "done":
+		return t.Primary.Render("✓ done")
 	default:
-		return ClaudeSquadMutedStyle.Render(status)
+		return t.Muted.Render(status)
 	}
 }
 
 // getActivityStatus determines activity status based on last update time and diff stats
 func (s SessionListItem) getActivityStatus() string {
+	// A paused agent is neither working nor stuck
+	if s.session.Paused {
+		return "paused"
+	}
+	// Neither is an agent that signalled its task is complete
+	if s.session.Done || s.session.Status == "done" {
+		return "done"
+	}
+
 	// Parse UpdatedAt timestamp, try multiple formats
 	lastUpdate, err := time.Parse(time.RFC3339, s.session.UpdatedAt)
 	if err != nil {
@@ -159,48 +382,78 @@ func (s SessionListItem) getActivityStatus() string {
 
 // formatActivityBar returns a colored activity bar (▮▮▯ style)
 func (s SessionListItem) formatActivityBar() string {
+	t := resolveTheme(s.theme)
 	activityStatus := s.getActivityStatus()
 
 	switch activityStatus {
 	case "working":
 		// Green activity bar - fully active
-		return ClaudeSquadAccentStyle.Render("▮▮▮")
+		return t.Accent.Render("▮▮▮")
 	case "idle":
 		// Yellow activity bar - some activity
-		return WarningStyle.Render("▮▮▯")
+		return t.Warning.Render("▮▮▯")
 	case "stuck":
 		// Red activity bar - no progress
-		return ErrorStyle.Render("▮▯▯")
+		return t.Error.Render("▮▯▯")
+	case "done":
+		// Completed - nothing left to do
+		return t.Primary.Render("▮▮▮")
 	default:
 		// Gray activity bar - unknown status
-		return ClaudeSquadMutedStyle.Render("▯▯▯")
+		return t.Muted.Render("▯▯▯")
 	}
 }
 
-// formatLastActivity returns a human-readable "time ago" string
+// formatLastActivity returns a human-readable "time ago" string for the last
+// update, falling back to the creation time
 func (s SessionListItem) formatLastActivity() string {
-	// Parse UpdatedAt timestamp
-	lastUpdate, err := time.Parse("2006-01-02T15:04:05Z", s.session.UpdatedAt)
+	if ago := state.Ago(s.session.UpdatedAt, time.Now()); ago != "" {
+		return ago
+	}
+	return state.Ago(s.session.CreatedAt, time.Now())
+}
+
+// formatAge returns how long ago the session was created, or an empty string
+// if that is unknown
+func (s SessionListItem) formatAge() string {
+	if ago := state.Ago(s.session.CreatedAt, time.Now()); ago != "" {
+		return "started " + ago
+	}
+	return ""
+}
+
+// formatRemainingRuntime returns the styled time left in the session's runtime
+// budget, or an empty string if the session has no budget
+func (s SessionListItem) formatRemainingRuntime(now time.Time) string {
+	if s.session.Deadline == "" {
+		return ""
+	}
+	deadline, err := time.Parse(time.RFC3339, s.session.Deadline)
 	if err != nil {
-		// Fallback to CreatedAt if UpdatedAt is invalid
-		if lastUpdate, err = time.Parse("2006-01-02T15:04:05Z", s.session.CreatedAt); err != nil {
-			return ""
-		}
+		return ""
 	}
 
-	// Calculate time since last activity
-	timeSince := time.Since(lastUpdate)
+	t := resolveTheme(s.theme)
+	icon := t.Glyph("⏱ ", "")
+	remaining := deadline.Sub(now)
+	if remaining <= 0 {
+		return t.Error.Render(icon + "over budget")
+	}
 
-	// Format as human-readable duration
-	if timeSince < time.Minute {
-		return fmt.Sprintf("%d s ago", int(timeSince.Seconds()))
-	} else if timeSince < time.Hour {
-		return fmt.Sprintf("%d m ago", int(timeSince.Minutes()))
-	} else if timeSince < 24*time.Hour {
-		return fmt.Sprintf("%d h ago", int(timeSince.Hours()))
+	var text string
+	if remaining < time.Hour {
+		text = fmt.Sprintf("%s%dm left", icon, int(remaining.Minutes()))
 	} else {
-		return fmt.Sprintf("%d d ago", int(timeSince.Hours()/24))
+		text = fmt.Sprintf("%s%dh%02dm left", icon, int(remaining.Hours()), int(remaining.Minutes())%60)
+	}
+
+	// Warn in the last 20% of the budget, matching `uzi auto`
+	if created, err := time.Parse(time.RFC3339, s.session.CreatedAt); err == nil {
+		if budget := deadline.Sub(created); budget > 0 && remaining*5 <= budget {
+			return t.Warning.Render(text)
+		}
 	}
+	return t.Muted.Render(text)
 }
 
 // getActivityStatusStyle returns the appropriate style for the given activity status
@@ -229,36 +482,49 @@ const (
 	FilterWorking
 )
 
+// filterTypeNames are the status names stored in filter presets
+var filterTypeNames = map[FilterType]string{
+	FilterStuck:   "stuck",
+	FilterWorking: "working",
+}
+
 // ListModel wraps the bubbles list component with Claude Squad styling
 type ListModel struct {
-	list         list.Model
-	width        int
-	height       int
-	allSessions  []SessionInfo // Store all sessions for filtering
-	filterType   FilterType    // Current filter type
-	stuckToggled bool          // Track if stuck filter is toggled on/off
+	list          list.Model
+	width         int
+	height        int
+	allSessions   []SessionInfo   // Store all sessions for filtering
+	filterType    FilterType      // Current filter type
+	stuckToggled  bool            // Track if stuck filter is toggled on/off
+	searchQuery   string          // Current fuzzy search query
+	tagFilter     string          // Only sessions with this tag are shown; empty shows all
+	pinned        map[string]bool // Session names shown first regardless of filter and search
+	marked        []string        // Marked session names, in the order they were marked
+	collapsedRuns map[string]bool // Runs whose sessions are hidden under their header
+	theme         *Theme
+	loaded        bool                    // Sessions were loaded at least once
+	changes       map[string]recentChange // Recently added or removed sessions by name
+	columns       []config.ColumnSpec     // Fields shown below each row's title; nil shows the defaults
+	now           func() time.Time
 }
 
 // NewListModel creates a new list model with Claude Squad styling
 func NewListModel(width, height int) ListModel {
-	// Create custom delegate for Claude Squad styling
-	delegate := list.NewDefaultDelegate()
-	delegate.Styles.SelectedTitle = ClaudeSquadSelectedStyle
-	delegate.Styles.SelectedDesc = ClaudeSquadSelectedDescStyle
-	delegate.Styles.NormalTitle = ClaudeSquadNormalTitleStyle
-	delegate.Styles.NormalDesc = ClaudeSquadNormalDescStyle
-	delegate.Styles.DimmedTitle = ClaudeSquadMutedStyle
-	delegate.Styles.DimmedDesc = ClaudeSquadMutedStyle
+	theme := DefaultTheme()
 
 	// Create list with custom delegate
-	l := list.New([]list.Item{}, delegate, width, height)
+	l := list.New([]list.Item{}, themedDelegate(theme), width, height)
 	l.Title = "Agent Sessions"
-	l.Styles.Title = ClaudeSquadHeaderStyle
-	l.Styles.TitleBar = ClaudeSquadHeaderBarStyle
+	l.Styles.Title = theme.Header
+	l.Styles.TitleBar = theme.HeaderBar
+
+	// Search is handled by the App's search input rather than the built-in filter
+	l.SetFilteringEnabled(false)
 
 	// Customize the empty state message
 	l.SetShowStatusBar(false)  // Hide the status bar to prevent double messages
 	l.SetShowPagination(false) // Hide pagination for cleaner look when few items
+	l.SetShowHelp(false)       // Key hints are shown in the App's status bar
 
 	return ListModel{
 		list:         l,
@@ -267,18 +533,122 @@ func NewListModel(width, height int) ListModel {
 		allSessions:  []SessionInfo{},
 		filterType:   FilterNone,
 		stuckToggled: false,
+		pinned:       map[string]bool{},
+		theme:        theme,
+		changes:      map[string]recentChange{},
+		now:          time.Now,
 	}
 }
 
-// LoadSessions loads session information and renders each row with agent name, status icon, diff stats, and dev URL
+// themedDelegate creates the list delegate that renders rows with the theme's styles
+func themedDelegate(theme *Theme) list.DefaultDelegate {
+	delegate := list.NewDefaultDelegate()
+	delegate.Styles.SelectedTitle = theme.Selected
+	delegate.Styles.SelectedDesc = theme.SelectedDesc
+	delegate.Styles.NormalTitle = theme.NormalTitle
+	delegate.Styles.NormalDesc = theme.NormalDesc
+	delegate.Styles.DimmedTitle = theme.Muted
+	delegate.Styles.DimmedDesc = theme.Muted
+	return delegate
+}
+
+// SetTheme switches the style profile used to render the list and its rows
+func (m *ListModel) SetTheme(theme *Theme) {
+	m.theme = theme
+	m.list.SetDelegate(themedDelegate(theme))
+	m.list.Styles.Title = theme.Header
+	m.list.Styles.TitleBar = theme.HeaderBar
+	m.applyFilter()
+}
+
+// SetColumns sets the fields shown below the title of each row, in order
+func (m *ListModel) SetColumns(columns []config.ColumnSpec) {
+	m.columns = columns
+	m.applyFilter()
+}
+
+// ShowsColumn reports whether the rows show column
+func (m *ListModel) ShowsColumn(column config.Column) bool {
+	columns := m.columns
+	if columns == nil {
+		columns = config.DefaultColumns
+	}
+	for _, spec := range columns {
+		if spec.Column == column {
+			return true
+		}
+	}
+	return false
+}
+
+// LoadSessions loads session information and renders each row with agent name, status icon, diff stats, and dev URL.
+// Rows are matched to the previous load by session name, so the cursor stays on
+// the same session; added sessions are highlighted and removed ones fade out
+// over rowChangeDuration.
 func (m *ListModel) LoadSessions(sessions []SessionInfo) {
+	if m.changes == nil {
+		m.changes = map[string]recentChange{}
+	}
+	now := time.Now()
+	if m.now != nil {
+		now = m.now()
+	}
+
+	// The first load is not a change, so nothing is highlighted on startup
+	if m.loaded {
+		current := make(map[string]bool, len(sessions))
+		for _, session := range sessions {
+			current[session.Name] = true
+		}
+		previous := make(map[string]bool, len(m.allSessions))
+		for _, session := range m.allSessions {
+			previous[session.Name] = true
+			if !current[session.Name] {
+				m.changes[session.Name] = recentChange{kind: rowRemoved, at: now, session: session, index: m.rowIndex(session.Name)}
+			}
+		}
+		for _, session := range sessions {
+			if !previous[session.Name] {
+				m.changes[session.Name] = recentChange{kind: rowAdded, at: now}
+			}
+		}
+	}
+	for name, change := range m.changes {
+		if now.Sub(change.at) >= rowChangeDuration {
+			delete(m.changes, name)
+		}
+	}
+
 	// Store all sessions for filtering
 	m.allSessions = sessions
+	m.loaded = true
 
 	// Apply current filter and update list
 	m.applyFilter()
 }
 
+// UpdateSession replaces the row of a session that is still being enriched
+// with its loaded version, keeping the cursor, filter, and search as they are
+func (m *ListModel) UpdateSession(session SessionInfo) {
+	for i, existing := range m.allSessions {
+		if existing.Name == session.Name && existing.Enriching {
+			m.allSessions[i] = session
+			m.applyFilter()
+			return
+		}
+	}
+}
+
+// rowIndex returns the row showing the named session, or -1
+func (m *ListModel) rowIndex(sessionName string) int {
+	for i, item := range m.list.Items() {
+		if sessionItem, ok := item.(SessionListItem); ok && sessionItem.session.Name == sessionName {
+			return i
+		}
+	}
+	return -1
+}
+
 // SetSize updates the dimensions of the list
 func (m *ListModel) SetSize(width, height int) {
 	m.width = width
@@ -289,7 +659,7 @@ func (m *ListModel) SetSize(width, height int) {
 // SelectedSession returns the currently selected session, if any
 func (m ListModel) SelectedSession() *SessionInfo {
 	if item := m.list.SelectedItem(); item != nil {
-		if sessionItem, ok := item.(SessionListItem); ok {
+		if sessionItem, ok := item.(SessionListItem); ok && sessionItem.change != rowRemoved {
 			return &sessionItem.session
 		}
 	}
@@ -327,10 +697,12 @@ func (m ListModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 
 // View implements tea.Model interface
 func (m ListModel) View() string {
+	t := resolveTheme(m.theme)
+
 	// Check if list is empty and provide custom empty state
 	if len(m.list.Items()) == 0 {
-		emptyMessage := ClaudeSquadMutedStyle.Render("No active agent sessions")
-		headerView := ClaudeSquadHeaderStyle.Render("Agent Sessions")
+		emptyMessage := t.Muted.Render("No active agent sessions")
+		headerView := t.Header.Render("Agent Sessions")
 
 		// Calculate padding to center the message
 		maxWidth := m.width - 4 // Account for border padding
@@ -344,10 +716,10 @@ func (m ListModel) View() string {
 			strings.Repeat(" ", padding),
 			emptyMessage)
 
-		return ClaudeSquadBorderStyle.Render(emptyView)
+		return t.Border.Render(emptyView)
 	}
 
-	return ClaudeSquadBorderStyle.Render(m.list.View())
+	return t.Border.Render(m.list.View())
 }
 
 // ToggleStuckFilter toggles the stuck agents filter on/off
@@ -375,47 +747,392 @@ func (m *ListModel) SetWorkingFilter() {
 func (m *ListModel) ClearFilter() {
 	m.filterType = FilterNone
 	m.stuckToggled = false
+	m.tagFilter = ""
+	m.applyFilter()
+}
+
+// SetTagFilter shows only sessions tagged with tag; an empty tag shows all sessions
+func (m *ListModel) SetTagFilter(tag string) {
+	m.tagFilter = tag
 	m.applyFilter()
 }
 
+// TagFilter returns the tag sessions are filtered by, or "" when there is none
+func (m *ListModel) TagFilter() string {
+	return m.tagFilter
+}
+
+// CycleTagFilter moves the tag filter to the next tag in use and, after the
+// last one, back to showing all sessions. It returns the new tag filter.
+func (m *ListModel) CycleTagFilter() string {
+	next := ""
+	for _, tag := range m.Tags() {
+		if m.tagFilter == "" || tag > m.tagFilter {
+			next = tag
+			break
+		}
+	}
+	m.SetTagFilter(next)
+	return next
+}
+
+// Tags returns the sorted tags of all loaded sessions
+func (m *ListModel) Tags() []string {
+	seen := map[string]bool{}
+	var tags []string
+	for _, session := range m.allSessions {
+		for _, tag := range session.Tags {
+			if !seen[tag] {
+				seen[tag] = true
+				tags = append(tags, tag)
+			}
+		}
+	}
+	sort.Strings(tags)
+	return tags
+}
+
+// Channels returns the sorted broadcast channels of all loaded sessions
+func (m *ListModel) Channels() []string {
+	seen := map[string]bool{}
+	var channels []string
+	for _, session := range m.allSessions {
+		for _, channel := range session.Channels {
+			if !seen[channel] {
+				seen[channel] = true
+				channels = append(channels, channel)
+			}
+		}
+	}
+	sort.Strings(channels)
+	return channels
+}
+
+// FilterPreset returns the current status filter, tag, and search as an unnamed preset
+func (m *ListModel) FilterPreset() FilterPreset {
+	return FilterPreset{
+		Status: filterTypeNames[m.filterType],
+		Tag:    m.tagFilter,
+		Search: m.searchQuery,
+	}
+}
+
+// ApplyFilterPreset replaces the status filter, tag, and search with the preset's
+func (m *ListModel) ApplyFilterPreset(preset FilterPreset) {
+	m.filterType = FilterNone
+	for filterType, name := range filterTypeNames {
+		if name == preset.Status {
+			m.filterType = filterType
+		}
+	}
+	m.stuckToggled = m.filterType == FilterStuck
+	m.tagFilter = preset.Tag
+	m.searchQuery = preset.Search
+	m.applyFilter()
+}
+
+// SetSearchQuery sets the fuzzy search query and re-filters the list
+func (m *ListModel) SetSearchQuery(query string) {
+	m.searchQuery = query
+	m.applyFilter()
+}
+
+// ClearSearch clears the fuzzy search query
+func (m *ListModel) ClearSearch() {
+	m.SetSearchQuery("")
+}
+
+// SearchQuery returns the current fuzzy search query
+func (m *ListModel) SearchQuery() string {
+	return m.searchQuery
+}
+
 // GetFilterStatus returns a string describing the current filter status
 func (m *ListModel) GetFilterStatus() string {
+	var status string
 	switch m.filterType {
 	case FilterStuck:
-		return "Showing stuck agents only"
+		status = "Showing stuck agents only"
 	case FilterWorking:
-		return "Showing working agents only"
-	default:
-		return ""
+		status = "Showing working agents only"
 	}
+	if m.tagFilter != "" {
+		tag := fmt.Sprintf("Tag: %s", m.tagFilter)
+		if status == "" {
+			status = tag
+		} else {
+			status += resolveTheme(m.theme).Separator() + tag
+		}
+	}
+
+	if m.searchQuery != "" {
+		search := fmt.Sprintf("Search: %q (%d matches)", m.searchQuery, len(m.list.Items()))
+		if status == "" {
+			return search
+		}
+		return status + resolveTheme(m.theme).Separator() + search
+	}
+	return status
 }
 
 // applyFilter applies the current filter to sessions and updates the list
 func (m *ListModel) applyFilter() {
 	filteredSessions := m.filterSessions(m.allSessions)
 
-	// Convert SessionInfo slice to list.Item slice
-	items := make([]list.Item, len(filteredSessions))
-	for i, session := range filteredSessions {
-		items[i] = NewSessionListItem(session)
+	// Pinned sessions come first and bypass the filter and search
+	items := make([]list.Item, 0, len(m.allSessions))
+	for _, session := range m.allSessions {
+		if !m.pinned[session.Name] {
+			continue
+		}
+		item := NewSessionListItem(session)
+		item.match, _ = matchSearch(session, m.searchQuery)
+		item.pinned = true
+		item.change = m.changes[session.Name].kind
+		item.marked = m.IsMarked(session.Name)
+		item.columns = m.columns
+		item.theme = m.theme
+		items = append(items, item)
+	}
+
+	// Convert SessionInfo slice to list.Item slice, keeping only search matches
+	for _, session := range filteredSessions {
+		if m.pinned[session.Name] {
+			continue
+		}
+		match, ok := matchSearch(session, m.searchQuery)
+		if !ok {
+			continue
+		}
+		item := NewSessionListItem(session)
+		item.match = match
+		item.change = m.changes[session.Name].kind
+		item.marked = m.IsMarked(session.Name)
+		item.columns = m.columns
+		item.theme = m.theme
+		items = append(items, item)
 	}
 
+	// Sessions of a run are listed together under its header
+	items = m.groupRuns(items)
+
+	// Removed sessions stay at their old row until they expire
+	items = m.insertRemovedRows(items)
+
 	// Update the list with filtered items
+	m.updateItems(items)
+}
+
+// insertRemovedRows adds recently removed sessions that would still pass the
+// filter and search back at the rows they occupied
+func (m *ListModel) insertRemovedRows(items []list.Item) []list.Item {
+	var removed []recentChange
+	for _, change := range m.changes {
+		if change.kind != rowRemoved {
+			continue
+		}
+		if _, ok := matchSearch(change.session, m.searchQuery); !ok && !m.pinned[change.session.Name] {
+			continue
+		}
+		if len(m.filterSessions([]SessionInfo{change.session})) == 0 && !m.pinned[change.session.Name] {
+			continue
+		}
+		removed = append(removed, change)
+	}
+	sort.Slice(removed, func(i, j int) bool { return removed[i].index < removed[j].index })
+
+	for _, change := range removed {
+		item := NewSessionListItem(change.session)
+		item.change = rowRemoved
+		item.pinned = m.pinned[change.session.Name]
+		item.columns = m.columns
+		item.theme = m.theme
+
+		index := change.index
+		if index < 0 || index > len(items) {
+			index = len(items)
+		}
+		items = append(items, nil)
+		copy(items[index+1:], items[index:])
+		items[index] = item
+	}
+	return items
+}
+
+// updateItems replaces the list rows. When the same sessions are shown in the
+// same order only the rows that changed are patched; otherwise the cursor is
+// moved to follow the selected session to its new row.
+func (m *ListModel) updateItems(items []list.Item) {
+	old := m.list.Items()
+	if sameRows(old, items) {
+		for i, item := range items {
+			if !reflect.DeepEqual(old[i], item) {
+				m.list.SetItem(i, item)
+			}
+		}
+		return
+	}
+
+	selectedIndex := m.list.Index()
+	selectedName := ""
+	if item, ok := m.list.SelectedItem().(SessionListItem); ok {
+		selectedName = item.session.Name
+	}
+	selectedRun := m.SelectedRun()
+
 	m.list.SetItems(items)
+	if len(items) == 0 {
+		return
+	}
+	for i, item := range items {
+		if sessionItem, ok := item.(SessionListItem); ok && selectedName != "" && sessionItem.session.Name == selectedName {
+			m.list.Select(i)
+			return
+		}
+		if header, ok := item.(RunHeaderItem); ok && selectedRun != "" && header.run == selectedRun {
+			m.list.Select(i)
+			return
+		}
+	}
+	// The selected session is gone; stay at the same row
+	if selectedIndex >= len(items) {
+		selectedIndex = len(items) - 1
+	}
+	m.list.Select(selectedIndex)
 }
 
-// filterSessions filters sessions based on the current filter type
+// sameRows reports whether both lists show the same sessions and run headers
+// in the same order
+func sameRows(a, b []list.Item) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if x, ok := a[i].(RunHeaderItem); ok {
+			if y, ok := b[i].(RunHeaderItem); !ok || x.run != y.run {
+				return false
+			}
+			continue
+		}
+		x, okX := a[i].(SessionListItem)
+		y, okY := b[i].(SessionListItem)
+		if !okX || !okY || x.session.Name != y.session.Name {
+			return false
+		}
+	}
+	return true
+}
+
+// SetNavigationKeys rebinds list cursor movement to the App's up/down keys and
+// paging to its left/right keys, so remapped navigation keys reach the list
+func (m *ListModel) SetNavigationKeys(keys KeyMap) {
+	m.list.KeyMap.CursorUp = keys.Up
+	m.list.KeyMap.CursorDown = keys.Down
+	m.list.KeyMap.PrevPage = key.NewBinding(key.WithKeys(append([]string{"pgup"}, keys.Left.Keys()...)...))
+	m.list.KeyMap.NextPage = key.NewBinding(key.WithKeys(append([]string{"pgdown"}, keys.Right.Keys()...)...))
+}
+
+// SetPinned replaces the pinned session names
+func (m *ListModel) SetPinned(names []string) {
+	m.pinned = make(map[string]bool, len(names))
+	for _, name := range names {
+		m.pinned[name] = true
+	}
+	m.applyFilter()
+}
+
+// TogglePin pins or unpins a session, keeps it selected, and reports whether it is now pinned
+func (m *ListModel) TogglePin(sessionName string) bool {
+	if m.pinned == nil {
+		m.pinned = map[string]bool{}
+	}
+	if m.pinned[sessionName] {
+		delete(m.pinned, sessionName)
+	} else {
+		m.pinned[sessionName] = true
+	}
+	m.applyFilter()
+
+	for i, item := range m.list.Items() {
+		if sessionItem, ok := item.(SessionListItem); ok && sessionItem.session.Name == sessionName {
+			m.list.Select(i)
+			break
+		}
+	}
+	return m.pinned[sessionName]
+}
+
+// IsPinned reports whether a session is pinned
+func (m *ListModel) IsPinned(sessionName string) bool {
+	return m.pinned[sessionName]
+}
+
+// PinnedSessions returns the sorted names of pinned sessions
+func (m *ListModel) PinnedSessions() []string {
+	names := make([]string, 0, len(m.pinned))
+	for name := range m.pinned {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// ToggleMark marks or unmarks a session and reports whether it is now marked
+func (m *ListModel) ToggleMark(sessionName string) bool {
+	for i, name := range m.marked {
+		if name == sessionName {
+			m.marked = append(m.marked[:i], m.marked[i+1:]...)
+			m.applyFilter()
+			return false
+		}
+	}
+	m.marked = append(m.marked, sessionName)
+	m.applyFilter()
+	return true
+}
+
+// IsMarked reports whether a session is marked
+func (m *ListModel) IsMarked(sessionName string) bool {
+	for _, name := range m.marked {
+		if name == sessionName {
+			return true
+		}
+	}
+	return false
+}
+
+// MarkedSessions returns the marked sessions that still exist, in the order
+// they were marked
+func (m *ListModel) MarkedSessions() []SessionInfo {
+	var sessions []SessionInfo
+	for _, name := range m.marked {
+		for _, session := range m.allSessions {
+			if session.Name == name {
+				sessions = append(sessions, session)
+				break
+			}
+		}
+	}
+	return sessions
+}
+
+// filterSessions filters sessions based on the current filter type and tag
 func (m *ListModel) filterSessions(sessions []SessionInfo) []SessionInfo {
-	if m.filterType == FilterNone {
+	if m.filterType == FilterNone && m.tagFilter == "" {
 		return sessions
 	}
 
 	var filtered []SessionInfo
 	for _, session := range sessions {
+		if m.tagFilter != "" && !hasTag(session, m.tagFilter) {
+			continue
+		}
 		item := NewSessionListItem(session)
 		activityStatus := item.getActivityStatus()
 
 		switch m.filterType {
+		case FilterNone:
+			filtered = append(filtered, session)
 		case FilterStuck:
 			if activityStatus == "stuck" {
 				filtered = append(filtered, session)
@@ -430,6 +1147,16 @@ func (m *ListModel) filterSessions(sessions []SessionInfo) []SessionInfo {
 	return filtered
 }
 
+// hasTag reports whether the session is tagged with tag
+func hasTag(session SessionInfo, tag string) bool {
+	for _, sessionTag := range session.Tags {
+		if sessionTag == tag {
+			return true
+		}
+	}
+	return false
+}
+
 // Items returns the current list items for test compatibility
 func (m *ListModel) Items() []list.Item {
 	return m.list.Items()
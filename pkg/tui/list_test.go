@@ -4,10 +4,13 @@
 package tui
 
 import (
+	"reflect"
 	"strings"
 	"testing"
+	"time"
 
 	"github.com/charmbracelet/lipgloss"
+	"github.com/nehpz/claudicus/pkg/config"
 )
 
 func TestClaudeSquadListView(t *testing.T) {
@@ -123,6 +126,7 @@ func TestClaudeSquadStatusFormatting(t *testing.T) {
 		{"attached", "●"},
 		{"ready", "○"},
 		{"inactive", "○"},
+		{"paused", "⏸"},
 		{"unknown", "?"},
 	}
 
@@ -145,6 +149,126 @@ func TestClaudeSquadStatusFormatting(t *testing.T) {
 	}
 }
 
+func TestPausedSessionItem(t *testing.T) {
+	session := SessionInfo{
+		Name:       "agent-proj-abc123-sarah",
+		AgentName:  "sarah",
+		Model:      "claude",
+		Status:     "paused",
+		Paused:     true,
+		Insertions: 12,
+		UpdatedAt:  time.Now().Format(time.RFC3339),
+	}
+	item := NewSessionListItem(session)
+
+	// A paused agent with changes is not counted as working or stuck
+	if got := item.getActivityStatus(); got != "paused" {
+		t.Errorf("getActivityStatus() = %q, want paused", got)
+	}
+	if !strings.Contains(item.Title(), "sarah") || !strings.Contains(item.Description(), "paused") {
+		t.Errorf("Expected the paused agent listed, got %q / %q", item.Title(), item.Description())
+	}
+}
+
+func TestDoneSessionItem(t *testing.T) {
+	session := SessionInfo{
+		Name:      "agent-proj-abc123-sarah",
+		AgentName: "sarah",
+		Model:     "claude",
+		Status:    "done",
+		Done:      true,
+		UpdatedAt: time.Now().Add(-time.Hour).Format(time.RFC3339),
+	}
+	item := NewSessionListItem(session)
+
+	// A done agent that has been quiet for an hour is not stuck
+	if got := item.getActivityStatus(); got != "done" {
+		t.Errorf("getActivityStatus() = %q, want done", got)
+	}
+	if !strings.Contains(item.Title(), "✓") || !strings.Contains(item.Description(), "done") {
+		t.Errorf("Expected a done badge, got %q / %q", item.Title(), item.Description())
+	}
+}
+
+func TestSessionItemColumns(t *testing.T) {
+	session := SessionInfo{
+		Name:         "agent-proj-abc123-sarah",
+		AgentName:    "sarah",
+		Model:        "claude",
+		Status:       "running",
+		Prompt:       "Add a login form with validation and tests",
+		Insertions:   12,
+		Deletions:    3,
+		FilesChanged: 4,
+		Port:         3001,
+		CostUSD:      1.234,
+	}
+	item := NewSessionListItem(session)
+	item.theme = PlainTheme()
+
+	defaults := item.Description()
+	if !strings.Contains(defaults, "Add a login form with validation and ...") || strings.Contains(defaults, "4 files") || strings.Contains(defaults, "$") {
+		t.Errorf("Expected the default columns, got %q", defaults)
+	}
+
+	item.columns = []config.ColumnSpec{{Column: config.ColumnCost}, {Column: config.ColumnFiles}, {Column: config.ColumnPrompt, Width: 12}}
+	if got, want := item.Description(), "$1.23 | 4 files | Add a log..."; got != want {
+		t.Errorf("Description() = %q, want %q", got, want)
+	}
+
+	// A width pads short cells and cuts long ones so rows line up
+	item.columns = []config.ColumnSpec{{Column: config.ColumnDiff, Width: 8}, {Column: config.ColumnPort, Width: 6}, {Column: config.ColumnHost, Width: 3}}
+	if got, want := item.Description(), "+12/-3   | local… |    "; got != want {
+		t.Errorf("Description() = %q, want %q", got, want)
+	}
+}
+
+func TestListModelColumns(t *testing.T) {
+	m := NewListModel(80, 24)
+	m.SetTheme(PlainTheme())
+	m.LoadSessions([]SessionInfo{{Name: "agent-proj-abc123-sarah", AgentName: "sarah", Status: "ready", Port: 3001, FilesChanged: 1}})
+	if m.ShowsColumn(config.ColumnFiles) || !m.ShowsColumn(config.ColumnPort) {
+		t.Error("Expected the default columns before any are set")
+	}
+
+	m.SetColumns([]config.ColumnSpec{{Column: config.ColumnFiles}, {Column: config.ColumnStatus}})
+	if !m.ShowsColumn(config.ColumnFiles) || m.ShowsColumn(config.ColumnPort) {
+		t.Error("Expected the configured columns")
+	}
+	item := m.list.Items()[0].(SessionListItem)
+	if got := item.Description(); !strings.HasPrefix(got, "1 file | ready") || strings.Contains(got, "3001") {
+		t.Errorf("Expected the rows redrawn with the configured columns, got %q", got)
+	}
+}
+
+func TestFormatRemainingRuntime(t *testing.T) {
+	created := time.Date(2025, 1, 1, 20, 0, 0, 0, time.UTC)
+	session := SessionInfo{
+		Name:      "agent-proj-abc123-sarah",
+		CreatedAt: created.Format(time.RFC3339),
+		Deadline:  created.Add(2 * time.Hour).Format(time.RFC3339),
+	}
+	item := NewSessionListItem(session)
+
+	testCases := []struct {
+		now  time.Time
+		want string
+	}{
+		{created.Add(30 * time.Minute), "1h30m left"},
+		{created.Add(110 * time.Minute), "10m left"},
+		{created.Add(3 * time.Hour), "over budget"},
+	}
+	for _, tc := range testCases {
+		if got := item.formatRemainingRuntime(tc.now); !strings.Contains(got, tc.want) {
+			t.Errorf("formatRemainingRuntime(%v) = %q, want it to contain %q", tc.now, got, tc.want)
+		}
+	}
+
+	if got := NewSessionListItem(SessionInfo{Name: "no-budget"}).formatRemainingRuntime(created); got != "" {
+		t.Errorf("Expected no remaining time without a budget, got %q", got)
+	}
+}
+
 // TestMetricsBasedActivityColors tests UI rendering colors based on ActivityMonitor metrics
 func TestMetricsBasedActivityColors(t *testing.T) {
 	type testCase struct {
@@ -320,3 +444,96 @@ func min(a, b int) int {
 	}
 	return b
 }
+
+func TestListSetNavigationKeys(t *testing.T) {
+	keys, err := DefaultKeyMap().WithOverrides(map[string][]string{
+		"up":      {"ctrl+p"},
+		"down":    {"ctrl+n"},
+		"palette": {":"},
+	})
+	if err != nil {
+		t.Fatalf("WithOverrides() error = %v", err)
+	}
+
+	model := NewListModel(80, 24)
+	model.SetNavigationKeys(keys)
+
+	if got := model.list.KeyMap.CursorDown.Keys(); len(got) != 1 || got[0] != "ctrl+n" {
+		t.Errorf("Expected list cursor down keys [ctrl+n], got %v", got)
+	}
+	if got := model.list.KeyMap.CursorUp.Keys(); len(got) != 1 || got[0] != "ctrl+p" {
+		t.Errorf("Expected list cursor up keys [ctrl+p], got %v", got)
+	}
+	if got := model.list.KeyMap.NextPage.Keys(); len(got) == 0 || got[0] != "pgdown" {
+		t.Errorf("Expected next page to keep pgdown, got %v", got)
+	}
+}
+
+func TestListModelLoadSessionsKeepsSelection(t *testing.T) {
+	m := NewListModel(80, 24)
+	m.LoadSessions(pinTestSessions())
+	m.list.Select(2) // sarah
+
+	// Sarah moves to the top when the order changes
+	reordered := []SessionInfo{pinTestSessions()[2], pinTestSessions()[0], pinTestSessions()[1]}
+	m.LoadSessions(reordered)
+	if selected := m.SelectedSession(); selected == nil || selected.AgentName != "sarah" {
+		t.Errorf("Expected cursor to follow sarah, got %+v", selected)
+	}
+
+	// Updates that keep the order patch rows in place
+	reordered[0].Insertions = 12
+	m.LoadSessions(reordered)
+	if m.list.Index() != 0 {
+		t.Errorf("Expected cursor to stay on row 0, got %d", m.list.Index())
+	}
+	if item := m.Items()[0].(SessionListItem); item.session.Insertions != 12 {
+		t.Errorf("Expected patched row, got %+v", item.session)
+	}
+}
+
+func TestListModelLoadSessionsHighlightsChanges(t *testing.T) {
+	now := time.Date(2025, 1, 1, 12, 0, 0, 0, time.UTC)
+	m := NewListModel(80, 24)
+	m.now = func() time.Time { return now }
+
+	sessions := pinTestSessions()
+	m.LoadSessions(sessions)
+	for _, item := range m.Items() {
+		if item.(SessionListItem).change != rowUnchanged {
+			t.Fatalf("Expected no highlight on the first load, got %+v", item)
+		}
+	}
+
+	// mary ends and emily starts
+	m.list.Select(1)
+	emily := SessionInfo{Name: "agent-proj-abc123-emily", AgentName: "emily", Status: "running"}
+	m.LoadSessions([]SessionInfo{sessions[0], sessions[2], emily})
+
+	if got := listItemNames(&m); !reflect.DeepEqual(got, []string{"john", "mary", "sarah", "emily"}) {
+		t.Errorf("Expected removed row to stay in place, got %v", got)
+	}
+	items := m.Items()
+	if items[1].(SessionListItem).change != rowRemoved || !strings.Contains(items[1].(SessionListItem).Title(), "removed") {
+		t.Errorf("Expected mary marked removed, got %q", items[1].(SessionListItem).Title())
+	}
+	if items[3].(SessionListItem).change != rowAdded || !strings.Contains(items[3].(SessionListItem).Title(), "new") {
+		t.Errorf("Expected emily marked new, got %q", items[3].(SessionListItem).Title())
+	}
+	if m.SelectedSession() != nil {
+		t.Error("Removed rows should not be selectable")
+	}
+
+	// Highlights expire on a later refresh
+	now = now.Add(rowChangeDuration)
+	m.LoadSessions([]SessionInfo{sessions[0], sessions[2], emily})
+	if got := listItemNames(&m); !reflect.DeepEqual(got, []string{"john", "sarah", "emily"}) {
+		t.Errorf("Expected removed row to expire, got %v", got)
+	}
+	if m.Items()[2].(SessionListItem).change != rowUnchanged {
+		t.Error("Expected new highlight to expire")
+	}
+	if selected := m.SelectedSession(); selected == nil || selected.AgentName != "sarah" {
+		t.Errorf("Expected cursor to stay on the same row, got %+v", selected)
+	}
+}
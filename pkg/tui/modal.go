@@ -0,0 +1,340 @@
+// Copyright (c) Subtrace, Inc.
+// SPDX-License-Identifier: BSD-3-Clause
+
+package tui
+
+import (
+	"strings"
+
+	"github.com/nehpz/claudicus/pkg/config"
+
+	"github.com/charmbracelet/bubbles/key"
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// Modal is an overlay that takes over key handling while it is focused.
+// Modals may hide themselves from Update (for example on Esc or submit);
+// the ModalStack drops them once they lose focus.
+type Modal interface {
+	Show()
+	Hide()
+	Update(msg tea.Msg) tea.Cmd
+	View() string
+	Focused() bool
+}
+
+// ModalStack tracks the open overlays in the order they were shown.
+// Only the top modal receives key events.
+type ModalStack struct {
+	modals []Modal
+}
+
+// NewModalStack creates an empty modal stack
+func NewModalStack() *ModalStack {
+	return &ModalStack{}
+}
+
+// Open shows the modal and moves it to the top of the stack
+func (s *ModalStack) Open(m Modal) {
+	s.remove(m)
+	m.Show()
+	s.modals = append(s.modals, m)
+}
+
+// Close hides the modal and removes it from the stack
+func (s *ModalStack) Close(m Modal) {
+	m.Hide()
+	s.remove(m)
+}
+
+// Top returns the topmost focused modal, or nil when no modal is open
+func (s *ModalStack) Top() Modal {
+	s.prune()
+	if len(s.modals) == 0 {
+		return nil
+	}
+	return s.modals[len(s.modals)-1]
+}
+
+// Active reports whether any modal is open
+func (s *ModalStack) Active() bool {
+	return s.Top() != nil
+}
+
+// HandleKey routes a key event to the top modal. It reports false when no
+// modal is open so the caller can handle the key itself.
+func (s *ModalStack) HandleKey(msg tea.KeyMsg) (tea.Cmd, bool) {
+	top := s.Top()
+	if top == nil {
+		return nil, false
+	}
+	cmd := top.Update(msg)
+	s.prune()
+	return cmd, true
+}
+
+// Views renders the open modals from the bottom of the stack to the top
+func (s *ModalStack) Views() []string {
+	s.prune()
+	views := make([]string, 0, len(s.modals))
+	for _, m := range s.modals {
+		if view := m.View(); view != "" {
+			views = append(views, view)
+		}
+	}
+	return views
+}
+
+// prune drops modals that have hidden themselves since they were opened
+func (s *ModalStack) prune() {
+	kept := s.modals[:0]
+	for _, m := range s.modals {
+		if m.Focused() {
+			kept = append(kept, m)
+		}
+	}
+	s.modals = kept
+}
+
+func (s *ModalStack) remove(m Modal) {
+	for i, existing := range s.modals {
+		if existing == m {
+			s.modals = append(s.modals[:i], s.modals[i+1:]...)
+			return
+		}
+	}
+}
+
+// confirmModalAdapter exposes the kill/replace confirmation as a Modal
+type confirmModalAdapter struct {
+	modal *ConfirmationModal
+}
+
+func (a *confirmModalAdapter) Show()         { a.modal.SetVisible(true) }
+func (a *confirmModalAdapter) Hide()         { a.modal.SetVisible(false) }
+func (a *confirmModalAdapter) View() string  { return a.modal.View() }
+func (a *confirmModalAdapter) Focused() bool { return a.modal.IsVisible() }
+
+func (a *confirmModalAdapter) Update(msg tea.Msg) tea.Cmd {
+	var cmd tea.Cmd
+	a.modal, cmd = a.modal.Update(msg)
+	return cmd
+}
+
+// checkpointModalAdapter exposes the checkpoint modal as a Modal
+type checkpointModalAdapter struct {
+	modal *CheckpointModal
+}
+
+func (a *checkpointModalAdapter) Show()         { a.modal.SetVisible(true) }
+func (a *checkpointModalAdapter) Hide()         { a.modal.SetVisible(false) }
+func (a *checkpointModalAdapter) View() string  { return a.modal.View() }
+func (a *checkpointModalAdapter) Focused() bool { return a.modal.IsVisible() }
+
+func (a *checkpointModalAdapter) Update(msg tea.Msg) tea.Cmd {
+	var cmd tea.Cmd
+	*a.modal, cmd = a.modal.Update(msg)
+	return cmd
+}
+
+// agentFormAdapter exposes the new agent form as a Modal
+type agentFormAdapter struct {
+	form *AgentFormModel
+}
+
+func (a *agentFormAdapter) Show()         { a.form.SetActive(true) }
+func (a *agentFormAdapter) Hide()         { a.form.SetActive(false) }
+func (a *agentFormAdapter) View() string  { return a.form.View() }
+func (a *agentFormAdapter) Focused() bool { return a.form.IsActive() }
+
+func (a *agentFormAdapter) Update(msg tea.Msg) tea.Cmd {
+	var cmd tea.Cmd
+	*a.form, cmd = a.form.Update(msg)
+	return cmd
+}
+
+// progressModalAdapter exposes the agent creation progress modal as a Modal
+type progressModalAdapter struct {
+	modal *ProgressModal
+}
+
+func (a *progressModalAdapter) Show()         { a.modal.SetActive(true) }
+func (a *progressModalAdapter) Hide()         { a.modal.SetActive(false) }
+func (a *progressModalAdapter) View() string  { return a.modal.View() }
+func (a *progressModalAdapter) Focused() bool { return a.modal.IsActive() }
+
+func (a *progressModalAdapter) Update(msg tea.Msg) tea.Cmd {
+	var cmd tea.Cmd
+	*a.modal, cmd = a.modal.Update(msg)
+	return cmd
+}
+
+// broadcastModal wraps the broadcast prompt: Enter sends the message through
+// onSubmit, Tab completes a "#" channel from channels or picks the next
+// template from templates, and Esc cancels
+type broadcastModal struct {
+	input     *BroadcastInputModel
+	keys      *KeyMap
+	templates func() []config.BroadcastTemplate
+	channels  func() []string
+	onSubmit  func(message string) tea.Cmd
+}
+
+func (m *broadcastModal) Show() {
+	m.input.SetActive(true)
+	if m.templates != nil {
+		m.input.SetTemplates(m.templates())
+	}
+	if m.channels != nil {
+		m.input.SetChannels(m.channels())
+	}
+}
+
+func (m *broadcastModal) Hide()         { m.input.SetActive(false) }
+func (m *broadcastModal) View() string  { return m.input.View() }
+func (m *broadcastModal) Focused() bool { return m.input.IsActive() }
+
+// KeyHints implements keyHinter
+func (m *broadcastModal) KeyHints() []key.Binding {
+	hints := []key.Binding{hint(m.keys.Enter, "send")}
+	if m.input.CompletingChannel() {
+		hints = append(hints, hint(m.keys.Tab, "complete channel"))
+	} else if len(m.input.templates) > 0 {
+		hints = append(hints, hint(m.keys.Tab, "next template"))
+	}
+	return append(hints, hint(m.keys.Escape, "cancel"))
+}
+
+func (m *broadcastModal) Update(msg tea.Msg) tea.Cmd {
+	if keyMsg, ok := msg.(tea.KeyMsg); ok {
+		switch {
+		case key.Matches(keyMsg, m.keys.Enter):
+			message := m.input.Value()
+			m.input.SetActive(false)
+			if message != "" {
+				return m.onSubmit(message)
+			}
+			return nil
+
+		case key.Matches(keyMsg, m.keys.Tab):
+			if m.input.CompletingChannel() {
+				m.input.CompleteChannel()
+			} else {
+				m.input.NextTemplate()
+			}
+			return nil
+
+		case key.Matches(keyMsg, m.keys.Escape):
+			m.input.SetActive(false)
+			return nil
+		}
+	}
+
+	var cmd tea.Cmd
+	m.input, cmd = m.input.Update(msg)
+	return cmd
+}
+
+// presetModal wraps the preset name prompt: Enter saves the current filter
+// through onSubmit and Esc cancels
+type presetModal struct {
+	input    *PresetInputModel
+	keys     *KeyMap
+	onSubmit func(name string) tea.Cmd
+}
+
+func (m *presetModal) Show()         { m.input.SetActive(true) }
+func (m *presetModal) Hide()         { m.input.SetActive(false) }
+func (m *presetModal) View() string  { return m.input.View() }
+func (m *presetModal) Focused() bool { return m.input.IsActive() }
+
+func (m *presetModal) Update(msg tea.Msg) tea.Cmd {
+	if keyMsg, ok := msg.(tea.KeyMsg); ok {
+		switch {
+		case key.Matches(keyMsg, m.keys.Enter):
+			name := strings.TrimSpace(m.input.Value())
+			m.input.SetActive(false)
+			if name != "" {
+				return m.onSubmit(name)
+			}
+			return nil
+
+		case key.Matches(keyMsg, m.keys.Escape):
+			m.input.SetActive(false)
+			return nil
+		}
+	}
+
+	var cmd tea.Cmd
+	m.input, cmd = m.input.Update(msg)
+	return cmd
+}
+
+// retryModal wraps the retry prompt editor: ctrl+s respawns the agent through
+// onSubmit and Esc cancels. Enter is left to the editor for new lines.
+type retryModal struct {
+	editor   *RetryPromptModel
+	keys     *KeyMap
+	onSubmit func(sessionName, prompt string) tea.Cmd
+}
+
+func (m *retryModal) Show()         { m.editor.SetActive(true) }
+func (m *retryModal) Hide()         { m.editor.SetActive(false) }
+func (m *retryModal) View() string  { return m.editor.View() }
+func (m *retryModal) Focused() bool { return m.editor.IsActive() }
+
+func (m *retryModal) Update(msg tea.Msg) tea.Cmd {
+	if keyMsg, ok := msg.(tea.KeyMsg); ok {
+		switch {
+		case keyMsg.Type == tea.KeyCtrlS:
+			prompt := strings.TrimSpace(m.editor.Value())
+			if prompt == "" {
+				return nil
+			}
+			m.editor.SetActive(false)
+			return m.onSubmit(m.editor.SessionName(), prompt)
+
+		case key.Matches(keyMsg, m.keys.Escape):
+			m.editor.SetActive(false)
+			return nil
+		}
+	}
+
+	var cmd tea.Cmd
+	m.editor, cmd = m.editor.Update(msg)
+	return cmd
+}
+
+// searchModal wraps the fuzzy search prompt and filters the list as the user types.
+// Enter keeps the query applied; Esc clears it.
+type searchModal struct {
+	input *SearchInputModel
+	list  *ListModel
+	keys  *KeyMap
+}
+
+func (m *searchModal) Show()         { m.input.SetActive(true) }
+func (m *searchModal) Hide()         { m.input.SetActive(false) }
+func (m *searchModal) View() string  { return m.input.View() }
+func (m *searchModal) Focused() bool { return m.input.IsActive() }
+
+func (m *searchModal) Update(msg tea.Msg) tea.Cmd {
+	if keyMsg, ok := msg.(tea.KeyMsg); ok {
+		switch {
+		case key.Matches(keyMsg, m.keys.Enter):
+			m.input.SetActive(false)
+			return nil
+
+		case key.Matches(keyMsg, m.keys.Escape):
+			m.input.Reset()
+			m.input.SetActive(false)
+			m.list.ClearSearch()
+			return nil
+		}
+	}
+
+	var cmd tea.Cmd
+	m.input, cmd = m.input.Update(msg)
+	m.list.SetSearchQuery(m.input.Value())
+	return cmd
+}
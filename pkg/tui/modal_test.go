@@ -0,0 +1,294 @@
+// Copyright (c) Subtrace, Inc.
+// SPDX-License-Identifier: BSD-3-Clause
+
+package tui
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/nehpz/claudicus/pkg/config"
+	"github.com/nehpz/claudicus/pkg/tmuxops"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// fakeModal records the keys it receives and hides itself on Esc
+type fakeModal struct {
+	name    string
+	visible bool
+	keys    []string
+}
+
+func (m *fakeModal) Show()         { m.visible = true }
+func (m *fakeModal) Hide()         { m.visible = false }
+func (m *fakeModal) View() string  { return m.name }
+func (m *fakeModal) Focused() bool { return m.visible }
+
+func (m *fakeModal) Update(msg tea.Msg) tea.Cmd {
+	if keyMsg, ok := msg.(tea.KeyMsg); ok {
+		m.keys = append(m.keys, keyMsg.String())
+		if keyMsg.Type == tea.KeyEsc {
+			m.visible = false
+		}
+	}
+	return nil
+}
+
+func TestModalStack_OnlyTopReceivesKeys(t *testing.T) {
+	stack := NewModalStack()
+	bottom := &fakeModal{name: "bottom"}
+	top := &fakeModal{name: "top"}
+
+	if _, handled := stack.HandleKey(tea.KeyMsg{Type: tea.KeyEnter}); handled {
+		t.Fatal("Expected empty stack not to handle keys")
+	}
+
+	stack.Open(bottom)
+	stack.Open(top)
+
+	if _, handled := stack.HandleKey(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune{'x'}}); !handled {
+		t.Fatal("Expected open stack to handle keys")
+	}
+	if len(top.keys) != 1 || len(bottom.keys) != 0 {
+		t.Errorf("Expected only top modal to get the key, top=%v bottom=%v", top.keys, bottom.keys)
+	}
+
+	// Top hides itself; focus returns to the modal below
+	stack.HandleKey(tea.KeyMsg{Type: tea.KeyEsc})
+	if stack.Top() != bottom {
+		t.Fatalf("Expected bottom modal on top after Esc, got %v", stack.Top())
+	}
+
+	stack.HandleKey(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune{'y'}})
+	if len(bottom.keys) != 1 {
+		t.Errorf("Expected bottom modal to receive key, got %v", bottom.keys)
+	}
+}
+
+func TestModalStack_OpenMovesToTop(t *testing.T) {
+	stack := NewModalStack()
+	first := &fakeModal{name: "first"}
+	second := &fakeModal{name: "second"}
+
+	stack.Open(first)
+	stack.Open(second)
+	stack.Open(first)
+
+	if stack.Top() != first {
+		t.Errorf("Expected reopened modal on top, got %v", stack.Top())
+	}
+	views := stack.Views()
+	if len(views) != 2 || views[0] != "second" || views[1] != "first" {
+		t.Errorf("Views() = %v, want [second first]", views)
+	}
+
+	stack.Close(first)
+	if first.visible {
+		t.Error("Expected Close to hide the modal")
+	}
+	if stack.Top() != second {
+		t.Errorf("Expected second modal on top after Close, got %v", stack.Top())
+	}
+
+	stack.Close(second)
+	if stack.Active() {
+		t.Error("Expected stack to be inactive after closing all modals")
+	}
+}
+
+func TestApp_ModalStackRoutesSearchAndBroadcast(t *testing.T) {
+	app := NewApp(&MockUziInterface{})
+	defer app.Cleanup()
+
+	// Open search, then type into it: list keys must not fire
+	app.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune{'/'}})
+	if app.modals.Top() != app.searchOverlay {
+		t.Fatal("Expected search overlay on top after '/'")
+	}
+	app.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune{'q'}})
+	if app.searchInput.Value() != "q" {
+		t.Errorf("Expected 'q' to go to the search input, got %q", app.searchInput.Value())
+	}
+
+	// Enter closes search and keeps the query
+	app.Update(tea.KeyMsg{Type: tea.KeyEnter})
+	if app.modals.Active() {
+		t.Error("Expected no open modal after Enter")
+	}
+	if app.list.SearchQuery() != "q" {
+		t.Errorf("Expected search query to stay applied, got %q", app.list.SearchQuery())
+	}
+
+	// Broadcast with an empty message just closes
+	app.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune{'b'}})
+	if app.modals.Top() != app.broadcastOverlay {
+		t.Fatal("Expected broadcast overlay on top after 'b'")
+	}
+	_, cmd := app.Update(tea.KeyMsg{Type: tea.KeyEnter})
+	if cmd != nil {
+		t.Error("Expected no broadcast command for an empty message")
+	}
+	if app.broadcastInput.IsActive() {
+		t.Error("Expected broadcast input to close after Enter")
+	}
+}
+
+func TestApp_ViewRendersOpenModals(t *testing.T) {
+	app := NewApp(&MockUziInterface{})
+	defer app.Cleanup()
+
+	app.width, app.height = 80, 24
+	app.loading = false
+	app.modals.Open(app.broadcastOverlay)
+
+	for _, split := range []bool{false, true} {
+		app.splitView = split
+		if view := app.View(); !strings.Contains(view, "Message:") {
+			t.Errorf("Expected broadcast prompt in view (split=%v)", split)
+		}
+	}
+}
+
+func TestApp_HelpKeyOpensHelpView(t *testing.T) {
+	app := NewApp(&MockUziInterface{})
+	defer app.Cleanup()
+
+	app.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune{'?'}})
+	if app.modals.Top() != app.helpView {
+		t.Fatal("Expected help view on top after '?'")
+	}
+	view := app.helpView.View()
+	for _, want := range []string{"Keyboard shortcuts", "kill", "pipelines"} {
+		if !strings.Contains(view, want) {
+			t.Errorf("Expected %q in help view, got %q", want, view)
+		}
+	}
+
+	app.Update(tea.KeyMsg{Type: tea.KeyEsc})
+	if app.modals.Active() {
+		t.Error("Expected Esc to close the help view")
+	}
+}
+
+func TestBroadcastModal_TabPicksTemplates(t *testing.T) {
+	app := NewApp(&MockUziInterface{})
+	defer app.Cleanup()
+	app.config = &config.Config{Broadcasts: map[string]string{
+		"status": "Summarize your progress",
+		"rebase": "Rebase {branch} on main",
+	}}
+
+	app.modals.Open(app.broadcastOverlay)
+	if !strings.Contains(app.broadcastInput.View(), "tab: template (rebase status)") {
+		t.Errorf("Expected the templates listed, got:\n%s", app.broadcastInput.View())
+	}
+	app.Update(tea.KeyMsg{Type: tea.KeyTab})
+	if got := app.broadcastInput.Value(); got != "Rebase {branch} on main" {
+		t.Errorf("Expected the first template by name, got %q", got)
+	}
+	app.Update(tea.KeyMsg{Type: tea.KeyTab})
+	app.Update(tea.KeyMsg{Type: tea.KeyTab})
+	if got := app.broadcastInput.Value(); got != "Rebase {branch} on main" {
+		t.Errorf("Expected Tab to wrap around, got %q", got)
+	}
+	if app.modals.Top() != app.broadcastOverlay {
+		t.Error("Expected the broadcast prompt to stay open while picking")
+	}
+}
+
+func TestBroadcastModal_TabCompletesChannels(t *testing.T) {
+	app := NewApp(&MockUziInterface{})
+	defer app.Cleanup()
+	app.config = &config.Config{
+		Broadcasts: map[string]string{"status": "Summarize your progress"},
+		Channels:   map[string][]string{"frontend": {"ui"}, "backend": {"api"}, "build": nil},
+	}
+
+	app.modals.Open(app.broadcastOverlay)
+	if !strings.Contains(app.broadcastInput.View(), "(backend build frontend)") {
+		t.Errorf("Expected the channels listed, got:\n%s", app.broadcastInput.View())
+	}
+	app.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("#b")})
+	app.Update(tea.KeyMsg{Type: tea.KeyTab})
+	if got := app.broadcastInput.Value(); got != "#b" {
+		t.Errorf("Expected an ambiguous prefix left alone, got %q", got)
+	}
+	app.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("a")})
+	app.Update(tea.KeyMsg{Type: tea.KeyTab})
+	if got := app.broadcastInput.Value(); got != "#backend " {
+		t.Errorf("Expected the channel completed, got %q", got)
+	}
+	app.Update(tea.KeyMsg{Type: tea.KeyTab})
+	if got := app.broadcastInput.Value(); got != "Summarize your progress" {
+		t.Errorf("Expected Tab to pick a template once the channel is complete, got %q", got)
+	}
+}
+
+func TestParseChannelMessage(t *testing.T) {
+	tests := []struct {
+		input, channel, message string
+	}{
+		{"#frontend rebuild the styles", "frontend", "rebuild the styles"},
+		{"#frontend", "frontend", ""},
+		{"rebuild everything", "", "rebuild everything"},
+		{"# heading", "", "# heading"},
+	}
+	for _, tt := range tests {
+		channel, message := parseChannelMessage(tt.input)
+		if channel != tt.channel || message != tt.message {
+			t.Errorf("parseChannelMessage(%q) = %q, %q, want %q, %q", tt.input, channel, message, tt.channel, tt.message)
+		}
+	}
+}
+
+// goneUziMock reports a session that died before a broadcast reached it
+type goneUziMock struct {
+	MockUziInterface
+}
+
+func (m *goneUziMock) RunBroadcast(message string) error {
+	return fmt.Errorf("uzi_proxy: RunBroadcast: %w", &tmuxops.SessionGoneError{Session: "agent-proj-abc123-john"})
+}
+
+func TestApp_BroadcastSkippedGoneSessions(t *testing.T) {
+	app := NewApp(&goneUziMock{})
+	defer app.Cleanup()
+
+	msg, ok := app.broadcastCmd("status?")().(BroadcastSkippedMsg)
+	if !ok || len(msg.Sessions) != 1 || msg.Sessions[0] != "agent-proj-abc123-john" {
+		t.Fatalf("Expected a BroadcastSkippedMsg for john, got %+v", msg)
+	}
+	if _, cmd := app.Update(msg); cmd == nil {
+		t.Error("Expected the list refreshed after skipping gone sessions")
+	}
+	if !strings.Contains(app.notice, "Broadcast skipped john: tmux session gone") {
+		t.Errorf("Expected the skipped agents on the status line, got %q", app.notice)
+	}
+}
+
+// failingBroadcastUziMock fails every broadcast
+type failingBroadcastUziMock struct {
+	MockUziInterface
+}
+
+func (m *failingBroadcastUziMock) RunBroadcast(message string) error {
+	return errors.New("tmux server not running")
+}
+
+func TestApp_BroadcastFailureReported(t *testing.T) {
+	app := NewApp(&failingBroadcastUziMock{})
+	defer app.Cleanup()
+
+	result := app.broadcastCmd("status?")()
+	msg, ok := result.(CommandErrorMsg)
+	if !ok {
+		t.Fatalf("Expected a failed broadcast to be reported, got %T", result)
+	}
+	app.Update(msg)
+	if !app.noticeIsError || !strings.Contains(app.notice, "broadcast failed: tmux server not running") {
+		t.Errorf("Expected an error notice for the failed broadcast, got %q", app.notice)
+	}
+}
@@ -0,0 +1,76 @@
+// Copyright (c) Subtrace, Inc.
+// SPDX-License-Identifier: BSD-3-Clause
+
+package tui
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/charmbracelet/bubbles/key"
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+func TestNudgeAgentHandling(t *testing.T) {
+	mockUzi := &MockUziInterface{}
+	app := NewApp(mockUzi)
+	defer app.Cleanup()
+
+	app.list.LoadSessions([]SessionInfo{
+		{Name: "agent-proj-abc123-sarah", AgentName: "sarah", Status: "ready"},
+	})
+
+	nudgeKeyMsg := tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune{'u'}}
+	if !key.Matches(nudgeKeyMsg, app.keys.Nudge) {
+		t.Fatal("Nudge key should match the Nudge binding")
+	}
+
+	_, cmd := app.Update(nudgeKeyMsg)
+	if cmd == nil {
+		t.Fatal("Expected a command to nudge the selected agent")
+	}
+
+	if _, ok := cmd().(RefreshMsg); !ok {
+		t.Error("Expected a refresh after a successful nudge")
+	}
+	if len(mockUzi.nudgedAgents) != 1 || mockUzi.nudgedAgents[0] != "sarah" {
+		t.Errorf("Expected agent 'sarah' to be nudged, got %v", mockUzi.nudgedAgents)
+	}
+}
+
+func TestNudgeAgentHandlingNoSelection(t *testing.T) {
+	mockUzi := &MockUziInterface{}
+	app := NewApp(mockUzi)
+	defer app.Cleanup()
+
+	app.list.LoadSessions([]SessionInfo{})
+
+	_, cmd := app.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune{'u'}})
+	if cmd != nil {
+		cmd()
+	}
+	if len(mockUzi.nudgedAgents) != 0 {
+		t.Errorf("Expected no nudges without a selection, got %v", mockUzi.nudgedAgents)
+	}
+}
+
+func TestNudgeAgentHandlingFailure(t *testing.T) {
+	mockUzi := &MockUziInterface{shouldFail: true}
+	app := NewApp(mockUzi)
+	defer app.Cleanup()
+
+	app.list.LoadSessions([]SessionInfo{
+		{Name: "agent-proj-abc123-sarah", AgentName: "sarah", Status: "ready"},
+	})
+
+	_, cmd := app.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune{'u'}})
+	result := cmd()
+	msg, ok := result.(CommandErrorMsg)
+	if !ok {
+		t.Fatalf("Expected a failed nudge to be reported, got %T", result)
+	}
+	app.Update(msg)
+	if !app.noticeIsError || !strings.Contains(app.notice, "nudge sarah failed: mock nudge failure") {
+		t.Errorf("Expected an error notice for the failed nudge, got %q", app.notice)
+	}
+}
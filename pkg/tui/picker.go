@@ -0,0 +1,204 @@
+// Copyright (c) Subtrace, Inc.
+// SPDX-License-Identifier: BSD-3-Clause
+
+package tui
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+
+	"github.com/charmbracelet/bubbles/list"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/nehpz/claudicus/pkg/hosts"
+	"github.com/nehpz/claudicus/pkg/state"
+	"golang.org/x/term"
+)
+
+// ErrNoAgentPicked is returned by PickAgent when the user cancels the picker
+var ErrNoAgentPicked = errors.New("no agent picked")
+
+// pickerMaxRows bounds how many agents the picker shows at once; the list
+// scrolls beyond them
+const pickerMaxRows = 6
+
+// PickerModel is a small agent list for CLI commands run without an agent
+// name. Rows look like the TUI's session list, and the changed files of the
+// highlighted agent are shown below it.
+type PickerModel struct {
+	list      list.Model
+	theme     *Theme
+	preview   func(SessionInfo) (state.DiffStat, error)
+	previews  map[string]string // rendered previews by session name
+	picked    *SessionInfo
+	cancelled bool
+}
+
+// NewPickerModel returns a picker of sessions titled with the question to
+// answer. preview loads the changed files of a session; nil shows none.
+func NewPickerModel(title string, sessions []SessionInfo, theme *Theme, preview func(SessionInfo) (state.DiffStat, error)) *PickerModel {
+	theme = resolveTheme(theme)
+	items := make([]list.Item, 0, len(sessions))
+	for _, session := range sessions {
+		item := NewSessionListItem(session)
+		item.theme = theme
+		items = append(items, item)
+	}
+	// Each row is a title and a description, with a blank line between rows
+	height := min(len(sessions), pickerMaxRows)*3 + 2
+	l := list.New(items, themedDelegate(theme), 80, height)
+	l.Title = title
+	l.Styles.Title = theme.Header
+	l.Styles.TitleBar = theme.HeaderBar
+	l.SetShowStatusBar(false)
+	l.SetShowHelp(false)
+	l.SetShowPagination(len(sessions) > pickerMaxRows)
+
+	return &PickerModel{
+		list:     l,
+		theme:    theme,
+		preview:  preview,
+		previews: map[string]string{},
+	}
+}
+
+// Picked returns the chosen session, or nil if none was chosen yet or the
+// picker was cancelled
+func (m *PickerModel) Picked() *SessionInfo {
+	return m.picked
+}
+
+// Cancelled reports whether the picker was closed without a choice
+func (m *PickerModel) Cancelled() bool {
+	return m.cancelled
+}
+
+// Init implements tea.Model
+func (m *PickerModel) Init() tea.Cmd {
+	return nil
+}
+
+// Update implements tea.Model. Enter picks the highlighted agent; esc, q, and
+// ctrl+c cancel, except that esc and q belong to the filter while typing one.
+func (m *PickerModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
+	switch msg := msg.(type) {
+	case tea.KeyMsg:
+		filtering := m.list.FilterState() == list.Filtering
+		switch msg.String() {
+		case "ctrl+c":
+			m.cancelled = true
+			return m, tea.Quit
+		case "esc", "q":
+			if !filtering && m.list.FilterState() != list.FilterApplied {
+				m.cancelled = true
+				return m, tea.Quit
+			}
+		case "enter":
+			if !filtering {
+				if item, ok := m.list.SelectedItem().(SessionListItem); ok {
+					session := item.session
+					m.picked = &session
+					return m, tea.Quit
+				}
+			}
+		}
+	case tea.WindowSizeMsg:
+		m.list.SetWidth(msg.Width)
+	}
+
+	var cmd tea.Cmd
+	m.list, cmd = m.list.Update(msg)
+	return m, cmd
+}
+
+// View implements tea.Model
+func (m *PickerModel) View() string {
+	if m.picked != nil || m.cancelled {
+		return ""
+	}
+	hint := m.theme.Muted.Render("↑/↓ move • / filter • enter select • esc cancel")
+	return m.list.View() + "\n" + m.previewView() + "\n\n" + hint + "\n"
+}
+
+// previewView renders the changed files of the highlighted session, loading
+// them the first time it is highlighted
+func (m *PickerModel) previewView() string {
+	item, ok := m.list.SelectedItem().(SessionListItem)
+	if !ok || m.preview == nil {
+		return ""
+	}
+	name := item.session.Name
+	if rendered, ok := m.previews[name]; ok {
+		return rendered
+	}
+	stat, err := m.preview(item.session)
+	var rendered string
+	switch {
+	case err != nil:
+		rendered = m.theme.Muted.Render("Changed files unavailable: " + err.Error())
+	case len(stat.Files) == 0:
+		rendered = m.theme.Muted.Render("No changes")
+	default:
+		rendered = formatFileStat(stat, m.theme)
+		if lines := strings.Split(rendered, "\n"); len(lines) > pickerMaxRows+1 {
+			rendered = strings.Join(lines[:pickerMaxRows+1], "\n") + "\n" +
+				m.theme.Muted.Render(fmt.Sprintf("  … %d more", len(lines)-pickerMaxRows-1))
+		}
+	}
+	m.previews[name] = rendered
+	return rendered
+}
+
+// Interactive reports whether stdin and stdout are terminals, so a picker
+// can be shown instead of requiring an argument
+func Interactive() bool {
+	return term.IsTerminal(int(os.Stdin.Fd())) && term.IsTerminal(int(os.Stdout.Fd()))
+}
+
+// PickAgent shows the active agents of the current repository with their
+// status and diff, and returns the name of the one the user picks. It returns
+// ErrNoAgentPicked if the user cancels, and an error if there are no agents.
+func PickAgent(title string) (string, error) {
+	sm := state.NewStateManager()
+	if sm == nil {
+		return "", fmt.Errorf("could not initialize state manager")
+	}
+	activeSessions, err := sm.GetActiveSessionsForRepo()
+	if err != nil {
+		return "", fmt.Errorf("error getting active sessions: %w", err)
+	}
+	states := make(map[string]state.AgentState, len(activeSessions))
+	for _, sessionName := range activeSessions {
+		if agentState, err := sm.GetWorktreeInfo(sessionName); err == nil {
+			states[sessionName] = *agentState
+		}
+	}
+	if len(states) == 0 {
+		return "", fmt.Errorf("no active agents")
+	}
+	sort.Strings(activeSessions)
+
+	aggregator := state.NewAggregator(state.WithTmuxStatus(), state.WithDiffs(), state.WithRemoteProbe(hosts.RemoteProbe))
+	var sessions []SessionInfo
+	for _, info := range aggregator.Sessions(states, activeSessions) {
+		sessions = append(sessions, sessionInfoFromState(info))
+	}
+	preview := func(session SessionInfo) (state.DiffStat, error) {
+		return aggregator.FileDiffs(states[session.Name])
+	}
+
+	theme := DefaultTheme()
+	if ColorDisabled(false) {
+		theme = PlainTheme()
+	}
+	model := NewPickerModel(title, sessions, theme, preview)
+	if _, err := tea.NewProgram(model).Run(); err != nil {
+		return "", err
+	}
+	if model.Picked() == nil {
+		return "", ErrNoAgentPicked
+	}
+	return model.Picked().AgentName, nil
+}
@@ -0,0 +1,69 @@
+package tui
+
+import (
+	"errors"
+	"strings"
+	"testing"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/nehpz/claudicus/pkg/state"
+)
+
+func pickerSessions() []SessionInfo {
+	return []SessionInfo{
+		{Name: "agent-proj-abc123-sarah", AgentName: "sarah", Model: "claude", Status: "ready", Insertions: 12, Deletions: 3},
+		{Name: "agent-proj-abc123-john", AgentName: "john", Model: "codex", Status: "running"},
+	}
+}
+
+func TestPickerModel_Pick(t *testing.T) {
+	loads := 0
+	preview := func(session SessionInfo) (state.DiffStat, error) {
+		loads++
+		if session.AgentName == "john" {
+			return state.DiffStat{}, errors.New("worktree is gone")
+		}
+		return state.DiffStat{Files: []state.FileDiff{{Path: "login.go", Change: state.ChangeModified, Insertions: 12, Deletions: 3}}}, nil
+	}
+	m := NewPickerModel("Kill which agent?", pickerSessions(), PlainTheme(), preview)
+
+	view := m.View()
+	for _, want := range []string{"Kill which agent?", "sarah (claude)", "john (codex)", "+12/-3", "login.go", "enter select"} {
+		if !strings.Contains(view, want) {
+			t.Errorf("Expected %q in the picker:\n%s", want, view)
+		}
+	}
+	m.View()
+	if loads != 1 {
+		t.Errorf("Expected the preview of a session loaded once, got %d loads", loads)
+	}
+
+	m.Update(tea.KeyMsg{Type: tea.KeyDown})
+	if view := m.View(); !strings.Contains(view, "Changed files unavailable: worktree is gone") {
+		t.Errorf("Expected the preview of the highlighted agent:\n%s", view)
+	}
+
+	_, cmd := m.Update(tea.KeyMsg{Type: tea.KeyEnter})
+	if m.Picked() == nil || m.Picked().AgentName != "john" || m.Cancelled() {
+		t.Fatalf("Expected john picked, got %+v", m.Picked())
+	}
+	if cmd == nil {
+		t.Error("Expected the picker to quit after a pick")
+	}
+	if m.View() != "" {
+		t.Error("Expected the picker cleared after a pick")
+	}
+}
+
+func TestPickerModel_Cancel(t *testing.T) {
+	for _, key := range []tea.KeyMsg{
+		{Type: tea.KeyEsc},
+		{Type: tea.KeyCtrlC},
+		{Type: tea.KeyRunes, Runes: []rune("q")},
+	} {
+		m := NewPickerModel("Checkpoint which agent?", pickerSessions(), PlainTheme(), nil)
+		if _, cmd := m.Update(key); cmd == nil || !m.Cancelled() || m.Picked() != nil {
+			t.Errorf("Expected %q to cancel the picker", key.String())
+		}
+	}
+}
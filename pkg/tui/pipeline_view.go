@@ -0,0 +1,130 @@
+// Copyright (c) Subtrace, Inc.
+// SPDX-License-Identifier: BSD-3-Clause
+
+package tui
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/charmbracelet/bubbles/key"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+	"github.com/nehpz/claudicus/pkg/pipeline"
+)
+
+// PipelineRunsMsg carries pipeline runs loaded for the pipeline view
+type PipelineRunsMsg struct {
+	Runs  []pipeline.Run
+	Error string
+}
+
+// PipelineView is an overlay listing pipeline runs and their stage progress.
+// It implements Modal directly so it can be opened on the modal stack.
+type PipelineView struct {
+	visible bool
+	loaded  bool
+	runs    []pipeline.Run
+	err     string
+	keys    *KeyMap
+	theme   *Theme
+}
+
+// NewPipelineView creates a hidden pipeline view
+func NewPipelineView(keys *KeyMap) *PipelineView {
+	return &PipelineView{keys: keys, theme: DefaultTheme()}
+}
+
+// SetTheme switches the style profile used to render the view
+func (v *PipelineView) SetTheme(theme *Theme) {
+	v.theme = theme
+}
+
+// Show opens the view; runs are filled in by SetRuns once they are loaded
+func (v *PipelineView) Show() {
+	v.visible = true
+	v.loaded = false
+	v.err = ""
+}
+
+// Hide closes the view
+func (v *PipelineView) Hide() {
+	v.visible = false
+}
+
+// Focused reports whether the view is open
+func (v *PipelineView) Focused() bool {
+	return v.visible
+}
+
+// SetRuns replaces the displayed runs
+func (v *PipelineView) SetRuns(runs []pipeline.Run, err string) {
+	v.runs = runs
+	v.err = err
+	v.loaded = true
+}
+
+// Update closes the view on Esc or the pipelines key
+func (v *PipelineView) Update(msg tea.Msg) tea.Cmd {
+	if keyMsg, ok := msg.(tea.KeyMsg); ok {
+		if key.Matches(keyMsg, v.keys.Escape, v.keys.Pipelines) {
+			v.Hide()
+		}
+	}
+	return nil
+}
+
+// View renders the runs with one line per stage
+func (v *PipelineView) View() string {
+	if !v.visible {
+		return ""
+	}
+
+	t := resolveTheme(v.theme)
+	lines := []string{t.Accent.Render("Pipelines"), ""}
+	switch {
+	case v.err != "":
+		lines = append(lines, t.Error.Render("Error: "+v.err))
+	case !v.loaded:
+		lines = append(lines, t.Muted.Render("Loading pipeline runs..."))
+	case len(v.runs) == 0:
+		lines = append(lines, t.Muted.Render("No pipeline runs. Start one with `uzi pipeline run pipeline.yaml`."))
+	default:
+		for _, run := range v.runs {
+			header := fmt.Sprintf("%s %s  %s", pipelineStatusIcon(run.Status, t), run.ID, run.Status)
+			lines = append(lines, t.Primary.Render(header))
+			for _, stage := range run.Stages {
+				line := fmt.Sprintf("    %s %-12s %d/%d checkpointed", pipelineStatusIcon(stage.Status, t), stage.Name, len(stage.Checkpointed), len(stage.Agents))
+				if len(stage.Agents) > 0 {
+					line += "  " + strings.Join(stage.Agents, ", ")
+				}
+				lines = append(lines, t.Muted.Render(line))
+			}
+			if run.Error != "" {
+				lines = append(lines, t.Error.Render("    "+run.Error))
+			}
+		}
+	}
+	lines = append(lines, "", t.Muted.Render("[ESC] to close"))
+
+	return t.Border.Copy().
+		Width(70).
+		Render(lipgloss.JoinVertical(lipgloss.Left, lines...))
+}
+
+// pipelineStatusIcon returns a compact marker for a run or stage status
+func pipelineStatusIcon(status pipeline.Status, t *Theme) string {
+	if t.Plain {
+		return "[" + string(status) + "]"
+	}
+	switch status {
+	case pipeline.StatusCompleted:
+		return "✓"
+	case pipeline.StatusRunning:
+		return "●"
+	case pipeline.StatusFailed:
+		return "✗"
+	default:
+		return "○"
+	}
+}
@@ -0,0 +1,129 @@
+// Copyright (c) Subtrace, Inc.
+// SPDX-License-Identifier: BSD-3-Clause
+
+package tui
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/nehpz/claudicus/pkg/pipeline"
+	"github.com/nehpz/claudicus/pkg/testutil/cmdmock"
+)
+
+func TestPipelineView_View(t *testing.T) {
+	keys := DefaultKeyMap()
+	view := NewPipelineView(&keys)
+	if view.View() != "" {
+		t.Error("Hidden pipeline view should render nothing")
+	}
+
+	view.Show()
+	if !strings.Contains(view.View(), "Loading pipeline runs") {
+		t.Errorf("Expected loading message, got %q", view.View())
+	}
+
+	view.SetRuns(nil, "")
+	if !strings.Contains(view.View(), "No pipeline runs") {
+		t.Errorf("Expected empty message, got %q", view.View())
+	}
+
+	run := pipeline.NewRun(&pipeline.Definition{
+		Name:   "chain",
+		Stages: []pipeline.Stage{{Name: "plan"}, {Name: "review"}},
+	}, "chain.yaml", time.Unix(100, 0))
+	run.Stages[0].Status = pipeline.StatusRunning
+	run.Stages[0].Agents = []string{"sarah", "john"}
+	run.Stages[0].Checkpointed = []string{"sarah"}
+
+	view.SetRuns([]pipeline.Run{*run}, "")
+	output := view.View()
+	for _, want := range []string{"chain-100", "plan", "1/2 checkpointed", "sarah, john", "review"} {
+		if !strings.Contains(output, want) {
+			t.Errorf("Expected %q in pipeline view, got %q", want, output)
+		}
+	}
+
+	view.SetRuns(nil, "uzi not found")
+	if !strings.Contains(view.View(), "uzi not found") {
+		t.Errorf("Expected error in pipeline view, got %q", view.View())
+	}
+}
+
+func TestPipelineView_Close(t *testing.T) {
+	keys := DefaultKeyMap()
+	for _, msg := range []tea.KeyMsg{
+		{Type: tea.KeyEsc},
+		{Type: tea.KeyRunes, Runes: []rune{'p'}},
+	} {
+		view := NewPipelineView(&keys)
+		view.Show()
+		view.Update(msg)
+		if view.Focused() {
+			t.Errorf("Expected %q to close the pipeline view", msg.String())
+		}
+	}
+
+	view := NewPipelineView(&keys)
+	view.Show()
+	view.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune{'k'}})
+	if !view.Focused() {
+		t.Error("Other keys should not close the pipeline view")
+	}
+}
+
+func TestApp_PipelinesKey(t *testing.T) {
+	app := NewApp(&MockUziInterface{})
+	defer app.Cleanup()
+
+	_, cmd := app.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune{'p'}})
+	if app.modals.Top() != app.pipelineView {
+		t.Fatal("Expected pipeline view on top after 'p'")
+	}
+	if cmd == nil {
+		t.Fatal("Expected a command to load pipeline runs")
+	}
+	msg, ok := cmd().(PipelineRunsMsg)
+	if !ok {
+		t.Fatalf("Expected PipelineRunsMsg, got %T", cmd())
+	}
+
+	app.Update(msg)
+	if !app.pipelineView.loaded {
+		t.Error("Expected pipeline runs to be delivered to the view")
+	}
+
+	// The kill key must not reach the list while the view is open
+	app.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune{'k'}})
+	if app.confirmModal.IsVisible() {
+		t.Error("Expected keys to go to the pipeline view only")
+	}
+}
+
+func TestUziCLI_GetPipelineRuns(t *testing.T) {
+	setupUziTest()
+	cli := NewUziCLI()
+	cmdmock.SetResponseWithArgs("uzi", []string{"pipeline", "ls", "--json"},
+		`[{"id":"chain-100","name":"chain","status":"running","stages":[{"name":"plan","status":"running","agents":["sarah"]}]}]`, "", false)
+
+	runs, err := cli.GetPipelineRuns()
+	if err != nil {
+		t.Fatalf("GetPipelineRuns() error = %v", err)
+	}
+	if len(runs) != 1 || runs[0].ID != "chain-100" || runs[0].Stages[0].Agents[0] != "sarah" {
+		t.Errorf("Unexpected runs: %+v", runs)
+	}
+}
+
+func TestUziCLI_GetPipelineRunsInvalidJSON(t *testing.T) {
+	setupUziTest()
+	cli := NewUziCLI()
+	cmdmock.SetResponseWithArgs("uzi", []string{"pipeline", "ls", "--json"}, "{bad", "", false)
+
+	_, err := cli.GetPipelineRuns()
+	if err == nil || !strings.Contains(err.Error(), "uzi_proxy: GetPipelineRuns") {
+		t.Errorf("Expected wrapped parse error, got %v", err)
+	}
+}
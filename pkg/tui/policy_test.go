@@ -0,0 +1,72 @@
+// Copyright (c) Subtrace, Inc.
+// SPDX-License-Identifier: BSD-3-Clause
+
+package tui
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/nehpz/claudicus/pkg/config"
+	"github.com/nehpz/claudicus/pkg/state"
+)
+
+// policyUziMock refuses broadcasts as a forbidBroadcastPatterns policy would
+type policyUziMock struct {
+	MockUziInterface
+}
+
+func (m *policyUziMock) RunBroadcast(message string) error {
+	cfg := &config.Config{Policy: &config.PolicyConfig{ForbidBroadcastPatterns: []string{"rm -rf"}}}
+	return cfg.CheckBroadcast(message)
+}
+
+func TestApp_BroadcastPolicyViolation(t *testing.T) {
+	app := NewApp(&policyUziMock{})
+	defer app.Cleanup()
+
+	msg, ok := app.broadcastCmd("please rm -rf dist")().(PolicyViolationMsg)
+	if !ok || msg.Violation.Rule != "forbidBroadcastPatterns" {
+		t.Fatalf("Expected a PolicyViolationMsg, got %+v", msg)
+	}
+	app.Update(msg)
+	if !app.noticeIsError || !strings.Contains(app.notice, "broadcast blocked by policy forbidBroadcastPatterns") {
+		t.Errorf("Expected the violation on the status line, got %q", app.notice)
+	}
+
+	if _, ok := app.broadcastCmd("run the tests")().(RefreshMsg); !ok {
+		t.Error("Expected an allowed broadcast to refresh the list")
+	}
+}
+
+func TestUziCLI_PolicyChecks(t *testing.T) {
+	setupUziTest()
+	cli := NewUziCLI()
+	cli.stateManager = &mockStateManagerForTest{
+		statePath: createTempStateFile(t, map[string]state.AgentState{
+			"agent-app-abc123-sarah": {WorktreePath: t.TempDir()},
+			"agent-app-abc123-john":  {WorktreePath: t.TempDir()},
+		}),
+		activeSessions: []string{"agent-app-abc123-sarah", "agent-app-abc123-john"},
+	}
+	cli.SetConfig(&config.Config{Policy: &config.PolicyConfig{
+		ForbidBroadcastPatterns: []string{`git push .*--force`},
+		MaxAgents:               2,
+	}})
+
+	var violation *config.PolicyViolation
+	if err := cli.RunBroadcast("git push origin main --force"); !errors.As(err, &violation) || violation.Operation != "broadcast" {
+		t.Errorf("Expected RunBroadcast to be refused, got %v", err)
+	}
+	if err := cli.RunChannelBroadcast("frontend", "git push origin main --force"); !errors.As(err, &violation) {
+		t.Errorf("Expected RunChannelBroadcast to be refused, got %v", err)
+	}
+	if _, err := cli.SpawnAgent("add a login page", "claude"); !errors.As(err, &violation) || violation.Rule != "maxAgents" {
+		t.Errorf("Expected SpawnAgent to be refused with 2 of 2 agents running, got %v", err)
+	}
+	if err := cli.SpawnAgentInteractive(context.Background(), "claude:1:add a login page", nil); !errors.As(err, &violation) {
+		t.Errorf("Expected SpawnAgentInteractive to be refused, got %v", err)
+	}
+}
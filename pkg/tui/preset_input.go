@@ -0,0 +1,93 @@
+// Copyright (c) Subtrace, Inc.
+// SPDX-License-Identifier: BSD-3-Clause
+
+package tui
+
+import (
+	"github.com/charmbracelet/bubbles/textinput"
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// PresetInputModel handles the prompt for a filter preset's name
+type PresetInputModel struct {
+	textInput textinput.Model
+	active    bool
+	width     int
+	theme     *Theme
+}
+
+// NewPresetInputModel creates a new preset name input model
+func NewPresetInputModel() *PresetInputModel {
+	ti := textinput.New()
+	ti.Placeholder = "e.g. stuck on project-x"
+	ti.CharLimit = 64
+	ti.Width = 50
+
+	return &PresetInputModel{
+		textInput: ti,
+		active:    false,
+		width:     50,
+		theme:     DefaultTheme(),
+	}
+}
+
+// SetTheme switches the style profile used to render the preset input
+func (m *PresetInputModel) SetTheme(theme *Theme) {
+	m.theme = theme
+}
+
+// SetActive activates the preset input with an empty name, or deactivates it
+func (m *PresetInputModel) SetActive(active bool) {
+	m.active = active
+	if active {
+		m.textInput.Focus()
+		m.textInput.SetValue("")
+	} else {
+		m.textInput.Blur()
+	}
+}
+
+// IsActive returns whether the preset input is currently active
+func (m *PresetInputModel) IsActive() bool {
+	return m.active
+}
+
+// Value returns the current preset name
+func (m *PresetInputModel) Value() string {
+	return m.textInput.Value()
+}
+
+// SetWidth updates the width of the input
+func (m *PresetInputModel) SetWidth(width int) {
+	m.width = width
+	m.textInput.Width = width - 20 // Account for prompt text and padding
+}
+
+// Update handles messages for the preset input
+func (m *PresetInputModel) Update(msg tea.Msg) (*PresetInputModel, tea.Cmd) {
+	if !m.active {
+		return m, nil
+	}
+
+	var cmd tea.Cmd
+	m.textInput, cmd = m.textInput.Update(msg)
+	return m, cmd
+}
+
+// View renders the preset name prompt
+func (m *PresetInputModel) View() string {
+	if !m.active {
+		return ""
+	}
+
+	t := resolveTheme(m.theme)
+	promptStyle := t.Accent.Copy().Bold(true)
+	inputStyle := t.Border.Copy().
+		Width(m.width-2).
+		Padding(0, 1)
+
+	prompt := promptStyle.Render("Save preset as: ")
+	input := m.textInput.View()
+
+	return inputStyle.Render(prompt + input)
+}
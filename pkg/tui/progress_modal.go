@@ -1,6 +1,7 @@
 package tui
 
 import (
+	"fmt"
 	"strings"
 	"time"
 
@@ -14,21 +15,62 @@ type ProgressStep int
 const (
 	ProgressStepSetupWorktree ProgressStep = iota
 	ProgressStepCreateTmux
+	ProgressStepInstallDeps
+	ProgressStepDevServer
 	ProgressStepStartAgent
 	ProgressStepComplete
 )
 
+// SpawnStepStatus is what a SpawnEvent says about its step
+type SpawnStepStatus int
+
+const (
+	// SpawnStepStarted means the step is under way
+	SpawnStepStarted SpawnStepStatus = iota
+	// SpawnStepDone means the step finished, possibly with a Note
+	SpawnStepDone
+	// SpawnStepSkipped means the step does not apply, such as installing
+	// dependencies without a setupCommand
+	SpawnStepSkipped
+)
+
+// SpawnEvent is the telemetry of an agent spawn: a step that started,
+// finished, or was skipped for one of the agents being created
+type SpawnEvent struct {
+	Step   ProgressStep
+	Status SpawnStepStatus
+	Agent  int    // which of the agents is being created, from 1
+	Agents int    // how many agents the spawn creates
+	Note   string // what a finished step found, such as a dev server that never answered
+	At     time.Time
+}
+
+// stepProgress is how far a step of the current agent got
+type stepProgress struct {
+	started  time.Time
+	finished time.Time
+	skipped  bool
+	note     string
+}
+
 // ProgressModal shows the progress of agent creation
 type ProgressModal struct {
 	active      bool
 	currentStep ProgressStep
 	steps       []string
+	progress    []stepProgress
+	agent       int
+	agents      int
+	cancelling  bool
+	cancelled   bool
 	width       int
 	height      int
 	spinner     []string
 	spinnerIdx  int
 	message     string
 	error       string
+	theme       *Theme
+	now         func() time.Time
 }
 
 // NewProgressModal creates a new progress modal
@@ -36,21 +78,34 @@ func NewProgressModal() ProgressModal {
 	return ProgressModal{
 		active: false,
 		steps: []string{
-			"Setting up git worktree...",
-			"Creating tmux session...",
-			"Starting agent...",
+			"Setting up git worktree",
+			"Creating tmux session",
+			"Installing dependencies",
+			"Starting dev server",
+			"Starting agent",
 			"Complete!",
 		},
+		progress:   make([]stepProgress, ProgressStepComplete),
 		spinner:    []string{"|", "/", "-", "\\"},
 		spinnerIdx: 0,
+		theme:      DefaultTheme(),
+		now:        time.Now,
 	}
 }
 
+// SetTheme switches the style profile used to render the modal
+func (m *ProgressModal) SetTheme(theme *Theme) {
+	m.theme = theme
+}
+
 // SetActive sets the modal's active state
 func (m *ProgressModal) SetActive(active bool) {
 	m.active = active
 	if active {
 		m.currentStep = ProgressStepSetupWorktree
+		m.progress = make([]stepProgress, ProgressStepComplete)
+		m.agent, m.agents = 0, 0
+		m.cancelling, m.cancelled = false, false
 		m.error = ""
 		m.message = ""
 	}
@@ -74,6 +129,50 @@ func (m *ProgressModal) NextStep() {
 	}
 }
 
+// Apply moves the modal along with a spawn event. The steps start over for
+// each agent of a spawn that creates several.
+func (m *ProgressModal) Apply(event SpawnEvent) {
+	if event.Step >= ProgressStepComplete {
+		m.currentStep = ProgressStepComplete
+		return
+	}
+	if event.Agent != m.agent {
+		m.agent, m.agents = event.Agent, event.Agents
+		m.progress = make([]stepProgress, ProgressStepComplete)
+	}
+	step := &m.progress[event.Step]
+	switch event.Status {
+	case SpawnStepStarted:
+		step.started = event.At
+		m.currentStep = event.Step
+	case SpawnStepDone:
+		if step.started.IsZero() {
+			step.started = event.At
+		}
+		step.finished = event.At
+		step.note = event.Note
+		m.currentStep = event.Step + 1
+	case SpawnStepSkipped:
+		step.skipped = true
+		m.currentStep = event.Step + 1
+	}
+}
+
+// Complete marks the spawn as finished
+func (m *ProgressModal) Complete() {
+	m.currentStep = ProgressStepComplete
+}
+
+// Cancelling reports whether the user asked to cancel the spawn
+func (m *ProgressModal) Cancelling() bool {
+	return m.cancelling
+}
+
+// SetCancelled shows that the spawn stopped because it was cancelled
+func (m *ProgressModal) SetCancelled() {
+	m.cancelled = true
+}
+
 // SetError sets an error message and stops progress
 func (m *ProgressModal) SetError(err string) {
 	m.error = err
@@ -84,6 +183,11 @@ func (m *ProgressModal) SetMessage(msg string) {
 	m.message = msg
 }
 
+// finished reports whether the spawn succeeded, failed or was cancelled
+func (m *ProgressModal) finished() bool {
+	return m.currentStep == ProgressStepComplete || m.error != "" || m.cancelled
+}
+
 // Update handles progress modal updates
 func (m ProgressModal) Update(msg tea.Msg) (ProgressModal, tea.Cmd) {
 	if !m.active {
@@ -94,10 +198,15 @@ func (m ProgressModal) Update(msg tea.Msg) (ProgressModal, tea.Cmd) {
 	case tea.KeyMsg:
 		switch msg.String() {
 		case "esc", "q":
-			if m.currentStep == ProgressStepComplete || m.error != "" {
+			if m.finished() {
 				m.active = false
 				return m, nil
 			}
+			// Esc cancels a spawn under way; the steps done so far are rolled back
+			if msg.String() == "esc" && !m.cancelling {
+				m.cancelling = true
+				return m, func() tea.Msg { return SpawnCancelMsg{} }
+			}
 		}
 	case SpinnerTickMsg:
 		m.spinnerIdx = (m.spinnerIdx + 1) % len(m.spinner)
@@ -107,45 +216,82 @@ func (m ProgressModal) Update(msg tea.Msg) (ProgressModal, tea.Cmd) {
 	return m, nil
 }
 
+// elapsed formats how long a step took, or has taken so far
+func (m ProgressModal) elapsed(step stepProgress) string {
+	end := step.finished
+	if end.IsZero() {
+		end = m.now()
+	}
+	if step.started.IsZero() || end.Before(step.started) {
+		return ""
+	}
+	return fmt.Sprintf(" (%.1fs)", end.Sub(step.started).Seconds())
+}
+
 // View renders the progress modal
 func (m ProgressModal) View() string {
 	if !m.active {
 		return ""
 	}
 
+	t := resolveTheme(m.theme)
 	style := lipgloss.NewStyle().
 		Border(lipgloss.RoundedBorder()).
 		BorderForeground(ClaudeSquadAccent).
 		Padding(1, 2).
 		Width(m.width / 2).
 		Align(lipgloss.Center)
+	if t.Plain {
+		style = t.Border
+	}
 
-	title := ClaudeSquadPrimaryStyle.Bold(true).Render("Creating Agent")
+	heading := "Creating Agent"
+	if m.agents > 1 {
+		heading = fmt.Sprintf("Creating Agent %d of %d", m.agent, m.agents)
+	}
+	title := t.Primary.Bold(true).Render(heading)
 	var content strings.Builder
 	content.WriteString(title)
 	content.WriteString("\n\n")
 
+	if m.cancelled {
+		content.WriteString(t.Muted.Render("Cancelled; the partially created agent was rolled back"))
+		content.WriteString("\n\n")
+		content.WriteString(t.Muted.Render("Press Esc to close"))
+		return style.Render(content.String())
+	}
+
 	// Show error if present
 	if m.error != "" {
-		content.WriteString(ErrorStyle.Render("Error: " + m.error))
+		content.WriteString(t.Error.Render("Error: " + m.error))
 		content.WriteString("\n\n")
-		content.WriteString(ClaudeSquadMutedStyle.Render("Press Esc to close"))
+		content.WriteString(t.Muted.Render("Press Esc to close"))
 		return style.Render(content.String())
 	}
 
 	// Show progress steps
 	for i, step := range m.steps {
 		stepNum := ProgressStep(i)
-		if stepNum < m.currentStep {
+		if stepNum == ProgressStepComplete {
+			continue
+		}
+		progress := m.progress[stepNum]
+		if progress.skipped {
+			content.WriteString(t.Muted.Render(t.Glyph("– ", "skipped: ") + step))
+		} else if stepNum < m.currentStep {
 			// Completed step
-			content.WriteString(ClaudeSquadAccentStyle.Render("✓ " + step))
+			content.WriteString(t.Accent.Render(t.Glyph("✓ ", "done: ") + step + m.elapsed(progress)))
+			if progress.note != "" {
+				content.WriteString("\n")
+				content.WriteString(t.Warning.Render("  " + progress.note))
+			}
 		} else if stepNum == m.currentStep {
 			// Current step with spinner
 			spinner := m.spinner[m.spinnerIdx]
-			content.WriteString(ClaudeSquadPrimaryStyle.Render(spinner + " " + step))
+			content.WriteString(t.Primary.Render(t.Glyph(spinner+" ", "in progress: ") + step + "..." + m.elapsed(progress)))
 		} else {
 			// Future step
-			content.WriteString(ClaudeSquadMutedStyle.Render("• " + step))
+			content.WriteString(t.Muted.Render(t.Glyph("• ", "pending: ") + step))
 		}
 		content.WriteString("\n")
 	}
@@ -153,16 +299,22 @@ func (m ProgressModal) View() string {
 	// Show custom message if present
 	if m.message != "" {
 		content.WriteString("\n")
-		content.WriteString(ClaudeSquadMutedStyle.Render(m.message))
+		content.WriteString(t.Muted.Render(m.message))
 		content.WriteString("\n")
 	}
 
-	// Show completion message
-	if m.currentStep == ProgressStepComplete {
+	switch {
+	case m.currentStep == ProgressStepComplete:
+		content.WriteString("\n")
+		content.WriteString(t.Accent.Render("Agent created successfully!"))
 		content.WriteString("\n")
-		content.WriteString(ClaudeSquadAccentStyle.Render("Agent created successfully!"))
+		content.WriteString(t.Muted.Render("Press Esc to close"))
+	case m.cancelling:
 		content.WriteString("\n")
-		content.WriteString(ClaudeSquadMutedStyle.Render("Press Esc to close"))
+		content.WriteString(t.Muted.Render("Cancelling and rolling back..."))
+	default:
+		content.WriteString("\n")
+		content.WriteString(t.Muted.Render("Press Esc to cancel"))
 	}
 
 	return style.Render(content.String())
@@ -178,12 +330,16 @@ func spinnerTick() tea.Cmd {
 	})
 }
 
-// ProgressStepMsg is sent when a progress step is completed
-type ProgressStepMsg struct {
-	Step    ProgressStep
-	Message string
+// SpawnProgressMsg carries a spawn event to the progress modal. Events is
+// where the next one comes from.
+type SpawnProgressMsg struct {
+	Event  SpawnEvent
+	Events <-chan SpawnEvent
 }
 
+// SpawnCancelMsg asks for the spawn under way to be cancelled
+type SpawnCancelMsg struct{}
+
 // ProgressErrorMsg is sent when an error occurs during progress
 type ProgressErrorMsg struct {
 	Error string
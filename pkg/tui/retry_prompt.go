@@ -0,0 +1,111 @@
+// Copyright (c) Subtrace, Inc.
+// SPDX-License-Identifier: BSD-3-Clause
+
+package tui
+
+import (
+	"github.com/charmbracelet/bubbles/textarea"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+)
+
+// RetryPromptModel edits the prompt of a stuck or failed agent before it is
+// respawned on a fresh worktree
+type RetryPromptModel struct {
+	textarea    textarea.Model
+	active      bool
+	sessionName string
+	width       int
+	theme       *Theme
+}
+
+// NewRetryPromptModel creates a new retry prompt editor
+func NewRetryPromptModel() *RetryPromptModel {
+	ta := textarea.New()
+	ta.Placeholder = "Prompt for the new agent"
+	ta.ShowLineNumbers = false
+	ta.CharLimit = 4000
+	ta.SetWidth(66)
+	ta.SetHeight(6)
+
+	return &RetryPromptModel{
+		textarea: ta,
+		active:   false,
+		width:    70,
+		theme:    DefaultTheme(),
+	}
+}
+
+// SetTheme switches the style profile used to render the editor
+func (m *RetryPromptModel) SetTheme(theme *Theme) {
+	m.theme = theme
+}
+
+// Edit loads a session's original prompt for editing
+func (m *RetryPromptModel) Edit(sessionName, prompt string) {
+	m.sessionName = sessionName
+	m.textarea.SetValue(prompt)
+}
+
+// SetActive activates or deactivates the editor, keeping the loaded prompt
+func (m *RetryPromptModel) SetActive(active bool) {
+	m.active = active
+	if active {
+		m.textarea.Focus()
+	} else {
+		m.textarea.Blur()
+	}
+}
+
+// IsActive returns whether the editor is currently active
+func (m *RetryPromptModel) IsActive() bool {
+	return m.active
+}
+
+// SessionName returns the session being retried
+func (m *RetryPromptModel) SessionName() string {
+	return m.sessionName
+}
+
+// Value returns the edited prompt
+func (m *RetryPromptModel) Value() string {
+	return m.textarea.Value()
+}
+
+// SetWidth fits the editor into the terminal width, up to 70 columns
+func (m *RetryPromptModel) SetWidth(width int) {
+	if width <= 0 {
+		return
+	}
+	m.width = min(width, 70)
+	m.textarea.SetWidth(m.width - 4) // Account for border and padding
+}
+
+// Update handles messages for the editor
+func (m *RetryPromptModel) Update(msg tea.Msg) (*RetryPromptModel, tea.Cmd) {
+	if !m.active {
+		return m, nil
+	}
+
+	var cmd tea.Cmd
+	m.textarea, cmd = m.textarea.Update(msg)
+	return m, cmd
+}
+
+// View renders the editor with the agent being retried
+func (m *RetryPromptModel) View() string {
+	if !m.active {
+		return ""
+	}
+
+	t := resolveTheme(m.theme)
+	title := t.Accent.Copy().Bold(true).Render("Retry " + extractAgentName(m.sessionName))
+	explanation := t.Muted.Render("Kills the agent and starts a new one on a fresh worktree")
+	hint := t.Muted.Render("ctrl+s respawn • esc cancel")
+
+	content := lipgloss.JoinVertical(lipgloss.Left, title, explanation, "", m.textarea.View(), "", hint)
+	return t.Border.Copy().
+		Width(m.width-2).
+		Padding(0, 1).
+		Render(content)
+}
@@ -0,0 +1,80 @@
+// Copyright (c) Subtrace, Inc.
+// SPDX-License-Identifier: BSD-3-Clause
+
+package tui
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+func TestRetryAgentHandling(t *testing.T) {
+	mockUzi := &MockUziInterface{}
+	app := NewApp(mockUzi)
+	defer app.Cleanup()
+
+	stale := time.Now().Add(-10 * time.Minute).Format(time.RFC3339)
+	app.list.LoadSessions([]SessionInfo{
+		{Name: "agent-proj-abc123-sarah", AgentName: "sarah", Status: "ready", Prompt: "fix login", UpdatedAt: stale},
+	})
+
+	app.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune{'e'}})
+	if app.modals.Top() != app.retryOverlay {
+		t.Fatal("Expected the retry editor to open for a stuck agent")
+	}
+	if app.retryPrompt.Value() != "fix login" || app.retryPrompt.SessionName() != "agent-proj-abc123-sarah" {
+		t.Errorf("Expected the original prompt loaded, got %q for %q", app.retryPrompt.Value(), app.retryPrompt.SessionName())
+	}
+
+	app.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune(" without the cache")})
+	_, cmd := app.Update(tea.KeyMsg{Type: tea.KeyCtrlS})
+	if app.modals.Active() {
+		t.Error("Expected the editor to close once the retry starts")
+	}
+	if cmd == nil {
+		t.Fatal("Expected a command waiting for the respawn")
+	}
+	if _, ok := cmd().(RefreshMsg); !ok {
+		t.Error("Expected a refresh once the respawn finished")
+	}
+	if got := mockUzi.respawned["agent-proj-abc123-sarah"]; got != "fix login without the cache" {
+		t.Errorf("Expected the edited prompt respawned, got %q", got)
+	}
+}
+
+func TestRetryAgentHandlingWorking(t *testing.T) {
+	mockUzi := &MockUziInterface{}
+	app := NewApp(mockUzi)
+	defer app.Cleanup()
+
+	app.list.LoadSessions([]SessionInfo{
+		{Name: "agent-proj-abc123-sarah", AgentName: "sarah", Status: "running", Prompt: "fix login", UpdatedAt: time.Now().Format(time.RFC3339)},
+	})
+
+	app.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune{'e'}})
+	if app.modals.Active() {
+		t.Error("Expected no retry editor for a working agent")
+	}
+	if !app.noticeIsError || !strings.Contains(app.notice, "still working") {
+		t.Errorf("Expected a notice explaining why, got %q", app.notice)
+	}
+}
+
+func TestRetryAgentHandlingCancel(t *testing.T) {
+	mockUzi := &MockUziInterface{}
+	app := NewApp(mockUzi)
+	defer app.Cleanup()
+
+	app.list.LoadSessions([]SessionInfo{
+		{Name: "agent-proj-abc123-sarah", AgentName: "sarah", Status: "ready", Prompt: "fix login"},
+	})
+
+	app.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune{'e'}})
+	app.Update(tea.KeyMsg{Type: tea.KeyEsc})
+	if app.modals.Active() || len(mockUzi.respawned) != 0 {
+		t.Errorf("Expected Esc to cancel without respawning, got %v", mockUzi.respawned)
+	}
+}
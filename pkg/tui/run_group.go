@@ -0,0 +1,145 @@
+package tui
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/charmbracelet/bubbles/list"
+)
+
+// RunHeaderItem heads the rows of the sessions spawned in one run with
+// uzi prompt --run-name. Collapsed runs show only their header.
+type RunHeaderItem struct {
+	run       string
+	sessions  []SessionInfo // Sessions of the run the filter and search let through
+	collapsed bool
+	theme     *Theme
+}
+
+// Title implements list.Item interface for run headers
+func (h RunHeaderItem) Title() string {
+	t := resolveTheme(h.theme)
+	count := fmt.Sprintf("(%d %s)", len(h.sessions), pluralize(len(h.sessions), "agent", "agents"))
+	if t.Plain {
+		title := "run " + h.run + " " + count
+		if h.collapsed {
+			title += " collapsed"
+		}
+		return title
+	}
+	arrow := "▾"
+	if h.collapsed {
+		arrow = "▸"
+	}
+	return t.Primary.Render(arrow+" run "+h.run) + " " + t.Muted.Render(count)
+}
+
+// Description implements list.Item interface for run headers: how many of
+// the run's agents are in each status and their combined diff
+func (h RunHeaderItem) Description() string {
+	t := resolveTheme(h.theme)
+	counts := map[string]int{}
+	insertions, deletions := 0, 0
+	for _, session := range h.sessions {
+		counts[session.Status]++
+		insertions += session.Insertions
+		deletions += session.Deletions
+	}
+	statuses := make([]string, 0, len(counts))
+	for status := range counts {
+		statuses = append(statuses, status)
+	}
+	sort.Strings(statuses)
+	parts := make([]string, 0, len(statuses))
+	for _, status := range statuses {
+		parts = append(parts, fmt.Sprintf("%d %s", counts[status], status))
+	}
+	return t.Muted.Render(strings.Join(parts, ", ")) + t.Separator() + t.Muted.Render(fmt.Sprintf("+%d -%d", insertions, deletions))
+}
+
+// FilterValue implements list.Item interface
+func (h RunHeaderItem) FilterValue() string {
+	return h.run
+}
+
+// pluralize picks the word for a count
+func pluralize(n int, one, many string) string {
+	if n == 1 {
+		return one
+	}
+	return many
+}
+
+// groupRuns gathers the rows of each run's sessions under a header at the
+// row of the run's first session, leaving the rows of sessions in no run and
+// of pinned sessions where they are. Collapsed runs keep only the header.
+func (m *ListModel) groupRuns(items []list.Item) []list.Item {
+	members := map[string][]SessionListItem{}
+	for _, item := range items {
+		if sessionItem, ok := item.(SessionListItem); ok && sessionItem.session.Run != "" && !sessionItem.pinned {
+			members[sessionItem.session.Run] = append(members[sessionItem.session.Run], sessionItem)
+		}
+	}
+	if len(members) == 0 {
+		return items
+	}
+
+	grouped := make([]list.Item, 0, len(items)+len(members))
+	for _, item := range items {
+		sessionItem, ok := item.(SessionListItem)
+		if !ok || sessionItem.session.Run == "" || sessionItem.pinned {
+			grouped = append(grouped, item)
+			continue
+		}
+		run := sessionItem.session.Run
+		group, pending := members[run]
+		if !pending {
+			continue // Already added under the run's header
+		}
+		delete(members, run)
+
+		header := RunHeaderItem{run: run, collapsed: m.collapsedRuns[run], theme: m.theme}
+		for _, member := range group {
+			header.sessions = append(header.sessions, member.session)
+		}
+		grouped = append(grouped, header)
+		if header.collapsed {
+			continue
+		}
+		for _, member := range group {
+			grouped = append(grouped, member)
+		}
+	}
+	return grouped
+}
+
+// SelectedRun returns the run whose header is selected, or ""
+func (m ListModel) SelectedRun() string {
+	if header, ok := m.list.SelectedItem().(RunHeaderItem); ok {
+		return header.run
+	}
+	return ""
+}
+
+// ToggleRun collapses or expands the rows of a run, keeps its header
+// selected, and reports whether the run is now collapsed
+func (m *ListModel) ToggleRun(run string) bool {
+	if m.collapsedRuns == nil {
+		m.collapsedRuns = map[string]bool{}
+	}
+	if m.collapsedRuns[run] {
+		delete(m.collapsedRuns, run)
+	} else {
+		m.collapsedRuns[run] = true
+	}
+	m.applyFilter()
+
+	for i, item := range m.list.Items() {
+		if header, ok := item.(RunHeaderItem); ok && header.run == run {
+			m.list.Select(i)
+			break
+		}
+	}
+	return m.collapsedRuns[run]
+}
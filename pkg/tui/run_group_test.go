@@ -0,0 +1,97 @@
+package tui
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// rowNames names the list rows: agents by name, run headers as "run:<name>"
+func rowNames(m *ListModel) []string {
+	names := []string{}
+	for _, item := range m.Items() {
+		switch item := item.(type) {
+		case SessionListItem:
+			names = append(names, item.session.AgentName)
+		case RunHeaderItem:
+			names = append(names, "run:"+item.run)
+		}
+	}
+	return names
+}
+
+func runTestSessions() []SessionInfo {
+	sessions := pinTestSessions()
+	sessions[0].Run = "auth-refactor"
+	sessions[0].Insertions = 10
+	sessions[2].Run = "auth-refactor"
+	sessions[2].Insertions = 5
+	return sessions
+}
+
+func TestListGroupsRuns(t *testing.T) {
+	m := NewListModel(80, 24)
+	m.LoadSessions(runTestSessions())
+	if got := rowNames(&m); !reflect.DeepEqual(got, []string{"run:auth-refactor", "john", "sarah", "mary"}) {
+		t.Fatalf("Expected the run's sessions under its header, got %v", got)
+	}
+
+	header := m.Items()[0].(RunHeaderItem)
+	if title := header.Title(); !strings.Contains(title, "run auth-refactor") || !strings.Contains(title, "(2 agents)") {
+		t.Errorf("Unexpected header title %q", title)
+	}
+	if desc := header.Description(); !strings.Contains(desc, "1 ready, 1 running") || !strings.Contains(desc, "+15 -0") {
+		t.Errorf("Expected the run's statuses and diff, got %q", desc)
+	}
+
+	// Pinned sessions stay at the top, outside their run
+	m.TogglePin("agent-proj-abc123-sarah")
+	if got := rowNames(&m); !reflect.DeepEqual(got, []string{"sarah", "run:auth-refactor", "john", "mary"}) {
+		t.Errorf("Expected sarah pinned outside the run, got %v", got)
+	}
+}
+
+func TestListToggleRun(t *testing.T) {
+	m := NewListModel(80, 24)
+	m.LoadSessions(runTestSessions())
+	m.list.Select(0)
+	if m.SelectedRun() != "auth-refactor" || m.SelectedSession() != nil {
+		t.Fatalf("Expected the run header selected, got run %q", m.SelectedRun())
+	}
+
+	if !m.ToggleRun("auth-refactor") {
+		t.Error("Expected the run collapsed")
+	}
+	if got := rowNames(&m); !reflect.DeepEqual(got, []string{"run:auth-refactor", "mary"}) {
+		t.Errorf("Expected only the header of a collapsed run, got %v", got)
+	}
+	if m.SelectedRun() != "auth-refactor" {
+		t.Error("Expected the header kept selected")
+	}
+
+	// A refresh keeps the run collapsed and its header selected
+	m.LoadSessions(runTestSessions())
+	if got := rowNames(&m); len(got) != 2 || m.SelectedRun() != "auth-refactor" {
+		t.Errorf("Expected the run still collapsed and selected, got %v", got)
+	}
+
+	if m.ToggleRun("auth-refactor") {
+		t.Error("Expected the run expanded")
+	}
+	if got := rowNames(&m); len(got) != 4 {
+		t.Errorf("Expected the run's sessions back, got %v", got)
+	}
+}
+
+func TestAppEnterTogglesRun(t *testing.T) {
+	app := NewApp(&MockUziInterface{})
+	app.list.LoadSessions(runTestSessions())
+	app.list.list.Select(0)
+
+	app.Update(tea.KeyMsg{Type: tea.KeyEnter})
+	if got := rowNames(app.list); !reflect.DeepEqual(got, []string{"run:auth-refactor", "mary"}) {
+		t.Errorf("Expected enter on the header to collapse the run, got %v", got)
+	}
+}
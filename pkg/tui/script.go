@@ -0,0 +1,256 @@
+package tui
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// A TUI script drives the App without a terminal, one action per line:
+//
+//	# comments and blank lines are skipped
+//	resize 100 30        # the terminal size, 120x40 unless resized
+//	key j j enter        # press keys by name: enter, esc, up, ctrl+p, alt+x, space, q
+//	type fix the tests   # type text into the open prompt, one key per character
+//	wait 500ms           # let sessions load and background work finish
+//	snapshot list        # print the rendered frame, under a label
+//
+// Keys are sent in order and each snapshot is taken once every key before it
+// has been handled. Sessions and diffs load in the background, so scripts wait
+// for them before taking snapshots. The final frame is always printed.
+
+// Script actions
+const (
+	ScriptKey      = "key"
+	ScriptType     = "type"
+	ScriptResize   = "resize"
+	ScriptWait     = "wait"
+	ScriptSnapshot = "snapshot"
+)
+
+// Default terminal size of a script
+const (
+	DefaultScriptWidth  = 120
+	DefaultScriptHeight = 40
+)
+
+// ScriptStep is one action of a TUI script
+type ScriptStep struct {
+	Line   int
+	Action string
+	Msgs   []tea.Msg     // sent to the App for key, type and resize
+	Wait   time.Duration // for wait
+	Label  string        // for snapshot
+}
+
+// scriptKeys maps key names, as tea.KeyMsg.String() spells them, to key types
+var scriptKeys = func() map[string]tea.KeyType {
+	keys := map[string]tea.KeyType{"space": tea.KeySpace}
+	for k := tea.KeyF20; k <= tea.KeyCtrlQuestionMark; k++ {
+		name := k.String()
+		if name == "" || k == tea.KeyRunes || k == tea.KeySpace {
+			continue
+		}
+		if _, ok := keys[name]; !ok {
+			keys[name] = k
+		}
+	}
+	return keys
+}()
+
+// ParseKey returns the key event for a key name such as "enter", "ctrl+c",
+// "alt+x" or a single character
+func ParseKey(name string) (tea.KeyMsg, error) {
+	if keyType, ok := scriptKeys[name]; ok {
+		return tea.KeyMsg{Type: keyType}, nil
+	}
+	if runes := []rune(name); len(runes) == 1 {
+		return tea.KeyMsg{Type: tea.KeyRunes, Runes: runes}, nil
+	}
+	if rest, ok := strings.CutPrefix(name, "alt+"); ok && rest != "" {
+		key, err := ParseKey(rest)
+		if err != nil {
+			return tea.KeyMsg{}, err
+		}
+		key.Alt = true
+		return key, nil
+	}
+	return tea.KeyMsg{}, fmt.Errorf("unknown key %q", name)
+}
+
+// ParseScript reads a TUI script
+func ParseScript(r io.Reader) ([]ScriptStep, error) {
+	var steps []ScriptStep
+	scanner := bufio.NewScanner(r)
+	for lineNo := 1; scanner.Scan(); lineNo++ {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		action, rest, _ := strings.Cut(line, " ")
+		step, err := parseScriptStep(action, strings.TrimSpace(rest))
+		if err != nil {
+			return nil, fmt.Errorf("line %d: %w", lineNo, err)
+		}
+		step.Line = lineNo
+		steps = append(steps, step)
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+	return steps, nil
+}
+
+// parseScriptStep parses the arguments of one script action
+func parseScriptStep(action, args string) (ScriptStep, error) {
+	step := ScriptStep{Action: action}
+	switch action {
+	case ScriptKey:
+		if args == "" {
+			return step, fmt.Errorf("key needs at least one key name")
+		}
+		for _, name := range strings.Fields(args) {
+			key, err := ParseKey(name)
+			if err != nil {
+				return step, err
+			}
+			step.Msgs = append(step.Msgs, key)
+		}
+	case ScriptType:
+		if args == "" {
+			return step, fmt.Errorf("type needs text")
+		}
+		for _, r := range args {
+			if r == ' ' {
+				step.Msgs = append(step.Msgs, tea.KeyMsg{Type: tea.KeySpace, Runes: []rune{r}})
+				continue
+			}
+			step.Msgs = append(step.Msgs, tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune{r}})
+		}
+	case ScriptResize:
+		fields := strings.Fields(args)
+		if len(fields) != 2 {
+			return step, fmt.Errorf("resize needs a width and a height")
+		}
+		width, err := strconv.Atoi(fields[0])
+		if err != nil || width <= 0 {
+			return step, fmt.Errorf("invalid width %q", fields[0])
+		}
+		height, err := strconv.Atoi(fields[1])
+		if err != nil || height <= 0 {
+			return step, fmt.Errorf("invalid height %q", fields[1])
+		}
+		step.Msgs = []tea.Msg{tea.WindowSizeMsg{Width: width, Height: height}}
+	case ScriptWait:
+		wait, err := time.ParseDuration(args)
+		if err != nil || wait < 0 {
+			return step, fmt.Errorf("invalid wait %q (e.g. 500ms)", args)
+		}
+		step.Wait = wait
+	case ScriptSnapshot:
+		step.Label = args
+	default:
+		return step, fmt.Errorf("unknown action %q (key, type, resize, wait or snapshot)", action)
+	}
+	return step, nil
+}
+
+// snapshotMsg asks the script model for the frame it renders now
+type snapshotMsg struct {
+	frame chan<- string
+}
+
+// scriptModel wraps the model a script drives, rendering snapshots inside the
+// program's event loop so they never race with Update
+type scriptModel struct {
+	model tea.Model
+}
+
+func (s *scriptModel) Init() tea.Cmd {
+	return s.model.Init()
+}
+
+func (s *scriptModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
+	if snapshot, ok := msg.(snapshotMsg); ok {
+		snapshot.frame <- s.model.View()
+		return s, nil
+	}
+	var cmd tea.Cmd
+	s.model, cmd = s.model.Update(msg)
+	return s, cmd
+}
+
+func (s *scriptModel) View() string {
+	return s.model.View()
+}
+
+// RunScript runs model headless at the given size, feeds it the steps and
+// writes every snapshot and the final frame to out, each under a
+// "--- label ---" line. The script stops early if the model quits.
+func RunScript(model tea.Model, steps []ScriptStep, out io.Writer, width, height int) error {
+	wrapped := &scriptModel{model: model}
+	program := tea.NewProgram(wrapped,
+		tea.WithInput(nil),
+		tea.WithOutput(io.Discard),
+		tea.WithoutSignalHandler(),
+	)
+
+	done := make(chan struct{})
+	finished := make(chan struct{})
+	go func() {
+		defer close(finished)
+		snapshot := func(label string) bool {
+			frame := make(chan string, 1)
+			program.Send(snapshotMsg{frame: frame})
+			select {
+			case view := <-frame:
+				fmt.Fprintf(out, "--- %s ---\n%s\n", label, view)
+				return true
+			case <-done:
+				return false
+			}
+		}
+
+		program.Send(tea.WindowSizeMsg{Width: width, Height: height})
+		snapshots := 0
+		for _, step := range steps {
+			switch step.Action {
+			case ScriptWait:
+				select {
+				case <-time.After(step.Wait):
+				case <-done:
+					return
+				}
+			case ScriptSnapshot:
+				snapshots++
+				label := step.Label
+				if label == "" {
+					label = fmt.Sprintf("snapshot %d", snapshots)
+				}
+				if !snapshot(label) {
+					return
+				}
+			default:
+				for _, msg := range step.Msgs {
+					program.Send(msg)
+				}
+			}
+		}
+		program.Quit()
+	}()
+
+	_, err := program.Run()
+	close(done)
+	<-finished
+	if err != nil {
+		return err
+	}
+	// The event loop has stopped, so the model can be rendered directly
+	fmt.Fprintf(out, "--- final ---\n%s\n", wrapped.View())
+	return nil
+}
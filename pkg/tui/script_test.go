@@ -0,0 +1,102 @@
+package tui
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+func TestParseKey(t *testing.T) {
+	for name, want := range map[string]tea.KeyMsg{
+		"enter":     {Type: tea.KeyEnter},
+		"esc":       {Type: tea.KeyEsc},
+		"ctrl+p":    {Type: tea.KeyCtrlP},
+		"shift+tab": {Type: tea.KeyShiftTab},
+		"space":     {Type: tea.KeySpace},
+		"j":         {Type: tea.KeyRunes, Runes: []rune("j")},
+		"?":         {Type: tea.KeyRunes, Runes: []rune("?")},
+		"alt+x":     {Type: tea.KeyRunes, Runes: []rune("x"), Alt: true},
+	} {
+		got, err := ParseKey(name)
+		if err != nil {
+			t.Errorf("ParseKey(%q) error = %v", name, err)
+			continue
+		}
+		if got.String() != want.String() {
+			t.Errorf("ParseKey(%q) = %q, want %q", name, got.String(), want.String())
+		}
+	}
+	if _, err := ParseKey("hyper+k"); err == nil {
+		t.Error("Expected an error for an unknown key")
+	}
+}
+
+func TestParseScript(t *testing.T) {
+	steps, err := ParseScript(strings.NewReader(`# open the broadcast prompt
+resize 100 30
+key b
+type hi all
+
+wait 200ms
+snapshot broadcast
+`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(steps) != 5 {
+		t.Fatalf("Expected 5 steps, got %+v", steps)
+	}
+	if size, ok := steps[0].Msgs[0].(tea.WindowSizeMsg); !ok || size.Width != 100 || size.Height != 30 || steps[0].Line != 2 {
+		t.Errorf("Unexpected resize step: %+v", steps[0])
+	}
+	if len(steps[2].Msgs) != 6 || steps[2].Msgs[2].(tea.KeyMsg).Type != tea.KeySpace {
+		t.Errorf("Expected a key per character with a space key, got %+v", steps[2].Msgs)
+	}
+	if steps[3].Wait != 200*time.Millisecond || steps[4].Label != "broadcast" {
+		t.Errorf("Unexpected wait or snapshot: %+v %+v", steps[3], steps[4])
+	}
+
+	for script, want := range map[string]string{
+		"key":            "line 1: key needs at least one key name",
+		"\nkey hyper+k":  `line 2: unknown key "hyper+k"`,
+		"resize 80":      "resize needs a width and a height",
+		"resize 80 tall": `invalid height "tall"`,
+		"wait soon":      `invalid wait "soon"`,
+		"click 3 4":      `unknown action "click"`,
+	} {
+		if _, err := ParseScript(strings.NewReader(script)); err == nil || !strings.Contains(err.Error(), want) {
+			t.Errorf("ParseScript(%q) error = %v, want %q", script, err, want)
+		}
+	}
+}
+
+func TestRunScript(t *testing.T) {
+	app := NewApp(&MockUziInterface{})
+	defer app.Cleanup()
+
+	steps, err := ParseScript(strings.NewReader("wait 300ms\nsnapshot\nkey ?\n"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	var out bytes.Buffer
+	if err := RunScript(app, steps, &out, 100, 30); err != nil {
+		t.Fatal(err)
+	}
+
+	before, final, found := strings.Cut(out.String(), "--- final ---\n")
+	if !found || !strings.HasPrefix(before, "--- snapshot 1 ---\n") {
+		t.Fatalf("Expected a snapshot and the final frame, got %q", out.String())
+	}
+	if !strings.Contains(before, "agent1") || !strings.Contains(before, "agent2") {
+		t.Errorf("Expected the loaded sessions in the snapshot, got %q", before)
+	}
+	if !strings.Contains(final, "Keyboard shortcuts") || strings.Contains(before, "Keyboard shortcuts") {
+		t.Errorf("Expected the help key to open help in the final frame, got %q", final)
+	}
+	if app.width != 100 || app.height != 30 {
+		t.Errorf("Expected the script size, got %dx%d", app.width, app.height)
+	}
+}
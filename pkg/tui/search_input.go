@@ -0,0 +1,174 @@
+// Copyright (c) Subtrace, Inc.
+// SPDX-License-Identifier: BSD-3-Clause
+
+package tui
+
+import (
+	"strings"
+	"unicode"
+
+	"github.com/charmbracelet/bubbles/textinput"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+)
+
+// SearchInputModel handles the fuzzy search input prompt
+type SearchInputModel struct {
+	textInput textinput.Model
+	active    bool
+	width     int
+	theme     *Theme
+}
+
+// NewSearchInputModel creates a new search input model
+func NewSearchInputModel() *SearchInputModel {
+	ti := textinput.New()
+	ti.Placeholder = "Search names, prompts, and tags..."
+	ti.CharLimit = 128
+	ti.Width = 50
+
+	return &SearchInputModel{
+		textInput: ti,
+		active:    false,
+		width:     50,
+		theme:     DefaultTheme(),
+	}
+}
+
+// SetTheme switches the style profile used to render the search input
+func (m *SearchInputModel) SetTheme(theme *Theme) {
+	m.theme = theme
+}
+
+// SetActive activates or deactivates the search input, keeping the current query
+func (m *SearchInputModel) SetActive(active bool) {
+	m.active = active
+	if active {
+		m.textInput.Focus()
+		m.textInput.CursorEnd()
+	} else {
+		m.textInput.Blur()
+	}
+}
+
+// IsActive returns whether the search input is currently active
+func (m *SearchInputModel) IsActive() bool {
+	return m.active
+}
+
+// Value returns the current search query
+func (m *SearchInputModel) Value() string {
+	return m.textInput.Value()
+}
+
+// Reset clears the search query
+func (m *SearchInputModel) Reset() {
+	m.textInput.SetValue("")
+}
+
+// SetValue replaces the search query, e.g. when a filter preset is applied
+func (m *SearchInputModel) SetValue(query string) {
+	m.textInput.SetValue(query)
+}
+
+// SetWidth updates the width of the input
+func (m *SearchInputModel) SetWidth(width int) {
+	m.width = width
+	m.textInput.Width = width - 20 // Account for prompt text and padding
+}
+
+// Update handles messages for the search input
+func (m *SearchInputModel) Update(msg tea.Msg) (*SearchInputModel, tea.Cmd) {
+	if !m.active {
+		return m, nil
+	}
+
+	var cmd tea.Cmd
+	m.textInput, cmd = m.textInput.Update(msg)
+	return m, cmd
+}
+
+// View renders the search input prompt
+func (m *SearchInputModel) View() string {
+	if !m.active {
+		return ""
+	}
+
+	t := resolveTheme(m.theme)
+	promptStyle := t.Accent.Copy().Bold(true)
+	inputStyle := t.Border.Copy().
+		Width(m.width-2).
+		Padding(0, 1)
+
+	prompt := promptStyle.Render("Search: ")
+	input := m.textInput.View()
+
+	return inputStyle.Render(prompt + input)
+}
+
+// fuzzyMatch reports the rune indexes of text that match query, or nil if it does not match.
+// A contiguous case-insensitive substring is preferred; otherwise the query characters
+// must appear in order as a subsequence.
+func fuzzyMatch(query, text string) []int {
+	query = strings.TrimSpace(query)
+	if query == "" {
+		return nil
+	}
+
+	textRunes := []rune(strings.ToLower(text))
+	queryRunes := []rune(strings.ToLower(query))
+
+	// Prefer a contiguous substring match for a tidier highlight
+	if start := runeIndex(textRunes, queryRunes); start >= 0 {
+		indexes := make([]int, len(queryRunes))
+		for i := range queryRunes {
+			indexes[i] = start + i
+		}
+		return indexes
+	}
+
+	var indexes []int
+	qi := 0
+	for ti, r := range textRunes {
+		if qi >= len(queryRunes) {
+			break
+		}
+		if unicode.IsSpace(queryRunes[qi]) {
+			qi++
+			continue
+		}
+		if r == queryRunes[qi] {
+			indexes = append(indexes, ti)
+			qi++
+		}
+	}
+	if qi < len(queryRunes) {
+		return nil
+	}
+	return indexes
+}
+
+// runeIndex returns the index of the first occurrence of needle in haystack, or -1
+func runeIndex(haystack, needle []rune) int {
+	for i := 0; i+len(needle) <= len(haystack); i++ {
+		match := true
+		for j := range needle {
+			if haystack[i+j] != needle[j] {
+				match = false
+				break
+			}
+		}
+		if match {
+			return i
+		}
+	}
+	return -1
+}
+
+// highlightMatches renders text with the matched rune indexes highlighted
+func highlightMatches(text string, indexes []int, t *Theme) string {
+	if len(indexes) == 0 {
+		return text
+	}
+	return lipgloss.StyleRunes(text, indexes, t.SearchMatch, lipgloss.NewStyle())
+}
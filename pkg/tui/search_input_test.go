@@ -0,0 +1,150 @@
+// Copyright (c) Subtrace, Inc.
+// SPDX-License-Identifier: BSD-3-Clause
+
+package tui
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+func TestFuzzyMatch(t *testing.T) {
+	tests := []struct {
+		name  string
+		query string
+		text  string
+		want  []int
+	}{
+		{"empty query", "", "sarah", nil},
+		{"substring", "ara", "sarah", []int{1, 2, 3}},
+		{"case insensitive", "SAR", "Sarah", []int{0, 1, 2}},
+		{"subsequence", "srh", "sarah", []int{0, 2, 4}},
+		{"no match", "xyz", "sarah", nil},
+		{"out of order", "has", "sarah", nil},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := fuzzyMatch(tt.query, tt.text); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("fuzzyMatch(%q, %q) = %v, want %v", tt.query, tt.text, got, tt.want)
+			}
+		})
+	}
+}
+
+func searchTestSessions() []SessionInfo {
+	return []SessionInfo{
+		{Name: "agent-repo-abc123-sarah", AgentName: "sarah", Model: "claude", Prompt: "Build a todo app"},
+		{Name: "agent-repo-abc123-john", AgentName: "john", Model: "claude", Prompt: "Fix login bug", Tags: []string{"backend"}},
+		{Name: "agent-repo-abc123-mary", AgentName: "mary", Model: "codex", Prompt: "Write docs"},
+	}
+}
+
+func TestListModelSearch(t *testing.T) {
+	tests := []struct {
+		name  string
+		query string
+		want  []string
+	}{
+		{"no query shows all", "", []string{"sarah", "john", "mary"}},
+		{"agent name", "mar", []string{"mary"}},
+		{"prompt text", "login", []string{"john"}},
+		{"tag", "backend", []string{"john"}},
+		{"session name", "abc123", []string{"sarah", "john", "mary"}},
+		{"no matches", "zzz", []string{}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			listModel := NewListModel(80, 24)
+			listModel.LoadSessions(searchTestSessions())
+			listModel.SetSearchQuery(tt.query)
+
+			got := []string{}
+			for _, item := range listModel.Items() {
+				got = append(got, item.(SessionListItem).session.AgentName)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("search %q = %v, want %v", tt.query, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestListModelSearchHighlights(t *testing.T) {
+	listModel := NewListModel(80, 24)
+	listModel.LoadSessions(searchTestSessions())
+	listModel.SetSearchQuery("todo")
+
+	items := listModel.Items()
+	if len(items) != 1 {
+		t.Fatalf("Expected 1 match, got %d", len(items))
+	}
+
+	item := items[0].(SessionListItem)
+	if !reflect.DeepEqual(item.match.prompt, []int{8, 9, 10, 11}) {
+		t.Errorf("Expected prompt match indexes [8 9 10 11], got %v", item.match.prompt)
+	}
+	if !strings.Contains(item.Description(), "todo") {
+		t.Errorf("Expected description to contain the matched text, got %q", item.Description())
+	}
+}
+
+func TestListModelSearchCombinesWithFilter(t *testing.T) {
+	listModel := NewListModel(80, 24)
+	listModel.LoadSessions(searchTestSessions())
+	listModel.SetSearchQuery("sarah")
+
+	if status := listModel.GetFilterStatus(); status != `Search: "sarah" (1 matches)` {
+		t.Errorf("Unexpected filter status: %q", status)
+	}
+
+	listModel.ToggleStuckFilter()
+	if status := listModel.GetFilterStatus(); !strings.HasPrefix(status, "Showing stuck agents only │ Search:") {
+		t.Errorf("Expected combined filter status, got %q", status)
+	}
+
+	listModel.ClearFilter()
+	listModel.ClearSearch()
+	if len(listModel.Items()) != 3 {
+		t.Errorf("Expected all sessions after clearing, got %d", len(listModel.Items()))
+	}
+	if listModel.GetFilterStatus() != "" {
+		t.Errorf("Expected empty filter status, got %q", listModel.GetFilterStatus())
+	}
+}
+
+func TestAppSearchKeys(t *testing.T) {
+	app := NewApp(&MockUziInterface{})
+	defer app.Cleanup()
+	app.list.LoadSessions(searchTestSessions())
+
+	// '/' opens the search input
+	app.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune{'/'}})
+	if !app.searchInput.IsActive() {
+		t.Fatal("Expected search input to be active after '/'")
+	}
+
+	// Typing filters live
+	for _, r := range "john" {
+		app.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune{r}})
+	}
+	if app.list.SearchQuery() != "john" || len(app.list.Items()) != 1 {
+		t.Errorf("Expected live filtering to 'john', got query %q with %d items", app.list.SearchQuery(), len(app.list.Items()))
+	}
+
+	// Enter keeps the query applied
+	app.Update(tea.KeyMsg{Type: tea.KeyEnter})
+	if app.searchInput.IsActive() || app.list.SearchQuery() != "john" {
+		t.Error("Expected Enter to close the input and keep the query")
+	}
+
+	// Esc clears the applied search
+	app.Update(tea.KeyMsg{Type: tea.KeyEsc})
+	if app.list.SearchQuery() != "" || len(app.list.Items()) != 3 {
+		t.Errorf("Expected Esc to clear search, got query %q with %d items", app.list.SearchQuery(), len(app.list.Items()))
+	}
+}
@@ -0,0 +1,100 @@
+// Copyright (c) Subtrace, Inc.
+// SPDX-License-Identifier: BSD-3-Clause
+
+package tui
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"time"
+
+	"github.com/nehpz/claudicus/pkg/state"
+)
+
+// devServerHealthTimeout is how long an interactive spawn waits for the dev
+// server to accept connections, and agentLaunchTimeout how long it waits for
+// the agent to show it is working, before moving on with a note
+var (
+	devServerHealthTimeout = 30 * time.Second
+	agentLaunchTimeout     = 20 * time.Second
+	spawnCheckPoll         = 250 * time.Millisecond
+)
+
+// devServerAnswers reports whether something accepts connections on a local
+// port; tests replace it
+var devServerAnswers = func(port int) bool {
+	conn, err := net.DialTimeout("tcp", fmt.Sprintf("localhost:%d", port), 500*time.Millisecond)
+	if err != nil {
+		return false
+	}
+	conn.Close()
+	return true
+}
+
+// spawnReporter sends the SpawnEvents of one agent of a spawn. Events that
+// don't fit in the channel are dropped rather than holding up the spawn. A
+// nil reporter sends nothing, and spawns without one skip the health checks
+// only the progress modal shows.
+type spawnReporter struct {
+	progress chan<- SpawnEvent
+	agent    int
+	agents   int
+}
+
+func (r *spawnReporter) send(step ProgressStep, status SpawnStepStatus, note string) {
+	if r == nil {
+		return
+	}
+	event := SpawnEvent{Step: step, Status: status, Agent: r.agent, Agents: r.agents, Note: note, At: time.Now()}
+	select {
+	case r.progress <- event:
+	default:
+	}
+}
+
+func (r *spawnReporter) start(step ProgressStep) { r.send(step, SpawnStepStarted, "") }
+
+func (r *spawnReporter) done(step ProgressStep, note string) { r.send(step, SpawnStepDone, note) }
+
+func (r *spawnReporter) skip(step ProgressStep) { r.send(step, SpawnStepSkipped, "") }
+
+// waitFor polls check until it holds, ctx is done, or timeout passes. It
+// reports whether check held.
+func waitFor(ctx context.Context, timeout time.Duration, check func() bool) bool {
+	deadline := time.Now().Add(timeout)
+	for {
+		if check() {
+			return true
+		}
+		if ctx.Err() != nil || time.Now().After(deadline) {
+			return false
+		}
+		select {
+		case <-ctx.Done():
+		case <-time.After(spawnCheckPoll):
+		}
+	}
+}
+
+// awaitDevServer waits for the dev server on port to accept connections and
+// returns a note when it does not in time
+func awaitDevServer(ctx context.Context, port int) string {
+	if waitFor(ctx, devServerHealthTimeout, func() bool { return devServerAnswers(port) }) {
+		return ""
+	}
+	return fmt.Sprintf("no answer on port %d after %s", port, devServerHealthTimeout)
+}
+
+// awaitAgentLaunch waits for the agent pane of a session to show the agent
+// working on its prompt and returns a note when it does not in time
+func (c *UziCLI) awaitAgentLaunch(ctx context.Context, sessionName string) string {
+	launched := waitFor(ctx, agentLaunchTimeout, func() bool {
+		content, err := c.getPaneContent(sessionName)
+		return err == nil && state.AgentStatusFromPane(content) == state.StatusRunning
+	})
+	if launched {
+		return ""
+	}
+	return fmt.Sprintf("agent not seen working after %s", agentLaunchTimeout)
+}
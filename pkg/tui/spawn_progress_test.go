@@ -0,0 +1,79 @@
+// Copyright (c) Subtrace, Inc.
+// SPDX-License-Identifier: BSD-3-Clause
+
+package tui
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/nehpz/claudicus/pkg/testutil/cmdmock"
+	"github.com/nehpz/claudicus/pkg/tmuxops"
+)
+
+// withShortSpawnChecks makes the spawn health checks give up quickly
+func withShortSpawnChecks(t *testing.T) {
+	t.Helper()
+	health, launch, poll := devServerHealthTimeout, agentLaunchTimeout, spawnCheckPoll
+	devServerHealthTimeout, agentLaunchTimeout, spawnCheckPoll = 30*time.Millisecond, 30*time.Millisecond, 5*time.Millisecond
+	t.Cleanup(func() { devServerHealthTimeout, agentLaunchTimeout, spawnCheckPoll = health, launch, poll })
+}
+
+func TestAwaitDevServer(t *testing.T) {
+	withShortSpawnChecks(t)
+	original := devServerAnswers
+	t.Cleanup(func() { devServerAnswers = original })
+
+	calls := 0
+	devServerAnswers = func(port int) bool {
+		calls++
+		return calls >= 3
+	}
+	if note := awaitDevServer(context.Background(), 3000); note != "" {
+		t.Errorf("Expected the dev server seen up once it answers, got %q", note)
+	}
+
+	devServerAnswers = func(int) bool { return false }
+	if note := awaitDevServer(context.Background(), 3000); !strings.Contains(note, "no answer on port 3000") {
+		t.Errorf("Expected a note for a dev server that never answers, got %q", note)
+	}
+}
+
+func TestAwaitAgentLaunch(t *testing.T) {
+	setupUziTest()
+	withShortSpawnChecks(t)
+	cli := NewUziCLI()
+	capture := []string{"capture-pane", "-t", tmuxops.AgentTarget("agent-proj-abc123-sarah"), "-p"}
+
+	cmdmock.SetResponseWithArgs("tmux", capture, "$ claude \"add a login page\"", "", false)
+	if note := cli.awaitAgentLaunch(context.Background(), "agent-proj-abc123-sarah"); !strings.Contains(note, "not seen working") {
+		t.Errorf("Expected a note for an agent that never starts working, got %q", note)
+	}
+
+	cmdmock.SetResponseWithArgs("tmux", capture, "✻ Thinking… (esc to interrupt)", "", false)
+	if note := cli.awaitAgentLaunch(context.Background(), "agent-proj-abc123-sarah"); note != "" {
+		t.Errorf("Expected the working agent confirmed, got %q", note)
+	}
+}
+
+func TestSpawnReporter(t *testing.T) {
+	var unset *spawnReporter
+	unset.start(ProgressStepSetupWorktree) // Spawns without progress report nothing
+
+	progress := make(chan SpawnEvent, 1)
+	report := &spawnReporter{progress: progress, agent: 2, agents: 3}
+	report.done(ProgressStepDevServer, "no answer")
+	report.skip(ProgressStepInstallDeps) // Dropped rather than blocking the spawn
+
+	event := <-progress
+	if event.Step != ProgressStepDevServer || event.Status != SpawnStepDone || event.Note != "no answer" || event.Agent != 2 || event.Agents != 3 || event.At.IsZero() {
+		t.Errorf("Unexpected event %+v", event)
+	}
+	select {
+	case event := <-progress:
+		t.Errorf("Expected the event that did not fit dropped, got %+v", event)
+	default:
+	}
+}
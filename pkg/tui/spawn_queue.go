@@ -0,0 +1,49 @@
+// Copyright (c) Subtrace, Inc.
+// SPDX-License-Identifier: BSD-3-Clause
+
+package tui
+
+import (
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/nehpz/claudicus/pkg/jobs"
+	"github.com/nehpz/claudicus/pkg/spawnqueue"
+)
+
+// SpawnQueueMsg carries the agents queued under maxConcurrentAgents for the jobs view
+type SpawnQueueMsg struct {
+	Entries []spawnqueue.Entry
+}
+
+// queueRunner is implemented by UziInterface backends that can start the
+// agents queued under maxConcurrentAgents
+type queueRunner interface {
+	RunQueue() error
+}
+
+// loadSpawnQueue reads the agents waiting for a slot
+func (a *App) loadSpawnQueue() tea.Cmd {
+	if a.spawnQueue == nil {
+		return nil
+	}
+	return func() tea.Msg {
+		entries, err := a.spawnQueue.List()
+		if err != nil {
+			return nil
+		}
+		return SpawnQueueMsg{Entries: entries}
+	}
+}
+
+// startQueued starts queued agents in the background once an agent has
+// given up its slot; it does nothing while the queue is empty
+func (a *App) startQueued() tea.Cmd {
+	runner, ok := a.uzi.(queueRunner)
+	if !ok || a.spawnQueue == nil {
+		return nil
+	}
+	if entries, err := a.spawnQueue.List(); err != nil || len(entries) == 0 {
+		return nil
+	}
+	job := a.jobs.Enqueue(jobs.KindSpawn, "queue", runner.RunQueue)
+	return a.awaitJob(job.ID, func(jobs.Job) tea.Msg { return RefreshMsg{} })
+}
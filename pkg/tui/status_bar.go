@@ -0,0 +1,114 @@
+// Copyright (c) Subtrace, Inc.
+// SPDX-License-Identifier: BSD-3-Clause
+
+package tui
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/charmbracelet/bubbles/key"
+	"github.com/charmbracelet/lipgloss"
+)
+
+// keyHinter is implemented by modals whose keys go beyond Enter to confirm
+// and Esc to close, so the status bar can show them while the modal is open
+type keyHinter interface {
+	KeyHints() []key.Binding
+}
+
+// hint relabels a binding for the status bar, keeping its keys
+func hint(b key.Binding, desc string) key.Binding {
+	return key.NewBinding(key.WithKeys(b.Keys()...), key.WithHelp(b.Help().Key, desc))
+}
+
+// statusBarView renders the bar at the bottom of the screen: the selected
+// session and any transient status on the first line, key hints for the
+// current mode on the second
+func (a *App) statusBarView() string {
+	t := resolveTheme(a.theme)
+
+	var items []string
+	if info := a.selectedSessionInfo(); info != "" {
+		items = append(items, info)
+	}
+	if a.loading {
+		items = append(items, t.Muted.Render("Refreshing sessions..."))
+	}
+	if !a.splitView {
+		if filterStatus := a.list.GetFilterStatus(); filterStatus != "" {
+			items = append(items, t.Accent.Render(filterStatus))
+		}
+	}
+	if running := jobsStatusLine(a.jobs.Jobs()); running != "" {
+		items = append(items, t.Accent.Render(running))
+	}
+	if notice := a.noticeView(); notice != "" {
+		items = append(items, notice)
+	}
+
+	// Hints are listed most important first; those that don't fit are dropped
+	sep := t.Muted.Render(t.Glyph(" • ", ", "))
+	var hints string
+	for _, b := range a.keyHints() {
+		if !b.Enabled() || b.Help().Key == "" {
+			continue
+		}
+		next := t.Accent.Render(b.Help().Key) + " " + t.Muted.Render(b.Help().Desc)
+		if hints != "" {
+			next = hints + sep + next
+		}
+		if a.width > 0 && lipgloss.Width(next) > a.width {
+			break
+		}
+		hints = next
+	}
+
+	lines := []string{strings.Join(items, t.Separator()), hints}
+	if lines[0] == "" {
+		lines = lines[1:]
+	}
+	return strings.Join(lines, "\n")
+}
+
+// selectedSessionInfo summarizes the selected session: agent, status, diff
+// totals, and dev server port
+func (a *App) selectedSessionInfo() string {
+	session := a.list.SelectedSession()
+	if session == nil {
+		return ""
+	}
+	t := resolveTheme(a.theme)
+	name := session.AgentName
+	if session.Host != "" {
+		name += "@" + session.Host
+	}
+	parts := []string{
+		t.Primary.Render(name),
+		session.Status,
+		t.Added.Render(fmt.Sprintf("+%d", session.Insertions)) + t.Removed.Render(fmt.Sprintf("/-%d", session.Deletions)),
+	}
+	if session.Port > 0 {
+		parts = append(parts, fmt.Sprintf(":%d", session.Port))
+	}
+	return strings.Join(parts, " ")
+}
+
+// keyHints returns the keys that matter in the current mode: the open
+// modal's keys, the split view's diff keys, or the list's actions
+func (a *App) keyHints() []key.Binding {
+	k := a.keys
+	if top := a.modals.Top(); top != nil {
+		if hinter, ok := top.(keyHinter); ok {
+			return hinter.KeyHints()
+		}
+		return []key.Binding{hint(k.Enter, "confirm"), hint(k.Escape, "close")}
+	}
+	if a.splitView {
+		return []key.Binding{k.Up, k.Down, k.ToggleCommits, hint(k.Tab, "list view"), k.Checkpoint, k.Help, k.Quit}
+	}
+	if a.list.SelectedSession() == nil {
+		return []key.Binding{k.NewAgent, k.Palette, k.Help, k.Quit}
+	}
+	return []key.Binding{hint(k.Enter, "attach"), k.Broadcast, k.Kill, k.NewAgent, hint(k.Tab, "split view"), k.Filter, k.Palette, k.Help, k.Quit}
+}
@@ -0,0 +1,78 @@
+// Copyright (c) Subtrace, Inc.
+// SPDX-License-Identifier: BSD-3-Clause
+
+package tui
+
+import (
+	"strings"
+	"testing"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+func TestStatusBar_SelectedSession(t *testing.T) {
+	app := NewApp(&MockUziInterface{})
+	defer app.Cleanup()
+	app.width, app.height = 120, 30
+	app.loading = false
+
+	bar := app.statusBarView()
+	if !strings.Contains(bar, "n new agent") || strings.Contains(bar, "k kill agent") {
+		t.Errorf("Expected only session-independent hints without a selection, got:\n%s", bar)
+	}
+
+	app.list.LoadSessions([]SessionInfo{{Name: "agent-app-abc123-sarah", AgentName: "sarah", Status: "running", Insertions: 12, Deletions: 3, Port: 3001}})
+	bar = app.statusBarView()
+	for _, want := range []string{"sarah running +12/-3 :3001", "enter attach", "k kill agent", "tab split view"} {
+		if !strings.Contains(bar, want) {
+			t.Errorf("Expected %q in status bar, got:\n%s", want, bar)
+		}
+	}
+	if !strings.HasSuffix(app.View(), bar) {
+		t.Error("Expected the status bar at the bottom of the view")
+	}
+
+	app.width = 40
+	hints := strings.Split(app.statusBarView(), "\n")[1]
+	if hints != "enter attach • b broadcast message" {
+		t.Errorf("Expected hints cut to the terminal width, got %q", hints)
+	}
+}
+
+func TestStatusBar_HintsFollowMode(t *testing.T) {
+	app := NewApp(&MockUziInterface{})
+	defer app.Cleanup()
+	app.width, app.height = 120, 30
+	app.loading = false
+	app.list.LoadSessions([]SessionInfo{{Name: "agent-app-abc123-sarah", AgentName: "sarah", Status: "ready"}})
+
+	app.splitView = true
+	if bar := app.statusBarView(); !strings.Contains(bar, "v toggle commits view") || !strings.Contains(bar, "tab list view") {
+		t.Errorf("Expected diff hints in split view, got:\n%s", bar)
+	}
+	app.splitView = false
+
+	app.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune{'b'}})
+	bar := app.statusBarView()
+	if !strings.Contains(bar, "enter send") || !strings.Contains(bar, "esc cancel") || strings.Contains(bar, "k kill agent") {
+		t.Errorf("Expected the broadcast prompt's hints, got:\n%s", bar)
+	}
+
+	app.Update(tea.KeyMsg{Type: tea.KeyEsc})
+	app.modals.Open(app.helpView)
+	if bar := app.statusBarView(); !strings.Contains(bar, "esc close") {
+		t.Errorf("Expected the default modal hints, got:\n%s", bar)
+	}
+}
+
+func TestStatusBar_TransientStatus(t *testing.T) {
+	app := NewApp(&MockUziInterface{})
+	defer app.Cleanup()
+	app.loading = true
+	app.setNotice("config reloaded", false)
+
+	bar := app.statusBarView()
+	if !strings.Contains(bar, "Refreshing sessions...") || !strings.Contains(bar, "config reloaded") {
+		t.Errorf("Expected loading and notice in the status bar, got:\n%s", bar)
+	}
+}
@@ -87,6 +87,11 @@ var (
 	// Normal description styling
 	ClaudeSquadNormalDescStyle = ClaudeSquadBaseStyle.Copy().
 					Foreground(ClaudeSquadMuted)
+
+	// Search match highlight styling
+	SearchMatchStyle = ClaudeSquadBaseStyle.Copy().
+				Foreground(ClaudeSquadAccent).
+				Underline(true)
 )
 
 // Legacy Base styles for backward compatibility
@@ -0,0 +1,118 @@
+// Copyright (c) Subtrace, Inc.
+// SPDX-License-Identifier: BSD-3-Clause
+
+package tui
+
+import (
+	"os"
+
+	"github.com/charmbracelet/lipgloss"
+	"github.com/muesli/termenv"
+)
+
+// Theme is the style profile the TUI renders with. The default theme uses the
+// Claude Squad palette, unicode glyphs, and bordered panels. The plain theme is
+// meant for screen readers: it drops glyphs in favour of text labels, removes
+// borders, and stacks panels instead of placing them side by side.
+type Theme struct {
+	Plain bool
+
+	Accent       lipgloss.Style
+	Primary      lipgloss.Style
+	Muted        lipgloss.Style
+	Warning      lipgloss.Style
+	Error        lipgloss.Style
+	Header       lipgloss.Style
+	HeaderBar    lipgloss.Style
+	Border       lipgloss.Style // Panels such as the list, diff preview, and modals
+	Selected     lipgloss.Style
+	SelectedDesc lipgloss.Style
+	NormalTitle  lipgloss.Style
+	NormalDesc   lipgloss.Style
+	SearchMatch  lipgloss.Style
+	Added        lipgloss.Style // Added lines and files in diffs
+	Removed      lipgloss.Style // Deleted lines and files in diffs
+	Modified     lipgloss.Style // Modified files in diffs
+}
+
+// DefaultTheme returns the Claude Squad theme
+func DefaultTheme() *Theme {
+	return &Theme{
+		Accent:       ClaudeSquadAccentStyle,
+		Primary:      ClaudeSquadPrimaryStyle,
+		Muted:        ClaudeSquadMutedStyle,
+		Warning:      WarningStyle,
+		Error:        ErrorStyle,
+		Header:       ClaudeSquadHeaderStyle,
+		HeaderBar:    ClaudeSquadHeaderBarStyle,
+		Border:       ClaudeSquadBorderStyle,
+		Selected:     ClaudeSquadSelectedStyle,
+		SelectedDesc: ClaudeSquadSelectedDescStyle,
+		NormalTitle:  ClaudeSquadNormalTitleStyle,
+		NormalDesc:   ClaudeSquadNormalDescStyle,
+		SearchMatch:  SearchMatchStyle,
+		Added:        lipgloss.NewStyle().Foreground(lipgloss.Color("#00ff9d")),
+		Removed:      lipgloss.NewStyle().Foreground(lipgloss.Color("#ff6b6b")),
+		Modified:     lipgloss.NewStyle().Foreground(lipgloss.Color("#ffa500")),
+	}
+}
+
+// PlainTheme returns the screen-reader friendly theme. It sets no colors, and
+// marks the selected row with a ">" so the cursor is visible without them.
+func PlainTheme() *Theme {
+	plain := lipgloss.NewStyle()
+	selected := plain.Copy().
+		Border(lipgloss.Border{Left: ">"}, false, false, false, true).
+		PaddingLeft(1)
+	return &Theme{
+		Plain:        true,
+		Accent:       plain.Copy().Bold(true),
+		Primary:      plain,
+		Muted:        plain,
+		Warning:      plain,
+		Error:        plain.Copy().Bold(true),
+		Header:       plain.Copy().Bold(true).MarginBottom(1),
+		HeaderBar:    plain,
+		Border:       plain,
+		Selected:     selected.Copy().Bold(true),
+		SelectedDesc: selected,
+		NormalTitle:  plain.Copy().PaddingLeft(2),
+		NormalDesc:   plain.Copy().PaddingLeft(2),
+		SearchMatch:  plain.Copy().Underline(true),
+		Added:        plain,
+		Removed:      plain,
+		Modified:     plain,
+	}
+}
+
+// resolveTheme returns t, or the default theme for models built without one
+func resolveTheme(t *Theme) *Theme {
+	if t == nil {
+		return DefaultTheme()
+	}
+	return t
+}
+
+// Glyph returns fancy in the default theme and plain in the plain theme
+func (t *Theme) Glyph(fancy, plain string) string {
+	if t.Plain {
+		return plain
+	}
+	return fancy
+}
+
+// Separator returns the divider placed between fields on one line
+func (t *Theme) Separator() string {
+	return t.Glyph(" │ ", " | ")
+}
+
+// ColorDisabled reports whether colors were turned off with --no-color or the
+// NO_COLOR environment variable (https://no-color.org)
+func ColorDisabled(noColorFlag bool) bool {
+	return noColorFlag || os.Getenv("NO_COLOR") != ""
+}
+
+// DisableColor makes every style render as plain text without escape sequences
+func DisableColor() {
+	lipgloss.SetColorProfile(termenv.Ascii)
+}
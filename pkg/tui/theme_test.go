@@ -0,0 +1,70 @@
+// Copyright (c) Subtrace, Inc.
+// SPDX-License-Identifier: BSD-3-Clause
+
+package tui
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/nehpz/claudicus/pkg/pipeline"
+)
+
+func TestThemeGlyph(t *testing.T) {
+	if got := DefaultTheme().Glyph("🔗", "attached"); got != "🔗" {
+		t.Errorf("DefaultTheme().Glyph() = %q, want the fancy glyph", got)
+	}
+	if got := PlainTheme().Glyph("🔗", "attached"); got != "attached" {
+		t.Errorf("PlainTheme().Glyph() = %q, want the plain label", got)
+	}
+	if got := PlainTheme().Separator(); got != " | " {
+		t.Errorf("PlainTheme().Separator() = %q, want ASCII separator", got)
+	}
+}
+
+func TestPlainListItemHasNoGlyphs(t *testing.T) {
+	item := NewSessionListItem(SessionInfo{
+		AgentName: "sarah",
+		Model:     "claude",
+		Status:    "attached",
+		Prompt:    "Fix the login form",
+	})
+	item.theme = PlainTheme()
+	item.pinned = true
+
+	rendered := item.Title() + "\n" + item.Description()
+	for _, glyph := range []string{"🔗", "●", "○", "▮", "▯", "📌", "│"} {
+		if strings.Contains(rendered, glyph) {
+			t.Errorf("Plain item contains %q: %q", glyph, rendered)
+		}
+	}
+	for _, label := range []string{"sarah", "attached", "pinned"} {
+		if !strings.Contains(rendered, label) {
+			t.Errorf("Plain item missing %q label: %q", label, rendered)
+		}
+	}
+}
+
+func TestPlainPipelineStatusIcon(t *testing.T) {
+	if got := pipelineStatusIcon(pipeline.StatusRunning, PlainTheme()); got != "[running]" {
+		t.Errorf("pipelineStatusIcon() = %q, want [running]", got)
+	}
+	if got := pipelineStatusIcon(pipeline.StatusRunning, DefaultTheme()); got != "●" {
+		t.Errorf("pipelineStatusIcon() = %q, want ●", got)
+	}
+}
+
+func TestColorDisabled(t *testing.T) {
+	t.Setenv("NO_COLOR", "")
+	if ColorDisabled(false) {
+		t.Error("Expected colors enabled without flag or NO_COLOR")
+	}
+	if !ColorDisabled(true) {
+		t.Error("Expected --no-color to disable colors")
+	}
+
+	t.Setenv("NO_COLOR", "1")
+	if !ColorDisabled(false) {
+		t.Error("Expected NO_COLOR to disable colors")
+	}
+}
@@ -4,11 +4,18 @@
 package tui
 
 import (
+	"context"
 	"fmt"
 	"os/exec"
 	"strconv"
 	"strings"
+	"sync"
 	"time"
+
+	"github.com/nehpz/claudicus/pkg/config"
+	"github.com/nehpz/claudicus/pkg/hosts"
+	"github.com/nehpz/claudicus/pkg/state"
+	"github.com/nehpz/claudicus/pkg/tmuxops"
 )
 
 // TmuxInterface defines the interface for interacting with tmux
@@ -17,29 +24,84 @@ type TmuxInterface interface {
 	ListWindows(sessionName string) ([]byte, error)
 	ListPanes(sessionName string) ([]byte, error)
 	CapturePane(sessionName string) ([]byte, error)
+	DisplayAgentPane(sessionName string) ([]byte, error)
+}
+
+// TmuxReal implements TmuxInterface for real tmux commands, on the local
+// machine or, with a remote Host, over ssh. Format strings are adapted to the
+// host's tmux version, which is probed on first use.
+type TmuxReal struct {
+	Host hosts.Target
+
+	versionOnce sync.Once
+	version     tmuxops.Version
+	versionErr  error
+}
+
+// tmuxVersioner is implemented by tmux servers that can report their version
+type tmuxVersioner interface {
+	Version() (tmuxops.Version, error)
+}
+
+// Version runs tmux -V on the host once and returns the tmux release
+func (t *TmuxReal) Version() (tmuxops.Version, error) {
+	t.versionOnce.Do(func() {
+		output, err := t.tmux("-V").Output()
+		if err != nil {
+			t.versionErr = fmt.Errorf("could not run tmux -V: %w", err)
+			return
+		}
+		t.version, t.versionErr = tmuxops.ParseVersion(string(output))
+	})
+	return t.version, t.versionErr
+}
+
+// tmux builds a tmux command on the host
+func (t *TmuxReal) tmux(args ...string) *exec.Cmd {
+	return t.Host.Command(context.Background(), "", "tmux", args...)
 }
 
-// TmuxReal implements TmuxInterface for real tmux commands
-type TmuxReal struct{}
+// sessionFormat lists a session's name, window count, attachment, creation and
+// activity times, then the uzi marker and agent CLI options. tmux before 2.1
+// has no session_activity, so the creation time stands in for it there.
+func sessionFormat(version tmuxops.Version) string {
+	activity := "#{session_activity}"
+	if !version.HasSessionActivity() {
+		activity = "#{session_created}"
+	}
+	return "#{session_name}|#{session_windows}|#{?session_attached,1,0}|#{session_created}|" + activity +
+		"|#{" + tmuxops.SessionOption + "}|#{" + tmuxops.SessionAgentOption + "}"
+}
 
-// ListSessions executes the real tmux list-sessions command
+// ListSessions executes the real tmux list-sessions command. When the version
+// can't be probed, the format of current tmux releases is used.
 func (t *TmuxReal) ListSessions() ([]byte, error) {
-	return exec.Command("tmux", "list-sessions", "-F", "#{session_name}|#{session_windows}|#{?session_attached,1,0}|#{session_created}|#{session_activity}").Output()
+	version, _ := t.Version()
+	return t.tmux("list-sessions", "-F", sessionFormat(version)).Output()
 }
 
+// windowRoleFormat lists a window by its uzi role when it has one and by its
+// name otherwise, so agent windows are recognized whatever they are named
+var windowRoleFormat = "#{?" + tmuxops.WindowRoleOption + ",#{" + tmuxops.WindowRoleOption + "},#{window_name}}"
+
 // ListWindows executes the real tmux list-windows command
 func (t *TmuxReal) ListWindows(sessionName string) ([]byte, error) {
-	return exec.Command("tmux", "list-windows", "-t", sessionName, "-F", "#{window_name}").Output()
+	return t.tmux("list-windows", "-t", sessionName, "-F", windowRoleFormat).Output()
 }
 
 // ListPanes executes the real tmux list-panes command
 func (t *TmuxReal) ListPanes(sessionName string) ([]byte, error) {
-	return exec.Command("tmux", "list-panes", "-t", sessionName, "-a", "-F", "#{pane_id}").Output()
+	return t.tmux("list-panes", "-t", sessionName, "-a", "-F", "#{pane_id}").Output()
 }
 
 // CapturePane executes the real tmux capture-pane command
 func (t *TmuxReal) CapturePane(sessionName string) ([]byte, error) {
-	return exec.Command("tmux", "capture-pane", "-t", sessionName+":agent", "-p").Output()
+	return t.tmux("capture-pane", "-t", tmuxops.AgentTarget(sessionName), "-p").Output()
+}
+
+// DisplayAgentPane executes tmux display-message for the agent pane's working directory and command
+func (t *TmuxReal) DisplayAgentPane(sessionName string) ([]byte, error) {
+	return t.tmux("display-message", "-p", "-t", tmuxops.AgentTarget(sessionName), "#{pane_current_path}|#{pane_current_command}").Output()
 }
 
 // execCommand allows mocking exec.Command for testing
@@ -53,8 +115,17 @@ type TmuxSessionInfo struct {
 	Attached    bool      `json:"attached"`
 	Created     time.Time `json:"created"`
 	LastUsed    time.Time `json:"last_used"`
-	WindowNames []string  `json:"window_names"`
-	Activity    string    `json:"activity"` // "active", "inactive", "attached"
+	WindowNames []string  `json:"window_names"`    // uzi role of each window, or its name when it has none
+	Activity    string    `json:"activity"`        // "active", "inactive", "attached"
+	Host        string    `json:"host,omitempty"`  // remote host the session runs on; empty for local sessions
+	Uzi         bool      `json:"uzi,omitempty"`   // session is marked as created by uzi
+	Agent       string    `json:"agent,omitempty"` // agent CLI recorded when the session was created
+}
+
+// AgentPaneInfo describes what is running in the agent pane of a session
+type AgentPaneInfo struct {
+	Path    string `json:"path"`    // current working directory of the pane
+	Command string `json:"command"` // foreground command, e.g. "claude"
 }
 
 // TmuxDiscovery provides functionality to discover and analyze tmux sessions
@@ -64,17 +135,53 @@ type TmuxDiscovery struct {
 	sessions   map[string]TmuxSessionInfo
 	cacheTime  time.Duration
 	tmux       TmuxInterface
+
+	// activeThreshold is how long after its last activity a detached
+	// session counts as active
+	activeThreshold time.Duration
+
+	// Remote hosts whose tmux sessions are discovered alongside local ones
+	remotes     []remoteTmux
+	sessionTmux map[string]TmuxInterface // tmux of each discovered remote session
+}
+
+// remoteTmux is the tmux server of a remote host
+type remoteTmux struct {
+	host string
+	tmux TmuxInterface
 }
 
 // NewTmuxDiscovery creates a new tmux discovery helper
 func NewTmuxDiscovery() *TmuxDiscovery {
 	return &TmuxDiscovery{
-		sessions:  make(map[string]TmuxSessionInfo),
-		cacheTime: 2 * time.Second, // Cache for 2 seconds to avoid excessive tmux calls
-		tmux:      &TmuxReal{},
+		sessions:        make(map[string]TmuxSessionInfo),
+		cacheTime:       2 * time.Second, // Cache for 2 seconds to avoid excessive tmux calls
+		tmux:            &TmuxReal{},
+		activeThreshold: config.DefaultActiveThreshold,
 	}
 }
 
+// SetActiveThreshold sets how long after its last activity a detached session
+// counts as active, from statusThresholds.active in uzi.yaml
+func (td *TmuxDiscovery) SetActiveThreshold(threshold time.Duration) {
+	td.activeThreshold = threshold
+	td.RefreshCache()
+}
+
+// AddHost discovers the sessions of a remote host's tmux server as well
+func (td *TmuxDiscovery) AddHost(name string, tmux TmuxInterface) {
+	td.remotes = append(td.remotes, remoteTmux{host: name, tmux: tmux})
+	td.RefreshCache()
+}
+
+// tmuxFor returns the tmux server a session was discovered on
+func (td *TmuxDiscovery) tmuxFor(sessionName string) TmuxInterface {
+	if tmux, ok := td.sessionTmux[sessionName]; ok {
+		return tmux
+	}
+	return td.tmux
+}
+
 // GetAllSessions calls `tmux ls` and returns all tmux sessions
 func (td *TmuxDiscovery) GetAllSessions() (map[string]TmuxSessionInfo, error) {
 	// Check cache first
@@ -166,6 +273,21 @@ func (td *TmuxDiscovery) GetSessionActivity(sessionName string) string {
 	return "inactive"
 }
 
+// CheckVersion probes the local tmux and returns its version, with an error
+// wrapping tmuxops.ErrUnsupportedVersion if it is older than
+// tmuxops.MinVersion, or the probe's error if tmux could not be run
+func (td *TmuxDiscovery) CheckVersion() (tmuxops.Version, error) {
+	versioner, ok := td.tmux.(tmuxVersioner)
+	if !ok {
+		return tmuxops.Version{}, nil
+	}
+	version, err := versioner.Version()
+	if err != nil {
+		return version, err
+	}
+	return version, version.Supported()
+}
+
 // discoverTmuxSessions calls `tmux ls` and parses the output
 func (td *TmuxDiscovery) discoverTmuxSessions() (map[string]TmuxSessionInfo, error) {
 	// Call tmux list-sessions with detailed format
@@ -181,6 +303,27 @@ func (td *TmuxDiscovery) discoverTmuxSessions() (map[string]TmuxSessionInfo, err
 	}
 
 	sessions := make(map[string]TmuxSessionInfo)
+	td.sessionTmux = make(map[string]TmuxInterface)
+	td.addSessions(sessions, output, "")
+
+	// An unreachable host, one without a tmux server, or one whose tmux is too
+	// old for uzi has no sessions to show
+	for _, remote := range td.remotes {
+		if versioner, ok := remote.tmux.(tmuxVersioner); ok {
+			if version, err := versioner.Version(); err == nil && version.Supported() != nil {
+				continue
+			}
+		}
+		if output, err := remote.tmux.ListSessions(); err == nil {
+			td.addSessions(sessions, output, remote.host)
+		}
+	}
+
+	return sessions, nil
+}
+
+// addSessions parses tmux list-sessions output from a host into sessions
+func (td *TmuxDiscovery) addSessions(sessions map[string]TmuxSessionInfo, output []byte, host string) {
 	lines := strings.Split(strings.TrimSpace(string(output)), "\n")
 
 	for _, line := range lines {
@@ -193,6 +336,14 @@ func (td *TmuxDiscovery) discoverTmuxSessions() (map[string]TmuxSessionInfo, err
 			// Log but don't fail completely for one bad line
 			continue
 		}
+		if host != "" {
+			session.Host = host
+			for _, remote := range td.remotes {
+				if remote.host == host {
+					td.sessionTmux[session.Name] = remote.tmux
+				}
+			}
+		}
 
 		// Get window information for this session
 		windowNames, paneCount, err := td.getSessionWindows(session.Name)
@@ -206,15 +357,13 @@ func (td *TmuxDiscovery) discoverTmuxSessions() (map[string]TmuxSessionInfo, err
 		session.Panes = paneCount
 		sessions[session.Name] = session
 	}
-
-	return sessions, nil
 }
 
 // parseSessionLine parses a single line from tmux list-sessions output
 func (td *TmuxDiscovery) parseSessionLine(line string) (TmuxSessionInfo, error) {
-	// Format: name|windows|attached|created|activity
+	// Format: name|windows|attached|created|activity[|uzi|agent]
 	parts := strings.Split(line, "|")
-	if len(parts) != 5 {
+	if len(parts) != 5 && len(parts) != 7 {
 		return TmuxSessionInfo{}, fmt.Errorf("unexpected tmux output format: %s", line)
 	}
 
@@ -226,28 +375,38 @@ func (td *TmuxDiscovery) parseSessionLine(line string) (TmuxSessionInfo, error)
 
 	created := time.Unix(createdUnix, 0)
 	lastUsed := time.Unix(activityUnix, 0)
+	if activityUnix == 0 {
+		// A tmux without session_activity leaves it empty
+		lastUsed = created
+	}
 
 	activity := "inactive"
 	if attached {
 		activity = "attached"
-	} else if time.Since(lastUsed) < 5*time.Minute {
+	} else if time.Since(lastUsed) < td.activeThreshold {
 		activity = "active"
 	}
 
-	return TmuxSessionInfo{
+	session := TmuxSessionInfo{
 		Name:     name,
 		Windows:  windows,
 		Attached: attached,
 		Created:  created,
 		LastUsed: lastUsed,
 		Activity: activity,
-	}, nil
+	}
+	if len(parts) == 7 {
+		session.Uzi = parts[5] == "1"
+		session.Agent = parts[6]
+	}
+	return session, nil
 }
 
 // getSessionWindows gets window names and pane count for a session
 func (td *TmuxDiscovery) getSessionWindows(sessionName string) ([]string, int, error) {
 	// Get window information
-	windowOutput, err := td.tmux.ListWindows(sessionName)
+	tmux := td.tmuxFor(sessionName)
+	windowOutput, err := tmux.ListWindows(sessionName)
 	if err != nil {
 		return nil, 0, err
 	}
@@ -258,7 +417,7 @@ func (td *TmuxDiscovery) getSessionWindows(sessionName string) ([]string, int, e
 	}
 
 	// Get pane count
-	paneOutput, err := td.tmux.ListPanes(sessionName)
+	paneOutput, err := tmux.ListPanes(sessionName)
 	if err != nil {
 		return windowNames, 0, err
 	}
@@ -272,8 +431,14 @@ func (td *TmuxDiscovery) getSessionWindows(sessionName string) ([]string, int, e
 	return windowNames, paneCount, nil
 }
 
-// isUziSession determines if a tmux session appears to be a Uzi agent session
+// isUziSession determines if a tmux session is a Uzi agent session. Sessions
+// are marked when uzi creates them; the name and window heuristics only
+// recognize sessions created before they were.
 func (td *TmuxDiscovery) isUziSession(sessionName string, session TmuxSessionInfo) bool {
+	if session.Uzi {
+		return true
+	}
+
 	// Check if session name follows Uzi pattern: agent-projectDir-gitHash-agentName
 	if strings.HasPrefix(sessionName, "agent-") {
 		parts := strings.Split(sessionName, "-")
@@ -284,7 +449,7 @@ func (td *TmuxDiscovery) isUziSession(sessionName string, session TmuxSessionInf
 
 	// Also check if session has windows that suggest it's a Uzi session
 	for _, windowName := range session.WindowNames {
-		if windowName == "agent" || windowName == "uzi-dev" {
+		if windowName == tmuxops.AgentWindowRole || windowName == "uzi-dev" {
 			return true
 		}
 	}
@@ -292,41 +457,25 @@ func (td *TmuxDiscovery) isUziSession(sessionName string, session TmuxSessionInf
 	return false
 }
 
-// GetSessionStatus returns a more detailed status for a session
+// GetSessionStatus returns the status of a session from what its tmux server
+// shows, resolved by the state package's status engine. A session that is
+// gone is dead; whether it is attached is not part of its status.
 func (td *TmuxDiscovery) GetSessionStatus(sessionName string) (string, error) {
 	sessions, err := td.GetAllSessions()
 	if err != nil {
-		return "unknown", err
+		return state.StatusUnknown, err
 	}
 
-	session, exists := sessions[sessionName]
-	if !exists {
-		return "not_found", nil
+	var signals state.StatusSignals
+	if _, exists := sessions[sessionName]; exists {
+		signals.Alive = true
+		signals.Pane, signals.PaneErr = td.getAgentWindowContent(sessionName)
 	}
-
-	if session.Attached {
-		return "attached", nil
-	}
-
-	// Check if session has any activity in agent window
-	if td.hasAgentWindow(sessionName) {
-		content, err := td.getAgentWindowContent(sessionName)
-		if err != nil {
-			return "ready", nil
-		}
-
-		// Check for running indicators in the agent pane
-		if strings.Contains(content, "esc to interrupt") ||
-			strings.Contains(content, "Thinking") ||
-			strings.Contains(content, "Working") {
-			return "running", nil
-		}
-	}
-
-	return "ready", nil
+	return signals.Resolve(), nil
 }
 
-// hasAgentWindow checks if session has an "agent" window
+// hasAgentWindow checks if session has a window marked as the agent window,
+// or one named "agent" from before windows were marked
 func (td *TmuxDiscovery) hasAgentWindow(sessionName string) bool {
 	sessions, err := td.GetAllSessions()
 	if err != nil {
@@ -339,7 +488,7 @@ func (td *TmuxDiscovery) hasAgentWindow(sessionName string) bool {
 	}
 
 	for _, windowName := range session.WindowNames {
-		if windowName == "agent" {
+		if windowName == tmuxops.AgentWindowRole {
 			return true
 		}
 	}
@@ -349,13 +498,29 @@ func (td *TmuxDiscovery) hasAgentWindow(sessionName string) bool {
 
 // getAgentWindowContent gets the content of the agent window/pane
 func (td *TmuxDiscovery) getAgentWindowContent(sessionName string) (string, error) {
-	output, err := td.tmux.CapturePane(sessionName)
+	output, err := td.tmuxFor(sessionName).CapturePane(sessionName)
 	if err != nil {
 		return "", err
 	}
 	return string(output), nil
 }
 
+// GetAgentPane returns the working directory and foreground command of a session's agent pane
+func (td *TmuxDiscovery) GetAgentPane(sessionName string) (AgentPaneInfo, error) {
+	output, err := td.tmuxFor(sessionName).DisplayAgentPane(sessionName)
+	if err != nil {
+		return AgentPaneInfo{}, err
+	}
+
+	// Format: path|command
+	line := strings.TrimSpace(string(output))
+	sep := strings.LastIndex(line, "|")
+	if sep < 0 {
+		return AgentPaneInfo{}, fmt.Errorf("unexpected tmux pane format: %s", line)
+	}
+	return AgentPaneInfo{Path: line[:sep], Command: line[sep+1:]}, nil
+}
+
 // RefreshCache forces a refresh of the tmux session cache
 func (td *TmuxDiscovery) RefreshCache() {
 	td.lastUpdate = time.Time{}
@@ -10,6 +10,7 @@ import (
 	"time"
 
 	"github.com/nehpz/claudicus/pkg/testutil/cmdmock"
+	"github.com/nehpz/claudicus/pkg/testutil/harness"
 )
 
 func init() {
@@ -43,7 +44,7 @@ func TestTmuxDiscovery_Comprehensive(t *testing.T) {
 			"session1|2|1|1640000000|1640000010", "", false)
 
 		// Mock list-windows for session1
-		cmdmock.SetResponseWithArgs("tmux", []string{"list-windows", "-t", "session1", "-F", "#{window_name}"},
+		cmdmock.SetResponseWithArgs("tmux", []string{"list-windows", "-t", "session1", "-F", windowRoleFormat},
 			"agent\ndev", "", false)
 
 		// Mock list-panes for session1
@@ -113,11 +114,11 @@ func TestTmuxDiscovery_Comprehensive(t *testing.T) {
 			"good-session|1|0|1640000000|1640000000\nbad-line\ngood-session2|2|1|1640000000|1640000000", "", false)
 
 		// Mock window/pane calls for valid sessions
-		cmdmock.SetResponseWithArgs("tmux", []string{"list-windows", "-t", "good-session", "-F", "#{window_name}"},
+		cmdmock.SetResponseWithArgs("tmux", []string{"list-windows", "-t", "good-session", "-F", windowRoleFormat},
 			"main", "", false)
 		cmdmock.SetResponseWithArgs("tmux", []string{"list-panes", "-t", "good-session", "-a", "-F", "#{pane_id}"},
 			"%0", "", false)
-		cmdmock.SetResponseWithArgs("tmux", []string{"list-windows", "-t", "good-session2", "-F", "#{window_name}"},
+		cmdmock.SetResponseWithArgs("tmux", []string{"list-windows", "-t", "good-session2", "-F", windowRoleFormat},
 			"bash\nhtop", "", false)
 		cmdmock.SetResponseWithArgs("tmux", []string{"list-panes", "-t", "good-session2", "-a", "-F", "#{pane_id}"},
 			"%0\n%1", "", false)
@@ -239,6 +240,13 @@ func TestActivityClassification_Comprehensive(t *testing.T) {
 			}
 		})
 	}
+
+	// A shorter configured threshold makes the same session inactive sooner
+	td.SetActiveThreshold(time.Minute)
+	result, err := td.parseSessionLine(fmt.Sprintf("session|1|0|1640000000|%d", now-120))
+	if err != nil || result.Activity != "inactive" {
+		t.Errorf("Expected a session idle past the threshold to be inactive, got %q, %v", result.Activity, err)
+	}
 }
 
 // Test Uzi session identification
@@ -272,6 +280,73 @@ func TestUziSessionIdentification_Comprehensive(t *testing.T) {
 	}
 }
 
+// Marked sessions are recognized by their tmux options, whatever their name
+func TestUziSessionMarker(t *testing.T) {
+	td := NewTmuxDiscovery()
+
+	marked, err := td.parseSessionLine("work|1|0|1640000000|1640000000|1|codex")
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if !marked.Uzi || marked.Agent != "codex" {
+		t.Errorf("Expected a uzi session running codex, got %+v", marked)
+	}
+	if !td.isUziSession("work", marked) {
+		t.Error("Expected a marked session to be a uzi session")
+	}
+
+	unmarked, err := td.parseSessionLine("agent-notes|1|0|1640000000|1640000000||")
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if unmarked.Uzi || unmarked.Agent != "" {
+		t.Errorf("Expected an unmarked session, got %+v", unmarked)
+	}
+	if td.isUziSession("agent-notes", unmarked) {
+		t.Error("Expected an unmarked session without agent windows not to be a uzi session")
+	}
+}
+
+// TestTmuxDiscoveryFakeServer discovers agents spawned on a fake tmux server
+func TestTmuxDiscoveryFakeServer(t *testing.T) {
+	h := harness.New(t)
+	sarah := h.Spawn("sarah", "claude", "fix the flaky tests")
+	h.Tmux.Execute("tmux", "new-session", "-d", "-s", "notes")
+
+	td := NewTmuxDiscovery()
+	td.tmux = h.Tmux
+
+	sessions, err := td.GetUziSessions()
+	if err != nil {
+		t.Fatalf("GetUziSessions() error = %v", err)
+	}
+	if len(sessions) != 1 || !sessions[sarah].Uzi || sessions[sarah].Agent != "claude" {
+		t.Fatalf("Expected only sarah's session, running claude, got %+v", sessions)
+	}
+	if status, _ := td.GetSessionStatus(sarah); status != "starting" {
+		t.Errorf("Expected sarah starting before claude draws, got %s", status)
+	}
+	h.Tmux.SetPaneContent(sarah, "> ")
+	if status, _ := td.GetSessionStatus(sarah); status != "ready" {
+		t.Errorf("Expected sarah ready, got %s", status)
+	}
+
+	h.Tmux.SetPaneContent(sarah, "Working... (esc to interrupt)")
+	if status, _ := td.GetSessionStatus(sarah); status != "running" {
+		t.Errorf("Expected sarah running, got %s", status)
+	}
+	pane, err := td.GetAgentPane(sarah)
+	if err != nil || pane.Command != "claude" || pane.Path != h.States()[sarah].WorktreePath {
+		t.Errorf("GetAgentPane() = %+v, %v", pane, err)
+	}
+
+	h.Kill(sarah)
+	td.RefreshCache()
+	if status, _ := td.GetSessionStatus(sarah); status != "dead" {
+		t.Errorf("Expected sarah gone after kill, got %s", status)
+	}
+}
+
 // Test session status detection
 func TestSessionStatus_Comprehensive(t *testing.T) {
 	setUp_Comprehensive()
@@ -282,7 +357,7 @@ func TestSessionStatus_Comprehensive(t *testing.T) {
 
 		cmdmock.SetResponseWithArgs("tmux", []string{"list-sessions", "-F", "#{session_name}|#{session_windows}|#{?session_attached,1,0}|#{session_created}|#{session_activity}"},
 			"attached-session|1|1|1640000000|1640000000", "", false)
-		cmdmock.SetResponseWithArgs("tmux", []string{"list-windows", "-t", "attached-session", "-F", "#{window_name}"},
+		cmdmock.SetResponseWithArgs("tmux", []string{"list-windows", "-t", "attached-session", "-F", windowRoleFormat},
 			"main", "", false)
 		cmdmock.SetResponseWithArgs("tmux", []string{"list-panes", "-t", "attached-session", "-a", "-F", "#{pane_id}"},
 			"%0", "", false)
@@ -294,8 +369,9 @@ func TestSessionStatus_Comprehensive(t *testing.T) {
 			t.Fatalf("Unexpected error: %v", err)
 		}
 
-		if status != "attached" {
-			t.Errorf("Expected status 'attached', got '%s'", status)
+		// Attachment is not a status; the unmocked agent pane can't be read
+		if status != "unknown" {
+			t.Errorf("Expected status 'unknown', got '%s'", status)
 		}
 	})
 
@@ -305,7 +381,7 @@ func TestSessionStatus_Comprehensive(t *testing.T) {
 
 		cmdmock.SetResponseWithArgs("tmux", []string{"list-sessions", "-F", "#{session_name}|#{session_windows}|#{?session_attached,1,0}|#{session_created}|#{session_activity}"},
 			"other-session|1|0|1640000000|1640000000", "", false)
-		cmdmock.SetResponseWithArgs("tmux", []string{"list-windows", "-t", "other-session", "-F", "#{window_name}"},
+		cmdmock.SetResponseWithArgs("tmux", []string{"list-windows", "-t", "other-session", "-F", windowRoleFormat},
 			"bash", "", false)
 		cmdmock.SetResponseWithArgs("tmux", []string{"list-panes", "-t", "other-session", "-a", "-F", "#{pane_id}"},
 			"%0", "", false)
@@ -317,8 +393,8 @@ func TestSessionStatus_Comprehensive(t *testing.T) {
 			t.Fatalf("Unexpected error: %v", err)
 		}
 
-		if status != "not_found" {
-			t.Errorf("Expected status 'not_found', got '%s'", status)
+		if status != "dead" {
+			t.Errorf("Expected status 'dead', got '%s'", status)
 		}
 	})
 }
@@ -407,7 +483,7 @@ func TestCaching_Comprehensive(t *testing.T) {
 	// Mock session data
 	cmdmock.SetResponseWithArgs("tmux", []string{"list-sessions", "-F", "#{session_name}|#{session_windows}|#{?session_attached,1,0}|#{session_created}|#{session_activity}"},
 		"session1|1|0|1640000000|1640000000", "", false)
-	cmdmock.SetResponseWithArgs("tmux", []string{"list-windows", "-t", "session1", "-F", "#{window_name}"},
+	cmdmock.SetResponseWithArgs("tmux", []string{"list-windows", "-t", "session1", "-F", windowRoleFormat},
 		"main", "", false)
 	cmdmock.SetResponseWithArgs("tmux", []string{"list-panes", "-t", "session1", "-a", "-F", "#{pane_id}"},
 		"%0", "", false)
@@ -500,7 +576,7 @@ func TestWindowPaneParsing_Comprehensive(t *testing.T) {
 	t.Run("EmptyWindowPaneOutput", func(t *testing.T) {
 		setUp_Comprehensive()
 
-		cmdmock.SetResponseWithArgs("tmux", []string{"list-windows", "-t", "empty-session", "-F", "#{window_name}"},
+		cmdmock.SetResponseWithArgs("tmux", []string{"list-windows", "-t", "empty-session", "-F", windowRoleFormat},
 			"", "", false)
 		cmdmock.SetResponseWithArgs("tmux", []string{"list-panes", "-t", "empty-session", "-a", "-F", "#{pane_id}"},
 			"", "", false)
@@ -525,7 +601,7 @@ func TestWindowPaneParsing_Comprehensive(t *testing.T) {
 	t.Run("WindowCommandFails", func(t *testing.T) {
 		setUp_Comprehensive()
 
-		cmdmock.SetResponseWithArgs("tmux", []string{"list-windows", "-t", "fail-session", "-F", "#{window_name}"},
+		cmdmock.SetResponseWithArgs("tmux", []string{"list-windows", "-t", "fail-session", "-F", windowRoleFormat},
 			"", "session not found", true)
 
 		td := NewTmuxDiscovery()
@@ -556,7 +632,7 @@ func TestAgentWindowDetection_Comprehensive(t *testing.T) {
 
 		cmdmock.SetResponseWithArgs("tmux", []string{"list-sessions", "-F", "#{session_name}|#{session_windows}|#{?session_attached,1,0}|#{session_created}|#{session_activity}"},
 			"agent-session|1|0|1640000000|1640000000", "", false)
-		cmdmock.SetResponseWithArgs("tmux", []string{"list-windows", "-t", "agent-session", "-F", "#{window_name}"},
+		cmdmock.SetResponseWithArgs("tmux", []string{"list-windows", "-t", "agent-session", "-F", windowRoleFormat},
 			"agent", "", false)
 		cmdmock.SetResponseWithArgs("tmux", []string{"list-panes", "-t", "agent-session", "-a", "-F", "#{pane_id}"},
 			"%0", "", false)
@@ -574,7 +650,7 @@ func TestAgentWindowDetection_Comprehensive(t *testing.T) {
 		setUp_Comprehensive()
 
 		expectedContent := "$ echo hello\nhello\n$ "
-		cmdmock.SetResponseWithArgs("tmux", []string{"capture-pane", "-t", "agent-session:agent", "-p"},
+		cmdmock.SetResponseWithArgs("tmux", []string{"capture-pane", "-t", "agent-session:{start}", "-p"},
 			expectedContent, "", false)
 
 		td := NewTmuxDiscovery()
@@ -595,7 +671,7 @@ func TestAgentWindowDetection_Comprehensive(t *testing.T) {
 	t.Run("GetAgentWindowContent_Fail", func(t *testing.T) {
 		setUp_Comprehensive()
 
-		cmdmock.SetResponseWithArgs("tmux", []string{"capture-pane", "-t", "missing-session:agent", "-p"},
+		cmdmock.SetResponseWithArgs("tmux", []string{"capture-pane", "-t", "missing-session:{start}", "-p"},
 			"", "no such window", true)
 
 		td := NewTmuxDiscovery()
@@ -42,9 +42,9 @@ func TestTmuxCoverage_Complete(t *testing.T) {
 			"session1|2|1|1640000000|1640000010\\nsession2|1|0|1640000000|1640000000", "", false)
 
 		// Mock window responses
-		cmdmock.SetResponseWithArgs("tmux", []string{"list-windows", "-t", "session1", "-F", "#{window_name}"},
+		cmdmock.SetResponseWithArgs("tmux", []string{"list-windows", "-t", "session1", "-F", windowRoleFormat},
 			"agent\\ndev", "", false)
-		cmdmock.SetResponseWithArgs("tmux", []string{"list-windows", "-t", "session2", "-F", "#{window_name}"},
+		cmdmock.SetResponseWithArgs("tmux", []string{"list-windows", "-t", "session2", "-F", windowRoleFormat},
 			"main", "", false)
 
 		// Mock pane responses
@@ -113,11 +113,11 @@ func TestTmuxCoverage_Complete(t *testing.T) {
 			"agent-proj-abc123-claude|1|0|1640000000|1640000000\\nregular-session|2|1|1640001000|1640001000\\nuzi-dev-session|1|0|1640002000|1640002000", "", false)
 
 		// Mock windows for each session type
-		cmdmock.SetResponseWithArgs("tmux", []string{"list-windows", "-t", "agent-proj-abc123-claude", "-F", "#{window_name}"},
+		cmdmock.SetResponseWithArgs("tmux", []string{"list-windows", "-t", "agent-proj-abc123-claude", "-F", windowRoleFormat},
 			"agent", "", false)
-		cmdmock.SetResponseWithArgs("tmux", []string{"list-windows", "-t", "regular-session", "-F", "#{window_name}"},
+		cmdmock.SetResponseWithArgs("tmux", []string{"list-windows", "-t", "regular-session", "-F", windowRoleFormat},
 			"bash\\nhtop", "", false)
-		cmdmock.SetResponseWithArgs("tmux", []string{"list-windows", "-t", "uzi-dev-session", "-F", "#{window_name}"},
+		cmdmock.SetResponseWithArgs("tmux", []string{"list-windows", "-t", "uzi-dev-session", "-F", windowRoleFormat},
 			"uzi-dev", "", false)
 
 		// Mock panes
@@ -152,7 +152,7 @@ func TestTmuxCoverage_Complete(t *testing.T) {
 
 		cmdmock.SetResponseWithArgs("tmux", []string{"list-sessions", "-F", "#{session_name}|#{session_windows}|#{?session_attached,1,0}|#{session_created}|#{session_activity}"},
 			"test-session|1|1|1640000000|1640000000", "", false)
-		cmdmock.SetResponseWithArgs("tmux", []string{"list-windows", "-t", "test-session", "-F", "#{window_name}"},
+		cmdmock.SetResponseWithArgs("tmux", []string{"list-windows", "-t", "test-session", "-F", windowRoleFormat},
 			"main", "", false)
 		cmdmock.SetResponseWithArgs("tmux", []string{"list-panes", "-t", "test-session", "-a", "-F", "#{pane_id}"},
 			"%0", "", false)
@@ -195,11 +195,11 @@ func TestTmuxCoverage_Complete(t *testing.T) {
 			"attached-session|1|1|1640000000|1640000000\\nagent-session|1|0|1640000000|1640000000\\nready-session|1|0|1640000000|1640000000", "", false)
 
 		// Mock windows
-		cmdmock.SetResponseWithArgs("tmux", []string{"list-windows", "-t", "attached-session", "-F", "#{window_name}"},
+		cmdmock.SetResponseWithArgs("tmux", []string{"list-windows", "-t", "attached-session", "-F", windowRoleFormat},
 			"main", "", false)
-		cmdmock.SetResponseWithArgs("tmux", []string{"list-windows", "-t", "agent-session", "-F", "#{window_name}"},
+		cmdmock.SetResponseWithArgs("tmux", []string{"list-windows", "-t", "agent-session", "-F", windowRoleFormat},
 			"agent", "", false)
-		cmdmock.SetResponseWithArgs("tmux", []string{"list-windows", "-t", "ready-session", "-F", "#{window_name}"},
+		cmdmock.SetResponseWithArgs("tmux", []string{"list-windows", "-t", "ready-session", "-F", windowRoleFormat},
 			"bash", "", false)
 
 		// Mock panes
@@ -211,7 +211,7 @@ func TestTmuxCoverage_Complete(t *testing.T) {
 			"%0", "", false)
 
 		// Mock capture-pane for running detection
-		cmdmock.SetResponseWithArgs("tmux", []string{"capture-pane", "-t", "agent-session:agent", "-p"},
+		cmdmock.SetResponseWithArgs("tmux", []string{"capture-pane", "-t", "agent-session:{start}", "-p"},
 			"Thinking about your request...", "", false)
 
 		td := NewTmuxDiscovery()
@@ -221,8 +221,9 @@ func TestTmuxCoverage_Complete(t *testing.T) {
 		if err != nil {
 			t.Errorf("GetSessionStatus failed: %v", err)
 		}
-		if status != "attached" {
-			t.Errorf("Expected 'attached' status, got '%s'", status)
+		// Attachment is not a status; the unmocked agent pane can't be read
+		if status != "unknown" {
+			t.Errorf("Expected 'unknown' status, got '%s'", status)
 		}
 
 		// Test GetSessionStatus for missing session
@@ -230,8 +231,8 @@ func TestTmuxCoverage_Complete(t *testing.T) {
 		if err != nil {
 			t.Errorf("GetSessionStatus should not error for missing session: %v", err)
 		}
-		if status != "not_found" {
-			t.Errorf("Expected 'not_found' status, got '%s'", status)
+		if status != "dead" {
+			t.Errorf("Expected 'dead' status, got '%s'", status)
 		}
 
 		// Test hasAgentWindow
@@ -246,7 +247,7 @@ func TestTmuxCoverage_Complete(t *testing.T) {
 		t.Logf("Agent content: %s", content)
 
 		// Test getAgentWindowContent failure case
-		cmdmock.SetResponseWithArgs("tmux", []string{"capture-pane", "-t", "missing:agent", "-p"},
+		cmdmock.SetResponseWithArgs("tmux", []string{"capture-pane", "-t", "missing:{start}", "-p"},
 			"", "no such window", true)
 		_, err = td.getAgentWindowContent("missing")
 		if err == nil {
@@ -316,7 +317,7 @@ func TestTmuxCoverage_Complete(t *testing.T) {
 
 		cmdmock.SetResponseWithArgs("tmux", []string{"list-sessions", "-F", "#{session_name}|#{session_windows}|#{?session_attached,1,0}|#{session_created}|#{session_activity}"},
 			"session1|1|0|1640000000|1640000000", "", false)
-		cmdmock.SetResponseWithArgs("tmux", []string{"list-windows", "-t", "session1", "-F", "#{window_name}"},
+		cmdmock.SetResponseWithArgs("tmux", []string{"list-windows", "-t", "session1", "-F", windowRoleFormat},
 			"main", "", false)
 		cmdmock.SetResponseWithArgs("tmux", []string{"list-panes", "-t", "session1", "-a", "-F", "#{pane_id}"},
 			"%0", "", false)
@@ -378,10 +379,10 @@ func TestTmuxCoverage_Complete(t *testing.T) {
 			sessionName := "test-" + scenario.name
 
 			if scenario.windowError {
-				cmdmock.SetResponseWithArgs("tmux", []string{"list-windows", "-t", sessionName, "-F", "#{window_name}"},
+				cmdmock.SetResponseWithArgs("tmux", []string{"list-windows", "-t", sessionName, "-F", windowRoleFormat},
 					"", "error", true)
 			} else {
-				cmdmock.SetResponseWithArgs("tmux", []string{"list-windows", "-t", sessionName, "-F", "#{window_name}"},
+				cmdmock.SetResponseWithArgs("tmux", []string{"list-windows", "-t", sessionName, "-F", windowRoleFormat},
 					scenario.windowOutput, "", false)
 			}
 
@@ -440,17 +441,17 @@ func TestTmuxCoverage_Complete(t *testing.T) {
 			"good-session|1|0|1640000000|1640000000\\nbad-line\\ngood-session2|2|1|1640000000|1640000000\\n\\nempty", "", false)
 
 		// Mock valid sessions only
-		cmdmock.SetResponseWithArgs("tmux", []string{"list-windows", "-t", "good-session", "-F", "#{window_name}"},
+		cmdmock.SetResponseWithArgs("tmux", []string{"list-windows", "-t", "good-session", "-F", windowRoleFormat},
 			"main", "", false)
 		cmdmock.SetResponseWithArgs("tmux", []string{"list-panes", "-t", "good-session", "-a", "-F", "#{pane_id}"},
 			"%0", "", false)
-		cmdmock.SetResponseWithArgs("tmux", []string{"list-windows", "-t", "good-session2", "-F", "#{window_name}"},
+		cmdmock.SetResponseWithArgs("tmux", []string{"list-windows", "-t", "good-session2", "-F", windowRoleFormat},
 			"dev", "", false)
 		cmdmock.SetResponseWithArgs("tmux", []string{"list-panes", "-t", "good-session2", "-a", "-F", "#{pane_id}"},
 			"%0\\n%1", "", false)
 
 		// Test window/pane command failures during session discovery
-		cmdmock.SetResponseWithArgs("tmux", []string{"list-windows", "-t", "fail-windows", "-F", "#{window_name}"},
+		cmdmock.SetResponseWithArgs("tmux", []string{"list-windows", "-t", "fail-windows", "-F", windowRoleFormat},
 			"", "not found", true)
 		cmdmock.SetResponseWithArgs("tmux", []string{"list-panes", "-t", "fail-panes", "-a", "-F", "#{pane_id}"},
 			"", "not found", true)
@@ -530,7 +531,7 @@ func TestStatusDetectionComplete(t *testing.T) {
 	// Setup session with agent window containing various content
 	cmdmock.SetResponseWithArgs("tmux", []string{"list-sessions", "-F", "#{session_name}|#{session_windows}|#{?session_attached,1,0}|#{session_created}|#{session_activity}"},
 		"test-session|1|0|1640000000|1640000000", "", false)
-	cmdmock.SetResponseWithArgs("tmux", []string{"list-windows", "-t", "test-session", "-F", "#{window_name}"},
+	cmdmock.SetResponseWithArgs("tmux", []string{"list-windows", "-t", "test-session", "-F", windowRoleFormat},
 		"agent", "", false)
 	cmdmock.SetResponseWithArgs("tmux", []string{"list-panes", "-t", "test-session", "-a", "-F", "#{pane_id}"},
 		"%0", "", false)
@@ -546,7 +547,7 @@ func TestStatusDetectionComplete(t *testing.T) {
 	}
 
 	for _, content := range contentTests {
-		cmdmock.SetResponseWithArgs("tmux", []string{"capture-pane", "-t", "test-session:agent", "-p"},
+		cmdmock.SetResponseWithArgs("tmux", []string{"capture-pane", "-t", "test-session:{start}", "-p"},
 			content, "", false)
 
 		status, err := td.GetSessionStatus("test-session")
@@ -568,7 +569,7 @@ func TestRemainingEdgeCases(t *testing.T) {
 		cmdmock.Reset()
 		cmdmock.Enable()
 
-		cmdmock.SetResponseWithArgs("tmux", []string{"list-windows", "-t", "empty-test", "-F", "#{window_name}"},
+		cmdmock.SetResponseWithArgs("tmux", []string{"list-windows", "-t", "empty-test", "-F", windowRoleFormat},
 			"", "", false)
 		cmdmock.SetResponseWithArgs("tmux", []string{"list-panes", "-t", "empty-test", "-a", "-F", "#{pane_id}"},
 			"", "", false)
@@ -587,7 +588,7 @@ func TestRemainingEdgeCases(t *testing.T) {
 		cmdmock.Reset()
 		cmdmock.Enable()
 
-		cmdmock.SetResponseWithArgs("tmux", []string{"list-windows", "-t", "test", "-F", "#{window_name}"},
+		cmdmock.SetResponseWithArgs("tmux", []string{"list-windows", "-t", "test", "-F", windowRoleFormat},
 			"main", "", false)
 		cmdmock.SetResponseWithArgs("tmux", []string{"list-panes", "-t", "test", "-a", "-F", "#{pane_id}"},
 			"", "", false)
@@ -49,7 +49,7 @@ func TestTmuxDiscovery_Final(t *testing.T) {
 		cmdmock.SetResponseWithArgs("tmux", []string{"list-sessions", "-F", "#{session_name}|#{session_windows}|#{?session_attached,1,0}|#{session_created}|#{session_activity}"},
 			"session1|2|1|1640000000|1640000010", "", false)
 
-		cmdmock.SetResponseWithArgs("tmux", []string{"list-windows", "-t", "session1", "-F", "#{window_name}"},
+		cmdmock.SetResponseWithArgs("tmux", []string{"list-windows", "-t", "session1", "-F", windowRoleFormat},
 			"agent\\ndev", "", false)
 
 		cmdmock.SetResponseWithArgs("tmux", []string{"list-panes", "-t", "session1", "-a", "-F", "#{pane_id}"},
@@ -135,9 +135,9 @@ func TestTmuxDiscovery_Final(t *testing.T) {
 			"agent-proj-abc123-claude|1|0|1640000000|1640000000\\nregular-session|2|1|1640001000|1640001000", "", false)
 
 		// Mock window calls
-		cmdmock.SetResponseWithArgs("tmux", []string{"list-windows", "-t", "agent-proj-abc123-claude", "-F", "#{window_name}"},
+		cmdmock.SetResponseWithArgs("tmux", []string{"list-windows", "-t", "agent-proj-abc123-claude", "-F", windowRoleFormat},
 			"agent", "", false)
-		cmdmock.SetResponseWithArgs("tmux", []string{"list-windows", "-t", "regular-session", "-F", "#{window_name}"},
+		cmdmock.SetResponseWithArgs("tmux", []string{"list-windows", "-t", "regular-session", "-F", windowRoleFormat},
 			"bash\\nhtop", "", false)
 
 		// Mock pane calls
@@ -169,7 +169,7 @@ func TestTmuxDiscovery_Final(t *testing.T) {
 		cmdmock.SetResponseWithArgs("tmux", []string{"list-sessions", "-F", "#{session_name}|#{session_windows}|#{?session_attached,1,0}|#{session_created}|#{session_activity}"},
 			"test-session|1|1|1640000000|1640000000", "", false)
 
-		cmdmock.SetResponseWithArgs("tmux", []string{"list-windows", "-t", "test-session", "-F", "#{window_name}"},
+		cmdmock.SetResponseWithArgs("tmux", []string{"list-windows", "-t", "test-session", "-F", windowRoleFormat},
 			"main", "", false)
 		cmdmock.SetResponseWithArgs("tmux", []string{"list-panes", "-t", "test-session", "-a", "-F", "#{pane_id}"},
 			"%0", "", false)
@@ -209,11 +209,11 @@ func TestTmuxDiscovery_Final(t *testing.T) {
 		// Test with agent window
 		cmdmock.SetResponseWithArgs("tmux", []string{"list-sessions", "-F", "#{session_name}|#{session_windows}|#{?session_attached,1,0}|#{session_created}|#{session_activity}"},
 			"agent-session|1|0|1640000000|1640000000", "", false)
-		cmdmock.SetResponseWithArgs("tmux", []string{"list-windows", "-t", "agent-session", "-F", "#{window_name}"},
+		cmdmock.SetResponseWithArgs("tmux", []string{"list-windows", "-t", "agent-session", "-F", windowRoleFormat},
 			"agent", "", false)
 		cmdmock.SetResponseWithArgs("tmux", []string{"list-panes", "-t", "agent-session", "-a", "-F", "#{pane_id}"},
 			"%0", "", false)
-		cmdmock.SetResponseWithArgs("tmux", []string{"capture-pane", "-t", "agent-session:agent", "-p"},
+		cmdmock.SetResponseWithArgs("tmux", []string{"capture-pane", "-t", "agent-session:{start}", "-p"},
 			"Thinking about your request...", "", false)
 
 		td := NewTmuxDiscovery()
@@ -238,8 +238,8 @@ func TestTmuxDiscovery_Final(t *testing.T) {
 		if err != nil {
 			t.Errorf("GetSessionStatus for missing session failed: %v", err)
 		}
-		if status2 != "not_found" {
-			t.Errorf("Expected 'not_found' for missing session, got '%s'", status2)
+		if status2 != "dead" {
+			t.Errorf("Expected 'dead' for missing session, got '%s'", status2)
 		}
 	})
 
@@ -249,7 +249,7 @@ func TestTmuxDiscovery_Final(t *testing.T) {
 
 		cmdmock.SetResponseWithArgs("tmux", []string{"list-sessions", "-F", "#{session_name}|#{session_windows}|#{?session_attached,1,0}|#{session_created}|#{session_activity}"},
 			"session1|1|0|1640000000|1640000000", "", false)
-		cmdmock.SetResponseWithArgs("tmux", []string{"list-windows", "-t", "session1", "-F", "#{window_name}"},
+		cmdmock.SetResponseWithArgs("tmux", []string{"list-windows", "-t", "session1", "-F", windowRoleFormat},
 			"main", "", false)
 		cmdmock.SetResponseWithArgs("tmux", []string{"list-panes", "-t", "session1", "-a", "-F", "#{pane_id}"},
 			"%0", "", false)
@@ -519,10 +519,10 @@ func TestWindowPaneParsing_Final(t *testing.T) {
 				sessionName := fmt.Sprintf("test-session-%s", tt.name)
 
 				if tt.windowError {
-					cmdmock.SetResponseWithArgs("tmux", []string{"list-windows", "-t", sessionName, "-F", "#{window_name}"},
+					cmdmock.SetResponseWithArgs("tmux", []string{"list-windows", "-t", sessionName, "-F", windowRoleFormat},
 						"", "session not found", true)
 				} else {
-					cmdmock.SetResponseWithArgs("tmux", []string{"list-windows", "-t", sessionName, "-F", "#{window_name}"},
+					cmdmock.SetResponseWithArgs("tmux", []string{"list-windows", "-t", sessionName, "-F", windowRoleFormat},
 						tt.windowOutput, "", false)
 				}
 
@@ -566,11 +566,11 @@ func TestErrorHandling_Final(t *testing.T) {
 			"good-session|1|0|1640000000|1640000000\\nbad-line\\ngood-session2|2|1|1640000000|1640000000\\n\\nempty-line", "", false)
 
 		// Mock window/pane calls for valid sessions only
-		cmdmock.SetResponseWithArgs("tmux", []string{"list-windows", "-t", "good-session", "-F", "#{window_name}"},
+		cmdmock.SetResponseWithArgs("tmux", []string{"list-windows", "-t", "good-session", "-F", windowRoleFormat},
 			"main", "", false)
 		cmdmock.SetResponseWithArgs("tmux", []string{"list-panes", "-t", "good-session", "-a", "-F", "#{pane_id}"},
 			"%0", "", false)
-		cmdmock.SetResponseWithArgs("tmux", []string{"list-windows", "-t", "good-session2", "-F", "#{window_name}"},
+		cmdmock.SetResponseWithArgs("tmux", []string{"list-windows", "-t", "good-session2", "-F", windowRoleFormat},
 			"main\\ndev", "", false)
 		cmdmock.SetResponseWithArgs("tmux", []string{"list-panes", "-t", "good-session2", "-a", "-F", "#{pane_id}"},
 			"%0\\n%1", "", false)
@@ -593,7 +593,7 @@ func TestErrorHandling_Final(t *testing.T) {
 	t.Run("AgentContentFailure", func(t *testing.T) {
 		setUp_Final()
 
-		cmdmock.SetResponseWithArgs("tmux", []string{"capture-pane", "-t", "missing-session:agent", "-p"},
+		cmdmock.SetResponseWithArgs("tmux", []string{"capture-pane", "-t", "missing-session:{start}", "-p"},
 			"", "no such window", true)
 
 		td := NewTmuxDiscovery()
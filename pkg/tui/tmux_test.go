@@ -1,6 +1,7 @@
 package tui
 
 import (
+	"errors"
 	"fmt"
 	"os"
 	"strings"
@@ -8,6 +9,7 @@ import (
 	"time"
 
 	"github.com/nehpz/claudicus/pkg/testutil/cmdmock"
+	"github.com/nehpz/claudicus/pkg/tmuxops"
 )
 
 // TmuxMock implements TmuxInterface for testing
@@ -16,6 +18,7 @@ type TmuxMock struct {
 	ListWindowsFunc  func(sessionName string) ([]byte, error)
 	ListPanesFunc    func(sessionName string) ([]byte, error)
 	CapturePaneFunc  func(sessionName string) ([]byte, error)
+	DisplayPaneFunc  func(sessionName string) ([]byte, error)
 }
 
 // ListSessions calls the mock function
@@ -50,6 +53,14 @@ func (m *TmuxMock) CapturePane(sessionName string) ([]byte, error) {
 	return nil, nil
 }
 
+// DisplayAgentPane calls the mock function
+func (m *TmuxMock) DisplayAgentPane(sessionName string) ([]byte, error) {
+	if m.DisplayPaneFunc != nil {
+		return m.DisplayPaneFunc(sessionName)
+	}
+	return nil, nil
+}
+
 // Helper function to strip cmdmock's -n prefix from output
 // This accounts for cmdmock using 'echo -n' which adds '-n ' to the beginning
 func stripCmdmockPrefix(s string) string {
@@ -801,12 +812,13 @@ func TestGetSessionStatus(t *testing.T) {
 		captureError   bool
 	}{
 		{
-			name:           "Attached session",
+			name:           "Attached session - attachment is not a status",
 			sessionName:    "attached-session",
 			tmuxOutput:     "attached-session|1|1|1640000000|1640000000",
 			windowOutput:   "main",
 			paneOutput:     "%0",
-			expectedStatus: "attached",
+			agentContent:   "$ waiting for command",
+			expectedStatus: "ready",
 		},
 		{
 			name:           "Session with agent window - ready",
@@ -845,12 +857,12 @@ func TestGetSessionStatus(t *testing.T) {
 			expectedStatus: "running",
 		},
 		{
-			name:           "Session without agent window",
+			name:           "Session with a blank agent pane - starting",
 			sessionName:    "regular-session",
 			tmuxOutput:     "regular-session|1|0|1640000000|1640000000",
 			windowOutput:   "bash",
 			paneOutput:     "%0",
-			expectedStatus: "ready",
+			expectedStatus: "starting",
 		},
 		{
 			name:           "Nonexistent session",
@@ -858,7 +870,7 @@ func TestGetSessionStatus(t *testing.T) {
 			tmuxOutput:     "other-session|1|0|1640000000|1640000000",
 			windowOutput:   "bash",
 			paneOutput:     "%0",
-			expectedStatus: "not_found",
+			expectedStatus: "dead",
 		},
 		{
 			name:           "Tmux command fails",
@@ -867,13 +879,13 @@ func TestGetSessionStatus(t *testing.T) {
 			expectedStatus: "unknown",
 		},
 		{
-			name:           "Capture pane fails - defaults to ready",
+			name:           "Capture pane fails - unknown",
 			sessionName:    "capture-fail",
 			tmuxOutput:     "capture-fail|1|0|1640000000|1640000000",
 			windowOutput:   "agent",
 			paneOutput:     "%0",
 			captureError:   true,
-			expectedStatus: "ready",
+			expectedStatus: "unknown",
 		},
 	}
 
@@ -905,9 +917,9 @@ func TestGetSessionStatus(t *testing.T) {
 
 			status, err := td.GetSessionStatus(tt.sessionName)
 
-			if tt.expectedStatus == "unknown" {
+			if tt.tmuxError {
 				if err == nil {
-					t.Error("Expected error for unknown status")
+					t.Error("Expected error when tmux fails")
 				}
 			} else {
 				if err != nil {
@@ -1352,3 +1364,153 @@ func TestDiscoverTmuxSessions_WindowPaneErrors(t *testing.T) {
 		t.Errorf("Expected fail session to have 0 panes, got %d", failSession.Panes)
 	}
 }
+
+// Test agent pane lookup used by uzi recover
+func TestGetAgentPane(t *testing.T) {
+	td := NewTmuxDiscovery()
+	td.tmux = &TmuxMock{
+		DisplayPaneFunc: func(sessionName string) ([]byte, error) {
+			if sessionName == "agent-repo-abc123-sarah" {
+				return []byte("/home/u/.local/share/uzi/worktrees/a|b|claude\n"), nil
+			}
+			if sessionName == "agent-repo-abc123-bad" {
+				return []byte("garbage"), nil
+			}
+			return nil, fmt.Errorf("can't find session")
+		},
+	}
+
+	pane, err := td.GetAgentPane("agent-repo-abc123-sarah")
+	if err != nil {
+		t.Fatalf("GetAgentPane should not error: %v", err)
+	}
+	if pane.Path != "/home/u/.local/share/uzi/worktrees/a|b" || pane.Command != "claude" {
+		t.Errorf("Unexpected pane info: %+v", pane)
+	}
+
+	if _, err := td.GetAgentPane("agent-repo-abc123-bad"); err == nil {
+		t.Error("Expected error for malformed pane output")
+	}
+	if _, err := td.GetAgentPane("agent-repo-abc123-gone"); err == nil {
+		t.Error("Expected error when tmux fails")
+	}
+}
+
+func TestDiscoverTmuxSessions_RemoteHosts(t *testing.T) {
+	td := NewTmuxDiscovery()
+	td.tmux = &TmuxMock{
+		ListSessionsFunc: func() ([]byte, error) {
+			return []byte("agent-repo-abc123-sarah|2|0|1640995200|1640995300\n"), nil
+		},
+		ListWindowsFunc: func(string) ([]byte, error) { return []byte("agent\nuzi-dev\n"), nil },
+	}
+	var remoteCaptures []string
+	td.AddHost("box", &TmuxMock{
+		ListSessionsFunc: func() ([]byte, error) {
+			return []byte("agent-repo-abc123-emily|1|0|1640995200|1640995300\n"), nil
+		},
+		ListWindowsFunc: func(string) ([]byte, error) { return []byte("agent\n"), nil },
+		CapturePaneFunc: func(sessionName string) ([]byte, error) {
+			remoteCaptures = append(remoteCaptures, sessionName)
+			return []byte("working"), nil
+		},
+	})
+	td.AddHost("down", &TmuxMock{
+		ListSessionsFunc: func() ([]byte, error) { return nil, fmt.Errorf("ssh: connect to host down: Connection refused") },
+	})
+
+	sessions, err := td.GetAllSessions()
+	if err != nil {
+		t.Fatalf("GetAllSessions should ignore unreachable hosts: %v", err)
+	}
+	if len(sessions) != 2 {
+		t.Fatalf("Expected local and remote sessions, got %+v", sessions)
+	}
+	if host := sessions["agent-repo-abc123-sarah"].Host; host != "" {
+		t.Errorf("Expected local session without a host, got %q", host)
+	}
+	remote := sessions["agent-repo-abc123-emily"]
+	if remote.Host != "box" || len(remote.WindowNames) != 1 {
+		t.Errorf("Expected remote session on box with its own windows, got %+v", remote)
+	}
+
+	if _, err := td.getAgentWindowContent("agent-repo-abc123-emily"); err != nil {
+		t.Fatalf("getAgentWindowContent error: %v", err)
+	}
+	if len(remoteCaptures) != 1 {
+		t.Errorf("Expected the remote pane captured on box, got %v", remoteCaptures)
+	}
+}
+
+// versionedTmuxMock is a TmuxMock that reports a tmux version
+type versionedTmuxMock struct {
+	TmuxMock
+	version tmuxops.Version
+}
+
+func (m *versionedTmuxMock) Version() (tmuxops.Version, error) {
+	return m.version, nil
+}
+
+func TestSessionFormat(t *testing.T) {
+	current := sessionFormat(tmuxops.Version{Major: 3, Minor: 3})
+	if !strings.Contains(current, "|#{session_activity}|") {
+		t.Errorf("Expected session_activity for tmux 3.3, got %q", current)
+	}
+	old := sessionFormat(tmuxops.Version{Major: 2, Minor: 0})
+	if strings.Contains(old, "session_activity") || !strings.Contains(old, "|#{session_created}|#{session_created}|") {
+		t.Errorf("Expected session_created in place of session_activity for tmux 2.0, got %q", old)
+	}
+}
+
+func TestParseSessionLine_NoActivity(t *testing.T) {
+	td := NewTmuxDiscovery()
+	session, err := td.parseSessionLine("session1|1|0|1640000000|")
+	if err != nil {
+		t.Fatalf("parseSessionLine error: %v", err)
+	}
+	if !session.LastUsed.Equal(session.Created) {
+		t.Errorf("Expected the creation time as last use, got %v and %v", session.LastUsed, session.Created)
+	}
+}
+
+func TestCheckVersion(t *testing.T) {
+	td := NewTmuxDiscovery()
+	td.tmux = &versionedTmuxMock{version: tmuxops.Version{Major: 3, Minor: 4, Raw: "3.4"}}
+	if _, err := td.CheckVersion(); err != nil {
+		t.Errorf("tmux 3.4 should pass: %v", err)
+	}
+
+	td.tmux = &versionedTmuxMock{version: tmuxops.Version{Major: 1, Minor: 8, Raw: "1.8"}}
+	if _, err := td.CheckVersion(); !errors.Is(err, tmuxops.ErrUnsupportedVersion) {
+		t.Errorf("tmux 1.8 should be unsupported, got %v", err)
+	}
+
+	td.tmux = &TmuxMock{}
+	if _, err := td.CheckVersion(); err != nil {
+		t.Errorf("A tmux without a version should pass: %v", err)
+	}
+}
+
+func TestDiscoverTmuxSessions_SkipsOldRemoteTmux(t *testing.T) {
+	td := NewTmuxDiscovery()
+	td.tmux = &TmuxMock{}
+	listed := false
+	td.AddHost("old", &versionedTmuxMock{
+		TmuxMock: TmuxMock{
+			ListSessionsFunc: func() ([]byte, error) {
+				listed = true
+				return []byte("agent-repo-abc123-emily|1|0|1640995200|1640995300\n"), nil
+			},
+		},
+		version: tmuxops.Version{Major: 1, Minor: 8, Raw: "1.8"},
+	})
+
+	sessions, err := td.GetAllSessions()
+	if err != nil {
+		t.Fatalf("GetAllSessions error: %v", err)
+	}
+	if listed || len(sessions) != 0 {
+		t.Errorf("Expected the host with tmux 1.8 skipped, got %+v", sessions)
+	}
+}
@@ -0,0 +1,185 @@
+// Copyright (c) Subtrace, Inc.
+// SPDX-License-Identifier: BSD-3-Clause
+
+package tui
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/charmbracelet/bubbles/key"
+	"github.com/charmbracelet/bubbles/viewport"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+	"github.com/nehpz/claudicus/pkg/scrollback"
+)
+
+// todoLister is implemented by UziInterface backends that can collect the
+// outstanding items every agent printed, as `uzi todos` does
+type todoLister interface {
+	ListTodos() ([]scrollback.Todo, error)
+}
+
+// TodosMsg carries the outstanding items of every agent
+type TodosMsg struct {
+	Todos []scrollback.Todo
+	Error string
+}
+
+// TodosView is an overlay listing, by agent, the TODO and FIXME markers and
+// "Next steps" entries the agents printed, so the operator can see what each
+// agent believes remains. The items are collected each time it opens; r
+// collects them again.
+type TodosView struct {
+	visible  bool
+	loading  bool
+	err      string
+	todos    []scrollback.Todo
+	viewport viewport.Model
+	keys     *KeyMap
+	theme    *Theme
+	onLoad   func() tea.Cmd
+}
+
+// NewTodosView creates a hidden todos view that collects the items through
+// onLoad
+func NewTodosView(keys *KeyMap, onLoad func() tea.Cmd) *TodosView {
+	return &TodosView{keys: keys, theme: DefaultTheme(), viewport: viewport.New(80, 15), onLoad: onLoad}
+}
+
+// SetTheme switches the style profile used to render the view
+func (v *TodosView) SetTheme(theme *Theme) {
+	v.theme = theme
+	v.render()
+}
+
+// SetSize fits the items into the lower half of a terminal of the given size
+func (v *TodosView) SetSize(width, height int) {
+	v.viewport.Width = max(20, min(width-4, 160))
+	v.viewport.Height = max(5, height/2-4)
+	v.render()
+}
+
+// Show opens the view, keeping the last items on screen until Reload
+// replaces them
+func (v *TodosView) Show() {
+	v.visible = true
+}
+
+// Hide closes the view
+func (v *TodosView) Hide() {
+	v.visible = false
+}
+
+// Focused reports whether the view is open
+func (v *TodosView) Focused() bool {
+	return v.visible
+}
+
+// Loading reports whether items are being collected
+func (v *TodosView) Loading() bool {
+	return v.loading
+}
+
+// Todos returns the shown items
+func (v *TodosView) Todos() []scrollback.Todo {
+	return v.todos
+}
+
+// Reload starts collecting the items
+func (v *TodosView) Reload() tea.Cmd {
+	if v.onLoad == nil || v.loading {
+		return nil
+	}
+	v.loading = true
+	return v.onLoad()
+}
+
+// SetTodos shows the collected items
+func (v *TodosView) SetTodos(todos []scrollback.Todo, err string) {
+	v.loading = false
+	v.err = err
+	v.todos = todos
+	v.render()
+	v.viewport.GotoTop()
+}
+
+// render lists the items under their agents at the current width
+func (v *TodosView) render() {
+	t := resolveTheme(v.theme)
+	lines := make([]string, 0, len(v.todos))
+	previous := ""
+	for _, todo := range v.todos {
+		if todo.Session != previous {
+			if previous != "" {
+				lines = append(lines, "")
+			}
+			lines = append(lines, t.Primary.Render(todo.Agent))
+			previous = todo.Session
+		}
+		kind := fmt.Sprintf("%-9s ", todo.Kind)
+		location := "  " + todo.Location()
+		text := truncateLine(todo.Text, max(10, v.viewport.Width-lipgloss.Width(kind)-lipgloss.Width(location)-2))
+		lines = append(lines, "  "+t.Accent.Render(kind)+text+t.Muted.Render(location))
+	}
+	v.viewport.SetContent(strings.Join(lines, "\n"))
+}
+
+// Update scrolls the items, collects them again on r, and closes the view on
+// Esc
+func (v *TodosView) Update(msg tea.Msg) tea.Cmd {
+	keyMsg, ok := msg.(tea.KeyMsg)
+	if !ok {
+		return nil
+	}
+	switch {
+	case key.Matches(keyMsg, v.keys.Escape), key.Matches(keyMsg, v.keys.Todos):
+		v.Hide()
+		return nil
+	case key.Matches(keyMsg, v.keys.Refresh):
+		return v.Reload()
+	}
+	var cmd tea.Cmd
+	v.viewport, cmd = v.viewport.Update(keyMsg)
+	return cmd
+}
+
+// View renders the items in a bordered panel
+func (v *TodosView) View() string {
+	if !v.visible {
+		return ""
+	}
+
+	t := resolveTheme(v.theme)
+	title := t.Accent.Render("Agent TODOs")
+
+	var body string
+	switch {
+	case v.err != "":
+		body = t.Error.Render("Error: " + v.err)
+	case v.loading && v.todos == nil:
+		body = t.Muted.Render("Reading agent output...")
+	case len(v.todos) == 0:
+		body = t.Muted.Render("No agent has mentioned a TODO, FIXME, or next step")
+	default:
+		summary := fmt.Sprintf("%d item(s) from %d agent(s)", len(v.todos), countTodoAgents(v.todos))
+		if v.loading {
+			summary += " (refreshing...)"
+		}
+		body = lipgloss.JoinVertical(lipgloss.Left, t.Muted.Render(summary), v.viewport.View())
+	}
+
+	footer := "[↑/↓ pgup/pgdn] scroll  [r] refresh  [ESC] close"
+	return t.Border.Copy().
+		Width(v.viewport.Width + 2).
+		Render(lipgloss.JoinVertical(lipgloss.Left, title, "", body, "", t.Muted.Render(footer)))
+}
+
+// countTodoAgents counts the sessions with items
+func countTodoAgents(todos []scrollback.Todo) int {
+	sessions := make(map[string]bool)
+	for _, todo := range todos {
+		sessions[todo.Session] = true
+	}
+	return len(sessions)
+}
@@ -0,0 +1,135 @@
+// Copyright (c) Subtrace, Inc.
+// SPDX-License-Identifier: BSD-3-Clause
+
+package tui
+
+import (
+	"strings"
+	"testing"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/nehpz/claudicus/pkg/scrollback"
+	"github.com/nehpz/claudicus/pkg/state"
+	"github.com/nehpz/claudicus/pkg/testutil/cmdmock"
+	"github.com/nehpz/claudicus/pkg/tmuxops"
+)
+
+// todoUziMock serves fixed agent todos
+type todoUziMock struct {
+	MockUziInterface
+	todos []scrollback.Todo
+	calls int
+}
+
+func (m *todoUziMock) ListTodos() ([]scrollback.Todo, error) {
+	m.calls++
+	return m.todos, nil
+}
+
+func TestTodosView_View(t *testing.T) {
+	keys := DefaultKeyMap()
+	loads := 0
+	view := NewTodosView(&keys, func() tea.Cmd {
+		loads++
+		return nil
+	})
+	view.SetTheme(PlainTheme())
+	view.SetSize(120, 40)
+	if view.View() != "" {
+		t.Error("Hidden todos view should render nothing")
+	}
+
+	view.Show()
+	view.Reload()
+	if output := view.View(); loads != 1 || !strings.Contains(output, "Agent TODOs") || !strings.Contains(output, "Reading agent output") {
+		t.Errorf("Expected the loading view, got %q", output)
+	}
+
+	view.SetTodos([]scrollback.Todo{
+		{Session: "agent-app-abc123-emily", Agent: "emily", Kind: scrollback.KindFixme, Source: scrollback.SourcePane, Line: 12, Text: "flaky login test"},
+		{Session: "agent-app-abc123-sarah", Agent: "sarah", Kind: scrollback.KindNextStep, Source: scrollback.SourceTranscript, File: "a1.jsonl", Line: 3, Text: "add pagination"},
+	}, "")
+	output := view.View()
+	for _, want := range []string{"2 item(s) from 2 agent(s)", "emily", "FIXME", "flaky login test", "pane:12", "sarah", "next step", "add pagination", "transcript a1.jsonl:3"} {
+		if !strings.Contains(output, want) {
+			t.Errorf("Expected %q in the view, got %q", want, output)
+		}
+	}
+
+	view.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune{'r'}})
+	if loads != 2 || !strings.Contains(view.View(), "refreshing") {
+		t.Errorf("Expected r to collect the items again, loads = %d", loads)
+	}
+	view.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune{'r'}})
+	if loads != 2 {
+		t.Error("Expected no second load while one is running")
+	}
+
+	view.SetTodos(nil, "")
+	if output := view.View(); !strings.Contains(output, "No agent has mentioned") {
+		t.Errorf("Expected the empty message, got %q", output)
+	}
+
+	view.Update(tea.KeyMsg{Type: tea.KeyEsc})
+	if view.Focused() {
+		t.Error("Expected Esc to close the todos view")
+	}
+}
+
+func TestApp_TodosKey(t *testing.T) {
+	mock := &todoUziMock{todos: []scrollback.Todo{
+		{Session: "agent-app-abc123-sarah", Agent: "sarah", Kind: scrollback.KindTodo, Source: scrollback.SourcePane, Line: 4, Text: "handle the timeout"},
+	}}
+	app := NewApp(mock)
+	defer app.Cleanup()
+
+	_, cmd := app.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune{'T'}})
+	if app.modals.Top() != app.todosView || cmd == nil {
+		t.Fatal("Expected the todos view on top and loading after 'T'")
+	}
+	msg, ok := cmd().(TodosMsg)
+	if !ok || len(msg.Todos) != 1 {
+		t.Fatalf("Expected a TodosMsg, got %+v", msg)
+	}
+	app.Update(msg)
+	if app.todosView.Loading() || len(app.todosView.Todos()) != 1 {
+		t.Errorf("Expected the todos in the view, got %+v", app.todosView.Todos())
+	}
+
+	app.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune{'T'}})
+	if app.todosView.Focused() {
+		t.Error("Expected 'T' to close the todos view")
+	}
+}
+
+func TestApp_TodosWithoutLister(t *testing.T) {
+	app := NewApp(&MockUziInterface{})
+	defer app.Cleanup()
+	msg := app.listTodos()().(TodosMsg)
+	if !strings.Contains(msg.Error, "not supported") {
+		t.Errorf("Expected an unsupported error, got %+v", msg)
+	}
+}
+
+func TestUziCLI_ListTodos(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+	setupUziTest()
+	cli := NewUziCLI()
+	cli.stateManager = &mockStateManagerForTest{
+		statePath: createTempStateFile(t, map[string]state.AgentState{
+			"agent-app-abc123-sarah": {WorktreePath: t.TempDir()},
+			"agent-app-abc123-john":  {WorktreePath: t.TempDir()},
+		}),
+		activeSessions: []string{"agent-app-abc123-sarah", "agent-app-abc123-john"},
+	}
+	cmdmock.SetResponseWithArgs("tmux", tmuxops.ScrollbackArgs("agent-app-abc123-sarah", 0), "Done.\n\nNext steps:\n1. add pagination\n", "", false)
+	cmdmock.SetResponseWithArgs("tmux", tmuxops.ScrollbackArgs("agent-app-abc123-john", 0), "", "can't find session", true)
+
+	todos, err := cli.ListTodos()
+	if err != nil {
+		t.Fatalf("ListTodos() error = %v", err)
+	}
+	if len(todos) != 1 || todos[0].Agent != "sarah" || todos[0].Text != "add pagination" || todos[0].Line != 4 {
+		t.Errorf("ListTodos() = %+v, want sarah's next step on line 4", todos)
+	}
+}
@@ -0,0 +1,114 @@
+// Copyright (c) Subtrace, Inc.
+// SPDX-License-Identifier: BSD-3-Clause
+
+package tui
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+)
+
+// TUIState holds view preferences that persist across TUI runs
+type TUIState struct {
+	Pinned       []string       `json:"pinned,omitempty"`       // Session names kept at the top of the list
+	Presets      []FilterPreset `json:"presets,omitempty"`      // Saved filters, applied with the number keys in order
+	TutorialSeen bool           `json:"tutorialSeen,omitempty"` // The first-launch tutorial was finished or skipped
+}
+
+// maxFilterPresets is how many presets the number keys 1-9 can switch between
+const maxFilterPresets = 9
+
+// FilterPreset is a saved combination of status filter, tag, and search
+type FilterPreset struct {
+	Name   string `json:"name"`
+	Status string `json:"status,omitempty"` // "stuck" or "working"; empty shows every status
+	Tag    string `json:"tag,omitempty"`
+	Search string `json:"search,omitempty"`
+}
+
+// IsEmpty reports whether the preset filters nothing
+func (p FilterPreset) IsEmpty() bool {
+	return p.Status == "" && p.Tag == "" && p.Search == ""
+}
+
+// DefaultTUIStatePath returns the location of tui_state.json next to the agent state file
+func DefaultTUIStatePath() string {
+	homeDir, err := os.UserHomeDir()
+	if err != nil {
+		return ""
+	}
+	return filepath.Join(homeDir, ".local", "share", "uzi", "tui_state.json")
+}
+
+// LoadTUIState reads the TUI state from path, returning an empty state if the file
+// does not exist yet
+func LoadTUIState(path string) (*TUIState, error) {
+	tuiState := &TUIState{}
+	if path == "" {
+		return tuiState, nil
+	}
+
+	data, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return tuiState, nil
+	}
+	if err != nil {
+		return tuiState, err
+	}
+
+	if err := json.Unmarshal(data, tuiState); err != nil {
+		return &TUIState{}, err
+	}
+	return tuiState, nil
+}
+
+// Save writes the TUI state to path, creating the parent directory if needed
+func (s *TUIState) Save(path string) error {
+	if path == "" {
+		return errors.New("no TUI state path")
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return err
+	}
+
+	data, err := json.MarshalIndent(s, "", "  ")
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(path, data, 0644)
+}
+
+// SetPinned replaces the pinned sessions with a sorted copy of names
+func (s *TUIState) SetPinned(names []string) {
+	s.Pinned = append([]string(nil), names...)
+	sort.Strings(s.Pinned)
+}
+
+// SavePreset stores a preset and returns the number key that applies it. A
+// preset with the same name is replaced in place; otherwise the preset takes
+// the next free number, failing once all of them are taken.
+func (s *TUIState) SavePreset(preset FilterPreset) (int, error) {
+	for i, existing := range s.Presets {
+		if existing.Name == preset.Name {
+			s.Presets[i] = preset
+			return i + 1, nil
+		}
+	}
+	if len(s.Presets) >= maxFilterPresets {
+		return 0, fmt.Errorf("all %d presets are taken; save under an existing name to replace one", maxFilterPresets)
+	}
+	s.Presets = append(s.Presets, preset)
+	return len(s.Presets), nil
+}
+
+// Preset returns the preset applied by number key n
+func (s *TUIState) Preset(n int) (FilterPreset, bool) {
+	if n < 1 || n > len(s.Presets) {
+		return FilterPreset{}, false
+	}
+	return s.Presets[n-1], true
+}
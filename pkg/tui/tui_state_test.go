@@ -0,0 +1,318 @@
+// Copyright (c) Subtrace, Inc.
+// SPDX-License-Identifier: BSD-3-Clause
+
+package tui
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+func TestTUIStateLoadMissingFile(t *testing.T) {
+	tuiState, err := LoadTUIState(filepath.Join(t.TempDir(), "tui_state.json"))
+	if err != nil {
+		t.Fatalf("Expected no error for missing file, got %v", err)
+	}
+	if len(tuiState.Pinned) != 0 {
+		t.Errorf("Expected no pinned sessions, got %v", tuiState.Pinned)
+	}
+}
+
+func TestTUIStateSaveAndLoad(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "nested", "tui_state.json")
+
+	tuiState := &TUIState{}
+	tuiState.SetPinned([]string{"agent-proj-abc123-sarah", "agent-proj-abc123-john"})
+	if err := tuiState.Save(path); err != nil {
+		t.Fatalf("Save() error = %v", err)
+	}
+
+	loaded, err := LoadTUIState(path)
+	if err != nil {
+		t.Fatalf("LoadTUIState() error = %v", err)
+	}
+	want := []string{"agent-proj-abc123-john", "agent-proj-abc123-sarah"}
+	if !reflect.DeepEqual(loaded.Pinned, want) {
+		t.Errorf("Pinned = %v, want %v", loaded.Pinned, want)
+	}
+}
+
+func TestTUIStateLoadCorruptFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "tui_state.json")
+	if err := os.WriteFile(path, []byte("{not json"), 0644); err != nil {
+		t.Fatalf("Failed to write state file: %v", err)
+	}
+
+	tuiState, err := LoadTUIState(path)
+	if err == nil {
+		t.Error("Expected error for corrupt file")
+	}
+	if tuiState == nil || len(tuiState.Pinned) != 0 {
+		t.Errorf("Expected empty state for corrupt file, got %v", tuiState)
+	}
+}
+
+func pinTestSessions() []SessionInfo {
+	return []SessionInfo{
+		{Name: "agent-proj-abc123-john", AgentName: "john", Status: "running", Prompt: "fix login"},
+		{Name: "agent-proj-abc123-mary", AgentName: "mary", Status: "ready", Prompt: "write docs"},
+		{Name: "agent-proj-abc123-sarah", AgentName: "sarah", Status: "ready", Prompt: "refactor api"},
+	}
+}
+
+func listItemNames(m *ListModel) []string {
+	names := []string{}
+	for _, item := range m.Items() {
+		names = append(names, item.(SessionListItem).session.AgentName)
+	}
+	return names
+}
+
+func TestListPinnedSessionsFirst(t *testing.T) {
+	m := NewListModel(80, 24)
+	m.LoadSessions(pinTestSessions())
+
+	if !m.TogglePin("agent-proj-abc123-sarah") {
+		t.Fatal("Expected sarah to be pinned")
+	}
+	if got := listItemNames(&m); !reflect.DeepEqual(got, []string{"sarah", "john", "mary"}) {
+		t.Errorf("Expected pinned session first, got %v", got)
+	}
+	if selected := m.SelectedSession(); selected == nil || selected.AgentName != "sarah" {
+		t.Errorf("Expected pinned session to stay selected, got %v", selected)
+	}
+
+	if m.TogglePin("agent-proj-abc123-sarah") {
+		t.Fatal("Expected sarah to be unpinned")
+	}
+	if got := listItemNames(&m); !reflect.DeepEqual(got, []string{"john", "mary", "sarah"}) {
+		t.Errorf("Expected original order after unpinning, got %v", got)
+	}
+}
+
+func TestListPinnedSessionsBypassSearch(t *testing.T) {
+	m := NewListModel(80, 24)
+	m.LoadSessions(pinTestSessions())
+	m.SetPinned([]string{"agent-proj-abc123-mary"})
+
+	m.SetSearchQuery("login")
+	if got := listItemNames(&m); !reflect.DeepEqual(got, []string{"mary", "john"}) {
+		t.Errorf("Expected pinned session to survive search, got %v", got)
+	}
+
+	if item := m.Items()[0].(SessionListItem); !item.pinned {
+		t.Error("Expected first item to be marked pinned")
+	}
+}
+
+func TestListSetPinnedIgnoresUnknownSessions(t *testing.T) {
+	m := NewListModel(80, 24)
+	m.LoadSessions(pinTestSessions())
+	m.SetPinned([]string{"agent-proj-abc123-gone"})
+
+	if got := listItemNames(&m); !reflect.DeepEqual(got, []string{"john", "mary", "sarah"}) {
+		t.Errorf("Expected unknown pins to be ignored, got %v", got)
+	}
+}
+
+func TestAppPinKeyPersistsState(t *testing.T) {
+	app := NewApp(&MockUziInterface{})
+	defer app.Cleanup()
+
+	app.tuiStatePath = filepath.Join(t.TempDir(), "tui_state.json")
+	app.list.SetPinned(nil)
+	app.list.LoadSessions(pinTestSessions())
+
+	app.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune{'P'}})
+
+	if !app.list.IsPinned("agent-proj-abc123-john") {
+		t.Fatal("Expected selected session to be pinned")
+	}
+
+	loaded, err := LoadTUIState(app.tuiStatePath)
+	if err != nil {
+		t.Fatalf("LoadTUIState() error = %v", err)
+	}
+	if !reflect.DeepEqual(loaded.Pinned, []string{"agent-proj-abc123-john"}) {
+		t.Errorf("Expected pin to be persisted, got %v", loaded.Pinned)
+	}
+}
+
+func TestAppPinKeyReportsSaveError(t *testing.T) {
+	app := NewApp(&MockUziInterface{})
+	defer app.Cleanup()
+
+	// A regular file where the state directory should be makes saving fail
+	blocker := filepath.Join(t.TempDir(), "blocker")
+	if err := os.WriteFile(blocker, nil, 0644); err != nil {
+		t.Fatal(err)
+	}
+	app.tuiStatePath = filepath.Join(blocker, "tui_state.json")
+	app.list.SetPinned(nil)
+	app.list.LoadSessions(pinTestSessions())
+
+	app.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune{'P'}})
+
+	if !app.list.IsPinned("agent-proj-abc123-john") {
+		t.Fatal("Expected the pin to apply for this run")
+	}
+	if !app.noticeIsError || !strings.Contains(app.notice, "pin applies for this run only") {
+		t.Errorf("Expected an error notice for the failed save, got %q", app.notice)
+	}
+}
+
+func TestTUIStateSavePreset(t *testing.T) {
+	tuiState := &TUIState{}
+
+	number, err := tuiState.SavePreset(FilterPreset{Name: "stuck", Status: "stuck"})
+	if err != nil || number != 1 {
+		t.Fatalf("SavePreset() = %d, %v, want 1", number, err)
+	}
+	number, _ = tuiState.SavePreset(FilterPreset{Name: "project-x", Tag: "project-x"})
+	if number != 2 {
+		t.Errorf("Expected the second preset on 2, got %d", number)
+	}
+
+	// Saving under an existing name keeps its number
+	number, _ = tuiState.SavePreset(FilterPreset{Name: "stuck", Status: "stuck", Search: "claude"})
+	if preset, ok := tuiState.Preset(1); number != 1 || !ok || preset.Search != "claude" {
+		t.Errorf("Expected stuck replaced on 1, got %d, %+v", number, preset)
+	}
+	if _, ok := tuiState.Preset(3); ok {
+		t.Error("Expected no preset on 3")
+	}
+
+	for i := len(tuiState.Presets); i < maxFilterPresets; i++ {
+		if _, err := tuiState.SavePreset(FilterPreset{Name: fmt.Sprintf("preset %d", i), Search: "x"}); err != nil {
+			t.Fatalf("SavePreset() error = %v", err)
+		}
+	}
+	if _, err := tuiState.SavePreset(FilterPreset{Name: "one too many"}); err == nil {
+		t.Error("Expected an error once every number key is taken")
+	}
+}
+
+func TestListFilterPresetRoundTrip(t *testing.T) {
+	sessions := pinTestSessions()
+	sessions[0].Tags = []string{"project-x"}
+	sessions[2].Tags = []string{"project-x", "github#7"}
+
+	m := NewListModel(80, 24)
+	m.LoadSessions(sessions)
+	if got := m.Tags(); !reflect.DeepEqual(got, []string{"github#7", "project-x"}) {
+		t.Errorf("Tags() = %v", got)
+	}
+
+	// Cycling walks the tags in order and then shows everything again
+	for _, want := range []string{"github#7", "project-x", ""} {
+		if got := m.CycleTagFilter(); got != want {
+			t.Errorf("CycleTagFilter() = %q, want %q", got, want)
+		}
+	}
+
+	m.SetTagFilter("project-x")
+	m.SetSearchQuery("api")
+	if got := listItemNames(&m); !reflect.DeepEqual(got, []string{"sarah"}) {
+		t.Errorf("Expected only sarah tagged project-x and matching api, got %v", got)
+	}
+	preset := m.FilterPreset()
+	if want := (FilterPreset{Tag: "project-x", Search: "api"}); preset != want {
+		t.Errorf("FilterPreset() = %+v, want %+v", preset, want)
+	}
+
+	m.ClearFilter()
+	m.ClearSearch()
+	m.ApplyFilterPreset(FilterPreset{Name: "ready on x", Status: "working", Tag: "project-x"})
+	if m.filterType != FilterWorking || m.TagFilter() != "project-x" || m.SearchQuery() != "" {
+		t.Errorf("Expected the preset applied, got %+v", m.FilterPreset())
+	}
+	if !strings.Contains(m.GetFilterStatus(), "Tag: project-x") {
+		t.Errorf("Expected the tag in the filter status, got %q", m.GetFilterStatus())
+	}
+}
+
+func TestAppFilterPresetKeys(t *testing.T) {
+	app := NewApp(&MockUziInterface{})
+	defer app.Cleanup()
+
+	app.tuiState = &TUIState{}
+	app.tuiStatePath = filepath.Join(t.TempDir(), "tui_state.json")
+	app.list.SetPinned(nil)
+	app.list.LoadSessions(pinTestSessions())
+
+	// Nothing to save without a filter
+	app.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune{'F'}})
+	if app.modals.Active() || !app.noticeIsError {
+		t.Fatal("Expected an error notice instead of the preset prompt")
+	}
+
+	app.list.SetSearchQuery("docs")
+	app.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune{'F'}})
+	if app.modals.Top() != app.presetOverlay {
+		t.Fatal("Expected the preset prompt to open")
+	}
+	for _, r := range "docs" {
+		app.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune{r}})
+	}
+	app.Update(tea.KeyMsg{Type: tea.KeyEnter})
+	if app.modals.Active() {
+		t.Fatal("Expected the prompt to close after saving")
+	}
+
+	loaded, err := LoadTUIState(app.tuiStatePath)
+	if err != nil {
+		t.Fatalf("LoadTUIState() error = %v", err)
+	}
+	if want := []FilterPreset{{Name: "docs", Search: "docs"}}; !reflect.DeepEqual(loaded.Presets, want) {
+		t.Errorf("Presets = %+v, want %+v", loaded.Presets, want)
+	}
+
+	app.list.ClearSearch()
+	app.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune{'1'}})
+	if got := listItemNames(app.list); !reflect.DeepEqual(got, []string{"mary"}) {
+		t.Errorf("Expected preset 1 to search for docs, got %v", got)
+	}
+	if app.searchInput.Value() != "docs" {
+		t.Errorf("Expected the search input to show the preset's query, got %q", app.searchInput.Value())
+	}
+
+	app.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune{'2'}})
+	if !app.noticeIsError || !strings.Contains(app.notice, "no preset on 2") {
+		t.Errorf("Expected a notice for an empty preset, got %q", app.notice)
+	}
+}
+
+func TestListToggleMark(t *testing.T) {
+	list := NewListModel(80, 24)
+	list.SetTheme(PlainTheme())
+	list.LoadSessions([]SessionInfo{
+		{Name: "agent-app-abc123-sarah", AgentName: "sarah"},
+		{Name: "agent-app-abc123-emily", AgentName: "emily"},
+	})
+
+	if !list.ToggleMark("agent-app-abc123-emily") || !list.ToggleMark("agent-app-abc123-sarah") {
+		t.Fatal("Expected both sessions marked")
+	}
+	marked := list.MarkedSessions()
+	if len(marked) != 2 || marked[0].AgentName != "emily" || marked[1].AgentName != "sarah" {
+		t.Errorf("Expected marked sessions in mark order, got %+v", marked)
+	}
+	if item, ok := list.Items()[0].(SessionListItem); !ok || !strings.Contains(item.Title(), "marked") {
+		t.Errorf("Expected marked rows labeled, got %+v", list.Items()[0])
+	}
+
+	if list.ToggleMark("agent-app-abc123-emily") {
+		t.Error("Expected a second toggle to unmark")
+	}
+	// Marks of sessions that went away are dropped
+	list.LoadSessions([]SessionInfo{{Name: "agent-app-abc123-emily", AgentName: "emily"}})
+	if marked := list.MarkedSessions(); len(marked) != 0 {
+		t.Errorf("Expected no marked sessions left, got %+v", marked)
+	}
+}
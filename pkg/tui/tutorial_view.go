@@ -0,0 +1,169 @@
+// Copyright (c) Subtrace, Inc.
+// SPDX-License-Identifier: BSD-3-Clause
+
+package tui
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/charmbracelet/bubbles/key"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+)
+
+// tutorialStep is one page of the tutorial. The key is taken from the live
+// KeyMap so remapped bindings show their configured keys.
+type tutorialStep struct {
+	title   string
+	binding func(k *KeyMap) key.Binding // nil for pages about no single key
+	text    string
+}
+
+// tutorialSteps walk through the core flow: spawn, attach, checkpoint, kill
+var tutorialSteps = []tutorialStep{
+	{
+		title: "Welcome to uzi",
+		text: "Each agent works on its own git worktree in its own tmux session, so several can\n" +
+			"take on tasks side by side. This tour shows the four keys of the core flow.",
+	},
+	{
+		title:   "Spawn agents",
+		binding: func(k *KeyMap) key.Binding { return k.NewAgent },
+		text: "opens the new agent form: pick the agent type and how many to start, then\n" +
+			"describe the task. They show up in the list as they start.",
+	},
+	{
+		title:   "Attach to an agent",
+		binding: func(k *KeyMap) key.Binding { return k.Enter },
+		text: "attaches to the selected agent's tmux session to watch or steer it. Detach\n" +
+			"with ctrl+b d to come back to this list.",
+	},
+	{
+		title:   "Checkpoint its work",
+		binding: func(k *KeyMap) key.Binding { return k.Checkpoint },
+		text: "commits the selected agent's changes and rebases them onto your current\n" +
+			"branch, once you are happy with them.",
+	},
+	{
+		title:   "Kill it when done",
+		binding: func(k *KeyMap) key.Binding { return k.Kill },
+		text: "removes the selected agent's tmux session and worktree. You are warned\n" +
+			"first if it has work you haven't checkpointed.",
+	},
+	{
+		title:   "That's it",
+		binding: func(k *KeyMap) key.Binding { return k.Help },
+		text:    "lists every key at any time, and ctrl+p searches every action by name.",
+	},
+}
+
+// TutorialView is an overlay shown on the first launch of the TUI that pages
+// through the core flow. It sits on the modal stack like any other overlay,
+// so once dismissed it leaves input alone.
+type TutorialView struct {
+	visible   bool
+	step      int
+	keys      *KeyMap
+	theme     *Theme
+	onDismiss func() tea.Cmd
+}
+
+// NewTutorialView creates a hidden tutorial that calls onDismiss when it is
+// finished or skipped
+func NewTutorialView(keys *KeyMap, onDismiss func() tea.Cmd) *TutorialView {
+	return &TutorialView{keys: keys, theme: DefaultTheme(), onDismiss: onDismiss}
+}
+
+// SetTheme switches the style profile used to render the overlay
+func (v *TutorialView) SetTheme(theme *Theme) {
+	v.theme = theme
+}
+
+// Show opens the tutorial on its first page
+func (v *TutorialView) Show() {
+	v.visible = true
+	v.step = 0
+}
+
+// Hide closes the tutorial
+func (v *TutorialView) Hide() { v.visible = false }
+
+// Focused reports whether the tutorial is open
+func (v *TutorialView) Focused() bool { return v.visible }
+
+// Step returns the index of the shown page
+func (v *TutorialView) Step() int { return v.step }
+
+// Update pages forward on Enter or right and back on left; Enter on the last
+// page finishes the tutorial and Esc skips it
+func (v *TutorialView) Update(msg tea.Msg) tea.Cmd {
+	keyMsg, ok := msg.(tea.KeyMsg)
+	if !ok {
+		return nil
+	}
+	switch {
+	case key.Matches(keyMsg, v.keys.Escape):
+		return v.dismiss()
+	case key.Matches(keyMsg, v.keys.Enter, v.keys.Right):
+		if v.step == len(tutorialSteps)-1 {
+			return v.dismiss()
+		}
+		v.step++
+	case key.Matches(keyMsg, v.keys.Left):
+		if v.step > 0 {
+			v.step--
+		}
+	}
+	return nil
+}
+
+// dismiss closes the tutorial for good
+func (v *TutorialView) dismiss() tea.Cmd {
+	v.Hide()
+	if v.onDismiss == nil {
+		return nil
+	}
+	return v.onDismiss()
+}
+
+// View renders the current page with its key highlighted
+func (v *TutorialView) View() string {
+	if !v.visible {
+		return ""
+	}
+
+	t := resolveTheme(v.theme)
+	step := tutorialSteps[v.step]
+	text := step.text
+	if step.binding != nil {
+		keyName := step.binding(v.keys).Help().Key
+		text = t.Selected.Render(" "+keyName+" ") + " " + text
+	}
+
+	dots := make([]string, len(tutorialSteps))
+	for i := range tutorialSteps {
+		if i == v.step {
+			dots[i] = t.Accent.Render("●")
+		} else {
+			dots[i] = t.Muted.Render("○")
+		}
+	}
+
+	next := "next"
+	if v.step == len(tutorialSteps)-1 {
+		next = "start"
+	}
+	footer := fmt.Sprintf("[enter/→] %s  [←] back  [ESC] skip", next)
+
+	content := lipgloss.JoinVertical(lipgloss.Left,
+		t.Accent.Render(fmt.Sprintf("%s (%d/%d)", step.title, v.step+1, len(tutorialSteps))),
+		"",
+		text,
+		"",
+		strings.Join(dots, " "),
+		"",
+		t.Muted.Render(footer),
+	)
+	return t.Border.Copy().Render(content)
+}
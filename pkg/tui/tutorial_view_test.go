@@ -0,0 +1,112 @@
+// Copyright (c) Subtrace, Inc.
+// SPDX-License-Identifier: BSD-3-Clause
+
+package tui
+
+import (
+	"path/filepath"
+	"strings"
+	"testing"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+func TestTutorialView_Pages(t *testing.T) {
+	keys := DefaultKeyMap()
+	keys.Checkpoint.SetKeys("K")
+	keys.Checkpoint.SetHelp("K", "checkpoint agent")
+	dismissed := 0
+	view := NewTutorialView(&keys, func() tea.Cmd {
+		dismissed++
+		return nil
+	})
+	view.SetTheme(PlainTheme())
+	if view.View() != "" {
+		t.Error("Hidden tutorial should render nothing")
+	}
+
+	view.Show()
+	if output := view.View(); !strings.Contains(output, "Welcome to uzi (1/6)") || !strings.Contains(output, "[ESC] skip") {
+		t.Errorf("Expected the welcome page, got %q", output)
+	}
+
+	// Left on the first page stays put
+	view.Update(tea.KeyMsg{Type: tea.KeyLeft})
+	if view.Step() != 0 {
+		t.Errorf("Expected to stay on the first page, got %d", view.Step())
+	}
+
+	wantKeys := []string{" n ", " enter ", " K ", " k ", " ? "}
+	for i, want := range wantKeys {
+		view.Update(tea.KeyMsg{Type: tea.KeyEnter})
+		if output := view.View(); view.Step() != i+1 || !strings.Contains(output, want) {
+			t.Errorf("Expected page %d to highlight %q, got %q", i+2, want, output)
+		}
+	}
+	view.Update(tea.KeyMsg{Type: tea.KeyLeft})
+	view.Update(tea.KeyMsg{Type: tea.KeyRight})
+	if output := view.View(); !strings.Contains(output, "[enter/→] start") {
+		t.Errorf("Expected the last page to offer to start, got %q", output)
+	}
+
+	view.Update(tea.KeyMsg{Type: tea.KeyEnter})
+	if view.Focused() || dismissed != 1 {
+		t.Errorf("Expected Enter on the last page to finish the tutorial, dismissed %d times", dismissed)
+	}
+
+	view.Show()
+	if view.Step() != 0 {
+		t.Error("Expected the tutorial to reopen on its first page")
+	}
+	view.Update(tea.KeyMsg{Type: tea.KeyEsc})
+	if view.Focused() || dismissed != 2 {
+		t.Errorf("Expected Esc to skip the tutorial, dismissed %d times", dismissed)
+	}
+}
+
+func TestApp_TutorialOnFirstRun(t *testing.T) {
+	app := NewApp(&MockUziInterface{})
+	defer app.Cleanup()
+	app.tuiState = &TUIState{}
+	app.tuiStatePath = filepath.Join(t.TempDir(), "tui_state.json")
+
+	app.ShowTutorialOnFirstRun()
+	if app.modals.Top() != app.tutorialView {
+		t.Fatal("Expected the tutorial on top on the first run")
+	}
+	// Keys go to the tutorial rather than the list while it is open
+	app.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune{'n'}})
+	if app.modals.Top() != app.tutorialView {
+		t.Error("Expected the tutorial to keep input while open")
+	}
+
+	app.Update(tea.KeyMsg{Type: tea.KeyEsc})
+	if app.modals.Active() {
+		t.Error("Expected no overlay after skipping the tutorial")
+	}
+	loaded, err := LoadTUIState(app.tuiStatePath)
+	if err != nil || !loaded.TutorialSeen {
+		t.Fatalf("Expected the tutorial remembered as seen, got %+v, %v", loaded, err)
+	}
+
+	// Later runs start on the list, and keys reach it again
+	app.ShowTutorialOnFirstRun()
+	if app.modals.Active() {
+		t.Error("Expected no tutorial once it was seen")
+	}
+	app.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune{'?'}})
+	if app.modals.Top() != app.helpView {
+		t.Error("Expected keys to reach the list after the tutorial")
+	}
+}
+
+func TestApp_TutorialSaveFailure(t *testing.T) {
+	app := NewApp(&MockUziInterface{})
+	defer app.Cleanup()
+	app.tuiState = &TUIState{}
+	app.tuiStatePath = ""
+
+	if cmd := app.finishTutorial(); cmd == nil || !app.tuiState.TutorialSeen {
+		t.Error("Expected a notice when the tutorial can't be remembered, and no repeat this run")
+	}
+}
@@ -17,11 +17,20 @@ import (
 	"sort"
 	"strconv"
 	"strings"
+	"sync"
+	"sync/atomic"
 	"time"
 
+	"github.com/nehpz/claudicus/pkg/activity"
 	"github.com/nehpz/claudicus/pkg/agents"
 	"github.com/nehpz/claudicus/pkg/config"
+	"github.com/nehpz/claudicus/pkg/events"
+	"github.com/nehpz/claudicus/pkg/hosts"
+	"github.com/nehpz/claudicus/pkg/keyring"
+	"github.com/nehpz/claudicus/pkg/pipeline"
+	"github.com/nehpz/claudicus/pkg/scrollback"
 	"github.com/nehpz/claudicus/pkg/state"
+	"github.com/nehpz/claudicus/pkg/tmuxops"
 )
 
 // execCommand allows mocking exec.Command for testing (separate from tmux.go variable)
@@ -29,18 +38,34 @@ var uziExecCommand = exec.Command
 
 // SessionInfo contains displayable information about a session
 type SessionInfo struct {
-	Name           string `json:"name"`
-	AgentName      string `json:"agent_name"`
-	Model          string `json:"model"`
-	Status         string `json:"status"`
-	Prompt         string `json:"prompt"`
-	Insertions     int    `json:"insertions"`
-	Deletions      int    `json:"deletions"`
-	WorktreePath   string `json:"worktree_path"`
-	Port           int    `json:"port,omitempty"`
-	CreatedAt      string `json:"created_at,omitempty"`
-	UpdatedAt      string `json:"updated_at,omitempty"`
-	ActivityStatus string `json:"activity_status,omitempty"` // For test compatibility
+	Name           string   `json:"name"`
+	AgentName      string   `json:"agent_name"`
+	Model          string   `json:"model"`
+	Status         string   `json:"status"`
+	Prompt         string   `json:"prompt"`
+	Insertions     int      `json:"insertions"`
+	Deletions      int      `json:"deletions"`
+	FilesChanged   int      `json:"files_changed,omitempty"`
+	WorktreePath   string   `json:"worktree_path"`
+	Port           int      `json:"port,omitempty"`
+	CreatedAt      string   `json:"created_at,omitempty"`
+	UpdatedAt      string   `json:"updated_at,omitempty"`
+	Deadline       string   `json:"deadline,omitempty"`        // end of the --max-runtime budget
+	ActivityStatus string   `json:"activity_status,omitempty"` // working, idle, stuck, or done from the activity monitor
+	Tags           []string `json:"tags,omitempty"`
+	Channels       []string `json:"channels,omitempty"` // Broadcast channels the session subscribes to
+	Run            string   `json:"run,omitempty"`      // Run the session was spawned in; the list groups its sessions
+	Host           string   `json:"host,omitempty"`     // Remote host the agent runs on; empty for local agents
+	Paused         bool     `json:"paused,omitempty"`   // Stopped by uzi pause until uzi resume
+	Done           bool     `json:"done,omitempty"`     // The agent signalled its task is complete
+
+	Commits            int    `json:"commits,omitempty"`               // Commits in the last 24 hours
+	LastCommitAt       string `json:"last_commit_at,omitempty"`        // Time of the newest commit
+	LastFileActivityAt string `json:"last_file_activity_at,omitempty"` // Time of the newest file change; only a running monitor sees it
+
+	CostUSD float64 `json:"-"` // Estimated cost of the agent's claude transcripts; only filled in when the list shows it
+
+	Enriching bool `json:"-"` // Status and diff counts are still being loaded
 }
 
 // UziInterface defines the interface for interacting with Uzi core functionality
@@ -48,6 +73,10 @@ type UziInterface interface {
 	// GetSessions returns a list of session information
 	GetSessions() ([]SessionInfo, error)
 
+	// GetSessionsWithMetrics returns the sessions with their activity metrics
+	// folded in: activity status, diff counts, and last-activity times
+	GetSessionsWithMetrics() ([]SessionInfo, error)
+
 	// GetSessionState returns the state for a specific session
 	GetSessionState(sessionName string) (*state.AgentState, error)
 
@@ -60,13 +89,17 @@ type UziInterface interface {
 	// KillSession terminates a session
 	KillSession(sessionName string) error
 
+	// InspectKill reports the uncommitted and unmerged work a kill would lose
+	InspectKill(sessionName string) (state.PendingWork, error)
+
 	// RefreshSessions refreshes the session list
 	RefreshSessions() error
 
 	// RunPrompt creates a new agent session
 	RunPrompt(agents string, prompt string) error
 
-	// RunBroadcast sends a message to all active sessions
+	// RunBroadcast sends a message to all active sessions, filling in {agent}
+	// and {branch} for each
 	RunBroadcast(message string) error
 
 	// RunCommand executes a command in all sessions
@@ -75,11 +108,37 @@ type UziInterface interface {
 	// RunCheckpoint creates a checkpoint for an agent
 	RunCheckpoint(agentName string, message string) error
 
+	// RunPartialCheckpoint checkpoints only the agent files matching the given globs
+	RunPartialCheckpoint(agentName string, message string, paths []string) error
+
+	// GetChangedFiles lists the files an agent changed in its worktree
+	GetChangedFiles(sessionName string) ([]string, error)
+
+	// GetSessionDiffStat breaks the uncommitted changes of a session's
+	// worktree down by file
+	GetSessionDiffStat(sessionName string) (*state.DiffStat, error)
+
+	// RunNudge sends the configured continue keystrokes to an idle agent
+	RunNudge(agentName string) error
+
+	// GetPipelineRuns lists pipeline runs with their stage progress, newest first
+	GetPipelineRuns() ([]pipeline.Run, error)
+
+	// GetDevLog captures up to the last lines lines of a session's dev server window
+	GetDevLog(sessionName string, lines int) (string, error)
+
 	// SpawnAgent creates a new agent and returns the session name
 	SpawnAgent(prompt, model string) (string, error)
 
-	// SpawnAgentInteractive launches an interactive agent creation
-	SpawnAgentInteractive(opts string) (<-chan struct{}, error)
+	// RespawnWithPrompt replaces a session with a new agent on a fresh worktree
+	// running newPrompt, and returns the new session name
+	RespawnWithPrompt(sessionName, newPrompt string) (string, error)
+
+	// SpawnAgentInteractive creates the agents opts describes, in the
+	// "agentType:count:prompt" format, and sends a SpawnEvent to progress as
+	// each step starts and finishes. Cancelling ctx stops the spawn and rolls
+	// back the agent being created.
+	SpawnAgentInteractive(ctx context.Context, opts string, progress chan<- SpawnEvent) error
 }
 
 // ProxyConfig defines configuration for the UziCLI proxy
@@ -109,6 +168,11 @@ type StateManagerInterface interface {
 	RemoveState(sessionName string) error
 	SaveState(prompt, branchName, sessionName, worktreePath, model string) error
 	SaveStateWithPort(prompt, branchName, sessionName, worktreePath, model string, port int) error
+	LockSession(sessionName, operation string) (*state.SessionLock, error)
+	SetTags(sessionName string, tags []string) error
+	SetChannels(sessionName string, channels []string) error
+	SetMaxRuntime(sessionName string, maxRuntime time.Duration) error
+	SetDevCommand(sessionName, devCommand string) error
 }
 
 // StateManagerBridge implements StateManagerInterface by wrapping state.StateManager
@@ -137,6 +201,13 @@ type UziCLI struct {
 	stateManager  StateManagerInterface
 	tmuxDiscovery *TmuxDiscovery
 	config        ProxyConfig
+	dispatcher    *events.Dispatcher
+	broadcaster   *tmuxops.Broadcaster
+	aggregator    *state.Aggregator             // Enriches sessions read from state.json in legacy mode
+	legacyMode    atomic.Bool                   // Set when the uzi binary fails the version handshake
+	spawnConfig   atomic.Pointer[config.Config] // Hot-reloaded uzi.yaml; nil reads the file on each spawn
+
+	activityMonitor atomic.Pointer[activity.AgentActivityMonitor] // The TUI's monitor; nil uses the metrics of uzi ls
 }
 
 // NewUziCLI creates a new UziCLI implementation with default configuration
@@ -146,11 +217,101 @@ func NewUziCLI() *UziCLI {
 
 // NewUziCLIWithConfig creates a new UziCLI implementation with custom configuration
 func NewUziCLIWithConfig(config ProxyConfig) *UziCLI {
-	return &UziCLI{
+	c := &UziCLI{
 		stateManager:  state.NewStateManager(),
 		tmuxDiscovery: NewTmuxDiscovery(),
 		config:        config,
+		dispatcher:    loadDispatcher(),
+	}
+	c.broadcaster = tmuxops.NewRoutedBroadcaster(c.executorFor)
+	c.aggregator = state.NewAggregator(
+		state.WithTmuxStatus(),
+		state.WithDiffs(),
+		state.WithProbe(cliProbe{c}),
+		state.WithRemoteProbe(hosts.RemoteProbe),
+		state.WithCacheTTL(diffCacheTTL),
+	)
+
+	// Sessions on remote hosts show up next to local ones; without a readable
+	// uzi.yaml the fleet is local only
+	if cfg, err := c.loadDefaultConfig(); err == nil {
+		c.tmuxDiscovery.SetActiveThreshold(cfg.ActiveThreshold())
+		for _, target := range hosts.Remotes(cfg) {
+			c.tmuxDiscovery.AddHost(target.Name, &TmuxReal{Host: target})
+		}
+	}
+	return c
+}
+
+// executorFor runs a session's commands on the host it was spawned on
+func (c *UziCLI) executorFor(sessionName string) tmuxops.CommandExecutor {
+	if agentState, err := c.GetSessionState(sessionName); err == nil && agentState.IsRemote() {
+		return hosts.ForState(*agentState)
+	}
+	return proxyExecutor{}
+}
+
+// diffCacheTTL bounds how often legacy mode recomputes each worktree's diff
+const diffCacheTTL = 5 * time.Second
+
+// cliProbe inspects sessions through uziExecCommand so tests can intercept tmux and git calls
+type cliProbe struct {
+	c *UziCLI
+}
+
+// PaneContent implements state.SessionProbe
+func (p cliProbe) PaneContent(sessionName string) (string, error) {
+	return p.c.getPaneContent(sessionName)
+}
+
+// DiffStat implements state.SessionProbe
+func (p cliProbe) DiffStat(worktreePath string) (string, error) {
+	return p.c.diffStat(worktreePath)
+}
+
+// FileDiffStat implements state.FileDiffProbe
+func (p cliProbe) FileDiffStat(worktreePath string) (string, error) {
+	cmd := uziExecCommand("sh", "-c", state.FileDiffScript)
+	cmd.Dir = worktreePath
+	output, err := cmd.Output()
+	if err != nil {
+		return "", err
 	}
+	return string(output), nil
+}
+
+// Hunks implements state.HunkProbe
+func (p cliProbe) Hunks(worktreePath string) (string, error) {
+	cmd := uziExecCommand("sh", "-c", state.HunkScript)
+	cmd.Dir = worktreePath
+	output, err := cmd.Output()
+	if err != nil {
+		return "", err
+	}
+	return string(output), nil
+}
+
+// proxyExecutor runs commands through uziExecCommand so tests can intercept tmux calls
+type proxyExecutor struct{}
+
+// Execute implements tmuxops.CommandExecutor
+func (proxyExecutor) Execute(command string, args ...string) error {
+	return uziExecCommand(command, args...).Run()
+}
+
+// ExecuteCommand implements state.CommandExecutor
+func (proxyExecutor) ExecuteCommand(name string, args ...string) ([]byte, error) {
+	return uziExecCommand(name, args...).Output()
+}
+
+// RunCommand implements state.CommandExecutor
+func (proxyExecutor) RunCommand(name string, args ...string) error {
+	return uziExecCommand(name, args...).Run()
+}
+
+// loadDispatcher creates the webhook dispatcher from the default uzi.yaml
+func loadDispatcher() *events.Dispatcher {
+	return events.LoadDispatcher(config.GetDefaultConfigPath())
 }
 
 // Core proxy infrastructure methods
@@ -215,6 +376,122 @@ func (c *UziCLI) executeCommandWithTimeout(timeout time.Duration, name string, a
 	return nil, c.wrapError(fmt.Sprintf("%s %v", name, args), lastErr)
 }
 
+// executeStreaming runs a command and hands each line of its stdout and
+// stderr to onLine as it is written, so long-running commands show progress.
+// The timeout restarts whenever the command prints a line, so only a command
+// that stays silent for the whole timeout is killed. Streamed commands aren't
+// retried, since their output has already been shown. A nil onLine only
+// collects the output.
+func (c *UziCLI) executeStreaming(timeout time.Duration, onLine func(string), name string, args ...string) ([]byte, error) {
+	start := time.Now()
+	operation := fmt.Sprintf("%s %v", name, args)
+
+	var mu sync.Mutex
+	activity := make(chan struct{}, 1)
+	emit := func(line string) {
+		mu.Lock()
+		if onLine != nil {
+			onLine(line)
+		}
+		mu.Unlock()
+		select {
+		case activity <- struct{}{}:
+		default:
+		}
+	}
+	stdout, stderr := &lineWriter{onLine: emit}, &lineWriter{onLine: emit}
+
+	cmd := uziExecCommand(name, args...)
+	cmd.Stdout = stdout
+	cmd.Stderr = stderr
+	if err := cmd.Start(); err != nil {
+		c.logOperation(operation, time.Since(start), err)
+		return nil, c.wrapError(operation, err)
+	}
+	done := make(chan error, 1)
+	go func() {
+		done <- cmd.Wait()
+	}()
+
+	idle := time.NewTimer(timeout)
+	defer idle.Stop()
+	for {
+		select {
+		case err := <-done:
+			stdout.flush()
+			stderr.flush()
+			if err != nil {
+				err = fmt.Errorf("command failed: %w - stderr: %s", err, stderr.String())
+				c.logOperation(operation, time.Since(start), err)
+				return stdout.Bytes(), c.wrapError(operation, err)
+			}
+			c.logOperation(operation, time.Since(start), nil)
+			return stdout.Bytes(), nil
+
+		case <-activity:
+			if !idle.Stop() {
+				<-idle.C
+			}
+			idle.Reset(timeout)
+
+		case <-idle.C:
+			cmd.Process.Kill()
+			<-done
+			err := fmt.Errorf("command produced no output for %v", timeout)
+			c.logOperation(operation, time.Since(start), err)
+			return stdout.Bytes(), c.wrapError(operation, err)
+		}
+	}
+}
+
+// lineWriter collects a command's output and hands each complete line to
+// onLine as it arrives
+type lineWriter struct {
+	mu      sync.Mutex
+	onLine  func(string)
+	all     bytes.Buffer
+	partial []byte
+}
+
+func (w *lineWriter) Write(p []byte) (int, error) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	w.all.Write(p)
+	w.partial = append(w.partial, p...)
+	for {
+		i := bytes.IndexByte(w.partial, '\n')
+		if i < 0 {
+			break
+		}
+		line := strings.TrimRight(string(w.partial[:i]), "\r")
+		w.partial = w.partial[i+1:]
+		w.onLine(line)
+	}
+	return len(p), nil
+}
+
+// flush hands over a last line that didn't end in a newline
+func (w *lineWriter) flush() {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	if len(w.partial) > 0 {
+		w.onLine(strings.TrimRight(string(w.partial), "\r"))
+		w.partial = nil
+	}
+}
+
+// Bytes returns everything written so far
+func (w *lineWriter) Bytes() []byte {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	return w.all.Bytes()
+}
+
+// String returns everything written so far
+func (w *lineWriter) String() string {
+	return string(w.Bytes())
+}
+
 // wrapError provides consistent error wrapping with proxy context
 func (c *UziCLI) wrapError(operation string, err error) error {
 	if err == nil {
@@ -240,16 +517,21 @@ func (c *UziCLI) GetSessions() ([]SessionInfo, error) {
 	start := time.Now()
 	defer func() { c.logOperation("GetSessions", time.Since(start), nil) }()
 
+	// A stale uzi binary can't be trusted to emit compatible JSON
+	if c.legacyMode.Load() {
+		return c.GetSessionsLegacy()
+	}
+
 	// Shell out to uzi ls --json
 	output, err := c.executeCommand("uzi", "ls", "--json")
 	if err != nil {
-		return nil, c.wrapError("GetSessions", err)
+		return c.fallbackOnMismatch(c.wrapError("GetSessions", err))
 	}
 
 	// Parse JSON response
 	var sessions []SessionInfo
 	if err := json.Unmarshal(output, &sessions); err != nil {
-		return nil, c.wrapError("GetSessions", fmt.Errorf("failed to parse JSON: %w", err))
+		return c.fallbackOnMismatch(c.wrapError("GetSessions", fmt.Errorf("failed to parse JSON: %w", err)))
 	}
 
 	return sessions, nil
@@ -283,33 +565,8 @@ func (c *UziCLI) GetSessionsLegacy() ([]SessionInfo, error) {
 
 	// Build session info list
 	var sessions []SessionInfo
-	for _, sessionName := range activeSessions {
-		state, ok := states[sessionName]
-		if !ok {
-			continue
-		}
-
-		// Extract agent name from session name
-		agentName := extractAgentName(sessionName)
-
-		// Get session status
-		status := c.getAgentStatus(sessionName)
-
-		// Get git diff stats
-		insertions, deletions := c.getGitDiffTotals(sessionName, &state)
-
-		sessionInfo := SessionInfo{
-			Name:         sessionName,
-			AgentName:    agentName,
-			Model:        state.Model,
-			Status:       status,
-			Prompt:       state.Prompt,
-			Insertions:   insertions,
-			Deletions:    deletions,
-			WorktreePath: state.WorktreePath,
-			Port:         state.Port,
-		}
-		sessions = append(sessions, sessionInfo)
+	for _, info := range c.aggregator.Sessions(states, activeSessions) {
+		sessions = append(sessions, sessionInfoFromState(info))
 	}
 
 	// Sort sessions by port for stable ordering
@@ -321,6 +578,136 @@ func (c *UziCLI) GetSessionsLegacy() ([]SessionInfo, error) {
 	return sessions, nil
 }
 
+// ListSessions implements lazySessionLister. It reads the sessions from
+// state.json without inspecting their panes or worktrees, so the list can be
+// shown at once; each session is marked Enriching until EnrichSession fills
+// in its status and diff counts.
+func (c *UziCLI) ListSessions() ([]SessionInfo, error) {
+	if c.stateManager == nil {
+		return nil, fmt.Errorf("state manager not initialized")
+	}
+	activeSessions, err := c.stateManager.GetActiveSessionsForRepo()
+	if err != nil {
+		return nil, fmt.Errorf("failed to get active sessions: %w", err)
+	}
+	states := make(map[string]state.AgentState)
+	if data, err := os.ReadFile(c.stateManager.GetStatePath()); err != nil {
+		if os.IsNotExist(err) {
+			return []SessionInfo{}, nil
+		}
+		return nil, fmt.Errorf("failed to read state file: %w", err)
+	} else if err := json.Unmarshal(data, &states); err != nil {
+		return nil, fmt.Errorf("failed to parse state file: %w", err)
+	}
+
+	sessions := []SessionInfo{}
+	for _, info := range state.NewAggregator().Sessions(states, activeSessions) {
+		session := sessionInfoFromState(info)
+		session.Enriching = true
+		sessions = append(sessions, session)
+	}
+	// Same order as uzi ls --json, so the full refresh doesn't reorder rows
+	sort.SliceStable(sessions, func(i, j int) bool {
+		return sessions[i].Port < sessions[j].Port
+	})
+	return sessions, nil
+}
+
+// EnrichSession implements lazySessionLister. It fills in the status and diff
+// counts of a session returned by ListSessions.
+func (c *UziCLI) EnrichSession(session SessionInfo) (SessionInfo, error) {
+	agentState, err := c.GetSessionState(session.Name)
+	if err != nil {
+		return session, err
+	}
+	info := c.aggregator.Session(session.Name, *agentState)
+	session.Status = info.Status
+	session.Insertions, session.Deletions = info.Insertions, info.Deletions
+	session.Enriching = false
+	return session, nil
+}
+
+// sessionInfoFromState converts the aggregator's view of a session
+func sessionInfoFromState(info state.SessionInfo) SessionInfo {
+	return SessionInfo{
+		Name:         info.SessionName,
+		AgentName:    info.AgentName,
+		Model:        info.Model,
+		Status:       info.Status,
+		Prompt:       info.Prompt,
+		Insertions:   info.Insertions,
+		Deletions:    info.Deletions,
+		FilesChanged: info.FilesChanged,
+		WorktreePath: info.WorktreePath,
+		Port:         info.Port,
+		CreatedAt:    info.CreatedAt,
+		UpdatedAt:    info.UpdatedAt,
+		Deadline:     info.Deadline,
+		Tags:         info.Tags,
+		Channels:     info.Channels,
+		Run:          info.Run,
+		Host:         info.Host,
+		Paused:       info.Paused,
+		Done:         info.Done,
+	}
+}
+
+// SetActivityMonitor sets the running monitor whose metrics
+// GetSessionsWithMetrics folds into sessions
+func (c *UziCLI) SetActivityMonitor(monitor *activity.AgentActivityMonitor) {
+	c.activityMonitor.Store(monitor)
+}
+
+// GetSessionsWithMetrics implements UziInterface. uzi ls --json measures each
+// worktree once; a running monitor set by SetActivityMonitor also sees file
+// activity and keeps its findings between refreshes, so its metrics win.
+func (c *UziCLI) GetSessionsWithMetrics() ([]SessionInfo, error) {
+	sessions, err := c.GetSessions()
+	if err != nil {
+		return nil, err
+	}
+	if monitor := c.activityMonitor.Load(); monitor != nil {
+		sessions = mergeMetrics(sessions, monitor.UpdateAll())
+	}
+	return sessions, nil
+}
+
+// mergeMetrics returns a copy of sessions with the metrics of the sessions
+// the monitor tracks folded in
+func mergeMetrics(sessions []SessionInfo, metrics map[string]*activity.Metrics) []SessionInfo {
+	merged := make([]SessionInfo, len(sessions))
+	copy(merged, sessions)
+	for i, session := range merged {
+		m, exists := metrics[session.Name]
+		if !exists {
+			continue
+		}
+		// uzi ls may have found the agent stuck where the monitor, which
+		// sees file activity, did not; stuck is only ever derived from ready
+		status := session.Status
+		if status == state.StatusStuck && m.Status != activity.StatusStuck {
+			status = state.StatusReady
+		}
+		merged[i].Status = m.SessionStatus(status)
+		merged[i].ActivityStatus = m.Status.String()
+		merged[i].Insertions = m.Insertions
+		merged[i].Deletions = m.Deletions
+		merged[i].Commits = m.Commits
+		merged[i].LastCommitAt = formatActivityTime(m.LastCommitAt, session.LastCommitAt)
+		merged[i].LastFileActivityAt = formatActivityTime(m.LastFileActivityAt, session.LastFileActivityAt)
+	}
+	return merged
+}
+
+// formatActivityTime formats t for SessionInfo, keeping fallback when the
+// monitor has not seen the activity
+func formatActivityTime(t time.Time, fallback string) string {
+	if t.IsZero() {
+		return fallback
+	}
+	return t.Format(time.RFC3339)
+}
+
 // GetSessionState implements UziInterface
 func (c *UziCLI) GetSessionState(sessionName string) (*state.AgentState, error) {
 	if c.stateManager == nil {
@@ -351,13 +738,17 @@ func (c *UziCLI) GetSessionStatus(sessionName string) (string, error) {
 	return c.getAgentStatus(sessionName), nil
 }
 
-// AttachToSession implements UziInterface by executing tmux attach
+// AttachToSession implements UziInterface by executing tmux attach, over ssh for remote sessions
 // Note: This is one case where we don't use executeCommand since it needs direct terminal access
 func (c *UziCLI) AttachToSession(sessionName string) error {
 	start := time.Now()
 	defer func() { c.logOperation("AttachToSession", time.Since(start), nil) }()
 
-	cmd := exec.Command("tmux", "attach-session", "-t", sessionName)
+	target := hosts.Local()
+	if agentState, err := c.GetSessionState(sessionName); err == nil {
+		target = hosts.ForState(*agentState)
+	}
+	cmd := target.AttachCommand(sessionName)
 	cmd.Stdin = os.Stdin
 	cmd.Stdout = os.Stdout
 	cmd.Stderr = os.Stderr
@@ -368,17 +759,72 @@ func (c *UziCLI) AttachToSession(sessionName string) error {
 	return nil
 }
 
-// KillSession implements UziInterface using the proxy pattern
+// OpenInTmux opens a session in the tmux client the TUI runs in, leaving the
+// TUI running. In window mode the client switches to a local session, while a
+// remote one is attached over ssh in a new window; in pane mode the session
+// is attached in a pane split off the TUI's.
+func (c *UziCLI) OpenInTmux(sessionName string, mode config.AttachMode) error {
+	start := time.Now()
+	defer func() { c.logOperation("OpenInTmux", time.Since(start), nil) }()
+
+	target := hosts.Local()
+	if agentState, err := c.GetSessionState(sessionName); err == nil {
+		target = hosts.ForState(*agentState)
+	}
+	var args []string
+	switch {
+	case mode == config.AttachModeWindow && target.IsLocal():
+		args = tmuxops.SwitchClientArgs(sessionName)
+	case mode == config.AttachModeWindow:
+		args = tmuxops.NewWindowArgs(extractAgentName(sessionName), attachShellCommand(target, sessionName))
+	default:
+		args = tmuxops.SplitWindowArgs(attachShellCommand(target, sessionName))
+	}
+	if output, err := uziExecCommand("tmux", args...).CombinedOutput(); err != nil {
+		if message := strings.TrimSpace(string(output)); message != "" {
+			err = fmt.Errorf("%s: %w", message, err)
+		}
+		return c.wrapError("OpenInTmux", err)
+	}
+	return nil
+}
+
+// attachShellCommand is the shell command a new tmux window or pane runs to
+// attach to a session. TMUX is unset so that tmux agrees to nest the client.
+func attachShellCommand(target hosts.Target, sessionName string) string {
+	return "TMUX= " + target.AttachCommandLine(sessionName)
+}
+
+// KillSession implements UziInterface using the proxy pattern. The TUI warns
+// about pending work and has the kill confirmed itself, so uzi kill is not
+// asked to check or confirm again.
 func (c *UziCLI) KillSession(sessionName string) error {
 	// Extract agent name from session name
 	agentName := extractAgentName(sessionName)
-	_, err := c.executeCommand("uzi", "kill", agentName)
+	_, err := c.executeCommand("uzi", "kill", "--force", "--yes", agentName)
 	if err != nil {
 		return c.wrapError("KillSession", err)
 	}
 	return nil
 }
 
+// InspectKill implements UziInterface by inspecting the session's worktree
+func (c *UziCLI) InspectKill(sessionName string) (state.PendingWork, error) {
+	agentState, err := c.GetSessionState(sessionName)
+	if err != nil {
+		return state.PendingWork{}, c.wrapError("InspectKill", err)
+	}
+	var executor state.CommandExecutor = proxyExecutor{}
+	if agentState.IsRemote() {
+		executor = hosts.ForState(*agentState)
+	}
+	work, err := state.InspectWork(executor, *agentState)
+	if err != nil {
+		return work, c.wrapError("InspectKill", err)
+	}
+	return work, nil
+}
+
 // RefreshSessions implements UziInterface (no-op as data is read fresh each time)
 func (c *UziCLI) RefreshSessions() error {
 	// No caching in this implementation, so nothing to refresh
@@ -395,18 +841,113 @@ func (c *UziCLI) RunPrompt(agents string, prompt string) error {
 	return nil
 }
 
+// RunQueue starts the agents queued under maxConcurrentAgents while slots are free
+func (c *UziCLI) RunQueue() error {
+	_, err := c.executeCommand("uzi", "queue", "run")
+	if err != nil {
+		return c.wrapError("RunQueue", err)
+	}
+	return nil
+}
+
 // RunBroadcast implements UziInterface using the proxy pattern
 func (c *UziCLI) RunBroadcast(message string) error {
-	_, err := c.executeCommand("uzi", "broadcast", message)
+	start := time.Now()
+	if err := c.checkBroadcast(message); err != nil {
+		return c.wrapError("RunBroadcast", err)
+	}
+	activeSessions, err := c.stateManager.GetActiveSessionsForRepo()
+	if err != nil {
+		return c.wrapError("RunBroadcast", err)
+	}
+	if len(activeSessions) == 0 {
+		return c.wrapError("RunBroadcast", fmt.Errorf("no active agent sessions found"))
+	}
+
+	err = c.broadcastTo(activeSessions, message)
+	c.logOperation("RunBroadcast", time.Since(start), err)
 	if err != nil {
 		return c.wrapError("RunBroadcast", err)
 	}
 	return nil
 }
 
+// RunChannelBroadcast sends a message to the active sessions subscribed to
+// channel, filling in {agent} and {branch} like RunBroadcast
+func (c *UziCLI) RunChannelBroadcast(channel, message string) error {
+	start := time.Now()
+	if err := c.checkBroadcast(message); err != nil {
+		return c.wrapError("RunChannelBroadcast", err)
+	}
+	activeSessions, err := c.stateManager.GetActiveSessionsForRepo()
+	if err != nil {
+		return c.wrapError("RunChannelBroadcast", err)
+	}
+	var subscribed []string
+	for _, sessionName := range activeSessions {
+		if agentState, err := c.GetSessionState(sessionName); err == nil && agentState.InChannel(channel) {
+			subscribed = append(subscribed, sessionName)
+		}
+	}
+	if len(subscribed) == 0 {
+		return c.wrapError("RunChannelBroadcast", fmt.Errorf("no active agent sessions subscribed to channel %q", channel))
+	}
+
+	err = c.broadcastTo(subscribed, message)
+	c.logOperation("RunChannelBroadcast", time.Since(start), err)
+	if err != nil {
+		return c.wrapError("RunChannelBroadcast", err)
+	}
+	return nil
+}
+
+// broadcastTo sends message to each session with its placeholders filled in.
+// Sessions paused with `uzi pause` are skipped.
+func (c *UziCLI) broadcastTo(sessions []string, message string) error {
+	var unpaused []string
+	for _, sessionName := range sessions {
+		if agentState, err := c.GetSessionState(sessionName); err == nil && agentState.Paused {
+			continue
+		}
+		unpaused = append(unpaused, sessionName)
+	}
+	if len(unpaused) == 0 {
+		return fmt.Errorf("all %d target sessions are paused; resume them with uzi resume", len(sessions))
+	}
+
+	err := c.broadcaster.BroadcastEach(unpaused, func(sessionName string) string {
+		var branch string
+		if agentState, err := c.GetSessionState(sessionName); err == nil {
+			branch = agentState.BranchName
+		}
+		return config.ExpandBroadcast(message, state.AgentNameFromSession(sessionName), branch)
+	})
+	c.forgetGoneSessions(tmuxops.GoneSessions(err))
+	return err
+}
+
+// forgetGoneSessions removes the local sessions a broadcast found gone from
+// state. Remote sessions are kept, since their host may only be unreachable.
+func (c *UziCLI) forgetGoneSessions(gone []string) {
+	for _, sessionName := range gone {
+		if agentState, err := c.GetSessionState(sessionName); err == nil && agentState.IsRemote() {
+			continue
+		}
+		start := time.Now()
+		err := c.stateManager.RemoveState(sessionName)
+		c.logOperation("RemoveState "+sessionName, time.Since(start), err)
+	}
+}
+
 // RunCommand implements UziInterface using the proxy pattern
 func (c *UziCLI) RunCommand(command string) error {
-	_, err := c.executeCommand("uzi", "run", command)
+	return c.RunCommandStreaming(command, nil)
+}
+
+// RunCommandStreaming runs `uzi run` and hands each line of its output to
+// onLine while it runs
+func (c *UziCLI) RunCommandStreaming(command string, onLine func(string)) error {
+	_, err := c.executeStreaming(c.config.Timeout, onLine, "uzi", "run", command)
 	if err != nil {
 		return c.wrapError("RunCommand", err)
 	}
@@ -415,14 +956,288 @@ func (c *UziCLI) RunCommand(command string) error {
 
 // RunCheckpoint implements UziInterface using the proxy pattern with streaming git output
 func (c *UziCLI) RunCheckpoint(agentName string, message string) error {
-	// Use the checkpoint command but capture detailed output
-	output, err := c.executeCommand("uzi", "checkpoint", agentName, message)
+	return c.RunCheckpointStreaming(agentName, message, nil, nil)
+}
+
+// RunPartialCheckpoint implements UziInterface using `uzi checkpoint --paths`
+func (c *UziCLI) RunPartialCheckpoint(agentName string, message string, paths []string) error {
+	return c.RunCheckpointStreaming(agentName, message, paths, nil)
+}
+
+// RunCheckpointStreaming checkpoints an agent, limited to paths when any are
+// given, and hands each line of the checkpoint's output to onLine while it runs
+func (c *UziCLI) RunCheckpointStreaming(agentName, message string, paths []string, onLine func(string)) error {
+	operation := "RunCheckpoint"
+	args := []string{"checkpoint"}
+	if len(paths) > 0 {
+		operation = "RunPartialCheckpoint"
+		for _, p := range paths {
+			args = append(args, "--paths", p)
+		}
+	}
+	args = append(args, agentName, message)
+
+	output, err := c.executeStreaming(c.config.Timeout, onLine, "uzi", args...)
+	if err != nil {
+		return c.wrapError(operation, fmt.Errorf("%w\nOutput: %s", err, string(output)))
+	}
+	return nil
+}
+
+// GetSessionDiffStat implements UziInterface, inspecting remote sessions on their host
+func (c *UziCLI) GetSessionDiffStat(sessionName string) (*state.DiffStat, error) {
+	agentState, err := c.GetSessionState(sessionName)
+	if err != nil {
+		return nil, c.wrapError("GetSessionDiffStat", err)
+	}
+	stat, err := c.aggregator.FileDiffs(*agentState)
+	if err != nil {
+		return nil, c.wrapError("GetSessionDiffStat", err)
+	}
+	return &stat, nil
+}
+
+// GetSessionPatch returns the full diff of a session's worktree against HEAD,
+// untracked files included, running it on the host of remote sessions
+func (c *UziCLI) GetSessionPatch(sessionName string) (string, error) {
+	agentState, err := c.GetSessionState(sessionName)
+	if err != nil {
+		return "", c.wrapError("GetSessionPatch", err)
+	}
+	if agentState.WorktreePath == "" {
+		return "", c.wrapError("GetSessionPatch", fmt.Errorf("no worktree for session %s", sessionName))
+	}
+
+	var cmd *exec.Cmd
+	if agentState.IsRemote() {
+		cmd = hosts.ForState(*agentState).Shell(context.Background(), agentState.WorktreePath, state.PatchScript)
+	} else {
+		cmd = uziExecCommand("sh", "-c", state.PatchScript)
+		cmd.Dir = agentState.WorktreePath
+	}
+	output, err := cmd.Output()
+	if err != nil {
+		return "", c.wrapError("GetSessionPatch", fmt.Errorf("git diff failed: %w", err))
+	}
+	return string(output), nil
+}
+
+// SearchOutput searches the pane history and, for local sessions, the agent
+// transcripts of every active session for pattern, ignoring case, as
+// `uzi grep -i` does. Sessions that can't be searched are skipped.
+func (c *UziCLI) SearchOutput(pattern string) ([]scrollback.Match, error) {
+	re, err := regexp.Compile("(?i)" + pattern)
+	if err != nil {
+		return nil, c.wrapError("SearchOutput", fmt.Errorf("invalid pattern: %w", err))
+	}
+	sessions, err := c.outputSessions()
+	if err != nil {
+		return nil, c.wrapError("SearchOutput", err)
+	}
+
+	var matches []scrollback.Match
+	for _, session := range sessions {
+		sessionMatches, err := scrollback.Search(session, re, 0)
+		if err != nil {
+			log.Printf("Skipping %s in output search: %v", session.Name, err)
+			continue
+		}
+		matches = append(matches, sessionMatches...)
+	}
+	return matches, nil
+}
+
+// ListTodos collects the TODO and FIXME markers and "Next steps" entries
+// every active agent printed, as `uzi todos` does. Sessions that can't be
+// read are skipped.
+func (c *UziCLI) ListTodos() ([]scrollback.Todo, error) {
+	sessions, err := c.outputSessions()
+	if err != nil {
+		return nil, c.wrapError("ListTodos", err)
+	}
+
+	var todos []scrollback.Todo
+	for _, session := range sessions {
+		sessionTodos, err := scrollback.Todos(session, 0)
+		if err != nil {
+			log.Printf("Skipping %s in todo list: %v", session.Name, err)
+			continue
+		}
+		todos = append(todos, sessionTodos...)
+	}
+	return todos, nil
+}
+
+// outputSessions describes how to read the output of every active session
+// in name order: the pane through tmux, on its host for remote sessions, and
+// the transcripts of local worktrees
+func (c *UziCLI) outputSessions() ([]scrollback.Session, error) {
+	if c.stateManager == nil {
+		return nil, fmt.Errorf("state manager not initialized")
+	}
+	activeSessions, err := c.stateManager.GetActiveSessionsForRepo()
+	if err != nil {
+		return nil, err
+	}
+	sort.Strings(activeSessions)
+	home, _ := os.UserHomeDir()
+
+	var sessions []scrollback.Session
+	for _, sessionName := range activeSessions {
+		agentState, err := c.GetSessionState(sessionName)
+		if err != nil {
+			continue
+		}
+		session := scrollback.Session{Name: sessionName, Executor: proxyExecutor{}}
+		if agentState.IsRemote() {
+			session.Executor = hosts.ForState(*agentState)
+		} else if home != "" && agentState.WorktreePath != "" {
+			session.TranscriptDir = scrollback.TranscriptDir(home, agentState.WorktreePath)
+		}
+		sessions = append(sessions, session)
+	}
+	return sessions, nil
+}
+
+// PendingOverlaps finds the files a session changed that other active
+// agents have pending changes to as well, as `uzi overlap <agent>` does.
+// Sessions whose changes can't be read are skipped.
+func (c *UziCLI) PendingOverlaps(sessionName string) ([]state.FileOverlap, error) {
+	if c.stateManager == nil {
+		return nil, c.wrapError("PendingOverlaps", fmt.Errorf("state manager not initialized"))
+	}
+	activeSessions, err := c.stateManager.GetActiveSessionsForRepo()
 	if err != nil {
-		return c.wrapError("RunCheckpoint", fmt.Errorf("%w\nOutput: %s", err, string(output)))
+		return nil, c.wrapError("PendingOverlaps", err)
+	}
+
+	changes := make(map[string]state.WorktreeChanges, len(activeSessions))
+	for _, name := range activeSessions {
+		agentState, err := c.GetSessionState(name)
+		if err != nil {
+			continue
+		}
+		agentChanges, err := c.aggregator.Changes(*agentState)
+		if err != nil {
+			log.Printf("Skipping %s in overlap check: %v", name, err)
+			continue
+		}
+		changes[state.AgentNameFromSession(name)] = agentChanges
+	}
+
+	agentName := state.AgentNameFromSession(sessionName)
+	if _, ok := changes[agentName]; !ok {
+		return nil, c.wrapError("PendingOverlaps", fmt.Errorf("could not read changes of session: %s", sessionName))
+	}
+	var overlaps []state.FileOverlap
+	for _, overlap := range state.Overlaps(changes) {
+		if overlap.Involves(agentName) {
+			overlaps = append(overlaps, overlap)
+		}
+	}
+	return overlaps, nil
+}
+
+// GetChangedFiles implements UziInterface by listing the files that differ between
+// the agent worktree and the point where its branch diverged, or the commit it was
+// pinned to, including untracked files. It only reads from git, so the agent's index
+// is left untouched.
+func (c *UziCLI) GetChangedFiles(sessionName string) ([]string, error) {
+	agentState, err := c.GetSessionState(sessionName)
+	if err != nil {
+		return nil, c.wrapError("GetChangedFiles", err)
+	}
+	if agentState.WorktreePath == "" {
+		return nil, c.wrapError("GetChangedFiles", fmt.Errorf("no worktree for session: %s", sessionName))
+	}
+
+	base := "HEAD"
+	switch {
+	case agentState.BaseCommit != "":
+		base = agentState.BaseCommit
+	case agentState.BranchFrom != "":
+		output, err := c.gitOutput(agentState.WorktreePath, "merge-base", agentState.BranchFrom, "HEAD")
+		if err != nil {
+			return nil, c.wrapError("GetChangedFiles", err)
+		}
+		base = strings.TrimSpace(output)
+	}
+
+	changed, err := c.gitOutput(agentState.WorktreePath, "diff", "--name-only", base)
+	if err != nil {
+		return nil, c.wrapError("GetChangedFiles", err)
+	}
+	untracked, err := c.gitOutput(agentState.WorktreePath, "ls-files", "--others", "--exclude-standard")
+	if err != nil {
+		return nil, c.wrapError("GetChangedFiles", err)
+	}
+
+	seen := make(map[string]bool)
+	files := []string{}
+	for _, line := range strings.Split(changed+"\n"+untracked, "\n") {
+		if file := strings.TrimSpace(line); file != "" && !seen[file] {
+			seen[file] = true
+			files = append(files, file)
+		}
+	}
+	sort.Strings(files)
+	return files, nil
+}
+
+// gitOutput runs a git command in dir and returns its stdout
+func (c *UziCLI) gitOutput(dir string, args ...string) (string, error) {
+	cmd := uziExecCommand("git", args...)
+	cmd.Dir = dir
+	var stdout, stderr bytes.Buffer
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+	if err := cmd.Run(); err != nil {
+		return "", fmt.Errorf("git %s failed: %w - stderr: %s", strings.Join(args, " "), err, stderr.String())
+	}
+	return stdout.String(), nil
+}
+
+// RunNudge implements UziInterface using the proxy pattern
+func (c *UziCLI) RunNudge(agentName string) error {
+	output, err := c.executeCommand("uzi", "nudge", agentName)
+	if err != nil {
+		return c.wrapError("RunNudge", fmt.Errorf("%w\nOutput: %s", err, string(output)))
 	}
 	return nil
 }
 
+// GetPipelineRuns implements UziInterface using the proxy pattern
+func (c *UziCLI) GetPipelineRuns() ([]pipeline.Run, error) {
+	output, err := c.executeCommand("uzi", "pipeline", "ls", "--json")
+	if err != nil {
+		return nil, c.wrapError("GetPipelineRuns", err)
+	}
+
+	var runs []pipeline.Run
+	if err := json.Unmarshal(output, &runs); err != nil {
+		return nil, c.wrapError("GetPipelineRuns", fmt.Errorf("failed to parse JSON: %w", err))
+	}
+	return runs, nil
+}
+
+// GetDevLog implements UziInterface by capturing the session's uzi-dev tmux
+// window, scrollback included, on the host the session runs on
+func (c *UziCLI) GetDevLog(sessionName string, lines int) (string, error) {
+	args := []string{"capture-pane", "-p", "-J", "-t", sessionName + ":uzi-dev", "-S", fmt.Sprintf("-%d", lines)}
+	var output []byte
+	var err error
+	if agentState, stateErr := c.GetSessionState(sessionName); stateErr == nil && agentState.IsRemote() {
+		output, err = hosts.ForState(*agentState).ExecuteCommand("tmux", args...)
+	} else {
+		output, err = uziExecCommand("tmux", args...).Output()
+	}
+	if err != nil {
+		return "", c.wrapError("GetDevLog", fmt.Errorf("no dev server window for %s (is devCommand set in uzi.yaml?): %w", sessionName, err))
+	}
+	// The capture is padded with the empty rows below the last output
+	return strings.TrimRight(string(output), " \n"), nil
+}
+
 // SpawnAgent implements UziInterface - creates a new agent following the uzi nuke && uzi start workflow
 // This method handles the full agent creation process including:
 // - Branch creation with unique naming
@@ -434,11 +1249,28 @@ func (c *UziCLI) SpawnAgent(prompt, model string) (string, error) {
 	start := time.Now()
 	defer func() { c.logOperation("SpawnAgent", time.Since(start), nil) }()
 
+	if err := c.checkCheckout(); err != nil {
+		return "", c.wrapError("SpawnAgent", err)
+	}
+	if err := c.checkAgentCount(1); err != nil {
+		return "", c.wrapError("SpawnAgent", err)
+	}
+
+	// Without a model, spawn the first of the default agents from uzi.yaml
+	if model == "" {
+		model = "claude"
+		if cfg, err := c.loadDefaultConfig(); err == nil {
+			if agent, _, ok := cfg.DefaultAgent(); ok {
+				model = agent
+			}
+		}
+	}
+
 	// Create the agent configuration by wrapping model in agent:count format
 	agentsFlag := fmt.Sprintf("%s:1", model)
 
 	// Execute the spawn workflow directly using our internal implementation
-	sessionName, err := c.executeSpawnWorkflow(agentsFlag, prompt)
+	sessionName, err := c.executeSpawnWorkflow(context.Background(), agentsFlag, prompt, "", nil)
 	if err != nil {
 		return "", c.wrapError("SpawnAgent", err)
 	}
@@ -446,9 +1278,102 @@ func (c *UziCLI) SpawnAgent(prompt, model string) (string, error) {
 	return sessionName, nil
 }
 
+// RespawnWithPrompt implements UziInterface by killing the session and spawning
+// an agent of the same model on a fresh worktree with newPrompt. The new
+// session keeps the old one's tags, runtime budget, and dev command.
+func (c *UziCLI) RespawnWithPrompt(sessionName, newPrompt string) (string, error) {
+	start := time.Now()
+	defer func() { c.logOperation("RespawnWithPrompt", time.Since(start), nil) }()
+
+	old, err := c.GetSessionState(sessionName)
+	if err != nil {
+		return "", c.wrapError("RespawnWithPrompt", err)
+	}
+	if old.IsRemote() {
+		return "", c.wrapError("RespawnWithPrompt", fmt.Errorf("%s runs on host %s; respawn it with uzi prompt --host", extractAgentName(sessionName), old.Host))
+	}
+	model := old.Model
+	if model == "" {
+		model = "claude"
+	}
+	// Refuse before the old agent is gone
+	if err := c.checkCheckout(); err != nil {
+		return "", c.wrapError("RespawnWithPrompt", err)
+	}
+
+	if err := c.KillSession(sessionName); err != nil {
+		return "", err
+	}
+	newSession, err := c.executeSpawnWorkflow(context.Background(), model+":1", newPrompt, old.DevCommand, nil)
+	if err != nil {
+		return "", c.wrapError("RespawnWithPrompt", err)
+	}
+
+	// The retry is already running; losing its tags or budget is not worth failing over
+	if len(old.Tags) > 0 {
+		if err := c.stateManager.SetTags(newSession, old.Tags); err != nil {
+			log.Printf("Failed to carry tags over to %s: %v", newSession, err)
+		}
+	}
+	if len(old.Channels) > 0 {
+		if err := c.stateManager.SetChannels(newSession, old.Channels); err != nil {
+			log.Printf("Failed to carry channels over to %s: %v", newSession, err)
+		}
+	}
+	if old.MaxRuntime > 0 {
+		if err := c.stateManager.SetMaxRuntime(newSession, old.MaxRuntime); err != nil {
+			log.Printf("Failed to carry runtime budget over to %s: %v", newSession, err)
+		}
+	}
+	return newSession, nil
+}
+
+// checkCheckout applies the dirtyCheckout policy of uzi.yaml to the main
+// checkout agents branch from, as uzi prompt does: a checkout with
+// uncommitted changes or missing upstream commits is logged, or refused under
+// block. A checkout that can't be inspected is not held against the spawn.
+func (c *UziCLI) checkCheckout() error {
+	cfg, _ := c.loadDefaultConfig()
+	policy := cfg.DirtyCheckout()
+	if policy == config.DirtyCheckoutAllow {
+		return nil
+	}
+	status, err := state.InspectCheckout(proxyExecutor{}, ".")
+	if err != nil || status.Clean() {
+		return nil
+	}
+	if policy == config.DirtyCheckoutBlock {
+		return fmt.Errorf("%s; set dirtyCheckout: warn in uzi.yaml to spawn anyway", status.SpawnWarning())
+	}
+	log.Printf("Spawning anyway: %s", status.SpawnWarning())
+	return nil
+}
+
+// checkAgentCount applies policy.maxAgents to spawning adding agents next to
+// the repository's active ones
+func (c *UziCLI) checkAgentCount(adding int) error {
+	cfg, _ := c.loadDefaultConfig()
+	if cfg.MaxAgents() == 0 {
+		return nil
+	}
+	active, err := c.stateManager.GetActiveSessionsForRepo()
+	if err != nil {
+		return fmt.Errorf("failed to count active agents: %w", err)
+	}
+	return cfg.CheckAgentCount(len(active), adding)
+}
+
+// checkBroadcast applies policy.forbidBroadcastPatterns to a broadcast message
+func (c *UziCLI) checkBroadcast(message string) error {
+	cfg, _ := c.loadDefaultConfig()
+	return cfg.CheckBroadcast(message)
+}
+
 // executeSpawnWorkflow implements the core agent spawning logic based on cmd/prompt/prompt.go
-// This follows the same workflow as `uzi prompt` but returns the created session name
-func (c *UziCLI) executeSpawnWorkflow(agentsFlag, promptText string) (string, error) {
+// This follows the same workflow as `uzi prompt` but returns the created session name.
+// A non-empty devCommand replaces devCommand from uzi.yaml, as --dev-command does.
+// With a progress channel, the steps of each agent are reported to it as SpawnEvents.
+func (c *UziCLI) executeSpawnWorkflow(ctx context.Context, agentsFlag, promptText, devCommand string, progress chan<- SpawnEvent) (string, error) {
 	// Load config - required for standardized dev environment setup (will be handled in individual helper methods)
 	// The UziCLI uses ProxyConfig, not uzi.yaml config, so we'll handle config loading in helper methods
 
@@ -470,11 +1395,21 @@ func (c *UziCLI) executeSpawnWorkflow(agentsFlag, promptText string) (string, er
 	// Track assigned ports
 	assignedPorts := existingPorts
 	var createdSessionName string
+	total := 0
+	for _, config := range agentConfigs {
+		total += config.Count
+	}
 
 	// Process each agent configuration (typically just one for SpawnAgent)
+	created := 0
 	for agent, config := range agentConfigs {
 		for i := 0; i < config.Count; i++ {
-			sessionName, err := c.createSingleAgent(agent, config, promptText, &assignedPorts, stateManager)
+			created++
+			var report *spawnReporter
+			if progress != nil {
+				report = &spawnReporter{progress: progress, agent: created, agents: total}
+			}
+			sessionName, err := c.createSingleAgent(ctx, agent, config, promptText, devCommand, &assignedPorts, stateManager, report)
 			if err != nil {
 				return "", fmt.Errorf("failed to create agent %s: %w", agent, err)
 			}
@@ -493,8 +1428,12 @@ func (c *UziCLI) executeSpawnWorkflow(agentsFlag, promptText string) (string, er
 	return createdSessionName, nil
 }
 
-// createSingleAgent creates a single agent session following the established workflow
-func (c *UziCLI) createSingleAgent(agent string, config AgentConfig, promptText string, assignedPorts *[]int, stateManager StateManagerInterface) (string, error) {
+// createSingleAgent creates a single agent session following the established workflow.
+// If a step fails, or ctx is cancelled before the agent is saved, the branch,
+// worktree and tmux session created before it are removed. With a reporter,
+// each step is reported, and the dev server and agent are watched until they
+// are seen up.
+func (c *UziCLI) createSingleAgent(ctx context.Context, agent string, agentConfig AgentConfig, promptText, devCommand string, assignedPorts *[]int, stateManager StateManagerInterface, report *spawnReporter) (_ string, err error) {
 	// Generate random agent name for unique identification
 	randomAgentName, err := c.getRandomAgentName(agent)
 	if err != nil {
@@ -507,126 +1446,175 @@ func (c *UziCLI) createSingleAgent(agent string, config AgentConfig, promptText
 		return "", fmt.Errorf("failed to get git information: %w", err)
 	}
 
-	// Generate unique identifiers
-	timestamp := time.Now().Unix()
-	uniqueId := fmt.Sprintf("%d", timestamp)
+	commandToUse := agentConfig.Command
+	if agent == "random" {
+		commandToUse = randomAgentName
+	}
 
-	// Create branch and session names
-	branchName := fmt.Sprintf("%s-%s-%s-%s", randomAgentName, projectDir, gitHash, uniqueId)
-	worktreeName := fmt.Sprintf("%s-%s-%s-%s", randomAgentName, projectDir, gitHash, uniqueId)
-	sessionName := fmt.Sprintf("agent-%s-%s-%s", projectDir, gitHash, randomAgentName)
+	// Create branch, session and window names from the naming templates in
+	// uzi.yaml; a missing config uses the default names
+	cfg, _ := c.loadDefaultConfig()
+	fields := config.NameFields{Agent: randomAgentName, Project: projectDir, Hash: gitHash, Time: time.Now(), Model: commandToUse, Prompt: promptText}
+	branchName := config.RenderName(cfg.BranchTemplate(), fields)
+	worktreeName := strings.ReplaceAll(branchName, "/", "-")
+	sessionName := config.RenderName(cfg.SessionTemplate(), fields)
+	windowName := config.RenderName(cfg.WindowTemplate(), fields)
+
+	// Another TUI may be spawning or killing a session with the same name
+	if stateManager != nil {
+		lock, err := stateManager.LockSession(sessionName, "spawn")
+		if err != nil {
+			return "", err
+		}
+		defer lock.Release()
+	}
+
+	// Undo the steps that succeeded, newest first, if a later one fails;
+	// this runs before the lock is released
+	var undo []func() error
+	defer func() {
+		if err == nil {
+			return
+		}
+		for i := len(undo) - 1; i >= 0; i-- {
+			if undoErr := undo[i](); undoErr != nil {
+				log.Printf("Failed to roll back spawn of %s: %v", sessionName, undoErr)
+			}
+		}
+	}()
 
 	// Create worktree
+	if err := ctx.Err(); err != nil {
+		return "", err
+	}
+	report.start(ProgressStepSetupWorktree)
 	worktreePath, err := c.createWorktree(branchName, worktreeName)
 	if err != nil {
 		return "", fmt.Errorf("failed to create worktree: %w", err)
 	}
+	undo = append(undo,
+		func() error { return exec.Command("git", "branch", "-D", branchName).Run() },
+		func() error { return exec.Command("git", "worktree", "remove", "--force", worktreePath).Run() },
+	)
+	report.done(ProgressStepSetupWorktree, "")
 
-	// Create tmux session
-	if err := c.createTmuxSession(sessionName, worktreePath); err != nil {
+	// Create tmux session; killing it also stops the dev server and frees its port
+	undo = append(undo, func() error {
+		if exec.Command("tmux", "has-session", "-t", sessionName).Run() != nil {
+			return nil
+		}
+		return exec.Command("tmux", "kill-session", "-t", sessionName).Run()
+	})
+	if err := ctx.Err(); err != nil {
+		return "", err
+	}
+	report.start(ProgressStepCreateTmux)
+	if err := c.createTmuxSession(sessionName, windowName, commandToUse, worktreePath); err != nil {
 		return "", fmt.Errorf("failed to create tmux session: %w", err)
 	}
+	report.done(ProgressStepCreateTmux, "")
+
+	// Install dependencies with setupCommand from uzi.yaml
+	if err := ctx.Err(); err != nil {
+		return "", err
+	}
+	if setup := cfg.WorktreeSetupCommand(); setup != "" {
+		report.start(ProgressStepInstallDeps)
+		setupExec := exec.CommandContext(ctx, "sh", "-c", setup)
+		setupExec.Dir = worktreePath
+		if output, err := setupExec.CombinedOutput(); err != nil {
+			if ctx.Err() != nil {
+				return "", ctx.Err()
+			}
+			return "", fmt.Errorf("setup command failed: %w: %s", err, strings.TrimSpace(string(output)))
+		}
+		report.done(ProgressStepInstallDeps, "")
+	} else {
+		report.skip(ProgressStepInstallDeps)
+	}
 
 	// Setup development environment and execute agent command
+	if err := ctx.Err(); err != nil {
+		return "", err
+	}
 	var selectedPort int
 	// Always try to setup dev environment - the method will check if config is available
-	selectedPort, err = c.setupDevEnvironment(sessionName, worktreePath, assignedPorts)
-	if err != nil {
+	report.start(ProgressStepDevServer)
+	selectedPort, err = c.setupDevEnvironment(sessionName, worktreePath, devCommand, assignedPorts)
+	switch {
+	case err != nil:
 		log.Printf("Failed to setup dev environment, continuing without it: %v", err)
 		selectedPort = 0
+		report.done(ProgressStepDevServer, "dev server not started: "+err.Error())
+	case selectedPort == 0:
+		report.skip(ProgressStepDevServer)
+	case report != nil:
+		report.done(ProgressStepDevServer, awaitDevServer(ctx, selectedPort))
 	}
 
-	// Execute the agent command
-	commandToUse := config.Command
-	if agent == "random" {
-		commandToUse = randomAgentName
+	// Model arguments come from uzi.yaml; a missing config starts the agent without any
+	modelArgs := cfg.AgentModelArgs(agent, commandToUse)
+	if err := config.CheckModelArgs(modelArgs); err != nil {
+		return "", err
 	}
 
-	if err := c.executeAgentCommand(sessionName, commandToUse, promptText, worktreePath); err != nil {
+	if err := ctx.Err(); err != nil {
+		return "", err
+	}
+	report.start(ProgressStepStartAgent)
+	if err := c.executeAgentCommand(sessionName, commandToUse, modelArgs, promptText, worktreePath); err != nil {
 		return "", fmt.Errorf("failed to execute agent command: %w", err)
 	}
+	if report != nil {
+		note := c.awaitAgentLaunch(ctx, sessionName)
+		if err := ctx.Err(); err != nil {
+			return "", err
+		}
+		report.done(ProgressStepStartAgent, note)
+	}
 
-	// Save state
+	// Save state; an agent uzi can't track is rolled back like any other failure
 	if stateManager != nil {
 		if selectedPort > 0 {
-			if err := stateManager.SaveStateWithPort(promptText, branchName, sessionName, worktreePath, commandToUse, selectedPort); err != nil {
-				log.Printf("Failed to save state with port: %v", err)
-			}
+			err = stateManager.SaveStateWithPort(promptText, branchName, sessionName, worktreePath, commandToUse, selectedPort)
 		} else {
-			if err := stateManager.SaveState(promptText, branchName, sessionName, worktreePath, commandToUse); err != nil {
-				log.Printf("Failed to save state: %v", err)
+			err = stateManager.SaveState(promptText, branchName, sessionName, worktreePath, commandToUse)
+		}
+		if err != nil {
+			return "", fmt.Errorf("failed to save state: %w", err)
+		}
+		if devCommand != "" {
+			if err = stateManager.SetDevCommand(sessionName, devCommand); err != nil {
+				return "", fmt.Errorf("failed to save dev command: %w", err)
 			}
 		}
 	}
 
+	c.dispatcher.Dispatch(events.NewEvent(events.EventSpawn, sessionName, promptText))
 	return sessionName, nil
 }
 
 // Helper functions (these replicate logic from cmd/ls/ls.go for now)
 
 // extractAgentName extracts the agent name from a session name
-// Session format: agent-projectDir-gitHash-agentName
 func extractAgentName(sessionName string) string {
-	// Session format: agent-<project>-<hash>-<agent-name>
-	// We need to find the last hash-like part and return everything after it
-	parts := strings.Split(sessionName, "-")
-	if len(parts) >= 4 && parts[0] == "agent" {
-		// Look for the hash part - typically 6+ alphanumeric characters
-		// Start from the end and work backwards to find the last hash-like part
-		for i := len(parts) - 2; i >= 2; i-- {
-			part := parts[i]
-			if isHashLike(part) {
-				// Return everything after this hash part
-				return strings.Join(parts[i+1:], "-")
-			}
-		}
-		// Fallback: assume standard format agent-project-hash-agent
-		if len(parts) >= 4 {
-			return strings.Join(parts[3:], "-")
-		}
-	}
-	return sessionName
-}
-
-// isHashLike checks if a string looks like a git hash (alphanumeric, typically 6+ chars)
-func isHashLike(s string) bool {
-	if len(s) < 6 {
-		return false
-	}
-
-	hasDigit := false
-	hasLetter := false
-
-	for _, r := range s {
-		if r >= '0' && r <= '9' {
-			hasDigit = true
-		} else if (r >= 'a' && r <= 'z') || (r >= 'A' && r <= 'Z') {
-			hasLetter = true
-		} else {
-			return false // Contains non-alphanumeric character
-		}
-	}
-
-	// A hash should have both letters and digits
-	return hasDigit && hasLetter
+	return state.AgentNameFromSession(sessionName)
 }
 
-// getAgentStatus determines the current status of an agent session
+// getAgentStatus determines the current status of an agent session from its
+// pane and state, resolved by the state package's status engine as uzi ls does
 func (c *UziCLI) getAgentStatus(sessionName string) string {
 	content, err := c.getPaneContent(sessionName)
-	if err != nil {
-		return "unknown"
-	}
-
-	if strings.Contains(content, "esc to interrupt") || strings.Contains(content, "Thinking") {
-		return "running"
+	signals := state.StatusSignals{Alive: true, Pane: content, PaneErr: err}
+	if agentState, err := c.GetSessionState(sessionName); err == nil {
+		signals.Paused, signals.Done = agentState.Paused, agentState.Done
 	}
-	return "ready"
+	return signals.Resolve()
 }
 
 // getPaneContent gets the content of a tmux pane
 func (c *UziCLI) getPaneContent(sessionName string) (string, error) {
-	cmd := uziExecCommand("tmux", "capture-pane", "-t", sessionName+":agent", "-p")
+	cmd := uziExecCommand("tmux", "capture-pane", "-t", tmuxops.AgentTarget(sessionName), "-p")
 	output, err := cmd.Output()
 	if err != nil {
 		return "", err
@@ -639,34 +1627,27 @@ func (c *UziCLI) getGitDiffTotals(sessionName string, sessionState *state.AgentS
 	if sessionState.WorktreePath == "" {
 		return 0, 0
 	}
+	output, err := c.diffStat(sessionState.WorktreePath)
+	if err != nil {
+		return 0, 0
+	}
+	return state.ParseDiffStat(output)
+}
 
-	shellCmdString := "git add -A . && git diff --cached --shortstat HEAD && git reset HEAD > /dev/null"
-	cmd := uziExecCommand("sh", "-c", shellCmdString)
+// diffStat returns `git diff --shortstat` output for all changes in a worktree
+func (c *UziCLI) diffStat(worktreePath string) (string, error) {
+	cmd := uziExecCommand("sh", "-c", state.DiffScript)
 
 	// Only set Dir if it's not a test directory
-	if !strings.Contains(sessionState.WorktreePath, "test-worktree") && !strings.Contains(sessionState.WorktreePath, "/tmp/test-") {
-		cmd.Dir = sessionState.WorktreePath
+	if !strings.Contains(worktreePath, "test-worktree") && !strings.Contains(worktreePath, "/tmp/test-") {
+		cmd.Dir = worktreePath
 	}
 
 	output, err := cmd.Output()
 	if err != nil {
-		return 0, 0
-	}
-
-	insertions := 0
-	deletions := 0
-
-	insRe := regexp.MustCompile(`(\d+) insertion(?:s)?\(\+\)`)
-	delRe := regexp.MustCompile(`(\d+) deletion(?:s)?\(\-\)`)
-
-	if m := insRe.FindStringSubmatch(string(output)); len(m) > 1 {
-		fmt.Sscanf(m[1], "%d", &insertions)
-	}
-	if m := delRe.FindStringSubmatch(string(output)); len(m) > 1 {
-		fmt.Sscanf(m[1], "%d", &deletions)
+		return "", err
 	}
-
-	return insertions, deletions
+	return string(output), nil
 }
 
 // Enhanced methods using tmux discovery
@@ -684,18 +1665,12 @@ func (c *UziCLI) GetSessionsWithTmuxInfo() ([]SessionInfo, map[string]TmuxSessio
 		return sessions, nil, err // Return sessions even if tmux mapping fails
 	}
 
-	// Update session status with tmux information where available
+	// Sessions missing from tmux are mapped to windowless placeholders and are
+	// dead; the others keep the status uzi ls resolved, which tmux attachment
+	// does not change
 	for i := range sessions {
-		if tmuxInfo, exists := tmuxMapping[sessions[i].Name]; exists {
-			// Enhance status with tmux information
-			if tmuxInfo.Attached {
-				sessions[i].Status = "attached"
-			} else if sessions[i].Status == "ready" {
-				// Only update if current status is generic "ready"
-				if tmuxStatus, err := c.tmuxDiscovery.GetSessionStatus(sessions[i].Name); err == nil {
-					sessions[i].Status = tmuxStatus
-				}
-			}
+		if tmuxMapping[sessions[i].Name].Windows == 0 {
+			sessions[i].Status = state.StatusDead
 		}
 	}
 
@@ -780,6 +1755,19 @@ func (c *UziClient) KillSession(sessionName string) error {
 	return fmt.Errorf("not implemented - use UziCLI instead")
 }
 
+func (c *UziClient) InspectKill(sessionName string) (state.PendingWork, error) {
+	// Stub: will be replaced by UziCLI implementation
+	_ = sessionName
+	return state.PendingWork{}, nil
+}
+
+func (c *UziClient) RespawnWithPrompt(sessionName, newPrompt string) (string, error) {
+	// Stub: will be replaced by UziCLI implementation
+	_ = sessionName
+	_ = newPrompt
+	return "", fmt.Errorf("not implemented - use UziCLI instead")
+}
+
 func (c *UziClient) RefreshSessions() error {
 	// Stub: will be replaced by UziCLI implementation
 	return nil
@@ -792,12 +1780,10 @@ func (c *UziClient) SpawnAgent(prompt, model string) (string, error) {
 	return "", fmt.Errorf("not implemented - use UziCLI instead")
 }
 
-func (c *UziClient) SpawnAgentInteractive(opts string) (<-chan struct{}, error) {
+func (c *UziClient) SpawnAgentInteractive(ctx context.Context, opts string, progress chan<- SpawnEvent) error {
 	// Stub: will be replaced by UziCLI implementation
 	_ = opts
-	ch := make(chan struct{})
-	close(ch)
-	return ch, fmt.Errorf("not implemented - use UziCLI instead")
+	return fmt.Errorf("not implemented - use UziCLI instead")
 }
 
 // SpawnAgent helper methods implementation
@@ -808,8 +1794,18 @@ type AgentConfig struct {
 	Count   int
 }
 
-// loadDefaultConfig loads the default uzi configuration
+// SetConfig makes spawns use cfg instead of re-reading uzi.yaml and applies its
+// status thresholds; the TUI calls it on hot-reload
+func (c *UziCLI) SetConfig(cfg *config.Config) {
+	c.spawnConfig.Store(cfg)
+	c.tmuxDiscovery.SetActiveThreshold(cfg.ActiveThreshold())
+}
+
+// loadDefaultConfig returns the hot-reloaded configuration, or loads the default uzi configuration
 func (c *UziCLI) loadDefaultConfig() (*config.Config, error) {
+	if cfg := c.spawnConfig.Load(); cfg != nil {
+		return cfg, nil
+	}
 	configPath := config.GetDefaultConfigPath()
 	return config.LoadConfig(configPath)
 }
@@ -851,23 +1847,10 @@ func (c *UziCLI) parseAgentConfigs(agentsStr string) (map[string]AgentConfig, er
 	return agentConfigs, nil
 }
 
-// getCommandForAgent maps agent names to their actual CLI commands
+// getCommandForAgent maps agent names to their actual CLI commands, from
+// the built-in and configured agent definitions
 func (c *UziCLI) getCommandForAgent(agent string) string {
-	switch agent {
-	case "claude":
-		return "claude"
-	case "cursor":
-		return "cursor"
-	case "codex":
-		return "codex"
-	case "gemini":
-		return "gemini"
-	case "random":
-		return "claude" // Default for random agents
-	default:
-		// For unknown agents, assume the agent name is the command
-		return agent
-	}
+	return agents.Command(agent)
 }
 
 // getExistingSessionPorts reads the state file and returns all currently assigned ports
@@ -942,13 +1925,16 @@ func (c *UziCLI) getGitInfo() (gitHash, projectDir string, err error) {
 func (c *UziCLI) createWorktree(branchName, worktreeName string) (string, error) {
 	ctx := context.Background()
 
-	// Get home directory for worktree storage
-	homeDir, err := os.UserHomeDir()
+	// Store worktrees in worktreeDir from uzi.yaml, relative to the main checkout
+	topLevel, err := exec.CommandContext(ctx, "git", "rev-parse", "--show-toplevel").Output()
 	if err != nil {
-		return "", fmt.Errorf("error getting home directory: %w", err)
+		return "", fmt.Errorf("error finding main checkout: %w", err)
+	}
+	cfg, _ := c.loadDefaultConfig()
+	worktreesDir, err := cfg.ResolveWorktreeDir(strings.TrimSpace(string(topLevel)))
+	if err != nil {
+		return "", fmt.Errorf("error resolving worktree directory: %w", err)
 	}
-
-	worktreesDir := filepath.Join(homeDir, ".local", "share", "uzi", "worktrees")
 	if err := os.MkdirAll(worktreesDir, 0755); err != nil {
 		return "", fmt.Errorf("error creating worktrees directory: %w", err)
 	}
@@ -965,29 +1951,44 @@ func (c *UziCLI) createWorktree(branchName, worktreeName string) (string, error)
 	return worktreePath, nil
 }
 
-// createTmuxSession creates a tmux session for the agent
-func (c *UziCLI) createTmuxSession(sessionName, worktreePath string) error {
+// apiKeys is where `uzi auth set` stores the API keys of agent types
+var apiKeys = keyring.System()
+
+// createTmuxSession creates a tmux session marked as running the agent CLI,
+// with its first window named windowName and marked as the agent window
+func (c *UziCLI) createTmuxSession(sessionName, windowName, agent, worktreePath string) error {
 	ctx := context.Background()
 
-	// Create tmux session
-	cmd := fmt.Sprintf("tmux new-session -d -s %s -c %s", sessionName, worktreePath)
-	cmdExec := exec.CommandContext(ctx, "sh", "-c", cmd)
+	// Create tmux session, exporting the agent's API key from the keyring.
+	// The environment goes to tmux on stdin to keep the key out of the
+	// process list.
+	cfg, _ := c.loadDefaultConfig()
+	env := append(agents.EnvFor(agent), keyring.AgentEnv(apiKeys, cfg, agent)...)
+	cmdExec := exec.CommandContext(ctx, "tmux", tmuxops.SourceStdinArgs()...)
+	cmdExec.Stdin = strings.NewReader(tmuxops.NewSessionScript(sessionName, worktreePath, env))
 	if err := cmdExec.Run(); err != nil {
 		return fmt.Errorf("error creating tmux session: %w", err)
 	}
 
-	// Rename the first window to "agent"
-	renameCmd := fmt.Sprintf("tmux rename-window -t %s:0 agent", sessionName)
-	renameExec := exec.CommandContext(ctx, "sh", "-c", renameCmd)
+	renameExec := exec.CommandContext(ctx, "tmux", "rename-window", "-t", tmuxops.AgentTarget(sessionName), windowName)
 	if err := renameExec.Run(); err != nil {
 		return fmt.Errorf("error renaming tmux window: %w", err)
 	}
+	markExec := exec.CommandContext(ctx, "tmux", tmuxops.MarkAgentWindowArgs(sessionName)...)
+	if err := markExec.Run(); err != nil {
+		return fmt.Errorf("error marking the agent window: %w", err)
+	}
+	markSessionExec := exec.CommandContext(ctx, "tmux", tmuxops.MarkSessionArgs(sessionName, agent)...)
+	if err := markSessionExec.Run(); err != nil {
+		return fmt.Errorf("error marking the tmux session: %w", err)
+	}
 
 	return nil
 }
 
-// setupDevEnvironment sets up the development environment if configured
-func (c *UziCLI) setupDevEnvironment(sessionName, worktreePath string, assignedPorts *[]int) (int, error) {
+// setupDevEnvironment sets up the development environment if configured, running
+// the given dev command, if any, or else devCommand from uzi.yaml
+func (c *UziCLI) setupDevEnvironment(sessionName, worktreePath, devCommand string, assignedPorts *[]int) (int, error) {
 	ctx := context.Background()
 
 	// Load configuration to get dev settings
@@ -996,20 +1997,15 @@ func (c *UziCLI) setupDevEnvironment(sessionName, worktreePath string, assignedP
 		return 0, fmt.Errorf("failed to load config: %w", err)
 	}
 
-	if cfg.DevCommand == nil || *cfg.DevCommand == "" || cfg.PortRange == nil || *cfg.PortRange == "" {
+	devCmdTemplate := cfg.DevCommandFor(devCommand)
+	if devCmdTemplate == "" {
 		return 0, nil // No dev environment to set up
 	}
 
-	// Parse port range
-	ports := strings.Split(*cfg.PortRange, "-")
-	if len(ports) != 2 {
-		return 0, fmt.Errorf("invalid port range format: %s", *cfg.PortRange)
-	}
-
-	startPort, err1 := strconv.Atoi(ports[0])
-	endPort, err2 := strconv.Atoi(ports[1])
-	if err1 != nil || err2 != nil || startPort <= 0 || endPort <= 0 || endPort < startPort {
-		return 0, fmt.Errorf("invalid port range: %s", *cfg.PortRange)
+	// Without portRange the port is picked from the automatic range
+	startPort, endPort, _, err := cfg.DevPortRange()
+	if err != nil {
+		return 0, err
 	}
 
 	// Find available port
@@ -1019,7 +2015,6 @@ func (c *UziCLI) setupDevEnvironment(sessionName, worktreePath string, assignedP
 	}
 
 	// Create development command
-	devCmdTemplate := *cfg.DevCommand
 	devCmd := strings.Replace(devCmdTemplate, "$PORT", strconv.Itoa(selectedPort), 1)
 
 	// Create new window named uzi-dev
@@ -1076,24 +2071,19 @@ func (c *UziCLI) isPortAvailable(port int) bool {
 	return true
 }
 
-// executeAgentCommand executes the agent command in the tmux session
-func (c *UziCLI) executeAgentCommand(sessionName, commandToUse, promptText, worktreePath string) error {
+// executeAgentCommand executes the agent command with its model arguments in the tmux session
+func (c *UziCLI) executeAgentCommand(sessionName, commandToUse, modelArgs, promptText, worktreePath string) error {
 	ctx := context.Background()
 
 	// Hit enter in the agent pane
-	hitEnterCmd := fmt.Sprintf("tmux send-keys -t %s:agent C-m", sessionName)
+	agentTarget := hosts.Quote(tmuxops.AgentTarget(sessionName))
+	hitEnterCmd := fmt.Sprintf("tmux send-keys -t %s C-m", agentTarget)
 	hitEnterExec := exec.CommandContext(ctx, "sh", "-c", hitEnterCmd)
 	if err := hitEnterExec.Run(); err != nil {
 		return fmt.Errorf("error hitting enter in tmux: %w", err)
 	}
 
-	// Prepare the command template based on the agent type
-	var tmuxCmd string
-	if commandToUse == "gemini" {
-		tmuxCmd = fmt.Sprintf("tmux send-keys -t %s:agent '%s -p \"%s\"' C-m", sessionName, commandToUse, promptText)
-	} else {
-		tmuxCmd = fmt.Sprintf("tmux send-keys -t %s:agent '%s \"%s\"' C-m", sessionName, commandToUse, promptText)
-	}
+	tmuxCmd := fmt.Sprintf("tmux send-keys -t %s '%s' C-m", agentTarget, agents.Invocation(commandToUse, modelArgs, promptText))
 
 	tmuxCmdExec := exec.CommandContext(ctx, "sh", "-c", tmuxCmd)
 	tmuxCmdExec.Dir = worktreePath
@@ -1105,14 +2095,11 @@ func (c *UziCLI) executeAgentCommand(sessionName, commandToUse, promptText, work
 }
 
 // SpawnAgentInteractive implements the interactive agent creation with progress reporting
-func (c *UziCLI) SpawnAgentInteractive(opts string) (<-chan struct{}, error) {
-	progressChan := make(chan struct{}, 1)
-
+func (c *UziCLI) SpawnAgentInteractive(ctx context.Context, opts string, progress chan<- SpawnEvent) error {
 	// Parse options (format: "agentType:count:prompt")
 	parts := strings.SplitN(opts, ":", 3)
 	if len(parts) != 3 {
-		close(progressChan)
-		return progressChan, fmt.Errorf("invalid options format, expected 'agentType:count:prompt'")
+		return fmt.Errorf("invalid options format, expected 'agentType:count:prompt'")
 	}
 
 	agentType := strings.TrimSpace(parts[0])
@@ -1122,27 +2109,23 @@ func (c *UziCLI) SpawnAgentInteractive(opts string) (<-chan struct{}, error) {
 	// Validate count
 	count, err := strconv.Atoi(countStr)
 	if err != nil || count < 1 || count > 10 {
-		close(progressChan)
-		return progressChan, fmt.Errorf("invalid count: must be between 1 and 10")
+		return fmt.Errorf("invalid count: must be between 1 and 10")
 	}
 
-	// Start async agent creation
-	go func() {
-		defer close(progressChan)
-
-		// Create the agent configuration
-		agentsFlag := fmt.Sprintf("%s:%d", agentType, count)
-
-		// Execute the spawn workflow
-		_, err := c.executeSpawnWorkflow(agentsFlag, prompt)
-		if err != nil {
-			log.Printf("SpawnAgentInteractive failed: %v", err)
-			return
-		}
+	if err := c.checkCheckout(); err != nil {
+		return err
+	}
+	if err := c.checkAgentCount(count); err != nil {
+		return err
+	}
 
-		// Signal completion
-		progressChan <- struct{}{}
-	}()
+	// Create the agent configuration
+	agentsFlag := fmt.Sprintf("%s:%d", agentType, count)
 
-	return progressChan, nil
+	// Execute the spawn workflow
+	if _, err := c.executeSpawnWorkflow(ctx, agentsFlag, prompt, "", progress); err != nil {
+		log.Printf("SpawnAgentInteractive failed: %v", err)
+		return err
+	}
+	return nil
 }
@@ -6,13 +6,21 @@ package tui
 import (
 	"encoding/json"
 	"fmt"
+	"net/http"
+	"net/http/httptest"
 	"os"
+	"reflect"
 	"strings"
+	"sync"
 	"testing"
 	"time"
 
+	"github.com/nehpz/claudicus/pkg/activity"
+	"github.com/nehpz/claudicus/pkg/config"
+	"github.com/nehpz/claudicus/pkg/events"
 	"github.com/nehpz/claudicus/pkg/state"
 	"github.com/nehpz/claudicus/pkg/testutil/cmdmock"
+	"github.com/nehpz/claudicus/pkg/tmuxops"
 )
 
 func setupUziTest() {
@@ -51,6 +59,31 @@ func (m *mockStateManagerForTest) SaveStateWithPort(prompt, branchName, sessionN
 	return nil
 }
 
+func (m *mockStateManagerForTest) LockSession(sessionName, operation string) (*state.SessionLock, error) {
+	// Mock implementation for test
+	return &state.SessionLock{}, nil
+}
+
+func (m *mockStateManagerForTest) SetTags(sessionName string, tags []string) error {
+	// Mock implementation for test
+	return nil
+}
+
+func (m *mockStateManagerForTest) SetChannels(sessionName string, channels []string) error {
+	// Mock implementation for test
+	return nil
+}
+
+func (m *mockStateManagerForTest) SetMaxRuntime(sessionName string, maxRuntime time.Duration) error {
+	// Mock implementation for test
+	return nil
+}
+
+func (m *mockStateManagerForTest) SetDevCommand(sessionName, devCommand string) error {
+	// Mock implementation for test
+	return nil
+}
+
 // Test helpers
 func createTempStateFile(t *testing.T, states map[string]state.AgentState) string {
 	t.Helper()
@@ -584,7 +617,7 @@ func TestUziCLI_GetGitDiffTotals_EdgeCases(t *testing.T) {
 			}
 
 			// Mock the exact command that getGitDiffTotals will execute
-			gitCmd := "git add -A . && git diff --cached --shortstat HEAD && git reset HEAD > /dev/null"
+			gitCmd := state.DiffScript
 			cmdmock.SetResponseWithArgs("sh", []string{"-c", gitCmd}, tc.diffOutput, "", false)
 
 			// Verify the mock was set up correctly by testing the command directly
@@ -637,7 +670,7 @@ func TestUziCLI_GetGitDiffTotals_CommandError(t *testing.T) {
 	}
 
 	// Mock git command failure
-	gitCmd := "git add -A . && git diff --cached --shortstat HEAD && git reset HEAD > /dev/null"
+	gitCmd := state.DiffScript
 	cmdmock.SetResponseWithArgs("sh", []string{"-c", gitCmd},
 		"", "fatal: not a git repository", true)
 
@@ -671,7 +704,7 @@ func TestUziCLI_TmuxInteractions(t *testing.T) {
 			name:            "GetPaneContent - Success",
 			sessionName:     "agent-proj-abc123-claude",
 			mockCmd:         "tmux",
-			mockArgs:        []string{"capture-pane", "-t", "agent-proj-abc123-claude:agent", "-p"},
+			mockArgs:        []string{"capture-pane", "-t", "agent-proj-abc123-claude:{start}", "-p"},
 			mockStdout:      "$ echo hello\nhello\n$ ",
 			mockStderr:      "",
 			mockExitErr:     false,
@@ -682,7 +715,7 @@ func TestUziCLI_TmuxInteractions(t *testing.T) {
 			name:            "GetPaneContent - Tmux Error",
 			sessionName:     "nonexistent-session",
 			mockCmd:         "tmux",
-			mockArgs:        []string{"capture-pane", "-t", "nonexistent-session:agent", "-p"},
+			mockArgs:        []string{"capture-pane", "-t", "nonexistent-session:{start}", "-p"},
 			mockStdout:      "",
 			mockStderr:      "session not found",
 			mockExitErr:     true,
@@ -693,7 +726,7 @@ func TestUziCLI_TmuxInteractions(t *testing.T) {
 			name:            "GetAgentStatus - Running",
 			sessionName:     "agent-proj-abc123-claude",
 			mockCmd:         "tmux",
-			mockArgs:        []string{"capture-pane", "-t", "agent-proj-abc123-claude:agent", "-p"},
+			mockArgs:        []string{"capture-pane", "-t", "agent-proj-abc123-claude:{start}", "-p"},
 			mockStdout:      "Thinking about your request...\nesc to interrupt",
 			mockStderr:      "",
 			mockExitErr:     false,
@@ -704,7 +737,7 @@ func TestUziCLI_TmuxInteractions(t *testing.T) {
 			name:            "GetAgentStatus - Ready",
 			sessionName:     "agent-proj-abc123-claude",
 			mockCmd:         "tmux",
-			mockArgs:        []string{"capture-pane", "-t", "agent-proj-abc123-claude:agent", "-p"},
+			mockArgs:        []string{"capture-pane", "-t", "agent-proj-abc123-claude:{start}", "-p"},
 			mockStdout:      "$ waiting for input\n$ ",
 			mockStderr:      "",
 			mockExitErr:     false,
@@ -715,7 +748,7 @@ func TestUziCLI_TmuxInteractions(t *testing.T) {
 			name:            "GetAgentStatus - Unknown on Error",
 			sessionName:     "broken-session",
 			mockCmd:         "tmux",
-			mockArgs:        []string{"capture-pane", "-t", "broken-session:agent", "-p"},
+			mockArgs:        []string{"capture-pane", "-t", "broken-session:{start}", "-p"},
 			mockStdout:      "",
 			mockStderr:      "capture failed",
 			mockExitErr:     true,
@@ -792,7 +825,7 @@ func TestUziCLI_SessionManagement(t *testing.T) {
 			method:        "KillSession",
 			sessionName:   "agent-proj-abc123-claude",
 			mockCmd:       "uzi",
-			mockArgs:      []string{"kill", "claude"},
+			mockArgs:      []string{"kill", "--force", "--yes", "claude"},
 			mockStdout:    "Session killed",
 			mockStderr:    "",
 			mockExitErr:   false,
@@ -804,7 +837,7 @@ func TestUziCLI_SessionManagement(t *testing.T) {
 			method:        "KillSession",
 			sessionName:   "agent-proj-abc123-nonexistent",
 			mockCmd:       "uzi",
-			mockArgs:      []string{"kill", "nonexistent"},
+			mockArgs:      []string{"kill", "--force", "--yes", "nonexistent"},
 			mockStdout:    "",
 			mockStderr:    "agent not found",
 			mockExitErr:   true,
@@ -823,26 +856,37 @@ func TestUziCLI_SessionManagement(t *testing.T) {
 			description:   "Should successfully run prompt",
 		},
 		{
-			name:          "RunBroadcast - Success",
-			method:        "RunBroadcast",
+			name:          "RunCommand - Success",
+			method:        "RunCommand",
 			mockCmd:       "uzi",
-			mockArgs:      []string{"broadcast", "test message"},
-			mockStdout:    "Message sent",
+			mockArgs:      []string{"run", "echo test"},
+			mockStdout:    "Command executed",
 			mockStderr:    "",
 			mockExitErr:   false,
 			expectedError: false,
-			description:   "Should successfully broadcast message",
+			description:   "Should successfully run command",
 		},
 		{
-			name:          "RunCommand - Success",
-			method:        "RunCommand",
+			name:          "RunNudge - Success",
+			method:        "RunNudge",
 			mockCmd:       "uzi",
-			mockArgs:      []string{"run", "echo test"},
-			mockStdout:    "Command executed",
+			mockArgs:      []string{"nudge", "claude"},
+			mockStdout:    "Nudged claude (Enter)",
 			mockStderr:    "",
 			mockExitErr:   false,
 			expectedError: false,
-			description:   "Should successfully run command",
+			description:   "Should successfully nudge agent",
+		},
+		{
+			name:          "RunNudge - Agent busy",
+			method:        "RunNudge",
+			mockCmd:       "uzi",
+			mockArgs:      []string{"nudge", "claude"},
+			mockStdout:    "",
+			mockStderr:    "agent claude is still working",
+			mockExitErr:   true,
+			expectedError: true,
+			description:   "Should surface nudge refusal for busy agents",
 		},
 	}
 
@@ -859,10 +903,10 @@ func TestUziCLI_SessionManagement(t *testing.T) {
 				err = cli.KillSession(tt.sessionName)
 			case "RunPrompt":
 				err = cli.RunPrompt("claude:1", "test prompt")
-			case "RunBroadcast":
-				err = cli.RunBroadcast("test message")
 			case "RunCommand":
 				err = cli.RunCommand("echo test")
+			case "RunNudge":
+				err = cli.RunNudge("claude")
 			}
 
 			if tt.expectedError && err == nil {
@@ -880,6 +924,190 @@ func TestUziCLI_SessionManagement(t *testing.T) {
 	}
 }
 
+func TestUziCLI_RunBroadcast(t *testing.T) {
+	setupUziTest()
+	cli := NewUziCLI()
+	cli.stateManager = &mockStateManagerForTest{
+		activeSessions: []string{"agent-proj-abc123-john", "agent-proj-abc123-sarah"},
+	}
+
+	setSessionsExist("agent-proj-abc123-john", "agent-proj-abc123-sarah")
+	for _, session := range []string{"agent-proj-abc123-john", "agent-proj-abc123-sarah"} {
+		cmdmock.SetResponseWithArgs("tmux", []string{"send-keys", "-t", session + ":{start}", "test message", "Enter"}, "", "", false)
+		cmdmock.SetResponseWithArgs("tmux", []string{"send-keys", "-t", session + ":{start}", "Enter"}, "", "", false)
+	}
+
+	if err := cli.RunBroadcast("test message"); err != nil {
+		t.Fatalf("Expected no error, got: %v", err)
+	}
+
+	for _, session := range []string{"agent-proj-abc123-john", "agent-proj-abc123-sarah"} {
+		if !cmdmock.WasCommandCalled("tmux", "send-keys", "-t", session+":{start}", "test message", "Enter") {
+			t.Errorf("Expected message to be sent to %s", session)
+		}
+		if !cmdmock.WasCommandCalled("tmux", "send-keys", "-t", session+":{start}", "Enter") {
+			t.Errorf("Expected follow-up Enter to be sent to %s", session)
+		}
+	}
+	if cmdmock.WasCommandCalled("uzi", "broadcast", "test message") {
+		t.Error("RunBroadcast should not shell out to uzi broadcast")
+	}
+}
+
+// setSessionsExist makes tmux report the sessions as existing when a
+// broadcast checks them
+func setSessionsExist(sessions ...string) {
+	for _, session := range sessions {
+		cmdmock.SetResponseWithArgs("tmux", tmuxops.HasSessionArgs(session), "", "", false)
+	}
+}
+
+// removeStateRecorder records the sessions removed from state
+type removeStateRecorder struct {
+	mockStateManagerForTest
+	removed []string
+}
+
+func (m *removeStateRecorder) RemoveState(sessionName string) error {
+	m.removed = append(m.removed, sessionName)
+	return nil
+}
+
+func TestUziCLI_RunBroadcastSkipsGoneSessions(t *testing.T) {
+	setupUziTest()
+	cli := NewUziCLI()
+	sm := &removeStateRecorder{mockStateManagerForTest: mockStateManagerForTest{
+		activeSessions: []string{"agent-proj-abc123-john", "agent-proj-abc123-sarah"},
+	}}
+	cli.stateManager = sm
+	// john was killed after the sessions were listed
+	cmdmock.SetResponseWithArgs("tmux", tmuxops.HasSessionArgs("agent-proj-abc123-john"), "", "can't find session", true)
+	setSessionsExist("agent-proj-abc123-sarah")
+	cmdmock.SetResponseWithArgs("tmux", []string{"send-keys", "-t", "agent-proj-abc123-sarah:{start}", "hi", "Enter"}, "", "", false)
+	cmdmock.SetResponseWithArgs("tmux", []string{"send-keys", "-t", "agent-proj-abc123-sarah:{start}", "Enter"}, "", "", false)
+
+	err := cli.RunBroadcast("hi")
+	if gone := tmuxops.GoneSessions(err); len(gone) != 1 || gone[0] != "agent-proj-abc123-john" {
+		t.Fatalf("Expected john reported as gone, got: %v", err)
+	}
+	if cmdmock.WasCommandCalled("tmux", "send-keys", "-t", "agent-proj-abc123-john:{start}", "hi", "Enter") {
+		t.Error("Expected no message for the gone session")
+	}
+	if !cmdmock.WasCommandCalled("tmux", "send-keys", "-t", "agent-proj-abc123-sarah:{start}", "hi", "Enter") {
+		t.Error("Expected the message sent to the remaining session")
+	}
+	if len(sm.removed) != 1 || sm.removed[0] != "agent-proj-abc123-john" {
+		t.Errorf("Expected the gone session removed from state, got %v", sm.removed)
+	}
+}
+
+func TestUziCLI_RunBroadcastFillsInAgent(t *testing.T) {
+	setupUziTest()
+	cli := NewUziCLI()
+	cli.stateManager = &mockStateManagerForTest{
+		activeSessions: []string{"agent-proj-abc123-john", "agent-proj-abc123-sarah"},
+	}
+
+	setSessionsExist("agent-proj-abc123-john", "agent-proj-abc123-sarah")
+	for _, agent := range []string{"john", "sarah"} {
+		session := "agent-proj-abc123-" + agent
+		cmdmock.SetResponseWithArgs("tmux", []string{"send-keys", "-t", session + ":{start}", agent + ", status?", "Enter"}, "", "", false)
+		cmdmock.SetResponseWithArgs("tmux", []string{"send-keys", "-t", session + ":{start}", "Enter"}, "", "", false)
+	}
+
+	if err := cli.RunBroadcast("{agent}, status?"); err != nil {
+		t.Fatalf("Expected no error, got: %v", err)
+	}
+	for _, agent := range []string{"john", "sarah"} {
+		if !cmdmock.WasCommandCalled("tmux", "send-keys", "-t", "agent-proj-abc123-"+agent+":{start}", agent+", status?", "Enter") {
+			t.Errorf("Expected the message addressed to %s", agent)
+		}
+	}
+}
+
+func TestUziCLI_RunChannelBroadcast(t *testing.T) {
+	setupUziTest()
+	cli := NewUziCLI()
+	cli.stateManager = &mockStateManagerForTest{
+		activeSessions: []string{"agent-proj-abc123-john", "agent-proj-abc123-sarah"},
+		statePath: createTempStateFile(t, map[string]state.AgentState{
+			"agent-proj-abc123-john":  {BranchName: "john-branch"},
+			"agent-proj-abc123-sarah": {BranchName: "sarah-branch", Channels: []string{"frontend"}},
+		}),
+	}
+	setSessionsExist("agent-proj-abc123-sarah")
+	cmdmock.SetResponseWithArgs("tmux", []string{"send-keys", "-t", "agent-proj-abc123-sarah:{start}", "rebase sarah-branch", "Enter"}, "", "", false)
+	cmdmock.SetResponseWithArgs("tmux", []string{"send-keys", "-t", "agent-proj-abc123-sarah:{start}", "Enter"}, "", "", false)
+
+	if err := cli.RunChannelBroadcast("frontend", "rebase {branch}"); err != nil {
+		t.Fatalf("Expected no error, got: %v", err)
+	}
+	if !cmdmock.WasCommandCalled("tmux", "send-keys", "-t", "agent-proj-abc123-sarah:{start}", "rebase sarah-branch", "Enter") {
+		t.Error("Expected the message sent to the subscriber")
+	}
+	if cmdmock.WasCommandCalled("tmux", "send-keys", "-t", "agent-proj-abc123-john:{start}", "rebase john-branch", "Enter") {
+		t.Error("Expected no message for a session outside the channel")
+	}
+
+	err := cli.RunChannelBroadcast("backend", "hi")
+	if err == nil || !strings.Contains(err.Error(), `subscribed to channel "backend"`) {
+		t.Errorf("Expected no subscribers error, got: %v", err)
+	}
+}
+
+func TestUziCLI_RunBroadcastSkipsPaused(t *testing.T) {
+	setupUziTest()
+	cli := NewUziCLI()
+	cli.stateManager = &mockStateManagerForTest{
+		activeSessions: []string{"agent-proj-abc123-john", "agent-proj-abc123-sarah"},
+		statePath: createTempStateFile(t, map[string]state.AgentState{
+			"agent-proj-abc123-john":  {BranchName: "john-branch", Paused: true},
+			"agent-proj-abc123-sarah": {BranchName: "sarah-branch"},
+		}),
+	}
+	setSessionsExist("agent-proj-abc123-sarah")
+	cmdmock.SetResponseWithArgs("tmux", []string{"send-keys", "-t", "agent-proj-abc123-sarah:{start}", "hi", "Enter"}, "", "", false)
+	cmdmock.SetResponseWithArgs("tmux", []string{"send-keys", "-t", "agent-proj-abc123-sarah:{start}", "Enter"}, "", "", false)
+
+	if err := cli.RunBroadcast("hi"); err != nil {
+		t.Fatalf("Expected no error, got: %v", err)
+	}
+	if cmdmock.WasCommandCalled("tmux", "send-keys", "-t", "agent-proj-abc123-john:{start}", "hi", "Enter") {
+		t.Error("Expected no message for a paused session")
+	}
+	if !cmdmock.WasCommandCalled("tmux", "send-keys", "-t", "agent-proj-abc123-sarah:{start}", "hi", "Enter") {
+		t.Error("Expected the message sent to the running session")
+	}
+
+	cli.stateManager = &mockStateManagerForTest{
+		activeSessions: []string{"agent-proj-abc123-john"},
+		statePath: createTempStateFile(t, map[string]state.AgentState{
+			"agent-proj-abc123-john": {Paused: true},
+		}),
+	}
+	if err := cli.RunBroadcast("hi"); err == nil || !strings.Contains(err.Error(), "paused") {
+		t.Errorf("Expected an error when every session is paused, got: %v", err)
+	}
+}
+
+func TestUziCLI_RunBroadcastErrors(t *testing.T) {
+	setupUziTest()
+	cli := NewUziCLI()
+
+	cli.stateManager = &mockStateManagerForTest{}
+	err := cli.RunBroadcast("test message")
+	if err == nil || !strings.Contains(err.Error(), "no active agent sessions found") {
+		t.Errorf("Expected no sessions error, got: %v", err)
+	}
+
+	cli.stateManager = &mockStateManagerForTest{activeSessions: []string{"agent-proj-abc123-john"}}
+	cmdmock.SetResponseWithArgs("tmux", []string{"send-keys", "-t", "agent-proj-abc123-john:{start}", "test message", "Enter"}, "", "can't find session", true)
+	err = cli.RunBroadcast("test message")
+	if err == nil || !strings.Contains(err.Error(), "uzi_proxy: RunBroadcast") {
+		t.Errorf("Expected wrapped send error, got: %v", err)
+	}
+}
+
 func TestUziCLI_RefreshSessions(t *testing.T) {
 	setupUziTest()
 	cli := NewUziCLI()
@@ -975,7 +1203,7 @@ func TestUziCLI_GetSessionStatus(t *testing.T) {
 	cli := NewUziCLI()
 
 	// Mock tmux capture-pane for status detection
-	cmdmock.SetResponseWithArgs("tmux", []string{"capture-pane", "-t", "test-session:agent", "-p"},
+	cmdmock.SetResponseWithArgs("tmux", []string{"capture-pane", "-t", "test-session:{start}", "-p"},
 		"$ ready for input", "", false)
 
 	status, err := cli.GetSessionStatus("test-session")
@@ -1432,21 +1660,21 @@ func TestUziCLI_GetAgentStatus(t *testing.T) {
 	cli := NewUziCLI()
 
 	// Test running status
-	cmdmock.SetResponseWithArgs("tmux", []string{"capture-pane", "-t", "running-session:agent", "-p"}, "Thinking... esc to interrupt", "", false)
+	cmdmock.SetResponseWithArgs("tmux", []string{"capture-pane", "-t", "running-session:{start}", "-p"}, "Thinking... esc to interrupt", "", false)
 	status := cli.getAgentStatus("running-session")
 	if status != "running" {
 		t.Errorf("Expected 'running', got %v", status)
 	}
 
 	// Test ready status
-	cmdmock.SetResponseWithArgs("tmux", []string{"capture-pane", "-t", "ready-session:agent", "-p"}, "$ waiting for input", "", false)
+	cmdmock.SetResponseWithArgs("tmux", []string{"capture-pane", "-t", "ready-session:{start}", "-p"}, "$ waiting for input", "", false)
 	status = cli.getAgentStatus("ready-session")
 	if status != "ready" {
 		t.Errorf("Expected 'ready', got %v", status)
 	}
 
 	// Test unknown status on error
-	cmdmock.SetResponseWithArgs("tmux", []string{"capture-pane", "-t", "error-session:agent", "-p"}, "", "session not found", true)
+	cmdmock.SetResponseWithArgs("tmux", []string{"capture-pane", "-t", "error-session:{start}", "-p"}, "", "session not found", true)
 	status = cli.getAgentStatus("error-session")
 	if status != "unknown" {
 		t.Errorf("Expected 'unknown', got %v", status)
@@ -1457,13 +1685,13 @@ func TestUziCLI_GetAgentStatus(t *testing.T) {
 
 func mockTmuxAndGitCommands() {
 	// Mock common tmux commands for status detection
-	cmdmock.SetResponseWithArgs("tmux", []string{"capture-pane", "-t", "agent-proj1-abc123-claude:agent", "-p"},
+	cmdmock.SetResponseWithArgs("tmux", []string{"capture-pane", "-t", "agent-proj1-abc123-claude:{start}", "-p"},
 		"$ ready for input", "", false)
-	cmdmock.SetResponseWithArgs("tmux", []string{"capture-pane", "-t", "agent-proj2-def456-coder:agent", "-p"},
+	cmdmock.SetResponseWithArgs("tmux", []string{"capture-pane", "-t", "agent-proj2-def456-coder:{start}", "-p"},
 		"Thinking...\nesc to interrupt", "", false)
 
 	// Mock git diff commands
-	gitCmd := "git add -A . && git diff --cached --shortstat HEAD && git reset HEAD > /dev/null"
+	gitCmd := state.DiffScript
 	cmdmock.SetResponseWithArgs("sh", []string{"-c", gitCmd},
 		" 3 files changed, 15 insertions(+), 3 deletions(-)", "", false)
 }
@@ -1482,12 +1710,12 @@ func TestUziCLI_SpawnAgent_Success(t *testing.T) {
 	cmdmock.SetResponseWithArgs("sh", []string{"-c", "git worktree add -b claude-project-abc123-1640000000 /Users/testuser/.local/share/uzi/worktrees/claude-project-abc123-1640000000"}, "", "", false)
 
 	// Mock tmux session creation
-	cmdmock.SetResponseWithArgs("sh", []string{"-c", "tmux new-session -d -s agent-project-abc123-claude -c /Users/testuser/.local/share/uzi/worktrees/claude-project-abc123-1640000000"}, "", "", false)
+	cmdmock.SetResponseWithArgs("tmux", tmuxops.SourceStdinArgs(), "", "", false)
 	cmdmock.SetResponseWithArgs("sh", []string{"-c", "tmux rename-window -t agent-project-abc123-claude:0 agent"}, "", "", false)
 
 	// Mock agent command execution
-	cmdmock.SetResponseWithArgs("sh", []string{"-c", "tmux send-keys -t agent-project-abc123-claude:agent C-m"}, "", "", false)
-	cmdmock.SetResponseWithArgs("sh", []string{"-c", "tmux send-keys -t agent-project-abc123-claude:agent 'claude \"test prompt\"' C-m"}, "", "", false)
+	cmdmock.SetResponseWithArgs("sh", []string{"-c", "tmux send-keys -t 'agent-project-abc123-claude:{start}' C-m"}, "", "", false)
+	cmdmock.SetResponseWithArgs("sh", []string{"-c", "tmux send-keys -t 'agent-project-abc123-claude:{start}' 'claude \"test prompt\"' C-m"}, "", "", false)
 
 	// Create a mock state manager
 	mockStateManager := &mockStateManagerForTest{
@@ -1655,10 +1883,12 @@ func TestUziCLI_SpawnAgent_CreateTmuxSession(t *testing.T) {
 	cli := NewUziCLI()
 
 	// Mock tmux commands
-	cmdmock.SetResponseWithArgs("sh", []string{"-c", "tmux new-session -d -s test-session -c /tmp"}, "", "", false)
-	cmdmock.SetResponseWithArgs("sh", []string{"-c", "tmux rename-window -t test-session:0 agent"}, "", "", false)
+	cmdmock.SetResponseWithArgs("tmux", tmuxops.SourceStdinArgs(), "", "", false)
+	cmdmock.SetResponseWithArgs("tmux", []string{"rename-window", "-t", "test-session:{start}", "claude: fix it"}, "", "", false)
+	cmdmock.SetResponseWithArgs("tmux", []string{"set-option", "-w", "-t", "test-session:{start}", "@uzi-window", "agent"}, "", "", false)
+	cmdmock.SetResponseWithArgs("tmux", []string{"set-option", "-t", "test-session", "@uzi", "1", ";", "set-option", "-t", "test-session", "@uzi_agent", "claude"}, "", "", false)
 
-	err := cli.createTmuxSession("test-session", "/tmp")
+	err := cli.createTmuxSession("test-session", "claude: fix it", "claude", "/tmp")
 	if err != nil {
 		t.Errorf("Expected no error, got: %v", err)
 	}
@@ -1669,9 +1899,9 @@ func TestUziCLI_SpawnAgent_CreateTmuxSession_Error(t *testing.T) {
 	cli := NewUziCLI()
 
 	// Mock tmux session creation failure
-	cmdmock.SetResponseWithArgs("sh", []string{"-c", "tmux new-session -d -s test-session -c /tmp"}, "", "tmux: session exists", true)
+	cmdmock.SetResponseWithArgs("tmux", tmuxops.SourceStdinArgs(), "", "tmux: session exists", true)
 
-	err := cli.createTmuxSession("test-session", "/tmp")
+	err := cli.createTmuxSession("test-session", "agent", "claude", "/tmp")
 	if err == nil {
 		t.Error("Expected error but got none")
 	}
@@ -1724,10 +1954,10 @@ func TestUziCLI_SpawnAgent_ExecuteAgentCommand(t *testing.T) {
 	cli := NewUziCLI()
 
 	// Mock tmux commands for agent execution
-	cmdmock.SetResponseWithArgs("sh", []string{"-c", "tmux send-keys -t test-session:agent C-m"}, "", "", false)
-	cmdmock.SetResponseWithArgs("sh", []string{"-c", "tmux send-keys -t test-session:agent 'claude \"test prompt\"' C-m"}, "", "", false)
+	cmdmock.SetResponseWithArgs("sh", []string{"-c", "tmux send-keys -t 'test-session:{start}' C-m"}, "", "", false)
+	cmdmock.SetResponseWithArgs("sh", []string{"-c", "tmux send-keys -t 'test-session:{start}' 'claude \"test prompt\"' C-m"}, "", "", false)
 
-	err := cli.executeAgentCommand("test-session", "claude", "test prompt", "/tmp")
+	err := cli.executeAgentCommand("test-session", "claude", "", "test prompt", "/tmp")
 	if err != nil {
 		t.Errorf("Expected no error, got: %v", err)
 	}
@@ -1738,10 +1968,10 @@ func TestUziCLI_SpawnAgent_ExecuteAgentCommand_Gemini(t *testing.T) {
 	cli := NewUziCLI()
 
 	// Mock tmux commands for gemini agent execution (different format)
-	cmdmock.SetResponseWithArgs("sh", []string{"-c", "tmux send-keys -t test-session:agent C-m"}, "", "", false)
-	cmdmock.SetResponseWithArgs("sh", []string{"-c", "tmux send-keys -t test-session:agent 'gemini -p \"test prompt\"' C-m"}, "", "", false)
+	cmdmock.SetResponseWithArgs("sh", []string{"-c", "tmux send-keys -t 'test-session:{start}' C-m"}, "", "", false)
+	cmdmock.SetResponseWithArgs("sh", []string{"-c", "tmux send-keys -t 'test-session:{start}' 'gemini -p \"test prompt\"' C-m"}, "", "", false)
 
-	err := cli.executeAgentCommand("test-session", "gemini", "test prompt", "/tmp")
+	err := cli.executeAgentCommand("test-session", "gemini", "", "test prompt", "/tmp")
 	if err != nil {
 		t.Errorf("Expected no error, got: %v", err)
 	}
@@ -1820,3 +2050,262 @@ func TestUziCLI_SpawnAgent_LoadDefaultConfig(t *testing.T) {
 	_, err := cli.loadDefaultConfig()
 	_ = err // Acknowledge expected error in test environment
 }
+
+func TestUziCLI_LifecycleWebhooks(t *testing.T) {
+	setupUziTest()
+
+	var mu sync.Mutex
+	received := []events.Event{}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var event events.Event
+		json.NewDecoder(r.Body).Decode(&event)
+		mu.Lock()
+		received = append(received, event)
+		mu.Unlock()
+	}))
+	defer server.Close()
+
+	cli := NewUziCLIWithConfig(ProxyConfig{Timeout: time.Second, Retries: 0})
+	cli.dispatcher = events.NewDispatcher(&config.WebhooksConfig{
+		OnSpawn:      server.URL,
+		OnKill:       server.URL,
+		OnCheckpoint: server.URL,
+	})
+
+	cmdmock.SetResponseWithArgs("uzi", []string{"kill", "--force", "--yes", "sarah"}, "", "", false)
+	cmdmock.SetResponseWithArgs("uzi", []string{"checkpoint", "sarah", "wip"}, "", "", false)
+	cmdmock.SetResponseWithArgs("uzi", []string{"prompt", "--agents", "claude:1", "fix it"}, "", "", false)
+
+	if err := cli.KillSession("agent-proj-abc123-sarah"); err != nil {
+		t.Fatalf("KillSession failed: %v", err)
+	}
+	if err := cli.RunCheckpoint("sarah", "wip"); err != nil {
+		t.Fatalf("RunCheckpoint failed: %v", err)
+	}
+	if err := cli.RunPrompt("claude:1", "fix it"); err != nil {
+		t.Fatalf("RunPrompt failed: %v", err)
+	}
+	cli.dispatcher.Wait()
+
+	// The uzi commands run for these fire the webhooks themselves
+	mu.Lock()
+	defer mu.Unlock()
+	if len(received) != 0 {
+		t.Errorf("Expected the uzi commands to fire the webhooks, got %d from the TUI: %+v", len(received), received)
+	}
+}
+
+func TestUziCLI_RunPartialCheckpoint(t *testing.T) {
+	setupUziTest()
+	cli := NewUziCLI()
+
+	args := []string{"checkpoint", "--paths", "pkg/**", "--paths", "README.md", "sarah", "api only"}
+	cmdmock.SetResponseWithArgs("uzi", args, "Checkpointing 2 files", "", false)
+
+	if err := cli.RunPartialCheckpoint("sarah", "api only", []string{"pkg/**", "README.md"}); err != nil {
+		t.Fatalf("RunPartialCheckpoint failed: %v", err)
+	}
+	if !cmdmock.WasCommandCalled("uzi", args...) {
+		t.Errorf("Expected uzi %v to be called", args)
+	}
+}
+
+func TestUziCLI_RunCheckpointStreaming(t *testing.T) {
+	setupUziTest()
+	cli := NewUziCLI()
+
+	args := []string{"checkpoint", "sarah", "wip"}
+	cmdmock.SetResponseWithArgs("uzi", args, "Committing changes\nRebasing onto main\nMerged", "", false)
+
+	var lines []string
+	if err := cli.RunCheckpointStreaming("sarah", "wip", nil, func(line string) { lines = append(lines, line) }); err != nil {
+		t.Fatalf("RunCheckpointStreaming failed: %v", err)
+	}
+	if want := []string{"Committing changes", "Rebasing onto main", "Merged"}; !reflect.DeepEqual(lines, want) {
+		t.Errorf("Expected output lines %v, got %v", want, lines)
+	}
+}
+
+func TestLineWriter(t *testing.T) {
+	var lines []string
+	w := &lineWriter{onLine: func(line string) { lines = append(lines, line) }}
+	w.Write([]byte("first\r\nsec"))
+	w.Write([]byte("ond\nthird"))
+	if !reflect.DeepEqual(lines, []string{"first", "second"}) {
+		t.Errorf("Expected complete lines only, got %v", lines)
+	}
+	w.flush()
+	if len(lines) != 3 || lines[2] != "third" || w.String() != "first\r\nsecond\nthird" {
+		t.Errorf("Expected the last line on flush and all output kept, got %v %q", lines, w.String())
+	}
+}
+
+func TestUziCLI_GetChangedFiles(t *testing.T) {
+	setupUziTest()
+	cli := NewUziCLI()
+	cli.stateManager = &mockStateManagerForTest{
+		statePath: createTempStateFile(t, map[string]state.AgentState{
+			"agent-proj-abc123-sarah": {WorktreePath: t.TempDir(), BranchFrom: "main"},
+		}),
+	}
+
+	cmdmock.SetResponseWithArgs("git", []string{"merge-base", "main", "HEAD"}, "abc123\n", "", false)
+	cmdmock.SetResponseWithArgs("git", []string{"diff", "--name-only", "abc123"}, "pkg/api.go\nREADME.md\n", "", false)
+	cmdmock.SetResponseWithArgs("git", []string{"ls-files", "--others", "--exclude-standard"}, "notes.txt\nREADME.md\n", "", false)
+
+	files, err := cli.GetChangedFiles("agent-proj-abc123-sarah")
+	if err != nil {
+		t.Fatalf("GetChangedFiles failed: %v", err)
+	}
+
+	want := []string{"README.md", "notes.txt", "pkg/api.go"}
+	if strings.Join(files, ",") != strings.Join(want, ",") {
+		t.Errorf("GetChangedFiles() = %v, want %v", files, want)
+	}
+	if cmdmock.WasCommandCalled("git", "add", "-A", ".") {
+		t.Error("GetChangedFiles should not modify the agent's index")
+	}
+}
+
+func TestUziCLI_GetChangedFilesPinned(t *testing.T) {
+	setupUziTest()
+	cli := NewUziCLI()
+	cli.stateManager = &mockStateManagerForTest{
+		statePath: createTempStateFile(t, map[string]state.AgentState{
+			"agent-proj-abc123-sarah": {WorktreePath: t.TempDir(), BranchFrom: "main", BaseCommit: "3f2a9c1"},
+		}),
+	}
+
+	cmdmock.SetResponseWithArgs("git", []string{"diff", "--name-only", "3f2a9c1"}, "pkg/api.go\n", "", false)
+	cmdmock.SetResponseWithArgs("git", []string{"ls-files", "--others", "--exclude-standard"}, "", "", false)
+
+	files, err := cli.GetChangedFiles("agent-proj-abc123-sarah")
+	if err != nil {
+		t.Fatalf("GetChangedFiles failed: %v", err)
+	}
+	if strings.Join(files, ",") != "pkg/api.go" {
+		t.Errorf("Expected the files changed since the base commit, got %v", files)
+	}
+	if cmdmock.WasCommandCalled("git", "merge-base", "main", "HEAD") {
+		t.Error("Expected the base commit used without a merge base")
+	}
+}
+
+func TestUziCLI_GetChangedFilesUnknownSession(t *testing.T) {
+	setupUziTest()
+	cli := NewUziCLI()
+	cli.stateManager = &mockStateManagerForTest{
+		statePath: createTempStateFile(t, map[string]state.AgentState{}),
+	}
+
+	_, err := cli.GetChangedFiles("agent-proj-abc123-missing")
+	if err == nil || !strings.Contains(err.Error(), "uzi_proxy: GetChangedFiles") {
+		t.Errorf("Expected wrapped session error, got: %v", err)
+	}
+}
+
+func TestUziCLI_RespawnWithPromptErrors(t *testing.T) {
+	setupUziTest()
+	cli := NewUziCLIWithConfig(ProxyConfig{Timeout: time.Second, Retries: 0})
+
+	stateFile := createTempStateFile(t, map[string]state.AgentState{
+		"agent-proj-abc123-sarah": {Prompt: "fix login", Model: "claude"},
+		"agent-proj-abc123-gpu":   {Prompt: "train", Model: "claude", Host: "gpu1", SSH: "dev@gpu1"},
+	})
+	cli.stateManager = &mockStateManagerForTest{statePath: stateFile}
+
+	if _, err := cli.RespawnWithPrompt("agent-proj-abc123-gone", "try again"); err == nil || !strings.Contains(err.Error(), "session not found") {
+		t.Errorf("Expected an error for an unknown session, got: %v", err)
+	}
+	if _, err := cli.RespawnWithPrompt("agent-proj-abc123-gpu", "try again"); err == nil || !strings.Contains(err.Error(), "runs on host gpu1") {
+		t.Errorf("Expected remote sessions to be refused, got: %v", err)
+	}
+
+	// Nothing is spawned when the old session can't be killed
+	cmdmock.SetResponseWithArgs("uzi", []string{"kill", "--force", "--yes", "sarah"}, "", "locked by checkpoint", true)
+	if _, err := cli.RespawnWithPrompt("agent-proj-abc123-sarah", "try again"); err == nil || !strings.Contains(err.Error(), "KillSession") {
+		t.Errorf("Expected the kill error, got: %v", err)
+	}
+}
+
+func TestUziCLI_GetSessionsWithMetrics(t *testing.T) {
+	setupUziTest()
+	cli := NewUziCLI()
+	cmdmock.SetResponseWithArgs("uzi", []string{"ls", "--json"},
+		`[{"name":"agent-app-abc123-sarah","status":"stuck","activity_status":"stuck","commits":2,"last_commit_at":"2025-06-01T12:00:00Z"}]`, "", false)
+
+	// Without a monitor the metrics of uzi ls are passed through
+	sessions, err := cli.GetSessionsWithMetrics()
+	if err != nil {
+		t.Fatalf("GetSessionsWithMetrics() error = %v", err)
+	}
+	if len(sessions) != 1 || sessions[0].ActivityStatus != "stuck" || sessions[0].Commits != 2 || sessions[0].LastCommitAt != "2025-06-01T12:00:00Z" {
+		t.Errorf("Expected the metrics of uzi ls, got %+v", sessions)
+	}
+
+	// A monitor that tracks none of the sessions leaves them as they are
+	cli.SetActivityMonitor(activity.NewAgentActivityMonitor())
+	if sessions, err := cli.GetSessionsWithMetrics(); err != nil || sessions[0].Status != state.StatusStuck {
+		t.Errorf("Expected untracked sessions unchanged, got %+v, %v", sessions, err)
+	}
+}
+
+func TestMergeMetrics(t *testing.T) {
+	lastFileActivityAt := time.Date(2025, 6, 1, 12, 30, 0, 0, time.UTC)
+	sessions := []SessionInfo{
+		{Name: "agent-app-abc123-sarah", Status: state.StatusStuck, ActivityStatus: "stuck", LastCommitAt: "2025-06-01T10:00:00Z"},
+		{Name: "agent-app-abc123-john", Status: state.StatusReady},
+		{Name: "agent-app-abc123-emily", Status: state.StatusRunning, Insertions: 4},
+	}
+	merged := mergeMetrics(sessions, map[string]*activity.Metrics{
+		"agent-app-abc123-sarah": {Insertions: 3, Deletions: 1, Commits: 1, LastFileActivityAt: lastFileActivityAt, Status: activity.StatusIdle},
+		"agent-app-abc123-john":  {Status: activity.StatusStuck},
+	})
+
+	// The monitor saw sarah's files change, so uzi ls's stuck finding is undone
+	sarah := merged[0]
+	if sarah.Status != state.StatusReady || sarah.ActivityStatus != "idle" || sarah.Insertions != 3 || sarah.Deletions != 1 || sarah.Commits != 1 {
+		t.Errorf("Unexpected metrics for sarah: %+v", sarah)
+	}
+	if sarah.LastCommitAt != "2025-06-01T10:00:00Z" || sarah.LastFileActivityAt != "2025-06-01T12:30:00Z" {
+		t.Errorf("Expected the last-activity times of both sources, got %q and %q", sarah.LastCommitAt, sarah.LastFileActivityAt)
+	}
+	if merged[1].Status != state.StatusStuck {
+		t.Errorf("Expected john stuck, got %q", merged[1].Status)
+	}
+	if merged[2].Insertions != 4 || merged[2].ActivityStatus != "" {
+		t.Errorf("Expected untracked emily unchanged, got %+v", merged[2])
+	}
+	if sessions[0].Status != state.StatusStuck {
+		t.Error("Expected mergeMetrics not to modify its input")
+	}
+}
+
+func TestUziCLI_CheckCheckout(t *testing.T) {
+	setupUziTest()
+	cli := NewUziCLI()
+	block := string(config.DirtyCheckoutBlock)
+	cli.SetConfig(&config.Config{DirtyCheckoutPolicy: &block})
+	cmdmock.SetResponseWithArgs("git", []string{"-C", ".", "status", "--porcelain", "--untracked-files=no"}, " M main.go\n", "", false)
+	cmdmock.SetResponseWithArgs("git", []string{"-C", ".", "rev-parse", "--abbrev-ref", "--symbolic-full-name", "@{upstream}"}, "", "no upstream", true)
+
+	err := cli.checkCheckout()
+	if err == nil || !strings.Contains(err.Error(), "1 uncommitted change") {
+		t.Fatalf("Expected block to refuse the dirty checkout, got %v", err)
+	}
+	if _, err := cli.SpawnAgent("fix the tests", "claude"); err == nil || !strings.Contains(err.Error(), "git stash") {
+		t.Errorf("Expected SpawnAgent to refuse the dirty checkout, got %v", err)
+	}
+
+	warn := string(config.DirtyCheckoutWarn)
+	cli.SetConfig(&config.Config{DirtyCheckoutPolicy: &warn})
+	if err := cli.checkCheckout(); err != nil {
+		t.Errorf("Expected warn to spawn anyway, got %v", err)
+	}
+
+	cmdmock.SetResponseWithArgs("git", []string{"-C", ".", "status", "--porcelain", "--untracked-files=no"}, "", "", false)
+	cli.SetConfig(&config.Config{DirtyCheckoutPolicy: &block})
+	if err := cli.checkCheckout(); err != nil {
+		t.Errorf("Expected a clean checkout to pass, got %v", err)
+	}
+}
@@ -0,0 +1,81 @@
+// Copyright (c) Subtrace, Inc.
+// SPDX-License-Identifier: BSD-3-Clause
+
+package tui
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/nehpz/claudicus/pkg/tmuxops"
+	"github.com/nehpz/claudicus/pkg/version"
+)
+
+// ErrVersionMismatch reports that the uzi binary on PATH emits a session schema
+// this TUI cannot parse
+var ErrVersionMismatch = errors.New("uzi binary version mismatch")
+
+// reinstallHint tells the user how to bring the uzi binary in line with the TUI
+const reinstallHint = "reinstall with `go install github.com/nehpz/claudicus@latest`"
+
+// CheckVersion performs the `uzi version --json` handshake with the uzi binary.
+// On a schema mismatch it returns an error wrapping ErrVersionMismatch and switches
+// GetSessions to read state directly through GetSessionsLegacy.
+func (c *UziCLI) CheckVersion() error {
+	cmd := uziExecCommand("uzi", "version", "--json")
+	var stdout, stderr bytes.Buffer
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+
+	if err := cmd.Run(); err != nil {
+		// Binaries built before the handshake reject the version subcommand
+		if strings.Contains(stderr.String(), "unknown command") {
+			c.legacyMode.Store(true)
+			return fmt.Errorf("%w: uzi binary predates version handshake; %s", ErrVersionMismatch, reinstallHint)
+		}
+		return c.wrapError("CheckVersion", fmt.Errorf("%w - stderr: %s", err, stderr.String()))
+	}
+
+	var info version.Info
+	if err := json.Unmarshal(stdout.Bytes(), &info); err != nil {
+		c.legacyMode.Store(true)
+		return fmt.Errorf("%w: unreadable version output: %v; %s", ErrVersionMismatch, err, reinstallHint)
+	}
+
+	if info.SchemaVersion != version.SchemaVersion {
+		c.legacyMode.Store(true)
+		return fmt.Errorf("%w: uzi %s uses schema %d but the TUI expects schema %d; %s",
+			ErrVersionMismatch, info.Version, info.SchemaVersion, version.SchemaVersion, reinstallHint)
+	}
+
+	c.legacyMode.Store(false)
+	return nil
+}
+
+// CheckTmux probes the local tmux at startup so that a tmux too old for uzi
+// fails with a clear error instead of sessions silently missing from the list.
+// The error wraps tmuxops.ErrUnsupportedVersion. A tmux that can't be run is
+// left to surface when sessions are listed.
+func (c *UziCLI) CheckTmux() error {
+	if _, err := c.tmuxDiscovery.CheckVersion(); errors.Is(err, tmuxops.ErrUnsupportedVersion) {
+		return err
+	}
+	return nil
+}
+
+// LegacyMode reports whether sessions are read from state directly because of a version mismatch
+func (c *UziCLI) LegacyMode() bool {
+	return c.legacyMode.Load()
+}
+
+// fallbackOnMismatch re-runs the version handshake after a proxy error and
+// falls back to GetSessionsLegacy if the binary turns out to be incompatible
+func (c *UziCLI) fallbackOnMismatch(proxyErr error) ([]SessionInfo, error) {
+	if err := c.CheckVersion(); errors.Is(err, ErrVersionMismatch) {
+		return c.GetSessionsLegacy()
+	}
+	return nil, proxyErr
+}
@@ -0,0 +1,134 @@
+// Copyright (c) Subtrace, Inc.
+// SPDX-License-Identifier: BSD-3-Clause
+
+package tui
+
+import (
+	"errors"
+	"fmt"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/nehpz/claudicus/pkg/testutil/cmdmock"
+	"github.com/nehpz/claudicus/pkg/version"
+)
+
+func versionJSON(schema int) string {
+	return fmt.Sprintf(`{"version":"v9.9.9","schema_version":%d}`, schema)
+}
+
+func TestUziCLI_CheckVersion(t *testing.T) {
+	tests := []struct {
+		name         string
+		stdout       string
+		stderr       string
+		exitErr      bool
+		wantMismatch bool
+		wantErr      bool
+	}{
+		{
+			name:   "matching schema",
+			stdout: versionJSON(version.SchemaVersion),
+		},
+		{
+			name:         "different schema",
+			stdout:       versionJSON(version.SchemaVersion + 1),
+			wantMismatch: true,
+			wantErr:      true,
+		},
+		{
+			name:         "binary without version command",
+			stderr:       `uzi: error: unknown command "version"`,
+			exitErr:      true,
+			wantMismatch: true,
+			wantErr:      true,
+		},
+		{
+			name:         "unreadable output",
+			stdout:       "uzi dev",
+			wantMismatch: true,
+			wantErr:      true,
+		},
+		{
+			name:    "binary not runnable",
+			stderr:  "exec: uzi: not found",
+			exitErr: true,
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			setupUziTest()
+			cli := NewUziCLI()
+			cmdmock.SetResponseWithArgs("uzi", []string{"version", "--json"}, tt.stdout, tt.stderr, tt.exitErr)
+
+			err := cli.CheckVersion()
+
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("CheckVersion() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if got := errors.Is(err, ErrVersionMismatch); got != tt.wantMismatch {
+				t.Errorf("errors.Is(err, ErrVersionMismatch) = %v, want %v (err: %v)", got, tt.wantMismatch, err)
+			}
+			if cli.LegacyMode() != tt.wantMismatch {
+				t.Errorf("LegacyMode() = %v, want %v", cli.LegacyMode(), tt.wantMismatch)
+			}
+			if tt.wantMismatch && !strings.Contains(err.Error(), "go install") {
+				t.Errorf("Expected reinstall hint in error, got: %v", err)
+			}
+		})
+	}
+}
+
+func TestUziCLI_GetSessionsFallsBackOnMismatch(t *testing.T) {
+	setupUziTest()
+	cli := NewUziCLI()
+	cli.stateManager = &mockStateManagerForTest{
+		activeSessions: []string{},
+		statePath:      filepath.Join(t.TempDir(), "state.json"),
+	}
+
+	// A newer binary emits JSON this TUI can't parse and advertises a new schema
+	cmdmock.SetResponseWithArgs("uzi", []string{"ls", "--json"}, `{"sessions":[]}`, "", false)
+	cmdmock.SetResponseWithArgs("uzi", []string{"version", "--json"}, versionJSON(version.SchemaVersion+1), "", false)
+
+	sessions, err := cli.GetSessions()
+	if err != nil {
+		t.Fatalf("Expected legacy fallback to succeed, got: %v", err)
+	}
+	if len(sessions) != 0 {
+		t.Errorf("Expected no sessions from empty state, got %d", len(sessions))
+	}
+	if !cli.LegacyMode() {
+		t.Error("Expected legacy mode after schema mismatch")
+	}
+
+	// Subsequent calls skip the proxy entirely
+	callsBefore := len(cmdmock.GetCalls())
+	if _, err := cli.GetSessions(); err != nil {
+		t.Fatalf("Expected legacy GetSessions to succeed, got: %v", err)
+	}
+	for _, call := range cmdmock.GetCalls()[callsBefore:] {
+		if call.Name == "uzi" {
+			t.Errorf("Expected no uzi calls in legacy mode, got %s %v", call.Name, call.Args)
+		}
+	}
+}
+
+func TestUziCLI_GetSessionsKeepsErrorWhenVersionsMatch(t *testing.T) {
+	setupUziTest()
+	cli := NewUziCLI()
+
+	cmdmock.SetResponseWithArgs("uzi", []string{"ls", "--json"}, "{invalid json", "", false)
+	cmdmock.SetResponseWithArgs("uzi", []string{"version", "--json"}, versionJSON(version.SchemaVersion), "", false)
+
+	_, err := cli.GetSessions()
+	if err == nil || !strings.Contains(err.Error(), "failed to parse JSON") {
+		t.Errorf("Expected original parse error, got: %v", err)
+	}
+	if cli.LegacyMode() {
+		t.Error("Expected proxy mode to be kept when versions match")
+	}
+}
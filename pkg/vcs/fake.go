@@ -0,0 +1,309 @@
+package vcs
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"sync"
+)
+
+// Fake is an in-memory VCS for tests. It holds a single repository whose
+// branches are lists of commits, oldest first. Every checkout, the main one
+// in the repository directory and each worktree, has a branch checked out and
+// uncommitted changes, set with SetChanges.
+type Fake struct {
+	mu        sync.Mutex
+	branches  map[string][]string
+	checkouts map[string]*fakeCheckout // by directory
+	messages  map[string]string        // by commit id
+	commits   int
+}
+
+type fakeCheckout struct {
+	branch  string
+	changes Stat
+}
+
+// NewFake returns a repository in repoDir with an initial commit on branch
+func NewFake(repoDir, branch string) *Fake {
+	f := &Fake{
+		branches:  make(map[string][]string),
+		checkouts: map[string]*fakeCheckout{repoDir: {branch: branch}},
+		messages:  make(map[string]string),
+	}
+	f.branches[branch] = []string{f.newCommit("initial")}
+	return f
+}
+
+// newCommit records a commit and returns its id
+func (f *Fake) newCommit(message string) string {
+	f.commits++
+	id := fmt.Sprintf("c%d", f.commits)
+	f.messages[id] = message
+	return id
+}
+
+func (f *Fake) checkout(dir string) (*fakeCheckout, error) {
+	checkout, ok := f.checkouts[dir]
+	if !ok {
+		return nil, fmt.Errorf("not a checkout: %s", dir)
+	}
+	return checkout, nil
+}
+
+// history returns the commits of a branch, or the commits up to a commit id
+func (f *Fake) history(rev string) ([]string, error) {
+	if commits, ok := f.branches[rev]; ok {
+		return commits, nil
+	}
+	for _, commits := range f.branches {
+		for i, id := range commits {
+			if id == rev {
+				return commits[:i+1], nil
+			}
+		}
+	}
+	return nil, fmt.Errorf("%w: %s", ErrUnknownRevision, rev)
+}
+
+// SetChanges sets the uncommitted changes of the checkout in dir, as an
+// agent editing its worktree would
+func (f *Fake) SetChanges(dir string, changes Stat) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	checkout, err := f.checkout(dir)
+	if err != nil {
+		return err
+	}
+	checkout.changes = changes
+	return nil
+}
+
+// Log returns the commit messages of a branch, oldest first
+func (f *Fake) Log(branch string) []string {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	var messages []string
+	for _, id := range f.branches[branch] {
+		messages = append(messages, f.messages[id])
+	}
+	return messages
+}
+
+// Branches returns the names of the branches in order
+func (f *Fake) Branches() []string {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	var names []string
+	for name := range f.branches {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// Worktrees returns the directories of the checkouts besides the main one, in order
+func (f *Fake) Worktrees(repoDir string) []string {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	var dirs []string
+	for dir := range f.checkouts {
+		if dir != repoDir {
+			dirs = append(dirs, dir)
+		}
+	}
+	sort.Strings(dirs)
+	return dirs
+}
+
+// CreateWorktree implements VCS
+func (f *Fake) CreateWorktree(ctx context.Context, repoDir, path string, opts WorktreeOptions) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	repo, err := f.checkout(repoDir)
+	if err != nil {
+		return err
+	}
+	if _, exists := f.checkouts[path]; exists {
+		return fmt.Errorf("worktree already exists: %s", path)
+	}
+
+	if opts.Branch == "" {
+		if _, ok := f.branches[opts.Base]; !ok {
+			return fmt.Errorf("no such branch: %s", opts.Base)
+		}
+		for _, checkout := range f.checkouts {
+			if checkout.branch == opts.Base {
+				return fmt.Errorf("branch %s is already checked out", opts.Base)
+			}
+		}
+		f.checkouts[path] = &fakeCheckout{branch: opts.Base}
+		return nil
+	}
+
+	if _, exists := f.branches[opts.Branch]; exists {
+		return fmt.Errorf("branch already exists: %s", opts.Branch)
+	}
+	base := opts.Base
+	if base == "" {
+		base = repo.branch
+	}
+	commits, err := f.history(base)
+	if err != nil {
+		return err
+	}
+	f.branches[opts.Branch] = append([]string(nil), commits...)
+	f.checkouts[path] = &fakeCheckout{branch: opts.Branch}
+	return nil
+}
+
+// RemoveWorktree implements VCS
+func (f *Fake) RemoveWorktree(ctx context.Context, repoDir, path string) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if path == repoDir {
+		return fmt.Errorf("cannot remove the main checkout")
+	}
+	if _, err := f.checkout(path); err != nil {
+		return err
+	}
+	delete(f.checkouts, path)
+	return nil
+}
+
+// DeleteBranch implements VCS
+func (f *Fake) DeleteBranch(ctx context.Context, repoDir, branch string) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if _, ok := f.branches[branch]; !ok {
+		return fmt.Errorf("no such branch: %s", branch)
+	}
+	for dir, checkout := range f.checkouts {
+		if checkout.branch == branch {
+			return fmt.Errorf("branch %s is checked out in %s", branch, dir)
+		}
+	}
+	delete(f.branches, branch)
+	return nil
+}
+
+// RevParse implements VCS; HEAD names the tip of the branch checked out in dir
+func (f *Fake) RevParse(ctx context.Context, dir, rev string) (string, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if rev == "HEAD" {
+		checkout, err := f.checkout(dir)
+		if err != nil {
+			return "", err
+		}
+		rev = checkout.branch
+	}
+	commits, err := f.history(rev)
+	if err != nil {
+		return "", err
+	}
+	return commits[len(commits)-1], nil
+}
+
+// CurrentBranch implements VCS
+func (f *Fake) CurrentBranch(ctx context.Context, dir string) (string, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	checkout, err := f.checkout(dir)
+	if err != nil {
+		return "", err
+	}
+	return checkout.branch, nil
+}
+
+// MergeBase implements VCS
+func (f *Fake) MergeBase(ctx context.Context, dir, a, b string) (string, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	aCommits, err := f.history(a)
+	if err != nil {
+		return "", err
+	}
+	bCommits, err := f.history(b)
+	if err != nil {
+		return "", err
+	}
+	shared := commonPrefix(aCommits, bCommits)
+	if shared == 0 {
+		return "", fmt.Errorf("%s and %s have no common commit", a, b)
+	}
+	return aCommits[shared-1], nil
+}
+
+// CountCommits implements VCS
+func (f *Fake) CountCommits(ctx context.Context, dir, base, branch string) (int, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	baseCommits, err := f.history(base)
+	if err != nil {
+		return 0, err
+	}
+	commits, err := f.history(branch)
+	if err != nil {
+		return 0, err
+	}
+	return len(commits) - commonPrefix(baseCommits, commits), nil
+}
+
+// DiffStat implements VCS
+func (f *Fake) DiffStat(ctx context.Context, dir string) (Stat, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	checkout, err := f.checkout(dir)
+	if err != nil {
+		return Stat{}, err
+	}
+	return checkout.changes, nil
+}
+
+// Commit implements VCS. Every change is committed, whatever the Paths.
+func (f *Fake) Commit(ctx context.Context, dir string, opts CommitOptions) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	checkout, err := f.checkout(dir)
+	if err != nil {
+		return err
+	}
+	if checkout.changes == (Stat{}) {
+		return ErrNothingToCommit
+	}
+	f.branches[checkout.branch] = append(f.branches[checkout.branch], f.newCommit(opts.Message))
+	checkout.changes = Stat{}
+	return nil
+}
+
+// Merge implements VCS like Git does, by replaying the commits of the branch
+// checked out in dir on top of branch
+func (f *Fake) Merge(ctx context.Context, dir, branch string) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	checkout, err := f.checkout(dir)
+	if err != nil {
+		return err
+	}
+	onto, err := f.history(branch)
+	if err != nil {
+		return err
+	}
+	current := f.branches[checkout.branch]
+	merged := append([]string(nil), onto...)
+	for _, id := range current[commonPrefix(current, onto):] {
+		merged = append(merged, f.newCommit(f.messages[id]))
+	}
+	f.branches[checkout.branch] = merged
+	return nil
+}
+
+// commonPrefix returns how many commits two histories start with in common
+func commonPrefix(a, b []string) int {
+	n := 0
+	for n < len(a) && n < len(b) && a[n] == b[n] {
+		n++
+	}
+	return n
+}
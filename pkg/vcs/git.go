@@ -0,0 +1,186 @@
+package vcs
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os/exec"
+	"strconv"
+	"strings"
+
+	"github.com/nehpz/claudicus/pkg/state"
+)
+
+// Git implements VCS by running git
+type Git struct {
+	// Command builds the commands to run; nil runs them on this machine.
+	// hosts.Target.Command runs them on the target's host.
+	Command func(ctx context.Context, dir, name string, args ...string) *exec.Cmd
+	// Stdout and Stderr receive what commits and merges print; nil discards it
+	Stdout io.Writer
+	Stderr io.Writer
+}
+
+func (g Git) command(ctx context.Context, dir string, args ...string) *exec.Cmd {
+	if g.Command != nil {
+		return g.Command(ctx, dir, "git", args...)
+	}
+	cmd := exec.CommandContext(ctx, "git", args...)
+	cmd.Dir = dir
+	return cmd
+}
+
+// output runs git and returns what it printed, trimmed; errors carry what
+// git printed to stderr
+func (g Git) output(ctx context.Context, dir string, args ...string) (string, error) {
+	output, err := g.command(ctx, dir, args...).Output()
+	if err != nil {
+		var exitErr *exec.ExitError
+		if errors.As(err, &exitErr) && len(exitErr.Stderr) > 0 {
+			return "", fmt.Errorf("git %s: %w: %s", args[0], err, strings.TrimSpace(string(exitErr.Stderr)))
+		}
+		return "", fmt.Errorf("git %s: %w", args[0], err)
+	}
+	return strings.TrimSpace(string(output)), nil
+}
+
+// stream runs git with its output going to Stdout and Stderr
+func (g Git) stream(ctx context.Context, dir string, args ...string) error {
+	cmd := g.command(ctx, dir, args...)
+	cmd.Stdout = g.Stdout
+	cmd.Stderr = g.Stderr
+	return cmd.Run()
+}
+
+// CreateWorktree implements VCS
+func (g Git) CreateWorktree(ctx context.Context, repoDir, path string, opts WorktreeOptions) error {
+	_, err := g.output(ctx, repoDir, WorktreeAddArgs(path, opts)...)
+	return err
+}
+
+// WorktreeAddArgs builds the git argument vector that creates a worktree
+func WorktreeAddArgs(path string, opts WorktreeOptions) []string {
+	args := []string{"worktree", "add"}
+	if opts.Branch != "" {
+		args = append(args, "-b", opts.Branch)
+	}
+	args = append(args, path)
+	if opts.Base != "" {
+		args = append(args, opts.Base)
+	}
+	return args
+}
+
+// RemoveWorktree implements VCS
+func (g Git) RemoveWorktree(ctx context.Context, repoDir, path string) error {
+	_, err := g.output(ctx, repoDir, "worktree", "remove", "--force", path)
+	return err
+}
+
+// DeleteBranch implements VCS
+func (g Git) DeleteBranch(ctx context.Context, repoDir, branch string) error {
+	_, err := g.output(ctx, repoDir, "branch", "-D", branch)
+	return err
+}
+
+// RevParse implements VCS
+func (g Git) RevParse(ctx context.Context, dir, rev string) (string, error) {
+	id, err := g.output(ctx, dir, "rev-parse", "--verify", "--quiet", rev+"^{commit}")
+	if err != nil {
+		var exitErr *exec.ExitError
+		if errors.As(err, &exitErr) && exitErr.ExitCode() == 1 {
+			return "", fmt.Errorf("%w: %s", ErrUnknownRevision, rev)
+		}
+		return "", err
+	}
+	return id, nil
+}
+
+// CurrentBranch implements VCS
+func (g Git) CurrentBranch(ctx context.Context, dir string) (string, error) {
+	return g.output(ctx, dir, "branch", "--show-current")
+}
+
+// MergeBase implements VCS
+func (g Git) MergeBase(ctx context.Context, dir, a, b string) (string, error) {
+	return g.output(ctx, dir, "merge-base", a, b)
+}
+
+// CountCommits implements VCS
+func (g Git) CountCommits(ctx context.Context, dir, base, branch string) (int, error) {
+	output, err := g.output(ctx, dir, "rev-list", "--count", base+".."+branch)
+	if err != nil {
+		return 0, err
+	}
+	return strconv.Atoi(output)
+}
+
+// DiffStat implements VCS. Untracked files count as changes, and the paths of
+// state.IgnoreFile are left out.
+func (g Git) DiffStat(ctx context.Context, dir string) (Stat, error) {
+	var cmd *exec.Cmd
+	if g.Command != nil {
+		cmd = g.Command(ctx, dir, "sh", "-c", state.DiffScript)
+	} else {
+		cmd = exec.CommandContext(ctx, "sh", "-c", state.DiffScript)
+		cmd.Dir = dir
+	}
+	output, err := cmd.Output()
+	if err != nil {
+		return Stat{}, fmt.Errorf("git diff: %w", err)
+	}
+	insertions, deletions, files := state.ParseDiffStatFiles(string(output))
+	return Stat{Files: files, Insertions: insertions, Deletions: deletions}, nil
+}
+
+// Commit implements VCS. Without Paths every change is staged first, so new
+// files are committed too, and -a also commits changes to tracked files
+// under Exclude.
+func (g Git) Commit(ctx context.Context, dir string, opts CommitOptions) error {
+	if len(opts.Paths) == 0 {
+		addArgs := []string{"add", "--", "."}
+		for _, path := range opts.Exclude {
+			addArgs = append(addArgs, ":(exclude)"+path)
+		}
+		if _, err := g.output(ctx, dir, addArgs...); err != nil {
+			return err
+		}
+		if g.command(ctx, dir, "diff", "--cached", "--quiet").Run() == nil {
+			return ErrNothingToCommit
+		}
+	}
+	if err := g.stream(ctx, dir, CommitArgs(opts)...); err != nil {
+		return fmt.Errorf("git commit: %w", err)
+	}
+	return nil
+}
+
+// CommitArgs builds the git argument vector that commits: the author and
+// signing options, then commit with the message and paths
+func CommitArgs(opts CommitOptions) []string {
+	var args []string
+	if opts.AuthorName != "" && opts.AuthorEmail != "" {
+		args = append(args, "-c", "user.name="+opts.AuthorName, "-c", "user.email="+opts.AuthorEmail)
+	}
+	if opts.Sign {
+		args = append(args, "-c", "commit.gpgsign=true")
+	}
+	args = append(args, "commit")
+	if opts.Signoff {
+		args = append(args, "--signoff")
+	}
+	if len(opts.Paths) == 0 {
+		return append(args, "-am", opts.Message)
+	}
+	return append(append(args, "-m", opts.Message, "--"), opts.Paths...)
+}
+
+// Merge implements VCS by rebasing the checked out branch onto branch, which
+// keeps the history linear
+func (g Git) Merge(ctx context.Context, dir, branch string) error {
+	if err := g.stream(ctx, dir, "--no-pager", "rebase", branch); err != nil {
+		return fmt.Errorf("git rebase: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,79 @@
+// Package vcs is the version control uzi drives: creating and removing the
+// worktrees agents work in, reading their changes, and committing and merging
+// their work. Git is the implementation uzi runs with; Fake keeps a
+// repository in memory so tests don't need real ones.
+package vcs
+
+import (
+	"context"
+	"errors"
+)
+
+var (
+	// ErrUnknownRevision is returned for revisions that don't name a commit
+	ErrUnknownRevision = errors.New("unknown revision")
+	// ErrNothingToCommit is returned by Commit for a checkout without changes
+	ErrNothingToCommit = errors.New("nothing to commit")
+)
+
+// VCS creates agent worktrees and moves their work between branches. Every
+// method takes the directory of the checkout to run in: the repository for
+// worktree and branch changes, a worktree for its own changes.
+type VCS interface {
+	// CreateWorktree checks out a new worktree at path
+	CreateWorktree(ctx context.Context, repoDir, path string, opts WorktreeOptions) error
+	// RemoveWorktree removes the worktree at path with its uncommitted changes;
+	// its branch is kept
+	RemoveWorktree(ctx context.Context, repoDir, path string) error
+	// DeleteBranch deletes a branch, merged or not
+	DeleteBranch(ctx context.Context, repoDir, branch string) error
+
+	// RevParse resolves a branch name or other revision to a commit id, or
+	// returns ErrUnknownRevision
+	RevParse(ctx context.Context, dir, rev string) (string, error)
+	// CurrentBranch returns the branch checked out in dir
+	CurrentBranch(ctx context.Context, dir string) (string, error)
+	// MergeBase returns the commit two branches diverged at
+	MergeBase(ctx context.Context, dir, a, b string) (string, error)
+	// CountCommits returns how many commits branch has on top of base
+	CountCommits(ctx context.Context, dir, base, branch string) (int, error)
+
+	// DiffStat summarizes the uncommitted changes of the checkout in dir
+	DiffStat(ctx context.Context, dir string) (Stat, error)
+	// Commit records the uncommitted changes of the checkout in dir on its branch
+	Commit(ctx context.Context, dir string, opts CommitOptions) error
+	// Merge brings the commits of branch into the branch checked out in dir
+	Merge(ctx context.Context, dir, branch string) error
+}
+
+// WorktreeOptions chooses what a new worktree checks out
+type WorktreeOptions struct {
+	// Branch is created for the worktree, starting at Base. Without one, Base
+	// is an existing branch and is checked out as it is.
+	Branch string
+	// Base is the branch or commit to start from; empty starts from the
+	// repository's current commit
+	Base string
+}
+
+// Stat counts the changed files and lines of a checkout
+type Stat struct {
+	Files      int
+	Insertions int
+	Deletions  int
+}
+
+// CommitOptions describe a commit
+type CommitOptions struct {
+	Message string
+	// AuthorName and AuthorEmail attribute the commit; empty uses the
+	// identity the repository is configured with
+	AuthorName  string
+	AuthorEmail string
+	Sign        bool // sign the commit
+	Signoff     bool // add a Signed-off-by trailer
+	// Paths commits only these files, leaving other changes uncommitted. By
+	// default every change is committed except those under Exclude.
+	Paths   []string
+	Exclude []string
+}
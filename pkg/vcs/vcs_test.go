@@ -0,0 +1,168 @@
+package vcs
+
+import (
+	"context"
+	"errors"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+// testVCS runs the same checkpoint-like workflow against a VCS: spawn a
+// worktree, change and commit in it, merge it into the main checkout, and
+// clean up. edit makes an uncommitted change of one file and two lines.
+func testVCS(t *testing.T, repo VCS, repoDir, worktreeDir string, edit func(dir string)) {
+	t.Helper()
+	ctx := context.Background()
+
+	main, err := repo.CurrentBranch(ctx, repoDir)
+	if err != nil {
+		t.Fatalf("CurrentBranch() error = %v", err)
+	}
+	start, err := repo.RevParse(ctx, repoDir, "HEAD")
+	if err != nil {
+		t.Fatalf("RevParse(HEAD) error = %v", err)
+	}
+	if _, err := repo.RevParse(ctx, repoDir, "no-such-branch"); !errors.Is(err, ErrUnknownRevision) {
+		t.Errorf("Expected ErrUnknownRevision for a missing branch, got %v", err)
+	}
+
+	if err := repo.CreateWorktree(ctx, repoDir, worktreeDir, WorktreeOptions{Branch: "agent"}); err != nil {
+		t.Fatalf("CreateWorktree() error = %v", err)
+	}
+	if branch, _ := repo.CurrentBranch(ctx, worktreeDir); branch != "agent" {
+		t.Errorf("Expected the worktree on branch agent, got %q", branch)
+	}
+	if err := repo.Commit(ctx, worktreeDir, CommitOptions{Message: "empty"}); !errors.Is(err, ErrNothingToCommit) {
+		t.Errorf("Expected ErrNothingToCommit without changes, got %v", err)
+	}
+
+	edit(worktreeDir)
+	stat, err := repo.DiffStat(ctx, worktreeDir)
+	if err != nil {
+		t.Fatalf("DiffStat() error = %v", err)
+	}
+	if stat != (Stat{Files: 1, Insertions: 2}) {
+		t.Errorf("DiffStat() = %+v, want 1 file and 2 insertions", stat)
+	}
+	if err := repo.Commit(ctx, worktreeDir, CommitOptions{Message: "agent work"}); err != nil {
+		t.Fatalf("Commit() error = %v", err)
+	}
+	if stat, _ := repo.DiffStat(ctx, worktreeDir); stat != (Stat{}) {
+		t.Errorf("Expected no changes after committing, got %+v", stat)
+	}
+
+	base, err := repo.MergeBase(ctx, repoDir, main, "agent")
+	if err != nil || base != start {
+		t.Errorf("MergeBase() = %q, %v, want %q", base, err, start)
+	}
+	if count, err := repo.CountCommits(ctx, repoDir, base, "agent"); err != nil || count != 1 {
+		t.Errorf("CountCommits() = %d, %v, want 1", count, err)
+	}
+
+	if err := repo.Merge(ctx, repoDir, "agent"); err != nil {
+		t.Fatalf("Merge() error = %v", err)
+	}
+	head, _ := repo.RevParse(ctx, repoDir, "HEAD")
+	agentTip, _ := repo.RevParse(ctx, repoDir, "agent")
+	if head != agentTip {
+		t.Errorf("Expected %s at the agent's commit after merging, got %q, want %q", main, head, agentTip)
+	}
+
+	if err := repo.DeleteBranch(ctx, repoDir, "agent"); err == nil {
+		t.Error("Expected deleting a checked out branch to fail")
+	}
+	if err := repo.RemoveWorktree(ctx, repoDir, worktreeDir); err != nil {
+		t.Fatalf("RemoveWorktree() error = %v", err)
+	}
+	if err := repo.DeleteBranch(ctx, repoDir, "agent"); err != nil {
+		t.Errorf("DeleteBranch() error = %v", err)
+	}
+	if _, err := repo.RevParse(ctx, repoDir, "agent"); !errors.Is(err, ErrUnknownRevision) {
+		t.Errorf("Expected the branch gone, got %v", err)
+	}
+}
+
+func TestGit(t *testing.T) {
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git not available")
+	}
+	t.Setenv("GIT_AUTHOR_NAME", "uzi")
+	t.Setenv("GIT_AUTHOR_EMAIL", "uzi@example.com")
+	t.Setenv("GIT_COMMITTER_NAME", "uzi")
+	t.Setenv("GIT_COMMITTER_EMAIL", "uzi@example.com")
+
+	dir := t.TempDir()
+	repoDir := filepath.Join(dir, "repo")
+	os.Mkdir(repoDir, 0755)
+	for _, args := range [][]string{
+		{"init", "-q", "-b", "main"},
+		{"commit", "-q", "--allow-empty", "-m", "initial"},
+	} {
+		cmd := exec.Command("git", args...)
+		cmd.Dir = repoDir
+		if output, err := cmd.CombinedOutput(); err != nil {
+			t.Fatalf("git %v: %v: %s", args, err, output)
+		}
+	}
+
+	testVCS(t, Git{}, repoDir, filepath.Join(dir, "agent"), func(dir string) {
+		if err := os.WriteFile(filepath.Join(dir, "new.txt"), []byte("1\n2\n"), 0644); err != nil {
+			t.Fatal(err)
+		}
+	})
+}
+
+func TestFake(t *testing.T) {
+	fake := NewFake("/repo", "main")
+	testVCS(t, fake, "/repo", "/wt/agent", func(dir string) {
+		fake.SetChanges(dir, Stat{Files: 1, Insertions: 2})
+	})
+
+	if got, want := fake.Log("main"), []string{"initial", "agent work"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("Log(main) = %v, want %v", got, want)
+	}
+	if got := fake.Branches(); !reflect.DeepEqual(got, []string{"main"}) {
+		t.Errorf("Branches() = %v, want [main]", got)
+	}
+	if got := fake.Worktrees("/repo"); len(got) != 0 {
+		t.Errorf("Expected no worktrees left, got %v", got)
+	}
+}
+
+func TestWorktreeAddArgs(t *testing.T) {
+	tests := []struct {
+		opts WorktreeOptions
+		want []string
+	}{
+		{WorktreeOptions{Branch: "agent"}, []string{"worktree", "add", "-b", "agent", "/wt"}},
+		{WorktreeOptions{Branch: "agent", Base: "feature-x"}, []string{"worktree", "add", "-b", "agent", "/wt", "feature-x"}},
+		{WorktreeOptions{Base: "feature-x"}, []string{"worktree", "add", "/wt", "feature-x"}},
+	}
+	for _, tt := range tests {
+		if got := WorktreeAddArgs("/wt", tt.opts); !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("WorktreeAddArgs(%+v) = %v, want %v", tt.opts, got, tt.want)
+		}
+	}
+}
+
+func TestCommitArgs(t *testing.T) {
+	if got := CommitArgs(CommitOptions{Message: "msg"}); !reflect.DeepEqual(got, []string{"commit", "-am", "msg"}) {
+		t.Errorf("CommitArgs() = %v", got)
+	}
+
+	got := CommitArgs(CommitOptions{
+		Message:     "msg",
+		AuthorName:  "Agent Claude",
+		AuthorEmail: "agents@team",
+		Sign:        true,
+		Signoff:     true,
+		Paths:       []string{"a.go"},
+	})
+	want := []string{"-c", "user.name=Agent Claude", "-c", "user.email=agents@team", "-c", "commit.gpgsign=true", "commit", "--signoff", "-m", "msg", "--", "a.go"}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("CommitArgs() = %v, want %v", got, want)
+	}
+}
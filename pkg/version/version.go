@@ -0,0 +1,23 @@
+package version
+
+// Version is the uzi release version. Release builds override it with
+// -ldflags "-X github.com/nehpz/claudicus/pkg/version.Version=v1.2.3".
+var Version = "dev"
+
+// SchemaVersion identifies the JSON format emitted by `uzi ls --json`.
+// Bump it whenever that output changes in a way older TUIs cannot parse.
+const SchemaVersion = 1
+
+// Info is the payload printed by `uzi version --json`
+type Info struct {
+	Version       string `json:"version"`
+	SchemaVersion int    `json:"schema_version"`
+}
+
+// Current returns the version information of this build
+func Current() Info {
+	return Info{
+		Version:       Version,
+		SchemaVersion: SchemaVersion,
+	}
+}
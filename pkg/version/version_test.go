@@ -0,0 +1,26 @@
+package version
+
+import (
+	"encoding/json"
+	"testing"
+)
+
+func TestCurrent(t *testing.T) {
+	info := Current()
+	if info.Version != Version {
+		t.Errorf("Version = %q, want %q", info.Version, Version)
+	}
+	if info.SchemaVersion != SchemaVersion {
+		t.Errorf("SchemaVersion = %d, want %d", info.SchemaVersion, SchemaVersion)
+	}
+}
+
+func TestInfoJSON(t *testing.T) {
+	data, err := json.Marshal(Info{Version: "v1.2.3", SchemaVersion: 4})
+	if err != nil {
+		t.Fatalf("Marshal() error = %v", err)
+	}
+	if string(data) != `{"version":"v1.2.3","schema_version":4}` {
+		t.Errorf("Unexpected JSON: %s", data)
+	}
+}
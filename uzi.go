@@ -13,16 +13,44 @@ import (
 	"regexp"
 	"strings"
 
+	"github.com/nehpz/claudicus/cmd/attach"
+	"github.com/nehpz/claudicus/cmd/auth"
 	"github.com/nehpz/claudicus/cmd/broadcast"
 	"github.com/nehpz/claudicus/cmd/checkpoint"
+	"github.com/nehpz/claudicus/cmd/ci"
+	"github.com/nehpz/claudicus/cmd/completion"
+	"github.com/nehpz/claudicus/cmd/diff"
+	"github.com/nehpz/claudicus/cmd/digest"
+	"github.com/nehpz/claudicus/cmd/export"
+	"github.com/nehpz/claudicus/cmd/grep"
+	"github.com/nehpz/claudicus/cmd/health"
+	importer "github.com/nehpz/claudicus/cmd/import"
+	initcmd "github.com/nehpz/claudicus/cmd/init"
 	"github.com/nehpz/claudicus/cmd/kill"
 	"github.com/nehpz/claudicus/cmd/ls"
+	"github.com/nehpz/claudicus/cmd/nudge"
+	"github.com/nehpz/claudicus/cmd/overlap"
+	"github.com/nehpz/claudicus/cmd/pause"
+	"github.com/nehpz/claudicus/cmd/ports"
 	"github.com/nehpz/claudicus/cmd/prompt"
+	"github.com/nehpz/claudicus/cmd/queue"
+	"github.com/nehpz/claudicus/cmd/recover"
+	"github.com/nehpz/claudicus/cmd/report"
 	"github.com/nehpz/claudicus/cmd/reset"
 	"github.com/nehpz/claudicus/cmd/run"
+	"github.com/nehpz/claudicus/cmd/status"
+	"github.com/nehpz/claudicus/cmd/todos"
+	"github.com/nehpz/claudicus/cmd/trash"
 	"github.com/nehpz/claudicus/cmd/tui"
+	"github.com/nehpz/claudicus/cmd/version"
 	"github.com/nehpz/claudicus/cmd/watch"
+	"github.com/nehpz/claudicus/cmd/worktrees"
+	"github.com/nehpz/claudicus/pkg/agents"
+	"github.com/nehpz/claudicus/pkg/cmdctx"
+	"github.com/nehpz/claudicus/pkg/config"
 
+	"github.com/charmbracelet/log"
+	"github.com/peterbourgon/ff/v3"
 	"github.com/peterbourgon/ff/v3/ffcli"
 )
 
@@ -35,7 +63,38 @@ var subcommands = []*ffcli.Command{
 	checkpoint.CmdCheckpoint,
 	watch.CmdWatch,
 	broadcast.CmdBroadcast,
+	broadcast.CmdAsk,
 	tui.CmdTui,
+	completion.CmdCompletion,
+	prompt.CmdAdopt,
+	nudge.CmdNudge,
+	version.CmdVersion,
+	prompt.CmdPipeline,
+	ls.CmdTop,
+	recover.CmdRecover,
+	importer.CmdImport,
+	watch.CmdFollow,
+	worktrees.CmdWorktrees,
+	export.CmdExport,
+	ci.CmdCI,
+	initcmd.CmdInit,
+	pause.CmdPause,
+	pause.CmdResume,
+	queue.CmdQueue,
+	diff.CmdDiff,
+	report.CmdReport,
+	trash.CmdTrash,
+	trash.CmdUndo,
+	health.CmdHealth,
+	grep.CmdGrep,
+	auth.CmdAuth,
+	todos.CmdTodos,
+	status.CmdStatus,
+	status.CmdRunStatus,
+	overlap.CmdOverlap,
+	digest.CmdDigest,
+	attach.CmdAttach,
+	ports.CmdPorts,
 }
 
 var commandAliases = map[string]*regexp.Regexp{
@@ -59,9 +118,15 @@ func main() {
 	c.Name = filepath.Base(os.Args[0])
 	c.ShortUsage = "uzi <command>"
 	c.Subcommands = subcommands
+	completion.SetSubcommands(subcommands)
 
 	c.FlagSet = flag.NewFlagSet("uzi", flag.ContinueOnError)
 	c.FlagSet.SetOutput(os.Stdout)
+	repo := c.FlagSet.String("repo", "", "operate on the repository at this path instead of the current directory (env: UZI_REPO)")
+	profile := c.FlagSet.String("profile", "", "merge this profile from the profiles: section of uzi.yaml over the base settings (env: UZI_PROFILE)")
+	logLevel := c.FlagSet.String("log-level", "info", "log at this level: debug, info, warn, or error (env: UZI_LOG_LEVEL)")
+	jsonOutput := c.FlagSet.Bool("json", false, "print JSON from the commands that support it (env: UZI_JSON)")
+	c.Options = []ff.Option{ff.WithEnvVarPrefix("UZI")}
 	c.Exec = func(ctx context.Context, args []string) error {
 		fmt.Fprintf(os.Stdout, "%s\n", c.UsageFunc(c))
 
@@ -72,16 +137,7 @@ func main() {
 	}
 
 	// Resolve command aliases before parsing
-	args := os.Args[1:]
-	if len(args) > 0 {
-		cmdName := args[0]
-		for realCmd, pattern := range commandAliases {
-			if pattern.MatchString(cmdName) {
-				args[0] = realCmd
-				break
-			}
-		}
-	}
+	args := resolveAliases(os.Args[1:])
 
 	switch err := c.Parse(args); {
 	case err == nil:
@@ -94,8 +150,116 @@ func main() {
 		os.Exit(1)
 	}
 
-	if err := c.Run(ctx); err != nil {
+	if err := useRepo(*repo); err != nil {
+		fmt.Fprintf(os.Stderr, "uzi: error: %v\n", err)
+		os.Exit(1)
+	}
+
+	if err := useProfile(*profile); err != nil {
+		fmt.Fprintf(os.Stderr, "uzi: error: %v\n", err)
+		os.Exit(1)
+	}
+
+	// Agent definitions dropped in ~/.config/uzi/agents join the built-in agents
+	if err := agents.Load(); err != nil {
+		fmt.Fprintf(os.Stderr, "uzi: warning: not loading agent definitions: %v\n", err)
+	}
+
+	level, err := log.ParseLevel(*logLevel)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "uzi: error: invalid --log-level %q\n", *logLevel)
+		os.Exit(1)
+	}
+
+	// Every subcommand shares one context with the config, state store, and
+	// logger, created after --repo and --profile took effect
+	cc := cmdctx.New(config.GetDefaultConfigPath(), level)
+	cc.JSON = *jsonOutput
+	log.SetDefault(cc.Logger)
+	ctx = cmdctx.With(ctx, cc)
+
+	err = c.Run(ctx)
+	// Webhooks for what the command did are delivered before uzi exits
+	cc.Wait()
+	if err != nil {
 		fmt.Fprintf(os.Stderr, "uzi: error: %v\n", err)
+		// Commands run from pipelines, such as `uzi ci run`, pick their own exit codes
+		var exitErr interface{ ExitCode() int }
+		if errors.As(err, &exitErr) {
+			os.Exit(exitErr.ExitCode())
+		}
 		os.Exit(1)
 	}
 }
+
+// resolveAliases expands a command alias in args, skipping over global flags
+// such as --repo that may precede the command name
+func resolveAliases(args []string) []string {
+	i := 0
+	for i < len(args) && strings.HasPrefix(args[i], "-") {
+		name := strings.TrimLeft(args[i], "-")
+		if name == "repo" || name == "profile" || name == "log-level" {
+			i++ // flag value is the next argument
+		}
+		i++
+	}
+	if i >= len(args) {
+		return args
+	}
+
+	for realCmd, pattern := range commandAliases {
+		if pattern.MatchString(args[i]) {
+			args[i] = realCmd
+			break
+		}
+	}
+	return args
+}
+
+// useRepo switches the working directory to the given repository so the state
+// manager, git operations, and tmux session naming all resolve against it.
+// UZI_REPO is exported as an absolute path so child uzi processes spawned by
+// the TUI operate on the same repository.
+func useRepo(repo string) error {
+	if repo == "" {
+		return nil
+	}
+
+	absRepo, err := filepath.Abs(repo)
+	if err != nil {
+		return fmt.Errorf("invalid repo path %q: %w", repo, err)
+	}
+
+	info, err := os.Stat(absRepo)
+	if err != nil {
+		return fmt.Errorf("repo path %q: %w", repo, err)
+	}
+	if !info.IsDir() {
+		return fmt.Errorf("repo path %q is not a directory", repo)
+	}
+
+	if err := os.Chdir(absRepo); err != nil {
+		return fmt.Errorf("failed to change to repo %q: %w", repo, err)
+	}
+
+	return os.Setenv("UZI_REPO", absRepo)
+}
+
+// useProfile selects the config profile every command loads uzi.yaml with.
+// A profile that uzi.yaml does not define is an error here rather than
+// settings silently falling back to the base ones. UZI_PROFILE is exported so
+// child uzi processes spawned by the TUI use the same profile.
+func useProfile(profile string) error {
+	config.SetProfile(profile)
+	if config.ActiveProfile() == "" {
+		return nil
+	}
+	path := config.GetDefaultConfigPath()
+	if _, err := config.LoadConfig(path); err != nil {
+		if os.IsNotExist(err) {
+			return fmt.Errorf("profile %q: no %s in this repository", profile, path)
+		}
+		return err
+	}
+	return os.Setenv("UZI_PROFILE", config.ActiveProfile())
+}